@@ -0,0 +1,162 @@
+// Command benchcmp compares two `go test -bench` outputs and exits non-zero
+// when any benchmark present in both regressed by more than the threshold.
+//
+//	benchcmp [-threshold 10] [-allocs] baseline.txt current.txt
+//
+// Each benchmark is summarised by the median of its runs, so results
+// recorded with -count N are robust against a single noisy sample.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// result holds the samples recorded for one benchmark.
+type result struct {
+	nsOp     []float64
+	allocsOp []float64
+}
+
+// parse reads `go test -bench` output, keyed by benchmark name with the
+// GOMAXPROCS suffix removed. Lines that are not benchmark results are
+// ignored.
+func parse(path string) (map[string]*result, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	out := make(map[string]*result)
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) < 4 || !strings.HasPrefix(fields[0], "Benchmark") {
+			continue
+		}
+		name := fields[0]
+		if i := strings.LastIndexByte(name, '-'); i > 0 {
+			if _, err := strconv.Atoi(name[i+1:]); err == nil {
+				name = name[:i]
+			}
+		}
+		r := out[name]
+		if r == nil {
+			r = &result{}
+			out[name] = r
+		}
+		// fields[1] is the iteration count; the rest are value/unit pairs.
+		for i := 2; i+1 < len(fields); i += 2 {
+			v, err := strconv.ParseFloat(fields[i], 64)
+			if err != nil {
+				continue
+			}
+			switch fields[i+1] {
+			case "ns/op":
+				r.nsOp = append(r.nsOp, v)
+			case "allocs/op":
+				r.allocsOp = append(r.allocsOp, v)
+			}
+		}
+	}
+	return out, sc.Err()
+}
+
+func median(xs []float64) float64 {
+	if len(xs) == 0 {
+		return 0
+	}
+	s := append([]float64(nil), xs...)
+	sort.Float64s(s)
+	n := len(s)
+	if n%2 == 1 {
+		return s[n/2]
+	}
+	return (s[n/2-1] + s[n/2]) / 2
+}
+
+// delta returns the relative change from base to cur in percent.
+func delta(base, cur float64) float64 {
+	if base == 0 {
+		if cur == 0 {
+			return 0
+		}
+		return 100
+	}
+	return (cur - base) / base * 100
+}
+
+func main() {
+	threshold := flag.Float64("threshold", 10, "maximum allowed slowdown in percent")
+	allocs := flag.Bool("allocs", false, "also fail when allocs/op grow beyond the threshold")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: benchcmp [flags] baseline.txt current.txt\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	base, err := parse(flag.Arg(0))
+	if err != nil {
+		log.Fatalf("baseline: %v", err)
+	}
+	cur, err := parse(flag.Arg(1))
+	if err != nil {
+		log.Fatalf("current: %v", err)
+	}
+
+	names := make([]string, 0, len(cur))
+	for name := range cur {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "benchmark\tbase ns/op\tnew ns/op\tdelta\tbase allocs\tnew allocs\t")
+	var regressions []string
+	for _, name := range names {
+		c := cur[name]
+		b, ok := base[name]
+		if !ok {
+			fmt.Fprintf(tw, "%s\t-\t%.0f\tnew\t-\t%.0f\t\n", name, median(c.nsOp), median(c.allocsOp))
+			continue
+		}
+		bNs, cNs := median(b.nsOp), median(c.nsOp)
+		bAl, cAl := median(b.allocsOp), median(c.allocsOp)
+		d := delta(bNs, cNs)
+		mark := ""
+		if d > *threshold || (*allocs && delta(bAl, cAl) > *threshold) {
+			mark = "  REGRESSION"
+			regressions = append(regressions, name)
+		}
+		fmt.Fprintf(tw, "%s\t%.0f\t%.0f\t%+.1f%%%s\t%.0f\t%.0f\t\n", name, bNs, cNs, d, mark, bAl, cAl)
+	}
+	var missing []string
+	for name := range base {
+		if _, ok := cur[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	sort.Strings(missing)
+	for _, name := range missing {
+		fmt.Fprintf(tw, "%s\t%.0f\t-\tmissing\t%.0f\t-\t\n", name, median(base[name].nsOp), median(base[name].allocsOp))
+	}
+	tw.Flush()
+
+	if len(regressions) > 0 {
+		fmt.Fprintf(os.Stderr, "\n%d benchmark(s) regressed by more than %.0f%%: %s\n",
+			len(regressions), *threshold, strings.Join(regressions, ", "))
+		os.Exit(1)
+	}
+}
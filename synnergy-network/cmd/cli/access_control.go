@@ -1,10 +1,8 @@
 package cli
 
 import (
-	"encoding/hex"
 	"errors"
 	"fmt"
-	"strings"
 	"sync"
 
 	"github.com/spf13/cobra"
@@ -30,13 +28,7 @@ func accessInit(cmd *cobra.Command, _ []string) error {
 }
 
 func acDecodeAddr(h string) (core.Address, error) {
-	var a core.Address
-	b, err := hex.DecodeString(strings.TrimPrefix(h, "0x"))
-	if err != nil || len(b) != len(a) {
-		return a, fmt.Errorf("invalid address")
-	}
-	copy(a[:], b)
-	return a, nil
+	return core.DecodeAddress(h)
 }
 
 func accessGrantHandler(cmd *cobra.Command, args []string) error {
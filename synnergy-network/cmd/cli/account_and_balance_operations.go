@@ -61,7 +61,7 @@ func acctHandleTransfer(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	to, err := core.StringToAddress(toStr)
+	to, err := core.ResolveRecipient(toStr)
 	if err != nil {
 		return err
 	}
@@ -80,7 +80,7 @@ var acctTransferCmd = &cobra.Command{Use: "transfer", Short: "Transfer between a
 
 func init() {
 	acctTransferCmd.Flags().String("from", "", "sender")
-	acctTransferCmd.Flags().String("to", "", "recipient")
+	acctTransferCmd.Flags().String("to", "", "recipient address or .syn name")
 	acctTransferCmd.Flags().Uint64("amt", 0, "amount")
 	acctTransferCmd.MarkFlagRequired("from")
 	acctTransferCmd.MarkFlagRequired("to")
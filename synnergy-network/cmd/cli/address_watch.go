@@ -0,0 +1,129 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	core "synnergy-network/core"
+)
+
+var addrWatchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Monitor addresses and notify on transfers, balance changes and contract calls",
+}
+
+var addrWatchAddCmd = &cobra.Command{
+	Use:   "add <addr>...",
+	Short: "Register addresses to monitor",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ownerStr, _ := cmd.Flags().GetString("owner")
+		owner, err := core.DecodeAddress(ownerStr)
+		if err != nil {
+			return fmt.Errorf("--owner: %w", err)
+		}
+		req := core.WatchRequest{Owner: owner, Nonce: core.RegistrationNonce(owner)}
+		for _, s := range args {
+			a, err := core.DecodeAddress(s)
+			if err != nil {
+				return err
+			}
+			req.Addresses = append(req.Addresses, a)
+		}
+		req.Label, _ = cmd.Flags().GetString("label")
+		req.Events, _ = cmd.Flags().GetStringSlice("events")
+		req.MinAmount, _ = cmd.Flags().GetUint64("min")
+		req.BalanceThreshold, _ = cmd.Flags().GetUint64("balance-threshold")
+		req.Webhook, _ = cmd.Flags().GetString("webhook")
+		req.Secret, _ = cmd.Flags().GetString("secret")
+		req.Email, _ = cmd.Flags().GetString("email")
+		sig, err := registrationSig(cmd, core.WatchRequestTypedData(req))
+		if err != nil || sig == nil {
+			return err
+		}
+		w, err := core.CreateWatch(req, sig)
+		if err != nil {
+			return err
+		}
+		out, _ := json.MarshalIndent(w, "", "  ")
+		fmt.Println(string(out))
+		return nil
+	},
+}
+
+var addrWatchListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List watches",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		list, err := core.ListWatches()
+		if err != nil {
+			return err
+		}
+		for _, w := range list {
+			fmt.Printf("%s %q %d addresses [%s]\n", w.ID, w.Label, len(w.Addresses), strings.Join(w.Events, ","))
+		}
+		return nil
+	},
+}
+
+var addrWatchRemoveCmd = &cobra.Command{
+	Use:   "remove <id>",
+	Short: "Stop a watch, keeping its notification history",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return core.DeleteWatch(args[0])
+	},
+}
+
+var addrWatchHistoryCmd = &cobra.Command{
+	Use:   "history <id>",
+	Short: "Print the notifications of a watch as JSON lines, oldest first",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if _, err := core.GetWatch(args[0]); err != nil {
+			return err
+		}
+		typ, _ := cmd.Flags().GetString("type")
+		from, _ := cmd.Flags().GetUint64("from")
+		limit, _ := cmd.Flags().GetInt("limit")
+		list, err := core.WatchNotifications(args[0], typ, from, limit)
+		if err != nil {
+			return err
+		}
+		for _, n := range list {
+			raw, _ := json.Marshal(n)
+			fmt.Println(string(raw))
+		}
+		return nil
+	},
+}
+
+func init() {
+	addrWatchAddCmd.Flags().String("owner", "", "account the watch belongs to, which signs the request")
+	addrWatchAddCmd.Flags().String("label", "", "name shown in notifications")
+	addrWatchAddCmd.Flags().StringSlice("events", nil, "incoming,outgoing,balance,contract (default: all)")
+	addrWatchAddCmd.Flags().Uint64("min", 0, "ignore transfers below this amount")
+	addrWatchAddCmd.Flags().Uint64("balance-threshold", 0, "net change in one block that raises a balance event")
+	addrWatchAddCmd.Flags().String("webhook", "", "URL notified of events")
+	addrWatchAddCmd.Flags().String("secret", "", "HMAC key signing webhook payloads")
+	addrWatchAddCmd.Flags().String("email", "", "address e-mailed on events")
+	registrationFlags(addrWatchAddCmd, "owner")
+
+	addrWatchHistoryCmd.Flags().String("type", "", "only this event type")
+	addrWatchHistoryCmd.Flags().Uint64("from", 0, "first block height")
+	addrWatchHistoryCmd.Flags().Int("limit", 0, "maximum notifications (0: all)")
+
+	addrWatchCmd.AddCommand(
+		addrWatchAddCmd,
+		addrWatchListCmd,
+		addrWatchRemoveCmd,
+		addrWatchHistoryCmd,
+	)
+}
+
+// WatchCmd is exported for index.go
+var WatchCmd = addrWatchCmd
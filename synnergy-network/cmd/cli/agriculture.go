@@ -1,10 +1,8 @@
 package cli
 
 import (
-	"encoding/hex"
 	"fmt"
 	"os"
-	"strings"
 
 	"github.com/joho/godotenv"
 	"github.com/spf13/cobra"
@@ -35,13 +33,7 @@ func agriInit(cmd *cobra.Command, _ []string) error {
 }
 
 func parseAddrString(addr string) (core.Address, error) {
-	var a core.Address
-	b, err := hex.DecodeString(strings.TrimPrefix(addr, "0x"))
-	if err != nil || len(b) != len(a) {
-		return a, fmt.Errorf("bad address")
-	}
-	copy(a[:], b)
-	return a, nil
+	return core.DecodeAddress(addr)
 }
 
 func agriRegister(cmd *cobra.Command, args []string) error {
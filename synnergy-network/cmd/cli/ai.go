@@ -72,13 +72,7 @@ func ensureAIInitialised(cmd *cobra.Command, _ []string) error {
 type AIController struct{}
 
 func parseAddr(hexStr string) (core.Address, error) {
-	var a core.Address
-	b, err := hex.DecodeString(strings.TrimPrefix(hexStr, "0x"))
-	if err != nil || len(b) != len(a) {
-		return a, fmt.Errorf("invalid address")
-	}
-	copy(a[:], b)
-	return a, nil
+	return core.DecodeAddress(hexStr)
 }
 
 func (c *AIController) PredictAnomaly(txPath string) (float32, error) {
@@ -14,15 +14,9 @@ import (
 	core "synnergy-network/core"
 )
 
-// parseAddress converts a hex string to core.Address
+// parseAddress converts a bech32 or hex string to core.Address
 func parseAddressAddr(h string) (core.Address, error) {
-	var a core.Address
-	b, err := hex.DecodeString(strings.TrimPrefix(h, "0x"))
-	if err != nil || len(b) != len(a) {
-		return a, fmt.Errorf("invalid address %s", h)
-	}
-	copy(a[:], b)
-	return a, nil
+	return core.DecodeAddress(h)
 }
 
 // Controller wraps the core functions
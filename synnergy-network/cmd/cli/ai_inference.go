@@ -6,10 +6,13 @@ package cli
 // for anomaly scores.
 
 import (
+	"crypto/ed25519"
+	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"strconv"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -44,6 +47,24 @@ func (c *AIInferenceController) Analyse(path string) (map[core.Hash]float32, err
 	return core.AI().AnalyseTransactions(txs)
 }
 
+// loadHostKey reads a hex encoded ed25519 seed used by model hosts.
+func loadHostKey(path string) (ed25519.PrivateKey, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	seed, err := hex.DecodeString(strings.TrimSpace(string(raw)))
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("invalid host key")
+	}
+	return ed25519.NewKeyFromSeed(seed), nil
+}
+
+func printInference(v interface{}) {
+	out, _ := json.MarshalIndent(v, "", "  ")
+	fmt.Println(string(out))
+}
+
 var aiInferenceCmd = &cobra.Command{
 	Use:               "ai_infer",
 	Short:             "AI inference and analysis utilities",
@@ -82,9 +103,232 @@ var analyseCmd = &cobra.Command{
 	},
 }
 
+var inferHostCmd = &cobra.Command{
+	Use:   "host-register <model-hash> <key-file> [endpoint]",
+	Short: "Bond and register this key as an inference host for a model",
+	Args:  cobra.RangeArgs(2, 3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		h, err := core.ParseModelHash(strings.TrimPrefix(args[0], "0x"))
+		if err != nil {
+			return err
+		}
+		key, err := loadHostKey(args[1])
+		if err != nil {
+			return err
+		}
+		endpoint := ""
+		if len(args) == 3 {
+			endpoint = args[2]
+		}
+		bond, _ := cmd.Flags().GetUint64("bond")
+		pub := key.Public().(ed25519.PublicKey)
+		return core.AI().RegisterModelHost(h, core.Ed25519Address(pub), pub, endpoint, bond)
+	},
+}
+
+var inferUnhostCmd = &cobra.Command{
+	Use:   "host-unregister <model-hash> <key-file>",
+	Short: "Stop hosting a model and reclaim the host bond",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		h, err := core.ParseModelHash(strings.TrimPrefix(args[0], "0x"))
+		if err != nil {
+			return err
+		}
+		key, err := loadHostKey(args[1])
+		if err != nil {
+			return err
+		}
+		return core.AI().UnregisterModelHost(h, core.Ed25519Address(key.Public().(ed25519.PublicKey)))
+	},
+}
+
+var inferRequestCmd = &cobra.Command{
+	Use:   "request <model-hash> <requester> <input-file> <payment>",
+	Short: "Escrow payment for an on-chain committed inference",
+	Args:  cobra.ExactArgs(4),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		h, err := core.ParseModelHash(strings.TrimPrefix(args[0], "0x"))
+		if err != nil {
+			return err
+		}
+		requester, err := core.ParseAddress(args[1])
+		if err != nil {
+			return err
+		}
+		input, err := ioutil.ReadFile(args[2])
+		if err != nil {
+			return fmt.Errorf("read input: %w", err)
+		}
+		pay, err := strconv.ParseUint(args[3], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid payment: %w", err)
+		}
+		cid, _ := cmd.Flags().GetString("input-cid")
+		req, err := core.AI().RequestInference(h, requester, sha256.Sum256(input), cid, pay)
+		if err != nil {
+			return err
+		}
+		printInference(req)
+		return nil
+	},
+}
+
+var inferServeCmd = &cobra.Command{
+	Use:   "serve <request-id> <input-file> <key-file>",
+	Short: "Execute a request as host or verifier and commit the result",
+	Args:  cobra.ExactArgs(3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		input, err := ioutil.ReadFile(args[1])
+		if err != nil {
+			return fmt.Errorf("read input: %w", err)
+		}
+		key, err := loadHostKey(args[2])
+		if err != nil {
+			return err
+		}
+		req, _, err := core.AI().ServeInference(args[0], input, key)
+		if err != nil {
+			return err
+		}
+		printInference(req)
+		return nil
+	},
+}
+
+var inferFinalizeCmd = &cobra.Command{
+	Use:   "finalize <request-id>",
+	Short: "Pay the host after the dispute window or refund an expired dispute",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		req, err := core.AI().FinalizeInference(args[0])
+		if err != nil {
+			return err
+		}
+		printInference(req)
+		return nil
+	},
+}
+
+var inferDisputeCmd = &cobra.Command{
+	Use:   "dispute <request-id> <challenger> <bond>",
+	Short: "Challenge a committed result and trigger re-execution",
+	Args:  cobra.ExactArgs(3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ch, err := core.ParseAddress(args[1])
+		if err != nil {
+			return err
+		}
+		bond, err := strconv.ParseUint(args[2], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid bond: %w", err)
+		}
+		req, err := core.AI().DisputeInference(args[0], ch, bond)
+		if err != nil {
+			return err
+		}
+		printInference(req)
+		return nil
+	},
+}
+
+var inferShowCmd = &cobra.Command{
+	Use:   "show <request-id>",
+	Short: "Show an inference request and its commitments",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		req, err := core.AI().GetInferenceRequest(args[0])
+		if err != nil {
+			return err
+		}
+		printInference(req)
+		return nil
+	},
+}
+
+var inferListCmd = &cobra.Command{
+	Use:   "list [status]",
+	Short: "List inference requests",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var st core.InferenceStatus
+		if len(args) == 1 {
+			st = core.InferenceStatus(args[0])
+		}
+		list, err := core.AI().ListInferenceRequests(st)
+		if err != nil {
+			return err
+		}
+		printInference(list)
+		return nil
+	},
+}
+
+var inferFeedbackCmd = &cobra.Command{
+	Use:   "feedback <model-hash> <predicted> <actual>",
+	Short: "Submit ground truth for a prediction",
+	Args:  cobra.ExactArgs(3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		h, err := core.ParseModelHash(strings.TrimPrefix(args[0], "0x"))
+		if err != nil {
+			return err
+		}
+		pred, err := strconv.ParseFloat(args[1], 32)
+		if err != nil {
+			return fmt.Errorf("invalid prediction: %w", err)
+		}
+		act, err := strconv.ParseFloat(args[2], 32)
+		if err != nil {
+			return fmt.Errorf("invalid label: %w", err)
+		}
+		fb, err := core.AI().SubmitGroundTruth(h, float32(pred), float32(act))
+		if err != nil {
+			return err
+		}
+		printInference(fb)
+		return nil
+	},
+}
+
+var inferDriftEvalCmd = &cobra.Command{
+	Use:   "drift-eval",
+	Short: "Evaluate drift for all loaded models",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		reps, err := core.AI().EvaluateDrift()
+		if err != nil {
+			return err
+		}
+		printInference(reps)
+		return nil
+	},
+}
+
+var inferDriftCmd = &cobra.Command{
+	Use:   "drift <model-hash>",
+	Short: "Show the latest drift report for a model",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		h, err := core.ParseModelHash(strings.TrimPrefix(args[0], "0x"))
+		if err != nil {
+			return err
+		}
+		rep, err := core.AI().DriftReportFor(h)
+		if err != nil {
+			return err
+		}
+		printInference(rep)
+		return nil
+	},
+}
+
 func init() {
+	inferRequestCmd.Flags().String("input-cid", "", "optional storage CID of the input")
+	inferHostCmd.Flags().Uint64("bond", core.MinModelHostBond, "bond locked while hosting the model")
 	aiInferenceCmd.AddCommand(inferCmd)
 	aiInferenceCmd.AddCommand(analyseCmd)
+	aiInferenceCmd.AddCommand(inferHostCmd, inferUnhostCmd, inferRequestCmd, inferServeCmd, inferFinalizeCmd, inferDisputeCmd, inferShowCmd, inferListCmd)
+	aiInferenceCmd.AddCommand(inferFeedbackCmd, inferDriftEvalCmd, inferDriftCmd)
 }
 
 var AIInferenceCmd = aiInferenceCmd
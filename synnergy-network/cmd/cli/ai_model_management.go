@@ -67,7 +67,7 @@ var mgmtListCmd = &cobra.Command{
 
 var mgmtUpdateCmd = &cobra.Command{
 	Use:   "update <id> <price>",
-	Short: "Update listing price",
+	Short: "Schedule a listing price change (24h timelock)",
 	Args:  cobra.ExactArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		p, err := strconv.ParseUint(args[1], 10, 64)
@@ -89,8 +89,46 @@ var mgmtRemoveCmd = &cobra.Command{
 	},
 }
 
+var mgmtUsageCmd = &cobra.Command{
+	Use:   "usage <model-hash>",
+	Short: "Show usage counters and revenue for a model",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		h, err := core.ParseModelHash(args[0])
+		if err != nil {
+			return err
+		}
+		u, err := core.GetModelUsage(h)
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		return enc.Encode(u)
+	},
+}
+
+var mgmtEarningsCmd = &cobra.Command{
+	Use:   "earnings <creator>",
+	Short: "Show royalties earned by a model creator",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		creator, err := core.ParseAddress(args[0])
+		if err != nil {
+			return err
+		}
+		e, err := core.GetCreatorEarnings(creator)
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		return enc.Encode(e)
+	},
+}
+
 func init() {
-	aiMgmtCmd.AddCommand(mgmtGetCmd, mgmtListCmd, mgmtUpdateCmd, mgmtRemoveCmd)
+	aiMgmtCmd.AddCommand(mgmtGetCmd, mgmtListCmd, mgmtUpdateCmd, mgmtRemoveCmd, mgmtUsageCmd, mgmtEarningsCmd)
 }
 
 // AIMgmtCmd is exported for registration in index.go.
@@ -0,0 +1,306 @@
+package cli
+
+import (
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	core "synnergy-network/core"
+)
+
+func airdropPrint(v interface{}) {
+	out, _ := json.MarshalIndent(v, "", "  ")
+	fmt.Println(string(out))
+}
+
+// airdropReadSnapshot loads the output of `tokens snapshot`, as JSON or as
+// CSV with address and balance columns.
+func airdropReadSnapshot(path string) (*core.HolderSnapshot, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(filepath.Ext(path), ".csv") {
+		var snap core.HolderSnapshot
+		if err := json.Unmarshal(raw, &snap); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		return &snap, nil
+	}
+	rows, err := csv.NewReader(strings.NewReader(string(raw))).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%s: empty snapshot", path)
+	}
+	addrCol, balCol := -1, -1
+	for i, h := range rows[0] {
+		switch h {
+		case "address":
+			addrCol = i
+		case "balance":
+			balCol = i
+		}
+	}
+	if addrCol < 0 || balCol < 0 {
+		return nil, fmt.Errorf("%s: address and balance columns required", path)
+	}
+	snap := &core.HolderSnapshot{}
+	for n, row := range rows[1:] {
+		bal, err := strconv.ParseUint(row[balCol], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s line %d: %w", path, n+2, err)
+		}
+		snap.Holders = append(snap.Holders, core.SnapshotHolder{Index: n, Address: row[addrCol], Balance: bal})
+		snap.Total += bal
+	}
+	return snap, nil
+}
+
+func airdropReadTree(path string) (*core.AirdropTree, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var t core.AirdropTree
+	if err := json.Unmarshal(raw, &t); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &t, nil
+}
+
+// airdropExpiry accepts a duration from now or an RFC 3339 time.
+func airdropExpiry(s string) (time.Time, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		return time.Now().UTC().Add(d), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expiry %q is neither a duration nor RFC 3339", s)
+	}
+	return t, nil
+}
+
+func airdropID(s string) (uint64, error) {
+	id, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid airdrop id %q", s)
+	}
+	return id, nil
+}
+
+var airdropCmd = &cobra.Command{
+	Use:   "airdrop",
+	Short: "Merkle airdrops: build trees from snapshots, publish, claim and sweep",
+}
+
+var airdropTreeCmd = &cobra.Command{
+	Use:   "tree",
+	Short: "Build the entry tree of a drop from a holder snapshot",
+	Long: `Reads a snapshot written by "tokens snapshot" and writes the airdrop
+tree: the Merkle root and every entry with its proof. By default each
+holder receives its balance; --total splits that amount pro rata instead.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		path, _ := cmd.Flags().GetString("snapshot")
+		snap, err := airdropReadSnapshot(path)
+		if err != nil {
+			return err
+		}
+		total, _ := cmd.Flags().GetUint64("total")
+		tree, err := core.BuildAirdropTree(core.AirdropEntriesFromSnapshot(snap, total))
+		if err != nil {
+			return err
+		}
+		out, _ := json.MarshalIndent(tree, "", "  ")
+		if file, _ := cmd.Flags().GetString("file"); file != "" {
+			if err := os.WriteFile(file, append(out, '\n'), 0o644); err != nil {
+				return err
+			}
+		} else {
+			fmt.Fprintln(cmd.OutOrStdout(), string(out))
+		}
+		fmt.Fprintf(cmd.ErrOrStderr(), "%d entries, total %d\nmerkle root: %s\n", len(tree.Entries), tree.Total, tree.Root)
+		return nil
+	},
+}
+
+var airdropProofCmd = &cobra.Command{
+	Use:   "proof <address>",
+	Short: "Print the entry and proof of one recipient",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		addr, err := core.DecodeAddress(args[0])
+		if err != nil {
+			return err
+		}
+		path, _ := cmd.Flags().GetString("tree")
+		tree, err := airdropReadTree(path)
+		if err != nil {
+			return err
+		}
+		e, ok := tree.Entry(addr)
+		if !ok {
+			return fmt.Errorf("%s is not in the drop", addr.Hex())
+		}
+		airdropPrint(struct {
+			Root string `json:"root"`
+			*core.AirdropEntry
+		}{tree.Root, e})
+		return nil
+	},
+}
+
+var airdropPublishCmd = &cobra.Command{
+	Use:   "publish <creator>",
+	Short: "Publish a drop, escrowing its total from creator",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		creator, err := core.DecodeAddress(args[0])
+		if err != nil {
+			return err
+		}
+		cfg := core.AirdropConfig{}
+		if path, _ := cmd.Flags().GetString("tree"); path != "" {
+			tree, err := airdropReadTree(path)
+			if err != nil {
+				return err
+			}
+			cfg.Root, cfg.Total, cfg.Entries = tree.Root, tree.Total, len(tree.Entries)
+		}
+		if cmd.Flags().Changed("root") {
+			cfg.Root, _ = cmd.Flags().GetString("root")
+		}
+		if cmd.Flags().Changed("total") {
+			cfg.Total, _ = cmd.Flags().GetUint64("total")
+		}
+		exp, _ := cmd.Flags().GetString("expires")
+		if cfg.Expires, err = airdropExpiry(exp); err != nil {
+			return err
+		}
+		if t, _ := cmd.Flags().GetString("treasury"); t != "" {
+			if cfg.Treasury, err = core.DecodeAddress(t); err != nil {
+				return err
+			}
+		}
+		if id, _ := cmd.Flags().GetUint32("token"); id != 0 {
+			cfg.Asset = core.AssetRef{Kind: core.AssetToken, TokenID: core.TokenID(id)}
+		}
+		a, err := core.PublishAirdrop(&core.Context{Caller: creator}, creator, cfg)
+		if err != nil {
+			return err
+		}
+		airdropPrint(a)
+		return nil
+	},
+}
+
+var airdropClaimCmd = &cobra.Command{
+	Use:   "claim <id> <address>",
+	Short: "Claim the entry of address with its proof from --tree",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := airdropID(args[0])
+		if err != nil {
+			return err
+		}
+		addr, err := core.DecodeAddress(args[1])
+		if err != nil {
+			return err
+		}
+		path, _ := cmd.Flags().GetString("tree")
+		tree, err := airdropReadTree(path)
+		if err != nil {
+			return err
+		}
+		e, ok := tree.Entry(addr)
+		if !ok {
+			return fmt.Errorf("%s is not in the drop", addr.Hex())
+		}
+		proof := make([][]byte, len(e.Proof))
+		for i, p := range e.Proof {
+			if proof[i], err = hex.DecodeString(p); err != nil {
+				return fmt.Errorf("proof element %d: %w", i, err)
+			}
+		}
+		if err := core.ClaimAirdrop(&core.Context{Caller: addr}, id, uint32(e.Index), addr, e.Amount, proof); err != nil {
+			return err
+		}
+		fmt.Printf("claimed %d for %s\n", e.Amount, addr.Hex())
+		return nil
+	},
+}
+
+var airdropSweepCmd = &cobra.Command{
+	Use:   "sweep <id>",
+	Short: "Return the unclaimed rest of an expired drop to its treasury",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := airdropID(args[0])
+		if err != nil {
+			return err
+		}
+		rest, err := core.SweepAirdrop(&core.Context{}, id)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("swept %d to the treasury\n", rest)
+		return nil
+	},
+}
+
+var airdropInfoCmd = &cobra.Command{
+	Use:   "info <id>",
+	Short: "Show a published drop",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := airdropID(args[0])
+		if err != nil {
+			return err
+		}
+		a, err := core.GetAirdrop(id)
+		if err != nil {
+			return err
+		}
+		airdropPrint(a)
+		return nil
+	},
+}
+
+func init() {
+	airdropTreeCmd.Flags().String("snapshot", "", "snapshot file (.json or .csv)")
+	airdropTreeCmd.Flags().Uint64("total", 0, "amount to split pro rata (default: each holder's balance)")
+	airdropTreeCmd.Flags().String("file", "", "write the tree to this file instead of stdout")
+	airdropTreeCmd.MarkFlagRequired("snapshot")
+	airdropProofCmd.Flags().String("tree", "", "tree file written by airdrop tree")
+	airdropProofCmd.MarkFlagRequired("tree")
+	airdropPublishCmd.Flags().String("tree", "", "take root, total and entry count from this tree file")
+	airdropPublishCmd.Flags().String("root", "", "hex Merkle root")
+	airdropPublishCmd.Flags().Uint64("total", 0, "amount to escrow")
+	airdropPublishCmd.Flags().String("expires", "720h", "claim window as a duration or RFC 3339 time")
+	airdropPublishCmd.Flags().String("treasury", "", "receives unclaimed funds (default: creator)")
+	airdropPublishCmd.Flags().Uint32("token", 0, "token ID to drop (default: the coin)")
+	airdropClaimCmd.Flags().String("tree", "", "tree file written by airdrop tree")
+	airdropClaimCmd.MarkFlagRequired("tree")
+
+	airdropCmd.AddCommand(
+		airdropTreeCmd,
+		airdropProofCmd,
+		airdropPublishCmd,
+		airdropClaimCmd,
+		airdropSweepCmd,
+		airdropInfoCmd,
+	)
+}
+
+// AirdropCmd is exported for index.go
+var AirdropCmd = airdropCmd
@@ -14,17 +14,20 @@
 //	$ synnergy amm remove  <poolID> <provider> <lpTokens>
 //	$ synnergy amm quote   <tokenIn> <amtIn> <tokenOut>
 //	$ synnergy amm pairs
+//	$ synnergy amm positions <addr> [--pool id]
+//	$ synnergy amm apr <poolID> [--limit n]
+//	$ synnergy amm treasury
+//	$ synnergy amm swap-permit <request.json>
 //
 // -----------------------------------------------------------
 package cli
 
 import (
 	"context"
-	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"os"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -44,6 +47,9 @@ type initMiddleware func(cmd *cobra.Command, args []string) error
 // routing graph built. It depends on other modules having initialised
 // the ledger and pool manager – we assert their presence.
 func ensureAMMInitialised(cmd *cobra.Command, _ []string) error {
+	if id := viper.GetInt64("network.chain_id"); id != 0 {
+		core.SetPermitChainID(id)
+	}
 	if core.Manager() != nil { // pools & router already set up
 		return nil
 	}
@@ -79,13 +85,7 @@ func decodeTokenID(s string) (core.TokenID, error) {
 }
 
 func decodeAddr(hexStr string) (core.Address, error) {
-	var a core.Address
-	b, err := hex.DecodeString(strings.TrimPrefix(hexStr, "0x"))
-	if err != nil || len(b) != len(a) {
-		return a, fmt.Errorf("invalid address")
-	}
-	copy(a[:], b)
-	return a, nil
+	return core.DecodeAddress(hexStr)
 }
 
 func (c *AMMController) SwapExactIn(trader core.Address, tokenIn core.TokenID, amtIn uint64, tokenOut core.TokenID, minOut uint64, maxHops int) (uint64, error) {
@@ -282,6 +282,111 @@ var pairsCmd = &cobra.Command{
 	},
 }
 
+// positions ------------------------------------------------------------------
+var positionsCmd = &cobra.Command{
+	Use:   "positions <provider‑addr>",
+	Short: "Show LP positions with accrued fees, impermanent loss and APR",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		provider, err := decodeAddr(args[0])
+		if err != nil {
+			return err
+		}
+		var positions []core.LPPositionView
+		if pid, _ := cmd.Flags().GetUint32("pool"); pid != 0 {
+			pos, err := core.LPPositionOf(core.PoolID(pid), provider)
+			if err != nil {
+				return err
+			}
+			positions = append(positions, pos)
+		} else if positions, err = core.LPPositions(provider); err != nil {
+			return err
+		}
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			enc, _ := json.MarshalIndent(positions, "", "  ")
+			fmt.Println(string(enc))
+			return nil
+		}
+		if len(positions) == 0 {
+			fmt.Println("no positions")
+			return nil
+		}
+		for _, p := range positions {
+			fmt.Printf("pool %d  lp %d  share %.4f%%  value %.2f  hold %.2f  fees %.2f  IL %.2f%%  APR %.2f%%\n",
+				p.Pool, p.LP, p.Share*100, p.Value, p.HoldValue, p.FeeValue, p.ImpermanentLoss*100, p.APR*100)
+		}
+		return nil
+	},
+}
+
+// apr ------------------------------------------------------------------------
+var aprCmd = &cobra.Command{
+	Use:   "apr <poolID>",
+	Short: "Show the sampled fee APR history of a pool",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pid64, err := strconv.ParseUint(args[0], 10, 32)
+		if err != nil {
+			return fmt.Errorf("poolID uint32: %w", err)
+		}
+		limit, _ := cmd.Flags().GetInt("limit")
+		hist, err := core.PoolAPRHistory(core.PoolID(pid64), limit)
+		if err != nil {
+			return err
+		}
+		for _, s := range hist {
+			fmt.Printf("%s  height %d  APR %.2f%%\n", time.Unix(s.Time, 0).UTC().Format(time.RFC3339), s.Height, s.APR*100)
+		}
+		return nil
+	},
+}
+
+// treasury -------------------------------------------------------------------
+var treasuryCmd = &cobra.Command{
+	Use:   "treasury",
+	Short: "Report protocol fee switch settings, fees taken and treasury-owned LP",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		report, err := core.TreasuryReport()
+		if err != nil {
+			return err
+		}
+		enc, _ := json.MarshalIndent(report, "", "  ")
+		fmt.Println(string(enc))
+		return nil
+	},
+}
+
+// swap-permit -------------------------------------------------------------------
+var swapPermitCmd = &cobra.Command{
+	Use:   "swap-permit <request.json>",
+	Short: "Approve and swap in one step with an owner's signed permit",
+	Long: `Reads a permit request (token, owner, spender, value, nonce, deadline,
+sig and swap {amount_in, token_out, min_out}) and swaps from the owner's
+balance. The permit must name the AMM router as spender.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		raw, err := os.ReadFile(args[0])
+		if err != nil {
+			return err
+		}
+		var req core.PermitRequest
+		if err := json.Unmarshal(raw, &req); err != nil {
+			return fmt.Errorf("bad permit request: %w", err)
+		}
+		if req.Swap == nil {
+			return fmt.Errorf("permit request has no swap")
+		}
+		res, err := core.SubmitPermit(req)
+		if err != nil {
+			return err
+		}
+		enc, _ := json.MarshalIndent(res, "", "  ")
+		fmt.Println(string(enc))
+		return nil
+	},
+}
+
 //---------------------------------------------------------------------
 // Consolidation & export
 //---------------------------------------------------------------------
@@ -290,6 +395,9 @@ func init() {
 	// shared flag across swap & quote
 	swapCmd.Flags().Int("max‑hops", 4, "maximum hops allowed in the route")
 	quoteCmd.Flags().Int("max‑hops", 4, "maximum hops allowed in the route")
+	positionsCmd.Flags().Uint32("pool", 0, "only show the position in this pool")
+	positionsCmd.Flags().Bool("json", false, "print positions as JSON")
+	aprCmd.Flags().Int("limit", 0, "only show the latest n samples")
 
 	ammCmd.AddCommand(initCmd)
 	ammCmd.AddCommand(swapCmd)
@@ -297,6 +405,10 @@ func init() {
 	ammCmd.AddCommand(removeCmd)
 	ammCmd.AddCommand(quoteCmd)
 	ammCmd.AddCommand(pairsCmd)
+	ammCmd.AddCommand(positionsCmd)
+	ammCmd.AddCommand(aprCmd)
+	ammCmd.AddCommand(treasuryCmd)
+	ammCmd.AddCommand(swapPermitCmd)
 }
 
 // Export for main‑index import: rootCmd.AddCommand(cli.AMMCmd)
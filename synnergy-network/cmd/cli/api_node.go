@@ -2,6 +2,7 @@ package cli
 
 import (
 	"fmt"
+	"net/http"
 	"os"
 	"os/signal"
 	"sync"
@@ -13,8 +14,11 @@ import (
 )
 
 var (
-	apiNode *core.APINode
-	apiMu   sync.RWMutex
+	apiNode   *core.APINode
+	apiAdmin  *core.AdminRPC
+	apiP2P    *core.Node
+	apiLedger *core.Ledger
+	apiMu     sync.RWMutex
 )
 
 func apiInit(cmd *cobra.Command, _ []string) error {
@@ -32,6 +36,7 @@ func apiInit(cmd *cobra.Command, _ []string) error {
 	}
 	apiMu.Lock()
 	apiNode = core.NewAPINode(n, led)
+	apiP2P, apiLedger = n, led
 	apiMu.Unlock()
 	return nil
 }
@@ -43,16 +48,53 @@ func apiStart(cmd *cobra.Command, _ []string) error {
 	if n == nil {
 		return fmt.Errorf("not initialised")
 	}
+	if err := apiStartAdmin(cmd); err != nil {
+		return err
+	}
 	go func() {
 		sig := make(chan os.Signal, 1)
 		signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
 		<-sig
 		_ = n.APINode_Stop()
+		if apiAdmin != nil {
+			_ = apiAdmin.Stop()
+		}
 		os.Exit(0)
 	}()
+	maxCalls, _ := cmd.Flags().GetInt("batch-max")
+	conc, _ := cmd.Flags().GetInt("batch-concurrency")
+	n.SetBatchConfig(core.BatchConfig{MaxCalls: maxCalls, Concurrency: conc})
 	return n.APINode_Start(cmd.Flag("addr").Value.String())
 }
 
+// apiStartAdmin starts the admin JSON-RPC listener when --admin-addr is
+// set. The token is read from ADMIN_RPC_TOKEN so it never shows up in the
+// process list.
+func apiStartAdmin(cmd *cobra.Command) error {
+	addr, _ := cmd.Flags().GetString("admin-addr")
+	if addr == "" {
+		return nil
+	}
+	auditPath, _ := cmd.Flags().GetString("admin-audit")
+	trail, err := core.NewAuditTrail(auditPath, apiLedger)
+	if err != nil {
+		return err
+	}
+	admin, err := core.NewAdminRPC(apiP2P, apiLedger, core.AdminConfig{Token: os.Getenv("ADMIN_RPC_TOKEN"), Audit: trail})
+	if err != nil {
+		return err
+	}
+	apiMu.Lock()
+	apiAdmin = admin
+	apiMu.Unlock()
+	go func() {
+		if err := admin.Start(addr); err != nil && err != http.ErrServerClosed {
+			fmt.Fprintln(os.Stderr, "admin rpc:", err)
+		}
+	}()
+	return nil
+}
+
 func apiStop(cmd *cobra.Command, _ []string) error {
 	apiMu.RLock()
 	n := apiNode
@@ -60,6 +102,9 @@ func apiStop(cmd *cobra.Command, _ []string) error {
 	if n == nil {
 		return fmt.Errorf("not running")
 	}
+	if apiAdmin != nil {
+		_ = apiAdmin.Stop()
+	}
 	return n.APINode_Stop()
 }
 
@@ -69,6 +114,10 @@ var apiStopCmd = &cobra.Command{Use: "stop", Short: "Stop API node", RunE: apiSt
 
 func init() {
 	apiStartCmd.Flags().String("addr", ":8080", "listen address")
+	apiStartCmd.Flags().Int("batch-max", core.DefaultBatchConfig().MaxCalls, "maximum calls per /batch request")
+	apiStartCmd.Flags().Int("batch-concurrency", core.DefaultBatchConfig().Concurrency, "calls of a batch executed concurrently")
+	apiStartCmd.Flags().String("admin-addr", "", "listen address of the admin JSON-RPC API (disabled when empty; token from ADMIN_RPC_TOKEN)")
+	apiStartCmd.Flags().String("admin-audit", "admin_audit.log", "audit trail recording every admin call")
 	apiCmd.AddCommand(apiStartCmd, apiStopCmd)
 }
 
@@ -5,9 +5,13 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log"
+	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
@@ -28,7 +32,31 @@ func ensureApplier(cmd *cobra.Command, _ []string) error {
 	if as == nil {
 		as = core.NewAuthoritySet(logrus.StandardLogger(), led)
 	}
-	applier = core.NewAuthorityApplier(logrus.StandardLogger(), led, as, nil)
+	stdlog := log.New(logrus.StandardLogger().Out, "", log.LstdFlags)
+	applier = core.NewAuthorityApplier(stdlog, led, as, nil)
+	return nil
+}
+
+// ensureDocumentStore wires the IPFS gateway used to pin application
+// documents from IPFS_GATEWAY and CACHE_DIR.
+func ensureDocumentStore() error {
+	gw := os.Getenv("IPFS_GATEWAY")
+	if gw == "" {
+		return errors.New("IPFS gateway must be provided via IPFS_GATEWAY")
+	}
+	dir := os.Getenv("CACHE_DIR")
+	if dir == "" {
+		dir = filepath.Join(os.TempDir(), "synnergy-authapply")
+	}
+	st, err := core.NewStorage(&core.StorageConfig{
+		IPFSGateway:    gw,
+		CacheDir:       dir,
+		GatewayTimeout: 30 * time.Second,
+	}, logrus.StandardLogger(), nil)
+	if err != nil {
+		return err
+	}
+	applier.SetDocumentStore(st)
 	return nil
 }
 
@@ -50,6 +78,27 @@ func (c *ApplyController) Get(id core.Hash) (core.AuthApplication, bool, error)
 func (c *ApplyController) List(st core.AuthAppStatus) ([]core.AuthApplication, error) {
 	return applier.ListApplications(st)
 }
+func (c *ApplyController) Attach(id core.Hash, sender core.Address, name string, data []byte) (core.ApplicationDocument, error) {
+	return applier.AttachDocument(id, sender, name, data)
+}
+func (c *ApplyController) Comment(id core.Hash, author core.Address, support bool, text string) error {
+	return applier.CommentApplication(id, author, support, text)
+}
+func (c *ApplyController) Comments(id core.Hash) ([]core.ApplicationComment, error) {
+	return applier.ListComments(id)
+}
+func (c *ApplyController) Tenure(addr core.Address) (core.AuthorityTenure, bool) {
+	return applier.Tenure(addr)
+}
+func (c *ApplyController) Tenures(activeOnly bool) ([]core.AuthorityTenure, error) {
+	return applier.ListTenures(activeOnly)
+}
+func (c *ApplyController) Deregister(sender, addr core.Address) error {
+	return applier.Deregister(sender, addr)
+}
+func (c *ApplyController) ProposeRemoval(creator, addr core.Address, reason string) (string, error) {
+	return core.ProposeAuthorityDeregistration(creator, addr, reason)
+}
 
 // helpers
 
@@ -74,13 +123,23 @@ func parseRoleApply(s string) (core.AuthorityRole, error) {
 }
 
 func hexToAddr(s string) (core.Address, error) {
-	var a core.Address
+	return core.DecodeAddress(s)
+}
+
+func parseAppID(s string) (core.Hash, error) {
+	var h core.Hash
 	b, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
-	if err != nil || len(b) != len(a) {
-		return a, fmt.Errorf("invalid address")
+	if err != nil {
+		return h, err
 	}
-	copy(a[:], b)
-	return a, nil
+	copy(h[:], b)
+	return h, nil
+}
+
+func printApplyJSON(cmd *cobra.Command, v interface{}) error {
+	enc := json.NewEncoder(cmd.OutOrStdout())
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
 }
 
 // CLI commands
@@ -144,10 +203,20 @@ var applyFinalizeCmd = &cobra.Command{
 	},
 }
 
-var applyTickCmd = &cobra.Command{Use: "tick", Run: func(cmd *cobra.Command, args []string) {
-	ctrl := &ApplyController{}
-	ctrl.Tick(time.Now().UTC())
-}}
+var applyTickCmd = &cobra.Command{
+	Use:   "tick",
+	Short: "Re-run application and term housekeeping at the time of the head block",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		led := core.CurrentLedger()
+		head, err := led.GetBlock(led.LastHeight())
+		if err != nil {
+			return err
+		}
+		ctrl := &ApplyController{}
+		ctrl.Tick(time.UnixMilli(head.Header.Timestamp))
+		return nil
+	},
+}
 
 var applyGetCmd = &cobra.Command{
 	Use:  "get <id>",
@@ -184,9 +253,150 @@ var applyListCmd = &cobra.Command{Use: "list", RunE: func(cmd *cobra.Command, ar
 	return enc.Encode(apps)
 }}
 
+var applyAttachCmd = &cobra.Command{
+	Use:   "attach <id> <candidate> <file>",
+	Short: "Pin a supporting document to an application under review",
+	Args:  cobra.ExactArgs(3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctrl := &ApplyController{}
+		id, err := parseAppID(args[0])
+		if err != nil {
+			return err
+		}
+		addr, err := hexToAddr(args[1])
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(args[2])
+		if err != nil {
+			return err
+		}
+		if err := ensureDocumentStore(); err != nil {
+			return err
+		}
+		doc, err := ctrl.Attach(id, addr, filepath.Base(args[2]), data)
+		if err != nil {
+			return err
+		}
+		return printApplyJSON(cmd, doc)
+	},
+}
+
+var applyCommentCmd = &cobra.Command{
+	Use:   "comment <id> <author> <text> [--support]",
+	Short: "Comment on an application in its public comment period",
+	Args:  cobra.MinimumNArgs(3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctrl := &ApplyController{}
+		id, err := parseAppID(args[0])
+		if err != nil {
+			return err
+		}
+		author, err := hexToAddr(args[1])
+		if err != nil {
+			return err
+		}
+		support, _ := cmd.Flags().GetBool("support")
+		return ctrl.Comment(id, author, support, strings.Join(args[2:], " "))
+	},
+}
+
+var applyCommentsCmd = &cobra.Command{
+	Use:  "comments <id>",
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctrl := &ApplyController{}
+		id, err := parseAppID(args[0])
+		if err != nil {
+			return err
+		}
+		cs, err := ctrl.Comments(id)
+		if err != nil {
+			return err
+		}
+		return printApplyJSON(cmd, cs)
+	},
+}
+
+var applyTenureCmd = &cobra.Command{
+	Use:  "tenure <addr>",
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctrl := &ApplyController{}
+		addr, err := hexToAddr(args[0])
+		if err != nil {
+			return err
+		}
+		t, ok := ctrl.Tenure(addr)
+		if !ok {
+			return errors.New("not found")
+		}
+		return printApplyJSON(cmd, t)
+	},
+}
+
+var applyTenuresCmd = &cobra.Command{Use: "tenures [--active]", RunE: func(cmd *cobra.Command, args []string) error {
+	ctrl := &ApplyController{}
+	active, _ := cmd.Flags().GetBool("active")
+	ts, err := ctrl.Tenures(active)
+	if err != nil {
+		return err
+	}
+	return printApplyJSON(cmd, ts)
+}}
+
+var applyDeregisterCmd = &cobra.Command{
+	Use:   "deregister <addr>",
+	Short: "End your own tenure as an authority and start its cooldown",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctrl := &ApplyController{}
+		addr, err := hexToAddr(args[0])
+		if err != nil {
+			return err
+		}
+		keyHex, _ := cmd.Flags().GetString("key")
+		key, err := crypto.HexToECDSA(strings.TrimPrefix(keyHex, "0x"))
+		if err != nil {
+			return fmt.Errorf("invalid --key: %w", err)
+		}
+		sender := core.FromCommon(crypto.PubkeyToAddress(key.PublicKey))
+		return ctrl.Deregister(sender, addr)
+	},
+}
+
+var applyProposeRemovalCmd = &cobra.Command{
+	Use:   "propose-removal <addr> <creator> <reason>",
+	Short: "Propose removing an authority through governance",
+	Args:  cobra.MinimumNArgs(3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctrl := &ApplyController{}
+		addr, err := hexToAddr(args[0])
+		if err != nil {
+			return err
+		}
+		creator, err := hexToAddr(args[1])
+		if err != nil {
+			return err
+		}
+		id, err := ctrl.ProposeRemoval(creator, addr, strings.Join(args[2:], " "))
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), id)
+		return nil
+	},
+}
+
 func init() {
 	applyVoteCmd.Flags().Bool("approve", true, "approve application")
-	applyCmd.AddCommand(applySubmitCmd, applyVoteCmd, applyFinalizeCmd, applyTickCmd, applyGetCmd, applyListCmd)
+	applyCommentCmd.Flags().Bool("support", false, "support rather than object to the application")
+	applyTenuresCmd.Flags().Bool("active", false, "only serving authorities")
+	applyDeregisterCmd.Flags().String("key", "", "hex secp256k1 private key of the authority")
+	applyDeregisterCmd.MarkFlagRequired("key")
+	applyCmd.AddCommand(applySubmitCmd, applyVoteCmd, applyFinalizeCmd, applyTickCmd, applyGetCmd, applyListCmd,
+		applyAttachCmd, applyCommentCmd, applyCommentsCmd, applyTenureCmd, applyTenuresCmd, applyDeregisterCmd,
+		applyProposeRemovalCmd)
 }
 
 var AuthorityApplyCmd = applyCmd
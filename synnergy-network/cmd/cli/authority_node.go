@@ -18,7 +18,6 @@ package cli
 
 import (
 	"context"
-	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -119,9 +118,7 @@ func parseRole(s string) (core.AuthorityRole, error) {
 }
 
 func mustHex(addr string) core.Address {
-	var a core.Address
-	b, _ := hex.DecodeString(strings.TrimPrefix(addr, "0x"))
-	copy(a[:], b)
+	a, _ := core.DecodeAddress(addr)
 	return a
 }
 
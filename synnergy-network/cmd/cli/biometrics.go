@@ -1,7 +1,12 @@
 package cli
 
 import (
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"io/ioutil"
+	"time"
 
 	"github.com/spf13/cobra"
 	core "synnergy-network/core"
@@ -43,6 +48,55 @@ func bioDelete(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func bioRegisterRecovery(cmd *cobra.Command, args []string) error {
+	led := core.CurrentLedger()
+	if led == nil {
+		return errors.New("ledger not initialised")
+	}
+	file, _ := cmd.Flags().GetString("file")
+	factor, _ := cmd.Flags().GetString("factor")
+	keyPath, _ := cmd.Flags().GetString("key")
+	sample, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	priv, err := loadHostKey(keyPath)
+	if err != nil {
+		return err
+	}
+	pub := priv.Public().(ed25519.PublicKey)
+	addr := core.Ed25519Address(pub)
+	ts := time.Now().Unix()
+	sig := ed25519.Sign(priv, core.RecoveryEnrolMessage(addr, []byte(factor), ts))
+	if _, err := core.RegisterBiometricRecovery(led, addr, sample, []byte(factor), ts, pub, sig); err != nil {
+		return err
+	}
+	cmd.Printf("recovery registered for %s\n", addr.Hex())
+	return nil
+}
+
+func bioRecover(cmd *cobra.Command, args []string) error {
+	led := core.CurrentLedger()
+	if led == nil {
+		return errors.New("ledger not initialised")
+	}
+	addr, _ := cmd.Flags().GetString("address")
+	to, _ := cmd.Flags().GetString("to")
+	file, _ := cmd.Flags().GetString("file")
+	factor, _ := cmd.Flags().GetString("factor")
+	sample, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	rec, err := core.RecoverAccountBiometric(led, mustHex(addr), mustHex(to), sample, []byte(factor))
+	if err != nil {
+		return err
+	}
+	out, _ := json.MarshalIndent(rec, "", "  ")
+	fmt.Fprintln(cmd.OutOrStdout(), string(out))
+	return nil
+}
+
 // -----------------------------------------------------------------------------
 // Cobra wiring
 // -----------------------------------------------------------------------------
@@ -69,6 +123,18 @@ var bioDeleteCmd = &cobra.Command{
 	RunE:  bioDelete,
 }
 
+var bioRegisterRecoveryCmd = &cobra.Command{
+	Use:   "register-recovery",
+	Short: "bind biometric sample and second factor to the signing key's account",
+	RunE:  bioRegisterRecovery,
+}
+
+var bioRecoverCmd = &cobra.Command{
+	Use:   "recover",
+	Short: "recover an account to a new address using biometrics and second factor",
+	RunE:  bioRecover,
+}
+
 func init() {
 	bioEnrollCmd.Flags().String("address", "", "address")
 	bioEnrollCmd.Flags().String("file", "", "data file")
@@ -83,7 +149,23 @@ func init() {
 	bioDeleteCmd.Flags().String("address", "", "address")
 	bioDeleteCmd.MarkFlagRequired("address")
 
-	bioCmd.AddCommand(bioEnrollCmd, bioVerifyCmd, bioDeleteCmd)
+	bioRegisterRecoveryCmd.Flags().String("file", "", "biometric sample file")
+	bioRegisterRecoveryCmd.Flags().String("factor", "", "second factor (PIN or recovery code)")
+	bioRegisterRecoveryCmd.Flags().String("key", "", "hex ed25519 seed of the account")
+	bioRegisterRecoveryCmd.MarkFlagRequired("file")
+	bioRegisterRecoveryCmd.MarkFlagRequired("factor")
+	bioRegisterRecoveryCmd.MarkFlagRequired("key")
+
+	bioRecoverCmd.Flags().String("address", "", "account to recover")
+	bioRecoverCmd.Flags().String("to", "", "new address receiving the balance")
+	bioRecoverCmd.Flags().String("file", "", "biometric sample file")
+	bioRecoverCmd.Flags().String("factor", "", "second factor")
+	bioRecoverCmd.MarkFlagRequired("address")
+	bioRecoverCmd.MarkFlagRequired("to")
+	bioRecoverCmd.MarkFlagRequired("file")
+	bioRecoverCmd.MarkFlagRequired("factor")
+
+	bioCmd.AddCommand(bioEnrollCmd, bioVerifyCmd, bioDeleteCmd, bioRegisterRecoveryCmd, bioRecoverCmd)
 }
 
 // Exported command variable
@@ -4,7 +4,6 @@ import (
 	"bufio"
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"net"
 	"os"
@@ -12,6 +11,8 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+
+	core "synnergy-network/core"
 )
 
 // -----------------------------------------------------------
@@ -86,13 +87,13 @@ func syncStatusRPC(ctx context.Context) (map[string]any, error) {
 	}
 	var resp struct {
 		Data  map[string]any `json:"data"`
-		Error string         `json:"error,omitempty"`
+		Error core.WireError `json:"error,omitempty"`
 	}
 	if err := cli.readJSON(&resp); err != nil {
 		return nil, err
 	}
-	if resp.Error != "" {
-		return nil, errors.New(resp.Error)
+	if resp.Error.Err() != nil {
+		return nil, resp.Error.Err()
 	}
 	return resp.Data, nil
 }
@@ -13,6 +13,7 @@
 //	$ synnergy charity vote tz1Alice tz1Charity
 //	$ synnergy charity tick                     # manual cron kick
 //	$ synnergy charity winners                  # inspect winners list
+//	$ synnergy charity cycles 3 --verify        # cycle report & draw replay
 //
 // -----------------------------------------------------------------------------
 package cli
@@ -264,6 +265,45 @@ var winnersCmd = &cobra.Command{
 	},
 }
 
+// cycles ---------------------------------------------------------------------
+var cyclesCmd = &cobra.Command{
+	Use:   "cycles [cycle]",
+	Short: "Show cycle reports (collections, payouts, verifiable winner draw)",
+	Args:  cobra.RangeArgs(0, 1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		led := core.CurrentLedger()
+		if led == nil {
+			return errors.New("ledger not initialised")
+		}
+		if len(args) == 0 {
+			reps, err := core.ListCharityCycleReports(led)
+			if err != nil {
+				return err
+			}
+			b, _ := json.MarshalIndent(reps, "", "  ")
+			fmt.Println(string(b))
+			return nil
+		}
+		c, err := strconv.ParseUint(args[0], 10, 64)
+		if err != nil {
+			return fmt.Errorf("cycle uint64: %w", err)
+		}
+		rep, err := core.GetCharityCycleReport(led, c)
+		if err != nil {
+			return err
+		}
+		b, _ := json.MarshalIndent(rep, "", "  ")
+		fmt.Println(string(b))
+		if verify, _ := cmd.Flags().GetBool("verify"); verify {
+			if err := core.VerifyCharityCycleReport(rep, core.NewLedgerBeacon(led)); err != nil {
+				return err
+			}
+			fmt.Printf("Cycle %d draw verified\n", c)
+		}
+		return nil
+	},
+}
+
 //---------------------------------------------------------------------
 // Consolidation & export
 //---------------------------------------------------------------------
@@ -274,6 +314,8 @@ func init() {
 	charityCmd.AddCommand(tickCmd)
 	charityCmd.AddCommand(registrationCmd)
 	charityCmd.AddCommand(winnersCmd)
+	cyclesCmd.Flags().Bool("verify", false, "replay the winner draw against the chain beacon")
+	charityCmd.AddCommand(cyclesCmd)
 }
 
 // Export for root‑CLI import
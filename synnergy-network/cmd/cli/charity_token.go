@@ -1,7 +1,6 @@
 package cli
 
 import (
-	"encoding/hex"
 	"fmt"
 	"strings"
 
@@ -25,13 +24,7 @@ func ctResolveToken(id string) (*Tokens.CharityToken, error) {
 }
 
 func ctParseAddr(a string) (core.Address, error) {
-	var addr core.Address
-	b, err := hex.DecodeString(strings.TrimPrefix(a, "0x"))
-	if err != nil || len(b) != len(addr) {
-		return addr, fmt.Errorf("bad address")
-	}
-	copy(addr[:], b)
-	return addr, nil
+	return core.DecodeAddress(a)
 }
 
 func ctHandleDonate(cmd *cobra.Command, args []string) error {
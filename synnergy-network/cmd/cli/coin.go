@@ -6,6 +6,9 @@ package cli
 // Commands exposed after `RegisterCoin(rootCmd)`:
 //   ~coin ~mint    <address> <amount>
 //   ~coin ~supply
+//   ~coin ~burned
+//   ~coin ~emission
+//   ~coin ~projection <from> <to> <step>
 //   ~coin ~balance <address>
 //
 // Every symbol is prefixed **coin*** to avoid clashes with other middleware
@@ -13,11 +16,9 @@ package cli
 // -----------------------------------------------------------------------------
 
 import (
-	"encoding/hex"
 	"fmt"
 	"os"
 	"strconv"
-	"strings"
 	"sync"
 
 	"github.com/joho/godotenv"
@@ -102,13 +103,7 @@ func coinEnvOrInt(k string, def int) int {
 // -----------------------------------------------------------------------------
 
 func coinDecodeAddr(h string) (core.Address, error) {
-	var a core.Address
-	b, err := hex.DecodeString(strings.TrimPrefix(h, "0x"))
-	if err != nil || len(b) != len(a) {
-		return a, fmt.Errorf("invalid address")
-	}
-	copy(a[:], b)
-	return a, nil
+	return core.DecodeAddress(h)
 }
 
 func coinParseAmt(s string) (uint64, error) {
@@ -144,6 +139,42 @@ func coinHandleSupply(cmd *cobra.Command, _ []string) error {
 	return nil
 }
 
+func coinHandleBurned(cmd *cobra.Command, _ []string) error {
+	fmt.Fprintf(cmd.OutOrStdout(), "%d\n", coinLedger.TotalBurned(core.Code))
+	return nil
+}
+
+func coinHandleEmission(cmd *cobra.Command, _ []string) error {
+	out := cmd.OutOrStdout()
+	for _, s := range coinLedger.EmissionPolicy().Schedules {
+		fmt.Fprintf(out, "from %d: reward %s halving every %d blocks, miner %d bps, stakers %d bps\n",
+			s.StartHeight, s.InitialReward, s.HalvingPeriod, s.MinerBps, s.StakerBps)
+	}
+	next := coinLedger.LastHeight() + 1
+	fmt.Fprintf(out, "next reward (block %d): %s\n", next, core.BlockRewardAt(next))
+	fmt.Fprintf(out, "total emitted: %s\n", coinLedger.TotalEmitted())
+	return nil
+}
+
+func coinHandleProjection(cmd *cobra.Command, args []string) error {
+	var bounds [3]uint64
+	for i, a := range args {
+		n, err := strconv.ParseUint(a, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid height %q", a)
+		}
+		bounds[i] = n
+	}
+	points, err := coinLedger.EmissionPolicy().Project(bounds[0], bounds[1], bounds[2])
+	if err != nil {
+		return err
+	}
+	for _, p := range points {
+		fmt.Fprintf(cmd.OutOrStdout(), "%d\t%s\t%s\n", p.Height, p.Reward, p.Cumulative)
+	}
+	return nil
+}
+
 func coinHandleBalance(cmd *cobra.Command, args []string) error {
 	addr, err := coinDecodeAddr(args[0])
 	if err != nil {
@@ -158,7 +189,7 @@ func coinHandleTransfer(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	to, err := coinDecodeAddr(args[1])
+	to, err := core.ResolveRecipient(args[1])
 	if err != nil {
 		return err
 	}
@@ -204,9 +235,12 @@ var coinSupplyCmd = &cobra.Command{Use: "supply", Short: "Total supply", Args: c
 var coinBalCmd = &cobra.Command{Use: "balance <addr>", Short: "Balance", Args: cobra.ExactArgs(1), RunE: coinHandleBalance}
 var coinTransferCmd = &cobra.Command{Use: "transfer <from> <to> <amt>", Short: "Transfer SYNN", Args: cobra.ExactArgs(3), RunE: coinHandleTransfer}
 var coinBurnCmd = &cobra.Command{Use: "burn <addr> <amt>", Short: "Burn SYNN", Args: cobra.ExactArgs(2), RunE: coinHandleBurn}
+var coinBurnedCmd = &cobra.Command{Use: "burned", Short: "Total burned", Args: cobra.NoArgs, RunE: coinHandleBurned}
+var coinEmissionCmd = &cobra.Command{Use: "emission", Short: "Emission schedule", Args: cobra.NoArgs, RunE: coinHandleEmission}
+var coinProjectionCmd = &cobra.Command{Use: "projection <from> <to> <step>", Short: "Projected emission", Args: cobra.ExactArgs(3), RunE: coinHandleProjection}
 
 func init() {
-	coinRootCmd.AddCommand(coinMintCmd, coinSupplyCmd, coinBalCmd, coinTransferCmd, coinBurnCmd)
+	coinRootCmd.AddCommand(coinMintCmd, coinSupplyCmd, coinBalCmd, coinTransferCmd, coinBurnCmd, coinBurnedCmd, coinEmissionCmd, coinProjectionCmd)
 }
 
 // -----------------------------------------------------------------------------
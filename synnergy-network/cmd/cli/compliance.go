@@ -63,7 +63,7 @@ func ensureComplianceInitialised(cmd *cobra.Command, _ []string) error {
 			issuers = append(issuers, b)
 		}
 	}
-	core.InitCompliance(nil, issuers)
+	core.InitCompliance(led, issuers)
 	return nil
 }
 
@@ -261,6 +261,147 @@ var verifyZKPCmd = &cobra.Command{
 	},
 }
 
+// issuer ---------------------------------------------------------------------
+var kycIssuerCmd = &cobra.Command{
+	Use:   "issuer",
+	Short: "Manage the KYC issuer registry",
+}
+
+var kycIssuerProposeCmd = &cobra.Command{
+	Use:   "propose <pubkeyHex> <name>",
+	Short: "Submit a governance proposal admitting a KYC issuer",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pk, err := hex.DecodeString(args[0])
+		if err != nil {
+			return fmt.Errorf("decode pubkey: %w", err)
+		}
+		from, _ := cmd.Flags().GetString("from")
+		juris, _ := cmd.Flags().GetStringSlice("jurisdictions")
+		expires, _ := cmd.Flags().GetInt64("expires")
+		iss := core.KYCIssuer{PubKey: pk, Name: args[1], Jurisdictions: juris, Expires: expires}
+		id, err := core.ProposeKYCIssuer(mustHex(from), iss, "")
+		if err != nil {
+			return err
+		}
+		fmt.Printf("proposal %s submitted\n", id)
+		return nil
+	},
+}
+
+var kycIssuerRevokeCmd = &cobra.Command{
+	Use:   "revoke <pubkeyHex> <reason>",
+	Short: "Revoke an issuer (regulator) or propose revocation (--propose)",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pk, err := hex.DecodeString(args[0])
+		if err != nil {
+			return fmt.Errorf("decode pubkey: %w", err)
+		}
+		from, _ := cmd.Flags().GetString("from")
+		if propose, _ := cmd.Flags().GetBool("propose"); propose {
+			id, err := core.ProposeKYCIssuerRevocation(mustHex(from), pk, args[1])
+			if err != nil {
+				return err
+			}
+			fmt.Printf("proposal %s submitted\n", id)
+			return nil
+		}
+		if err := core.Compliance().RevokeIssuer(mustHex(from), pk, args[1]); err != nil {
+			return err
+		}
+		fmt.Println("issuer revoked")
+		return nil
+	},
+}
+
+var kycIssuerListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List registered KYC issuers",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		list, err := core.Compliance().Issuers()
+		if err != nil {
+			return err
+		}
+		b, _ := json.MarshalIndent(list, "", "  ")
+		fmt.Println(string(b))
+		return nil
+	},
+}
+
+// revoke-credential ----------------------------------------------------------
+var kycRevokeCredCmd = &cobra.Command{
+	Use:   "revoke-credential <credIDHex> <issuerPubHex> <sigHex> [reason]",
+	Short: "Add an issuer-signed credential revocation to the list",
+	Args:  cobra.RangeArgs(3, 4),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		idb, err := hex.DecodeString(args[0])
+		if err != nil || len(idb) != 32 {
+			return errors.New("credential id must be 32-byte hex")
+		}
+		var id [32]byte
+		copy(id[:], idb)
+		pk, err := hex.DecodeString(args[1])
+		if err != nil {
+			return fmt.Errorf("decode pubkey: %w", err)
+		}
+		sig, err := hex.DecodeString(args[2])
+		if err != nil {
+			return fmt.Errorf("decode sig: %w", err)
+		}
+		reason := ""
+		if len(args) == 4 {
+			reason = args[3]
+		}
+		if err := core.Compliance().RevokeCredential(id, pk, sig, reason); err != nil {
+			return err
+		}
+		fmt.Println("credential revoked")
+		return nil
+	},
+}
+
+var kycRevocationsCmd = &cobra.Command{
+	Use:   "revocations [issuerPubHex]",
+	Short: "Show the credential revocation list",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var pk []byte
+		if len(args) == 1 {
+			var err error
+			if pk, err = hex.DecodeString(args[0]); err != nil {
+				return fmt.Errorf("decode pubkey: %w", err)
+			}
+		}
+		list, err := core.Compliance().Revocations(pk)
+		if err != nil {
+			return err
+		}
+		b, _ := json.MarshalIndent(list, "", "  ")
+		fmt.Println(string(b))
+		return nil
+	},
+}
+
+// kyc-status -----------------------------------------------------------------
+var kycStatusCmd = &cobra.Command{
+	Use:   "kyc-status <address>",
+	Short: "Check whether an address holds a valid KYC credential",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		addr := mustHex(args[0])
+		if err := core.Compliance().CheckKYC(addr); err != nil {
+			fmt.Printf("%s: not verified (%v)\n", args[0], err)
+			return nil
+		}
+		cred, _ := core.Compliance().Credential(addr)
+		b, _ := json.MarshalIndent(cred, "", "  ")
+		fmt.Println(string(b))
+		return nil
+	},
+}
+
 //---------------------------------------------------------------------
 // Consolidation & export
 //---------------------------------------------------------------------
@@ -274,6 +415,17 @@ func init() {
 	complianceCmd.AddCommand(monitorCmd)
 	complianceCmd.AddCommand(verifyZKPCmd)
 
+	kycIssuerProposeCmd.Flags().String("from", "", "proposer address")
+	kycIssuerProposeCmd.Flags().StringSlice("jurisdictions", nil, "ISO country codes the issuer may attest for")
+	kycIssuerProposeCmd.Flags().Int64("expires", 0, "key expiry as unix seconds (0 = never)")
+	kycIssuerRevokeCmd.Flags().String("from", "", "regulator or proposer address")
+	kycIssuerRevokeCmd.Flags().Bool("propose", false, "submit a governance proposal instead of revoking directly")
+	kycIssuerCmd.AddCommand(kycIssuerProposeCmd, kycIssuerRevokeCmd, kycIssuerListCmd)
+	complianceCmd.AddCommand(kycIssuerCmd)
+	complianceCmd.AddCommand(kycRevokeCredCmd)
+	complianceCmd.AddCommand(kycRevocationsCmd)
+	complianceCmd.AddCommand(kycStatusCmd)
+
 }
 
 // Export for root‑CLI integration
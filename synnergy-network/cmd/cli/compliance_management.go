@@ -1,9 +1,11 @@
 package cli
 
 import (
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
 
 	core "synnergy-network/core"
 
@@ -61,6 +63,47 @@ func (ComplianceMgmtController) Review(txPath string) error {
 	return core.ComplianceMgmt().ReviewTransaction(&tx)
 }
 
+func ensureScreener() *core.TxScreener {
+	if s := core.CurrentScreener(); s != nil {
+		return s
+	}
+	return core.InitTxScreener(core.CurrentLedger(), core.DefaultScreeningRules())
+}
+
+func parseTxHash(s string) (core.Hash, error) {
+	var h core.Hash
+	b, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil || len(b) != len(h) {
+		return h, fmt.Errorf("invalid tx hash")
+	}
+	copy(h[:], b)
+	return h, nil
+}
+
+func (ComplianceMgmtController) Screen(txPath string) (core.ScreeningResult, error) {
+	raw, err := os.ReadFile(txPath)
+	if err != nil {
+		return core.ScreeningResult{}, err
+	}
+	var tx core.Transaction
+	if err := json.Unmarshal(raw, &tx); err != nil {
+		return core.ScreeningResult{}, err
+	}
+	return ensureScreener().Score(&tx), nil
+}
+
+func (ComplianceMgmtController) Quarantine() ([]core.QuarantinedTx, error) {
+	return ensureScreener().PendingReview()
+}
+
+func (ComplianceMgmtController) Decide(hash, reviewer string, approve bool) (*core.QuarantinedTx, error) {
+	h, err := parseTxHash(hash)
+	if err != nil {
+		return nil, err
+	}
+	return ensureScreener().ReviewTx(h, mustHex(reviewer), approve)
+}
+
 // CLI
 
 var compMgmtCmd = &cobra.Command{
@@ -129,6 +172,56 @@ var reviewCmd = &cobra.Command{
 	},
 }
 
+var screenCmd = &cobra.Command{
+	Use:   "screen <tx.json>",
+	Short: "Score a transaction against the admission risk rules",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		res, err := ComplianceMgmtController{}.Screen(args[0])
+		if err != nil {
+			return err
+		}
+		out, _ := json.MarshalIndent(res, "", "  ")
+		fmt.Println(string(out))
+		return nil
+	},
+}
+
+var quarantineCmd = &cobra.Command{
+	Use:   "quarantine",
+	Short: "List transactions awaiting authority review",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		list, err := ComplianceMgmtController{}.Quarantine()
+		if err != nil {
+			return err
+		}
+		out, _ := json.MarshalIndent(list, "", "  ")
+		fmt.Println(string(out))
+		return nil
+	},
+}
+
+var approveTxCmd = &cobra.Command{
+	Use:   "approve <tx-hash> <authority>",
+	Short: "Release a quarantined transaction",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		_, err := ComplianceMgmtController{}.Decide(args[0], args[1], true)
+		return err
+	},
+}
+
+var denyTxCmd = &cobra.Command{
+	Use:   "deny <tx-hash> <authority>",
+	Short: "Reject a quarantined transaction",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		_, err := ComplianceMgmtController{}.Decide(args[0], args[1], false)
+		return err
+	},
+}
+
 func init() {
 	compMgmtCmd.AddCommand(suspendCmd)
 	compMgmtCmd.AddCommand(resumeCmd)
@@ -136,6 +229,10 @@ func init() {
 	compMgmtCmd.AddCommand(unwhitelistCmd)
 	compMgmtCmd.AddCommand(statusCmd)
 	compMgmtCmd.AddCommand(reviewCmd)
+	compMgmtCmd.AddCommand(screenCmd)
+	compMgmtCmd.AddCommand(quarantineCmd)
+	compMgmtCmd.AddCommand(approveTxCmd)
+	compMgmtCmd.AddCommand(denyTxCmd)
 }
 
 var ComplianceMgmtCmd = compMgmtCmd
@@ -15,6 +15,12 @@ package cli
 //   • AUTH_DB_PATH          – path to authority/validator DB (SQLite, Bolt, etc.).
 //   • LOG_LEVEL             – trace|debug|info|warn|error (default info).
 //   • CONSENSUS_AUTO_START  – "true" to auto‑start engine when CLI initialises.
+//   • CONSENSUS_TELEMETRY_INTERVAL – adaptive weight recomputation interval
+//                             (Go duration, default 30s; "0" disables).
+//   • CONSENSUS_ORDERING    – pool|fair sub-block ordering (default pool).
+//   • CONSENSUS_MIN_RECEIPTS – validator receipts a tx needs in fair mode (default 1).
+//   • CONSENSUS_RECEIPT_WAIT – longest a tx waits for receipts (Go duration, default 2s).
+//   • CONSENSUS_RECEIPT_KEY – hex secp256k1 key signing this node's tx receipts.
 //
 // Wiring into root CLI remains identical:
 //     import "synnergy-network/cmd/cli/middleware" // adjust path
@@ -24,6 +30,7 @@ package cli
 
 import (
 	"context"
+	"crypto/ecdsa"
 	"fmt"
 	"os"
 	"os/signal"
@@ -31,7 +38,9 @@ import (
 	"strings"
 	"sync"
 	"syscall"
+	"time"
 
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/joho/godotenv"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -143,9 +152,27 @@ func startConsensus(cmd *cobra.Command, _ []string) error {
 		return nil
 	}
 
+	interval := core.DefaultTelemetryInterval
+	if v := os.Getenv("CONSENSUS_TELEMETRY_INTERVAL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("invalid CONSENSUS_TELEMETRY_INTERVAL %s: %w", v, err)
+		}
+		interval = d
+	}
+
+	if err := configureOrdering(consensus); err != nil {
+		return err
+	}
+
 	ctx, cancelFn = context.WithCancel(context.Background())
 	consensus.Start(ctx)
 
+	// Feed the adaptive weights from live telemetry while consensus runs.
+	if interval > 0 && consensusLedger != nil {
+		go core.NewConsensusAdaptiveManager(consensusLedger, consensus, 20).RunTelemetry(ctx, interval)
+	}
+
 	// Handle SIGINT/SIGTERM so standalone CLI sessions exit gracefully.
 	go func() {
 		sigC := make(chan os.Signal, 1)
@@ -159,6 +186,36 @@ func startConsensus(cmd *cobra.Command, _ []string) error {
 	return nil
 }
 
+// configureOrdering applies the CONSENSUS_ORDERING family of variables.
+func configureOrdering(sc *core.SynnergyConsensus) error {
+	mode, err := core.ParseOrderingMode(os.Getenv("CONSENSUS_ORDERING"))
+	if err != nil {
+		return err
+	}
+	if mode != core.OrderingFair {
+		return nil
+	}
+	cfg := core.FairOrderingConfig{Mode: mode}
+	if v := os.Getenv("CONSENSUS_MIN_RECEIPTS"); v != "" {
+		if cfg.MinReceipts, err = strconv.Atoi(v); err != nil {
+			return fmt.Errorf("invalid CONSENSUS_MIN_RECEIPTS %s: %w", v, err)
+		}
+	}
+	if v := os.Getenv("CONSENSUS_RECEIPT_WAIT"); v != "" {
+		if cfg.MaxWait, err = time.ParseDuration(v); err != nil {
+			return fmt.Errorf("invalid CONSENSUS_RECEIPT_WAIT %s: %w", v, err)
+		}
+	}
+	var key *ecdsa.PrivateKey
+	if v := os.Getenv("CONSENSUS_RECEIPT_KEY"); v != "" {
+		if key, err = crypto.HexToECDSA(strings.TrimPrefix(v, "0x")); err != nil {
+			return fmt.Errorf("invalid CONSENSUS_RECEIPT_KEY: %w", err)
+		}
+	}
+	sc.SetFairOrdering(cfg, key)
+	return nil
+}
+
 func stopConsensus(cmd *cobra.Command, _ []string) error {
 	consensusMu.Lock()
 	defer consensusMu.Unlock()
@@ -3,8 +3,13 @@ package cli
 // Adaptive consensus management CLI
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"os/signal"
 	"strconv"
+	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 	core "synnergy-network/core"
@@ -35,7 +40,9 @@ func initAdaptive(cmd *cobra.Command, _ []string) error {
 func adaptMetrics(cmd *cobra.Command, _ []string) error {
 	d := adaptMgr.ComputeDemand()
 	s := adaptMgr.ComputeStakeConcentration()
+	t := adaptMgr.Telemetry()
 	fmt.Fprintf(cmd.OutOrStdout(), "demand: %.2f\nstake-concentration: %.4f\n", d, s)
+	fmt.Fprintf(cmd.OutOrStdout(), "pool-depth: %d\nblock-fullness: %.4f\nstake-gini: %.4f\n", t.PoolDepth, t.BlockFullness, t.StakeGini)
 	return nil
 }
 
@@ -48,6 +55,35 @@ func adaptAdjust(cmd *cobra.Command, _ []string) error {
 	return nil
 }
 
+func adaptRun(cmd *cobra.Command, _ []string) error {
+	interval, _ := cmd.Flags().GetDuration("interval")
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	if err := adaptMgr.RunTelemetry(ctx, interval); err != context.Canceled {
+		return err
+	}
+	return nil
+}
+
+func adaptHistory(cmd *cobra.Command, _ []string) error {
+	limit, _ := cmd.Flags().GetInt("limit")
+	h, err := core.WeightHistory(core.CurrentLedger(), limit)
+	if err != nil {
+		return err
+	}
+	if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		return enc.Encode(h)
+	}
+	for _, t := range h {
+		fmt.Fprintf(cmd.OutOrStdout(), "%8d  %s  pow=%.3f pos=%.3f poh=%.3f  pool=%d full=%.2f gini=%.3f\n",
+			t.Height, time.Unix(t.Timestamp, 0).UTC().Format(time.RFC3339), t.Weights.PoW, t.Weights.PoS, t.Weights.PoH,
+			t.PoolDepth, t.BlockFullness, t.StakeGini)
+	}
+	return nil
+}
+
 func adaptSetCfg(cmd *cobra.Command, args []string) error {
 	if len(args) != 5 {
 		return fmt.Errorf("requires 5 args: alpha beta gamma dmax smax")
@@ -84,6 +120,20 @@ var adaptiveAdjustCmd = &cobra.Command{
 	RunE:  adaptAdjust,
 }
 
+var adaptiveRunCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Recompute weights from live telemetry on an interval",
+	Args:  cobra.NoArgs,
+	RunE:  adaptRun,
+}
+
+var adaptiveHistoryCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Show the recorded weight history, newest first",
+	Args:  cobra.NoArgs,
+	RunE:  adaptHistory,
+}
+
 var adaptiveSetCfgCmd = &cobra.Command{
 	Use:   "set-config [alpha] [beta] [gamma] [dmax] [smax]",
 	Short: "Update weighting coefficients",
@@ -94,7 +144,13 @@ var adaptiveSetCfgCmd = &cobra.Command{
 func init() {
 	adaptiveCmd.AddCommand(adaptiveMetricsCmd)
 	adaptiveCmd.AddCommand(adaptiveAdjustCmd)
+	adaptiveCmd.AddCommand(adaptiveRunCmd)
+	adaptiveCmd.AddCommand(adaptiveHistoryCmd)
 	adaptiveCmd.AddCommand(adaptiveSetCfgCmd)
+
+	adaptiveRunCmd.Flags().Duration("interval", core.DefaultTelemetryInterval, "recomputation interval")
+	adaptiveHistoryCmd.Flags().Int("limit", 20, "samples to show (0 = all)")
+	adaptiveHistoryCmd.Flags().Bool("json", false, "print samples as JSON")
 }
 
 var AdaptiveCmd = adaptiveCmd
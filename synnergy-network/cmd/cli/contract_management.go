@@ -2,9 +2,11 @@ package cli
 
 import (
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"os"
 	"sync"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/spf13/cobra"
@@ -57,23 +59,56 @@ func cmHandleTransfer(cmd *cobra.Command, args []string) error {
 }
 
 func cmHandlePause(cmd *cobra.Command, args []string) error {
+	return cmSetPaused(cmd, args, true)
+}
+
+func cmHandleResume(cmd *cobra.Command, args []string) error {
+	return cmSetPaused(cmd, args, false)
+}
+
+func cmSetPaused(cmd *cobra.Command, args []string, paused bool) error {
 	b, err := hex.DecodeString(args[0])
 	if err != nil {
 		return err
 	}
 	var a core.Address
 	copy(a[:], b)
-	return cmManager.PauseContract(a)
+	from, err := cmCaller(cmd)
+	if err != nil {
+		return err
+	}
+	reason, _ := cmd.Flags().GetString("reason")
+	if paused {
+		return cmManager.PauseContract(from, a, reason)
+	}
+	return cmManager.ResumeContract(from, a, reason)
 }
 
-func cmHandleResume(cmd *cobra.Command, args []string) error {
+func cmHandlePaused(cmd *cobra.Command, args []string) error {
+	led := core.CurrentLedger()
+	if len(args) == 0 {
+		list, err := core.PausedContracts(led)
+		if err != nil {
+			return err
+		}
+		for _, a := range list {
+			fmt.Fprintln(cmd.OutOrStdout(), hex.EncodeToString(a[:]))
+		}
+		return nil
+	}
 	b, err := hex.DecodeString(args[0])
 	if err != nil {
 		return err
 	}
 	var a core.Address
 	copy(a[:], b)
-	return cmManager.ResumeContract(a)
+	log, err := core.ContractPauseLog(led, a)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(cmd.OutOrStdout())
+	enc.SetIndent("", "  ")
+	return enc.Encode(map[string]interface{}{"paused": cmManager.IsPaused(a), "log": log})
 }
 
 func cmHandleUpgrade(cmd *cobra.Command, args []string) error {
@@ -88,7 +123,73 @@ func cmHandleUpgrade(cmd *cobra.Command, args []string) error {
 		return err
 	}
 	gas, _ := cmd.Flags().GetUint64("gas")
-	return cmManager.UpgradeContract(addr, code, gas)
+	from, err := cmCaller(cmd)
+	if err != nil {
+		return err
+	}
+	return cmManager.UpgradeContract(from, addr, code, gas)
+}
+
+func cmCaller(cmd *cobra.Command) (core.Address, error) {
+	var a core.Address
+	s, _ := cmd.Flags().GetString("from")
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return a, fmt.Errorf("invalid --from: %w", err)
+	}
+	copy(a[:], b)
+	return a, nil
+}
+
+func cmHandleAdmin(cmd *cobra.Command, args []string) error {
+	b, err := hex.DecodeString(args[0])
+	if err != nil {
+		return err
+	}
+	var addr core.Address
+	copy(addr[:], b)
+	if len(args) == 1 {
+		admin, err := cmManager.UpgradeAdminOf(addr)
+		if err != nil {
+			return err
+		}
+		fmt.Println(hex.EncodeToString(admin[:]))
+		return nil
+	}
+	nb, err := hex.DecodeString(args[1])
+	if err != nil {
+		return err
+	}
+	var admin core.Address
+	copy(admin[:], nb)
+	from, err := cmCaller(cmd)
+	if err != nil {
+		return err
+	}
+	return cmManager.SetUpgradeAdmin(from, addr, admin)
+}
+
+func cmHandleHistory(cmd *cobra.Command, args []string) error {
+	b, err := hex.DecodeString(args[0])
+	if err != nil {
+		return err
+	}
+	var a core.Address
+	copy(a[:], b)
+	hist, err := cmManager.UpgradeHistory(a)
+	if err != nil {
+		return err
+	}
+	if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		return enc.Encode(hist)
+	}
+	for _, u := range hist {
+		fmt.Fprintf(cmd.OutOrStdout(), "v%d\t%s\tby %s\theight %d\t%s\n",
+			u.Version, u.CodeHash, hex.EncodeToString(u.By[:]), u.Height, time.Unix(u.Timestamp, 0).UTC().Format(time.RFC3339))
+	}
+	return nil
 }
 
 func cmHandleInfo(cmd *cobra.Command, args []string) error {
@@ -117,10 +218,20 @@ var cmPauseCmd = &cobra.Command{Use: "pause <addr>", Args: cobra.ExactArgs(1), R
 var cmResumeCmd = &cobra.Command{Use: "resume <addr>", Args: cobra.ExactArgs(1), RunE: cmHandleResume}
 var cmUpgradeCmd = &cobra.Command{Use: "upgrade <addr> <wasm>", Args: cobra.ExactArgs(2), RunE: cmHandleUpgrade}
 var cmInfoCmd = &cobra.Command{Use: "info <addr>", Args: cobra.ExactArgs(1), RunE: cmHandleInfo}
+var cmAdminCmd = &cobra.Command{Use: "admin <addr> [newAdmin]", Args: cobra.RangeArgs(1, 2), RunE: cmHandleAdmin}
+var cmHistoryCmd = &cobra.Command{Use: "history <addr>", Args: cobra.ExactArgs(1), RunE: cmHandleHistory}
+var cmPausedCmd = &cobra.Command{Use: "paused [addr]", Args: cobra.MaximumNArgs(1), RunE: cmHandlePaused}
 
 func init() {
 	cmUpgradeCmd.Flags().Uint64("gas", 200000, "gas limit")
-	contractMgmtCmd.AddCommand(cmTransferCmd, cmPauseCmd, cmResumeCmd, cmUpgradeCmd, cmInfoCmd)
+	cmUpgradeCmd.Flags().String("from", "", "hex address of the upgrade admin")
+	cmAdminCmd.Flags().String("from", "", "hex address of the current upgrade admin")
+	cmHistoryCmd.Flags().Bool("json", false, "print the history as JSON")
+	for _, c := range []*cobra.Command{cmPauseCmd, cmResumeCmd} {
+		c.Flags().String("from", "", "hex address of the upgrade admin")
+		c.Flags().String("reason", "", "reason recorded in the pause log")
+	}
+	contractMgmtCmd.AddCommand(cmTransferCmd, cmPauseCmd, cmResumeCmd, cmUpgradeCmd, cmInfoCmd, cmAdminCmd, cmHistoryCmd, cmPausedCmd)
 }
 
 // ContractMgmtCmd exposes the root command.
@@ -7,7 +7,10 @@ package cli
 // Sub‑routes (micro‑CLIs):
 //   compile     – compile .wat/.wasm → deterministic wasm blob
 //   deploy      – deploy contract byte‑code + ricardian JSON to ledger
+//   address     – compute a salted (CREATE2-style) deployment address
 //   invoke      – call method with arbitrary args (hex) + gas limit
+//   debug       – invoke and print the full receipt, failed or not
+//   revert-reason – decode a revert payload into a readable message
 //   list        – list deployed contract addresses & code hash
 //   info        – show ricardian manifest for address
 //
@@ -26,6 +29,7 @@ package cli
 //   ~contracts ~deploy --wasm ./hello.wasm --ric ./manifest.json --gas 5_000_000
 //   ~contracts ~list
 //   ~contracts ~invoke 0xabc... --method greet --args 48656c6c6f --gas 200_000
+//   ~contracts ~revert-reason 0x08c379a0... --abi ./token.abi.json
 //
 // ──────────────────────────────────────────────────────────────────────────────
 
@@ -97,13 +101,7 @@ func initContractsMiddleware(cmd *cobra.Command, _ []string) error {
 // ──────────────────────────────────────────────────────────────────────────────
 
 func mustParseAddr(h string) (core.Address, error) {
-	var a core.Address
-	b, err := hex.DecodeString(strings.TrimPrefix(h, "0x"))
-	if err != nil || len(b) != len(a) {
-		return a, fmt.Errorf("invalid address %s", h)
-	}
-	copy(a[:], b)
-	return a, nil
+	return core.DecodeAddress(h)
 }
 
 // ──────────────────────────────────────────────────────────────────────────────
@@ -137,6 +135,8 @@ type deployFlags struct {
 	wasm string
 	ric  string
 	gas  uint64
+	from string
+	salt string
 }
 
 func handleDeploy(cmd *cobra.Command, _ []string) error {
@@ -154,10 +154,30 @@ func handleDeploy(cmd *cobra.Command, _ []string) error {
 		}
 	}
 
+	caller := core.AddressZero // system account 0x0… unless --from is given
+	if df.from != "" {
+		if caller, err = mustParseAddr(df.from); err != nil {
+			return err
+		}
+	}
+	cr := core.GetContractRegistry()
+
+	// salted deployment: address = hash(deployer, salt, code hash)
+	if df.salt != "" {
+		salt, err := parseSalt(df.salt)
+		if err != nil {
+			return err
+		}
+		addr, err := cr.DeploySalted(caller, salt, code, ricData, df.gas)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "deployed at 0x%x\n", addr[:])
+		return nil
+	}
+
 	// derive address & register
-	caller := core.AddressZero // system account 0x0…; could be flag in future
 	addr := core.DeriveContractAddress(caller, code)
-	cr := core.GetContractRegistry()
 	if err := cr.Deploy(addr, code, ricData, df.gas); err != nil {
 		return err
 	}
@@ -165,6 +185,45 @@ func handleDeploy(cmd *cobra.Command, _ []string) error {
 	return nil
 }
 
+// parseSalt reads up to 32 hex bytes, left-padded with zeros.
+func parseSalt(h string) (core.Hash, error) {
+	var salt core.Hash
+	b, err := hex.DecodeString(strings.TrimPrefix(h, "0x"))
+	if err != nil || len(b) > len(salt) {
+		return salt, fmt.Errorf("--salt must be up to 32 hex bytes")
+	}
+	copy(salt[len(salt)-len(b):], b)
+	return salt, nil
+}
+
+// handleSaltedAddress prints the address a salted deployment would use without
+// deploying, so it can be funded or referenced in advance.
+func handleSaltedAddress(cmd *cobra.Command, _ []string) error {
+	wasm, _ := cmd.Flags().GetString("wasm")
+	from, _ := cmd.Flags().GetString("from")
+	saltHex, _ := cmd.Flags().GetString("salt")
+	if wasm == "" || saltHex == "" {
+		return fmt.Errorf("--wasm and --salt required")
+	}
+	code, err := os.ReadFile(wasm)
+	if err != nil {
+		return err
+	}
+	deployer := core.AddressZero
+	if from != "" {
+		if deployer, err = mustParseAddr(from); err != nil {
+			return err
+		}
+	}
+	salt, err := parseSalt(saltHex)
+	if err != nil {
+		return err
+	}
+	addr := core.Create2Address(deployer, salt, code)
+	fmt.Fprintf(cmd.OutOrStdout(), "0x%x\n", addr[:])
+	return nil
+}
+
 type invokeFlags struct {
 	method string
 	args   string
@@ -216,11 +275,43 @@ func handleDebug(cmd *cobra.Command, args []string) error {
 
 	caller := core.AddressZero
 	rec, err := core.GetContractRegistry().InvokeWithReceipt(caller, addr, df.method, argBytes, df.gas)
-	if err != nil {
+	if rec == nil {
 		return err
 	}
 	b, _ := json.MarshalIndent(rec, "", "  ")
 	fmt.Fprintln(cmd.OutOrStdout(), string(b))
+	if rec.Revert != nil {
+		fmt.Fprintf(cmd.ErrOrStderr(), "reverted: %s\n", rec.Revert.Message)
+	}
+	return nil
+}
+
+func handleRevertReason(cmd *cobra.Command, args []string) error {
+	data, err := hex.DecodeString(strings.TrimPrefix(args[0], "0x"))
+	if err != nil {
+		return fmt.Errorf("payload must be hex bytes")
+	}
+	reg := core.ABIs()
+	files, _ := cmd.Flags().GetStringSlice("abi")
+	if len(files) > 0 {
+		reg = core.NewABIRegistry()
+		for _, f := range files {
+			raw, err := os.ReadFile(f)
+			if err != nil {
+				return err
+			}
+			if _, err := reg.RegisterJSON(raw); err != nil {
+				return fmt.Errorf("%s: %w", f, err)
+			}
+		}
+	}
+	rr := reg.Decode(data)
+	if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+		b, _ := json.MarshalIndent(rr, "", "  ")
+		fmt.Fprintln(cmd.OutOrStdout(), string(b))
+		return nil
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "%s: %s\n", rr.Kind, rr.Message)
 	return nil
 }
 
@@ -283,6 +374,8 @@ var deployCmd = &cobra.Command{
 		df := deployFlags{}
 		df.wasm, _ = cmd.Flags().GetString("wasm")
 		df.ric, _ = cmd.Flags().GetString("ric")
+		df.from, _ = cmd.Flags().GetString("from")
+		df.salt, _ = cmd.Flags().GetString("salt")
 		gasStr, _ := cmd.Flags().GetString("gas")
 		if df.wasm == "" {
 			return fmt.Errorf("--wasm required")
@@ -337,13 +430,25 @@ var debugCmd = &cobra.Command{
 	},
 }
 
+var revertReasonCmd = &cobra.Command{
+	Use:   "revert-reason <hex>",
+	Short: "Decode a revert payload into a readable message",
+	Args:  cobra.ExactArgs(1),
+	RunE:  handleRevertReason,
+	// Decoding needs no ledger.
+	PersistentPreRunE: func(*cobra.Command, []string) error { return nil },
+}
+
 var contractsListCmd = &cobra.Command{Use: "list", Short: "List deployed contracts", Args: cobra.NoArgs, RunE: handleList}
 var contractsInfoCmd = &cobra.Command{Use: "info <address>", Short: "Show ricardian manifest", Args: cobra.ExactArgs(1), RunE: handleInfo}
+var contractsAddressCmd = &cobra.Command{Use: "address", Short: "Compute a salted deployment address", Args: cobra.NoArgs, RunE: handleSaltedAddress}
 
 func init() {
 	deployCmd.Flags().String("wasm", "", "compiled wasm path")
 	deployCmd.Flags().String("ric", "", "ricardian manifest JSON (optional)")
 	deployCmd.Flags().String("gas", "", "gas limit (default 3M)")
+	deployCmd.Flags().String("from", "", "deployer address (default 0x0…)")
+	deployCmd.Flags().String("salt", "", "hex salt for a deterministic CREATE2-style address")
 
 	invokeCmd.Flags().String("method", "", "method name")
 	invokeCmd.Flags().String("args", "", "hex‑encoded arg bytes")
@@ -353,7 +458,14 @@ func init() {
 	debugCmd.Flags().String("args", "", "hex‑encoded arg bytes")
 	debugCmd.Flags().Uint64("gas", 200_000, "gas limit")
 
-	contractsCmd.AddCommand(compileCmd, deployCmd, invokeCmd, debugCmd, contractsListCmd, contractsInfoCmd)
+	revertReasonCmd.Flags().StringSlice("abi", nil, "JSON ABI files declaring custom errors")
+	revertReasonCmd.Flags().Bool("json", false, "print the decoded reason as JSON")
+
+	contractsAddressCmd.Flags().String("wasm", "", "compiled wasm path")
+	contractsAddressCmd.Flags().String("from", "", "deployer address (default 0x0…)")
+	contractsAddressCmd.Flags().String("salt", "", "hex salt")
+
+	contractsCmd.AddCommand(compileCmd, deployCmd, invokeCmd, debugCmd, revertReasonCmd, contractsListCmd, contractsInfoCmd, contractsAddressCmd)
 }
 
 // ──────────────────────────────────────────────────────────────────────────────
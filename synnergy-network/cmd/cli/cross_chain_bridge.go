@@ -1,12 +1,10 @@
 package cli
 
 import (
-	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"strconv"
-	"strings"
 
 	"github.com/spf13/cobra"
 	core "synnergy-network/core"
@@ -17,13 +15,7 @@ import (
 // ---------------------------------------------------------------------
 
 func xbridgeParseAddr(hexStr string) (core.Address, error) {
-	var a core.Address
-	b, err := hex.DecodeString(strings.TrimPrefix(hexStr, "0x"))
-	if err != nil || len(b) != len(a) {
-		return a, fmt.Errorf("invalid address")
-	}
-	copy(a[:], b)
-	return a, nil
+	return core.DecodeAddress(hexStr)
 }
 
 // ---------------------------------------------------------------------
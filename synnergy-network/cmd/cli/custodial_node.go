@@ -1,7 +1,6 @@
 package cli
 
 import (
-	"encoding/hex"
 	"fmt"
 	"github.com/spf13/cobra"
 	core "synnergy-network/core"
@@ -94,9 +93,7 @@ func custodialBalance(cmd *cobra.Command, args []string) error {
 }
 
 func hexToAddr(s string) core.Address {
-	var a core.Address
-	b, _ := hex.DecodeString(s)
-	copy(a[:], b)
+	a, _ := core.DecodeAddress(s)
 	return a
 }
 
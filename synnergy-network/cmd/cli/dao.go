@@ -1,25 +1,16 @@
 package cli
 
 import (
-	"encoding/hex"
 	"encoding/json"
-	"fmt"
-	"strings"
 
 	"github.com/spf13/cobra"
 
 	core "synnergy-network/core"
 )
 
-// helper to decode hex address
+// helper to decode a bech32 or hex address
 func daoParseAddr(h string) (core.Address, error) {
-	var a core.Address
-	b, err := hex.DecodeString(strings.TrimPrefix(h, "0x"))
-	if err != nil || len(b) != len(a) {
-		return a, fmt.Errorf("invalid address")
-	}
-	copy(a[:], b)
-	return a, nil
+	return core.DecodeAddress(h)
 }
 
 var daoCmd = &cobra.Command{
@@ -1,7 +1,6 @@
 package cli
 
 import (
-	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"time"
@@ -12,13 +11,7 @@ import (
 )
 
 func dpParseAddr(h string) (core.Address, error) {
-	var a core.Address
-	b, err := hex.DecodeString(h)
-	if err != nil || len(b) != len(a) {
-		return a, fmt.Errorf("invalid address")
-	}
-	copy(a[:], b)
-	return a, nil
+	return core.DecodeAddress(h)
 }
 
 var daoProposalCmd = &cobra.Command{
@@ -2,6 +2,7 @@ package cli
 
 import (
 	"fmt"
+	"log"
 	"os"
 	"strconv"
 	"sync"
@@ -34,7 +35,7 @@ func stakeInit(cmd *cobra.Command, _ []string) error {
 		if err != nil {
 			return
 		}
-		core.InitDAOStaking(logrus.StandardLogger(), stakeLedger)
+		core.InitDAOStaking(log.New(logrus.StandardLogger().Out, "", log.LstdFlags), stakeLedger)
 		stakeMgr = core.StakingManager()
 	})
 	return err
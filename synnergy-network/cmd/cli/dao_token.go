@@ -1,10 +1,8 @@
 package cli
 
 import (
-	"encoding/hex"
 	"fmt"
 	"strconv"
-	"strings"
 	"sync"
 
 	"github.com/spf13/cobra"
@@ -35,13 +33,7 @@ func daoTokenInit(cmd *cobra.Command, _ []string) error {
 }
 
 func parseAddr(h string) (core.Address, error) {
-	var a core.Address
-	b, err := hex.DecodeString(strings.TrimPrefix(h, "0x"))
-	if err != nil || len(b) != 20 {
-		return a, fmt.Errorf("invalid address")
-	}
-	copy(a[:], b)
-	return a, nil
+	return core.DecodeAddress(h)
 }
 
 var daoTokenCmd = &cobra.Command{
@@ -0,0 +1,266 @@
+package cli
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	core "synnergy-network/core"
+)
+
+// DataMarketController wraps the priced dataset access helpers.
+type DataMarketController struct{}
+
+func (DataMarketController) List(l core.DataListing) (*core.DataListing, error) {
+	return core.ListDataSet(l)
+}
+
+func (DataMarketController) Buy(p core.DataPurchase, sig []byte) (*core.DataAccessToken, error) {
+	return core.PurchaseDataAccess(p, sig)
+}
+
+// dmOrder builds the consumer's next purchase order for a listing.
+func dmOrder(cmd *cobra.Command, listingID, consumerStr string) (core.DataPurchase, error) {
+	p := core.DataPurchase{ListingID: listingID}
+	consumer, err := core.ParseAddress(consumerStr)
+	if err != nil {
+		return p, err
+	}
+	l, err := core.GetDataListing(listingID)
+	if err != nil {
+		return p, err
+	}
+	p.Consumer, p.Price, p.Nonce = consumer, l.Price, core.DataPurchaseNonce(consumer)
+	if s, _ := cmd.Flags().GetString("channel"); s != "" {
+		if p.Channel, err = parseChannelID(s); err != nil {
+			return p, err
+		}
+	}
+	return p, nil
+}
+
+func (DataMarketController) Query(tok string) (*core.DataAccessToken, core.DataSet, error) {
+	return core.MeterDataAccess(tok)
+}
+
+func (DataMarketController) Settle(tok string, st core.SignedState) (*core.DataAccessToken, error) {
+	return core.SettleDataUsage(tok, st)
+}
+
+func parseChannelID(s string) (core.ChannelID, error) {
+	var id core.ChannelID
+	b, err := hex.DecodeString(s)
+	if err != nil || len(b) != len(id) {
+		return id, fmt.Errorf("invalid channel id")
+	}
+	copy(id[:], b)
+	return id, nil
+}
+
+func dmPrint(cmd *cobra.Command, v interface{}) {
+	enc, _ := json.MarshalIndent(v, "", "  ")
+	fmt.Fprintln(cmd.OutOrStdout(), string(enc))
+}
+
+var dataMarketCmd = &cobra.Command{
+	Use:   "datamarket",
+	Short: "Sell metered dataset access",
+}
+
+var dmListCmd = &cobra.Command{
+	Use:   "list <datasetID> <provider> <oneoff|subscription|perquery> <price>",
+	Short: "Publish a pricing model for a dataset",
+	Args:  cobra.ExactArgs(4),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		prov, err := core.ParseAddress(args[1])
+		if err != nil {
+			return err
+		}
+		model, err := core.ParsePricingModel(args[2])
+		if err != nil {
+			return err
+		}
+		price, err := strconv.ParseUint(args[3], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid price: %w", err)
+		}
+		period, _ := cmd.Flags().GetDuration("period")
+		fee, _ := cmd.Flags().GetUint16("fee-bp")
+		l, err := DataMarketController{}.List(core.DataListing{
+			DataSetID: args[0], Provider: prov, Model: model, Price: price, Period: period, FeeBp: fee,
+		})
+		if err != nil {
+			return err
+		}
+		dmPrint(cmd, l)
+		return nil
+	},
+}
+
+var dmListingsCmd = &cobra.Command{
+	Use:   "listings [datasetID]",
+	Short: "Show active and inactive listings",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ds := ""
+		if len(args) == 1 {
+			ds = args[0]
+		}
+		list, err := core.ListDataListings(ds)
+		if err != nil {
+			return err
+		}
+		dmPrint(cmd, list)
+		return nil
+	},
+}
+
+var dmDelistCmd = &cobra.Command{
+	Use:   "delist <listingID> <provider>",
+	Short: "Deactivate a listing",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		prov, err := core.ParseAddress(args[1])
+		if err != nil {
+			return err
+		}
+		return core.DelistDataSet(args[0], prov)
+	},
+}
+
+var dmOrderCmd = &cobra.Command{
+	Use:   "order <listingID> <consumer>",
+	Short: "Print the typed data the consumer signs to buy a listing",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		p, err := dmOrder(cmd, args[0], args[1])
+		if err != nil {
+			return err
+		}
+		dmPrint(cmd, core.DataPurchaseTypedData(p))
+		return nil
+	},
+}
+
+var dmBuyCmd = &cobra.Command{
+	Use:   "buy <listingID> <consumer>",
+	Short: "Purchase an access token with a signed order",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		p, err := dmOrder(cmd, args[0], args[1])
+		if err != nil {
+			return err
+		}
+		var sig []byte
+		if key, _ := cmd.Flags().GetString("key"); key != "" {
+			priv, err := hex.DecodeString(strings.TrimPrefix(key, "0x"))
+			if err != nil {
+				return fmt.Errorf("bad key: %w", err)
+			}
+			defer core.Wipe(priv)
+			s, err := core.SignTypedData(priv, core.DataPurchaseTypedData(p))
+			if err != nil {
+				return err
+			}
+			sig, _ = hex.DecodeString(s.Sig)
+		} else if s, _ := cmd.Flags().GetString("sig"); s != "" {
+			if sig, err = hex.DecodeString(strings.TrimPrefix(s, "0x")); err != nil {
+				return fmt.Errorf("bad sig: %w", err)
+			}
+		} else {
+			return errors.New("--key or --sig required")
+		}
+		at, err := DataMarketController{}.Buy(p, sig)
+		if err != nil {
+			return err
+		}
+		dmPrint(cmd, at)
+		return nil
+	},
+}
+
+var dmQueryCmd = &cobra.Command{
+	Use:   "query <token>",
+	Short: "Meter one access and print the dataset CID",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		at, ds, err := DataMarketController{}.Query(args[0])
+		if err != nil {
+			return err
+		}
+		dmPrint(cmd, map[string]interface{}{"cid": ds.CID, "queries": at.Queries, "outstanding": at.Outstanding()})
+		return nil
+	},
+}
+
+var dmSettleCmd = &cobra.Command{
+	Use:   "settle <token> <state.json>",
+	Short: "Settle metered usage with a signed channel state",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		raw, err := os.ReadFile(args[1])
+		if err != nil {
+			return err
+		}
+		var st core.SignedState
+		if err := json.Unmarshal(raw, &st); err != nil {
+			return err
+		}
+		at, err := DataMarketController{}.Settle(args[0], st)
+		if err != nil {
+			return err
+		}
+		dmPrint(cmd, at)
+		return nil
+	},
+}
+
+var dmRevokeCmd = &cobra.Command{
+	Use:   "revoke <token> <provider>",
+	Short: "Revoke an access token",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		prov, err := core.ParseAddress(args[1])
+		if err != nil {
+			return err
+		}
+		return core.RevokeDataAccess(args[0], prov)
+	},
+}
+
+var dmTokensCmd = &cobra.Command{
+	Use:   "tokens <consumer>",
+	Short: "List access tokens held by a consumer",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		consumer, err := core.ParseAddress(args[0])
+		if err != nil {
+			return err
+		}
+		list, err := core.ListDataAccessTokens(consumer)
+		if err != nil {
+			return err
+		}
+		dmPrint(cmd, list)
+		return nil
+	},
+}
+
+func init() {
+	dmListCmd.Flags().Duration("period", 30*24*time.Hour, "subscription period")
+	dmListCmd.Flags().Uint16("fee-bp", 250, "marketplace fee in basis points")
+	dmBuyCmd.Flags().String("channel", "", "hex channel ID funding per-query access")
+	dmBuyCmd.Flags().String("key", "", "consumer Ed25519 private key (hex) to sign the order")
+	dmBuyCmd.Flags().String("sig", "", "order signature from `datamarket order` (hex)")
+	dmOrderCmd.Flags().String("channel", "", "hex channel ID funding per-query access")
+	dataMarketCmd.AddCommand(dmListCmd, dmListingsCmd, dmDelistCmd, dmOrderCmd, dmBuyCmd, dmQueryCmd, dmSettleCmd, dmRevokeCmd, dmTokensCmd)
+}
+
+// DataMarketCmd is exported for index.go
+var DataMarketCmd = dataMarketCmd
@@ -1,10 +1,10 @@
 package cli
 
 import (
-	"encoding/hex"
+	"errors"
 	"fmt"
 	"io/ioutil"
-	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	core "synnergy-network/core"
@@ -20,13 +20,7 @@ func newDRController() *DataResourceController {
 }
 
 func drmParseAddr(a string) (core.Address, error) {
-	b, err := hex.DecodeString(strings.TrimPrefix(a, "0x"))
-	if err != nil || len(b) != 20 {
-		return core.AddressZero, fmt.Errorf("invalid address")
-	}
-	var out core.Address
-	copy(out[:], b)
-	return out, nil
+	return core.DecodeAddress(a)
 }
 
 func (c *DataResourceController) store(owner, key, file string, gas uint64) error {
@@ -105,8 +99,123 @@ var resDelCmd = &cobra.Command{
 	},
 }
 
+func resLedger() (*core.Ledger, error) {
+	led := core.CurrentLedger()
+	if led == nil {
+		return nil, errors.New("ledger not initialised")
+	}
+	return led, nil
+}
+
+var resPolicyCmd = &cobra.Command{
+	Use:   "deposit-policy",
+	Short: "Show the storage deposit policy",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		led, err := resLedger()
+		if err != nil {
+			return err
+		}
+		resPrint(cmd, led.StoragePolicy())
+		return nil
+	},
+}
+
+var resDepositCmd = &cobra.Command{
+	Use:   "deposit <owner>",
+	Short: "Show the storage account and deposit of an owner",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		led, err := resLedger()
+		if err != nil {
+			return err
+		}
+		addr, err := drmParseAddr(args[0])
+		if err != nil {
+			return err
+		}
+		acct, err := led.StorageAccount(addr)
+		if err != nil {
+			return err
+		}
+		resPrint(cmd, struct {
+			core.StorageAccount
+			Required uint64 `json:"required"`
+		}{acct, acct.Required(led.StoragePolicy())})
+		return nil
+	},
+}
+
+var resTopUpCmd = &cobra.Command{
+	Use:   "topup <owner>",
+	Short: "Lock the deposit an owner falls short of",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		led, err := resLedger()
+		if err != nil {
+			return err
+		}
+		addr, err := drmParseAddr(args[0])
+		if err != nil {
+			return err
+		}
+		acct, err := led.TopUpStorage(addr)
+		if err != nil {
+			return err
+		}
+		resPrint(cmd, acct)
+		return nil
+	},
+}
+
+var resSweepCmd = &cobra.Command{
+	Use:   "sweep",
+	Short: "Mark underfunded storage accounts and list the reclaimable ones",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		led, err := resLedger()
+		if err != nil {
+			return err
+		}
+		addrs, err := led.SweepStorage(time.Now())
+		if err != nil {
+			return err
+		}
+		for _, a := range addrs {
+			fmt.Println(a.Hex())
+		}
+		return nil
+	},
+}
+
+var resPurgeCmd = &cobra.Command{
+	Use:   "purge <owner> <reaper>",
+	Short: "Reclaim the storage of an underfunded owner",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		led, err := resLedger()
+		if err != nil {
+			return err
+		}
+		owner, err := drmParseAddr(args[0])
+		if err != nil {
+			return err
+		}
+		reaper, err := drmParseAddr(args[1])
+		if err != nil {
+			return err
+		}
+		res, err := led.PurgeStorage(owner, reaper, time.Now())
+		if err != nil {
+			return err
+		}
+		resPrint(cmd, res)
+		return nil
+	},
+}
+
 func init() {
-	resourceCmd.AddCommand(resStoreCmd, resLoadCmd, resDelCmd)
+	resourceCmd.AddCommand(resStoreCmd, resLoadCmd, resDelCmd, resPolicyCmd, resDepositCmd, resTopUpCmd, resSweepCmd, resPurgeCmd)
 }
 
 // ResourceCmd is exported for registration in the root CLI.
@@ -0,0 +1,166 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	core "synnergy-network/core"
+)
+
+// adminRPCDo calls method on the admin JSON-RPC API of a running node and
+// decodes the result into out. The token is read from ADMIN_RPC_TOKEN.
+func adminRPCDo(cmd *cobra.Command, method string, params []interface{}, out interface{}) error {
+	endpoint, _ := cmd.Flags().GetString("rpc")
+	if params == nil {
+		params = []interface{}{}
+	}
+	rawParams, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(core.RPCRequest{JSONRPC: "2.0", ID: json.RawMessage("1"), Method: method, Params: rawParams})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(cmd.Context(), http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+os.Getenv("ADMIN_RPC_TOKEN"))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 300 {
+		return core.DecodeErrorBody(resp.StatusCode, data)
+	}
+	var res struct {
+		Result json.RawMessage `json:"result"`
+		Error  *core.RPCError  `json:"error"`
+	}
+	if err := json.Unmarshal(data, &res); err != nil {
+		return err
+	}
+	if res.Error != nil {
+		if res.Error.Data != nil {
+			return res.Error.Data
+		}
+		return res.Error
+	}
+	return json.Unmarshal(res.Result, out)
+}
+
+func debugTrace(cmd *cobra.Command, args []string) error {
+	tracer, _ := cmd.Flags().GetString("tracer")
+	raw := json.RawMessage{}
+	if err := adminRPCDo(cmd, "debug_traceTransaction", []interface{}{args[0], core.TraceOptions{Tracer: tracer}}, &raw); err != nil {
+		return err
+	}
+	if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, raw, "", "  "); err != nil {
+			return err
+		}
+		buf.WriteByte('\n')
+		_, err := buf.WriteTo(cmd.OutOrStdout())
+		return err
+	}
+	var t struct {
+		core.TxTrace
+		VM json.RawMessage `json:"vm"`
+	}
+	if err := json.Unmarshal(raw, &t); err != nil {
+		return err
+	}
+	w := cmd.OutOrStdout()
+	fmt.Fprintf(w, "tx %s  block %d  index %d  type %d\n", t.Hash, t.Height, t.Index, t.Type)
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "EFFECT\tKEY\tBEFORE\tAFTER\tAMOUNT")
+	for _, st := range t.Steps {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\n", st.Op, st.Key, st.Before, st.After, st.Amount)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	switch {
+	case !t.Archive:
+		fmt.Fprintln(w, "no archive state before the block; VM steps unavailable")
+	case len(t.VM) == 0:
+	case t.Tracer == core.TracerCall:
+		var f core.CallFrame
+		if err := json.Unmarshal(t.VM, &f); err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "\n%s %s -> %s  gas %d/%d  output %s\n", f.Type, f.From, f.To, f.GasUsed, f.Gas, f.Output)
+		if f.Revert != nil {
+			fmt.Fprintln(w, "reverted:", f.Revert.Message)
+		} else if f.Error != "" {
+			fmt.Fprintln(w, "error:", f.Error)
+		}
+		keys := make([]string, 0, len(f.Storage))
+		for k := range f.Storage {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Fprintf(w, "  storage %s = %s\n", k, f.Storage[k])
+		}
+	default:
+		var r core.StructLogResult
+		if err := json.Unmarshal(t.VM, &r); err != nil {
+			return err
+		}
+		fmt.Fprintln(w)
+		tw = tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "PC\tOP\tGAS\tCOST\tSTACK\tWRITES\tERROR")
+		for _, st := range r.StructLogs {
+			var writes []string
+			for k, v := range st.Storage {
+				writes = append(writes, k+"="+v)
+			}
+			for _, m := range st.Memory {
+				writes = append(writes, fmt.Sprintf("mem[%d]=%s", m.Offset, m.Data))
+			}
+			fmt.Fprintf(tw, "%d\t%s\t%d\t%d\t%s\t%s\t%s\n", st.PC, st.Op, st.Gas, st.GasCost,
+				strings.Join(st.Stack, " "), strings.Join(writes, " "), st.Error)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+		status := "ok"
+		if r.Revert != nil {
+			status = "reverted: " + r.Revert.Message
+		} else if r.Failed {
+			status = "failed: " + r.Error
+		}
+		fmt.Fprintf(w, "gas used %d, return %s, %s\n", r.Gas, r.ReturnValue, status)
+	}
+	return nil
+}
+
+var debugRootCmd = &cobra.Command{Use: "debug", Short: "Debug transactions through the admin RPC of a running node"}
+var debugTraceCmd = &cobra.Command{Use: "trace <txhash>", Short: "Re-execute a transaction and show every VM step", Args: cobra.ExactArgs(1), RunE: debugTrace}
+
+func init() {
+	debugRootCmd.PersistentFlags().String("rpc", "http://127.0.0.1:8545", "admin RPC endpoint (token from ADMIN_RPC_TOKEN)")
+	debugTraceCmd.Flags().String("tracer", core.TracerStructLogger, "tracer: structLogger or callTracer")
+	debugTraceCmd.Flags().Bool("json", false, "print the raw trace as JSON")
+	debugRootCmd.AddCommand(debugTraceCmd)
+}
+
+var DebugCmd = debugRootCmd
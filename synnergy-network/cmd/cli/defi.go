@@ -7,8 +7,14 @@ package cli
 
 import (
 	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/spf13/cobra"
 	core "synnergy-network/core"
 )
@@ -31,44 +37,686 @@ func ensureDeFi(cmd *cobra.Command, _ []string) error {
 
 // Controllers
 
+func defiInsPoolCreate(cmd *cobra.Command, args []string) error {
+	var rates core.InsuranceRateModel
+	rates.BaseRateBps, _ = cmd.Flags().GetUint64("base")
+	rates.SlopeBps, _ = cmd.Flags().GetUint64("slope")
+	rates.KinkBps, _ = cmd.Flags().GetUint64("kink")
+	rates.JumpBps, _ = cmd.Flags().GetUint64("jump")
+	id, err := defiMgr.CreateInsurancePool(args[0], rates)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "insurance pool %d created\n", id)
+	return nil
+}
+
+func defiInsPools(cmd *cobra.Command, _ []string) error {
+	pools, err := defiMgr.InsurancePools()
+	if err != nil {
+		return err
+	}
+	return defiPrintJSON(cmd, pools)
+}
+
+// defiIDAmountArgs parses the "<id> <addr> <amount>" arguments shared by the
+// insurance pool and crowdfund commands.
+func defiIDAmountArgs(args []string) (uint64, core.Address, uint64, error) {
+	pool, err := parseUintArg(args[0])
+	if err != nil {
+		return 0, core.Address{}, 0, err
+	}
+	addr, err := defiAddr(args[1])
+	if err != nil {
+		return 0, core.Address{}, 0, err
+	}
+	amt, err := parseUintArg(args[2])
+	return pool, addr, amt, err
+}
+
+func defiInsUnderwrite(cmd *cobra.Command, args []string) error {
+	pool, from, amt, err := defiIDAmountArgs(args)
+	if err != nil {
+		return err
+	}
+	shares, err := defiMgr.Underwrite(pool, from, amt)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "issued %d shares\n", shares)
+	return nil
+}
+
+func defiInsWithdraw(cmd *cobra.Command, args []string) error {
+	pool, to, shares, err := defiIDAmountArgs(args)
+	if err != nil {
+		return err
+	}
+	out, err := defiMgr.WithdrawCapital(pool, to, shares)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "withdrew %d\n", out)
+	return nil
+}
+
+func defiInsAssessorStake(cmd *cobra.Command, args []string) error {
+	pool, from, amt, err := defiIDAmountArgs(args)
+	if err != nil {
+		return err
+	}
+	return defiMgr.StakeAssessor(pool, from, amt)
+}
+
+func defiInsAssessorUnstake(cmd *cobra.Command, args []string) error {
+	pool, to, amt, err := defiIDAmountArgs(args)
+	if err != nil {
+		return err
+	}
+	return defiMgr.UnstakeAssessor(pool, to, amt)
+}
+
+func defiInsQuote(cmd *cobra.Command, args []string) error {
+	pool, err := parseUintArg(args[0])
+	if err != nil {
+		return err
+	}
+	cover, err := parseUintArg(args[1])
+	if err != nil {
+		return err
+	}
+	dur, err := time.ParseDuration(args[2])
+	if err != nil {
+		return err
+	}
+	prem, err := defiMgr.QuotePremium(pool, cover, dur)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "premium %d\n", prem)
+	return nil
+}
+
 func defiCreateInsurance(cmd *cobra.Command, args []string) error {
-	if len(args) < 4 {
-		return fmt.Errorf("usage: insurance <hexID> <holder> <premium> <payout>")
+	id, err := defiHash(args[0])
+	if err != nil {
+		return err
 	}
-	idBytes, err := hex.DecodeString(args[0])
-	if err != nil || len(idBytes) != 32 {
-		return fmt.Errorf("bad id")
+	pool, holder, cover, err := defiIDAmountArgs(args[1:])
+	if err != nil {
+		return err
 	}
-	var id core.Hash
-	copy(id[:], idBytes)
-	holder := core.AddressZero
-	b, err := hex.DecodeString(args[1])
-	if err != nil || len(b) != len(holder) {
-		return fmt.Errorf("bad address")
+	dur, err := time.ParseDuration(args[4])
+	if err != nil {
+		return err
 	}
-	copy(holder[:], b)
-	premium, err := parseUintArg(args[2])
+	maxPrem, err := parseUintArg(args[5])
 	if err != nil {
 		return err
 	}
-	payout, err := parseUintArg(args[3])
+	prem, err := defiMgr.CreateInsurance(id, pool, holder, cover, dur, maxPrem)
 	if err != nil {
 		return err
 	}
-	return defiMgr.CreateInsurance(id, holder, premium, payout)
+	fmt.Fprintf(cmd.OutOrStdout(), "policy bought for premium %d\n", prem)
+	return nil
 }
 
 func defiClaim(cmd *cobra.Command, args []string) error {
-	if len(args) < 1 {
-		return fmt.Errorf("usage: claim <hexID>")
+	id, err := defiHash(args[0])
+	if err != nil {
+		return err
+	}
+	holder, err := defiAddr(args[1])
+	if err != nil {
+		return err
+	}
+	amt, err := parseUintArg(args[2])
+	if err != nil {
+		return err
+	}
+	evidence, _ := cmd.Flags().GetString("evidence")
+	return defiMgr.ClaimInsurance(id, holder, amt, evidence)
+}
+
+func defiInsVote(cmd *cobra.Command, args []string) error {
+	id, err := defiHash(args[0])
+	if err != nil {
+		return err
+	}
+	voter, err := defiAddr(args[1])
+	if err != nil {
+		return err
+	}
+	approve, err := strconv.ParseBool(args[2])
+	if err != nil {
+		return err
+	}
+	if cmd.Name() == "rule" {
+		return defiMgr.RuleOnAppeal(id, voter, approve)
+	}
+	return defiMgr.AssessClaim(id, voter, approve)
+}
+
+func defiInsDecide(cmd *cobra.Command, args []string) error {
+	id, err := defiHash(args[0])
+	if err != nil {
+		return err
+	}
+	approved, err := defiMgr.DecideClaim(id)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "approved: %v\n", approved)
+	return nil
+}
+
+func defiInsAppeal(cmd *cobra.Command, args []string) error {
+	id, err := defiHash(args[0])
+	if err != nil {
+		return err
+	}
+	appellant, err := defiAddr(args[1])
+	if err != nil {
+		return err
+	}
+	panel, err := defiMgr.AppealClaim(id, appellant)
+	if err != nil {
+		return err
+	}
+	return defiPrintJSON(cmd, panel)
+}
+
+func defiInsSettle(cmd *cobra.Command, args []string) error {
+	id, err := defiHash(args[0])
+	if err != nil {
+		return err
+	}
+	return defiMgr.SettleClaim(id)
+}
+
+func defiInsShow(cmd *cobra.Command, args []string) error {
+	id, err := defiHash(args[0])
+	if err != nil {
+		return err
+	}
+	pol, err := defiMgr.InsurancePolicyOf(id)
+	if err != nil {
+		return err
 	}
-	idBytes, err := hex.DecodeString(args[0])
-	if err != nil || len(idBytes) != 32 {
-		return fmt.Errorf("bad id")
+	out := map[string]any{"policy": pol}
+	if c, err := defiMgr.InsuranceClaimOf(id); err == nil {
+		out["claim"] = c
+	}
+	return defiPrintJSON(cmd, out)
+}
+
+func defiAddr(s string) (core.Address, error) {
+	return core.DecodeAddress(s)
+}
+
+func defiPrintJSON(cmd *cobra.Command, v any) error {
+	enc, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), string(enc))
+	return nil
+}
+
+func defiCrowdfundStart(cmd *cobra.Command, args []string) error {
+	creator, err := defiAddr(args[0])
+	if err != nil {
+		return err
+	}
+	beneficiary, err := defiAddr(args[1])
+	if err != nil {
+		return err
+	}
+	goal, err := parseUintArg(args[3])
+	if err != nil {
+		return err
+	}
+	dur, err := time.ParseDuration(args[4])
+	if err != nil {
+		return err
+	}
+	var milestones []core.CrowdfundMilestone
+	shares, _ := cmd.Flags().GetString("milestones")
+	labels, _ := cmd.Flags().GetStringSlice("labels")
+	for i, f := range strings.Split(shares, ",") {
+		if f == "" {
+			continue
+		}
+		bps, err := parseUintArg(f)
+		if err != nil {
+			return err
+		}
+		m := core.CrowdfundMilestone{ShareBps: bps}
+		if i < len(labels) {
+			m.Description = labels[i]
+		}
+		milestones = append(milestones, m)
+	}
+	id, err := defiMgr.StartCrowdfund(creator, beneficiary, args[2], goal, time.Now().Add(dur), milestones)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "crowdfund %d started\n", id)
+	return nil
+}
+
+func defiCrowdfundContribute(cmd *cobra.Command, args []string) error {
+	id, from, amt, err := defiIDAmountArgs(args)
+	if err != nil {
+		return err
+	}
+	return defiMgr.Contribute(id, from, amt)
+}
+
+func defiCrowdfundFinalize(cmd *cobra.Command, args []string) error {
+	id, err := parseUintArg(args[0])
+	if err != nil {
+		return err
+	}
+	st, err := defiMgr.FinalizeCrowdfund(id)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "crowdfund %d %s\n", id, st)
+	return nil
+}
+
+func defiCrowdfundRequest(cmd *cobra.Command, args []string) error {
+	id, err := parseUintArg(args[0])
+	if err != nil {
+		return err
+	}
+	creator, err := defiAddr(args[1])
+	if err != nil {
+		return err
+	}
+	idx, err := defiMgr.RequestMilestone(id, creator)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "vote opened on milestone %d\n", idx)
+	return nil
+}
+
+func defiCrowdfundVote(cmd *cobra.Command, args []string) error {
+	id, err := parseUintArg(args[0])
+	if err != nil {
+		return err
+	}
+	backer, err := defiAddr(args[1])
+	if err != nil {
+		return err
+	}
+	approve, err := strconv.ParseBool(args[2])
+	if err != nil {
+		return err
+	}
+	return defiMgr.VoteMilestone(id, backer, approve)
+}
+
+func defiCrowdfundClose(cmd *cobra.Command, args []string) error {
+	id, err := parseUintArg(args[0])
+	if err != nil {
+		return err
+	}
+	approved, err := defiMgr.CloseMilestone(id)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "approved: %v\n", approved)
+	return nil
+}
+
+func defiCrowdfundShow(cmd *cobra.Command, args []string) error {
+	id, err := parseUintArg(args[0])
+	if err != nil {
+		return err
+	}
+	cf, contribs, err := defiMgr.CrowdfundOf(id)
+	if err != nil {
+		return err
 	}
-	var id core.Hash
-	copy(id[:], idBytes)
-	return defiMgr.ClaimInsurance(id)
+	return defiPrintJSON(cmd, map[string]any{"crowdfund": cf, "contributions": contribs})
+}
+
+func defiCrowdfundList(cmd *cobra.Command, _ []string) error {
+	list, err := defiMgr.Crowdfunds()
+	if err != nil {
+		return err
+	}
+	return defiPrintJSON(cmd, list)
+}
+
+func defiSynthCreate(cmd *cobra.Command, args []string) error {
+	return defiMgr.CreateSynthetic(args[0], args[1])
+}
+
+func defiSynthList(cmd *cobra.Command, _ []string) error {
+	synths, err := defiMgr.Synthetics()
+	if err != nil {
+		return err
+	}
+	return defiPrintJSON(cmd, synths)
+}
+
+// defiSynthAmount handles the "<minter> <amount>" collateral commands.
+func defiSynthAmount(fn func(core.Address, uint64) error) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		minter, err := defiAddr(args[0])
+		if err != nil {
+			return err
+		}
+		amt, err := parseUintArg(args[1])
+		if err != nil {
+			return err
+		}
+		return fn(minter, amt)
+	}
+}
+
+func defiSynthMint(cmd *cobra.Command, args []string) error {
+	minter, err := defiAddr(args[0])
+	if err != nil {
+		return err
+	}
+	amt, err := parseUintArg(args[2])
+	if err != nil {
+		return err
+	}
+	fee, err := defiMgr.MintSynthetic(minter, args[1], amt)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "minted %d s%s, fee %d\n", amt, strings.ToUpper(args[1]), fee)
+	return nil
+}
+
+func defiSynthBurn(cmd *cobra.Command, args []string) error {
+	minter, err := defiAddr(args[0])
+	if err != nil {
+		return err
+	}
+	amt, err := parseUintArg(args[2])
+	if err != nil {
+		return err
+	}
+	return defiMgr.BurnSynthetic(minter, args[1], amt)
+}
+
+func defiSynthClaim(cmd *cobra.Command, args []string) error {
+	minter, err := defiAddr(args[0])
+	if err != nil {
+		return err
+	}
+	amt, err := defiMgr.ClaimSyntheticFees(minter)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "claimed %d\n", amt)
+	return nil
+}
+
+func defiSynthLiquidate(cmd *cobra.Command, args []string) error {
+	liquidator, err := defiAddr(args[0])
+	if err != nil {
+		return err
+	}
+	minter, err := defiAddr(args[1])
+	if err != nil {
+		return err
+	}
+	amt, err := parseUintArg(args[3])
+	if err != nil {
+		return err
+	}
+	seized, err := defiMgr.LiquidateSynthetic(liquidator, minter, args[2], amt)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "seized %d collateral\n", seized)
+	return nil
+}
+
+func defiSynthPosition(cmd *cobra.Command, args []string) error {
+	minter, err := defiAddr(args[0])
+	if err != nil {
+		return err
+	}
+	pos, err := defiMgr.SynthPositionOf(minter)
+	if err != nil {
+		return err
+	}
+	return defiPrintJSON(cmd, pos)
+}
+
+func defiHash(s string) (core.Hash, error) {
+	var h core.Hash
+	b, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil || len(b) != len(h) {
+		return h, fmt.Errorf("bad id")
+	}
+	copy(h[:], b)
+	return h, nil
+}
+
+func defiPredictCreate(cmd *cobra.Command, args []string) error {
+	id, err := defiHash(args[0])
+	if err != nil {
+		return err
+	}
+	creator, err := defiAddr(args[1])
+	if err != nil {
+		return err
+	}
+	liquidity, err := parseUintArg(args[4])
+	if err != nil {
+		return err
+	}
+	resolveAt, err := time.Parse(time.RFC3339, args[6])
+	if err != nil {
+		return fmt.Errorf("resolve time: %w", err)
+	}
+	res, _ := cmd.Flags().GetString("resolution")
+	strike, _ := cmd.Flags().GetFloat64("strike")
+	fallback, _ := cmd.Flags().GetDuration("fallback")
+	spec := core.PredictionSpec{
+		Question:     args[2],
+		Outcomes:     strings.Split(args[3], ","),
+		Liquidity:    liquidity,
+		OracleID:     args[5],
+		Resolution:   core.PredictionResolution(res),
+		Strike:       int64(math.Round(strike * core.OraclePriceScale)),
+		ResolveAfter: resolveAt,
+	}
+	if fallback > 0 {
+		spec.FallbackAfter = resolveAt.Add(fallback)
+	}
+	return defiMgr.CreatePrediction(id, creator, spec)
+}
+
+// defiPredictTrade handles buy and sell: "<id> <trader> <outcome> <amount> <limit>".
+func defiPredictTrade(buy bool) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		id, err := defiHash(args[0])
+		if err != nil {
+			return err
+		}
+		trader, err := defiAddr(args[1])
+		if err != nil {
+			return err
+		}
+		outcome, err := strconv.Atoi(args[2])
+		if err != nil {
+			return err
+		}
+		amt, err := parseUintArg(args[3])
+		if err != nil {
+			return err
+		}
+		limit, err := parseUintArg(args[4])
+		if err != nil {
+			return err
+		}
+		if buy {
+			cost, err := defiMgr.BuyPrediction(id, trader, outcome, amt, limit)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "bought %d shares for %d\n", amt, cost)
+			return nil
+		}
+		payout, err := defiMgr.SellPrediction(id, trader, outcome, amt, limit)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "sold %d shares for %d\n", amt, payout)
+		return nil
+	}
+}
+
+func defiPredictResolve(cmd *cobra.Command, args []string) error {
+	id, err := defiHash(args[0])
+	if err != nil {
+		return err
+	}
+	winner, err := defiMgr.ResolvePrediction(id)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "winning outcome %d\n", winner)
+	return nil
+}
+
+func defiPredictClaim(cmd *cobra.Command, args []string) error {
+	id, err := defiHash(args[0])
+	if err != nil {
+		return err
+	}
+	holder, err := defiAddr(args[1])
+	if err != nil {
+		return err
+	}
+	amt, err := defiMgr.ClaimPrediction(id, holder)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "claimed %d\n", amt)
+	return nil
+}
+
+func defiPredictShow(cmd *cobra.Command, args []string) error {
+	id, err := defiHash(args[0])
+	if err != nil {
+		return err
+	}
+	pm, err := defiMgr.PredictionMarketOf(id)
+	if err != nil {
+		return err
+	}
+	return defiPrintJSON(cmd, struct {
+		core.PredictionMarket
+		Prices []float64 `json:"prices"`
+	}{pm, pm.Prices()})
+}
+
+// defiFarmArgs parses the "<farm> <owner>" prefix of the farm commands.
+func defiFarmArgs(args []string) (uint64, core.Address, error) {
+	id, err := parseUintArg(args[0])
+	if err != nil {
+		return 0, core.Address{}, err
+	}
+	owner, err := defiAddr(args[1])
+	return id, owner, err
+}
+
+func defiFarmStake(cmd *cobra.Command, args []string) error {
+	id, owner, err := defiFarmArgs(args)
+	if err != nil {
+		return err
+	}
+	amt, err := parseUintArg(args[2])
+	if err != nil {
+		return err
+	}
+	lock, _ := cmd.Flags().GetDuration("lock")
+	paid, err := defiMgr.Stake(id, owner, amt, lock)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "staked %d, harvested %d\n", amt, paid)
+	return nil
+}
+
+func defiFarmUnstake(cmd *cobra.Command, args []string) error {
+	id, owner, err := defiFarmArgs(args)
+	if err != nil {
+		return err
+	}
+	amt, err := parseUintArg(args[2])
+	if err != nil {
+		return err
+	}
+	paid, err := defiMgr.Unstake(id, owner, amt)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "unstaked %d, harvested %d\n", amt, paid)
+	return nil
+}
+
+func defiFarmHarvest(cmd *cobra.Command, args []string) error {
+	id, owner, err := defiFarmArgs(args)
+	if err != nil {
+		return err
+	}
+	paid, err := defiMgr.Harvest(id, owner)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "harvested %d\n", paid)
+	return nil
+}
+
+func defiFarmEmergency(cmd *cobra.Command, args []string) error {
+	id, owner, err := defiFarmArgs(args)
+	if err != nil {
+		return err
+	}
+	amt, err := defiMgr.EmergencyWithdraw(id, owner)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "withdrew %d, rewards forfeited\n", amt)
+	return nil
+}
+
+func defiFarmList(cmd *cobra.Command, _ []string) error {
+	farms, err := defiMgr.YieldFarms()
+	if err != nil {
+		return err
+	}
+	return defiPrintJSON(cmd, struct {
+		Controller core.FarmController `json:"controller"`
+		Farms      []core.YieldFarm    `json:"farms"`
+	}{defiMgr.FarmControllerState(), farms})
+}
+
+func defiFarmStakes(cmd *cobra.Command, args []string) error {
+	owner, err := defiAddr(args[0])
+	if err != nil {
+		return err
+	}
+	stakes, err := defiMgr.FarmStakes(owner)
+	if err != nil {
+		return err
+	}
+	return defiPrintJSON(cmd, stakes)
 }
 
 // minimal helper to parse uint
@@ -86,27 +734,140 @@ var defiCmd = &cobra.Command{
 
 var defiInsuranceCmd = &cobra.Command{
 	Use:   "insurance",
-	Short: "Manage insurance policies",
+	Short: "Underwritten insurance pools and claims",
 }
 
-var defiInsuranceNewCmd = &cobra.Command{
-	Use:   "new <id> <holder> <premium> <payout>",
-	Short: "Create an insurance policy",
-	Args:  cobra.MinimumNArgs(4),
-	RunE:  defiCreateInsurance,
+var defiInsurancePoolCmd = &cobra.Command{
+	Use:   "pool-create <name>",
+	Short: "Open an underwriting pool with a utilisation rate model",
+	Args:  cobra.ExactArgs(1),
+	RunE:  defiInsPoolCreate,
 }
 
 var defiInsuranceClaimCmd = &cobra.Command{
-	Use:   "claim <id>",
-	Short: "Claim an insurance payout",
-	Args:  cobra.MinimumNArgs(1),
+	Use:   "claim <policy> <holder> <amount>",
+	Short: "File a claim for assessor review",
+	Args:  cobra.ExactArgs(3),
 	RunE:  defiClaim,
 }
 
+var defiInsuranceCmds = []*cobra.Command{
+	{Use: "pools", Short: "List underwriting pools", Args: cobra.NoArgs, RunE: defiInsPools},
+	{Use: "underwrite <pool> <from> <amount>", Short: "Deposit capital for pool shares", Args: cobra.ExactArgs(3), RunE: defiInsUnderwrite},
+	{Use: "withdraw <pool> <to> <shares>", Short: "Redeem pool shares for capital", Args: cobra.ExactArgs(3), RunE: defiInsWithdraw},
+	{Use: "assessor-stake <pool> <from> <amount>", Short: "Stake to assess the pool's claims", Args: cobra.ExactArgs(3), RunE: defiInsAssessorStake},
+	{Use: "assessor-unstake <pool> <to> <amount>", Short: "Withdraw assessor stake", Args: cobra.ExactArgs(3), RunE: defiInsAssessorUnstake},
+	{Use: "quote <pool> <cover> <duration>", Short: "Price cover at the pool's utilisation", Args: cobra.ExactArgs(3), RunE: defiInsQuote},
+	{Use: "new <id> <pool> <holder> <cover> <duration> <maxPremium>", Short: "Buy cover from a pool", Args: cobra.ExactArgs(6), RunE: defiCreateInsurance},
+	{Use: "vote <policy> <assessor> <approve>", Short: "Cast a stake-weighted assessor vote", Args: cobra.ExactArgs(3), RunE: defiInsVote},
+	{Use: "decide <policy>", Short: "Close voting and open the appeal window", Args: cobra.ExactArgs(1), RunE: defiInsDecide},
+	{Use: "appeal <policy> <appellant>", Short: "Appeal the decision to an authority panel", Args: cobra.ExactArgs(2), RunE: defiInsAppeal},
+	{Use: "rule <policy> <member> <approve>", Short: "Record an authority panel ruling", Args: cobra.ExactArgs(3), RunE: defiInsVote},
+	{Use: "settle <policy>", Short: "Pay or reject an unappealed claim", Args: cobra.ExactArgs(1), RunE: defiInsSettle},
+	{Use: "show <policy>", Short: "Show a policy and its claim", Args: cobra.ExactArgs(1), RunE: defiInsShow},
+}
+
+var defiCrowdfundCmd = &cobra.Command{
+	Use:   "crowdfund",
+	Short: "Escrowed crowdfunding with milestone releases",
+}
+
+var defiCrowdfundStartCmd = &cobra.Command{
+	Use:   "start <creator> <beneficiary> <title> <goal> <duration>",
+	Short: "Open a campaign, optionally released by milestones",
+	Args:  cobra.ExactArgs(5),
+	RunE:  defiCrowdfundStart,
+}
+
+var defiCrowdfundCmds = []*cobra.Command{
+	{Use: "contribute <id> <from> <amount>", Short: "Escrow a contribution", Args: cobra.ExactArgs(3), RunE: defiCrowdfundContribute},
+	{Use: "finalize <id>", Short: "Settle the campaign against its goal", Args: cobra.ExactArgs(1), RunE: defiCrowdfundFinalize},
+	{Use: "request <id> <creator>", Short: "Open the vote on the next milestone", Args: cobra.ExactArgs(2), RunE: defiCrowdfundRequest},
+	{Use: "vote <id> <backer> <approve>", Short: "Vote on the open milestone", Args: cobra.ExactArgs(3), RunE: defiCrowdfundVote},
+	{Use: "close <id>", Short: "Tally the milestone vote", Args: cobra.ExactArgs(1), RunE: defiCrowdfundClose},
+	{Use: "show <id>", Short: "Show a campaign and its contributions", Args: cobra.ExactArgs(1), RunE: defiCrowdfundShow},
+	{Use: "list", Short: "List campaigns", Args: cobra.NoArgs, RunE: defiCrowdfundList},
+}
+
+var defiSynthCmd = &cobra.Command{
+	Use:   "synth",
+	Short: "Collateralised synthetic assets",
+}
+
+var defiSynthCmds = []*cobra.Command{
+	{Use: "create <symbol> <oracleID>", Short: "Register a synthetic asset priced by an oracle", Args: cobra.ExactArgs(2), RunE: defiSynthCreate},
+	{Use: "list", Short: "List synthetic assets and their supply", Args: cobra.NoArgs, RunE: defiSynthList},
+	{Use: "deposit <minter> <amount>", Short: "Lock collateral", Args: cobra.ExactArgs(2), RunE: defiSynthAmount(func(a core.Address, amt uint64) error { return defiMgr.DepositCollateral(a, amt) })},
+	{Use: "withdraw <minter> <amount>", Short: "Release collateral above the minimum ratio", Args: cobra.ExactArgs(2), RunE: defiSynthAmount(func(a core.Address, amt uint64) error { return defiMgr.WithdrawCollateral(a, amt) })},
+	{Use: "mint <minter> <symbol> <amount>", Short: "Mint synths against collateral", Args: cobra.ExactArgs(3), RunE: defiSynthMint},
+	{Use: "burn <minter> <symbol> <amount>", Short: "Burn synths to repay debt", Args: cobra.ExactArgs(3), RunE: defiSynthBurn},
+	{Use: "claim <minter>", Short: "Claim accrued mint fees", Args: cobra.ExactArgs(1), RunE: defiSynthClaim},
+	{Use: "liquidate <liquidator> <minter> <symbol> <amount>", Short: "Liquidate an under-collateralised minter", Args: cobra.ExactArgs(4), RunE: defiSynthLiquidate},
+	{Use: "position <minter>", Short: "Show collateral, debt and collateral ratio", Args: cobra.ExactArgs(1), RunE: defiSynthPosition},
+}
+
+var defiPredictCmd = &cobra.Command{
+	Use:   "predict",
+	Short: "LMSR prediction markets",
+}
+
+var defiPredictCreateCmd = &cobra.Command{
+	Use:   "create <id> <creator> <question> <outcome,outcome,...> <liquidity> <oracleID> <resolveAfter>",
+	Short: "Open a prediction market, funding its b·ln(n) subsidy",
+	Args:  cobra.ExactArgs(7),
+	RunE:  defiPredictCreate,
+}
+
+var defiPredictCmds = []*cobra.Command{
+	{Use: "buy <id> <buyer> <outcome> <amount> <maxCost>", Short: "Buy outcome shares", Args: cobra.ExactArgs(5), RunE: defiPredictTrade(true)},
+	{Use: "sell <id> <seller> <outcome> <amount> <minPayout>", Short: "Sell outcome shares back to the market", Args: cobra.ExactArgs(5), RunE: defiPredictTrade(false)},
+	{Use: "resolve <id>", Short: "Resolve a market from its oracle", Args: cobra.ExactArgs(1), RunE: defiPredictResolve},
+	{Use: "claim <id> <holder>", Short: "Redeem shares of a resolved market", Args: cobra.ExactArgs(2), RunE: defiPredictClaim},
+	{Use: "show <id>", Short: "Show a market and its prices", Args: cobra.ExactArgs(1), RunE: defiPredictShow},
+}
+
+var defiFarmCmd = &cobra.Command{
+	Use:   "farm",
+	Short: "Yield farms and reward emissions",
+}
+
+var defiFarmStakeCmd = &cobra.Command{
+	Use:   "stake <farm> <owner> <amount>",
+	Short: "Stake into a farm, optionally locking for a boost",
+	Args:  cobra.ExactArgs(3),
+	RunE:  defiFarmStake,
+}
+
+var defiFarmCmds = []*cobra.Command{
+	{Use: "list", Short: "Show the emission schedule and farms", Args: cobra.NoArgs, RunE: defiFarmList},
+	{Use: "unstake <farm> <owner> <amount>", Short: "Withdraw an unlocked stake", Args: cobra.ExactArgs(3), RunE: defiFarmUnstake},
+	{Use: "harvest <farm> <owner>", Short: "Claim pending rewards", Args: cobra.ExactArgs(2), RunE: defiFarmHarvest},
+	{Use: "emergency-withdraw <farm> <owner>", Short: "Withdraw the whole stake, forfeiting rewards", Args: cobra.ExactArgs(2), RunE: defiFarmEmergency},
+	{Use: "stakes <owner>", Short: "Show an owner's stakes with pending rewards", Args: cobra.ExactArgs(1), RunE: defiFarmStakes},
+}
+
 func init() {
-	defiInsuranceCmd.AddCommand(defiInsuranceNewCmd)
-	defiInsuranceCmd.AddCommand(defiInsuranceClaimCmd)
-	defiCmd.AddCommand(defiInsuranceCmd)
+	defiFarmStakeCmd.Flags().Duration("lock", 0, "lock the stake for this long to earn the matching tier boost")
+	defiFarmCmd.AddCommand(defiFarmStakeCmd)
+	defiFarmCmd.AddCommand(defiFarmCmds...)
+	defiPredictCreateCmd.Flags().String("resolution", string(core.PredictionOracleOutcome), "price_above or oracle_outcome")
+	defiPredictCreateCmd.Flags().Float64("strike", 0, "strike price for price_above markets")
+	defiPredictCreateCmd.Flags().Duration("fallback", 0, "governance fallback delay after the resolve time (default 72h)")
+	defiPredictCmd.AddCommand(defiPredictCreateCmd)
+	defiPredictCmd.AddCommand(defiPredictCmds...)
+	defiInsurancePoolCmd.Flags().Uint64("base", 200, "annual base rate in bps")
+	defiInsurancePoolCmd.Flags().Uint64("slope", 800, "annual rate added up to the kink, in bps")
+	defiInsurancePoolCmd.Flags().Uint64("kink", 8_000, "utilisation kink in bps")
+	defiInsurancePoolCmd.Flags().Uint64("jump", 5_000, "annual rate added from the kink to full utilisation, in bps")
+	defiInsuranceClaimCmd.Flags().String("evidence", "", "evidence reference for assessors")
+	defiInsuranceCmd.AddCommand(defiInsurancePoolCmd, defiInsuranceClaimCmd)
+	defiInsuranceCmd.AddCommand(defiInsuranceCmds...)
+	defiCrowdfundStartCmd.Flags().String("milestones", "", "comma separated milestone shares in bps, summing to 10000")
+	defiCrowdfundStartCmd.Flags().StringSlice("labels", nil, "milestone descriptions, in order")
+	defiCrowdfundCmd.AddCommand(defiCrowdfundStartCmd)
+	defiCrowdfundCmd.AddCommand(defiCrowdfundCmds...)
+	defiSynthCmd.AddCommand(defiSynthCmds...)
+	defiCmd.AddCommand(defiInsuranceCmd, defiCrowdfundCmd, defiSynthCmd, defiPredictCmd, defiFarmCmd)
 }
 
 // DeFiCmd exported for index.go
@@ -1,7 +1,6 @@
 package cli
 
 import (
-	"encoding/hex"
 	"fmt"
 	"os"
 	"strconv"
@@ -46,13 +45,7 @@ func distInit(cmd *cobra.Command, _ []string) error {
 }
 
 func distParseAddr(h string) (core.Address, error) {
-	var a core.Address
-	b, err := hex.DecodeString(strings.TrimPrefix(h, "0x"))
-	if err != nil || len(b) != len(a) {
-		return a, fmt.Errorf("bad address")
-	}
-	copy(a[:], b)
-	return a, nil
+	return core.DecodeAddress(h)
 }
 
 func distAirdrop(_ *cobra.Command, args []string) error {
@@ -0,0 +1,120 @@
+package cli
+
+// doctor.go – `synnergy doctor` runs the state invariants registered by core
+// modules and reports violations. With --every it keeps running as a
+// verifier that re-checks every N blocks.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/spf13/cobra"
+
+	core "synnergy-network/core"
+)
+
+var (
+	doctorOnce sync.Once
+	doctorErr  error
+)
+
+func doctorInit(cmd *cobra.Command, _ []string) error {
+	doctorOnce.Do(func() {
+		_ = godotenv.Load()
+		if core.CurrentLedger() != nil {
+			return
+		}
+		path := os.Getenv("LEDGER_PATH")
+		if path == "" {
+			doctorErr = fmt.Errorf("LEDGER_PATH not set")
+			return
+		}
+		doctorErr = core.InitLedger(path)
+	})
+	return doctorErr
+}
+
+func printInvariantReport(cmd *cobra.Command, rep core.InvariantReport, asJSON bool) {
+	out := cmd.OutOrStdout()
+	if asJSON {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(rep)
+		return
+	}
+	for _, res := range rep.Results {
+		if res.OK {
+			fmt.Fprintf(out, "✔ %-28s %s\n", res.Invariant, res.Took)
+		} else {
+			fmt.Fprintf(out, "✘ %-28s %s\n", res.Invariant, res.Error)
+		}
+	}
+	fmt.Fprintf(out, "height %d: %d checks, %d violations\n", rep.Height, len(rep.Results), rep.Violations)
+}
+
+func doctorRun(cmd *cobra.Command, args []string) error {
+	asJSON, _ := cmd.Flags().GetBool("json")
+	every, _ := cmd.Flags().GetUint64("every")
+	if every == 0 {
+		rep := core.RunInvariants(args...)
+		printInvariantReport(cmd, rep, asJSON)
+		if !rep.OK() {
+			return fmt.Errorf("%d invariant violations", rep.Violations)
+		}
+		return nil
+	}
+
+	halt, _ := cmd.Flags().GetBool("halt")
+	poll, _ := cmd.Flags().GetDuration("poll")
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	failed := make(chan core.InvariantReport, 1)
+	core.StartInvariantVerifier(ctx, core.InvariantVerifierConfig{Every: every, Poll: poll, Halt: halt},
+		func(rep core.InvariantReport) { failed <- rep })
+	fmt.Fprintf(cmd.OutOrStdout(), "verifying invariants every %d blocks (Ctrl-C to stop)\n", every)
+	select {
+	case <-ctx.Done():
+		return nil
+	case rep := <-failed:
+		printInvariantReport(cmd, rep, asJSON)
+		return fmt.Errorf("halted: %d invariant violations", rep.Violations)
+	}
+}
+
+func doctorList(cmd *cobra.Command, _ []string) error {
+	for _, inv := range core.Invariants() {
+		fmt.Fprintln(cmd.OutOrStdout(), inv.FullName())
+	}
+	return nil
+}
+
+var doctorCmd = &cobra.Command{
+	Use:               "doctor [module|module/name ...]",
+	Short:             "Check cross-module state invariants",
+	PersistentPreRunE: doctorInit,
+	RunE:              doctorRun,
+}
+
+var doctorListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List registered invariants",
+	Args:  cobra.NoArgs,
+	RunE:  doctorList,
+}
+
+func init() {
+	doctorCmd.Flags().Bool("json", false, "print the report as JSON")
+	doctorCmd.Flags().Uint64("every", 0, "keep running and re-check every N blocks")
+	doctorCmd.Flags().Duration("poll", 5*time.Second, "chain height poll interval with --every")
+	doctorCmd.Flags().Bool("halt", false, "with --every, exit on the first violation")
+	doctorCmd.AddCommand(doctorListCmd)
+}
+
+// DoctorCmd exposes the invariant checker.
+var DoctorCmd = doctorCmd
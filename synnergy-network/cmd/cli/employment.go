@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -67,6 +68,32 @@ func (c *EmploymentController) Show(id string) (core.EmploymentContract, error)
 	return ec, nil
 }
 
+func parseWithholdings(specs []string) ([]core.Withholding, error) {
+	var out []core.Withholding
+	for _, s := range specs {
+		parts := strings.Split(s, ":")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("withholding %q: want label:authority:bps", s)
+		}
+		auth, err := core.ParseAddress(parts[1])
+		if err != nil {
+			return nil, err
+		}
+		bps, err := strconv.ParseUint(parts[2], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, core.Withholding{Label: parts[0], Authority: auth, Bps: bps})
+	}
+	return out, nil
+}
+
+func printEmp(cmd *cobra.Command, v interface{}) error {
+	enc := json.NewEncoder(cmd.OutOrStdout())
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
 var employmentCmd = &cobra.Command{Use: "employment", Short: "Employment contracts", PersistentPreRunE: ensureEmployment}
 
 var empCreateCmd = &cobra.Command{Use: "create <employer> <employee> <salary> <hours>", Args: cobra.ExactArgs(4), RunE: func(cmd *cobra.Command, args []string) error {
@@ -112,8 +139,87 @@ var empShowCmd = &cobra.Command{Use: "show <id>", Args: cobra.ExactArgs(1), RunE
 	return enc.Encode(ec)
 }}
 
+var empStreamCmd = &cobra.Command{Use: "stream <id> <employer> <rate-per-second>", Short: "Start streaming salary for a signed contract", Args: cobra.ExactArgs(3), RunE: func(cmd *cobra.Command, args []string) error {
+	emp, err := core.ParseAddress(args[1])
+	if err != nil {
+		return err
+	}
+	rate, err := strconv.ParseUint(args[2], 10, 64)
+	if err != nil {
+		return err
+	}
+	specs, _ := cmd.Flags().GetStringSlice("withhold")
+	ws, err := parseWithholdings(specs)
+	if err != nil {
+		return err
+	}
+	s, err := empCtrl.reg.StartSalaryStream(args[0], emp, rate, ws)
+	if err != nil {
+		return err
+	}
+	return printEmp(cmd, s)
+}}
+
+var empWithholdCmd = &cobra.Command{Use: "withholding <id> <employer>", Short: "Replace the withholding schedule of a stream", Args: cobra.ExactArgs(2), RunE: func(cmd *cobra.Command, args []string) error {
+	emp, err := core.ParseAddress(args[1])
+	if err != nil {
+		return err
+	}
+	specs, _ := cmd.Flags().GetStringSlice("withhold")
+	ws, err := parseWithholdings(specs)
+	if err != nil {
+		return err
+	}
+	return empCtrl.reg.SetWithholdings(args[0], emp, ws)
+}}
+
+var empAccruedCmd = &cobra.Command{Use: "accrued <id>", Short: "Show claimable gross salary", Args: cobra.ExactArgs(1), RunE: func(cmd *cobra.Command, args []string) error {
+	amt, err := empCtrl.reg.StreamAccrued(args[0])
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), amt)
+	return nil
+}}
+
+var empClaimCmd = &cobra.Command{Use: "claim <id> <employee>", Short: "Claim accrued salary", Args: cobra.ExactArgs(2), RunE: func(cmd *cobra.Command, args []string) error {
+	ee, err := core.ParseAddress(args[1])
+	if err != nil {
+		return err
+	}
+	slip, err := empCtrl.reg.ClaimSalary(args[0], ee)
+	if err != nil {
+		return err
+	}
+	return printEmp(cmd, slip)
+}}
+
+var empTerminateCmd = &cobra.Command{Use: "terminate <id> <caller>", Short: "Settle accrued salary and close the contract", Args: cobra.ExactArgs(2), RunE: func(cmd *cobra.Command, args []string) error {
+	caller, err := core.ParseAddress(args[1])
+	if err != nil {
+		return err
+	}
+	slip, err := empCtrl.reg.TerminateSalaryStream(args[0], caller)
+	if err != nil {
+		return err
+	}
+	return printEmp(cmd, slip)
+}}
+
+var empPayslipsCmd = &cobra.Command{Use: "payslips <id>", Short: "List payslips of a contract", Args: cobra.ExactArgs(1), RunE: func(cmd *cobra.Command, args []string) error {
+	list, err := empCtrl.reg.Payslips(args[0])
+	if err != nil {
+		return err
+	}
+	return printEmp(cmd, list)
+}}
+
 func init() {
-	employmentCmd.AddCommand(empCreateCmd, empSignCmd, empHoursCmd, empPayCmd, empShowCmd)
+	for _, c := range []*cobra.Command{empStreamCmd, empWithholdCmd} {
+		c.Flags().StringSlice("withhold", nil, "withholding as label:authority:bps (repeatable)")
+	}
+	employmentCmd.AddCommand(empCreateCmd, empSignCmd, empHoursCmd, empPayCmd, empShowCmd,
+		empStreamCmd, empWithholdCmd, empAccruedCmd, empClaimCmd, empTerminateCmd, empPayslipsCmd)
 }
 
 var EmploymentCmd = employmentCmd
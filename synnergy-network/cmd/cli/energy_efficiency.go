@@ -109,6 +109,22 @@ func networkAvgRPC(ctx context.Context) (float64, error) {
 	return resp.Score, nil
 }
 
+func policyRPC(ctx context.Context) (map[string]any, error) {
+	cli, err := newEffClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer cli.Close()
+	if err := cli.writeJSON(map[string]any{"action": "policy"}); err != nil {
+		return nil, err
+	}
+	var resp map[string]any
+	if err := cli.readJSON(&resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
 // -----------------------------------------------------------------------------
 // Cobra commands
 // -----------------------------------------------------------------------------
@@ -167,6 +183,22 @@ var energyNetCmd = &cobra.Command{
 	},
 }
 
+var energyPolicyCmd = &cobra.Command{
+	Use:   "policy",
+	Short: "Show the governed proposer scheduling policy",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(cmd.Context(), 2*time.Second)
+		defer cancel()
+		p, err := policyRPC(ctx)
+		if err != nil {
+			return err
+		}
+		b, _ := json.MarshalIndent(p, "", "  ")
+		fmt.Println(string(b))
+		return nil
+	},
+}
+
 func init() {
 	energyRecordCmd.Flags().String("txs", "", "number of transactions")
 	energyRecordCmd.Flags().String("energy", "", "energy used in kWh")
@@ -174,6 +206,7 @@ func init() {
 	energyCmd.AddCommand(energyRecordCmd)
 	energyCmd.AddCommand(energyEffCmd)
 	energyCmd.AddCommand(energyNetCmd)
+	energyCmd.AddCommand(energyPolicyCmd)
 }
 
 // EnergyCmd is the exported command tree.
@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -17,20 +19,78 @@ func escParseAddr(hexStr string) (core.Address, error) {
 
 // ---------------------------- Controllers ----------------------------
 
-type EscrowController struct{}
+type EscrowController struct{ From core.Address }
 
-func (EscrowController) Create(parties []core.EscrowParty) (*core.EscrowContract, error) {
-	return core.EscrowCreate(&core.Context{}, parties)
+func (c EscrowController) ctx() *core.Context { return &core.Context{Caller: c.From} }
+
+func (c EscrowController) Create(parties []core.EscrowParty) (*core.EscrowContract, error) {
+	return core.EscrowCreate(c.ctx(), parties)
+}
+
+func (c EscrowController) CreateMilestones(ms []core.EscrowMilestone, deadline time.Time) (*core.EscrowContract, error) {
+	return core.EscrowCreateMilestones(c.ctx(), ms, deadline)
+}
+
+func (c EscrowController) Deposit(id string, amt uint64) error {
+	return core.EscrowDeposit(c.ctx(), id, amt)
+}
+
+func (c EscrowController) Release(id string) error { return core.EscrowRelease(c.ctx(), id) }
+func (c EscrowController) ReleaseMilestone(id string, idx int) error {
+	return core.EscrowReleaseMilestone(c.ctx(), id, idx)
+}
+func (c EscrowController) Cancel(id string) error { return core.EscrowCancel(c.ctx(), id) }
+func (c EscrowController) Dispute(id, reason string, panel int) (*core.EscrowDispute, error) {
+	return core.EscrowRaiseDispute(c.ctx(), id, reason, panel)
+}
+func (c EscrowController) Arbitrate(id string, bps uint16) error {
+	return core.EscrowArbitrate(c.ctx(), id, bps)
 }
+func (c EscrowController) Sweep() ([]string, error) {
+	return core.EscrowRefundExpired(c.ctx(), time.Now().UTC())
+}
+func (EscrowController) Get(id string) (*core.EscrowContract, error)   { return core.EscrowGet(id) }
+func (EscrowController) History(id string) ([]core.EscrowEvent, error) { return core.EscrowHistory(id) }
+func (EscrowController) List() ([]core.EscrowContract, error)          { return core.EscrowList() }
 
-func (EscrowController) Deposit(id string, amt uint64) error {
-	return core.EscrowDeposit(&core.Context{}, id, amt)
+// escController builds a controller acting as the --from address.
+func escController(cmd *cobra.Command) (EscrowController, error) {
+	from, _ := cmd.Flags().GetString("from")
+	if from == "" {
+		return EscrowController{}, nil
+	}
+	addr, err := escParseAddr(strings.TrimPrefix(from, "0x"))
+	if err != nil {
+		return EscrowController{}, err
+	}
+	return EscrowController{From: addr}, nil
 }
 
-func (EscrowController) Release(id string) error                     { return core.EscrowRelease(&core.Context{}, id) }
-func (EscrowController) Cancel(id string) error                      { return core.EscrowCancel(&core.Context{}, id) }
-func (EscrowController) Get(id string) (*core.EscrowContract, error) { return core.EscrowGet(id) }
-func (EscrowController) List() ([]core.EscrowContract, error)        { return core.EscrowList() }
+// parseMilestone parses "payee:amount[:description]".
+func parseMilestone(s string) (core.EscrowMilestone, error) {
+	parts := strings.SplitN(s, ":", 3)
+	if len(parts) < 2 {
+		return core.EscrowMilestone{}, fmt.Errorf("milestone %q: want payee:amount[:description]", s)
+	}
+	addr, err := escParseAddr(strings.TrimPrefix(parts[0], "0x"))
+	if err != nil {
+		return core.EscrowMilestone{}, err
+	}
+	amt, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil {
+		return core.EscrowMilestone{}, fmt.Errorf("milestone %q: %w", s, err)
+	}
+	m := core.EscrowMilestone{Payee: addr, Amount: amt}
+	if len(parts) == 3 {
+		m.Description = parts[2]
+	}
+	return m, nil
+}
+
+func printEscrowJSON(cmd *cobra.Command, v interface{}) {
+	out, _ := json.MarshalIndent(v, "", "  ")
+	fmt.Fprintln(cmd.OutOrStdout(), string(out))
+}
 
 // ------------------------------ CLI ---------------------------------
 
@@ -40,30 +100,54 @@ var escrowCmd = &cobra.Command{
 }
 
 var escrowCreateCmd = &cobra.Command{
-	Use:   "create <amount> <addr1> [addrN...]",
-	Short: "Create a new escrow splitting amount equally",
-	Args:  cobra.MinimumNArgs(2),
+	Use:   "create [<amount> <addr1> [addrN...]]",
+	Short: "Create an escrow from milestones or split amount equally",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		total, err := strconv.ParseUint(args[0], 10, 64)
+		c, err := escController(cmd)
 		if err != nil {
-			return fmt.Errorf("invalid amount: %w", err)
+			return err
 		}
-		n := len(args) - 1
-		share := total / uint64(n)
-		parties := make([]core.EscrowParty, n)
-		for i, hexAddr := range args[1:] {
-			addr, err := escParseAddr(hexAddr)
+		specs, _ := cmd.Flags().GetStringArray("milestone")
+		within, _ := cmd.Flags().GetDuration("deadline")
+		var ms []core.EscrowMilestone
+		if len(specs) > 0 {
+			if len(args) > 0 {
+				return fmt.Errorf("use either --milestone or positional parties")
+			}
+			for _, spec := range specs {
+				m, err := parseMilestone(spec)
+				if err != nil {
+					return err
+				}
+				ms = append(ms, m)
+			}
+		} else {
+			if len(args) < 2 {
+				return fmt.Errorf("provide --milestone or <amount> <addr...>")
+			}
+			total, err := strconv.ParseUint(args[0], 10, 64)
 			if err != nil {
-				return err
+				return fmt.Errorf("invalid amount: %w", err)
+			}
+			n := len(args) - 1
+			share := total / uint64(n)
+			for _, hexAddr := range args[1:] {
+				addr, err := escParseAddr(hexAddr)
+				if err != nil {
+					return err
+				}
+				ms = append(ms, core.EscrowMilestone{Payee: addr, Amount: share})
 			}
-			parties[i] = core.EscrowParty{Address: addr, Amount: share}
 		}
-		esc, err := EscrowController{}.Create(parties)
+		var deadline time.Time
+		if within > 0 {
+			deadline = time.Now().UTC().Add(within)
+		}
+		esc, err := c.CreateMilestones(ms, deadline)
 		if err != nil {
 			return err
 		}
-		out, _ := json.MarshalIndent(esc, "", "  ")
-		fmt.Fprintln(cmd.OutOrStdout(), string(out))
+		printEscrowJSON(cmd, esc)
 		return nil
 	},
 }
@@ -77,25 +161,106 @@ var escrowDepositCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
-		return EscrowController{}.Deposit(args[0], amt)
+		c, err := escController(cmd)
+		if err != nil {
+			return err
+		}
+		return c.Deposit(args[0], amt)
 	},
 }
 
 var escrowReleaseCmd = &cobra.Command{
 	Use:   "release <escrow-id>",
-	Short: "Release funds to escrow parties",
+	Short: "Release one milestone or all outstanding milestones",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return EscrowController{}.Release(args[0])
+		c, err := escController(cmd)
+		if err != nil {
+			return err
+		}
+		if cmd.Flags().Changed("milestone") {
+			idx, _ := cmd.Flags().GetInt("milestone")
+			return c.ReleaseMilestone(args[0], idx)
+		}
+		return c.Release(args[0])
 	},
 }
 
 var escrowCancelCmd = &cobra.Command{
 	Use:   "cancel <escrow-id>",
-	Short: "Cancel an escrow and refund creator",
+	Short: "Consent to cancel; refunds the creator once all parties consent",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := escController(cmd)
+		if err != nil {
+			return err
+		}
+		return c.Cancel(args[0])
+	},
+}
+
+var escrowDisputeCmd = &cobra.Command{
+	Use:   "dispute <escrow-id>",
+	Short: "Freeze an escrow and draw an arbitration panel",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return EscrowController{}.Cancel(args[0])
+		c, err := escController(cmd)
+		if err != nil {
+			return err
+		}
+		reason, _ := cmd.Flags().GetString("reason")
+		panel, _ := cmd.Flags().GetInt("panel")
+		d, err := c.Dispute(args[0], reason, panel)
+		if err != nil {
+			return err
+		}
+		printEscrowJSON(cmd, d)
+		return nil
+	},
+}
+
+var escrowArbitrateCmd = &cobra.Command{
+	Use:   "arbitrate <escrow-id> <payee-bps>",
+	Short: "Vote the share (basis points) of unreleased funds awarded to payees",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := escController(cmd)
+		if err != nil {
+			return err
+		}
+		bps, err := strconv.ParseUint(args[1], 10, 16)
+		if err != nil {
+			return fmt.Errorf("invalid bps: %w", err)
+		}
+		return c.Arbitrate(args[0], uint16(bps))
+	},
+}
+
+var escrowHistoryCmd = &cobra.Command{
+	Use:   "history <escrow-id>",
+	Short: "Show the event history of an escrow",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		h, err := EscrowController{}.History(args[0])
+		if err != nil {
+			return err
+		}
+		printEscrowJSON(cmd, h)
+		return nil
+	},
+}
+
+var escrowSweepCmd = &cobra.Command{
+	Use:   "sweep",
+	Short: "Refund escrows whose deadline has passed",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		ids, err := EscrowController{}.Sweep()
+		if err != nil {
+			return err
+		}
+		printEscrowJSON(cmd, ids)
+		return nil
 	},
 }
 
@@ -130,12 +295,23 @@ var escrowListCmd = &cobra.Command{
 }
 
 func init() {
+	escrowCmd.PersistentFlags().String("from", "", "acting account address")
+	escrowCreateCmd.Flags().StringArray("milestone", nil, "milestone as payee:amount[:description] (repeatable)")
+	escrowCreateCmd.Flags().Duration("deadline", 0, "refund unreleased funds after this duration")
+	escrowReleaseCmd.Flags().Int("milestone", 0, "release only this milestone index")
+	escrowDisputeCmd.Flags().String("reason", "", "dispute reason")
+	escrowDisputeCmd.Flags().Int("panel", core.DefaultEscrowPanelSize, "number of arbiters")
+
 	escrowCmd.AddCommand(escrowCreateCmd)
 	escrowCmd.AddCommand(escrowDepositCmd)
 	escrowCmd.AddCommand(escrowReleaseCmd)
 	escrowCmd.AddCommand(escrowCancelCmd)
 	escrowCmd.AddCommand(escrowInfoCmd)
 	escrowCmd.AddCommand(escrowListCmd)
+	escrowCmd.AddCommand(escrowDisputeCmd)
+	escrowCmd.AddCommand(escrowArbitrateCmd)
+	escrowCmd.AddCommand(escrowHistoryCmd)
+	escrowCmd.AddCommand(escrowSweepCmd)
 }
 
 // EscrowRoute is exported for registration in the main CLI.
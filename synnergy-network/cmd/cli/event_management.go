@@ -1,19 +1,29 @@
 package cli
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"os/signal"
+	"strings"
+	"syscall"
 
 	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
 
 	core "synnergy-network/core"
 )
 
 var (
-	eventsCmd     = &cobra.Command{Use: "events", Short: "Manage on-chain events", PersistentPreRunE: eventsInit}
-	eventsEmitCmd = &cobra.Command{Use: "emit <type> <data>", Short: "Emit custom event", Args: cobra.ExactArgs(2), RunE: eventsEmit}
-	eventsListCmd = &cobra.Command{Use: "list <type>", Short: "List events", Args: cobra.ExactArgs(1), RunE: eventsList}
-	eventsGetCmd  = &cobra.Command{Use: "get <type> <id>", Short: "Get event by ID", Args: cobra.ExactArgs(2), RunE: eventsGet}
+	eventsCmd         = &cobra.Command{Use: "events", Short: "Manage on-chain events", PersistentPreRunE: eventsInit}
+	eventsEmitCmd     = &cobra.Command{Use: "emit <type> <data>", Short: "Emit custom event", Args: cobra.ExactArgs(2), RunE: eventsEmit}
+	eventsListCmd     = &cobra.Command{Use: "list <type>", Short: "List events", Args: cobra.ExactArgs(1), RunE: eventsList}
+	eventsGetCmd      = &cobra.Command{Use: "get <type> <id>", Short: "Get event by ID", Args: cobra.ExactArgs(2), RunE: eventsGet}
+	eventsHeadCmd     = &cobra.Command{Use: "head", Short: "Show latest event sequence number", Args: cobra.NoArgs, RunE: eventsHead}
+	eventsRangeCmd    = &cobra.Command{Use: "range", Short: "Replay events by sequence number", Args: cobra.NoArgs, RunE: eventsRange}
+	eventsTailCmd     = &cobra.Command{Use: "tail", Short: "Follow events, optionally from a sequence number", Args: cobra.NoArgs, RunE: eventsTail}
+	eventsContractCmd = &cobra.Command{Use: "contract <addr>", Short: "List indexed contract events", Args: cobra.ExactArgs(1), RunE: eventsContract}
 )
 
 func eventsInit(cmd *cobra.Command, _ []string) error {
@@ -36,6 +46,77 @@ func eventsEmit(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func eventsHead(cmd *cobra.Command, _ []string) error {
+	fmt.Fprintln(cmd.OutOrStdout(), core.Events().Head())
+	return nil
+}
+
+func eventsTopics(cmd *cobra.Command) []string {
+	v, _ := cmd.Flags().GetString("topics")
+	if v == "" {
+		return nil
+	}
+	return strings.Split(v, ",")
+}
+
+func eventsRange(cmd *cobra.Command, _ []string) error {
+	from, _ := cmd.Flags().GetUint64("from")
+	limit, _ := cmd.Flags().GetInt("limit")
+	evs, err := core.Events().Range(from, limit, eventsTopics(cmd))
+	if err != nil {
+		return err
+	}
+	out, _ := json.MarshalIndent(evs, "", "  ")
+	fmt.Fprintln(cmd.OutOrStdout(), string(out))
+	return nil
+}
+
+func eventsTail(cmd *cobra.Command, _ []string) error {
+	from, _ := cmd.Flags().GetUint64("from")
+	addr, _ := cmd.Flags().GetString("grpc")
+	f := core.EventFilter{From: from, Topics: eventsTopics(cmd)}
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	var evs <-chan core.Event
+	var err error
+	if addr != "" {
+		conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		evs, err = core.SubscribeEventsGRPC(ctx, conn, f)
+		if err != nil {
+			return err
+		}
+	} else if evs, err = core.Events().Subscribe(ctx, f); err != nil {
+		return err
+	}
+	enc := json.NewEncoder(cmd.OutOrStdout())
+	for ev := range evs {
+		if err := enc.Encode(ev); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func eventsContract(cmd *cobra.Command, args []string) error {
+	addr, err := core.ParseAddress(args[0])
+	if err != nil {
+		return err
+	}
+	limit, _ := cmd.Flags().GetInt("limit")
+	evs, err := core.Events().ContractEvents(addr, limit)
+	if err != nil {
+		return err
+	}
+	out, _ := json.MarshalIndent(evs, "", "  ")
+	fmt.Fprintln(cmd.OutOrStdout(), string(out))
+	return nil
+}
+
 func eventsList(cmd *cobra.Command, args []string) error {
 	typ := args[0]
 	limit, _ := cmd.Flags().GetInt("limit")
@@ -61,7 +142,14 @@ func eventsGet(cmd *cobra.Command, args []string) error {
 
 func init() {
 	eventsListCmd.Flags().Int("limit", 0, "max items")
-	eventsCmd.AddCommand(eventsEmitCmd, eventsListCmd, eventsGetCmd)
+	eventsRangeCmd.Flags().Uint64("from", 1, "first sequence number")
+	eventsRangeCmd.Flags().Int("limit", 100, "max items")
+	eventsRangeCmd.Flags().String("topics", "", "comma separated topics")
+	eventsTailCmd.Flags().Uint64("from", 0, "first sequence number (0 = new events only)")
+	eventsTailCmd.Flags().String("topics", "", "comma separated topics")
+	eventsTailCmd.Flags().String("grpc", "", "node gRPC address to stream from")
+	eventsContractCmd.Flags().Int("limit", 0, "max items")
+	eventsCmd.AddCommand(eventsEmitCmd, eventsListCmd, eventsGetCmd, eventsHeadCmd, eventsRangeCmd, eventsTailCmd, eventsContractCmd)
 }
 
 var EventsCmd = eventsCmd
@@ -1,7 +1,6 @@
 package cli
 
 import (
-	"encoding/hex"
 	"fmt"
 	"os"
 	"sync"
@@ -44,13 +43,7 @@ func etInit(cmd *cobra.Command, _ []string) error {
 }
 
 func parseAddr(str string) (core.Address, error) {
-	var a core.Address
-	b, err := hex.DecodeString(str)
-	if err != nil || len(b) != len(a) {
-		return a, fmt.Errorf("bad address")
-	}
-	copy(a[:], b)
-	return a, nil
+	return core.DecodeAddress(str)
 }
 
 func handleCreateEvent(cmd *cobra.Command, _ []string) error {
@@ -0,0 +1,167 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	core "synnergy-network/core"
+	"synnergy-network/pkg/sdk"
+)
+
+var exchangeCmd = &cobra.Command{
+	Use:   "exchange",
+	Short: "Exchange integration: destination tags, deposit scans and sweeps",
+}
+
+var exchangeRequireTagCmd = &cobra.Command{
+	Use:   "require-tag <addr>",
+	Short: "Reject untagged payments to an address, or allow them again with --off",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		addr, err := core.DecodeAddress(args[0])
+		if err != nil {
+			return err
+		}
+		off, _ := cmd.Flags().GetBool("off")
+		if err := core.SetRequireDestinationTag(addr, !off); err != nil {
+			return err
+		}
+		fmt.Printf("%s requires destination tag: %v\n", addr.Hex(), !off)
+		return nil
+	},
+}
+
+var exchangeDepositsCmd = &cobra.Command{
+	Use:   "deposits <hot-addr>",
+	Short: "List payments to a hot address with their destination tags and memos",
+	Long: `Walks the blocks of the ledger at LEDGER_PATH between --from and --to
+(default: the head) and prints one JSON line per coin or token payment to
+the hot address.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		hot, err := core.DecodeAddress(args[0])
+		if err != nil {
+			return err
+		}
+		led, err := snapshotLedger()
+		if err != nil {
+			return err
+		}
+		from, _ := cmd.Flags().GetUint64("from")
+		to, _ := cmd.Flags().GetUint64("to")
+		if head := led.LastHeight(); to == 0 || to > head {
+			to = head
+		}
+		deps, err := core.ScanDeposits(led, hot, from, to)
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(os.Stdout)
+		for _, d := range deps {
+			if err := enc.Encode(d); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+var exchangeSweepCmd = &cobra.Command{
+	Use:   "sweep",
+	Short: "Consolidate deposit addresses into cold storage",
+	Long: `Reads one hex private key per line from --keys (blank lines and lines
+starting with # are skipped), looks up their balances on --node and sends
+each balance less the fee to --cold, in batches of --batch transactions.
+Balances that do not cover the fee plus --min are left alone. Every step
+is recorded in the --audit log. --dry-run prints the plan without sending.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		node, _ := cmd.Flags().GetString("node")
+		keyFile, _ := cmd.Flags().GetString("keys")
+		coldStr, _ := cmd.Flags().GetString("cold")
+		cold, err := core.DecodeAddress(coldStr)
+		if err != nil {
+			return fmt.Errorf("invalid --cold: %w", err)
+		}
+		raw, err := os.ReadFile(keyFile)
+		if err != nil {
+			return err
+		}
+		var keys []*sdk.Key
+		for n, line := range strings.Split(string(raw), "\n") {
+			if line = strings.TrimSpace(line); line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			k, err := sdk.KeyFromHex(line)
+			if err != nil {
+				return fmt.Errorf("%s:%d: %w", keyFile, n+1, err)
+			}
+			keys = append(keys, k)
+		}
+		opts := sdk.SweepOptions{Cold: sdk.Address(cold)}
+		opts.GasLimit, _ = cmd.Flags().GetUint64("gas")
+		opts.GasPrice, _ = cmd.Flags().GetUint64("price")
+		opts.MinAmount, _ = cmd.Flags().GetUint64("min")
+		opts.BatchSize, _ = cmd.Flags().GetInt("batch")
+
+		c, err := sdk.New(sdk.Config{NodeURL: node})
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if dry, _ := cmd.Flags().GetBool("dry-run"); dry {
+			plan, err := c.PlanSweep(cmd.Context(), keys, opts)
+			if err != nil {
+				return err
+			}
+			return enc.Encode(plan)
+		}
+		var audit sdk.AuditLog
+		if path, _ := cmd.Flags().GetString("audit"); path != "" {
+			trail, err := core.NewAuditTrail(path, nil)
+			if err != nil {
+				return err
+			}
+			defer trail.Close()
+			audit = trail
+		}
+		rep, err := c.Sweep(cmd.Context(), keys, opts, audit)
+		if rep != nil {
+			_ = enc.Encode(rep)
+		}
+		return err
+	},
+}
+
+func init() {
+	exchangeRequireTagCmd.Flags().Bool("off", false, "lift the requirement")
+
+	exchangeDepositsCmd.Flags().Uint64("from", 0, "first block height")
+	exchangeDepositsCmd.Flags().Uint64("to", 0, "last block height (default: head)")
+
+	exchangeSweepCmd.Flags().String("node", "http://localhost:8080", "API node URL")
+	exchangeSweepCmd.Flags().String("keys", "", "file of deposit private keys")
+	exchangeSweepCmd.MarkFlagRequired("keys")
+	exchangeSweepCmd.Flags().String("cold", "", "cold storage address")
+	exchangeSweepCmd.MarkFlagRequired("cold")
+	exchangeSweepCmd.Flags().Uint64("gas", sdk.DefaultGasLimit, "gas limit per transfer")
+	exchangeSweepCmd.Flags().Uint64("price", sdk.DefaultGasPrice, "gas price")
+	exchangeSweepCmd.Flags().Uint64("min", 0, "smallest amount worth sweeping after fees")
+	exchangeSweepCmd.Flags().Int("batch", core.DefaultSweepBatch, "transfers per batch request")
+	exchangeSweepCmd.Flags().String("audit", "sweep-audit.log", "append-only audit log; empty disables it")
+	exchangeSweepCmd.Flags().Bool("dry-run", false, "print the plan without sending")
+
+	exchangeCmd.AddCommand(
+		exchangeRequireTagCmd,
+		exchangeDepositsCmd,
+		exchangeSweepCmd,
+	)
+}
+
+// ExchangeCmd is exported for index.go
+var ExchangeCmd = exchangeCmd
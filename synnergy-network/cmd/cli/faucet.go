@@ -2,6 +2,7 @@ package cli
 
 import (
 	"fmt"
+	"strconv"
 	"sync"
 	"time"
 
@@ -106,6 +107,68 @@ var faucetConfigCmd = &cobra.Command{
 	},
 }
 
+func faucetMove(withdraw bool) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		addr, err := core.StringToAddress(args[0])
+		if err != nil {
+			return err
+		}
+		amt, err := strconv.ParseUint(args[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("amount uint64: %w", err)
+		}
+		if withdraw {
+			err = faucet.Withdraw(addr, amt)
+		} else {
+			err = faucet.TopUp(addr, amt)
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), "ok")
+		return nil
+	}
+}
+
+var faucetTopUpCmd = &cobra.Command{
+	Use:   "topup <from> <amount>",
+	Short: "Move funds from an operator account into the faucet",
+	Args:  cobra.ExactArgs(2),
+	RunE:  faucetMove(false),
+}
+
+var faucetWithdrawCmd = &cobra.Command{
+	Use:   "withdraw <to> <amount>",
+	Short: "Move funds out of the faucet",
+	Args:  cobra.ExactArgs(2),
+	RunE:  faucetMove(true),
+}
+
+var faucetSolveCmd = &cobra.Command{
+	Use:   "solve <nonce> <addr> <difficulty>",
+	Short: "Solve a faucet proof-of-work challenge",
+	Args:  cobra.ExactArgs(3),
+	// Solving is local; skip faucet initialisation.
+	PersistentPreRunE: func(*cobra.Command, []string) error { return nil },
+	RunE: func(cmd *cobra.Command, args []string) error {
+		addr, err := core.StringToAddress(args[1])
+		if err != nil {
+			return err
+		}
+		bits, err := strconv.ParseUint(args[2], 10, 8)
+		if err != nil {
+			return fmt.Errorf("difficulty: %w", err)
+		}
+		for i := uint64(0); ; i++ {
+			sol := strconv.FormatUint(i, 10)
+			if core.FaucetSolutionValid(args[0], addr, sol, uint8(bits)) {
+				fmt.Fprintln(cmd.OutOrStdout(), sol)
+				return nil
+			}
+		}
+	},
+}
+
 func init() {
 	faucetAmt = 1
 	faucetCooldown = time.Hour
@@ -116,7 +179,7 @@ func init() {
 	faucetConfigCmd.Flags().Uint64("amount", 0, "new amount")
 	faucetConfigCmd.Flags().Duration("cooldown", 0, "new cooldown")
 
-	faucetCmd.AddCommand(faucetRequestCmd, faucetBalanceCmd, faucetConfigCmd)
+	faucetCmd.AddCommand(faucetRequestCmd, faucetBalanceCmd, faucetConfigCmd, faucetTopUpCmd, faucetWithdrawCmd, faucetSolveCmd)
 }
 
 var FaucetCmd = faucetCmd
@@ -94,13 +94,13 @@ func snapshotPeers(ctx context.Context) ([]core.PeerInfo, error) {
 	}
 	var resp struct {
 		Peers []core.PeerInfo `json:"peers"`
-		Error string          `json:"error,omitempty"`
+		Error core.WireError  `json:"error,omitempty"`
 	}
 	if err := cli.readJSON(&resp); err != nil {
 		return nil, err
 	}
-	if resp.Error != "" {
-		return nil, fmt.Errorf(resp.Error)
+	if resp.Error.Err() != nil {
+		return nil, resp.Error.Err()
 	}
 	return resp.Peers, nil
 }
@@ -170,14 +170,14 @@ func predictiveCheck(ctx context.Context, addr string) (float64, error) {
 		return 0, err
 	}
 	var resp struct {
-		Prob  float64 `json:"prob"`
-		Error string  `json:"error,omitempty"`
+		Prob  float64        `json:"prob"`
+		Error core.WireError `json:"error,omitempty"`
 	}
 	if err := cli.readJSON(&resp); err != nil {
 		return 0, err
 	}
-	if resp.Error != "" {
-		return 0, fmt.Errorf(resp.Error)
+	if resp.Error.Err() != nil {
+		return 0, resp.Error.Err()
 	}
 	return resp.Prob, nil
 }
@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	core "synnergy-network/core"
+	"synnergy-network/pkg/sdk"
+)
+
+var feesCmd = &cobra.Command{
+	Use:   "fees",
+	Short: "Gas price estimation",
+}
+
+var feesEstimateCmd = &cobra.Command{
+	Use:   "estimate",
+	Short: "Estimate the gas price needed for inclusion within --blocks blocks",
+	Long: `Asks --node for a gas price that gets a transaction included within
+--blocks blocks with 95% confidence, based on the lowest prices recent blocks
+of similar fullness included. --local computes the estimate from the ledger
+at LEDGER_PATH instead.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		blocks, _ := cmd.Flags().GetInt("blocks")
+		var est interface{} // *core.FeeEstimate or its wire form
+		if local, _ := cmd.Flags().GetBool("local"); local {
+			led, err := snapshotLedger()
+			if err != nil {
+				return err
+			}
+			window, _ := cmd.Flags().GetInt("window")
+			e, err := core.NewFeeOracle(led, window).EstimateFee(blocks)
+			if err != nil {
+				return err
+			}
+			est = e
+		} else {
+			node, _ := cmd.Flags().GetString("node")
+			c, err := sdk.New(sdk.Config{NodeURL: node})
+			if err != nil {
+				return err
+			}
+			e, err := c.EstimateFee(cmd.Context(), blocks)
+			if err != nil {
+				return err
+			}
+			est = e
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(est)
+	},
+}
+
+func init() {
+	feesEstimateCmd.Flags().Int("blocks", core.DefaultFeeTarget, "target number of blocks")
+	feesEstimateCmd.Flags().String("node", "http://localhost:8080", "API node URL")
+	feesEstimateCmd.Flags().Bool("local", false, "estimate from the local ledger")
+	feesEstimateCmd.Flags().Int("window", core.DefaultFeeWindow, "recent blocks sampled with --local")
+
+	feesCmd.AddCommand(feesEstimateCmd)
+}
+
+// FeesCmd is exported for index.go
+var FeesCmd = feesCmd
@@ -1,9 +1,13 @@
 package cli
 
 import (
+	"crypto/ed25519"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"strconv"
 	"sync"
 
 	"github.com/joho/godotenv"
@@ -14,6 +18,7 @@ import (
 
 var (
 	gameOnce sync.Once
+	gameLed  *core.Ledger
 )
 
 func gameInit(cmd *cobra.Command, _ []string) error {
@@ -33,6 +38,7 @@ func gameInit(cmd *cobra.Command, _ []string) error {
 			err = e
 			return
 		}
+		gameLed = led
 		core.InitGaming(led)
 	})
 	return err
@@ -102,6 +108,143 @@ func gameList(cmd *cobra.Command, _ []string) error {
 	return nil
 }
 
+func gamePrint(cmd *cobra.Command, v interface{}) error {
+	enc, _ := json.MarshalIndent(v, "", "  ")
+	fmt.Fprintln(cmd.OutOrStdout(), string(enc))
+	return nil
+}
+
+func gameRules(cmd *cobra.Command, args []string) error {
+	code, err := os.ReadFile(args[0])
+	if err != nil {
+		return err
+	}
+	hash, err := core.RegisterGameRules(code)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), hash)
+	return nil
+}
+
+func gameTurnCreate(cmd *cobra.Command, args []string) error {
+	creatorStr, _ := cmd.Flags().GetString("creator")
+	stake, _ := cmd.Flags().GetUint64("stake")
+	seats, _ := cmd.Flags().GetInt("seats")
+	stateHex, _ := cmd.Flags().GetString("state")
+	window, _ := cmd.Flags().GetDuration("window")
+	addr, err := core.ParseAddress(creatorStr)
+	if err != nil {
+		return err
+	}
+	state, err := hex.DecodeString(stateHex)
+	if err != nil {
+		return fmt.Errorf("invalid state: %w", err)
+	}
+	g, err := core.CreateTurnGame(addr, args[0], stake, seats, state, window)
+	if err != nil {
+		return err
+	}
+	return gamePrint(cmd, g)
+}
+
+func gameTurnJoin(cmd *cobra.Command, args []string) error {
+	playerStr, _ := cmd.Flags().GetString("player")
+	addr, err := core.ParseAddress(playerStr)
+	if err != nil {
+		return err
+	}
+	g, err := core.JoinTurnGame(args[0], addr)
+	if err != nil {
+		return err
+	}
+	return gamePrint(cmd, g)
+}
+
+func gameTurnCancel(cmd *cobra.Command, args []string) error {
+	creatorStr, _ := cmd.Flags().GetString("creator")
+	addr, err := core.ParseAddress(creatorStr)
+	if err != nil {
+		return err
+	}
+	if err := core.CancelTurnGame(args[0], addr); err != nil {
+		return err
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), "cancelled ✔")
+	return nil
+}
+
+func gameMove(cmd *cobra.Command, args []string) error {
+	move, err := hex.DecodeString(args[1])
+	if err != nil {
+		return fmt.Errorf("invalid move: %w", err)
+	}
+	keyHex, _ := cmd.Flags().GetString("key")
+	key, err := hex.DecodeString(keyHex)
+	if err != nil || len(key) != ed25519.PrivateKeySize {
+		return errors.New("invalid ed25519 private key")
+	}
+	m, err := core.PrepareGameMove(args[0], move, ed25519.PrivateKey(key))
+	if err != nil {
+		return err
+	}
+	g, err := core.SubmitGameMove(*m)
+	if err != nil {
+		return err
+	}
+	return gamePrint(cmd, g)
+}
+
+func gameDispute(cmd *cobra.Command, args []string) error {
+	turn, err := strconv.ParseUint(args[1], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid turn: %w", err)
+	}
+	challStr, _ := cmd.Flags().GetString("challenger")
+	addr, err := core.ParseAddress(challStr)
+	if err != nil {
+		return err
+	}
+	d, err := core.DisputeGameMove(args[0], turn, addr)
+	if err != nil {
+		return err
+	}
+	return gamePrint(cmd, d)
+}
+
+func gameFinalize(cmd *cobra.Command, args []string) error {
+	g, err := core.FinalizeTurnGame(args[0])
+	if err != nil {
+		return err
+	}
+	return gamePrint(cmd, g)
+}
+
+func gameTurnGet(cmd *cobra.Command, args []string) error {
+	g, err := core.GetTurnGame(args[0])
+	if err != nil {
+		return err
+	}
+	return gamePrint(cmd, g)
+}
+
+func gameMoves(cmd *cobra.Command, args []string) error {
+	moves, err := core.GameMoves(args[0])
+	if err != nil {
+		return err
+	}
+	return gamePrint(cmd, moves)
+}
+
+func gameLeaderboard(cmd *cobra.Command, _ []string) error {
+	limit, _ := cmd.Flags().GetInt("limit")
+	board, err := core.GameLeaderboard(gameLed, limit)
+	if err != nil {
+		return err
+	}
+	return gamePrint(cmd, board)
+}
+
 var gamingCmd = &cobra.Command{
 	Use:               "gaming",
 	Short:             "Manage simple on-chain games",
@@ -142,6 +285,76 @@ var gameListCmd = &cobra.Command{
 	RunE:  gameList,
 }
 
+var gameRulesCmd = &cobra.Command{
+	Use:   "rules <code.wasm>",
+	Short: "Register a WASM rules contract",
+	Args:  cobra.ExactArgs(1),
+	RunE:  gameRules,
+}
+
+var gameTurnCreateCmd = &cobra.Command{
+	Use:   "turn-create <rules-hash>",
+	Short: "Create a turn-based game",
+	Args:  cobra.ExactArgs(1),
+	RunE:  gameTurnCreate,
+}
+
+var gameTurnJoinCmd = &cobra.Command{
+	Use:   "turn-join <id>",
+	Short: "Take a seat in a turn-based game",
+	Args:  cobra.ExactArgs(1),
+	RunE:  gameTurnJoin,
+}
+
+var gameTurnCancelCmd = &cobra.Command{
+	Use:   "turn-cancel <id>",
+	Short: "Cancel a turn-based game before it starts",
+	Args:  cobra.ExactArgs(1),
+	RunE:  gameTurnCancel,
+}
+
+var gameMoveCmd = &cobra.Command{
+	Use:   "move <id> <move-hex>",
+	Short: "Apply the rules locally and submit a signed move",
+	Args:  cobra.ExactArgs(2),
+	RunE:  gameMove,
+}
+
+var gameDisputeCmd = &cobra.Command{
+	Use:   "dispute <id> <turn>",
+	Short: "Re-execute a move on chain",
+	Args:  cobra.ExactArgs(2),
+	RunE:  gameDispute,
+}
+
+var gameFinalizeCmd = &cobra.Command{
+	Use:   "finalize <id>",
+	Short: "Settle a game after its dispute window",
+	Args:  cobra.ExactArgs(1),
+	RunE:  gameFinalize,
+}
+
+var gameTurnGetCmd = &cobra.Command{
+	Use:   "turn-get <id>",
+	Short: "Show a turn-based game",
+	Args:  cobra.ExactArgs(1),
+	RunE:  gameTurnGet,
+}
+
+var gameMovesCmd = &cobra.Command{
+	Use:   "moves <id>",
+	Short: "List the moves of a game",
+	Args:  cobra.ExactArgs(1),
+	RunE:  gameMoves,
+}
+
+var gameLeaderboardCmd = &cobra.Command{
+	Use:   "leaderboard",
+	Short: "Show player standings",
+	Args:  cobra.NoArgs,
+	RunE:  gameLeaderboard,
+}
+
 func init() {
 	gamingCmd.PersistentFlags().String("ledger", "", "path to ledger")
 
@@ -155,7 +368,30 @@ func init() {
 	gameFinishCmd.Flags().String("winner", "", "winner address")
 	gameFinishCmd.MarkFlagRequired("winner")
 
-	gamingCmd.AddCommand(gameCreateCmd, gameJoinCmd, gameFinishCmd, gameGetCmd, gameListCmd)
+	gameTurnCreateCmd.Flags().String("creator", "", "creator address")
+	gameTurnCreateCmd.MarkFlagRequired("creator")
+	gameTurnCreateCmd.Flags().Uint64("stake", 0, "stake per player")
+	gameTurnCreateCmd.Flags().Int("seats", 2, "number of players")
+	gameTurnCreateCmd.Flags().String("state", "", "initial state (hex)")
+	gameTurnCreateCmd.Flags().Duration("window", core.DefaultGameDisputeWindow, "dispute window")
+
+	gameTurnJoinCmd.Flags().String("player", "", "player address")
+	gameTurnJoinCmd.MarkFlagRequired("player")
+
+	gameTurnCancelCmd.Flags().String("creator", "", "creator address")
+	gameTurnCancelCmd.MarkFlagRequired("creator")
+
+	gameMoveCmd.Flags().String("key", "", "player ed25519 private key (hex)")
+	gameMoveCmd.MarkFlagRequired("key")
+
+	gameDisputeCmd.Flags().String("challenger", "", "challenger address")
+	gameDisputeCmd.MarkFlagRequired("challenger")
+
+	gameLeaderboardCmd.Flags().Int("limit", 0, "maximum entries (0 for all)")
+
+	gamingCmd.AddCommand(gameCreateCmd, gameJoinCmd, gameFinishCmd, gameGetCmd, gameListCmd,
+		gameRulesCmd, gameTurnCreateCmd, gameTurnJoinCmd, gameTurnCancelCmd, gameMoveCmd,
+		gameDisputeCmd, gameFinalizeCmd, gameTurnGetCmd, gameMovesCmd, gameLeaderboardCmd)
 }
 
 // GamingCmd exported for index.go
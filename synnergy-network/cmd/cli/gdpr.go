@@ -0,0 +1,139 @@
+package cli
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	core "synnergy-network/core"
+)
+
+func gdprPrint(v interface{}) {
+	out, _ := json.MarshalIndent(v, "", "  ")
+	fmt.Println(string(out))
+}
+
+var gdprCmd = &cobra.Command{
+	Use:              "gdpr",
+	Short:            "Off-chain personal data and right-to-erasure workflow",
+	PersistentPreRun: initIPFSMiddleware,
+}
+
+var gdprStoreCmd = &cobra.Command{
+	Use:   "store <subject> <controller> <category> <file>",
+	Short: "Pin personal data off-chain and record its hash",
+	Args:  cobra.ExactArgs(4),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		data, err := os.ReadFile(args[3])
+		if err != nil {
+			return err
+		}
+		rec, err := core.StorePersonalData(context.Background(), mustHex(args[0]), mustHex(args[1]), args[2], data)
+		if err != nil {
+			return err
+		}
+		gdprPrint(rec)
+		return nil
+	},
+}
+
+var gdprListCmd = &cobra.Command{
+	Use:   "list <subject>",
+	Short: "List personal data records held for a subject",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		list, err := core.ListPersonalData(mustHex(args[0]))
+		if err != nil {
+			return err
+		}
+		gdprPrint(list)
+		return nil
+	},
+}
+
+var gdprRequestCmd = &cobra.Command{
+	Use:   "request <subject> <key-file> [reason]",
+	Short: "Sign and submit an erasure request",
+	Args:  cobra.RangeArgs(2, 3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		subject := mustHex(args[0])
+		key, err := loadHostKey(args[1])
+		if err != nil {
+			return err
+		}
+		reason := "data subject request"
+		if len(args) == 3 {
+			reason = args[2]
+		}
+		ts := time.Now().Unix()
+		sig := ed25519.Sign(key, core.ErasureMessage(subject, ts))
+		req, err := core.RequestErasure(subject, reason, ts, key.Public().(ed25519.PublicKey), sig)
+		if err != nil {
+			return err
+		}
+		gdprPrint(req)
+		return nil
+	},
+}
+
+var gdprExecuteCmd = &cobra.Command{
+	Use:   "execute <request-id>",
+	Short: "Erase the data and issue the certificate",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cert, err := core.ExecuteErasure(context.Background(), args[0])
+		if err != nil {
+			return err
+		}
+		gdprPrint(cert)
+		return nil
+	},
+}
+
+var gdprCertCmd = &cobra.Command{
+	Use:   "certificate <request-id>",
+	Short: "Fetch and verify an erasure certificate",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cert, err := core.GetErasureCertificate(args[0])
+		if err != nil {
+			return err
+		}
+		gdprPrint(map[string]interface{}{"certificate": cert, "valid": core.VerifyErasureCertificate(cert)})
+		return nil
+	},
+}
+
+var gdprTombstoneCmd = &cobra.Command{
+	Use:   "tombstone <hash>",
+	Short: "Show the tombstone for an erased payload hash",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var h [32]byte
+		b, err := hex.DecodeString(strings.TrimPrefix(args[0], "0x"))
+		if err != nil || len(b) != len(h) {
+			return fmt.Errorf("invalid hash")
+		}
+		copy(h[:], b)
+		ts, err := core.GetTombstone(h)
+		if err != nil {
+			return err
+		}
+		gdprPrint(ts)
+		return nil
+	},
+}
+
+func init() {
+	gdprCmd.AddCommand(gdprStoreCmd, gdprListCmd, gdprRequestCmd, gdprExecuteCmd, gdprCertCmd, gdprTombstoneCmd)
+}
+
+// GDPRCmd is exported for index.go
+var GDPRCmd = gdprCmd
@@ -98,14 +98,14 @@ func submitProposal(ctx context.Context, changes map[string]string, desc string,
 		return "", err
 	}
 	var resp struct {
-		ID    string `json:"id"`
-		Error string `json:"error,omitempty"`
+		ID    string         `json:"id"`
+		Error core.WireError `json:"error,omitempty"`
 	}
 	if err := cli.readJSON(&resp); err != nil {
 		return "", err
 	}
-	if resp.Error != "" {
-		return "", errors.New(resp.Error)
+	if resp.Error.Err() != nil {
+		return "", resp.Error.Err()
 	}
 	return resp.ID, nil
 }
@@ -139,13 +139,13 @@ func listProposals(ctx context.Context) ([]core.GovProposal, error) {
 	}
 	var resp struct {
 		Proposals []core.GovProposal `json:"proposals"`
-		Error     string             `json:"error,omitempty"`
+		Error     core.WireError     `json:"error,omitempty"`
 	}
 	if err := cli.readJSON(&resp); err != nil {
 		return nil, err
 	}
-	if resp.Error != "" {
-		return nil, errors.New(resp.Error)
+	if resp.Error.Err() != nil {
+		return nil, resp.Error.Err()
 	}
 	return resp.Proposals, nil
 }
@@ -161,13 +161,13 @@ func getProposal(ctx context.Context, id string) (*core.GovProposal, error) {
 	}
 	var resp struct {
 		Proposal core.GovProposal `json:"proposal"`
-		Error    string           `json:"error,omitempty"`
+		Error    core.WireError   `json:"error,omitempty"`
 	}
 	if err := cli.readJSON(&resp); err != nil {
 		return nil, err
 	}
-	if resp.Error != "" {
-		return nil, errors.New(resp.Error)
+	if resp.Error.Err() != nil {
+		return nil, resp.Error.Err()
 	}
 	return &resp.Proposal, nil
 }
@@ -1,7 +1,6 @@
 package cli
 
 import (
-	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"github.com/spf13/cobra"
@@ -23,12 +22,7 @@ func ensureGovMgmt(cmd *cobra.Command, _ []string) error {
 }
 
 func addrFromHex(h string) core.Address {
-	var a core.Address
-	b, err := hex.DecodeString(h)
-	if err != nil || len(b) != len(a) {
-		return a
-	}
-	copy(a[:], b)
+	a, _ := core.DecodeAddress(h)
 	return a
 }
 
@@ -1,9 +1,10 @@
 // cmd/cli/green_technology.go – Sustainability & carbon‑accounting CLI
 // -----------------------------------------------------------------------------
 // This command tree is mounted under the consolidated route “~green”.  It lets
-// operators record validator energy usage / carbon emissions, retire carbon‑
-// offset credits, force a certification run for the current epoch, and inspect
-// certificates or throttle status.
+// operators submit meter‑oracle signed energy usage / carbon emissions and
+// carbon‑offset readings, bind meter oracles to validators, force a
+// certification run for the current epoch, inspect certificates or throttle
+// status and export signed certificates for ESG reporting.
 //
 // • All top‑level Cobra *commands* are declared first (for readability).
 // • Middleware provides a small JSON‑over‑TCP client with newline framing.
@@ -12,11 +13,14 @@
 //   the consolidated tree for `rootCmd.AddCommand()`.
 // -----------------------------------------------------------------------------
 // Example usage
-//   synnergy ~green usage 76c2…ffae --energy=1234.5 --carbon=321.0
-//   synnergy ~green offset 76c2…ffae --kg=500
+//   synnergy ~green meter meter-01 76c2…ffae --regulator=9a1b…
+//   synnergy ~green usage 76c2…ffae --energy=1234.5 --carbon=321.0 \
+//                         --oracle=meter-01 --key=meter.key
+//   synnergy ~green offset 76c2…ffae --kg=500 --oracle=meter-01 --key=meter.key
 //   synnergy ~green certify           # recompute certificates for all nodes
 //   synnergy ~green cert 76c2…ffae    # print node certificate
 //   synnergy ~green list --format=json
+//   synnergy ~green export 76c2…ffae > cert.json
 // -----------------------------------------------------------------------------
 // Environment
 //   GREEN_API_ADDR – host:port of the green‑technology daemon (default
@@ -28,6 +32,8 @@ package cli
 import (
 	"bufio"
 	"context"
+	"crypto/ed25519"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -86,31 +92,107 @@ func (c *greenClient) readJSON(v any) error {
 // Controller helpers – thin wrappers around RPC
 // -----------------------------------------------------------------------------
 
-func recordUsageRPC(ctx context.Context, validator string, energy, carbon float64) error {
+// signReading builds a meter reading for the last 24 h and signs it with the
+// meter oracle key at keyPath.
+func signReading(oracleID, validator, keyPath string, energy, carbon, offset float64) (core.MeterReading, []byte, error) {
+	if oracleID == "" || keyPath == "" {
+		return core.MeterReading{}, nil, errors.New("--oracle and --key required")
+	}
+	key, err := loadHostKey(keyPath)
+	if err != nil {
+		return core.MeterReading{}, nil, err
+	}
+	now := time.Now().UTC()
+	r := core.MeterReading{
+		OracleID:    oracleID,
+		Validator:   mustHex(validator),
+		EnergyKWh:   energy,
+		CarbonKg:    carbon,
+		OffsetKg:    offset,
+		PeriodStart: now.Add(-24 * time.Hour).Unix(),
+		PeriodEnd:   now.Unix(),
+		Timestamp:   now.Unix(),
+	}
+	return r, ed25519.Sign(key, core.MeterReadingMessage(r)), nil
+}
+
+func recordReadingRPC(ctx context.Context, action string, r core.MeterReading, sig []byte) error {
 	cli, err := newGreenClient(ctx)
 	if err != nil {
 		return err
 	}
 	defer cli.Close()
-	return cli.writeJSON(map[string]any{
-		"action":    "record_usage",
-		"validator": validator,
-		"energy":    energy,
-		"carbon":    carbon,
-	})
+	if err := cli.writeJSON(map[string]any{
+		"action":  action,
+		"reading": r,
+		"sig":     hex.EncodeToString(sig),
+	}); err != nil {
+		return err
+	}
+	var resp struct {
+		Error core.WireError `json:"error,omitempty"`
+	}
+	if err := cli.readJSON(&resp); err != nil {
+		return err
+	}
+	if resp.Error.Err() != nil {
+		return resp.Error.Err()
+	}
+	return nil
 }
 
-func recordOffsetRPC(ctx context.Context, validator string, kg float64) error {
+func registerMeterRPC(ctx context.Context, regulator, oracleID, validator string) error {
 	cli, err := newGreenClient(ctx)
 	if err != nil {
 		return err
 	}
 	defer cli.Close()
-	return cli.writeJSON(map[string]any{
-		"action":    "record_offset",
+	if err := cli.writeJSON(map[string]any{
+		"action":    "register_meter",
+		"regulator": regulator,
+		"oracle":    oracleID,
 		"validator": validator,
-		"kg":        kg,
-	})
+	}); err != nil {
+		return err
+	}
+	var resp struct {
+		Error core.WireError `json:"error,omitempty"`
+	}
+	if err := cli.readJSON(&resp); err != nil {
+		return err
+	}
+	if resp.Error.Err() != nil {
+		return resp.Error.Err()
+	}
+	return nil
+}
+
+func exportCertRPC(ctx context.Context, addr string) (*core.SignedGreenCertificate, error) {
+	cli, err := newGreenClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer cli.Close()
+	if err := cli.writeJSON(map[string]any{"action": "export_certificate", "addr": addr}); err != nil {
+		return nil, err
+	}
+	var resp struct {
+		Export *core.SignedGreenCertificate `json:"export"`
+		Error  core.WireError               `json:"error,omitempty"`
+	}
+	if err := cli.readJSON(&resp); err != nil {
+		return nil, err
+	}
+	if resp.Error.Err() != nil {
+		return nil, resp.Error.Err()
+	}
+	if resp.Export == nil {
+		return nil, errors.New("empty export")
+	}
+	if err := core.VerifyGreenCertificateExport(resp.Export); err != nil {
+		return nil, err
+	}
+	return resp.Export, nil
 }
 
 func certifyRPC(ctx context.Context) error {
@@ -133,13 +215,13 @@ func certificateOfRPC(ctx context.Context, addr string) (core.Certificate, error
 	}
 	var resp struct {
 		Cert  core.Certificate `json:"cert"`
-		Error string           `json:"error,omitempty"`
+		Error core.WireError   `json:"error,omitempty"`
 	}
 	if err := cli.readJSON(&resp); err != nil {
 		return "", err
 	}
-	if resp.Error != "" {
-		return "", errors.New(resp.Error)
+	if resp.Error.Err() != nil {
+		return "", resp.Error.Err()
 	}
 	return resp.Cert, nil
 }
@@ -154,14 +236,14 @@ func shouldThrottleRPC(ctx context.Context, addr string) (bool, error) {
 		return false, err
 	}
 	var resp struct {
-		Throttle bool   `json:"throttle"`
-		Error    string `json:"error,omitempty"`
+		Throttle bool           `json:"throttle"`
+		Error    core.WireError `json:"error,omitempty"`
 	}
 	if err := cli.readJSON(&resp); err != nil {
 		return false, err
 	}
-	if resp.Error != "" {
-		return false, errors.New(resp.Error)
+	if resp.Error.Err() != nil {
+		return false, resp.Error.Err()
 	}
 	return resp.Throttle, nil
 }
@@ -185,13 +267,13 @@ func listCertsRPC(ctx context.Context) ([]struct {
 			Cert    core.Certificate `json:"cert"`
 			Score   float64          `json:"score"`
 		} `json:"certs"`
-		Error string `json:"error,omitempty"`
+		Error core.WireError `json:"error,omitempty"`
 	}
 	if err := cli.readJSON(&resp); err != nil {
 		return nil, err
 	}
-	if resp.Error != "" {
-		return nil, errors.New(resp.Error)
+	if resp.Error.Err() != nil {
+		return nil, resp.Error.Err()
 	}
 	return resp.Certs, nil
 }
@@ -229,9 +311,15 @@ var usageCmd = &cobra.Command{
 		if err != nil {
 			return fmt.Errorf("invalid --carbon: %w", err)
 		}
+		oracleID, _ := cmd.Flags().GetString("oracle")
+		keyPath, _ := cmd.Flags().GetString("key")
+		r, sig, err := signReading(oracleID, args[0], keyPath, energy, carbon, 0)
+		if err != nil {
+			return err
+		}
 		ctx, cancel := context.WithTimeout(cmd.Context(), 2*time.Second)
 		defer cancel()
-		return recordUsageRPC(ctx, args[0], energy, carbon)
+		return recordReadingRPC(ctx, "record_usage", r, sig)
 	},
 }
 
@@ -249,9 +337,31 @@ var offsetCmd = &cobra.Command{
 		if err != nil {
 			return fmt.Errorf("invalid --kg: %w", err)
 		}
+		oracleID, _ := cmd.Flags().GetString("oracle")
+		keyPath, _ := cmd.Flags().GetString("key")
+		r, sig, err := signReading(oracleID, args[0], keyPath, 0, 0, kg)
+		if err != nil {
+			return err
+		}
 		ctx, cancel := context.WithTimeout(cmd.Context(), 2*time.Second)
 		defer cancel()
-		return recordOffsetRPC(ctx, args[0], kg)
+		return recordReadingRPC(ctx, "record_offset", r, sig)
+	},
+}
+
+// meter -----------------------------------------------------------------------
+var meterCmd = &cobra.Command{
+	Use:   "meter [oracle‑id] [validator‑addr]",
+	Short: "Bind a registered oracle as the energy meter of a validator",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		regulator, _ := cmd.Flags().GetString("regulator")
+		if regulator == "" {
+			return errors.New("--regulator required")
+		}
+		ctx, cancel := context.WithTimeout(cmd.Context(), 2*time.Second)
+		defer cancel()
+		return registerMeterRPC(ctx, regulator, args[0], args[1])
 	},
 }
 
@@ -330,6 +440,24 @@ var greenListCmd = &cobra.Command{
 	},
 }
 
+// export ----------------------------------------------------------------------
+var greenExportCmd = &cobra.Command{
+	Use:   "export [validator‑addr]",
+	Short: "Export the validator certificate as signed JSON",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(cmd.Context(), 2*time.Second)
+		defer cancel()
+		exp, err := exportCertRPC(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(exp)
+	},
+}
+
 // -----------------------------------------------------------------------------
 // init – config & route wiring
 // -----------------------------------------------------------------------------
@@ -356,7 +484,14 @@ func init() {
 	usageCmd.Flags().String("energy", "", "energy consumption in kWh")
 	usageCmd.Flags().String("carbon", "", "CO₂ emissions in kg")
 
+	usageCmd.Flags().String("oracle", "", "meter oracle ID")
+	usageCmd.Flags().String("key", "", "meter oracle ed25519 key file (hex seed)")
+
 	offsetCmd.Flags().String("kg", "", "offset amount in kg CO₂e")
+	offsetCmd.Flags().String("oracle", "", "meter oracle ID")
+	offsetCmd.Flags().String("key", "", "meter oracle ed25519 key file (hex seed)")
+
+	meterCmd.Flags().String("regulator", "", "regulator address authorising the binding")
 
 	greenListCmd.Flags().StringP("format", "f", "table", "output format: table|json")
 	_ = viper.BindPFlag("output.format", greenListCmd.Flags().Lookup("format"))
@@ -368,6 +503,8 @@ func init() {
 	greenCmd.AddCommand(certCmd)
 	greenCmd.AddCommand(throttleCmd)
 	greenCmd.AddCommand(greenListCmd)
+	greenCmd.AddCommand(meterCmd)
+	greenCmd.AddCommand(greenExportCmd)
 }
 
 // NewGreenCommand exposes the consolidated green‑tech command tree.
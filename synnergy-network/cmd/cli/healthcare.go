@@ -1,9 +1,15 @@
 package cli
 
 import (
+	"crypto/rand"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"strings"
+	"time"
+
 	"github.com/spf13/cobra"
+	"golang.org/x/crypto/nacl/box"
 	"synnergy-network/core"
 )
 
@@ -12,13 +18,7 @@ import (
 // ----------------------------------------------------------------------------
 
 func hcParseAddr(s string) (core.Address, error) {
-	b, err := hex.DecodeString(s)
-	if err != nil || len(b) != 20 {
-		return core.AddressZero, fmt.Errorf("bad address")
-	}
-	var a core.Address
-	copy(a[:], b)
-	return a, nil
+	return core.DecodeAddress(s)
 }
 
 func hcInitLedger(cmd *cobra.Command, _ []string) error {
@@ -98,6 +98,201 @@ func hcList(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func hcKey32(s string) (*[32]byte, error) {
+	b, err := hex.DecodeString(s)
+	if err != nil || len(b) != 32 {
+		return nil, fmt.Errorf("bad 32-byte hex key")
+	}
+	var k [32]byte
+	copy(k[:], b)
+	return &k, nil
+}
+
+func hcPrint(cmd *cobra.Command, v interface{}) error {
+	enc, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), string(enc))
+	return nil
+}
+
+func hcKeygen(cmd *cobra.Command, _ []string) error {
+	pub, priv, err := box.GenerateKey(rand.Reader)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "public:  %x\nprivate: %x\n", pub[:], priv[:])
+	return nil
+}
+
+func hcRegisterKey(cmd *cobra.Command, args []string) error {
+	a, err := hcParseAddr(args[0])
+	if err != nil {
+		return err
+	}
+	pub, err := hcKey32(args[1])
+	if err != nil {
+		return err
+	}
+	return core.RegisterHealthKey(a, *pub)
+}
+
+func hcAddEncrypted(cmd *cobra.Command, args []string) error {
+	p, err := hcParseAddr(args[0])
+	if err != nil {
+		return err
+	}
+	d, err := hcParseAddr(args[1])
+	if err != nil {
+		return err
+	}
+	key, err := hcKey32(args[3])
+	if err != nil {
+		return err
+	}
+	id, err := core.AddEncryptedHealthRecord(p, d, args[2], key[:])
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), id)
+	return nil
+}
+
+func hcGrantRecord(cmd *cobra.Command, args []string) error {
+	p, err := hcParseAddr(args[0])
+	if err != nil {
+		return err
+	}
+	g, err := hcParseAddr(args[1])
+	if err != nil {
+		return err
+	}
+	key, err := hcKey32(args[3])
+	if err != nil {
+		return err
+	}
+	var exp time.Time
+	if d, _ := cmd.Flags().GetDuration("expires"); d > 0 {
+		exp = time.Now().Add(d)
+	}
+	grant, err := core.GrantRecordAccess(p, g, args[2], key[:], exp)
+	if err != nil {
+		return err
+	}
+	return hcPrint(cmd, grant)
+}
+
+func hcRevokeRecord(cmd *cobra.Command, args []string) error {
+	p, err := hcParseAddr(args[0])
+	if err != nil {
+		return err
+	}
+	g, err := hcParseAddr(args[1])
+	if err != nil {
+		return err
+	}
+	return core.RevokeRecordAccess(p, g, args[2])
+}
+
+func hcFetchKey(cmd *cobra.Command, args []string) error {
+	p, err := hcParseAddr(args[0])
+	if err != nil {
+		return err
+	}
+	r, err := hcParseAddr(args[1])
+	if err != nil {
+		return err
+	}
+	grant, err := core.FetchRecordKey(p, r, args[2])
+	if err != nil {
+		return err
+	}
+	pubHex, _ := cmd.Flags().GetString("pub")
+	privHex, _ := cmd.Flags().GetString("priv")
+	if pubHex == "" || privHex == "" {
+		fmt.Fprintf(cmd.OutOrStdout(), "%x\n", grant.WrappedKey)
+		return nil
+	}
+	pub, err := hcKey32(pubHex)
+	if err != nil {
+		return err
+	}
+	priv, err := hcKey32(privHex)
+	if err != nil {
+		return err
+	}
+	key, err := core.OpenHealthKey(grant.WrappedKey, pub, priv)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "%x\n", key)
+	return nil
+}
+
+func hcFulfil(cmd *cobra.Command, args []string) error {
+	p, err := hcParseAddr(args[0])
+	if err != nil {
+		return err
+	}
+	g, err := hcParseAddr(args[1])
+	if err != nil {
+		return err
+	}
+	by, err := hcParseAddr(args[2])
+	if err != nil {
+		return err
+	}
+	key, err := hcKey32(args[4])
+	if err != nil {
+		return err
+	}
+	return core.FulfilHealthGrant(p, g, by, args[3], key[:])
+}
+
+func hcBreakGlass(cmd *cobra.Command, args []string) error {
+	p, err := hcParseAddr(args[0])
+	if err != nil {
+		return err
+	}
+	r, err := hcParseAddr(args[2])
+	if err != nil {
+		return err
+	}
+	ts, _ := cmd.Flags().GetInt64("timestamp")
+	specs, _ := cmd.Flags().GetStringSlice("approval")
+	var approvals []core.BreakGlassApproval
+	for _, s := range specs {
+		parts := strings.SplitN(s, ":", 2)
+		if len(parts) != 2 {
+			return fmt.Errorf("approval %q: want pubhex:sighex", s)
+		}
+		pub, err1 := hex.DecodeString(parts[0])
+		sig, err2 := hex.DecodeString(parts[1])
+		if err1 != nil || err2 != nil {
+			return fmt.Errorf("approval %q: bad hex", s)
+		}
+		approvals = append(approvals, core.BreakGlassApproval{PubKey: pub, Sig: sig})
+	}
+	grant, err := core.BreakGlassAccess(p, args[1], r, args[3], ts, approvals)
+	if err != nil {
+		return err
+	}
+	return hcPrint(cmd, grant)
+}
+
+func hcAccessLog(cmd *cobra.Command, args []string) error {
+	p, err := hcParseAddr(args[0])
+	if err != nil {
+		return err
+	}
+	evs, err := core.HealthAccessLog(p)
+	if err != nil {
+		return err
+	}
+	return hcPrint(cmd, evs)
+}
+
 // ----------------------------------------------------------------------------
 // Cobra tree
 // ----------------------------------------------------------------------------
@@ -109,10 +304,26 @@ var hcGrantCmd = &cobra.Command{Use: "grant <patient> <provider>", Short: "Grant
 var hcRevokeCmd = &cobra.Command{Use: "revoke <patient> <provider>", Short: "Revoke access", Args: cobra.ExactArgs(2), RunE: hcRevoke}
 var hcAddCmd = &cobra.Command{Use: "add <patient> <provider> <cid>", Short: "Add record", Args: cobra.ExactArgs(3), RunE: hcAddRecord}
 var hcListCmd = &cobra.Command{Use: "list <patient>", Short: "List records", Args: cobra.ExactArgs(1), RunE: hcList}
+var hcKeygenCmd = &cobra.Command{Use: "keygen", Short: "Generate an X25519 encryption key pair", Args: cobra.NoArgs, RunE: hcKeygen}
+var hcRegisterKeyCmd = &cobra.Command{Use: "register-key <addr> <x25519-pub-hex>", Short: "Register an encryption public key", Args: cobra.ExactArgs(2), RunE: hcRegisterKey}
+var hcAddEncCmd = &cobra.Command{Use: "add-encrypted <patient> <provider> <cid> <record-key-hex>", Short: "Add an encrypted record", Args: cobra.ExactArgs(4), RunE: hcAddEncrypted}
+var hcGrantRecordCmd = &cobra.Command{Use: "grant-record <patient> <grantee> <record> <record-key-hex>", Short: "Seal a record key to a grantee", Args: cobra.ExactArgs(4), RunE: hcGrantRecord}
+var hcRevokeRecordCmd = &cobra.Command{Use: "revoke-record <patient> <grantee> <record>", Short: "Revoke a record grant", Args: cobra.ExactArgs(3), RunE: hcRevokeRecord}
+var hcFetchKeyCmd = &cobra.Command{Use: "fetch-key <patient> <requester> <record>", Short: "Fetch (and optionally unseal) a record key", Args: cobra.ExactArgs(3), RunE: hcFetchKey}
+var hcFulfilCmd = &cobra.Command{Use: "fulfil <patient> <grantee> <holder> <record> <record-key-hex>", Short: "Seal the record key into a pending grant", Args: cobra.ExactArgs(5), RunE: hcFulfil}
+var hcBreakGlassCmd = &cobra.Command{Use: "break-glass <patient> <record> <requester> <reason>", Short: "Open emergency access with two authority signatures", Args: cobra.ExactArgs(4), RunE: hcBreakGlass}
+var hcAccessLogCmd = &cobra.Command{Use: "access-log <patient>", Short: "List every access event on a patient's records", Args: cobra.ExactArgs(1), RunE: hcAccessLog}
 
 func init() {
 	hcCmd.Flags().String("ledger", "", "ledger path")
-	hcCmd.AddCommand(hcRegisterCmd, hcGrantCmd, hcRevokeCmd, hcAddCmd, hcListCmd)
+	hcGrantRecordCmd.Flags().Duration("expires", 0, "grant lifetime (0 = no expiry)")
+	hcFetchKeyCmd.Flags().String("pub", "", "X25519 public key hex to unseal with")
+	hcFetchKeyCmd.Flags().String("priv", "", "X25519 private key hex to unseal with")
+	hcBreakGlassCmd.Flags().Int64("timestamp", 0, "unix timestamp the authorities signed")
+	hcBreakGlassCmd.Flags().StringSlice("approval", nil, "authority approval as ed25519 pubhex:sighex (repeatable)")
+	hcCmd.AddCommand(hcRegisterCmd, hcGrantCmd, hcRevokeCmd, hcAddCmd, hcListCmd,
+		hcKeygenCmd, hcRegisterKeyCmd, hcAddEncCmd, hcGrantRecordCmd, hcRevokeRecordCmd,
+		hcFetchKeyCmd, hcFulfilCmd, hcBreakGlassCmd, hcAccessLogCmd)
 }
 
 var HealthcareCmd = hcCmd
@@ -1,10 +1,14 @@
 package cli
 
 import (
+	"context"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -78,6 +82,68 @@ func (c *HAController) Snapshot(path string) error {
 	return haSvc.HA_Snapshot(path)
 }
 
+func (c *HAController) Status(cmd *cobra.Command) error {
+	enc := json.NewEncoder(cmd.OutOrStdout())
+	enc.SetIndent("", "  ")
+	return enc.Encode(haSvc.HA_Status())
+}
+
+// enableReplication reads the shared secret, node address and epoch file
+// flags common to stream and follow.
+func enableReplication(cmd *cobra.Command) (core.Address, error) {
+	var self core.Address
+	secret, _ := cmd.Flags().GetString("secret")
+	if secret == "" {
+		secret = os.Getenv("HA_REPL_SECRET")
+	}
+	if secret == "" {
+		return self, errors.New("--secret or HA_REPL_SECRET required")
+	}
+	if s, _ := cmd.Flags().GetString("self"); s != "" {
+		b, err := hex.DecodeString(s)
+		if err != nil || len(b) != 20 {
+			return self, fmt.Errorf("invalid --self address")
+		}
+		copy(self[:], b)
+	}
+	epochFile, _ := cmd.Flags().GetString("epoch-file")
+	return self, haSvc.EnableReplication(core.HAReplicationConfig{Self: self, Secret: []byte(secret), EpochPath: epochFile})
+}
+
+func (c *HAController) Stream(cmd *cobra.Command, listen string) error {
+	if _, err := enableReplication(cmd); err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	fmt.Fprintf(cmd.OutOrStdout(), "streaming WAL on %s\n", listen)
+	return haSvc.HA_StreamWAL(ctx, listen)
+}
+
+// Follow applies the primary's WAL until interrupted. With promote set, a
+// lost primary makes this node the primary, serving its WAL on listen.
+func (c *HAController) Follow(cmd *cobra.Command, primary string, promote bool, listen string) error {
+	self, err := enableReplication(cmd)
+	if err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	err = haSvc.HA_Follow(ctx, primary)
+	if err == nil || !promote {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "%v; promoting\n", err)
+	if err := haSvc.HA_Promote(self); err != nil {
+		return err
+	}
+	if listen == "" {
+		return nil
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "streaming WAL on %s\n", listen)
+	return haSvc.HA_StreamWAL(ctx, listen)
+}
+
 var haCmd = &cobra.Command{
 	Use:               "high_availability",
 	Short:             "High availability utilities",
@@ -108,8 +174,29 @@ var haSnapshotCmd = &cobra.Command{Use: "snapshot [path]", Args: cobra.MaximumNA
 	return (&HAController{}).Snapshot(path)
 }}
 
+var haStatusCmd = &cobra.Command{Use: "status", Args: cobra.NoArgs, RunE: func(cmd *cobra.Command, args []string) error {
+	return (&HAController{}).Status(cmd)
+}}
+
+var haStreamCmd = &cobra.Command{Use: "stream <listen-addr>", Short: "Stream WAL records to standbys", Args: cobra.ExactArgs(1), RunE: func(cmd *cobra.Command, args []string) error {
+	return (&HAController{}).Stream(cmd, args[0])
+}}
+
+var haFollowCmd = &cobra.Command{Use: "follow <primary-addr>", Short: "Apply a primary's WAL stream as a hot standby", Args: cobra.ExactArgs(1), RunE: func(cmd *cobra.Command, args []string) error {
+	promote, _ := cmd.Flags().GetBool("promote-on-loss")
+	listen, _ := cmd.Flags().GetString("listen")
+	return (&HAController{}).Follow(cmd, args[0], promote, listen)
+}}
+
 func init() {
-	haCmd.AddCommand(haAddCmd, haRemoveCmd, haListCmd, haPromoteCmd, haSnapshotCmd)
+	for _, c := range []*cobra.Command{haStreamCmd, haFollowCmd} {
+		c.Flags().String("secret", "", "shared replication secret (default $HA_REPL_SECRET)")
+		c.Flags().String("epoch-file", "", "file persisting the fencing epoch")
+		c.Flags().String("self", "", "this node's address [hex]")
+	}
+	haFollowCmd.Flags().Bool("promote-on-loss", false, "promote this standby when the primary is lost")
+	haFollowCmd.Flags().String("listen", "", "address to stream WAL on after promotion")
+	haCmd.AddCommand(haAddCmd, haRemoveCmd, haListCmd, haPromoteCmd, haSnapshotCmd, haStatusCmd, haStreamCmd, haFollowCmd)
 }
 
 var HACmd = haCmd
@@ -1,11 +1,9 @@
 package cli
 
 import (
-	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"os"
-	"strings"
 	"sync"
 	"time"
 
@@ -41,13 +39,7 @@ func itInit(cmd *cobra.Command, _ []string) error {
 }
 
 func itParseAddr(s string) (core.Address, error) {
-	var a core.Address
-	b, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
-	if err != nil || len(b) != len(a) {
-		return a, fmt.Errorf("bad address")
-	}
-	copy(a[:], b)
-	return a, nil
+	return core.DecodeAddress(s)
 }
 
 func itHandleRegister(cmd *cobra.Command, args []string) error {
@@ -1,11 +1,9 @@
 package cli
 
 import (
-	"encoding/hex"
 	"errors"
 	"fmt"
 	"os"
-	"strings"
 	"sync"
 
 	"github.com/joho/godotenv"
@@ -41,13 +39,7 @@ func idMiddleware(cmd *cobra.Command, _ []string) error {
 }
 
 func idParseAddr(h string) (core.Address, error) {
-	var a core.Address
-	b, err := hex.DecodeString(strings.TrimPrefix(h, "0x"))
-	if err != nil || len(b) != len(a) {
-		return a, fmt.Errorf("bad address")
-	}
-	copy(a[:], b)
-	return a, nil
+	return core.DecodeAddress(h)
 }
 
 var idwalletCmd = &cobra.Command{
@@ -88,6 +88,8 @@ func RegisterRoutes(root *cobra.Command) {
 		IDWalletCmd,
 		OffWalletCmd,
 		RecoveryCmd,
+		SocialRecoveryCmd,
+		SessionKeysCmd,
 		WalletMgmtCmd,
 		AICmd,
 		AIContractCmd,
@@ -123,6 +125,15 @@ func RegisterRoutes(root *cobra.Command) {
 		AuditCmd,
 		AuditNodeCmd,
 		ComplianceMgmtCmd,
+		SanctionsCmd,
+		SnsCmd,
+		AirdropCmd,
+		StreamCmd,
+		InvoiceCmd,
+		ExchangeCmd,
+		FeesCmd,
+		WatchCmd,
+		GDPRCmd,
 		CrossChainCmd,
 		CCSNCmd,
 		XContractCmd,
@@ -139,6 +150,7 @@ func RegisterRoutes(root *cobra.Command) {
 		ResourceCmd,
 		PartitionCmd,
 		DistributionCmd,
+		DataMarketCmd,
 		OracleMgmtCmd,
 		DataOpsCmd,
 		HACmd,
@@ -206,6 +218,9 @@ func RegisterRoutes(root *cobra.Command) {
 		ForensicCmd,
 		EnvironmentalNodeCmd,
 		WitnessCmd,
+		DoctorCmd,
+		LogLevelCmd,
+		DebugCmd,
 	)
 
 	// modules that expose constructors
@@ -213,6 +228,7 @@ func RegisterRoutes(root *cobra.Command) {
 		NewFaultToleranceCommand(),
 		NewFailoverCommand(),
 		NewDisasterRecoveryCommand(),
+		NewBackupCommand(),
 		NewGovernanceCommand(),
 		NewTokenVoteCommand(),
 		NewSYN300Command(),
@@ -1,7 +1,6 @@
 package cli
 
 import (
-	"encoding/hex"
 	"fmt"
 	"github.com/spf13/cobra"
 	core "synnergy-network/core"
@@ -13,13 +12,7 @@ var (
 )
 
 func itParseAddr(s string) (core.Address, error) {
-	b, err := hex.DecodeString(s)
-	if err != nil || len(b) != 20 {
-		return core.AddressZero, fmt.Errorf("bad address")
-	}
-	var a core.Address
-	copy(a[:], b)
-	return a, nil
+	return core.DecodeAddress(s)
 }
 
 func itInit(_ *cobra.Command, _ []string) error {
@@ -0,0 +1,161 @@
+package cli
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	core "synnergy-network/core"
+)
+
+func invoicePrint(inv *core.Invoice) {
+	out, _ := json.MarshalIndent(inv, "", "  ")
+	fmt.Println(string(out))
+}
+
+// registrationSig signs td with --key or decodes --sig. With --typed-data
+// it prints td for signing elsewhere and returns a nil signature.
+func registrationSig(cmd *cobra.Command, td core.TypedData) ([]byte, error) {
+	if show, _ := cmd.Flags().GetBool("typed-data"); show {
+		out, _ := json.MarshalIndent(td, "", "  ")
+		fmt.Fprintln(cmd.OutOrStdout(), string(out))
+		return nil, nil
+	}
+	if key, _ := cmd.Flags().GetString("key"); key != "" {
+		priv, err := hex.DecodeString(strings.TrimPrefix(key, "0x"))
+		if err != nil {
+			return nil, fmt.Errorf("bad key: %w", err)
+		}
+		defer core.Wipe(priv)
+		s, err := core.SignTypedData(priv, td)
+		if err != nil {
+			return nil, err
+		}
+		sig, _ := hex.DecodeString(s.Sig)
+		return sig, nil
+	}
+	if s, _ := cmd.Flags().GetString("sig"); s != "" {
+		sig, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+		if err != nil {
+			return nil, fmt.Errorf("bad sig: %w", err)
+		}
+		return sig, nil
+	}
+	return nil, errors.New("--key, --sig or --typed-data required")
+}
+
+func registrationFlags(cmd *cobra.Command, signer string) {
+	cmd.Flags().String("key", "", signer+" Ed25519 private key (hex) to sign the request")
+	cmd.Flags().String("sig", "", "request signature over the --typed-data document (hex)")
+	cmd.Flags().Bool("typed-data", false, "print the typed data to sign instead of creating")
+}
+
+var invoiceCmd = &cobra.Command{
+	Use:   "invoice",
+	Short: "Merchant invoices with payment URIs and payment webhooks",
+}
+
+var invoiceCreateCmd = &cobra.Command{
+	Use:   "create <merchant> <amount>",
+	Short: "Create an invoice signed by the merchant and print its payment URI",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		merchant, err := core.DecodeAddress(args[0])
+		if err != nil {
+			return err
+		}
+		amt, err := strconv.ParseUint(args[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid amount: %w", err)
+		}
+		req := core.InvoiceRequest{Merchant: merchant, Amount: amt}
+		if s, _ := cmd.Flags().GetString("pay-to"); s != "" {
+			if req.PayTo, err = core.DecodeAddress(s); err != nil {
+				return err
+			}
+		}
+		token, _ := cmd.Flags().GetUint32("token")
+		req.Token = core.TokenID(token)
+		req.Memo, _ = cmd.Flags().GetString("memo")
+		req.TTL, _ = cmd.Flags().GetDuration("expires")
+		req.Confirmations, _ = cmd.Flags().GetUint64("confirmations")
+		req.Webhook, _ = cmd.Flags().GetString("webhook")
+		req.Secret, _ = cmd.Flags().GetString("secret")
+		req.Nonce = core.RegistrationNonce(merchant)
+		sig, err := registrationSig(cmd, core.InvoiceRequestTypedData(req))
+		if err != nil || sig == nil {
+			return err
+		}
+		inv, err := core.CreateInvoice(req, sig)
+		if err != nil {
+			return err
+		}
+		invoicePrint(inv)
+		return nil
+	},
+}
+
+var invoiceInfoCmd = &cobra.Command{
+	Use:   "info <id>",
+	Short: "Show an invoice and its payment status",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		inv, err := core.GetInvoice(args[0])
+		if err != nil {
+			return err
+		}
+		invoicePrint(inv)
+		return nil
+	},
+}
+
+var invoiceListCmd = &cobra.Command{
+	Use:   "list [merchant]",
+	Short: "List the invoices of a merchant, or all invoices",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		merchant := core.AddressZero
+		if len(args) == 1 {
+			a, err := core.DecodeAddress(args[0])
+			if err != nil {
+				return err
+			}
+			merchant = a
+		}
+		list, err := core.ListInvoices(merchant)
+		if err != nil {
+			return err
+		}
+		for _, inv := range list {
+			fmt.Printf("%s %s %d token %d %s expires %s\n", inv.ID, inv.PayTo.Hex(), inv.Amount, inv.Token,
+				inv.Status, inv.Expires.Format(time.RFC3339))
+		}
+		return nil
+	},
+}
+
+func init() {
+	invoiceCreateCmd.Flags().String("pay-to", "", "address to pay (default: the merchant)")
+	invoiceCreateCmd.Flags().Uint32("token", 0, "token ID to be paid in (default: the coin)")
+	invoiceCreateCmd.Flags().String("memo", "", "memo hashed into the payment reference")
+	invoiceCreateCmd.Flags().Duration("expires", core.InvoiceDefaultTTL, "validity of the invoice")
+	invoiceCreateCmd.Flags().Uint64("confirmations", core.InvoiceDefaultConfirmations, "blocks before a payment counts as paid")
+	invoiceCreateCmd.Flags().String("webhook", "", "URL notified of payment events")
+	invoiceCreateCmd.Flags().String("secret", "", "HMAC key signing webhook payloads")
+	registrationFlags(invoiceCreateCmd, "merchant")
+
+	invoiceCmd.AddCommand(
+		invoiceCreateCmd,
+		invoiceInfoCmd,
+		invoiceListCmd,
+	)
+}
+
+// InvoiceCmd is exported for index.go
+var InvoiceCmd = invoiceCmd
@@ -10,13 +10,20 @@
 //   synnergy ~ledger head                          # height + last block hash
 //   synnergy ~ledger block 123 --format=json       # inspect block
 //   synnergy ~ledger balance 0xabc…                # token balances
-//   synnergy ~ledger utxo 0xabc… --limit=20
+//   synnergy ~ledger utxo 0xabc… --limit=20 --cursor=<next>
 //   synnergy ~ledger pool --limit=10 --format=json # mem‑pool slice
 //   synnergy ~ledger mint 0xabc… --token=SYNR --amount=1000
 //   synnergy ~ledger transfer 0xabc… 0xdef… --token=SYNR --amount=250
+//   synnergy ~ledger export-snapshot --out snap.tar.gz  # portable snapshot
+//   synnergy ~ledger import-snapshot snap.tar.gz --state-root=<hex> --dir=./data
+//   synnergy ~ledger reindex --indexes=txs,holders --batch=1000  # rebuild indexes
+//   synnergy ~ledger restore --from s3://ledger-backups/node1 --verify  # DR drill
+//   synnergy ~ledger restore --from /mnt/backups --seq=12 --dir=./data
 // -----------------------------------------------------------------------------
 // Environment
 //   LEDGER_API_ADDR – host:port of ledger daemon (default "127.0.0.1:7900")
+//   LEDGER_PATH     – ledger directory read by export-snapshot and reindex
+//   BACKUP_DEST     – default backup location for restore
 // -----------------------------------------------------------------------------
 
 package cli
@@ -31,6 +38,7 @@ import (
 	"net"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -93,15 +101,15 @@ func headRPC(ctx context.Context) (height uint64, hash string, err error) {
 		return
 	}
 	var resp struct {
-		Height uint64 `json:"height"`
-		Hash   string `json:"hash"`
-		Error  string `json:"error,omitempty"`
+		Height uint64         `json:"height"`
+		Hash   string         `json:"hash"`
+		Error  core.WireError `json:"error,omitempty"`
 	}
 	if err = cli.readJSON(&resp); err != nil {
 		return
 	}
-	if resp.Error != "" {
-		err = errors.New(resp.Error)
+	if resp.Error.Err() != nil {
+		err = resp.Error.Err()
 		return
 	}
 	height, hash = resp.Height, resp.Hash
@@ -118,14 +126,14 @@ func blockRPC(ctx context.Context, h uint64) (*core.Block, error) {
 		return nil, err
 	}
 	var resp struct {
-		Block core.Block `json:"block"`
-		Error string     `json:"error,omitempty"`
+		Block core.Block     `json:"block"`
+		Error core.WireError `json:"error,omitempty"`
 	}
 	if err := cli.readJSON(&resp); err != nil {
 		return nil, err
 	}
-	if resp.Error != "" {
-		return nil, errors.New(resp.Error)
+	if resp.Error.Err() != nil {
+		return nil, resp.Error.Err()
 	}
 	return &resp.Block, nil
 }
@@ -141,37 +149,40 @@ func balanceRPC(ctx context.Context, addr string) (map[string]uint64, error) {
 	}
 	var resp struct {
 		Bal   map[string]uint64 `json:"bal"`
-		Error string            `json:"error,omitempty"`
+		Error core.WireError    `json:"error,omitempty"`
 	}
 	if err := cli.readJSON(&resp); err != nil {
 		return nil, err
 	}
-	if resp.Error != "" {
-		return nil, errors.New(resp.Error)
+	if resp.Error.Err() != nil {
+		return nil, resp.Error.Err()
 	}
 	return resp.Bal, nil
 }
 
-func utxoRPC(ctx context.Context, addr string, limit int) ([]core.UTXO, error) {
+// utxoRPC fetches one page of an address's UTXOs; the returned cursor is
+// passed back to fetch the next page and is empty after the last one.
+func utxoRPC(ctx context.Context, addr, cursor string, limit int) ([]core.UTXO, string, error) {
 	cli, err := newLedgerClient(ctx)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	defer cli.Close()
-	if err := cli.writeJSON(map[string]any{"action": "utxo", "addr": addr, "limit": limit}); err != nil {
-		return nil, err
+	if err := cli.writeJSON(map[string]any{"action": "utxo", "addr": addr, "cursor": cursor, "limit": limit}); err != nil {
+		return nil, "", err
 	}
 	var resp struct {
-		List  []core.UTXO `json:"list"`
-		Error string      `json:"error,omitempty"`
+		List  []core.UTXO    `json:"list"`
+		Next  string         `json:"next,omitempty"`
+		Error core.WireError `json:"error,omitempty"`
 	}
 	if err := cli.readJSON(&resp); err != nil {
-		return nil, err
+		return nil, "", err
 	}
-	if resp.Error != "" {
-		return nil, errors.New(resp.Error)
+	if resp.Error.Err() != nil {
+		return nil, "", resp.Error.Err()
 	}
-	return resp.List, nil
+	return resp.List, resp.Next, nil
 }
 
 func poolRPC(ctx context.Context, limit int) ([]core.Transaction, error) {
@@ -185,13 +196,13 @@ func poolRPC(ctx context.Context, limit int) ([]core.Transaction, error) {
 	}
 	var resp struct {
 		List  []core.Transaction `json:"list"`
-		Error string             `json:"error,omitempty"`
+		Error core.WireError     `json:"error,omitempty"`
 	}
 	if err := cli.readJSON(&resp); err != nil {
 		return nil, err
 	}
-	if resp.Error != "" {
-		return nil, errors.New(resp.Error)
+	if resp.Error.Err() != nil {
+		return nil, resp.Error.Err()
 	}
 	return resp.List, nil
 }
@@ -303,9 +314,10 @@ var utxoCmd = &cobra.Command{
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		limit, _ := cmd.Flags().GetInt("limit")
+		cursor, _ := cmd.Flags().GetString("cursor")
 		ctx, cancel := context.WithTimeout(cmd.Context(), 3*time.Second)
 		defer cancel()
-		list, err := utxoRPC(ctx, args[0], limit)
+		list, next, err := utxoRPC(ctx, args[0], cursor, limit)
 		if err != nil {
 			return err
 		}
@@ -313,6 +325,9 @@ var utxoCmd = &cobra.Command{
 			txid := hex.EncodeToString(u.TxID[:])
 			fmt.Printf("%s:%d  value=%d\n", txid, u.Index, u.Output.Amount)
 		}
+		if next != "" {
+			fmt.Printf("next cursor: %s\n", next)
+		}
 		return nil
 	},
 }
@@ -386,6 +401,170 @@ var transferCmd = &cobra.Command{
 	},
 }
 
+// export-snapshot -------------------------------------------------------------
+var exportSnapshotCmd = &cobra.Command{
+	Use:   "export-snapshot",
+	Short: "Write a portable, checksummed snapshot archive of the local ledger",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, _ := cmd.Flags().GetString("ledger")
+		if dir == "" {
+			dir = os.Getenv("LEDGER_PATH")
+		}
+		if dir == "" {
+			return errors.New("--ledger or LEDGER_PATH required")
+		}
+		out, _ := cmd.Flags().GetString("out")
+		height, _ := cmd.Flags().GetUint64("height")
+		headers, _ := cmd.Flags().GetInt("headers")
+		led, err := core.OpenLedger(dir)
+		if err != nil {
+			return err
+		}
+		man, sum, err := led.ExportSnapshotFile(out, height, headers)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("height:     %d\nblock:      %s\nstate root: %s\nheaders:    %d\nsha256:     %s\n",
+			man.Height, man.BlockHash, man.StateRoot, man.Headers, sum)
+		return nil
+	},
+}
+
+// import-snapshot -------------------------------------------------------------
+var importSnapshotCmd = &cobra.Command{
+	Use:   "import-snapshot [archive]",
+	Short: "Verify a snapshot archive against a trusted state root and bootstrap a ledger directory",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		rootHex, _ := cmd.Flags().GetString("state-root")
+		dir, _ := cmd.Flags().GetString("dir")
+		if dir == "" {
+			return errors.New("--dir required")
+		}
+		if rootHex == "" {
+			man, err := core.ReadSnapshotManifest(args[0])
+			if err != nil {
+				return err
+			}
+			return fmt.Errorf("--state-root required; archive claims %s at height %d", man.StateRoot, man.Height)
+		}
+		raw, err := hex.DecodeString(strings.TrimPrefix(rootHex, "0x"))
+		if err != nil || len(raw) != 32 {
+			return errors.New("--state-root must be 32 hex bytes")
+		}
+		var root core.Hash
+		copy(root[:], raw)
+		man, err := core.ImportSnapshot(args[0], dir, root)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("imported height %d (block %s) into %s\n", man.Height, man.BlockHash, dir)
+		return nil
+	},
+}
+
+// reindex ---------------------------------------------------------------------
+var reindexCmd = &cobra.Command{
+	Use:   "reindex",
+	Short: "Rebuild derived indexes (txs, logs, holders, analytics) from the canonical chain",
+	Long: "Replays the retained block history of a stopped node's ledger directory to rebuild\n" +
+		"the selected indexes. Each batch is committed with a cursor; --resume continues an\n" +
+		"interrupted run.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, _ := cmd.Flags().GetString("ledger")
+		if dir == "" {
+			dir = os.Getenv("LEDGER_PATH")
+		}
+		if dir == "" {
+			return errors.New("--ledger or LEDGER_PATH required")
+		}
+		indexes, _ := cmd.Flags().GetStringSlice("indexes")
+		batch, _ := cmd.Flags().GetInt("batch")
+		resume, _ := cmd.Flags().GetBool("resume")
+		led, err := core.OpenLedger(dir)
+		if err != nil {
+			return err
+		}
+		defer led.Close()
+		start := time.Now()
+		err = led.Reindex(core.ReindexOptions{
+			Indexes: indexes,
+			Batch:   batch,
+			Resume:  resume,
+			Progress: func(p core.ReindexProgress) {
+				fmt.Printf("%-9s %d/%d (%d entries)\n", p.Index, p.Cursor, p.Target, p.Entries)
+			},
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("reindex complete in %s\n", time.Since(start).Round(time.Millisecond))
+		return nil
+	},
+}
+
+// restore ---------------------------------------------------------------------
+var ledgerRestoreCmd = &cobra.Command{
+	Use:   "restore",
+	Short: "Restore a ledger from backup; --verify runs a disaster-recovery drill",
+	Long: "Restores the backup chain ending at --seq into --dir. With --verify the chain is\n" +
+		"restored into a temporary directory (or --dir), replayed to the tip and every\n" +
+		"recomputed state root is compared with the root recorded at backup time. The\n" +
+		"DR report is printed and the command fails if any check fails.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		from, _ := cmd.Flags().GetString("from")
+		seq, _ := cmd.Flags().GetUint64("seq")
+		dir, _ := cmd.Flags().GetString("dir")
+		verify, _ := cmd.Flags().GetBool("verify")
+		store, err := openBackupStore(from)
+		if err != nil {
+			return err
+		}
+		if !verify {
+			if dir == "" {
+				return errors.New("--dir required without --verify")
+			}
+			h, err := core.RestoreBackup(cmd.Context(), store, seq, dir)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("restored %s to height %d\n", dir, h)
+			return nil
+		}
+		rep, err := core.RestoreDrill(cmd.Context(), store, seq, dir)
+		if err != nil {
+			return err
+		}
+		if keep, _ := cmd.Flags().GetBool("keep"); !keep && dir == "" {
+			defer os.RemoveAll(rep.Dir)
+		}
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(rep); err != nil {
+				return err
+			}
+		} else {
+			fmt.Printf("DR drill: backup %d restored into %s in %s\n", rep.Seq, rep.Dir, rep.Duration.Round(time.Millisecond))
+			for _, c := range rep.Checks {
+				status := "PASS"
+				if !c.OK {
+					status = "FAIL"
+				}
+				fmt.Printf("  %-4s %-32s %s\n", status, c.Name, c.Detail)
+			}
+		}
+		if !rep.Passed {
+			return fmt.Errorf("DR drill failed for backup %d", rep.Seq)
+		}
+		fmt.Printf("DR drill passed: height %d state root %s\n", rep.Height, rep.StateRoot)
+		return nil
+	},
+}
+
 // -----------------------------------------------------------------------------
 // init – config + route wiring
 // -----------------------------------------------------------------------------
@@ -413,7 +592,8 @@ func init() {
 	blockCmd.Flags().StringP("format", "f", "table", "output format: table|json")
 	_ = viper.BindPFlag("output.format", blockCmd.Flags().Lookup("format"))
 
-	utxoCmd.Flags().Int("limit", 0, "max entries (0=all)")
+	utxoCmd.Flags().Int("limit", 0, "page size (0=default 100, max 1000)")
+	utxoCmd.Flags().String("cursor", "", "resume after this cursor from a previous page")
 	ledgerPoolCmd.Flags().Int("limit", 0, "max transactions (0=all)")
 	ledgerPoolCmd.Flags().StringP("format", "f", "table", "output format: table|json")
 	_ = viper.BindPFlag("output.format", ledgerPoolCmd.Flags().Lookup("format"))
@@ -424,6 +604,26 @@ func init() {
 	transferCmd.Flags().String("token", "", "token symbol or ID")
 	transferCmd.Flags().String("amount", "", "amount to transfer")
 
+	exportSnapshotCmd.Flags().String("ledger", "", "ledger directory (default $LEDGER_PATH)")
+	exportSnapshotCmd.Flags().String("out", "snapshot.tar.gz", "archive path")
+	exportSnapshotCmd.Flags().Uint64("height", 0, "snapshot height (0 = head; state exists only at the head)")
+	exportSnapshotCmd.Flags().Int("headers", core.DefaultSnapshotHeaders, "recent block headers to include")
+
+	importSnapshotCmd.Flags().String("state-root", "", "trusted state root [hex]")
+	importSnapshotCmd.Flags().String("dir", "", "new ledger directory")
+
+	reindexCmd.Flags().String("ledger", "", "ledger directory (default $LEDGER_PATH)")
+	reindexCmd.Flags().StringSlice("indexes", nil, "indexes to rebuild: "+strings.Join(core.ReindexNames(), ",")+" (default all)")
+	reindexCmd.Flags().Int("batch", core.DefaultReindexBatch, "blocks or events committed per batch")
+	reindexCmd.Flags().Bool("resume", false, "continue an interrupted run from its cursor")
+
+	ledgerRestoreCmd.Flags().String("from", "", "backup location: directory or s3://bucket/prefix (default $BACKUP_DEST)")
+	ledgerRestoreCmd.Flags().Uint64("seq", 0, "backup to restore up to (default newest)")
+	ledgerRestoreCmd.Flags().String("dir", "", "ledger directory to restore into (--verify: default a temporary directory)")
+	ledgerRestoreCmd.Flags().Bool("verify", false, "run a restore drill and compare state roots with the recorded ones")
+	ledgerRestoreCmd.Flags().Bool("keep", false, "keep the temporary drill directory")
+	ledgerRestoreCmd.Flags().Bool("json", false, "print the DR report as JSON")
+
 	// wire routes
 	ledgerCmd.AddCommand(headCmd)
 	ledgerCmd.AddCommand(blockCmd)
@@ -432,6 +632,10 @@ func init() {
 	ledgerCmd.AddCommand(ledgerPoolCmd)
 	ledgerCmd.AddCommand(mintCmd)
 	ledgerCmd.AddCommand(transferCmd)
+	ledgerCmd.AddCommand(exportSnapshotCmd)
+	ledgerCmd.AddCommand(importSnapshotCmd)
+	ledgerCmd.AddCommand(reindexCmd)
+	ledgerCmd.AddCommand(ledgerRestoreCmd)
 }
 
 // NewLedgerCommand exposes the consolidated command tree.
@@ -35,13 +35,7 @@ func lnMiddleware(cmd *cobra.Command, _ []string) error {
 }
 
 func parseAddr(hexStr string) (core.Address, error) {
-	b, err := hex.DecodeString(hexStr)
-	var a core.Address
-	if err != nil || len(b) != len(a) {
-		return a, errors.New("address hex invalid")
-	}
-	copy(a[:], b)
-	return a, nil
+	return core.DecodeAddress(hexStr)
 }
 
 func lnOpen(cmd *cobra.Command, args []string) error {
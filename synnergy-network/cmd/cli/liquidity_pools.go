@@ -1,11 +1,9 @@
 package cli
 
 import (
-	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"strconv"
-	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -26,8 +24,8 @@ func lpEnsureInit(cmd *cobra.Command, _ []string) error {
 
 type lpController struct{}
 
-func (lpController) Create(a, b core.TokenID, fee uint16) (core.PoolID, error) {
-	return core.Manager().CreatePool(a, b, fee)
+func (lpController) Create(a, b core.TokenID, fee uint16, spec core.PoolSpec) (core.PoolID, error) {
+	return core.Manager().CreatePool(a, b, fee, spec)
 }
 
 func (lpController) Add(pid core.PoolID, provider core.Address, aAmt, bAmt uint64) (uint64, error) {
@@ -42,16 +40,15 @@ func (lpController) Remove(pid core.PoolID, provider core.Address, lp uint64) (u
 	return core.Manager().RemoveLiquidity(pid, provider, lp)
 }
 
+func (lpController) RemoveImbalance(pid core.PoolID, provider core.Address, aAmt, bAmt, maxBurn uint64) (uint64, error) {
+	return core.Manager().RemoveLiquidityImbalance(pid, provider, aAmt, bAmt, maxBurn)
+}
+
 func (lpController) Pool(pid core.PoolID) (*core.Pool, error) { return core.Manager().Pool(pid) }
 func (lpController) Pools() []*core.Pool                      { return core.Manager().Pools() }
 
 func mustAddr(hexStr string) core.Address {
-	var a core.Address
-	b, err := hex.DecodeString(strings.TrimPrefix(hexStr, "0x"))
-	if err != nil || len(b) != len(a) {
-		return a
-	}
-	copy(a[:], b)
+	a, _ := core.DecodeAddress(hexStr)
 	return a
 }
 
@@ -80,7 +77,13 @@ var poolCreateCmd = &cobra.Command{
 			}
 			fee = uint16(f)
 		}
-		pid, err := ctl.Create(tA, tB, fee)
+		typ, _ := cmd.Flags().GetString("type")
+		kind, err := core.ParsePoolType(typ)
+		if err != nil {
+			return err
+		}
+		amp, _ := cmd.Flags().GetUint64("amp")
+		pid, err := ctl.Create(tA, tB, fee, core.PoolSpec{Type: kind, Amp: amp})
 		if err != nil {
 			return err
 		}
@@ -177,6 +180,39 @@ var poolRemoveCmd = &cobra.Command{
 	},
 }
 
+var poolRemoveImbalanceCmd = &cobra.Command{
+	Use:   "remove-imbalance <poolID> <provider> <amtA> <amtB> <maxLP>",
+	Short: "Withdraw exact amounts from a stable-swap pool",
+	Args:  cobra.ExactArgs(5),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctl := lpController{}
+		pidInt, err := strconv.ParseUint(args[0], 10, 32)
+		if err != nil {
+			return err
+		}
+		pid := core.PoolID(pidInt)
+		provider := mustAddr(args[1])
+		aAmt, err := strconv.ParseUint(args[2], 10, 64)
+		if err != nil {
+			return err
+		}
+		bAmt, err := strconv.ParseUint(args[3], 10, 64)
+		if err != nil {
+			return err
+		}
+		maxBurn, err := strconv.ParseUint(args[4], 10, 64)
+		if err != nil {
+			return err
+		}
+		burned, err := ctl.RemoveImbalance(pid, provider, aAmt, bAmt, maxBurn)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "%d\n", burned)
+		return nil
+	},
+}
+
 var poolInfoCmd = &cobra.Command{
 	Use:   "info <poolID>",
 	Short: "Show pool state",
@@ -212,7 +248,9 @@ var poolListCmd = &cobra.Command{
 }
 
 func init() {
-	poolsCmd.AddCommand(poolCreateCmd, poolAddCmd, poolSwapCmd, poolRemoveCmd, poolInfoCmd, poolListCmd)
+	poolCreateCmd.Flags().String("type", "constant_product", "pool type: constant_product or stable_swap")
+	poolCreateCmd.Flags().Uint64("amp", 0, "stable-swap amplification (default 100)")
+	poolsCmd.AddCommand(poolCreateCmd, poolAddCmd, poolSwapCmd, poolRemoveCmd, poolRemoveImbalanceCmd, poolInfoCmd, poolListCmd)
 }
 
 var PoolsCmd = poolsCmd
@@ -4,8 +4,11 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log"
+	"math"
 	"os"
+	"sort"
 	"strconv"
 	"time"
 
@@ -192,8 +195,49 @@ var lpExtendCmd = &cobra.Command{Use: "extend <creator> <id> <hrs>", Args: cobra
 	return ctrl.Extend(args[0], h, hrs)
 }}
 
+var lpReportCmd = &cobra.Command{Use: "report [--from <time>] [--to <time>]", Args: cobra.NoArgs, RunE: func(cmd *cobra.Command, args []string) error {
+	from, to := int64(0), int64(math.MaxInt64)
+	for name, dst := range map[string]*int64{"from": &from, "to": &to} {
+		if v, _ := cmd.Flags().GetString(name); v != "" {
+			n, err := core.ParseTreasuryTime(v)
+			if err != nil {
+				return err
+			}
+			*dst = n
+		}
+	}
+	rep, err := core.GetTreasuryFlowReport(core.CurrentLedger(), from, to)
+	if err != nil {
+		return err
+	}
+	if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		return enc.Encode(rep)
+	}
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "opening balance  %d\n", rep.Opening)
+	cats := make([]string, 0, len(rep.Categories))
+	for c := range rep.Categories {
+		cats = append(cats, string(c))
+	}
+	sort.Strings(cats)
+	for _, c := range cats {
+		sign := "-"
+		if core.TreasuryCategory(c).Inflow() {
+			sign = "+"
+		}
+		fmt.Fprintf(out, "  %s %-15s %d\n", sign, c, rep.Categories[core.TreasuryCategory(c)])
+	}
+	fmt.Fprintf(out, "inflows          %d\noutflows         %d\nclosing balance  %d\n", rep.Inflows, rep.Outflows, rep.Closing)
+	return nil
+}}
+
 func init() {
 	lpVoteCmd.Flags().Bool("approve", true, "approve or reject")
+	lpReportCmd.Flags().String("from", "", "start of the period (unix seconds, RFC 3339 or YYYY-MM-DD)")
+	lpReportCmd.Flags().String("to", "", "end of the period (unix seconds, RFC 3339 or YYYY-MM-DD)")
+	lpReportCmd.Flags().Bool("json", false, "print the report as JSON")
 	loanCmd.AddCommand(
 		lpSubmitCmd,
 		lpVoteCmd,
@@ -203,6 +247,7 @@ func init() {
 		lpListCmd,
 		lpCancelCmd,
 		lpExtendCmd,
+		lpReportCmd,
 	)
 }
 
@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	core "synnergy-network/core"
+)
+
+// logLevelDo calls the /log/levels endpoint of a running API node and
+// decodes the resulting levels.
+func logLevelDo(cmd *cobra.Command, method string, body interface{}) (*core.LogLevels, error) {
+	api, _ := cmd.Flags().GetString("api")
+	var rd io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		rd = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(cmd.Context(), method, strings.TrimSuffix(api, "/")+"/log/levels", rd)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if tok := os.Getenv("LOG_ADMIN_TOKEN"); tok != "" {
+		req.Header.Set("Authorization", "Bearer "+tok)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		return nil, core.DecodeErrorBody(resp.StatusCode, data)
+	}
+	var lv core.LogLevels
+	if err := json.Unmarshal(data, &lv); err != nil {
+		return nil, err
+	}
+	return &lv, nil
+}
+
+func logLevelPrint(cmd *cobra.Command, lv *core.LogLevels) error {
+	mods := make([]string, 0, len(lv.Modules))
+	for m := range lv.Modules {
+		mods = append(mods, m)
+	}
+	sort.Strings(mods)
+	tw := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "MODULE\tLEVEL\n(default)\t%s\n", lv.Default)
+	for _, m := range mods {
+		fmt.Fprintf(tw, "%s\t%s\n", m, lv.Modules[m])
+	}
+	return tw.Flush()
+}
+
+func logLevelList(cmd *cobra.Command, _ []string) error {
+	lv, err := logLevelDo(cmd, http.MethodGet, nil)
+	if err != nil {
+		return err
+	}
+	return logLevelPrint(cmd, lv)
+}
+
+func logLevelSet(cmd *cobra.Command, args []string) error {
+	module := args[0]
+	if module == "default" {
+		module = ""
+	}
+	lv, err := logLevelDo(cmd, http.MethodPut, core.SetLogLevelRequest{Module: module, Level: args[1]})
+	if err != nil {
+		return err
+	}
+	return logLevelPrint(cmd, lv)
+}
+
+var logLevelCmd = &cobra.Command{Use: "log-level", Short: "Inspect and change module log levels of a running node"}
+var logLevelListCmd = &cobra.Command{Use: "list", Short: "Show the default and per-module log levels", Args: cobra.NoArgs, RunE: logLevelList}
+var logLevelSetCmd = &cobra.Command{Use: "set <module|default> <level>", Short: "Set the level of one module, or the default", Args: cobra.ExactArgs(2), RunE: logLevelSet}
+
+func init() {
+	logLevelCmd.PersistentFlags().String("api", "http://127.0.0.1:8080", "API node base URL")
+	logLevelCmd.AddCommand(logLevelListCmd, logLevelSetCmd)
+}
+
+var LogLevelCmd = logLevelCmd
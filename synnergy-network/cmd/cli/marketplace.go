@@ -1,24 +1,18 @@
 package cli
 
 import (
-	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"synnergy-network/core"
 )
 
 func mpParseAddr(hexStr string) (core.Address, error) {
-	var a core.Address
-	b, err := hex.DecodeString(strings.TrimPrefix(hexStr, "0x"))
-	if err != nil || len(b) != len(a) {
-		return a, fmt.Errorf("invalid address")
-	}
-	copy(a[:], b)
-	return a, nil
+	return core.DecodeAddress(hexStr)
 }
 
 var marketCmd = &cobra.Command{
@@ -40,6 +34,33 @@ var mpListCreateCmd = &cobra.Command{
 			return fmt.Errorf("invalid meta JSON: %w", err)
 		}
 		listing := &core.MarketListing{Seller: core.ModuleAddress("cli"), Price: price, Meta: meta}
+		if s, _ := cmd.Flags().GetString("seller"); s != "" {
+			if listing.Seller, err = mpParseAddr(s); err != nil {
+				return err
+			}
+		}
+		listing.Kind, _ = cmd.Flags().GetString("kind")
+		if d, _ := cmd.Flags().GetDuration("duration"); d > 0 {
+			listing.EndsAt = time.Now().UTC().Add(d)
+		}
+		listing.ReservePrice, _ = cmd.Flags().GetUint64("reserve")
+		listing.MinIncrementBps, _ = cmd.Flags().GetUint16("increment-bps")
+		listing.RoyaltyBps, _ = cmd.Flags().GetUint16("royalty-bps")
+		if nft, _ := cmd.Flags().GetString("nft"); nft != "" {
+			parts := strings.SplitN(nft, ":", 2)
+			if len(parts) != 2 {
+				return fmt.Errorf("--nft must be token:id")
+			}
+			tok, err := strconv.ParseUint(parts[0], 10, 32)
+			if err != nil {
+				return fmt.Errorf("invalid token id: %w", err)
+			}
+			id, err := strconv.ParseUint(parts[1], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid nft id: %w", err)
+			}
+			listing.Item = &core.MarketItem{Token: core.TokenID(tok), NFTID: id}
+		}
 		if err := core.CreateMarketListing(listing); err != nil {
 			return err
 		}
@@ -144,7 +165,74 @@ var mpDealListCmd = &cobra.Command{
 	},
 }
 
+var mpBidCmd = &cobra.Command{
+	Use:   "bid [listing-id] [bidder] [amount]",
+	Short: "Bid on an English or Dutch auction",
+	Args:  cobra.ExactArgs(3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		addr, err := mpParseAddr(args[1])
+		if err != nil {
+			return err
+		}
+		amt, err := strconv.ParseUint(args[2], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid amount: %w", err)
+		}
+		bid, err := core.PlaceBid(&core.Context{Caller: addr}, args[0], addr, amt)
+		if err != nil {
+			return err
+		}
+		out, _ := json.MarshalIndent(bid, "", "  ")
+		fmt.Fprintln(cmd.OutOrStdout(), string(out))
+		return nil
+	},
+}
+
+var mpBidsCmd = &cobra.Command{
+	Use:   "bids [listing-id]",
+	Short: "Show the bid history of a listing",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		bids, err := core.ListMarketBids(args[0])
+		if err != nil {
+			return err
+		}
+		out, _ := json.MarshalIndent(bids, "", "  ")
+		fmt.Fprintln(cmd.OutOrStdout(), string(out))
+		return nil
+	},
+}
+
+var mpSettleCmd = &cobra.Command{
+	Use:   "settle [listing-id]",
+	Short: "Settle an ended auction, or all ended auctions when no ID is given",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 1 {
+			return core.SettleAuction(&core.Context{}, args[0])
+		}
+		ids, err := core.SettleEndedAuctions(&core.Context{}, time.Now().UTC())
+		if err != nil {
+			return err
+		}
+		out, _ := json.MarshalIndent(ids, "", "  ")
+		fmt.Fprintln(cmd.OutOrStdout(), string(out))
+		return nil
+	},
+}
+
 func init() {
+	mpListCreateCmd.Flags().String("seller", "", "seller address")
+	mpListCreateCmd.Flags().String("kind", core.ListingFixed, "fixed, english or dutch")
+	mpListCreateCmd.Flags().Duration("duration", 0, "auction duration")
+	mpListCreateCmd.Flags().Uint64("reserve", 0, "dutch auction floor price")
+	mpListCreateCmd.Flags().Uint16("increment-bps", 0, "english auction minimum raise in basis points")
+	mpListCreateCmd.Flags().String("nft", "", "NFT to sell as token:id")
+	mpListCreateCmd.Flags().Uint16("royalty-bps", 0, "creator royalty set on first listing of the NFT")
+
+	marketCmd.AddCommand(mpBidCmd)
+	marketCmd.AddCommand(mpBidsCmd)
+	marketCmd.AddCommand(mpSettleCmd)
 	marketCmd.AddCommand(mpListCreateCmd)
 	marketCmd.AddCommand(mpListGetCmd)
 	marketCmd.AddCommand(mpListCmd)
@@ -33,10 +33,15 @@ func minerInit(cmd *cobra.Command, _ []string) error {
 	if netCfg.ListenAddr == "" {
 		netCfg.ListenAddr = "/ip4/0.0.0.0/tcp/4001"
 	}
+	emission, err := core.LoadGenesisEmission(viper.GetString("network.genesis_file"))
+	if err != nil {
+		return err
+	}
 	ledCfg := core.LedgerConfig{
 		WALPath:          viper.GetString("ledger.wal"),
 		SnapshotPath:     viper.GetString("ledger.snapshot"),
 		SnapshotInterval: 100,
+		Emission:         emission,
 	}
 	node, err := core.NewMiningNode(&core.MiningNodeConfig{Network: netCfg, Ledger: ledCfg})
 	if err != nil {
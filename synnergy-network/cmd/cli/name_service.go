@@ -0,0 +1,286 @@
+package cli
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	core "synnergy-network/core"
+)
+
+func snsPrint(v interface{}) {
+	out, _ := json.MarshalIndent(v, "", "  ")
+	fmt.Println(string(out))
+}
+
+func snsSalt(s string) (core.Hash, error) {
+	var h core.Hash
+	b, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil || len(b) != len(h) {
+		return h, fmt.Errorf("salt must be %d bytes of hex", len(h))
+	}
+	copy(h[:], b)
+	return h, nil
+}
+
+var snsCmd = &cobra.Command{
+	Use:   "sns",
+	Short: "Synnergy Name Service: register and resolve .syn names",
+}
+
+var snsStartCmd = &cobra.Command{
+	Use:   "start <name>",
+	Short: "Open a sealed-bid auction for an unregistered name",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		a, err := core.StartNameAuction(args[0])
+		if err != nil {
+			return err
+		}
+		snsPrint(a)
+		return nil
+	},
+}
+
+var snsCommitCmd = &cobra.Command{
+	Use:   "commit <name> <bidder> <amount>",
+	Short: "Place a sealed bid; keep the printed salt to reveal it",
+	Args:  cobra.ExactArgs(3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		bidder, err := core.DecodeAddress(args[1])
+		if err != nil {
+			return err
+		}
+		amount, err := strconv.ParseUint(args[2], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid amount: %w", err)
+		}
+		deposit, _ := cmd.Flags().GetUint64("deposit")
+		if deposit == 0 {
+			deposit = amount
+		}
+		if deposit < amount {
+			return fmt.Errorf("deposit %d is below the bid %d", deposit, amount)
+		}
+		var salt core.Hash
+		if s, _ := cmd.Flags().GetString("salt"); s != "" {
+			if salt, err = snsSalt(s); err != nil {
+				return err
+			}
+		} else if _, err := rand.Read(salt[:]); err != nil {
+			return err
+		}
+		commitment, err := core.NameBidCommitment(args[0], bidder, amount, salt)
+		if err != nil {
+			return err
+		}
+		if err := core.CommitNameBid(&core.Context{Caller: bidder}, args[0], bidder, commitment, deposit); err != nil {
+			return err
+		}
+		fmt.Printf("bid committed, deposit %d\nsalt: %x\n", deposit, salt)
+		return nil
+	},
+}
+
+var snsRevealCmd = &cobra.Command{
+	Use:   "reveal <name> <bidder> <amount> <salt>",
+	Short: "Reveal a sealed bid during the reveal window",
+	Args:  cobra.ExactArgs(4),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		bidder, err := core.DecodeAddress(args[1])
+		if err != nil {
+			return err
+		}
+		amount, err := strconv.ParseUint(args[2], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid amount: %w", err)
+		}
+		salt, err := snsSalt(args[3])
+		if err != nil {
+			return err
+		}
+		if err := core.RevealNameBid(args[0], bidder, amount, salt); err != nil {
+			return err
+		}
+		fmt.Println("bid revealed")
+		return nil
+	},
+}
+
+var snsFinalizeCmd = &cobra.Command{
+	Use:   "finalize <name>",
+	Short: "Settle an auction whose reveal window has closed",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		rec, err := core.FinalizeNameAuction(&core.Context{}, args[0])
+		if err != nil {
+			return err
+		}
+		if rec == nil {
+			fmt.Println("no valid bids; the name remains available")
+			return nil
+		}
+		snsPrint(rec)
+		return nil
+	},
+}
+
+var snsRenewCmd = &cobra.Command{
+	Use:   "renew <name> <payer>",
+	Short: "Extend a registration",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		payer, err := core.DecodeAddress(args[1])
+		if err != nil {
+			return err
+		}
+		years, _ := cmd.Flags().GetUint32("years")
+		rec, err := core.RenewName(&core.Context{Caller: payer}, args[0], payer, years)
+		if err != nil {
+			return err
+		}
+		snsPrint(rec)
+		return nil
+	},
+}
+
+// snsOwnerCmd builds a command that the name owner runs to update a record.
+func snsOwnerCmd(use, short string, nargs int, fn func(name string, owner core.Address, args []string) (*core.NameRecord, error)) *cobra.Command {
+	return &cobra.Command{
+		Use:   use,
+		Short: short,
+		Args:  cobra.ExactArgs(nargs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			owner, err := core.DecodeAddress(args[1])
+			if err != nil {
+				return err
+			}
+			rec, err := fn(args[0], owner, args[2:])
+			if err != nil {
+				return err
+			}
+			snsPrint(rec)
+			return nil
+		},
+	}
+}
+
+var snsTransferCmd = snsOwnerCmd("transfer <name> <owner> <to>", "Transfer ownership of a name", 3,
+	func(name string, owner core.Address, args []string) (*core.NameRecord, error) {
+		to, err := core.DecodeAddress(args[0])
+		if err != nil {
+			return nil, err
+		}
+		return core.TransferName(name, owner, to)
+	})
+
+var snsSetAddrCmd = snsOwnerCmd("set-addr <name> <owner> <addr>", "Set the address a name resolves to", 3,
+	func(name string, owner core.Address, args []string) (*core.NameRecord, error) {
+		addr, err := core.DecodeAddress(args[0])
+		if err != nil {
+			return nil, err
+		}
+		return core.SetNameAddr(name, owner, addr)
+	})
+
+var snsSetContentCmd = snsOwnerCmd("set-content <name> <owner> <cid>", "Set the content CID of a name", 3,
+	func(name string, owner core.Address, args []string) (*core.NameRecord, error) {
+		return core.SetNameContent(name, owner, args[0])
+	})
+
+var snsSetTextCmd = snsOwnerCmd("set-text <name> <owner> <key> <value>", "Set a text record; an empty value deletes it", 4,
+	func(name string, owner core.Address, args []string) (*core.NameRecord, error) {
+		return core.SetNameText(name, owner, args[0], args[1])
+	})
+
+var snsSetReverseCmd = &cobra.Command{
+	Use:   "set-reverse <addr> <name>",
+	Short: "Use a name that resolves to addr as its display name",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		addr, err := core.DecodeAddress(args[0])
+		if err != nil {
+			return err
+		}
+		return core.SetReverseName(addr, args[1])
+	},
+}
+
+var snsResolveCmd = &cobra.Command{
+	Use:   "resolve <name|addr>",
+	Short: "Resolve a name to its address, or an address to its reverse name",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if core.IsName(args[0]) {
+			addr, err := core.ResolveName(args[0])
+			if err != nil {
+				return err
+			}
+			fmt.Println(addr.Bech32())
+			return nil
+		}
+		addr, err := core.DecodeAddress(args[0])
+		if err != nil {
+			return err
+		}
+		name, ok := core.ReverseName(addr)
+		if !ok {
+			return fmt.Errorf("%s has no reverse name", addr.Bech32())
+		}
+		fmt.Println(name)
+		return nil
+	},
+}
+
+var snsLookupCmd = &cobra.Command{
+	Use:   "lookup <name>",
+	Short: "Show the registration and auction of a name",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := map[string]interface{}{}
+		if rec, err := core.LookupName(args[0]); err == nil {
+			out["record"] = rec
+		}
+		if a, err := core.GetNameAuction(args[0]); err == nil {
+			out["auction"] = a
+		}
+		if len(out) == 0 {
+			n, err := core.NormalizeName(args[0])
+			if err != nil {
+				return err
+			}
+			return fmt.Errorf("%s is neither registered nor under auction", n)
+		}
+		snsPrint(out)
+		return nil
+	},
+}
+
+func init() {
+	snsCommitCmd.Flags().Uint64("deposit", 0, "amount to escrow; more than the bid hides its value (default: the bid)")
+	snsCommitCmd.Flags().String("salt", "", "32-byte hex salt (default: random)")
+	snsRenewCmd.Flags().Uint32("years", 1, "registration periods to add")
+
+	snsCmd.AddCommand(
+		snsStartCmd,
+		snsCommitCmd,
+		snsRevealCmd,
+		snsFinalizeCmd,
+		snsRenewCmd,
+		snsTransferCmd,
+		snsSetAddrCmd,
+		snsSetContentCmd,
+		snsSetTextCmd,
+		snsSetReverseCmd,
+		snsResolveCmd,
+		snsLookupCmd,
+	)
+}
+
+// SnsCmd is exported for index.go
+var SnsCmd = snsCmd
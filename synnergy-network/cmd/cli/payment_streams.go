@@ -0,0 +1,165 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	core "synnergy-network/core"
+)
+
+func streamPrint(s *core.PaymentStream) {
+	now := time.Now().UTC()
+	out, _ := json.MarshalIndent(struct {
+		*core.PaymentStream
+		Vested       uint64 `json:"vested"`
+		Withdrawable uint64 `json:"withdrawable"`
+	}{s, s.Vested(now), s.Withdrawable(now)}, "", "  ")
+	fmt.Println(string(out))
+}
+
+var streamCmd = &cobra.Command{
+	Use:   "stream",
+	Short: "Per-second payment streams of coins or tokens",
+}
+
+var streamCreateCmd = &cobra.Command{
+	Use:   "create <sender> <recipient> <rate-per-second> <duration>",
+	Short: "Escrow rate × duration and stream it to recipient",
+	Args:  cobra.ExactArgs(4),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		from, err := core.DecodeAddress(args[0])
+		if err != nil {
+			return err
+		}
+		to, err := core.ResolveRecipient(args[1])
+		if err != nil {
+			return err
+		}
+		rate, err := strconv.ParseUint(args[2], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid rate: %w", err)
+		}
+		dur, err := time.ParseDuration(args[3])
+		if err != nil {
+			return fmt.Errorf("invalid duration: %w", err)
+		}
+		asset := core.AssetRef{Kind: core.AssetCoin}
+		if id, _ := cmd.Flags().GetUint32("token"); id != 0 {
+			asset = core.AssetRef{Kind: core.AssetToken, TokenID: core.TokenID(id)}
+		}
+		var start time.Time
+		if s, _ := cmd.Flags().GetString("start"); s != "" {
+			if start, err = time.Parse(time.RFC3339, s); err != nil {
+				return fmt.Errorf("invalid start: %w", err)
+			}
+		}
+		st, err := core.CreatePaymentStream(&core.Context{Caller: from}, to, asset, rate, dur, start)
+		if err != nil {
+			return err
+		}
+		streamPrint(st)
+		return nil
+	},
+}
+
+var streamWithdrawCmd = &cobra.Command{
+	Use:   "withdraw <id> <recipient> [amount]",
+	Short: "Withdraw vested funds; without an amount everything vested",
+	Args:  cobra.RangeArgs(2, 3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		to, err := core.DecodeAddress(args[1])
+		if err != nil {
+			return err
+		}
+		var amt uint64
+		if len(args) == 3 {
+			if amt, err = strconv.ParseUint(args[2], 10, 64); err != nil {
+				return fmt.Errorf("invalid amount: %w", err)
+			}
+		}
+		st, err := core.WithdrawFromStream(&core.Context{Caller: to}, args[0], amt)
+		if err != nil {
+			return err
+		}
+		streamPrint(st)
+		return nil
+	},
+}
+
+var streamCancelCmd = &cobra.Command{
+	Use:   "cancel <id> <caller>",
+	Short: "Cancel a stream as sender or recipient and settle it pro rata",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		caller, err := core.DecodeAddress(args[1])
+		if err != nil {
+			return err
+		}
+		st, err := core.CancelPaymentStream(&core.Context{Caller: caller}, args[0])
+		if err != nil {
+			return err
+		}
+		streamPrint(st)
+		return nil
+	},
+}
+
+var streamInfoCmd = &cobra.Command{
+	Use:   "info <id>",
+	Short: "Show a stream with its vested and withdrawable amounts",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		st, err := core.GetPaymentStream(args[0])
+		if err != nil {
+			return err
+		}
+		streamPrint(st)
+		return nil
+	},
+}
+
+var streamListCmd = &cobra.Command{
+	Use:   "list [addr]",
+	Short: "List the streams an address sends or receives, or all streams",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		addr := core.AddressZero
+		if len(args) == 1 {
+			a, err := core.DecodeAddress(args[0])
+			if err != nil {
+				return err
+			}
+			addr = a
+		}
+		list, err := core.ListPaymentStreams(addr)
+		if err != nil {
+			return err
+		}
+		now := time.Now().UTC()
+		for _, s := range list {
+			fmt.Printf("%s %s -> %s %d/s %s vested %d/%d withdrawn %d\n", s.ID, s.Sender.Hex(), s.Recipient.Hex(),
+				s.RatePerSecond, s.Status, s.Vested(now), s.Deposit, s.Withdrawn)
+		}
+		return nil
+	},
+}
+
+func init() {
+	streamCreateCmd.Flags().Uint32("token", 0, "token ID to stream (default: the coin)")
+	streamCreateCmd.Flags().String("start", "", "RFC 3339 start time (default: now)")
+
+	streamCmd.AddCommand(
+		streamCreateCmd,
+		streamWithdrawCmd,
+		streamCancelCmd,
+		streamInfoCmd,
+		streamListCmd,
+	)
+}
+
+// StreamCmd is exported for index.go
+var StreamCmd = streamCmd
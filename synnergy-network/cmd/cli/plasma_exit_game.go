@@ -0,0 +1,213 @@
+package cli
+
+// plasma_exit_game.go - commitments, bonded exits, challenges and mass exit
+// for the plasma bridge.
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	core "synnergy-network/core"
+)
+
+// plasmaEvidence is the file format of exit and challenge evidence: a
+// child-chain transaction and its merkle proof as hex strings ordered from
+// the leaf upwards.
+type plasmaEvidence struct {
+	Tx    core.PlasmaTx `json:"tx"`
+	Proof []string      `json:"proof"`
+}
+
+func loadPlasmaEvidence(path string) (core.PlasmaTx, [][]byte, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return core.PlasmaTx{}, nil, err
+	}
+	var ev plasmaEvidence
+	if err := json.Unmarshal(raw, &ev); err != nil {
+		return core.PlasmaTx{}, nil, err
+	}
+	proof := make([][]byte, len(ev.Proof))
+	for i, p := range ev.Proof {
+		if proof[i], err = hex.DecodeString(strings.TrimPrefix(p, "0x")); err != nil {
+			return core.PlasmaTx{}, nil, fmt.Errorf("proof[%d]: %w", i, err)
+		}
+	}
+	return ev.Tx, proof, nil
+}
+
+func plasmaExitInit(cmd *cobra.Command, _ []string) error {
+	if core.PlasmaExits() != nil {
+		return nil
+	}
+	led := core.CurrentLedger()
+	if led == nil {
+		return fmt.Errorf("ledger not initialised")
+	}
+	var op core.Address
+	if s := viper.GetString("plasma.operator"); s != "" {
+		a, err := decodeAddr(s)
+		if err != nil {
+			return fmt.Errorf("plasma.operator: %w", err)
+		}
+		op = a
+	}
+	core.InitPlasmaExitGame(led, op, core.DefaultPlasmaExitParams())
+	return nil
+}
+
+func plasmaPrint(cmd *cobra.Command, v interface{}) error {
+	b, _ := json.MarshalIndent(v, "", "  ")
+	fmt.Fprintln(cmd.OutOrStdout(), string(b))
+	return nil
+}
+
+var plasmaCommitCmd = &cobra.Command{
+	Use:     "commit <operator> <root>",
+	Short:   "Publish a child-chain block root",
+	Args:    cobra.ExactArgs(2),
+	PreRunE: plasmaExitInit,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		op, err := decodeAddr(args[0])
+		if err != nil {
+			return err
+		}
+		b, err := hex.DecodeString(strings.TrimPrefix(args[1], "0x"))
+		if err != nil || len(b) != 32 {
+			return fmt.Errorf("root must be 32 hex bytes")
+		}
+		var root [32]byte
+		copy(root[:], b)
+		c, err := core.PlasmaExits().SubmitCommitment(op, root)
+		if err != nil {
+			return err
+		}
+		return plasmaPrint(cmd, c)
+	},
+}
+
+var plasmaExitOutputCmd = &cobra.Command{
+	Use:     "exit-output <owner> <position> <evidence.json>",
+	Short:   "Start a bonded exit of a child-chain output",
+	Args:    cobra.ExactArgs(3),
+	PreRunE: plasmaExitInit,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		owner, err := decodeAddr(args[0])
+		if err != nil {
+			return err
+		}
+		pos, err := strconv.ParseUint(args[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("position uint64: %w", err)
+		}
+		tx, proof, err := loadPlasmaEvidence(args[2])
+		if err != nil {
+			return err
+		}
+		ex, err := core.PlasmaExits().StartExit(owner, pos, tx, proof)
+		if err != nil {
+			return err
+		}
+		return plasmaPrint(cmd, ex)
+	},
+}
+
+var plasmaChallengeCmd = &cobra.Command{
+	Use:     "challenge <challenger> <position> <spend-position> <evidence.json>",
+	Short:   "Challenge an exit with a committed spend of the output",
+	Args:    cobra.ExactArgs(4),
+	PreRunE: plasmaExitInit,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		who, err := decodeAddr(args[0])
+		if err != nil {
+			return err
+		}
+		pos, err := strconv.ParseUint(args[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("position uint64: %w", err)
+		}
+		spendPos, err := strconv.ParseUint(args[2], 10, 64)
+		if err != nil {
+			return fmt.Errorf("spend position uint64: %w", err)
+		}
+		tx, proof, err := loadPlasmaEvidence(args[3])
+		if err != nil {
+			return err
+		}
+		return core.PlasmaExits().ChallengeExit(who, pos, tx, spendPos, proof)
+	},
+}
+
+var plasmaProcessCmd = &cobra.Command{
+	Use:     "process",
+	Short:   "Pay matured exits in priority order",
+	Args:    cobra.NoArgs,
+	PreRunE: plasmaExitInit,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		limit, _ := cmd.Flags().GetInt("limit")
+		paid, err := core.PlasmaExits().ProcessExits(limit)
+		if err != nil {
+			return err
+		}
+		return plasmaPrint(cmd, paid)
+	},
+}
+
+var plasmaQueueCmd = &cobra.Command{
+	Use:     "queue",
+	Short:   "List pending exits in priority order",
+	Args:    cobra.NoArgs,
+	PreRunE: plasmaExitInit,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		q, err := core.PlasmaExits().ExitQueue()
+		if err != nil {
+			return err
+		}
+		return plasmaPrint(cmd, q)
+	},
+}
+
+var plasmaMassExitCmd = &cobra.Command{
+	Use:     "mass-exit",
+	Short:   "Enter mass-exit mode if the operator stopped committing",
+	Args:    cobra.NoArgs,
+	PreRunE: plasmaExitInit,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		on, err := core.PlasmaExits().TriggerMassExit()
+		if err != nil {
+			return err
+		}
+		if !on {
+			return fmt.Errorf("operator is live; mass exit not allowed")
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), "mass exit active")
+		return nil
+	},
+}
+
+var plasmaExitStatusCmd = &cobra.Command{
+	Use:     "exit-status",
+	Short:   "Show commitment height, operator liveness and exit queue size",
+	Args:    cobra.NoArgs,
+	PreRunE: plasmaExitInit,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		st, err := core.PlasmaExits().Status()
+		if err != nil {
+			return err
+		}
+		return plasmaPrint(cmd, st)
+	},
+}
+
+func init() {
+	plasmaProcessCmd.Flags().Int("limit", 0, "max exits to pay (0 = all matured)")
+	plasmaCmd.AddCommand(plasmaCommitCmd, plasmaExitOutputCmd, plasmaChallengeCmd,
+		plasmaProcessCmd, plasmaQueueCmd, plasmaMassExitCmd, plasmaExitStatusCmd)
+}
@@ -33,6 +33,33 @@ func (PollController) Close(id string) error            { return core.ClosePoll(
 func (PollController) Get(id string) (core.Poll, error) { return core.GetPoll(id) }
 func (PollController) List() ([]core.Poll, error)       { return core.ListPolls() }
 
+func (PollController) Flag(id, flagger, reason string) (bool, error) {
+	addr, err := core.ParseAddress(flagger)
+	if err != nil {
+		return false, err
+	}
+	return core.FlagPoll(id, addr, reason)
+}
+
+func (PollController) ReleaseStake(id, owner string) error {
+	addr, err := core.ParseAddress(owner)
+	if err != nil {
+		return err
+	}
+	return core.ReleasePollStake(id, addr)
+}
+
+func (PollController) Moderate(id, moderator, reason string, restore bool) error {
+	addr, err := core.ParseAddress(moderator)
+	if err != nil {
+		return err
+	}
+	if restore {
+		return core.RestoreContent(core.ContentPoll, id, addr, reason)
+	}
+	return core.HideContent(core.ContentPoll, id, addr, reason)
+}
+
 // ----------------------------------------------------------------------
 // CLI commands
 // ----------------------------------------------------------------------
@@ -104,6 +131,68 @@ var pollsListCmd = &cobra.Command{Use: "list", Short: "List polls", RunE: func(c
 	return enc.Encode(polls)
 }}
 
+var pollsFlagCmd = &cobra.Command{
+	Use:   "flag <id>",
+	Short: "Report a poll as spam",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flagger, _ := cmd.Flags().GetString("flagger")
+		reason, _ := cmd.Flags().GetString("reason")
+		hidden, err := PollController{}.Flag(args[0], flagger, reason)
+		if err != nil {
+			return err
+		}
+		if hidden {
+			fmt.Fprintln(cmd.OutOrStdout(), "flagged – poll hidden")
+			return nil
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), "flagged")
+		return nil
+	},
+}
+
+var pollsReleaseCmd = &cobra.Command{
+	Use:   "release-stake <id>",
+	Short: "Reclaim the creation stake of a poll",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		owner, _ := cmd.Flags().GetString("owner")
+		return PollController{}.ReleaseStake(args[0], owner)
+	},
+}
+
+var pollsHideCmd = &cobra.Command{
+	Use:   "hide <id>",
+	Short: "Hide a poll (authority only)",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		mod, _ := cmd.Flags().GetString("moderator")
+		reason, _ := cmd.Flags().GetString("reason")
+		return PollController{}.Moderate(args[0], mod, reason, false)
+	},
+}
+
+var pollsRestoreCmd = &cobra.Command{
+	Use:   "restore <id>",
+	Short: "Restore a hidden poll (authority only)",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		mod, _ := cmd.Flags().GetString("moderator")
+		reason, _ := cmd.Flags().GetString("reason")
+		return PollController{}.Moderate(args[0], mod, reason, true)
+	},
+}
+
+var pollsModLogCmd = &cobra.Command{Use: "modlog", Short: "Show the moderation log", RunE: func(cmd *cobra.Command, args []string) error {
+	log, err := core.ModerationHistory(core.ContentPoll)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(cmd.OutOrStdout())
+	enc.SetIndent("", "  ")
+	return enc.Encode(log)
+}}
+
 func init() {
 	pollsCreateCmd.Flags().String("question", "", "poll question")
 	pollsCreateCmd.Flags().StringSlice("option", nil, "poll option (repeat)")
@@ -118,7 +207,21 @@ func init() {
 	pollsVoteCmd.MarkFlagRequired("voter")
 	pollsVoteCmd.MarkFlagRequired("option")
 
-	pollsCmd.AddCommand(pollsCreateCmd, pollsVoteCmd, pollsCloseCmd, pollsGetCmd, pollsListCmd)
+	pollsFlagCmd.Flags().String("flagger", "", "flagging address")
+	pollsFlagCmd.Flags().String("reason", "", "reason")
+	pollsFlagCmd.MarkFlagRequired("flagger")
+
+	pollsReleaseCmd.Flags().String("owner", "", "poll creator")
+	pollsReleaseCmd.MarkFlagRequired("owner")
+
+	for _, c := range []*cobra.Command{pollsHideCmd, pollsRestoreCmd} {
+		c.Flags().String("moderator", "", "authority address")
+		c.Flags().String("reason", "", "reason")
+		c.MarkFlagRequired("moderator")
+	}
+
+	pollsCmd.AddCommand(pollsCreateCmd, pollsVoteCmd, pollsCloseCmd, pollsGetCmd, pollsListCmd,
+		pollsFlagCmd, pollsReleaseCmd, pollsHideCmd, pollsRestoreCmd, pollsModLogCmd)
 }
 
 var PollsCmd = pollsCmd
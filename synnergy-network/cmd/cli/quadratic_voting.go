@@ -1,7 +1,6 @@
 package cli
 
 import (
-	"encoding/hex"
 	"fmt"
 
 	"github.com/spf13/cobra"
@@ -10,13 +9,7 @@ import (
 )
 
 func qvParseAddr(h string) (core.Address, error) {
-	var a core.Address
-	b, err := hex.DecodeString(h)
-	if err != nil || len(b) != len(a) {
-		return a, fmt.Errorf("bad address")
-	}
-	copy(a[:], b)
-	return a, nil
+	return core.DecodeAddress(h)
 }
 
 func ensureQVReady(cmd *cobra.Command, _ []string) error {
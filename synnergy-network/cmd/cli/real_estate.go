@@ -4,6 +4,8 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"strconv"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -44,6 +46,21 @@ func (realEstateController) list(owner string) ([]core.Property, error) {
 	return core.ListProperties(addr)
 }
 
+func parseREAddr(h string) (core.Address, error) {
+	return core.DecodeAddress(h)
+}
+
+func shareRulesFromFlags(cmd *cobra.Command) core.ShareRules {
+	kyc, _ := cmd.Flags().GetBool("kyc")
+	holders, _ := cmd.Flags().GetInt("max-holders")
+	return core.ShareRules{KYCRequired: kyc, MaxHolders: holders}
+}
+
+func printRE(cmd *cobra.Command, v interface{}) {
+	enc, _ := json.MarshalIndent(v, "", "  ")
+	fmt.Fprintln(cmd.OutOrStdout(), string(enc))
+}
+
 // -----------------------------------------------------------------------------
 // Cobra command tree
 // -----------------------------------------------------------------------------
@@ -132,8 +149,143 @@ var reListCmd = &cobra.Command{
 	},
 }
 
+var reFractionaliseCmd = &cobra.Command{
+	Use:   "fractionalise <id> <owner> <shares>",
+	Short: "Split a property into transferable shares",
+	Args:  cobra.ExactArgs(3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		owner, err := parseREAddr(args[1])
+		if err != nil {
+			return err
+		}
+		total, err := strconv.ParseUint(args[2], 10, 64)
+		if err != nil {
+			return err
+		}
+		period, _ := cmd.Flags().GetDuration("period")
+		ps, err := core.FractionaliseProperty(args[0], owner, total, period, shareRulesFromFlags(cmd))
+		if err != nil {
+			return err
+		}
+		printRE(cmd, ps)
+		return nil
+	},
+}
+
+var reRulesCmd = &cobra.Command{
+	Use:   "rules <id> <issuer>",
+	Short: "Replace the share transfer rules of a property",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		issuer, err := parseREAddr(args[1])
+		if err != nil {
+			return err
+		}
+		return core.SetShareRules(args[0], issuer, shareRulesFromFlags(cmd))
+	},
+}
+
+var reSharesCmd = &cobra.Command{
+	Use:   "shares <id> [holder]",
+	Short: "Show share details, holders or one holder's balance",
+	Args:  cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 2 {
+			holder, err := parseREAddr(args[1])
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), core.ShareBalance(args[0], holder))
+			return nil
+		}
+		ps, err := core.GetPropertyShares(args[0])
+		if err != nil {
+			return err
+		}
+		holders, err := core.ShareHolders(args[0])
+		if err != nil {
+			return err
+		}
+		printRE(cmd, map[string]interface{}{"shares": ps, "holders": holders})
+		return nil
+	},
+}
+
+var reTransferSharesCmd = &cobra.Command{
+	Use:   "transfer-shares <id> <from> <to> <amount>",
+	Short: "Transfer property shares subject to its rules",
+	Args:  cobra.ExactArgs(4),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		from, err := parseREAddr(args[1])
+		if err != nil {
+			return err
+		}
+		to, err := parseREAddr(args[2])
+		if err != nil {
+			return err
+		}
+		amt, err := strconv.ParseUint(args[3], 10, 64)
+		if err != nil {
+			return err
+		}
+		return core.TransferShares(args[0], from, to, amt)
+	},
+}
+
+var reDepositRentCmd = &cobra.Command{
+	Use:   "deposit-rent <id> <payer> <amount>",
+	Short: "Deposit rent for distribution to shareholders",
+	Args:  cobra.ExactArgs(3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		payer, err := parseREAddr(args[1])
+		if err != nil {
+			return err
+		}
+		amt, err := strconv.ParseUint(args[2], 10, 64)
+		if err != nil {
+			return err
+		}
+		return core.DepositRent(&core.Context{Caller: payer}, args[0], payer, amt)
+	},
+}
+
+var reDistributeCmd = &cobra.Command{
+	Use:   "distribute <id>",
+	Short: "Distribute pending rent to shareholders now",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dist, err := core.DistributeRent(&core.Context{}, args[0])
+		if err != nil {
+			return err
+		}
+		printRE(cmd, dist)
+		return nil
+	},
+}
+
+var reDistributionsCmd = &cobra.Command{
+	Use:   "distributions <id>",
+	Short: "Show the rent distribution history of a property",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		list, err := core.RentDistributions(args[0])
+		if err != nil {
+			return err
+		}
+		printRE(cmd, list)
+		return nil
+	},
+}
+
 func init() {
-	realEstateCmd.AddCommand(reRegisterCmd, reTransferCmd, reGetCmd, reListCmd)
+	for _, c := range []*cobra.Command{reFractionaliseCmd, reRulesCmd} {
+		c.Flags().Bool("kyc", false, "restrict share transfers to KYC verified holders")
+		c.Flags().Int("max-holders", 0, "maximum number of shareholders (0 = unlimited)")
+	}
+	reFractionaliseCmd.Flags().Duration("period", 30*24*time.Hour, "rent distribution period")
+	realEstateCmd.AddCommand(reRegisterCmd, reTransferCmd, reGetCmd, reListCmd,
+		reFractionaliseCmd, reRulesCmd, reSharesCmd, reTransferSharesCmd,
+		reDepositRentCmd, reDistributeCmd, reDistributionsCmd)
 }
 
 // RealEstateCmd is the root command exported for registration.
@@ -39,6 +39,8 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+
+	core "synnergy-network/core"
 )
 
 // -----------------------------------------------------------------------------
@@ -113,13 +115,13 @@ func statusRPC(ctx context.Context) (map[string]any, error) {
 	}
 	var resp struct {
 		Data  map[string]any `json:"data"`
-		Error string         `json:"error,omitempty"`
+		Error core.WireError `json:"error,omitempty"`
 	}
 	if err := cli.readJSON(&resp); err != nil {
 		return nil, err
 	}
-	if resp.Error != "" {
-		return nil, errors.New(resp.Error)
+	if resp.Error.Err() != nil {
+		return nil, resp.Error.Err()
 	}
 	return resp.Data, nil
 }
@@ -1,10 +1,8 @@
 package cli
 
 import (
-	"encoding/hex"
 	"fmt"
 	"os"
-	"strings"
 	"sync"
 
 	"github.com/joho/godotenv"
@@ -50,13 +48,7 @@ func resInit(cmd *cobra.Command, _ []string) error {
 }
 
 func resParseAddr(h string) (core.Address, error) {
-	var a core.Address
-	b, err := hex.DecodeString(strings.TrimPrefix(h, "0x"))
-	if err != nil || len(b) != len(a) {
-		return a, fmt.Errorf("bad address")
-	}
-	copy(a[:], b)
-	return a, nil
+	return core.DecodeAddress(h)
 }
 
 func resHandleSet(cmd *cobra.Command, args []string) error {
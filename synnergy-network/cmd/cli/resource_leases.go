@@ -0,0 +1,256 @@
+package cli
+
+// resource_leases.go -- CLI bindings for metered compute leases: capacity
+// offers, escrow-funded leases, co-signed usage reports and arbitration.
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"synnergy-network/core"
+)
+
+func resSpecFlags(cmd *cobra.Command, prefix string) core.ResourceSpec {
+	cpu, _ := cmd.Flags().GetUint64(prefix + "cpu")
+	ram, _ := cmd.Flags().GetUint64(prefix + "ram")
+	disk, _ := cmd.Flags().GetUint64(prefix + "storage")
+	return core.ResourceSpec{CPU: cpu, RAMMB: ram, StorageGB: disk}
+}
+
+func addResSpecFlags(cmd *cobra.Command, prefix, what string) {
+	cmd.Flags().Uint64(prefix+"cpu", 0, what+" CPU cores")
+	cmd.Flags().Uint64(prefix+"ram", 0, what+" RAM in MB")
+	cmd.Flags().Uint64(prefix+"storage", 0, what+" storage in GB")
+}
+
+func resAddrFlag(cmd *cobra.Command, name string) core.Address {
+	v, _ := cmd.Flags().GetString(name)
+	if v == "" {
+		_ = cmd.Usage()
+		resBail(fmt.Errorf("--%s required", name))
+	}
+	a, err := parseResAddr(v)
+	resBail(err)
+	return a
+}
+
+func resStringFlag(cmd *cobra.Command, name string) string {
+	v, _ := cmd.Flags().GetString(name)
+	if v == "" {
+		_ = cmd.Usage()
+		resBail(fmt.Errorf("--%s required", name))
+	}
+	return v
+}
+
+func resPrint(cmd *cobra.Command, v interface{}) {
+	enc := json.NewEncoder(cmd.OutOrStdout())
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(v)
+}
+
+func resOfferCreate(cmd *cobra.Command, _ []string) {
+	provider := resAddrFlag(cmd, "provider")
+	o, err := core.AdvertiseResources(provider, resSpecFlags(cmd, ""), resSpecFlags(cmd, "rate-"))
+	resBail(err)
+	resPrint(cmd, o)
+}
+
+func resOfferCapacity(cmd *cobra.Command, _ []string) {
+	provider := resAddrFlag(cmd, "provider")
+	resBail(core.UpdateResourceCapacity(provider, resStringFlag(cmd, "offer"), resSpecFlags(cmd, "")))
+	fmt.Fprintln(cmd.OutOrStdout(), "✅ capacity updated")
+}
+
+func resOfferList(cmd *cobra.Command, _ []string) {
+	var p *core.Address
+	if v, _ := cmd.Flags().GetString("provider"); v != "" {
+		a, err := parseResAddr(v)
+		resBail(err)
+		p = &a
+	}
+	offers, err := core.ListResourceOffers(p)
+	resBail(err)
+	resPrint(cmd, offers)
+}
+
+func resLeaseOpen(cmd *cobra.Command, _ []string) {
+	client := resAddrFlag(cmd, "client")
+	deposit, _ := cmd.Flags().GetUint64("deposit")
+	l, err := core.OpenResourceLease(client, resStringFlag(cmd, "offer"), resSpecFlags(cmd, ""), deposit)
+	resBail(err)
+	resPrint(cmd, l)
+}
+
+func resLeaseGet(cmd *cobra.Command, _ []string) {
+	l, err := core.GetResourceLease(resStringFlag(cmd, "id"))
+	resBail(err)
+	resPrint(cmd, l)
+}
+
+func resLeaseList(cmd *cobra.Command, _ []string) {
+	var p, c *core.Address
+	if v, _ := cmd.Flags().GetString("provider"); v != "" {
+		a, err := parseResAddr(v)
+		resBail(err)
+		p = &a
+	}
+	if v, _ := cmd.Flags().GetString("client"); v != "" {
+		a, err := parseResAddr(v)
+		resBail(err)
+		c = &a
+	}
+	ls, err := core.ListResourceLeases(p, c)
+	resBail(err)
+	resPrint(cmd, ls)
+}
+
+func resLeaseTopUp(cmd *cobra.Command, _ []string) {
+	client := resAddrFlag(cmd, "client")
+	amt, _ := cmd.Flags().GetUint64("amount")
+	resBail(core.TopUpResourceLease(client, resStringFlag(cmd, "id"), amt))
+	fmt.Fprintln(cmd.OutOrStdout(), "✅ escrow topped up")
+}
+
+// resUsageSign creates or countersigns a usage report file.
+func resUsageSign(cmd *cobra.Command, _ []string) {
+	path := resStringFlag(cmd, "file")
+	keyHex := resStringFlag(cmd, "key")
+	asProvider, _ := cmd.Flags().GetBool("provider")
+	key, err := hex.DecodeString(keyHex)
+	if err != nil || len(key) != ed25519.PrivateKeySize {
+		resBail(errors.New("invalid ed25519 private key"))
+	}
+	var r core.UsageReport
+	if raw, err := os.ReadFile(path); err == nil {
+		resBail(json.Unmarshal(raw, &r))
+	} else {
+		r.LeaseID = resStringFlag(cmd, "lease")
+		r.From, _ = cmd.Flags().GetUint64("from")
+		r.To, _ = cmd.Flags().GetUint64("to")
+		r.Used = resSpecFlags(cmd, "")
+	}
+	resBail(core.SignUsageReport(&r, ed25519.PrivateKey(key), asProvider))
+	raw, _ := json.MarshalIndent(r, "", "  ")
+	resBail(os.WriteFile(path, raw, 0o600))
+	fmt.Fprintf(cmd.OutOrStdout(), "✅ report signed: %s\n", path)
+}
+
+func resUsageSubmit(cmd *cobra.Command, _ []string) {
+	raw, err := os.ReadFile(resStringFlag(cmd, "file"))
+	resBail(err)
+	var r core.UsageReport
+	resBail(json.Unmarshal(raw, &r))
+	l, err := core.SubmitUsageReport(r)
+	resBail(err)
+	resPrint(cmd, l)
+}
+
+func resUsageList(cmd *cobra.Command, _ []string) {
+	rs, err := core.LeaseUsageReports(resStringFlag(cmd, "id"))
+	resBail(err)
+	resPrint(cmd, rs)
+}
+
+func resLeaseTerminate(cmd *cobra.Command, _ []string) {
+	l, err := core.TerminateResourceLease(resAddrFlag(cmd, "caller"), resStringFlag(cmd, "id"))
+	resBail(err)
+	resPrint(cmd, l)
+}
+
+func resLeaseDispute(cmd *cobra.Command, _ []string) {
+	reason, _ := cmd.Flags().GetString("reason")
+	l, err := core.DisputeResourceLease(resAddrFlag(cmd, "caller"), resStringFlag(cmd, "id"), reason)
+	resBail(err)
+	resPrint(cmd, l)
+}
+
+func resLeaseArbitrate(cmd *cobra.Command, _ []string) {
+	refund, _ := cmd.Flags().GetUint64("refund")
+	terminate, _ := cmd.Flags().GetBool("terminate")
+	resolution, _ := cmd.Flags().GetString("resolution")
+	l, err := core.ArbitrateResourceLease(resAddrFlag(cmd, "arbitrator"), resStringFlag(cmd, "id"), refund, terminate, resolution)
+	resBail(err)
+	resPrint(cmd, l)
+}
+
+func resLeaseSettle(cmd *cobra.Command, _ []string) {
+	h, _ := cmd.Flags().GetUint64("height")
+	if h == 0 {
+		if led := core.CurrentLedger(); led != nil {
+			h = led.LastHeight()
+		}
+	}
+	n, err := core.SettleResourceLeases(h)
+	resBail(err)
+	fmt.Fprintf(cmd.OutOrStdout(), "✅ settled %d leases at height %d\n", n, h)
+}
+
+var resOfferCreateCmd = &cobra.Command{Use: "offer:create", Short: "Advertise CPU/RAM/storage capacity", Run: resOfferCreate}
+var resOfferCapacityCmd = &cobra.Command{Use: "offer:capacity", Short: "Change advertised capacity", Run: resOfferCapacity}
+var resOfferListCmd = &cobra.Command{Use: "offer:list", Short: "List capacity offers", Run: resOfferList}
+var resLeaseOpenCmd = &cobra.Command{Use: "lease:open", Short: "Open an escrow-funded lease", Run: resLeaseOpen}
+var resLeaseGetCmd = &cobra.Command{Use: "lease:get", Short: "Show a lease", Run: resLeaseGet}
+var resLeaseListCmd = &cobra.Command{Use: "lease:list", Short: "List leases", Run: resLeaseList}
+var resLeaseTopUpCmd = &cobra.Command{Use: "lease:topup", Short: "Add funds to a lease escrow", Run: resLeaseTopUp}
+var resUsageSignCmd = &cobra.Command{Use: "usage:sign", Short: "Create or countersign a usage report", Run: resUsageSign}
+var resUsageSubmitCmd = &cobra.Command{Use: "usage:submit", Short: "Submit a co-signed usage report", Run: resUsageSubmit}
+var resUsageListCmd = &cobra.Command{Use: "usage:list", Short: "List usage reports of a lease", Run: resUsageList}
+var resLeaseTerminateCmd = &cobra.Command{Use: "lease:terminate", Short: "End a lease and refund the escrow", Run: resLeaseTerminate}
+var resLeaseDisputeCmd = &cobra.Command{Use: "lease:dispute", Short: "Dispute a lease and pause payments", Run: resLeaseDispute}
+var resLeaseArbitrateCmd = &cobra.Command{Use: "lease:arbitrate", Short: "Resolve a lease dispute (authority)", Run: resLeaseArbitrate}
+var resLeaseSettleCmd = &cobra.Command{Use: "lease:settle", Short: "Pay providers up to a block height", Run: resLeaseSettle}
+
+func init() {
+	resOfferCreateCmd.Flags().String("provider", "", "provider address [hex]")
+	addResSpecFlags(resOfferCreateCmd, "", "offered")
+	addResSpecFlags(resOfferCreateCmd, "rate-", "price per block of one unit of")
+	resOfferCapacityCmd.Flags().String("provider", "", "provider address [hex]")
+	resOfferCapacityCmd.Flags().String("offer", "", "offer id")
+	addResSpecFlags(resOfferCapacityCmd, "", "offered")
+	resOfferListCmd.Flags().String("provider", "", "filter by provider")
+
+	resLeaseOpenCmd.Flags().String("client", "", "client address [hex]")
+	resLeaseOpenCmd.Flags().String("offer", "", "offer id")
+	resLeaseOpenCmd.Flags().Uint64("deposit", 0, "escrow deposit")
+	addResSpecFlags(resLeaseOpenCmd, "", "reserved")
+	resLeaseGetCmd.Flags().String("id", "", "lease id")
+	resLeaseListCmd.Flags().String("provider", "", "filter provider")
+	resLeaseListCmd.Flags().String("client", "", "filter client")
+	resLeaseTopUpCmd.Flags().String("client", "", "client address [hex]")
+	resLeaseTopUpCmd.Flags().String("id", "", "lease id")
+	resLeaseTopUpCmd.Flags().Uint64("amount", 0, "amount to add")
+
+	resUsageSignCmd.Flags().String("file", "", "usage report file (created if missing)")
+	resUsageSignCmd.Flags().String("key", "", "ed25519 private key [hex]")
+	resUsageSignCmd.Flags().Bool("provider", false, "sign as provider (default client)")
+	resUsageSignCmd.Flags().String("lease", "", "lease id")
+	resUsageSignCmd.Flags().Uint64("from", 0, "first block (exclusive)")
+	resUsageSignCmd.Flags().Uint64("to", 0, "last block (inclusive)")
+	addResSpecFlags(resUsageSignCmd, "", "peak used")
+	resUsageSubmitCmd.Flags().String("file", "", "co-signed usage report file")
+	resUsageListCmd.Flags().String("id", "", "lease id")
+
+	for _, c := range []*cobra.Command{resLeaseTerminateCmd, resLeaseDisputeCmd} {
+		c.Flags().String("caller", "", "client or provider address [hex]")
+		c.Flags().String("id", "", "lease id")
+	}
+	resLeaseDisputeCmd.Flags().String("reason", "", "dispute reason")
+	resLeaseArbitrateCmd.Flags().String("arbitrator", "", "authority address [hex]")
+	resLeaseArbitrateCmd.Flags().String("id", "", "lease id")
+	resLeaseArbitrateCmd.Flags().Uint64("refund", 0, "escrow refunded to the client")
+	resLeaseArbitrateCmd.Flags().Bool("terminate", false, "end the lease")
+	resLeaseArbitrateCmd.Flags().String("resolution", "", "resolution note")
+	resLeaseSettleCmd.Flags().Uint64("height", 0, "settle up to height (default current)")
+
+	resCmd.AddCommand(resOfferCreateCmd, resOfferCapacityCmd, resOfferListCmd,
+		resLeaseOpenCmd, resLeaseGetCmd, resLeaseListCmd, resLeaseTopUpCmd,
+		resUsageSignCmd, resUsageSubmitCmd, resUsageListCmd,
+		resLeaseTerminateCmd, resLeaseDisputeCmd, resLeaseArbitrateCmd, resLeaseSettleCmd)
+}
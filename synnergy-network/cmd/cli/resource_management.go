@@ -1,10 +1,8 @@
 package cli
 
 import (
-	"encoding/hex"
 	"fmt"
 	"strconv"
-	"strings"
 
 	"github.com/spf13/cobra"
 	core "synnergy-network/core"
@@ -26,13 +24,7 @@ func init() {
 var ResourceCmd = resourceCmd
 
 func rmParseAddr(h string) (core.Address, error) {
-	var a core.Address
-	b, err := hex.DecodeString(strings.TrimPrefix(h, "0x"))
-	if err != nil || len(b) != len(a) {
-		return a, fmt.Errorf("bad address")
-	}
-	copy(a[:], b)
-	return a, nil
+	return core.DecodeAddress(h)
 }
 
 func rmHandleSet(_ *cobra.Command, args []string) error {
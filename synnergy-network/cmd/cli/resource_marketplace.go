@@ -5,7 +5,6 @@ package cli
 // backed by escrow in the ledger.
 
 import (
-	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -19,13 +18,7 @@ import (
 
 // helpers -------------------------------------------------------------------
 func parseResAddr(hexStr string) (core.Address, error) {
-	var a core.Address
-	b, err := hex.DecodeString(hexStr)
-	if err != nil || len(b) != len(a) {
-		return a, errors.New("address must be 20-byte hex")
-	}
-	copy(a[:], b)
-	return a, nil
+	return core.DecodeAddress(hexStr)
 }
 
 func resBail(err error) {
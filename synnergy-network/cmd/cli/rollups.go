@@ -10,6 +10,7 @@
 //   • finalize    – finalise or revert a batch after the challenge window
 //   • info        – show batch header & state
 //   • list        – list recent batches (paginated)
+//   • proof       – Merkle path of a batch transaction for challenge
 // -----------------------------------------------------------------------------
 // Environment / Config
 //   ROLLUP_API_ADDR – host:port of roll‑up daemon (default "127.0.0.1:7960")
@@ -99,14 +100,14 @@ func sendAndGetID(cli *rollClient, payload map[string]any) (uint64, error) {
 		return 0, err
 	}
 	var resp struct {
-		ID    uint64 `json:"id"`
-		Error string `json:"error,omitempty"`
+		ID    uint64         `json:"id"`
+		Error core.WireError `json:"error,omitempty"`
 	}
 	if err := cli.readJSON(&resp); err != nil {
 		return 0, err
 	}
-	if resp.Error != "" {
-		return 0, errors.New(resp.Error)
+	if resp.Error.Err() != nil {
+		return 0, resp.Error.Err()
 	}
 	return resp.ID, nil
 }
@@ -146,13 +147,13 @@ func infoRPC(ctx context.Context, batchID uint64) (*core.BatchHeader, core.Batch
 	var resp struct {
 		Header core.BatchHeader `json:"header"`
 		State  core.BatchState  `json:"state"`
-		Error  string           `json:"error,omitempty"`
+		Error  core.WireError   `json:"error,omitempty"`
 	}
 	if err := cli.readJSON(&resp); err != nil {
 		return nil, 0, err
 	}
-	if resp.Error != "" {
-		return nil, 0, errors.New(resp.Error)
+	if resp.Error.Err() != nil {
+		return nil, 0, resp.Error.Err()
 	}
 	return &resp.Header, resp.State, nil
 }
@@ -174,13 +175,13 @@ func rollupsListRPC(ctx context.Context, limit int) ([]struct {
 			Header core.BatchHeader `json:"header"`
 			State  core.BatchState  `json:"state"`
 		} `json:"list"`
-		Error string `json:"error,omitempty"`
+		Error core.WireError `json:"error,omitempty"`
 	}
 	if err := cli.readJSON(&resp); err != nil {
 		return nil, err
 	}
-	if resp.Error != "" {
-		return nil, errors.New(resp.Error)
+	if resp.Error.Err() != nil {
+		return nil, resp.Error.Err()
 	}
 	return resp.List, nil
 }
@@ -195,18 +196,40 @@ func txsRPC(ctx context.Context, batchID uint64) ([][]byte, error) {
 		return nil, err
 	}
 	var resp struct {
-		Txs   [][]byte `json:"txs"`
-		Error string   `json:"error,omitempty"`
+		Txs   [][]byte       `json:"txs"`
+		Error core.WireError `json:"error,omitempty"`
 	}
 	if err := cli.readJSON(&resp); err != nil {
 		return nil, err
 	}
-	if resp.Error != "" {
-		return nil, errors.New(resp.Error)
+	if resp.Error.Err() != nil {
+		return nil, resp.Error.Err()
 	}
 	return resp.Txs, nil
 }
 
+func proofRPC(ctx context.Context, batchID uint64, idx uint32) ([][]byte, error) {
+	cli, err := newRollClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer cli.Close()
+	if err := cli.writeJSON(map[string]any{"action": "proof", "batch_id": batchID, "tx_idx": idx}); err != nil {
+		return nil, err
+	}
+	var resp struct {
+		Proof [][]byte       `json:"proof"`
+		Error core.WireError `json:"error,omitempty"`
+	}
+	if err := cli.readJSON(&resp); err != nil {
+		return nil, err
+	}
+	if resp.Error.Err() != nil {
+		return nil, resp.Error.Err()
+	}
+	return resp.Proof, nil
+}
+
 func pauseRPC(ctx context.Context) error {
 	cli, err := newRollClient(ctx)
 	if err != nil {
@@ -235,14 +258,14 @@ func rollupStatusRPC(ctx context.Context) (string, error) {
 		return "", err
 	}
 	var resp struct {
-		Status string `json:"status"`
-		Error  string `json:"error,omitempty"`
+		Status string         `json:"status"`
+		Error  core.WireError `json:"error,omitempty"`
 	}
 	if err := cli.readJSON(&resp); err != nil {
 		return "", err
 	}
-	if resp.Error != "" {
-		return "", errors.New(resp.Error)
+	if resp.Error.Err() != nil {
+		return "", resp.Error.Err()
 	}
 	return resp.Status, nil
 }
@@ -438,6 +461,33 @@ var txsCmd = &cobra.Command{
 	},
 }
 
+// proof -----------------------------------------------------------------------
+var rollupsProofCmd = &cobra.Command{
+	Use:   "proof [batchID] [txIdx]",
+	Short: "Print the Merkle path of a batch transaction, as challenge takes it",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := strconv.ParseUint(args[0], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid batchID: %w", err)
+		}
+		idxU, err := strconv.ParseUint(args[1], 10, 32)
+		if err != nil {
+			return fmt.Errorf("invalid txIdx: %w", err)
+		}
+		ctx, cancel := context.WithTimeout(cmd.Context(), 3*time.Second)
+		defer cancel()
+		proof, err := proofRPC(ctx, id, uint32(idxU))
+		if err != nil {
+			return err
+		}
+		for _, p := range proof {
+			fmt.Println(hex.EncodeToString(p))
+		}
+		return nil
+	},
+}
+
 // pause -----------------------------------------------------------------------
 var rollupsPauseCmd = &cobra.Command{
 	Use:   "pause",
@@ -514,6 +564,7 @@ func init() {
 	rollCmd.AddCommand(rollupsInfoCmd)
 	rollCmd.AddCommand(rollupsListCmd)
 	rollCmd.AddCommand(txsCmd)
+	rollCmd.AddCommand(rollupsProofCmd)
 	rollCmd.AddCommand(rollupsPauseCmd)
 	rollCmd.AddCommand(rollupsResumeCmd)
 	rollCmd.AddCommand(rollupsStatusCmd)
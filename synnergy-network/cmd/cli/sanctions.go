@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	core "synnergy-network/core"
+)
+
+func ensureSanctions(cmd *cobra.Command, _ []string) error {
+	if core.Sanctions() != nil {
+		return nil
+	}
+	led := core.CurrentLedger()
+	if led == nil {
+		return fmt.Errorf("ledger not initialised")
+	}
+	_, err := core.InitSanctionsRegistry(led)
+	return err
+}
+
+func sanctionsPrint(v interface{}) {
+	out, _ := json.MarshalIndent(v, "", "  ")
+	fmt.Println(string(out))
+}
+
+type enforceFn func(r *core.SanctionsRegistry, regulator, target core.Address, reason, ref string) (*core.EnforcementAction, error)
+
+func enforcementCmd(use, short string, fn enforceFn) *cobra.Command {
+	c := &cobra.Command{
+		Use:   use + " <regulator> <addr> <reason>",
+		Short: short,
+		Args:  cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ref, _ := cmd.Flags().GetString("ref")
+			act, err := fn(core.Sanctions(), mustHex(args[0]), mustHex(args[1]), args[2], ref)
+			if err != nil {
+				return err
+			}
+			sanctionsPrint(act)
+			return nil
+		},
+	}
+	c.Flags().String("ref", "", "external case or list reference")
+	return c
+}
+
+var sanctionsCmd = &cobra.Command{
+	Use:               "sanctions",
+	Short:             "Regulator blacklist and freeze registry",
+	PersistentPreRunE: ensureSanctions,
+}
+
+var sanctionsStatusCmd = &cobra.Command{
+	Use:   "status <addr>",
+	Short: "Show enforcement status of an address",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		sanctionsPrint(core.Sanctions().Status(mustHex(args[0])))
+	},
+}
+
+var sanctionsActionsCmd = &cobra.Command{
+	Use:   "actions <addr>",
+	Short: "Show the enforcement history of an address",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		list, err := core.Sanctions().Actions(mustHex(args[0]))
+		if err != nil {
+			return err
+		}
+		sanctionsPrint(list)
+		return nil
+	},
+}
+
+var sanctionsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List blacklisted and frozen addresses",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		sanctionsPrint(core.Sanctions().Listed())
+	},
+}
+
+func init() {
+	sanctionsCmd.AddCommand(
+		enforcementCmd("blacklist", "Add an address to the sanctions list", (*core.SanctionsRegistry).Blacklist),
+		enforcementCmd("unblacklist", "Remove an address from the sanctions list", (*core.SanctionsRegistry).Unblacklist),
+		enforcementCmd("freeze", "Freeze all transfers of an address", (*core.SanctionsRegistry).Freeze),
+		enforcementCmd("unfreeze", "Lift a freeze", (*core.SanctionsRegistry).Unfreeze),
+		sanctionsStatusCmd,
+		sanctionsActionsCmd,
+		sanctionsListCmd,
+	)
+}
+
+// SanctionsCmd is exported for index.go
+var SanctionsCmd = sanctionsCmd
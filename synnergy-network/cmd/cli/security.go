@@ -14,7 +14,12 @@
 //  dilithium-gen   – generate Dilithium key pair
 //  dilithium-sign  – sign message with Dilithium private key
 //  dilithium-verify– verify Dilithium signature
+//  typed-hash      – digest of a typed structured data document
+//  sign-typed      – sign typed structured data with an Ed25519 key
+//  verify-typed    – verify a typed structured data signature
 //  anomaly-score   – compute anomaly score for a value
+//  audit verify    – verify an exported audit log against ledger checkpoints
+//  audit prove     – build an inclusion proof for one audit entry
 // -----------------------------------------------------------------------------
 // Environment
 //   SECURITY_API_ADDR – host:port of security daemon (default "127.0.0.1:7970")
@@ -93,14 +98,14 @@ func signRPC(ctx context.Context, algo, keyHex, msgHex string) (string, error) {
 		return "", err
 	}
 	var resp struct {
-		Sig   string `json:"sig"`
-		Error string `json:"error,omitempty"`
+		Sig   string         `json:"sig"`
+		Error core.WireError `json:"error,omitempty"`
 	}
 	if err := cli.readJSON(&resp); err != nil {
 		return "", err
 	}
-	if resp.Error != "" {
-		return "", errors.New(resp.Error)
+	if resp.Error.Err() != nil {
+		return "", resp.Error.Err()
 	}
 	return resp.Sig, nil
 }
@@ -115,14 +120,14 @@ func verifyRPC(ctx context.Context, algo, pubHex, msgHex, sigHex string) (bool,
 		return false, err
 	}
 	var resp struct {
-		Ok    bool   `json:"ok"`
-		Error string `json:"error,omitempty"`
+		Ok    bool           `json:"ok"`
+		Error core.WireError `json:"error,omitempty"`
 	}
 	if err := cli.readJSON(&resp); err != nil {
 		return false, err
 	}
-	if resp.Error != "" {
-		return false, errors.New(resp.Error)
+	if resp.Error.Err() != nil {
+		return false, resp.Error.Err()
 	}
 	return resp.Ok, nil
 }
@@ -137,14 +142,14 @@ func aggregateRPC(ctx context.Context, sigs []string) (string, error) {
 		return "", err
 	}
 	var resp struct {
-		Agg   string `json:"agg"`
-		Error string `json:"error,omitempty"`
+		Agg   string         `json:"agg"`
+		Error core.WireError `json:"error,omitempty"`
 	}
 	if err := cli.readJSON(&resp); err != nil {
 		return "", err
 	}
-	if resp.Error != "" {
-		return "", errors.New(resp.Error)
+	if resp.Error.Err() != nil {
+		return "", resp.Error.Err()
 	}
 	return resp.Agg, nil
 }
@@ -159,14 +164,14 @@ func encryptRPC(ctx context.Context, keyHex, msgHex string) (string, error) {
 		return "", err
 	}
 	var resp struct {
-		Blob  string `json:"blob"`
-		Error string `json:"error,omitempty"`
+		Blob  string         `json:"blob"`
+		Error core.WireError `json:"error,omitempty"`
 	}
 	if err := cli.readJSON(&resp); err != nil {
 		return "", err
 	}
-	if resp.Error != "" {
-		return "", errors.New(resp.Error)
+	if resp.Error.Err() != nil {
+		return "", resp.Error.Err()
 	}
 	return resp.Blob, nil
 }
@@ -181,14 +186,14 @@ func decryptRPC(ctx context.Context, keyHex, blobHex string) (string, error) {
 		return "", err
 	}
 	var resp struct {
-		Msg   string `json:"msg"`
-		Error string `json:"error,omitempty"`
+		Msg   string         `json:"msg"`
+		Error core.WireError `json:"error,omitempty"`
 	}
 	if err := cli.readJSON(&resp); err != nil {
 		return "", err
 	}
-	if resp.Error != "" {
-		return "", errors.New(resp.Error)
+	if resp.Error.Err() != nil {
+		return "", resp.Error.Err()
 	}
 	return resp.Msg, nil
 }
@@ -203,14 +208,14 @@ func merkleRPC(ctx context.Context, leaves []string) (string, error) {
 		return "", err
 	}
 	var resp struct {
-		Root  string `json:"root"`
-		Error string `json:"error,omitempty"`
+		Root  string         `json:"root"`
+		Error core.WireError `json:"error,omitempty"`
 	}
 	if err := cli.readJSON(&resp); err != nil {
 		return "", err
 	}
-	if resp.Error != "" {
-		return "", errors.New(resp.Error)
+	if resp.Error.Err() != nil {
+		return "", resp.Error.Err()
 	}
 	return resp.Root, nil
 }
@@ -439,6 +444,102 @@ var dilVerifyCmd = &cobra.Command{
 	},
 }
 
+// typed data ------------------------------------------------------------------
+
+// readTypedData loads a typed structured data document (EIP-712 JSON).
+func readTypedData(path string) (core.TypedData, error) {
+	var td core.TypedData
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return td, err
+	}
+	if err := json.Unmarshal(raw, &td); err != nil {
+		return td, fmt.Errorf("bad typed data: %w", err)
+	}
+	return td, nil
+}
+
+var typedHashCmd = &cobra.Command{
+	Use:   "typed-hash <data.json>",
+	Short: "Print the signing digest of typed structured data",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		td, err := readTypedData(args[0])
+		if err != nil {
+			return err
+		}
+		h, err := core.TypedDataHash(td)
+		if err != nil {
+			return err
+		}
+		fmt.Println(hex.EncodeToString(h[:]))
+		return nil
+	},
+}
+
+var signTypedCmd = &cobra.Command{
+	Use:   "sign-typed <data.json>",
+	Short: "Sign typed structured data with an Ed25519 private key (hex)",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		key, _ := cmd.Flags().GetString("key")
+		if key == "" {
+			return errors.New("--key required")
+		}
+		priv, err := hex.DecodeString(key)
+		if err != nil {
+			return fmt.Errorf("bad key: %w", err)
+		}
+		defer core.Wipe(priv)
+		td, err := readTypedData(args[0])
+		if err != nil {
+			return err
+		}
+		sig, err := core.SignTypedData(priv, td)
+		if err != nil {
+			return err
+		}
+		out, _ := json.MarshalIndent(sig, "", "  ")
+		fmt.Println(string(out))
+		return nil
+	},
+}
+
+var verifyTypedCmd = &cobra.Command{
+	Use:   "verify-typed <data.json>",
+	Short: "Verify a typed structured data signature",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sigHex, _ := cmd.Flags().GetString("sig")
+		signerStr, _ := cmd.Flags().GetString("signer")
+		if sigHex == "" || signerStr == "" {
+			return errors.New("--sig --signer required")
+		}
+		sig, err := hex.DecodeString(strings.TrimPrefix(sigHex, "0x"))
+		if err != nil {
+			return fmt.Errorf("bad sig: %w", err)
+		}
+		signer, err := core.DecodeAddress(signerStr)
+		if err != nil {
+			return fmt.Errorf("bad signer: %w", err)
+		}
+		td, err := readTypedData(args[0])
+		if err != nil {
+			return err
+		}
+		ok, err := core.VerifyTypedData(td, sig, signer)
+		if err != nil {
+			return err
+		}
+		if ok {
+			fmt.Println("OK")
+		} else {
+			fmt.Println("FAIL")
+		}
+		return nil
+	},
+}
+
 // anomaly-score ---------------------------------------------------------------
 var anomalyCmd = &cobra.Command{
 	Use:   "anomaly-score",
@@ -463,6 +564,87 @@ var anomalyCmd = &cobra.Command{
 	},
 }
 
+// audit -----------------------------------------------------------------------
+var secAuditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Verify Merkle-anchored audit logs",
+}
+
+// auditLedger opens the ledger used to check checkpoint anchors. An empty
+// path skips the on-chain check.
+func auditLedger(cmd *cobra.Command) (*core.Ledger, error) {
+	path, _ := cmd.Flags().GetString("ledger")
+	if path == "" {
+		return nil, nil
+	}
+	return core.OpenLedger(path)
+}
+
+var secAuditVerifyCmd = &cobra.Command{
+	Use:   "verify <audit.log>",
+	Short: "Re-hash an exported audit log and check every batch root",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		f, err := os.Open(args[0])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		led, err := auditLedger(cmd)
+		if err != nil {
+			return err
+		}
+		res, err := core.VerifyAuditLog(f, led)
+		if err != nil {
+			return err
+		}
+		out, _ := json.MarshalIndent(res, "", "  ")
+		fmt.Println(string(out))
+		if !res.Valid {
+			return errors.New("audit log failed verification")
+		}
+		return nil
+	},
+}
+
+var secAuditProveCmd = &cobra.Command{
+	Use:   "prove <audit.log> <seq>",
+	Short: "Print and check the inclusion proof of one entry",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		seq, err := strconv.ParseUint(args[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid seq: %w", err)
+		}
+		raw, err := os.ReadFile(args[0])
+		if err != nil {
+			return err
+		}
+		var evs []core.AuditEvent
+		for _, line := range strings.Split(string(raw), "\n") {
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			var ev core.AuditEvent
+			if err := json.Unmarshal([]byte(line), &ev); err != nil {
+				return err
+			}
+			evs = append(evs, ev)
+		}
+		p, err := core.ProveAuditEntry(evs, seq)
+		if err != nil {
+			return err
+		}
+		led, err := auditLedger(cmd)
+		if err != nil {
+			return err
+		}
+		out, _ := json.MarshalIndent(p, "", "  ")
+		fmt.Println(string(out))
+		return core.VerifyAuditProof(led, p)
+	},
+}
+
 // -----------------------------------------------------------------------------
 // init – config & route wiring
 // -----------------------------------------------------------------------------
@@ -511,6 +693,11 @@ func init() {
 	dilVerifyCmd.Flags().String("msg", "", "message hex")
 	dilVerifyCmd.Flags().String("sig", "", "signature hex")
 
+	// typed data flags
+	signTypedCmd.Flags().String("key", "", "ed25519 private key hex")
+	verifyTypedCmd.Flags().String("sig", "", "signature hex (sig || pubkey)")
+	verifyTypedCmd.Flags().String("signer", "", "expected signer address")
+
 	// anomaly-score flags
 	anomalyCmd.Flags().String("data", "", "comma separated floats")
 	anomalyCmd.Flags().Float64("value", 0, "value to score")
@@ -525,7 +712,12 @@ func init() {
 	secCmd.AddCommand(dilGenCmd)
 	secCmd.AddCommand(dilSignCmd)
 	secCmd.AddCommand(dilVerifyCmd)
+	secCmd.AddCommand(typedHashCmd, signTypedCmd, verifyTypedCmd)
 	secCmd.AddCommand(anomalyCmd)
+
+	secAuditCmd.PersistentFlags().String("ledger", os.Getenv("LEDGER_PATH"), "ledger holding audit checkpoints (empty skips anchor check)")
+	secAuditCmd.AddCommand(secAuditVerifyCmd, secAuditProveCmd)
+	secCmd.AddCommand(secAuditCmd)
 }
 
 // NewSecurityCommand exposes the consolidated command tree.
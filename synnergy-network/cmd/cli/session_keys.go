@@ -0,0 +1,128 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	core "synnergy-network/core"
+)
+
+func ensureSessionKeys(cmd *cobra.Command, _ []string) error {
+	if core.SessionKeys() != nil {
+		return nil
+	}
+	led := core.CurrentLedger()
+	if led == nil {
+		return fmt.Errorf("ledger not initialised")
+	}
+	if _, err := core.InitSocialRecovery(led); err != nil {
+		return err
+	}
+	_, err := core.InitSessionKeys(led)
+	return err
+}
+
+func sessionPrint(v interface{}) {
+	out, _ := json.MarshalIndent(v, "", "  ")
+	fmt.Println(string(out))
+}
+
+var sessionKeysCmd = &cobra.Command{
+	Use:               "session_keys",
+	Short:             "Authorise scoped session keys with spending limits",
+	PersistentPreRunE: ensureSessionKeys,
+}
+
+var sessionAuthoriseCmd = &cobra.Command{
+	Use:   "authorise <account> <key>",
+	Short: "Authorise a session key for an account",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ttl, _ := cmd.Flags().GetDuration("ttl")
+		maxTx, _ := cmd.Flags().GetUint64("max-tx")
+		daily, _ := cmd.Flags().GetUint64("daily")
+		contracts, _ := cmd.Flags().GetStringSlice("contracts")
+		opcodes, _ := cmd.Flags().GetStringSlice("opcodes")
+		label, _ := cmd.Flags().GetString("label")
+		caller, _ := cmd.Flags().GetString("caller")
+
+		acct := mustHex(args[0])
+		from := acct
+		if caller != "" {
+			from = mustHex(caller)
+		}
+		p := core.SessionKeyPolicy{
+			Account:        acct,
+			Key:            mustHex(args[1]),
+			Label:          label,
+			Expires:        time.Now().Add(ttl).UTC(),
+			MaxPerTx:       maxTx,
+			DailyAllowance: daily,
+			AllowedOpcodes: opcodes,
+		}
+		for _, c := range contracts {
+			p.AllowedContracts = append(p.AllowedContracts, mustHex(c))
+		}
+		out, err := core.SessionKeys().Authorise(from, p)
+		if err != nil {
+			return err
+		}
+		sessionPrint(out)
+		return nil
+	},
+}
+
+var sessionRevokeCmd = &cobra.Command{
+	Use:   "revoke <account> <key>",
+	Short: "Revoke a session key",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		caller, _ := cmd.Flags().GetString("caller")
+		acct := mustHex(args[0])
+		from := acct
+		if caller != "" {
+			from = mustHex(caller)
+		}
+		if err := core.SessionKeys().Revoke(from, acct, mustHex(args[1])); err != nil {
+			return err
+		}
+		fmt.Println("session key revoked")
+		return nil
+	},
+}
+
+var sessionListCmd = &cobra.Command{
+	Use:   "list <account>",
+	Short: "List session keys and today's usage",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		acct := mustHex(args[0])
+		type row struct {
+			core.SessionKeyPolicy
+			Usage core.SessionKeyUsage `json:"usage"`
+		}
+		var out []row
+		for _, p := range core.SessionKeys().List(acct) {
+			out = append(out, row{p, core.SessionKeys().Usage(acct, p.Key)})
+		}
+		sessionPrint(out)
+	},
+}
+
+func init() {
+	sessionAuthoriseCmd.Flags().Duration("ttl", 24*time.Hour, "session key lifetime")
+	sessionAuthoriseCmd.Flags().Uint64("max-tx", 0, "maximum value per transaction (0 = unlimited)")
+	sessionAuthoriseCmd.Flags().Uint64("daily", 0, "daily allowance (0 = unlimited)")
+	sessionAuthoriseCmd.Flags().StringSlice("contracts", nil, "allowed recipient addresses")
+	sessionAuthoriseCmd.Flags().StringSlice("opcodes", nil, "allowed payload opcode names")
+	sessionAuthoriseCmd.Flags().String("label", "", "human readable label")
+	sessionAuthoriseCmd.Flags().String("caller", "", "current signer if the account was recovered")
+	sessionRevokeCmd.Flags().String("caller", "", "current signer if the account was recovered")
+	sessionKeysCmd.AddCommand(sessionAuthoriseCmd, sessionRevokeCmd, sessionListCmd)
+}
+
+// SessionKeysCmd exposes session key management.
+var SessionKeysCmd = sessionKeysCmd
@@ -32,6 +32,8 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+
+	core "synnergy-network/core"
 )
 
 // -----------------------------------------------------------------------------
@@ -87,14 +89,14 @@ func getLeaderRPC(ctx context.Context, shard uint16) (string, error) {
 		return "", err
 	}
 	var resp struct {
-		Addr  string `json:"addr"`
-		Error string `json:"error,omitempty"`
+		Addr  string         `json:"addr"`
+		Error core.WireError `json:"error,omitempty"`
 	}
 	if err := cli.readJSON(&resp); err != nil {
 		return "", err
 	}
-	if resp.Error != "" {
-		return "", errors.New(resp.Error)
+	if resp.Error.Err() != nil {
+		return "", resp.Error.Err()
 	}
 	return resp.Addr, nil
 }
@@ -119,13 +121,13 @@ func mapRPC(ctx context.Context) (map[string]string, error) {
 	}
 	var resp struct {
 		Map   map[string]string `json:"map"`
-		Error string            `json:"error,omitempty"`
+		Error core.WireError    `json:"error,omitempty"`
 	}
 	if err := cli.readJSON(&resp); err != nil {
 		return nil, err
 	}
-	if resp.Error != "" {
-		return nil, errors.New(resp.Error)
+	if resp.Error.Err() != nil {
+		return nil, resp.Error.Err()
 	}
 	return resp.Map, nil
 }
@@ -150,13 +152,13 @@ func pullRPC(ctx context.Context, shard uint16, limit int) ([]map[string]any, er
 	}
 	var resp struct {
 		List  []map[string]any `json:"list"`
-		Error string           `json:"error,omitempty"`
+		Error core.WireError   `json:"error,omitempty"`
 	}
 	if err := cli.readJSON(&resp); err != nil {
 		return nil, err
 	}
-	if resp.Error != "" {
-		return nil, errors.New(resp.Error)
+	if resp.Error.Err() != nil {
+		return nil, resp.Error.Err()
 	}
 	return resp.List, nil
 }
@@ -180,14 +182,14 @@ func rebalanceRPC(ctx context.Context, threshold float64) ([]uint16, error) {
 		return nil, err
 	}
 	var resp struct {
-		Hot   []uint16 `json:"hot"`
-		Error string   `json:"error,omitempty"`
+		Hot   []uint16       `json:"hot"`
+		Error core.WireError `json:"error,omitempty"`
 	}
 	if err := cli.readJSON(&resp); err != nil {
 		return nil, err
 	}
-	if resp.Error != "" {
-		return nil, errors.New(resp.Error)
+	if resp.Error.Err() != nil {
+		return nil, resp.Error.Err()
 	}
 	return resp.Hot, nil
 }
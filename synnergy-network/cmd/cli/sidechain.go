@@ -11,6 +11,11 @@
 //   withdraw      – verify L2→L1 withdrawal proof
 //   meta          – show side‑chain metadata
 //   list          – list all registered side‑chains
+//   checkpoint    – anchor a signed checkpoint in main‑chain state
+//   checkpoints   – list checkpoints for a side‑chain
+//   dispute       – challenge a checkpoint inside its window
+//   resolve       – rule on an invalid‑state dispute (authority only)
+//   finalize      – finalise checkpoints whose window has closed
 // -----------------------------------------------------------------------------
 // Environment / Config
 //   SIDECHAIN_API_ADDR – host:port of coordinator daemon (default "127.0.0.1:7990")
@@ -33,6 +38,8 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+
+	core "synnergy-network/core"
 )
 
 // -----------------------------------------------------------------------------
@@ -107,13 +114,13 @@ func getHeaderRPC(ctx context.Context, chain uint32, height uint64) (map[string]
 	}
 	var resp struct {
 		Header map[string]any `json:"header"`
-		Error  string         `json:"error,omitempty"`
+		Error  core.WireError `json:"error,omitempty"`
 	}
 	if err := cli.readJSON(&resp); err != nil {
 		return nil, err
 	}
-	if resp.Error != "" {
-		return nil, errors.New(resp.Error)
+	if resp.Error.Err() != nil {
+		return nil, resp.Error.Err()
 	}
 	return resp.Header, nil
 }
@@ -128,14 +135,14 @@ func depositRPC(ctx context.Context, chain uint32, from string, to string, token
 		return 0, err
 	}
 	var resp struct {
-		Nonce uint64 `json:"nonce"`
-		Error string `json:"error,omitempty"`
+		Nonce uint64         `json:"nonce"`
+		Error core.WireError `json:"error,omitempty"`
 	}
 	if err := cli.readJSON(&resp); err != nil {
 		return 0, err
 	}
-	if resp.Error != "" {
-		return 0, errors.New(resp.Error)
+	if resp.Error.Err() != nil {
+		return 0, resp.Error.Err()
 	}
 	return resp.Nonce, nil
 }
@@ -160,13 +167,13 @@ func metaRPC(ctx context.Context, id uint32) (map[string]any, error) {
 	}
 	var resp struct {
 		Meta  map[string]any `json:"meta"`
-		Error string         `json:"error,omitempty"`
+		Error core.WireError `json:"error,omitempty"`
 	}
 	if err := cli.readJSON(&resp); err != nil {
 		return nil, err
 	}
-	if resp.Error != "" {
-		return nil, errors.New(resp.Error)
+	if resp.Error.Err() != nil {
+		return nil, resp.Error.Err()
 	}
 	return resp.Meta, nil
 }
@@ -182,13 +189,13 @@ func sidechainListRPC(ctx context.Context) ([]map[string]any, error) {
 	}
 	var resp struct {
 		List  []map[string]any `json:"list"`
-		Error string           `json:"error,omitempty"`
+		Error core.WireError   `json:"error,omitempty"`
 	}
 	if err := cli.readJSON(&resp); err != nil {
 		return nil, err
 	}
-	if resp.Error != "" {
-		return nil, errors.New(resp.Error)
+	if resp.Error.Err() != nil {
+		return nil, resp.Error.Err()
 	}
 	return resp.List, nil
 }
@@ -229,6 +236,90 @@ func removeRPC(ctx context.Context, id uint32) error {
 	return cli.writeJSON(map[string]any{"action": "remove", "id": id})
 }
 
+func checkpointRPC(ctx context.Context, payload map[string]any) error {
+	cli, err := newSCClient(ctx)
+	if err != nil {
+		return err
+	}
+	defer cli.Close()
+	return cli.writeJSON(payload)
+}
+
+func checkpointsRPC(ctx context.Context, id uint32) ([]map[string]any, error) {
+	cli, err := newSCClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer cli.Close()
+	if err := cli.writeJSON(map[string]any{"action": "checkpoints", "id": id}); err != nil {
+		return nil, err
+	}
+	var resp struct {
+		Checkpoints []map[string]any `json:"checkpoints"`
+		Error       core.WireError   `json:"error,omitempty"`
+	}
+	if err := cli.readJSON(&resp); err != nil {
+		return nil, err
+	}
+	if resp.Error.Err() != nil {
+		return nil, resp.Error.Err()
+	}
+	return resp.Checkpoints, nil
+}
+
+func disputeRPC(ctx context.Context, payload map[string]any) (bool, error) {
+	cli, err := newSCClient(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer cli.Close()
+	if err := cli.writeJSON(payload); err != nil {
+		return false, err
+	}
+	var resp struct {
+		Invalidated bool           `json:"invalidated"`
+		Error       core.WireError `json:"error,omitempty"`
+	}
+	if err := cli.readJSON(&resp); err != nil {
+		return false, err
+	}
+	if resp.Error.Err() != nil {
+		return false, resp.Error.Err()
+	}
+	return resp.Invalidated, nil
+}
+
+func resolveDisputeRPC(ctx context.Context, id uint32, height uint64, resolver string, fraud bool) error {
+	cli, err := newSCClient(ctx)
+	if err != nil {
+		return err
+	}
+	defer cli.Close()
+	return cli.writeJSON(map[string]any{"action": "resolve_dispute", "id": id, "height": height, "resolver": resolver, "fraud": fraud})
+}
+
+func sideFinalizeRPC(ctx context.Context, id uint32) (uint64, error) {
+	cli, err := newSCClient(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer cli.Close()
+	if err := cli.writeJSON(map[string]any{"action": "finalize", "id": id}); err != nil {
+		return 0, err
+	}
+	var resp struct {
+		Finalized uint64         `json:"finalized"`
+		Error     core.WireError `json:"error,omitempty"`
+	}
+	if err := cli.readJSON(&resp); err != nil {
+		return 0, err
+	}
+	if resp.Error.Err() != nil {
+		return 0, resp.Error.Err()
+	}
+	return resp.Finalized, nil
+}
+
 // -----------------------------------------------------------------------------
 // Top-level cobra tree
 // -----------------------------------------------------------------------------
@@ -485,6 +576,143 @@ var scRemoveCmd = &cobra.Command{
 	},
 }
 
+// checkpoint -----------------------------------------------------------------
+var checkpointCmd = &cobra.Command{
+	Use:   "checkpoint",
+	Short: "Anchor a validator-signed checkpoint in main-chain state",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		chain, _ := cmd.Flags().GetUint32("chain")
+		height, _ := cmd.Flags().GetUint64("height")
+		hdrHash, _ := cmd.Flags().GetString("header-hash")
+		stateRoot, _ := cmd.Flags().GetString("stateroot")
+		sigAgg, _ := cmd.Flags().GetString("sig")
+		for _, fld := range []string{hdrHash, stateRoot, sigAgg} {
+			if _, err := hex.DecodeString(fld); err != nil || fld == "" {
+				return errors.New("--header-hash --stateroot --sig must be hex")
+			}
+		}
+		ctx, cancel := context.WithTimeout(cmd.Context(), 3*time.Second)
+		defer cancel()
+		return checkpointRPC(ctx, map[string]any{
+			"action":      "checkpoint",
+			"chain":       chain,
+			"height":      height,
+			"header_hash": hdrHash,
+			"state_root":  stateRoot,
+			"sig_agg":     sigAgg,
+		})
+	},
+}
+
+// checkpoints ----------------------------------------------------------------
+var checkpointsCmd = &cobra.Command{
+	Use:   "checkpoints [chainID]",
+	Short: "List checkpoints and their dispute status",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		idU, err := strconv.ParseUint(args[0], 10, 32)
+		if err != nil {
+			return fmt.Errorf("invalid chainID: %w", err)
+		}
+		ctx, cancel := context.WithTimeout(cmd.Context(), 3*time.Second)
+		defer cancel()
+		list, err := checkpointsRPC(ctx, uint32(idU))
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(list)
+	},
+}
+
+// dispute --------------------------------------------------------------------
+var disputeCmd = &cobra.Command{
+	Use:   "dispute",
+	Short: "Challenge a checkpoint inside its challenge window",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		chain, _ := cmd.Flags().GetUint32("chain")
+		height, _ := cmd.Flags().GetUint64("height")
+		from, _ := cmd.Flags().GetString("from")
+		kind, _ := cmd.Flags().GetString("kind")
+		file, _ := cmd.Flags().GetString("header")
+		evidence, _ := cmd.Flags().GetString("evidence")
+		if from == "" {
+			return errors.New("--from required")
+		}
+		payload := map[string]any{
+			"action":     "dispute",
+			"chain":      chain,
+			"height":     height,
+			"challenger": from,
+			"kind":       kind,
+			"evidence":   evidence,
+		}
+		if file != "" {
+			raw, err := os.ReadFile(file)
+			if err != nil {
+				return err
+			}
+			var hdr map[string]any
+			if err := json.Unmarshal(raw, &hdr); err != nil {
+				return err
+			}
+			payload["header"] = hdr
+		}
+		ctx, cancel := context.WithTimeout(cmd.Context(), 4*time.Second)
+		defer cancel()
+		inv, err := disputeRPC(ctx, payload)
+		if err != nil {
+			return err
+		}
+		if inv {
+			fmt.Println("checkpoint invalidated – side-chain rolled back and paused")
+		} else {
+			fmt.Println("dispute opened – deposits and withdrawals frozen")
+		}
+		return nil
+	},
+}
+
+// resolve --------------------------------------------------------------------
+var resolveDisputeCmd = &cobra.Command{
+	Use:   "resolve",
+	Short: "Rule on an invalid-state dispute (authority only)",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		chain, _ := cmd.Flags().GetUint32("chain")
+		height, _ := cmd.Flags().GetUint64("height")
+		from, _ := cmd.Flags().GetString("from")
+		fraud, _ := cmd.Flags().GetBool("fraud")
+		if from == "" {
+			return errors.New("--from required")
+		}
+		ctx, cancel := context.WithTimeout(cmd.Context(), 3*time.Second)
+		defer cancel()
+		return resolveDisputeRPC(ctx, chain, height, from, fraud)
+	},
+}
+
+// finalize -------------------------------------------------------------------
+var sideFinalizeCmd = &cobra.Command{
+	Use:   "finalize [chainID]",
+	Short: "Finalise checkpoints whose challenge window has closed",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		idU, err := strconv.ParseUint(args[0], 10, 32)
+		if err != nil {
+			return fmt.Errorf("invalid chainID: %w", err)
+		}
+		ctx, cancel := context.WithTimeout(cmd.Context(), 3*time.Second)
+		defer cancel()
+		h, err := sideFinalizeRPC(ctx, uint32(idU))
+		if err != nil {
+			return err
+		}
+		fmt.Printf("finalised height: %d\n", h)
+		return nil
+	},
+}
+
 // -----------------------------------------------------------------------------
 // init – config & route wiring
 // -----------------------------------------------------------------------------
@@ -534,6 +762,24 @@ func init() {
 	updateValsCmd.Flags().Uint("threshold", 67, "BLS threshold percent")
 	updateValsCmd.Flags().String("validators", "", "comma-separated BLS pubkeys hex")
 
+	checkpointCmd.Flags().Uint32("chain", 0, "chainID")
+	checkpointCmd.Flags().Uint64("height", 0, "checkpointed header height")
+	checkpointCmd.Flags().String("header-hash", "", "header signing hash hex")
+	checkpointCmd.Flags().String("stateroot", "", "stateRoot hex")
+	checkpointCmd.Flags().String("sig", "", "aggregate signature over the checkpoint digest hex")
+
+	disputeCmd.Flags().Uint32("chain", 0, "chainID")
+	disputeCmd.Flags().Uint64("height", 0, "checkpoint height")
+	disputeCmd.Flags().String("from", "", "challenger address hex")
+	disputeCmd.Flags().String("kind", "equivocation", "equivocation | invalid_state")
+	disputeCmd.Flags().String("header", "", "path to conflicting signed header JSON (equivocation)")
+	disputeCmd.Flags().String("evidence", "", "fraud evidence hex (invalid_state)")
+
+	resolveDisputeCmd.Flags().Uint32("chain", 0, "chainID")
+	resolveDisputeCmd.Flags().Uint64("height", 0, "checkpoint height")
+	resolveDisputeCmd.Flags().String("from", "", "authority address hex")
+	resolveDisputeCmd.Flags().Bool("fraud", false, "uphold the dispute and invalidate the checkpoint")
+
 	// route wiring
 	scCmd.AddCommand(sideRegisterCmd)
 	scCmd.AddCommand(headerCmd)
@@ -546,6 +792,11 @@ func init() {
 	scCmd.AddCommand(resumeCmd)
 	scCmd.AddCommand(updateValsCmd)
 	scCmd.AddCommand(scRemoveCmd)
+	scCmd.AddCommand(checkpointCmd)
+	scCmd.AddCommand(checkpointsCmd)
+	scCmd.AddCommand(disputeCmd)
+	scCmd.AddCommand(resolveDisputeCmd)
+	scCmd.AddCommand(sideFinalizeCmd)
 }
 
 // NewSidechainCommand exposes the consolidated CLI route.
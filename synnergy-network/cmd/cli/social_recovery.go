@@ -0,0 +1,155 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	core "synnergy-network/core"
+)
+
+func ensureSocialRecovery(cmd *cobra.Command, _ []string) error {
+	if core.SocialRecoveryManager() != nil {
+		return nil
+	}
+	led := core.CurrentLedger()
+	if led == nil {
+		return fmt.Errorf("ledger not initialised")
+	}
+	_, err := core.InitSocialRecovery(led)
+	return err
+}
+
+func socialPrint(v interface{}) {
+	out, _ := json.MarshalIndent(v, "", "  ")
+	fmt.Println(string(out))
+}
+
+var socialRecoveryCmd = &cobra.Command{
+	Use:               "social_recovery",
+	Short:             "Guardian-based account recovery with M-of-N approval and timelock",
+	PersistentPreRunE: ensureSocialRecovery,
+}
+
+var socialRegisterCmd = &cobra.Command{
+	Use:   "register <account> <guardian,...> <threshold>",
+	Short: "Register the guardian set of an account",
+	Args:  cobra.ExactArgs(3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var guardians []core.Address
+		for _, g := range strings.Split(args[1], ",") {
+			if g = strings.TrimSpace(g); g != "" {
+				guardians = append(guardians, mustHex(g))
+			}
+		}
+		var threshold int
+		if _, err := fmt.Sscanf(args[2], "%d", &threshold); err != nil {
+			return fmt.Errorf("invalid threshold: %w", err)
+		}
+		delay, _ := cmd.Flags().GetDuration("delay")
+		caller, _ := cmd.Flags().GetString("caller")
+		acct := mustHex(args[0])
+		from := acct
+		if caller != "" {
+			from = mustHex(caller)
+		}
+		cfg, err := core.SocialRecoveryManager().RegisterRecovery(from, acct, guardians, threshold, delay)
+		if err != nil {
+			return err
+		}
+		socialPrint(cfg)
+		return nil
+	},
+}
+
+var socialInitiateCmd = &cobra.Command{
+	Use:   "initiate <guardian> <account> <newSigner>",
+	Short: "Open a recovery request rotating the account to a new signer",
+	Args:  cobra.ExactArgs(3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		req, err := core.SocialRecoveryManager().InitiateRecovery(mustHex(args[0]), mustHex(args[1]), mustHex(args[2]))
+		if err != nil {
+			return err
+		}
+		socialPrint(req)
+		return nil
+	},
+}
+
+var socialApproveCmd = &cobra.Command{
+	Use:   "approve <guardian> <requestID>",
+	Short: "Approve a pending recovery request",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		req, err := core.SocialRecoveryManager().ApproveRecovery(mustHex(args[0]), args[1])
+		if err != nil {
+			return err
+		}
+		socialPrint(req)
+		return nil
+	},
+}
+
+var socialCancelCmd = &cobra.Command{
+	Use:   "cancel <signer> <requestID>",
+	Short: "Cancel a recovery request as the account's current signer",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := core.SocialRecoveryManager().CancelRecovery(mustHex(args[0]), args[1]); err != nil {
+			return err
+		}
+		fmt.Println("recovery request cancelled")
+		return nil
+	},
+}
+
+var socialExecuteCmd = &cobra.Command{
+	Use:   "execute <requestID>",
+	Short: "Rotate the account's signer once the timelock has elapsed",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		req, err := core.SocialRecoveryManager().RecoverAccount(args[0])
+		if err != nil {
+			return err
+		}
+		socialPrint(req)
+		return nil
+	},
+}
+
+var socialStatusCmd = &cobra.Command{
+	Use:   "status <account>",
+	Short: "Show guardians, current signer and recovery requests",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		m := core.SocialRecoveryManager()
+		acct := mustHex(args[0])
+		cfg, err := m.Config(acct)
+		if err != nil && err != core.ErrNotFound {
+			return err
+		}
+		reqs, err := m.Requests(acct)
+		if err != nil {
+			return err
+		}
+		socialPrint(struct {
+			Config   *core.SocialRecoveryConfig   `json:"config,omitempty"`
+			Signer   string                       `json:"signer"`
+			Requests []core.SocialRecoveryRequest `json:"requests"`
+		}{cfg, m.Signer(acct).Hex(), reqs})
+		return nil
+	},
+}
+
+func init() {
+	socialRegisterCmd.Flags().Duration("delay", 48*time.Hour, "timelock between approval and execution")
+	socialRegisterCmd.Flags().String("caller", "", "current signer if the account was already recovered")
+	socialRecoveryCmd.AddCommand(socialRegisterCmd, socialInitiateCmd, socialApproveCmd,
+		socialCancelCmd, socialExecuteCmd, socialStatusCmd)
+}
+
+// SocialRecoveryCmd exposes guardian recovery commands.
+var SocialRecoveryCmd = socialRecoveryCmd
@@ -1,11 +1,9 @@
 package cli
 
 import (
-	"encoding/hex"
 	"fmt"
 	"os"
 	"strconv"
-	"strings"
 	"sync"
 
 	"github.com/joho/godotenv"
@@ -51,13 +49,7 @@ func spInitMiddleware(cmd *cobra.Command, _ []string) error {
 }
 
 func spParseAddr(s string) (core.Address, error) {
-	var a core.Address
-	b, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
-	if err != nil || len(b) != len(a) {
-		return a, fmt.Errorf("bad address")
-	}
-	copy(a[:], b)
-	return a, nil
+	return core.DecodeAddress(s)
 }
 
 func spHandleAdjust(cmd *cobra.Command, args []string) error {
@@ -54,13 +54,7 @@ func channelMiddleware(cmd *cobra.Command, args []string) {
 //-------------------------------------------------------------------------
 
 func parseChannelAddress(hexStr string) (core.Address, error) {
-	var a core.Address
-	b, err := hex.DecodeString(hexStr)
-	if err != nil || len(b) != len(a) {
-		return a, errors.New("address must be 20‑byte hex")
-	}
-	copy(a[:], b)
-	return a, nil
+	return core.DecodeAddress(hexStr)
 }
 
 func parseTokenID(hexStr string) (core.TokenID, error) {
@@ -11,7 +11,6 @@ package cli
 
 import (
 	"context"
-	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -84,13 +83,7 @@ func initStorageMiddleware(cmd *cobra.Command, args []string) {
 // ---------------------------------------------------------------------------
 
 func parseStorageAddress(hexStr string) (core.Address, error) {
-	var a core.Address
-	b, err := hex.DecodeString(hexStr)
-	if err != nil || len(b) != len(a) {
-		return a, errors.New("address must be 20-byte hex")
-	}
-	copy(a[:], b)
-	return a, nil
+	return core.DecodeAddress(hexStr)
 }
 
 func storageBail(err error) {
@@ -129,6 +122,24 @@ func pinHandler(cmd *cobra.Command, args []string) {
 	fmt.Printf("✅ pinned %s (%.2f KB)\n", cid, float64(size)/1024)
 }
 
+func storageUnpinHandler(cmd *cobra.Command, args []string) {
+	cidStr, _ := cmd.Flags().GetString("cid")
+	payerHex, _ := cmd.Flags().GetString("payer")
+
+	if cidStr == "" || payerHex == "" {
+		_ = cmd.Usage()
+		storageBail(errors.New("--cid and --payer are required"))
+	}
+	payer, err := parseStorageAddress(payerHex)
+	storageBail(err)
+
+	ctx, cancel := context.WithTimeout(cmd.Context(), time.Duration(storageFlags.timeoutSec)*time.Second)
+	defer cancel()
+
+	storageBail(storage.Unpin(ctx, cidStr, payer))
+	fmt.Printf("✅ unpinned %s, deposit refunded\n", cidStr)
+}
+
 func getHandler(cmd *cobra.Command, args []string) {
 	cidStr, _ := cmd.Flags().GetString("cid")
 	outPath, _ := cmd.Flags().GetString("out")
@@ -295,6 +306,12 @@ var pinCmd = &cobra.Command{
 	Run:   pinHandler,
 }
 
+var storageUnpinCmd = &cobra.Command{
+	Use:   "unpin",
+	Short: "Release a pin and refund its storage deposit",
+	Run:   storageUnpinHandler,
+}
+
 var storageGetCmd = &cobra.Command{
 	Use:   "get",
 	Short: "Retrieve data by CID (cache → gateway)",
@@ -355,6 +372,10 @@ func init() {
 	pinCmd.Flags().String("file", "", "Path to file to pin [required]")
 	pinCmd.Flags().String("payer", "", "Address paying storage rent (hex) [required]")
 
+	// unpin flags
+	storageUnpinCmd.Flags().String("cid", "", "Content identifier to unpin [required]")
+	storageUnpinCmd.Flags().String("payer", "", "Address that pinned it (hex) [required]")
+
 	// get flags
 	storageGetCmd.Flags().String("cid", "", "Content identifier to fetch [required]")
 	storageGetCmd.Flags().String("out", "-", "Output file or '-' for STDOUT")
@@ -379,6 +400,7 @@ func init() {
 
 	// register sub‑commands
 	storageCmd.AddCommand(pinCmd)
+	storageCmd.AddCommand(storageUnpinCmd)
 	storageCmd.AddCommand(storageGetCmd)
 	storageCmd.AddCommand(listCreateCmd)
 	storageCmd.AddCommand(listGetCmd)
@@ -1,9 +1,16 @@
 package cli
 
 import (
+	"crypto/ed25519"
 	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/spf13/cobra"
 	core "synnergy-network/core"
 )
@@ -19,17 +26,81 @@ func supplyMiddleware(cmd *cobra.Command, args []string) error {
 // Controller wraps core supply chain helpers.
 type SupplyController struct{}
 
-func (c *SupplyController) Register(id, desc, ownerHex, loc string) error {
-	ownerBytes, err := hex.DecodeString(ownerHex)
-	if err != nil || len(ownerBytes) != 20 {
-		return fmt.Errorf("invalid owner address")
+func supplyAddr(h string) (core.Address, error) {
+	return core.DecodeAddress(h)
+}
+
+func (c *SupplyController) Register(id, desc, ownerHex, loc, kind, parent string) error {
+	addr, err := supplyAddr(ownerHex)
+	if err != nil {
+		return err
 	}
-	var addr core.Address
-	copy(addr[:], ownerBytes)
-	item := core.SupplyItem{ID: id, Description: desc, Owner: addr, Location: loc}
+	item := core.SupplyItem{ID: id, Description: desc, Owner: addr, Location: loc, Kind: kind, Parent: parent}
 	return core.RegisterItem(item)
 }
 
+// NewCustody prepares an unsigned custody hand-over for the next sequence
+// number of the item.
+func (c *SupplyController) NewCustody(id, fromHex, toHex, loc string) (*core.CustodyRecord, error) {
+	from, err := supplyAddr(fromHex)
+	if err != nil {
+		return nil, err
+	}
+	to, err := supplyAddr(toHex)
+	if err != nil {
+		return nil, err
+	}
+	chain, err := core.CustodyChain(id)
+	if err != nil {
+		return nil, err
+	}
+	return &core.CustodyRecord{
+		ItemID:    id,
+		Seq:       uint64(len(chain)),
+		From:      from,
+		To:        to,
+		Location:  loc,
+		Timestamp: time.Now().Unix(),
+	}, nil
+}
+
+func readCustody(path string) (*core.CustodyRecord, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var rec core.CustodyRecord
+	if err := json.Unmarshal(raw, &rec); err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
+
+func parseThreshold(s string) (core.SupplyThreshold, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 {
+		return core.SupplyThreshold{}, fmt.Errorf("threshold %q: want metric:min:max", s)
+	}
+	lo, err := strconv.ParseFloat(parts[1], 64)
+	if err != nil {
+		return core.SupplyThreshold{}, err
+	}
+	hi, err := strconv.ParseFloat(parts[2], 64)
+	if err != nil {
+		return core.SupplyThreshold{}, err
+	}
+	return core.SupplyThreshold{Metric: parts[0], Min: lo, Max: hi}, nil
+}
+
+func printSupply(cmd *cobra.Command, v interface{}) error {
+	enc, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), string(enc))
+	return nil
+}
+
 func (c *SupplyController) UpdateLocation(id, loc string) error {
 	return core.UpdateLocation(id, loc)
 }
@@ -55,8 +126,10 @@ var (
 		Short: "Register a new supply item",
 		Args:  cobra.ExactArgs(4),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			kind, _ := cmd.Flags().GetString("kind")
+			parent, _ := cmd.Flags().GetString("parent")
 			ctrl := &SupplyController{}
-			return ctrl.Register(args[0], args[1], args[2], args[3])
+			return ctrl.Register(args[0], args[1], args[2], args[3], kind, parent)
 		},
 	}
 
@@ -94,13 +167,171 @@ var (
 			return nil
 		},
 	}
+
+	supplyChildrenCmd = &cobra.Command{
+		Use:   "children <id>",
+		Short: "List the lots or units of a batch",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			list, err := core.SupplyChildren(args[0])
+			if err != nil {
+				return err
+			}
+			return printSupply(cmd, list)
+		},
+	}
+
+	supplyCustodyNewCmd = &cobra.Command{
+		Use:   "custody-new <id> <from> <to> <location>",
+		Short: "Print an unsigned custody hand-over for both parties to sign",
+		Args:  cobra.ExactArgs(4),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctrl := &SupplyController{}
+			rec, err := ctrl.NewCustody(args[0], args[1], args[2], args[3])
+			if err != nil {
+				return err
+			}
+			return printSupply(cmd, rec)
+		},
+	}
+
+	supplyCustodySignCmd = &cobra.Command{
+		Use:   "custody-sign <record.json> <ed25519-priv-hex>",
+		Short: "Add the sender's or recipient's signature to a hand-over",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rec, err := readCustody(args[0])
+			if err != nil {
+				return err
+			}
+			key, err := hex.DecodeString(args[1])
+			if err != nil || len(key) != ed25519.PrivateKeySize {
+				return errors.New("invalid ed25519 private key")
+			}
+			if err := core.SignCustody(rec, ed25519.PrivateKey(key)); err != nil {
+				return err
+			}
+			raw, _ := json.MarshalIndent(rec, "", "  ")
+			return os.WriteFile(args[0], raw, 0o600)
+		},
+	}
+
+	supplyCustodySubmitCmd = &cobra.Command{
+		Use:   "custody-submit <record.json>",
+		Short: "Submit a hand-over signed by both parties",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rec, err := readCustody(args[0])
+			if err != nil {
+				return err
+			}
+			return core.TransferCustody(*rec)
+		},
+	}
+
+	supplyCustodyCmd = &cobra.Command{
+		Use:   "custody <id>",
+		Short: "Show the signed custody chain of an item",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			chain, err := core.CustodyChain(args[0])
+			if err != nil {
+				return err
+			}
+			return printSupply(cmd, chain)
+		},
+	}
+
+	supplyAttachCmd = &cobra.Command{
+		Use:   "attach-sensor <id> <sensor-id>",
+		Short: "Attach a registered sensor with thresholds to an item",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			specs, _ := cmd.Flags().GetStringSlice("threshold")
+			att := core.SupplySensor{SensorID: args[1]}
+			for _, s := range specs {
+				t, err := parseThreshold(s)
+				if err != nil {
+					return err
+				}
+				att.Thresholds = append(att.Thresholds, t)
+			}
+			return core.AttachSupplySensor(args[0], att)
+		},
+	}
+
+	supplyReadingCmd = &cobra.Command{
+		Use:   "reading <id> <sensor-id> <json>",
+		Short: "Record a sensor reading such as {\"temperature\":4.2}",
+		Args:  cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rd, err := core.RecordSupplyReading(args[0], args[1], []byte(args[2]))
+			if err != nil {
+				return err
+			}
+			return printSupply(cmd, rd)
+		},
+	}
+
+	supplyPollCmd = &cobra.Command{
+		Use:   "poll <id>",
+		Short: "Poll every sensor attached to an item",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			list, err := core.PollSupplySensors(args[0])
+			if err != nil {
+				return err
+			}
+			return printSupply(cmd, list)
+		},
+	}
+
+	supplyAlertsCmd = &cobra.Command{
+		Use:   "alerts <id>",
+		Short: "List threshold breaches of an item",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			list, err := core.SupplyAlerts(args[0])
+			if err != nil {
+				return err
+			}
+			return printSupply(cmd, list)
+		},
+	}
+
+	supplyProvenanceCmd = &cobra.Command{
+		Use:   "provenance <id>",
+		Short: "Show ancestors, custody chain, readings and alerts",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rep, err := core.Provenance(args[0])
+			if err != nil {
+				return err
+			}
+			return printSupply(cmd, rep)
+		},
+	}
 )
 
 func init() {
+	supplyRegisterCmd.Flags().String("kind", "", "item kind (batch, lot, unit)")
+	supplyRegisterCmd.Flags().String("parent", "", "parent batch or lot ID")
+	supplyAttachCmd.Flags().StringSlice("threshold", nil, "metric:min:max bound (repeatable)")
+
 	supplyCmd.AddCommand(supplyRegisterCmd)
 	supplyCmd.AddCommand(supplyUpdateCmd)
 	supplyCmd.AddCommand(supplyStatusCmd)
 	supplyCmd.AddCommand(supplyGetCmd)
+	supplyCmd.AddCommand(supplyChildrenCmd)
+	supplyCmd.AddCommand(supplyCustodyNewCmd)
+	supplyCmd.AddCommand(supplyCustodySignCmd)
+	supplyCmd.AddCommand(supplyCustodySubmitCmd)
+	supplyCmd.AddCommand(supplyCustodyCmd)
+	supplyCmd.AddCommand(supplyAttachCmd)
+	supplyCmd.AddCommand(supplyReadingCmd)
+	supplyCmd.AddCommand(supplyPollCmd)
+	supplyCmd.AddCommand(supplyAlertsCmd)
+	supplyCmd.AddCommand(supplyProvenanceCmd)
 }
 
 var SupplyCmd = supplyCmd
@@ -3,10 +3,18 @@ package cli
 // swarm.go - manage groups of network nodes
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
+	"os/signal"
 	"sync"
+	"syscall"
+	"text/tabwriter"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -81,6 +89,161 @@ func swarmStop(cmd *cobra.Command, _ []string) error {
 	return nil
 }
 
+// swarmFleetFile is the on-disk fleet description used by `swarm run`.
+// Durations use Go syntax such as "2s" or "1m".
+type swarmFleetFile struct {
+	Stagger       string               `json:"stagger"`
+	StopTimeout   string               `json:"stop_timeout"`
+	HealthTimeout string               `json:"health_timeout"`
+	MinBackoff    string               `json:"min_backoff"`
+	MaxBackoff    string               `json:"max_backoff"`
+	StableAfter   string               `json:"stable_after"`
+	Nodes         []core.SwarmNodeSpec `json:"nodes"`
+}
+
+func parseFleetDuration(name, v string) (time.Duration, error) {
+	if v == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", name, err)
+	}
+	return d, nil
+}
+
+func loadSwarmFleet(path string) (*core.SwarmController, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var f swarmFleetFile
+	if err := json.Unmarshal(raw, &f); err != nil {
+		return nil, err
+	}
+	var p core.SwarmPolicy
+	for _, d := range []struct {
+		name string
+		val  string
+		dst  *time.Duration
+	}{
+		{"stagger", f.Stagger, &p.Stagger},
+		{"stop_timeout", f.StopTimeout, &p.StopTimeout},
+		{"health_timeout", f.HealthTimeout, &p.HealthTimeout},
+		{"min_backoff", f.MinBackoff, &p.MinBackoff},
+		{"max_backoff", f.MaxBackoff, &p.MaxBackoff},
+		{"stable_after", f.StableAfter, &p.StableAfter},
+	} {
+		if *d.dst, err = parseFleetDuration(d.name, d.val); err != nil {
+			return nil, err
+		}
+	}
+	ctl := core.NewSwarmController(p)
+	for _, spec := range f.Nodes {
+		if err := ctl.AddNode(spec); err != nil {
+			return nil, err
+		}
+	}
+	return ctl, nil
+}
+
+// swarmRun starts the fleet, serves the controller API and blocks until
+// interrupted, then stops the nodes in reverse order.
+func swarmRun(cmd *cobra.Command, args []string) error {
+	fleet, err := loadSwarmFleet(args[0])
+	if err != nil {
+		return err
+	}
+	swarmCtl.SetFleet(fleet)
+	ctx, stop := signal.NotifyContext(cmd.Context(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	addr, _ := cmd.Flags().GetString("control")
+	srv := &http.Server{Addr: addr, Handler: fleet, ReadTimeout: 10 * time.Second}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			fmt.Fprintln(cmd.ErrOrStderr(), "swarm control api:", err)
+		}
+	}()
+
+	swarmCtl.Start(ctx)
+	<-ctx.Done()
+	_ = srv.Shutdown(context.Background())
+	swarmCtl.Stop()
+	fleet.Close()
+	return nil
+}
+
+func swarmControlURL(cmd *cobra.Command, path string) string {
+	addr, _ := cmd.Flags().GetString("control")
+	return "http://" + addr + path
+}
+
+func swarmControlDo(req *http.Request) ([]byte, error) {
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("%s: %s", resp.Status, bytes.TrimSpace(body))
+	}
+	return body, nil
+}
+
+func swarmPrintStatus(cmd *cobra.Command, body []byte) error {
+	var nodes []core.SwarmNodeState
+	if err := json.Unmarshal(body, &nodes); err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tSTATE\tPID\tHEALTHY\tHEIGHT\tPEERS\tVERSION\tRESTARTS")
+	for _, n := range nodes {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%v\t%d\t%d\t%s\t%d\n", n.ID, n.State, n.PID, n.Healthy, n.Height, n.Peers, n.Version, n.Restarts)
+	}
+	return tw.Flush()
+}
+
+func swarmStatus(cmd *cobra.Command, _ []string) error {
+	req, err := http.NewRequestWithContext(cmd.Context(), http.MethodGet, swarmControlURL(cmd, "/status"), nil)
+	if err != nil {
+		return err
+	}
+	body, err := swarmControlDo(req)
+	if err != nil {
+		return err
+	}
+	return swarmPrintStatus(cmd, body)
+}
+
+func swarmUpgrade(cmd *cobra.Command, args []string) error {
+	version, _ := cmd.Flags().GetString("version")
+	payload, _ := json.Marshal(map[string]string{"binary": args[0], "version": version})
+	req, err := http.NewRequestWithContext(cmd.Context(), http.MethodPost, swarmControlURL(cmd, "/upgrade"), bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	body, err := swarmControlDo(req)
+	if err != nil {
+		return err
+	}
+	return swarmPrintStatus(cmd, body)
+}
+
+func swarmRestart(cmd *cobra.Command, args []string) error {
+	req, err := http.NewRequestWithContext(cmd.Context(), http.MethodPost, swarmControlURL(cmd, "/nodes/"+args[0]+"/restart"), nil)
+	if err != nil {
+		return err
+	}
+	_, err = swarmControlDo(req)
+	return err
+}
+
 var swarmCmd = &cobra.Command{Use: "swarm", Short: "Manage node swarms", PersistentPreRunE: swarmInit}
 
 var swarmAddCmd = &cobra.Command{Use: "add <id> <addr>", Args: cobra.ExactArgs(2), RunE: swarmAdd}
@@ -89,9 +252,18 @@ var swarmBroadcastCmd = &cobra.Command{Use: "broadcast <tx.json>", Args: cobra.E
 var swarmPeersCmd = &cobra.Command{Use: "peers", Args: cobra.NoArgs, RunE: swarmPeers}
 var swarmStartCmd = &cobra.Command{Use: "start", Args: cobra.NoArgs, RunE: swarmStart}
 var swarmStopCmd = &cobra.Command{Use: "stop", Args: cobra.NoArgs, RunE: swarmStop}
+var swarmRunCmd = &cobra.Command{Use: "run <fleet.json>", Short: "Run and supervise a fleet of node processes", Args: cobra.ExactArgs(1), RunE: swarmRun}
+var swarmStatusCmd = &cobra.Command{Use: "status", Short: "Show per-node state, height, peers and version", Args: cobra.NoArgs, RunE: swarmStatus}
+var swarmUpgradeCmd = &cobra.Command{Use: "upgrade <binary>", Short: "Rolling upgrade of the fleet binary", Args: cobra.ExactArgs(1), RunE: swarmUpgrade}
+var swarmRestartCmd = &cobra.Command{Use: "restart <id>", Short: "Restart one fleet node", Args: cobra.ExactArgs(1), RunE: swarmRestart}
 
 func init() {
-	swarmCmd.AddCommand(swarmAddCmd, swarmRemoveCmd, swarmBroadcastCmd, swarmPeersCmd, swarmStartCmd, swarmStopCmd)
+	for _, c := range []*cobra.Command{swarmRunCmd, swarmStatusCmd, swarmUpgradeCmd, swarmRestartCmd} {
+		c.Flags().String("control", "127.0.0.1:7950", "swarm controller API address")
+	}
+	swarmUpgradeCmd.Flags().String("version", "", "version the upgraded nodes must report")
+	swarmCmd.AddCommand(swarmAddCmd, swarmRemoveCmd, swarmBroadcastCmd, swarmPeersCmd, swarmStartCmd, swarmStopCmd,
+		swarmRunCmd, swarmStatusCmd, swarmUpgradeCmd, swarmRestartCmd)
 }
 
 var SwarmCmd = swarmCmd
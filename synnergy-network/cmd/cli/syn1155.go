@@ -3,9 +3,7 @@ package cli
 // SYN1155 CLI provides helpers for managing multi asset tokens.
 
 import (
-	"encoding/hex"
 	"fmt"
-	"strings"
 	"sync"
 
 	"github.com/joho/godotenv"
@@ -39,13 +37,7 @@ func syn1155Init(cmd *cobra.Command, _ []string) error {
 }
 
 func parseAddr1155(h string) (core.Address, error) {
-	var a core.Address
-	b, err := hex.DecodeString(strings.TrimPrefix(h, "0x"))
-	if err != nil || len(b) != len(a) {
-		return a, fmt.Errorf("bad address")
-	}
-	copy(a[:], b)
-	return a, nil
+	return core.DecodeAddress(h)
 }
 
 func handleCreate1155(cmd *cobra.Command, _ []string) error {
@@ -1,7 +1,6 @@
 package cli
 
 import (
-	"encoding/hex"
 	"fmt"
 	"github.com/joho/godotenv"
 	"github.com/sirupsen/logrus"
@@ -39,13 +38,7 @@ func synInit(cmd *cobra.Command, _ []string) error {
 }
 
 func synParseAddr(h string) (core.Address, error) {
-	var a core.Address
-	b, err := hex.DecodeString(trimHex(h))
-	if err != nil || len(b) != len(a) {
-		return a, fmt.Errorf("bad address")
-	}
-	copy(a[:], b)
-	return a, nil
+	return core.DecodeAddress(h)
 }
 
 func synHandleCreate(cmd *cobra.Command, _ []string) error {
@@ -1,11 +1,9 @@
 package cli
 
 import (
-	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"os"
-	"strings"
 	"sync"
 	"time"
 
@@ -40,13 +38,7 @@ func synInit(cmd *cobra.Command, _ []string) error {
 }
 
 func parseSynAddr(s string) (core.Address, error) {
-	var a core.Address
-	b, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
-	if err != nil || len(b) != len(a) {
-		return a, fmt.Errorf("bad address")
-	}
-	copy(a[:], b)
-	return a, nil
+	return core.DecodeAddress(s)
 }
 
 func synHandleIssue(cmd *cobra.Command, _ []string) error {
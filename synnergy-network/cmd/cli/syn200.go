@@ -71,7 +71,7 @@ var syn200IssueCmd = &cobra.Command{
 
 var syn200RetireCmd = &cobra.Command{
 	Use:   "retire <holder> <amount>",
-	Short: "Retire carbon credits",
+	Short: "Permanently retire carbon credits and print the certificate",
 	Args:  cobra.ExactArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		holder := mustAddrSYN200(args[0])
@@ -79,7 +79,95 @@ var syn200RetireCmd = &cobra.Command{
 		if err != nil {
 			return fmt.Errorf("amount uint64: %w", err)
 		}
-		return core.Carbon().RetireCredits(holder, amt)
+		beneficiary, _ := cmd.Flags().GetString("beneficiary")
+		reason, _ := cmd.Flags().GetString("reason")
+		cert, err := core.Carbon().Retire(holder, amt, beneficiary, reason)
+		if err != nil {
+			return err
+		}
+		b, _ := json.MarshalIndent(cert, "", "  ")
+		fmt.Fprintln(cmd.OutOrStdout(), string(b))
+		return nil
+	},
+}
+
+var syn200MintableCmd = &cobra.Command{
+	Use:   "offsets [owner]",
+	Short: "List attested offset records not yet tokenised",
+	Args:  cobra.RangeArgs(0, 1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		owner := core.AddressZero
+		if len(args) == 1 {
+			owner = mustAddrSYN200(args[0])
+		}
+		list, err := core.Carbon().MintableOffsets(owner)
+		if err != nil {
+			return err
+		}
+		b, _ := json.MarshalIndent(list, "", "  ")
+		fmt.Fprintln(cmd.OutOrStdout(), string(b))
+		return nil
+	},
+}
+
+var syn200MintOffsetCmd = &cobra.Command{
+	Use:   "mint-offset <offsetID>",
+	Short: "Mint credits against an attested offset record",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		iss, err := core.Carbon().MintFromOffset(args[0])
+		if err != nil {
+			return err
+		}
+		b, _ := json.MarshalIndent(iss, "", "  ")
+		fmt.Fprintln(cmd.OutOrStdout(), string(b))
+		return nil
+	},
+}
+
+var syn200RetirementsCmd = &cobra.Command{
+	Use:   "retirements [holder]",
+	Short: "List retirement certificates",
+	Args:  cobra.RangeArgs(0, 1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		holder := core.AddressZero
+		if len(args) == 1 {
+			holder = mustAddrSYN200(args[0])
+		}
+		list, err := core.Carbon().Retirements(holder)
+		if err != nil {
+			return err
+		}
+		b, _ := json.MarshalIndent(list, "", "  ")
+		fmt.Fprintln(cmd.OutOrStdout(), string(b))
+		return nil
+	},
+}
+
+var syn200RegistryCmd = &cobra.Command{
+	Use:   "registry",
+	Short: "Show issued, retired and outstanding credit totals",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		b, _ := json.MarshalIndent(core.Carbon().Registry(), "", "  ")
+		fmt.Fprintln(cmd.OutOrStdout(), string(b))
+		return nil
+	},
+}
+
+var syn200ReconcileCmd = &cobra.Command{
+	Use:   "reconcile",
+	Short: "Reconcile the credit registry against offset records and supply",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		rep, err := core.Carbon().Reconcile()
+		if err != nil {
+			return err
+		}
+		b, _ := json.MarshalIndent(rep, "", "  ")
+		fmt.Fprintln(cmd.OutOrStdout(), string(b))
+		if !rep.Consistent {
+			return fmt.Errorf("registry inconsistent: %d issue(s)", len(rep.Issues))
+		}
+		return nil
 	},
 }
 
@@ -156,7 +244,10 @@ var syn200ListCmd = &cobra.Command{
 }
 
 func init() {
-	syn200Cmd.AddCommand(syn200RegisterCmd, syn200IssueCmd, syn200RetireCmd, syn200VerifyCmd, syn200VerListCmd, syn200InfoCmd, syn200ListCmd)
+	syn200RetireCmd.Flags().String("beneficiary", "", "party on whose behalf credits are retired")
+	syn200RetireCmd.Flags().String("reason", "", "retirement reason")
+	syn200Cmd.AddCommand(syn200RegisterCmd, syn200IssueCmd, syn200RetireCmd, syn200VerifyCmd, syn200VerListCmd, syn200InfoCmd, syn200ListCmd,
+		syn200MintableCmd, syn200MintOffsetCmd, syn200RetirementsCmd, syn200RegistryCmd, syn200ReconcileCmd)
 }
 
 var SYN200Cmd = syn200Cmd
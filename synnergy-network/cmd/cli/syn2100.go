@@ -1,7 +1,6 @@
 package cli
 
 import (
-	"encoding/hex"
 	"fmt"
 	"strconv"
 	"time"
@@ -12,13 +11,7 @@ import (
 )
 
 func parseAddr2100(s string) (core.Address, error) {
-	var a core.Address
-	b, err := hex.DecodeString(s)
-	if err != nil || len(b) != len(a) {
-		return a, fmt.Errorf("bad address")
-	}
-	copy(a[:], b)
-	return a, nil
+	return core.DecodeAddress(s)
 }
 
 func handleRegisterDoc(cmd *cobra.Command, args []string) error {
@@ -1,7 +1,6 @@
 package cli
 
 import (
-	"encoding/hex"
 	"fmt"
 	"strings"
 
@@ -21,13 +20,7 @@ func getSYN223Token(idOrSym string) (*core.SYN223Token, error) {
 }
 
 func parseAddr223(h string) (core.Address, error) {
-	var a core.Address
-	b, err := hex.DecodeString(strings.TrimPrefix(h, "0x"))
-	if err != nil || len(b) != len(a) {
-		return a, fmt.Errorf("bad address")
-	}
-	copy(a[:], b)
-	return a, nil
+	return core.DecodeAddress(h)
 }
 
 func syn223HandleWhitelistAdd(cmd *cobra.Command, args []string) error {
@@ -1,7 +1,6 @@
 package cli
 
 import (
-	"encoding/hex"
 	"fmt"
 	"strconv"
 	"time"
@@ -13,13 +12,7 @@ import (
 )
 
 func parseAddr845(s string) (core.Address, error) {
-	var a core.Address
-	b, err := hex.DecodeString(s)
-	if err != nil || len(b) != len(a) {
-		return a, fmt.Errorf("bad address")
-	}
-	copy(a[:], b)
-	return a, nil
+	return core.DecodeAddress(s)
 }
 
 var syn845Cmd = &cobra.Command{Use: "syn845", Short: "Manage SYN845 debt tokens"}
@@ -1,11 +1,10 @@
 package cli
 
 import (
-	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"os"
 	"strconv"
-	"strings"
 	"sync"
 
 	"github.com/joho/godotenv"
@@ -40,13 +39,7 @@ func tmInit(cmd *cobra.Command, _ []string) error {
 }
 
 func tmParseAddr(h string) (core.Address, error) {
-	var a core.Address
-	b, err := hex.DecodeString(strings.TrimPrefix(h, "0x"))
-	if err != nil || len(b) != len(a) {
-		return a, fmt.Errorf("bad address")
-	}
-	copy(a[:], b)
-	return a, nil
+	return core.DecodeAddress(h)
 }
 
 func tmHandleCreate(cmd *cobra.Command, _ []string) error {
@@ -106,6 +99,54 @@ func tmHandleTransfer(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func tmHandlePermit(cmd *cobra.Command, args []string) error {
+	raw, err := os.ReadFile(args[0])
+	if err != nil {
+		return err
+	}
+	var req core.PermitRequest
+	if err := json.Unmarshal(raw, &req); err != nil {
+		return fmt.Errorf("bad permit request: %w", err)
+	}
+	p, sig, err := req.Permit()
+	if err != nil {
+		return err
+	}
+	if err := tmMgr.Permit(p, sig); err != nil {
+		return err
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), "permit applied ✔")
+	return nil
+}
+
+func tmHandlePermitInfo(cmd *cobra.Command, args []string) error {
+	id64, err := strconv.ParseUint(args[0], 10, 32)
+	if err != nil {
+		return fmt.Errorf("token ID uint32: %w", err)
+	}
+	owner, err := tmParseAddr(args[1])
+	if err != nil {
+		return err
+	}
+	var spender *core.Address
+	if s, _ := cmd.Flags().GetString("spender"); s != "" {
+		sp, err := tmParseAddr(s)
+		if err != nil {
+			return err
+		}
+		spender = &sp
+	}
+	value, _ := cmd.Flags().GetUint64("value")
+	deadline, _ := cmd.Flags().GetInt64("deadline")
+	info, err := core.GetPermitInfo(core.TokenID(id64), owner, spender, value, deadline)
+	if err != nil {
+		return err
+	}
+	enc, _ := json.MarshalIndent(info, "", "  ")
+	fmt.Fprintln(cmd.OutOrStdout(), string(enc))
+	return nil
+}
+
 var tokenMgmtCmd = &cobra.Command{
 	Use:               "token_management",
 	Short:             "High level token management",
@@ -115,6 +156,8 @@ var tokenMgmtCmd = &cobra.Command{
 var tmCreateCmd = &cobra.Command{Use: "create", Short: "Create a token", RunE: tmHandleCreate}
 var tmBalCmd = &cobra.Command{Use: "balance <id> <addr>", Short: "Token balance", Args: cobra.ExactArgs(2), RunE: tmHandleBalance}
 var tmTransferCmd = &cobra.Command{Use: "transfer <id>", Short: "Transfer tokens", Args: cobra.ExactArgs(1), RunE: tmHandleTransfer}
+var tmPermitCmd = &cobra.Command{Use: "permit <request.json>", Short: "Apply an owner-signed permit (gasless approval)", Args: cobra.ExactArgs(1), RunE: tmHandlePermit}
+var tmPermitInfoCmd = &cobra.Command{Use: "permit-info <id> <owner>", Short: "Permit nonce and signing domain of an owner", Args: cobra.ExactArgs(2), RunE: tmHandlePermitInfo}
 
 func init() {
 	tmCreateCmd.Flags().String("name", "", "token name")
@@ -134,7 +177,11 @@ func init() {
 	tmTransferCmd.MarkFlagRequired("to")
 	tmTransferCmd.MarkFlagRequired("amt")
 
-	tokenMgmtCmd.AddCommand(tmCreateCmd, tmBalCmd, tmTransferCmd)
+	tmPermitInfoCmd.Flags().String("spender", "", "also print the permit document for this spender")
+	tmPermitInfoCmd.Flags().Uint64("value", 0, "permitted amount")
+	tmPermitInfoCmd.Flags().Int64("deadline", 0, "permit deadline (unix seconds)")
+
+	tokenMgmtCmd.AddCommand(tmCreateCmd, tmBalCmd, tmTransferCmd, tmPermitCmd, tmPermitInfoCmd)
 }
 
 var TokenMgmtCmd = tokenMgmtCmd
@@ -31,7 +31,6 @@ import (
 	"context"
 	"crypto/ecdsa"
 	"crypto/x509"
-	"encoding/hex"
 	"encoding/json"
 	"encoding/pem"
 	"fmt"
@@ -139,8 +138,23 @@ func initTxMiddleware(cmd *cobra.Command, _ []string) error {
 		// 7. Gas calculator – dynamic gas based on opcode costs
 		gasCalc := core.NewDynamicGasCalculator()
 
-		// 8. TxPool
+		// 8. Signer rotation and session keys consulted by VerifySig
+		if _, err := core.InitSocialRecovery(txLedger); err != nil {
+			retErr = fmt.Errorf("init social recovery: %w", err)
+			return
+		}
+		if _, err := core.InitSessionKeys(txLedger); err != nil {
+			retErr = fmt.Errorf("init session keys: %w", err)
+			return
+		}
+
+		// 9. TxPool
 		txPoolSvc = core.NewTxPool(nil, txLedger, authSvc, gasCalc, p2pSvc, 0)
+		if os.Getenv("TX_SCREENING") == "1" {
+			rules := core.DefaultScreeningRules()
+			rules.RequireKYC = os.Getenv("TX_REQUIRE_KYC") == "1"
+			txPoolSvc.EnableScreening(core.InitTxScreener(txLedger, rules))
+		}
 
 		// background processor
 		go txPoolSvc.Run(context.Background())
@@ -159,6 +173,8 @@ type txCreateFlags struct {
 	gasPrice uint64
 	nonce    uint64
 	payload  string
+	tag      uint64
+	memo     string
 	txType   string
 	output   string
 }
@@ -168,11 +184,11 @@ func txHandleCreate(cmd *cobra.Command, _ []string) error {
 
 	var toAddr core.Address
 	if flags.to != "" {
-		b, err := hex.DecodeString(strings.TrimPrefix(flags.to, "0x"))
-		if err != nil || len(b) != len(toAddr) {
-			return fmt.Errorf("invalid --to address")
+		a, err := core.ResolveRecipient(flags.to)
+		if err != nil {
+			return fmt.Errorf("invalid --to address: %w", err)
 		}
-		copy(toAddr[:], b)
+		toAddr = a
 	}
 
 	var t core.TxType
@@ -196,6 +212,9 @@ func txHandleCreate(cmd *cobra.Command, _ []string) error {
 		Nonce:     flags.nonce,
 		Payload:   []byte(flags.payload),
 		Timestamp: time.Now().UnixMilli(),
+
+		DestinationTag: flags.tag,
+		Memo:           flags.memo,
 	}
 	tx.HashTx()
 
@@ -306,6 +325,50 @@ func handlePool(cmd *cobra.Command, _ []string) error {
 	return nil
 }
 
+func handleBundle(cmd *cobra.Command, _ []string) error {
+	in, _ := cmd.Flags().GetString("in")
+	id, _ := cmd.Flags().GetString("id")
+	lane, _ := cmd.Flags().GetUint8("lane")
+	raw, err := ioutil.ReadFile(in)
+	if err != nil {
+		return err
+	}
+	var txs []*core.Transaction
+	if err := json.Unmarshal(raw, &txs); err != nil {
+		return fmt.Errorf("decode bundle: %w", err)
+	}
+	if err := txPoolSvc.SubmitBundle(core.TxBundle{ID: id, Lane: lane, Txs: txs}); err != nil {
+		return fmt.Errorf("bundle reject: %w", err)
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "bundle %s queued in lane %d (%d txs)\n", id, lane, len(txs))
+	return nil
+}
+
+func handleBuild(cmd *cobra.Command, _ []string) error {
+	var opts core.BlockBuilderOptions
+	opts.MaxTxs, _ = cmd.Flags().GetInt("max")
+	opts.GasLimit, _ = cmd.Flags().GetUint64("gas")
+	opts.AccountGasLimit, _ = cmd.Flags().GetUint64("account-gas")
+	opts.BaseFee, _ = cmd.Flags().GetUint64("base-fee")
+	out, err := txPoolSvc.PickTxs(opts)
+	if err != nil {
+		return err
+	}
+	if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		return enc.Encode(out)
+	}
+	for _, tx := range out.Txs {
+		fmt.Fprintf(cmd.OutOrStdout(), "%s nonce=%d gas=%d price=%d\n", tx.IDHex(), tx.Nonce, tx.GasLimit, tx.GasPrice)
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "%d txs, gas %d, tips %d\n", len(out.Txs), out.GasUsed, out.Tips)
+	for id, reason := range out.Rejected {
+		fmt.Fprintf(cmd.OutOrStdout(), "bundle %s rejected: %s\n", id, reason)
+	}
+	return nil
+}
+
 // ──────────────────────────────────────────────────────────────────────────────
 // Cobra commands (primary – declared before init())
 // ──────────────────────────────────────────────────────────────────────────────
@@ -328,7 +391,7 @@ var txCreateCmd = &cobra.Command{
 		if cf.to == "" {
 			return fmt.Errorf("--to required")
 		}
-		if _, err := core.StringToAddress(cf.to); err != nil {
+		if _, err := core.ResolveRecipient(cf.to); err != nil {
 			return fmt.Errorf("invalid --to address: %w", err)
 		}
 
@@ -349,6 +412,11 @@ var txCreateCmd = &cobra.Command{
 
 		cf.nonce, _ = cmd.Flags().GetUint64("nonce")
 		cf.payload, _ = cmd.Flags().GetString("payload")
+		cf.tag, _ = cmd.Flags().GetUint64("tag")
+		cf.memo, _ = cmd.Flags().GetString("memo")
+		if len(cf.memo) > core.MaxTxMemo {
+			return fmt.Errorf("--memo longer than %d bytes", core.MaxTxMemo)
+		}
 		cf.txType, _ = cmd.Flags().GetString("type")
 		cf.output, _ = cmd.Flags().GetString("out")
 		ctx := context.WithValue(cmd.Context(), "flags", cf)
@@ -412,9 +480,25 @@ var txPoolCmd = &cobra.Command{
 	RunE:  handlePool,
 }
 
+// bundle
+var txBundleCmd = &cobra.Command{
+	Use:   "bundle",
+	Short: "Queue a JSON array of signed transactions as a priority-lane bundle",
+	Args:  cobra.NoArgs,
+	RunE:  handleBundle,
+}
+
+// build
+var txBuildCmd = &cobra.Command{
+	Use:   "build",
+	Short: "Select block transactions by effective tip, bundles first",
+	Args:  cobra.NoArgs,
+	RunE:  handleBuild,
+}
+
 func init() {
 	// create flags
-	txCreateCmd.Flags().String("to", "", "hex recipient address (0x…)")
+	txCreateCmd.Flags().String("to", "", "recipient address or .syn name")
 	txCreateCmd.MarkFlagRequired("to")
 	txCreateCmd.Flags().Uint64("value", 0, "value in wei")
 	txCreateCmd.MarkFlagRequired("value")
@@ -422,6 +506,8 @@ func init() {
 	txCreateCmd.Flags().Uint64("price", 1, "gas price in wei")
 	txCreateCmd.Flags().Uint64("nonce", 0, "transaction nonce")
 	txCreateCmd.Flags().String("payload", "", "optional input data (hex/string)")
+	txCreateCmd.Flags().Uint64("tag", 0, "destination tag identifying the customer at an exchange")
+	txCreateCmd.Flags().String("memo", "", "memo for the recipient")
 	txCreateCmd.Flags().String("type", "payment", "payment|call|reversal")
 	txCreateCmd.Flags().String("out", "", "output file path (defaults to stdout)")
 
@@ -439,12 +525,28 @@ func init() {
 	txSubmitCmd.Flags().String("in", "", "signed JSON file")
 	txSubmitCmd.MarkFlagRequired("in")
 
+	// bundle
+	txBundleCmd.Flags().String("in", "", "JSON array of signed transactions")
+	txBundleCmd.MarkFlagRequired("in")
+	txBundleCmd.Flags().String("id", "", "bundle identifier")
+	txBundleCmd.MarkFlagRequired("id")
+	txBundleCmd.Flags().Uint8("lane", 0, "priority lane (0 is filled first)")
+
+	// build
+	txBuildCmd.Flags().Int("max", 0, "maximum transactions (0 = no limit)")
+	txBuildCmd.Flags().Uint64("gas", 0, "block gas limit (0 = governance limit)")
+	txBuildCmd.Flags().Uint64("account-gas", 0, "gas limit per sender (0 = no limit)")
+	txBuildCmd.Flags().Uint64("base-fee", 0, "per-gas base fee")
+	txBuildCmd.Flags().Bool("json", false, "print the selection as JSON")
+
 	// assemble tree
 	txCmd.AddCommand(txCreateCmd)
 	txCmd.AddCommand(txSignCmd)
 	txCmd.AddCommand(txVerifyCmd)
 	txCmd.AddCommand(txSubmitCmd)
 	txCmd.AddCommand(txPoolCmd)
+	txCmd.AddCommand(txBundleCmd)
+	txCmd.AddCommand(txBuildCmd)
 }
 
 // ──────────────────────────────────────────────────────────────────────────────
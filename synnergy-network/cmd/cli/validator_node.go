@@ -1,12 +1,10 @@
 package cli
 
 import (
-	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"os"
 	"strconv"
-	"strings"
 	"sync"
 
 	"github.com/joho/godotenv"
@@ -42,13 +40,7 @@ func valInit(cmd *cobra.Command, _ []string) error {
 }
 
 func parseAddr(s string) (core.Address, error) {
-	var a core.Address
-	b, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
-	if err != nil || len(b) != len(a) {
-		return a, fmt.Errorf("bad address")
-	}
-	copy(a[:], b)
-	return a, nil
+	return core.DecodeAddress(s)
 }
 
 func handleRegister(cmd *cobra.Command, args []string) error {
@@ -169,6 +169,7 @@ type addrFlags struct {
 	pwd    string
 	acct   uint32
 	idx    uint32
+	hex    bool
 }
 
 type walletSignFlags struct {
@@ -256,7 +257,11 @@ func handleAddress(cmd *cobra.Command, _ []string) error {
 	if err != nil {
 		return err
 	}
-	fmt.Fprintln(cmd.OutOrStdout(), addr.Hex())
+	if af.hex {
+		fmt.Fprintln(cmd.OutOrStdout(), addr.ChecksumHex())
+		return nil
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), addr.Bech32())
 	return nil
 }
 
@@ -350,6 +355,7 @@ var addressCmd = &cobra.Command{
 		af.pwd, _ = cmd.Flags().GetString("password")
 		af.acct, _ = cmd.Flags().GetUint32("account")
 		af.idx, _ = cmd.Flags().GetUint32("index")
+		af.hex, _ = cmd.Flags().GetBool("hex")
 		if af.wallet == "" || af.pwd == "" {
 			return errors.New("--wallet and --password required")
 		}
@@ -399,6 +405,7 @@ func init() {
 	addressCmd.Flags().String("password", "", "wallet password")
 	addressCmd.Flags().Uint32("account", 0, "account # (hardened)")
 	addressCmd.Flags().Uint32("index", 0, "index # (hardened)")
+	addressCmd.Flags().Bool("hex", false, "print legacy EIP-55 hex instead of bech32")
 
 	// sign flags
 	signCmd.Flags().String("wallet", "", "wallet file")
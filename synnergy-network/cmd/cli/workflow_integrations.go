@@ -1,7 +1,9 @@
 package cli
 
 import (
+	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/spf13/cobra"
 	core "synnergy-network/core"
@@ -25,9 +27,21 @@ func (w *workflowController) trigger(id, expr string) error {
 func (w *workflowController) webhook(id, url string) error {
 	return core.SetWebhook(id, url)
 }
-func (w *workflowController) run(cmd *cobra.Command, id string) error {
+func (w *workflowController) run(cmd *cobra.Command, id string, input map[string]string) (*core.WorkflowRun, error) {
 	ctx := &cliOpCtx{cmd}
-	return core.ExecuteWorkflow(ctx, id)
+	return core.RunWorkflow(ctx, id, "manual", input)
+}
+func (w *workflowController) step(id string, st core.WorkflowStep) error {
+	return core.AddWorkflowStep(id, st)
+}
+func (w *workflowController) retry(id string, p core.RetryPolicy) error {
+	return core.SetWorkflowRetry(id, p)
+}
+
+func wfPrint(cmd *cobra.Command, v interface{}) error {
+	enc := json.NewEncoder(cmd.OutOrStdout())
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
 }
 
 // cliOpCtx is a minimal OpContext implementation for CLI use.
@@ -39,6 +53,10 @@ func (c *cliOpCtx) Gas(g uint64) error     { return nil }
 var wfCmd = &cobra.Command{
 	Use:   "workflow",
 	Short: "Manage automation workflows",
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		// restore persisted workflows when a KV store is configured
+		_ = core.LoadWorkflows()
+	},
 }
 
 var wfNewCmd = &cobra.Command{
@@ -68,7 +86,7 @@ var wfAddCmd = &cobra.Command{
 }
 
 var wfTriggerCmd = &cobra.Command{
-	Use:  "trigger [id] [cron]",
+	Use:  "trigger [id] [manual|every:<n>|height:<n>|event:<topic>|webhook]",
 	Args: cobra.ExactArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ctrl := &workflowController{}
@@ -97,17 +115,135 @@ var wfRunCmd = &cobra.Command{
 	Use:  "run [id]",
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		input, _ := cmd.Flags().GetStringToString("input")
+		ctrl := &workflowController{}
+		run, err := ctrl.run(cmd, args[0], input)
+		if err != nil {
+			return err
+		}
+		return wfPrint(cmd, run)
+	},
+}
+
+var wfStepCmd = &cobra.Command{
+	Use:   "step [id] [step-id] [function]",
+	Short: "Add a DAG step with dependencies and a condition",
+	Args:  cobra.ExactArgs(3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		after, _ := cmd.Flags().GetStringSlice("after")
+		cond, _ := cmd.Flags().GetString("if")
+		st := core.WorkflowStep{ID: args[1], Op: args[2], DependsOn: after, Condition: cond}
+		if cmd.Flags().Changed("attempts") {
+			attempts, _ := cmd.Flags().GetInt("attempts")
+			backoff, _ := cmd.Flags().GetDuration("backoff")
+			st.Retry = &core.RetryPolicy{MaxAttempts: attempts, Backoff: backoff}
+		}
 		ctrl := &workflowController{}
-		return ctrl.run(cmd, args[0])
+		if err := ctrl.step(args[0], st); err != nil {
+			return err
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), "step added")
+		return nil
+	},
+}
+
+var wfRetryCmd = &cobra.Command{
+	Use:   "retry [id]",
+	Short: "Set the default retry policy",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		attempts, _ := cmd.Flags().GetInt("attempts")
+		backoff, _ := cmd.Flags().GetDuration("backoff")
+		maxBackoff, _ := cmd.Flags().GetDuration("max-backoff")
+		ctrl := &workflowController{}
+		return ctrl.retry(args[0], core.RetryPolicy{MaxAttempts: attempts, Backoff: backoff, MaxBackoff: maxBackoff})
+	},
+}
+
+var wfSecretCmd = &cobra.Command{
+	Use:   "secret [id] [secret]",
+	Short: "Set the HMAC secret for inbound webhooks",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return core.SetWorkflowSecret(args[0], args[1])
+	},
+}
+
+var wfGetCmd = &cobra.Command{
+	Use:   "get [id]",
+	Short: "Show a workflow definition",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		wf, err := core.GetWorkflow(args[0])
+		if err != nil {
+			return err
+		}
+		return wfPrint(cmd, wf)
+	},
+}
+
+var wfListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List workflows",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return wfPrint(cmd, core.ListWorkflows())
+	},
+}
+
+var wfRunsCmd = &cobra.Command{
+	Use:   "runs [id]",
+	Short: "List the runs of a workflow",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return wfPrint(cmd, core.ListWorkflowRuns(args[0]))
+	},
+}
+
+var wfStatusCmd = &cobra.Command{
+	Use:   "status [run-id]",
+	Short: "Show a workflow run",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		run, err := core.GetWorkflowRun(args[0])
+		if err != nil {
+			return err
+		}
+		return wfPrint(cmd, run)
+	},
+}
+
+var wfResumeCmd = &cobra.Command{
+	Use:   "resume [run-id]",
+	Short: "Retry the due steps of a running run",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		run, err := core.ResumeWorkflowRun(&cliOpCtx{cmd}, args[0])
+		if err != nil {
+			return err
+		}
+		return wfPrint(cmd, run)
 	},
 }
 
 func init() {
+	wfRunCmd.Flags().StringToString("input", nil, "run input key=value pairs")
+
+	wfStepCmd.Flags().StringSlice("after", nil, "steps this step depends on")
+	wfStepCmd.Flags().String("if", "", "condition: success, failure, always, input.k==v, input.k!=v")
+	wfStepCmd.Flags().Int("attempts", 1, "attempts before the step fails")
+	wfStepCmd.Flags().Duration("backoff", 0, "delay before the first retry")
+
+	wfRetryCmd.Flags().Int("attempts", 3, "attempts before a step fails")
+	wfRetryCmd.Flags().Duration("backoff", 10*time.Second, "delay before the first retry")
+	wfRetryCmd.Flags().Duration("max-backoff", 10*time.Minute, "maximum retry delay")
+
 	wfCmd.AddCommand(wfNewCmd)
 	wfCmd.AddCommand(wfAddCmd)
 	wfCmd.AddCommand(wfTriggerCmd)
 	wfCmd.AddCommand(wfWebhookCmd)
 	wfCmd.AddCommand(wfRunCmd)
+	wfCmd.AddCommand(wfStepCmd, wfRetryCmd, wfSecretCmd, wfGetCmd, wfListCmd, wfRunsCmd, wfStatusCmd, wfResumeCmd)
 }
 
 // WorkflowCmd exported for index registration
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"net/http"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+
+	"synnergy-network/cmd/dataserver/server"
+	core "synnergy-network/core"
+)
+
+func main() {
+	addr := os.Getenv("DATAMARKET_API_ADDR")
+	if addr == "" {
+		addr = ":8083"
+	}
+	ledgerPath := os.Getenv("LEDGER_PATH")
+	if ledgerPath == "" {
+		ledgerPath = "./ledger.db"
+	}
+	if err := core.InitLedger(ledgerPath); err != nil {
+		log.Fatalf("ledger init: %v", err)
+	}
+	core.InitStateChannels(core.CurrentLedger())
+
+	r := server.NewRouter()
+	log.Infof("data marketplace server listening on %s", addr)
+	if err := http.ListenAndServe(addr, r); err != nil {
+		log.Fatalf("server error: %v", err)
+	}
+}
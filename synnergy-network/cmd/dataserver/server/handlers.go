@@ -0,0 +1,191 @@
+package server
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	core "synnergy-network/core"
+)
+
+// ListDataSets returns every registered dataset.
+func ListDataSets(w http.ResponseWriter, _ *http.Request) {
+	list, err := core.ListDataSets()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, list)
+}
+
+// ListListings returns the pricing options for a dataset.
+func ListListings(w http.ResponseWriter, r *http.Request) {
+	list, err := core.ListDataListings(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, list)
+}
+
+// purchaseRequest is a DataPurchase order with the consumer's signature.
+type purchaseRequest struct {
+	Consumer string `json:"consumer"`
+	Price    uint64 `json:"price"`
+	Nonce    uint64 `json:"nonce"`
+	Channel  string `json:"channel,omitempty"`
+	Sig      string `json:"sig"` // hex signature ‖ public key over the order's typed data
+}
+
+func (req purchaseRequest) order(listingID string) (core.DataPurchase, error) {
+	p := core.DataPurchase{ListingID: listingID, Price: req.Price, Nonce: req.Nonce}
+	consumer, err := core.ParseAddress(req.Consumer)
+	if err != nil {
+		return p, err
+	}
+	p.Consumer = consumer
+	if req.Channel != "" {
+		b, err := hex.DecodeString(strings.TrimPrefix(req.Channel, "0x"))
+		if err != nil || len(b) != len(p.Channel) {
+			return p, errors.New("invalid channel id")
+		}
+		copy(p.Channel[:], b)
+	}
+	return p, nil
+}
+
+// Order returns the unsigned purchase order for ?consumer=<addr> and its
+// typed data, ready for the consumer's wallet to sign.
+func Order(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	l, err := core.GetDataListing(id)
+	if err != nil {
+		writeError(w, statusFor(err), err)
+		return
+	}
+	req := purchaseRequest{Consumer: r.URL.Query().Get("consumer"), Channel: r.URL.Query().Get("channel")}
+	p, err := req.order(id)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	p.Price = l.Price
+	p.Nonce = core.DataPurchaseNonce(p.Consumer)
+	writeJSON(w, map[string]interface{}{
+		"order":      p,
+		"typed_data": core.DataPurchaseTypedData(p),
+	})
+}
+
+// Purchase buys an access token with an order signed by the consumer.
+func Purchase(w http.ResponseWriter, r *http.Request) {
+	var req purchaseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	p, err := req.order(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	sig, err := hex.DecodeString(strings.TrimPrefix(req.Sig, "0x"))
+	if err != nil || len(sig) == 0 {
+		writeError(w, http.StatusUnauthorized, core.ErrDataPurchaseSig)
+		return
+	}
+	at, err := core.PurchaseDataAccess(p, sig)
+	if err != nil {
+		writeError(w, statusFor(err), err)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	writeJSON(w, at)
+}
+
+// ListTokens lists the tokens held by ?consumer=<addr>.
+func ListTokens(w http.ResponseWriter, r *http.Request) {
+	consumer, err := core.ParseAddress(r.URL.Query().Get("consumer"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	list, err := core.ListDataAccessTokens(consumer)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, list)
+}
+
+// GetToken shows usage and settlement state of a token.
+func GetToken(w http.ResponseWriter, r *http.Request) {
+	at, err := core.GetDataAccessToken(mux.Vars(r)["token"])
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	writeJSON(w, at)
+}
+
+// Query meters one access and returns the dataset location.
+func Query(w http.ResponseWriter, r *http.Request) {
+	at, ds, err := core.MeterDataAccess(mux.Vars(r)["token"])
+	if err != nil {
+		writeError(w, statusFor(err), err)
+		return
+	}
+	writeJSON(w, map[string]interface{}{
+		"cid":         ds.CID,
+		"queries":     at.Queries,
+		"outstanding": at.Outstanding(),
+	})
+}
+
+// Settle accepts a co-signed channel state covering metered usage.
+func Settle(w http.ResponseWriter, r *http.Request) {
+	var st core.SignedState
+	if err := json.NewDecoder(r.Body).Decode(&st); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	at, err := core.SettleDataUsage(mux.Vars(r)["token"], st)
+	if err != nil {
+		writeError(w, statusFor(err), err)
+		return
+	}
+	writeJSON(w, at)
+}
+
+// statusFor maps marketplace errors to HTTP status codes.
+func statusFor(err error) int {
+	switch {
+	case errors.Is(err, core.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, core.ErrDataPurchaseSig):
+		return http.StatusUnauthorized
+	case errors.Is(err, core.ErrDataAccessDenied):
+		return http.StatusForbidden
+	case errors.Is(err, core.ErrDataPurchaseNonce):
+		return http.StatusConflict
+	case errors.Is(err, core.ErrDataAccessExpired), errors.Is(err, core.ErrUnsettledUsage):
+		return http.StatusPaymentRequired
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// helper to encode JSON
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError answers with the shared error envelope (see core.ErrorEnvelope).
+func writeError(w http.ResponseWriter, status int, err error) {
+	core.WriteHTTPError(w, status, "dataserver", err)
+}
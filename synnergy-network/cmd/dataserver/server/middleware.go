@@ -0,0 +1,19 @@
+package server
+
+import (
+	"net/http"
+
+	core "synnergy-network/core"
+)
+
+// RequestLogger tags each request with an ID and writes one structured
+// access line for it on the data module logger (see core.RequestLogger).
+var RequestLogger = core.RequestLogger("data")
+
+// JSONHeaders sets Content-Type application/json for all responses.
+func JSONHeaders(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,29 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// NewRouter configures the consumer facing data marketplace routes.
+func NewRouter() *mux.Router {
+	r := mux.NewRouter()
+
+	r.Use(RequestLogger)
+	r.Use(JSONHeaders)
+
+	// catalogue
+	r.HandleFunc("/api/datasets", ListDataSets).Methods(http.MethodGet)
+	r.HandleFunc("/api/datasets/{id}/listings", ListListings).Methods(http.MethodGet)
+	r.HandleFunc("/api/listings/{id}/order", Order).Methods(http.MethodGet)
+	r.HandleFunc("/api/listings/{id}/purchase", Purchase).Methods(http.MethodPost)
+
+	// access tokens
+	r.HandleFunc("/api/access", ListTokens).Methods(http.MethodGet)
+	r.HandleFunc("/api/access/{token}", GetToken).Methods(http.MethodGet)
+	r.HandleFunc("/api/access/{token}/query", Query).Methods(http.MethodPost)
+	r.HandleFunc("/api/access/{token}/settle", Settle).Methods(http.MethodPost)
+
+	return r
+}
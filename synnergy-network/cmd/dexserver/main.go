@@ -2,12 +2,20 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
+	"flag"
 	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 
 	core "synnergy-network/core"
 	config "synnergy-network/pkg/config"
+	"synnergy-network/pkg/httpcache"
+	"synnergy-network/pkg/openapi"
 	"synnergy-network/pkg/utils"
 )
 
@@ -20,12 +28,74 @@ type poolView struct {
 	ResB    uint64       `json:"res_b"`
 	TotalLP uint64       `json:"total_lp"`
 	FeeBps  uint16       `json:"fee_bps"`
+	Type    string       `json:"type"`
+	Amp     uint64       `json:"amp,omitempty"`
+
+	ProtocolFeeA uint64 `json:"protocol_fee_a"`
+	ProtocolFeeB uint64 `json:"protocol_fee_b"`
+	ProtocolLP   uint64 `json:"protocol_lp"`
+}
+
+// createPoolRequest is the body of POST /api/pools.
+type createPoolRequest struct {
+	TokenA core.TokenID `json:"token_a" validate:"required"`
+	TokenB core.TokenID `json:"token_b" validate:"required"`
+	FeeBps uint16       `json:"fee_bps" validate:"max=10000"`
+	Type   string       `json:"type"`
+	Amp    uint64       `json:"amp"`
+}
+
+// farmsResponse is the body of GET /api/farms.
+type farmsResponse struct {
+	Controller core.FarmController `json:"controller"`
+	Farms      []core.YieldFarm    `json:"farms"`
+}
+
+// routes is the route table of the DEX API. It drives the mux
+// registration, request validation and the OpenAPI document.
+var routes = []openapi.Route{
+	{Method: http.MethodGet, Path: "/api/pools", Summary: "List liquidity pools",
+		Params:   []openapi.Param{{Name: "type", In: "query", Desc: "constant_product or stable_swap", Type: "string"}},
+		Response: []poolView{}, Handler: poolsHandler},
+	{Method: http.MethodPost, Path: "/api/pools", Summary: "Create a liquidity pool",
+		Request: createPoolRequest{}, Response: map[string]core.PoolID{}, Status: http.StatusCreated, Handler: createPoolHandler},
+	{Method: http.MethodGet, Path: "/api/pools/{id}/apr", Summary: "APR history of a pool",
+		Params: []openapi.Param{
+			{Name: "id", In: "path", Desc: "pool ID", Type: "integer"},
+			{Name: "limit", In: "query", Desc: "keep only the latest samples", Type: "integer"},
+		},
+		Response: []core.PoolAPRSample{}, Handler: poolAPRHandler},
+	{Method: http.MethodGet, Path: "/api/positions/{address}", Summary: "LP positions of an address with fees, impermanent loss and APR",
+		Params:   []openapi.Param{{Name: "address", In: "path", Desc: "hex or bech32 address", Type: "string"}},
+		Response: []core.LPPositionView{}, Handler: positionsHandler},
+	{Method: http.MethodGet, Path: "/api/treasury", Summary: "Protocol fee switch settings and treasury liquidity per pool",
+		Response: []core.TreasuryPoolReport{}, Handler: treasuryHandler},
+	{Method: http.MethodGet, Path: "/api/farms", Summary: "Emission schedule and yield farms",
+		Response: farmsResponse{}, Handler: farmsHandler},
+	{Method: http.MethodGet, Path: "/api/farms/stakes/{address}", Summary: "Farm stakes of an address with pending rewards",
+		Params:   []openapi.Param{{Name: "address", In: "path", Desc: "hex or bech32 address", Type: "string"}},
+		Response: []core.FarmStakeView{}, Handler: farmStakesHandler},
 }
 
-func poolsHandler(w http.ResponseWriter, _ *http.Request) {
+var spec = openapi.Spec{Title: "Synnergy DEX API", Version: "1.0.0", Routes: routes}
+
+// poolsHandler lists pools, optionally filtered by ?type=.
+func poolsHandler(w http.ResponseWriter, r *http.Request) {
+	var filter *core.PoolType
+	if t := r.URL.Query().Get("type"); t != "" {
+		kind, err := core.ParsePoolType(t)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		filter = &kind
+	}
 	pools := core.Manager().Snapshot()
 	out := make([]poolView, 0, len(pools))
 	for _, p := range pools {
+		if filter != nil && p.Type != *filter {
+			continue
+		}
 		pv := poolView{
 			ID:      p.ID,
 			TokenA:  p.TokenA,
@@ -34,6 +104,12 @@ func poolsHandler(w http.ResponseWriter, _ *http.Request) {
 			ResB:    p.ResB,
 			FeeBps:  p.FeeBps,
 			TotalLP: p.TotalLP,
+			Type:    p.Type.String(),
+			Amp:     p.Amp,
+
+			ProtocolFeeA: p.ProtocolFeeA,
+			ProtocolFeeB: p.ProtocolFeeB,
+			ProtocolLP:   p.ProtocolLP,
 		}
 		out = append(out, pv)
 	}
@@ -41,18 +117,153 @@ func poolsHandler(w http.ResponseWriter, _ *http.Request) {
 	_ = json.NewEncoder(w).Encode(out)
 }
 
+func createPoolHandler(w http.ResponseWriter, r *http.Request) {
+	var req createPoolRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, errors.New("invalid request"))
+		return
+	}
+	kind, err := core.ParsePoolType(req.Type)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	pid, err := core.Manager().CreatePool(req.TokenA, req.TokenB, req.FeeBps, core.PoolSpec{Type: kind, Amp: req.Amp})
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(map[string]core.PoolID{"id": pid})
+}
+
+// positionsHandler serves /api/positions/{address}: the LP positions of an
+// address with accrued fees, impermanent loss and APR.
+func positionsHandler(w http.ResponseWriter, r *http.Request) {
+	addr, err := core.ParseAddress(strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, "/api/positions/"), "0x"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errors.New("invalid address"))
+		return
+	}
+	positions, err := core.LPPositions(addr)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if positions == nil {
+		positions = []core.LPPositionView{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(positions)
+}
+
+// poolAPRHandler serves /api/pools/{id}/apr: the APR history of a pool.
+// The optional limit query parameter keeps only the latest samples.
+func poolAPRHandler(w http.ResponseWriter, r *http.Request) {
+	rest := strings.TrimPrefix(r.URL.Path, "/api/pools/")
+	id, err := strconv.ParseUint(strings.TrimSuffix(rest, "/apr"), 10, 32)
+	if err != nil || !strings.HasSuffix(rest, "/apr") {
+		http.NotFound(w, r)
+		return
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	hist, err := core.PoolAPRHistory(core.PoolID(id), limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if hist == nil {
+		hist = []core.PoolAPRSample{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(hist)
+}
+
+// treasuryHandler serves /api/treasury: per pool fee switch settings,
+// protocol fees taken and treasury-owned liquidity.
+func treasuryHandler(w http.ResponseWriter, _ *http.Request) {
+	report, err := core.TreasuryReport()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(report)
+}
+
+// farmsHandler serves /api/farms: the emission schedule and every farm.
+func farmsHandler(w http.ResponseWriter, _ *http.Request) {
+	dm := core.NewDeFiManager(core.CurrentLedger())
+	farms, err := dm.YieldFarms()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if farms == nil {
+		farms = []core.YieldFarm{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(farmsResponse{dm.FarmControllerState(), farms})
+}
+
+// farmStakesHandler serves /api/farms/stakes/{address}: the address's farm
+// stakes with pending rewards.
+func farmStakesHandler(w http.ResponseWriter, r *http.Request) {
+	addr, err := core.ParseAddress(strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, "/api/farms/stakes/"), "0x"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errors.New("invalid address"))
+		return
+	}
+	stakes, err := core.NewDeFiManager(core.CurrentLedger()).FarmStakes(addr)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if stakes == nil {
+		stakes = []core.FarmStakeView{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(stakes)
+}
+
 func main() {
+	openapiFlag := flag.Bool("openapi", false, "print the OpenAPI spec and exit")
+	flag.Parse()
+	if *openapiFlag {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(spec.Document())
+		return
+	}
 	if _, err := config.LoadFromEnv(); err != nil {
 		log.Fatalf("config: %v", err)
 	}
 	if err := core.InitLedger(utils.EnvOrDefault("LEDGER_PATH", "")); err != nil {
 		log.Fatalf("ledger init: %v", err)
 	}
-	logger := log.New()
-	core.InitAMM(logger, nil)
+	logger := core.ModuleLogger("dex")
+	core.InitAMM(logger.Logger, nil)
 
 	addr := utils.EnvOrDefault("DEX_API_ADDR", "127.0.0.1:8081")
-	http.HandleFunc("/api/pools", poolsHandler)
+	mux := http.NewServeMux()
+	for _, rt := range routes {
+		mux.HandleFunc(rt.Method+" "+rt.Path, rt.Handler)
+	}
+	mux.HandleFunc("GET /api/openapi.json", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		httpcache.MaxAge(w, time.Minute)
+		_ = json.NewEncoder(w).Encode(spec.Document())
+	})
+	// Pool reserves change with every swap, so responses are revalidated
+	// by ETag rather than held in memory.
+	cache := httpcache.New(httpcache.Config{})
 	logger.Printf("dexserver listening on %s", addr)
-	logger.Fatal(http.ListenAndServe(addr, nil))
+	handler := core.RequestLogger("dex")(cache.Middleware(spec.Validator("dex")(mux)))
+	logger.Fatal(http.ListenAndServe(addr, handler))
+}
+
+// writeError answers with the shared error envelope (see core.ErrorEnvelope).
+func writeError(w http.ResponseWriter, status int, err error) {
+	core.WriteHTTPError(w, status, "dex", err)
 }
@@ -0,0 +1,375 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	core "synnergy-network/core"
+	"synnergy-network/pkg/httpcache"
+)
+
+// REST API v1. Every route is declared once in v1Routes, which drives both
+// the mux registration and the OpenAPI document served at
+// /api/v1/openapi.json.
+//
+// List endpoints take page (1-based) and per_page query parameters and
+// answer with {"data": [...], "pagination": {...}}. Responses carry an ETag
+// and Cache-Control; blocks and transactions buried finalityDepth blocks
+// deep are served as immutable.
+
+const (
+	defaultPerPage = 25
+	maxPerPage     = 100
+	finalityDepth  = 12
+	listMaxAge     = 5 * time.Second
+)
+
+// apiParam documents a path or query parameter.
+type apiParam struct {
+	Name string
+	In   string // path | query
+	Desc string
+	Type string // string | integer
+}
+
+// apiRoute is one v1 endpoint.
+type apiRoute struct {
+	Path      string // mux path; {name} segments are path parameters
+	Summary   string
+	Params    []apiParam
+	Paginated bool
+	Response  interface{} // zero value of the data type, for the spec
+	handler   func(*Server, ExplorerV1Service) http.HandlerFunc
+}
+
+var v1Routes = []apiRoute{
+	{
+		Path: "/blocks", Summary: "List blocks, newest first", Paginated: true,
+		Response: []BlockSummary{}, handler: (*Server).handleV1Blocks,
+	},
+	{
+		Path: "/blocks/{ref}", Summary: "Get a block by height or hash",
+		Params:   []apiParam{{"ref", "path", "block height or hex hash", "string"}},
+		Response: core.Block{}, handler: (*Server).handleV1Block,
+	},
+	{
+		Path: "/blocks/{ref}/state-diff", Summary: "Get the accounts, storage and contracts changed by a block",
+		Params:   []apiParam{{"ref", "path", "block height or hex hash", "string"}},
+		Response: core.BlockStateDiff{}, handler: (*Server).handleV1BlockStateDiff,
+	},
+	{
+		Path: "/txs/{hash}", Summary: "Get a transaction by hash",
+		Params:   []apiParam{{"hash", "path", "hex transaction hash", "string"}},
+		Response: TxView{}, handler: (*Server).handleV1Tx,
+	},
+	{
+		Path: "/txs/{hash}/state-diff", Summary: "Get the accounts, storage and contracts changed by a transaction",
+		Params:   []apiParam{{"hash", "path", "hex transaction hash", "string"}},
+		Response: core.StateDiff{}, handler: (*Server).handleV1TxStateDiff,
+	},
+	{
+		Path: "/address/{addr}", Summary: "Get balances, tokens and transaction count of an address",
+		Params:   []apiParam{{"addr", "path", "hex or bech32 address, or .syn name", "string"}},
+		Response: AddressView{}, handler: (*Server).handleV1Address,
+	},
+	{
+		Path: "/address/{addr}/txs", Summary: "List transactions of an address, newest first", Paginated: true,
+		Params:   []apiParam{{"addr", "path", "hex or bech32 address, or .syn name", "string"}},
+		Response: []TxView{}, handler: (*Server).handleV1AddressTxs,
+	},
+	{
+		Path: "/tokens", Summary: "List registered tokens", Paginated: true,
+		Response: []TokenView{}, handler: (*Server).handleV1Tokens,
+	},
+	{
+		Path: "/tokens/{id}/holders", Summary: "List token holders, largest balance first", Paginated: true,
+		Params:   []apiParam{{"id", "path", "token id", "integer"}},
+		Response: []HolderView{}, handler: (*Server).handleV1TokenHolders,
+	},
+	{
+		Path: "/names/{name}", Summary: "Get the owner, expiry and resolver records of an SNS name",
+		Params:   []apiParam{{"name", "path", "name, e.g. alice.syn", "string"}},
+		Response: NameView{}, handler: (*Server).handleV1Name,
+	},
+	{
+		Path: "/search", Summary: "Search blocks, transactions, addresses, names and tokens",
+		Params:   []apiParam{{"q", "query", "height, hash, address, .syn name or token symbol", "string"}},
+		Response: []SearchResult{}, handler: (*Server).handleV1Search,
+	},
+	{
+		Path: "/revert-reason", Summary: "Decode a revert payload into a readable failure message",
+		Params:   []apiParam{{"data", "query", "hex revert payload", "string"}},
+		Response: core.RevertReason{}, handler: (*Server).handleV1RevertReason,
+	},
+}
+
+func (s *Server) routesV1(svc ExplorerV1Service) {
+	api := s.router.PathPrefix("/api/v1").Subrouter()
+	api.Use(v1ValidationSpec().Validator("explorer"))
+	for _, rt := range v1Routes {
+		api.HandleFunc(rt.Path, rt.handler(s, svc)).Methods("GET")
+	}
+	api.HandleFunc("/openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		writeCached(w, r, OpenAPISpec(), time.Minute, false)
+	}).Methods("GET")
+}
+
+// pageQuery is the parsed page/per_page of a list request.
+type pageQuery struct {
+	Page    int
+	PerPage int
+}
+
+func (p pageQuery) offset() int { return (p.Page - 1) * p.PerPage }
+
+// pagination is the metadata of a list response.
+type pagination struct {
+	Page    int    `json:"page"`
+	PerPage int    `json:"per_page"`
+	Total   int    `json:"total"`
+	Next    string `json:"next,omitempty"`
+}
+
+type pageResponse struct {
+	Data       interface{} `json:"data"`
+	Pagination pagination  `json:"pagination"`
+}
+
+func parsePage(r *http.Request) (pageQuery, error) {
+	p := pageQuery{Page: 1, PerPage: defaultPerPage}
+	q := r.URL.Query()
+	if v := q.Get("page"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return p, errors.New("invalid page")
+		}
+		p.Page = n
+	}
+	if v := q.Get("per_page"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxPerPage {
+			return p, fmt.Errorf("per_page must be 1-%d", maxPerPage)
+		}
+		p.PerPage = n
+	}
+	return p, nil
+}
+
+func writePage(w http.ResponseWriter, r *http.Request, p pageQuery, data interface{}, total int) {
+	meta := pagination{Page: p.Page, PerPage: p.PerPage, Total: total}
+	if p.offset()+p.PerPage < total {
+		u := url.URL{Path: r.URL.Path, RawQuery: r.URL.RawQuery}
+		q := u.Query()
+		q.Set("page", strconv.Itoa(p.Page+1))
+		q.Set("per_page", strconv.Itoa(p.PerPage))
+		u.RawQuery = q.Encode()
+		meta.Next = u.String()
+	}
+	writeCached(w, r, pageResponse{Data: data, Pagination: meta}, listMaxAge, false)
+}
+
+// writeCached writes v as JSON with an ETag and Cache-Control header and
+// answers 304 when the client already holds the same representation.
+func writeCached(w http.ResponseWriter, r *http.Request, v interface{}, maxAge time.Duration, immutable bool) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	etag := httpcache.ETag(body)
+	if immutable {
+		httpcache.Immutable(w)
+	} else {
+		httpcache.MaxAge(w, maxAge)
+	}
+	w.Header().Set("ETag", etag)
+	if httpcache.NotModified(r, etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(append(body, '\n'))
+}
+
+// writeError answers with the shared error envelope (see core.ErrorEnvelope).
+func writeError(w http.ResponseWriter, code int, err error) {
+	core.WriteHTTPError(w, code, "explorer", err)
+}
+
+// final reports whether height is deep enough to never change.
+func final(svc ExplorerV1Service, height uint64) bool {
+	return svc.Head() >= height+finalityDepth
+}
+
+func (s *Server) handleV1Blocks(svc ExplorerV1Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		p, err := parsePage(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		list, total := svc.Blocks(p.offset(), p.PerPage)
+		writePage(w, r, p, list, total)
+	}
+}
+
+func (s *Server) handleV1Block(svc ExplorerV1Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		blk, err := svc.Block(mux.Vars(r)["ref"])
+		if err != nil {
+			writeError(w, http.StatusNotFound, err)
+			return
+		}
+		writeCached(w, r, blk, listMaxAge, final(svc, blk.Header.Height))
+	}
+}
+
+func (s *Server) handleV1Tx(svc ExplorerV1Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tx, err := svc.Tx(mux.Vars(r)["hash"])
+		if err != nil {
+			writeError(w, statusFor(err), err)
+			return
+		}
+		writeCached(w, r, tx, listMaxAge, final(svc, tx.BlockHeight))
+	}
+}
+
+func (s *Server) handleV1BlockStateDiff(svc ExplorerV1Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		d, err := svc.BlockStateDiff(mux.Vars(r)["ref"])
+		if err != nil {
+			writeError(w, statusFor(err), err)
+			return
+		}
+		writeCached(w, r, d, listMaxAge, final(svc, d.Height))
+	}
+}
+
+func (s *Server) handleV1TxStateDiff(svc ExplorerV1Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		d, err := svc.TxStateDiff(mux.Vars(r)["hash"])
+		if err != nil {
+			writeError(w, statusFor(err), err)
+			return
+		}
+		writeCached(w, r, d, listMaxAge, final(svc, d.Height))
+	}
+}
+
+func (s *Server) handleV1Address(svc ExplorerV1Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		v, err := svc.Address(mux.Vars(r)["addr"])
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		writeCached(w, r, v, listMaxAge, false)
+	}
+}
+
+func (s *Server) handleV1AddressTxs(svc ExplorerV1Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		p, err := parsePage(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		list, total, err := svc.AddressTxs(mux.Vars(r)["addr"], p.offset(), p.PerPage)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		writePage(w, r, p, list, total)
+	}
+}
+
+func (s *Server) handleV1Tokens(svc ExplorerV1Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		p, err := parsePage(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		list, total := svc.Tokens(p.offset(), p.PerPage)
+		writePage(w, r, p, list, total)
+	}
+}
+
+func (s *Server) handleV1TokenHolders(svc ExplorerV1Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, errors.New("invalid token id"))
+			return
+		}
+		p, err := parsePage(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		list, total, err := svc.TokenHolders(core.TokenID(id), p.offset(), p.PerPage)
+		if err != nil {
+			writeError(w, statusFor(err), err)
+			return
+		}
+		writePage(w, r, p, list, total)
+	}
+}
+
+func (s *Server) handleV1Name(svc ExplorerV1Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		v, err := svc.Name(mux.Vars(r)["name"])
+		if err != nil {
+			writeError(w, statusFor(err), err)
+			return
+		}
+		writeCached(w, r, v, listMaxAge, false)
+	}
+}
+
+func (s *Server) handleV1Search(svc ExplorerV1Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q := strings.TrimSpace(r.URL.Query().Get("q"))
+		if q == "" {
+			writeError(w, http.StatusBadRequest, errors.New("q required"))
+			return
+		}
+		res, err := svc.Search(q)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		if res == nil {
+			res = []SearchResult{}
+		}
+		writeCached(w, r, res, listMaxAge, false)
+	}
+}
+
+func (s *Server) handleV1RevertReason(ExplorerV1Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		data, err := hex.DecodeString(strings.TrimPrefix(r.URL.Query().Get("data"), "0x"))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, errors.New("data must be hex"))
+			return
+		}
+		writeCached(w, r, core.DecodeRevert(data), time.Minute, false)
+	}
+}
+
+func statusFor(err error) int {
+	if errors.Is(err, core.ErrNotFound) || errors.Is(err, &core.Error{Code: core.CodeNotFound}) {
+		return http.StatusNotFound
+	}
+	return http.StatusBadRequest
+}
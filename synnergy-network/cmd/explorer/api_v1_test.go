@@ -0,0 +1,190 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	core "synnergy-network/core"
+)
+
+// mockV1Service serves 30 blocks at heights 0..29.
+type mockV1Service struct{ mockService }
+
+func (m *mockV1Service) Head() uint64 { return 29 }
+
+func (m *mockV1Service) Blocks(offset, limit int) ([]BlockSummary, int) {
+	var out []BlockSummary
+	for h := 29 - offset; h >= 0 && len(out) < limit; h-- {
+		out = append(out, BlockSummary{Height: uint64(h)})
+	}
+	return out, 30
+}
+
+func (m *mockV1Service) Block(ref string) (*core.Block, error) {
+	var h uint64
+	if _, err := fmt.Sscan(ref, &h); err != nil || h > 29 {
+		return nil, core.ErrNotFound
+	}
+	return &core.Block{Header: core.BlockHeader{Height: h}}, nil
+}
+
+func (m *mockV1Service) Tx(hash string) (*TxView, error) {
+	if hash != "aa" {
+		return nil, core.ErrNotFound
+	}
+	return &TxView{Hash: "aa", BlockHeight: 28}, nil
+}
+
+func (m *mockV1Service) Address(addr string) (*AddressView, error) {
+	return &AddressView{Address: addr}, nil
+}
+
+func (m *mockV1Service) AddressTxs(addr string, offset, limit int) ([]TxView, int, error) {
+	return []TxView{}, 0, nil
+}
+
+func (m *mockV1Service) Tokens(offset, limit int) ([]TokenView, int) {
+	return []TokenView{{ID: 1, Symbol: "SYN"}}, 1
+}
+
+func (m *mockV1Service) TokenHolders(id core.TokenID, offset, limit int) ([]HolderView, int, error) {
+	if id != 1 {
+		return nil, 0, core.ErrNotFound
+	}
+	return []HolderView{{Address: "0x01", Balance: 5}}, 1, nil
+}
+
+func (m *mockV1Service) Search(q string) ([]SearchResult, error) {
+	return []SearchResult{{Type: "token", ID: "1", Label: q}}, nil
+}
+
+func (m *mockV1Service) Name(name string) (*NameView, error) {
+	if name != "alice.syn" {
+		return nil, core.ErrNotFound
+	}
+	return &NameView{Name: name, Owner: "0x01", Address: "0x01", Active: true}, nil
+}
+
+func (m *mockV1Service) BlockStateDiff(ref string) (*core.BlockStateDiff, error) {
+	blk, err := m.Block(ref)
+	if err != nil {
+		return nil, err
+	}
+	return &core.BlockStateDiff{StateDiff: core.StateDiff{Height: blk.Header.Height}, Source: "execution"}, nil
+}
+
+func (m *mockV1Service) TxStateDiff(hash string) (*core.StateDiff, error) {
+	if hash != "aa" {
+		return nil, core.ErrNotFound
+	}
+	return &core.StateDiff{Height: 28, TxHash: "aa"}, nil
+}
+
+func v1Get(t *testing.T, srv *Server, path string, hdr map[string]string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	for k, v := range hdr {
+		req.Header.Set(k, v)
+	}
+	rr := httptest.NewRecorder()
+	srv.router.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestV1BlocksPagination(t *testing.T) {
+	srv := NewServer(":0", &mockV1Service{})
+	rr := v1Get(t, srv, "/api/v1/blocks?per_page=10&page=2", nil)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+	var res struct {
+		Data       []BlockSummary `json:"data"`
+		Pagination pagination     `json:"pagination"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &res); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(res.Data) != 10 || res.Data[0].Height != 19 {
+		t.Fatalf("unexpected page: %+v", res.Data)
+	}
+	if res.Pagination.Total != 30 || !strings.Contains(res.Pagination.Next, "page=3") {
+		t.Fatalf("unexpected pagination: %+v", res.Pagination)
+	}
+
+	if rr := v1Get(t, srv, "/api/v1/blocks?per_page=500", nil); rr.Code != http.StatusBadRequest {
+		t.Fatalf("oversized page: expected 400, got %d", rr.Code)
+	}
+	if rr := v1Get(t, srv, "/api/v1/blocks?page=3&per_page=10", nil); strings.Contains(rr.Body.String(), `"next"`) {
+		t.Fatalf("last page must not link to a next page: %s", rr.Body.String())
+	}
+}
+
+func TestV1CachingHeaders(t *testing.T) {
+	srv := NewServer(":0", &mockV1Service{})
+	old := v1Get(t, srv, "/api/v1/blocks/3", nil)
+	if cc := old.Header().Get("Cache-Control"); !strings.Contains(cc, "immutable") {
+		t.Fatalf("final block not immutable: %q", cc)
+	}
+	recent := v1Get(t, srv, "/api/v1/blocks/29", nil)
+	if cc := recent.Header().Get("Cache-Control"); strings.Contains(cc, "immutable") {
+		t.Fatalf("head block cached as immutable: %q", cc)
+	}
+	etag := old.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("missing ETag")
+	}
+	if rr := v1Get(t, srv, "/api/v1/blocks/3", map[string]string{"If-None-Match": etag}); rr.Code != http.StatusNotModified {
+		t.Fatalf("expected 304, got %d", rr.Code)
+	}
+}
+
+func TestV1Errors(t *testing.T) {
+	srv := NewServer(":0", &mockV1Service{})
+	if rr := v1Get(t, srv, "/api/v1/txs/bb", nil); rr.Code != http.StatusNotFound {
+		t.Fatalf("unknown tx: expected 404, got %d", rr.Code)
+	}
+	if rr := v1Get(t, srv, "/api/v1/tokens/2/holders", nil); rr.Code != http.StatusNotFound {
+		t.Fatalf("unknown token: expected 404, got %d", rr.Code)
+	}
+	if rr := v1Get(t, srv, "/api/v1/search", nil); rr.Code != http.StatusBadRequest {
+		t.Fatalf("empty search: expected 400, got %d", rr.Code)
+	}
+}
+
+func TestV1OpenAPISpec(t *testing.T) {
+	srv := NewServer(":0", &mockV1Service{})
+	rr := v1Get(t, srv, "/api/v1/openapi.json", nil)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+	var spec struct {
+		Paths      map[string]json.RawMessage `json:"paths"`
+		Components struct {
+			Schemas map[string]json.RawMessage `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &spec); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	for _, rt := range v1Routes {
+		if _, ok := spec.Paths["/api/v1"+rt.Path]; !ok {
+			t.Fatalf("spec missing %s", rt.Path)
+		}
+	}
+	for _, name := range []string{"BlockSummary", "TxView", "Pagination", "ErrorEnvelope"} {
+		if _, ok := spec.Components.Schemas[name]; !ok {
+			t.Fatalf("spec missing schema %s", name)
+		}
+	}
+}
+
+func TestV1RoutesOnlyForV1Service(t *testing.T) {
+	srv := newTestServer()
+	if rr := v1Get(t, srv, "/api/v1/openapi.json", nil); rr.Code == http.StatusOK && strings.Contains(rr.Body.String(), "openapi") {
+		t.Fatal("v1 routes mounted for a service without v1 support")
+	}
+}
@@ -0,0 +1,1106 @@
+package main
+
+// graphql.go – a small GraphQL engine for the explorer.
+//
+// It implements the subset of GraphQL the explorer schema needs: query and
+// subscription operations, variables with defaults, aliases, arguments,
+// nested selection sets, named and inline fragments and __typename. Types
+// are declared in Go (gqlSchema) and every field has a resolver that
+// receives the parent value.
+//
+// Documents are validated before execution: fragment cycles are rejected
+// at parse time and an operation may not nest deeper than gqlMaxDepth or
+// select more than gqlMaxComplexity fields once fragments are expanded.
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ---------------------------------------------------------------------------
+// Schema
+// ---------------------------------------------------------------------------
+
+// gqlArg declares a field argument. Default is used when the argument is
+// omitted.
+type gqlArg struct {
+	Name    string
+	Type    string
+	Default interface{}
+}
+
+// gqlField is an object field. Type uses GraphQL notation, e.g. "[Tx!]!".
+// Subscription root fields set Subscribe instead of Resolve.
+type gqlField struct {
+	Type      string
+	Doc       string
+	Args      []gqlArg
+	Resolve   func(src interface{}, args map[string]interface{}) (interface{}, error)
+	Subscribe func(ctx context.Context, args map[string]interface{}) (<-chan interface{}, error)
+}
+
+// gqlObject is an object type.
+type gqlObject struct {
+	Name   string
+	Doc    string
+	Fields map[string]*gqlField
+}
+
+// gqlSchema holds the object types and the root operation types.
+type gqlSchema struct {
+	Query        string
+	Subscription string
+	Types        map[string]*gqlObject
+}
+
+// Limits applied to every operation before it runs.
+// gqlMaxNesting bounds the parser's recursion through selection sets,
+// inline fragments and list or object values.
+const (
+	gqlMaxDepth      = 12
+	gqlMaxComplexity = 1000
+	gqlMaxNesting    = 64
+)
+
+var gqlScalars = map[string]bool{"Int": true, "Float": true, "String": true, "Boolean": true, "ID": true}
+
+// unwrapType strips non-null markers and reports whether t is a list.
+func unwrapType(t string) (inner string, list bool) {
+	t = strings.TrimSuffix(t, "!")
+	if strings.HasPrefix(t, "[") && strings.HasSuffix(t, "]") {
+		return t[1 : len(t)-1], true
+	}
+	return t, false
+}
+
+// SDL renders the schema in GraphQL schema definition language.
+func (s *gqlSchema) SDL() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "schema {\n  query: %s\n", s.Query)
+	if s.Subscription != "" {
+		fmt.Fprintf(&b, "  subscription: %s\n", s.Subscription)
+	}
+	b.WriteString("}\n")
+	names := make([]string, 0, len(s.Types))
+	for n := range s.Types {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		obj := s.Types[n]
+		b.WriteString("\n")
+		if obj.Doc != "" {
+			fmt.Fprintf(&b, "\"%s\"\n", obj.Doc)
+		}
+		fmt.Fprintf(&b, "type %s {\n", n)
+		fields := make([]string, 0, len(obj.Fields))
+		for f := range obj.Fields {
+			fields = append(fields, f)
+		}
+		sort.Strings(fields)
+		for _, fn := range fields {
+			f := obj.Fields[fn]
+			if f.Doc != "" {
+				fmt.Fprintf(&b, "  \"%s\"\n", f.Doc)
+			}
+			b.WriteString("  " + fn)
+			if len(f.Args) > 0 {
+				args := make([]string, len(f.Args))
+				for i, a := range f.Args {
+					args[i] = a.Name + ": " + a.Type
+					if a.Default != nil {
+						args[i] += fmt.Sprintf(" = %v", a.Default)
+					}
+				}
+				b.WriteString("(" + strings.Join(args, ", ") + ")")
+			}
+			b.WriteString(": " + f.Type + "\n")
+		}
+		b.WriteString("}\n")
+	}
+	return b.String()
+}
+
+// ---------------------------------------------------------------------------
+// Documents
+// ---------------------------------------------------------------------------
+
+type gqlValueKind int
+
+const (
+	gqlNull gqlValueKind = iota
+	gqlInt
+	gqlFloat
+	gqlString
+	gqlBool
+	gqlEnum
+	gqlVariable
+	gqlList
+	gqlObjectValue
+)
+
+type gqlValue struct {
+	Kind   gqlValueKind
+	Raw    string
+	List   []gqlValue
+	Fields map[string]gqlValue
+}
+
+type gqlSelection struct {
+	Alias    string
+	Name     string
+	Args     map[string]gqlValue
+	Sel      []gqlSelection
+	Spread   string // named fragment spread
+	OnType   string // inline fragment type condition
+	isInline bool
+}
+
+type gqlVarDef struct {
+	Name    string
+	Type    string
+	Default *gqlValue
+}
+
+type gqlOperation struct {
+	Type string // query | subscription
+	Name string
+	Vars []gqlVarDef
+	Sel  []gqlSelection
+}
+
+type gqlFragment struct {
+	On  string
+	Sel []gqlSelection
+}
+
+type gqlDocument struct {
+	Ops       []gqlOperation
+	Fragments map[string]gqlFragment
+}
+
+// gqlError is a GraphQL error entry.
+type gqlError struct {
+	Message string        `json:"message"`
+	Path    []interface{} `json:"path,omitempty"`
+}
+
+// gqlResponse is the result of executing a request.
+type gqlResponse struct {
+	Data   interface{} `json:"data"`
+	Errors []gqlError  `json:"errors,omitempty"`
+}
+
+// gqlRequest is the JSON body of a GraphQL request.
+type gqlRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+// ---------------------------------------------------------------------------
+// Lexer and parser
+// ---------------------------------------------------------------------------
+
+type gqlTokKind int
+
+const (
+	tokEOF gqlTokKind = iota
+	tokPunct
+	tokName
+	tokInt
+	tokFloat
+	tokString
+)
+
+type gqlTok struct {
+	kind gqlTokKind
+	val  string
+	pos  int
+}
+
+func gqlLex(src string) ([]gqlTok, error) {
+	var toks []gqlTok
+	r := []rune(src)
+	for i := 0; i < len(r); {
+		c := r[i]
+		switch {
+		case c == ',' || c == '\uFEFF' || unicode.IsSpace(c):
+			i++
+		case c == '#':
+			for i < len(r) && r[i] != '\n' {
+				i++
+			}
+		case strings.ContainsRune("{}()[]:=!$@|&", c):
+			toks = append(toks, gqlTok{tokPunct, string(c), i})
+			i++
+		case c == '.':
+			if i+2 < len(r) && r[i+1] == '.' && r[i+2] == '.' {
+				toks = append(toks, gqlTok{tokPunct, "...", i})
+				i += 3
+				continue
+			}
+			return nil, fmt.Errorf("syntax error at %d: unexpected '.'", i)
+		case c == '_' || unicode.IsLetter(c):
+			j := i
+			for j < len(r) && (r[j] == '_' || unicode.IsLetter(r[j]) || unicode.IsDigit(r[j])) {
+				j++
+			}
+			toks = append(toks, gqlTok{tokName, string(r[i:j]), i})
+			i = j
+		case c == '-' || unicode.IsDigit(c):
+			j := i + 1
+			kind := tokInt
+			for j < len(r) && (unicode.IsDigit(r[j]) || strings.ContainsRune(".eE+-", r[j])) {
+				if strings.ContainsRune(".eE", r[j]) {
+					kind = tokFloat
+				}
+				j++
+			}
+			toks = append(toks, gqlTok{kind, string(r[i:j]), i})
+			i = j
+		case c == '"':
+			var b strings.Builder
+			j := i + 1
+			for ; j < len(r) && r[j] != '"'; j++ {
+				if r[j] == '\\' && j+1 < len(r) {
+					j++
+					switch r[j] {
+					case 'n':
+						b.WriteRune('\n')
+					case 't':
+						b.WriteRune('\t')
+					case 'r':
+						b.WriteRune('\r')
+					case 'u':
+						if j+4 >= len(r) {
+							return nil, fmt.Errorf("syntax error at %d: bad escape", j)
+						}
+						n, err := strconv.ParseUint(string(r[j+1:j+5]), 16, 32)
+						if err != nil {
+							return nil, fmt.Errorf("syntax error at %d: bad escape", j)
+						}
+						b.WriteRune(rune(n))
+						j += 4
+					default:
+						b.WriteRune(r[j])
+					}
+					continue
+				}
+				b.WriteRune(r[j])
+			}
+			if j >= len(r) {
+				return nil, fmt.Errorf("syntax error at %d: unterminated string", i)
+			}
+			toks = append(toks, gqlTok{tokString, b.String(), i})
+			i = j + 1
+		default:
+			return nil, fmt.Errorf("syntax error at %d: unexpected %q", i, c)
+		}
+	}
+	return append(toks, gqlTok{kind: tokEOF, pos: len(r)}), nil
+}
+
+type gqlParser struct {
+	toks  []gqlTok
+	i     int
+	depth int
+}
+
+// enter tracks recursion into nested selection sets and values.
+func (p *gqlParser) enter() error {
+	p.depth++
+	if p.depth > gqlMaxNesting {
+		return fmt.Errorf("syntax error at %d: document nested too deeply", p.peek().pos)
+	}
+	return nil
+}
+
+func (p *gqlParser) peek() gqlTok { return p.toks[p.i] }
+
+// next consumes a token; the trailing EOF token is never consumed.
+func (p *gqlParser) next() gqlTok {
+	t := p.toks[p.i]
+	if t.kind != tokEOF {
+		p.i++
+	}
+	return t
+}
+
+func (p *gqlParser) is(val string) bool {
+	t := p.peek()
+	return t.kind == tokPunct && t.val == val
+}
+
+func (p *gqlParser) expect(val string) error {
+	t := p.next()
+	if t.kind != tokPunct || t.val != val {
+		return fmt.Errorf("syntax error at %d: expected %q, got %q", t.pos, val, t.val)
+	}
+	return nil
+}
+
+func (p *gqlParser) name() (string, error) {
+	t := p.next()
+	if t.kind != tokName {
+		return "", fmt.Errorf("syntax error at %d: expected name, got %q", t.pos, t.val)
+	}
+	return t.val, nil
+}
+
+func parseGraphQL(src string) (*gqlDocument, error) {
+	toks, err := gqlLex(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &gqlParser{toks: toks}
+	doc := &gqlDocument{Fragments: map[string]gqlFragment{}}
+	for p.peek().kind != tokEOF {
+		if p.is("{") {
+			sel, err := p.selectionSet()
+			if err != nil {
+				return nil, err
+			}
+			doc.Ops = append(doc.Ops, gqlOperation{Type: "query", Sel: sel})
+			continue
+		}
+		kw, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		switch kw {
+		case "query", "subscription", "mutation":
+			op, err := p.operation(kw)
+			if err != nil {
+				return nil, err
+			}
+			doc.Ops = append(doc.Ops, op)
+		case "fragment":
+			name, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+			if on, err := p.name(); err != nil || on != "on" {
+				return nil, fmt.Errorf("syntax error: expected 'on' in fragment %s", name)
+			}
+			typ, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+			sel, err := p.selectionSet()
+			if err != nil {
+				return nil, err
+			}
+			doc.Fragments[name] = gqlFragment{On: typ, Sel: sel}
+		default:
+			return nil, fmt.Errorf("syntax error: unexpected %q", kw)
+		}
+	}
+	if len(doc.Ops) == 0 {
+		return nil, fmt.Errorf("document has no operations")
+	}
+	if err := doc.checkFragmentCycles(); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// spreads appends the named fragments spread anywhere in sel.
+func spreads(sel []gqlSelection, out []string) []string {
+	for _, s := range sel {
+		if s.Spread != "" {
+			out = append(out, s.Spread)
+		}
+		out = spreads(s.Sel, out)
+	}
+	return out
+}
+
+// checkFragmentCycles implements the NoFragmentCycles rule: no fragment
+// may spread itself, directly or through other fragments.
+func (d *gqlDocument) checkFragmentCycles() error {
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := map[string]int{}
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		switch state[name] {
+		case visiting:
+			return fmt.Errorf("fragment cycle: %s", strings.Join(append(path, name), " -> "))
+		case done:
+			return nil
+		}
+		fr, ok := d.Fragments[name]
+		if !ok {
+			return nil // reported as an unknown fragment during execution
+		}
+		state[name] = visiting
+		for _, next := range spreads(fr.Sel, nil) {
+			if err := visit(next, append(path, name)); err != nil {
+				return err
+			}
+		}
+		state[name] = done
+		return nil
+	}
+	names := make([]string, 0, len(d.Fragments))
+	for n := range d.Fragments {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		if err := visit(n, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// gqlCost is the nesting depth and field count of a selection set.
+type gqlCost struct {
+	depth  int
+	fields int
+}
+
+// measure returns the cost of sel with fragments expanded. Fragment costs
+// are memoised so documents that spread the same fragment many times are
+// measured in linear time; the document must be free of cycles.
+func (d *gqlDocument) measure(sel []gqlSelection, memo map[string]gqlCost) gqlCost {
+	var c gqlCost
+	add := func(sub gqlCost, nest int) {
+		if sub.depth+nest > c.depth {
+			c.depth = sub.depth + nest
+		}
+		c.fields += sub.fields
+		if c.fields > gqlMaxComplexity {
+			c.fields = gqlMaxComplexity + 1
+		}
+	}
+	for _, s := range sel {
+		switch {
+		case s.Spread != "":
+			sub, ok := memo[s.Spread]
+			if !ok {
+				sub = d.measure(d.Fragments[s.Spread].Sel, memo)
+				memo[s.Spread] = sub
+			}
+			add(sub, 0)
+		case s.isInline:
+			add(d.measure(s.Sel, memo), 0)
+		default:
+			sub := d.measure(s.Sel, memo)
+			sub.fields++
+			add(sub, 1)
+		}
+	}
+	return c
+}
+
+// checkLimits rejects operations that nest too deeply or select too many
+// fields.
+func (d *gqlDocument) checkLimits(op *gqlOperation) error {
+	c := d.measure(op.Sel, map[string]gqlCost{})
+	if c.depth > gqlMaxDepth {
+		return fmt.Errorf("query depth %d exceeds the limit of %d", c.depth, gqlMaxDepth)
+	}
+	if c.fields > gqlMaxComplexity {
+		return fmt.Errorf("query selects more than %d fields", gqlMaxComplexity)
+	}
+	return nil
+}
+
+func (p *gqlParser) operation(kind string) (gqlOperation, error) {
+	op := gqlOperation{Type: kind}
+	if p.peek().kind == tokName {
+		op.Name = p.next().val
+	}
+	if p.is("(") {
+		p.next()
+		for !p.is(")") {
+			if err := p.expect("$"); err != nil {
+				return op, err
+			}
+			name, err := p.name()
+			if err != nil {
+				return op, err
+			}
+			if err := p.expect(":"); err != nil {
+				return op, err
+			}
+			typ, err := p.typeRef()
+			if err != nil {
+				return op, err
+			}
+			def := gqlVarDef{Name: name, Type: typ}
+			if p.is("=") {
+				p.next()
+				v, err := p.value(true)
+				if err != nil {
+					return op, err
+				}
+				def.Default = &v
+			}
+			op.Vars = append(op.Vars, def)
+		}
+		p.next()
+	}
+	if err := p.skipDirectives(); err != nil {
+		return op, err
+	}
+	sel, err := p.selectionSet()
+	op.Sel = sel
+	return op, err
+}
+
+func (p *gqlParser) typeRef() (string, error) {
+	defer func() { p.depth-- }()
+	if err := p.enter(); err != nil {
+		return "", err
+	}
+	var t string
+	if p.is("[") {
+		p.next()
+		inner, err := p.typeRef()
+		if err != nil {
+			return "", err
+		}
+		if err := p.expect("]"); err != nil {
+			return "", err
+		}
+		t = "[" + inner + "]"
+	} else {
+		n, err := p.name()
+		if err != nil {
+			return "", err
+		}
+		t = n
+	}
+	if p.is("!") {
+		p.next()
+		t += "!"
+	}
+	return t, nil
+}
+
+// skipDirectives rejects directives; the engine does not implement any.
+func (p *gqlParser) skipDirectives() error {
+	if p.is("@") {
+		return fmt.Errorf("directives are not supported")
+	}
+	return nil
+}
+
+func (p *gqlParser) selectionSet() ([]gqlSelection, error) {
+	defer func() { p.depth-- }()
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var out []gqlSelection
+	for !p.is("}") {
+		if p.peek().kind == tokEOF {
+			return nil, fmt.Errorf("syntax error: unterminated selection set")
+		}
+		if p.is("...") {
+			p.next()
+			if p.peek().kind == tokName && p.peek().val != "on" {
+				out = append(out, gqlSelection{Spread: p.next().val})
+				if err := p.skipDirectives(); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			s := gqlSelection{isInline: true}
+			if p.peek().kind == tokName && p.peek().val == "on" {
+				p.next()
+				typ, err := p.name()
+				if err != nil {
+					return nil, err
+				}
+				s.OnType = typ
+			}
+			if err := p.skipDirectives(); err != nil {
+				return nil, err
+			}
+			sel, err := p.selectionSet()
+			if err != nil {
+				return nil, err
+			}
+			s.Sel = sel
+			out = append(out, s)
+			continue
+		}
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		s := gqlSelection{Name: name, Alias: name}
+		if p.is(":") {
+			p.next()
+			if s.Name, err = p.name(); err != nil {
+				return nil, err
+			}
+		}
+		if p.is("(") {
+			p.next()
+			s.Args = map[string]gqlValue{}
+			for !p.is(")") {
+				an, err := p.name()
+				if err != nil {
+					return nil, err
+				}
+				if err := p.expect(":"); err != nil {
+					return nil, err
+				}
+				v, err := p.value(false)
+				if err != nil {
+					return nil, err
+				}
+				s.Args[an] = v
+			}
+			p.next()
+		}
+		if err := p.skipDirectives(); err != nil {
+			return nil, err
+		}
+		if p.is("{") {
+			if s.Sel, err = p.selectionSet(); err != nil {
+				return nil, err
+			}
+		}
+		out = append(out, s)
+	}
+	p.next()
+	return out, nil
+}
+
+func (p *gqlParser) value(constant bool) (gqlValue, error) {
+	defer func() { p.depth-- }()
+	if err := p.enter(); err != nil {
+		return gqlValue{}, err
+	}
+	t := p.next()
+	switch t.kind {
+	case tokInt:
+		return gqlValue{Kind: gqlInt, Raw: t.val}, nil
+	case tokFloat:
+		return gqlValue{Kind: gqlFloat, Raw: t.val}, nil
+	case tokString:
+		return gqlValue{Kind: gqlString, Raw: t.val}, nil
+	case tokName:
+		switch t.val {
+		case "true", "false":
+			return gqlValue{Kind: gqlBool, Raw: t.val}, nil
+		case "null":
+			return gqlValue{Kind: gqlNull}, nil
+		}
+		return gqlValue{Kind: gqlEnum, Raw: t.val}, nil
+	case tokPunct:
+		switch t.val {
+		case "$":
+			if constant {
+				return gqlValue{}, fmt.Errorf("syntax error at %d: variable in constant value", t.pos)
+			}
+			n, err := p.name()
+			return gqlValue{Kind: gqlVariable, Raw: n}, err
+		case "[":
+			v := gqlValue{Kind: gqlList}
+			for !p.is("]") {
+				item, err := p.value(constant)
+				if err != nil {
+					return v, err
+				}
+				v.List = append(v.List, item)
+			}
+			p.next()
+			return v, nil
+		case "{":
+			v := gqlValue{Kind: gqlObjectValue, Fields: map[string]gqlValue{}}
+			for !p.is("}") {
+				n, err := p.name()
+				if err != nil {
+					return v, err
+				}
+				if err := p.expect(":"); err != nil {
+					return v, err
+				}
+				if v.Fields[n], err = p.value(constant); err != nil {
+					return v, err
+				}
+			}
+			p.next()
+			return v, nil
+		}
+	}
+	return gqlValue{}, fmt.Errorf("syntax error at %d: unexpected %q", t.pos, t.val)
+}
+
+// ---------------------------------------------------------------------------
+// Execution
+// ---------------------------------------------------------------------------
+
+type gqlExec struct {
+	schema *gqlSchema
+	doc    *gqlDocument
+	vars   map[string]interface{}
+	errs   []gqlError
+}
+
+// prepare parses req and selects the operation to run.
+func (s *gqlSchema) prepare(req gqlRequest) (*gqlExec, *gqlOperation, error) {
+	doc, err := parseGraphQL(req.Query)
+	if err != nil {
+		return nil, nil, err
+	}
+	var op *gqlOperation
+	for i := range doc.Ops {
+		if req.OperationName == "" || doc.Ops[i].Name == req.OperationName {
+			if op != nil && req.OperationName == "" {
+				return nil, nil, fmt.Errorf("operationName required for documents with several operations")
+			}
+			op = &doc.Ops[i]
+		}
+	}
+	if op == nil {
+		return nil, nil, fmt.Errorf("unknown operation %q", req.OperationName)
+	}
+	if op.Type == "mutation" {
+		return nil, nil, fmt.Errorf("mutations are not supported")
+	}
+	if err := doc.checkLimits(op); err != nil {
+		return nil, nil, err
+	}
+	vars := map[string]interface{}{}
+	for _, d := range op.Vars {
+		if v, ok := req.Variables[d.Name]; ok {
+			vars[d.Name] = v
+		} else if d.Default != nil {
+			vars[d.Name] = literal(*d.Default, nil)
+		} else if strings.HasSuffix(d.Type, "!") {
+			return nil, nil, fmt.Errorf("variable $%s of type %s required", d.Name, d.Type)
+		}
+	}
+	return &gqlExec{schema: s, doc: doc, vars: vars}, op, nil
+}
+
+// Execute runs a query operation.
+func (s *gqlSchema) Execute(req gqlRequest) gqlResponse {
+	ex, op, err := s.prepare(req)
+	if err != nil {
+		return gqlResponse{Errors: []gqlError{{Message: err.Error()}}}
+	}
+	if op.Type != "query" {
+		return gqlResponse{Errors: []gqlError{{Message: op.Type + " operations must use the WebSocket endpoint"}}}
+	}
+	data := ex.object(s.Types[s.Query], nil, op.Sel, nil)
+	return gqlResponse{Data: data, Errors: ex.errs}
+}
+
+// errNotSubscription is returned by Subscribe for query operations.
+var errNotSubscription = errors.New("not a subscription")
+
+// Subscribe starts a subscription operation and returns a channel of
+// responses that closes when ctx ends or the source is exhausted.
+func (s *gqlSchema) Subscribe(ctx context.Context, req gqlRequest) (<-chan gqlResponse, error) {
+	ex, op, err := s.prepare(req)
+	if err != nil {
+		return nil, err
+	}
+	if op.Type != "subscription" || s.Subscription == "" {
+		return nil, errNotSubscription
+	}
+	root := s.Types[s.Subscription]
+	fields := ex.collect(root, op.Sel)
+	if len(fields) != 1 {
+		return nil, fmt.Errorf("subscriptions must select exactly one field")
+	}
+	sel := fields[0]
+	f, ok := root.Fields[sel.Name]
+	if !ok || f.Subscribe == nil {
+		return nil, fmt.Errorf("unknown subscription field %q", sel.Name)
+	}
+	args, err := ex.args(f, sel)
+	if err != nil {
+		return nil, err
+	}
+	src, err := f.Subscribe(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	out := make(chan gqlResponse)
+	go func() {
+		defer close(out)
+		for v := range src {
+			run := &gqlExec{schema: s, doc: ex.doc, vars: ex.vars}
+			val := run.value(f.Type, v, sel.Sel, []interface{}{sel.Alias})
+			resp := gqlResponse{Data: map[string]interface{}{sel.Alias: val}, Errors: run.errs}
+			select {
+			case out <- resp:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+// collect flattens fragments into the field selections that apply to obj.
+func (ex *gqlExec) collect(obj *gqlObject, sel []gqlSelection) []gqlSelection {
+	var out []gqlSelection
+	for _, s := range sel {
+		switch {
+		case s.Spread != "":
+			fr, ok := ex.doc.Fragments[s.Spread]
+			if !ok {
+				ex.errs = append(ex.errs, gqlError{Message: "unknown fragment " + s.Spread})
+				continue
+			}
+			if fr.On == obj.Name {
+				out = append(out, ex.collect(obj, fr.Sel)...)
+			}
+		case s.isInline:
+			if s.OnType == "" || s.OnType == obj.Name {
+				out = append(out, ex.collect(obj, s.Sel)...)
+			}
+		default:
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// orderedMap keeps response keys in selection order when marshalled.
+type orderedMap struct {
+	keys []string
+	vals map[string]interface{}
+}
+
+// Get returns the value stored under key.
+func (m *orderedMap) Get(key string) interface{} { return m.vals[key] }
+
+// MarshalJSON implements json.Marshaler.
+func (m *orderedMap) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, k := range m.keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		key, _ := json.Marshal(k)
+		val, err := json.Marshal(m.vals[k])
+		if err != nil {
+			return nil, err
+		}
+		b.Write(key)
+		b.WriteByte(':')
+		b.Write(val)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+func (ex *gqlExec) object(obj *gqlObject, src interface{}, sel []gqlSelection, path []interface{}) *orderedMap {
+	out := &orderedMap{vals: map[string]interface{}{}}
+	for _, s := range ex.collect(obj, sel) {
+		if _, dup := out.vals[s.Alias]; dup {
+			continue
+		}
+		fpath := append(append([]interface{}{}, path...), s.Alias)
+		out.keys = append(out.keys, s.Alias)
+		if s.Name == "__typename" {
+			out.vals[s.Alias] = obj.Name
+			continue
+		}
+		f, ok := obj.Fields[s.Name]
+		if !ok {
+			ex.errs = append(ex.errs, gqlError{Message: fmt.Sprintf("unknown field %s on %s", s.Name, obj.Name), Path: fpath})
+			out.vals[s.Alias] = nil
+			continue
+		}
+		args, err := ex.args(f, s)
+		if err != nil {
+			ex.errs = append(ex.errs, gqlError{Message: err.Error(), Path: fpath})
+			out.vals[s.Alias] = nil
+			continue
+		}
+		if f.Resolve == nil {
+			ex.errs = append(ex.errs, gqlError{Message: "field " + s.Name + " has no resolver", Path: fpath})
+			out.vals[s.Alias] = nil
+			continue
+		}
+		v, err := f.Resolve(src, args)
+		if err != nil {
+			ex.errs = append(ex.errs, gqlError{Message: err.Error(), Path: fpath})
+			out.vals[s.Alias] = nil
+			continue
+		}
+		out.vals[s.Alias] = ex.value(f.Type, v, s.Sel, fpath)
+	}
+	return out
+}
+
+func (ex *gqlExec) value(typ string, v interface{}, sel []gqlSelection, path []interface{}) interface{} {
+	if isNil(v) {
+		return nil
+	}
+	inner, list := unwrapType(typ)
+	if list {
+		items, ok := v.([]interface{})
+		if !ok {
+			ex.errs = append(ex.errs, gqlError{Message: "resolver returned a non-list for " + typ, Path: path})
+			return nil
+		}
+		out := make([]interface{}, len(items))
+		for i, it := range items {
+			out[i] = ex.value(inner, it, sel, append(append([]interface{}{}, path...), i))
+		}
+		return out
+	}
+	if gqlScalars[inner] {
+		if len(sel) > 0 {
+			ex.errs = append(ex.errs, gqlError{Message: "scalar field cannot have a selection set", Path: path})
+		}
+		return v
+	}
+	obj, ok := ex.schema.Types[inner]
+	if !ok {
+		ex.errs = append(ex.errs, gqlError{Message: "unknown type " + inner, Path: path})
+		return nil
+	}
+	if len(sel) == 0 {
+		ex.errs = append(ex.errs, gqlError{Message: "field of type " + inner + " needs a selection set", Path: path})
+		return nil
+	}
+	return ex.object(obj, v, sel, path)
+}
+
+// isNil reports whether v is nil or a typed nil pointer, slice or map.
+func isNil(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+		return rv.IsNil()
+	}
+	return false
+}
+
+func (ex *gqlExec) args(f *gqlField, s gqlSelection) (map[string]interface{}, error) {
+	out := map[string]interface{}{}
+	for _, a := range f.Args {
+		if v, ok := s.Args[a.Name]; ok {
+			val, err := coerceArg(a.Type, literal(v, ex.vars))
+			if err != nil {
+				return nil, fmt.Errorf("argument %s: %w", a.Name, err)
+			}
+			if val != nil {
+				out[a.Name] = val
+				continue
+			}
+		}
+		if a.Default != nil {
+			out[a.Name] = a.Default
+		} else if strings.HasSuffix(a.Type, "!") {
+			return nil, fmt.Errorf("argument %s of type %s required", a.Name, a.Type)
+		}
+	}
+	for name := range s.Args {
+		found := false
+		for _, a := range f.Args {
+			found = found || a.Name == name
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown argument %s", name)
+		}
+	}
+	return out, nil
+}
+
+// literal converts a parsed value to Go, substituting variables.
+func literal(v gqlValue, vars map[string]interface{}) interface{} {
+	switch v.Kind {
+	case gqlInt:
+		n, _ := strconv.ParseInt(v.Raw, 10, 64)
+		return n
+	case gqlFloat:
+		f, _ := strconv.ParseFloat(v.Raw, 64)
+		return f
+	case gqlString, gqlEnum:
+		return v.Raw
+	case gqlBool:
+		return v.Raw == "true"
+	case gqlVariable:
+		return vars[v.Raw]
+	case gqlList:
+		out := make([]interface{}, len(v.List))
+		for i, it := range v.List {
+			out[i] = literal(it, vars)
+		}
+		return out
+	case gqlObjectValue:
+		out := map[string]interface{}{}
+		for k, it := range v.Fields {
+			out[k] = literal(it, vars)
+		}
+		return out
+	}
+	return nil
+}
+
+// coerceArg converts an argument value to the Go type resolvers expect:
+// int for Int, float64 for Float, string for String and ID, bool for
+// Boolean.
+func coerceArg(typ string, v interface{}) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+	inner, _ := unwrapType(typ)
+	switch inner {
+	case "Int":
+		switch n := v.(type) {
+		case int64:
+			return int(n), nil
+		case float64: // JSON variables
+			if n != float64(int(n)) {
+				return nil, fmt.Errorf("%v is not an Int", v)
+			}
+			return int(n), nil
+		}
+	case "Float":
+		switch n := v.(type) {
+		case int64:
+			return float64(n), nil
+		case float64:
+			return n, nil
+		}
+	case "String", "ID":
+		if s, ok := v.(string); ok {
+			return s, nil
+		}
+		if inner == "ID" {
+			if n, ok := v.(int64); ok {
+				return strconv.FormatInt(n, 10), nil
+			}
+		}
+	case "Boolean":
+		if b, ok := v.(bool); ok {
+			return b, nil
+		}
+	default:
+		return v, nil
+	}
+	return nil, fmt.Errorf("%v is not a valid %s", v, inner)
+}
@@ -0,0 +1,399 @@
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	core "synnergy-network/core"
+)
+
+// ExplorerGraphQLService adds the data the GraphQL layer exposes on top of
+// the v1 REST service. Services implementing it get /graphql mounted.
+type ExplorerGraphQLService interface {
+	ExplorerV1Service
+	Receipt(hash string) (*ReceiptView, error)
+	Pools() []PoolSummary
+}
+
+var _ ExplorerGraphQLService = (*LedgerService)(nil)
+
+// ReceiptView is the outcome of an included transaction. Fee is what the
+// ledger charged when the block was applied (gas limit times gas price).
+type ReceiptView struct {
+	TxHash      string    `json:"tx_hash"`
+	Status      string    `json:"status"`
+	BlockHeight uint64    `json:"block_height"`
+	BlockHash   string    `json:"block_hash"`
+	Index       int       `json:"index"`
+	GasLimit    uint64    `json:"gas_limit"`
+	GasPrice    uint64    `json:"gas_price"`
+	Fee         uint64    `json:"fee"`
+	Logs        []LogView `json:"logs"`
+}
+
+// LogView is a contract event emitted by a transaction.
+type LogView struct {
+	Contract string `json:"contract"`
+	Name     string `json:"name"`
+	Data     string `json:"data"`
+}
+
+// PoolSummary describes an AMM pool.
+type PoolSummary struct {
+	ID       uint64 `json:"id"`
+	TokenA   uint64 `json:"token_a"`
+	TokenB   uint64 `json:"token_b"`
+	ReserveA uint64 `json:"reserve_a"`
+	ReserveB uint64 `json:"reserve_b"`
+	TotalLP  uint64 `json:"total_lp"`
+	FeeBps   uint16 `json:"fee_bps"`
+}
+
+// Receipt derives the receipt of an included transaction. Only included
+// transactions are stored so their status is always "success"; logs come
+// from the event index when the event manager is running.
+func (s *LedgerService) Receipt(hash string) (*ReceiptView, error) {
+	tx, err := s.Tx(hash)
+	if err != nil {
+		return nil, err
+	}
+	r := &ReceiptView{
+		TxHash:      tx.Hash,
+		Status:      "success",
+		BlockHeight: tx.BlockHeight,
+		BlockHash:   tx.BlockHash,
+		Index:       tx.Index,
+		GasLimit:    tx.Tx.GasLimit,
+		GasPrice:    tx.Tx.GasPrice,
+		Fee:         tx.Tx.GasLimit * tx.Tx.GasPrice,
+		Logs:        []LogView{},
+	}
+	evs, err := core.Events().ContractEvents(tx.Tx.To, 0)
+	if err != nil {
+		return r, nil
+	}
+	id := tx.Tx.ID()
+	for _, ev := range evs {
+		var ce core.ContractEventData
+		if json.Unmarshal(ev.Data, &ce) != nil || ce.TxHash != id {
+			continue
+		}
+		r.Logs = append(r.Logs, LogView{Contract: ce.Contract.Hex(), Name: ce.Name, Data: hex.EncodeToString(ce.Data)})
+	}
+	return r, nil
+}
+
+// Pools lists the AMM pools known to this process.
+func (s *LedgerService) Pools() []PoolSummary {
+	amm := core.Manager()
+	if amm == nil {
+		return []PoolSummary{}
+	}
+	views := amm.Snapshot()
+	out := make([]PoolSummary, 0, len(views))
+	for _, p := range views {
+		out = append(out, PoolSummary{
+			ID:       uint64(p.ID),
+			TokenA:   uint64(p.TokenA),
+			TokenB:   uint64(p.TokenB),
+			ReserveA: p.ResA,
+			ReserveB: p.ResB,
+			TotalLP:  p.TotalLP,
+			FeeBps:   p.FeeBps,
+		})
+	}
+	return out
+}
+
+// gqlPollInterval is how often the newBlocks subscription checks the head.
+var gqlPollInterval = time.Second
+
+// newExplorerSchema builds the explorer GraphQL schema over svc.
+//
+// Amounts are exposed as decimal strings because GraphQL Int is 32-bit.
+func newExplorerSchema(svc ExplorerGraphQLService) *gqlSchema {
+	page := []gqlArg{{Name: "offset", Type: "Int", Default: 0}, {Name: "limit", Type: "Int", Default: defaultPerPage}}
+
+	blockField := func(get func(b *core.Block) interface{}, typ string) *gqlField {
+		return &gqlField{Type: typ, Resolve: func(src interface{}, _ map[string]interface{}) (interface{}, error) {
+			return get(src.(*core.Block)), nil
+		}}
+	}
+	txField := func(get func(t TxView) interface{}, typ string) *gqlField {
+		return &gqlField{Type: typ, Resolve: func(src interface{}, _ map[string]interface{}) (interface{}, error) {
+			return get(src.(TxView)), nil
+		}}
+	}
+	plain := func(typ string, get func(src interface{}) interface{}) *gqlField {
+		return &gqlField{Type: typ, Resolve: func(src interface{}, _ map[string]interface{}) (interface{}, error) {
+			return get(src), nil
+		}}
+	}
+	amount := func(v uint64) interface{} { return strconv.FormatUint(v, 10) }
+
+	return &gqlSchema{
+		Query:        "Query",
+		Subscription: "Subscription",
+		Types: map[string]*gqlObject{
+			"Query": {Name: "Query", Fields: map[string]*gqlField{
+				"head": {Type: "Int!", Doc: "Current chain height", Resolve: func(interface{}, map[string]interface{}) (interface{}, error) {
+					return svc.Head(), nil
+				}},
+				"block": {Type: "Block", Doc: "Block by height or hash", Args: []gqlArg{{Name: "height", Type: "Int"}, {Name: "hash", Type: "String"}},
+					Resolve: func(_ interface{}, args map[string]interface{}) (interface{}, error) {
+						ref, ok := args["hash"].(string)
+						if h, set := args["height"].(int); set {
+							ref, ok = strconv.Itoa(h), true
+						}
+						if !ok {
+							return nil, fmt.Errorf("height or hash required")
+						}
+						return gqlNotFound(svc.Block(ref))
+					}},
+				"blocks": {Type: "[Block!]!", Doc: "Blocks newest first", Args: page,
+					Resolve: func(_ interface{}, args map[string]interface{}) (interface{}, error) {
+						offset, limit, err := gqlPage(args)
+						if err != nil {
+							return nil, err
+						}
+						list, _ := svc.Blocks(offset, limit)
+						out := make([]interface{}, 0, len(list))
+						for _, sum := range list {
+							b, err := svc.Block(strconv.FormatUint(sum.Height, 10))
+							if err != nil {
+								return nil, err
+							}
+							out = append(out, b)
+						}
+						return out, nil
+					}},
+				"tx": {Type: "Tx", Args: []gqlArg{{Name: "hash", Type: "String!"}},
+					Resolve: func(_ interface{}, args map[string]interface{}) (interface{}, error) {
+						tx, err := svc.Tx(args["hash"].(string))
+						if err != nil {
+							return gqlNotFound(nil, err)
+						}
+						return *tx, nil
+					}},
+				"account": {Type: "Account", Args: []gqlArg{{Name: "address", Type: "String!"}},
+					Resolve: func(_ interface{}, args map[string]interface{}) (interface{}, error) {
+						return svc.Address(args["address"].(string))
+					}},
+				"tokens": {Type: "[Token!]!", Args: page,
+					Resolve: func(_ interface{}, args map[string]interface{}) (interface{}, error) {
+						offset, limit, err := gqlPage(args)
+						if err != nil {
+							return nil, err
+						}
+						list, _ := svc.Tokens(offset, limit)
+						out := make([]interface{}, len(list))
+						for i := range list {
+							out[i] = list[i]
+						}
+						return out, nil
+					}},
+				"pools": {Type: "[Pool!]!", Doc: "AMM liquidity pools", Resolve: func(interface{}, map[string]interface{}) (interface{}, error) {
+					list := svc.Pools()
+					out := make([]interface{}, len(list))
+					for i := range list {
+						out[i] = list[i]
+					}
+					return out, nil
+				}},
+				"search": {Type: "[SearchResult!]!", Args: []gqlArg{{Name: "q", Type: "String!"}},
+					Resolve: func(_ interface{}, args map[string]interface{}) (interface{}, error) {
+						list, err := svc.Search(args["q"].(string))
+						if err != nil {
+							return nil, err
+						}
+						out := make([]interface{}, len(list))
+						for i := range list {
+							out[i] = list[i]
+						}
+						return out, nil
+					}},
+			}},
+			"Subscription": {Name: "Subscription", Fields: map[string]*gqlField{
+				"newBlocks": {Type: "Block!", Doc: "Emits every block appended after subscribing",
+					Subscribe: func(ctx context.Context, _ map[string]interface{}) (<-chan interface{}, error) {
+						return gqlNewBlocks(ctx, svc), nil
+					}},
+			}},
+			"Block": {Name: "Block", Fields: map[string]*gqlField{
+				"height":    blockField(func(b *core.Block) interface{} { return b.Header.Height }, "Int!"),
+				"hash":      blockField(func(b *core.Block) interface{} { return b.Hash().Hex() }, "String!"),
+				"prevHash":  blockField(func(b *core.Block) interface{} { return hex.EncodeToString(b.Header.PrevHash) }, "String!"),
+				"timestamp": blockField(func(b *core.Block) interface{} { return b.Header.Timestamp }, "Int!"),
+				"miner":     blockField(func(b *core.Block) interface{} { return hex.EncodeToString(b.Header.MinerPk) }, "String"),
+				"txCount":   blockField(func(b *core.Block) interface{} { return len(b.Transactions) }, "Int!"),
+				"txs": blockField(func(b *core.Block) interface{} {
+					out := make([]interface{}, len(b.Transactions))
+					for i, tx := range b.Transactions {
+						v := txView(b, i, tx)
+						v.Tx = tx
+						out[i] = v
+					}
+					return out
+				}, "[Tx!]!"),
+			}},
+			"Tx": {Name: "Tx", Fields: map[string]*gqlField{
+				"hash":        txField(func(t TxView) interface{} { return t.Hash }, "String!"),
+				"blockHeight": txField(func(t TxView) interface{} { return t.BlockHeight }, "Int!"),
+				"blockHash":   txField(func(t TxView) interface{} { return t.BlockHash }, "String!"),
+				"index":       txField(func(t TxView) interface{} { return t.Index }, "Int!"),
+				"from":        txField(func(t TxView) interface{} { return t.From }, "String!"),
+				"to":          txField(func(t TxView) interface{} { return t.To }, "String!"),
+				"value":       txField(func(t TxView) interface{} { return amount(t.Value) }, "String!"),
+				"timestamp":   txField(func(t TxView) interface{} { return t.Timestamp }, "Int!"),
+				"receipt": {Type: "Receipt", Resolve: func(src interface{}, _ map[string]interface{}) (interface{}, error) {
+					return gqlNotFound(svc.Receipt(src.(TxView).Hash))
+				}},
+			}},
+			"Receipt": {Name: "Receipt", Fields: map[string]*gqlField{
+				"status":   plain("String!", func(s interface{}) interface{} { return s.(*ReceiptView).Status }),
+				"gasLimit": plain("String!", func(s interface{}) interface{} { return amount(s.(*ReceiptView).GasLimit) }),
+				"gasPrice": plain("String!", func(s interface{}) interface{} { return amount(s.(*ReceiptView).GasPrice) }),
+				"fee":      plain("String!", func(s interface{}) interface{} { return amount(s.(*ReceiptView).Fee) }),
+				"logs": plain("[Log!]!", func(s interface{}) interface{} {
+					logs := s.(*ReceiptView).Logs
+					out := make([]interface{}, len(logs))
+					for i := range logs {
+						out[i] = logs[i]
+					}
+					return out
+				}),
+			}},
+			"Log": {Name: "Log", Fields: map[string]*gqlField{
+				"contract": plain("String!", func(s interface{}) interface{} { return s.(LogView).Contract }),
+				"name":     plain("String!", func(s interface{}) interface{} { return s.(LogView).Name }),
+				"data":     plain("String!", func(s interface{}) interface{} { return s.(LogView).Data }),
+			}},
+			"Account": {Name: "Account", Fields: map[string]*gqlField{
+				"address": plain("String!", func(s interface{}) interface{} { return s.(*AddressView).Address }),
+				"balance": plain("String!", func(s interface{}) interface{} { return amount(s.(*AddressView).Balance) }),
+				"txCount": plain("Int!", func(s interface{}) interface{} { return s.(*AddressView).TxCount }),
+				"tokens": plain("[TokenBalance!]!", func(s interface{}) interface{} {
+					list := s.(*AddressView).Tokens
+					out := make([]interface{}, len(list))
+					for i := range list {
+						out[i] = list[i]
+					}
+					return out
+				}),
+				"txs": {Type: "[Tx!]!", Args: page, Resolve: func(src interface{}, args map[string]interface{}) (interface{}, error) {
+					offset, limit, err := gqlPage(args)
+					if err != nil {
+						return nil, err
+					}
+					list, _, err := svc.AddressTxs(src.(*AddressView).Address, offset, limit)
+					if err != nil {
+						return nil, err
+					}
+					out := make([]interface{}, len(list))
+					for i := range list {
+						out[i] = list[i]
+					}
+					return out, nil
+				}},
+			}},
+			"TokenBalance": {Name: "TokenBalance", Fields: map[string]*gqlField{
+				"tokenId": plain("Int!", func(s interface{}) interface{} { return uint64(s.(TokenBalance).ID) }),
+				"symbol":  plain("String!", func(s interface{}) interface{} { return s.(TokenBalance).Symbol }),
+				"balance": plain("String!", func(s interface{}) interface{} { return amount(s.(TokenBalance).Balance) }),
+			}},
+			"Token": {Name: "Token", Fields: map[string]*gqlField{
+				"id":          plain("Int!", func(s interface{}) interface{} { return uint64(s.(TokenView).ID) }),
+				"name":        plain("String!", func(s interface{}) interface{} { return s.(TokenView).Name }),
+				"symbol":      plain("String!", func(s interface{}) interface{} { return s.(TokenView).Symbol }),
+				"decimals":    plain("Int!", func(s interface{}) interface{} { return s.(TokenView).Decimals }),
+				"standard":    plain("Int!", func(s interface{}) interface{} { return s.(TokenView).Standard }),
+				"totalSupply": plain("String!", func(s interface{}) interface{} { return amount(s.(TokenView).TotalSupply) }),
+				"holders": {Type: "[Holder!]!", Args: page, Resolve: func(src interface{}, args map[string]interface{}) (interface{}, error) {
+					offset, limit, err := gqlPage(args)
+					if err != nil {
+						return nil, err
+					}
+					list, _, err := svc.TokenHolders(src.(TokenView).ID, offset, limit)
+					if err != nil {
+						return nil, err
+					}
+					out := make([]interface{}, len(list))
+					for i := range list {
+						out[i] = list[i]
+					}
+					return out, nil
+				}},
+			}},
+			"Holder": {Name: "Holder", Fields: map[string]*gqlField{
+				"address": plain("String!", func(s interface{}) interface{} { return s.(HolderView).Address }),
+				"balance": plain("String!", func(s interface{}) interface{} { return amount(s.(HolderView).Balance) }),
+			}},
+			"Pool": {Name: "Pool", Fields: map[string]*gqlField{
+				"id":       plain("Int!", func(s interface{}) interface{} { return s.(PoolSummary).ID }),
+				"tokenA":   plain("Int!", func(s interface{}) interface{} { return s.(PoolSummary).TokenA }),
+				"tokenB":   plain("Int!", func(s interface{}) interface{} { return s.(PoolSummary).TokenB }),
+				"reserveA": plain("String!", func(s interface{}) interface{} { return amount(s.(PoolSummary).ReserveA) }),
+				"reserveB": plain("String!", func(s interface{}) interface{} { return amount(s.(PoolSummary).ReserveB) }),
+				"totalLP":  plain("String!", func(s interface{}) interface{} { return amount(s.(PoolSummary).TotalLP) }),
+				"feeBps":   plain("Int!", func(s interface{}) interface{} { return s.(PoolSummary).FeeBps }),
+			}},
+			"SearchResult": {Name: "SearchResult", Fields: map[string]*gqlField{
+				"type":  plain("String!", func(s interface{}) interface{} { return s.(SearchResult).Type }),
+				"id":    plain("String!", func(s interface{}) interface{} { return s.(SearchResult).ID }),
+				"label": plain("String", func(s interface{}) interface{} { return s.(SearchResult).Label }),
+			}},
+		},
+	}
+}
+
+// gqlPage validates offset/limit arguments.
+func gqlPage(args map[string]interface{}) (offset, limit int, err error) {
+	offset, _ = args["offset"].(int)
+	limit, _ = args["limit"].(int)
+	if offset < 0 || limit < 1 || limit > maxPerPage {
+		return 0, 0, fmt.Errorf("limit must be 1-%d and offset non-negative", maxPerPage)
+	}
+	return offset, limit, nil
+}
+
+// gqlNotFound maps core.ErrNotFound to a null result.
+func gqlNotFound(v interface{}, err error) (interface{}, error) {
+	if err == core.ErrNotFound {
+		return nil, nil
+	}
+	return v, err
+}
+
+// gqlNewBlocks polls the chain head and emits each new block in order.
+func gqlNewBlocks(ctx context.Context, svc ExplorerV1Service) <-chan interface{} {
+	out := make(chan interface{})
+	go func() {
+		defer close(out)
+		last := svc.Head()
+		t := time.NewTicker(gqlPollInterval)
+		defer t.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+			}
+			for head := svc.Head(); last < head; last++ {
+				b, err := svc.Block(strconv.FormatUint(last+1, 10))
+				if err != nil {
+					break
+				}
+				select {
+				case out <- b:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return out
+}
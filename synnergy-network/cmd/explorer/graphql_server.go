@@ -0,0 +1,230 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// GraphQL over HTTP and WebSocket.
+//
+//	POST /graphql          {"query": ..., "variables": ..., "operationName": ...}
+//	GET  /graphql?query=   queries only
+//	GET  /graphql/schema   schema in SDL
+//	GET  /graphql (Upgrade: websocket, subprotocol graphql-transport-ws)
+//
+// Subscriptions use the graphql-transport-ws message flow:
+// connection_init/connection_ack, subscribe, next, error, complete and
+// ping/pong.
+//
+// Browsers may only open the socket from the explorer's own host or from
+// an origin listed with SetWSOrigins; requests without an Origin header
+// come from non-browser clients and are accepted.
+
+const gqlWSProtocol = "graphql-transport-ws"
+
+// SetWSOrigins sets the origins, e.g. "https://explorer.example.org",
+// allowed to open GraphQL WebSocket connections in addition to the
+// explorer's own host.
+func (s *Server) SetWSOrigins(origins []string) {
+	s.wsOrigins = nil
+	for _, o := range origins {
+		if o = strings.TrimRight(strings.TrimSpace(o), "/"); o != "" {
+			s.wsOrigins = append(s.wsOrigins, strings.ToLower(o))
+		}
+	}
+}
+
+// checkWSOrigin reports whether r may be upgraded to a WebSocket.
+func (s *Server) checkWSOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	if strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+	origin = strings.ToLower(u.Scheme + "://" + u.Host)
+	for _, o := range s.wsOrigins {
+		if o == origin {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) routesGraphQL(svc ExplorerGraphQLService) {
+	schema := newExplorerSchema(svc)
+	s.router.HandleFunc("/graphql/schema", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_, _ = w.Write([]byte(schema.SDL()))
+	}).Methods("GET")
+	s.router.HandleFunc("/graphql", func(w http.ResponseWriter, r *http.Request) {
+		if websocket.IsWebSocketUpgrade(r) {
+			s.serveGraphQLWS(schema, w, r)
+			return
+		}
+		var req gqlRequest
+		if r.Method == http.MethodGet {
+			req.Query = r.URL.Query().Get("query")
+			req.OperationName = r.URL.Query().Get("operationName")
+			if v := r.URL.Query().Get("variables"); v != "" {
+				if err := json.Unmarshal([]byte(v), &req.Variables); err != nil {
+					writeGraphQL(w, http.StatusBadRequest, gqlResponse{Errors: []gqlError{{Message: "invalid variables: " + err.Error()}}})
+					return
+				}
+			}
+		} else if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeGraphQL(w, http.StatusBadRequest, gqlResponse{Errors: []gqlError{{Message: "invalid request: " + err.Error()}}})
+			return
+		}
+		if req.Query == "" {
+			writeGraphQL(w, http.StatusBadRequest, gqlResponse{Errors: []gqlError{{Message: "query required"}}})
+			return
+		}
+		resp := schema.Execute(req)
+		code := http.StatusOK
+		if resp.Data == nil {
+			code = http.StatusBadRequest
+		}
+		writeGraphQL(w, code, resp)
+	}).Methods("GET", "POST")
+}
+
+func writeGraphQL(w http.ResponseWriter, code int, resp gqlResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// gqlWSMessage is a graphql-transport-ws frame.
+type gqlWSMessage struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+func (s *Server) serveGraphQLWS(schema *gqlSchema, w http.ResponseWriter, r *http.Request) {
+	up := websocket.Upgrader{Subprotocols: []string{gqlWSProtocol}, CheckOrigin: s.checkWSOrigin}
+	conn, err := up.Upgrade(w, r, nil)
+	if err != nil {
+		logger.Printf("graphql ws upgrade: %v", err)
+		return
+	}
+	defer conn.Close()
+	// The HTTP server's read/write timeouts still apply to the hijacked
+	// connection; subscriptions are long lived.
+	_ = conn.SetReadDeadline(time.Time{})
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	var wmu sync.Mutex
+	send := func(m gqlWSMessage) error {
+		wmu.Lock()
+		defer wmu.Unlock()
+		_ = conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+		return conn.WriteJSON(m)
+	}
+	payload := func(v interface{}) json.RawMessage {
+		raw, _ := json.Marshal(v)
+		return raw
+	}
+
+	var mu sync.Mutex
+	subs := map[string]context.CancelFunc{}
+	acked := false
+	for {
+		var msg gqlWSMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			return
+		}
+		switch msg.Type {
+		case "connection_init":
+			acked = true
+			if send(gqlWSMessage{Type: "connection_ack"}) != nil {
+				return
+			}
+		case "ping":
+			if send(gqlWSMessage{Type: "pong"}) != nil {
+				return
+			}
+		case "pong":
+		case "subscribe":
+			if !acked {
+				_ = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(4401, "Unauthorized"), time.Now().Add(time.Second))
+				return
+			}
+			var req gqlRequest
+			if err := json.Unmarshal(msg.Payload, &req); err != nil {
+				_ = send(gqlWSMessage{ID: msg.ID, Type: "error", Payload: payload([]gqlError{{Message: err.Error()}})})
+				continue
+			}
+			mu.Lock()
+			_, dup := subs[msg.ID]
+			mu.Unlock()
+			if dup {
+				_ = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(4409, "Subscriber for "+msg.ID+" already exists"), time.Now().Add(time.Second))
+				return
+			}
+			subCtx, subCancel := context.WithCancel(ctx)
+			stream, err := schema.Subscribe(subCtx, req)
+			if err != nil && err != errNotSubscription {
+				subCancel()
+				_ = send(gqlWSMessage{ID: msg.ID, Type: "error", Payload: payload([]gqlError{{Message: err.Error()}})})
+				continue
+			}
+			if err == errNotSubscription {
+				// Plain queries are answered once over the socket as well.
+				subCancel()
+				resp := schema.Execute(req)
+				if resp.Data == nil {
+					_ = send(gqlWSMessage{ID: msg.ID, Type: "error", Payload: payload(resp.Errors)})
+					continue
+				}
+				_ = send(gqlWSMessage{ID: msg.ID, Type: "next", Payload: payload(resp)})
+				_ = send(gqlWSMessage{ID: msg.ID, Type: "complete"})
+				continue
+			}
+			mu.Lock()
+			subs[msg.ID] = subCancel
+			mu.Unlock()
+			go func(id string) {
+				for resp := range stream {
+					if send(gqlWSMessage{ID: id, Type: "next", Payload: payload(resp)}) != nil {
+						cancel()
+						return
+					}
+				}
+				mu.Lock()
+				_, live := subs[id]
+				delete(subs, id)
+				mu.Unlock()
+				if live && ctx.Err() == nil {
+					_ = send(gqlWSMessage{ID: id, Type: "complete"})
+				}
+			}(msg.ID)
+		case "complete":
+			mu.Lock()
+			if stop, ok := subs[msg.ID]; ok {
+				delete(subs, msg.ID)
+				stop()
+			}
+			mu.Unlock()
+		default:
+			_ = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(4400, "unknown message type "+msg.Type), time.Now().Add(time.Second))
+			return
+		}
+	}
+}
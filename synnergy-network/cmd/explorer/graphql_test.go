@@ -0,0 +1,251 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testBlock struct {
+	Height int
+}
+
+// testSchema is a chain of blocks 0..9 with a head subscription fed by ch.
+func testSchema(ch chan interface{}) *gqlSchema {
+	block := func(h int) interface{} {
+		if h < 0 || h > 9 {
+			return nil
+		}
+		return &testBlock{Height: h}
+	}
+	return &gqlSchema{
+		Query:        "Query",
+		Subscription: "Subscription",
+		Types: map[string]*gqlObject{
+			"Query": {Name: "Query", Fields: map[string]*gqlField{
+				"block": {Type: "Block", Args: []gqlArg{{Name: "height", Type: "Int!"}},
+					Resolve: func(_ interface{}, args map[string]interface{}) (interface{}, error) {
+						return block(args["height"].(int)), nil
+					}},
+				"blocks": {Type: "[Block!]!", Args: []gqlArg{{Name: "limit", Type: "Int", Default: 2}},
+					Resolve: func(_ interface{}, args map[string]interface{}) (interface{}, error) {
+						var out []interface{}
+						for h := 9; h > 9-args["limit"].(int); h-- {
+							out = append(out, block(h))
+						}
+						return out, nil
+					}},
+			}},
+			"Subscription": {Name: "Subscription", Fields: map[string]*gqlField{
+				"heads": {Type: "Block!", Subscribe: func(ctx context.Context, _ map[string]interface{}) (<-chan interface{}, error) {
+					return ch, nil
+				}},
+			}},
+			"Block": {Name: "Block", Fields: map[string]*gqlField{
+				"height": {Type: "Int!", Resolve: func(src interface{}, _ map[string]interface{}) (interface{}, error) {
+					return src.(*testBlock).Height, nil
+				}},
+				"parent": {Type: "Block", Resolve: func(src interface{}, _ map[string]interface{}) (interface{}, error) {
+					return block(src.(*testBlock).Height - 1), nil
+				}},
+			}},
+		},
+	}
+}
+
+func gqlJSON(t *testing.T, v interface{}) string {
+	t.Helper()
+	raw, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	return string(raw)
+}
+
+func TestGraphQLParse(t *testing.T) {
+	doc, err := parseGraphQL(`
+		query Q($h: Int! = 3, $l: [Int]) { b: block(height: $h) { ...F ... on Block { height } } }
+		fragment F on Block { parent { __typename } }`)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	op := doc.Ops[0]
+	if op.Type != "query" || op.Name != "Q" || len(op.Vars) != 2 || op.Vars[0].Type != "Int!" || op.Vars[1].Type != "[Int]" {
+		t.Fatalf("operation %+v", op)
+	}
+	sel := op.Sel[0]
+	if sel.Alias != "b" || sel.Name != "block" || sel.Args["height"].Kind != gqlVariable {
+		t.Fatalf("selection %+v", sel)
+	}
+	if sel.Sel[0].Spread != "F" || !sel.Sel[1].isInline || sel.Sel[1].OnType != "Block" {
+		t.Fatalf("fragments %+v", sel.Sel)
+	}
+	if fr := doc.Fragments["F"]; fr.On != "Block" || fr.Sel[0].Name != "parent" {
+		t.Fatalf("fragment F %+v", fr)
+	}
+
+	for _, bad := range []string{
+		``,
+		`{ block(height: 1) `,
+		`{ block(height: [1, 2 }`,
+		`{ block(height: "x) }`,
+		`query ($h: Int = $x) { block(height: $h) }`,
+		`{ block @skip(if: true) }`,
+		`{ a } fragment F Block { a }`,
+		`{ ` + strings.Repeat("...{ ", gqlMaxNesting) + `a` + strings.Repeat(" }", gqlMaxNesting) + ` }`,
+		`{ a(x: ` + strings.Repeat("[", gqlMaxNesting+1) + `) }`,
+	} {
+		if _, err := parseGraphQL(bad); err == nil {
+			t.Fatalf("parsed invalid document %q", bad)
+		}
+	}
+}
+
+func TestGraphQLRejectsFragmentCycles(t *testing.T) {
+	for _, q := range []string{
+		`query { ...F } fragment F on Query { ...F }`,
+		`{ ...A } fragment A on Query { blocks { ...B } } fragment B on Block { parent { ...C } } fragment C on Block { ... on Block { ...B } }`,
+	} {
+		_, err := parseGraphQL(q)
+		if err == nil || !strings.Contains(err.Error(), "fragment cycle") {
+			t.Fatalf("%q: err=%v", q, err)
+		}
+		if resp := testSchema(nil).Execute(gqlRequest{Query: q}); resp.Data != nil || len(resp.Errors) != 1 {
+			t.Fatalf("executed cyclic document: %+v", resp)
+		}
+	}
+	// reusing a fragment is not a cycle
+	if _, err := parseGraphQL(`{ a: block(height: 1) { ...F } b: block(height: 2) { ...F } } fragment F on Block { height }`); err != nil {
+		t.Fatalf("shared fragment: %v", err)
+	}
+}
+
+func TestGraphQLExecute(t *testing.T) {
+	s := testSchema(nil)
+	resp := s.Execute(gqlRequest{
+		Query: `query Q($h: Int!, $n: Int = 3) {
+			b: block(height: $h) { height ...Parent }
+			blocks(limit: $n) { height }
+			latest: blocks { ... on Block { height } }
+			missing: block(height: 42) { height }
+			__typename
+		}
+		fragment Parent on Block { parent { __typename height } }`,
+		Variables: map[string]interface{}{"h": float64(5)},
+	})
+	if len(resp.Errors) != 0 {
+		t.Fatalf("errors %+v", resp.Errors)
+	}
+	want := `{"b":{"height":5,"parent":{"__typename":"Block","height":4}},` +
+		`"blocks":[{"height":9},{"height":8},{"height":7}],` +
+		`"latest":[{"height":9},{"height":8}],"missing":null,"__typename":"Query"}`
+	if got := gqlJSON(t, resp.Data); got != want {
+		t.Fatalf("data\n got %s\nwant %s", got, want)
+	}
+
+	for q, msg := range map[string]string{
+		`query ($h: Int!) { block(height: $h) { height } }`: "variable $h of type Int! required",
+		`{ block(height: 1.5) { height } }`:                 "argument height",
+		`{ block(height: 1, at: 2) { height } }`:            "unknown argument at",
+		`{ nope }`:                                          "unknown field nope on Query",
+		`{ block(height: 1) }`:                              "needs a selection set",
+		`{ block(height: 1) { ...Missing } }`:               "unknown fragment Missing",
+		`mutation { block(height: 1) { height } }`:          "mutations are not supported",
+		`subscription { heads { height } }`:                 "must use the WebSocket endpoint",
+		`query A { __typename } query B { __typename }`:     "operationName required",
+	} {
+		resp := s.Execute(gqlRequest{Query: q})
+		if len(resp.Errors) == 0 || !strings.Contains(resp.Errors[0].Message, msg) {
+			t.Fatalf("%q: errors %+v, want %q", q, resp.Errors, msg)
+		}
+	}
+
+	resp = s.Execute(gqlRequest{Query: `query A { __typename } query B { b: __typename }`, OperationName: "B"})
+	if got := gqlJSON(t, resp.Data); got != `{"b":"Query"}` {
+		t.Fatalf("named operation: %s", got)
+	}
+}
+
+func TestGraphQLLimits(t *testing.T) {
+	s := testSchema(nil)
+	nest := func(depth int) string {
+		return `{ block(height: 9) ` + strings.Repeat(`{ parent `, depth-1) + `{ height }` + strings.Repeat(` }`, depth-1) + ` }`
+	}
+	if resp := s.Execute(gqlRequest{Query: nest(gqlMaxDepth - 1)}); len(resp.Errors) != 0 {
+		t.Fatalf("query at the depth limit: %+v", resp.Errors)
+	}
+	resp := s.Execute(gqlRequest{Query: nest(gqlMaxDepth)})
+	if resp.Data != nil || !strings.Contains(resp.Errors[0].Message, "depth") {
+		t.Fatalf("query past the depth limit: %+v", resp)
+	}
+
+	// each fragment doubles the previous one: 2^20 fields once expanded
+	var b strings.Builder
+	b.WriteString(`{ ...F0 } fragment F20 on Query { __typename }`)
+	for i := 0; i < 20; i++ {
+		b.WriteString(" fragment F" + strconv.Itoa(i) + " on Query { a: __typename ...F" + strconv.Itoa(i+1) + " ... on Query { ...F" + strconv.Itoa(i+1) + " } }")
+	}
+	start := time.Now()
+	resp = s.Execute(gqlRequest{Query: b.String()})
+	if resp.Data != nil || !strings.Contains(resp.Errors[0].Message, "more than") {
+		t.Fatalf("query past the complexity limit: %+v", resp)
+	}
+	if time.Since(start) > time.Second {
+		t.Fatalf("measuring the query took %v", time.Since(start))
+	}
+}
+
+func TestGraphQLSubscribe(t *testing.T) {
+	ch := make(chan interface{})
+	s := testSchema(ch)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if _, err := s.Subscribe(ctx, gqlRequest{Query: `{ __typename }`}); err != errNotSubscription {
+		t.Fatalf("query as subscription: err=%v", err)
+	}
+	if _, err := s.Subscribe(ctx, gqlRequest{Query: `subscription { heads { height } h2: heads { height } }`}); err == nil {
+		t.Fatal("subscription with two root fields accepted")
+	}
+	stream, err := s.Subscribe(ctx, gqlRequest{Query: `subscription S { head: heads { ...H } } fragment H on Block { height parent { height } }`})
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	for _, h := range []int{3, 4} {
+		ch <- &testBlock{Height: h}
+		resp := <-stream
+		want := `{"head":{"height":` + strconv.Itoa(h) + `,"parent":{"height":` + strconv.Itoa(h-1) + `}}}`
+		if got := gqlJSON(t, resp.Data); got != want || len(resp.Errors) != 0 {
+			t.Fatalf("event %d: %s %+v", h, got, resp.Errors)
+		}
+	}
+	close(ch)
+	if _, open := <-stream; open {
+		t.Fatal("stream still open after the source closed")
+	}
+}
+
+func TestGraphQLWebSocketOrigin(t *testing.T) {
+	s := &Server{}
+	s.SetWSOrigins([]string{" https://Wallet.example.org/ ", ""})
+	for origin, ok := range map[string]bool{
+		"":                           true,
+		"http://explorer.local:8081": true,
+		"https://wallet.example.org": true,
+		"https://evil.example.org":   false,
+		"http://wallet.example.org":  false,
+		"null":                       false,
+	} {
+		r := httptest.NewRequest("GET", "http://explorer.local:8081/graphql", nil)
+		if origin != "" {
+			r.Header.Set("Origin", origin)
+		}
+		if got := s.checkWSOrigin(r); got != ok {
+			t.Fatalf("origin %q allowed=%v, want %v", origin, got, ok)
+		}
+	}
+}
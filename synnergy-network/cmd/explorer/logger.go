@@ -1,8 +1,7 @@
 package main
 
 import (
-	"log"
-	"os"
+	core "synnergy-network/core"
 )
 
-var logger = log.New(os.Stdout, "[EXPLORER] ", log.LstdFlags|log.Lmicroseconds)
+var logger = core.ModuleLogger("explorer")
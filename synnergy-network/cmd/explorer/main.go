@@ -1,6 +1,11 @@
 package main
 
 import (
+	"encoding/json"
+	"flag"
+	"os"
+	"strings"
+
 	"github.com/joho/godotenv"
 	"github.com/spf13/viper"
 
@@ -8,6 +13,15 @@ import (
 )
 
 func main() {
+	openapi := flag.Bool("openapi", false, "print the v1 OpenAPI spec and exit")
+	flag.Parse()
+	if *openapi {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(OpenAPISpec())
+		return
+	}
+
 	// Load environment variables from project .env if present
 	_ = godotenv.Load(".env")
 	_ = godotenv.Load("../.env")
@@ -34,6 +48,13 @@ func main() {
 	}
 
 	srv := NewServer(addr, svc)
+	srv.SetBatchConfig(core.BatchConfig{
+		MaxCalls:    viper.GetInt("EXPLORER_BATCH_MAX"),
+		Concurrency: viper.GetInt("EXPLORER_BATCH_CONCURRENCY"),
+	})
+	if origins := viper.GetString("EXPLORER_WS_ORIGINS"); origins != "" {
+		srv.SetWSOrigins(strings.Split(origins, ","))
+	}
 
 	logger.Printf("listening on %s", addr)
 	if err := srv.Start(); err != nil {
@@ -1,12 +1,8 @@
 package main
 
 import (
-	"net/http"
+	core "synnergy-network/core"
 )
 
-func loggingMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		logger.Printf("%s %s", r.Method, r.URL.Path)
-		next.ServeHTTP(w, r)
-	})
-}
+// loggingMiddleware assigns request IDs and writes access logs.
+var loggingMiddleware = core.RequestLogger("explorer")
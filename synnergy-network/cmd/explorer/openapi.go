@@ -0,0 +1,94 @@
+package main
+
+import (
+	"net/http"
+	"reflect"
+
+	core "synnergy-network/core"
+	"synnergy-network/pkg/openapi"
+)
+
+// OpenAPISpec builds the OpenAPI 3 document of the v1 API from v1Routes.
+// Response schemas are derived from the Go types by reflection so the spec
+// follows the JSON the handlers actually emit.
+func OpenAPISpec() map[string]interface{} {
+	schemas := map[string]interface{}{}
+	paths := map[string]interface{}{}
+	for _, rt := range v1Routes {
+		params := []interface{}{}
+		for _, p := range rt.Params {
+			params = append(params, map[string]interface{}{
+				"name":        p.Name,
+				"in":          p.In,
+				"required":    p.In == "path",
+				"description": p.Desc,
+				"schema":      map[string]interface{}{"type": p.Type},
+			})
+		}
+		data := openapi.SchemaOf(reflect.TypeOf(rt.Response), schemas)
+		body := data
+		if rt.Paginated {
+			params = append(params,
+				map[string]interface{}{"name": "page", "in": "query", "schema": map[string]interface{}{"type": "integer", "minimum": 1, "default": 1}},
+				map[string]interface{}{"name": "per_page", "in": "query", "schema": map[string]interface{}{"type": "integer", "minimum": 1, "maximum": maxPerPage, "default": defaultPerPage}},
+			)
+			body = map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"data":       data,
+					"pagination": map[string]interface{}{"$ref": "#/components/schemas/Pagination"},
+				},
+			}
+		}
+		paths["/api/v1"+rt.Path] = map[string]interface{}{
+			"get": map[string]interface{}{
+				"summary":    rt.Summary,
+				"parameters": params,
+				"responses": map[string]interface{}{
+					"200": map[string]interface{}{
+						"description": "OK",
+						"content":     map[string]interface{}{"application/json": map[string]interface{}{"schema": body}},
+					},
+					"304": map[string]interface{}{"description": "Not modified (If-None-Match matched the ETag)"},
+					"default": map[string]interface{}{
+						"description": "Error",
+						"content": map[string]interface{}{"application/json": map[string]interface{}{
+							"schema": map[string]interface{}{"$ref": "#/components/schemas/ErrorEnvelope"},
+						}},
+					},
+				},
+			},
+		}
+	}
+	openapi.SchemaOf(reflect.TypeOf(pagination{}), schemas)
+	openapi.SchemaOf(reflect.TypeOf(core.ErrorEnvelope{}), schemas)
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":   "Synnergy Explorer API",
+			"version": "1.0.0",
+		},
+		"paths":      paths,
+		"components": map[string]interface{}{"schemas": schemas},
+	}
+}
+
+// v1ValidationSpec describes the parameters of v1Routes for request
+// validation; the document itself is built by OpenAPISpec.
+func v1ValidationSpec() openapi.Spec {
+	spec := openapi.Spec{Title: "Synnergy Explorer API", Version: "1.0.0"}
+	for _, rt := range v1Routes {
+		params := make([]openapi.Param, 0, len(rt.Params)+2)
+		for _, p := range rt.Params {
+			params = append(params, openapi.Param{Name: p.Name, In: p.In, Desc: p.Desc, Type: p.Type})
+		}
+		if rt.Paginated {
+			params = append(params,
+				openapi.Param{Name: "page", In: "query", Type: "integer"},
+				openapi.Param{Name: "per_page", In: "query", Type: "integer"},
+			)
+		}
+		spec.Routes = append(spec.Routes, openapi.Route{Method: http.MethodGet, Path: "/api/v1" + rt.Path, Summary: rt.Summary, Params: params})
+	}
+	return spec
+}
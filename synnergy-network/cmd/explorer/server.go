@@ -3,12 +3,17 @@ package main
 import (
 	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"math"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gorilla/mux"
+
+	core "synnergy-network/core"
+	"synnergy-network/pkg/httpcache"
 )
 
 // Server exposes ledger data over a small HTTP API.
@@ -16,11 +21,20 @@ type Server struct {
 	router     *mux.Router
 	httpServer *http.Server
 	service    ExplorerService
+	batch      core.BatchConfig
+	cache      *httpcache.Cache
+	wsOrigins  []string
 }
 
-// NewServer constructs the router and HTTP server.
+// NewServer constructs the router and HTTP server. GET responses go through
+// an httpcache.Cache; when svc reports the chain head, head data is held
+// until the next block.
 func NewServer(addr string, svc ExplorerService) *Server {
-	s := &Server{router: mux.NewRouter(), service: svc}
+	var cfg httpcache.Config
+	if v1, ok := svc.(ExplorerV1Service); ok {
+		cfg.Version = v1.Head
+	}
+	s := &Server{router: mux.NewRouter(), service: svc, cache: httpcache.New(cfg)}
 	s.routes()
 	s.httpServer = &http.Server{
 		Addr:         addr,
@@ -34,28 +48,61 @@ func NewServer(addr string, svc ExplorerService) *Server {
 
 func (s *Server) Start() error { return s.httpServer.ListenAndServe() }
 
+// SetBatchConfig sets the limits of POST /api/batch.
+func (s *Server) SetBatchConfig(cfg core.BatchConfig) { s.batch = cfg }
+
 func (s *Server) routes() {
 	s.router.Use(loggingMiddleware)
+	s.router.Use(s.cache.Middleware)
 	s.router.HandleFunc("/api/blocks", s.handleBlocks).Methods("GET")
 	s.router.HandleFunc("/api/blocks/{height:[0-9]+}", s.handleBlock).Methods("GET")
 	s.router.HandleFunc("/api/tx/{id}", s.handleTx).Methods("GET")
 	s.router.HandleFunc("/api/balance/{addr}", s.handleBalance).Methods("GET")
 	s.router.HandleFunc("/api/info", s.handleInfo).Methods("GET")
+	s.router.HandleFunc("/api/charity/cycles", s.handleCharityCycles).Methods("GET")
+	s.router.HandleFunc("/api/charity/cycles/{cycle:[0-9]+}", s.handleCharityCycle).Methods("GET")
+	s.router.HandleFunc("/api/market/listings", s.handleMarketListings).Methods("GET")
+	s.router.HandleFunc("/api/market/listings/{id}", s.handleMarketListing).Methods("GET")
+	s.router.HandleFunc("/api/market/listings/{id}/bids", s.handleMarketBids).Methods("GET")
+	s.router.HandleFunc("/api/games/leaderboard", s.handleGameLeaderboard).Methods("GET")
+	s.router.HandleFunc("/api/crowdfunds", s.handleCrowdfunds).Methods("GET")
+	s.router.HandleFunc("/api/crowdfunds/{id:[0-9]+}", s.handleCrowdfund).Methods("GET")
+	if v1, ok := s.service.(ExplorerV1Service); ok {
+		s.routesV1(v1)
+	}
+	if gql, ok := s.service.(ExplorerGraphQLService); ok {
+		s.routesGraphQL(gql)
+	}
+	if cs, ok := s.service.(ExplorerContractService); ok {
+		s.router.HandleFunc("/api/contracts/paused", s.handlePausedContracts(cs)).Methods("GET")
+		s.router.HandleFunc("/api/contracts/{addr}/pauses", s.handleContractPauses(cs)).Methods("GET")
+	}
+	if ts, ok := s.service.(ExplorerTreasuryService); ok {
+		s.router.HandleFunc("/api/loanpool/treasury/history", s.handleTreasuryHistory(ts)).Methods("GET")
+		s.router.HandleFunc("/api/loanpool/treasury/report", s.handleTreasuryReport(ts)).Methods("GET")
+	}
+	s.router.HandleFunc("/api/batch", s.handleBatch).Methods("POST")
 
 	// serve static GUI
 	s.router.PathPrefix("/").Handler(http.FileServer(http.Dir("GUI/explorer")))
 }
 
+// handleBatch runs an array of API or GraphQL calls against the router in
+// one round trip; see core.BatchHandler.
+func (s *Server) handleBatch(w http.ResponseWriter, r *http.Request) {
+	core.BatchHandler(s.router, s.batch, "explorer").ServeHTTP(w, r)
+}
+
 func (s *Server) handleBlocks(w http.ResponseWriter, r *http.Request) {
 	count := 10
 	if c := r.URL.Query().Get("count"); c != "" {
 		n, err := strconv.Atoi(c)
 		if err != nil || n <= 0 {
-			http.Error(w, "invalid count", http.StatusBadRequest)
+			writeError(w, http.StatusBadRequest, errors.New("invalid count"))
 			return
 		}
 		if n > 100 {
-			http.Error(w, "count too large", http.StatusBadRequest)
+			writeError(w, http.StatusBadRequest, errors.New("count too large"))
 			return
 		}
 		count = n
@@ -68,26 +115,29 @@ func (s *Server) handleBlock(w http.ResponseWriter, r *http.Request) {
 	hStr := vars["height"]
 	h, err := strconv.ParseUint(hStr, 10, 64)
 	if err != nil {
-		http.Error(w, "invalid height", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, errors.New("invalid height"))
 		return
 	}
 	blk, err := s.service.BlockByHeight(h)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		writeError(w, http.StatusNotFound, err)
 		return
 	}
+	if v1, ok := s.service.(ExplorerV1Service); ok && final(v1, h) {
+		httpcache.Immutable(w)
+	}
 	writeJSON(w, blk)
 }
 
 func (s *Server) handleTx(w http.ResponseWriter, r *http.Request) {
 	idHex := mux.Vars(r)["id"]
 	if _, err := hex.DecodeString(idHex); err != nil {
-		http.Error(w, "invalid tx id", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, errors.New("invalid tx id"))
 		return
 	}
 	tx, err := s.service.TxByID(idHex)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		writeError(w, http.StatusNotFound, err)
 		return
 	}
 	writeJSON(w, tx)
@@ -97,12 +147,12 @@ func (s *Server) handleBalance(w http.ResponseWriter, r *http.Request) {
 	addr := mux.Vars(r)["addr"]
 	addr = strings.TrimPrefix(addr, "0x")
 	if _, err := hex.DecodeString(addr); err != nil {
-		http.Error(w, "invalid address", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, errors.New("invalid address"))
 		return
 	}
 	bal, err := s.service.Balance(addr)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, err)
 		return
 	}
 	writeJSON(w, map[string]interface{}{"balance": bal})
@@ -112,6 +162,210 @@ func (s *Server) handleInfo(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, s.service.Info())
 }
 
+func (s *Server) handleCharityCycles(w http.ResponseWriter, r *http.Request) {
+	reps, err := s.service.CharityCycles()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, reps)
+}
+
+func (s *Server) handleCharityCycle(w http.ResponseWriter, r *http.Request) {
+	n, err := strconv.ParseUint(mux.Vars(r)["cycle"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errors.New("invalid cycle"))
+		return
+	}
+	rep, err := s.service.CharityCycle(n)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	writeJSON(w, rep)
+}
+
+// handleMarketListings lists listings. Optional filters: seller=<addr>,
+// kind=fixed|english|dutch and open=true for unsettled listings.
+func (s *Server) handleMarketListings(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	var seller *core.Address
+	if sv := q.Get("seller"); sv != "" {
+		a, err := core.ParseAddress(strings.TrimPrefix(sv, "0x"))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, errors.New("invalid seller"))
+			return
+		}
+		seller = &a
+	}
+	list, err := s.service.MarketListings(seller)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	kind, open := q.Get("kind"), q.Get("open") == "true"
+	out := make([]core.MarketListing, 0, len(list))
+	for _, l := range list {
+		if kind != "" && l.Kind != kind {
+			continue
+		}
+		if open && (l.Sold || l.Settled) {
+			continue
+		}
+		out = append(out, l)
+	}
+	writeJSON(w, out)
+}
+
+func (s *Server) handleMarketListing(w http.ResponseWriter, r *http.Request) {
+	l, err := s.service.MarketListing(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	writeJSON(w, l)
+}
+
+func (s *Server) handleMarketBids(w http.ResponseWriter, r *http.Request) {
+	bids, err := s.service.MarketBids(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, bids)
+}
+
+// handleGameLeaderboard ranks players of turn-based games. Optional
+// limit=<n> truncates the result.
+func (s *Server) handleGameLeaderboard(w http.ResponseWriter, r *http.Request) {
+	limit := 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			writeError(w, http.StatusBadRequest, errors.New("invalid limit"))
+			return
+		}
+		limit = n
+	}
+	board, err := s.service.GameLeaderboard(limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, board)
+}
+
+// handleCrowdfunds lists crowdfunding campaigns. Optional filter:
+// status=open|funded|completed|failed.
+func (s *Server) handleCrowdfunds(w http.ResponseWriter, r *http.Request) {
+	list, err := s.service.Crowdfunds()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	status := core.CrowdfundStatus(r.URL.Query().Get("status"))
+	out := make([]core.Crowdfund, 0, len(list))
+	for _, cf := range list {
+		if status == "" || cf.Status == status {
+			out = append(out, cf)
+		}
+	}
+	writeJSON(w, out)
+}
+
+func (s *Server) handleCrowdfund(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errors.New("invalid id"))
+		return
+	}
+	cf, contribs, err := s.service.Crowdfund(id)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	writeJSON(w, map[string]interface{}{"crowdfund": cf, "contributions": contribs})
+}
+
+// handlePausedContracts lists contracts currently stopped by the circuit
+// breaker.
+func (s *Server) handlePausedContracts(cs ExplorerContractService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		list, err := cs.PausedContracts()
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, list)
+	}
+}
+
+// handleContractPauses returns a contract's pause and resume history.
+func (s *Server) handleContractPauses(cs ExplorerContractService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log, err := cs.ContractPauseLog(mux.Vars(r)["addr"])
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		writeJSON(w, log)
+	}
+}
+
+// treasuryRange parses the from and to query parameters (unix seconds,
+// RFC 3339 or a date); missing bounds are open.
+func treasuryRange(r *http.Request) (from, to int64, err error) {
+	from, to = 0, math.MaxInt64
+	q := r.URL.Query()
+	if v := q.Get("from"); v != "" {
+		if from, err = core.ParseTreasuryTime(v); err != nil {
+			return 0, 0, err
+		}
+	}
+	if v := q.Get("to"); v != "" {
+		if to, err = core.ParseTreasuryTime(v); err != nil {
+			return 0, 0, err
+		}
+	}
+	return from, to, nil
+}
+
+// handleTreasuryHistory returns the LoanPool treasury entries, with the
+// balance after each, between from and to. Optional filter: category.
+func (s *Server) handleTreasuryHistory(ts ExplorerTreasuryService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		from, to, err := treasuryRange(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		list, err := ts.TreasuryHistory(from, to, core.TreasuryCategory(r.URL.Query().Get("category")))
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, list)
+	}
+}
+
+// handleTreasuryReport returns the opening and closing balance and the
+// inflows and outflows by category between from and to.
+func (s *Server) handleTreasuryReport(ts ExplorerTreasuryService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		from, to, err := treasuryRange(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		rep, err := ts.TreasuryReport(from, to)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, rep)
+	}
+}
+
 func writeJSON(w http.ResponseWriter, v interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	enc := json.NewEncoder(w)
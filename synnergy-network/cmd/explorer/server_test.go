@@ -41,6 +41,53 @@ func (m *mockService) Info() map[string]interface{} {
 	return map[string]interface{}{"height": uint64(1)}
 }
 
+func (m *mockService) CharityCycles() ([]core.CharityCycleReport, error) {
+	return []core.CharityCycleReport{{Cycle: 0, Finalised: true}}, nil
+}
+
+func (m *mockService) CharityCycle(cycle uint64) (*core.CharityCycleReport, error) {
+	if cycle != 0 {
+		return nil, core.ErrNotFound
+	}
+	return &core.CharityCycleReport{Cycle: 0, Finalised: true}, nil
+}
+
+func (m *mockService) MarketListings(seller *core.Address) ([]core.MarketListing, error) {
+	return []core.MarketListing{
+		{ID: "a", Kind: core.ListingEnglish},
+		{ID: "b", Kind: core.ListingFixed, Sold: true},
+	}, nil
+}
+
+func (m *mockService) MarketListing(id string) (*core.MarketListing, error) {
+	if id != "a" {
+		return nil, core.ErrNotFound
+	}
+	return &core.MarketListing{ID: "a", Kind: core.ListingEnglish}, nil
+}
+
+func (m *mockService) MarketBids(id string) ([]core.MarketBid, error) {
+	return []core.MarketBid{{ListingID: id, Amount: 10}}, nil
+}
+
+func (m *mockService) GameLeaderboard(limit int) ([]core.GameStanding, error) {
+	return []core.GameStanding{{Wins: 2, Played: 3}}, nil
+}
+
+func (m *mockService) Crowdfunds() ([]core.Crowdfund, error) {
+	return []core.Crowdfund{
+		{ID: 1, Status: core.CrowdfundOpen},
+		{ID: 2, Status: core.CrowdfundFailed},
+	}, nil
+}
+
+func (m *mockService) Crowdfund(id uint64) (*core.Crowdfund, []core.CrowdfundContribution, error) {
+	if id != 1 {
+		return nil, nil, core.ErrNotFound
+	}
+	return &core.Crowdfund{ID: 1, Status: core.CrowdfundOpen}, []core.CrowdfundContribution{{Amount: 5}}, nil
+}
+
 func newTestServer() *Server {
 	svc := &mockService{}
 	return NewServer(":0", svc)
@@ -169,3 +216,54 @@ func TestHandleInfo(t *testing.T) {
 		t.Fatalf("expected 200, got %d", rr.Code)
 	}
 }
+
+func TestHandleCharityCycles(t *testing.T) {
+	srv := newTestServer()
+	req := httptest.NewRequest(http.MethodGet, "/api/charity/cycles", nil)
+	rr := httptest.NewRecorder()
+	srv.router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+	var reps []core.CharityCycleReport
+	if err := json.Unmarshal(rr.Body.Bytes(), &reps); err != nil || len(reps) != 1 {
+		t.Fatalf("unexpected body %s", rr.Body.String())
+	}
+}
+
+func TestHandleCharityCycleNotFound(t *testing.T) {
+	srv := newTestServer()
+	req := httptest.NewRequest(http.MethodGet, "/api/charity/cycles/7", nil)
+	rr := httptest.NewRecorder()
+	srv.router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", rr.Code)
+	}
+}
+
+func TestHandleMarketListingsFilter(t *testing.T) {
+	srv := newTestServer()
+	req := httptest.NewRequest(http.MethodGet, "/api/market/listings?open=true", nil)
+	rr := httptest.NewRecorder()
+	srv.router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+	var list []core.MarketListing
+	if err := json.Unmarshal(rr.Body.Bytes(), &list); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(list) != 1 || list[0].ID != "a" {
+		t.Fatalf("unexpected listings: %+v", list)
+	}
+}
+
+func TestHandleMarketListingNotFound(t *testing.T) {
+	srv := newTestServer()
+	req := httptest.NewRequest(http.MethodGet, "/api/market/listings/zzz", nil)
+	rr := httptest.NewRecorder()
+	srv.router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", rr.Code)
+	}
+}
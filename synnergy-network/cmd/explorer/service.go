@@ -17,9 +17,35 @@ type ExplorerService interface {
 	TxByID(hexID string) (*core.Transaction, error)
 	Balance(addrHex string) (uint64, error)
 	Info() map[string]interface{}
+	CharityCycles() ([]core.CharityCycleReport, error)
+	CharityCycle(cycle uint64) (*core.CharityCycleReport, error)
+	MarketListings(seller *core.Address) ([]core.MarketListing, error)
+	MarketListing(id string) (*core.MarketListing, error)
+	MarketBids(id string) ([]core.MarketBid, error)
+	GameLeaderboard(limit int) ([]core.GameStanding, error)
+	Crowdfunds() ([]core.Crowdfund, error)
+	Crowdfund(id uint64) (*core.Crowdfund, []core.CrowdfundContribution, error)
 }
 
-// LedgerService wraps common ledger queries used by the Explorer.
+// ExplorerContractService is implemented by services that expose the
+// contract circuit-breaker registry.
+type ExplorerContractService interface {
+	PausedContracts() ([]core.Address, error)
+	ContractPauseLog(addrHex string) ([]core.ContractPauseEvent, error)
+}
+
+// ExplorerTreasuryService is implemented by services that expose the
+// LoanPool treasury accounts.
+type ExplorerTreasuryService interface {
+	TreasuryHistory(from, to int64, cat core.TreasuryCategory) ([]core.TreasuryEntry, error)
+	TreasuryReport(from, to int64) (*core.TreasuryFlowReport, error)
+}
+
+var _ ExplorerTreasuryService = (*LedgerService)(nil)
+
+// LedgerService wraps common ledger queries used by the Explorer. Queries
+// read the ledger's published read view, so they neither wait for nor hold
+// up block import.
 type LedgerService struct {
 	ledger *core.Ledger
 }
@@ -34,7 +60,7 @@ func NewLedgerService() (*LedgerService, error) {
 
 // LatestBlocks returns summaries for the most recent blocks.
 func (s *LedgerService) LatestBlocks(count int) []map[string]interface{} {
-	blocks := s.ledger.Blocks
+	blocks := s.ledger.ReadView().Blocks()
 	if count > len(blocks) {
 		count = len(blocks)
 	}
@@ -56,7 +82,7 @@ func (s *LedgerService) LatestBlocks(count int) []map[string]interface{} {
 
 // BlockByHeight returns a block at given height.
 func (s *LedgerService) BlockByHeight(h uint64) (*core.Block, error) {
-	return s.ledger.GetBlock(h)
+	return s.ledger.ReadView().GetBlock(h)
 }
 
 // TxByID searches for a transaction by hex encoded ID.
@@ -65,7 +91,7 @@ func (s *LedgerService) TxByID(hexID string) (*core.Transaction, error) {
 	if err != nil {
 		return nil, fmt.Errorf("bad tx id")
 	}
-	for _, blk := range s.ledger.Blocks {
+	for _, blk := range s.ledger.ReadView().Blocks() {
 		for i := range blk.Transactions {
 			tx := blk.Transactions[i]
 			h := tx.ID()
@@ -84,15 +110,15 @@ func (s *LedgerService) Balance(addrHex string) (uint64, error) {
 	if err != nil {
 		return 0, err
 	}
-	return s.ledger.BalanceOf(a), nil
+	return s.ledger.ReadView().BalanceOf(a), nil
 }
 
 // Info returns basic ledger information.
 func (s *LedgerService) Info() map[string]interface{} {
 	var height uint64
 	var hash string
-	if len(s.ledger.Blocks) > 0 {
-		last := s.ledger.Blocks[len(s.ledger.Blocks)-1]
+	if blocks := s.ledger.ReadView().Blocks(); len(blocks) > 0 {
+		last := blocks[len(blocks)-1]
 		height = last.Header.Height
 		hash = last.Hash().Hex()
 	}
@@ -101,3 +127,82 @@ func (s *LedgerService) Info() map[string]interface{} {
 		"hash":   hash,
 	}
 }
+
+// CharityCycles returns the published charity pool cycle reports.
+func (s *LedgerService) CharityCycles() ([]core.CharityCycleReport, error) {
+	return core.ListCharityCycleReports(s.ledger)
+}
+
+// CharityCycle returns the report of a single charity pool cycle.
+func (s *LedgerService) CharityCycle(cycle uint64) (*core.CharityCycleReport, error) {
+	return core.GetCharityCycleReport(s.ledger, cycle)
+}
+
+// MarketListings returns marketplace listings, optionally for one seller.
+func (s *LedgerService) MarketListings(seller *core.Address) ([]core.MarketListing, error) {
+	return core.ListMarketListings(seller)
+}
+
+// MarketListing returns a single marketplace listing.
+func (s *LedgerService) MarketListing(id string) (*core.MarketListing, error) {
+	return core.GetMarketListing(id)
+}
+
+// MarketBids returns the bid history of a listing.
+func (s *LedgerService) MarketBids(id string) ([]core.MarketBid, error) {
+	return core.ListMarketBids(id)
+}
+
+// GameLeaderboard returns turn-based game standings, best first.
+func (s *LedgerService) GameLeaderboard(limit int) ([]core.GameStanding, error) {
+	return core.GameLeaderboard(s.ledger, limit)
+}
+
+// Crowdfunds returns every crowdfunding campaign.
+func (s *LedgerService) Crowdfunds() ([]core.Crowdfund, error) {
+	return core.NewDeFiManager(s.ledger).Crowdfunds()
+}
+
+// Crowdfund returns a campaign with its escrowed contributions.
+func (s *LedgerService) Crowdfund(id uint64) (*core.Crowdfund, []core.CrowdfundContribution, error) {
+	cf, contribs, err := core.NewDeFiManager(s.ledger).CrowdfundOf(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &cf, contribs, nil
+}
+
+// PausedContracts lists contracts currently paused.
+func (s *LedgerService) PausedContracts() ([]core.Address, error) {
+	return core.PausedContracts(s.ledger)
+}
+
+// ContractPauseLog returns the pause history of a contract.
+func (s *LedgerService) ContractPauseLog(addrHex string) ([]core.ContractPauseEvent, error) {
+	a, err := core.ParseAddress(strings.TrimPrefix(addrHex, "0x"))
+	if err != nil {
+		return nil, err
+	}
+	return core.ContractPauseLog(s.ledger, a)
+}
+
+// TreasuryHistory returns the LoanPool treasury entries of a time range,
+// optionally of one category.
+func (s *LedgerService) TreasuryHistory(from, to int64, cat core.TreasuryCategory) ([]core.TreasuryEntry, error) {
+	entries, err := core.TreasuryEntries(s.ledger, from, to)
+	if err != nil || cat == "" {
+		return entries, err
+	}
+	out := []core.TreasuryEntry{}
+	for _, e := range entries {
+		if e.Category == cat {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+// TreasuryReport returns the category breakdown of a time range.
+func (s *LedgerService) TreasuryReport(from, to int64) (*core.TreasuryFlowReport, error) {
+	return core.GetTreasuryFlowReport(s.ledger, from, to)
+}
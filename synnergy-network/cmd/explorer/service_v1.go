@@ -0,0 +1,346 @@
+package main
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	core "synnergy-network/core"
+)
+
+// ExplorerV1Service backs the paginated /api/v1 REST API. Services that
+// implement it in addition to ExplorerService get the v1 routes mounted.
+type ExplorerV1Service interface {
+	Head() uint64
+	Blocks(offset, limit int) ([]BlockSummary, int)
+	Block(ref string) (*core.Block, error)
+	Tx(hash string) (*TxView, error)
+	Address(addr string) (*AddressView, error)
+	AddressTxs(addr string, offset, limit int) ([]TxView, int, error)
+	Tokens(offset, limit int) ([]TokenView, int)
+	TokenHolders(id core.TokenID, offset, limit int) ([]HolderView, int, error)
+	Search(q string) ([]SearchResult, error)
+	Name(name string) (*NameView, error)
+	BlockStateDiff(ref string) (*core.BlockStateDiff, error)
+	TxStateDiff(hash string) (*core.StateDiff, error)
+}
+
+var _ ExplorerV1Service = (*LedgerService)(nil)
+
+// BlockSummary is the list representation of a block.
+type BlockSummary struct {
+	Height    uint64 `json:"height"`
+	Hash      string `json:"hash"`
+	PrevHash  string `json:"prev_hash"`
+	Timestamp int64  `json:"timestamp"`
+	Txs       int    `json:"txs"`
+	Miner     string `json:"miner,omitempty"`
+}
+
+// TxView is a transaction together with its position in the chain.
+type TxView struct {
+	Hash        string            `json:"hash"`
+	BlockHeight uint64            `json:"block_height"`
+	BlockHash   string            `json:"block_hash"`
+	Index       int               `json:"index"`
+	From        string            `json:"from"`
+	To          string            `json:"to"`
+	Value       uint64            `json:"value"`
+	Timestamp   int64             `json:"timestamp"`
+	Tx          *core.Transaction `json:"tx,omitempty"`
+}
+
+// TokenBalance is one token held by an address.
+type TokenBalance struct {
+	ID      core.TokenID `json:"id"`
+	Symbol  string       `json:"symbol"`
+	Balance uint64       `json:"balance"`
+}
+
+// AddressView summarises an account.
+type AddressView struct {
+	Address string         `json:"address"`
+	Name    string         `json:"name,omitempty"`
+	Balance uint64         `json:"balance"`
+	Tokens  []TokenBalance `json:"tokens"`
+	TxCount int            `json:"tx_count"`
+}
+
+// TokenView describes a registered token.
+type TokenView struct {
+	ID          core.TokenID `json:"id"`
+	Name        string       `json:"name"`
+	Symbol      string       `json:"symbol"`
+	Decimals    uint8        `json:"decimals"`
+	Standard    uint16       `json:"standard"`
+	TotalSupply uint64       `json:"total_supply"`
+}
+
+// HolderView is a token holder.
+type HolderView struct {
+	Address string `json:"address"`
+	Balance uint64 `json:"balance"`
+}
+
+// NameView is an SNS name and its resolver records.
+type NameView struct {
+	Name    string            `json:"name"`
+	Owner   string            `json:"owner"`
+	Address string            `json:"address,omitempty"`
+	Content string            `json:"content,omitempty"`
+	Text    map[string]string `json:"text,omitempty"`
+	Expires int64             `json:"expires"`
+	Active  bool              `json:"active"`
+}
+
+// SearchResult is one match of /api/v1/search. Type is block, tx, address,
+// name or token and ID is the value to use in the matching resource path.
+type SearchResult struct {
+	Type  string `json:"type"`
+	ID    string `json:"id"`
+	Label string `json:"label,omitempty"`
+}
+
+// Head returns the current chain height.
+func (s *LedgerService) Head() uint64 { return s.ledger.ReadView().Height() }
+
+// Blocks returns block summaries newest first together with the total
+// block count.
+func (s *LedgerService) Blocks(offset, limit int) ([]BlockSummary, int) {
+	blocks := s.ledger.ReadView().Blocks()
+	total := len(blocks)
+	out := make([]BlockSummary, 0, limit)
+	for i := total - 1 - offset; i >= 0 && len(out) < limit; i-- {
+		out = append(out, summariseBlock(blocks[i]))
+	}
+	return out, total
+}
+
+func summariseBlock(b *core.Block) BlockSummary {
+	return BlockSummary{
+		Height:    b.Header.Height,
+		Hash:      b.Hash().Hex(),
+		PrevHash:  hex.EncodeToString(b.Header.PrevHash),
+		Timestamp: b.Header.Timestamp,
+		Txs:       len(b.Transactions),
+		Miner:     hex.EncodeToString(b.Header.MinerPk),
+	}
+}
+
+// Block resolves a block by height or by hex hash.
+func (s *LedgerService) Block(ref string) (*core.Block, error) {
+	if h, err := strconv.ParseUint(ref, 10, 64); err == nil {
+		return s.ledger.ReadView().GetBlock(h)
+	}
+	hash, err := parseHash(ref)
+	if err != nil {
+		return nil, err
+	}
+	return s.ledger.ReadView().BlockByHash(hash)
+}
+
+// BlockStateDiff returns the state changes of a block, by height or hash.
+func (s *LedgerService) BlockStateDiff(ref string) (*core.BlockStateDiff, error) {
+	blk, err := s.Block(ref)
+	if err != nil {
+		return nil, err
+	}
+	return s.ledger.BlockStateDiff(blk.Header.Height)
+}
+
+// TxStateDiff returns the state changes of a transaction by hex hash.
+func (s *LedgerService) TxStateDiff(hash string) (*core.StateDiff, error) {
+	h, err := parseHash(hash)
+	if err != nil {
+		return nil, err
+	}
+	return s.ledger.TxStateDiff(h)
+}
+
+// Tx finds a transaction by hex hash.
+func (s *LedgerService) Tx(hash string) (*TxView, error) {
+	h, err := parseHash(hash)
+	if err != nil {
+		return nil, err
+	}
+	blocks := s.ledger.ReadView().Blocks()
+	for i := len(blocks) - 1; i >= 0; i-- {
+		for j, tx := range blocks[i].Transactions {
+			if tx.ID() == h {
+				v := txView(blocks[i], j, tx)
+				v.Tx = tx
+				return &v, nil
+			}
+		}
+	}
+	return nil, core.ErrNotFound
+}
+
+func txView(b *core.Block, idx int, tx *core.Transaction) TxView {
+	return TxView{
+		Hash:        tx.ID().Hex(),
+		BlockHeight: b.Header.Height,
+		BlockHash:   b.Hash().Hex(),
+		Index:       idx,
+		From:        tx.From.Hex(),
+		To:          tx.To.Hex(),
+		Value:       tx.Value,
+		Timestamp:   tx.Timestamp,
+	}
+}
+
+// Address returns the coin balance, token balances and transaction count
+// of an account. addr may be hex, bech32 or an SNS name.
+func (s *LedgerService) Address(addr string) (*AddressView, error) {
+	a, err := core.ResolveRecipient(addr)
+	if err != nil {
+		return nil, err
+	}
+	v := &AddressView{Address: a.Hex(), Balance: s.ledger.ReadView().BalanceOf(a), Tokens: []TokenBalance{}}
+	v.Name, _ = core.ReverseName(a)
+	for _, t := range core.GetRegistryTokens() {
+		if bal := t.BalanceOf(a); bal > 0 {
+			v.Tokens = append(v.Tokens, TokenBalance{ID: t.ID(), Symbol: t.Meta().Symbol, Balance: bal})
+		}
+	}
+	_, v.TxCount, _ = s.AddressTxs(addr, 0, 0)
+	return v, nil
+}
+
+// AddressTxs returns the transactions sent or received by addr, newest
+// first, and the total number of such transactions.
+func (s *LedgerService) AddressTxs(addr string, offset, limit int) ([]TxView, int, error) {
+	a, err := core.ResolveRecipient(addr)
+	if err != nil {
+		return nil, 0, err
+	}
+	var out []TxView
+	total := 0
+	blocks := s.ledger.ReadView().Blocks()
+	for i := len(blocks) - 1; i >= 0; i-- {
+		txs := blocks[i].Transactions
+		for j := len(txs) - 1; j >= 0; j-- {
+			tx := txs[j]
+			if tx.From != a && tx.To != a {
+				continue
+			}
+			if total >= offset && len(out) < limit {
+				out = append(out, txView(blocks[i], j, tx))
+			}
+			total++
+		}
+	}
+	return out, total, nil
+}
+
+// Tokens lists registered tokens by ID.
+func (s *LedgerService) Tokens(offset, limit int) ([]TokenView, int) {
+	all := core.GetRegistryTokens()
+	out := make([]TokenView, 0, limit)
+	for i := offset; i < len(all) && len(out) < limit; i++ {
+		m := all[i].Meta()
+		out = append(out, TokenView{
+			ID:          all[i].ID(),
+			Name:        m.Name,
+			Symbol:      m.Symbol,
+			Decimals:    m.Decimals,
+			Standard:    uint16(m.Standard),
+			TotalSupply: m.TotalSupply,
+		})
+	}
+	return out, len(all)
+}
+
+// TokenHolders lists the holders of a token, largest balance first.
+func (s *LedgerService) TokenHolders(id core.TokenID, offset, limit int) ([]HolderView, int, error) {
+	holders, err := core.TokenHolders(id)
+	if err != nil {
+		return nil, 0, err
+	}
+	out := make([]HolderView, 0, limit)
+	for i := offset; i < len(holders) && len(out) < limit; i++ {
+		out = append(out, HolderView{Address: holders[i].Address.Hex(), Balance: holders[i].Balance})
+	}
+	return out, len(holders), nil
+}
+
+// Search matches q against block heights and hashes, transaction hashes,
+// addresses, SNS names and token symbols or names.
+func (s *LedgerService) Search(q string) ([]SearchResult, error) {
+	q = strings.TrimSpace(q)
+	if q == "" {
+		return nil, fmt.Errorf("empty query")
+	}
+	var out []SearchResult
+	if h, err := strconv.ParseUint(q, 10, 64); err == nil {
+		if b, err := s.ledger.ReadView().GetBlock(h); err == nil {
+			out = append(out, SearchResult{Type: "block", ID: q, Label: b.Hash().Hex()})
+		}
+	}
+	raw := strings.TrimPrefix(strings.ToLower(q), "0x")
+	switch len(raw) {
+	case 64:
+		if b, err := s.Block(raw); err == nil {
+			out = append(out, SearchResult{Type: "block", ID: raw, Label: strconv.FormatUint(b.Header.Height, 10)})
+		}
+		if tx, err := s.Tx(raw); err == nil {
+			out = append(out, SearchResult{Type: "tx", ID: raw, Label: fmt.Sprintf("block %d", tx.BlockHeight)})
+		}
+	}
+	if a, err := core.DecodeAddress(q); err == nil {
+		out = append(out, SearchResult{Type: "address", ID: a.Hex(), Label: a.Bech32()})
+	}
+	if core.IsName(q) {
+		if rec, err := core.LookupName(q); err == nil {
+			out = append(out, SearchResult{Type: "name", ID: rec.Name, Label: rec.Addr.Hex()})
+		}
+	}
+	lq := strings.ToLower(q)
+	for _, t := range core.GetRegistryTokens() {
+		m := t.Meta()
+		if strings.ToLower(m.Symbol) == lq || strings.Contains(strings.ToLower(m.Name), lq) {
+			out = append(out, SearchResult{Type: "token", ID: strconv.FormatUint(uint64(t.ID()), 10), Label: m.Symbol})
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return searchRank[out[i].Type] < searchRank[out[j].Type] })
+	return out, nil
+}
+
+var searchRank = map[string]int{"block": 0, "tx": 1, "address": 2, "name": 3, "token": 4}
+
+// Name returns the registration and records of an SNS name.
+func (s *LedgerService) Name(name string) (*NameView, error) {
+	rec, err := core.LookupName(name)
+	if err != nil {
+		if errors.Is(err, core.ErrNameNotFound) {
+			return nil, fmt.Errorf("%w: %v", core.ErrNotFound, err)
+		}
+		return nil, err
+	}
+	v := &NameView{
+		Name:    rec.Name,
+		Owner:   rec.Owner.Hex(),
+		Content: rec.Content,
+		Text:    rec.Text,
+		Expires: rec.Expires.Unix(),
+		Active:  rec.Active(time.Now().UTC()),
+	}
+	if rec.Addr != (core.Address{}) {
+		v.Address = rec.Addr.Hex()
+	}
+	return v, nil
+}
+
+func parseHash(s string) (core.Hash, error) {
+	var h core.Hash
+	b, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil || len(b) != len(h) {
+		return h, fmt.Errorf("invalid hash")
+	}
+	copy(h[:], b)
+	return h, nil
+}
@@ -0,0 +1,259 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	core "synnergy-network/core"
+	"synnergy-network/pkg/utils"
+)
+
+var (
+	faucet     *core.Faucet
+	adminToken string
+	trustProxy bool
+)
+
+// siteVerifier checks CAPTCHA tokens against an hCaptcha/reCAPTCHA style
+// siteverify endpoint.
+type siteVerifier struct {
+	url    string
+	secret string
+	client *http.Client
+}
+
+func (v *siteVerifier) VerifyCaptcha(token, ip string) error {
+	form := url.Values{"secret": {v.secret}, "response": {token}}
+	if ip != "" {
+		form.Set("remoteip", ip)
+	}
+	resp, err := v.client.PostForm(v.url, form)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	var out struct {
+		Success bool `json:"success"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return err
+	}
+	if !out.Success {
+		return errors.New("verification failed")
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeErr(w http.ResponseWriter, code int, err error) {
+	writeJSON(w, code, map[string]string{"error": err.Error()})
+}
+
+func clientIP(r *http.Request) string {
+	if trustProxy {
+		if fwd := r.Header.Get("X-Forwarded-For"); fwd != "" {
+			return strings.TrimSpace(strings.Split(fwd, ",")[0])
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func challengeHandler(w http.ResponseWriter, r *http.Request) {
+	ch, err := faucet.Challenge()
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, ch)
+}
+
+func claimHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeErr(w, http.StatusMethodNotAllowed, errors.New("POST required"))
+		return
+	}
+	var req struct {
+		To       string `json:"to"`
+		Nonce    string `json:"nonce"`
+		Solution string `json:"solution"`
+		Captcha  string `json:"captcha"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, http.StatusBadRequest, err)
+		return
+	}
+	to, err := core.StringToAddress(req.To)
+	if err != nil {
+		writeErr(w, http.StatusBadRequest, err)
+		return
+	}
+	grant, err := faucet.Claim(core.FaucetClaim{
+		To:       to,
+		IP:       clientIP(r),
+		Nonce:    req.Nonce,
+		Solution: req.Solution,
+		Captcha:  req.Captcha,
+	})
+	switch {
+	case errors.Is(err, core.ErrFaucetCooldown):
+		writeErr(w, http.StatusTooManyRequests, err)
+	case errors.Is(err, core.ErrFaucetChallenge), errors.Is(err, core.ErrFaucetCaptcha):
+		writeErr(w, http.StatusForbidden, err)
+	case err != nil:
+		writeErr(w, http.StatusServiceUnavailable, err)
+	default:
+		writeJSON(w, http.StatusOK, grant)
+	}
+}
+
+func infoHandler(w http.ResponseWriter, _ *http.Request) {
+	bal, err := faucet.Balance()
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"balance": bal, "settings": faucet.Settings()})
+}
+
+// admin wraps h with bearer-token authentication.
+func admin(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tok := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if adminToken == "" || subtle.ConstantTimeCompare([]byte(tok), []byte(adminToken)) != 1 {
+			writeErr(w, http.StatusUnauthorized, errors.New("unauthorized"))
+			return
+		}
+		if r.Method != http.MethodPost {
+			writeErr(w, http.StatusMethodNotAllowed, errors.New("POST required"))
+			return
+		}
+		h(w, r)
+	}
+}
+
+func moveHandler(withdraw bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Addr   string `json:"addr"`
+			Amount uint64 `json:"amount"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeErr(w, http.StatusBadRequest, err)
+			return
+		}
+		addr, err := core.StringToAddress(req.Addr)
+		if err != nil {
+			writeErr(w, http.StatusBadRequest, err)
+			return
+		}
+		if withdraw {
+			err = faucet.Withdraw(addr, req.Amount)
+		} else {
+			err = faucet.TopUp(addr, req.Amount)
+		}
+		if err != nil {
+			writeErr(w, http.StatusBadRequest, err)
+			return
+		}
+		infoHandler(w, r)
+	}
+}
+
+func configHandler(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Amount     *uint64 `json:"amount"`
+		Cooldown   string  `json:"cooldown"`
+		IPCooldown string  `json:"ip_cooldown"`
+		Difficulty *uint8  `json:"difficulty"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, http.StatusBadRequest, err)
+		return
+	}
+	if req.Amount != nil {
+		if *req.Amount == 0 {
+			writeErr(w, http.StatusBadRequest, errors.New("amount must be >0"))
+			return
+		}
+		faucet.SetAmount(*req.Amount)
+	}
+	for _, d := range []struct {
+		val string
+		set func(time.Duration)
+	}{{req.Cooldown, faucet.SetCooldown}, {req.IPCooldown, faucet.SetIPCooldown}} {
+		if d.val == "" {
+			continue
+		}
+		dur, err := time.ParseDuration(d.val)
+		if err != nil || dur <= 0 {
+			writeErr(w, http.StatusBadRequest, fmt.Errorf("invalid duration %q", d.val))
+			return
+		}
+		d.set(dur)
+	}
+	if req.Difficulty != nil {
+		faucet.SetDifficulty(*req.Difficulty)
+	}
+	writeJSON(w, http.StatusOK, faucet.Settings())
+}
+
+func main() {
+	if err := core.InitLedger(utils.EnvOrDefault("LEDGER_PATH", "")); err != nil {
+		log.Fatalf("ledger init: %v", err)
+	}
+	logger := log.New()
+
+	cooldown, err := time.ParseDuration(utils.EnvOrDefault("FAUCET_COOLDOWN", "24h"))
+	if err != nil {
+		log.Fatalf("FAUCET_COOLDOWN: %v", err)
+	}
+	ipCooldown, err := time.ParseDuration(utils.EnvOrDefault("FAUCET_IP_COOLDOWN", cooldown.String()))
+	if err != nil {
+		log.Fatalf("FAUCET_IP_COOLDOWN: %v", err)
+	}
+	faucet = core.NewFaucet(logger, core.CurrentLedger(),
+		core.TokenID(utils.EnvOrDefaultUint64("FAUCET_TOKEN", 0)),
+		utils.EnvOrDefaultUint64("FAUCET_AMOUNT", 100),
+		cooldown)
+	faucet.SetIPCooldown(ipCooldown)
+	faucet.SetDifficulty(uint8(utils.EnvOrDefaultInt("FAUCET_POW_BITS", 20)))
+	if u := utils.EnvOrDefault("FAUCET_CAPTCHA_VERIFY_URL", ""); u != "" {
+		faucet.SetCaptchaVerifier(&siteVerifier{
+			url:    u,
+			secret: utils.EnvOrDefault("FAUCET_CAPTCHA_SECRET", ""),
+			client: &http.Client{Timeout: 5 * time.Second},
+		})
+	}
+	adminToken = utils.EnvOrDefault("FAUCET_ADMIN_TOKEN", "")
+	trustProxy = utils.EnvOrDefault("FAUCET_TRUST_PROXY", "") == "1"
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/challenge", challengeHandler)
+	mux.HandleFunc("/api/claim", claimHandler)
+	mux.HandleFunc("/api/info", infoHandler)
+	mux.HandleFunc("/api/admin/topup", admin(moveHandler(false)))
+	mux.HandleFunc("/api/admin/withdraw", admin(moveHandler(true)))
+	mux.HandleFunc("/api/admin/config", admin(configHandler))
+
+	addr := utils.EnvOrDefault("FAUCET_API_ADDR", "127.0.0.1:8090")
+	logger.Printf("faucetserver listening on %s", addr)
+	logger.Fatal(http.ListenAndServe(addr, mux))
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"log"
 
@@ -8,6 +9,8 @@ import (
 )
 
 func main() {
+	dump := flag.Bool("dump", false, "print the opcode catalogue")
+	flag.Parse()
 	ops := core.Catalogue()
 	seenOps := make(map[core.Opcode]struct{})
 	seenNames := make(map[string]struct{})
@@ -20,6 +23,9 @@ func main() {
 			log.Fatalf("duplicate opcode name %s", info.Name)
 		}
 		seenNames[info.Name] = struct{}{}
+		if *dump {
+			fmt.Printf("%-32s = %08b = 0x%06X\n", info.Name, info.Op.Bytes(), info.Op)
+		}
 	}
 	fmt.Printf("checked %d opcodes, no collisions detected\n", len(ops))
 }
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// SourceConfig describes one upstream market.
+type SourceConfig struct {
+	Name   string `mapstructure:"name"`
+	Kind   string `mapstructure:"kind"`   // coinbase | binance | http | ws
+	Symbol string `mapstructure:"symbol"` // exchange specific pair symbol
+	URL    string `mapstructure:"url"`    // custom http/ws endpoint
+	Path   string `mapstructure:"path"`   // dot separated JSON path to the price
+}
+
+// FeedConfig binds a registered oracle to its upstream sources.
+type FeedConfig struct {
+	OracleID    string         `mapstructure:"oracle_id"`
+	Pair        string         `mapstructure:"pair"`
+	MinSources  int            `mapstructure:"min_sources"`
+	DeviationBp uint64         `mapstructure:"deviation_bp"`
+	Sources     []SourceConfig `mapstructure:"sources"`
+}
+
+// Config is the daemon configuration loaded from YAML and ORACLED_* env vars.
+type Config struct {
+	KeyFile     string        `mapstructure:"key_file"`
+	LedgerPath  string        `mapstructure:"ledger_path"`
+	MetricsAddr string        `mapstructure:"metrics_addr"`
+	Interval    time.Duration `mapstructure:"interval"`
+	Heartbeat   time.Duration `mapstructure:"heartbeat"`
+	Timeout     time.Duration `mapstructure:"timeout"`
+	Feeds       []FeedConfig  `mapstructure:"feeds"`
+}
+
+func loadConfig(path string) (*Config, error) {
+	v := viper.New()
+	v.SetEnvPrefix("ORACLED")
+	v.AutomaticEnv()
+	v.SetDefault("ledger_path", "./ledger.db")
+	v.SetDefault("metrics_addr", ":9102")
+	v.SetDefault("interval", "10s")
+	v.SetDefault("heartbeat", "5m")
+	v.SetDefault("timeout", "5s")
+	if path != "" {
+		v.SetConfigFile(path)
+		if err := v.ReadInConfig(); err != nil {
+			return nil, fmt.Errorf("read config: %w", err)
+		}
+	}
+	var cfg Config
+	if err := v.Unmarshal(&cfg); err != nil {
+		return nil, err
+	}
+	if cfg.KeyFile == "" {
+		return nil, fmt.Errorf("key_file is required")
+	}
+	if len(cfg.Feeds) == 0 {
+		return nil, fmt.Errorf("no feeds configured")
+	}
+	for i := range cfg.Feeds {
+		f := &cfg.Feeds[i]
+		if f.OracleID == "" || len(f.Sources) == 0 {
+			return nil, fmt.Errorf("feed %d: oracle_id and sources are required", i)
+		}
+		if f.MinSources <= 0 {
+			f.MinSources = 1
+		}
+	}
+	return &cfg, nil
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	core "synnergy-network/core"
+)
+
+// feed aggregates one oracle's sources and decides when to publish.
+type feed struct {
+	cfg     FeedConfig
+	sources []Source
+
+	lastPrice int64
+	lastPush  time.Time
+}
+
+// aggregate queries every source concurrently and returns the median of the
+// successful quotes. Failing sources are skipped so that the remaining ones
+// fail over transparently, as long as MinSources still answer.
+func (f *feed) aggregate(ctx context.Context) (float64, int, error) {
+	type quote struct {
+		src   string
+		price float64
+		err   error
+	}
+	out := make([]quote, len(f.sources))
+	var wg sync.WaitGroup
+	for i, s := range f.sources {
+		wg.Add(1)
+		go func(i int, s Source) {
+			defer wg.Done()
+			p, err := s.Fetch(ctx)
+			if err == nil && (p <= 0 || math.IsNaN(p) || math.IsInf(p, 0)) {
+				err = fmt.Errorf("invalid price %v", p)
+			}
+			out[i] = quote{s.Name(), p, err}
+		}(i, s)
+	}
+	wg.Wait()
+
+	var prices []float64
+	for _, q := range out {
+		ok := q.err == nil
+		observeSource(f.cfg.OracleID, q.src, ok)
+		if !ok {
+			log.WithFields(log.Fields{"oracle": f.cfg.OracleID, "source": q.src}).Warnf("fetch failed: %v", q.err)
+			continue
+		}
+		prices = append(prices, q.price)
+	}
+	if len(prices) < f.cfg.MinSources {
+		return 0, len(prices), fmt.Errorf("only %d of %d required sources answered", len(prices), f.cfg.MinSources)
+	}
+	return median(prices), len(prices), nil
+}
+
+func median(v []float64) float64 {
+	sort.Float64s(v)
+	n := len(v)
+	if n%2 == 1 {
+		return v[n/2]
+	}
+	return (v[n/2-1] + v[n/2]) / 2
+}
+
+// shouldPush reports whether price moved past the deviation threshold or the
+// heartbeat interval elapsed since the last submission.
+func (f *feed) shouldPush(price int64, heartbeat time.Duration) bool {
+	if f.lastPush.IsZero() || time.Since(f.lastPush) >= heartbeat {
+		return true
+	}
+	if f.cfg.DeviationBp == 0 || f.lastPrice == 0 {
+		return false
+	}
+	diff := price - f.lastPrice
+	if diff < 0 {
+		diff = -diff
+	}
+	return uint64(diff)*10_000/uint64(f.lastPrice) >= f.cfg.DeviationBp
+}
+
+// submitter signs updates and hands PushFeedSigned transactions to the pool.
+type submitter struct {
+	mu    sync.Mutex
+	priv  ed25519.PrivateKey
+	from  core.Address
+	nonce uint64
+}
+
+func (s *submitter) submit(oracleID string, p core.OraclePrice) error {
+	value, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if led := core.CurrentLedger(); led != nil {
+		if n := led.NonceOf(s.from); n > s.nonce {
+			s.nonce = n
+		}
+	}
+	tx, err := core.NewOracleFeedTx(oracleID, value, s.priv, s.nonce)
+	if err != nil {
+		return err
+	}
+	if err := core.BroadcastSignedTx(tx); err != nil {
+		return err
+	}
+	s.nonce++
+	return nil
+}
+
+// tick runs one aggregation round for a feed.
+func (f *feed) tick(ctx context.Context, sub *submitter, heartbeat time.Duration) {
+	price, n, err := f.aggregate(ctx)
+	if err != nil {
+		observeRound(f.cfg.OracleID, false)
+		log.WithField("oracle", f.cfg.OracleID).Errorf("aggregate: %v", err)
+		return
+	}
+	scaled := int64(math.Round(price * core.OraclePriceScale))
+	observePrice(f.cfg.OracleID, price)
+	if !f.shouldPush(scaled, heartbeat) {
+		observeRound(f.cfg.OracleID, true)
+		return
+	}
+	update := core.OraclePrice{Pair: f.cfg.Pair, Price: scaled, Sources: n, Timestamp: time.Now().Unix()}
+	if err := sub.submit(f.cfg.OracleID, update); err != nil {
+		observeRound(f.cfg.OracleID, false)
+		log.WithField("oracle", f.cfg.OracleID).Errorf("submit: %v", err)
+		return
+	}
+	f.lastPrice, f.lastPush = scaled, time.Now()
+	observeRound(f.cfg.OracleID, true)
+	observeSubmit(f.cfg.OracleID)
+	log.WithFields(log.Fields{"oracle": f.cfg.OracleID, "pair": f.cfg.Pair, "price": price, "sources": n}).Info("feed submitted")
+}
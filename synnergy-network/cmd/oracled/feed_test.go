@@ -0,0 +1,48 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestExtractPrice(t *testing.T) {
+	var doc interface{}
+	_ = json.Unmarshal([]byte(`{"data":{"amount":"64000.12"},"ticks":[{"p":1.5}]}`), &doc)
+	if p, err := extractPrice(doc, "data.amount"); err != nil || p != 64000.12 {
+		t.Fatalf("string price: got %v, %v", p, err)
+	}
+	if p, err := extractPrice(doc, "ticks.0.p"); err != nil || p != 1.5 {
+		t.Fatalf("array price: got %v, %v", p, err)
+	}
+	if _, err := extractPrice(doc, "data.missing"); err == nil {
+		t.Fatal("expected error for missing path")
+	}
+}
+
+func TestMedian(t *testing.T) {
+	if m := median([]float64{3, 1, 2}); m != 2 {
+		t.Fatalf("odd median = %v", m)
+	}
+	if m := median([]float64{4, 1, 3, 2}); m != 2.5 {
+		t.Fatalf("even median = %v", m)
+	}
+}
+
+func TestShouldPush(t *testing.T) {
+	f := &feed{cfg: FeedConfig{DeviationBp: 100}}
+	if !f.shouldPush(1000, time.Hour) {
+		t.Fatal("first update must be pushed")
+	}
+	f.lastPrice, f.lastPush = 1000, time.Now()
+	if f.shouldPush(1005, time.Hour) {
+		t.Fatal("0.5% move should not trigger at 1% threshold")
+	}
+	if !f.shouldPush(1010, time.Hour) {
+		t.Fatal("1% move should trigger")
+	}
+	f.lastPush = time.Now().Add(-2 * time.Hour)
+	if !f.shouldPush(1000, time.Hour) {
+		t.Fatal("heartbeat should trigger")
+	}
+}
@@ -0,0 +1,101 @@
+// Command oracled pulls prices from external markets, aggregates them and
+// submits signed PushFeedSigned transactions for registered oracles.
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	core "synnergy-network/core"
+)
+
+// loadKey reads a hex encoded ed25519 seed or private key.
+func loadKey(path string) (ed25519.PrivateKey, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	b, err := hex.DecodeString(strings.TrimSpace(string(raw)))
+	if err != nil {
+		return nil, fmt.Errorf("decode key: %w", err)
+	}
+	switch len(b) {
+	case ed25519.SeedSize:
+		return ed25519.NewKeyFromSeed(b), nil
+	case ed25519.PrivateKeySize:
+		return ed25519.PrivateKey(b), nil
+	}
+	return nil, fmt.Errorf("key must be %d or %d bytes", ed25519.SeedSize, ed25519.PrivateKeySize)
+}
+
+func main() {
+	cfgPath := flag.String("config", os.Getenv("ORACLED_CONFIG"), "path to oracled YAML config")
+	flag.Parse()
+
+	cfg, err := loadConfig(*cfgPath)
+	if err != nil {
+		log.Fatalf("config: %v", err)
+	}
+	priv, err := loadKey(cfg.KeyFile)
+	if err != nil {
+		log.Fatalf("oracle key: %v", err)
+	}
+	if err := core.InitLedger(cfg.LedgerPath); err != nil {
+		log.Fatalf("ledger init: %v", err)
+	}
+
+	client := &http.Client{Timeout: cfg.Timeout}
+	feeds := make([]*feed, 0, len(cfg.Feeds))
+	for _, fc := range cfg.Feeds {
+		f := &feed{cfg: fc}
+		for _, sc := range fc.Sources {
+			src, err := newSource(sc, client)
+			if err != nil {
+				log.Fatalf("oracle %s: %v", fc.OracleID, err)
+			}
+			f.sources = append(f.sources, src)
+		}
+		feeds = append(feeds, f)
+	}
+
+	sub := &submitter{priv: priv, from: core.Ed25519Address(priv.Public().(ed25519.PublicKey))}
+
+	srv := serveMetrics(cfg.MetricsAddr, cfg.Feeds, cfg.Interval)
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Errorf("metrics server: %v", err)
+		}
+	}()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	log.Infof("oracled started: %d feeds, interval %s, submitter %s", len(feeds), cfg.Interval, hex.EncodeToString(sub.from[:]))
+	ticker := time.NewTicker(cfg.Interval)
+	defer ticker.Stop()
+	for {
+		for _, f := range feeds {
+			rctx, cancel := context.WithTimeout(ctx, cfg.Timeout)
+			f.tick(rctx, sub, cfg.Heartbeat)
+			cancel()
+		}
+		select {
+		case <-ctx.Done():
+			_ = srv.Close()
+			log.Info("oracled stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	registry = prometheus.NewRegistry()
+
+	priceGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "oracled_price", Help: "Last aggregated price per oracle.",
+	}, []string{"oracle"})
+	sourceResults = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "oracled_source_fetch_total", Help: "Source fetches by outcome.",
+	}, []string{"oracle", "source", "result"})
+	submissions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "oracled_submissions_total", Help: "PushFeedSigned transactions submitted.",
+	}, []string{"oracle"})
+
+	healthMu  sync.RWMutex
+	lastRound = make(map[string]time.Time) // last successful round per oracle
+)
+
+func init() {
+	registry.MustRegister(priceGauge, sourceResults, submissions)
+}
+
+func observePrice(oracle string, p float64) { priceGauge.WithLabelValues(oracle).Set(p) }
+func observeSubmit(oracle string)           { submissions.WithLabelValues(oracle).Inc() }
+
+func observeSource(oracle, src string, ok bool) {
+	res := "ok"
+	if !ok {
+		res = "error"
+	}
+	sourceResults.WithLabelValues(oracle, src, res).Inc()
+}
+
+func observeRound(oracle string, ok bool) {
+	if !ok {
+		return
+	}
+	healthMu.Lock()
+	lastRound[oracle] = time.Now()
+	healthMu.Unlock()
+}
+
+// healthHandler reports unhealthy when any feed missed three intervals.
+func healthHandler(feeds []FeedConfig, interval time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		healthMu.RLock()
+		defer healthMu.RUnlock()
+		status := make(map[string]string, len(feeds))
+		healthy := true
+		for _, f := range feeds {
+			at, ok := lastRound[f.OracleID]
+			if !ok || time.Since(at) > 3*interval {
+				status[f.OracleID] = "stale"
+				healthy = false
+				continue
+			}
+			status[f.OracleID] = "ok"
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if !healthy {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		_ = json.NewEncoder(w).Encode(status)
+	}
+}
+
+func serveMetrics(addr string, feeds []FeedConfig, interval time.Duration) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+	mux.HandleFunc("/healthz", healthHandler(feeds, interval))
+	return &http.Server{Addr: addr, Handler: mux, ReadTimeout: 5 * time.Second}
+}
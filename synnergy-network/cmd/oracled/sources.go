@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Source fetches the latest price for a single market.
+type Source interface {
+	Name() string
+	Fetch(ctx context.Context) (float64, error)
+}
+
+func newSource(sc SourceConfig, client *http.Client) (Source, error) {
+	name := sc.Name
+	if name == "" {
+		name = sc.Kind
+	}
+	switch sc.Kind {
+	case "coinbase":
+		return &httpSource{name: name, client: client,
+			url:  fmt.Sprintf("https://api.coinbase.com/v2/prices/%s/spot", sc.Symbol),
+			path: "data.amount"}, nil
+	case "binance":
+		return &httpSource{name: name, client: client,
+			url:  fmt.Sprintf("https://api.binance.com/api/v3/ticker/price?symbol=%s", sc.Symbol),
+			path: "price"}, nil
+	case "http":
+		if sc.URL == "" || sc.Path == "" {
+			return nil, fmt.Errorf("source %s: url and path required", name)
+		}
+		return &httpSource{name: name, client: client, url: sc.URL, path: sc.Path}, nil
+	case "ws":
+		if sc.URL == "" || sc.Path == "" {
+			return nil, fmt.Errorf("source %s: url and path required", name)
+		}
+		return newWSSource(name, sc.URL, sc.Path), nil
+	}
+	return nil, fmt.Errorf("source %s: unknown kind %q", name, sc.Kind)
+}
+
+// httpSource polls a JSON endpoint.
+type httpSource struct {
+	name   string
+	url    string
+	path   string
+	client *http.Client
+}
+
+func (s *httpSource) Name() string { return s.name }
+
+func (s *httpSource) Fetch(ctx context.Context) (float64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("%s: status %d", s.name, resp.StatusCode)
+	}
+	var doc interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return 0, err
+	}
+	return extractPrice(doc, s.path)
+}
+
+// wsSource keeps a streaming connection open and serves the last tick.
+type wsSource struct {
+	name string
+	url  string
+	path string
+
+	mu     sync.RWMutex
+	last   float64
+	lastAt time.Time
+	err    error
+	once   sync.Once
+}
+
+// wsMaxAge is how old a streamed tick may be before the source is stale.
+const wsMaxAge = 30 * time.Second
+
+func newWSSource(name, url, path string) *wsSource {
+	return &wsSource{name: name, url: url, path: path}
+}
+
+func (s *wsSource) Name() string { return s.name }
+
+func (s *wsSource) Fetch(ctx context.Context) (float64, error) {
+	s.once.Do(func() { go s.run() })
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.lastAt.IsZero() || time.Since(s.lastAt) > wsMaxAge {
+		if s.err != nil {
+			return 0, s.err
+		}
+		return 0, fmt.Errorf("%s: no fresh tick", s.name)
+	}
+	return s.last, nil
+}
+
+// run reconnects with capped backoff for the lifetime of the daemon.
+func (s *wsSource) run() {
+	backoff := time.Second
+	for {
+		err := s.stream()
+		s.mu.Lock()
+		s.err = err
+		s.mu.Unlock()
+		time.Sleep(backoff)
+		if backoff < time.Minute {
+			backoff *= 2
+		}
+	}
+}
+
+func (s *wsSource) stream() error {
+	conn, _, err := websocket.DefaultDialer.Dial(s.url, nil)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	for {
+		var doc interface{}
+		if err := conn.ReadJSON(&doc); err != nil {
+			return err
+		}
+		p, err := extractPrice(doc, s.path)
+		if err != nil {
+			continue // heartbeats and subscription acks carry no price
+		}
+		s.mu.Lock()
+		s.last, s.lastAt, s.err = p, time.Now(), nil
+		s.mu.Unlock()
+	}
+}
+
+// extractPrice walks a dot separated path and parses the leaf as a float.
+// Exchanges commonly encode prices as strings, so both forms are accepted.
+func extractPrice(doc interface{}, path string) (float64, error) {
+	cur := doc
+	for _, part := range strings.Split(path, ".") {
+		switch node := cur.(type) {
+		case map[string]interface{}:
+			cur = node[part]
+		case []interface{}:
+			i, err := strconv.Atoi(part)
+			if err != nil || i < 0 || i >= len(node) {
+				return 0, fmt.Errorf("path %s: bad index %q", path, part)
+			}
+			cur = node[i]
+		default:
+			return 0, fmt.Errorf("path %s: not found", path)
+		}
+	}
+	switch v := cur.(type) {
+	case float64:
+		return v, nil
+	case string:
+		return strconv.ParseFloat(v, 64)
+	}
+	return 0, fmt.Errorf("path %s: not a price", path)
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+
+	"synnergy-network/cmd/regulatorserver/server"
+	core "synnergy-network/core"
+)
+
+// The regulator API only accepts clients presenting a certificate signed by
+// REGULATOR_CLIENT_CA. The certificate common name carries the regulator's
+// hex address, which must hold the regulator role on chain.
+func main() {
+	addr := os.Getenv("REGULATOR_API_ADDR")
+	if addr == "" {
+		addr = ":8443"
+	}
+	ledgerPath := os.Getenv("LEDGER_PATH")
+	if ledgerPath == "" {
+		ledgerPath = "./ledger.db"
+	}
+	certFile, keyFile, caFile := os.Getenv("REGULATOR_TLS_CERT"), os.Getenv("REGULATOR_TLS_KEY"), os.Getenv("REGULATOR_CLIENT_CA")
+	if certFile == "" || keyFile == "" || caFile == "" {
+		log.Fatal("REGULATOR_TLS_CERT, REGULATOR_TLS_KEY and REGULATOR_CLIENT_CA must be set")
+	}
+
+	if err := core.InitLedger(ledgerPath); err != nil {
+		log.Fatalf("ledger init: %v", err)
+	}
+	if err := core.InitAuditManager(core.CurrentLedger(), os.Getenv("AUDIT_TRAIL_PATH")); err != nil {
+		log.Fatalf("audit init: %v", err)
+	}
+	if _, err := core.InitSanctionsRegistry(core.CurrentLedger()); err != nil {
+		log.Fatalf("sanctions registry: %v", err)
+	}
+
+	caPEM, err := os.ReadFile(caFile)
+	if err != nil {
+		log.Fatalf("read client CA: %v", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		log.Fatal("client CA contains no certificates")
+	}
+
+	srv := &http.Server{
+		Addr:    addr,
+		Handler: server.NewRouter(),
+		TLSConfig: &tls.Config{
+			ClientAuth: tls.RequireAndVerifyClientCert,
+			ClientCAs:  pool,
+			MinVersion: tls.VersionTLS12,
+		},
+	}
+	log.Infof("regulator API listening on %s (mTLS)", addr)
+	if err := srv.ListenAndServeTLS(certFile, keyFile); err != nil {
+		log.Fatalf("server error: %v", err)
+	}
+}
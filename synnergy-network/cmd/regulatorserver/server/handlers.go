@@ -0,0 +1,95 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	core "synnergy-network/core"
+)
+
+// ListSanctioned returns every blacklisted or frozen address.
+func ListSanctioned(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, core.Sanctions().Listed())
+}
+
+// GetStatus returns the enforcement status of an address.
+func GetStatus(w http.ResponseWriter, r *http.Request) {
+	addr, err := core.ParseAddress(mux.Vars(r)["addr"])
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, core.Sanctions().Status(addr))
+}
+
+// ListActions returns the enforcement history of an address.
+func ListActions(w http.ResponseWriter, r *http.Request) {
+	addr, err := core.ParseAddress(mux.Vars(r)["addr"])
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	list, err := core.Sanctions().Actions(addr)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, list)
+}
+
+// Enforce applies blacklist, unblacklist, freeze or unfreeze to an address.
+func Enforce(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	target, err := core.ParseAddress(vars["addr"])
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	var req struct {
+		Reason    string `json:"reason"`
+		Reference string `json:"reference,omitempty"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	reg, regulator := core.Sanctions(), regulatorFrom(r)
+	var act *core.EnforcementAction
+	switch core.EnforcementKind(vars["action"]) {
+	case core.EnforceBlacklist:
+		act, err = reg.Blacklist(regulator, target, req.Reason, req.Reference)
+	case core.EnforceUnblacklist:
+		act, err = reg.Unblacklist(regulator, target, req.Reason, req.Reference)
+	case core.EnforceFreeze:
+		act, err = reg.Freeze(regulator, target, req.Reason, req.Reference)
+	case core.EnforceUnfreeze:
+		act, err = reg.Unfreeze(regulator, target, req.Reason, req.Reference)
+	default:
+		writeError(w, http.StatusNotFound, errors.New("unknown action"))
+		return
+	}
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, core.ErrUnauthorized) {
+			status = http.StatusForbidden
+		}
+		writeError(w, status, err)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	writeJSON(w, act)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+	}
+}
+
+// writeError answers with the shared error envelope (see core.ErrorEnvelope).
+func writeError(w http.ResponseWriter, status int, err error) {
+	core.WriteHTTPError(w, status, "regulator", err)
+}
@@ -0,0 +1,54 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	core "synnergy-network/core"
+)
+
+type ctxKey int
+
+const regulatorKey ctxKey = 0
+
+// RequestLogger tags each request with an ID and writes one structured
+// access line for it on the regulator module logger (see core.RequestLogger).
+var RequestLogger = core.RequestLogger("regulator")
+
+// JSONHeaders sets Content-Type application/json for all responses.
+func JSONHeaders(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RequireRegulator maps the verified client certificate to a regulator
+// address. The certificate common name must be the hex address and the
+// address must hold the regulator role.
+func RequireRegulator(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+			writeError(w, http.StatusUnauthorized, errors.New("client certificate required"))
+			return
+		}
+		cn := r.TLS.VerifiedChains[0][0].Subject.CommonName
+		addr, err := core.ParseAddress(cn)
+		if err != nil {
+			writeError(w, http.StatusForbidden, errors.New("certificate subject is not an address"))
+			return
+		}
+		reg := core.Sanctions()
+		if reg == nil || !reg.IsRegulator(addr) {
+			writeError(w, http.StatusForbidden, errors.New("not a regulator"))
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), regulatorKey, addr)))
+	})
+}
+
+func regulatorFrom(r *http.Request) core.Address {
+	addr, _ := r.Context().Value(regulatorKey).(core.Address)
+	return addr
+}
@@ -0,0 +1,24 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// NewRouter configures the regulator enforcement routes. All routes require
+// an authenticated regulator certificate.
+func NewRouter() *mux.Router {
+	r := mux.NewRouter()
+
+	r.Use(RequestLogger)
+	r.Use(JSONHeaders)
+	r.Use(RequireRegulator)
+
+	r.HandleFunc("/api/sanctions", ListSanctioned).Methods(http.MethodGet)
+	r.HandleFunc("/api/sanctions/{addr}", GetStatus).Methods(http.MethodGet)
+	r.HandleFunc("/api/sanctions/{addr}/actions", ListActions).Methods(http.MethodGet)
+	r.HandleFunc("/api/sanctions/{addr}/{action}", Enforce).Methods(http.MethodPost)
+
+	return r
+}
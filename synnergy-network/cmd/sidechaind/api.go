@@ -0,0 +1,230 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	core "synnergy-network/core"
+)
+
+const relayHeader = "X-Sidechaind-Relay"
+
+// proposal is sent by the proposer to every other validator.
+type proposal struct {
+	Header   core.SidechainHeader `json:"header"`
+	Txs      []*core.Transaction  `json:"txs"`
+	Proposer int                  `json:"proposer"`
+	Sig      []byte               `json:"sig"`
+}
+
+// endorsement is a validator's answer to a proposal.
+type endorsement struct {
+	Sig        []byte `json:"sig"`
+	Checkpoint []byte `json:"checkpoint_sig,omitempty"`
+}
+
+func (n *node) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/propose", n.handlePropose)
+	mux.HandleFunc("POST /v1/commit", n.handleCommit)
+	mux.HandleFunc("POST /v1/tx", n.handleTx)
+	mux.HandleFunc("GET /v1/headers/{height}", n.handleHeader)
+	mux.HandleFunc("GET /v1/withdrawals/{height}/{index}", n.handleWithdrawProof)
+	mux.HandleFunc("GET /healthz", n.handleHealth)
+	return mux
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	core.WriteHTTPError(w, status, "sidechain", err)
+}
+
+func (n *node) handlePropose(w http.ResponseWriter, r *http.Request) {
+	var p proposal
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	e, err := n.endorse(p)
+	switch {
+	case errors.Is(err, errOutOfSync), errors.Is(err, errConflict):
+		writeError(w, http.StatusConflict, err)
+	case err != nil:
+		writeError(w, http.StatusUnprocessableEntity, err)
+	default:
+		writeJSON(w, http.StatusOK, e)
+	}
+}
+
+func (n *node) handleCommit(w http.ResponseWriter, r *http.Request) {
+	var h core.SidechainHeader
+	if err := json.NewDecoder(r.Body).Decode(&h); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := n.commit(h); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleTx adds a transaction to the pool and relays it to the other
+// validators so whoever proposes next can include it.
+func (n *node) handleTx(w http.ResponseWriter, r *http.Request) {
+	var tx core.Transaction
+	if err := json.NewDecoder(r.Body).Decode(&tx); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if tx.Hash == (core.Hash{}) {
+		tx.HashTx()
+	}
+	n.led.AddToPool(&tx)
+	if r.Header.Get(relayHeader) == "" {
+		for i, v := range n.vals {
+			if i == n.self {
+				continue
+			}
+			go func(v validator) {
+				ctx, cancel := context.WithTimeout(context.Background(), n.cfg.Timeout)
+				defer cancel()
+				if err := n.post(ctx, v, "/v1/tx", &tx, nil); err != nil {
+					log.WithField("peer", v.url).Warnf("tx relay: %v", err)
+				}
+			}(v)
+		}
+	}
+	writeJSON(w, http.StatusAccepted, map[string]string{"hash": tx.IDHex()})
+}
+
+func (n *node) handleHeader(w http.ResponseWriter, r *http.Request) {
+	h, err := strconv.ParseUint(r.PathValue("height"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	rec, err := n.store.get(h)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"header": rec.Header, "final": rec.Final, "txs": len(rec.Txs)})
+}
+
+// handleWithdrawProof returns the proof for `synnergy ~sc withdraw`; the
+// hex field is the argument that command expects.
+func (n *node) handleWithdrawProof(w http.ResponseWriter, r *http.Request) {
+	h, err := strconv.ParseUint(r.PathValue("height"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	idx, err := strconv.ParseUint(r.PathValue("index"), 10, 32)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	p, err := n.withdrawProof(h, uint32(idx))
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	raw, _ := json.Marshal(p)
+	writeJSON(w, http.StatusOK, map[string]any{"proof": p, "hex": hex.EncodeToString(raw)})
+}
+
+// handleHealth reports 503 once no block has been produced for five block
+// times.
+func (n *node) handleHealth(w http.ResponseWriter, r *http.Request) {
+	height := n.led.LastHeight()
+	status := http.StatusOK
+	var last int64
+	if rec, err := n.store.get(height); err == nil {
+		last = rec.Header.Timestamp
+	}
+	if time.Since(time.Unix(last, 0)) > 5*n.cfg.BlockTime {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, map[string]any{
+		"chain_id":      n.cfg.ChainID,
+		"height":        height,
+		"submitted":     n.submitted.Load(),
+		"validator":     n.self,
+		"next_proposer": n.proposer(height + 1),
+	})
+}
+
+// post sends a JSON request to a peer validator and decodes its reply.
+func (n *node) post(ctx context.Context, v validator, path string, body, out any) error {
+	raw, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.url+path, bytes.NewReader(raw))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(relayHeader, "1")
+	resp, err := n.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<16))
+		return fmt.Errorf("%s: %w", v.url, core.DecodeErrorBody(resp.StatusCode, body))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// requestEndorsement asks a validator to sign a proposed block and checks
+// the returned signatures before they are aggregated.
+func (n *node) requestEndorsement(ctx context.Context, v validator, rec *blockRecord) (endorsement, error) {
+	p := proposal{Header: rec.Header, Txs: rec.Txs, Proposer: n.self, Sig: rec.Sigs[n.self]}
+	var e endorsement
+	if err := n.post(ctx, v, "/v1/propose", p, &e); err != nil {
+		return e, err
+	}
+	hash := rec.Header.SigningHash()
+	if !verifySig(v.pub, e.Sig, hash[:]) {
+		return e, errors.New("invalid header signature")
+	}
+	if n.checkpointDue(rec.Header.Height) {
+		d := core.CheckpointDigest(rec.Header.ChainID, rec.Header.Height, hash, rec.Header.StateRoot)
+		if !verifySig(v.pub, e.Checkpoint, d[:]) {
+			return e, errors.New("invalid checkpoint signature")
+		}
+	}
+	return e, nil
+}
+
+func (n *node) broadcastCommit(ctx context.Context, h core.SidechainHeader) {
+	for i, v := range n.vals {
+		if i == n.self {
+			continue
+		}
+		if err := n.post(ctx, v, "/v1/commit", h, nil); err != nil {
+			log.WithFields(log.Fields{"height": h.Height, "validator": i}).Warnf("commit: %v", err)
+		}
+	}
+}
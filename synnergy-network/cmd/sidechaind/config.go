@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/spf13/viper"
+
+	core "synnergy-network/core"
+)
+
+// ValidatorConfig describes one member of the side-chain validator set. The
+// order of the list must match the validator order registered on the main
+// chain because header signatures are aggregated in that order.
+type ValidatorConfig struct {
+	Address   string `mapstructure:"address"`    // authority address hex
+	BLSPubKey string `mapstructure:"bls_pubkey"` // serialized BLS public key hex
+	URL       string `mapstructure:"url"`        // peer API base URL
+}
+
+// Config is the node configuration loaded from YAML and SIDECHAIND_* env vars.
+type Config struct {
+	ChainID         uint32            `mapstructure:"chain_id"`
+	Consensus       string            `mapstructure:"consensus"` // poa | solo
+	VM              string            `mapstructure:"vm"`        // light | superlight
+	LedgerPath      string            `mapstructure:"ledger_path"`
+	KeyFile         string            `mapstructure:"key_file"`
+	Address         string            `mapstructure:"address"`
+	ListenAddr      string            `mapstructure:"listen_addr"`
+	CoordinatorAddr string            `mapstructure:"coordinator_addr"`
+	BlockTime       time.Duration     `mapstructure:"block_time"`
+	MaxBlockTxs     int               `mapstructure:"max_block_txs"`
+	BlockGasLimit   uint64            `mapstructure:"block_gas_limit"`
+	CheckpointEvery uint64            `mapstructure:"checkpoint_every"`
+	Timeout         time.Duration     `mapstructure:"timeout"`
+	Validators      []ValidatorConfig `mapstructure:"validators"`
+}
+
+func loadConfig(path string) (*Config, error) {
+	v := viper.New()
+	v.SetEnvPrefix("SIDECHAIND")
+	v.AutomaticEnv()
+	v.SetDefault("consensus", "poa")
+	v.SetDefault("vm", "light")
+	v.SetDefault("ledger_path", "./sidechain")
+	v.SetDefault("listen_addr", ":7991")
+	v.SetDefault("coordinator_addr", "127.0.0.1:7990")
+	v.SetDefault("block_time", "2s")
+	v.SetDefault("max_block_txs", 500)
+	v.SetDefault("block_gas_limit", 30_000_000)
+	v.SetDefault("checkpoint_every", core.DefaultCheckpointInterval)
+	v.SetDefault("timeout", "5s")
+	if path != "" {
+		v.SetConfigFile(path)
+		if err := v.ReadInConfig(); err != nil {
+			return nil, fmt.Errorf("read config: %w", err)
+		}
+	}
+	var cfg Config
+	if err := v.Unmarshal(&cfg); err != nil {
+		return nil, err
+	}
+	if cfg.ChainID == 0 {
+		return nil, fmt.Errorf("chain_id is required")
+	}
+	if cfg.KeyFile == "" || cfg.Address == "" {
+		return nil, fmt.Errorf("key_file and address are required")
+	}
+	switch cfg.Consensus {
+	case "poa", "solo":
+	default:
+		return nil, fmt.Errorf("unknown consensus %q", cfg.Consensus)
+	}
+	switch cfg.VM {
+	case "light", "superlight":
+	default:
+		return nil, fmt.Errorf("unknown vm %q", cfg.VM)
+	}
+	if len(cfg.Validators) == 0 {
+		return nil, fmt.Errorf("no validators configured")
+	}
+	if cfg.Consensus == "solo" && len(cfg.Validators) != 1 {
+		return nil, fmt.Errorf("solo consensus takes exactly one validator")
+	}
+	for i, val := range cfg.Validators {
+		if _, err := core.ParseAddress(trim0x(val.Address)); err != nil {
+			return nil, fmt.Errorf("validator %d: address: %w", i, err)
+		}
+		if _, err := hex.DecodeString(val.BLSPubKey); err != nil || val.BLSPubKey == "" {
+			return nil, fmt.Errorf("validator %d: bls_pubkey must be hex", i)
+		}
+	}
+	if cfg.CheckpointEvery == 0 {
+		cfg.CheckpointEvery = core.DefaultCheckpointInterval
+	}
+	return &cfg, nil
+}
+
+func trim0x(s string) string {
+	if len(s) > 1 && s[0] == '0' && (s[1] == 'x' || s[1] == 'X') {
+		return s[2:]
+	}
+	return s
+}
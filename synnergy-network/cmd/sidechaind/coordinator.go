@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	core "synnergy-network/core"
+)
+
+// coordinator speaks the newline framed JSON-RPC of the main-chain
+// side-chain coordinator daemon, the same protocol `synnergy ~sc` uses.
+type coordinator struct {
+	addr    string
+	timeout time.Duration
+}
+
+// call sends one action and waits for the optional reply. A daemon that
+// closes the connection without replying has accepted the request.
+func (c *coordinator) call(ctx context.Context, action string, body any) error {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	d := net.Dialer{}
+	conn, err := d.DialContext(ctx, "tcp", c.addr)
+	if err != nil {
+		return fmt.Errorf("cannot connect to sidechain coordinator at %s: %w", c.addr, err)
+	}
+	defer conn.Close()
+	if dl, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(dl)
+	}
+
+	raw, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	payload := map[string]any{}
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return err
+	}
+	payload["action"] = action
+	raw, _ = json.Marshal(payload)
+	if _, err := conn.Write(append(raw, '\n')); err != nil {
+		return err
+	}
+
+	var resp struct {
+		Error core.WireError `json:"error,omitempty"`
+	}
+	if err := json.NewDecoder(bufio.NewReader(conn)).Decode(&resp); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		return err
+	}
+	if err := resp.Error.Err(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (c *coordinator) submitHeader(ctx context.Context, h core.SidechainHeader) error {
+	return c.call(ctx, "header", h)
+}
+
+func (c *coordinator) submitCheckpoint(ctx context.Context, cp core.SidechainCheckpoint) error {
+	return c.call(ctx, "checkpoint", cp)
+}
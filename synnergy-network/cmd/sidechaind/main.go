@@ -0,0 +1,79 @@
+// Command sidechaind runs a validator node for a Synnergy side-chain. It
+// produces blocks on a core ledger under PoA, gathers the validator set's
+// BLS signatures and relays headers and checkpoints to the main-chain
+// side-chain coordinator.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	bls "github.com/herumi/bls-eth-go-binary/bls"
+	log "github.com/sirupsen/logrus"
+)
+
+// loadKey reads a hex encoded BLS secret key.
+func loadKey(path string) (bls.SecretKey, error) {
+	var sk bls.SecretKey
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return sk, err
+	}
+	if err := sk.DeserializeHexStr(strings.TrimSpace(string(raw))); err != nil {
+		return sk, fmt.Errorf("decode key: %w", err)
+	}
+	return sk, nil
+}
+
+func main() {
+	cfgPath := flag.String("config", os.Getenv("SIDECHAIND_CONFIG"), "path to sidechaind YAML config")
+	flag.Parse()
+
+	cfg, err := loadConfig(*cfgPath)
+	if err != nil {
+		log.Fatalf("config: %v", err)
+	}
+	sk, err := loadKey(cfg.KeyFile)
+	if err != nil {
+		log.Fatalf("validator key: %v", err)
+	}
+	n, err := newNode(cfg, sk)
+	if err != nil {
+		log.Fatalf("node: %v", err)
+	}
+
+	srv := &http.Server{Addr: cfg.ListenAddr, Handler: n.routes(), ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("api server: %v", err)
+		}
+	}()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	log.Infof("sidechaind started: chain %d, validator %d of %d, %s consensus, block time %s",
+		cfg.ChainID, n.self, len(n.vals), cfg.Consensus, cfg.BlockTime)
+	ticker := time.NewTicker(cfg.BlockTime)
+	defer ticker.Stop()
+	for {
+		if err := n.step(ctx); err != nil {
+			log.Warnf("block production: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			_ = srv.Close()
+			_ = n.led.Close()
+			log.Info("sidechaind stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
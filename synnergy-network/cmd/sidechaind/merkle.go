@@ -0,0 +1,89 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+
+	core "synnergy-network/core"
+)
+
+// exitAddress receives token transfers that withdraw to the main chain.
+var exitAddress = core.ModuleAddress("sidechain_exit")
+
+// withdrawal is the leaf format core.SidechainCoordinator.VerifyWithdraw
+// decodes from WithdrawProof.TxData.
+type withdrawal struct {
+	Recipient core.Address `json:"recipient"`
+	Token     core.TokenID `json:"token"`
+	Amount    uint64       `json:"amount"`
+}
+
+// txLeaves returns the leaves committed to by a header's TxRoot: one
+// withdrawal leaf per token transfer to exitAddress, and the transaction
+// hash for transactions that do not withdraw.
+func txLeaves(txs []*core.Transaction) [][]byte {
+	var leaves [][]byte
+	for _, tx := range txs {
+		exits := 0
+		for _, tr := range tx.TokenTransfers {
+			if tr.To != exitAddress || tr.Amount == 0 {
+				continue
+			}
+			leaf, _ := json.Marshal(withdrawal{Recipient: tr.From, Token: tr.Token, Amount: tr.Amount})
+			leaves = append(leaves, leaf)
+			exits++
+		}
+		if exits == 0 {
+			h := tx.Hash
+			leaves = append(leaves, h[:])
+		}
+	}
+	return leaves
+}
+
+// merkleLevels builds the tree core.VerifyMerkleProof checks against: raw
+// leaves at the bottom, SHA-256(left || right) above, odd nodes paired with
+// themselves.
+func merkleLevels(leaves [][]byte) [][][]byte {
+	if len(leaves) == 0 {
+		return nil
+	}
+	var levels [][][]byte
+	level := leaves
+	for {
+		if len(level)%2 == 1 {
+			level = append(level[:len(level):len(level)], level[len(level)-1])
+		}
+		levels = append(levels, level)
+		next := make([][]byte, len(level)/2)
+		for i := range next {
+			next[i] = core.HashConcat(level[2*i], level[2*i+1])
+		}
+		if len(next) == 1 {
+			return append(levels, next)
+		}
+		level = next
+	}
+}
+
+func txRoot(leaves [][]byte) [32]byte {
+	var root [32]byte
+	if levels := merkleLevels(leaves); levels != nil {
+		copy(root[:], levels[len(levels)-1][0])
+	}
+	return root
+}
+
+func merkleProof(leaves [][]byte, index uint32) ([][]byte, error) {
+	if int(index) >= len(leaves) {
+		return nil, errors.New("leaf index out of range")
+	}
+	levels := merkleLevels(leaves)
+	proof := make([][]byte, 0, len(levels)-1)
+	idx := int(index)
+	for _, level := range levels[:len(levels)-1] {
+		proof = append(proof, level[idx^1])
+		idx /= 2
+	}
+	return proof, nil
+}
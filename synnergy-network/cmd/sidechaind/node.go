@@ -0,0 +1,441 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	bls "github.com/herumi/bls-eth-go-binary/bls"
+	log "github.com/sirupsen/logrus"
+
+	core "synnergy-network/core"
+)
+
+var (
+	errOutOfSync = errors.New("node is not at the proposal's parent height")
+	errConflict  = errors.New("conflicting proposal for an endorsed height")
+)
+
+// validator is a parsed ValidatorConfig.
+type validator struct {
+	addr core.Address
+	pub  []byte
+	url  string
+}
+
+// node produces and endorses side-chain blocks on top of a core ledger.
+//
+// Block heights start at 1; height 0 is an empty genesis block shared by all
+// validators. Under PoA the proposer for a height rotates over the
+// configured validators that are active authorities in the side-chain's own
+// AuthoritySet. The proposer executes the block, collects every validator's
+// BLS signature over the header – each validator re-executes the block and
+// only signs matching roots – and relays the aggregated header, and every
+// CheckpointEvery blocks its checkpoint, to the main-chain coordinator.
+type node struct {
+	cfg   *Config
+	led   *core.Ledger
+	vmst  core.StateRW // contract storage of the configured VM
+	auth  *core.AuthoritySet
+	store *blockStore
+	coord *coordinator
+	http  *http.Client
+	sk    bls.SecretKey
+	self  int
+	vals  []validator
+
+	mu        sync.Mutex
+	submitted atomic.Uint64
+}
+
+func newNode(cfg *Config, sk bls.SecretKey) (*node, error) {
+	n := &node{
+		cfg:   cfg,
+		coord: &coordinator{addr: cfg.CoordinatorAddr, timeout: cfg.Timeout},
+		http:  &http.Client{Timeout: cfg.Timeout},
+		sk:    sk,
+		self:  -1,
+	}
+	self, err := core.ParseAddress(trim0x(cfg.Address))
+	if err != nil {
+		return nil, err
+	}
+	pub := sk.GetPublicKey().Serialize()
+	for i, vc := range cfg.Validators {
+		addr, _ := core.ParseAddress(trim0x(vc.Address))
+		pk, _ := hex.DecodeString(vc.BLSPubKey)
+		n.vals = append(n.vals, validator{addr: addr, pub: pk, url: vc.URL})
+		if addr == self {
+			if !bytes.Equal(pk, pub) {
+				return nil, fmt.Errorf("key file does not match bls_pubkey of validator %d", i)
+			}
+			n.self = i
+		}
+	}
+	if n.self < 0 {
+		return nil, fmt.Errorf("address %s is not in the validator set", cfg.Address)
+	}
+
+	if err := os.MkdirAll(cfg.LedgerPath, 0o700); err != nil {
+		return nil, err
+	}
+	if n.led, err = core.OpenLedger(cfg.LedgerPath); err != nil {
+		return nil, fmt.Errorf("ledger: %w", err)
+	}
+	if _, err := n.led.GetBlock(0); err != nil {
+		if err := n.led.AddBlock(&core.Block{Header: core.BlockHeader{Height: 0}}); err != nil {
+			return nil, fmt.Errorf("genesis: %w", err)
+		}
+	}
+	if n.store, err = newBlockStore(filepath.Join(cfg.LedgerPath, "headers")); err != nil {
+		return nil, err
+	}
+	if n.vmst, err = core.NewInMemory(); err != nil {
+		return nil, fmt.Errorf("vm state: %w", err)
+	}
+	n.auth = core.NewAuthoritySet(log.StandardLogger(), n.led)
+	return n, nil
+}
+
+func (n *node) pubkeys() [][]byte {
+	out := make([][]byte, len(n.vals))
+	for i, v := range n.vals {
+		out[i] = v.pub
+	}
+	return out
+}
+
+// rotation lists the validators eligible to propose. With no authorities
+// registered yet the whole configured set rotates so a fresh chain can
+// bootstrap its own authority module.
+func (n *node) rotation() []int {
+	if n.cfg.Consensus == "solo" {
+		return []int{0}
+	}
+	var idx []int
+	for i, v := range n.vals {
+		if n.auth.IsAuthority(v.addr) {
+			idx = append(idx, i)
+		}
+	}
+	if len(idx) == 0 {
+		for i := range n.vals {
+			idx = append(idx, i)
+		}
+	}
+	return idx
+}
+
+func (n *node) proposer(height uint64) int {
+	r := n.rotation()
+	return r[height%uint64(len(r))]
+}
+
+func (n *node) checkpointDue(height uint64) bool { return height%n.cfg.CheckpointEvery == 0 }
+
+func (n *node) parentHash(height uint64) ([32]byte, error) {
+	if height == 1 {
+		return [32]byte{}, nil
+	}
+	rec, err := n.store.get(height - 1)
+	if err != nil {
+		return [32]byte{}, fmt.Errorf("parent %d: %w", height-1, err)
+	}
+	return [32]byte(rec.Header.SigningHash()), nil
+}
+
+// execute runs txs as block height on the ledger through the configured VM
+// and returns the transactions that were applied with their TxRoot leaves.
+func (n *node) execute(height uint64, txs []*core.Transaction) ([]*core.Transaction, [][]byte, error) {
+	var vm core.VM
+	switch n.cfg.VM {
+	case "superlight":
+		vm = core.NewSuperLightVM(n.vmst)
+	default:
+		vm = core.NewLightVM(n.vmst, core.NewGasMeter(n.cfg.BlockGasLimit))
+	}
+	em := core.NewExecutionManager(n.led, vm)
+	em.BeginBlock(height)
+	for _, tx := range txs {
+		if err := em.ExecuteTx(tx); err != nil {
+			log.WithFields(log.Fields{"height": height, "tx": tx.IDHex()}).Warnf("tx dropped: %v", err)
+		}
+	}
+	blk, err := em.FinalizeBlock()
+	if err != nil {
+		return nil, nil, err
+	}
+	return blk.Transactions, txLeaves(blk.Transactions), nil
+}
+
+// sign returns this validator's signature over the header and, when the
+// height is a checkpoint, over its CheckpointDigest.
+func (n *node) sign(h core.SidechainHeader) (sig, ckpt []byte) {
+	hash := h.SigningHash()
+	sig = n.sk.SignByte(hash[:]).Serialize()
+	if n.checkpointDue(h.Height) {
+		d := core.CheckpointDigest(h.ChainID, h.Height, hash, h.StateRoot)
+		ckpt = n.sk.SignByte(d[:]).Serialize()
+	}
+	return sig, ckpt
+}
+
+// step runs one round of the production loop: it finishes signing and
+// relaying the latest block when this node proposed it, then proposes the
+// next block when this node is scheduled to.
+func (n *node) step(ctx context.Context) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	height := n.led.LastHeight()
+	if height > 0 {
+		rec, err := n.store.get(height)
+		if err != nil {
+			return fmt.Errorf("block %d: %w", height, err)
+		}
+		if rec.Proposer == n.self && (!rec.Final || !rec.Submitted || rec.CkptPending) {
+			return n.finish(ctx, rec)
+		}
+	}
+	if n.proposer(height+1) != n.self {
+		return nil
+	}
+	return n.propose(ctx, height+1)
+}
+
+func (n *node) propose(ctx context.Context, height uint64) error {
+	parent, err := n.parentHash(height)
+	if err != nil {
+		return err
+	}
+	txs := n.led.ListPool(n.cfg.MaxBlockTxs)
+	sort.Slice(txs, func(i, j int) bool { return bytes.Compare(txs[i].Hash[:], txs[j].Hash[:]) < 0 })
+	applied, leaves, err := n.execute(height, txs)
+	if err != nil {
+		return err
+	}
+	rec := &blockRecord{
+		Header: core.SidechainHeader{
+			ChainID:   core.SidechainID(n.cfg.ChainID),
+			Height:    height,
+			Parent:    parent,
+			StateRoot: [32]byte(n.led.StateRoot()),
+			TxRoot:    txRoot(leaves),
+			Timestamp: time.Now().Unix(),
+		},
+		Txs:      applied,
+		Leaves:   leaves,
+		Proposer: n.self,
+		Sigs:     map[int][]byte{},
+		CkptSigs: map[int][]byte{},
+	}
+	sig, ckpt := n.sign(rec.Header)
+	rec.Sigs[n.self] = sig
+	if ckpt != nil {
+		rec.CkptSigs[n.self] = ckpt
+	}
+	if err := n.store.put(rec); err != nil {
+		return err
+	}
+	log.WithFields(log.Fields{"height": height, "txs": len(applied)}).Info("block proposed")
+	return n.finish(ctx, rec)
+}
+
+// finish collects missing endorsements for a block this node proposed,
+// shares the aggregate header with the other validators and relays it,
+// plus its checkpoint when one is due, to the main chain. Progress is
+// persisted after every stage so a restart resumes where it stopped.
+func (n *node) finish(ctx context.Context, rec *blockRecord) error {
+	h := rec.Header.Height
+	hash := rec.Header.SigningHash()
+	if !rec.Final {
+		for i, v := range n.vals {
+			if _, ok := rec.Sigs[i]; ok {
+				continue
+			}
+			e, err := n.requestEndorsement(ctx, v, rec)
+			if err != nil {
+				log.WithFields(log.Fields{"height": h, "validator": i}).Warnf("endorsement: %v", err)
+				continue
+			}
+			rec.Sigs[i] = e.Sig
+			if e.Checkpoint != nil {
+				rec.CkptSigs[i] = e.Checkpoint
+			}
+		}
+		if len(rec.Sigs) < len(n.vals) {
+			_ = n.store.put(rec)
+			return fmt.Errorf("block %d: %d of %d validator signatures", h, len(rec.Sigs), len(n.vals))
+		}
+		agg, err := aggregate(rec.Sigs)
+		if err != nil {
+			return err
+		}
+		if !core.VerifyAggregateSig(n.pubkeys(), agg, hash[:]) {
+			return fmt.Errorf("block %d: aggregate signature does not verify", h)
+		}
+		rec.Header.SigAgg = agg
+		rec.Final = true
+		rec.CkptPending = n.checkpointDue(h)
+		if err := n.store.put(rec); err != nil {
+			return err
+		}
+		n.broadcastCommit(ctx, rec.Header)
+	}
+	if !rec.Submitted {
+		if err := n.coord.submitHeader(ctx, rec.Header); err != nil {
+			return fmt.Errorf("submit header %d: %w", h, err)
+		}
+		rec.Submitted = true
+		if err := n.store.put(rec); err != nil {
+			return err
+		}
+		n.submitted.Store(h)
+		log.WithField("height", h).Info("header submitted")
+	}
+	if rec.CkptPending {
+		agg, err := aggregate(rec.CkptSigs)
+		if err != nil {
+			return err
+		}
+		cp := core.SidechainCheckpoint{ChainID: rec.Header.ChainID, Height: h, HeaderHash: hash, StateRoot: rec.Header.StateRoot, SigAgg: agg}
+		if err := n.coord.submitCheckpoint(ctx, cp); err != nil {
+			return fmt.Errorf("submit checkpoint %d: %w", h, err)
+		}
+		rec.CkptPending = false
+		if err := n.store.put(rec); err != nil {
+			return err
+		}
+		log.WithField("height", h).Info("checkpoint submitted")
+	}
+	return nil
+}
+
+// endorse validates a proposal from the scheduled proposer, executes it and
+// returns this validator's signatures. A block that has already been
+// endorsed is signed again so that proposers can retry, but a different
+// block at the same height never is.
+func (n *node) endorse(p proposal) (endorsement, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	h := p.Header
+	h.SigAgg = nil
+	if uint32(h.ChainID) != n.cfg.ChainID {
+		return endorsement{}, fmt.Errorf("wrong chain %d", h.ChainID)
+	}
+	if p.Proposer < 0 || p.Proposer >= len(n.vals) || p.Proposer != n.proposer(h.Height) {
+		return endorsement{}, fmt.Errorf("validator %d is not scheduled for height %d", p.Proposer, h.Height)
+	}
+	hash := h.SigningHash()
+	if !verifySig(n.vals[p.Proposer].pub, p.Sig, hash[:]) {
+		return endorsement{}, errors.New("bad proposer signature")
+	}
+	if rec, err := n.store.get(h.Height); err == nil {
+		if rec.Header.SigningHash() != hash {
+			return endorsement{}, errConflict
+		}
+		sig, ckpt := n.sign(rec.Header)
+		return endorsement{Sig: sig, Checkpoint: ckpt}, nil
+	}
+	if h.Height != n.led.LastHeight()+1 {
+		return endorsement{}, errOutOfSync
+	}
+	parent, err := n.parentHash(h.Height)
+	if err != nil {
+		return endorsement{}, err
+	}
+	if parent != h.Parent {
+		return endorsement{}, errors.New("parent does not match the local chain")
+	}
+
+	applied, leaves, err := n.execute(h.Height, p.Txs)
+	if err != nil {
+		return endorsement{}, err
+	}
+	if len(applied) != len(p.Txs) || txRoot(leaves) != h.TxRoot || [32]byte(n.led.StateRoot()) != h.StateRoot {
+		log.WithField("height", h.Height).Error("local execution diverged from proposal; resync required")
+		return endorsement{}, fmt.Errorf("block %d diverges from local execution", h.Height)
+	}
+	rec := &blockRecord{Header: h, Txs: applied, Leaves: leaves, Proposer: p.Proposer}
+	if err := n.store.put(rec); err != nil {
+		return endorsement{}, err
+	}
+	sig, ckpt := n.sign(h)
+	return endorsement{Sig: sig, Checkpoint: ckpt}, nil
+}
+
+// commit records the aggregate signature for an endorsed block so that
+// every validator can serve withdrawal proofs for it.
+func (n *node) commit(h core.SidechainHeader) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	rec, err := n.store.get(h.Height)
+	if err != nil {
+		return err
+	}
+	hash := h.SigningHash()
+	if rec.Header.SigningHash() != hash {
+		return errConflict
+	}
+	if !core.VerifyAggregateSig(n.pubkeys(), h.SigAgg, hash[:]) {
+		return errors.New("bad aggregate signature")
+	}
+	rec.Header.SigAgg = h.SigAgg
+	rec.Final = true
+	return n.store.put(rec)
+}
+
+// withdrawProof builds the core.WithdrawProof for a withdrawal leaf.
+func (n *node) withdrawProof(height uint64, index uint32) (core.WithdrawProof, error) {
+	rec, err := n.store.get(height)
+	if err != nil {
+		return core.WithdrawProof{}, err
+	}
+	if !rec.Final {
+		return core.WithdrawProof{}, fmt.Errorf("block %d is not signed by all validators yet", height)
+	}
+	proof, err := merkleProof(rec.Leaves, index)
+	if err != nil {
+		return core.WithdrawProof{}, err
+	}
+	var w withdrawal
+	if err := json.Unmarshal(rec.Leaves[index], &w); err != nil || w.Amount == 0 {
+		return core.WithdrawProof{}, fmt.Errorf("leaf %d of block %d is not a withdrawal", index, height)
+	}
+	return core.WithdrawProof{Header: rec.Header, TxData: rec.Leaves[index], Proof: proof, TxIndex: index, Recipient: w.Recipient}, nil
+}
+
+func aggregate(sigs map[int][]byte) ([]byte, error) {
+	var agg bls.Sign
+	first := true
+	for i, raw := range sigs {
+		var s bls.Sign
+		if err := s.Deserialize(raw); err != nil {
+			return nil, fmt.Errorf("signature of validator %d: %w", i, err)
+		}
+		if first {
+			agg, first = s, false
+			continue
+		}
+		agg.Add(&s)
+	}
+	return agg.Serialize(), nil
+}
+
+func verifySig(pub, sig, msg []byte) bool {
+	var pk bls.PublicKey
+	var s bls.Sign
+	if pk.Deserialize(pub) != nil || s.Deserialize(sig) != nil {
+		return false
+	}
+	return s.VerifyByte(&pk, msg)
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	core "synnergy-network/core"
+)
+
+// blockRecord is the node's bookkeeping for one produced side-chain block.
+// It lives outside the ledger state so that it does not feed into the state
+// root the validators sign.
+type blockRecord struct {
+	Header      core.SidechainHeader `json:"header"`
+	Txs         []*core.Transaction  `json:"txs"`
+	Leaves      [][]byte             `json:"leaves"`
+	Proposer    int                  `json:"proposer"`
+	Sigs        map[int][]byte       `json:"sigs,omitempty"`
+	CkptSigs    map[int][]byte       `json:"checkpoint_sigs,omitempty"`
+	Final       bool                 `json:"final"`
+	Submitted   bool                 `json:"submitted"`
+	CkptPending bool                 `json:"checkpoint_pending"`
+}
+
+var errNoRecord = errors.New("block record not found")
+
+type blockStore struct{ dir string }
+
+func newBlockStore(dir string) (*blockStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &blockStore{dir: dir}, nil
+}
+
+func (s *blockStore) path(h uint64) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%012d.json", h))
+}
+
+func (s *blockStore) get(h uint64) (*blockRecord, error) {
+	raw, err := os.ReadFile(s.path(h))
+	if os.IsNotExist(err) {
+		return nil, errNoRecord
+	}
+	if err != nil {
+		return nil, err
+	}
+	var rec blockRecord
+	if err := json.Unmarshal(raw, &rec); err != nil {
+		return nil, fmt.Errorf("decode block %d: %w", h, err)
+	}
+	return &rec, nil
+}
+
+// put writes the record atomically so a crash never leaves a torn file.
+func (s *blockStore) put(rec *blockRecord) error {
+	raw, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	p := s.path(rec.Header.Height)
+	if err := os.WriteFile(p+".tmp", raw, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(p+".tmp", p)
+}
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"encoding/json"
+	"flag"
 	"net/http"
 	"os"
 
@@ -10,6 +12,15 @@ import (
 )
 
 func main() {
+	openapi := flag.Bool("openapi", false, "print the OpenAPI spec and exit")
+	flag.Parse()
+	if *openapi {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(server.Spec.Document())
+		return
+	}
+
 	addr := os.Getenv("CROSSCHAIN_API_ADDR")
 	if addr == "" {
 		addr = ":8082"
@@ -3,8 +3,12 @@ package server
 import (
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"github.com/gorilla/mux"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	core "synnergy-network/core"
 )
@@ -13,31 +17,34 @@ import (
 func ListBridges(w http.ResponseWriter, _ *http.Request) {
 	bridges, err := core.ListBridges()
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, err)
 		return
 	}
 	writeJSON(w, bridges)
 }
 
+// registerBridgeRequest is the body of POST /api/bridges.
+type registerBridgeRequest struct {
+	SourceChain string `json:"source_chain" validate:"required"`
+	TargetChain string `json:"target_chain" validate:"required"`
+	Relayer     string `json:"relayer" validate:"required,format=address"`
+}
+
 // RegisterBridge creates a new bridge entry.
 func RegisterBridge(w http.ResponseWriter, r *http.Request) {
-	var req struct {
-		SourceChain string `json:"source_chain"`
-		TargetChain string `json:"target_chain"`
-		Relayer     string `json:"relayer"`
-	}
+	var req registerBridgeRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, err)
 		return
 	}
 	rel, err := core.ParseAddress(req.Relayer)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, err)
 		return
 	}
 	b := core.Bridge{SourceChain: req.SourceChain, TargetChain: req.TargetChain, Relayer: rel}
 	if err := core.RegisterBridge(b); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, err)
 		return
 	}
 	writeJSON(w, b)
@@ -48,23 +55,26 @@ func GetBridge(w http.ResponseWriter, r *http.Request) {
 	id := mux.Vars(r)["id"]
 	b, err := core.GetBridge(id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		writeError(w, http.StatusNotFound, err)
 		return
 	}
 	writeJSON(w, b)
 }
 
+// relayerRequest is the body of the relayer admin endpoints.
+type relayerRequest struct {
+	Addr string `json:"addr" validate:"required,format=hex"`
+}
+
 // AuthorizeRelayer adds a relayer to the whitelist.
 func AuthorizeRelayer(w http.ResponseWriter, r *http.Request) {
-	var req struct {
-		Addr string `json:"addr"`
-	}
+	var req relayerRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, err)
 		return
 	}
 	if _, err := hex.DecodeString(req.Addr); err != nil {
-		http.Error(w, "invalid address", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, errors.New("invalid address"))
 		return
 	}
 	core.AuthorizedRelayers[req.Addr] = true
@@ -73,11 +83,9 @@ func AuthorizeRelayer(w http.ResponseWriter, r *http.Request) {
 
 // RevokeRelayer removes a relayer from the whitelist.
 func RevokeRelayer(w http.ResponseWriter, r *http.Request) {
-	var req struct {
-		Addr string `json:"addr"`
-	}
+	var req relayerRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, err)
 		return
 	}
 	delete(core.AuthorizedRelayers, req.Addr)
@@ -90,46 +98,310 @@ func writeJSON(w http.ResponseWriter, v any) {
 	_ = json.NewEncoder(w).Encode(v)
 }
 
+// lockMintRequest is the body of POST /api/lockmint.
+type lockMintRequest struct {
+	AssetID uint32 `json:"asset_id" validate:"required"`
+	Amount  uint64 `json:"amount" validate:"required,min=1"`
+	Proof   string `json:"proof" validate:"required"`
+}
+
 // LockMint invokes the LockAndMint opcode via core helpers.
 func LockMint(w http.ResponseWriter, r *http.Request) {
-	var req struct {
-		AssetID uint32 `json:"asset_id"`
-		Amount  uint64 `json:"amount"`
-		Proof   string `json:"proof"`
-	}
+	var req lockMintRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, err)
 		return
 	}
 	ctx := &core.Context{}
 	proof := core.Proof{TxHash: []byte(req.Proof)}
 	if err := core.LockAndMint(ctx, core.AssetRef{Kind: core.AssetToken, TokenID: core.TokenID(req.AssetID)}, proof, req.Amount); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, err)
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// burnReleaseRequest is the body of POST /api/burnrelease.
+type burnReleaseRequest struct {
+	AssetID uint32 `json:"asset_id" validate:"required"`
+	To      string `json:"to" validate:"required,format=address"`
+	Amount  uint64 `json:"amount" validate:"required,min=1"`
+}
+
 // BurnRelease invokes the BurnAndRelease opcode via core helpers.
 func BurnRelease(w http.ResponseWriter, r *http.Request) {
-	var req struct {
-		AssetID uint32 `json:"asset_id"`
-		To      string `json:"to"`
-		Amount  uint64 `json:"amount"`
-	}
+	var req burnReleaseRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, err)
 		return
 	}
 	target, err := core.ParseAddress(req.To)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, err)
 		return
 	}
 	ctx := &core.Context{}
 	if err := core.BurnAndRelease(ctx, core.AssetRef{Kind: core.AssetToken, TokenID: core.TokenID(req.AssetID)}, target, req.Amount); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, err)
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// BridgeSafety reports the circuit breaker, limits and window usage.
+func BridgeSafety(w http.ResponseWriter, _ *http.Request) {
+	st, err := core.BridgeSafety()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, st)
+}
+
+// BridgeSafetyEvents lists safety alerts, optionally after ?since=<seq>.
+func BridgeSafetyEvents(w http.ResponseWriter, r *http.Request) {
+	var since uint64
+	if v := r.URL.Query().Get("since"); v != "" {
+		n, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, errors.New("invalid since"))
+			return
+		}
+		since = n
+	}
+	evs, err := core.BridgeSafetyEvents(since)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, evs)
+}
+
+type rateLimitReq struct {
+	Max    uint64 `json:"max"`
+	Window string `json:"window" validate:"format=duration"`
+}
+
+func (l rateLimitReq) limit() (core.BridgeRateLimit, error) {
+	out := core.BridgeRateLimit{Max: l.Max}
+	if l.Window == "" {
+		return out, nil
+	}
+	d, err := time.ParseDuration(l.Window)
+	out.Window = d
+	return out, err
+}
+
+// guardianSignature is one guardian's hex signature in a signed request.
+type guardianSignature struct {
+	Guardian  string `json:"guardian" validate:"required,format=address"`
+	Signature string `json:"signature" validate:"required"`
+}
+
+// safetyConfigRequest is the body of PUT /api/safety/config. Signatures
+// are those of a quorum of the current guardians over the typed data of
+// PUT /api/safety/config/typed-data for the same configuration.
+type safetyConfigRequest struct {
+	Global           rateLimitReq            `json:"global"`
+	Assets           map[string]rateLimitReq `json:"assets"`
+	AnomalyThreshold float64                 `json:"anomaly_threshold" validate:"min=0"`
+	AnomalyMinSample uint64                  `json:"anomaly_min_samples"`
+	Guardians        []string                `json:"guardians"`
+	Threshold        int                     `json:"threshold" validate:"min=0"`
+	Signatures       []guardianSignature     `json:"signatures"`
+}
+
+func (req safetyConfigRequest) config() (core.BridgeSafetyConfig, error) {
+	cfg := core.BridgeSafetyConfig{
+		Assets:           map[string]core.BridgeRateLimit{},
+		AnomalyThreshold: req.AnomalyThreshold,
+		AnomalyMinSample: req.AnomalyMinSample,
+		Threshold:        req.Threshold,
+	}
+	var err error
+	if cfg.Global, err = req.Global.limit(); err != nil {
+		return cfg, err
+	}
+	for asset, l := range req.Assets {
+		if cfg.Assets[asset], err = l.limit(); err != nil {
+			return cfg, err
+		}
+	}
+	for _, g := range req.Guardians {
+		a, err := core.ParseAddress(strings.TrimPrefix(g, "0x"))
+		if err != nil {
+			return cfg, err
+		}
+		cfg.Guardians = append(cfg.Guardians, a)
+	}
+	return cfg, nil
+}
+
+// safetyDocument is typed data a guardian signs, with the guardian nonce
+// or configuration version it is bound to.
+type safetyDocument struct {
+	Nonce     uint64         `json:"nonce"`
+	TypedData core.TypedData `json:"typed_data"`
+}
+
+// SafetyConfigTypedData returns the document guardians sign to replace the
+// current configuration with the one in the body.
+func SafetyConfigTypedData(w http.ResponseWriter, r *http.Request) {
+	var req safetyConfigRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	cfg, err := req.config()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	st, err := core.BridgeSafety()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	v := st.Config.Version
+	writeJSON(w, safetyDocument{Nonce: v, TypedData: core.BridgeSafetyConfigTypedData(cfg, v)})
+}
+
+// SetBridgeSafetyConfig replaces the outflow limits, anomaly settings and
+// guardian set with the approval of a quorum of the current guardians.
+func SetBridgeSafetyConfig(w http.ResponseWriter, r *http.Request) {
+	var req safetyConfigRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	cfg, err := req.config()
+	if err == nil {
+		err = cfg.Validate()
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	var sigs []core.BridgeGuardianSignature
+	for _, s := range req.Signatures {
+		g, err := core.ParseAddress(strings.TrimPrefix(s.Guardian, "0x"))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		sig, err := hex.DecodeString(strings.TrimPrefix(s.Signature, "0x"))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, errors.New("invalid signature"))
+			return
+		}
+		sigs = append(sigs, core.BridgeGuardianSignature{Guardian: g, Sig: sig})
+	}
+	if err := core.SetBridgeSafetyConfig(cfg, sigs); err != nil {
+		writeError(w, guardianStatus(err), err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// guardianRequest is the body of the pause and unpause endpoints. The
+// signature is the guardian's over the typed data of
+// POST /api/safety/{action}/typed-data.
+type guardianRequest struct {
+	Guardian  string `json:"guardian" validate:"required,format=address"`
+	Reason    string `json:"reason"`
+	Nonce     uint64 `json:"nonce"`
+	Signature string `json:"signature" validate:"required"`
+}
+
+// guardianDocumentRequest is the body of the typed data endpoints of pause
+// and unpause.
+type guardianDocumentRequest struct {
+	Guardian string `json:"guardian" validate:"required,format=address"`
+	Reason   string `json:"reason"`
+}
+
+func guardianReq(r *http.Request) (core.Address, guardianRequest, []byte, error) {
+	var req guardianRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return core.Address{}, req, nil, err
+	}
+	a, err := core.ParseAddress(strings.TrimPrefix(req.Guardian, "0x"))
+	if err != nil {
+		return a, req, nil, err
+	}
+	sig, err := hex.DecodeString(strings.TrimPrefix(req.Signature, "0x"))
+	if err != nil {
+		return a, req, nil, errors.New("invalid signature")
+	}
+	return a, req, sig, nil
+}
+
+func guardianStatus(err error) int {
+	switch {
+	case errors.Is(err, core.ErrNotBridgeGuardian), errors.Is(err, core.ErrGuardianSignature),
+		errors.Is(err, core.ErrGuardianQuorum):
+		return http.StatusForbidden
+	}
+	return http.StatusConflict
+}
+
+// GuardianTypedData returns the document a guardian signs to pause the
+// bridge or approve resuming it, bound to its next nonce.
+func GuardianTypedData(w http.ResponseWriter, r *http.Request) {
+	action := mux.Vars(r)["action"]
+	if action != core.BridgeActionPause && action != core.BridgeActionUnpause {
+		writeError(w, http.StatusNotFound, errors.New("unknown guardian action"))
+		return
+	}
+	var req guardianDocumentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	g, err := core.ParseAddress(strings.TrimPrefix(req.Guardian, "0x"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if action == core.BridgeActionUnpause {
+		req.Reason = ""
+	}
+	n := core.BridgeGuardianNonce(g)
+	writeJSON(w, safetyDocument{Nonce: n, TypedData: core.BridgeGuardianTypedData(action, g, req.Reason, n)})
+}
+
+// PauseBridge trips the circuit breaker on behalf of the guardian that
+// signed the request.
+func PauseBridge(w http.ResponseWriter, r *http.Request) {
+	g, req, sig, err := guardianReq(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := core.PauseBridge(g, req.Reason, req.Nonce, sig); err != nil {
+		writeError(w, guardianStatus(err), err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ApproveBridgeUnpause records the approval of the guardian that signed
+// the request to resume the bridge.
+func ApproveBridgeUnpause(w http.ResponseWriter, r *http.Request) {
+	g, req, sig, err := guardianReq(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	resumed, err := core.ApproveBridgeUnpause(g, req.Nonce, sig)
+	if err != nil {
+		writeError(w, guardianStatus(err), err)
+		return
+	}
+	writeJSON(w, map[string]bool{"resumed": resumed})
+}
+
+// writeError answers with the shared error envelope (see core.ErrorEnvelope).
+func writeError(w http.ResponseWriter, status int, err error) {
+	core.WriteHTTPError(w, status, "xchain", err)
+}
@@ -0,0 +1,141 @@
+package server
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	core "synnergy-network/core"
+)
+
+type guardianKey struct {
+	priv ed25519.PrivateKey
+	addr string
+}
+
+// newSafetyServer returns a router over an empty store whose bridge has
+// two guardians with threshold 2, configured through governance.
+func newSafetyServer(t *testing.T) (http.Handler, []guardianKey) {
+	t.Helper()
+	core.SetStore(core.NewInMemoryStore())
+	t.Cleanup(func() { core.SetStore(nil) })
+	var keys []guardianKey
+	var addrs []core.Address
+	for i := 0; i < 2; i++ {
+		_, priv, _ := ed25519.GenerateKey(nil)
+		s, err := core.SignTypedData(priv, core.BridgeGuardianTypedData(core.BridgeActionPause, core.Address{}, "", 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		a, err := core.DecodeAddress(s.Signer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys, addrs = append(keys, guardianKey{priv, s.Signer}), append(addrs, a)
+	}
+	raw, _ := json.Marshal(core.BridgeSafetyConfig{Guardians: addrs, Threshold: 2})
+	if err := core.UpdateParam("bridge_safety_config", string(raw)); err != nil {
+		t.Fatal(err)
+	}
+	return NewRouter(), keys
+}
+
+func call(t *testing.T, h http.Handler, method, path string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+	raw, _ := json.Marshal(body)
+	req := httptest.NewRequest(method, path, bytes.NewReader(raw))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+// sign fetches the document at path for body and returns its nonce and
+// the hex signature of k.
+func sign(t *testing.T, h http.Handler, method, path string, body any, k guardianKey) (uint64, string) {
+	t.Helper()
+	rec := call(t, h, method, path, body)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("%s: %d %s", path, rec.Code, rec.Body)
+	}
+	var doc safetyDocument
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	s, err := core.SignTypedData(k.priv, doc.TypedData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return doc.Nonce, s.Sig
+}
+
+func TestGuardianActionsRequireTheGuardiansSignature(t *testing.T) {
+	h, keys := newSafetyServer(t)
+	g := keys[0]
+
+	if rec := call(t, h, http.MethodPost, "/api/safety/pause", map[string]any{"guardian": g.addr, "reason": "drill"}); rec.Code != http.StatusBadRequest {
+		t.Fatalf("unsigned pause: %d %s", rec.Code, rec.Body)
+	}
+	// signed by the other guardian in g's name
+	doc := guardianDocumentRequest{Guardian: g.addr, Reason: "drill"}
+	n, forged := sign(t, h, http.MethodPost, "/api/safety/pause/typed-data", doc, keys[1])
+	pause := guardianRequest{Guardian: g.addr, Reason: "drill", Nonce: n, Signature: forged}
+	if rec := call(t, h, http.MethodPost, "/api/safety/pause", pause); rec.Code != http.StatusForbidden {
+		t.Fatalf("forged pause: %d %s", rec.Code, rec.Body)
+	}
+
+	_, pause.Signature = sign(t, h, http.MethodPost, "/api/safety/pause/typed-data", doc, g)
+	if rec := call(t, h, http.MethodPost, "/api/safety/pause", pause); rec.Code != http.StatusNoContent {
+		t.Fatalf("pause: %d %s", rec.Code, rec.Body)
+	}
+
+	// one guardian cannot make up the quorum by replaying its approval
+	doc = guardianDocumentRequest{Guardian: g.addr}
+	n, sig := sign(t, h, http.MethodPost, "/api/safety/unpause/typed-data", doc, g)
+	approve := guardianRequest{Guardian: g.addr, Nonce: n, Signature: sig}
+	for i, want := range []int{http.StatusOK, http.StatusConflict} {
+		if rec := call(t, h, http.MethodPost, "/api/safety/unpause", approve); rec.Code != want {
+			t.Fatalf("approval %d: %d %s", i, rec.Code, rec.Body)
+		}
+	}
+	if st, _ := core.BridgeSafety(); !st.Breaker.Paused {
+		t.Fatal("bridge resumed with one guardian")
+	}
+}
+
+func TestSafetyConfigRequiresAGuardianQuorum(t *testing.T) {
+	h, keys := newSafetyServer(t)
+	cfg := safetyConfigRequest{
+		Global:    rateLimitReq{Max: 1_000, Window: "1h"},
+		Guardians: []string{keys[0].addr, keys[1].addr},
+		Threshold: 1,
+	}
+	if rec := call(t, h, http.MethodPut, "/api/safety/config", cfg); rec.Code != http.StatusForbidden {
+		t.Fatalf("unsigned config: %d %s", rec.Code, rec.Body)
+	}
+
+	var sigs []guardianSignature
+	for _, k := range keys {
+		_, sig := sign(t, h, http.MethodPut, "/api/safety/config/typed-data", cfg, k)
+		sigs = append(sigs, guardianSignature{Guardian: k.addr, Signature: sig})
+	}
+	cfg.Signatures = sigs[:1]
+	if rec := call(t, h, http.MethodPut, "/api/safety/config", cfg); rec.Code != http.StatusForbidden {
+		t.Fatalf("config signed by one of two guardians: %d %s", rec.Code, rec.Body)
+	}
+	cfg.Signatures = sigs
+	if rec := call(t, h, http.MethodPut, "/api/safety/config", cfg); rec.Code != http.StatusNoContent {
+		t.Fatalf("config signed by the quorum: %d %s", rec.Code, rec.Body)
+	}
+	st, err := core.BridgeSafety()
+	if err != nil || st.Config.Threshold != 1 || st.Config.Global.Max != 1_000 {
+		t.Fatalf("config %+v %v", st.Config, err)
+	}
+	// the signatures approved the previous version only
+	if rec := call(t, h, http.MethodPut, "/api/safety/config", cfg); rec.Code != http.StatusForbidden {
+		t.Fatalf("replayed config: %d %s", rec.Code, rec.Body)
+	}
+}
@@ -3,19 +3,12 @@ package server
 import (
 	"net/http"
 
-	log "github.com/sirupsen/logrus"
+	core "synnergy-network/core"
 )
 
-// RequestLogger writes basic request info using structured logging.
-func RequestLogger(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		log.WithFields(log.Fields{
-			"method": r.Method,
-			"path":   r.URL.Path,
-		}).Info("incoming request")
-		next.ServeHTTP(w, r)
-	})
-}
+// RequestLogger tags each request with an ID and writes one structured
+// access line for it on the xchain module logger (see core.RequestLogger).
+var RequestLogger = core.RequestLogger("xchain")
 
 // JSONHeaders sets Content-Type application/json for all responses.
 func JSONHeaders(next http.Handler) http.Handler {
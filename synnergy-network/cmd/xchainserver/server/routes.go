@@ -1,11 +1,61 @@
 package server
 
 import (
+	"encoding/json"
 	"net/http"
 
 	"github.com/gorilla/mux"
+
+	core "synnergy-network/core"
+	"synnergy-network/pkg/openapi"
 )
 
+// Routes is the route table of the cross-chain server. It drives the mux
+// registration, request validation and the OpenAPI document.
+var Routes = []openapi.Route{
+	// bridge management
+	{Method: http.MethodGet, Path: "/api/bridges", Summary: "List registered bridges",
+		Response: []core.Bridge{}, Handler: ListBridges},
+	{Method: http.MethodPost, Path: "/api/bridges", Summary: "Register a bridge",
+		Request: registerBridgeRequest{}, Response: core.Bridge{}, Handler: RegisterBridge},
+	{Method: http.MethodGet, Path: "/api/bridges/{id}", Summary: "Get a bridge by ID",
+		Params:   []openapi.Param{{Name: "id", In: "path", Desc: "bridge ID", Type: "string"}},
+		Response: core.Bridge{}, Handler: GetBridge},
+
+	// relayer admin
+	{Method: http.MethodPost, Path: "/api/relayer/authorize", Summary: "Whitelist a relayer",
+		Request: relayerRequest{}, Handler: AuthorizeRelayer},
+	{Method: http.MethodPost, Path: "/api/relayer/revoke", Summary: "Remove a relayer from the whitelist",
+		Request: relayerRequest{}, Handler: RevokeRelayer},
+
+	// safety controls
+	{Method: http.MethodGet, Path: "/api/safety", Summary: "Circuit breaker, outflow limits and window usage",
+		Response: core.BridgeSafetyStatus{}, Handler: BridgeSafety},
+	{Method: http.MethodPut, Path: "/api/safety/config", Summary: "Replace outflow limits, anomaly settings and guardians with a guardian quorum's signatures",
+		Request: safetyConfigRequest{}, Handler: SetBridgeSafetyConfig},
+	{Method: http.MethodPut, Path: "/api/safety/config/typed-data", Summary: "Typed data guardians sign to approve a configuration",
+		Request: safetyConfigRequest{}, Response: safetyDocument{}, Handler: SafetyConfigTypedData},
+	{Method: http.MethodGet, Path: "/api/safety/events", Summary: "List safety alerts",
+		Params:   []openapi.Param{{Name: "since", In: "query", Desc: "return events after this sequence number", Type: "integer"}},
+		Response: []core.BridgeSafetyEvent{}, Handler: BridgeSafetyEvents},
+	{Method: http.MethodPost, Path: "/api/safety/{action}/typed-data", Summary: "Typed data a guardian signs to pause or approve resuming",
+		Params:  []openapi.Param{{Name: "action", In: "path", Desc: "pause or unpause", Type: "string"}},
+		Request: guardianDocumentRequest{}, Response: safetyDocument{}, Handler: GuardianTypedData},
+	{Method: http.MethodPost, Path: "/api/safety/pause", Summary: "Trip the circuit breaker as a guardian",
+		Request: guardianRequest{}, Handler: PauseBridge},
+	{Method: http.MethodPost, Path: "/api/safety/unpause", Summary: "Approve resuming the bridge as a guardian",
+		Request: guardianRequest{}, Response: map[string]bool{}, Handler: ApproveBridgeUnpause},
+
+	// token actions
+	{Method: http.MethodPost, Path: "/api/lockmint", Summary: "Lock assets on the source chain and mint wrapped tokens",
+		Request: lockMintRequest{}, Handler: LockMint},
+	{Method: http.MethodPost, Path: "/api/burnrelease", Summary: "Burn wrapped tokens and release the locked assets",
+		Request: burnReleaseRequest{}, Handler: BurnRelease},
+}
+
+// Spec describes the cross-chain API.
+var Spec = openapi.Spec{Title: "Synnergy Cross-Chain API", Version: "1.0.0", Routes: Routes}
+
 // NewRouter configures the HTTP routes for the cross-chain server.
 func NewRouter() *mux.Router {
 	r := mux.NewRouter()
@@ -13,19 +63,14 @@ func NewRouter() *mux.Router {
 	// middleware
 	r.Use(RequestLogger)
 	r.Use(JSONHeaders)
+	r.Use(Spec.Validator("xchain"))
 
-	// bridge management
-	r.HandleFunc("/api/bridges", ListBridges).Methods(http.MethodGet)
-	r.HandleFunc("/api/bridges", RegisterBridge).Methods(http.MethodPost)
-	r.HandleFunc("/api/bridges/{id}", GetBridge).Methods(http.MethodGet)
-
-	// relayer admin
-	r.HandleFunc("/api/relayer/authorize", AuthorizeRelayer).Methods(http.MethodPost)
-	r.HandleFunc("/api/relayer/revoke", RevokeRelayer).Methods(http.MethodPost)
-
-	// token actions
-	r.HandleFunc("/api/lockmint", LockMint).Methods(http.MethodPost)
-	r.HandleFunc("/api/burnrelease", BurnRelease).Methods(http.MethodPost)
+	for _, rt := range Routes {
+		r.HandleFunc(rt.Path, rt.Handler).Methods(rt.Method)
+	}
+	r.HandleFunc("/api/openapi.json", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(Spec.Document())
+	}).Methods(http.MethodGet)
 
 	return r
 }
@@ -26,26 +26,30 @@ func (am *AccountManager) CreateAccount(addr Address) error {
 	}
 	am.mu.Lock()
 	defer am.mu.Unlock()
-	key := addr.String()
-	if _, ok := am.ledger.TokenBalances[key]; ok {
-		return fmt.Errorf("account %s exists", key)
+	key := CoinKey(addr)
+	if _, ok := am.ledger.Balances[key]; ok {
+		return fmt.Errorf("account %s exists", addr)
 	}
-	am.ledger.TokenBalances[key] = 0
+	am.ledger.credit(key, 0)
 	return nil
 }
 
-// DeleteAccount removes addr from the ledger balance map.
+// DeleteAccount removes addr from the ledger balance map, burning any
+// balance it still holds.
 func (am *AccountManager) DeleteAccount(addr Address) error {
 	if am.ledger == nil {
 		return fmt.Errorf("account manager: nil ledger")
 	}
 	am.mu.Lock()
 	defer am.mu.Unlock()
-	key := addr.String()
-	if _, ok := am.ledger.TokenBalances[key]; !ok {
-		return fmt.Errorf("account %s not found", key)
+	key := CoinKey(addr)
+	if _, ok := am.ledger.Balances[key]; !ok {
+		return fmt.Errorf("account %s not found", addr)
 	}
-	delete(am.ledger.TokenBalances, key)
+	if err := am.ledger.burn(key, am.ledger.Balances[key]); err != nil {
+		return err
+	}
+	delete(am.ledger.Balances, key)
 	return nil
 }
 
@@ -56,7 +60,7 @@ func (am *AccountManager) Balance(addr Address) (uint64, error) {
 	}
 	am.mu.RLock()
 	defer am.mu.RUnlock()
-	return am.ledger.TokenBalances[addr.String()], nil
+	return am.ledger.Balances[CoinKey(addr)], nil
 }
 
 // Transfer moves amt coins from src to dst, verifying sufficient funds.
@@ -69,10 +73,5 @@ func (am *AccountManager) Transfer(src, dst Address, amt uint64) error {
 	}
 	am.mu.Lock()
 	defer am.mu.Unlock()
-	if am.ledger.TokenBalances[src.String()] < amt {
-		return fmt.Errorf("insufficient balance")
-	}
-	am.ledger.TokenBalances[src.String()] -= amt
-	am.ledger.TokenBalances[dst.String()] += amt
-	return nil
+	return am.ledger.move(CoinKey(src), dst, amt)
 }
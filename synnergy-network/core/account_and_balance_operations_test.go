@@ -3,7 +3,7 @@ package core
 import "testing"
 
 func TestAccountManagerCreateAndBalance(t *testing.T) {
-	ledger := &Ledger{TokenBalances: make(map[string]uint64)}
+	ledger := &Ledger{Balances: make(map[BalanceKey]uint64)}
 	am := NewAccountManager(ledger)
 	var addr Address
 	copy(addr[:], []byte("address-1-000000"))
@@ -26,7 +26,7 @@ func TestAccountManagerCreateAndBalance(t *testing.T) {
 }
 
 func TestAccountManagerTransferAndDelete(t *testing.T) {
-	ledger := &Ledger{TokenBalances: make(map[string]uint64)}
+	ledger := &Ledger{Balances: make(map[BalanceKey]uint64)}
 	am := NewAccountManager(ledger)
 
 	var src, dst Address
@@ -36,7 +36,7 @@ func TestAccountManagerTransferAndDelete(t *testing.T) {
 	if err := am.CreateAccount(src); err != nil {
 		t.Fatalf("CreateAccount src failed: %v", err)
 	}
-	ledger.TokenBalances[src.String()] = 100
+	ledger.Balances[CoinKey(src)] = 100
 	if err := am.CreateAccount(dst); err != nil {
 		t.Fatalf("CreateAccount dst failed: %v", err)
 	}
@@ -44,17 +44,17 @@ func TestAccountManagerTransferAndDelete(t *testing.T) {
 	if err := am.Transfer(src, dst, 40); err != nil {
 		t.Fatalf("Transfer failed: %v", err)
 	}
-	if ledger.TokenBalances[src.String()] != 60 {
-		t.Fatalf("src expected 60, got %d", ledger.TokenBalances[src.String()])
+	if ledger.Balances[CoinKey(src)] != 60 {
+		t.Fatalf("src expected 60, got %d", ledger.Balances[CoinKey(src)])
 	}
-	if ledger.TokenBalances[dst.String()] != 40 {
-		t.Fatalf("dst expected 40, got %d", ledger.TokenBalances[dst.String()])
+	if ledger.Balances[CoinKey(dst)] != 40 {
+		t.Fatalf("dst expected 40, got %d", ledger.Balances[CoinKey(dst)])
 	}
 
 	if err := am.DeleteAccount(src); err != nil {
 		t.Fatalf("DeleteAccount failed: %v", err)
 	}
-	if _, ok := ledger.TokenBalances[src.String()]; ok {
+	if _, ok := ledger.Balances[CoinKey(src)]; ok {
 		t.Fatalf("source account still exists after deletion")
 	}
 }
@@ -0,0 +1,251 @@
+package core
+
+// address_format.go – checksummed, human-readable address encodings.
+//
+// Addresses are written as bech32 strings (BIP-173) under the network's
+// human-readable part, e.g. "syn1t2htvpfl862vnwdqnuekd9p4ulh3h6hdwkn2n4".
+// The BCH checksum detects any error affecting up to four characters, so a
+// mistyped address is rejected instead of silently burning funds.
+//
+// During the transition period DecodeAddress also accepts the legacy hex
+// form, with or without a 0x prefix. All-lower or all-upper hex carries no
+// checksum and is accepted as is; mixed-case hex is treated as EIP-55 and
+// its checksum is verified.
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	// MainnetAddressHRP is the bech32 prefix of main network addresses.
+	MainnetAddressHRP = "syn"
+	// TestnetAddressHRP is the bech32 prefix of test network addresses.
+	TestnetAddressHRP = "tsyn"
+)
+
+var (
+	// ErrAddressFormat is returned for strings that are neither bech32 nor
+	// 20-byte hex.
+	ErrAddressFormat = errors.New("invalid address")
+	// ErrAddressChecksum is returned when a bech32 or EIP-55 checksum does
+	// not match.
+	ErrAddressChecksum = errors.New("address checksum mismatch")
+	// ErrAddressNetwork is returned for bech32 addresses of another network.
+	ErrAddressNetwork = errors.New("address belongs to another network")
+)
+
+var addressHRP = MainnetAddressHRP
+
+// SetAddressHRP selects the bech32 prefix used to format and accept
+// addresses. It is meant to be called once during node start-up.
+func SetAddressHRP(hrp string) error {
+	if hrp == "" || strings.ToLower(hrp) != hrp || len(hrp) > 83 {
+		return fmt.Errorf("invalid address prefix %q", hrp)
+	}
+	addressHRP = hrp
+	return nil
+}
+
+// AddressHRP returns the active bech32 prefix.
+func AddressHRP() string { return addressHRP }
+
+// Bech32 returns the checksummed bech32 form of the address.
+func (a Address) Bech32() string {
+	return bech32Encode(addressHRP, convertBits(a[:], 8, 5, true))
+}
+
+// ChecksumHex returns the EIP-55 mixed-case hex form of the address.
+func (a Address) ChecksumHex() string {
+	lower := fmt.Sprintf("%x", a[:])
+	sum := crypto.Keccak256([]byte(lower))
+	out := []byte(lower)
+	for i, c := range out {
+		if c >= 'a' && sum[i/2]>>(4*(1-uint(i)%2))&0xf >= 8 {
+			out[i] = c - 'a' + 'A'
+		}
+	}
+	return "0x" + string(out)
+}
+
+// DecodeAddress parses a bech32 address of the active network or a legacy
+// hex address. Checksums are verified whenever the input carries one.
+func DecodeAddress(s string) (Address, error) {
+	s = strings.TrimSpace(s)
+	if h, ok := legacyHex(s); ok {
+		return decodeHexAddress(h)
+	}
+
+	hrp, data, err := bech32Decode(s)
+	if err != nil {
+		return AddressZero, fmt.Errorf("%w: %s: %v", ErrAddressFormat, s, err)
+	}
+	if hrp != addressHRP {
+		return AddressZero, fmt.Errorf("%w: prefix %q, want %q", ErrAddressNetwork, hrp, addressHRP)
+	}
+	raw, ok := convertBits5to8(data)
+	if !ok || len(raw) != len(Address{}) {
+		return AddressZero, fmt.Errorf("%w: %s", ErrAddressFormat, s)
+	}
+	var a Address
+	copy(a[:], raw)
+	return a, nil
+}
+
+// legacyHex reports whether s is 20-byte hex and returns it without prefix.
+func legacyHex(s string) (string, bool) {
+	h := strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X")
+	if len(h) != 2*len(Address{}) {
+		return "", false
+	}
+	for _, c := range h {
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F') {
+			return "", false
+		}
+	}
+	return h, true
+}
+
+func decodeHexAddress(h string) (Address, error) {
+	var a Address
+	for i := range a {
+		a[i] = unhex(h[2*i])<<4 | unhex(h[2*i+1])
+	}
+	if h != strings.ToLower(h) && h != strings.ToUpper(h) {
+		if want := a.ChecksumHex()[2:]; h != want {
+			return AddressZero, fmt.Errorf("%w: 0x%s", ErrAddressChecksum, h)
+		}
+	}
+	return a, nil
+}
+
+func unhex(c byte) byte {
+	switch {
+	case c >= 'a':
+		return c - 'a' + 10
+	case c >= 'A':
+		return c - 'A' + 10
+	default:
+		return c - '0'
+	}
+}
+
+//---------------------------------------------------------------------
+// bech32 (BIP-173)
+//---------------------------------------------------------------------
+
+const bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+var bech32Gen = [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+
+func bech32Polymod(values []byte) uint32 {
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (top>>uint(i))&1 == 1 {
+				chk ^= bech32Gen[i]
+			}
+		}
+	}
+	return chk
+}
+
+func bech32HRPExpand(hrp string) []byte {
+	out := make([]byte, 0, 2*len(hrp)+1)
+	for i := 0; i < len(hrp); i++ {
+		out = append(out, hrp[i]>>5)
+	}
+	out = append(out, 0)
+	for i := 0; i < len(hrp); i++ {
+		out = append(out, hrp[i]&31)
+	}
+	return out
+}
+
+func bech32Checksum(hrp string, data []byte) []byte {
+	values := append(bech32HRPExpand(hrp), data...)
+	mod := bech32Polymod(append(values, 0, 0, 0, 0, 0, 0)) ^ 1
+	out := make([]byte, 6)
+	for i := range out {
+		out[i] = byte(mod >> uint(5*(5-i)) & 31)
+	}
+	return out
+}
+
+func bech32Encode(hrp string, data []byte) string {
+	var sb strings.Builder
+	sb.WriteString(hrp)
+	sb.WriteByte('1')
+	for _, d := range data {
+		sb.WriteByte(bech32Charset[d])
+	}
+	for _, d := range bech32Checksum(hrp, data) {
+		sb.WriteByte(bech32Charset[d])
+	}
+	return sb.String()
+}
+
+func bech32Decode(s string) (string, []byte, error) {
+	if len(s) > 90 {
+		return "", nil, errors.New("too long")
+	}
+	if strings.ToLower(s) != s && strings.ToUpper(s) != s {
+		return "", nil, errors.New("mixed case")
+	}
+	s = strings.ToLower(s)
+	pos := strings.LastIndexByte(s, '1')
+	if pos < 1 || pos+7 > len(s) {
+		return "", nil, errors.New("missing separator or checksum")
+	}
+	hrp := s[:pos]
+	data := make([]byte, 0, len(s)-pos-1)
+	for i := pos + 1; i < len(s); i++ {
+		d := strings.IndexByte(bech32Charset, s[i])
+		if d < 0 {
+			return "", nil, fmt.Errorf("invalid character %q", s[i])
+		}
+		data = append(data, byte(d))
+	}
+	if bech32Polymod(append(bech32HRPExpand(hrp), data...)) != 1 {
+		return "", nil, ErrAddressChecksum
+	}
+	return hrp, data[:len(data)-6], nil
+}
+
+// convertBits regroups data from groups of from bits into groups of to
+// bits, zero-padding the final group when pad is set.
+func convertBits(data []byte, from, to uint, pad bool) []byte {
+	var acc, bits uint
+	maxv := uint(1)<<to - 1
+	out := make([]byte, 0, len(data)*int(from)/int(to)+1)
+	for _, v := range data {
+		acc = acc<<from | uint(v)
+		bits += from
+		for bits >= to {
+			bits -= to
+			out = append(out, byte(acc>>bits&maxv))
+		}
+	}
+	if pad && bits > 0 {
+		out = append(out, byte(acc<<(to-bits)&maxv))
+	}
+	return out
+}
+
+// convertBits5to8 is the inverse of convertBits(data, 8, 5, true); it fails
+// when the padding is longer than four bits or not zero.
+func convertBits5to8(data []byte) ([]byte, bool) {
+	if len(data) == 0 {
+		return nil, false
+	}
+	bits := uint(len(data)*5) % 8
+	if bits >= 5 || data[len(data)-1]&(1<<bits-1) != 0 {
+		return nil, false
+	}
+	return convertBits(data, 5, 8, false), true
+}
@@ -0,0 +1,630 @@
+package core
+
+// address_watch.go – watch-only wallets and address monitoring.
+//
+// A watch lists addresses to monitor and the events to report on them. It
+// belongs to the account that signed its request as described in
+// webhook_registration.go:
+//
+//	incoming   a coin or token transfer to a watched address
+//	outgoing   a coin or token transfer from a watched address
+//	balance    the net coin change of an address in one block reaching
+//	           the watch's BalanceThreshold
+//	contract   a contract call sent by, or to, a watched address
+//
+// Transfers below MinAmount are ignored. An AddressMonitor scans new blocks
+// from a BlockSource, records a notification per match and delivers it to
+// the watch's webhook (signed as in webhook_signing.go) and e-mail address
+// through the monitor's EmailSender, retrying failed deliveries with
+// exponential backoff. Notification IDs are derived from the block, the
+// transaction and the event, so rescanning a block records nothing twice.
+// Notifications stay queryable after delivery.
+//
+// Wallet keys are derived with hardened ed25519 steps only, so there is no
+// extended public key to derive addresses from. HDWallet.WatchOnly exports
+// the addresses of an account instead; the export carries no key material
+// and can be registered as a watch.
+//
+// Ledger layout:
+//   watch:w:<id>                          -> Watch
+//   watch:n:<watch id>:<height>:<digest>  -> WatchNotification
+//   watch:pending:<notification key>      -> notification awaiting delivery
+//   watch:scan                            -> last scanned height
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// Watch event kinds.
+const (
+	WatchIncoming = "incoming"
+	WatchOutgoing = "outgoing"
+	WatchBalance  = "balance"
+	WatchContract = "contract"
+)
+
+// watchDeliveryAttempts bounds the deliveries of one notification per channel.
+const watchDeliveryAttempts = 12
+
+var ErrWatchNotFound = errors.New("watch not found")
+
+var watchMu sync.Mutex
+
+// EmailSender delivers e-mail notifications; plug in an SMTP client or a
+// mail service.
+type EmailSender interface {
+	SendEmail(ctx context.Context, to, subject, body string) error
+}
+
+// WatchOnlyAccount is the public part of an HD wallet account: its
+// addresses from index 0, without keys.
+type WatchOnlyAccount struct {
+	Account   uint32    `json:"account"`
+	Addresses []Address `json:"addresses"`
+}
+
+// WatchOnly exports the first count addresses of account.
+func (w *HDWallet) WatchOnly(account, count uint32) (*WatchOnlyAccount, error) {
+	if count == 0 {
+		return nil, NewError(CodeInvalidArgument, "watch", "count must be >0")
+	}
+	out := &WatchOnlyAccount{Account: account, Addresses: make([]Address, 0, count)}
+	for i := uint32(0); i < count; i++ {
+		a, err := w.NewAddress(account, i)
+		if err != nil {
+			return nil, err
+		}
+		out.Addresses = append(out.Addresses, a)
+	}
+	return out, nil
+}
+
+// Watch is a set of monitored addresses and how to report on them.
+type Watch struct {
+	ID               string    `json:"id"`
+	Owner            Address   `json:"owner"`
+	Label            string    `json:"label,omitempty"`
+	Addresses        []Address `json:"addresses"`
+	Events           []string  `json:"events"`
+	MinAmount        uint64    `json:"min_amount,omitempty"`
+	BalanceThreshold uint64    `json:"balance_threshold,omitempty"`
+	Webhook          string    `json:"webhook,omitempty"`
+	Email            string    `json:"email,omitempty"`
+	Created          time.Time `json:"created"`
+}
+
+// watchRecord keeps the webhook secret out of API responses.
+type watchRecord struct {
+	Watch
+	Secret string `json:"secret,omitempty"`
+}
+
+// WatchRequest describes a watch to create. Addresses of the watch-only
+// Accounts are added to Addresses. Events defaults to all kinds, balance
+// only when BalanceThreshold is set.
+type WatchRequest struct {
+	Owner            Address            `json:"owner"`
+	Label            string             `json:"label,omitempty"`
+	Addresses        []Address          `json:"addresses"`
+	Accounts         []WatchOnlyAccount `json:"accounts,omitempty"`
+	Events           []string           `json:"events,omitempty"`
+	MinAmount        uint64             `json:"min_amount,omitempty"`
+	BalanceThreshold uint64             `json:"balance_threshold,omitempty"`
+	Webhook          string             `json:"webhook,omitempty"`
+	Secret           string             `json:"secret,omitempty"`
+	Email            string             `json:"email,omitempty"`
+	Nonce            uint64             `json:"nonce"` // the owner's RegistrationNonce
+}
+
+// WatchDelivery is the delivery state of a notification on one channel.
+type WatchDelivery struct {
+	Attempts  int       `json:"attempts"`
+	Next      time.Time `json:"next"`
+	Delivered bool      `json:"delivered"`
+	LastError string    `json:"last_error,omitempty"`
+}
+
+func (d *WatchDelivery) pending() bool {
+	return d != nil && !d.Delivered && d.Attempts < watchDeliveryAttempts
+}
+
+// WatchNotification is one event observed for a watch.
+type WatchNotification struct {
+	ID           string         `json:"id"`
+	WatchID      string         `json:"watch_id"`
+	Type         string         `json:"type"`
+	Address      Address        `json:"address"`
+	Counterparty Address        `json:"counterparty,omitempty"`
+	Token        TokenID        `json:"token,omitempty"` // 0 for the coin
+	Amount       uint64         `json:"amount,omitempty"`
+	Delta        int64          `json:"delta,omitempty"` // balance events
+	TxHash       string         `json:"tx_hash,omitempty"`
+	Height       uint64         `json:"height"`
+	Time         time.Time      `json:"time"`
+	Webhook      *WatchDelivery `json:"webhook,omitempty"`
+	Email        *WatchDelivery `json:"email,omitempty"`
+}
+
+func watchKey(id string) []byte { return []byte("watch:w:" + id) }
+
+func watchNotificationKey(n *WatchNotification) string {
+	d := sha256.Sum256([]byte(n.ID))
+	return fmt.Sprintf("watch:n:%s:%020d:%x", n.WatchID, n.Height, d[:8])
+}
+
+// WatchRequestTypedData returns the document the owner signs to create the
+// watch req describes: its addresses as CreateWatch collects them and the
+// other fields as given. The webhook secret is not part of it.
+func WatchRequestTypedData(req WatchRequest) TypedData {
+	addrs := make([]interface{}, 0, len(req.Addresses))
+	for _, a := range watchAddresses(req) {
+		addrs = append(addrs, a.Hex())
+	}
+	events := make([]interface{}, len(req.Events))
+	for i, e := range req.Events {
+		events[i] = e
+	}
+	return registrationTypedData("WatchRequest", []apitypes.Type{
+		{Name: "owner", Type: "address"},
+		{Name: "label", Type: "string"},
+		{Name: "addresses", Type: "address[]"},
+		{Name: "events", Type: "string[]"},
+		{Name: "minAmount", Type: "uint256"},
+		{Name: "balanceThreshold", Type: "uint256"},
+		{Name: "webhook", Type: "string"},
+		{Name: "email", Type: "string"},
+		{Name: "nonce", Type: "uint256"},
+	}, apitypes.TypedDataMessage{
+		"owner":            req.Owner.Hex(),
+		"label":            req.Label,
+		"addresses":        addrs,
+		"events":           events,
+		"minAmount":        strconv.FormatUint(req.MinAmount, 10),
+		"balanceThreshold": strconv.FormatUint(req.BalanceThreshold, 10),
+		"webhook":          req.Webhook,
+		"email":            req.Email,
+		"nonce":            strconv.FormatUint(req.Nonce, 10),
+	})
+}
+
+// watchAddresses collects the addresses and account addresses of req,
+// without duplicates or the zero address.
+func watchAddresses(req WatchRequest) []Address {
+	seen := make(map[Address]bool)
+	var addrs []Address
+	for _, list := range append([][]Address{req.Addresses}, accountAddresses(req.Accounts)...) {
+		for _, a := range list {
+			if a != AddressZero && !seen[a] {
+				seen[a] = true
+				addrs = append(addrs, a)
+			}
+		}
+	}
+	return addrs
+}
+
+// CreateWatch validates req and stores a new watch. sig is the owner's
+// 96-byte signature ‖ public key over WatchRequestTypedData(req).
+func CreateWatch(req WatchRequest, sig []byte) (*Watch, error) {
+	if req.Owner == AddressZero {
+		return nil, NewError(CodeInvalidArgument, "watch", "owner required")
+	}
+	addrs := watchAddresses(req)
+	if len(addrs) == 0 {
+		return nil, NewError(CodeInvalidArgument, "watch", "at least one address required")
+	}
+	events := req.Events
+	if len(events) == 0 {
+		events = []string{WatchIncoming, WatchOutgoing, WatchContract}
+		if req.BalanceThreshold > 0 {
+			events = append(events, WatchBalance)
+		}
+	}
+	for _, e := range events {
+		switch e {
+		case WatchIncoming, WatchOutgoing, WatchContract:
+		case WatchBalance:
+			if req.BalanceThreshold == 0 {
+				return nil, NewError(CodeInvalidArgument, "watch", "balance events need a balance threshold")
+			}
+		default:
+			return nil, NewError(CodeInvalidArgument, "watch", "unknown event %q", e)
+		}
+	}
+	if req.Webhook != "" {
+		if err := ValidateWebhookURL(req.Webhook); err != nil {
+			return nil, err
+		}
+		if req.Secret == "" {
+			return nil, NewError(CodeInvalidArgument, "watch", "webhook secret required")
+		}
+	}
+	if req.Email != "" && !strings.Contains(req.Email, "@") {
+		return nil, NewError(CodeInvalidArgument, "watch", "invalid e-mail address")
+	}
+	if err := spendRegistration(req.Owner, req.Nonce, WatchRequestTypedData(req), sig); err != nil {
+		return nil, err
+	}
+	rec := &watchRecord{Watch: Watch{
+		ID:               uuid.New().String(),
+		Owner:            req.Owner,
+		Label:            req.Label,
+		Addresses:        addrs,
+		Events:           events,
+		MinAmount:        req.MinAmount,
+		BalanceThreshold: req.BalanceThreshold,
+		Webhook:          req.Webhook,
+		Email:            req.Email,
+		Created:          time.Now().UTC(),
+	}, Secret: req.Secret}
+	watchMu.Lock()
+	defer watchMu.Unlock()
+	if err := saveWatch(rec); err != nil {
+		return nil, err
+	}
+	return &rec.Watch, nil
+}
+
+func accountAddresses(accts []WatchOnlyAccount) [][]Address {
+	out := make([][]Address, len(accts))
+	for i, a := range accts {
+		out[i] = a.Addresses
+	}
+	return out
+}
+
+// GetWatch returns a watch by ID.
+func GetWatch(id string) (*Watch, error) {
+	rec, err := loadWatch(id)
+	if err != nil {
+		return nil, err
+	}
+	return &rec.Watch, nil
+}
+
+// ListWatches returns all watches.
+func ListWatches() ([]Watch, error) {
+	recs, err := loadWatches()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Watch, len(recs))
+	for i, r := range recs {
+		out[i] = r.Watch
+	}
+	return out, nil
+}
+
+// DeleteWatch stops a watch. Its notification history is kept.
+func DeleteWatch(id string) error {
+	watchMu.Lock()
+	defer watchMu.Unlock()
+	if _, err := loadWatch(id); err != nil {
+		return err
+	}
+	return CurrentStore().Delete(watchKey(id))
+}
+
+// WatchNotifications returns the notifications of a watch from height
+// onwards, oldest first, optionally of one event type, at most limit of
+// them when limit > 0.
+func WatchNotifications(watchID, typ string, height uint64, limit int) ([]WatchNotification, error) {
+	it := CurrentStore().Iterator([]byte("watch:n:"+watchID+":"), nil)
+	defer it.Close()
+	var out []WatchNotification
+	for it.Next() {
+		var n WatchNotification
+		if err := json.Unmarshal(it.Value(), &n); err != nil {
+			continue
+		}
+		if n.Height >= height && (typ == "" || n.Type == typ) {
+			out = append(out, n)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Height != out[j].Height {
+			return out[i].Height < out[j].Height
+		}
+		return out[i].ID < out[j].ID
+	})
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out, it.Error()
+}
+
+func loadWatch(id string) (*watchRecord, error) {
+	raw, err := CurrentStore().Get(watchKey(id))
+	if err != nil || raw == nil {
+		return nil, fmt.Errorf("%w: %s", ErrWatchNotFound, id)
+	}
+	var rec watchRecord
+	if err := json.Unmarshal(raw, &rec); err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
+
+func loadWatches() ([]*watchRecord, error) {
+	it := CurrentStore().Iterator([]byte("watch:w:"), nil)
+	defer it.Close()
+	var out []*watchRecord
+	for it.Next() {
+		var rec watchRecord
+		if err := json.Unmarshal(it.Value(), &rec); err != nil {
+			continue
+		}
+		out = append(out, &rec)
+	}
+	return out, it.Error()
+}
+
+func saveWatch(rec *watchRecord) error {
+	data, _ := json.Marshal(rec)
+	return CurrentStore().Set(watchKey(rec.ID), data)
+}
+
+func (w *Watch) wants(event string) bool {
+	for _, e := range w.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// AddressMonitor matches new blocks against the watches and delivers the
+// notifications.
+type AddressMonitor struct {
+	src    BlockSource
+	email  EmailSender
+	client *http.Client
+	logger *logrus.Entry
+}
+
+// NewAddressMonitor returns a monitor reading blocks from src. email may be
+// nil, in which case e-mail notifications are recorded but not sent.
+func NewAddressMonitor(src BlockSource, email EmailSender) *AddressMonitor {
+	return &AddressMonitor{
+		src:    src,
+		email:  email,
+		client: newWebhookClient(),
+		logger: ModuleLogger("watch"),
+	}
+}
+
+// Start scans and delivers every interval until ctx is cancelled.
+func (m *AddressMonitor) Start(ctx context.Context, interval time.Duration) {
+	go func() {
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-t.C:
+				if err := m.Scan(); err != nil {
+					m.logger.Warnf("scan: %v", err)
+				}
+				m.Deliver(ctx, now.UTC())
+			}
+		}
+	}()
+}
+
+// Scan records the notifications of the blocks added since the last scan.
+// The first scan starts at the head.
+func (m *AddressMonitor) Scan() error {
+	watchMu.Lock()
+	defer watchMu.Unlock()
+	head := m.src.LastHeight()
+	from := head
+	if raw, err := CurrentStore().Get([]byte("watch:scan")); err == nil && len(raw) == 8 {
+		from = binary.BigEndian.Uint64(raw) + 1
+	}
+	if from > head {
+		return nil
+	}
+	watches, err := loadWatches()
+	if err != nil {
+		return err
+	}
+	byAddr := make(map[Address][]*watchRecord)
+	for _, w := range watches {
+		for _, a := range w.Addresses {
+			byAddr[a] = append(byAddr[a], w)
+		}
+	}
+	for h := from; h <= head; h++ {
+		b, err := m.src.GetBlock(h)
+		if err != nil {
+			return fmt.Errorf("block %d: %w", h, err)
+		}
+		if len(byAddr) > 0 {
+			m.matchBlock(b, byAddr)
+		}
+		if err := CurrentStore().Set([]byte("watch:scan"), uint64ToBytes(h)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *AddressMonitor) matchBlock(b *Block, byAddr map[Address][]*watchRecord) {
+	at := time.UnixMilli(b.Header.Timestamp).UTC()
+	delta := make(map[Address]int64)
+	for _, tx := range b.Transactions {
+		hash := tx.IDHex()
+		base := WatchNotification{TxHash: hash, Height: b.Header.Height, Time: at}
+		transfer := func(from, to Address, token TokenID, amt uint64) {
+			for _, w := range byAddr[to] {
+				if w.wants(WatchIncoming) && amt >= w.MinAmount {
+					n := base
+					n.Type, n.Address, n.Counterparty, n.Token, n.Amount = WatchIncoming, to, from, token, amt
+					m.record(w, &n)
+				}
+			}
+			for _, w := range byAddr[from] {
+				if w.wants(WatchOutgoing) && amt >= w.MinAmount {
+					n := base
+					n.Type, n.Address, n.Counterparty, n.Token, n.Amount = WatchOutgoing, from, to, token, amt
+					m.record(w, &n)
+				}
+			}
+		}
+		if tx.Value > 0 {
+			transfer(tx.From, tx.To, 0, tx.Value)
+			delta[tx.To] += int64(tx.Value)
+			delta[tx.From] -= int64(tx.Value)
+		}
+		for _, tr := range tx.TokenTransfers {
+			if tr.Amount > 0 {
+				transfer(tr.From, tr.To, tr.Token, tr.Amount)
+			}
+		}
+		if tx.Type == TxContractCall {
+			for _, a := range []Address{tx.From, tx.To} {
+				for _, w := range byAddr[a] {
+					if w.wants(WatchContract) {
+						n := base
+						n.Type, n.Address, n.Amount = WatchContract, a, tx.Value
+						if a == tx.From {
+							n.Counterparty = tx.To
+						} else {
+							n.Counterparty = tx.From
+						}
+						m.record(w, &n)
+					}
+				}
+			}
+		}
+	}
+	for a, d := range delta {
+		abs := d
+		if abs < 0 {
+			abs = -abs
+		}
+		for _, w := range byAddr[a] {
+			if w.wants(WatchBalance) && uint64(abs) >= w.BalanceThreshold {
+				n := WatchNotification{Type: WatchBalance, Address: a, Delta: d, Height: b.Header.Height, Time: at}
+				m.record(w, &n)
+			}
+		}
+	}
+}
+
+// record stores n for w unless it was recorded before, and queues its
+// deliveries.
+func (m *AddressMonitor) record(w *watchRecord, n *WatchNotification) {
+	n.WatchID = w.ID
+	n.ID = fmt.Sprintf("%s:%d:%s:%s:%s", w.ID, n.Height, n.TxHash, n.Type, n.Address.Hex())
+	if n.Type != WatchBalance {
+		n.ID += fmt.Sprintf(":%s:%d:%d", n.Counterparty.Hex(), n.Token, n.Amount)
+	}
+	key := watchNotificationKey(n)
+	if raw, err := CurrentStore().Get([]byte(key)); err == nil && raw != nil {
+		return
+	}
+	if w.Webhook != "" {
+		n.Webhook = &WatchDelivery{Next: n.Time}
+	}
+	if w.Email != "" {
+		n.Email = &WatchDelivery{Next: n.Time}
+	}
+	if err := m.save(key, n); err != nil {
+		m.logger.Warnf("watch %s: %v", w.ID, err)
+		return
+	}
+	Broadcast("watch:"+n.Type, mustJSON(n))
+}
+
+func (m *AddressMonitor) save(key string, n *WatchNotification) error {
+	raw, _ := json.Marshal(n)
+	if err := CurrentStore().Set([]byte(key), raw); err != nil {
+		return err
+	}
+	if n.Webhook.pending() || n.Email.pending() {
+		return CurrentStore().Set([]byte("watch:pending:"+key), []byte{1})
+	}
+	return CurrentStore().Delete([]byte("watch:pending:" + key))
+}
+
+// Deliver sends the notifications due at now over each channel of their
+// watch. Failed deliveries are retried with exponential backoff up to
+// watchDeliveryAttempts times.
+func (m *AddressMonitor) Deliver(ctx context.Context, now time.Time) {
+	it := CurrentStore().Iterator([]byte("watch:pending:"), nil)
+	var keys []string
+	for it.Next() {
+		keys = append(keys, strings.TrimPrefix(string(it.Key()), "watch:pending:"))
+	}
+	it.Close()
+	for _, key := range keys {
+		raw, err := CurrentStore().Get([]byte(key))
+		if err != nil || raw == nil {
+			_ = CurrentStore().Delete([]byte("watch:pending:" + key))
+			continue
+		}
+		var n WatchNotification
+		if err := json.Unmarshal(raw, &n); err != nil {
+			continue
+		}
+		w, err := loadWatch(n.WatchID)
+		if err != nil {
+			// deleted watches are not notified any more
+			n.Webhook, n.Email = nil, nil
+			_ = m.save(key, &n)
+			continue
+		}
+		body, _ := json.Marshal(n)
+		m.attempt(n.Webhook, now, func() error {
+			return postWebhook(ctx, m.client, w.Webhook, w.Secret, n.ID, "watch."+n.Type, body, now)
+		})
+		m.attempt(n.Email, now, func() error {
+			if m.email == nil {
+				return errors.New("no e-mail sender configured")
+			}
+			return m.email.SendEmail(ctx, w.Email, watchSubject(&w.Watch, &n), string(body))
+		})
+		if err := m.save(key, &n); err != nil {
+			m.logger.Warnf("watch %s: %v", n.WatchID, err)
+		}
+	}
+}
+
+func (m *AddressMonitor) attempt(d *WatchDelivery, now time.Time, send func() error) {
+	if !d.pending() || now.Before(d.Next) {
+		return
+	}
+	d.Attempts++
+	if err := send(); err != nil {
+		d.LastError = err.Error()
+		d.Next = now.Add(time.Duration(1<<uint(d.Attempts-1)) * 10 * time.Second)
+		m.logger.Warnf("delivery attempt %d: %v", d.Attempts, err)
+		return
+	}
+	d.Delivered, d.LastError = true, ""
+}
+
+func watchSubject(w *Watch, n *WatchNotification) string {
+	name := w.Label
+	if name == "" {
+		name = w.ID
+	}
+	return fmt.Sprintf("[%s] %s %s at height %d", name, n.Type, n.Address.Short(), n.Height)
+}
@@ -0,0 +1,216 @@
+package core
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+type testMailer struct {
+	sent []string
+	fail bool
+}
+
+func (m *testMailer) SendEmail(_ context.Context, to, subject, _ string) error {
+	if m.fail {
+		return errors.New("relay down")
+	}
+	m.sent = append(m.sent, to+" "+subject)
+	return nil
+}
+
+func TestCreateWatchRequiresOwnerSignature(t *testing.T) {
+	priv, owner := newInvoiceTest(t)
+	_, other, _ := ed25519.GenerateKey(nil)
+	req := WatchRequest{Owner: owner, Addresses: []Address{{0xa1}}, Webhook: "https://ops.example.com/hook", Secret: "k"}
+
+	if _, err := CreateWatch(req, signRegistration(t, other, WatchRequestTypedData(req))); !errors.Is(err, ErrRegistrationSig) {
+		t.Fatalf("request signed by another key: %v", err)
+	}
+	widened := req
+	widened.Addresses = append(widened.Addresses, Address{0xa2})
+	if _, err := CreateWatch(widened, signRegistration(t, priv, WatchRequestTypedData(req))); !errors.Is(err, ErrRegistrationSig) {
+		t.Fatalf("request with addresses the owner did not sign: %v", err)
+	}
+	for _, hook := range []string{"http://127.0.0.1:9000/", "http://10.0.0.5/hook", "http://[fe80::1]/"} {
+		internal := req
+		internal.Webhook = hook
+		if _, err := CreateWatch(internal, signRegistration(t, priv, WatchRequestTypedData(internal))); !errors.Is(err, ErrWebhookTarget) {
+			t.Fatalf("signed request for webhook %s: %v", hook, err)
+		}
+	}
+	if _, err := CreateWatch(WatchRequest{Addresses: req.Addresses}, nil); ErrorCodeOf(err) != CodeInvalidArgument {
+		t.Fatalf("watch without owner: %v", err)
+	}
+
+	// addresses from watch-only accounts are signed as CreateWatch collects them
+	req.Accounts = []WatchOnlyAccount{{Addresses: []Address{{0xa1}, {0xa3}}}}
+	w, err := CreateWatch(req, signRegistration(t, priv, WatchRequestTypedData(req)))
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if w.Owner != owner || len(w.Addresses) != 2 || len(w.Events) != 3 {
+		t.Fatalf("watch %+v", w)
+	}
+	got, err := GetWatch(w.ID)
+	if err != nil || got.Owner != owner {
+		t.Fatalf("get: %+v %v", got, err)
+	}
+	if raw, _ := json.Marshal(got); containsSecret(raw) {
+		t.Fatalf("watch response leaks the webhook secret: %s", raw)
+	}
+}
+
+func TestAddressMonitorRecordsAndDelivers(t *testing.T) {
+	priv, owner := newInvoiceTest(t)
+	start := time.Now().UTC()
+	chain := &testChain{}
+	chain.add(start)
+
+	hot, cold, stranger := Address{0xb1}, Address{0xb2}, Address{0xb3}
+	req := WatchRequest{Owner: owner, Addresses: []Address{hot, cold}, MinAmount: 10, BalanceThreshold: 500,
+		Webhook: "https://ops.example.com/hook", Secret: "whsec", Email: "ops@example.com"}
+	w, err := CreateWatch(req, signRegistration(t, priv, WatchRequestTypedData(req)))
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	hooks, mail := &hookRecorder{status: 200}, &testMailer{}
+	m := NewAddressMonitor(chain, mail)
+	m.client = hooks.client()
+	if err := m.Scan(); err != nil {
+		t.Fatalf("first scan: %v", err)
+	}
+
+	chain.add(start,
+		&Transaction{Hash: Hash{1}, From: stranger, To: hot, Value: 600},
+		&Transaction{Hash: Hash{2}, From: hot, To: cold, Value: 5}, // below MinAmount
+		&Transaction{Hash: Hash{3}, From: cold, To: stranger, Value: 20, Type: TxContractCall},
+		&Transaction{Hash: Hash{4}, From: stranger, To: stranger, Value: 1_000},
+	)
+	if err := m.Scan(); err != nil {
+		t.Fatalf("scan: %v", err)
+	}
+	// rescanning the same block records nothing twice
+	_ = CurrentStore().Set([]byte("watch:scan"), uint64ToBytes(0))
+	_ = m.Scan()
+
+	list, err := WatchNotifications(w.ID, "", 0, 0)
+	if err != nil {
+		t.Fatalf("notifications: %v", err)
+	}
+	count := make(map[string]int)
+	for _, n := range list {
+		count[n.Type]++
+		if n.Webhook == nil || n.Email == nil {
+			t.Fatalf("notification %s without deliveries", n.ID)
+		}
+	}
+	// incoming 600 to hot; outgoing 20 from cold; the call from cold;
+	// hot's +595 balance change
+	if len(list) != 4 || count[WatchIncoming] != 1 || count[WatchOutgoing] != 1 ||
+		count[WatchContract] != 1 || count[WatchBalance] != 1 {
+		t.Fatalf("notifications %v", count)
+	}
+	if only, _ := WatchNotifications(w.ID, WatchBalance, 0, 0); len(only) != 1 || only[0].Address != hot || only[0].Delta != 595 {
+		t.Fatalf("balance notifications %+v", only)
+	}
+
+	m.Deliver(context.Background(), start)
+	m.Deliver(context.Background(), start.Add(time.Hour))
+	if len(hooks.calls) != 4 || len(mail.sent) != 4 {
+		t.Fatalf("%d webhook calls and %d e-mails, want 4 each", len(hooks.calls), len(mail.sent))
+	}
+	for _, c := range hooks.calls {
+		if err := VerifyWebhook("whsec", c.header.Get("X-Synnergy-Timestamp"), c.header.Get("X-Synnergy-Signature"), c.body, time.Hour); err != nil {
+			t.Fatalf("webhook signature: %v", err)
+		}
+	}
+	list, _ = WatchNotifications(w.ID, "", 0, 0)
+	for _, n := range list {
+		if !n.Webhook.Delivered || !n.Email.Delivered {
+			t.Fatalf("notification %s not marked delivered: %+v %+v", n.ID, n.Webhook, n.Email)
+		}
+	}
+}
+
+func TestAddressMonitorRetriesAndStopsForDeletedWatches(t *testing.T) {
+	priv, owner := newInvoiceTest(t)
+	start := time.Now().UTC()
+	chain := &testChain{}
+	chain.add(start)
+
+	req := WatchRequest{Owner: owner, Addresses: []Address{{0xc1}}, Events: []string{WatchIncoming}, Email: "ops@example.com"}
+	w, err := CreateWatch(req, signRegistration(t, priv, WatchRequestTypedData(req)))
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	mail := &testMailer{fail: true}
+	m := NewAddressMonitor(chain, mail)
+	_ = m.Scan()
+	chain.add(start, &Transaction{Hash: Hash{1}, From: Address{0xc2}, To: Address{0xc1}, Value: 1})
+	chain.add(start, &Transaction{Hash: Hash{2}, From: Address{0xc2}, To: Address{0xc1}, Value: 2})
+	_ = m.Scan()
+
+	m.Deliver(context.Background(), start)
+	list, _ := WatchNotifications(w.ID, "", 0, 0)
+	if len(list) != 2 || list[0].Email.Attempts != 1 || list[0].Email.LastError == "" {
+		t.Fatalf("after a failed delivery: %+v", list)
+	}
+	next := list[0].Email.Next
+	if !next.After(start) {
+		t.Fatalf("retry not backed off: next %v", next)
+	}
+	mail.fail = false
+	m.Deliver(context.Background(), next.Add(-time.Millisecond))
+	if len(mail.sent) != 0 {
+		t.Fatal("retried before the backoff elapsed")
+	}
+	m.Deliver(context.Background(), next)
+	if len(mail.sent) != 2 {
+		t.Fatalf("%d e-mails after the relay recovered", len(mail.sent))
+	}
+
+	// notifications of a deleted watch are dropped, not delivered
+	chain.add(start, &Transaction{Hash: Hash{3}, From: Address{0xc2}, To: Address{0xc1}, Value: 3})
+	_ = m.Scan()
+	if err := DeleteWatch(w.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	m.Deliver(context.Background(), start.Add(time.Hour))
+	if len(mail.sent) != 2 {
+		t.Fatalf("deleted watch notified: %v", mail.sent)
+	}
+	if _, err := GetWatch(w.ID); !errors.Is(err, ErrWatchNotFound) {
+		t.Fatalf("get deleted watch: %v", err)
+	}
+	if hist, _ := WatchNotifications(w.ID, "", 0, 0); len(hist) != 3 {
+		t.Fatalf("history of deleted watch: %d notifications", len(hist))
+	}
+}
+
+func TestRegistrationNonceIsSharedAndSpentOnce(t *testing.T) {
+	SetStore(NewInMemoryStore())
+	t.Cleanup(func() { SetStore(nil) })
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	owner := pubKeyToAddress(pub)
+
+	inv := InvoiceRequest{Merchant: owner, Amount: 5, Nonce: RegistrationNonce(owner)}
+	if _, err := CreateInvoice(inv, signRegistration(t, priv, InvoiceRequestTypedData(inv))); err != nil {
+		t.Fatalf("invoice: %v", err)
+	}
+	// a watch signed with the nonce the invoice used is a replay
+	w := WatchRequest{Owner: owner, Addresses: []Address{{1}}, Nonce: 0}
+	if _, err := CreateWatch(w, signRegistration(t, priv, WatchRequestTypedData(w))); !errors.Is(err, ErrRegistrationNonce) {
+		t.Fatalf("stale nonce: %v", err)
+	}
+	w.Nonce = RegistrationNonce(owner)
+	if w.Nonce != 1 {
+		t.Fatalf("nonce after one registration = %d", w.Nonce)
+	}
+	if _, err := CreateWatch(w, signRegistration(t, priv, WatchRequestTypedData(w))); err != nil {
+		t.Fatalf("watch: %v", err)
+	}
+}
@@ -0,0 +1,499 @@
+package core
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AdminRPC serves operator methods over JSON-RPC 2.0 on a listener separate
+// from the public API, so a running node can be inspected and adjusted
+// without a restart:
+//
+//	admin_addPeer(multiaddr)                connect to a peer
+//	admin_removePeer(peerID)                drop a peer
+//	admin_peers()                           connected peers
+//	admin_nodeInfo()                        identity, listen addresses, height, version
+//	txpool_content()                        pending and queued pool transactions by sender
+//	debug_traceTransaction(hash, {tracer})  effects and VM steps of an included transaction
+//
+// Every request must carry the configured bearer token, and every call is
+// written to the audit trail before it runs; calls are refused when the
+// audit entry cannot be written.
+type AdminRPC struct {
+	node   *Node
+	ledger *Ledger
+	token  string
+	audit  *AuditTrail
+
+	srv *http.Server
+	mu  sync.Mutex
+}
+
+// AdminConfig configures an AdminRPC. Token is required. Audit receives
+// one entry per call; when nil, the trail of the global audit manager is
+// used.
+type AdminConfig struct {
+	Token string
+	Audit *AuditTrail
+}
+
+// NewAdminRPC creates the admin server for a node and ledger.
+func NewAdminRPC(n *Node, led *Ledger, cfg AdminConfig) (*AdminRPC, error) {
+	if cfg.Token == "" {
+		return nil, NewError(CodeInvalidArgument, "admin", "admin RPC requires a token")
+	}
+	if cfg.Audit == nil {
+		if am := AuditManagerInstance(); am != nil {
+			cfg.Audit = am.trail
+		}
+	}
+	if cfg.Audit == nil {
+		return nil, NewError(CodeFailedPrecondition, "admin", "admin RPC requires an audit trail")
+	}
+	return &AdminRPC{node: n, ledger: led, token: cfg.Token, audit: cfg.Audit}, nil
+}
+
+// Start serves the admin API on addr, which should not be reachable from
+// the public network.
+func (a *AdminRPC) Start(addr string) error {
+	a.mu.Lock()
+	a.srv = &http.Server{
+		Addr:         addr,
+		Handler:      RequestLogger("admin")(a),
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 30 * time.Second,
+	}
+	srv := a.srv
+	a.mu.Unlock()
+	return srv.ListenAndServe()
+}
+
+// Stop shuts the admin listener down.
+func (a *AdminRPC) Stop() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.srv == nil {
+		return nil
+	}
+	return a.srv.Shutdown(context.Background())
+}
+
+// JSON-RPC 2.0 error codes.
+const (
+	rpcParseError     = -32700
+	rpcInvalidRequest = -32600
+	rpcMethodNotFound = -32601
+	rpcInvalidParams  = -32602
+	rpcServerError    = -32000
+)
+
+// RPCRequest is a JSON-RPC 2.0 request.
+type RPCRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// RPCError is the error member of a JSON-RPC response. Data carries the
+// structured error of the shared envelope.
+type RPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    *Error `json:"data,omitempty"`
+}
+
+func (e *RPCError) Error() string { return fmt.Sprintf("rpc error %d: %s", e.Code, e.Message) }
+
+// RPCResponse is a JSON-RPC 2.0 response.
+type RPCResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *RPCError       `json:"error,omitempty"`
+}
+
+type adminMethod func(a *AdminRPC, params json.RawMessage) (interface{}, error)
+
+var adminMethods = map[string]adminMethod{
+	"admin_addPeer":          (*AdminRPC).addPeer,
+	"admin_removePeer":       (*AdminRPC).removePeer,
+	"admin_peers":            (*AdminRPC).peers,
+	"admin_nodeInfo":         (*AdminRPC).nodeInfo,
+	"txpool_content":         (*AdminRPC).txpoolContent,
+	"debug_traceTransaction": (*AdminRPC).traceTransaction,
+}
+
+// ServeHTTP authenticates the caller and answers a single request or a
+// batch of them.
+func (a *AdminRPC) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte("Bearer "+a.token)) != 1 {
+		WriteHTTPError(w, http.StatusUnauthorized, "admin", NewError(CodeUnauthenticated, "admin", "admin token required"))
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, 1<<20))
+	if err != nil {
+		WriteHTTPError(w, http.StatusRequestEntityTooLarge, "admin", err)
+		return
+	}
+	body = []byte(strings.TrimSpace(string(body)))
+	if len(body) > 0 && body[0] == '[' {
+		var reqs []RPCRequest
+		if err := json.Unmarshal(body, &reqs); err != nil || len(reqs) == 0 {
+			writeJSON(w, rpcFailure(nil, rpcParseError, "invalid batch", nil))
+			return
+		}
+		out := make([]RPCResponse, len(reqs))
+		for i := range reqs {
+			out[i] = a.call(r, &reqs[i])
+		}
+		writeJSON(w, out)
+		return
+	}
+	var req RPCRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		writeJSON(w, rpcFailure(nil, rpcParseError, err.Error(), nil))
+		return
+	}
+	writeJSON(w, a.call(r, &req))
+}
+
+func rpcFailure(id json.RawMessage, code int, msg string, err error) RPCResponse {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	e := &RPCError{Code: code, Message: msg}
+	if err != nil {
+		e.Data = ClassifyError(err)
+	}
+	return RPCResponse{JSONRPC: "2.0", ID: id, Error: e}
+}
+
+// call audits and dispatches one request.
+func (a *AdminRPC) call(r *http.Request, req *RPCRequest) RPCResponse {
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		return rpcFailure(req.ID, rpcInvalidRequest, "invalid request", nil)
+	}
+	fn, ok := adminMethods[req.Method]
+	if !ok {
+		return rpcFailure(req.ID, rpcMethodNotFound, "method not found: "+req.Method, nil)
+	}
+	params := string(req.Params)
+	if len(params) > 512 {
+		params = params[:512] + "…"
+	}
+	meta := map[string]string{
+		"method":     req.Method,
+		"params":     params,
+		"remote":     r.RemoteAddr,
+		"request_id": RequestIDFrom(r.Context()),
+	}
+	if err := a.audit.Log("admin_rpc", meta); err != nil {
+		ContextLogger(r.Context(), "admin").WithError(err).Error("audit trail unavailable, refusing admin call")
+		return rpcFailure(req.ID, rpcServerError, "audit trail unavailable", NewError(CodeUnavailable, "admin", "audit trail unavailable: %v", err))
+	}
+	ContextLogger(r.Context(), "admin").WithField("method", req.Method).Info("admin call")
+	res, err := fn(a, req.Params)
+	if err != nil {
+		code := rpcServerError
+		if e := ClassifyError(err); e.Code == CodeInvalidArgument {
+			code = rpcInvalidParams
+		}
+		return rpcFailure(req.ID, code, err.Error(), err)
+	}
+	if req.ID == nil {
+		req.ID = json.RawMessage("null")
+	}
+	if res == nil {
+		res = true
+	}
+	return RPCResponse{JSONRPC: "2.0", ID: req.ID, Result: res}
+}
+
+// stringParam decodes the single string positional parameter of a method.
+func stringParam(params json.RawMessage, name string) (string, error) {
+	var p []string
+	if err := json.Unmarshal(params, &p); err != nil || len(p) != 1 || p[0] == "" {
+		return "", NewError(CodeInvalidArgument, "admin", "expected params [%s]", name)
+	}
+	return p[0], nil
+}
+
+func (a *AdminRPC) requireNode() error {
+	if a.node == nil {
+		return NewError(CodeFailedPrecondition, "admin", "no network node attached")
+	}
+	return nil
+}
+
+func (a *AdminRPC) addPeer(params json.RawMessage) (interface{}, error) {
+	addr, err := stringParam(params, "multiaddr")
+	if err != nil {
+		return nil, err
+	}
+	if err := a.requireNode(); err != nil {
+		return nil, err
+	}
+	if err := NewPeerManagement(a.node).Connect(addr); err != nil {
+		return nil, NewError(CodeUnavailable, "admin", "connect %s: %v", addr, err)
+	}
+	return true, nil
+}
+
+func (a *AdminRPC) removePeer(params json.RawMessage) (interface{}, error) {
+	id, err := stringParam(params, "peerID")
+	if err != nil {
+		return nil, err
+	}
+	if err := a.requireNode(); err != nil {
+		return nil, err
+	}
+	if err := NewPeerManagement(a.node).Disconnect(NodeID(id)); err != nil {
+		return nil, NewError(CodeInvalidArgument, "admin", "disconnect %s: %v", id, err)
+	}
+	return true, nil
+}
+
+// AdminPeer is a connected peer as reported by admin_peers.
+type AdminPeer struct {
+	ID        string `json:"id"`
+	Addr      string `json:"addr"`
+	LatencyMs int64  `json:"latency_ms"`
+}
+
+func (a *AdminRPC) peers(json.RawMessage) (interface{}, error) {
+	if err := a.requireNode(); err != nil {
+		return nil, err
+	}
+	out := []AdminPeer{}
+	for _, p := range a.node.Peers() {
+		out = append(out, AdminPeer{ID: string(p.ID), Addr: p.Addr, LatencyMs: p.Latency.Milliseconds()})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
+
+// AdminNodeInfo is the result of admin_nodeInfo.
+type AdminNodeInfo struct {
+	ID          string   `json:"id,omitempty"`
+	ListenAddrs []string `json:"listen_addrs,omitempty"`
+	Peers       int      `json:"peers"`
+	Height      uint64   `json:"height"`
+	PoolSize    int      `json:"pool_size"`
+	Version     string   `json:"version"`
+}
+
+func (a *AdminRPC) nodeInfo(json.RawMessage) (interface{}, error) {
+	info := AdminNodeInfo{Version: NodeVersion}
+	if a.node != nil {
+		info.ID = a.node.host.ID().String()
+		for _, ma := range a.node.host.Addrs() {
+			info.ListenAddrs = append(info.ListenAddrs, ma.String()+"/p2p/"+info.ID)
+		}
+		info.Peers = len(a.node.Peers())
+	}
+	if a.ledger != nil {
+		info.Height = a.ledger.LastHeight()
+		info.PoolSize = len(a.ledger.ListPool(0))
+	}
+	return info, nil
+}
+
+// TxPoolContent is the result of txpool_content: pool transactions keyed by
+// sender and nonce. Pending transactions continue the sender's account
+// nonce without gaps; the rest are queued until the gap is filled.
+type TxPoolContent struct {
+	Pending map[string]map[uint64]*Transaction `json:"pending"`
+	Queued  map[string]map[uint64]*Transaction `json:"queued"`
+}
+
+func (a *AdminRPC) txpoolContent(json.RawMessage) (interface{}, error) {
+	if a.ledger == nil {
+		return nil, NewError(CodeFailedPrecondition, "admin", "ledger not initialised")
+	}
+	bySender := make(map[Address][]*Transaction)
+	for _, tx := range a.ledger.ListPool(0) {
+		bySender[tx.From] = append(bySender[tx.From], tx)
+	}
+	out := TxPoolContent{
+		Pending: make(map[string]map[uint64]*Transaction),
+		Queued:  make(map[string]map[uint64]*Transaction),
+	}
+	for from, txs := range bySender {
+		sort.Slice(txs, func(i, j int) bool { return txs[i].Nonce < txs[j].Nonce })
+		next := a.ledger.NonceOf(from)
+		key := from.Hex()
+		for _, tx := range txs {
+			dst := out.Queued
+			if tx.Nonce == next {
+				dst = out.Pending
+				next++
+			}
+			if dst[key] == nil {
+				dst[key] = make(map[uint64]*Transaction)
+			}
+			dst[key][tx.Nonce] = tx
+		}
+	}
+	return out, nil
+}
+
+// TxTrace is the result of debug_traceTransaction: the effects an included
+// transaction had on the ledger, in the order they were applied, and for
+// contract calls the VM trace of re-executing it against the state before
+// its block.
+type TxTrace struct {
+	Hash   string      `json:"hash"`
+	Height uint64      `json:"height"`
+	Index  int         `json:"index"`
+	Type   TxType      `json:"type"`
+	From   string      `json:"from"`
+	To     string      `json:"to"`
+	Steps  []TraceStep `json:"steps"`
+	// Tracer names the tracer that produced VM, see NewTracer.
+	Tracer string      `json:"tracer,omitempty"`
+	VM     interface{} `json:"vm,omitempty"`
+	// Archive reports whether prior state was available; without an
+	// archive, state writes lack their before values and calls are not
+	// re-executed.
+	Archive bool `json:"archive"`
+}
+
+// TraceStep is one ledger effect of a transaction.
+type TraceStep struct {
+	Op     string `json:"op"` // utxo_spend | utxo_create | state_write | contract_deploy | token_transfer | fee
+	Key    string `json:"key,omitempty"`
+	Before string `json:"before,omitempty"` // hex
+	After  string `json:"after,omitempty"`  // hex
+	From   string `json:"from,omitempty"`
+	To     string `json:"to,omitempty"`
+	Amount uint64 `json:"amount,omitempty"`
+}
+
+// TraceOptions is the optional second parameter of debug_traceTransaction.
+type TraceOptions struct {
+	Tracer string `json:"tracer,omitempty"` // structLogger (default) | callTracer
+}
+
+func (a *AdminRPC) traceTransaction(params json.RawMessage) (interface{}, error) {
+	var p []json.RawMessage
+	var s string
+	if err := json.Unmarshal(params, &p); err != nil || len(p) < 1 || len(p) > 2 || json.Unmarshal(p[0], &s) != nil {
+		return nil, NewError(CodeInvalidArgument, "admin", "expected params [hash, {tracer}]")
+	}
+	var opts TraceOptions
+	if len(p) == 2 {
+		if err := json.Unmarshal(p[1], &opts); err != nil {
+			return nil, NewError(CodeInvalidArgument, "admin", "invalid trace options: %v", err)
+		}
+	}
+	raw, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil || len(raw) != len(Hash{}) {
+		return nil, NewError(CodeInvalidArgument, "admin", "invalid transaction hash")
+	}
+	tracer, err := NewTracer(opts.Tracer)
+	if err != nil {
+		return nil, err
+	}
+	if a.ledger == nil {
+		return nil, NewError(CodeFailedPrecondition, "admin", "ledger not initialised")
+	}
+	var h Hash
+	copy(h[:], raw)
+	tx, loc, err := a.findTx(h)
+	if err != nil {
+		return nil, err
+	}
+	t, err := a.ledger.TraceTx(tx, loc, tracer)
+	if err != nil {
+		return nil, err
+	}
+	if t.VM != nil {
+		t.Tracer = opts.Tracer
+		if t.Tracer == "" {
+			t.Tracer = TracerStructLogger
+		}
+	}
+	return t, nil
+}
+
+// findTx locates an included transaction through the tx index, falling
+// back to scanning the retained chain.
+func (a *AdminRPC) findTx(h Hash) (*Transaction, TxLocation, error) {
+	if tx, loc, err := a.ledger.LookupTx(h); err == nil {
+		return tx, loc, nil
+	}
+	a.ledger.mu.RLock()
+	defer a.ledger.mu.RUnlock()
+	for i := len(a.ledger.Blocks) - 1; i >= 0; i-- {
+		blk := a.ledger.Blocks[i]
+		for j, tx := range blk.Transactions {
+			if tx.ID() == h {
+				return tx, TxLocation{Height: blk.Header.Height, Index: j}, nil
+			}
+		}
+	}
+	return nil, TxLocation{}, NewError(CodeNotFound, "admin", "transaction %x not found", h)
+}
+
+// TraceTx reconstructs the effects of tx, included at loc, as applied by
+// applyBlock. On archive nodes state writes are annotated with their prior
+// values and contract calls are re-executed in a sandbox over the state
+// before the block, reporting every VM step to tracer when it is non-nil.
+func (l *Ledger) TraceTx(tx *Transaction, loc TxLocation, tracer VMTracer) (*TxTrace, error) {
+	t := &TxTrace{
+		Hash: tx.ID().Hex(), Height: loc.Height, Index: loc.Index, Type: tx.Type,
+		From: tx.From.Hex(), To: tx.To.Hex(), Steps: []TraceStep{},
+	}
+	var prior *StateView
+	if loc.Height > 0 {
+		if v, err := l.StateAt(loc.Height - 1); err == nil {
+			prior, t.Archive = v, true
+		}
+	}
+	for _, in := range tx.Inputs {
+		t.Steps = append(t.Steps, TraceStep{Op: "utxo_spend", Key: utxoKey(in.TxID, in.Index)})
+	}
+	for i, out := range tx.Outputs {
+		t.Steps = append(t.Steps, TraceStep{Op: "utxo_create", Key: utxoKey(tx.ID(), uint32(i)), Amount: out.Amount})
+	}
+	for _, k := range sortedKeys(tx.StateChanges) {
+		st := TraceStep{Op: "state_write", Key: k, After: hex.EncodeToString(tx.StateChanges[k])}
+		if prior != nil {
+			if v, err := prior.GetState([]byte(k)); err == nil {
+				st.Before = hex.EncodeToString(v)
+			}
+		}
+		t.Steps = append(t.Steps, st)
+	}
+	if tx.Contract != nil {
+		t.Steps = append(t.Steps, TraceStep{Op: "contract_deploy", Key: hex.EncodeToString(tx.Contract.Address[:])})
+	}
+	for _, tr := range tx.TokenTransfers {
+		t.Steps = append(t.Steps, TraceStep{Op: "token_transfer", From: tr.From.Hex(), To: tr.To.Hex(), Amount: tr.Amount})
+	}
+	if fee := tx.GasLimit * tx.GasPrice; fee > 0 {
+		t.Steps = append(t.Steps, TraceStep{Op: "fee", From: tx.From.Hex(), Amount: fee})
+	}
+	if tx.Type == TxContractCall && prior != nil && tracer != nil {
+		// Execution failures are part of the trace, not an error of the
+		// trace itself.
+		_, _ = prior.TraceCall(tx.From, tx.To, tx.Payload, new(big.Int).SetUint64(tx.Value), tx.GasLimit, tracer)
+		t.VM = tracer.Result()
+	}
+	return t, nil
+}
@@ -0,0 +1,168 @@
+package core
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newTestAdminRPC(t *testing.T) (*AdminRPC, *AuditTrail, *Ledger) {
+	t.Helper()
+	cfg, cleanup := tmpLedgerConfig(t, nil)
+	t.Cleanup(cleanup)
+	led, err := NewLedger(cfg)
+	if err != nil {
+		t.Fatalf("NewLedger: %v", err)
+	}
+	trail, err := NewAuditTrail(filepath.Join(t.TempDir(), "audit.log"), nil)
+	if err != nil {
+		t.Fatalf("NewAuditTrail: %v", err)
+	}
+	t.Cleanup(func() { trail.Close() })
+	a, err := NewAdminRPC(nil, led, AdminConfig{Token: "secret", Audit: trail})
+	if err != nil {
+		t.Fatalf("NewAdminRPC: %v", err)
+	}
+	return a, trail, led
+}
+
+func adminPost(a *AdminRPC, auth, body string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	if auth != "" {
+		r.Header.Set("Authorization", auth)
+	}
+	w := httptest.NewRecorder()
+	a.ServeHTTP(w, r)
+	return w
+}
+
+func auditedMethods(t *testing.T, trail *AuditTrail) []string {
+	t.Helper()
+	evs, err := trail.Report()
+	if err != nil {
+		t.Fatalf("Report: %v", err)
+	}
+	var out []string
+	for _, ev := range evs {
+		if ev.Event == "admin_rpc" {
+			out = append(out, ev.Meta["method"])
+		}
+	}
+	return out
+}
+
+func TestNewAdminRPCRequiresTokenAndAudit(t *testing.T) {
+	trail, err := NewAuditTrail(filepath.Join(t.TempDir(), "audit.log"), nil)
+	if err != nil {
+		t.Fatalf("NewAuditTrail: %v", err)
+	}
+	defer trail.Close()
+	if _, err := NewAdminRPC(nil, nil, AdminConfig{Audit: trail}); ErrorCodeOf(err) != CodeInvalidArgument {
+		t.Fatalf("missing token: %v", err)
+	}
+	if AuditManagerInstance() == nil {
+		if _, err := NewAdminRPC(nil, nil, AdminConfig{Token: "secret"}); ErrorCodeOf(err) != CodeFailedPrecondition {
+			t.Fatalf("missing audit trail: %v", err)
+		}
+	}
+}
+
+func TestAdminRPCRejectsUnauthenticatedCalls(t *testing.T) {
+	a, trail, _ := newTestAdminRPC(t)
+	body := `{"jsonrpc":"2.0","id":1,"method":"admin_nodeInfo"}`
+	for _, auth := range []string{"", "Bearer wrong", "secret", "Bearer secret2"} {
+		if w := adminPost(a, auth, body); w.Code != http.StatusUnauthorized {
+			t.Fatalf("Authorization %q: status %d", auth, w.Code)
+		}
+	}
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Authorization", "Bearer secret")
+	w := httptest.NewRecorder()
+	a.ServeHTTP(w, r)
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("GET: status %d", w.Code)
+	}
+	if got := auditedMethods(t, trail); len(got) != 0 {
+		t.Fatalf("rejected calls were audited: %v", got)
+	}
+}
+
+func TestAdminRPCAuditsEveryCall(t *testing.T) {
+	a, trail, led := newTestAdminRPC(t)
+	w := adminPost(a, "Bearer secret", `{"jsonrpc":"2.0","id":7,"method":"admin_nodeInfo"}`)
+	var resp struct {
+		ID     int           `json:"id"`
+		Result AdminNodeInfo `json:"result"`
+		Error  *RPCError     `json:"error"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode %s: %v", w.Body.String(), err)
+	}
+	if resp.Error != nil || resp.ID != 7 || resp.Result.Height != led.LastHeight() || resp.Result.Version != NodeVersion {
+		t.Fatalf("admin_nodeInfo: %s", w.Body.String())
+	}
+
+	// bad params are audited and reported as invalid params; unknown
+	// methods are refused before they are audited
+	w = adminPost(a, "Bearer secret", `[
+		{"jsonrpc":"2.0","id":1,"method":"admin_addPeer","params":[]},
+		{"jsonrpc":"2.0","id":2,"method":"admin_shutdown"},
+		{"jsonrpc":"1.0","id":3,"method":"admin_peers"}
+	]`)
+	var batch []RPCResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &batch); err != nil || len(batch) != 3 {
+		t.Fatalf("batch: %s", w.Body.String())
+	}
+	for i, want := range []int{rpcInvalidParams, rpcMethodNotFound, rpcInvalidRequest} {
+		if batch[i].Error == nil || batch[i].Error.Code != want {
+			t.Fatalf("batch[%d]: %+v, want code %d", i, batch[i].Error, want)
+		}
+	}
+	got := auditedMethods(t, trail)
+	if len(got) != 2 || got[0] != "admin_nodeInfo" || got[1] != "admin_addPeer" {
+		t.Fatalf("audited %v", got)
+	}
+}
+
+func TestAdminRPCRefusesCallsWithoutAudit(t *testing.T) {
+	a, trail, led := newTestAdminRPC(t)
+	trail.Close()
+	led.AddToPool(&Transaction{Hash: Hash{1}, From: Address{1}})
+	w := adminPost(a, "Bearer secret", `{"jsonrpc":"2.0","id":1,"method":"txpool_content"}`)
+	var resp RPCResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Error == nil || resp.Error.Code != rpcServerError || resp.Result != nil {
+		t.Fatalf("call ran without an audit entry: %s", w.Body.String())
+	}
+	if resp.Error.Data == nil || resp.Error.Data.Code != CodeUnavailable {
+		t.Fatalf("error data %+v", resp.Error.Data)
+	}
+}
+
+func TestAdminRPCTxPoolContent(t *testing.T) {
+	a, _, led := newTestAdminRPC(t)
+	from := Address{0xaa}
+	led.mu.Lock()
+	led.nonces[from] = 3
+	led.mu.Unlock()
+	for i, n := range []uint64{3, 4, 6} {
+		led.AddToPool(&Transaction{Hash: Hash{byte(i + 1)}, From: from, Nonce: n})
+	}
+	res, err := a.txpoolContent(nil)
+	if err != nil {
+		t.Fatalf("txpool_content: %v", err)
+	}
+	c := res.(TxPoolContent)
+	pending, queued := c.Pending[from.Hex()], c.Queued[from.Hex()]
+	if len(pending) != 2 || pending[3] == nil || pending[4] == nil {
+		t.Fatalf("pending %v", pending)
+	}
+	if len(queued) != 1 || queued[6] == nil {
+		t.Fatalf("queued %v", queued)
+	}
+}
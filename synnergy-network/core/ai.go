@@ -272,6 +272,11 @@ type ModelListing struct {
 	Seller Address           `json:"seller"`
 	Price  uint64            `json:"price"` // price per sale or per hour rental
 	Meta   map[string]string `json:"meta"`
+	Model  [32]byte          `json:"model"` // published model the listing sells
+
+	// Scheduled price change, applied once PriceEffective is reached.
+	PendingPrice   uint64    `json:"pending_price,omitempty"`
+	PriceEffective time.Time `json:"price_effective,omitempty"`
 }
 
 // Rental holds rental details for an AI model.
@@ -284,11 +289,13 @@ type Rental struct {
 
 // Escrow holds payment until sale/rental conditions are met.
 type Escrow struct {
-	ID     string  `json:"id"`
-	Buyer  Address `json:"buyer"`
-	Seller Address `json:"seller"`
-	Amount uint64  `json:"amount"`
-	State  string  `json:"state"` // "funded", "released"
+	ID     string         `json:"id"`
+	Buyer  Address        `json:"buyer"`
+	Seller Address        `json:"seller"`
+	Amount uint64         `json:"amount"`
+	State  string         `json:"state"` // "funded", "released"
+	Model  [32]byte       `json:"model"`
+	Kind   ModelUsageKind `json:"kind"`
 }
 
 // resolveEscrow finalizes an escrow by releasing funds to the seller.
@@ -301,8 +308,8 @@ func resolveEscrow(ctx *Context, e *Escrow) error {
 
 	escrowAcc := ModuleAddress("ai_marketplace")
 
-	// Transfer funds from escrow account to seller
-	if err := Transfer(ctx, AssetRef{Kind: AssetCoin}, escrowAcc, e.Seller, e.Amount); err != nil {
+	// Transfer funds from escrow account to seller, less the creator royalty
+	if err := payModelRevenue(ctx, e.Model, e.Kind, escrowAcc, e.Seller, e.Amount); err != nil {
 		logger.Errorw("failed to release escrow funds", "escrow", e.ID, "error", err)
 		return fmt.Errorf("transfer escrow funds: %w", err)
 	}
@@ -334,6 +341,10 @@ func ListModel(m *ModelListing) error {
 	}
 
 	m.ID = uuid.New().String()
+	var zero [32]byte
+	if m.Model == zero && m.Meta["cid"] != "" {
+		m.Model = ModelHashForCID(m.Meta["cid"])
+	}
 	key := fmt.Sprintf("ai_marketplace:listing:%s", m.ID)
 
 	// Check if listing exists
@@ -384,17 +395,20 @@ func BuyModel(ctx *Context, listingID string, buyer Address) (*Escrow, error) {
 	}
 
 	// Create escrow object
+	price := m.EffectivePrice(time.Now())
 	esc := &Escrow{
 		ID:     uuid.New().String(),
 		Buyer:  buyer,
 		Seller: m.Seller,
-		Amount: m.Price,
+		Amount: price,
 		State:  "funded",
+		Model:  m.Model,
+		Kind:   ModelUsageSale,
 	}
 
 	// Transfer funds to escrow account
 	escrowAcc := ModuleAddress("ai_marketplace")
-	if err := Transfer(ctx, AssetRef{Kind: AssetCoin}, buyer, escrowAcc, m.Price); err != nil {
+	if err := Transfer(ctx, AssetRef{Kind: AssetCoin}, buyer, escrowAcc, price); err != nil {
 		return nil, fmt.Errorf("transfer to escrow: %w", err)
 	}
 
@@ -431,7 +445,7 @@ func RentModel(ctx *Context, listingID string, renter Address, duration time.Dur
 	}
 
 	hours := uint64(duration.Hours())
-	amount := m.Price * hours
+	amount := m.EffectivePrice(time.Now()) * hours
 
 	esc := &Escrow{
 		ID:     uuid.New().String(),
@@ -439,6 +453,8 @@ func RentModel(ctx *Context, listingID string, renter Address, duration time.Dur
 		Seller: m.Seller,
 		Amount: amount,
 		State:  "funded",
+		Model:  m.Model,
+		Kind:   ModelUsageRental,
 	}
 
 	escrowAcc := ModuleAddress("ai_marketplace")
@@ -1,8 +1,69 @@
 package core
 
-// ai_drift_monitor.go - simple sliding window drift detector for AI models.
+// ai_drift_monitor.go - drift detection for published AI models.
+//
+// The monitor keeps a sliding window of prediction scores per model and a
+// baseline histogram captured from the first window of predictions. On every
+// evaluation the live window is compared against the baseline using the
+// Population Stability Index (PSI) and KL divergence, and ground-truth
+// feedback submitted by users is folded into an accuracy estimate. Models
+// that cross the configured thresholds are flagged as degraded on the ledger
+// and a governance proposal to delist them is raised.
 
-import "sync"
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// driftBins is the number of equal-width buckets over [0,1] used for the
+// prediction distribution histograms.
+const driftBins = 10
+
+// DriftThresholds configures when a model is considered degraded.
+type DriftThresholds struct {
+	PSI         float64 `json:"psi"`
+	KL          float64 `json:"kl"`
+	MinAccuracy float64 `json:"min_accuracy"`
+	MinFeedback int     `json:"min_feedback"` // feedback samples before accuracy counts
+}
+
+// DefaultDriftThresholds follows the usual PSI rule of thumb where values
+// above 0.25 indicate a significant population shift.
+var DefaultDriftThresholds = DriftThresholds{PSI: 0.25, KL: 0.1, MinAccuracy: 0.7, MinFeedback: 20}
+
+// DriftFeedback is a ground-truth observation for a prior prediction. Only
+// its hash is kept on-chain together with the outcome.
+type DriftFeedback struct {
+	Model     [32]byte `json:"model"`
+	Hash      [32]byte `json:"hash"`
+	Predicted float32  `json:"predicted"`
+	Actual    float32  `json:"actual"`
+	Correct   bool     `json:"correct"`
+	Time      int64    `json:"time"`
+}
+
+// DriftReport is the result of a periodic drift evaluation.
+type DriftReport struct {
+	Model      [32]byte `json:"model"`
+	PSI        float64  `json:"psi"`
+	KL         float64  `json:"kl"`
+	Accuracy   float64  `json:"accuracy"`
+	Samples    int      `json:"samples"`
+	Feedback   int      `json:"feedback"`
+	DistHash   [32]byte `json:"dist_hash"` // hash of the live histogram
+	Degraded   bool     `json:"degraded"`
+	ProposalID string   `json:"proposal_id,omitempty"`
+	Time       int64    `json:"time"`
+}
 
 // DriftMonitor tracks average scores and signals when deviation exceeds threshold.
 type DriftMonitor struct {
@@ -10,22 +71,58 @@ type DriftMonitor struct {
 	window int
 	data   map[[32]byte][]float32
 	base   map[[32]byte]float32
+
+	baseHist  map[[32]byte][]float64
+	baseN     map[[32]byte]int
+	fbTotal   map[[32]byte]int
+	fbCorrect map[[32]byte]int
+	thr       DriftThresholds
+	proposer  Address
 }
 
 // NewDriftMonitor returns a monitor with the provided window size.
 func NewDriftMonitor(window int) *DriftMonitor {
 	return &DriftMonitor{
-		window: window,
-		data:   make(map[[32]byte][]float32),
-		base:   make(map[[32]byte]float32),
+		window:    window,
+		data:      make(map[[32]byte][]float32),
+		base:      make(map[[32]byte]float32),
+		baseHist:  make(map[[32]byte][]float64),
+		baseN:     make(map[[32]byte]int),
+		fbTotal:   make(map[[32]byte]int),
+		fbCorrect: make(map[[32]byte]int),
+		thr:       DefaultDriftThresholds,
 	}
 }
 
+// SetThresholds replaces the degradation thresholds.
+func (d *DriftMonitor) SetThresholds(t DriftThresholds) {
+	d.mu.Lock()
+	d.thr = t
+	d.mu.Unlock()
+}
+
+// SetProposer sets the account used to raise delisting proposals. It must
+// hold a balance to satisfy SubmitProposal.
+func (d *DriftMonitor) SetProposer(a Address) {
+	d.mu.Lock()
+	d.proposer = a
+	d.mu.Unlock()
+}
+
 // Record adds a new score for the model and calculates drift.
 // It returns the drift value and whether it exceeds 20% of the baseline.
 func (d *DriftMonitor) Record(model [32]byte, score float32) (float64, bool) {
 	d.mu.Lock()
 	defer d.mu.Unlock()
+	if d.baseN[model] < d.window {
+		h := d.baseHist[model]
+		if h == nil {
+			h = make([]float64, driftBins)
+			d.baseHist[model] = h
+		}
+		h[driftBin(score)]++
+		d.baseN[model]++
+	}
 	arr := d.data[model]
 	arr = append(arr, score)
 	if len(arr) > d.window {
@@ -48,9 +145,253 @@ func (d *DriftMonitor) Record(model [32]byte, score float32) (float64, bool) {
 	return drift, false
 }
 
+// Feedback records whether a prediction matched the ground truth.
+func (d *DriftMonitor) Feedback(model [32]byte, correct bool) {
+	d.mu.Lock()
+	d.fbTotal[model]++
+	if correct {
+		d.fbCorrect[model]++
+	}
+	d.mu.Unlock()
+}
+
+// Evaluate compares the live window against the baseline distribution. The
+// second return value is false while the baseline is still being collected.
+func (d *DriftMonitor) Evaluate(model [32]byte) (DriftReport, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	rep := DriftReport{Model: model, Time: time.Now().Unix(), Accuracy: 1}
+	if d.baseN[model] < d.window {
+		return rep, false
+	}
+	cur := make([]float64, driftBins)
+	for _, s := range d.data[model] {
+		cur[driftBin(s)]++
+	}
+	rep.Samples = len(d.data[model])
+	rep.PSI = PSI(d.baseHist[model], cur)
+	rep.KL = KLDivergence(cur, d.baseHist[model])
+	rep.DistHash = histogramHash(cur)
+	rep.Feedback = d.fbTotal[model]
+	if rep.Feedback > 0 {
+		rep.Accuracy = float64(d.fbCorrect[model]) / float64(rep.Feedback)
+	}
+	rep.Degraded = rep.PSI > d.thr.PSI || rep.KL > d.thr.KL ||
+		(rep.Feedback >= d.thr.MinFeedback && rep.Accuracy < d.thr.MinAccuracy)
+	return rep, true
+}
+
+// PSI returns the Population Stability Index between an expected and an
+// actual histogram. Inputs are raw bucket counts.
+func PSI(expected, actual []float64) float64 {
+	e, a := normalise(expected), normalise(actual)
+	var psi float64
+	for i := range e {
+		psi += (a[i] - e[i]) * math.Log(a[i]/e[i])
+	}
+	return psi
+}
+
+// KLDivergence returns D_KL(p || q) for two histograms of raw counts.
+func KLDivergence(p, q []float64) float64 {
+	pn, qn := normalise(p), normalise(q)
+	var kl float64
+	for i := range pn {
+		kl += pn[i] * math.Log(pn[i]/qn[i])
+	}
+	return kl
+}
+
+// normalise converts counts into probabilities, smoothing empty buckets so
+// the log terms stay finite.
+func normalise(h []float64) []float64 {
+	const eps = 1e-4
+	out := make([]float64, driftBins)
+	var total float64
+	for i := 0; i < driftBins && i < len(h); i++ {
+		total += h[i]
+	}
+	for i := range out {
+		if total > 0 && i < len(h) {
+			out[i] = h[i] / total
+		}
+		if out[i] < eps {
+			out[i] = eps
+		}
+	}
+	return out
+}
+
+func driftBin(score float32) int {
+	i := int(score * driftBins)
+	if i < 0 {
+		return 0
+	}
+	if i >= driftBins {
+		return driftBins - 1
+	}
+	return i
+}
+
+func histogramHash(h []float64) [32]byte {
+	buf := make([]byte, 8*len(h))
+	for i, v := range h {
+		binary.BigEndian.PutUint64(buf[i*8:], math.Float64bits(v))
+	}
+	return sha256.Sum256(buf)
+}
+
 func abs(v float64) float64 {
 	if v < 0 {
 		return -v
 	}
 	return v
 }
+
+//---------------------------------------------------------------------
+// Engine integration
+//---------------------------------------------------------------------
+
+func driftFeedbackKey(h [32]byte) []byte { return append([]byte("ai:drift:feedback:"), h[:]...) }
+func driftReportKey(m [32]byte) []byte   { return append([]byte("ai:drift:report:"), m[:]...) }
+func degradedKey(m [32]byte) []byte      { return append([]byte("ai:degraded:"), m[:]...) }
+
+// Drift exposes the engine's drift monitor.
+func (ai *AIEngine) Drift() *DriftMonitor { return ai.drift }
+
+// SubmitGroundTruth records the real outcome for a prediction. The
+// observation is hashed and stored on-chain and counted towards the model's
+// accuracy. A prediction is correct when it lies within 0.5 of the label.
+func (ai *AIEngine) SubmitGroundTruth(model [32]byte, predicted, actual float32) (*DriftFeedback, error) {
+	if ai == nil || ai.drift == nil {
+		return nil, fmt.Errorf("AI engine not initialised")
+	}
+	if _, ok := ai.modelMeta(model); !ok {
+		return nil, fmt.Errorf("model %x not found", model)
+	}
+	fb := &DriftFeedback{
+		Model:     model,
+		Predicted: predicted,
+		Actual:    actual,
+		Correct:   abs(float64(predicted-actual)) < 0.5,
+		Time:      time.Now().Unix(),
+	}
+	buf := make([]byte, 0, 48)
+	buf = append(buf, model[:]...)
+	buf = binary.BigEndian.AppendUint32(buf, math.Float32bits(predicted))
+	buf = binary.BigEndian.AppendUint32(buf, math.Float32bits(actual))
+	buf = binary.BigEndian.AppendUint64(buf, uint64(fb.Time))
+	fb.Hash = sha256.Sum256(buf)
+	ai.led.SetState(driftFeedbackKey(fb.Hash), mustJSON(fb))
+	ai.drift.Feedback(model, fb.Correct)
+	return fb, nil
+}
+
+// EvaluateDrift scores every loaded model, persists the reports and flags
+// newly degraded models, raising a delisting proposal for each.
+func (ai *AIEngine) EvaluateDrift() ([]DriftReport, error) {
+	if ai == nil || ai.drift == nil {
+		return nil, fmt.Errorf("AI engine not initialised")
+	}
+	ai.mu.RLock()
+	models := make([][32]byte, 0, len(ai.models))
+	for h := range ai.models {
+		models = append(models, h)
+	}
+	ai.mu.RUnlock()
+
+	var out []DriftReport
+	for _, h := range models {
+		rep, ok := ai.drift.Evaluate(h)
+		if !ok {
+			continue
+		}
+		if rep.Degraded && !ai.IsModelDegraded(h) {
+			rep.ProposalID = ai.proposeDelisting(rep)
+			ai.led.SetState(degradedKey(h), mustJSON(rep))
+			Broadcast("ai:degraded", mustJSON(rep))
+		}
+		ai.led.SetState(driftReportKey(h), mustJSON(rep))
+		out = append(out, rep)
+	}
+	return out, nil
+}
+
+// proposeDelisting submits a governance proposal to remove a degraded model
+// from the marketplace. Failures are logged; the model stays flagged.
+func (ai *AIEngine) proposeDelisting(rep DriftReport) string {
+	ai.drift.mu.Lock()
+	proposer := ai.drift.proposer
+	ai.drift.mu.Unlock()
+	if proposer == (Address{}) {
+		proposer = ModuleAddress("ai_drift")
+	}
+	p := &GovProposal{
+		Creator:     proposer,
+		Changes:     map[string]string{"ai_delist_model": hex.EncodeToString(rep.Model[:])},
+		Votes:       make(map[string]bool),
+		Description: fmt.Sprintf("delist degraded model %x (psi=%.3f kl=%.3f acc=%.2f)", rep.Model, rep.PSI, rep.KL, rep.Accuracy),
+	}
+	if err := SubmitProposal(p); err != nil {
+		zap.L().Sugar().Warnf("drift: delisting proposal for %x failed: %v", rep.Model, err)
+		return ""
+	}
+	return p.ID
+}
+
+// IsModelDegraded reports whether the drift monitor has flagged a model.
+func (ai *AIEngine) IsModelDegraded(model [32]byte) bool {
+	raw, err := ai.led.GetState(degradedKey(model))
+	return err == nil && len(raw) > 0
+}
+
+// DriftReportFor returns the latest persisted drift report for a model.
+func (ai *AIEngine) DriftReportFor(model [32]byte) (*DriftReport, error) {
+	raw, err := ai.led.GetState(driftReportKey(model))
+	if err != nil || len(raw) == 0 {
+		return nil, ErrNotFound
+	}
+	var rep DriftReport
+	if err := json.Unmarshal(raw, &rep); err != nil {
+		return nil, err
+	}
+	return &rep, nil
+}
+
+// StartDriftMonitor evaluates drift every interval until ctx is cancelled.
+func (ai *AIEngine) StartDriftMonitor(ctx context.Context, interval time.Duration) {
+	go func() {
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+				if _, err := ai.EvaluateDrift(); err != nil {
+					zap.L().Sugar().Warnf("drift evaluation: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// DelistModel removes every marketplace listing selling model. It is applied
+// when a drift delisting proposal passes.
+func DelistModel(model [32]byte) (int, error) {
+	list, err := ListModelListings()
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, m := range list {
+		if m.Model != model {
+			continue
+		}
+		if err := CurrentStore().Delete([]byte(fmt.Sprintf("ai_marketplace:listing:%s", m.ID))); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
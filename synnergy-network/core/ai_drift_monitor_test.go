@@ -0,0 +1,52 @@
+package core
+
+import "testing"
+
+func TestPSIIdenticalDistributions(t *testing.T) {
+	h := []float64{1, 2, 3, 4, 5, 5, 4, 3, 2, 1}
+	if v := PSI(h, h); v > 1e-9 {
+		t.Fatalf("expected zero PSI, got %f", v)
+	}
+	if v := KLDivergence(h, h); v > 1e-9 {
+		t.Fatalf("expected zero KL, got %f", v)
+	}
+}
+
+func TestDriftMonitorFlagsShift(t *testing.T) {
+	d := NewDriftMonitor(20)
+	var m [32]byte
+	m[0] = 1
+	if _, ok := d.Evaluate(m); ok {
+		t.Fatalf("evaluation before baseline should be skipped")
+	}
+	for i := 0; i < 20; i++ {
+		d.Record(m, 0.15)
+	}
+	rep, ok := d.Evaluate(m)
+	if !ok || rep.Degraded {
+		t.Fatalf("stable model flagged: %+v", rep)
+	}
+	for i := 0; i < 20; i++ {
+		d.Record(m, 0.85)
+	}
+	rep, _ = d.Evaluate(m)
+	if !rep.Degraded || rep.PSI <= DefaultDriftThresholds.PSI {
+		t.Fatalf("shifted model not flagged: %+v", rep)
+	}
+}
+
+func TestDriftMonitorAccuracyThreshold(t *testing.T) {
+	d := NewDriftMonitor(5)
+	d.SetThresholds(DriftThresholds{PSI: 10, KL: 10, MinAccuracy: 0.5, MinFeedback: 4})
+	var m [32]byte
+	for i := 0; i < 5; i++ {
+		d.Record(m, 0.5)
+	}
+	for i := 0; i < 4; i++ {
+		d.Feedback(m, i == 0)
+	}
+	rep, ok := d.Evaluate(m)
+	if !ok || !rep.Degraded || rep.Accuracy != 0.25 {
+		t.Fatalf("unexpected report %+v", rep)
+	}
+}
@@ -46,6 +46,7 @@ func (ai *AIEngine) InferModel(hash [32]byte, input []byte) (*InferenceResult, e
 	res := &InferenceResult{Model: hash, Output: resp.Result, Score: resp.Score, Timestamp: time.Now().Unix()}
 	key := append([]byte("ai:inference:"), hash[:]...)
 	ai.led.SetState(key, mustJSON(res))
+	_ = RecordModelUsage(hash, ModelUsageInference, 0, 0)
 	if ai.drift != nil {
 		if diff, alert := ai.drift.Record(hash, resp.Score); alert {
 			dkey := append([]byte("ai:drift:"), hash[:]...)
@@ -0,0 +1,596 @@
+package core
+
+// ai_inference_commitments.go – verifiable off-chain inference.
+//
+// Flow
+// ----
+// 0. **RegisterModelHost** – a host bonds at least MinModelHostBond under the
+//    address of its ed25519 key. Only bonded hosts are assigned requests.
+// 1. **RequestInference** – the requester escrows the fee in the inference
+//    module account and the request is assigned to a registered model host.
+// 2. **ServeInference / SubmitInferenceResult** – the host runs the model via
+//    the AI service and commits SHA256(inputHash || outputHash || modelCID)
+//    signed with its registered ed25519 key.
+// 3. **FinalizeInference** – once the dispute window passes unchallenged the
+//    host is paid and the model creator receives its royalty.
+// 4. **DisputeInference / SubmitReexecution** – a challenger posts a bond and
+//    randomly selected hosts re-run the request. The majority commitment
+//    decides whether the host or the challenger is paid out; an overturned
+//    host also loses InferenceHostSlashBp of its bond to the challenger.
+//    Without a majority by the re-execution deadline FinalizeInference
+//    refunds both the requester and the challenger.
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	crand "crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// InferenceDisputeWindow is how long a committed result can be challenged.
+	InferenceDisputeWindow = 10 * time.Minute
+	// InferenceVerifiers is the number of hosts asked to re-execute a dispute.
+	InferenceVerifiers = 3
+	// InferenceReexecWindow is how long verifiers have to reach a majority.
+	InferenceReexecWindow = 30 * time.Minute
+	// MinModelHostBond is the bond a host locks to serve a model.
+	MinModelHostBond uint64 = 10_000
+	// InferenceHostSlashBp is the share of its bond an overturned host loses.
+	InferenceHostSlashBp = 5_000
+)
+
+// InferenceStatus tracks the lifecycle of an inference request.
+type InferenceStatus string
+
+const (
+	InferencePending    InferenceStatus = "pending"
+	InferenceCommitted  InferenceStatus = "committed"
+	InferenceDisputed   InferenceStatus = "disputed"
+	InferenceFinalized  InferenceStatus = "finalized"
+	InferenceOverturned InferenceStatus = "overturned"
+	InferenceExpired    InferenceStatus = "expired"
+)
+
+// ModelHost is a node registered to serve inference for a model.
+type ModelHost struct {
+	Model      [32]byte  `json:"model"`
+	Host       Address   `json:"host"`
+	PubKey     []byte    `json:"pub_key"`
+	Endpoint   string    `json:"endpoint,omitempty"`
+	Bond       uint64    `json:"bond"`
+	Registered time.Time `json:"registered"`
+}
+
+// InferenceCommitment binds a host to an inference output.
+type InferenceCommitment struct {
+	Host       Address   `json:"host"`
+	OutputHash [32]byte  `json:"output_hash"`
+	OutputCID  string    `json:"output_cid,omitempty"`
+	Commitment [32]byte  `json:"commitment"`
+	Sig        []byte    `json:"sig"`
+	At         time.Time `json:"at"`
+}
+
+// InferenceRequest is the on-chain record of an escrowed inference job.
+type InferenceRequest struct {
+	ID         string                `json:"id"`
+	Model      [32]byte              `json:"model"`
+	ModelCID   string                `json:"model_cid"`
+	Requester  Address               `json:"requester"`
+	InputHash  [32]byte              `json:"input_hash"`
+	InputCID   string                `json:"input_cid,omitempty"`
+	Payment    uint64                `json:"payment"`
+	Host       Address               `json:"host"`
+	Status     InferenceStatus       `json:"status"`
+	Result     *InferenceCommitment  `json:"result,omitempty"`
+	Challenger Address               `json:"challenger,omitempty"`
+	Bond       uint64                `json:"bond,omitempty"`
+	Verifiers  []Address             `json:"verifiers,omitempty"`
+	Reexecs    []InferenceCommitment `json:"reexecs,omitempty"`
+	Created    time.Time             `json:"created"`
+	DisputeEnd time.Time             `json:"dispute_end,omitempty"`
+	ReexecEnd  time.Time             `json:"reexec_end,omitempty"`
+}
+
+var (
+	ErrInferenceState = errors.New("inference request in wrong state")
+
+	// inferMu serialises request state transitions. It is separate from
+	// AIEngine.mu because payouts read model metadata under that lock.
+	inferMu sync.Mutex
+)
+
+func inferenceAccount() Address { return ModuleAddress("ai_inference") }
+
+func inferReqKey(id string) []byte { return []byte("ai:infer:req:" + id) }
+func modelHostKey(model [32]byte, host Address) []byte {
+	return []byte(fmt.Sprintf("ai:host:%x:%x", model, host[:]))
+}
+
+// InferenceCommitmentHash computes the commitment a host signs for a result.
+func InferenceCommitmentHash(inputHash, outputHash [32]byte, modelCID string) [32]byte {
+	var buf bytes.Buffer
+	buf.Write(inputHash[:])
+	buf.Write(outputHash[:])
+	buf.WriteString(modelCID)
+	return sha256.Sum256(buf.Bytes())
+}
+
+// RegisterModelHost records host as a server for a published model. The host
+// address must be the address of pub, and bond, at least MinModelHostBond,
+// is locked in the inference account until the host unregisters.
+func (ai *AIEngine) RegisterModelHost(model [32]byte, host Address, pub ed25519.PublicKey, endpoint string, bond uint64) error {
+	if ai == nil {
+		return errors.New("AI engine not initialised")
+	}
+	if _, ok := ai.modelMeta(model); !ok {
+		return fmt.Errorf("model %x not found", model)
+	}
+	if len(pub) != ed25519.PublicKeySize {
+		return errors.New("invalid host public key")
+	}
+	if Ed25519Address(pub) != host {
+		return errors.New("host address does not match public key")
+	}
+	if bond < MinModelHostBond {
+		return fmt.Errorf("host bond must be at least %d", MinModelHostBond)
+	}
+	inferMu.Lock()
+	defer inferMu.Unlock()
+	if _, err := ai.modelHost(model, host); err == nil {
+		return errors.New("host already registered for model")
+	}
+	if err := ai.led.Transfer(host, inferenceAccount(), bond); err != nil {
+		return fmt.Errorf("lock host bond: %w", err)
+	}
+	h := ModelHost{Model: model, Host: host, PubKey: pub, Endpoint: endpoint, Bond: bond, Registered: time.Now().UTC()}
+	return ai.led.SetState(modelHostKey(model, host), mustJSON(h))
+}
+
+// UnregisterModelHost removes host from a model and returns what is left of
+// its bond. Hosts with a request still open as host or verifier must wait
+// until it is settled.
+func (ai *AIEngine) UnregisterModelHost(model [32]byte, host Address) error {
+	if ai == nil {
+		return errors.New("AI engine not initialised")
+	}
+	inferMu.Lock()
+	defer inferMu.Unlock()
+	h, err := ai.modelHost(model, host)
+	if err != nil {
+		return err
+	}
+	reqs, err := ai.ListInferenceRequests("")
+	if err != nil {
+		return err
+	}
+	for _, r := range reqs {
+		if r.Model != model {
+			continue
+		}
+		switch r.Status {
+		case InferencePending, InferenceCommitted, InferenceDisputed:
+			if r.Host == host || containsAddr(r.Verifiers, host) {
+				return fmt.Errorf("host has open request %s", r.ID)
+			}
+		}
+	}
+	if h.Bond > 0 {
+		if err := ai.led.Transfer(inferenceAccount(), host, h.Bond); err != nil {
+			return err
+		}
+	}
+	return ai.led.DeleteState(modelHostKey(model, host))
+}
+
+// ModelHosts returns the registered hosts for a model ordered by address.
+func (ai *AIEngine) ModelHosts(model [32]byte) ([]ModelHost, error) {
+	it := ai.led.PrefixIterator([]byte(fmt.Sprintf("ai:host:%x:", model)))
+	var out []ModelHost
+	for it.Next() {
+		var h ModelHost
+		if err := json.Unmarshal(it.Value(), &h); err == nil {
+			out = append(out, h)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return bytes.Compare(out[i].Host[:], out[j].Host[:]) < 0 })
+	return out, it.Error()
+}
+
+func (ai *AIEngine) modelHost(model [32]byte, host Address) (ModelHost, error) {
+	raw, err := ai.led.GetState(modelHostKey(model, host))
+	if err != nil || raw == nil {
+		return ModelHost{}, fmt.Errorf("host %x not registered for model", host[:])
+	}
+	var h ModelHost
+	err = json.Unmarshal(raw, &h)
+	return h, err
+}
+
+// bondedHosts returns the hosts of a model whose bond covers MinModelHostBond.
+func (ai *AIEngine) bondedHosts(model [32]byte) ([]ModelHost, error) {
+	hosts, err := ai.ModelHosts(model)
+	if err != nil {
+		return nil, err
+	}
+	out := hosts[:0]
+	for _, h := range hosts {
+		if h.Bond >= MinModelHostBond {
+			out = append(out, h)
+		}
+	}
+	return out, nil
+}
+
+// pickVerifiers draws up to n hosts other than exclude uniformly at random,
+// so a host cannot predict or arrange who re-executes its results.
+func pickVerifiers(hosts []ModelHost, exclude Address, n int) ([]Address, error) {
+	var pool []Address
+	for _, h := range hosts {
+		if h.Host != exclude {
+			pool = append(pool, h.Host)
+		}
+	}
+	for i := 0; i < len(pool) && i < n; i++ {
+		j, err := crand.Int(crand.Reader, big.NewInt(int64(len(pool)-i)))
+		if err != nil {
+			return nil, err
+		}
+		k := i + int(j.Int64())
+		pool[i], pool[k] = pool[k], pool[i]
+	}
+	if len(pool) > n {
+		pool = pool[:n]
+	}
+	return pool, nil
+}
+
+// slashHost moves InferenceHostSlashBp of the host's bond to to. A host left
+// below MinModelHostBond is no longer assigned requests.
+func (ai *AIEngine) slashHost(model [32]byte, host, to Address) (uint64, error) {
+	h, err := ai.modelHost(model, host)
+	if err != nil {
+		return 0, nil // unregistered since; nothing left to slash
+	}
+	cut := h.Bond * InferenceHostSlashBp / 10_000
+	if cut == 0 {
+		return 0, nil
+	}
+	if err := ai.led.Transfer(inferenceAccount(), to, cut); err != nil {
+		return 0, err
+	}
+	h.Bond -= cut
+	return cut, ai.led.SetState(modelHostKey(model, host), mustJSON(h))
+}
+
+// RequestInference escrows payment and assigns the request to a host chosen
+// deterministically from the request ID.
+func (ai *AIEngine) RequestInference(model [32]byte, requester Address, inputHash [32]byte, inputCID string, payment uint64) (*InferenceRequest, error) {
+	if ai == nil {
+		return nil, errors.New("AI engine not initialised")
+	}
+	meta, ok := ai.modelMeta(model)
+	if !ok {
+		return nil, fmt.Errorf("model %x not found", model)
+	}
+	if payment == 0 {
+		return nil, errors.New("payment must be >0")
+	}
+	hosts, err := ai.bondedHosts(model)
+	if err != nil {
+		return nil, err
+	}
+	if len(hosts) == 0 {
+		return nil, errors.New("no bonded hosts registered for model")
+	}
+	req := &InferenceRequest{
+		ID:        uuid.New().String(),
+		Model:     model,
+		ModelCID:  meta.CID,
+		Requester: requester,
+		InputHash: inputHash,
+		InputCID:  inputCID,
+		Payment:   payment,
+		Status:    InferencePending,
+		Created:   time.Now().UTC(),
+	}
+	pick := sha256.Sum256([]byte(req.ID))
+	req.Host = hosts[int(pick[0])%len(hosts)].Host
+
+	if err := ai.led.Transfer(requester, inferenceAccount(), payment); err != nil {
+		return nil, fmt.Errorf("escrow payment: %w", err)
+	}
+	if err := ai.putInference(req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// GetInferenceRequest loads a request by ID.
+func (ai *AIEngine) GetInferenceRequest(id string) (*InferenceRequest, error) {
+	raw, err := ai.led.GetState(inferReqKey(id))
+	if err != nil || raw == nil {
+		return nil, ErrNotFound
+	}
+	var req InferenceRequest
+	if err := json.Unmarshal(raw, &req); err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+func (ai *AIEngine) putInference(req *InferenceRequest) error {
+	return ai.led.SetState(inferReqKey(req.ID), mustJSON(req))
+}
+
+// verifyCommitment checks a host's signed commitment for a request.
+func (ai *AIEngine) verifyCommitment(req *InferenceRequest, c *InferenceCommitment) error {
+	h, err := ai.modelHost(req.Model, c.Host)
+	if err != nil {
+		return err
+	}
+	c.Commitment = InferenceCommitmentHash(req.InputHash, c.OutputHash, req.ModelCID)
+	if !ed25519.Verify(ed25519.PublicKey(h.PubKey), c.Commitment[:], c.Sig) {
+		return errors.New("invalid commitment signature")
+	}
+	return nil
+}
+
+// SubmitInferenceResult records the assigned host's signed result and opens
+// the dispute window.
+func (ai *AIEngine) SubmitInferenceResult(id string, c InferenceCommitment) (*InferenceRequest, error) {
+	inferMu.Lock()
+	defer inferMu.Unlock()
+	req, err := ai.GetInferenceRequest(id)
+	if err != nil {
+		return nil, err
+	}
+	if req.Status != InferencePending {
+		return nil, ErrInferenceState
+	}
+	if c.Host != req.Host {
+		return nil, errors.New("result not from assigned host")
+	}
+	if err := ai.verifyCommitment(req, &c); err != nil {
+		return nil, err
+	}
+	c.At = time.Now().UTC()
+	req.Result = &c
+	req.Status = InferenceCommitted
+	req.DisputeEnd = c.At.Add(InferenceDisputeWindow)
+	return req, ai.putInference(req)
+}
+
+// ServeInference is run by a host: it executes the model through the AI
+// service, signs the commitment with the host key and submits it on-chain.
+func (ai *AIEngine) ServeInference(id string, input []byte, key ed25519.PrivateKey) (*InferenceRequest, []byte, error) {
+	req, err := ai.GetInferenceRequest(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if sha256.Sum256(input) != req.InputHash {
+		return nil, nil, errors.New("input does not match request")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	resp, err := ai.client.Inference(ctx, &TFRequest{Payload: input})
+	if err != nil {
+		return nil, nil, err
+	}
+	out := sha256.Sum256(resp.Result)
+	com := InferenceCommitmentHash(req.InputHash, out, req.ModelCID)
+	c := InferenceCommitment{
+		Host:       Ed25519Address(key.Public().(ed25519.PublicKey)),
+		OutputHash: out,
+		Sig:        ed25519.Sign(key, com[:]),
+	}
+	if req.Status == InferenceDisputed {
+		req, err = ai.SubmitReexecution(id, c)
+	} else {
+		req, err = ai.SubmitInferenceResult(id, c)
+	}
+	return req, resp.Result, err
+}
+
+// payInference releases escrow to the host minus the creator royalty.
+func (ai *AIEngine) payInference(req *InferenceRequest, host Address) error {
+	pay := req.Payment
+	var royalty uint64
+	if meta, ok := ai.modelMeta(req.Model); ok && meta.RoyaltyBp > 0 {
+		royalty = pay * uint64(meta.RoyaltyBp) / 10_000
+		if royalty > 0 {
+			if err := ai.led.Transfer(inferenceAccount(), meta.Creator, royalty); err != nil {
+				return err
+			}
+			pay -= royalty
+		}
+	}
+	if err := ai.led.Transfer(inferenceAccount(), host, pay); err != nil {
+		return err
+	}
+	return RecordModelUsage(req.Model, ModelUsageInference, royalty, pay)
+}
+
+// FinalizeInference pays the host once the dispute window has closed. A
+// dispute whose verifiers reached no majority by the re-execution deadline
+// is closed by refunding the payment to the requester and the bond to the
+// challenger.
+func (ai *AIEngine) FinalizeInference(id string) (*InferenceRequest, error) {
+	inferMu.Lock()
+	defer inferMu.Unlock()
+	req, err := ai.GetInferenceRequest(id)
+	if err != nil {
+		return nil, err
+	}
+	if req.Status == InferenceDisputed {
+		return ai.expireReexecution(req)
+	}
+	if req.Status != InferenceCommitted {
+		return nil, ErrInferenceState
+	}
+	if time.Now().Before(req.DisputeEnd) {
+		return nil, errors.New("dispute window still open")
+	}
+	if err := ai.payInference(req, req.Host); err != nil {
+		return nil, err
+	}
+	req.Status = InferenceFinalized
+	return req, ai.putInference(req)
+}
+
+func (ai *AIEngine) expireReexecution(req *InferenceRequest) (*InferenceRequest, error) {
+	if time.Now().Before(req.ReexecEnd) {
+		return nil, errors.New("re-execution still open")
+	}
+	if err := ai.led.Transfer(inferenceAccount(), req.Requester, req.Payment); err != nil {
+		return nil, err
+	}
+	if err := ai.led.Transfer(inferenceAccount(), req.Challenger, req.Bond); err != nil {
+		return nil, err
+	}
+	req.Status = InferenceExpired
+	return req, ai.putInference(req)
+}
+
+// DisputeInference challenges a committed result. The challenger bonds at
+// least the request payment and up to InferenceVerifiers bonded hosts other
+// than the original host are drawn at random to re-execute the request
+// within InferenceReexecWindow.
+func (ai *AIEngine) DisputeInference(id string, challenger Address, bond uint64) (*InferenceRequest, error) {
+	inferMu.Lock()
+	defer inferMu.Unlock()
+	req, err := ai.GetInferenceRequest(id)
+	if err != nil {
+		return nil, err
+	}
+	if req.Status != InferenceCommitted || time.Now().After(req.DisputeEnd) {
+		return nil, ErrInferenceState
+	}
+	if bond < req.Payment {
+		return nil, fmt.Errorf("bond must be at least %d", req.Payment)
+	}
+	hosts, err := ai.bondedHosts(req.Model)
+	if err != nil {
+		return nil, err
+	}
+	if req.Verifiers, err = pickVerifiers(hosts, req.Host, InferenceVerifiers); err != nil {
+		return nil, err
+	}
+	if len(req.Verifiers) == 0 {
+		return nil, errors.New("no independent hosts available for re-execution")
+	}
+	if err := ai.led.Transfer(challenger, inferenceAccount(), bond); err != nil {
+		return nil, fmt.Errorf("escrow bond: %w", err)
+	}
+	req.Challenger = challenger
+	req.Bond = bond
+	req.Status = InferenceDisputed
+	req.ReexecEnd = time.Now().UTC().Add(InferenceReexecWindow)
+	return req, ai.putInference(req)
+}
+
+// SubmitReexecution records a verifier's commitment for a disputed request
+// and resolves the dispute once a majority of verifiers agree.
+func (ai *AIEngine) SubmitReexecution(id string, c InferenceCommitment) (*InferenceRequest, error) {
+	inferMu.Lock()
+	defer inferMu.Unlock()
+	req, err := ai.GetInferenceRequest(id)
+	if err != nil {
+		return nil, err
+	}
+	if req.Status != InferenceDisputed || time.Now().After(req.ReexecEnd) {
+		return nil, ErrInferenceState
+	}
+	assigned := false
+	for _, v := range req.Verifiers {
+		assigned = assigned || v == c.Host
+	}
+	if !assigned {
+		return nil, errors.New("host not selected for re-execution")
+	}
+	for _, r := range req.Reexecs {
+		if r.Host == c.Host {
+			return nil, errors.New("host already re-executed")
+		}
+	}
+	if err := ai.verifyCommitment(req, &c); err != nil {
+		return nil, err
+	}
+	c.At = time.Now().UTC()
+	req.Reexecs = append(req.Reexecs, c)
+
+	quorum := len(req.Verifiers)/2 + 1
+	var agree, disagree int
+	for _, r := range req.Reexecs {
+		if r.Commitment == req.Result.Commitment {
+			agree++
+		} else {
+			disagree++
+		}
+	}
+	switch {
+	case agree >= quorum:
+		// Original result upheld: host is paid and receives the bond.
+		if err := ai.payInference(req, req.Host); err != nil {
+			return nil, err
+		}
+		if err := ai.led.Transfer(inferenceAccount(), req.Host, req.Bond); err != nil {
+			return nil, err
+		}
+		req.Status = InferenceFinalized
+	case disagree >= quorum:
+		// Result overturned: requester refunded, challenger bond returned
+		// and the host slashed in the challenger's favour.
+		if err := ai.led.Transfer(inferenceAccount(), req.Requester, req.Payment); err != nil {
+			return nil, err
+		}
+		if err := ai.led.Transfer(inferenceAccount(), req.Challenger, req.Bond); err != nil {
+			return nil, err
+		}
+		if _, err := ai.slashHost(req.Model, req.Host, req.Challenger); err != nil {
+			return nil, err
+		}
+		req.Status = InferenceOverturned
+	}
+	return req, ai.putInference(req)
+}
+
+// ListInferenceRequests returns all requests, optionally filtered by status.
+func (ai *AIEngine) ListInferenceRequests(status InferenceStatus) ([]InferenceRequest, error) {
+	it := ai.led.PrefixIterator([]byte("ai:infer:req:"))
+	var out []InferenceRequest
+	for it.Next() {
+		var r InferenceRequest
+		if err := json.Unmarshal(it.Value(), &r); err != nil {
+			continue
+		}
+		if status == "" || r.Status == status {
+			out = append(out, r)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Created.Before(out[j].Created) })
+	return out, it.Error()
+}
+
+// ParseModelHash decodes a hex encoded model hash.
+func ParseModelHash(s string) ([32]byte, error) {
+	var h [32]byte
+	b, err := hex.DecodeString(s)
+	if err != nil || len(b) != len(h) {
+		return h, errors.New("invalid model hash")
+	}
+	copy(h[:], b)
+	return h, nil
+}
@@ -0,0 +1,156 @@
+package core
+
+import (
+	"crypto/ed25519"
+	"testing"
+	"time"
+)
+
+type inferenceHost struct {
+	addr Address
+	key  ed25519.PrivateKey
+}
+
+func newInferenceFixture(t *testing.T, hosts int) (*AIEngine, StateRW, [32]byte, []inferenceHost) {
+	t.Helper()
+	st, _ := NewInMemory()
+	model := [32]byte{0x11}
+	ai := &AIEngine{led: st, models: map[[32]byte]ModelMeta{model: {CID: "bafymodel"}}}
+	SetStore(NewInMemoryStore())
+	t.Cleanup(func() { SetStore(nil) })
+
+	var out []inferenceHost
+	for i := 0; i < hosts; i++ {
+		pub, priv, _ := ed25519.GenerateKey(nil)
+		h := inferenceHost{addr: Ed25519Address(pub), key: priv}
+		_ = st.Mint(h.addr, MinModelHostBond)
+		if err := ai.RegisterModelHost(model, h.addr, pub, "", MinModelHostBond); err != nil {
+			t.Fatalf("register host %d: %v", i, err)
+		}
+		out = append(out, h)
+	}
+	return ai, st, model, out
+}
+
+func (h inferenceHost) commit(req *InferenceRequest, output byte) InferenceCommitment {
+	out := [32]byte{output}
+	com := InferenceCommitmentHash(req.InputHash, out, req.ModelCID)
+	return InferenceCommitment{Host: h.addr, OutputHash: out, Sig: ed25519.Sign(h.key, com[:])}
+}
+
+func hostByAddr(hosts []inferenceHost, a Address) inferenceHost {
+	for _, h := range hosts {
+		if h.addr == a {
+			return h
+		}
+	}
+	return inferenceHost{}
+}
+
+func TestRegisterModelHostRequiresBondAndKey(t *testing.T) {
+	ai, st, model, _ := newInferenceFixture(t, 0)
+	pub, _, _ := ed25519.GenerateKey(nil)
+	addr := Ed25519Address(pub)
+	_ = st.Mint(addr, MinModelHostBond)
+
+	if err := ai.RegisterModelHost(model, Address{0x01}, pub, "", MinModelHostBond); err == nil {
+		t.Fatal("host registered under an address that is not its key")
+	}
+	if err := ai.RegisterModelHost(model, addr, pub, "", MinModelHostBond-1); err == nil {
+		t.Fatal("host registered below the minimum bond")
+	}
+	if err := ai.RegisterModelHost(model, addr, pub, "", MinModelHostBond); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	if st.BalanceOf(addr) != 0 || st.BalanceOf(inferenceAccount()) != MinModelHostBond {
+		t.Fatalf("bond not locked: host=%d escrow=%d", st.BalanceOf(addr), st.BalanceOf(inferenceAccount()))
+	}
+	if err := ai.UnregisterModelHost(model, addr); err != nil {
+		t.Fatalf("unregister: %v", err)
+	}
+	if st.BalanceOf(addr) != MinModelHostBond {
+		t.Fatalf("bond not returned: %d", st.BalanceOf(addr))
+	}
+}
+
+func TestOverturnedInferenceSlashesHost(t *testing.T) {
+	ai, st, model, hosts := newInferenceFixture(t, 4)
+	requester, challenger := Address{0xa1}, Address{0xc1}
+	_ = st.Mint(requester, 100)
+	_ = st.Mint(challenger, 100)
+
+	req, err := ai.RequestInference(model, requester, [32]byte{0x01}, "", 100)
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	host := hostByAddr(hosts, req.Host)
+	if _, err := ai.SubmitInferenceResult(req.ID, host.commit(req, 0xba)); err != nil {
+		t.Fatalf("result: %v", err)
+	}
+	if req, err = ai.DisputeInference(req.ID, challenger, 100); err != nil {
+		t.Fatalf("dispute: %v", err)
+	}
+	if len(req.Verifiers) != InferenceVerifiers || containsAddr(req.Verifiers, req.Host) {
+		t.Fatalf("verifiers %x", req.Verifiers)
+	}
+	for _, v := range req.Verifiers[:2] {
+		if req, err = ai.SubmitReexecution(req.ID, hostByAddr(hosts, v).commit(req, 0x60)); err != nil {
+			t.Fatalf("reexec: %v", err)
+		}
+	}
+	if req.Status != InferenceOverturned {
+		t.Fatalf("status %s", req.Status)
+	}
+	slash := MinModelHostBond * InferenceHostSlashBp / 10_000
+	if st.BalanceOf(requester) != 100 || st.BalanceOf(challenger) != 100+slash {
+		t.Fatalf("requester=%d challenger=%d", st.BalanceOf(requester), st.BalanceOf(challenger))
+	}
+	h, _ := ai.modelHost(model, req.Host)
+	if h.Bond != MinModelHostBond-slash {
+		t.Fatalf("host bond %d", h.Bond)
+	}
+	bonded, _ := ai.bondedHosts(model)
+	for _, b := range bonded {
+		if b.Host == req.Host {
+			t.Fatal("slashed host still assigned requests")
+		}
+	}
+}
+
+func TestExpiredReexecutionRefundsBothSides(t *testing.T) {
+	ai, st, model, hosts := newInferenceFixture(t, 2)
+	requester, challenger := Address{0xa2}, Address{0xc2}
+	_ = st.Mint(requester, 50)
+	_ = st.Mint(challenger, 80)
+
+	req, err := ai.RequestInference(model, requester, [32]byte{0x02}, "", 50)
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	if _, err := ai.SubmitInferenceResult(req.ID, hostByAddr(hosts, req.Host).commit(req, 1)); err != nil {
+		t.Fatalf("result: %v", err)
+	}
+	if req, err = ai.DisputeInference(req.ID, challenger, 80); err != nil {
+		t.Fatalf("dispute: %v", err)
+	}
+	if _, err := ai.FinalizeInference(req.ID); err == nil {
+		t.Fatal("dispute refunded before the re-execution deadline")
+	}
+	if err := ai.UnregisterModelHost(model, req.Host); err == nil {
+		t.Fatal("host left with a dispute open")
+	}
+
+	req.ReexecEnd = time.Now().Add(-time.Second)
+	if err := ai.putInference(req); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ai.SubmitReexecution(req.ID, hostByAddr(hosts, req.Verifiers[0]).commit(req, 1)); err == nil {
+		t.Fatal("re-execution accepted after the deadline")
+	}
+	if req, err = ai.FinalizeInference(req.ID); err != nil {
+		t.Fatalf("expire: %v", err)
+	}
+	if req.Status != InferenceExpired || st.BalanceOf(requester) != 50 || st.BalanceOf(challenger) != 80 {
+		t.Fatalf("status=%s requester=%d challenger=%d", req.Status, st.BalanceOf(requester), st.BalanceOf(challenger))
+	}
+}
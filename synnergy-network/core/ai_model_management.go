@@ -3,6 +3,7 @@ package core
 import (
 	"encoding/json"
 	"fmt"
+	"time"
 )
 
 // GetModelListing fetches a marketplace listing by ID.
@@ -16,6 +17,7 @@ func GetModelListing(id string) (ModelListing, error) {
 	if err := json.Unmarshal(raw, &m); err != nil {
 		return ModelListing{}, err
 	}
+	m.applyPendingPrice(time.Now())
 	return m, nil
 }
 
@@ -29,12 +31,15 @@ func ListModelListings() ([]ModelListing, error) {
 		if err := json.Unmarshal(it.Value(), &m); err != nil {
 			return nil, err
 		}
+		m.applyPendingPrice(time.Now())
 		out = append(out, m)
 	}
 	return out, it.Error()
 }
 
-// UpdateListingPrice updates the price of an existing listing.
+// UpdateListingPrice schedules a new price for an existing listing. The
+// change takes effect after ListingPriceTimelock; until then buyers keep
+// paying the current price. Scheduling again replaces the pending change.
 func UpdateListingPrice(id string, seller Address, price uint64) error {
 	key := fmt.Sprintf("ai_marketplace:listing:%s", id)
 	raw, err := CurrentStore().Get([]byte(key))
@@ -48,7 +53,10 @@ func UpdateListingPrice(id string, seller Address, price uint64) error {
 	if m.Seller != seller {
 		return fmt.Errorf("seller mismatch")
 	}
-	m.Price = price
+	now := time.Now().UTC()
+	m.applyPendingPrice(now)
+	m.PendingPrice = price
+	m.PriceEffective = now.Add(ListingPriceTimelock)
 	updated, _ := json.Marshal(m)
 	return CurrentStore().Set([]byte(key), updated)
 }
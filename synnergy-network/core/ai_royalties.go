@@ -0,0 +1,185 @@
+package core
+
+// ai_royalties.go – royalty enforcement and usage accounting for the AI model
+// marketplace. Every sale, rental or paid inference against a published model
+// is split between the model creator (RoyaltyBp) and the seller/host, and the
+// outcome is recorded in per-model usage counters and per-creator earnings.
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ListingPriceTimelock delays listing price changes so buyers can react
+// before a new price takes effect.
+const ListingPriceTimelock = 24 * time.Hour
+
+// ModelUsageKind identifies what a usage counter tracks.
+type ModelUsageKind string
+
+const (
+	ModelUsageSale      ModelUsageKind = "sale"
+	ModelUsageRental    ModelUsageKind = "rental"
+	ModelUsageInference ModelUsageKind = "inference"
+)
+
+// ModelUsage aggregates activity and revenue for a published model.
+type ModelUsage struct {
+	Model       [32]byte  `json:"model"`
+	Sales       uint64    `json:"sales"`
+	Rentals     uint64    `json:"rentals"`
+	Inferences  uint64    `json:"inferences"`
+	Royalties   uint64    `json:"royalties"`    // paid to the creator
+	HostRevenue uint64    `json:"host_revenue"` // paid to sellers / hosts
+	LastUsed    time.Time `json:"last_used"`
+}
+
+// CreatorEarnings totals the royalties paid to a model creator.
+type CreatorEarnings struct {
+	Creator  Address           `json:"creator"`
+	Total    uint64            `json:"total"`
+	PerModel map[string]uint64 `json:"per_model"`
+}
+
+var modelUsageMu sync.Mutex
+
+func modelUsageKey(model [32]byte) []byte { return []byte(fmt.Sprintf("ai:usage:%x", model)) }
+func creatorEarningsKey(a Address) []byte {
+	return []byte("ai:earnings:" + hex.EncodeToString(a[:]))
+}
+
+// ModelHashForCID derives the model identifier PublishModel assigns to cid.
+func ModelHashForCID(cid string) [32]byte { return sha256.Sum256([]byte(cid)) }
+
+// modelRoyalty returns the creator and royalty share of amount for a model.
+// Unknown models pay no royalty.
+func modelRoyalty(model [32]byte, amount uint64) (Address, uint64) {
+	ai := AI()
+	if ai == nil {
+		return Address{}, 0
+	}
+	meta, ok := ai.modelMeta(model)
+	if !ok || meta.RoyaltyBp == 0 {
+		return Address{}, 0
+	}
+	return meta.Creator, amount * uint64(meta.RoyaltyBp) / 10_000
+}
+
+// RecordModelUsage bumps the usage counter for kind and books the royalty
+// and host share of a payment against the model and its creator.
+func RecordModelUsage(model [32]byte, kind ModelUsageKind, royalty, hostShare uint64) error {
+	modelUsageMu.Lock()
+	defer modelUsageMu.Unlock()
+
+	u, err := GetModelUsage(model)
+	if err != nil {
+		return err
+	}
+	switch kind {
+	case ModelUsageSale:
+		u.Sales++
+	case ModelUsageRental:
+		u.Rentals++
+	case ModelUsageInference:
+		u.Inferences++
+	default:
+		return fmt.Errorf("unknown usage kind %q", kind)
+	}
+	u.Royalties += royalty
+	u.HostRevenue += hostShare
+	u.LastUsed = time.Now().UTC()
+	raw, _ := json.Marshal(u)
+	if err := CurrentStore().Set(modelUsageKey(model), raw); err != nil {
+		return err
+	}
+	if royalty == 0 {
+		return nil
+	}
+
+	ai := AI()
+	if ai == nil {
+		return nil
+	}
+	meta, ok := ai.modelMeta(model)
+	if !ok {
+		return nil
+	}
+	e, err := GetCreatorEarnings(meta.Creator)
+	if err != nil {
+		return err
+	}
+	e.Total += royalty
+	e.PerModel[fmt.Sprintf("%x", model)] += royalty
+	raw, _ = json.Marshal(e)
+	return CurrentStore().Set(creatorEarningsKey(meta.Creator), raw)
+}
+
+// GetModelUsage returns the usage counters for a model.
+func GetModelUsage(model [32]byte) (*ModelUsage, error) {
+	raw, err := CurrentStore().Get(modelUsageKey(model))
+	if err != nil || raw == nil {
+		return &ModelUsage{Model: model}, nil
+	}
+	var u ModelUsage
+	if err := json.Unmarshal(raw, &u); err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+// GetCreatorEarnings returns the royalties accrued by a model creator.
+func GetCreatorEarnings(creator Address) (*CreatorEarnings, error) {
+	e := &CreatorEarnings{Creator: creator, PerModel: make(map[string]uint64)}
+	raw, err := CurrentStore().Get(creatorEarningsKey(creator))
+	if err != nil || raw == nil {
+		return e, nil
+	}
+	if err := json.Unmarshal(raw, e); err != nil {
+		return nil, err
+	}
+	if e.PerModel == nil {
+		e.PerModel = make(map[string]uint64)
+	}
+	return e, nil
+}
+
+// payModelRevenue moves amount out of the marketplace escrow account and
+// splits it between creator royalty and payee, then records usage.
+func payModelRevenue(ctx *Context, model [32]byte, kind ModelUsageKind, from, payee Address, amount uint64) error {
+	creator, royalty := modelRoyalty(model, amount)
+	if royalty > 0 {
+		if err := Transfer(ctx, AssetRef{Kind: AssetCoin}, from, creator, royalty); err != nil {
+			return fmt.Errorf("royalty transfer: %w", err)
+		}
+	}
+	if err := Transfer(ctx, AssetRef{Kind: AssetCoin}, from, payee, amount-royalty); err != nil {
+		return err
+	}
+	var zero [32]byte
+	if model == zero {
+		return nil
+	}
+	return RecordModelUsage(model, kind, royalty, amount-royalty)
+}
+
+// EffectivePrice returns the listing price in force at now, applying a
+// scheduled price change once its timelock has expired.
+func (m *ModelListing) EffectivePrice(now time.Time) uint64 {
+	if !m.PriceEffective.IsZero() && !now.Before(m.PriceEffective) {
+		return m.PendingPrice
+	}
+	return m.Price
+}
+
+// applyPendingPrice folds a matured price change into Price.
+func (m *ModelListing) applyPendingPrice(now time.Time) {
+	if !m.PriceEffective.IsZero() && !now.Before(m.PriceEffective) {
+		m.Price = m.PendingPrice
+		m.PendingPrice = 0
+		m.PriceEffective = time.Time{}
+	}
+}
@@ -0,0 +1,89 @@
+package core
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestPayModelRevenueSplitsRoyalty(t *testing.T) {
+	st, _ := NewInMemory()
+	creator, seller, escrow := Address{0xc0}, Address{0x5e}, ModuleAddress("ai_marketplace")
+	model := ModelHashForCID("bafyroyalty")
+
+	prev := engine
+	engine = &AIEngine{led: st, models: map[[32]byte]ModelMeta{model: {CID: "bafyroyalty", Creator: creator, RoyaltyBp: 1_250}}}
+	SetStore(NewInMemoryStore())
+	t.Cleanup(func() { engine = prev; SetStore(nil) })
+
+	_ = st.Mint(escrow, 2_000)
+	ctx := &Context{State: st}
+	if err := payModelRevenue(ctx, model, ModelUsageSale, escrow, seller, 1_000); err != nil {
+		t.Fatalf("sale: %v", err)
+	}
+	if err := payModelRevenue(ctx, model, ModelUsageRental, escrow, seller, 999); err != nil {
+		t.Fatalf("rental: %v", err)
+	}
+
+	// 12.5% of 1000 and of 999, rounded down
+	if got := st.BalanceOf(creator); got != 125+124 {
+		t.Fatalf("creator royalty = %d", got)
+	}
+	if got := st.BalanceOf(seller); got != 875+875 {
+		t.Fatalf("seller share = %d", got)
+	}
+	if got := st.BalanceOf(escrow); got != 1 {
+		t.Fatalf("escrow left = %d", got)
+	}
+
+	u, _ := GetModelUsage(model)
+	if u.Sales != 1 || u.Rentals != 1 || u.Royalties != 249 || u.HostRevenue != 1_750 {
+		t.Fatalf("usage %+v", u)
+	}
+	e, _ := GetCreatorEarnings(creator)
+	if e.Total != 249 || e.PerModel[fmt.Sprintf("%x", model)] != 249 {
+		t.Fatalf("earnings %+v", e)
+	}
+}
+
+func TestPayModelRevenueWithoutRoyalty(t *testing.T) {
+	st, _ := NewInMemory()
+	seller, escrow := Address{0x5e}, ModuleAddress("ai_marketplace")
+	model := ModelHashForCID("bafyfree")
+
+	prev := engine
+	engine = &AIEngine{led: st, models: map[[32]byte]ModelMeta{model: {CID: "bafyfree", Creator: Address{0xc0}}}}
+	SetStore(NewInMemoryStore())
+	t.Cleanup(func() { engine = prev; SetStore(nil) })
+
+	_ = st.Mint(escrow, 500)
+	if err := payModelRevenue(&Context{State: st}, model, ModelUsageSale, escrow, seller, 500); err != nil {
+		t.Fatalf("sale: %v", err)
+	}
+	if st.BalanceOf(seller) != 500 || st.BalanceOf(Address{0xc0}) != 0 {
+		t.Fatalf("seller=%d creator=%d", st.BalanceOf(seller), st.BalanceOf(Address{0xc0}))
+	}
+	if err := payModelRevenue(&Context{State: st}, model, ModelUsageSale, escrow, seller, 1); err == nil {
+		t.Fatal("payment beyond escrow balance succeeded")
+	}
+}
+
+func TestListingPriceTimelock(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	m := &ModelListing{Price: 100, PendingPrice: 300, PriceEffective: now.Add(ListingPriceTimelock)}
+
+	if got := m.EffectivePrice(now); got != 100 {
+		t.Fatalf("price before timelock = %d", got)
+	}
+	m.applyPendingPrice(now.Add(time.Hour))
+	if m.Price != 100 || m.PendingPrice != 300 {
+		t.Fatalf("price applied early: %+v", m)
+	}
+	if got := m.EffectivePrice(now.Add(ListingPriceTimelock)); got != 300 {
+		t.Fatalf("price after timelock = %d", got)
+	}
+	m.applyPendingPrice(now.Add(ListingPriceTimelock))
+	if m.Price != 300 || m.PendingPrice != 0 || !m.PriceEffective.IsZero() {
+		t.Fatalf("pending price not folded: %+v", m)
+	}
+}
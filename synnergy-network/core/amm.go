@@ -37,6 +37,10 @@ type PoolFixture struct {
 	FeeBps uint16  `json:"feeBps"`
 	ResA   uint64  `json:"resA"`
 	ResB   uint64  `json:"resB"`
+	// Type is "constant_product" (default) or "stable_swap"; Amp is the
+	// stable-swap amplification.
+	Type string `json:"type,omitempty"`
+	Amp  uint64 `json:"amp,omitempty"`
 }
 
 // InitPoolsFromFile initialises the AMM manager if needed and loads pools from
@@ -59,7 +63,11 @@ func InitPoolsFromFile(path string) error {
 		InitAMM(log.StandardLogger(), ledger)
 	}
 	for _, f := range fixtures {
-		pid, err := Manager().CreatePool(f.TokenA, f.TokenB, f.FeeBps)
+		kind, err := ParsePoolType(f.Type)
+		if err != nil {
+			return err
+		}
+		pid, err := Manager().CreatePool(f.TokenA, f.TokenB, f.FeeBps, PoolSpec{Type: kind, Amp: f.Amp})
 		if err != nil {
 			return err
 		}
@@ -67,7 +75,11 @@ func InitPoolsFromFile(path string) error {
 		p.resA = f.ResA
 		p.resB = f.ResB
 		if f.ResA > 0 && f.ResB > 0 {
-			p.totalLP = uint64(math.Sqrt(float64(f.ResA * f.ResB)))
+			if kind == PoolStableSwap {
+				p.totalLP = stableD(p.amp, u64(f.ResA), u64(f.ResB)).Uint64()
+			} else {
+				p.totalLP = uint64(math.Sqrt(float64(f.ResA * f.ResB)))
+			}
 		}
 		registerPoolForRouting(p)
 	}
@@ -162,15 +174,24 @@ func SwapExactIn(trader Address, tokenIn TokenID, amtIn uint64, tokenOut TokenID
 	if amount < minOut {
 		return 0, errors.New("slippage final")
 	}
+	publishEvent(TopicAMM, currentHeight(), AMMEventData{Action: "swap", Trader: trader, TokenIn: tokenIn, TokenOut: tokenOut, AmountIn: amtIn, AmountOut: amount})
 	return amount, nil
 }
 
 func AddLiquidity(pid PoolID, provider Address, amtA, amtB uint64) (uint64, error) {
-	return Manager().AddLiquidity(pid, provider, amtA, amtB)
+	lp, err := Manager().AddLiquidity(pid, provider, amtA, amtB)
+	if err == nil {
+		publishEvent(TopicAMM, currentHeight(), AMMEventData{Action: "add_liquidity", Pool: pid, Trader: provider, AmountIn: amtA + amtB, AmountOut: lp})
+	}
+	return lp, err
 }
 
 func RemoveLiquidity(pid PoolID, provider Address, lp uint64) (uint64, uint64, error) {
-	return Manager().RemoveLiquidity(pid, provider, lp)
+	a, b, err := Manager().RemoveLiquidity(pid, provider, lp)
+	if err == nil {
+		publishEvent(TopicAMM, currentHeight(), AMMEventData{Action: "remove_liquidity", Pool: pid, Trader: provider, AmountIn: lp, AmountOut: a + b})
+	}
+	return a, b, err
 }
 
 // Quote returns expected output amount for given path (without fees rounding).
@@ -191,8 +212,15 @@ func Quote(tokenIn TokenID, amtIn uint64, tokenOut TokenID, maxHops int) (uint64
 		}
 		feeAdj := 1 - float64(p.feeBps)/10_000
 		amtWithFee := amt * feeAdj
-		out := (amtWithFee * float64(reserveOut)) / (float64(reserveIn) + amtWithFee)
-		amt = out
+		if p.kind == PoolStableSwap {
+			out, err := p.outGivenIn(reserveIn, reserveOut, uint64(amtWithFee))
+			if err != nil {
+				return 0, err
+			}
+			amt = float64(out)
+		} else {
+			amt = (amtWithFee * float64(reserveOut)) / (float64(reserveIn) + amtWithFee)
+		}
 		if cur == p.tokenA {
 			cur = p.tokenB
 		} else {
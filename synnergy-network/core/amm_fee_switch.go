@@ -0,0 +1,199 @@
+package core
+
+// amm_fee_switch.go – governance controlled protocol fee switch.
+//
+// Of the swap fee that stays with liquidity providers, a governed fraction
+// (ShareBps) can be taken by the protocol:
+//
+//   - FeeSwitchMintLP mints LP units worth that fraction to the LoanPool
+//     treasury, making it a protocol-owned liquidity position.
+//   - FeeSwitchCollector transfers the fraction out of the pool to a
+//     collector address.
+//
+// The switch is set through the amm_fee_switch governance parameter with a
+// JSON FeeSwitch value. Pool 0 is the default for every pool; a non-zero
+// Pool overrides it for that pool only. Amounts taken are accumulated on the
+// pool and exposed through PoolView and TreasuryReport.
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// FeeSwitchMode selects where the protocol fee share goes.
+type FeeSwitchMode string
+
+const (
+	FeeSwitchOff       FeeSwitchMode = "off"
+	FeeSwitchMintLP    FeeSwitchMode = "mint_lp"
+	FeeSwitchCollector FeeSwitchMode = "collector"
+
+	maxFeeSwitchShareBps = 5_000
+	feeSwitchPrefix      = "amm:feeswitch:"
+)
+
+// FeeSwitch is the governed protocol fee configuration of a pool.
+type FeeSwitch struct {
+	Pool      PoolID        `json:"pool"` // 0 = default for all pools
+	Mode      FeeSwitchMode `json:"mode"`
+	ShareBps  uint16        `json:"share_bps"`
+	Collector Address       `json:"collector,omitempty"`
+}
+
+// Validate checks the switch parameters.
+func (f FeeSwitch) Validate() error {
+	switch f.Mode {
+	case FeeSwitchOff:
+		return nil
+	case FeeSwitchMintLP, FeeSwitchCollector:
+	default:
+		return fmt.Errorf("unknown fee switch mode %q", f.Mode)
+	}
+	if f.ShareBps == 0 || f.ShareBps > maxFeeSwitchShareBps {
+		return fmt.Errorf("share must be 1-%d bps", maxFeeSwitchShareBps)
+	}
+	if f.Mode == FeeSwitchCollector && f.Collector == (Address{}) {
+		return errors.New("collector address required")
+	}
+	return nil
+}
+
+func feeSwitchKey(pid PoolID) []byte {
+	return []byte(fmt.Sprintf("%s%010d", feeSwitchPrefix, pid))
+}
+
+// setFeeSwitch stores a fee switch. Only reached via governance.
+func setFeeSwitch(value string) error {
+	var f FeeSwitch
+	if err := json.Unmarshal([]byte(value), &f); err != nil {
+		return fmt.Errorf("invalid fee switch: %w", err)
+	}
+	if err := f.Validate(); err != nil {
+		return err
+	}
+	a := Manager()
+	if a == nil || a.ledger == nil {
+		return errors.New("AMM not initialised")
+	}
+	if f.Pool != 0 {
+		if _, err := a.Pool(f.Pool); err != nil {
+			return err
+		}
+	}
+	raw, _ := json.Marshal(f)
+	return a.ledger.SetState(feeSwitchKey(f.Pool), raw)
+}
+
+// FeeSwitchFor returns the switch in effect for a pool: its own override or
+// the default.
+func (a *AMM) FeeSwitchFor(pid PoolID) FeeSwitch {
+	off := FeeSwitch{Pool: pid, Mode: FeeSwitchOff}
+	if a.ledger == nil {
+		return off
+	}
+	for _, key := range [][]byte{feeSwitchKey(pid), feeSwitchKey(0)} {
+		raw, err := a.ledger.GetState(key)
+		if err != nil || len(raw) == 0 {
+			continue
+		}
+		var f FeeSwitch
+		if json.Unmarshal(raw, &f) == nil {
+			f.Pool = pid
+			return f
+		}
+	}
+	return off
+}
+
+// distributeLPFee splits an LP fee that has already been added to the pool
+// reserve resIn between liquidity providers and the protocol according to
+// the pool's fee switch. Called inside the Swap ledger snapshot.
+func (a *AMM) distributeLPFee(pool *Pool, tokenIn TokenID, resIn *uint64, lpFee uint64) error {
+	sw := a.FeeSwitchFor(pool.ID)
+	var protocol uint64
+	if sw.Mode != FeeSwitchOff {
+		protocol = lpFee * uint64(sw.ShareBps) / 10_000
+	}
+	// Fee growth is recorded against the supply before any protocol mint.
+	if err := a.recordLPFee(pool, tokenIn, lpFee-protocol); err != nil {
+		return err
+	}
+	if protocol == 0 {
+		return nil
+	}
+	switch sw.Mode {
+	case FeeSwitchCollector:
+		*resIn -= protocol
+		if err := transferToken(tokenIn, poolAccount(pool.ID), sw.Collector, protocol); err != nil {
+			return err
+		}
+	case FeeSwitchMintLP:
+		// Mint LP so the treasury's share of the pool equals the protocol
+		// fee's share of pool value (2 × reserve of the fee token):
+		// minted / (total + minted) = protocol / (2·res).
+		if pool.totalLP == 0 || 2*(*resIn) <= protocol {
+			return nil
+		}
+		minted := pool.totalLP * protocol / (2*(*resIn) - protocol)
+		if minted == 0 {
+			return nil
+		}
+		if err := a.ledger.MintLP(LoanPoolAccount, pool.ID, minted); err != nil {
+			return err
+		}
+		pool.totalLP += minted
+		pool.protoLP += minted
+	}
+	if tokenIn == pool.tokenA {
+		pool.protoFeeA += protocol
+	} else {
+		pool.protoFeeB += protocol
+	}
+	return nil
+}
+
+// TreasuryPoolReport is the protocol's take from one pool.
+type TreasuryPoolReport struct {
+	FeeSwitch
+	TokenA       TokenID `json:"token_a"`
+	TokenB       TokenID `json:"token_b"`
+	ProtocolFeeA uint64  `json:"protocol_fee_a"`
+	ProtocolFeeB uint64  `json:"protocol_fee_b"`
+	ProtocolLP   uint64  `json:"protocol_lp"`
+	// Share is ProtocolLP over the pool LP supply; UnderlyingA/B is what
+	// that LP would redeem for now.
+	Share       float64 `json:"share"`
+	UnderlyingA uint64  `json:"underlying_a"`
+	UnderlyingB uint64  `json:"underlying_b"`
+}
+
+// TreasuryReport summarises protocol fees and protocol-owned liquidity for
+// every pool, ordered by pool ID.
+func TreasuryReport() ([]TreasuryPoolReport, error) {
+	a := Manager()
+	if a == nil {
+		return nil, errors.New("AMM not initialised")
+	}
+	views := a.Snapshot()
+	out := make([]TreasuryPoolReport, 0, len(views))
+	for _, p := range views {
+		r := TreasuryPoolReport{
+			FeeSwitch:    a.FeeSwitchFor(p.ID),
+			TokenA:       p.TokenA,
+			TokenB:       p.TokenB,
+			ProtocolFeeA: p.ProtocolFeeA,
+			ProtocolFeeB: p.ProtocolFeeB,
+			ProtocolLP:   p.ProtocolLP,
+		}
+		if p.TotalLP > 0 {
+			r.Share = float64(p.ProtocolLP) / float64(p.TotalLP)
+			r.UnderlyingA = p.ProtocolLP * p.ResA / p.TotalLP
+			r.UnderlyingB = p.ProtocolLP * p.ResB / p.TotalLP
+		}
+		out = append(out, r)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Pool < out[j].Pool })
+	return out, nil
+}
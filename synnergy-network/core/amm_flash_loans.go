@@ -0,0 +1,215 @@
+package core
+
+// amm_flash_loans.go – uncollateralised flash loans from AMM pool reserves.
+//
+// A flash loan hands a borrower part of a pool reserve and runs a callback
+// inside the same ledger snapshot. When the callback returns the pool
+// account must hold the borrowed amount plus the fee again; otherwise the
+// snapshot is rolled back and the loan never happened. Contracts borrow
+// through FlashLoan, which calls the borrower's onFlashLoan entry point in a
+// nested VM frame of the same transaction.
+//
+// While a loan is outstanding the pool is locked: swaps, liquidity changes
+// and further flash loans against it fail with ErrPoolLocked, so the
+// callback cannot move the pool price it is measured against.
+//
+// The fee is a governed parameter (amm_flash_fee_bps, see UpdateParam) and
+// accrues entirely to liquidity providers.
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/bits"
+	"strconv"
+)
+
+const (
+	defaultFlashFeeBps = 9 // 0.09 %
+	maxFlashFeeBps     = 1_000
+	flashLoanGas       = 500_000
+)
+
+var flashFeeKey = []byte("amm:flashfee")
+
+var (
+	// ErrPoolLocked is returned for pool operations while a flash loan
+	// against the pool is outstanding.
+	ErrPoolLocked = errors.New("pool locked by flash loan")
+	// ErrFlashLoanNotRepaid is returned when the callback leaves the pool
+	// short of the loan plus fee.
+	ErrFlashLoanNotRepaid = errors.New("flash loan not repaid")
+)
+
+// FlashLoanCallback runs with the borrowed funds and must return amount+fee
+// of token to the pool account (see PoolAccount) before returning.
+type FlashLoanCallback func(token TokenID, amount, fee uint64) error
+
+// PoolAccount returns the address holding a pool's reserves.
+func PoolAccount(pid PoolID) Address { return poolAccount(pid) }
+
+// FlashFeeBps returns the governed flash loan fee.
+func (a *AMM) FlashFeeBps() uint16 {
+	if a.ledger == nil {
+		return defaultFlashFeeBps
+	}
+	raw, err := a.ledger.GetState(flashFeeKey)
+	if err != nil || len(raw) == 0 {
+		return defaultFlashFeeBps
+	}
+	v, err := strconv.ParseUint(string(raw), 10, 16)
+	if err != nil {
+		return defaultFlashFeeBps
+	}
+	return uint16(v)
+}
+
+// setFlashFeeBps stores the flash loan fee. Only reached via governance.
+func setFlashFeeBps(value string) error {
+	v, err := strconv.ParseUint(value, 10, 16)
+	if err != nil {
+		return fmt.Errorf("invalid uint: %w", err)
+	}
+	if v > maxFlashFeeBps {
+		return fmt.Errorf("flash fee %d bps exceeds %d", v, maxFlashFeeBps)
+	}
+	a := Manager()
+	if a == nil || a.ledger == nil {
+		return errors.New("AMM not initialised")
+	}
+	return a.ledger.SetState(flashFeeKey, []byte(strconv.FormatUint(v, 10)))
+}
+
+// FlashFee returns the fee charged for borrowing amount, rounded up. The
+// product is taken in 128 bits so large amounts do not wrap around.
+func (a *AMM) FlashFee(amount uint64) uint64 {
+	hi, lo := bits.Mul64(amount, uint64(a.FlashFeeBps()))
+	lo, carry := bits.Add64(lo, 9_999, 0)
+	// hi < bps <= maxFlashFeeBps < 10_000, so the quotient fits in 64 bits
+	fee, _ := bits.Div64(hi+carry, lo, 10_000)
+	return fee
+}
+
+func (p *Pool) lock() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.locked {
+		return ErrPoolLocked
+	}
+	p.locked = true
+	return nil
+}
+
+func (p *Pool) unlock() {
+	p.mu.Lock()
+	p.locked = false
+	p.mu.Unlock()
+}
+
+func (p *Pool) isLocked() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.locked
+}
+
+// FlashLoan lends amount of token from pool pid to borrower and runs cb.
+// The whole loan executes in one ledger snapshot and is reverted unless the
+// pool account is repaid amount plus fee when cb returns.
+func (a *AMM) FlashLoan(pid PoolID, borrower Address, token TokenID, amount uint64, cb FlashLoanCallback) (fee uint64, err error) {
+	a.mu.RLock()
+	pool, ok := a.pools[pid]
+	a.mu.RUnlock()
+	if !ok {
+		return 0, errors.New("pool not found")
+	}
+	if amount == 0 {
+		return 0, errors.New("amount zero")
+	}
+	var reserve *uint64
+	switch token {
+	case pool.tokenA:
+		reserve = &pool.resA
+	case pool.tokenB:
+		reserve = &pool.resB
+	default:
+		return 0, errors.New("token not in pool")
+	}
+	if err := pool.lock(); err != nil {
+		return 0, err
+	}
+	defer pool.unlock()
+	pool.mu.RLock()
+	available := *reserve
+	pool.mu.RUnlock()
+	if amount >= available {
+		return 0, errors.New("insufficient reserves")
+	}
+
+	tok, ok := GetToken(token)
+	if !ok {
+		return 0, fmt.Errorf("token %d not found", token)
+	}
+	fee = a.FlashFee(amount)
+	acct := poolAccount(pid)
+	err = a.ledger.Snapshot(func() error {
+		before := tok.BalanceOf(acct)
+		if err := tok.Transfer(acct, borrower, amount); err != nil {
+			return err
+		}
+		if err := cb(token, amount, fee); err != nil {
+			return fmt.Errorf("flash loan callback: %w", err)
+		}
+		if after := tok.BalanceOf(acct); after < before || after-before < fee {
+			return fmt.Errorf("%w: pool holds %d, owed %d plus a fee of %d", ErrFlashLoanNotRepaid, after, before, fee)
+		}
+		pool.mu.Lock()
+		defer pool.mu.Unlock()
+		*reserve += fee
+		if err := a.recordLPFee(pool, token, fee); err != nil {
+			*reserve -= fee
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	publishEvent(TopicAMM, currentHeight(), AMMEventData{Action: "flash_loan", Pool: pid, Trader: borrower, TokenIn: token, AmountIn: amount, AmountOut: fee})
+	return fee, nil
+}
+
+// flashLoanSelector identifies the onFlashLoan entry point of a borrower
+// contract.
+var flashLoanSelector = func() []byte {
+	h := sha256.Sum256([]byte("onFlashLoan(address,uint32,uint64,uint64,bytes)"))
+	return h[:4]
+}()
+
+// FlashLoanCallData encodes the onFlashLoan call a borrower contract
+// receives: selector | pool account | token | amount | fee | data.
+func FlashLoanCallData(pid PoolID, token TokenID, amount, fee uint64, data []byte) []byte {
+	acct := poolAccount(pid)
+	out := make([]byte, 0, 4+len(acct)+4+16+len(data))
+	out = append(out, flashLoanSelector...)
+	out = append(out, acct[:]...)
+	out = binary.BigEndian.AppendUint32(out, uint32(token))
+	out = binary.BigEndian.AppendUint64(out, amount)
+	out = binary.BigEndian.AppendUint64(out, fee)
+	return append(out, data...)
+}
+
+// FlashLoan borrows from a pool on behalf of the borrower contract, calling
+// its onFlashLoan entry point with FlashLoanCallData. The contract runs in a
+// nested VM frame inside the loan snapshot and must transfer amount+fee back
+// to the pool account before returning.
+func FlashLoan(pid PoolID, borrower Address, token TokenID, amount uint64, data []byte) (uint64, error) {
+	a := Manager()
+	if a == nil {
+		return 0, errors.New("AMM not initialised")
+	}
+	return a.FlashLoan(pid, borrower, token, amount, func(token TokenID, amount, fee uint64) error {
+		_, err := a.ledger.Call(poolAccount(pid), borrower, FlashLoanCallData(pid, token, amount, fee, data), nil, flashLoanGas)
+		return err
+	})
+}
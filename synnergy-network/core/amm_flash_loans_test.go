@@ -0,0 +1,178 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+// flashState is a map-backed StateRW whose Snapshot restores the map when
+// fn fails. Writes to failKey fail.
+type flashState struct {
+	StateRW
+	kv      map[string][]byte
+	failKey string
+}
+
+func (s *flashState) GetState(k []byte) ([]byte, error) { return s.kv[string(k)], nil }
+
+func (s *flashState) SetState(k, v []byte) error {
+	if s.failKey != "" && string(k) == s.failKey {
+		return errors.New("store unavailable")
+	}
+	s.kv[string(k)] = v
+	return nil
+}
+
+func (s *flashState) Snapshot(fn func() error) error {
+	saved := make(map[string][]byte, len(s.kv))
+	for k, v := range s.kv {
+		saved[k] = v
+	}
+	if err := fn(); err != nil {
+		s.kv = saved
+		return err
+	}
+	return nil
+}
+
+// stateToken keeps its balances in a flashState so loan snapshots cover
+// them.
+type stateToken struct {
+	Token
+	id TokenID
+	st *flashState
+}
+
+func (t *stateToken) key(a Address) string { return fmt.Sprintf("%d:%s", t.id, a.Hex()) }
+
+func (t *stateToken) ID() TokenID { return t.id }
+
+func (t *stateToken) BalanceOf(a Address) uint64 {
+	return new(big.Int).SetBytes(t.st.kv[t.key(a)]).Uint64()
+}
+
+func (t *stateToken) Transfer(from, to Address, amount uint64) error {
+	if t.BalanceOf(from) < amount {
+		return ErrInsufficientBalance
+	}
+	t.st.kv[t.key(from)] = new(big.Int).SetUint64(t.BalanceOf(from) - amount).Bytes()
+	t.st.kv[t.key(to)] = new(big.Int).SetUint64(t.BalanceOf(to) + amount).Bytes()
+	return nil
+}
+
+// newFlashTest returns an AMM over a flashState with one pool holding
+// reserves of 1 000 000 of two state-backed tokens.
+func newFlashTest(t *testing.T) (*AMM, *Pool, *stateToken) {
+	t.Helper()
+	st := &flashState{kv: make(map[string][]byte)}
+	a := &AMM{logger: logrus.New(), ledger: st, pools: make(map[PoolID]*Pool), nextID: 1}
+	tokA := &stateToken{id: TokenID(0x7f000001), st: st}
+	tokB := &stateToken{id: TokenID(0x7f000002), st: st}
+	RegisterToken(tokA)
+	RegisterToken(tokB)
+	pid, err := a.CreatePool(tokA.id, tokB.id, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := a.pools[pid]
+	pool.resA, pool.resB, pool.totalLP = 1_000_000, 1_000_000, 1_000
+	acct := poolAccount(pid)
+	st.kv[tokA.key(acct)] = new(big.Int).SetUint64(1_000_000).Bytes()
+	st.kv[tokB.key(acct)] = new(big.Int).SetUint64(1_000_000).Bytes()
+	return a, pool, tokA
+}
+
+func TestFlashFeeDoesNotOverflow(t *testing.T) {
+	a := &AMM{}
+	for _, amount := range []uint64{0, 1, 10_000, 1 << 62, math.MaxUint64} {
+		want := new(big.Int).SetUint64(amount)
+		want.Mul(want, big.NewInt(defaultFlashFeeBps))
+		want.Add(want, big.NewInt(9_999))
+		want.Div(want, big.NewInt(10_000))
+		if got := a.FlashFee(amount); got != want.Uint64() {
+			t.Errorf("FlashFee(%d) = %d want %s", amount, got, want)
+		}
+	}
+}
+
+func TestFlashLoanAccruesTheFeeToLiquidityProviders(t *testing.T) {
+	a, pool, tok := newFlashTest(t)
+	borrower := Address{0xb0}
+	acct := poolAccount(pool.ID)
+	tok.st.kv[tok.key(borrower)] = big.NewInt(1_000).Bytes()
+
+	fee, err := a.FlashLoan(pool.ID, borrower, tok.id, 500_000, func(_ TokenID, amount, fee uint64) error {
+		if tok.BalanceOf(borrower) != 501_000 {
+			t.Errorf("borrower holds %d during the loan", tok.BalanceOf(borrower))
+		}
+		return tok.Transfer(borrower, acct, amount+fee)
+	})
+	if err != nil || fee != 450 {
+		t.Fatalf("loan: fee %d %v", fee, err)
+	}
+	if pool.resA != 1_000_450 || tok.BalanceOf(acct) != 1_000_450 || tok.BalanceOf(borrower) != 550 {
+		t.Fatalf("after the loan: reserve %d, pool %d, borrower %d", pool.resA, tok.BalanceOf(acct), tok.BalanceOf(borrower))
+	}
+	f, err := a.poolFees(a.ledger, pool.ID)
+	if err != nil || f.TotalA != 450 || f.GrowthA != 0.45 {
+		t.Fatalf("LP fees %+v %v", f, err)
+	}
+	if pool.isLocked() {
+		t.Fatal("pool still locked")
+	}
+}
+
+func TestFlashLoanShortfallIsRolledBack(t *testing.T) {
+	a, pool, tok := newFlashTest(t)
+	borrower := Address{0xb0}
+	acct := poolAccount(pool.ID)
+
+	// repays the loan without the fee
+	_, err := a.FlashLoan(pool.ID, borrower, tok.id, 500_000, func(_ TokenID, amount, _ uint64) error {
+		return tok.Transfer(borrower, acct, amount)
+	})
+	if !errors.Is(err, ErrFlashLoanNotRepaid) {
+		t.Fatalf("loan repaid without the fee: %v", err)
+	}
+	if pool.resA != 1_000_000 || tok.BalanceOf(acct) != 1_000_000 || tok.BalanceOf(borrower) != 0 {
+		t.Fatalf("after the shortfall: reserve %d, pool %d, borrower %d", pool.resA, tok.BalanceOf(acct), tok.BalanceOf(borrower))
+	}
+
+	// the fee cannot be recorded: the reserve keeps its old value
+	tok.st.kv[tok.key(borrower)] = big.NewInt(1_000).Bytes()
+	tok.st.failKey = string(lpPoolFeeKey(pool.ID))
+	_, err = a.FlashLoan(pool.ID, borrower, tok.id, 500_000, func(_ TokenID, amount, fee uint64) error {
+		return tok.Transfer(borrower, acct, amount+fee)
+	})
+	if err == nil || pool.resA != 1_000_000 || tok.BalanceOf(acct) != 1_000_000 {
+		t.Fatalf("failed fee record: %v, reserve %d, pool %d", err, pool.resA, tok.BalanceOf(acct))
+	}
+}
+
+func TestFlashLoanLocksThePool(t *testing.T) {
+	a, pool, tok := newFlashTest(t)
+	borrower := Address{0xb0}
+	_, err := a.FlashLoan(pool.ID, borrower, tok.id, 1_000, func(TokenID, uint64, uint64) error {
+		if _, err := a.FlashLoan(pool.ID, borrower, tok.id, 1_000, func(TokenID, uint64, uint64) error { return nil }); !errors.Is(err, ErrPoolLocked) {
+			t.Errorf("nested loan: %v", err)
+		}
+		if _, err := a.AddLiquidity(pool.ID, borrower, 1, 1); !errors.Is(err, ErrPoolLocked) {
+			t.Errorf("liquidity during the loan: %v", err)
+		}
+		return errors.New("abort")
+	})
+	if err == nil {
+		t.Fatal("aborted loan succeeded")
+	}
+	if pool.isLocked() || tok.BalanceOf(poolAccount(pool.ID)) != 1_000_000 {
+		t.Fatal("aborted loan left the pool locked or short")
+	}
+	if _, err := a.FlashLoan(pool.ID, borrower, tok.id, 1_000_000, nil); err == nil {
+		t.Fatal("loan of the whole reserve accepted")
+	}
+}
@@ -2,12 +2,18 @@ package core
 
 import (
 	"context"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"io"
+	"math/big"
 	"net/http"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/ethereum/go-ethereum/common"
 )
 
 // APINode exposes a HTTP API gateway backed by a network node and
@@ -20,6 +26,8 @@ import (
 type APINode struct {
 	node   *Node
 	ledger *Ledger
+	batch  BatchConfig
+	fees   *FeeOracle
 
 	srv *http.Server
 	mu  sync.Mutex
@@ -27,7 +35,19 @@ type APINode struct {
 
 // NewAPINode creates a new API node using the provided components.
 func NewAPINode(n *Node, led *Ledger) *APINode {
-	return &APINode{node: n, ledger: led}
+	a := &APINode{node: n, ledger: led}
+	if led != nil {
+		a.fees = NewFeeOracle(led, DefaultFeeWindow)
+	}
+	return a
+}
+
+// SetBatchConfig sets the limits of the /batch endpoint. It must be called
+// before APINode_Start.
+func (a *APINode) SetBatchConfig(cfg BatchConfig) {
+	a.mu.Lock()
+	a.batch = cfg
+	a.mu.Unlock()
 }
 
 // APINode_Start launches the HTTP server on the given address.
@@ -35,10 +55,32 @@ func (a *APINode) APINode_Start(addr string) error {
 	mux := http.NewServeMux()
 	mux.HandleFunc("/balance/", a.handleBalance)
 	mux.HandleFunc("/tx", a.handleTx)
+	mux.HandleFunc("/tx/simulate", a.handleSimulate)
 	mux.HandleFunc("/block/", a.handleBlock)
+	mux.HandleFunc("/status", a.handleStatus)
+	mux.HandleFunc("/status/weights", a.handleWeights)
+	mux.HandleFunc("/fees/estimate", a.handleFeeEstimate)
+	mux.HandleFunc("/abi/errors", a.handleABIErrors)
+	mux.HandleFunc("/tokens/permit", a.handlePermit)
+	mux.HandleFunc("/workflows", a.handleWorkflows)
+	mux.HandleFunc("/workflows/", a.handleWorkflow)
+	mux.HandleFunc("/workflow-runs/", a.handleWorkflowRun)
+	mux.HandleFunc("/events", a.handleEvents)
+	mux.HandleFunc("/events/ws", a.handleEventStream)
+	mux.HandleFunc("/events/contract/", a.handleContractEvents)
+	mux.HandleFunc("/logs", a.handleLogs)
+	mux.HandleFunc("/supply/", a.handleSupply)
+	mux.HandleFunc("/emission", a.handleEmission)
+	mux.HandleFunc("/emission/", a.handleEmission)
+	mux.HandleFunc("/archive/", a.handleArchive)
+	mux.HandleFunc("/state/diff/", a.handleStateDiff)
+	mux.HandleFunc("/log/levels", LogLevelHandler)
+	a.mu.Lock()
+	mux.Handle("/batch", BatchHandler(mux, a.batch, "api"))
+	a.mu.Unlock()
 	a.srv = &http.Server{
 		Addr:         addr,
-		Handler:      mux,
+		Handler:      RequestLogger("api")(mux),
 		ReadTimeout:  10 * time.Second,
 		WriteTimeout: 10 * time.Second,
 		IdleTimeout:  120 * time.Second,
@@ -62,24 +104,24 @@ func (a *APINode) APINode_Stop() error {
 	return nil
 }
 
-// handleBalance returns the balance for the given address.
+// handleBalance returns the balance and next expected nonce of an address.
 func (a *APINode) handleBalance(w http.ResponseWriter, req *http.Request) {
 	if req.Method != http.MethodGet {
 		w.WriteHeader(http.StatusMethodNotAllowed)
 		return
 	}
 	if a.ledger == nil {
-		http.Error(w, "ledger not initialised", http.StatusInternalServerError)
+		WriteHTTPError(w, http.StatusInternalServerError, "api", errors.New("ledger not initialised"))
 		return
 	}
 	addrHex := strings.TrimPrefix(req.URL.Path, "/balance/")
 	addr, err := ParseAddress(addrHex)
 	if err != nil {
-		http.Error(w, "invalid address", http.StatusBadRequest)
+		WriteHTTPError(w, http.StatusBadRequest, "api", errors.New("invalid address"))
 		return
 	}
 	bal := a.ledger.TokenBalance(IDTokenID, addr)
-	writeJSON(w, map[string]uint64{"balance": bal})
+	writeJSON(w, map[string]uint64{"balance": bal, "nonce": a.ledger.ReadView().NonceOf(addr)})
 }
 
 // handleTx accepts a raw transaction and forwards it to the ledger pool
@@ -90,11 +132,11 @@ func (a *APINode) handleTx(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 	if a.ledger == nil {
-		http.Error(w, "ledger not initialised", http.StatusInternalServerError)
+		WriteHTTPError(w, http.StatusInternalServerError, "api", errors.New("ledger not initialised"))
 		return
 	}
 	if ct := req.Header.Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
-		http.Error(w, "content type must be application/json", http.StatusUnsupportedMediaType)
+		WriteHTTPError(w, http.StatusUnsupportedMediaType, "api", errors.New("content type must be application/json"))
 		return
 	}
 	req.Body = http.MaxBytesReader(w, req.Body, 1<<20) // 1MB limit
@@ -103,12 +145,41 @@ func (a *APINode) handleTx(w http.ResponseWriter, req *http.Request) {
 	dec.DisallowUnknownFields()
 	var tx Transaction
 	if err := dec.Decode(&tx); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		WriteHTTPError(w, http.StatusBadRequest, "api", err)
 		return
 	}
 	a.ledger.AddToPool(&tx)
+	ContextLogger(req.Context(), "api").WithField("hash", tx.Hash.Hex()).Debug("transaction accepted")
 	w.WriteHeader(http.StatusAccepted)
-	writeJSON(w, map[string]string{"status": "accepted"})
+	writeJSON(w, map[string]string{"status": "accepted", "hash": tx.Hash.Hex()})
+}
+
+// handleSimulate dry-runs a transaction, which need not be signed, against
+// the head or a past state with optional overrides: POST /tx/simulate.
+func (a *APINode) handleSimulate(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if a.ledger == nil {
+		WriteHTTPError(w, http.StatusInternalServerError, "api", errors.New("ledger not initialised"))
+		return
+	}
+	req.Body = http.MaxBytesReader(w, req.Body, 1<<20)
+	defer req.Body.Close()
+	dec := json.NewDecoder(req.Body)
+	dec.DisallowUnknownFields()
+	var body SimulateRequest
+	if err := dec.Decode(&body); err != nil {
+		WriteHTTPError(w, http.StatusBadRequest, "api", err)
+		return
+	}
+	res, err := a.ledger.SimulateTx(&body.Tx, body.SimulateOptions)
+	if err != nil {
+		WriteHTTPError(w, 0, "api", err)
+		return
+	}
+	writeJSON(w, res)
 }
 
 // handleBlock returns basic block data for the given height.
@@ -118,22 +189,580 @@ func (a *APINode) handleBlock(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 	if a.ledger == nil {
-		http.Error(w, "ledger not initialised", http.StatusInternalServerError)
+		WriteHTTPError(w, http.StatusInternalServerError, "api", errors.New("ledger not initialised"))
 		return
 	}
 	h, err := strconv.ParseUint(req.URL.Path[len("/block/"):], 10, 64)
 	if err != nil {
-		http.Error(w, "invalid height", http.StatusBadRequest)
+		WriteHTTPError(w, http.StatusBadRequest, "api", errors.New("invalid height"))
 		return
 	}
-	blk, err := a.ledger.GetBlock(h)
+	blk, err := a.ledger.ReadView().GetBlock(h)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		WriteHTTPError(w, http.StatusNotFound, "api", err)
 		return
 	}
 	writeJSON(w, blk)
 }
 
+// NodeVersion is the software version reported by /status. Release builds
+// set it with -ldflags "-X synnergy-network/core.NodeVersion=<v>".
+var NodeVersion = "dev"
+
+// NodeStatus is the health summary served by /status. Consensus is the
+// latest adaptive weight sample, when one has been recorded; ReadView is
+// the lag of the snapshot API reads are served from.
+type NodeStatus struct {
+	Height    uint64            `json:"height"`
+	Peers     int               `json:"peers"`
+	Version   string            `json:"version"`
+	Consensus *NetworkTelemetry `json:"consensus,omitempty"`
+	ReadView  ReadViewStats     `json:"read_view"`
+}
+
+// handleStatus reports chain height, peer count, software version and the
+// current consensus weights.
+func (a *APINode) handleStatus(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	st := NodeStatus{Version: NodeVersion}
+	if a.ledger != nil {
+		st.Height = a.ledger.LastHeight()
+		st.ReadView = a.ledger.ReadViewStats()
+		if h, err := WeightHistory(a.ledger, 1); err == nil && len(h) > 0 {
+			st.Consensus = &h[0]
+		}
+	}
+	if a.node != nil {
+		st.Peers = len(a.node.Peers())
+	}
+	writeJSON(w, st)
+}
+
+// handleWeights serves the adaptive consensus weight history, newest
+// first: GET /status/weights?limit=N (default 100).
+func (a *APINode) handleWeights(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if a.ledger == nil {
+		WriteHTTPError(w, http.StatusInternalServerError, "api", errors.New("ledger not initialised"))
+		return
+	}
+	limit := 100
+	if v := req.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			WriteHTTPError(w, http.StatusBadRequest, "api", errors.New("invalid limit"))
+			return
+		}
+		limit = n
+	}
+	h, err := WeightHistory(a.ledger, limit)
+	if err != nil {
+		WriteHTTPError(w, http.StatusInternalServerError, "api", err)
+		return
+	}
+	writeJSON(w, h)
+}
+
+// handleFeeEstimate serves a gas price likely to be included within the
+// target number of blocks: GET /fees/estimate?blocks=N (default 3).
+func (a *APINode) handleFeeEstimate(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if a.fees == nil {
+		WriteHTTPError(w, http.StatusInternalServerError, "api", errors.New("ledger not initialised"))
+		return
+	}
+	blocks := DefaultFeeTarget
+	if v := req.URL.Query().Get("blocks"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			WriteHTTPError(w, http.StatusBadRequest, "api", errors.New("invalid blocks"))
+			return
+		}
+		blocks = n
+	}
+	est, err := a.fees.EstimateFee(blocks)
+	if err != nil {
+		WriteHTTPError(w, http.StatusInternalServerError, "api", err)
+		return
+	}
+	writeJSON(w, est)
+}
+
+// handleABIErrors lists the custom errors revert payloads are decoded with
+// (GET) or registers those of a JSON ABI (POST).
+func (a *APINode) handleABIErrors(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodGet:
+		writeJSON(w, map[string][]string{"errors": ABIs().Errors()})
+	case http.MethodPost:
+		body, err := io.ReadAll(http.MaxBytesReader(w, req.Body, 1<<20))
+		if err != nil {
+			WriteHTTPError(w, http.StatusBadRequest, "api", err)
+			return
+		}
+		n, err := ABIs().RegisterJSON(body)
+		if err != nil {
+			WriteHTTPError(w, 0, "api", err)
+			return
+		}
+		writeJSON(w, map[string]int{"registered": n})
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// handlePermit serves
+//
+//	GET  /tokens/permit?token=&owner=[&spender=&value=&deadline=]
+//	                                   permit nonce, domain and document to sign
+//	POST /tokens/permit                apply a signed permit, or swap with it
+func (a *APINode) handlePermit(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodGet:
+		q := req.URL.Query()
+		id, err := strconv.ParseUint(q.Get("token"), 10, 32)
+		if err != nil {
+			WriteHTTPError(w, http.StatusBadRequest, "api", errors.New("invalid token"))
+			return
+		}
+		owner, err := DecodeAddress(q.Get("owner"))
+		if err != nil {
+			WriteHTTPError(w, http.StatusBadRequest, "api", err)
+			return
+		}
+		var spender *Address
+		var value uint64
+		var deadline int64
+		if v := q.Get("spender"); v != "" {
+			sp, err := DecodeAddress(v)
+			if err != nil {
+				WriteHTTPError(w, http.StatusBadRequest, "api", err)
+				return
+			}
+			spender = &sp
+			value, _ = strconv.ParseUint(q.Get("value"), 10, 64)
+			deadline, _ = strconv.ParseInt(q.Get("deadline"), 10, 64)
+		}
+		info, err := GetPermitInfo(TokenID(id), owner, spender, value, deadline)
+		if err != nil {
+			WriteHTTPError(w, 0, "api", err)
+			return
+		}
+		writeJSON(w, info)
+	case http.MethodPost:
+		req.Body = http.MaxBytesReader(w, req.Body, 1<<20)
+		defer req.Body.Close()
+		dec := json.NewDecoder(req.Body)
+		dec.DisallowUnknownFields()
+		var body PermitRequest
+		if err := dec.Decode(&body); err != nil {
+			WriteHTTPError(w, http.StatusBadRequest, "api", err)
+			return
+		}
+		res, err := SubmitPermit(body)
+		if err != nil {
+			WriteHTTPError(w, 0, "api", err)
+			return
+		}
+		writeJSON(w, res)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// handleWorkflows lists workflow IDs.
+func (a *APINode) handleWorkflows(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	writeJSON(w, ListWorkflows())
+}
+
+// handleWorkflow serves
+//
+//	GET  /workflows/{id}          workflow definition
+//	GET  /workflows/{id}/runs     runs, newest first
+//	POST /workflows/{id}/webhook  signed inbound webhook trigger
+func (a *APINode) handleWorkflow(w http.ResponseWriter, req *http.Request) {
+	id, sub, _ := strings.Cut(strings.TrimPrefix(req.URL.Path, "/workflows/"), "/")
+	switch {
+	case sub == "" && req.Method == http.MethodGet:
+		wf, err := GetWorkflow(id)
+		if err != nil {
+			WriteHTTPError(w, http.StatusNotFound, "api", err)
+			return
+		}
+		writeJSON(w, wf)
+	case sub == "runs" && req.Method == http.MethodGet:
+		writeJSON(w, ListWorkflowRuns(id))
+	case sub == "webhook" && req.Method == http.MethodPost:
+		req.Body = http.MaxBytesReader(w, req.Body, 1<<20)
+		defer req.Body.Close()
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			WriteHTTPError(w, http.StatusBadRequest, "api", err)
+			return
+		}
+		run, err := WorkflowWebhook(id, body, req.Header.Get("X-Synnergy-Signature"))
+		if err != nil {
+			status := http.StatusBadRequest
+			if errors.Is(err, ErrUnauthorized) {
+				status = http.StatusUnauthorized
+			}
+			WriteHTTPError(w, status, "api", err)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+		writeJSON(w, run)
+	default:
+		http.NotFound(w, req)
+	}
+}
+
+// handleWorkflowRun returns a single workflow run.
+func (a *APINode) handleWorkflowRun(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	run, err := GetWorkflowRun(strings.TrimPrefix(req.URL.Path, "/workflow-runs/"))
+	if err != nil {
+		WriteHTTPError(w, http.StatusNotFound, "api", err)
+		return
+	}
+	writeJSON(w, run)
+}
+
+// handleEvents returns persisted events starting at the from sequence
+// number, optionally filtered by a comma separated topics list.
+func (a *APINode) handleEvents(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	f, err := parseEventFilter(req)
+	if err != nil {
+		WriteHTTPError(w, http.StatusBadRequest, "api", err)
+		return
+	}
+	limit := 100
+	if v := req.URL.Query().Get("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil {
+			WriteHTTPError(w, http.StatusBadRequest, "api", errors.New("invalid limit"))
+			return
+		}
+	}
+	evs, err := Events().Range(f.From, limit, f.Topics)
+	if err != nil {
+		WriteHTTPError(w, http.StatusInternalServerError, "api", err)
+		return
+	}
+	writeJSON(w, evs)
+}
+
+// handleEventStream upgrades to a WebSocket event subscription.
+func (a *APINode) handleEventStream(w http.ResponseWriter, req *http.Request) {
+	Events().EventWebSocket(w, req)
+}
+
+// handleContractEvents returns the indexed events of a contract.
+func (a *APINode) handleContractEvents(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	addr, err := ParseAddress(strings.TrimPrefix(req.URL.Path, "/events/contract/"))
+	if err != nil {
+		WriteHTTPError(w, http.StatusBadRequest, "api", errors.New("invalid address"))
+		return
+	}
+	evs, err := Events().ContractEvents(addr, 0)
+	if err != nil {
+		WriteHTTPError(w, http.StatusInternalServerError, "api", err)
+		return
+	}
+	writeJSON(w, evs)
+}
+
+// LogsRequest is the body of POST /logs.
+type LogsRequest struct {
+	FromBlock uint64          `json:"from_block"`
+	ToBlock   uint64          `json:"to_block"`
+	Addresses []string        `json:"addresses,omitempty"` // hex
+	Topics    [][]common.Hash `json:"topics,omitempty"`
+}
+
+// handleLogs serves POST /logs, the contract logs matching a LogsRequest.
+func (a *APINode) handleLogs(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if a.ledger == nil {
+		WriteHTTPError(w, http.StatusInternalServerError, "api", errors.New("ledger not initialised"))
+		return
+	}
+	req.Body = http.MaxBytesReader(w, req.Body, 1<<20)
+	defer req.Body.Close()
+	var lr LogsRequest
+	if err := json.NewDecoder(req.Body).Decode(&lr); err != nil {
+		WriteHTTPError(w, http.StatusBadRequest, "api", err)
+		return
+	}
+	f := LogFilter{FromBlock: lr.FromBlock, ToBlock: lr.ToBlock, Topics: lr.Topics}
+	for _, s := range lr.Addresses {
+		addr, err := ParseAddress(strings.TrimPrefix(s, "0x"))
+		if err != nil {
+			WriteHTTPError(w, http.StatusBadRequest, "api", errors.New("invalid address"))
+			return
+		}
+		f.Addresses = append(f.Addresses, addr)
+	}
+	logs, err := a.ledger.ReadView().FilterLogs(f)
+	if err != nil {
+		WriteHTTPError(w, 0, "api", err)
+		return
+	}
+	writeJSON(w, logs)
+}
+
+// handleSupply serves GET /supply/{asset}: the minted, burned and
+// circulating supply of a ledger asset or of a registry token.
+func (a *APINode) handleSupply(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	asset := strings.TrimPrefix(req.URL.Path, "/supply/")
+	if asset == "" {
+		WriteHTTPError(w, http.StatusBadRequest, "api", errors.New("missing asset"))
+		return
+	}
+	if id, ok := registryTokenID(asset); ok {
+		st, err := TokenSupply(id)
+		if err != nil {
+			WriteHTTPError(w, 0, "api", err)
+			return
+		}
+		writeJSON(w, st)
+		return
+	}
+	if a.ledger == nil {
+		WriteHTTPError(w, http.StatusInternalServerError, "api", errors.New("ledger not initialised"))
+		return
+	}
+	writeJSON(w, a.ledger.SupplyOf(LedgerAsset(asset)))
+}
+
+// EmissionStatus is the body of GET /emission.
+type EmissionStatus struct {
+	Policy       EmissionPolicy `json:"policy"`
+	Height       uint64         `json:"height"`
+	NextReward   *big.Int       `json:"next_reward"`
+	TotalEmitted *big.Int       `json:"total_emitted"`
+}
+
+// handleEmission serves the emission policy: GET /emission for the policy
+// and totals, GET /emission/projection?from=&to=&step= for the projected
+// supply curve and GET /emission/block/{height} for a recorded emission.
+func (a *APINode) handleEmission(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if a.ledger == nil {
+		WriteHTTPError(w, http.StatusInternalServerError, "api", errors.New("ledger not initialised"))
+		return
+	}
+	policy := a.ledger.EmissionPolicy()
+	head := a.ledger.LastHeight()
+	switch path := strings.TrimPrefix(req.URL.Path, "/emission"); {
+	case path == "" || path == "/":
+		writeJSON(w, EmissionStatus{Policy: policy, Height: head, NextReward: policy.RewardAt(head + 1), TotalEmitted: a.ledger.TotalEmitted()})
+	case path == "/projection":
+		q := req.URL.Query()
+		from, to, step := head+1, head+1+10*RewardHalvingPeriod, uint64(RewardHalvingPeriod)
+		for name, dst := range map[string]*uint64{"from": &from, "to": &to, "step": &step} {
+			if v := q.Get(name); v != "" {
+				n, err := strconv.ParseUint(v, 10, 64)
+				if err != nil {
+					WriteHTTPError(w, http.StatusBadRequest, "api", errors.New("invalid "+name))
+					return
+				}
+				*dst = n
+			}
+		}
+		points, err := policy.Project(from, to, step)
+		if err != nil {
+			WriteHTTPError(w, 0, "api", err)
+			return
+		}
+		writeJSON(w, points)
+	case strings.HasPrefix(path, "/block/"):
+		h, err := strconv.ParseUint(strings.TrimPrefix(path, "/block/"), 10, 64)
+		if err != nil {
+			WriteHTTPError(w, http.StatusBadRequest, "api", errors.New("invalid height"))
+			return
+		}
+		e, err := a.ledger.BlockEmission(h)
+		if err != nil {
+			WriteHTTPError(w, 0, "api", err)
+			return
+		}
+		writeJSON(w, e)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// ArchiveCallRequest is the body of POST /archive/{height}/call.
+type ArchiveCallRequest struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Input string `json:"input"` // hex
+	Value uint64 `json:"value"`
+	Gas   uint64 `json:"gas"`
+}
+
+// handleStateDiff serves GET /state/diff/block/{height} and
+// GET /state/diff/tx/{hash}.
+func (a *APINode) handleStateDiff(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if a.ledger == nil {
+		WriteHTTPError(w, http.StatusInternalServerError, "api", errors.New("ledger not initialised"))
+		return
+	}
+	kind, arg, _ := strings.Cut(strings.TrimPrefix(req.URL.Path, "/state/diff/"), "/")
+	var (
+		diff interface{}
+		err  error
+	)
+	switch kind {
+	case "block":
+		height, perr := strconv.ParseUint(arg, 10, 64)
+		if perr != nil {
+			WriteHTTPError(w, http.StatusBadRequest, "api", NewError(CodeInvalidArgument, "api", "invalid height"))
+			return
+		}
+		diff, err = a.ledger.BlockStateDiff(height)
+	case "tx":
+		raw, herr := hex.DecodeString(strings.TrimPrefix(arg, "0x"))
+		if herr != nil || len(raw) != len(Hash{}) {
+			WriteHTTPError(w, http.StatusBadRequest, "api", NewError(CodeInvalidArgument, "api", "invalid transaction hash"))
+			return
+		}
+		var h Hash
+		copy(h[:], raw)
+		diff, err = a.ledger.TxStateDiff(h)
+	default:
+		http.NotFound(w, req)
+		return
+	}
+	if err != nil {
+		WriteHTTPError(w, http.StatusNotFound, "api", err)
+		return
+	}
+	writeJSON(w, diff)
+}
+
+// handleArchive serves historical state on archive nodes:
+//
+//	GET  /archive/{height}/balance/{addr}   coin balance and nonce
+//	GET  /archive/{height}/state/{keyHex}   raw state value
+//	GET  /archive/{height}/contract/{addr}  contract metadata and code
+//	POST /archive/{height}/call             eth_call-style contract read
+func (a *APINode) handleArchive(w http.ResponseWriter, req *http.Request) {
+	if a.ledger == nil {
+		WriteHTTPError(w, http.StatusInternalServerError, "api", errors.New("ledger not initialised"))
+		return
+	}
+	hStr, rest, _ := strings.Cut(strings.TrimPrefix(req.URL.Path, "/archive/"), "/")
+	kind, arg, _ := strings.Cut(rest, "/")
+	height, err := strconv.ParseUint(hStr, 10, 64)
+	if err != nil {
+		WriteHTTPError(w, http.StatusBadRequest, "api", errors.New("invalid height"))
+		return
+	}
+	view, err := a.ledger.StateAt(height)
+	if err != nil {
+		status := http.StatusNotFound
+		if errors.Is(err, ErrNotArchive) {
+			status = http.StatusNotImplemented
+		}
+		WriteHTTPError(w, status, "api", err)
+		return
+	}
+	switch {
+	case kind == "balance" && req.Method == http.MethodGet:
+		addr, err := ParseAddress(strings.TrimPrefix(arg, "0x"))
+		if err != nil {
+			WriteHTTPError(w, http.StatusBadRequest, "api", errors.New("invalid address"))
+			return
+		}
+		writeJSON(w, map[string]uint64{"height": height, "balance": view.CoinBalance(addr), "nonce": view.NonceOf(addr)})
+	case kind == "state" && req.Method == http.MethodGet:
+		key, err := hex.DecodeString(arg)
+		if err != nil {
+			WriteHTTPError(w, http.StatusBadRequest, "api", errors.New("invalid key"))
+			return
+		}
+		val, err := view.GetState(key)
+		if err != nil {
+			WriteHTTPError(w, http.StatusNotFound, "api", err)
+			return
+		}
+		writeJSON(w, map[string]interface{}{"height": height, "value": hex.EncodeToString(val)})
+	case kind == "contract" && req.Method == http.MethodGet:
+		addr, err := ParseAddress(strings.TrimPrefix(arg, "0x"))
+		if err != nil {
+			WriteHTTPError(w, http.StatusBadRequest, "api", errors.New("invalid address"))
+			return
+		}
+		c, err := view.GetContract(addr[:])
+		if err != nil {
+			WriteHTTPError(w, http.StatusNotFound, "api", err)
+			return
+		}
+		writeJSON(w, c)
+	case kind == "call" && arg == "" && req.Method == http.MethodPost:
+		req.Body = http.MaxBytesReader(w, req.Body, 1<<20)
+		defer req.Body.Close()
+		var cr ArchiveCallRequest
+		if err := json.NewDecoder(req.Body).Decode(&cr); err != nil {
+			WriteHTTPError(w, http.StatusBadRequest, "api", err)
+			return
+		}
+		from, err1 := ParseAddress(strings.TrimPrefix(cr.From, "0x"))
+		to, err2 := ParseAddress(strings.TrimPrefix(cr.To, "0x"))
+		input, err3 := hex.DecodeString(strings.TrimPrefix(cr.Input, "0x"))
+		if err := errors.Join(err1, err2, err3); err != nil {
+			WriteHTTPError(w, http.StatusBadRequest, "api", err)
+			return
+		}
+		out, err := view.Call(from, to, input, new(big.Int).SetUint64(cr.Value), cr.Gas)
+		if err != nil {
+			WriteHTTPError(w, http.StatusUnprocessableEntity, "api", err)
+			return
+		}
+		writeJSON(w, map[string]interface{}{"height": height, "output": hex.EncodeToString(out)})
+	default:
+		http.NotFound(w, req)
+	}
+}
+
 func writeJSON(w http.ResponseWriter, v interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(v); err != nil {
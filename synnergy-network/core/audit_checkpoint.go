@@ -0,0 +1,350 @@
+package core
+
+// audit_checkpoint.go – Merkle checkpointing for AuditTrail.
+//
+// Audit entries are grouped into batches. When a batch is closed its entry
+// hashes are folded into a Merkle tree, the root is stored in the ledger
+// under "audit:checkpoint:<root>" and a checkpoint marker is appended to the
+// log. Markers are not leaves themselves; they delimit the batch that
+// precedes them. Auditors can verify a whole exported log with
+// VerifyAuditLog or check a single entry with an AuditProof.
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+const (
+	// DefaultAuditBatchSize is the number of entries per checkpoint batch.
+	DefaultAuditBatchSize = 256
+	// AuditCheckpointEvent is the event name of checkpoint markers.
+	AuditCheckpointEvent = "audit_checkpoint"
+)
+
+// AuditCheckpoint anchors a batch of audit entries.
+type AuditCheckpoint struct {
+	Root     [32]byte `json:"root"`
+	FirstSeq uint64   `json:"first_seq"`
+	LastSeq  uint64   `json:"last_seq"`
+	Count    int      `json:"count"`
+	Time     int64    `json:"time"`
+}
+
+// AuditProof shows that an entry belongs to an anchored batch.
+type AuditProof struct {
+	Entry    AuditEvent `json:"entry"`
+	Index    uint32     `json:"index"`
+	Path     []string   `json:"path"` // hex sibling hashes, leaf upwards
+	Root     string     `json:"root"`
+	FirstSeq uint64     `json:"first_seq"`
+	LastSeq  uint64     `json:"last_seq"`
+}
+
+// AuditBatchResult is the verification outcome of one batch.
+type AuditBatchResult struct {
+	Root        string `json:"root"`
+	FirstSeq    uint64 `json:"first_seq"`
+	LastSeq     uint64 `json:"last_seq"`
+	Count       int    `json:"count"`
+	RootMatches bool   `json:"root_matches"`
+	Anchored    bool   `json:"anchored"`
+}
+
+// AuditVerification summarises the verification of an exported log.
+type AuditVerification struct {
+	Entries    int                `json:"entries"`
+	Tampered   []uint64           `json:"tampered,omitempty"`
+	Batches    []AuditBatchResult `json:"batches"`
+	Unanchored []uint64           `json:"unanchored,omitempty"`
+	Valid      bool               `json:"valid"`
+}
+
+func auditCheckpointKey(root [32]byte) []byte {
+	return []byte("audit:checkpoint:" + hex.EncodeToString(root[:]))
+}
+
+// SetBatchSize changes the number of entries per batch. Zero disables
+// size-triggered checkpoints.
+func (a *AuditTrail) SetBatchSize(n int) {
+	a.mu.Lock()
+	a.batchSize = n
+	a.mu.Unlock()
+}
+
+// Checkpoint closes the current batch. It returns nil when no entries are
+// pending.
+func (a *AuditTrail) Checkpoint() (*AuditCheckpoint, error) {
+	if a == nil || a.file == nil {
+		return nil, errors.New("audit trail not initialised")
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.checkpointLocked()
+}
+
+// StartCheckpointing closes a batch every interval until ctx is cancelled.
+func (a *AuditTrail) StartCheckpointing(ctx context.Context, interval time.Duration) {
+	go func() {
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+				_, _ = a.Checkpoint()
+			}
+		}
+	}()
+}
+
+func (a *AuditTrail) checkpointLocked() (*AuditCheckpoint, error) {
+	n := a.pending.Len()
+	if n == 0 {
+		return nil, nil
+	}
+	root, err := a.pending.Root()
+	if err != nil {
+		return nil, err
+	}
+	cp := &AuditCheckpoint{
+		Root:     root,
+		FirstSeq: a.firstSeq,
+		LastSeq:  a.firstSeq + uint64(n) - 1,
+		Count:    n,
+		Time:     time.Now().Unix(),
+	}
+	if a.ledger != nil {
+		raw, _ := json.Marshal(cp)
+		if err := a.ledger.SetState(auditCheckpointKey(root), raw); err != nil {
+			return nil, err
+		}
+	}
+	if err := a.appendLocked(AuditCheckpointEvent, cp.meta()); err != nil {
+		return nil, err
+	}
+	// markers are not part of any batch
+	a.pending.Reset()
+	return cp, nil
+}
+
+// recover restores the sequence counter and the open batch from the log.
+func (a *AuditTrail) recover() error {
+	if _, err := a.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	evs, err := readAuditEvents(a.file)
+	if err != nil {
+		return err
+	}
+	for _, ev := range evs {
+		a.seq = ev.Seq + 1
+		if ev.Event == AuditCheckpointEvent {
+			a.pending.Reset()
+			continue
+		}
+		if a.pending.Len() == 0 {
+			a.firstSeq = ev.Seq
+		}
+		a.pending.Append(ev.Hash)
+	}
+	return nil
+}
+
+// Prove builds an inclusion proof for the entry with the given sequence
+// number. The entry's batch must already be checkpointed.
+func (a *AuditTrail) Prove(seq uint64) (*AuditProof, error) {
+	if a == nil || a.file == nil {
+		return nil, errors.New("audit trail not initialised")
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, err := a.file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	evs, err := readAuditEvents(a.file)
+	if err != nil {
+		return nil, err
+	}
+	return ProveAuditEntry(evs, seq)
+}
+
+// ProveAuditEntry builds an inclusion proof from a parsed audit log.
+func ProveAuditEntry(evs []AuditEvent, seq uint64) (*AuditProof, error) {
+	var batch []AuditEvent
+	for _, ev := range evs {
+		if ev.Event != AuditCheckpointEvent {
+			batch = append(batch, ev)
+			continue
+		}
+		for i, b := range batch {
+			if b.Seq != seq {
+				continue
+			}
+			leaves := make([][]byte, len(batch))
+			for j := range batch {
+				leaves[j] = batch[j].Hash
+			}
+			path, root, err := MerkleProof(leaves, uint32(i))
+			if err != nil {
+				return nil, err
+			}
+			p := &AuditProof{
+				Entry:    b,
+				Index:    uint32(i),
+				Root:     hex.EncodeToString(root[:]),
+				FirstSeq: batch[0].Seq,
+				LastSeq:  batch[len(batch)-1].Seq,
+			}
+			for _, h := range path {
+				p.Path = append(p.Path, hex.EncodeToString(h))
+			}
+			return p, nil
+		}
+		batch = nil
+	}
+	for _, b := range batch {
+		if b.Seq == seq {
+			return nil, fmt.Errorf("entry %d not yet checkpointed", seq)
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// VerifyAuditProof checks the entry hash, the Merkle path and, when led is
+// non-nil, that the root is anchored on-chain.
+func VerifyAuditProof(led *Ledger, p *AuditProof) error {
+	if p == nil {
+		return errors.New("nil proof")
+	}
+	h := p.Entry.digest()
+	if !bytes.Equal(h[:], p.Entry.Hash) {
+		return errors.New("entry hash mismatch")
+	}
+	var root [32]byte
+	rb, err := hex.DecodeString(p.Root)
+	if err != nil || len(rb) != len(root) {
+		return errors.New("invalid root")
+	}
+	copy(root[:], rb)
+	path := make([][]byte, len(p.Path))
+	for i, s := range p.Path {
+		if path[i], err = hex.DecodeString(s); err != nil {
+			return fmt.Errorf("invalid path element %d", i)
+		}
+	}
+	if !VerifyMerklePath(root, p.Entry.Hash, path, p.Index) {
+		return errors.New("merkle path does not reproduce root")
+	}
+	if led != nil && !auditAnchored(led, root, p.FirstSeq, p.LastSeq) {
+		return errors.New("root not anchored on ledger")
+	}
+	return nil
+}
+
+// VerifyAuditLog re-hashes every entry of an exported log, recomputes each
+// batch root and, when led is non-nil, checks the roots against the ledger.
+func VerifyAuditLog(r io.Reader, led *Ledger) (*AuditVerification, error) {
+	evs, err := readAuditEvents(r)
+	if err != nil {
+		return nil, err
+	}
+	out := &AuditVerification{Entries: len(evs)}
+	var batch []AuditEvent
+	for _, ev := range evs {
+		if h := ev.digest(); !bytes.Equal(h[:], ev.Hash) {
+			out.Tampered = append(out.Tampered, ev.Seq)
+		}
+		if ev.Event != AuditCheckpointEvent {
+			batch = append(batch, ev)
+			continue
+		}
+		res := AuditBatchResult{Root: ev.Meta["root"], Count: len(batch)}
+		res.FirstSeq, _ = strconv.ParseUint(ev.Meta["first"], 10, 64)
+		res.LastSeq, _ = strconv.ParseUint(ev.Meta["last"], 10, 64)
+		if len(batch) > 0 {
+			leaves := make([][]byte, len(batch))
+			for i := range batch {
+				leaves[i] = batch[i].Hash
+			}
+			root, err := auditRoot(leaves)
+			if err == nil {
+				res.RootMatches = hex.EncodeToString(root[:]) == res.Root &&
+					batch[0].Seq == res.FirstSeq && batch[len(batch)-1].Seq == res.LastSeq
+				res.Anchored = led != nil && auditAnchored(led, root, res.FirstSeq, res.LastSeq)
+			}
+		}
+		out.Batches = append(out.Batches, res)
+		batch = nil
+	}
+	for _, ev := range batch {
+		out.Unanchored = append(out.Unanchored, ev.Seq)
+	}
+	out.Valid = len(out.Tampered) == 0
+	for _, b := range out.Batches {
+		if !b.RootMatches || (led != nil && !b.Anchored) {
+			out.Valid = false
+		}
+	}
+	return out, nil
+}
+
+// GetAuditCheckpoint returns the on-chain checkpoint for a batch root.
+func GetAuditCheckpoint(led *Ledger, root [32]byte) (*AuditCheckpoint, error) {
+	raw, err := led.GetState(auditCheckpointKey(root))
+	if err != nil || len(raw) == 0 {
+		return nil, ErrNotFound
+	}
+	var cp AuditCheckpoint
+	if err := json.Unmarshal(raw, &cp); err != nil {
+		return nil, err
+	}
+	return &cp, nil
+}
+
+func auditAnchored(led *Ledger, root [32]byte, first, last uint64) bool {
+	cp, err := GetAuditCheckpoint(led, root)
+	return err == nil && cp.FirstSeq == first && cp.LastSeq == last
+}
+
+func auditRoot(leaves [][]byte) ([32]byte, error) {
+	tree, err := BuildMerkleTree(leaves)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	return tree[len(tree)-1][0], nil
+}
+
+func (cp *AuditCheckpoint) meta() map[string]string {
+	return map[string]string{
+		"root":  hex.EncodeToString(cp.Root[:]),
+		"first": strconv.FormatUint(cp.FirstSeq, 10),
+		"last":  strconv.FormatUint(cp.LastSeq, 10),
+		"count": strconv.Itoa(cp.Count),
+	}
+}
+
+func readAuditEvents(r io.Reader) ([]AuditEvent, error) {
+	var out []AuditEvent
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for sc.Scan() {
+		if len(bytes.TrimSpace(sc.Bytes())) == 0 {
+			continue
+		}
+		var ev AuditEvent
+		if err := json.Unmarshal(sc.Bytes(), &ev); err != nil {
+			return nil, fmt.Errorf("malformed audit line: %w", err)
+		}
+		out = append(out, ev)
+	}
+	return out, sc.Err()
+}
@@ -0,0 +1,71 @@
+package core
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAuditTrailCheckpointAndVerify(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "audit.log")
+	at, err := NewAuditTrail(logPath, nil)
+	if err != nil {
+		t.Fatalf("NewAuditTrail: %v", err)
+	}
+	defer at.Close()
+	at.SetBatchSize(3)
+	for i := 0; i < 7; i++ {
+		if err := at.Log("event", map[string]string{"i": string(rune('a' + i))}); err != nil {
+			t.Fatalf("Log: %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("read log: %v", err)
+	}
+	res, err := VerifyAuditLog(bytes.NewReader(data), nil)
+	if err != nil {
+		t.Fatalf("VerifyAuditLog: %v", err)
+	}
+	if !res.Valid || len(res.Batches) != 2 || len(res.Unanchored) != 1 {
+		t.Fatalf("unexpected verification %+v", res)
+	}
+
+	p, err := at.Prove(4)
+	if err != nil {
+		t.Fatalf("Prove: %v", err)
+	}
+	if err := VerifyAuditProof(nil, p); err != nil {
+		t.Fatalf("VerifyAuditProof: %v", err)
+	}
+	if _, err := at.Prove(8); err == nil {
+		t.Fatalf("expected error for unanchored entry")
+	}
+
+	tampered := bytes.Replace(data, []byte(`"i":"b"`), []byte(`"i":"z"`), 1)
+	res, _ = VerifyAuditLog(bytes.NewReader(tampered), nil)
+	if res.Valid || len(res.Tampered) != 1 {
+		t.Fatalf("tampering not detected: %+v", res)
+	}
+}
+
+func TestAuditTrailRecoversOpenBatch(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "audit.log")
+	at, _ := NewAuditTrail(logPath, nil)
+	at.SetBatchSize(0)
+	_ = at.Log("a", nil)
+	_ = at.Log("b", nil)
+	at.Close()
+
+	at, err := NewAuditTrail(logPath, nil)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer at.Close()
+	cp, err := at.Checkpoint()
+	if err != nil || cp == nil || cp.Count != 2 || cp.FirstSeq != 0 || cp.LastSeq != 1 {
+		t.Fatalf("unexpected checkpoint %+v err %v", cp, err)
+	}
+}
@@ -1,57 +1,149 @@
 package core
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/binary"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log"
 	"sync"
 	"time"
 )
 
-// AuthorityApplier manages proposals for new authority nodes.
-// Each application collects votes from a sampled electorate and
-// registers the candidate once approved.
+// AuthorityApplier runs the authority node application workflow:
+//
+//  1. Review – SubmitApplication samples an electorate of active authorities
+//     who vote with VoteApplication until the review deadline. While under
+//     review the candidate may attach supporting documents, which are pinned
+//     through the configured DocumentStore.
+//  2. Public comment – an application that passes review is open for
+//     CommentPeriod to comments from accounts holding DAO stake. Each comment
+//     weighs the stake its author holds when the period ends, so coins count
+//     once however many addresses they pass through; enough objecting stake
+//     rejects the application.
+//  3. Activation – the candidate is registered and activated for a term; see
+//     authority_tenure.go for term limits, re-election and deregistration.
+//
+// Stages are timed on the chain: the ledger ticks the applier with the
+// timestamp of every block it applies, and deadlines are measured against
+// the last of those.
+//
+// Applications, documents and comments are kept in the ledger under the
+// "authapply:" prefix and remain queryable after they are decided.
 
 type AuthAppStatus uint8
 
 const (
-	AuthPending AuthAppStatus = iota + 1
-	AuthApproved
-	AuthRejected
-	AuthExpired
+	AuthPending   AuthAppStatus = iota + 1 // under authority review
+	AuthApproved                           // activated
+	AuthRejected                           // failed review or public comment
+	AuthExpired                            // re-election still open when the term ended
+	AuthInComment                          // passed review, open for public comment
 )
 
+func (s AuthAppStatus) String() string {
+	switch s {
+	case AuthPending:
+		return "review"
+	case AuthApproved:
+		return "approved"
+	case AuthRejected:
+		return "rejected"
+	case AuthExpired:
+		return "expired"
+	case AuthInComment:
+		return "comment"
+	default:
+		return "unknown"
+	}
+}
+
 // AuthApplication is stored in the ledger under prefix "authapply:app:".
 // Votes are tracked separately to prevent double voting.
 
 type AuthApplication struct {
-	ID           Hash          `json:"id"`
-	Candidate    Address       `json:"candidate"`
-	Role         AuthorityRole `json:"role"`
-	Description  string        `json:"description"`
-	Electorate   []Address     `json:"electorate"`
-	VotesFor     uint32        `json:"votes_for"`
-	VotesAgainst uint32        `json:"votes_against"`
-	Deadline     int64         `json:"deadline_unix"`
-	Status       AuthAppStatus `json:"status"`
-	ExecutedAt   int64         `json:"executed_unix,omitempty"`
+	ID              Hash                  `json:"id"`
+	Candidate       Address               `json:"candidate"`
+	Role            AuthorityRole         `json:"role"`
+	Description     string                `json:"description"`
+	Documents       []ApplicationDocument `json:"documents,omitempty"`
+	Electorate      []Address             `json:"electorate"`
+	VotesFor        uint32                `json:"votes_for"`
+	VotesAgainst    uint32                `json:"votes_against"`
+	Deadline        int64                 `json:"deadline_unix"` // end of review
+	CommentDeadline int64                 `json:"comment_deadline_unix,omitempty"`
+	Support         uint32                `json:"support,omitempty"`         // supporting comments
+	Objections      uint32                `json:"objections,omitempty"`      // objecting comments
+	SupportStake    uint64                `json:"support_stake,omitempty"`   // stake behind support when comment ended
+	ObjectionStake  uint64                `json:"objection_stake,omitempty"` // stake behind objections when comment ended
+	Reelection      bool                  `json:"reelection,omitempty"`
+	Term            uint32                `json:"term"` // term applied for; 1 for a new authority
+	Status          AuthAppStatus         `json:"status"`
+	SubmittedAt     int64                 `json:"submitted_unix"`
+	ExecutedAt      int64                 `json:"executed_unix,omitempty"`
 }
 
 func (a *AuthApplication) Marshal() []byte { b, _ := json.Marshal(a); return b }
 
+// ApplicationDocument is a supporting document pinned to storage.
+type ApplicationDocument struct {
+	Name    string `json:"name"`
+	CID     string `json:"cid"`
+	Size    int64  `json:"size"`
+	Digest  Hash   `json:"sha256"`
+	AddedAt int64  `json:"added_unix"`
+}
+
+// ApplicationComment is a public comment on an application. Each staked
+// address may comment once.
+type ApplicationComment struct {
+	Application Hash    `json:"application"`
+	Author      Address `json:"author"`
+	Support     bool    `json:"support"`
+	Text        string  `json:"text"`
+	At          int64   `json:"at_unix"`
+}
+
+// DocumentStore pins application documents; *Storage implements it.
+type DocumentStore interface {
+	Pin(ctx context.Context, data []byte, payer Address) (string, int64, error)
+}
+
+// CommentStake reports the stake that weighs a public comment; *DAOStaking
+// implements it.
+type CommentStake interface {
+	StakedOf(addr Address) uint64
+}
+
+const (
+	maxApplicationDocuments = 16
+	maxApplicationDocSize   = 8 << 20
+	maxCommentLength        = 1024
+)
+
 // AuthVoteRule defines quorum and majority thresholds per role.
 type AuthVoteRule struct {
 	Quorum   int `yaml:"quorum"`
 	Majority int `yaml:"majority"` // percentage
 }
 
-// AuthorityApplierConfig controls electorate size and voting rules.
+// AuthorityApplierConfig controls the electorate, the length of each stage
+// and the terms of activated authorities.
 type AuthorityApplierConfig struct {
 	ElectorateSize int                            `yaml:"electorate_size"`
-	VotePeriod     time.Duration                  `yaml:"vote_period"`
+	VotePeriod     time.Duration                  `yaml:"vote_period"` // review stage
 	Rules          map[AuthorityRole]AuthVoteRule `yaml:"rules"`
+
+	CommentPeriod     time.Duration `yaml:"comment_period"`     // 0 skips public comment
+	ObjectionQuorum   uint64        `yaml:"objection_quorum"`   // stake behind comments before objections count; 0 disables
+	ObjectionMajority int           `yaml:"objection_majority"` // percentage of that stake objecting that rejects
+
+	TermLength     time.Duration `yaml:"term_length"`     // 0: terms do not expire
+	MaxTerms       int           `yaml:"max_terms"`       // consecutive terms; 0: unlimited
+	ReelectionLead time.Duration `yaml:"reelection_lead"` // re-election opens this long before a term ends
+	Cooldown       time.Duration `yaml:"cooldown"`        // wait after leaving before applying again
 }
 
 // AuthorityApplier coordinates authority node applications.
@@ -60,8 +152,24 @@ type AuthorityApplier struct {
 	logger *log.Logger
 	ledger StateRW
 	auth   *AuthoritySet
+	docs   DocumentStore
+	stakes CommentStake
 	cfg    AuthorityApplierConfig
 	nextID uint64
+	clock  time.Time // timestamp of the last block ticked
+}
+
+var (
+	applierMu      sync.RWMutex
+	currentApplier *AuthorityApplier
+)
+
+// CurrentAuthorityApplier returns the applier created last, on which
+// governance executes authority removals.
+func CurrentAuthorityApplier() *AuthorityApplier {
+	applierMu.RLock()
+	defer applierMu.RUnlock()
+	return currentApplier
 }
 
 func NewAuthorityApplier(lg *log.Logger, led StateRW, auth *AuthoritySet, cfg *AuthorityApplierConfig) *AuthorityApplier {
@@ -72,14 +180,63 @@ func NewAuthorityApplier(lg *log.Logger, led StateRW, auth *AuthoritySet, cfg *A
 		ap.cfg.ElectorateSize = 5
 		ap.cfg.VotePeriod = 72 * time.Hour
 		ap.cfg.Rules = make(map[AuthorityRole]AuthVoteRule)
+		ap.cfg.CommentPeriod = 7 * 24 * time.Hour
+		ap.cfg.ObjectionQuorum = 100_000
+		ap.cfg.ObjectionMajority = 67
+		ap.cfg.TermLength = 365 * 24 * time.Hour
+		ap.cfg.MaxTerms = 2
+		ap.cfg.ReelectionLead = 30 * 24 * time.Hour
+		ap.cfg.Cooldown = 90 * 24 * time.Hour
 	}
 	if ap.cfg.Rules == nil {
 		ap.cfg.Rules = make(map[AuthorityRole]AuthVoteRule)
 	}
+	applierMu.Lock()
+	currentApplier = ap
+	applierMu.Unlock()
 	return ap
 }
 
-// SubmitApplication registers a new authority application.
+// SetDocumentStore configures where supporting documents are pinned.
+func (ap *AuthorityApplier) SetDocumentStore(ds DocumentStore) {
+	ap.mu.Lock()
+	ap.docs = ds
+	ap.mu.Unlock()
+}
+
+// SetCommentStake configures the stake that weighs public comments. Without
+// one the DAO staking module is used once it is initialised.
+func (ap *AuthorityApplier) SetCommentStake(s CommentStake) {
+	ap.mu.Lock()
+	ap.stakes = s
+	ap.mu.Unlock()
+}
+
+// commentStake returns the stake source for comments. The caller holds
+// ap.mu.
+func (ap *AuthorityApplier) commentStake() CommentStake {
+	if ap.stakes != nil {
+		return ap.stakes
+	}
+	if s := StakingManager(); s != nil {
+		return s
+	}
+	return nil
+}
+
+// now returns the chain time stages are measured against: the timestamp
+// of the last block the applier was ticked with, or the wall clock before
+// the first. The caller holds ap.mu.
+func (ap *AuthorityApplier) now() time.Time {
+	if ap.clock.IsZero() {
+		return time.Now()
+	}
+	return ap.clock
+}
+
+// SubmitApplication registers a new authority application and opens its
+// review. Candidates that are active, have an open application or are in
+// their cooldown are refused.
 func (ap *AuthorityApplier) SubmitApplication(candidate Address, role AuthorityRole, desc string) (Hash, error) {
 	ap.mu.Lock()
 	defer ap.mu.Unlock()
@@ -87,13 +244,40 @@ func (ap *AuthorityApplier) SubmitApplication(candidate Address, role AuthorityR
 	if ap.auth.IsAuthority(candidate) {
 		return Hash{}, errors.New("candidate already active")
 	}
-	elect, err := ap.auth.RandomElectorate(ap.cfg.ElectorateSize)
+	now := ap.now()
+	if t, ok := ap.tenure(candidate); ok && now.Unix() < t.CooldownUntil {
+		return Hash{}, fmt.Errorf("candidate in cooldown until %s", time.Unix(t.CooldownUntil, 0).UTC().Format(time.RFC3339))
+	}
+	open, err := ap.openApplication(candidate)
+	if err != nil {
+		return Hash{}, err
+	}
+	if open {
+		return Hash{}, errors.New("candidate has an open application")
+	}
+	return ap.submit(candidate, role, desc, false, 1, now)
+}
+
+// submit stores a new application under review. The caller holds ap.mu.
+func (ap *AuthorityApplier) submit(candidate Address, role AuthorityRole, desc string, reelection bool, term uint32, now time.Time) (Hash, error) {
+	elect, err := ap.auth.RandomElectorate(ap.cfg.ElectorateSize + 1)
 	if err != nil {
 		return Hash{}, err
 	}
+	// a sitting authority never reviews its own re-election
+	reviewers := elect[:0]
+	for _, a := range elect {
+		if a != candidate && len(reviewers) < ap.cfg.ElectorateSize {
+			reviewers = append(reviewers, a)
+		}
+	}
+	if len(reviewers) == 0 {
+		return Hash{}, errors.New("no authorities available for review")
+	}
 	ap.nextID++
-	buf := make([]byte, 8)
+	buf := make([]byte, 16)
 	binary.BigEndian.PutUint64(buf, ap.nextID)
+	binary.BigEndian.PutUint64(buf[8:], uint64(now.UnixNano()))
 	h := sha256.Sum256(append(candidate.Bytes(), buf...))
 	var id Hash
 	copy(id[:], h[:])
@@ -103,30 +287,49 @@ func (ap *AuthorityApplier) SubmitApplication(candidate Address, role AuthorityR
 		Candidate:   candidate,
 		Role:        role,
 		Description: desc,
-		Electorate:  elect,
-		Deadline:    time.Now().Add(ap.cfg.VotePeriod).Unix(),
+		Electorate:  reviewers,
+		Deadline:    now.Add(ap.cfg.VotePeriod).Unix(),
+		Reelection:  reelection,
+		Term:        term,
 		Status:      AuthPending,
+		SubmittedAt: now.Unix(),
 	}
 	ap.ledger.SetState(appKey(id), app.Marshal())
-	ap.logger.Printf("authority application %s submitted", id.Hex())
+	ap.logger.Printf("authority application %s submitted (term %d)", id.Hex(), term)
 	return id, nil
 }
 
-// VoteApplication casts a vote from an authority node in the electorate.
+// openApplication reports whether candidate has an application under
+// review or public comment. The caller holds ap.mu.
+func (ap *AuthorityApplier) openApplication(candidate Address) (bool, error) {
+	iter := ap.ledger.PrefixIterator([]byte("authapply:app:"))
+	for iter.Next() {
+		var app AuthApplication
+		if err := json.Unmarshal(iter.Value(), &app); err != nil {
+			return false, err
+		}
+		if app.Candidate == candidate && (app.Status == AuthPending || app.Status == AuthInComment) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// VoteApplication casts a vote from an authority node in the electorate
+// while the application is under review.
 func (ap *AuthorityApplier) VoteApplication(voter Address, id Hash, approve bool) error {
 	ap.mu.Lock()
 	defer ap.mu.Unlock()
 
-	raw, err := ap.ledger.GetState(appKey(id))
-	if err != nil || len(raw) == 0 {
-		return errors.New("application not found")
-	}
-	var app AuthApplication
-	if err := json.Unmarshal(raw, &app); err != nil {
+	app, err := ap.load(id)
+	if err != nil {
 		return err
 	}
 	if app.Status != AuthPending {
-		return errors.New("application not pending")
+		return errors.New("application not under review")
+	}
+	if ap.now().Unix() >= app.Deadline {
+		return errors.New("review period ended")
 	}
 	if !containsAddr(app.Electorate, voter) {
 		return errors.New("voter not in electorate")
@@ -144,62 +347,241 @@ func (ap *AuthorityApplier) VoteApplication(voter Address, id Hash, approve bool
 	return nil
 }
 
-// FinalizeApplication evaluates a finished vote and registers the authority if approved.
-func (ap *AuthorityApplier) FinalizeApplication(id Hash) error {
+// AttachDocument pins a supporting document and adds it to an application
+// under review. Only the candidate may attach documents.
+func (ap *AuthorityApplier) AttachDocument(id Hash, sender Address, name string, data []byte) (ApplicationDocument, error) {
+	if name == "" || len(data) == 0 {
+		return ApplicationDocument{}, errors.New("document name and content required")
+	}
+	if len(data) > maxApplicationDocSize {
+		return ApplicationDocument{}, fmt.Errorf("document exceeds %d bytes", maxApplicationDocSize)
+	}
 	ap.mu.Lock()
-	defer ap.mu.Unlock()
+	ds := ap.docs
+	err := ap.checkAttach(id, sender)
+	ap.mu.Unlock()
+	if err != nil {
+		return ApplicationDocument{}, err
+	}
+	if ds == nil {
+		return ApplicationDocument{}, errors.New("document storage not configured")
+	}
 
-	raw, err := ap.ledger.GetState(appKey(id))
-	if err != nil || len(raw) == 0 {
-		return errors.New("application not found")
+	// pin outside the lock; the gateway round trip can be slow
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	cid, size, err := ds.Pin(ctx, data, sender)
+	if err != nil {
+		return ApplicationDocument{}, fmt.Errorf("pin document: %w", err)
 	}
-	var app AuthApplication
-	if err := json.Unmarshal(raw, &app); err != nil {
+	doc := ApplicationDocument{Name: name, CID: cid, Size: size, Digest: sha256.Sum256(data), AddedAt: ap.now().Unix()}
+
+	ap.mu.Lock()
+	defer ap.mu.Unlock()
+	if err := ap.checkAttach(id, sender); err != nil {
+		return ApplicationDocument{}, err
+	}
+	app, _ := ap.load(id)
+	app.Documents = append(app.Documents, doc)
+	ap.ledger.SetState(appKey(id), app.Marshal())
+	ap.logger.Printf("authority application %s: document %s pinned as %s", id.Hex(), name, cid)
+	return doc, nil
+}
+
+func (ap *AuthorityApplier) checkAttach(id Hash, sender Address) error {
+	app, err := ap.load(id)
+	if err != nil {
 		return err
 	}
+	if sender != app.Candidate {
+		return errors.New("only the candidate may attach documents")
+	}
 	if app.Status != AuthPending {
-		return errors.New("already finalised")
+		return errors.New("documents can only be attached during review")
 	}
-	if time.Now().Unix() < app.Deadline {
-		return errors.New("voting period not ended")
+	if len(app.Documents) >= maxApplicationDocuments {
+		return fmt.Errorf("application already has %d documents", maxApplicationDocuments)
 	}
-	rule := ap.cfg.Rules[app.Role]
-	if rule.Quorum == 0 {
-		rule.Quorum = len(app.Electorate)
-		rule.Majority = 51
+	return nil
+}
+
+// CommentApplication records a public comment in support of or objecting
+// to an application in its comment period. Only accounts holding stake may
+// comment, so throwaway addresses cannot pad either side.
+func (ap *AuthorityApplier) CommentApplication(id Hash, author Address, support bool, text string) error {
+	if len(text) > maxCommentLength {
+		return fmt.Errorf("comment exceeds %d bytes", maxCommentLength)
 	}
-	total := int(app.VotesFor + app.VotesAgainst)
-	if total >= rule.Quorum && int(app.VotesFor)*100/total >= rule.Majority {
-		if err := ap.auth.RegisterCandidate(app.Candidate, app.Role, app.Candidate); err != nil {
-			return err
-		}
-		app.Status = AuthApproved
-		app.ExecutedAt = time.Now().Unix()
-		ap.logger.Printf("authority application %s approved", id.Hex())
+	ap.mu.Lock()
+	defer ap.mu.Unlock()
+
+	app, err := ap.load(id)
+	if err != nil {
+		return err
+	}
+	now := ap.now().Unix()
+	if app.Status != AuthInComment || now >= app.CommentDeadline {
+		return errors.New("application not open for comment")
+	}
+	if ok, _ := ap.ledger.HasState(appCommentKey(id, author)); ok {
+		return errors.New("already commented")
+	}
+	stakes := ap.commentStake()
+	if stakes == nil {
+		return errors.New("comment staking not configured")
+	}
+	if stakes.StakedOf(author) == 0 {
+		return errors.New("only accounts holding DAO stake may comment")
+	}
+	c := ApplicationComment{Application: id, Author: author, Support: support, Text: text, At: now}
+	ap.ledger.SetState(appCommentKey(id, author), mustJSON(c))
+	if support {
+		app.Support++
 	} else {
-		app.Status = AuthRejected
-		ap.logger.Printf("authority application %s rejected", id.Hex())
+		app.Objections++
 	}
 	ap.ledger.SetState(appKey(id), app.Marshal())
 	return nil
 }
 
-// Tick finalises expired applications.
+// ListComments returns the public comments on an application.
+func (ap *AuthorityApplier) ListComments(id Hash) ([]ApplicationComment, error) {
+	ap.mu.Lock()
+	defer ap.mu.Unlock()
+	iter := ap.ledger.PrefixIterator(append([]byte("authapply:comment:"), id[:]...))
+	var out []ApplicationComment
+	for iter.Next() {
+		var c ApplicationComment
+		if err := json.Unmarshal(iter.Value(), &c); err != nil {
+			return nil, err
+		}
+		out = append(out, c)
+	}
+	return out, nil
+}
+
+// FinalizeApplication advances an application whose current stage has
+// ended: a review that passed opens public comment (or activates the
+// candidate when there is none), and a comment period without enough
+// objections activates the candidate.
+func (ap *AuthorityApplier) FinalizeApplication(id Hash) error {
+	ap.mu.Lock()
+	defer ap.mu.Unlock()
+
+	app, err := ap.load(id)
+	if err != nil {
+		return err
+	}
+	return ap.finalize(&app, ap.now())
+}
+
+// finalize advances app and stores it. The caller holds ap.mu.
+func (ap *AuthorityApplier) finalize(app *AuthApplication, now time.Time) error {
+	switch app.Status {
+	case AuthPending:
+		if now.Unix() < app.Deadline {
+			return errors.New("review period not ended")
+		}
+		rule := ap.cfg.Rules[app.Role]
+		if rule.Quorum == 0 {
+			rule.Quorum = len(app.Electorate)
+			rule.Majority = 51
+		}
+		total := int(app.VotesFor + app.VotesAgainst)
+		switch {
+		case total == 0 || total < rule.Quorum || int(app.VotesFor)*100/total < rule.Majority:
+			app.Status = AuthRejected
+			ap.logger.Printf("authority application %s rejected in review", app.ID.Hex())
+		case ap.cfg.CommentPeriod > 0:
+			app.Status = AuthInComment
+			app.CommentDeadline = now.Add(ap.cfg.CommentPeriod).Unix()
+			ap.logger.Printf("authority application %s open for public comment", app.ID.Hex())
+		default:
+			if err := ap.activate(app, now); err != nil {
+				return err
+			}
+		}
+	case AuthInComment:
+		if now.Unix() < app.CommentDeadline {
+			return errors.New("comment period not ended")
+		}
+		if ap.objected(app) {
+			app.Status = AuthRejected
+			ap.logger.Printf("authority application %s rejected after public comment", app.ID.Hex())
+		} else if err := ap.activate(app, now); err != nil {
+			return err
+		}
+	default:
+		return errors.New("already finalised")
+	}
+	ap.ledger.SetState(appKey(app.ID), app.Marshal())
+	return nil
+}
+
+// objected weighs the public comments on app by the stake their authors
+// hold now, records the weights and reports whether they reject it. The
+// caller holds ap.mu.
+func (ap *AuthorityApplier) objected(app *AuthApplication) bool {
+	app.SupportStake, app.ObjectionStake = 0, 0
+	if stakes := ap.commentStake(); stakes != nil {
+		iter := ap.ledger.PrefixIterator(append([]byte("authapply:comment:"), app.ID[:]...))
+		for iter.Next() {
+			var c ApplicationComment
+			if err := json.Unmarshal(iter.Value(), &c); err != nil {
+				continue
+			}
+			if c.Support {
+				app.SupportStake += stakes.StakedOf(c.Author)
+			} else {
+				app.ObjectionStake += stakes.StakedOf(c.Author)
+			}
+		}
+	}
+	total := app.SupportStake + app.ObjectionStake
+	if ap.cfg.ObjectionQuorum == 0 || total < ap.cfg.ObjectionQuorum {
+		return false
+	}
+	return app.ObjectionStake*100/total >= uint64(ap.cfg.ObjectionMajority)
+}
+
+// Tick advances the applier's clock to now, the timestamp of a block,
+// finalises applications whose stage has ended and runs term housekeeping:
+// scheduling re-elections and retiring authorities whose term is over. The
+// clock never moves back; an earlier now ticks at the current clock.
 func (ap *AuthorityApplier) Tick(now time.Time) {
 	ap.mu.Lock()
 	defer ap.mu.Unlock()
+	if now.After(ap.clock) {
+		ap.clock = now
+	}
+	now = ap.clock
 	iter := ap.ledger.PrefixIterator([]byte("authapply:app:"))
 	for iter.Next() {
 		var app AuthApplication
-		_ = json.Unmarshal(iter.Value(), &app)
-		if app.Status != AuthPending {
+		if err := json.Unmarshal(iter.Value(), &app); err != nil {
 			continue
 		}
-		if now.Unix() >= app.Deadline {
-			id := app.ID
-			_ = ap.FinalizeApplication(id)
+		due := (app.Status == AuthPending && now.Unix() >= app.Deadline) ||
+			(app.Status == AuthInComment && now.Unix() >= app.CommentDeadline)
+		if !due {
+			continue
 		}
+		if err := ap.finalize(&app, now); err != nil {
+			ap.logger.Printf("authority application %s: %v", app.ID.Hex(), err)
+		}
+	}
+	ap.tickTenures(now)
+}
+
+// tickAuthorityApplier ticks the current applier with the timestamp of
+// block, which l has just applied. Only the node's ledger drives the
+// applier; l.mu must not be held since the applier writes ledger state.
+func tickAuthorityApplier(l *Ledger, block *Block) {
+	ap := CurrentAuthorityApplier()
+	if ap == nil || l != CurrentLedger() {
+		return
 	}
+	ap.Tick(time.UnixMilli(block.Header.Timestamp))
 }
 
 // GetApplication returns a stored application.
@@ -238,7 +620,23 @@ func (ap *AuthorityApplier) ListApplications(status AuthAppStatus) ([]AuthApplic
 	return out, nil
 }
 
+// load reads an application. The caller holds ap.mu.
+func (ap *AuthorityApplier) load(id Hash) (AuthApplication, error) {
+	var app AuthApplication
+	raw, err := ap.ledger.GetState(appKey(id))
+	if err != nil || len(raw) == 0 {
+		return app, errors.New("application not found")
+	}
+	if err := json.Unmarshal(raw, &app); err != nil {
+		return app, err
+	}
+	return app, nil
+}
+
 func appKey(id Hash) []byte { return append([]byte("authapply:app:"), id[:]...) }
 func appVoteKey(id Hash, voter Address) []byte {
 	return append(append([]byte("authapply:vote:"), id[:]...), voter.Bytes()...)
 }
+func appCommentKey(id Hash, author Address) []byte {
+	return append(append([]byte("authapply:comment:"), id[:]...), author.Bytes()...)
+}
@@ -0,0 +1,214 @@
+package core
+
+import (
+	"io"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// applierState exposes the key/value state of a ledger as a StateRW.
+type applierState struct {
+	StateRW
+	l *Ledger
+}
+
+func (s applierState) GetState(k []byte) ([]byte, error)     { return s.l.GetState(k) }
+func (s applierState) SetState(k, v []byte) error            { return s.l.SetState(k, v) }
+func (s applierState) DeleteState(k []byte) error            { return s.l.DeleteState(k) }
+func (s applierState) HasState(k []byte) (bool, error)       { return s.l.HasState(k) }
+func (s applierState) PrefixIterator(p []byte) StateIterator { return s.l.PrefixIterator(p) }
+
+// testStakes is a CommentStake over a map.
+type testStakes map[Address]uint64
+
+func (s testStakes) StakedOf(addr Address) uint64 { return s[addr] }
+
+// newApplierTest returns an applier with one active authority to review
+// applications.
+func newApplierTest(t *testing.T, cfg AuthorityApplierConfig) *AuthorityApplier {
+	t.Helper()
+	l := newDepositLedger(t)
+	as := NewAuthoritySet(logrus.New(), l)
+	reviewer := Address{0x0e}
+	if err := as.RegisterCandidate(reviewer, StandardAuthorityNode, reviewer); err != nil {
+		t.Fatalf("register reviewer: %v", err)
+	}
+	if err := as.setActive(reviewer, true); err != nil {
+		t.Fatalf("activate reviewer: %v", err)
+	}
+	cfg.ElectorateSize = 1
+	cfg.VotePeriod = time.Hour
+	cfg.Cooldown = time.Hour
+	prev := CurrentAuthorityApplier()
+	ap := NewAuthorityApplier(log.New(io.Discard, "", 0), applierState{l: l}, as, &cfg)
+	t.Cleanup(func() {
+		applierMu.Lock()
+		currentApplier = prev
+		applierMu.Unlock()
+	})
+	return ap
+}
+
+// reviewApplication submits an application for candidate and has its
+// electorate approve it, returning the time its review ended.
+func reviewApplication(t *testing.T, ap *AuthorityApplier, candidate Address) (Hash, time.Time) {
+	t.Helper()
+	id, err := ap.SubmitApplication(candidate, StandardAuthorityNode, "candidate")
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	app, _, _ := ap.GetApplication(id)
+	for _, voter := range app.Electorate {
+		if err := ap.VoteApplication(voter, id, true); err != nil {
+			t.Fatalf("vote: %v", err)
+		}
+	}
+	end := ap.now().Add(2 * time.Hour)
+	ap.Tick(end)
+	return id, end
+}
+
+func TestDeregisterRequiresTheAuthorityOrGovernance(t *testing.T) {
+	ap := newApplierTest(t, AuthorityApplierConfig{})
+	reviewer := Address{0x0e}
+	alice, bob := Address{0xa1}, Address{0xb0}
+	reviewApplication(t, ap, alice)
+	reviewApplication(t, ap, bob)
+	if !ap.auth.IsAuthority(alice) || !ap.auth.IsAuthority(bob) {
+		t.Fatal("approved candidates not active")
+	}
+
+	if err := ap.Deregister(reviewer, alice); err == nil {
+		t.Fatal("another authority deregistered alice")
+	}
+	if tn, _ := ap.Tenure(alice); !tn.Active() || !ap.auth.IsAuthority(alice) {
+		t.Fatal("refused deregistration ended the tenure")
+	}
+	if err := ap.Deregister(alice, alice); err != nil {
+		t.Fatalf("self deregistration: %v", err)
+	}
+	tn, _ := ap.Tenure(alice)
+	if tn.Active() || tn.EndReason != TenureDeregistered || ap.auth.IsAuthority(alice) {
+		t.Fatalf("tenure after deregistration: %+v", tn)
+	}
+	if _, err := ap.SubmitApplication(alice, StandardAuthorityNode, "again"); err == nil {
+		t.Fatal("application accepted during the cooldown")
+	}
+
+	// governance removes bob once a proposal carrying the change executes
+	if err := UpdateParam("authority_deregister", bob.Hex()); err != nil {
+		t.Fatalf("governance removal: %v", err)
+	}
+	if tn, _ := ap.Tenure(bob); tn.Active() || tn.EndReason != TenureRemoved {
+		t.Fatalf("tenure after removal: %+v", tn)
+	}
+	if err := UpdateParam("authority_deregister", bob.Hex()); err == nil {
+		t.Fatal("removed an authority twice")
+	}
+}
+
+func TestPublicCommentIsWeighedByStake(t *testing.T) {
+	ap := newApplierTest(t, AuthorityApplierConfig{
+		CommentPeriod: time.Hour, ObjectionQuorum: 100, ObjectionMajority: 67,
+	})
+	whale, small, mover := Address{0xc1}, Address{0xc2}, Address{0xc3}
+	stakes := testStakes{whale: 150, small: 40, mover: 90}
+	ap.SetCommentStake(stakes)
+
+	// throwaway addresses without stake cannot pad the objections
+	id, end := reviewApplication(t, ap, Address{0xd1})
+	for i := byte(0); i < 20; i++ {
+		if err := ap.CommentApplication(id, Address{0xf0, i}, false, "no"); err == nil {
+			t.Fatal("comment from an address without stake accepted")
+		}
+	}
+	if err := ap.CommentApplication(id, small, false, "no"); err != nil {
+		t.Fatalf("comment: %v", err)
+	}
+	if err := ap.CommentApplication(id, small, false, "again"); err == nil {
+		t.Fatal("second comment from the same address accepted")
+	}
+	if err := ap.CommentApplication(id, whale, true, "yes"); err != nil {
+		t.Fatalf("comment: %v", err)
+	}
+	ap.Tick(end.Add(2 * time.Hour))
+	app, _, _ := ap.GetApplication(id)
+	if app.Status != AuthApproved || app.SupportStake != 150 || app.ObjectionStake != 40 {
+		t.Fatalf("outvoted objection: %+v", app)
+	}
+
+	// stake is weighed when the period ends: coins moved to a second address
+	// after commenting do not count twice
+	id, end = reviewApplication(t, ap, Address{0xd2})
+	if err := ap.CommentApplication(id, mover, false, "no"); err != nil {
+		t.Fatalf("comment: %v", err)
+	}
+	second := Address{0xc4}
+	delete(stakes, mover)
+	stakes[second] = 90
+	if err := ap.CommentApplication(id, second, false, "no again"); err != nil {
+		t.Fatalf("comment: %v", err)
+	}
+	if err := ap.CommentApplication(id, small, false, "no"); err != nil {
+		t.Fatalf("comment: %v", err)
+	}
+	ap.Tick(end.Add(2 * time.Hour))
+	app, _, _ = ap.GetApplication(id)
+	if app.Status != AuthRejected || app.ObjectionStake != 130 || app.Objections != 3 {
+		t.Fatalf("objection by stake: %+v", app)
+	}
+}
+
+func TestBlocksDriveTheApplierClock(t *testing.T) {
+	ap := newApplierTest(t, AuthorityApplierConfig{TermLength: 24 * time.Hour})
+	l := ap.ledger.(applierState).l
+	prev := globalLedger
+	globalLedger = l
+	t.Cleanup(func() { globalLedger = prev })
+	block := func(at time.Time) {
+		t.Helper()
+		if err := l.AddBlock(&Block{Header: BlockHeader{Height: uint64(len(l.Blocks)), Timestamp: at.UnixMilli()}}); err != nil {
+			t.Fatalf("block: %v", err)
+		}
+	}
+	start := time.Now()
+	block(start)
+
+	alice, bob := Address{0xa1}, Address{0xb0}
+	id, err := ap.SubmitApplication(alice, StandardAuthorityNode, "alice")
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	app, _, _ := ap.GetApplication(id)
+	if app.Deadline != start.Add(time.Hour).Unix() {
+		t.Fatalf("deadline %d, want an hour after the block", app.Deadline)
+	}
+	if err := ap.VoteApplication(app.Electorate[0], id, true); err != nil {
+		t.Fatalf("vote: %v", err)
+	}
+	late, err := ap.SubmitApplication(bob, StandardAuthorityNode, "bob")
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+
+	// the review ends with the block past the deadline, not the wall clock
+	block(start.Add(2 * time.Hour))
+	if app, _, _ = ap.GetApplication(id); app.Status != AuthApproved {
+		t.Fatalf("application after the deadline block: %+v", app)
+	}
+	if err := ap.VoteApplication(app.Electorate[0], late, true); err == nil {
+		t.Fatal("vote accepted after the review ended on chain")
+	}
+
+	// the term runs out on chain as well
+	block(start.Add(30 * time.Hour))
+	if tn, _ := ap.Tenure(alice); tn.Active() || tn.EndReason != TenureNotReelected {
+		t.Fatalf("tenure after its term: %+v", tn)
+	}
+	if ap.auth.IsAuthority(alice) {
+		t.Fatal("authority still active after its term")
+	}
+}
@@ -72,7 +72,16 @@ var admissionRules = map[AuthorityRole]struct {
 // AuthoritySet keeper
 //---------------------------------------------------------------------
 
-func NewAuthoritySet(lg *logrus.Logger, led StateRW) *AuthoritySet {
+// authorityState is the ledger state the authority set is kept in.
+type authorityState interface {
+	GetState(key []byte) ([]byte, error)
+	SetState(key, value []byte) error
+	DeleteState(key []byte) error
+	HasState(key []byte) (bool, error)
+	PrefixIterator(prefix []byte) StateIterator
+}
+
+func NewAuthoritySet(lg *logrus.Logger, led authorityState) *AuthoritySet {
 	return &AuthoritySet{logger: lg, led: led}
 }
 
@@ -309,6 +318,23 @@ func (as *AuthoritySet) Deregister(addr Address) error {
 	return nil
 }
 
+// setActive activates or deactivates a registered authority node.
+func (as *AuthoritySet) setActive(addr Address, active bool) error {
+	as.mu.Lock()
+	defer as.mu.Unlock()
+
+	raw, _ := as.led.GetState(nodeKey(addr))
+	if len(raw) == 0 {
+		return errors.New("authority not found")
+	}
+	var n AuthorityNode
+	if err := json.Unmarshal(raw, &n); err != nil {
+		return err
+	}
+	n.Active = active
+	return as.led.SetState(nodeKey(addr), mustJSON(n))
+}
+
 //---------------------------------------------------------------------
 // Helper funcs
 //---------------------------------------------------------------------
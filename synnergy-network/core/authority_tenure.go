@@ -0,0 +1,256 @@
+package core
+
+// authority_tenure.go – terms of authorities admitted through the
+// AuthorityApplier.
+//
+// An approved application activates the candidate for TermLength and records
+// an AuthorityTenure under authapply:tenure:<addr>. ReelectionLead before the
+// term ends, Tick opens a re-election application reviewed like a new one;
+// approval extends the tenure by another term. An authority leaves when its
+// term ends without re-election, when it reaches MaxTerms consecutive terms,
+// when it deregisters itself, or when governance removes it with the
+// authority_deregister parameter. Leaving removes the node from the
+// AuthoritySet and starts a Cooldown during which the address cannot apply
+// again.
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Reasons recorded when an authority leaves.
+const (
+	TenureTermLimit    = "term_limit"
+	TenureNotReelected = "not_reelected"
+	TenureDeregistered = "deregistered"
+	TenureRemoved      = "removed"
+)
+
+// AuthorityTenure tracks the terms served by an authority.
+type AuthorityTenure struct {
+	Addr          Address       `json:"addr"`
+	Role          AuthorityRole `json:"role"`
+	Terms         uint32        `json:"terms"` // consecutive terms, including the current one
+	TermStart     int64         `json:"term_start_unix"`
+	TermEnd       int64         `json:"term_end_unix,omitempty"` // 0: no term limit
+	Reelection    *Hash         `json:"reelection,omitempty"`    // open re-election application
+	Ended         int64         `json:"ended_unix,omitempty"`
+	EndReason     string        `json:"end_reason,omitempty"`
+	CooldownUntil int64         `json:"cooldown_until_unix,omitempty"`
+}
+
+// Active reports whether the authority is serving a term.
+func (t AuthorityTenure) Active() bool { return t.Ended == 0 }
+
+// activate starts or extends the tenure of an approved application. The
+// caller holds ap.mu.
+func (ap *AuthorityApplier) activate(app *AuthApplication, now time.Time) error {
+	t, ok := ap.tenure(app.Candidate)
+	if app.Reelection {
+		if !ok || !t.Active() {
+			return errors.New("re-election of an authority no longer serving")
+		}
+		t.Terms = app.Term
+		t.TermStart = t.TermEnd
+		t.TermEnd = ap.termEnd(time.Unix(t.TermStart, 0))
+		t.Reelection = nil
+	} else {
+		// candidates registered through RecordVote keep their node entry
+		if _, err := ap.auth.GetAuthority(app.Candidate); err != nil {
+			if err := ap.auth.RegisterCandidate(app.Candidate, app.Role, app.Candidate); err != nil {
+				return err
+			}
+		}
+		if err := ap.auth.setActive(app.Candidate, true); err != nil {
+			return err
+		}
+		t = AuthorityTenure{
+			Addr:      app.Candidate,
+			Role:      app.Role,
+			Terms:     1,
+			TermStart: now.Unix(),
+			TermEnd:   ap.termEnd(now),
+		}
+	}
+	ap.putTenure(t)
+	app.Status = AuthApproved
+	app.ExecutedAt = now.Unix()
+	ap.logger.Printf("authority application %s approved (term %d)", app.ID.Hex(), t.Terms)
+	return nil
+}
+
+func (ap *AuthorityApplier) termEnd(start time.Time) int64 {
+	if ap.cfg.TermLength == 0 {
+		return 0
+	}
+	return start.Add(ap.cfg.TermLength).Unix()
+}
+
+// tickTenures opens re-elections for terms ending within ReelectionLead and
+// retires authorities whose term has ended. The caller holds ap.mu.
+func (ap *AuthorityApplier) tickTenures(now time.Time) {
+	iter := ap.ledger.PrefixIterator([]byte("authapply:tenure:"))
+	var due []AuthorityTenure
+	for iter.Next() {
+		var t AuthorityTenure
+		if err := json.Unmarshal(iter.Value(), &t); err != nil {
+			continue
+		}
+		if t.Active() && t.TermEnd != 0 {
+			due = append(due, t)
+		}
+	}
+	for _, t := range due {
+		switch {
+		case now.Unix() >= t.TermEnd:
+			reason := TenureNotReelected
+			if ap.cfg.MaxTerms > 0 && int(t.Terms) >= ap.cfg.MaxTerms {
+				reason = TenureTermLimit
+			}
+			ap.expireReelection(&t, now)
+			if err := ap.depart(&t, reason, now); err != nil {
+				ap.logger.Printf("authority %s: end of term: %v", t.Addr.Short(), err)
+			}
+		case t.Reelection == nil && now.Unix() >= t.TermEnd-int64(ap.cfg.ReelectionLead/time.Second):
+			if ap.cfg.MaxTerms > 0 && int(t.Terms) >= ap.cfg.MaxTerms {
+				continue
+			}
+			id, err := ap.submit(t.Addr, t.Role, "re-election", true, t.Terms+1, now)
+			if err != nil {
+				ap.logger.Printf("authority %s: schedule re-election: %v", t.Addr.Short(), err)
+				continue
+			}
+			t.Reelection = &id
+			ap.putTenure(t)
+		}
+	}
+}
+
+// expireReelection closes a re-election still open when the term ends.
+func (ap *AuthorityApplier) expireReelection(t *AuthorityTenure, now time.Time) {
+	if t.Reelection == nil {
+		return
+	}
+	if app, err := ap.load(*t.Reelection); err == nil && (app.Status == AuthPending || app.Status == AuthInComment) {
+		app.Status = AuthExpired
+		app.ExecutedAt = now.Unix()
+		ap.ledger.SetState(appKey(app.ID), app.Marshal())
+	}
+	t.Reelection = nil
+}
+
+// depart removes the authority and starts its cooldown. The caller holds
+// ap.mu.
+func (ap *AuthorityApplier) depart(t *AuthorityTenure, reason string, now time.Time) error {
+	if err := ap.auth.Deregister(t.Addr); err != nil {
+		return err
+	}
+	t.Ended = now.Unix()
+	t.EndReason = reason
+	t.CooldownUntil = now.Add(ap.cfg.Cooldown).Unix()
+	ap.putTenure(*t)
+	ap.logger.Printf("authority %s left after %d term(s): %s", t.Addr.Short(), t.Terms, reason)
+	return nil
+}
+
+// Deregister ends the tenure of addr before its term is over at the request
+// of sender, which must be the authority itself; other authorities are
+// removed by governance with ProposeAuthorityDeregistration. The address
+// may not apply again until its cooldown has passed.
+func (ap *AuthorityApplier) Deregister(sender, addr Address) error {
+	if sender != addr {
+		return errors.New("only the authority may deregister itself; use a governance proposal")
+	}
+	return ap.deregister(addr, TenureDeregistered)
+}
+
+func (ap *AuthorityApplier) deregister(addr Address, reason string) error {
+	ap.mu.Lock()
+	defer ap.mu.Unlock()
+
+	t, ok := ap.tenure(addr)
+	if !ok || !t.Active() {
+		return errors.New("no active tenure")
+	}
+	now := ap.now()
+	ap.expireReelection(&t, now)
+	return ap.depart(&t, reason, now)
+}
+
+// ProposeAuthorityDeregistration submits a governance proposal removing
+// addr. The authority leaves, with reason "removed", once the proposal is
+// executed.
+func ProposeAuthorityDeregistration(creator, addr Address, reason string) (string, error) {
+	p := &GovProposal{
+		Creator:     creator,
+		Changes:     map[string]string{"authority_deregister": addr.Hex()},
+		Votes:       make(map[string]bool),
+		Description: "remove authority " + addr.Hex() + ": " + reason,
+	}
+	if err := SubmitProposal(p); err != nil {
+		return "", err
+	}
+	return p.ID, nil
+}
+
+// deregisterByGovernance applies the authority_deregister governance
+// parameter, the address of the authority to remove.
+func deregisterByGovernance(value string) error {
+	ap := CurrentAuthorityApplier()
+	if ap == nil {
+		return errors.New("authority applier not initialised")
+	}
+	addr, err := ParseAddress(value)
+	if err != nil {
+		return fmt.Errorf("invalid authority address: %w", err)
+	}
+	return ap.deregister(addr, TenureRemoved)
+}
+
+// Tenure returns the tenure record of addr.
+func (ap *AuthorityApplier) Tenure(addr Address) (AuthorityTenure, bool) {
+	ap.mu.Lock()
+	defer ap.mu.Unlock()
+	return ap.tenure(addr)
+}
+
+// ListTenures returns tenure records. If activeOnly is true only serving
+// authorities are returned.
+func (ap *AuthorityApplier) ListTenures(activeOnly bool) ([]AuthorityTenure, error) {
+	ap.mu.Lock()
+	defer ap.mu.Unlock()
+	iter := ap.ledger.PrefixIterator([]byte("authapply:tenure:"))
+	var out []AuthorityTenure
+	for iter.Next() {
+		var t AuthorityTenure
+		if err := json.Unmarshal(iter.Value(), &t); err != nil {
+			return nil, err
+		}
+		if activeOnly && !t.Active() {
+			continue
+		}
+		out = append(out, t)
+	}
+	return out, nil
+}
+
+// tenure reads the tenure of addr. The caller holds ap.mu.
+func (ap *AuthorityApplier) tenure(addr Address) (AuthorityTenure, bool) {
+	var t AuthorityTenure
+	raw, err := ap.ledger.GetState(tenureKey(addr))
+	if err != nil || len(raw) == 0 {
+		return t, false
+	}
+	if err := json.Unmarshal(raw, &t); err != nil {
+		return t, false
+	}
+	return t, true
+}
+
+func (ap *AuthorityApplier) putTenure(t AuthorityTenure) {
+	ap.ledger.SetState(tenureKey(t.Addr), mustJSON(t))
+}
+
+func tenureKey(addr Address) []byte { return []byte("authapply:tenure:" + addr.Hex()) }
@@ -0,0 +1,110 @@
+package core
+
+// backup_drill.go – disaster-recovery restore drills.
+//
+// A drill restores a backup chain into a scratch directory exactly as an
+// operator would after losing a node, then checks the result instead of
+// trusting it: the state root after the snapshot and after every WAL
+// segment must equal the root recorded when that backup was taken, the
+// block tips must match the manifest, and the restored directory must
+// reopen from disk at the same height and root.
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+)
+
+// DrillCheck is one verification step of a restore drill.
+type DrillCheck struct {
+	Name   string `json:"name"`
+	OK     bool   `json:"ok"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// DrillReport is the outcome of a restore drill.
+type DrillReport struct {
+	Seq       uint64        `json:"seq"`
+	Dir       string        `json:"dir"`
+	Started   time.Time     `json:"started"`
+	Duration  time.Duration `json:"duration"`
+	Height    uint64        `json:"height"`
+	StateRoot string        `json:"state_root"`
+	Checks    []DrillCheck  `json:"checks"`
+	Passed    bool          `json:"passed"`
+}
+
+func (r *DrillReport) check(name string, ok bool, format string, args ...interface{}) {
+	r.Checks = append(r.Checks, DrillCheck{Name: name, OK: ok, Detail: fmt.Sprintf(format, args...)})
+}
+
+// RestoreDrill restores the backup chain ending at seq (0 = newest) into
+// dir, or into a new temporary directory when dir is empty, replays it to
+// the tip and compares the recomputed state roots with the recorded ones.
+// A failed check is reported, not returned as an error; the error is only
+// set when the drill could not run at all. The caller owns the directory
+// named in the report and should remove it when done.
+func RestoreDrill(ctx context.Context, store BackupStore, seq uint64, dir string) (*DrillReport, error) {
+	chain, err := findChain(ctx, store, seq)
+	if err != nil {
+		return nil, err
+	}
+	if dir == "" {
+		if dir, err = os.MkdirTemp("", "synnergy-drill-"); err != nil {
+			return nil, err
+		}
+	}
+	last := chain[len(chain)-1]
+	rep := &DrillReport{Seq: last.Seq, Dir: dir, Started: time.Now().UTC()}
+	defer func() {
+		rep.Duration = time.Since(rep.Started)
+		rep.Passed = len(rep.Checks) > 0
+		for _, c := range rep.Checks {
+			rep.Passed = rep.Passed && c.OK
+		}
+	}()
+
+	led, err := restoreChain(ctx, store, chain, dir, func(rec BackupRecord, l *Ledger) {
+		name := fmt.Sprintf("%s backup %d", rec.Kind, rec.Seq)
+		l.mu.RLock()
+		root := stateRoot(l.State).Hex()
+		var tip string
+		if n := len(l.Blocks); n > 0 {
+			tip = l.Blocks[n-1].Hash().Hex()
+		}
+		l.mu.RUnlock()
+		rep.check(name+" tip", tip == rec.TipHash, "height %d hash %s", rec.To, tip)
+		switch {
+		case rec.StateRoot == "":
+			rep.check(name+" state root", true, "no root recorded; computed %s", root)
+		case root != rec.StateRoot:
+			rep.check(name+" state root", false, "computed %s, recorded %s", root, rec.StateRoot)
+		default:
+			rep.check(name+" state root", true, "%s", root)
+		}
+	})
+	if err != nil {
+		rep.check("restore", false, "%v", err)
+		return rep, nil
+	}
+	rep.check("restore", true, "%d backups replayed into %s", len(chain), dir)
+	if err := led.Close(); err != nil {
+		rep.check("reopen", false, "close restored ledger: %v", err)
+		return rep, nil
+	}
+
+	led, err = OpenLedger(dir)
+	if err != nil {
+		rep.check("reopen", false, "%v", err)
+		return rep, nil
+	}
+	defer led.Close()
+	rep.Height = led.LastHeight()
+	rep.StateRoot = led.StateRoot().Hex()
+	rep.check("reopen height", rep.Height == last.To, "height %d, expected %d", rep.Height, last.To)
+	if last.StateRoot != "" {
+		rep.check("reopen state root", rep.StateRoot == last.StateRoot, "computed %s, recorded %s", rep.StateRoot, last.StateRoot)
+	}
+	return rep, nil
+}
@@ -0,0 +1,679 @@
+package core
+
+// backup_scheduler.go – scheduled full and incremental ledger backups.
+//
+// A full backup is the gzip-compressed ledger snapshot. An incremental
+// backup is a WAL segment: the blocks appended since the previous backup of
+// the same chain, in the line-per-block format of ledger.wal, plus a state
+// delta object for the writes the blocks do not replay (events, indexes and
+// other state set outside block application). Every record carries the
+// state root it should restore to. Backups are uploaded to a BackupStore
+// (a directory or an S3 bucket) and catalogued in a manifest stored next to
+// them, so any machine with access to the store can list, verify and
+// restore them. The scheduler periodically checks that
+// the newest chain is restorable and enforces the retention policy.
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// BackupStore is a destination for backup objects.
+type BackupStore interface {
+	Put(ctx context.Context, name string, data []byte) error
+	Get(ctx context.Context, name string) ([]byte, error)
+	Delete(ctx context.Context, name string) error
+}
+
+// FSBackupStore keeps backups in a local or mounted directory.
+type FSBackupStore struct{ Dir string }
+
+func (s FSBackupStore) Put(_ context.Context, name string, data []byte) error {
+	if err := os.MkdirAll(s.Dir, 0o755); err != nil {
+		return err
+	}
+	tmp := filepath.Join(s.Dir, "."+name+".tmp")
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(s.Dir, name))
+}
+
+func (s FSBackupStore) Get(_ context.Context, name string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(s.Dir, name))
+}
+
+func (s FSBackupStore) Delete(_ context.Context, name string) error {
+	err := os.Remove(filepath.Join(s.Dir, name))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// Backup kinds.
+const (
+	BackupFull        = "full"
+	BackupIncremental = "incremental"
+)
+
+const backupManifestName = "manifest.json"
+
+// BackupRecord describes one uploaded backup. Base is the sequence number
+// of the full backup an incremental builds on; From and To are the block
+// heights it covers, TipHash the hash of block To and StateRoot the ledger
+// state root when the backup was taken.
+type BackupRecord struct {
+	Seq         uint64    `json:"seq"`
+	Kind        string    `json:"kind"`
+	Name        string    `json:"name"`
+	Base        uint64    `json:"base"`
+	From        uint64    `json:"from"`
+	To          uint64    `json:"to"`
+	TipHash     string    `json:"tip_hash"`
+	StateRoot   string    `json:"state_root"`
+	SHA256      string    `json:"sha256"`
+	Size        int       `json:"size"`
+	DeltaName   string    `json:"delta_name,omitempty"`
+	DeltaSHA256 string    `json:"delta_sha256,omitempty"`
+	Created     time.Time `json:"created"`
+	Verified    time.Time `json:"verified,omitempty"`
+}
+
+// stateDelta is the state difference carried by an incremental backup.
+type stateDelta struct {
+	Set     map[string][]byte `json:"set,omitempty"`
+	Deleted []string          `json:"deleted,omitempty"`
+}
+
+// BackupManifest is the catalogue kept in the store.
+type BackupManifest struct {
+	Records []BackupRecord `json:"records"`
+}
+
+// BackupPolicy sets the backup schedule and retention. KeepFull is the
+// number of full backups kept together with their incrementals; chains
+// older than MaxAge are dropped too, but the newest chain is always kept.
+type BackupPolicy struct {
+	FullEvery        time.Duration `json:"full_every"`
+	IncrementalEvery time.Duration `json:"incremental_every"`
+	VerifyEvery      time.Duration `json:"verify_every"`
+	KeepFull         int           `json:"keep_full"`
+	MaxAge           time.Duration `json:"max_age"`
+}
+
+// DefaultBackupPolicy takes a daily full backup, hourly incrementals and
+// keeps a week of chains.
+var DefaultBackupPolicy = BackupPolicy{
+	FullEvery:        24 * time.Hour,
+	IncrementalEvery: time.Hour,
+	VerifyEvery:      6 * time.Hour,
+	KeepFull:         7,
+}
+
+// BackupScheduler takes, verifies and prunes backups of a ledger. base is
+// a copy of the state at the backup numbered baseSeq, against which the
+// next incremental's delta is computed.
+type BackupScheduler struct {
+	ledger  *Ledger
+	store   BackupStore
+	policy  BackupPolicy
+	mu      sync.Mutex
+	base    map[string][]byte
+	baseSeq uint64
+}
+
+// NewBackupScheduler binds a ledger to a store. Zero policy fields take
+// their DefaultBackupPolicy values.
+func NewBackupScheduler(l *Ledger, store BackupStore, p BackupPolicy) *BackupScheduler {
+	d := DefaultBackupPolicy
+	if p.FullEvery <= 0 {
+		p.FullEvery = d.FullEvery
+	}
+	if p.IncrementalEvery <= 0 {
+		p.IncrementalEvery = d.IncrementalEvery
+	}
+	if p.VerifyEvery <= 0 {
+		p.VerifyEvery = d.VerifyEvery
+	}
+	if p.KeepFull <= 0 {
+		p.KeepFull = d.KeepFull
+	}
+	return &BackupScheduler{ledger: l, store: store, policy: p}
+}
+
+// Run takes backups on the policy's schedule until ctx is done. A full
+// backup is taken first when the store holds none.
+func (s *BackupScheduler) Run(ctx context.Context) error {
+	m, err := s.Manifest(ctx)
+	if err != nil {
+		return err
+	}
+	if latestFull(m) == nil {
+		if _, err := s.Full(ctx); err != nil {
+			return err
+		}
+	}
+	full := time.NewTicker(s.policy.FullEvery)
+	incr := time.NewTicker(s.policy.IncrementalEvery)
+	verify := time.NewTicker(s.policy.VerifyEvery)
+	defer full.Stop()
+	defer incr.Stop()
+	defer verify.Stop()
+	for {
+		var err error
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-full.C:
+			_, err = s.Full(ctx)
+		case <-incr.C:
+			_, err = s.Incremental(ctx)
+		case <-verify.C:
+			_, err = s.VerifyLatest(ctx)
+		}
+		if err != nil {
+			logrus.Errorf("backup: %v", err)
+			continue
+		}
+		if _, err := s.Prune(ctx); err != nil {
+			logrus.Errorf("backup retention: %v", err)
+		}
+	}
+}
+
+// Manifest loads the store's catalogue.
+func (s *BackupScheduler) Manifest(ctx context.Context) (BackupManifest, error) {
+	var m BackupManifest
+	raw, err := s.store.Get(ctx, backupManifestName)
+	if errors.Is(err, os.ErrNotExist) || errors.Is(err, ErrNotFound) {
+		return m, nil
+	}
+	if err != nil {
+		return m, err
+	}
+	return m, json.Unmarshal(raw, &m)
+}
+
+func (s *BackupScheduler) saveManifest(ctx context.Context, m BackupManifest) error {
+	raw, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return s.store.Put(ctx, backupManifestName, raw)
+}
+
+func latestFull(m BackupManifest) *BackupRecord {
+	for i := len(m.Records) - 1; i >= 0; i-- {
+		if m.Records[i].Kind == BackupFull {
+			return &m.Records[i]
+		}
+	}
+	return nil
+}
+
+func nextSeq(m BackupManifest) uint64 {
+	if n := len(m.Records); n > 0 {
+		return m.Records[n-1].Seq + 1
+	}
+	return 1
+}
+
+func copyState(state map[string][]byte) map[string][]byte {
+	out := make(map[string][]byte, len(state))
+	for k, v := range state {
+		out[k] = v
+	}
+	return out
+}
+
+// Full uploads a snapshot of the ledger as a new full backup.
+func (s *BackupScheduler) Full(ctx context.Context) (BackupRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.full(ctx)
+}
+
+func (s *BackupScheduler) full(ctx context.Context) (BackupRecord, error) {
+	s.ledger.mu.RLock()
+	snap, err := json.Marshal(s.ledger)
+	var tip *Block
+	if n := len(s.ledger.Blocks); n > 0 {
+		tip = s.ledger.Blocks[n-1]
+	}
+	root := stateRoot(s.ledger.State)
+	state := copyState(s.ledger.State)
+	s.ledger.mu.RUnlock()
+	if err != nil {
+		return BackupRecord{}, err
+	}
+	if tip == nil {
+		return BackupRecord{}, errors.New("ledger has no blocks to back up")
+	}
+	m, err := s.Manifest(ctx)
+	if err != nil {
+		return BackupRecord{}, err
+	}
+	seq := nextSeq(m)
+	rec := BackupRecord{
+		Seq:  seq,
+		Kind: BackupFull,
+		Name: fmt.Sprintf("full-%06d-h%d.snap.gz", seq, tip.Header.Height),
+		Base: seq,
+		From: s.ledger.firstHeight(), To: tip.Header.Height,
+		TipHash:   tip.Hash().Hex(),
+		StateRoot: root.Hex(),
+	}
+	if rec.SHA256, rec.Size, err = s.put(ctx, rec.Name, snap); err != nil {
+		return rec, err
+	}
+	if rec, err = s.record(ctx, m, rec); err == nil {
+		s.base, s.baseSeq = state, rec.Seq
+	}
+	return rec, err
+}
+
+// Incremental uploads the blocks appended since the newest backup as a WAL
+// segment, with the state delta since that backup. It takes a full backup
+// instead when there is none yet, when the ledger has pruned blocks the
+// segment would need, or when this scheduler did not take the previous
+// backup (after a restart) and so has no base state to diff against. It
+// does nothing when no block has been added.
+func (s *BackupScheduler) Incremental(ctx context.Context) (BackupRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, err := s.Manifest(ctx)
+	if err != nil {
+		return BackupRecord{}, err
+	}
+	base := latestFull(m)
+	if base == nil {
+		return s.full(ctx)
+	}
+	prev := m.Records[len(m.Records)-1]
+	from := prev.To + 1
+	if from < s.ledger.firstHeight() || s.base == nil || s.baseSeq != prev.Seq {
+		return s.full(ctx)
+	}
+	var seg bytes.Buffer
+	var tip *Block
+	delta := stateDelta{Set: make(map[string][]byte)}
+	s.ledger.mu.RLock()
+	for _, b := range s.ledger.Blocks {
+		if b.Header.Height < from {
+			continue
+		}
+		raw, err := json.Marshal(b)
+		if err != nil {
+			s.ledger.mu.RUnlock()
+			return BackupRecord{}, err
+		}
+		seg.Write(append(raw, '\n'))
+		tip = b
+	}
+	if tip == nil {
+		s.ledger.mu.RUnlock()
+		return BackupRecord{}, nil
+	}
+	for k, v := range s.ledger.State {
+		if old, ok := s.base[k]; !ok || !bytes.Equal(old, v) {
+			delta.Set[k] = v
+		}
+	}
+	for k := range s.base {
+		if _, ok := s.ledger.State[k]; !ok {
+			delta.Deleted = append(delta.Deleted, k)
+		}
+	}
+	root := stateRoot(s.ledger.State)
+	state := copyState(s.ledger.State)
+	s.ledger.mu.RUnlock()
+	sort.Strings(delta.Deleted)
+
+	seq := nextSeq(m)
+	rec := BackupRecord{
+		Seq:  seq,
+		Kind: BackupIncremental,
+		Name: fmt.Sprintf("incr-%06d-h%d-%d.wal.gz", seq, from, tip.Header.Height),
+		Base: base.Seq,
+		From: from, To: tip.Header.Height,
+		TipHash:   tip.Hash().Hex(),
+		StateRoot: root.Hex(),
+		DeltaName: fmt.Sprintf("incr-%06d.state.gz", seq),
+	}
+	rawDelta, err := json.Marshal(delta)
+	if err != nil {
+		return rec, err
+	}
+	if rec.SHA256, rec.Size, err = s.put(ctx, rec.Name, seg.Bytes()); err != nil {
+		return rec, err
+	}
+	if rec.DeltaSHA256, _, err = s.put(ctx, rec.DeltaName, rawDelta); err != nil {
+		return rec, err
+	}
+	if rec, err = s.record(ctx, m, rec); err == nil {
+		s.base, s.baseSeq = state, rec.Seq
+	}
+	return rec, err
+}
+
+// put compresses and uploads an object, returning its checksum and size.
+func (s *BackupScheduler) put(ctx context.Context, name string, data []byte) (string, int, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return "", 0, err
+	}
+	if err := zw.Close(); err != nil {
+		return "", 0, err
+	}
+	if err := s.store.Put(ctx, name, buf.Bytes()); err != nil {
+		return "", 0, fmt.Errorf("upload %s: %w", name, err)
+	}
+	sum := sha256.Sum256(buf.Bytes())
+	return hex.EncodeToString(sum[:]), buf.Len(), nil
+}
+
+// record adds an uploaded backup to the manifest.
+func (s *BackupScheduler) record(ctx context.Context, m BackupManifest, rec BackupRecord) (BackupRecord, error) {
+	rec.Created = time.Now().UTC()
+	m.Records = append(m.Records, rec)
+	if err := s.saveManifest(ctx, m); err != nil {
+		return rec, err
+	}
+	logrus.Infof("backup: %s backup %d uploaded (heights %d-%d, %d bytes)", rec.Kind, rec.Seq, rec.From, rec.To, rec.Size)
+	return rec, nil
+}
+
+// fetchBackup downloads a backup object and checks it against its
+// recorded checksum.
+func fetchBackup(ctx context.Context, store BackupStore, name, sum string) ([]byte, error) {
+	raw, err := store.Get(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("fetch %s: %w", name, err)
+	}
+	if got := sha256.Sum256(raw); hex.EncodeToString(got[:]) != sum {
+		return nil, fmt.Errorf("%s: checksum mismatch", name)
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	defer zr.Close()
+	return io.ReadAll(zr)
+}
+
+// backupChain returns the full backup base and its incrementals up to and
+// including seq (0 = all of them).
+func backupChain(m BackupManifest, base, seq uint64) ([]BackupRecord, error) {
+	var out []BackupRecord
+	for _, r := range m.Records {
+		if r.Base == base && (seq == 0 || r.Seq <= seq) {
+			out = append(out, r)
+		}
+	}
+	if len(out) == 0 || out[0].Kind != BackupFull {
+		return nil, fmt.Errorf("full backup %d not found", base)
+	}
+	return out, nil
+}
+
+// backupSegment is a downloaded incremental backup.
+type backupSegment struct {
+	rec    BackupRecord
+	blocks []*Block
+	delta  stateDelta
+}
+
+// loadChain downloads a backup chain, decodes the snapshot and checks that
+// every segment extends the chain before it, block by block.
+func loadChain(ctx context.Context, store BackupStore, chain []BackupRecord) ([]byte, []backupSegment, error) {
+	snap, err := fetchBackup(ctx, store, chain[0].Name, chain[0].SHA256)
+	if err != nil {
+		return nil, nil, err
+	}
+	var led Ledger
+	if err := json.Unmarshal(snap, &led); err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", chain[0].Name, err)
+	}
+	if len(led.Blocks) == 0 {
+		return nil, nil, fmt.Errorf("%s: snapshot holds no blocks", chain[0].Name)
+	}
+	tip := led.Blocks[len(led.Blocks)-1]
+	if tip.Header.Height != chain[0].To || tip.Hash().Hex() != chain[0].TipHash {
+		return nil, nil, fmt.Errorf("%s: snapshot tip does not match the manifest", chain[0].Name)
+	}
+	segs := make([]backupSegment, 0, len(chain)-1)
+	for _, rec := range chain[1:] {
+		seg := backupSegment{rec: rec}
+		raw, err := fetchBackup(ctx, store, rec.Name, rec.SHA256)
+		if err != nil {
+			return nil, nil, err
+		}
+		sc := bufio.NewScanner(bytes.NewReader(raw))
+		sc.Buffer(make([]byte, 1<<20), 1<<30)
+		for sc.Scan() {
+			var b Block
+			if err := json.Unmarshal(sc.Bytes(), &b); err != nil {
+				return nil, nil, fmt.Errorf("%s: %w", rec.Name, err)
+			}
+			if b.Header.Height != tip.Header.Height+1 {
+				return nil, nil, fmt.Errorf("%s: block %d does not follow %d", rec.Name, b.Header.Height, tip.Header.Height)
+			}
+			if ph := tip.Hash(); !bytes.Equal(b.Header.PrevHash, ph[:]) {
+				return nil, nil, fmt.Errorf("%s: block %d does not link to its parent", rec.Name, b.Header.Height)
+			}
+			tip = &b
+			seg.blocks = append(seg.blocks, &b)
+		}
+		if err := sc.Err(); err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", rec.Name, err)
+		}
+		if tip.Header.Height != rec.To || tip.Hash().Hex() != rec.TipHash {
+			return nil, nil, fmt.Errorf("%s: segment tip does not match the manifest", rec.Name)
+		}
+		if rec.DeltaName != "" {
+			raw, err := fetchBackup(ctx, store, rec.DeltaName, rec.DeltaSHA256)
+			if err != nil {
+				return nil, nil, err
+			}
+			if err := json.Unmarshal(raw, &seg.delta); err != nil {
+				return nil, nil, fmt.Errorf("%s: %w", rec.DeltaName, err)
+			}
+		}
+		segs = append(segs, seg)
+	}
+	return snap, segs, nil
+}
+
+// VerifyLatest checks that the newest backup chain is restorable: every
+// object matches its checksum, the snapshot decodes and each WAL segment
+// links onto the chain before it. Verified records are stamped in the
+// manifest.
+func (s *BackupScheduler) VerifyLatest(ctx context.Context) (uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, err := s.Manifest(ctx)
+	if err != nil {
+		return 0, err
+	}
+	base := latestFull(m)
+	if base == nil {
+		return 0, errors.New("no full backup to verify")
+	}
+	chain, err := backupChain(m, base.Seq, 0)
+	if err != nil {
+		return 0, err
+	}
+	if _, _, err := loadChain(ctx, s.store, chain); err != nil {
+		return 0, fmt.Errorf("backup %d is not restorable: %w", base.Seq, err)
+	}
+	now := time.Now().UTC()
+	for i := range m.Records {
+		if m.Records[i].Base == base.Seq {
+			m.Records[i].Verified = now
+		}
+	}
+	return chain[len(chain)-1].To, s.saveManifest(ctx, m)
+}
+
+// Prune enforces the retention policy and returns the names it deleted.
+func (s *BackupScheduler) Prune(ctx context.Context) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, err := s.Manifest(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var fulls []BackupRecord
+	for _, r := range m.Records {
+		if r.Kind == BackupFull {
+			fulls = append(fulls, r)
+		}
+	}
+	sort.Slice(fulls, func(i, j int) bool { return fulls[i].Seq > fulls[j].Seq })
+	drop := make(map[uint64]bool)
+	for i, f := range fulls {
+		if i == 0 {
+			continue
+		}
+		if i >= s.policy.KeepFull || (s.policy.MaxAge > 0 && time.Since(f.Created) > s.policy.MaxAge) {
+			drop[f.Seq] = true
+		}
+	}
+	if len(drop) == 0 {
+		return nil, nil
+	}
+	var kept []BackupRecord
+	var deleted []string
+	for _, r := range m.Records {
+		if !drop[r.Base] {
+			kept = append(kept, r)
+			continue
+		}
+		for _, name := range []string{r.Name, r.DeltaName} {
+			if name == "" {
+				continue
+			}
+			if err := s.store.Delete(ctx, name); err != nil {
+				return deleted, fmt.Errorf("delete %s: %w", name, err)
+			}
+			deleted = append(deleted, name)
+		}
+	}
+	m.Records = kept
+	return deleted, s.saveManifest(ctx, m)
+}
+
+// findChain resolves the backup chain ending at seq (0 = newest backup).
+func findChain(ctx context.Context, store BackupStore, seq uint64) ([]BackupRecord, error) {
+	m, err := (&BackupScheduler{store: store}).Manifest(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var target *BackupRecord
+	for i := range m.Records {
+		if m.Records[i].Seq == seq || (seq == 0 && i == len(m.Records)-1) {
+			target = &m.Records[i]
+		}
+	}
+	if target == nil {
+		return nil, fmt.Errorf("backup %d not found", seq)
+	}
+	return backupChain(m, target.Base, target.Seq)
+}
+
+// restoreChain writes the full backup of chain to dir as ledger.snap, opens
+// it and replays each segment: its blocks through AddBlock, which also
+// writes them to ledger.wal, then its state delta. step is called after the
+// snapshot and after every segment with the record just restored. On
+// success the restored state is saved as a fresh snapshot.
+func restoreChain(ctx context.Context, store BackupStore, chain []BackupRecord, dir string, step func(BackupRecord, *Ledger)) (*Ledger, error) {
+	snap, segs, err := loadChain(ctx, store, chain)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(filepath.Join(dir, "ledger.snap")); err == nil {
+		return nil, fmt.Errorf("%s already holds a ledger", dir)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "ledger.snap"), snap, 0o600); err != nil {
+		return nil, err
+	}
+	led, err := OpenLedger(dir)
+	if err != nil {
+		return nil, err
+	}
+	if step != nil {
+		step(chain[0], led)
+	}
+	for _, seg := range segs {
+		for _, b := range seg.blocks {
+			if err := led.AddBlock(b); err != nil {
+				led.Close()
+				return nil, fmt.Errorf("replay block %d: %w", b.Header.Height, err)
+			}
+		}
+		led.mu.Lock()
+		for k, v := range seg.delta.Set {
+			led.State[k] = v
+		}
+		for _, k := range seg.delta.Deleted {
+			delete(led.State, k)
+		}
+		led.mu.Unlock()
+		if step != nil {
+			step(seg.rec, led)
+		}
+	}
+	led.mu.Lock()
+	err = led.snapshot()
+	led.mu.Unlock()
+	if err != nil {
+		led.Close()
+		return nil, err
+	}
+	return led, nil
+}
+
+// RestoreBackup rebuilds a ledger directory from the backup chain ending
+// at seq (0 = newest). The directory must not already hold a ledger.
+func RestoreBackup(ctx context.Context, store BackupStore, seq uint64, dir string) (uint64, error) {
+	chain, err := findChain(ctx, store, seq)
+	if err != nil {
+		return 0, err
+	}
+	led, err := restoreChain(ctx, store, chain, dir, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer led.Close()
+	return led.LastHeight(), nil
+}
+
+// firstHeight is the height of the oldest retained block.
+func (l *Ledger) firstHeight() uint64 {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if len(l.Blocks) == 0 {
+		return 0
+	}
+	return l.Blocks[0].Header.Height
+}
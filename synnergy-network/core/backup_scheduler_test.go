@@ -0,0 +1,184 @@
+package core
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newBackupLedger returns a ledger holding genesis and one block.
+func newBackupLedger(t *testing.T) *Ledger {
+	t.Helper()
+	cfg, _ := tmpLedgerConfig(t, &Block{Header: BlockHeader{Height: 0}})
+	led, err := NewLedger(cfg)
+	if err != nil {
+		t.Fatalf("ledger: %v", err)
+	}
+	t.Cleanup(func() { led.Close() })
+	addBackupBlocks(t, led, 1)
+	return led
+}
+
+// addBackupBlocks appends n blocks linked to the current tip.
+func addBackupBlocks(t *testing.T, led *Ledger, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		prev := led.LastBlockHash()
+		b := &Block{Header: BlockHeader{Height: led.LastHeight() + 1, PrevHash: prev[:]}}
+		if err := led.AddBlock(b); err != nil {
+			t.Fatalf("add block %d: %v", b.Header.Height, err)
+		}
+	}
+}
+
+func TestBackupFullAndIncrementalRestore(t *testing.T) {
+	ctx := context.Background()
+	led := newBackupLedger(t)
+	store := FSBackupStore{Dir: t.TempDir()}
+	s := NewBackupScheduler(led, store, BackupPolicy{})
+
+	_ = led.SetState([]byte("kept"), []byte("1"))
+	_ = led.SetState([]byte("dropped"), []byte("1"))
+	full, err := s.Incremental(ctx) // no full backup yet
+	if err != nil {
+		t.Fatalf("first backup: %v", err)
+	}
+	if full.Kind != BackupFull || full.To != 1 {
+		t.Fatalf("first backup %+v", full)
+	}
+
+	addBackupBlocks(t, led, 2)
+	_ = led.SetState([]byte("kept"), []byte("2"))
+	_ = led.DeleteState([]byte("dropped"))
+	_ = led.SetState([]byte("added"), []byte("3"))
+	incr, err := s.Incremental(ctx)
+	if err != nil {
+		t.Fatalf("incremental: %v", err)
+	}
+	if incr.Kind != BackupIncremental || incr.Base != full.Seq || incr.From != 2 || incr.To != 3 || incr.DeltaName == "" {
+		t.Fatalf("incremental %+v", incr)
+	}
+	if rec, err := s.Incremental(ctx); err != nil || rec.Seq != 0 {
+		t.Fatalf("incremental without new blocks: %+v err=%v", rec, err)
+	}
+
+	if to, err := s.VerifyLatest(ctx); err != nil || to != 3 {
+		t.Fatalf("verify: to=%d err=%v", to, err)
+	}
+	m, _ := s.Manifest(ctx)
+	for _, r := range m.Records {
+		if r.Verified.IsZero() {
+			t.Fatalf("record %d not stamped verified", r.Seq)
+		}
+	}
+
+	// replaying the chain reaches the state root recorded for each backup
+	chain, err := findChain(ctx, store, 0)
+	if err != nil {
+		t.Fatalf("chain: %v", err)
+	}
+	dir := filepath.Join(t.TempDir(), "restored")
+	restored, err := restoreChain(ctx, store, chain, dir, func(rec BackupRecord, l *Ledger) {
+		if got := stateRoot(l.State).Hex(); got != rec.StateRoot {
+			t.Errorf("backup %d restored to root %s, want %s", rec.Seq, got, rec.StateRoot)
+		}
+	})
+	if err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if restored.LastHeight() != 3 || restored.LastBlockHash() != led.LastBlockHash() {
+		t.Fatalf("restored tip %d", restored.LastHeight())
+	}
+	if v, _ := restored.GetState([]byte("kept")); string(v) != "2" {
+		t.Fatalf("kept = %q", v)
+	}
+	if ok, _ := restored.HasState([]byte("dropped")); ok {
+		t.Fatal("deleted key restored")
+	}
+	restored.Close()
+
+	if _, err := RestoreBackup(ctx, store, 0, dir); err == nil {
+		t.Fatal("restored over an existing ledger")
+	}
+	// point-in-time restore of the full backup alone
+	if h, err := RestoreBackup(ctx, store, full.Seq, filepath.Join(t.TempDir(), "pit")); err != nil || h != 1 {
+		t.Fatalf("restore full backup: height=%d err=%v", h, err)
+	}
+}
+
+func TestBackupVerifyDetectsCorruption(t *testing.T) {
+	ctx := context.Background()
+	led := newBackupLedger(t)
+	store := FSBackupStore{Dir: t.TempDir()}
+	s := NewBackupScheduler(led, store, BackupPolicy{})
+	if _, err := s.Full(ctx); err != nil {
+		t.Fatalf("full: %v", err)
+	}
+	addBackupBlocks(t, led, 1)
+	incr, err := s.Incremental(ctx)
+	if err != nil {
+		t.Fatalf("incremental: %v", err)
+	}
+
+	path := filepath.Join(store.Dir, incr.Name)
+	raw, _ := os.ReadFile(path)
+	raw[len(raw)-1] ^= 0xff
+	if err := os.WriteFile(path, raw, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.VerifyLatest(ctx); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Fatalf("verify corrupted segment: err=%v", err)
+	}
+	if _, err := RestoreBackup(ctx, store, 0, filepath.Join(t.TempDir(), "r")); err == nil {
+		t.Fatal("restored a corrupted chain")
+	}
+
+	// a scheduler without the base state starts a new chain
+	incr2, err := NewBackupScheduler(led, store, BackupPolicy{}).Incremental(ctx)
+	if err != nil || incr2.Kind != BackupFull {
+		t.Fatalf("incremental after restart: %+v err=%v", incr2, err)
+	}
+}
+
+func TestBackupPruneKeepsNewestChains(t *testing.T) {
+	ctx := context.Background()
+	led := newBackupLedger(t)
+	store := FSBackupStore{Dir: t.TempDir()}
+	s := NewBackupScheduler(led, store, BackupPolicy{KeepFull: 2})
+
+	var chains [][]BackupRecord
+	for i := 0; i < 3; i++ {
+		full, err := s.Full(ctx)
+		if err != nil {
+			t.Fatalf("full: %v", err)
+		}
+		addBackupBlocks(t, led, 1)
+		incr, err := s.Incremental(ctx)
+		if err != nil {
+			t.Fatalf("incremental: %v", err)
+		}
+		chains = append(chains, []BackupRecord{full, incr})
+	}
+
+	deleted, err := s.Prune(ctx)
+	if err != nil {
+		t.Fatalf("prune: %v", err)
+	}
+	if len(deleted) != 3 { // snapshot, segment and delta of the oldest chain
+		t.Fatalf("deleted %v", deleted)
+	}
+	for _, name := range deleted {
+		if _, err := os.Stat(filepath.Join(store.Dir, name)); !os.IsNotExist(err) {
+			t.Fatalf("%s still in the store", name)
+		}
+	}
+	m, _ := s.Manifest(ctx)
+	if len(m.Records) != 4 || m.Records[0].Seq != chains[1][0].Seq {
+		t.Fatalf("manifest after prune %+v", m.Records)
+	}
+	if deleted, err := s.Prune(ctx); err != nil || len(deleted) != 0 {
+		t.Fatalf("second prune deleted %v err=%v", deleted, err)
+	}
+}
@@ -0,0 +1,226 @@
+package core
+
+// balances.go – typed account balances.
+//
+// The ledger keeps the balances it holds itself, of the native coin and of
+// the tokens minted with MintToken, in one map keyed by BalanceKey: the
+// asset and the account. The coin is the asset Code, so Transfer, Mint,
+// Burn, MintToken(addr, Code), BalanceOf and CoinBalance all read and write
+// the same entry. Registry tokens keep their balances in their own tables.
+//
+// Keys persist in the text form "<hex address>:<asset>". Ledgers written
+// before typed keys stored balances under several string formats – the bare
+// hex address, hex with a ":SYNN" or token suffix, and raw address bytes –
+// which the coin paths did not read alike, so one account could hold
+// disjoint coin balances. MigrateBalances folds such a map into typed keys,
+// summing the entries that name the same balance; the ledger runs it when
+// it opens an old snapshot and CheckBalanceInvariants verifies the result.
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ErrBalanceInvariant is returned when the balance table is inconsistent.
+var ErrBalanceInvariant = errors.New("balance invariant violated")
+
+// LedgerAsset names an asset held in the ledger's balance table: Code for
+// the native coin, else the name a token was minted under with MintToken.
+// Registry tokens are identified by TokenID instead.
+type LedgerAsset string
+
+// BalanceKey identifies the balance of one asset held by one account.
+type BalanceKey struct {
+	Token LedgerAsset // see LedgerAsset
+	Addr  Address     // holder
+}
+
+// CoinKey is the key of the native coin balance of a.
+func CoinKey(a Address) BalanceKey { return BalanceKey{Token: Code, Addr: a} }
+
+// TokenKey is the key of the balance of token held by a.
+func TokenKey(token LedgerAsset, a Address) BalanceKey { return BalanceKey{Token: token, Addr: a} }
+
+// String returns the persisted form "<hex address>:<asset>".
+func (k BalanceKey) String() string {
+	return hex.EncodeToString(k.Addr[:]) + ":" + string(k.Token)
+}
+
+// MarshalText encodes k in its persisted form.
+func (k BalanceKey) MarshalText() ([]byte, error) {
+	if k.Token == "" {
+		return nil, fmt.Errorf("%w: balance key without asset", ErrBalanceInvariant)
+	}
+	return []byte(k.String()), nil
+}
+
+// UnmarshalText decodes the persisted form of a key.
+func (k *BalanceKey) UnmarshalText(text []byte) error {
+	key, err := ParseBalanceKey(string(text))
+	if err != nil {
+		return err
+	}
+	*k = key
+	return nil
+}
+
+// ParseBalanceKey parses "<hex address>:<asset>".
+func ParseBalanceKey(s string) (BalanceKey, error) {
+	addrHex, token, ok := strings.Cut(s, ":")
+	if !ok || token == "" || len(addrHex) != 2*len(Address{}) {
+		return BalanceKey{}, fmt.Errorf("invalid balance key %q", s)
+	}
+	var k BalanceKey
+	if _, err := hex.Decode(k.Addr[:], []byte(addrHex)); err != nil {
+		return BalanceKey{}, fmt.Errorf("invalid balance key %q: %w", s, err)
+	}
+	k.Token = LedgerAsset(token)
+	return k, nil
+}
+
+// legacyBalanceKey maps a key of the string-keyed balance map to the
+// balance it held.
+func legacyBalanceKey(s string) (BalanceKey, error) {
+	if len(s) == len(Address{}) {
+		// raw address bytes, credited by MintBig
+		var a Address
+		copy(a[:], s)
+		return CoinKey(a), nil
+	}
+	addrHex, token, _ := strings.Cut(strings.TrimPrefix(s, "0x"), ":")
+	if token == "" {
+		token = Code
+	}
+	return ParseBalanceKey(strings.ToLower(addrHex) + ":" + token)
+}
+
+// BalanceMigration reports the outcome of MigrateBalances.
+type BalanceMigration struct {
+	Entries int                    `json:"entries"` // legacy entries read
+	Merged  int                    `json:"merged"`  // entries summed into another entry's balance
+	Totals  map[LedgerAsset]uint64 `json:"totals"`  // per asset; the same before and after
+}
+
+// MigrateBalances converts a string-keyed balance map to typed keys.
+// Entries naming the same balance are summed. Unrecognised keys fail the
+// migration rather than being dropped.
+func MigrateBalances(legacy map[string]uint64) (map[BalanceKey]uint64, BalanceMigration, error) {
+	out := make(map[BalanceKey]uint64, len(legacy))
+	rep := BalanceMigration{Entries: len(legacy), Totals: make(map[LedgerAsset]uint64)}
+	keys := make([]string, 0, len(legacy))
+	for k := range legacy {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var bad []string
+	for _, s := range keys {
+		k, err := legacyBalanceKey(s)
+		if err != nil {
+			bad = append(bad, fmt.Sprintf("%q", s))
+			continue
+		}
+		v := legacy[s]
+		cur, seen := out[k]
+		if v > math.MaxUint64-cur || v > math.MaxUint64-rep.Totals[k.Token] {
+			return nil, rep, fmt.Errorf("%w: %s overflows", ErrBalanceInvariant, k)
+		}
+		if seen {
+			rep.Merged++
+		}
+		out[k] = cur + v
+		rep.Totals[k.Token] += v
+	}
+	if len(bad) > 0 {
+		return nil, rep, fmt.Errorf("%w: unrecognised balance keys %s", ErrBalanceInvariant, strings.Join(bad, ", "))
+	}
+	if err := checkBalances(out); err != nil {
+		return nil, rep, err
+	}
+	return out, rep, nil
+}
+
+// checkBalances verifies that every key names an asset and that no asset's
+// supply overflows, nor the coin's exceed MaxSupply.
+func checkBalances(m map[BalanceKey]uint64) error {
+	totals := make(map[LedgerAsset]uint64)
+	for k, v := range m {
+		if k.Token == "" {
+			return fmt.Errorf("%w: balance of %s without asset", ErrBalanceInvariant, k.Addr.Hex())
+		}
+		if v > math.MaxUint64-totals[k.Token] {
+			return fmt.Errorf("%w: supply of %s overflows", ErrBalanceInvariant, k.Token)
+		}
+		totals[k.Token] += v
+	}
+	if totals[Code] > MaxSupply {
+		return fmt.Errorf("%w: coin supply %d exceeds %d", ErrBalanceInvariant, totals[Code], MaxSupply)
+	}
+	return nil
+}
+
+// CheckBalanceInvariants verifies the ledger's balance table and its
+// supply records.
+func (l *Ledger) CheckBalanceInvariants() error {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if err := checkBalances(l.Balances); err != nil {
+		return err
+	}
+	return l.checkSupply()
+}
+
+// adoptLegacyBalances migrates balances decoded from a ledger written with
+// string keys and merges them into l.Balances.
+func (l *Ledger) adoptLegacyBalances(legacy map[string]uint64) error {
+	if len(legacy) == 0 {
+		return nil
+	}
+	m, rep, err := MigrateBalances(legacy)
+	if err != nil {
+		return err
+	}
+	for k, v := range m {
+		l.credit(k, v)
+	}
+	if err := checkBalances(l.Balances); err != nil {
+		return err
+	}
+	logrus.Infof("balances: migrated %d legacy entries (%d merged) to typed keys", rep.Entries, rep.Merged)
+	return nil
+}
+
+// AssetSupply returns the sum of all ledger balances of token.
+func (l *Ledger) AssetSupply(token LedgerAsset) uint64 {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	var total uint64
+	for k, v := range l.Balances {
+		if k.Token == token {
+			total += v
+		}
+	}
+	return total
+}
+
+// credit adds amt to the balance at k. The caller holds l.mu.
+func (l *Ledger) credit(k BalanceKey, amt uint64) {
+	if l.Balances == nil {
+		l.Balances = make(map[BalanceKey]uint64)
+	}
+	l.Balances[k] += amt
+}
+
+// debit subtracts amt from the balance at k. The caller holds l.mu.
+func (l *Ledger) debit(k BalanceKey, amt uint64) error {
+	if l.Balances[k] < amt {
+		return ErrInsufficientBalance
+	}
+	l.Balances[k] -= amt
+	return nil
+}
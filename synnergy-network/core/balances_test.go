@@ -0,0 +1,140 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestMigrateBalancesMergesLegacyKeys(t *testing.T) {
+	a, b := Address{1}, Address{2}
+	legacy := map[string]uint64{
+		a.String():               10, // Transfer/Mint/Burn
+		a.String() + ":" + Code:  5,  // MintToken(addr, Code)
+		"0x" + b.String():        3,
+		string(b[:]):             4, // MintBig
+		a.String() + ":ABC":      7,
+		b.String() + ":" + "ABC": 1,
+	}
+	m, rep, err := MigrateBalances(legacy)
+	if err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	want := map[BalanceKey]uint64{CoinKey(a): 15, CoinKey(b): 7, TokenKey("ABC", a): 7, TokenKey("ABC", b): 1}
+	if len(m) != len(want) {
+		t.Fatalf("migrated = %v", m)
+	}
+	for k, v := range want {
+		if m[k] != v {
+			t.Fatalf("%s = %d, want %d", k, m[k], v)
+		}
+	}
+	if rep.Entries != 6 || rep.Merged != 2 || rep.Totals[Code] != 22 || rep.Totals["ABC"] != 8 {
+		t.Fatalf("report = %+v", rep)
+	}
+
+	if _, _, err := MigrateBalances(map[string]uint64{"nope": 1}); !errors.Is(err, ErrBalanceInvariant) {
+		t.Fatalf("unrecognised key: %v", err)
+	}
+	if _, _, err := MigrateBalances(map[string]uint64{a.String(): MaxSupply, b.String(): 1}); !errors.Is(err, ErrBalanceInvariant) {
+		t.Fatalf("supply above cap: %v", err)
+	}
+}
+
+func TestBalanceKeysRoundTripAndShareCoinPaths(t *testing.T) {
+	a, b := Address{1}, Address{2}
+	l := &Ledger{}
+	if err := l.MintToken(a, Code, 50); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Transfer(a, b, 20); err != nil {
+		t.Fatalf("transfer of minted coin: %v", err)
+	}
+	if err := l.Burn(b, 5); err != nil {
+		t.Fatal(err)
+	}
+	if l.BalanceOf(a) != 30 || l.CoinBalance(b) != 15 || l.AssetSupply(Code) != 45 {
+		t.Fatalf("balances = %v", l.Balances)
+	}
+
+	raw, err := json.Marshal(l.Balances)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var back map[BalanceKey]uint64
+	if err := json.Unmarshal(raw, &back); err != nil {
+		t.Fatalf("decode %s: %v", raw, err)
+	}
+	if back[CoinKey(a)] != 30 || back[CoinKey(b)] != 15 {
+		t.Fatalf("round trip = %v", back)
+	}
+	if err := l.CheckBalanceInvariants(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestZeroAddressTransferIsBurn(t *testing.T) {
+	a, b := Address{1}, Address{2}
+	l := &Ledger{}
+	if err := l.Mint(a, 100); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Transfer(a, AddressZero, 30); err != nil {
+		t.Fatalf("burn by transfer: %v", err)
+	}
+	if err := l.Burn(a, 10); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Transfer(AddressZero, b, 1); !errors.Is(err, ErrZeroAddress) {
+		t.Fatalf("transfer from zero address: %v", err)
+	}
+	s := l.SupplyOf(Code)
+	if s.Minted != 100 || s.Burned != 40 || s.Circulating != 60 || l.BalanceOf(AddressZero) != 0 {
+		t.Fatalf("supply = %+v, zero address holds %d", s, l.BalanceOf(AddressZero))
+	}
+	if err := l.CheckBalanceInvariants(); err != nil {
+		t.Fatal(err)
+	}
+
+	// a pre-accounting ledger: zero-address balances count as burned
+	old := &Ledger{Balances: map[BalanceKey]uint64{CoinKey(a): 5, CoinKey(AddressZero): 3}}
+	old.backfillSupply()
+	if s := old.SupplyOf(Code); s.Minted != 8 || s.Burned != 3 {
+		t.Fatalf("back-filled supply = %+v", s)
+	}
+	if err := old.CheckBalanceInvariants(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBlockOverdrawingATokenTransferIsRejected(t *testing.T) {
+	l := newDepositLedger(t)
+	a, b, c := Address{1}, Address{2}, Address{3}
+	if err := l.Mint(a, 100); err != nil {
+		t.Fatal(err)
+	}
+	transfer := func(from, to Address, amt uint64) *Transaction {
+		return &Transaction{TokenTransfers: []TokenTransfer{{From: from, To: to, Amount: amt}}}
+	}
+
+	// the second transfer overdraws a once the first has been applied
+	over := &Block{Header: BlockHeader{Height: 0}, Transactions: []*Transaction{transfer(a, b, 60), transfer(a, c, 50)}}
+	if err := l.applyBlock(over, false); !errors.Is(err, ErrInsufficientBalance) {
+		t.Fatalf("overdrawn block: %v", err)
+	}
+	if len(l.Blocks) != 0 || l.BalanceOf(a) != 100 || l.BalanceOf(b) != 0 || l.BalanceOf(c) != 0 {
+		t.Fatalf("rejected block changed state: %d blocks, balances %d %d %d",
+			len(l.Blocks), l.BalanceOf(a), l.BalanceOf(b), l.BalanceOf(c))
+	}
+
+	ok := &Block{Header: BlockHeader{Height: 0}, Transactions: []*Transaction{transfer(a, b, 60), transfer(b, AddressZero, 10)}}
+	if err := l.applyBlock(ok, false); err != nil {
+		t.Fatalf("block: %v", err)
+	}
+	if l.BalanceOf(a) != 40 || l.BalanceOf(b) != 50 || l.TotalBurned(Code) != 10 {
+		t.Fatalf("balances %d %d, burned %d", l.BalanceOf(a), l.BalanceOf(b), l.TotalBurned(Code))
+	}
+	if err := l.CheckBalanceInvariants(); err != nil {
+		t.Fatal(err)
+	}
+}
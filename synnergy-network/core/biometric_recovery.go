@@ -0,0 +1,204 @@
+package core
+
+// biometric_recovery.go – wallet recovery gated on biometrics and a second
+// factor.
+//
+// The account owner registers recovery by signing RecoveryEnrolMessage with
+// the account's ed25519 key; this stops anyone from binding their own
+// biometric to somebody else's account. The enrolment stores a protected
+// biometric template and an argon2id hash of a second factor (PIN or
+// recovery code). RecoverAccountBiometric requires both to match and then
+// moves the account balance to a fresh address. Repeated failures lock the enrolment.
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"golang.org/x/crypto/argon2"
+)
+
+const (
+	// RecoveryMaxFailures is the number of failed attempts before lockout.
+	RecoveryMaxFailures = 5
+	// RecoveryLockout is how long an enrolment stays locked.
+	RecoveryLockout = 24 * time.Hour
+)
+
+var (
+	ErrRecoverySignature = errors.New("recovery enrolment signature invalid")
+	ErrRecoveryLocked    = errors.New("recovery locked after repeated failures")
+	ErrRecoveryFactor    = errors.New("second factor does not match")
+)
+
+// RecoveryEnrolment binds an account to a biometric template and a second
+// factor. Neither the sample nor the factor is stored.
+type RecoveryEnrolment struct {
+	Address     Address     `json:"address"`
+	Template    BioTemplate `json:"template"`
+	FactorSalt  []byte      `json:"factor_salt"`
+	FactorHash  []byte      `json:"factor_hash"`
+	Enrolled    time.Time   `json:"enrolled"`
+	Failures    int         `json:"failures"`
+	LockedUntil time.Time   `json:"locked_until,omitempty"`
+}
+
+// RecoveryRecord documents a completed recovery.
+type RecoveryRecord struct {
+	From   Address   `json:"from"`
+	To     Address   `json:"to"`
+	Amount uint64    `json:"amount"`
+	Time   time.Time `json:"time"`
+}
+
+func recoveryEnrolKey(addr Address) []byte { return []byte("bio:recovery:" + addr.Hex()) }
+func recoveryRecordKey(addr Address) []byte {
+	return []byte("bio:recovered:" + addr.Hex())
+}
+
+// RecoveryEnrolMessage is the payload the account key signs to register
+// recovery. It commits to the second factor so a relayer cannot swap it.
+func RecoveryEnrolMessage(addr Address, factor []byte, timestamp int64) []byte {
+	fh := sha256.Sum256(factor)
+	return []byte("synnergy-bio-recovery:" + addr.Hex() + ":" + hex.EncodeToString(fh[:]) + ":" + strconv.FormatInt(timestamp, 10))
+}
+
+// RegisterBiometricRecovery enrols sample and factor for addr. pub must
+// derive to addr and sig must cover RecoveryEnrolMessage with a timestamp
+// within five minutes of now. An existing enrolment is replaced.
+func RegisterBiometricRecovery(led *Ledger, addr Address, sample, factor []byte, timestamp int64, pub ed25519.PublicKey, sig []byte) (*RecoveryEnrolment, error) {
+	if led == nil {
+		return nil, errors.New("ledger not initialised")
+	}
+	if len(pub) != ed25519.PublicKeySize || Ed25519Address(pub) != addr ||
+		!ed25519.Verify(pub, RecoveryEnrolMessage(addr, factor, timestamp), sig) {
+		return nil, ErrRecoverySignature
+	}
+	if d := time.Since(time.Unix(timestamp, 0)); d > 5*time.Minute || d < -5*time.Minute {
+		return nil, fmt.Errorf("%w: stale timestamp", ErrRecoverySignature)
+	}
+	if len(factor) < 6 {
+		return nil, errors.New("second factor must be at least 6 bytes")
+	}
+	t, _, err := GenerateBioTemplate(sample, 0)
+	if err != nil {
+		return nil, err
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	enr := &RecoveryEnrolment{
+		Address:    addr,
+		Template:   *t,
+		FactorSalt: salt,
+		FactorHash: recoveryFactorHash(factor, salt),
+		Enrolled:   time.Now().UTC(),
+	}
+	if err := putRecoveryEnrolment(led, enr); err != nil {
+		return nil, err
+	}
+	recoveryAudit(addr, "bio_recovery_registered", nil)
+	return enr, nil
+}
+
+// RecoverAccountBiometric verifies the biometric sample and the second
+// factor for addr and moves its balance to newAddr. The enrolment is consumed.
+func RecoverAccountBiometric(led *Ledger, addr, newAddr Address, sample, factor []byte) (*RecoveryRecord, error) {
+	if led == nil {
+		return nil, errors.New("ledger not initialised")
+	}
+	if addr == newAddr {
+		return nil, errors.New("recovery target must differ from account")
+	}
+	enr, err := GetRecoveryEnrolment(led, addr)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	if now.Before(enr.LockedUntil) {
+		return nil, ErrRecoveryLocked
+	}
+	if _, err := enr.Template.Reproduce(sample); err != nil {
+		return nil, recoveryFailed(led, enr, err)
+	}
+	if subtle.ConstantTimeCompare(recoveryFactorHash(factor, enr.FactorSalt), enr.FactorHash) != 1 {
+		return nil, recoveryFailed(led, enr, ErrRecoveryFactor)
+	}
+
+	bal, err := NewAccountManager(led).Balance(addr)
+	if err != nil {
+		return nil, err
+	}
+	if bal > 0 {
+		if err := led.Transfer(addr, newAddr, bal); err != nil {
+			return nil, err
+		}
+	}
+	rec := &RecoveryRecord{From: addr, To: newAddr, Amount: bal, Time: now}
+	raw, _ := json.Marshal(rec)
+	if err := led.SetState(recoveryRecordKey(addr), raw); err != nil {
+		return nil, err
+	}
+	if err := led.DeleteState(recoveryEnrolKey(addr)); err != nil {
+		return nil, err
+	}
+	recoveryAudit(addr, "bio_account_recovered", map[string]string{"to": newAddr.Hex(), "amount": strconv.FormatUint(bal, 10)})
+	Broadcast("bio:recovered", raw)
+	return rec, nil
+}
+
+// GetRecoveryEnrolment returns the recovery enrolment of addr.
+func GetRecoveryEnrolment(led *Ledger, addr Address) (*RecoveryEnrolment, error) {
+	raw, err := led.GetState(recoveryEnrolKey(addr))
+	if err != nil || len(raw) == 0 {
+		return nil, ErrNotFound
+	}
+	var enr RecoveryEnrolment
+	if err := json.Unmarshal(raw, &enr); err != nil {
+		return nil, err
+	}
+	return &enr, nil
+}
+
+// GetRecoveryRecord returns the record of a completed recovery of addr.
+func GetRecoveryRecord(led *Ledger, addr Address) (*RecoveryRecord, error) {
+	raw, err := led.GetState(recoveryRecordKey(addr))
+	if err != nil || len(raw) == 0 {
+		return nil, ErrNotFound
+	}
+	var rec RecoveryRecord
+	if err := json.Unmarshal(raw, &rec); err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
+
+func recoveryFailed(led *Ledger, enr *RecoveryEnrolment, cause error) error {
+	enr.Failures++
+	if enr.Failures >= RecoveryMaxFailures {
+		enr.Failures = 0
+		enr.LockedUntil = time.Now().UTC().Add(RecoveryLockout)
+	}
+	if err := putRecoveryEnrolment(led, enr); err != nil {
+		return err
+	}
+	recoveryAudit(enr.Address, "bio_recovery_failed", map[string]string{"reason": cause.Error()})
+	return cause
+}
+
+func recoveryFactorHash(factor, salt []byte) []byte {
+	return argon2.IDKey(factor, salt, 1, 64*1024, 4, 32)
+}
+
+func putRecoveryEnrolment(led *Ledger, enr *RecoveryEnrolment) error {
+	raw, _ := json.Marshal(enr)
+	return led.SetState(recoveryEnrolKey(enr.Address), raw)
+}
@@ -25,7 +25,7 @@ func NewBiometricSecurityNode(netCfg Config, ledCfg LedgerConfig) (*BiometricSec
 		_ = n.Close()
 		return nil, err
 	}
-	return &BiometricSecurityNode{Node: n, ledger: led, auth: NewBiometricsAuth()}, nil
+	return &BiometricSecurityNode{Node: n, ledger: led, auth: NewLedgerBiometricsAuth(led)}, nil
 }
 
 // Enroll registers biometric data for an address.
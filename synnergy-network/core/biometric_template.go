@@ -0,0 +1,141 @@
+package core
+
+// biometric_template.go – template protection for biometric samples.
+//
+// Raw biometric templates are never stored. Enrolment uses a code-offset
+// fuzzy extractor: a random secret is encoded with a repetition code and
+// XORed with the sample to produce public helper data. A later, noisy
+// sample of the same trait XORed with the helper yields the codeword with
+// a few flipped bits, which majority decoding corrects back to the secret.
+// Only the helper data, a salt and a hash of the derived key are kept.
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+)
+
+const (
+	// BioSecretBits is the entropy of the secret bound to a sample.
+	BioSecretBits = 128
+	// DefaultBioRepetition tolerates up to three flipped bits per seven.
+	DefaultBioRepetition = 7
+)
+
+var ErrBioMismatch = errors.New("biometric sample does not match template")
+
+// BioTemplate is the public part of a fuzzy-extractor enrolment. It reveals
+// neither the sample nor the derived key.
+type BioTemplate struct {
+	Helper     []byte   `json:"helper"`
+	Salt       []byte   `json:"salt"`
+	KeyHash    [32]byte `json:"key_hash"`
+	Repetition int      `json:"repetition"`
+	SampleLen  int      `json:"sample_len"`
+}
+
+// BioSampleSize returns the minimum sample length in bytes for rep.
+func BioSampleSize(rep int) int { return (BioSecretBits*rep + 7) / 8 }
+
+// GenerateBioTemplate enrols sample and returns the template together with
+// the stable 32-byte key it protects. rep must be odd; zero selects the
+// default.
+func GenerateBioTemplate(sample []byte, rep int) (*BioTemplate, []byte, error) {
+	if rep == 0 {
+		rep = DefaultBioRepetition
+	}
+	if rep < 3 || rep%2 == 0 {
+		return nil, nil, errors.New("repetition must be odd and at least 3")
+	}
+	n := BioSampleSize(rep)
+	if len(sample) < n {
+		return nil, nil, fmt.Errorf("biometric sample too short: need %d bytes", n)
+	}
+	secret := make([]byte, BioSecretBits/8)
+	salt := make([]byte, 16)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, nil, err
+	}
+	if _, err := rand.Read(salt); err != nil {
+		return nil, nil, err
+	}
+	cw := repetitionEncode(secret, rep, n)
+	helper := make([]byte, n)
+	for i := range helper {
+		helper[i] = cw[i] ^ sample[i]
+	}
+	key := bioKey(salt, secret)
+	t := &BioTemplate{
+		Helper:     helper,
+		Salt:       salt,
+		KeyHash:    bioKeyHash(salt, key),
+		Repetition: rep,
+		SampleLen:  len(sample),
+	}
+	return t, key, nil
+}
+
+// Reproduce recovers the key from a fresh sample. It fails with
+// ErrBioMismatch when the sample is too far from the enrolled one.
+func (t *BioTemplate) Reproduce(sample []byte) ([]byte, error) {
+	if t == nil || len(t.Helper) == 0 {
+		return nil, errors.New("empty template")
+	}
+	if len(sample) != t.SampleLen {
+		return nil, ErrBioMismatch
+	}
+	cw := make([]byte, len(t.Helper))
+	for i := range cw {
+		cw[i] = t.Helper[i] ^ sample[i]
+	}
+	secret := repetitionDecode(cw, t.Repetition)
+	key := bioKey(t.Salt, secret)
+	h := bioKeyHash(t.Salt, key)
+	if subtle.ConstantTimeCompare(h[:], t.KeyHash[:]) != 1 {
+		return nil, ErrBioMismatch
+	}
+	return key, nil
+}
+
+func bioKey(salt, secret []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte("synnergy-bio-key"))
+	h.Write(salt)
+	h.Write(secret)
+	return h.Sum(nil)
+}
+
+func bioKeyHash(salt, key []byte) [32]byte {
+	return sha256.Sum256(append(append([]byte("synnergy-bio-check"), salt...), key...))
+}
+
+func repetitionEncode(secret []byte, rep, size int) []byte {
+	out := make([]byte, size)
+	for i := 0; i < len(secret)*8; i++ {
+		if secret[i/8]>>(7-uint(i%8))&1 == 0 {
+			continue
+		}
+		for j := 0; j < rep; j++ {
+			pos := i*rep + j
+			out[pos/8] |= 1 << (7 - uint(pos%8))
+		}
+	}
+	return out
+}
+
+func repetitionDecode(cw []byte, rep int) []byte {
+	secret := make([]byte, BioSecretBits/8)
+	for i := 0; i < BioSecretBits; i++ {
+		ones := 0
+		for j := 0; j < rep; j++ {
+			pos := i*rep + j
+			ones += int(cw[pos/8] >> (7 - uint(pos%8)) & 1)
+		}
+		if ones*2 > rep {
+			secret[i/8] |= 1 << (7 - uint(i%8))
+		}
+	}
+	return secret
+}
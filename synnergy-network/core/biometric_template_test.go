@@ -0,0 +1,63 @@
+package core
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"testing"
+)
+
+func TestBioTemplateToleratesNoise(t *testing.T) {
+	sample := make([]byte, BioSampleSize(DefaultBioRepetition))
+	rand.Read(sample)
+
+	tpl, key, err := GenerateBioTemplate(sample, 0)
+	if err != nil {
+		t.Fatalf("enrol: %v", err)
+	}
+	if bytes.Contains(tpl.Helper, sample) {
+		t.Fatalf("helper data leaks raw sample")
+	}
+
+	// flip one bit in every repetition block
+	noisy := append([]byte(nil), sample...)
+	for i := 0; i < BioSecretBits; i++ {
+		pos := i * DefaultBioRepetition
+		noisy[pos/8] ^= 1 << (7 - uint(pos%8))
+	}
+	got, err := tpl.Reproduce(noisy)
+	if err != nil {
+		t.Fatalf("reproduce noisy sample: %v", err)
+	}
+	if !bytes.Equal(got, key) {
+		t.Fatalf("derived key differs from enrolment key")
+	}
+
+	other := make([]byte, len(sample))
+	rand.Read(other)
+	if _, err := tpl.Reproduce(other); !errors.Is(err, ErrBioMismatch) {
+		t.Fatalf("expected mismatch for foreign sample, got %v", err)
+	}
+}
+
+func TestBioTemplateRejectsShortSample(t *testing.T) {
+	if _, _, err := GenerateBioTemplate(make([]byte, 8), 0); err == nil {
+		t.Fatalf("expected error for short sample")
+	}
+}
+
+func TestBiometricsAuthVerify(t *testing.T) {
+	b := NewBiometricsAuth()
+	sample := make([]byte, BioSampleSize(DefaultBioRepetition))
+	rand.Read(sample)
+	if err := b.Enroll("alice", sample); err != nil {
+		t.Fatalf("enroll: %v", err)
+	}
+	if !b.Verify("alice", sample) {
+		t.Fatalf("verify failed for enrolled sample")
+	}
+	b.Delete("alice")
+	if b.Verify("alice", sample) {
+		t.Fatalf("verify succeeded after delete")
+	}
+}
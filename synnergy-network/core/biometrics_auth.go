@@ -1,53 +1,103 @@
 package core
 
 import (
-	"crypto/sha256"
-	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"sync"
 )
 
-// BiometricsAuth manages hashed biometric templates for addresses. It
-// provides simple enrollment and verification helpers that could be
-// extended to integrate with the ledger or external authentication
-// services. All templates are stored in memory for the prototype.
+// BiometricsAuth manages protected biometric templates for addresses. Raw
+// samples are never retained: enrolment keeps only the fuzzy-extractor
+// helper data and key hash (see biometric_template.go). Templates live in
+// memory and, when a ledger is attached, under "bio:template:<addr>".
 type BiometricsAuth struct {
 	mu    sync.RWMutex
-	store map[string]string // address -> hex encoded hash
+	led   *Ledger
+	store map[string]*BioTemplate // address -> protected template
 }
 
-// NewBiometricsAuth initialises an empty authenticator.
+// NewBiometricsAuth initialises an empty in-memory authenticator.
 func NewBiometricsAuth() *BiometricsAuth {
-	return &BiometricsAuth{store: make(map[string]string)}
+	return &BiometricsAuth{store: make(map[string]*BioTemplate)}
 }
 
-// Enroll registers a new biometric template for an address. The
-// provided data is hashed using SHA-256 before being stored.
+// NewLedgerBiometricsAuth initialises an authenticator that persists
+// templates in ledger state.
+func NewLedgerBiometricsAuth(led *Ledger) *BiometricsAuth {
+	b := NewBiometricsAuth()
+	b.led = led
+	return b
+}
+
+func bioTemplateKey(address string) []byte { return []byte("bio:template:" + address) }
+
+// Enroll registers a new biometric template for an address. The sample is
+// discarded once the helper data has been derived.
 func (b *BiometricsAuth) Enroll(address string, data []byte) error {
+	_, err := b.EnrollKey(address, data)
+	return err
+}
+
+// EnrollKey enrols data and returns the stable key bound to the template.
+func (b *BiometricsAuth) EnrollKey(address string, data []byte) ([]byte, error) {
 	if address == "" || len(data) == 0 {
-		return errors.New("invalid enrollment parameters")
+		return nil, errors.New("invalid enrollment parameters")
+	}
+	t, key, err := GenerateBioTemplate(data, 0)
+	if err != nil {
+		return nil, err
+	}
+	if b.led != nil {
+		raw, _ := json.Marshal(t)
+		if err := b.led.SetState(bioTemplateKey(address), raw); err != nil {
+			return nil, err
+		}
 	}
-	h := sha256.Sum256(data)
 	b.mu.Lock()
-	b.store[address] = hex.EncodeToString(h[:])
+	b.store[address] = t
 	b.mu.Unlock()
-	return nil
+	return key, nil
 }
 
 // Verify checks that the provided biometric data matches the stored
 // template for the given address. It returns true on success.
 func (b *BiometricsAuth) Verify(address string, data []byte) bool {
+	_, err := b.DeriveKey(address, data)
+	return err == nil
+}
+
+// DeriveKey reproduces the enrolment key from a fresh sample.
+func (b *BiometricsAuth) DeriveKey(address string, data []byte) ([]byte, error) {
 	if address == "" || len(data) == 0 {
-		return false
+		return nil, ErrBioMismatch
 	}
-	h := sha256.Sum256(data)
+	t, ok := b.Template(address)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return t.Reproduce(data)
+}
+
+// Template returns the protected template enrolled for address.
+func (b *BiometricsAuth) Template(address string) (*BioTemplate, bool) {
 	b.mu.RLock()
-	stored, ok := b.store[address]
+	t, ok := b.store[address]
 	b.mu.RUnlock()
-	if !ok {
-		return false
+	if ok || b.led == nil {
+		return t, ok
+	}
+	raw, err := b.led.GetState(bioTemplateKey(address))
+	if err != nil || len(raw) == 0 {
+		return nil, false
 	}
-	return stored == hex.EncodeToString(h[:])
+	t = new(BioTemplate)
+	if err := json.Unmarshal(raw, t); err != nil {
+		return nil, false
+	}
+	b.mu.Lock()
+	b.store[address] = t
+	b.mu.Unlock()
+	return t, true
 }
 
 // Delete removes a biometric template for an address if present.
@@ -55,4 +105,7 @@ func (b *BiometricsAuth) Delete(address string) {
 	b.mu.Lock()
 	delete(b.store, address)
 	b.mu.Unlock()
+	if b.led != nil {
+		_ = b.led.DeleteState(bioTemplateKey(address))
+	}
 }
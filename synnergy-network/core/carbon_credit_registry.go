@@ -0,0 +1,342 @@
+//go:build tokens
+// +build tokens
+
+package core
+
+// carbon_credit_registry.go – SYN-CO2 credits backed by attested offsets.
+//
+// Offset records accepted by the GreenTechEngine (signed by a meter oracle)
+// can be tokenised once: MintFromOffset mints one credit per whole kg CO2e
+// to the offset owner and marks the record so it no longer counts towards
+// the owner's green certificate. Credits are an ordinary registered token
+// and therefore trade on the AMM like any other TokenID. Retire burns
+// credits for good and issues a retirement certificate. The registry keeps
+// running totals that Reconcile checks against the offset records and the
+// token supply to detect double counting.
+//
+// Ledger layout:
+//   ccs:minted:<offset id>  -> CarbonOffsetIssuance
+//   ccs:retire:<id>         -> RetirementCertificate
+//   ccs:registry            -> CarbonRegistry
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CarbonCreditKg is the amount of CO2e one credit represents.
+const CarbonCreditKg = 1.0
+
+var (
+	ErrOffsetTokenised  = errors.New("offset record already tokenised")
+	ErrOffsetUnattested = errors.New("offset record not attested by a meter oracle")
+)
+
+// CarbonOffsetIssuance records credits minted against one offset record.
+type CarbonOffsetIssuance struct {
+	OffsetID string    `json:"offset_id"`
+	Owner    Address   `json:"owner"`
+	Oracle   string    `json:"oracle"`
+	OffsetKg float64   `json:"offset_kg"`
+	Credits  uint64    `json:"credits"`
+	Minted   time.Time `json:"minted"`
+}
+
+// RetirementCertificate proves credits were permanently burned.
+type RetirementCertificate struct {
+	ID          string    `json:"id"`
+	Holder      Address   `json:"holder"`
+	Amount      uint64    `json:"amount"`
+	Beneficiary string    `json:"beneficiary,omitempty"`
+	Reason      string    `json:"reason,omitempty"`
+	Retired     time.Time `json:"retired"`
+}
+
+// CarbonRegistry holds running totals of credit issuance and retirement.
+type CarbonRegistry struct {
+	MintedFromOffsets  uint64 `json:"minted_from_offsets"`
+	MintedFromProjects uint64 `json:"minted_from_projects"`
+	Retired            uint64 `json:"retired"`
+}
+
+// Outstanding returns credits issued and not yet retired.
+func (r CarbonRegistry) Outstanding() uint64 {
+	minted := r.MintedFromOffsets + r.MintedFromProjects
+	if r.Retired > minted {
+		return 0
+	}
+	return minted - r.Retired
+}
+
+// CarbonReconciliation is the result of Reconcile.
+type CarbonReconciliation struct {
+	Registry         CarbonRegistry `json:"registry"`
+	OffsetKg         float64        `json:"offset_kg"`
+	TokenisedKg      float64        `json:"tokenised_kg"`
+	IssuedFromOffset uint64         `json:"issued_from_offsets"`
+	RetiredRecorded  uint64         `json:"retired_recorded"`
+	TokenSupply      uint64         `json:"token_supply"`
+	Consistent       bool           `json:"consistent"`
+	Issues           []string       `json:"issues,omitempty"`
+}
+
+// MintableOffset is an offset record that has not been tokenised yet.
+type MintableOffset struct {
+	ID string `json:"id"`
+	OffsetRecord
+}
+
+var carbonRegistryKey = []byte("ccs:registry")
+
+func carbonRetireKey(id string) []byte { return []byte("ccs:retire:" + id) }
+
+// ensureCarbonToken registers the SYN-CO2 token if no other component has.
+func ensureCarbonToken() {
+	if _, ok := GetToken(CarbonCreditTokenID); ok {
+		return
+	}
+	_, _ = Factory{}.Create(Metadata{Name: "Synnergy Carbon Credit", Symbol: "SYN-CO2", Standard: StdSYN200}, nil)
+}
+
+func (e *CarbonEngine) registry() CarbonRegistry {
+	var r CarbonRegistry
+	if b, err := e.ledger.GetState(carbonRegistryKey); err == nil && len(b) > 0 {
+		_ = json.Unmarshal(b, &r)
+	}
+	return r
+}
+
+func (e *CarbonEngine) putRegistry(r CarbonRegistry) error {
+	b, _ := json.Marshal(r)
+	return e.ledger.SetState(carbonRegistryKey, b)
+}
+
+// Registry returns the issuance and retirement totals.
+func (e *CarbonEngine) Registry() CarbonRegistry {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.registry()
+}
+
+// MintableOffsets lists attested offset records of owner that have not been
+// tokenised. A zero owner lists all of them.
+func (e *CarbonEngine) MintableOffsets(owner Address) ([]MintableOffset, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	it := e.ledger.PrefixIterator([]byte("offset:"))
+	var out []MintableOffset
+	for it.Next() {
+		id := it.Key()[len("offset:"):]
+		var rec OffsetRecord
+		if err := json.Unmarshal(it.Value(), &rec); err != nil || rec.Oracle == "" {
+			continue
+		}
+		if owner != AddressZero && rec.Validator != owner {
+			continue
+		}
+		if ok, _ := e.ledger.HasState(offsetMintedKey(id)); ok {
+			continue
+		}
+		out = append(out, MintableOffset{ID: hex.EncodeToString(id), OffsetRecord: rec})
+	}
+	return out, it.Error()
+}
+
+// MintFromOffset mints credits to the owner of the attested offset record
+// offsetID (hex). Each record can be minted once.
+func (e *CarbonEngine) MintFromOffset(offsetID string) (*CarbonOffsetIssuance, error) {
+	id, err := hex.DecodeString(strings.TrimPrefix(offsetID, "0x"))
+	if err != nil || len(id) == 0 {
+		return nil, fmt.Errorf("invalid offset id %q", offsetID)
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	raw, err := e.ledger.GetState(append([]byte("offset:"), id...))
+	if err != nil || len(raw) == 0 {
+		return nil, fmt.Errorf("%w: offset %s", ErrNotFound, offsetID)
+	}
+	var rec OffsetRecord
+	if err := json.Unmarshal(raw, &rec); err != nil {
+		return nil, err
+	}
+	if rec.Oracle == "" {
+		return nil, ErrOffsetUnattested
+	}
+	if ok, _ := e.ledger.HasState(offsetMintedKey(id)); ok {
+		return nil, ErrOffsetTokenised
+	}
+	credits := uint64(math.Floor(rec.OffsetKg / CarbonCreditKg))
+	if credits == 0 {
+		return nil, errors.New("offset too small for one credit")
+	}
+	ensureCarbonToken()
+	tok, ok := GetToken(e.tokenID)
+	if !ok {
+		return nil, fmt.Errorf("carbon credit token not found")
+	}
+	iss := &CarbonOffsetIssuance{
+		OffsetID: hex.EncodeToString(id),
+		Owner:    rec.Validator,
+		Oracle:   rec.Oracle,
+		OffsetKg: rec.OffsetKg,
+		Credits:  credits,
+		Minted:   time.Now().UTC(),
+	}
+	b, _ := json.Marshal(iss)
+	if err := e.ledger.SetState(offsetMintedKey(id), b); err != nil {
+		return nil, err
+	}
+	if err := tok.Mint(rec.Validator, credits); err != nil {
+		_ = e.ledger.DeleteState(offsetMintedKey(id))
+		return nil, err
+	}
+	reg := e.registry()
+	reg.MintedFromOffsets += credits
+	if err := e.putRegistry(reg); err != nil {
+		return nil, err
+	}
+	return iss, nil
+}
+
+// Retire permanently burns amount credits held by holder and returns the
+// retirement certificate.
+func (e *CarbonEngine) Retire(holder Address, amount uint64, beneficiary, reason string) (*RetirementCertificate, error) {
+	if amount == 0 {
+		return nil, errors.New("amount > 0")
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	tok, ok := GetToken(e.tokenID)
+	if !ok {
+		return nil, fmt.Errorf("carbon credit token not found")
+	}
+	if err := tok.Burn(holder, amount); err != nil {
+		return nil, err
+	}
+	cert := &RetirementCertificate{
+		ID:          uuid.New().String(),
+		Holder:      holder,
+		Amount:      amount,
+		Beneficiary: beneficiary,
+		Reason:      reason,
+		Retired:     time.Now().UTC(),
+	}
+	b, _ := json.Marshal(cert)
+	if err := e.ledger.SetState(carbonRetireKey(cert.ID), b); err != nil {
+		return nil, err
+	}
+	reg := e.registry()
+	reg.Retired += amount
+	if err := e.putRegistry(reg); err != nil {
+		return nil, err
+	}
+	Broadcast("carbon:retired", b)
+	return cert, nil
+}
+
+// Retirement returns a retirement certificate by id.
+func (e *CarbonEngine) Retirement(id string) (*RetirementCertificate, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	b, err := e.ledger.GetState(carbonRetireKey(id))
+	if err != nil || len(b) == 0 {
+		return nil, ErrNotFound
+	}
+	var c RetirementCertificate
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// Retirements lists retirement certificates, optionally filtered by holder.
+func (e *CarbonEngine) Retirements(holder Address) ([]RetirementCertificate, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	it := e.ledger.PrefixIterator([]byte("ccs:retire:"))
+	var out []RetirementCertificate
+	for it.Next() {
+		var c RetirementCertificate
+		if err := json.Unmarshal(it.Value(), &c); err != nil {
+			continue
+		}
+		if holder == AddressZero || c.Holder == holder {
+			out = append(out, c)
+		}
+	}
+	return out, it.Error()
+}
+
+// Reconcile cross-checks the registry against the offset records, the
+// issuance and retirement records and the token supply.
+func (e *CarbonEngine) Reconcile() (*CarbonReconciliation, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	rep := &CarbonReconciliation{Registry: e.registry()}
+	issue := func(format string, args ...interface{}) {
+		rep.Issues = append(rep.Issues, fmt.Sprintf(format, args...))
+	}
+
+	offsets := make(map[string]OffsetRecord)
+	it := e.ledger.PrefixIterator([]byte("offset:"))
+	for it.Next() {
+		var rec OffsetRecord
+		if err := json.Unmarshal(it.Value(), &rec); err != nil || rec.Oracle == "" {
+			continue
+		}
+		offsets[hex.EncodeToString(it.Key()[len("offset:"):])] = rec
+		rep.OffsetKg += rec.OffsetKg
+	}
+
+	it = e.ledger.PrefixIterator([]byte("ccs:minted:"))
+	for it.Next() {
+		var iss CarbonOffsetIssuance
+		if err := json.Unmarshal(it.Value(), &iss); err != nil {
+			issue("unreadable issuance %s", it.Key())
+			continue
+		}
+		rec, ok := offsets[iss.OffsetID]
+		switch {
+		case !ok:
+			issue("issuance %s has no attested offset record", iss.OffsetID)
+		case rec.Validator != iss.Owner:
+			issue("issuance %s owner differs from offset owner", iss.OffsetID)
+		case float64(iss.Credits)*CarbonCreditKg > rec.OffsetKg:
+			issue("issuance %s mints %d credits for %.3f kg", iss.OffsetID, iss.Credits, rec.OffsetKg)
+		}
+		rep.TokenisedKg += iss.OffsetKg
+		rep.IssuedFromOffset += iss.Credits
+	}
+	if rep.IssuedFromOffset != rep.Registry.MintedFromOffsets {
+		issue("registry offset mints %d != issuance records %d", rep.Registry.MintedFromOffsets, rep.IssuedFromOffset)
+	}
+	if rep.TokenisedKg > rep.OffsetKg {
+		issue("tokenised %.3f kg exceeds attested offsets %.3f kg", rep.TokenisedKg, rep.OffsetKg)
+	}
+
+	it = e.ledger.PrefixIterator([]byte("ccs:retire:"))
+	for it.Next() {
+		var c RetirementCertificate
+		if err := json.Unmarshal(it.Value(), &c); err == nil {
+			rep.RetiredRecorded += c.Amount
+		}
+	}
+	if rep.RetiredRecorded != rep.Registry.Retired {
+		issue("registry retired %d != retirement certificates %d", rep.Registry.Retired, rep.RetiredRecorded)
+	}
+	if tok, ok := GetToken(e.tokenID); ok {
+		rep.TokenSupply = tok.Meta().TotalSupply
+		if rep.TokenSupply != rep.Registry.Outstanding() {
+			issue("token supply %d != outstanding credits %d", rep.TokenSupply, rep.Registry.Outstanding())
+		}
+	}
+	rep.Consistent = len(rep.Issues) == 0
+	return rep, it.Error()
+}
@@ -33,7 +33,8 @@ type CarbonProject struct {
 
 // CarbonEngine manages carbon credit issuance and retirement. Projects are
 // stored on the ledger under the "ccs:proj:" prefix. Credits are minted using
-// the built‑in SYN-CO2 token.
+// the built‑in SYN-CO2 token; offset-backed issuance, retirement certificates
+// and reconciliation live in carbon_credit_registry.go.
 type CarbonEngine struct {
 	ledger  StateRW
 	mu      sync.RWMutex
@@ -52,6 +53,7 @@ const CarbonCreditTokenID TokenID = TokenID(0x53000000 | uint32(StdSYN200)<<8)
 // InitCarbonEngine initialises the singleton engine.
 func InitCarbonEngine(led StateRW) {
 	carbonOnce.Do(func() {
+		ensureCarbonToken()
 		carbon = &CarbonEngine{ledger: led, tokenID: CarbonCreditTokenID}
 		if b, err := led.GetState([]byte("ccs:nextID")); err == nil && len(b) > 0 {
 			_ = json.Unmarshal(b, &carbon.nextID)
@@ -121,19 +123,19 @@ func (e *CarbonEngine) IssueCredits(id uint64, to Address, amount uint64) error
 	if !ok {
 		return fmt.Errorf("carbon credit token not found")
 	}
-	return tok.Mint(to, amount)
+	if err := tok.Mint(to, amount); err != nil {
+		return err
+	}
+	reg := e.registry()
+	reg.MintedFromProjects += amount
+	return e.putRegistry(reg)
 }
 
-// RetireCredits burns SYN‑CO2 tokens from the holder's balance.
+// RetireCredits burns SYN‑CO2 tokens from the holder's balance. See Retire
+// for the variant returning a retirement certificate.
 func (e *CarbonEngine) RetireCredits(holder Address, amount uint64) error {
-	if amount == 0 {
-		return errors.New("amount > 0")
-	}
-	tok, ok := GetToken(e.tokenID)
-	if !ok {
-		return fmt.Errorf("carbon credit token not found")
-	}
-	return tok.Burn(holder, amount)
+	_, err := e.Retire(holder, amount, "", "")
+	return err
 }
 
 // AddVerification records a verification entry for the specified project.
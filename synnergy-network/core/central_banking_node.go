@@ -93,7 +93,9 @@ func (cb *CentralBankingNode) IssueDigitalCurrency(addr Address, amount uint64)
 	cb.mu.Lock()
 	defer cb.mu.Unlock()
 	amt := new(big.Int).SetUint64(amount)
-	cb.ledger.MintBig(addr[:], amt)
+	if err := cb.ledger.MintBig(addr[:], amt); err != nil {
+		return err
+	}
 	logrus.Infof("issued %d units to %s", amount, addr.Short())
 	return nil
 }
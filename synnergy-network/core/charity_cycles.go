@@ -0,0 +1,296 @@
+package core
+
+// charity_cycles.go – automated cycle management for the CharityPool.
+//
+// Tick drives two schedules. Once per day the pool pays out half of its
+// balance (the charity share of fees collected by TxDistributor) to the
+// winners of the last finalised cycle and the internal charity wallet. When
+// a 90-day cycle has ended the pool draws that cycle's winners: up to five
+// per category, sampled without replacement with probability proportional to
+// votes. The draw is seeded from the randomness beacon so it can be replayed
+// by anyone with VerifyCharityCycleReport.
+//
+// Every payout and draw is recorded in a CharityCycleReport:
+//   charity:report:<cycle>   -> CharityCycleReport
+//   charity:retained         -> pool balance left after the last payout
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+const charityWinnersPerCategory = 5
+
+var charityRetainedKey = []byte("charity:retained")
+
+// ErrCharityReportMismatch is returned when a cycle report does not replay.
+var ErrCharityReportMismatch = errors.New("charity cycle report does not verify")
+
+// CharityWinner is one charity drawn for a cycle. Draw is the random value
+// that selected it.
+type CharityWinner struct {
+	Addr     Address         `json:"addr"`
+	Category CharityCategory `json:"cat"`
+	Votes    uint32          `json:"votes"`
+	Draw     uint64          `json:"draw"`
+}
+
+// CharityPayout records one daily distribution.
+type CharityPayout struct {
+	Time        time.Time `json:"time"`
+	WinnerCycle uint64    `json:"winner_cycle"`
+	Collected   uint64    `json:"collected"`
+	PerCharity  uint64    `json:"per_charity"`
+	Internal    uint64    `json:"internal"`
+	Disbursed   uint64    `json:"disbursed"`
+	Recipients  []Address `json:"recipients"`
+}
+
+// CharityCycleReport summarises a cycle: fees collected and paid out during
+// it and, once it has ended, the verifiable winner draw.
+type CharityCycleReport struct {
+	Cycle       uint64                `json:"cycle"`
+	Start       time.Time             `json:"start"`
+	End         time.Time             `json:"end"`
+	Collected   uint64                `json:"collected"`
+	Disbursed   uint64                `json:"disbursed"`
+	Payouts     []CharityPayout       `json:"payouts,omitempty"`
+	Beacon      *BeaconOutput         `json:"beacon,omitempty"`
+	Seed        Hash                  `json:"seed"`
+	Candidates  []CharityRegistration `json:"candidates,omitempty"`
+	Winners     []CharityWinner       `json:"winners,omitempty"`
+	Finalised   bool                  `json:"finalised"`
+	FinalisedAt time.Time             `json:"finalised_at,omitempty"`
+}
+
+type charityReportReader interface {
+	GetState(key []byte) ([]byte, error)
+	PrefixIterator(prefix []byte) StateIterator
+}
+
+func charityReportKey(cycle uint64) []byte {
+	return []byte(fmt.Sprintf("charity:report:%d", cycle))
+}
+
+// CharitySeed derives the draw seed of cycle from a beacon value.
+func CharitySeed(cycle uint64, beacon Hash) Hash {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], cycle)
+	h := sha256.New()
+	h.Write([]byte("synnergy-charity:"))
+	h.Write(buf[:])
+	h.Write(beacon[:])
+	var out Hash
+	copy(out[:], h.Sum(nil))
+	return out
+}
+
+// SelectCharityWinners draws up to five winners per category from
+// candidates. Each draw picks a charity with probability proportional to its
+// votes; once no remaining candidate has votes the rest are drawn uniformly.
+// The result depends only on seed and the candidate set.
+func SelectCharityWinners(seed Hash, candidates []CharityRegistration) []CharityWinner {
+	byCat := make(map[CharityCategory][]CharityRegistration)
+	for _, c := range candidates {
+		byCat[c.Category] = append(byCat[c.Category], c)
+	}
+	cats := make([]int, 0, len(byCat))
+	for c := range byCat {
+		cats = append(cats, int(c))
+	}
+	sort.Ints(cats)
+
+	var winners []CharityWinner
+	for _, c := range cats {
+		pool := byCat[CharityCategory(c)]
+		sort.Slice(pool, func(i, j int) bool { return pool[i].Addr.Hex() < pool[j].Addr.Hex() })
+		for k := 0; k < charityWinnersPerCategory && len(pool) > 0; k++ {
+			draw := charityDraw(seed, CharityCategory(c), k)
+			var total uint64
+			for _, r := range pool {
+				total += uint64(r.VoteCount)
+			}
+			idx := 0
+			if total == 0 {
+				idx = int(draw % uint64(len(pool)))
+			} else {
+				target := draw % total
+				for i, r := range pool {
+					if target < uint64(r.VoteCount) {
+						idx = i
+						break
+					}
+					target -= uint64(r.VoteCount)
+				}
+			}
+			r := pool[idx]
+			winners = append(winners, CharityWinner{Addr: r.Addr, Category: r.Category, Votes: r.VoteCount, Draw: draw})
+			pool = append(pool[:idx:idx], pool[idx+1:]...)
+		}
+	}
+	return winners
+}
+
+func charityDraw(seed Hash, cat CharityCategory, k int) uint64 {
+	h := sha256.Sum256(append(seed[:], byte(cat), byte(k)))
+	return binary.BigEndian.Uint64(h[:8])
+}
+
+// VerifyCharityCycleReport replays the winner draw of rep. When beacon is
+// non-nil the recorded beacon output is also checked against the chain.
+func VerifyCharityCycleReport(rep *CharityCycleReport, beacon RandomnessBeacon) error {
+	if !rep.Finalised {
+		return fmt.Errorf("cycle %d not finalised", rep.Cycle)
+	}
+	var value Hash
+	if rep.Beacon != nil {
+		if BeaconValue(rep.Beacon.Round, rep.Beacon.Source) != rep.Beacon.Value {
+			return ErrBeaconMismatch
+		}
+		if beacon != nil {
+			if err := beacon.Verify(*rep.Beacon); err != nil {
+				return err
+			}
+		}
+		value = rep.Beacon.Value
+	}
+	if CharitySeed(rep.Cycle, value) != rep.Seed {
+		return fmt.Errorf("%w: seed", ErrCharityReportMismatch)
+	}
+	want := SelectCharityWinners(rep.Seed, rep.Candidates)
+	if len(want) != len(rep.Winners) {
+		return fmt.Errorf("%w: winner count", ErrCharityReportMismatch)
+	}
+	for i := range want {
+		if want[i] != rep.Winners[i] {
+			return fmt.Errorf("%w: winner %d", ErrCharityReportMismatch, i)
+		}
+	}
+	return nil
+}
+
+// GetCharityCycleReport loads the report of cycle from st.
+func GetCharityCycleReport(st charityReportReader, cycle uint64) (*CharityCycleReport, error) {
+	raw, err := st.GetState(charityReportKey(cycle))
+	if err != nil || len(raw) == 0 {
+		return nil, ErrNotFound
+	}
+	var rep CharityCycleReport
+	if err := json.Unmarshal(raw, &rep); err != nil {
+		return nil, err
+	}
+	return &rep, nil
+}
+
+// ListCharityCycleReports returns every stored report ordered by cycle.
+func ListCharityCycleReports(st charityReportReader) ([]CharityCycleReport, error) {
+	it := st.PrefixIterator([]byte("charity:report:"))
+	var out []CharityCycleReport
+	for it.Next() {
+		var rep CharityCycleReport
+		if err := json.Unmarshal(it.Value(), &rep); err != nil {
+			return nil, err
+		}
+		out = append(out, rep)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Cycle < out[j].Cycle })
+	return out, nil
+}
+
+//---------------------------------------------------------------------
+// CharityPool integration
+//---------------------------------------------------------------------
+
+// SetBeacon configures the randomness source used for winner draws. Without
+// a beacon the seed depends on the cycle number only.
+func (cp *CharityPool) SetBeacon(b RandomnessBeacon) {
+	cp.mu.Lock()
+	cp.beacon = b
+	cp.mu.Unlock()
+}
+
+// StartCycles calls Tick every interval until ctx is cancelled.
+func (cp *CharityPool) StartCycles(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	go func() {
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-t.C:
+				cp.Tick(now.UTC())
+			}
+		}
+	}()
+}
+
+// CycleReport returns the report of cycle.
+func (cp *CharityPool) CycleReport(cycle uint64) (*CharityCycleReport, error) {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	return GetCharityCycleReport(cp.led, cycle)
+}
+
+// CycleReports returns all cycle reports ordered by cycle.
+func (cp *CharityPool) CycleReports() ([]CharityCycleReport, error) {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	return ListCharityCycleReports(cp.led)
+}
+
+func (cp *CharityPool) loadReport(cycle uint64) *CharityCycleReport {
+	if rep, err := GetCharityCycleReport(cp.led, cycle); err == nil {
+		return rep
+	}
+	return &CharityCycleReport{
+		Cycle: cycle,
+		Start: cp.genesis.Add(time.Duration(cycle) * cycleDuration),
+		End:   cp.cycleEnd(cycle),
+	}
+}
+
+func (cp *CharityPool) saveReport(rep *CharityCycleReport) []byte {
+	raw := mustJSON(rep)
+	cp.led.SetState(charityReportKey(rep.Cycle), raw)
+	return raw
+}
+
+func (cp *CharityPool) isFinalised(cycle uint64) bool {
+	rep, err := GetCharityCycleReport(cp.led, cycle)
+	return err == nil && rep.Finalised
+}
+
+func (cp *CharityPool) retained() uint64 {
+	raw, _ := cp.led.GetState(charityRetainedKey)
+	if len(raw) != 8 {
+		return 0
+	}
+	return binary.BigEndian.Uint64(raw)
+}
+
+func (cp *CharityPool) setRetained(v uint64) {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], v)
+	cp.led.SetState(charityRetainedKey, buf[:])
+}
+
+// payoutWinners returns the winners paid during cycle: those drawn for the
+// previous cycle, or a list recorded for the cycle itself.
+func (cp *CharityPool) payoutWinners(cycle uint64) (uint64, []Address) {
+	if cycle > 0 {
+		if w := cp.winnerList(cycle - 1); len(w) > 0 {
+			return cycle - 1, w
+		}
+	}
+	return cycle, cp.winnerList(cycle)
+}
@@ -8,11 +8,12 @@ package core
 //   burned. Funds accumulate in `CharityPoolAccount`.
 // * **Daily distribution**: 24 h cron (triggered via `Tick`) pays out 50 % of the
 //   contract balance – 50 % split equally across the **30 winning charities** of
-//   the current 90-day cycle (max 5 per category) and 50 % sent to an internal
-//   charity wallet (`InternalCharityAccount`).
+//   the last finalised 90-day cycle (max 5 per category) and 50 % sent to an
+//   internal charity wallet (`InternalCharityAccount`).
 // * **Cycle**: 90-day windows numbered from genesis timestamp; charities must
 //   **register 30 d before cycle end**; community (ID-token holders) vote in the
-//   last 15 d; top 5 per category win.
+//   last 15 d; 5 per category are drawn by a vote-weighted, beacon-seeded
+//   lottery once the cycle ends (see charity_cycles.go).
 //
 // Dependencies: ledger (state+balance), authority (ID token check), security
 // (sig verify for charity wallet keys). All times in UTC.
@@ -71,6 +72,8 @@ const (
 	registrationCutoff = 30 * 24 * time.Hour
 	votingWindow       = 15 * 24 * time.Hour
 	dailyPayout        = 24 * time.Hour
+
+	maxCategoryRegistrations = 25
 )
 
 var (
@@ -135,9 +138,9 @@ func (cp *CharityPool) Register(addr Address, name string, cat CharityCategory)
 	if exists, _ := cp.led.HasState(key); exists {
 		return errors.New("already registered")
 	}
-	// Ensure category cap <=5
+	// Ensure category cap
 	count := cp.countCategoryRegistrations(cycle, cat)
-	if count >= 5 {
+	if count >= maxCategoryRegistrations {
 		return errors.New("category full for cycle")
 	}
 	r := CharityRegistration{Addr: addr, Name: name, Category: cat, Cycle: cycle}
@@ -198,65 +201,94 @@ func (cp *CharityPool) Tick(ts time.Time) {
 	cp.mu.Lock()
 	defer cp.mu.Unlock()
 
+	// Draw winners of the cycle that just ended before paying out so the
+	// first payout of a cycle already goes to the new winners.
+	if cycle := cp.currentCycle(ts); cycle > 0 && !cp.isFinalised(cycle-1) {
+		cp.finaliseCycle(cycle-1, ts)
+	}
+
 	// Daily payout?
 	if ts.Unix()-cp.lastDaily >= int64(dailyPayout.Seconds()) {
-		cp.distributeDaily()
+		cp.distributeDaily(ts)
 		cp.lastDaily = ts.Unix()
 	}
-
-	// At cycle end +1 block, compute winners & persist for next 90d payouts.
-	if cp.isCycleBoundary(ts) {
-		cp.finaliseCycle(cp.currentCycle(ts))
-	}
 }
 
 //---------------------------------------------------------------------
 // Internal helpers
 //---------------------------------------------------------------------
 
-func (cp *CharityPool) distributeDaily() {
+func (cp *CharityPool) distributeDaily(ts time.Time) {
 	bal := cp.led.BalanceOf(CharityPoolAccount)
 	if bal == 0 {
 		return
 	}
 	half := bal / 2
 
-	cycle := cp.currentCycle(time.Now().UTC())
-	winners := cp.winnerList(cycle)
-	per := uint64(0)
+	cycle := cp.currentCycle(ts)
+	winCycle, winners := cp.payoutWinners(cycle)
+	p := CharityPayout{Time: ts.UTC(), WinnerCycle: winCycle}
+	if prev := cp.retained(); bal > prev {
+		p.Collected = bal - prev
+	}
 	if len(winners) > 0 {
-		per = half / uint64(len(winners))
+		p.PerCharity = half / uint64(len(winners))
 		for _, w := range winners {
-			_ = cp.led.Transfer(CharityPoolAccount, w, per)
+			if err := cp.led.Transfer(CharityPoolAccount, w, p.PerCharity); err == nil {
+				p.Recipients = append(p.Recipients, w)
+				p.Disbursed += p.PerCharity
+			}
 		}
 	}
-	_ = cp.led.Transfer(CharityPoolAccount, InternalCharityAccount, half)
-	cp.logger.Printf("charity daily payout half=%d perCharity=%d to %d winners", half, per, len(winners))
+	if err := cp.led.Transfer(CharityPoolAccount, InternalCharityAccount, half); err == nil {
+		p.Internal = half
+		p.Disbursed += half
+	}
+	cp.setRetained(bal - p.Disbursed)
+
+	rep := cp.loadReport(cycle)
+	rep.Payouts = append(rep.Payouts, p)
+	rep.Collected += p.Collected
+	rep.Disbursed += p.Disbursed
+	cp.saveReport(rep)
+	cp.logger.Printf("charity daily payout half=%d perCharity=%d to %d winners", half, p.PerCharity, len(winners))
 }
 
-func (cp *CharityPool) finaliseCycle(cycle uint64) {
-	// Determine top 5 per category by votes.
-	cats := make(map[CharityCategory][]CharityRegistration)
+func (cp *CharityPool) finaliseCycle(cycle uint64, ts time.Time) {
+	var cands []CharityRegistration
 	iter := cp.led.PrefixIterator([]byte(fmt.Sprintf("charity:reg:%d:", cycle)))
 	for iter.Next() {
 		var r CharityRegistration
-		_ = json.Unmarshal(iter.Value(), &r)
-		cats[r.Category] = append(cats[r.Category], r)
-	}
-	var winners []Address
-	for cat, list := range cats {
-		sort.Slice(list, func(i, j int) bool { return list[i].VoteCount > list[j].VoteCount })
-		n := 5
-		if len(list) < 5 {
-			n = len(list)
+		if err := json.Unmarshal(iter.Value(), &r); err == nil {
+			cands = append(cands, r)
 		}
-		for i := 0; i < n; i++ {
-			winners = append(winners, list[i].Addr)
+	}
+	sort.Slice(cands, func(i, j int) bool { return cands[i].Addr.Hex() < cands[j].Addr.Hex() })
+
+	rep := cp.loadReport(cycle)
+	var value Hash
+	if cp.beacon != nil {
+		if out, err := cp.beacon.Latest(); err == nil {
+			rep.Beacon = &out
+			value = out.Value
+		} else {
+			cp.logger.Printf("cycle %d beacon unavailable: %v", cycle, err)
 		}
-		cp.logger.Printf("cycle %d cat %s winners: %d", cycle, cat, n)
+	}
+	rep.Seed = CharitySeed(cycle, value)
+	rep.Candidates = cands
+	rep.Winners = SelectCharityWinners(rep.Seed, cands)
+	rep.Finalised = true
+	rep.FinalisedAt = ts.UTC()
+
+	winners := make([]Address, len(rep.Winners))
+	for i, w := range rep.Winners {
+		winners[i] = w.Addr
 	}
 	// Persist winners set for daily payouts (next cycle period)
 	cp.led.SetState(winKey(cycle), mustJSON(winners))
+	Broadcast("charity:cycle", cp.saveReport(rep))
+	cp.logger.Printf("cycle %d finalised: %d winners from %d candidates", cycle, len(winners), len(cands))
 }
 
 func (cp *CharityPool) winnerList(cycle uint64) []Address {
@@ -322,9 +354,6 @@ func (cp *CharityPool) currentCycle(t time.Time) uint64 {
 func (cp *CharityPool) cycleEnd(cycle uint64) time.Time {
 	return cp.genesis.Add(time.Duration(cycle+1) * cycleDuration)
 }
-func (cp *CharityPool) isCycleBoundary(ts time.Time) bool {
-	return ts.UTC().Truncate(cycleDuration) == ts.UTC()
-}
 
 //---------------------------------------------------------------------
 // Ledger key helpers
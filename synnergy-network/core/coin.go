@@ -1,7 +1,6 @@
 package core
 
 import (
-	"encoding/json"
 	"fmt"
 	"math/big"
 
@@ -24,26 +23,9 @@ const GenesisAlloc uint64 = 10_000_000
 // It initializes totalMinted by summing existing balances, so that
 // any genesis allocation applied in consensus is included.
 func NewCoin(lg *Ledger) (*Coin, error) {
-	// take a snapshot of the ledger to read TokenBalances atomically
-	snap, err := lg.Snapshot()
-	if err != nil {
-		return nil, fmt.Errorf("coin: failed to snapshot ledger: %w", err)
-	}
-
-	// extract the TokenBalances field
-	var state struct {
-		TokenBalances map[string]uint64 `json:"token_balances"`
-	}
-	if err := json.Unmarshal(snap, &state); err != nil {
-		return nil, fmt.Errorf("coin: failed to unmarshal snapshot: %w", err)
-	}
-
-	var total uint64
-	for _, bal := range state.TokenBalances {
-		total += bal
-	}
+	total := lg.AssetSupply(Code)
 	if total > MaxSupply {
-		return nil, fmt.Errorf("coin: snapshot total %d exceeds MaxSupply %d", total, MaxSupply)
+		return nil, fmt.Errorf("coin: ledger total %d exceeds MaxSupply %d", total, MaxSupply)
 	}
 
 	c := &Coin{
@@ -67,9 +49,10 @@ func (c *Coin) Mint(to []byte, amount uint64) error {
 		return fmt.Errorf("coin: minting %d would exceed cap %d", amount, MaxSupply)
 	}
 
-	var addr Address
-	copy(addr[:], to) // convert []byte to Address type
-
+	addr, err := coinAddress(to)
+	if err != nil {
+		return err
+	}
 	if err := c.ledger.MintToken(addr, Code, amount); err != nil {
 		return fmt.Errorf("coin: ledger mint error: %w", err)
 	}
@@ -87,12 +70,14 @@ func (c *Coin) Transfer(from, to []byte, amount uint64) error {
 		return fmt.Errorf("coin: transfer amount must be positive")
 	}
 
-	var src, dst Address
-	if len(from) != len(src) || len(to) != len(dst) {
-		return fmt.Errorf("coin: invalid address length")
+	src, err := coinAddress(from)
+	if err != nil {
+		return err
+	}
+	dst, err := coinAddress(to)
+	if err != nil {
+		return err
 	}
-	copy(src[:], from)
-	copy(dst[:], to)
 
 	if err := c.ledger.Transfer(src, dst, amount); err != nil {
 		return fmt.Errorf("coin: ledger transfer error: %w", err)
@@ -109,11 +94,10 @@ func (c *Coin) Burn(from []byte, amount uint64) error {
 		return fmt.Errorf("coin: burn amount must be positive")
 	}
 
-	var addr Address
-	if len(from) != len(addr) {
-		return fmt.Errorf("coin: invalid address length")
+	addr, err := coinAddress(from)
+	if err != nil {
+		return err
 	}
-	copy(addr[:], from)
 
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -139,20 +123,31 @@ func (c *Coin) TotalSupply() uint64 {
 
 // BalanceOf returns the Synthron token balance for the given address.
 func (c *Coin) BalanceOf(address []byte) uint64 {
-	var addr Address
-	if len(address) >= len(addr) {
-		copy(addr[:], address[:len(addr)])
-	} else {
-		copy(addr[len(addr)-len(address):], address)
+	addr, err := coinAddress(address)
+	if err != nil {
+		return 0
 	}
 	return c.ledger.BalanceOf(addr)
 }
 
-// BlockRewardAt returns the block reward at the given height applying the
-// consensus halving schedule defined in consensus.go.
+// coinAddress converts raw address bytes to an Address. Shorter inputs are
+// right-aligned, as a big-endian number would be, so Mint, Transfer, Burn
+// and BalanceOf all name the same account for the same bytes.
+func coinAddress(b []byte) (Address, error) {
+	var addr Address
+	if len(b) > len(addr) {
+		return addr, fmt.Errorf("coin: invalid address length")
+	}
+	copy(addr[len(addr)-len(b):], b)
+	return addr, nil
+}
+
+// BlockRewardAt returns the block reward at the given height under the
+// emission policy of the current ledger, or DefaultEmissionSchedule when no
+// ledger is open.
 func BlockRewardAt(height uint64) *big.Int {
-	halves := height / RewardHalvingPeriod
-	r := new(big.Int).Set(InitialReward)
-	r.Rsh(r, uint(halves))
-	return r
+	if led := CurrentLedger(); led != nil {
+		return led.EmissionPolicy().RewardAt(height)
+	}
+	return DefaultEmissionSchedule().RewardAt(height)
 }
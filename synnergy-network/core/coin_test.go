@@ -25,7 +25,7 @@ func TestBlockRewardAt(t *testing.T) {
 
 // TestCoinMintAndBurn ensures minting and burning adjust supply correctly.
 func TestCoinMintAndBurn(t *testing.T) {
-	ldg := &Ledger{TokenBalances: make(map[string]uint64)}
+	ldg := &Ledger{Balances: make(map[BalanceKey]uint64)}
 	c, err := NewCoin(ldg)
 	if err != nil {
 		t.Fatalf("NewCoin failed: %v", err)
@@ -46,11 +46,17 @@ func TestCoinMintAndBurn(t *testing.T) {
 	if got := c.TotalSupply(); got != 60 {
 		t.Fatalf("TotalSupply=%d want 60", got)
 	}
+	if bal := c.BalanceOf(addr); bal != 60 {
+		t.Fatalf("Balance=%d want 60", bal)
+	}
+	if err := c.Mint(make([]byte, len(Address{})+1), 1); err == nil {
+		t.Fatalf("expected error for an over-long address")
+	}
 }
 
 // TestCoinMintExceedsCap verifies minting beyond MaxSupply is rejected.
 func TestCoinMintExceedsCap(t *testing.T) {
-	ldg := &Ledger{TokenBalances: make(map[string]uint64)}
+	ldg := &Ledger{Balances: make(map[BalanceKey]uint64)}
 	c, err := NewCoin(ldg)
 	if err != nil {
 		t.Fatalf("NewCoin failed: %v", err)
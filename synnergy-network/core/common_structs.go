@@ -9,6 +9,7 @@ package core
 
 import (
 	"context"
+	"crypto/ecdsa"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
@@ -90,7 +91,7 @@ type AuthorityNode struct {
 
 type AuthoritySet struct {
 	logger  *log.Logger
-	led     StateRW
+	led     authorityState
 	mu      sync.RWMutex
 	members map[Address]struct{}
 }
@@ -112,6 +113,7 @@ type CharityPool struct {
 	logger *log.Logger
 	led    StateRW
 	vote   Voter
+	beacon RandomnessBeacon
 
 	genesis   time.Time
 	lastDaily int64
@@ -152,6 +154,16 @@ type SynnergyConsensus struct {
 
 	weights   ConsensusWeights
 	weightCfg WeightConfig
+
+	validators *ValidatorManager // optional proposer schedule
+
+	pipeline SubBlockPipelineConfig // see consensus_pipeline.go
+	votes    chan posVote           // outgoing PoS votes awaiting batch gossip
+
+	ordering   FairOrderingConfig // see fair_ordering.go
+	receipts   *ReceiptBook
+	receiptKey *ecdsa.PrivateKey
+	held       [][]byte // txs awaiting receipts
 }
 
 // ConsensusWeights reflects the active weighting across PoW, PoS and PoH.
@@ -179,6 +191,7 @@ type BlockHeader struct {
 	PoWHash   []byte
 	Nonce     uint64
 	MinerPk   []byte
+	LogsBloom Bloom `rlp:"optional"` // addresses and topics of Body.Logs
 }
 
 type SubBlockHeader struct {
@@ -189,9 +202,15 @@ type SubBlockHeader struct {
 	Sig       []byte
 }
 
-type SubBlockBody struct{ Transactions [][]byte }
+type SubBlockBody struct {
+	Transactions [][]byte
+	Receipts     []TxReceipt `json:",omitempty"` // fair-ordering evidence
+}
 
-type BlockBody struct{ SubHeaders []SubBlockHeader }
+type BlockBody struct {
+	SubHeaders []SubBlockHeader
+	Logs       []Log `json:",omitempty"` // logs emitted by the block's transactions, in order
+}
 
 type SubBlock struct {
 	Header SubBlockHeader
@@ -274,12 +293,14 @@ type UsageRecord struct {
 	EnergyKWh float64 `json:"energy_kwh"`
 	CarbonKg  float64 `json:"carbon_kg"`
 	Timestamp int64   `json:"ts"`
+	Oracle    string  `json:"oracle"`
 }
 
 type OffsetRecord struct {
 	Validator Address `json:"validator"`
 	OffsetKg  float64 `json:"offset_kg"`
 	Timestamp int64   `json:"ts"`
+	Oracle    string  `json:"oracle"`
 }
 
 type Certificate string
@@ -299,6 +320,8 @@ type CertificateInfo struct {
 	Address Address     `json:"address"`
 	Score   float64     `json:"score"`
 	Cert    Certificate `json:"cert"`
+	Expires int64       `json:"expires,omitempty"`
+	Expired bool        `json:"expired,omitempty"`
 }
 
 type GreenTechEngine struct {
@@ -317,6 +340,8 @@ type LedgerConfig struct {
 	SnapshotInterval int
 	ArchivePath      string // optional gzip file to archive pruned blocks
 	PruneInterval    int    // number of recent blocks to retain in memory/WAL
+	StateHistoryPath string // archive mode: versioned state journal backing StateAt
+	Emission         *EmissionSchedule // genesis emission schedule; default when nil
 }
 
 // UTXO represents a spendable output identified by (TxID, Index).
@@ -368,9 +393,12 @@ type Ledger struct {
 	blockIndex       map[Hash]*Block
 	State            map[string][]byte
 	UTXO             map[string]UTXO
+	utxoIdx          *utxoIndex // owner index, spent journal, balance cache
 	TxPool           map[string]*Transaction
+	poolIdx          map[poolSlot]string // pool ID by sender and nonce, see AddToPool
 	Contracts        map[string]Contract
-	TokenBalances    map[string]uint64
+	Balances         map[BalanceKey]uint64        // see balances.go
+	Supply           map[LedgerAsset]SupplyTotals // see supply.go
 	logs             []*Log
 	walFile          *os.File
 	snapshotPath     string
@@ -384,6 +412,11 @@ type Ledger struct {
 	NodeLocations    map[NodeID]Location
 	pendingSubBlocks []SubBlock // <- store sub-blocks here
 	holoData         map[Hash][]byte
+	archive          *stateArchive // nil unless running in archive mode
+	stateDiffs       *stateDiffLog // per-transaction diffs of recent blocks
+	walWatchers      map[chan struct{}]struct{} // WAL replication streams
+	fenced           bool                       // a newer primary has taken over
+	views            readViews                  // snapshots served to API reads
 }
 
 //---------------------------------------------------------------------
@@ -403,7 +436,15 @@ type Pool struct {
 	resB    uint64
 	totalLP uint64
 	feeBps  uint16
+	kind    PoolType // pricing invariant (see stable_swap.go)
+	amp     uint64   // stable-swap amplification
+	locked  bool     // flash loan outstanding
 	mu      sync.RWMutex
+
+	// protocol fee switch accounting (see amm_fee_switch.go)
+	protoFeeA uint64
+	protoFeeB uint64
+	protoLP   uint64
 }
 
 type AMM struct {
@@ -525,14 +566,18 @@ type ShardCoordinator struct {
 //---------------------------------------------------------------------
 
 type Sidechain struct {
-	ID         SidechainID `json:"id"`
-	Name       string      `json:"name"`
-	Threshold  uint8       `json:"threshold"`
-	Validators [][]byte    `json:"validators"`
-	LastHeight uint64      `json:"last_height"`
-	LastRoot   [32]byte    `json:"last_state_root"`
-	Paused     bool        `json:"paused"`
-	Registered int64       `json:"registered_unix"`
+	ID              SidechainID `json:"id"`
+	Name            string      `json:"name"`
+	Threshold       uint8       `json:"threshold"`
+	Validators      [][]byte    `json:"validators"`
+	LastHeight      uint64      `json:"last_height"`
+	LastRoot        [32]byte    `json:"last_state_root"`
+	Paused          bool        `json:"paused"`
+	Registered      int64       `json:"registered_unix"`
+	CheckpointEvery uint64      `json:"checkpoint_every"`
+	LastCheckpoint  uint64      `json:"last_checkpoint"`
+	FinalizedHeight uint64      `json:"finalized_height"`
+	OpenDisputes    int         `json:"open_disputes"`
 }
 
 type SidechainHeader struct {
@@ -587,7 +632,7 @@ type SignedState struct {
 }
 
 type ChannelEngine struct {
-	led StateRW
+	led channelState
 	mu  sync.RWMutex
 }
 
@@ -637,6 +682,9 @@ const (
 	// multiple authority co‑signatures and refunds the original sender minus
 	// a protocol‑defined fee.
 	TxReversal
+	// TxMulticall executes an ordered list of calls and transfers
+	// atomically; see multicall.go.
+	TxMulticall
 )
 
 
@@ -651,6 +699,8 @@ type Transaction struct {
 	Nonce            uint64            `json:"nonce"`
 	Timestamp        int64             `json:"timestamp"`
 	Payload          []byte            `json:"payload,omitempty"`
+	DestinationTag   uint64            `json:"dest_tag,omitempty"`
+	Memo             string            `json:"memo,omitempty"`
 	Private          bool              `json:"private,omitempty"`
 	EncryptedPayload []byte            `json:"encrypted_payload,omitempty"`
 	AuthSigs         [][]byte          `json:"auth_sigs,omitempty"`
@@ -662,6 +712,7 @@ type Transaction struct {
 	StateChanges     map[string][]byte `json:"state,omitempty"`
 	Contract         *Contract         `json:"contract,omitempty"`
 	TokenTransfers   []TokenTransfer   `json:"token_transfers,omitempty"`
+	Calls            []Call            `json:"calls,omitempty"` // TxMulticall
 }
 
 // HashTx computes and caches a simple SHA-256 hash of the transaction
@@ -889,7 +940,7 @@ func (s *Stack) Push(v *big.Int) {
 // the stack is empty, mirroring EVM-style stack semantics.
 func (s *Stack) Pop() *big.Int {
 	if len(s.data) == 0 {
-		panic("stack underflow")
+		panic(ErrStackUnderflow)
 	}
 	idx := len(s.data) - 1
 	val := s.data[idx]
@@ -927,14 +978,25 @@ type TxPool struct {
 	mu        sync.RWMutex
 	ledger    ReadOnlyState
 	gasCalc   GasCalculator
-	net       *Broadcaster
+	net       txBroadcaster
 	lookup    map[Hash]*Transaction
 	queue     []*Transaction
 	authority *AuthoritySet
+	screener  *TxScreener // optional admission risk screening
+	bundles   []TxBundle  // priority-lane bundles awaiting PickTxs
+}
+
+// TxBundle is an ordered, all-or-nothing group of transactions placed in a
+// priority lane ahead of the public pool. Lane 0 is filled first.
+type TxBundle struct {
+	ID   string         `json:"id"`
+	Lane uint8          `json:"lane"`
+	Txs  []*Transaction `json:"txs"`
 }
 
+// ReadOnlyState is the account view the mem-pool checks nonces and
+// balances against; *Ledger satisfies it.
 type ReadOnlyState interface {
-	Get(key string) ([]byte, error)
 	BalanceOf(addr Address) uint64
 	NonceOf(addr Address) uint64
 }
@@ -8,6 +8,7 @@ package core
 //     preserving cryptographic proofs of compliance (hash commitments remain).
 //   • KYC validation (`ValidateKYC(doc)`): verifies signature chain from issuer
 //     (government / bank) and stores a blinded commitment in ledger state.
+//     Issuers are governed by the registry in kyc_issuers.go.
 //   • FraudTracking (`RecordFraudSignal`, `RiskScore(addr)`): cooperates with AI
 //     engine to flag suspicious addresses.
 //
@@ -22,6 +23,7 @@ import (
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/binary"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -42,18 +44,26 @@ import (
 
 type ComplianceEngine struct {
 	mu      sync.RWMutex
-	ledger  StateRW
+	ledger  complianceState
 	allowed map[[33]byte]struct{} // issuer pubkey compressed
 	fraud   map[Address]int
 	auditNS []byte
 }
 
+// complianceState is the ledger state KYC commitments and the issuer
+// registry are kept in.
+type complianceState interface {
+	GetState(key []byte) ([]byte, error)
+	SetState(key, value []byte) error
+	PrefixIterator(prefix []byte) StateIterator
+}
+
 var (
 	compOnce sync.Once
 	comp     *ComplianceEngine
 )
 
-func InitCompliance(led StateRW, trustedIssuers [][]byte) {
+func InitCompliance(led complianceState, trustedIssuers [][]byte) {
 	compOnce.Do(func() {
 		iss := make(map[[33]byte]struct{})
 		for _, pk := range trustedIssuers {
@@ -81,22 +91,19 @@ func (c *ComplianceEngine) ValidateKYC(doc *KYCDocument) error {
 		return errors.New("nil doc")
 	}
 
-	// issuer trust check
-	var issuerKey [33]byte
-	copy(issuerKey[:], doc.IssuerPK)
-	if _, ok := c.allowed[issuerKey]; !ok {
-		return errors.New("untrusted issuer")
+	if c.ledger == nil {
+		return errors.New("compliance engine has no ledger")
 	}
 
-	// prepare message
-	raw, _ := json.Marshal(struct {
-		Address     Address
-		CountryCode string
-		IDHash      [32]byte
-		IssuedAt    int64
-	}{doc.Address, doc.CountryCode, doc.IDHash, doc.IssuedAt})
+	// issuer trust check
+	if err := c.checkIssuer(doc.IssuerPK, doc.CountryCode, doc.IssuedAt); err != nil {
+		return err
+	}
 
-	hash := sha256.Sum256(raw)
+	hash := KYCCredentialID(doc)
+	if c.IsCredentialRevoked(hash) {
+		return ErrCredentialRevoked
+	}
 
 	// recover public key
 	pk, err := secp256k1.ParsePubKey(doc.IssuerPK)
@@ -115,11 +122,22 @@ func (c *ComplianceEngine) ValidateKYC(doc *KYCDocument) error {
 		return errors.New("invalid signature")
 	}
 
-	// store blinded commitment
+	// store blinded commitment and credential reference
 	key := kycKey(doc.Address)
 	val := sha256.Sum256(doc.Signature)
-	c.ledger.SetState(key, val[:])
-	return nil
+	if err := c.ledger.SetState(key, val[:]); err != nil {
+		return err
+	}
+	cred := KYCCredential{
+		ID:          hash,
+		Address:     doc.Address,
+		Issuer:      hex.EncodeToString(doc.IssuerPK),
+		CountryCode: doc.CountryCode,
+		IssuedAt:    doc.IssuedAt,
+		Validated:   time.Now().UTC(),
+	}
+	raw, _ := json.Marshal(cred)
+	return c.ledger.SetState(kycCredKey(doc.Address), raw)
 }
 
 func decodeSig(sig []byte) (r, s *big.Int, err error) {
@@ -32,15 +32,12 @@ import (
 // ---------------------------------------------------------------------
 // Consensus constants
 // ---------------------------------------------------------------------
-var InitialReward *big.Int
 
-func init() {
-	var ok bool
-	InitialReward, ok = new(big.Int).SetString("102400000000000000000", 10)
-	if !ok {
-		panic("invalid InitialReward value")
-	}
-}
+// InitialReward and RewardHalvingPeriod define DefaultEmissionSchedule;
+// the schedule in force is the ledger's EmissionPolicy. Over its lifetime
+// the default schedule emits just under 400 million coins, within
+// MaxSupply.
+var InitialReward = big.NewInt(1_000)
 
 const (
 	MaxSubBlocksPerBlock = 1_000
@@ -154,26 +151,10 @@ func (sc *SynnergyConsensus) ValidateTx(tx *Transaction) error {
 	return sc.pool.ValidateTx(tx)
 }
 
-// Sub‑block proposer loop (PoH + immediate PoS self‑sign)
+// Sub‑block proposer (PoH + immediate PoS self‑sign); the proposer loop
+// lives in consensus_pipeline.go.
 //---------------------------------------------------------------------
 
-func (sc *SynnergyConsensus) subBlockLoop(ctx context.Context) {
-	ticker := time.NewTicker(SubBlockInterval)
-	defer ticker.Stop()
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case <-ticker.C:
-			sb, err := sc.ProposeSubBlock()
-			if err != nil {
-				continue // nothing to propose
-			}
-			_ = sc.p2p.Broadcast("subblock", sb.Header) // body gossiped via tx replication already
-		}
-	}
-}
-
 // ProposeSubBlock selects transactions, computes a PoH commitment and signs the
 // resulting sub‑block header. It returns the fully assembled SubBlock ready for
 // gossiping to peers and appending to the ledger.
@@ -183,14 +164,22 @@ func (sc *SynnergyConsensus) ProposeSubBlock() (*SubBlock, error) {
 		return nil, errors.New("consensus not initialised")
 	}
 
-	rawTxs := sc.pool.Pick(MaxTxPerSubBlock)
+	if err := sc.checkProposer(); err != nil {
+		return nil, err
+	}
+
+	held := sc.takeHeld()
+	rawTxs := append(held, sc.pool.Pick(MaxTxPerSubBlock-len(held))...)
 	if len(rawTxs) == 0 {
 		return nil, errors.New("no txs")
 	}
 
-	// Filter and validate picked transactions.
+	// Filter and validate picked transactions; those that would take the
+	// sub-block over the block gas limit wait for the next proposal.
 	validTxs := make([][]byte, 0, len(rawTxs))
-	for _, b := range rawTxs {
+	var gas uint64
+	gasLimit := CurrentResourceLimits().BlockGasLimit
+	for i, b := range rawTxs {
 		var tx Transaction
 		if err := json.Unmarshal(b, &tx); err != nil {
 			if sc.logger != nil {
@@ -204,12 +193,28 @@ func (sc *SynnergyConsensus) ProposeSubBlock() (*SubBlock, error) {
 			}
 			continue
 		}
+		if gasLimit > 0 && gas+tx.GasLimit > gasLimit {
+			sc.mu.Lock()
+			sc.held = append(sc.held, rawTxs[i:]...)
+			sc.mu.Unlock()
+			break
+		}
+		gas += tx.GasLimit
 		validTxs = append(validTxs, b)
 	}
 	if len(validTxs) == 0 {
 		return nil, errors.New("no valid txs")
 	}
 
+	// Fair ordering: median validator receipt time instead of pool order.
+	var receipts []TxReceipt
+	if cfg := sc.fairConfig(); cfg.Mode == OrderingFair {
+		validTxs, receipts = sc.fairOrder(validTxs, cfg)
+		if len(validTxs) == 0 {
+			return nil, errors.New("txs awaiting receipts")
+		}
+	}
+
 	header := SubBlockHeader{
 		Height:    sc.nextSubHeightAtomic(),
 		Timestamp: time.Now().UnixMilli(),
@@ -221,6 +226,9 @@ func (sc *SynnergyConsensus) ProposeSubBlock() (*SubBlock, error) {
 	for _, tx := range validTxs {
 		h.Write(tx)
 	}
+	for _, r := range receipts {
+		h.Write(r.Sig)
+	}
 	ts := make([]byte, 8)
 	binary.LittleEndian.PutUint64(ts, uint64(header.Timestamp))
 	h.Write(ts)
@@ -232,7 +240,7 @@ func (sc *SynnergyConsensus) ProposeSubBlock() (*SubBlock, error) {
 	}
 	header.Sig = sig
 
-	sb := &SubBlock{Header: header, Body: SubBlockBody{Transactions: validTxs}}
+	sb := &SubBlock{Header: header, Body: SubBlockBody{Transactions: validTxs, Receipts: receipts}}
 	if err := sc.ledger.AppendSubBlock(sb); err != nil {
 		return nil, err
 	}
@@ -247,14 +255,13 @@ func (sc *SynnergyConsensus) ProposeSubBlock() (*SubBlock, error) {
 //---------------------------------------------------------------------
 
 func (sc *SynnergyConsensus) handlePoSVote(msg InboundMsg) {
-	var vote struct {
-		HeaderHash []byte
-		Sig        []byte
-	}
-	if err := msg.Decode(&vote); err != nil {
+	votes, err := decodePoSVotes(msg.Payload)
+	if err != nil {
 		return
 	}
-	sc.ledger.RecordPoSVote(vote.HeaderHash, vote.Sig)
+	for _, v := range votes {
+		sc.ledger.RecordPoSVote(v.HeaderHash, v.Sig)
+	}
 }
 
 func (m *InboundMsg) Decode(v interface{}) error {
@@ -298,6 +305,12 @@ func (sc *SynnergyConsensus) collectSubHeaders() []SubBlockHeader {
 		if err := sc.ValidatePoS(&sb); err != nil {
 			continue
 		}
+		if err := CurrentResourceLimits().CheckSubBlockLimits(&sb); err != nil {
+			if sc.logger != nil {
+				sc.logger.Printf("sub-block #%d over limits: %v", sb.Header.Height, err)
+			}
+			continue
+		}
 		headers = append(headers, sb.Header)
 	}
 
@@ -393,40 +406,26 @@ func (sc *SynnergyConsensus) SealMainBlockPOW(headers []SubBlockHeader) error {
 	sc.logger.Printf("block #%d sealed (nonce %d)", bh.Height, nonce)
 	sc.recordBlkTime(bh.Timestamp)
 	sc.retargetDifficulty()
-	sc.DistributeRewards(blk)
+	if err := sc.DistributeRewards(blk); err != nil {
+		return fmt.Errorf("block %d rewards: %w", bh.Height, err)
+	}
 	_ = sc.p2p.Broadcast("block", blk)
 	return nil
 }
 
 //---------------------------------------------------------------------
-// Reward distribution 30/30/40
+// Reward distribution (see emission.go; 30/30/40 by default)
 //---------------------------------------------------------------------
 
-func (sc *SynnergyConsensus) DistributeRewards(blk *Block) {
-	halves := blk.Header.Height / RewardHalvingPeriod
-
-	reward := new(big.Int).Rsh(InitialReward, uint(halves))
-
-	minerR := new(big.Int).Mul(reward, big.NewInt(30))
-	minerR.Div(minerR, big.NewInt(100))
-
-	stakerR := new(big.Int).Mul(reward, big.NewInt(30))
-	stakerR.Div(stakerR, big.NewInt(100))
-
-	loanR := new(big.Int).Sub(reward, minerR)
-	loanR.Sub(loanR, stakerR)
-
-	sc.ledger.MintBig(blk.Header.MinerPk, minerR)
-
-	if len(blk.Body.SubHeaders) > 0 {
-		per := new(big.Int).Div(stakerR, big.NewInt(int64(len(blk.Body.SubHeaders))))
-		for _, sh := range blk.Body.SubHeaders {
-			sc.ledger.MintBig(sh.Validator, per)
-		}
+// DistributeRewards mints the reward of blk as the emission policy in the
+// ledger schedules it and records what was paid.
+func (sc *SynnergyConsensus) DistributeRewards(blk *Block) error {
+	validators := make([][]byte, 0, len(blk.Body.SubHeaders))
+	for _, sh := range blk.Body.SubHeaders {
+		validators = append(validators, sh.Validator)
 	}
-
-	addr := sc.auth.LoanPoolAddress()
-	sc.ledger.MintBig(addr[:], loanR)
+	_, err := sc.ledger.payEmission(blk.Header.Height, blk.Header.MinerPk, validators, sc.auth.LoanPoolAddress())
+	return err
 }
 
 func mustBigInt(s string) *big.Int {
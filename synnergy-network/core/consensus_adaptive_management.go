@@ -59,7 +59,10 @@ func (am *ConsensusAdaptiveManager) ComputeStakeConcentration() float64 {
 	am.mu.Lock()
 	defer am.mu.Unlock()
 	var total, max uint64
-	for _, bal := range am.ledger.TokenBalances {
+	for k, bal := range am.ledger.Balances {
+		if k.Token != Code {
+			continue
+		}
 		total += bal
 		if bal > max {
 			max = bal
@@ -71,9 +74,10 @@ func (am *ConsensusAdaptiveManager) ComputeStakeConcentration() float64 {
 	return float64(max) / float64(total)
 }
 
-// AdjustConsensus recalculates consensus weights based on the latest
-// demand and stake metrics. The updated weights are stored inside the
-// consensus engine and returned to the caller.
+// AdjustConsensus recalculates consensus weights from live network
+// telemetry (see Telemetry). The updated weights are stored inside the
+// consensus engine, appended to the on-chain weight history and returned
+// to the caller.
 func (am *ConsensusAdaptiveManager) AdjustConsensus() (ConsensusWeights, error) {
 	am.mu.Lock()
 	c := am.cons
@@ -81,10 +85,12 @@ func (am *ConsensusAdaptiveManager) AdjustConsensus() (ConsensusWeights, error)
 	if c == nil {
 		return ConsensusWeights{}, fmt.Errorf("consensus not initialised")
 	}
-	d := am.ComputeDemand()
-	s := am.ComputeStakeConcentration()
-	w := c.CalculateWeights(d, s)
-	return w, nil
+	t := am.Telemetry()
+	t.Weights = c.CalculateWeights(t.Demand, t.Stake)
+	if err := am.recordWeights(t); err != nil {
+		return t.Weights, fmt.Errorf("record weights: %w", err)
+	}
+	return t.Weights, nil
 }
 
 // SetWeightConfig proxies through to the consensus engine allowing the
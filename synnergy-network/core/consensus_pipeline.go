@@ -0,0 +1,214 @@
+package core
+
+// consensus_pipeline.go – adaptive sub-block proposing and batched vote gossip.
+//
+// The proposer no longer waits for the 1s SubBlockInterval tick when the
+// pool is under pressure: once the pending transaction count crosses
+// PoolThreshold a sub-block is proposed at the next poll. Header broadcast
+// runs on its own goroutine behind a bounded queue, so the PoH commitment
+// for the next sub-block is computed while the previous header is still
+// being sent. PoS votes are gossiped in batches instead of one message per
+// vote, flushed when a batch fills or after VoteFlush.
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// SubBlockPipelineConfig tunes sub-block proposing and PoS vote gossip.
+type SubBlockPipelineConfig struct {
+	PoolThreshold int           // pending txs that trigger an immediate proposal (<0 = ticker only)
+	PollInterval  time.Duration // how often pool pressure is checked
+	SendDepth     int           // proposed headers queued for broadcast
+	VoteBatch     int           // PoS votes per gossip message
+	VoteFlush     time.Duration // longest a partial vote batch waits
+}
+
+// DefaultSubBlockPipeline is used until SetSubBlockPipeline is called.
+var DefaultSubBlockPipeline = SubBlockPipelineConfig{
+	PoolThreshold: MaxTxPerSubBlock / 2,
+	PollInterval:  50 * time.Millisecond,
+	SendDepth:     4,
+	VoteBatch:     64,
+	VoteFlush:     100 * time.Millisecond,
+}
+
+// posVote is a PoS endorsement of a sub-block header. Gossip messages on
+// the "posvote" topic carry either one vote or a posVoteBatch.
+type posVote struct {
+	HeaderHash []byte
+	Sig        []byte
+}
+
+type posVoteBatch struct {
+	Votes []posVote
+}
+
+type broadcaster interface {
+	Broadcast(topic string, data interface{}) error
+}
+
+// SetSubBlockPipeline replaces the pipeline configuration. It takes
+// effect the next time the engine is started; zero fields keep their
+// defaults and a negative PoolThreshold disables adaptive proposing.
+func (sc *SynnergyConsensus) SetSubBlockPipeline(cfg SubBlockPipelineConfig) {
+	sc.mu.Lock()
+	sc.pipeline = cfg
+	sc.mu.Unlock()
+}
+
+func (sc *SynnergyConsensus) pipelineConfig() SubBlockPipelineConfig {
+	sc.mu.Lock()
+	cfg := sc.pipeline
+	sc.mu.Unlock()
+	d := DefaultSubBlockPipeline
+	if cfg.PoolThreshold == 0 {
+		cfg.PoolThreshold = d.PoolThreshold
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = d.PollInterval
+	}
+	if cfg.SendDepth <= 0 {
+		cfg.SendDepth = d.SendDepth
+	}
+	if cfg.VoteBatch <= 0 {
+		cfg.VoteBatch = d.VoteBatch
+	}
+	if cfg.VoteFlush <= 0 {
+		cfg.VoteFlush = d.VoteFlush
+	}
+	return cfg
+}
+
+// subBlockLoop proposes a sub-block every SubBlockInterval, or as soon as
+// the pool holds PoolThreshold pending transactions, and hands the header
+// to the sender goroutine.
+func (sc *SynnergyConsensus) subBlockLoop(ctx context.Context) {
+	cfg := sc.pipelineConfig()
+	ticker := time.NewTicker(SubBlockInterval)
+	defer ticker.Stop()
+
+	var poll <-chan time.Time
+	counter, ok := sc.pool.(interface{ Len() int })
+	if ok && cfg.PoolThreshold > 0 {
+		pt := time.NewTicker(cfg.PollInterval)
+		defer pt.Stop()
+		poll = pt.C
+	}
+
+	sendQ := make(chan SubBlockHeader, cfg.SendDepth)
+	defer close(sendQ)
+	go func() {
+		b, _ := sc.p2p.(broadcaster)
+		for h := range sendQ {
+			if b != nil {
+				_ = b.Broadcast("subblock", h) // body gossiped via tx replication already
+			}
+		}
+	}()
+
+	propose := func() {
+		sb, err := sc.ProposeSubBlock()
+		if err != nil {
+			return // nothing to propose
+		}
+		select {
+		case sendQ <- sb.Header:
+		case <-ctx.Done():
+		}
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			propose()
+		case <-poll:
+			if counter.Len() >= cfg.PoolThreshold {
+				propose()
+				ticker.Reset(SubBlockInterval)
+			}
+		}
+	}
+}
+
+// GossipPoSVote records a PoS vote locally and queues it for batched
+// gossip to peers. Before the engine is started the vote is sent on its
+// own.
+func (sc *SynnergyConsensus) GossipPoSVote(headerHash, sig []byte) error {
+	if err := sc.ledger.RecordPoSVote(headerHash, sig); err != nil {
+		return err
+	}
+	v := posVote{HeaderHash: headerHash, Sig: sig}
+	sc.mu.Lock()
+	q := sc.votes
+	sc.mu.Unlock()
+	if q != nil {
+		select {
+		case q <- v:
+			return nil
+		default: // queue full: fall through and send directly
+		}
+	}
+	if b, ok := sc.p2p.(broadcaster); ok {
+		return b.Broadcast("posvote", v)
+	}
+	return nil
+}
+
+// voteLoop gossips queued PoS votes in batches.
+func (sc *SynnergyConsensus) voteLoop(ctx context.Context) {
+	cfg := sc.pipelineConfig()
+	q := make(chan posVote, cfg.VoteBatch*4)
+	sc.mu.Lock()
+	sc.votes = q
+	sc.mu.Unlock()
+	defer func() {
+		sc.mu.Lock()
+		sc.votes = nil
+		sc.mu.Unlock()
+	}()
+
+	b, _ := sc.p2p.(broadcaster)
+	batch := make([]posVote, 0, cfg.VoteBatch)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if b != nil {
+			_ = b.Broadcast("posvote", posVoteBatch{Votes: batch})
+		}
+		batch = make([]posVote, 0, cfg.VoteBatch)
+	}
+	timer := time.NewTimer(cfg.VoteFlush)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			flush()
+			return
+		case v := <-q:
+			batch = append(batch, v)
+			if len(batch) >= cfg.VoteBatch {
+				flush()
+			}
+		case <-timer.C:
+			flush()
+			timer.Reset(cfg.VoteFlush)
+		}
+	}
+}
+
+// decodePoSVotes accepts a single vote or a batch.
+func decodePoSVotes(payload []byte) ([]posVote, error) {
+	var batch posVoteBatch
+	if err := json.Unmarshal(payload, &batch); err == nil && len(batch.Votes) > 0 {
+		return batch.Votes, nil
+	}
+	var v posVote
+	if err := json.Unmarshal(payload, &v); err != nil {
+		return nil, err
+	}
+	return []posVote{v}, nil
+}
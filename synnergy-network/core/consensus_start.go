@@ -3,7 +3,8 @@ package core
 import "context"
 
 // Start launches the consensus engine, spinning up proposer and block
-// aggregation loops and wiring the PoS vote subscription. The method is
+// aggregation loops and wiring the PoS vote subscription (and, in fair
+// ordering mode, the transaction receipt subscriptions). The method is
 // idempotent and returns immediately; background routines terminate when the
 // supplied context is cancelled.
 func (sc *SynnergyConsensus) Start(ctx context.Context) {
@@ -25,8 +26,9 @@ func (sc *SynnergyConsensus) Start(ctx context.Context) {
 	sc.cancel = cancel
 	sc.mu.Unlock()
 
-	// Launch sub‑block proposer and block sealing loops.
+	// Launch sub‑block proposer, vote gossip and block sealing loops.
 	go sc.subBlockLoop(ctx)
+	go sc.voteLoop(ctx)
 	go sc.blockLoop(ctx)
 
 	// Subscribe to PoS vote messages if the p2p layer supports it.
@@ -45,6 +47,29 @@ func (sc *SynnergyConsensus) Start(ctx context.Context) {
 				}
 			}
 		}()
+
+		// Fair ordering: stamp gossiped txs and collect peer receipts.
+		if sc.fairConfig().Mode == OrderingFair {
+			txs, unsubTx := subber.Subscribe("tx:new")
+			rcpts, unsubRc := subber.Subscribe("txreceipt")
+			go func() {
+				defer unsubTx()
+				defer unsubRc()
+				for {
+					select {
+					case <-ctx.Done():
+						return
+					case m := <-txs:
+						var tx Transaction
+						if m.Decode(&tx) == nil {
+							_ = sc.ObserveTx(&tx)
+						}
+					case m := <-rcpts:
+						sc.handleTxReceipt(m)
+					}
+				}
+			}()
+		}
 	}
 
 	// Log lifecycle events when a logger is provided.
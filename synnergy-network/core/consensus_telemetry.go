@@ -0,0 +1,213 @@
+package core
+
+// consensus_telemetry.go – live network telemetry for adaptive consensus.
+//
+// The collector samples the transaction pool depth, how full recent blocks
+// are relative to the governance block gas limit and the Gini coefficient
+// of validator stake, feeds them to AdjustConsensus and records every
+// recomputation in ledger state under consensus:weights:<height>, so the
+// weight history is part of the replicated state and survives restarts.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultTelemetryInterval is how often RunTelemetry recomputes weights.
+const DefaultTelemetryInterval = 30 * time.Second
+
+// WeightHistoryLimit bounds the number of weight samples kept in state.
+const WeightHistoryLimit = 1024
+
+const weightHistoryPrefix = "consensus:weights:"
+
+// NetworkTelemetry is one sample of the network signals behind a weight
+// recomputation, together with the weights it produced.
+type NetworkTelemetry struct {
+	Height        uint64           `json:"height"`
+	Timestamp     int64            `json:"timestamp"`
+	PoolDepth     int              `json:"pool_depth"`
+	BlockFullness float64          `json:"block_fullness"`
+	StakeGini     float64          `json:"stake_gini"`
+	Demand        float64          `json:"demand"`
+	Stake         float64          `json:"stake"`
+	Weights       ConsensusWeights `json:"weights"`
+}
+
+// ComputeBlockFullness returns the average share of the block gas limit
+// used by the blocks in the window, capped at 1.
+func (am *ConsensusAdaptiveManager) ComputeBlockFullness() float64 {
+	if blockGasLimit == 0 {
+		return 0
+	}
+	am.ledger.mu.RLock()
+	defer am.ledger.mu.RUnlock()
+	blocks := am.ledger.Blocks
+	if len(blocks) > am.window {
+		blocks = blocks[len(blocks)-am.window:]
+	}
+	if len(blocks) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, b := range blocks {
+		var gas uint64
+		for _, tx := range b.Transactions {
+			gas += tx.GasLimit
+		}
+		f := float64(gas) / float64(blockGasLimit)
+		if f > 1 {
+			f = 1
+		}
+		sum += f
+	}
+	return sum / float64(len(blocks))
+}
+
+// ComputePoolDepth returns the number of pending transactions.
+func (am *ConsensusAdaptiveManager) ComputePoolDepth() int {
+	am.ledger.mu.RLock()
+	defer am.ledger.mu.RUnlock()
+	return len(am.ledger.TxPool)
+}
+
+// ComputeStakeGini returns the Gini coefficient of active validator stake,
+// 0 for a perfectly even distribution and approaching 1 as stake
+// concentrates in one validator. Without a validator set it falls back to
+// token balances.
+func (am *ConsensusAdaptiveManager) ComputeStakeGini() float64 {
+	var stakes []uint64
+	var vm *ValidatorManager
+	if am.cons != nil {
+		am.cons.mu.Lock()
+		vm = am.cons.validators
+		am.cons.mu.Unlock()
+	}
+	if vm != nil {
+		if list, err := vm.List(true); err == nil {
+			for _, v := range list {
+				stakes = append(stakes, v.Stake)
+			}
+		}
+	} else {
+		am.ledger.mu.RLock()
+		for k, bal := range am.ledger.Balances {
+			if k.Token == Code {
+				stakes = append(stakes, bal)
+			}
+		}
+		am.ledger.mu.RUnlock()
+	}
+	return gini(stakes)
+}
+
+func gini(xs []uint64) float64 {
+	n := len(xs)
+	if n < 2 {
+		return 0
+	}
+	sort.Slice(xs, func(i, j int) bool { return xs[i] < xs[j] })
+	var total, weighted float64
+	for i, x := range xs {
+		total += float64(x)
+		weighted += float64(i+1) * float64(x)
+	}
+	if total == 0 {
+		return 0
+	}
+	return 2*weighted/(float64(n)*total) - float64(n+1)/float64(n)
+}
+
+// Telemetry samples the current network signals. Demand is the average
+// number of transactions per block plus the pending backlog spread over
+// the window, scaled up by up to 2x as blocks approach the gas limit;
+// Stake is the validator stake Gini, or the largest balance share when no
+// validators are registered.
+func (am *ConsensusAdaptiveManager) Telemetry() NetworkTelemetry {
+	t := NetworkTelemetry{
+		Height:        am.ledger.LastHeight(),
+		Timestamp:     time.Now().Unix(),
+		PoolDepth:     am.ComputePoolDepth(),
+		BlockFullness: am.ComputeBlockFullness(),
+		StakeGini:     am.ComputeStakeGini(),
+	}
+	t.Demand = (am.ComputeDemand() + float64(t.PoolDepth)/float64(am.window)) * (1 + t.BlockFullness)
+	t.Stake = t.StakeGini
+	if t.Stake == 0 {
+		t.Stake = am.ComputeStakeConcentration()
+	}
+	return t
+}
+
+// recordWeights stores a sample in the weight history and trims the
+// history to WeightHistoryLimit entries.
+func (am *ConsensusAdaptiveManager) recordWeights(t NetworkTelemetry) error {
+	raw, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+	if err := am.ledger.SetState([]byte(fmt.Sprintf("%s%020d", weightHistoryPrefix, t.Height)), raw); err != nil {
+		return err
+	}
+	it := am.ledger.PrefixIterator([]byte(weightHistoryPrefix))
+	var keys []string
+	for it.Next() {
+		keys = append(keys, string(it.Key()))
+	}
+	if len(keys) <= WeightHistoryLimit {
+		return nil
+	}
+	sort.Strings(keys)
+	for _, k := range keys[:len(keys)-WeightHistoryLimit] {
+		if err := am.ledger.DeleteState([]byte(k)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RunTelemetry recomputes the consensus weights from live telemetry every
+// interval until ctx is cancelled.
+func (am *ConsensusAdaptiveManager) RunTelemetry(ctx context.Context, interval time.Duration) error {
+	if interval <= 0 {
+		interval = DefaultTelemetryInterval
+	}
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		if w, err := am.AdjustConsensus(); err != nil {
+			logrus.Warnf("adaptive consensus: %v", err)
+		} else {
+			logrus.Debugf("adaptive consensus: weights pow=%.3f pos=%.3f poh=%.3f", w.PoW, w.PoS, w.PoH)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// WeightHistory returns up to limit recorded weight samples, newest first
+// (0 = all of them).
+func WeightHistory(led *Ledger, limit int) ([]NetworkTelemetry, error) {
+	it := led.PrefixIterator([]byte(weightHistoryPrefix))
+	var out []NetworkTelemetry
+	for it.Next() {
+		var t NetworkTelemetry
+		if err := json.Unmarshal(it.Value(), &t); err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Height > out[j].Height })
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
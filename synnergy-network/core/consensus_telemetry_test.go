@@ -0,0 +1,40 @@
+package core
+
+import (
+	"math"
+	"testing"
+)
+
+func TestStakeGini(t *testing.T) {
+	cases := []struct {
+		stakes []uint64
+		want   float64
+	}{
+		{nil, 0},
+		{[]uint64{100}, 0},
+		{[]uint64{5, 5, 5, 5}, 0},
+		{[]uint64{0, 0, 0, 10}, 0.75},
+		{[]uint64{4, 1, 3, 2}, 0.25},
+	}
+	for _, c := range cases {
+		if got := gini(c.stakes); math.Abs(got-c.want) > 1e-9 {
+			t.Fatalf("gini(%v) = %v, want %v", c.stakes, got, c.want)
+		}
+	}
+}
+
+func TestBlockFullness(t *testing.T) {
+	limit := blockGasLimit
+	defer func() { blockGasLimit = limit }()
+	blockGasLimit = 1000
+
+	led := &Ledger{}
+	for _, gas := range []uint64{250, 750, 5000} {
+		led.Blocks = append(led.Blocks, &Block{Transactions: []*Transaction{{GasLimit: gas}}})
+	}
+	am := NewConsensusAdaptiveManager(led, nil, 10)
+	// the last block overflows the limit and counts as full
+	if got, want := am.ComputeBlockFullness(), (0.25+0.75+1)/3; math.Abs(got-want) > 1e-9 {
+		t.Fatalf("fullness %v, want %v", got, want)
+	}
+}
@@ -183,6 +183,29 @@ func (vm *ValidatorManager) List(activeOnly bool) ([]ValidatorInfo, error) {
 	return out, nil
 }
 
+// Schedulable returns the active validators eligible for block production.
+// Validators whose green certificate marks them as heavy emitters are
+// skipped while the certificate is valid, as are chronic energy-efficiency
+// under-performers.
+func (vm *ValidatorManager) Schedulable() ([]ValidatorInfo, error) {
+	list, err := vm.List(true)
+	if err != nil {
+		return nil, err
+	}
+	g, eff := Green(), EnergyEff()
+	out := list[:0]
+	for _, v := range list {
+		if g != nil && g.ShouldThrottle(v.Addr) {
+			continue
+		}
+		if eff != nil && eff.ShouldThrottle(v.Addr) {
+			continue
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
+
 // IsValidator checks if the address is registered and active.
 func (vm *ValidatorManager) IsValidator(addr Address) bool {
 	raw, err := vm.ledger.GetState(vm.key(addr))
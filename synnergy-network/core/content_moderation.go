@@ -0,0 +1,500 @@
+package core
+
+// content_moderation.go – anti-spam rules for forum threads, comments and
+// polls.
+//
+// Opening a thread or a poll locks a refundable stake in the moderation
+// account. The author can reclaim it once the lock period has passed and the
+// content is still visible. Posting is rate limited per hour according to the
+// author's SYN-REP balance (see governance_reputation_voting.go).
+//
+// Any account may flag content once. When the number of flags reaches the
+// policy threshold the content is hidden and SlashBps of its stake is sent
+// to the charity pool, the remainder is returned to the author. Authority
+// nodes can hide or restore content directly. Every moderation action is
+// appended to an on-chain log.
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Moderated content kinds.
+const (
+	ContentThread  = "thread"
+	ContentComment = "comment"
+	ContentPoll    = "poll"
+)
+
+// Stake states.
+const (
+	StakeLocked   = "locked"
+	StakeRefunded = "refunded"
+	StakeSlashed  = "slashed"
+)
+
+var (
+	// ErrContentHidden is returned when reading content removed by moderation.
+	ErrContentHidden = errors.New("content hidden by moderation")
+	// ErrRateLimited is returned when an author exceeds their posting tier.
+	ErrRateLimited = errors.New("posting rate limit reached")
+)
+
+var contentMu sync.Mutex
+
+// PostingTier sets the hourly posting allowance for accounts holding at
+// least MinReputation SYN-REP. PostsPerHour 0 means unlimited.
+type PostingTier struct {
+	MinReputation uint64 `json:"min_reputation"`
+	PostsPerHour  int    `json:"posts_per_hour"`
+}
+
+// ContentPolicy configures stakes, flagging and rate limits.
+type ContentPolicy struct {
+	ThreadStake   uint64        `json:"thread_stake"`
+	PollStake     uint64        `json:"poll_stake"`
+	StakeLock     time.Duration `json:"stake_lock"`
+	FlagThreshold int           `json:"flag_threshold"`
+	SlashBps      uint64        `json:"slash_bps"`
+	Tiers         []PostingTier `json:"tiers"`
+}
+
+// DefaultContentPolicy is used until authorities configure one.
+func DefaultContentPolicy() ContentPolicy {
+	return ContentPolicy{
+		ThreadStake:   10,
+		PollStake:     25,
+		StakeLock:     7 * 24 * time.Hour,
+		FlagThreshold: 5,
+		SlashBps:      5_000,
+		Tiers: []PostingTier{
+			{MinReputation: 0, PostsPerHour: 2},
+			{MinReputation: 10, PostsPerHour: 10},
+			{MinReputation: 100, PostsPerHour: 60},
+			{MinReputation: 1_000, PostsPerHour: 0},
+		},
+	}
+}
+
+// ContentStake is the refundable deposit backing a thread or poll.
+type ContentStake struct {
+	Kind      string    `json:"kind"`
+	ContentID string    `json:"content_id"`
+	Owner     Address   `json:"owner"`
+	Amount    uint64    `json:"amount"`
+	Locked    time.Time `json:"locked"`
+	Unlocks   time.Time `json:"unlocks"`
+	Status    string    `json:"status"`
+}
+
+// ContentFlag is one community report.
+type ContentFlag struct {
+	Flagger Address   `json:"flagger"`
+	Reason  string    `json:"reason"`
+	Time    time.Time `json:"time"`
+}
+
+// ModerationAction is an entry of the on-chain moderation log.
+type ModerationAction struct {
+	Action    string    `json:"action"`
+	Kind      string    `json:"kind,omitempty"`
+	ContentID string    `json:"content_id,omitempty"`
+	Actor     Address   `json:"actor"`
+	Reason    string    `json:"reason,omitempty"`
+	Amount    uint64    `json:"amount,omitempty"`
+	Time      time.Time `json:"time"`
+}
+
+// ContentGuard applies the content policy on a ledger.
+type ContentGuard struct {
+	led contentState
+}
+
+// contentState is the ledger state and balances a guard works on.
+type contentState interface {
+	GetState(key []byte) ([]byte, error)
+	SetState(key, value []byte) error
+	DeleteState(key []byte) error
+	HasState(key []byte) (bool, error)
+	PrefixIterator(prefix []byte) StateIterator
+	Transfer(from, to Address, amount uint64) error
+}
+
+// NewContentGuard returns a guard storing its state in led.
+func NewContentGuard(led contentState) *ContentGuard { return &ContentGuard{led: led} }
+
+func moderationAccount() Address { return ModuleAddress("moderation") }
+
+func contentPolicyKey() []byte { return []byte("content:policy") }
+func contentStakeKey(kind, id string) []byte {
+	return []byte("content:stake:" + kind + ":" + id)
+}
+func contentFlagPrefix(kind, id string) []byte {
+	return []byte("content:flag:" + kind + ":" + id + ":")
+}
+func contentHiddenKey(kind, id string) []byte {
+	return []byte("content:hidden:" + kind + ":" + id)
+}
+func contentRateKey(a Address) []byte { return []byte("content:rate:" + a.Hex()) }
+func modLogKey(t time.Time) []byte {
+	return []byte(fmt.Sprintf("content:modlog:%020d", t.UnixNano()))
+}
+
+// Policy returns the active content policy.
+func (g *ContentGuard) Policy() ContentPolicy {
+	raw, err := g.led.GetState(contentPolicyKey())
+	if err != nil || len(raw) == 0 {
+		return DefaultContentPolicy()
+	}
+	var p ContentPolicy
+	if err := json.Unmarshal(raw, &p); err != nil {
+		return DefaultContentPolicy()
+	}
+	return p
+}
+
+// SetPolicy replaces the content policy. Only authority nodes may call it.
+func (g *ContentGuard) SetPolicy(caller Address, p ContentPolicy) error {
+	if !isModerator(caller) {
+		return ErrUnauthorized
+	}
+	if p.SlashBps > bpsDenom {
+		return fmt.Errorf("slash_bps above %d", bpsDenom)
+	}
+	if p.FlagThreshold <= 0 {
+		return errors.New("flag threshold must be >0")
+	}
+	contentMu.Lock()
+	defer contentMu.Unlock()
+	raw, _ := json.Marshal(p)
+	if err := g.led.SetState(contentPolicyKey(), raw); err != nil {
+		return err
+	}
+	return g.logAction(ModerationAction{Action: "policy", Actor: caller})
+}
+
+func isModerator(a Address) bool {
+	as := CurrentAuthoritySet()
+	return as != nil && as.IsAuthority(a)
+}
+
+// postsPerHour returns the hourly allowance for a reputation balance, or -1
+// when no tier applies.
+func postsPerHour(p ContentPolicy, rep uint64) int {
+	allowed, best := 0, uint64(0)
+	found := false
+	for _, t := range p.Tiers {
+		if rep >= t.MinReputation && (!found || t.MinReputation >= best) {
+			allowed, best, found = t.PostsPerHour, t.MinReputation, true
+		}
+	}
+	if !found {
+		return -1
+	}
+	return allowed
+}
+
+// checkRate records a post by author or returns ErrRateLimited.
+func (g *ContentGuard) checkRate(p ContentPolicy, author Address, now time.Time) error {
+	rep, err := ReputationOf(author)
+	if err != nil {
+		rep = 0
+	}
+	allowed := postsPerHour(p, rep)
+	if allowed < 0 {
+		return ErrRateLimited
+	}
+	var recent []int64
+	if raw, err := g.led.GetState(contentRateKey(author)); err == nil && len(raw) > 0 {
+		_ = json.Unmarshal(raw, &recent)
+	}
+	cutoff := now.Add(-time.Hour).Unix()
+	kept := recent[:0]
+	for _, ts := range recent {
+		if ts > cutoff {
+			kept = append(kept, ts)
+		}
+	}
+	if allowed > 0 && len(kept) >= allowed {
+		return ErrRateLimited
+	}
+	kept = append(kept, now.Unix())
+	raw, _ := json.Marshal(kept)
+	return g.led.SetState(contentRateKey(author), raw)
+}
+
+// AdmitPost enforces the posting rate of author and, for threads and polls,
+// locks the policy stake against id.
+func (g *ContentGuard) AdmitPost(kind, id string, author Address) error {
+	contentMu.Lock()
+	defer contentMu.Unlock()
+	p := g.Policy()
+	now := time.Now().UTC()
+	if err := g.checkRate(p, author, now); err != nil {
+		return err
+	}
+	var amt uint64
+	switch kind {
+	case ContentThread:
+		amt = p.ThreadStake
+	case ContentPoll:
+		amt = p.PollStake
+	}
+	if amt == 0 {
+		return nil
+	}
+	if err := g.led.Transfer(author, moderationAccount(), amt); err != nil {
+		return fmt.Errorf("stake: %w", err)
+	}
+	s := ContentStake{Kind: kind, ContentID: id, Owner: author, Amount: amt, Locked: now, Unlocks: now.Add(p.StakeLock), Status: StakeLocked}
+	raw, _ := json.Marshal(s)
+	return g.led.SetState(contentStakeKey(kind, id), raw)
+}
+
+// Stake returns the stake backing a piece of content.
+func (g *ContentGuard) Stake(kind, id string) (*ContentStake, error) {
+	raw, err := g.led.GetState(contentStakeKey(kind, id))
+	if err != nil || len(raw) == 0 {
+		return nil, ErrNotFound
+	}
+	var s ContentStake
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// ReleaseStake refunds the stake of visible content once its lock expired.
+func (g *ContentGuard) ReleaseStake(kind, id string, owner Address) error {
+	contentMu.Lock()
+	defer contentMu.Unlock()
+	s, err := g.Stake(kind, id)
+	if err != nil {
+		return err
+	}
+	if s.Owner != owner {
+		return ErrUnauthorized
+	}
+	if s.Status != StakeLocked {
+		return fmt.Errorf("stake already %s", s.Status)
+	}
+	if g.IsHidden(kind, id) {
+		return ErrContentHidden
+	}
+	if time.Now().Before(s.Unlocks) {
+		return fmt.Errorf("stake locked until %s", s.Unlocks.Format(time.RFC3339))
+	}
+	if err := g.led.Transfer(moderationAccount(), owner, s.Amount); err != nil {
+		return err
+	}
+	s.Status = StakeRefunded
+	raw, _ := json.Marshal(s)
+	if err := g.led.SetState(contentStakeKey(kind, id), raw); err != nil {
+		return err
+	}
+	return g.logAction(ModerationAction{Action: "refund", Kind: kind, ContentID: id, Actor: owner, Amount: s.Amount})
+}
+
+// Flag records a report by flagger. Content is hidden and its stake slashed
+// when the policy threshold is reached. It reports whether the content is
+// now hidden.
+func (g *ContentGuard) Flag(kind, id string, owner, flagger Address, reason string) (bool, error) {
+	if flagger == owner {
+		return false, errors.New("cannot flag own content")
+	}
+	contentMu.Lock()
+	defer contentMu.Unlock()
+	if g.IsHidden(kind, id) {
+		return true, nil
+	}
+	key := append(contentFlagPrefix(kind, id), flagger.Hex()...)
+	if ok, _ := g.led.HasState(key); ok {
+		return false, errors.New("already flagged")
+	}
+	f := ContentFlag{Flagger: flagger, Reason: reason, Time: time.Now().UTC()}
+	raw, _ := json.Marshal(f)
+	if err := g.led.SetState(key, raw); err != nil {
+		return false, err
+	}
+	if err := g.logAction(ModerationAction{Action: "flag", Kind: kind, ContentID: id, Actor: flagger, Reason: reason}); err != nil {
+		return false, err
+	}
+	flags, err := g.Flags(kind, id)
+	if err != nil {
+		return false, err
+	}
+	if len(flags) < g.Policy().FlagThreshold {
+		return false, nil
+	}
+	return true, g.hide(kind, id, AddressZero, "flag threshold reached")
+}
+
+// Flags lists the reports filed against a piece of content.
+func (g *ContentGuard) Flags(kind, id string) ([]ContentFlag, error) {
+	it := g.led.PrefixIterator(contentFlagPrefix(kind, id))
+	var out []ContentFlag
+	for it.Next() {
+		var f ContentFlag
+		if err := json.Unmarshal(it.Value(), &f); err != nil {
+			return nil, err
+		}
+		out = append(out, f)
+	}
+	return out, it.Error()
+}
+
+// Hide removes content on behalf of an authority node. The stake is slashed
+// as for community flagging.
+func (g *ContentGuard) Hide(kind, id string, moderator Address, reason string) error {
+	if !isModerator(moderator) {
+		return ErrUnauthorized
+	}
+	contentMu.Lock()
+	defer contentMu.Unlock()
+	if g.IsHidden(kind, id) {
+		return errors.New("content already hidden")
+	}
+	return g.hide(kind, id, moderator, reason)
+}
+
+// Restore makes hidden content visible again. Slashed stake is not
+// returned.
+func (g *ContentGuard) Restore(kind, id string, moderator Address, reason string) error {
+	if !isModerator(moderator) {
+		return ErrUnauthorized
+	}
+	contentMu.Lock()
+	defer contentMu.Unlock()
+	if !g.IsHidden(kind, id) {
+		return errors.New("content not hidden")
+	}
+	if err := g.led.DeleteState(contentHiddenKey(kind, id)); err != nil {
+		return err
+	}
+	return g.logAction(ModerationAction{Action: "restore", Kind: kind, ContentID: id, Actor: moderator, Reason: reason})
+}
+
+func (g *ContentGuard) hide(kind, id string, actor Address, reason string) error {
+	if err := g.led.SetState(contentHiddenKey(kind, id), []byte{1}); err != nil {
+		return err
+	}
+	if err := g.logAction(ModerationAction{Action: "hide", Kind: kind, ContentID: id, Actor: actor, Reason: reason}); err != nil {
+		return err
+	}
+	s, err := g.Stake(kind, id)
+	if err != nil || s.Status != StakeLocked {
+		return nil
+	}
+	slashed := s.Amount / bpsDenom * g.Policy().SlashBps
+	slashed += s.Amount % bpsDenom * g.Policy().SlashBps / bpsDenom
+	if slashed > 0 {
+		if err := g.led.Transfer(moderationAccount(), CharityPoolAccount, slashed); err != nil {
+			return err
+		}
+	}
+	if rest := s.Amount - slashed; rest > 0 {
+		if err := g.led.Transfer(moderationAccount(), s.Owner, rest); err != nil {
+			return err
+		}
+	}
+	s.Status = StakeSlashed
+	raw, _ := json.Marshal(s)
+	if err := g.led.SetState(contentStakeKey(kind, id), raw); err != nil {
+		return err
+	}
+	return g.logAction(ModerationAction{Action: "slash", Kind: kind, ContentID: id, Actor: actor, Amount: slashed})
+}
+
+// IsHidden reports whether content was removed by moderation.
+func (g *ContentGuard) IsHidden(kind, id string) bool {
+	ok, err := g.led.HasState(contentHiddenKey(kind, id))
+	return err == nil && ok
+}
+
+func (g *ContentGuard) logAction(a ModerationAction) error {
+	a.Time = time.Now().UTC()
+	raw, _ := json.Marshal(a)
+	if err := g.led.SetState(modLogKey(a.Time), raw); err != nil {
+		return err
+	}
+	Broadcast("moderation", raw)
+	return nil
+}
+
+// ModerationLog returns moderation actions, oldest first.
+func (g *ContentGuard) ModerationLog() ([]ModerationAction, error) {
+	it := g.led.PrefixIterator([]byte("content:modlog:"))
+	var out []ModerationAction
+	for it.Next() {
+		var a ModerationAction
+		if err := json.Unmarshal(it.Value(), &a); err != nil {
+			return nil, err
+		}
+		out = append(out, a)
+	}
+	return out, it.Error()
+}
+
+// contentGuardFor returns the guard moderating content of kind.
+func contentGuardFor(kind string) (*ContentGuard, error) {
+	switch kind {
+	case ContentThread, ContentComment:
+		if forum == nil {
+			return nil, errors.New("forum not initialised")
+		}
+		return forum.guard, nil
+	case ContentPoll:
+		return pollGuard()
+	default:
+		return nil, fmt.Errorf("unknown content kind %q", kind)
+	}
+}
+
+// HideContent hides content on behalf of an authority node.
+func HideContent(kind, id string, moderator Address, reason string) error {
+	g, err := contentGuardFor(kind)
+	if err != nil {
+		return err
+	}
+	return g.Hide(kind, id, moderator, reason)
+}
+
+// RestoreContent makes hidden content visible again.
+func RestoreContent(kind, id string, moderator Address, reason string) error {
+	g, err := contentGuardFor(kind)
+	if err != nil {
+		return err
+	}
+	return g.Restore(kind, id, moderator, reason)
+}
+
+// ContentFlags lists the reports filed against content.
+func ContentFlags(kind, id string) ([]ContentFlag, error) {
+	g, err := contentGuardFor(kind)
+	if err != nil {
+		return nil, err
+	}
+	return g.Flags(kind, id)
+}
+
+// SetContentPolicy replaces the policy used for content of kind.
+func SetContentPolicy(kind string, caller Address, p ContentPolicy) error {
+	g, err := contentGuardFor(kind)
+	if err != nil {
+		return err
+	}
+	return g.SetPolicy(caller, p)
+}
+
+// ModerationHistory returns the moderation log kept alongside content of
+// kind.
+func ModerationHistory(kind string) ([]ModerationAction, error) {
+	g, err := contentGuardFor(kind)
+	if err != nil {
+		return nil, err
+	}
+	return g.ModerationLog()
+}
@@ -0,0 +1,41 @@
+package core
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+// Vectors from EIP-1014.
+func TestCreate2Address(t *testing.T) {
+	cases := []struct {
+		deployer, salt, code, want string
+	}{
+		{"0000000000000000000000000000000000000000", "0000000000000000000000000000000000000000000000000000000000000000", "00", "4d1a2e2bb4f88f0250f26ffff098b0b30b26bf38"},
+		{"deadbeef00000000000000000000000000000000", "0000000000000000000000000000000000000000000000000000000000000000", "00", "b928f69bb1d91cd65274e3c79d8986362984fda3"},
+		{"deadbeef00000000000000000000000000000000", "000000000000000000000000feed000000000000000000000000000000000000", "00", "d04116cdd17bebe565eb2422f2497e06cc1c9833"},
+		{"00000000000000000000000000000000deadbeef", "00000000000000000000000000000000000000000000000000000000cafebabe", "deadbeef", "60f3f640a8508fc6a86d45df051962668e1e8ac7"},
+		{"0000000000000000000000000000000000000000", "0000000000000000000000000000000000000000000000000000000000000000", "", "e33c0c7f7df4809055c3eba6c09cfe4baf1bd9e0"},
+	}
+	for _, c := range cases {
+		var deployer Address
+		b, _ := hex.DecodeString(c.deployer)
+		copy(deployer[:], b)
+		var salt Hash
+		s, _ := hex.DecodeString(c.salt)
+		copy(salt[:], s)
+		code, _ := hex.DecodeString(c.code)
+		got := Create2Address(deployer, salt, code)
+		if g := hex.EncodeToString(got[:]); g != c.want {
+			t.Errorf("Create2Address(%s, %s, %s) = %s, want %s", c.deployer, c.salt, c.code, g, c.want)
+		}
+	}
+}
+
+func TestCreate2AddressDependsOnInputs(t *testing.T) {
+	a := Create2Address(Address{1}, Hash{1}, []byte{1})
+	if a == Create2Address(Address{2}, Hash{1}, []byte{1}) ||
+		a == Create2Address(Address{1}, Hash{2}, []byte{1}) ||
+		a == Create2Address(Address{1}, Hash{1}, []byte{2}) {
+		t.Fatal("address must change with deployer, salt and code")
+	}
+}
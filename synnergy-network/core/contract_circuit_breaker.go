@@ -0,0 +1,185 @@
+package core
+
+// contract_circuit_breaker.go – contract pause registry enforced at execution.
+//
+// A paused contract cannot be executed: Dispatch refuses opcodes issued on
+// behalf of it, and ContractRegistry.InvokeWithReceipt and Ledger.Call
+// refuse calls into it. Administrative opcodes (resume, ownership, upgrade
+// admin and the read-only queries) stay available so the incident can be
+// handled. The contract's upgrade admin may pause and resume it; governance
+// can do so through the contract_pause / contract_resume parameters. Every
+// change is appended to contract:pauselog:<addr>: and published on the
+// "contract:pause" event topic.
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// TopicContractPause carries ContractPauseEvent payloads.
+const TopicContractPause = "contract:pause"
+
+const pauseLogPrefix = "contract:pauselog:"
+
+// ErrContractPaused is returned when execution targets a paused contract.
+var ErrContractPaused = errors.New("contract is paused")
+
+// ContractPauseEvent records one pause or resume.
+type ContractPauseEvent struct {
+	Contract   Address `json:"contract"`
+	Paused     bool    `json:"paused"`
+	By         Address `json:"by"`
+	Governance bool    `json:"governance,omitempty"`
+	Reason     string  `json:"reason,omitempty"`
+	Height     uint64  `json:"height"`
+	Timestamp  int64   `json:"timestamp"`
+}
+
+// pauseExempt lists the opcodes a paused contract may still issue.
+var pauseExempt = map[string]bool{
+	"ResumeContract":    true,
+	"PauseContract":     true,
+	"ContractInfo":      true,
+	"TransferOwnership": true,
+	"SetUpgradeAdmin":   true,
+	"UpgradeAdminOf":    true,
+	"UpgradeHistory":    true,
+	"IsContractPaused":  true,
+	"ContractPauseLog":  true,
+}
+
+// contractPaused reports whether addr is paused in led's state.
+func contractPaused(led *Ledger, addr Address) bool {
+	if led == nil {
+		return false
+	}
+	b, err := led.GetState(pausedKey(addr))
+	return err == nil && len(b) > 0 && b[0] == 1
+}
+
+// IsContractPaused reports whether addr is paused on the current ledger.
+func IsContractPaused(addr Address) bool {
+	led := CurrentLedger()
+	if led == nil {
+		return false
+	}
+	return contractPaused(led, addr)
+}
+
+// contractAddresser is implemented by opcode contexts executing on behalf
+// of a contract.
+type contractAddresser interface {
+	ContractAddress() Address
+}
+
+// ContractAddress returns the contract the transaction executes against.
+func (ctx *Context) ContractAddress() Address { return ctx.Contract }
+
+// checkPaused refuses op when the executing contract is paused.
+func checkPaused(ctx OpContext, op Opcode) error {
+	ca, ok := ctx.(contractAddresser)
+	if !ok {
+		return nil
+	}
+	addr := ca.ContractAddress()
+	if addr == AddressZero || !IsContractPaused(addr) {
+		return nil
+	}
+	mu.RLock()
+	name := opName(op)
+	mu.RUnlock()
+	if pauseExempt[name] {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrContractPaused, addr.Hex())
+}
+
+func opName(op Opcode) string {
+	for name, o := range nameToOp {
+		if o == op {
+			return name
+		}
+	}
+	return ""
+}
+
+// setPaused writes the flag and the log entry and publishes the event.
+func setPaused(led *Ledger, ev ContractPauseEvent) error {
+	flag := byte(0)
+	if ev.Paused {
+		flag = 1
+	}
+	if err := led.SetState(pausedKey(ev.Contract), []byte{flag}); err != nil {
+		return err
+	}
+	raw, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	key := fmt.Sprintf("%s%x:%020d", pauseLogPrefix, ev.Contract.Bytes(), time.Now().UnixNano())
+	if err := led.SetState([]byte(key), raw); err != nil {
+		return err
+	}
+	publishEvent(TopicContractPause, ev.Height, ev)
+	return nil
+}
+
+// ContractPauseLog returns the pause history of addr, oldest first.
+func ContractPauseLog(led *Ledger, addr Address) ([]ContractPauseEvent, error) {
+	it := led.PrefixIterator([]byte(fmt.Sprintf("%s%x:", pauseLogPrefix, addr.Bytes())))
+	var out []ContractPauseEvent
+	for it.Next() {
+		var ev ContractPauseEvent
+		if err := json.Unmarshal(it.Value(), &ev); err != nil {
+			return nil, err
+		}
+		out = append(out, ev)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Timestamp < out[j].Timestamp })
+	return out, nil
+}
+
+// PausedContracts lists the contracts currently paused.
+func PausedContracts(led *Ledger) ([]Address, error) {
+	it := led.PrefixIterator([]byte(pausedPrefix))
+	var out []Address
+	for it.Next() {
+		if v := it.Value(); len(v) == 0 || v[0] != 1 {
+			continue
+		}
+		var a Address
+		copy(a[:], it.Key()[len(pausedPrefix):])
+		out = append(out, a)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Hex() < out[j].Hex() })
+	return out, nil
+}
+
+// governancePause applies the contract_pause / contract_resume governance
+// parameters. value is "<hex address>[:reason]".
+func governancePause(value string, paused bool) error {
+	led := CurrentLedger()
+	if led == nil {
+		return errors.New("ledger not initialised")
+	}
+	addrHex, reason, _ := strings.Cut(value, ":")
+	b, err := hex.DecodeString(strings.TrimPrefix(addrHex, "0x"))
+	if err != nil || len(b) != len(Address{}) {
+		return fmt.Errorf("invalid contract address %q", addrHex)
+	}
+	var addr Address
+	copy(addr[:], b)
+	return setPaused(led, ContractPauseEvent{
+		Contract:   addr,
+		Paused:     paused,
+		Governance: true,
+		Reason:     reason,
+		Height:     led.LastHeight(),
+		Timestamp:  time.Now().Unix(),
+	})
+}
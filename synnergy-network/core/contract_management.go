@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"sync"
+	"time"
 )
 
 // ContractManager provides administrative lifecycle operations for
@@ -61,38 +62,62 @@ func (cm *ContractManager) OwnerOf(addr Address) (Address, error) {
 	return out, nil
 }
 
-// PauseContract marks the contract as paused. The VM should reject
-// calls when this flag is set. The manager only records state.
-func (cm *ContractManager) PauseContract(addr Address) error {
-	if cm.ledger == nil {
-		return errors.New("ledger not available")
-	}
-	return cm.ledger.SetState(pausedKey(addr), []byte{1})
+// PauseContract marks the contract as paused on behalf of caller, who
+// must be its upgrade admin. While paused the dispatcher and the registry
+// refuse to execute it (see contract_circuit_breaker.go).
+func (cm *ContractManager) PauseContract(caller, addr Address, reason string) error {
+	return cm.setPaused(caller, addr, true, reason)
+}
+
+// ResumeContract clears the paused flag on behalf of the upgrade admin.
+func (cm *ContractManager) ResumeContract(caller, addr Address, reason string) error {
+	return cm.setPaused(caller, addr, false, reason)
 }
 
-// ResumeContract clears the paused flag.
-func (cm *ContractManager) ResumeContract(addr Address) error {
+func (cm *ContractManager) setPaused(caller, addr Address, paused bool, reason string) error {
 	if cm.ledger == nil {
 		return errors.New("ledger not available")
 	}
-	return cm.ledger.SetState(pausedKey(addr), []byte{0})
+	admin, err := cm.UpgradeAdminOf(addr)
+	if err != nil {
+		return err
+	}
+	if admin == AddressZero || caller != admin {
+		return ErrNotUpgradeAdmin
+	}
+	return setPaused(cm.ledger, ContractPauseEvent{
+		Contract:  addr,
+		Paused:    paused,
+		By:        caller,
+		Reason:    reason,
+		Height:    cm.ledger.LastHeight(),
+		Timestamp: time.Now().Unix(),
+	})
 }
 
 // IsPaused reports whether a contract is currently paused.
 func (cm *ContractManager) IsPaused(addr Address) bool {
-	if cm.ledger == nil {
-		return false
-	}
-	b, err := cm.ledger.GetState(pausedKey(addr))
-	return err == nil && len(b) > 0 && b[0] == 1
+	return contractPaused(cm.ledger, addr)
 }
 
-// UpgradeContract replaces the bytecode for a deployed contract and
-// updates the registry entry. Existing paused state is preserved.
-func (cm *ContractManager) UpgradeContract(addr Address, code []byte, gas uint64) error {
+// UpgradeContract replaces the bytecode for a deployed contract on behalf
+// of caller, who must be the contract's upgrade admin (see
+// contract_upgrades.go), and records the new implementation in the upgrade
+// history. Existing paused state is preserved.
+func (cm *ContractManager) UpgradeContract(caller, addr Address, code []byte, gas uint64) error {
 	if cm.ledger == nil || cm.reg == nil {
 		return errors.New("contract manager not initialised")
 	}
+	if len(code) == 0 {
+		return errors.New("empty contract bytecode")
+	}
+	admin, err := cm.UpgradeAdminOf(addr)
+	if err != nil {
+		return err
+	}
+	if admin == AddressZero || caller != admin {
+		return ErrNotUpgradeAdmin
+	}
 	cm.mu.Lock()
 	defer cm.mu.Unlock()
 	sc, ok := cm.reg.byAddr[addr]
@@ -102,6 +127,7 @@ func (cm *ContractManager) UpgradeContract(addr Address, code []byte, gas uint64
 	if cm.IsPaused(addr) {
 		return errors.New("contract is paused")
 	}
+	prev, prevGas := sc.CodeHash, sc.GasLimit
 	hash := sha256.Sum256(code)
 	sc.Bytecode = code
 	sc.CodeHash = hash
@@ -109,7 +135,7 @@ func (cm *ContractManager) UpgradeContract(addr Address, code []byte, gas uint64
 	if err := cm.ledger.SetState(contractKey(addr), code); err != nil {
 		return err
 	}
-	return nil
+	return cm.recordUpgrade(sc, caller, prev, hash, prevGas)
 }
 
 // ContractInfo returns a JSON blob describing the contract including
@@ -0,0 +1,138 @@
+package core
+
+// contract_upgrades.go – upgrade authorisation and implementation history.
+//
+// Each contract may have an upgrade admin (an operator key or a governance
+// contract address). Until one is set the recorded owner, and failing that
+// the creator, may upgrade. Every code replacement is appended to an
+// on-ledger history under contract:upgrades:<addr>:<version> so explorers
+// can show the provenance of the running implementation; version 0 is the
+// code as deployed.
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+const (
+	upgradeAdminPrefix   = "contract:upgradeadmin:"
+	upgradeHistoryPrefix = "contract:upgrades:"
+)
+
+// ErrNotUpgradeAdmin is returned when the caller may not upgrade a contract.
+var ErrNotUpgradeAdmin = errors.New("caller is not the contract's upgrade admin")
+
+// ContractUpgrade is one entry of a contract's implementation history.
+type ContractUpgrade struct {
+	Version   uint32  `json:"version"`
+	CodeHash  string  `json:"code_hash"`
+	PrevHash  string  `json:"prev_hash,omitempty"`
+	By        Address `json:"by"`
+	GasLimit  uint64  `json:"gas_limit"`
+	Height    uint64  `json:"height"`
+	Timestamp int64   `json:"timestamp"`
+}
+
+func upgradeAdminKey(addr Address) []byte {
+	return append([]byte(upgradeAdminPrefix), addr.Bytes()...)
+}
+
+func upgradeHistoryKey(addr Address, version uint32) []byte {
+	return []byte(fmt.Sprintf("%s%x:%010d", upgradeHistoryPrefix, addr.Bytes(), version))
+}
+
+// UpgradeAdminOf returns the address allowed to upgrade the contract: the
+// explicit upgrade admin, else the owner, else the creator.
+func (cm *ContractManager) UpgradeAdminOf(addr Address) (Address, error) {
+	if cm.ledger == nil || cm.reg == nil {
+		return AddressZero, errors.New("contract manager not initialised")
+	}
+	if b, err := cm.ledger.GetState(upgradeAdminKey(addr)); err == nil && len(b) == len(Address{}) {
+		var a Address
+		copy(a[:], b)
+		return a, nil
+	}
+	if owner, err := cm.OwnerOf(addr); err == nil && owner != AddressZero {
+		return owner, nil
+	}
+	cm.reg.mu.RLock()
+	sc, ok := cm.reg.byAddr[addr]
+	cm.reg.mu.RUnlock()
+	if !ok {
+		return AddressZero, errors.New("contract not found")
+	}
+	return sc.Creator, nil
+}
+
+// SetUpgradeAdmin hands upgrade rights to admin. Only the current upgrade
+// admin may do so.
+func (cm *ContractManager) SetUpgradeAdmin(caller, addr, admin Address) error {
+	cur, err := cm.UpgradeAdminOf(addr)
+	if err != nil {
+		return err
+	}
+	if cur == AddressZero || caller != cur {
+		return ErrNotUpgradeAdmin
+	}
+	return cm.ledger.SetState(upgradeAdminKey(addr), admin.Bytes())
+}
+
+// UpgradeHistory returns the recorded implementations of a contract,
+// oldest first.
+func (cm *ContractManager) UpgradeHistory(addr Address) ([]ContractUpgrade, error) {
+	if cm.ledger == nil {
+		return nil, errors.New("ledger not available")
+	}
+	it := cm.ledger.PrefixIterator([]byte(fmt.Sprintf("%s%x:", upgradeHistoryPrefix, addr.Bytes())))
+	var out []ContractUpgrade
+	for it.Next() {
+		var u ContractUpgrade
+		if err := json.Unmarshal(it.Value(), &u); err != nil {
+			return nil, err
+		}
+		out = append(out, u)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Version < out[j].Version })
+	return out, nil
+}
+
+// recordUpgrade appends an entry for the new code, writing the deployed
+// code as version 0 the first time a contract is upgraded.
+func (cm *ContractManager) recordUpgrade(sc *SmartContract, by Address, prev, next [32]byte, prevGas uint64) error {
+	hist, err := cm.UpgradeHistory(sc.Address)
+	if err != nil {
+		return err
+	}
+	put := func(u ContractUpgrade) error {
+		raw, err := json.Marshal(u)
+		if err != nil {
+			return err
+		}
+		return cm.ledger.SetState(upgradeHistoryKey(sc.Address, u.Version), raw)
+	}
+	if len(hist) == 0 {
+		genesis := ContractUpgrade{
+			CodeHash:  hex.EncodeToString(prev[:]),
+			By:        sc.Creator,
+			GasLimit:  prevGas,
+			Timestamp: sc.CreatedAt.Unix(),
+		}
+		if err := put(genesis); err != nil {
+			return err
+		}
+		hist = append(hist, genesis)
+	}
+	return put(ContractUpgrade{
+		Version:   hist[len(hist)-1].Version + 1,
+		CodeHash:  hex.EncodeToString(next[:]),
+		PrevHash:  hex.EncodeToString(prev[:]),
+		By:        by,
+		GasLimit:  sc.GasLimit,
+		Height:    cm.ledger.LastHeight(),
+		Timestamp: time.Now().Unix(),
+	})
+}
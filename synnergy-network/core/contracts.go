@@ -18,6 +18,7 @@ package core
 import (
 	"crypto/sha256"
 	"errors"
+	"fmt"
 	"github.com/ethereum/go-ethereum/common"
 	"os"
 	"os/exec"
@@ -98,6 +99,9 @@ func (cr *ContractRegistry) InvokeWithReceipt(
 	if !ok {
 		return nil, errors.New("contract not found")
 	}
+	if contractPaused(cr.ledger, addr) {
+		return nil, ErrContractPaused
+	}
 
 	// 2. Clamp gas
 	if gasLimit == 0 || gasLimit > sc.GasLimit {
@@ -116,13 +120,9 @@ func (cr *ContractRegistry) InvokeWithReceipt(
 		GasLimit: gasLimit,
 	}
 
-	// 5. Execute bytecode
-	rec, err := cr.vm.Execute(sc.Bytecode, vmCtx)
-	if err != nil {
-		return nil, err
-	}
-
-	return rec, nil
+	// 5. Execute bytecode; a failed run still returns its receipt, which
+	// carries the decoded revert reason.
+	return cr.vm.Execute(sc.Bytecode, vmCtx)
 }
 
 func (cr *ContractRegistry) Invoke(
@@ -144,6 +144,9 @@ func (cr *ContractRegistry) Deploy(addr Address, code, ric []byte, gas uint64) e
 	if len(code) == 0 {
 		return errors.New("empty contract bytecode")
 	}
+	if max := CurrentResourceLimits().MaxCodeSize; len(code) > max {
+		return fmt.Errorf("%w: %d bytes, limit %d", ErrCodeTooLarge, len(code), max)
+	}
 
 	cr.mu.Lock()
 	defer cr.mu.Unlock()
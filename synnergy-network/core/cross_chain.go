@@ -239,6 +239,10 @@ func LockAndMint(ctx *Context, wrappedAsset AssetRef, proof Proof, amount uint64
 	logger := zap.L().Sugar()
 	caller := ctx.Caller
 
+	if err := bridgeGuard(); err != nil {
+		return err
+	}
+
 	// verify SPV proof
 	if !verifySPV(proof) {
 		logger.Warnf("SPV proof verification failed for tx %x", proof.TxHash)
@@ -269,9 +273,16 @@ func BurnAndRelease(ctx *Context, wrappedAsset AssetRef, target Address, amount
 	logger := zap.L().Sugar()
 	caller := ctx.Caller
 
+	undo, err := admitBridgeOutflow(AssetRef{Kind: AssetCoin}, amount)
+	if err != nil {
+		logger.Warnf("Release of %d blocked: %v", amount, err)
+		return err
+	}
+
 	// burn wrapped token from caller
 	if err := Burn(ctx, wrappedAsset, caller, amount); err != nil {
 		logger.Errorf("Burn wrapped token failed: %v", err)
+		undo()
 		return err
 	}
 
@@ -281,6 +292,7 @@ func BurnAndRelease(ctx *Context, wrappedAsset AssetRef, target Address, amount
 		logger.Errorf("Release transfer failed: %v", err)
 		// rollback burn by minting back
 		_ = Mint(ctx, wrappedAsset, caller, amount)
+		undo()
 		return err
 	}
 
@@ -26,6 +26,9 @@ func StartBridgeTransfer(ctx *Context, bridgeID string, asset AssetRef, to Addre
 	if amount == 0 {
 		return BridgeTransfer{}, fmt.Errorf("amount must be positive")
 	}
+	if err := bridgeGuard(); err != nil {
+		return BridgeTransfer{}, err
+	}
 	if _, err := GetBridge(bridgeID); err != nil {
 		return BridgeTransfer{}, err
 	}
@@ -63,8 +66,13 @@ func CompleteBridgeTransfer(ctx *Context, id string, proof Proof) error {
 	if !verifySPV(proof) {
 		return ErrInvalidProof
 	}
+	undo, err := admitBridgeOutflow(bt.Asset, bt.Amount)
+	if err != nil {
+		return err
+	}
 	escrow := ModuleAddress("bridge:" + bt.BridgeID)
 	if err := Transfer(ctx, bt.Asset, escrow, bt.To, bt.Amount); err != nil {
+		undo()
 		return err
 	}
 	bt.Completed = true
@@ -0,0 +1,606 @@
+package core
+
+// cross_chain_safety.go – bridge outflow rate limits and circuit breaker.
+//
+// Every release of escrowed assets (BurnAndRelease, CompleteBridgeTransfer)
+// is admitted against rolling-window outflow limits, one per asset plus a
+// global one, and scored against the asset's outflow history. An outflow
+// that would exceed a limit, or whose anomaly score (standard deviations
+// above the exponentially weighted mean) passes the configured threshold,
+// is rejected and trips the circuit breaker. While tripped every bridge
+// operation fails with ErrBridgePaused. Any guardian may pause manually;
+// unpausing takes approvals from Threshold distinct guardians.
+//
+// Guardians act by signing typed data (BridgeGuardianTypedData) carrying
+// their next guardian nonce, so a request names its guardian only if that
+// guardian signed it and cannot be replayed. The configuration, guardian
+// set included, is changed by governance ("bridge_safety_config") or by
+// Threshold current guardians signing the new configuration against the
+// current version (BridgeSafetyConfigTypedData); the first configuration
+// can only come from governance.
+//
+// Store layout:
+//   crosschain:safety:config                 -> BridgeSafetyConfig
+//   crosschain:safety:breaker                -> BridgeBreaker
+//   crosschain:safety:stats:<asset>          -> bridgeOutflowStats
+//   crosschain:safety:out:<asset>:<ns>:<seq> -> amount released at <ns>
+//   crosschain:safety:outseq                 -> last outflow sequence number
+//   crosschain:safety:nonce:<guardian>       -> next guardian nonce
+//   crosschain:safety:event:<seq>            -> BridgeSafetyEvent
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	gmath "github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"go.uber.org/zap"
+)
+
+const (
+	bridgeSafetyConfigKey  = "crosschain:safety:config"
+	bridgeBreakerKey       = "crosschain:safety:breaker"
+	bridgeStatsPrefix      = "crosschain:safety:stats:"
+	bridgeOutflowPrefix    = "crosschain:safety:out:"
+	bridgeOutflowSeq       = "crosschain:safety:outseq"
+	bridgeNoncePrefix      = "crosschain:safety:nonce:"
+	bridgeSafetyEventPref  = "crosschain:safety:event:"
+	bridgeSafetyEventSeq   = "crosschain:safety:eventseq"
+	bridgeGlobalAsset      = "global"
+	bridgeAnomalyAlpha     = 0.1
+	TopicBridgeSafety      = "bridge:safety"
+	DefaultBridgeWindow    = 24 * time.Hour
+	DefaultAnomalyScore    = 6.0
+	DefaultAnomalySamples  = 20
+	bridgeSafetyEventLimit = 1000
+)
+
+var (
+	ErrBridgePaused      = errors.New("bridge paused by circuit breaker")
+	ErrNotBridgeGuardian = errors.New("caller is not a bridge guardian")
+	ErrGuardianSignature = errors.New("request not signed by the guardian")
+	ErrGuardianNonce     = errors.New("guardian nonce mismatch")
+	ErrGuardianQuorum    = errors.New("configuration not signed by a guardian quorum")
+)
+
+// Guardian actions signed with BridgeGuardianTypedData.
+const (
+	BridgeActionPause   = "pause"
+	BridgeActionUnpause = "unpause"
+)
+
+// BridgeRateLimit caps the amount released within a rolling window. A zero
+// Max disables the limit.
+type BridgeRateLimit struct {
+	Max    uint64        `json:"max"`
+	Window time.Duration `json:"window"`
+}
+
+// BridgeSafetyConfig holds the outflow limits, anomaly detection settings
+// and the guardian multisig.
+type BridgeSafetyConfig struct {
+	Global           BridgeRateLimit            `json:"global"`
+	Assets           map[string]BridgeRateLimit `json:"assets"` // keyed by BridgeAssetKey
+	AnomalyThreshold float64                    `json:"anomaly_threshold"`
+	AnomalyMinSample uint64                     `json:"anomaly_min_samples"`
+	Guardians        []Address                  `json:"guardians"`
+	Threshold        int                        `json:"threshold"`
+	Version          uint64                     `json:"version"` // bumped by every change
+}
+
+// BridgeGuardianSignature is one guardian's signature over a configuration
+// change.
+type BridgeGuardianSignature struct {
+	Guardian Address `json:"guardian"`
+	Sig      []byte  `json:"sig"` // 96-byte signature ‖ public key
+}
+
+// BridgeBreaker is the circuit breaker state.
+type BridgeBreaker struct {
+	Paused    bool                 `json:"paused"`
+	Reason    string               `json:"reason,omitempty"`
+	TrippedAt time.Time            `json:"tripped_at,omitempty"`
+	Approvals map[string]time.Time `json:"approvals,omitempty"` // guardian hex -> time
+}
+
+// BridgeSafetyEvent is an alert raised by the safety controls.
+type BridgeSafetyEvent struct {
+	Seq    uint64    `json:"seq"`
+	Type   string    `json:"type"`
+	Asset  string    `json:"asset,omitempty"`
+	Amount uint64    `json:"amount,omitempty"`
+	Score  float64   `json:"score,omitempty"`
+	Detail string    `json:"detail,omitempty"`
+	Time   time.Time `json:"time"`
+}
+
+// BridgeSafetyStatus summarises the controls for operators.
+type BridgeSafetyStatus struct {
+	Config  BridgeSafetyConfig `json:"config"`
+	Breaker BridgeBreaker      `json:"breaker"`
+	Usage   map[string]uint64  `json:"usage"` // released within each limit's window
+}
+
+type bridgeOutflowStats struct {
+	Mean    float64 `json:"mean"`
+	Var     float64 `json:"var"`
+	Samples uint64  `json:"samples"`
+}
+
+var bridgeSafetyMu sync.Mutex
+
+// BridgeAssetKey names an asset in limits and events: "coin" or "token:<id>".
+func BridgeAssetKey(a AssetRef) string {
+	if a.Kind == AssetCoin {
+		return "coin"
+	}
+	return fmt.Sprintf("token:%d", a.TokenID)
+}
+
+func bridgeGet(key string, out any) bool {
+	raw, err := CurrentStore().Get([]byte(key))
+	return err == nil && json.Unmarshal(raw, out) == nil
+}
+
+func bridgeSet(key string, v any) error {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return CurrentStore().Set([]byte(key), raw)
+}
+
+func bridgeSafetyConfig() BridgeSafetyConfig {
+	var cfg BridgeSafetyConfig
+	bridgeGet(bridgeSafetyConfigKey, &cfg)
+	if cfg.AnomalyMinSample == 0 {
+		cfg.AnomalyMinSample = DefaultAnomalySamples
+	}
+	return cfg
+}
+
+func (c BridgeSafetyConfig) isGuardian(a Address) bool {
+	for _, g := range c.Guardians {
+		if g == a {
+			return true
+		}
+	}
+	return false
+}
+
+func (c BridgeSafetyConfig) limitFor(asset string) BridgeRateLimit {
+	l := c.Assets[asset]
+	if asset == bridgeGlobalAsset {
+		l = c.Global
+	}
+	if l.Window <= 0 {
+		l.Window = DefaultBridgeWindow
+	}
+	return l
+}
+
+// Validate checks the configuration.
+func (c BridgeSafetyConfig) Validate() error {
+	if c.AnomalyThreshold < 0 {
+		return errors.New("anomaly threshold must not be negative")
+	}
+	seen := map[Address]bool{}
+	for _, g := range c.Guardians {
+		if g == AddressZero || seen[g] {
+			return fmt.Errorf("invalid or duplicate guardian %s", g.Hex())
+		}
+		seen[g] = true
+	}
+	if len(c.Guardians) == 0 {
+		return errors.New("at least one guardian is required to unpause")
+	}
+	if c.Threshold < 1 || c.Threshold > len(c.Guardians) {
+		return fmt.Errorf("threshold must be between 1 and %d", len(c.Guardians))
+	}
+	return nil
+}
+
+// BridgeSafetyAccount is the module account guardian documents are bound
+// to.
+func BridgeSafetyAccount() Address { return ModuleAddress("bridge-safety") }
+
+func bridgeNonceKey(g Address) string { return bridgeNoncePrefix + g.Hex() }
+
+// BridgeGuardianNonce returns the nonce guardian's next signed pause or
+// unpause request must carry.
+func BridgeGuardianNonce(guardian Address) uint64 {
+	var n uint64
+	bridgeGet(bridgeNonceKey(guardian), &n)
+	return n
+}
+
+// bridgeTypedData returns the document of type primary with the given
+// fields and message, bound to the bridge safety account and the permit
+// chain ID.
+func bridgeTypedData(primary string, fields []apitypes.Type, msg apitypes.TypedDataMessage) TypedData {
+	permitMu.Lock()
+	chainID := new(big.Int).Set(permitChainID)
+	permitMu.Unlock()
+	return TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": {
+				{Name: "name", Type: "string"},
+				{Name: "version", Type: "string"},
+				{Name: "chainId", Type: "uint256"},
+				{Name: "verifyingContract", Type: "address"},
+			},
+			primary: fields,
+		},
+		PrimaryType: primary,
+		Domain: TypedDataDomain{
+			Name:              "Synnergy Bridge Safety",
+			Version:           "1",
+			ChainId:           (*gmath.HexOrDecimal256)(chainID),
+			VerifyingContract: BridgeSafetyAccount().Hex(),
+		},
+		Message: msg,
+	}
+}
+
+// BridgeGuardianTypedData returns the document guardian signs to take
+// action (BridgeActionPause or BridgeActionUnpause) with its nonce.
+func BridgeGuardianTypedData(action string, guardian Address, reason string, nonce uint64) TypedData {
+	return bridgeTypedData("GuardianAction", []apitypes.Type{
+		{Name: "action", Type: "string"},
+		{Name: "guardian", Type: "address"},
+		{Name: "reason", Type: "string"},
+		{Name: "nonce", Type: "uint256"},
+	}, apitypes.TypedDataMessage{
+		"action":   action,
+		"guardian": guardian.Hex(),
+		"reason":   reason,
+		"nonce":    strconv.FormatUint(nonce, 10),
+	})
+}
+
+// BridgeSafetyConfigTypedData returns the document guardians sign to
+// replace configuration version with cfg. cfg.Version is ignored.
+func BridgeSafetyConfigTypedData(cfg BridgeSafetyConfig, version uint64) TypedData {
+	cfg.Version = 0
+	raw, _ := json.Marshal(cfg)
+	return bridgeTypedData("SafetyConfig", []apitypes.Type{
+		{Name: "config", Type: "string"},
+		{Name: "version", Type: "uint256"},
+	}, apitypes.TypedDataMessage{
+		"config":  string(raw),
+		"version": strconv.FormatUint(version, 10),
+	})
+}
+
+// spendGuardianNonce checks that guardian signed the action with its next
+// nonce and consumes the nonce; bridgeSafetyMu must be held.
+func spendGuardianNonce(cfg BridgeSafetyConfig, action string, guardian Address, reason string, nonce uint64, sig []byte) error {
+	if !cfg.isGuardian(guardian) {
+		return ErrNotBridgeGuardian
+	}
+	if want := BridgeGuardianNonce(guardian); nonce != want {
+		return fmt.Errorf("%w: got %d want %d", ErrGuardianNonce, nonce, want)
+	}
+	ok, err := VerifyTypedData(BridgeGuardianTypedData(action, guardian, reason, nonce), sig, guardian)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrGuardianSignature
+	}
+	return bridgeSet(bridgeNonceKey(guardian), nonce+1)
+}
+
+// SetBridgeSafetyConfig replaces the safety configuration with the
+// approval of Threshold guardians of the current one, each signing
+// BridgeSafetyConfigTypedData(cfg, current version). An AnomalyThreshold of
+// zero applies DefaultAnomalyScore.
+func SetBridgeSafetyConfig(cfg BridgeSafetyConfig, sigs []BridgeGuardianSignature) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	bridgeSafetyMu.Lock()
+	defer bridgeSafetyMu.Unlock()
+	cur := bridgeSafetyConfig()
+	if len(cur.Guardians) == 0 {
+		return fmt.Errorf("%w: no guardians yet, the first configuration is set by governance", ErrGuardianQuorum)
+	}
+	td := BridgeSafetyConfigTypedData(cfg, cur.Version)
+	signed := map[Address]bool{}
+	for _, s := range sigs {
+		if !cur.isGuardian(s.Guardian) || signed[s.Guardian] {
+			continue
+		}
+		if ok, err := VerifyTypedData(td, s.Sig, s.Guardian); err == nil && ok {
+			signed[s.Guardian] = true
+		}
+	}
+	if len(signed) < cur.Threshold {
+		return fmt.Errorf("%w: %d of %d", ErrGuardianQuorum, len(signed), cur.Threshold)
+	}
+	return storeBridgeSafetyConfig(cfg, cur.Version)
+}
+
+// setBridgeSafetyConfigByGovernance applies the "bridge_safety_config"
+// parameter: a JSON BridgeSafetyConfig.
+func setBridgeSafetyConfigByGovernance(value string) error {
+	var cfg BridgeSafetyConfig
+	if err := json.Unmarshal([]byte(value), &cfg); err != nil {
+		return fmt.Errorf("invalid bridge safety config: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	bridgeSafetyMu.Lock()
+	defer bridgeSafetyMu.Unlock()
+	return storeBridgeSafetyConfig(cfg, bridgeSafetyConfig().Version)
+}
+
+// storeBridgeSafetyConfig saves cfg as the version after version;
+// bridgeSafetyMu must be held.
+func storeBridgeSafetyConfig(cfg BridgeSafetyConfig, version uint64) error {
+	if cfg.AnomalyThreshold == 0 {
+		cfg.AnomalyThreshold = DefaultAnomalyScore
+	}
+	cfg.Version = version + 1
+	if err := bridgeSet(bridgeSafetyConfigKey, cfg); err != nil {
+		return err
+	}
+	bridgeSafetyEvent(BridgeSafetyEvent{Type: "config", Detail: fmt.Sprintf("version %d: %d guardians, threshold %d", cfg.Version, len(cfg.Guardians), cfg.Threshold)})
+	return nil
+}
+
+// bridgeSafetyEvent persists and broadcasts an alert. Older events beyond
+// bridgeSafetyEventLimit are pruned.
+func bridgeSafetyEvent(ev BridgeSafetyEvent) {
+	var seq uint64
+	bridgeGet(bridgeSafetyEventSeq, &seq)
+	seq++
+	ev.Seq, ev.Time = seq, time.Now().UTC()
+	_ = bridgeSet(bridgeSafetyEventSeq, seq)
+	_ = bridgeSet(fmt.Sprintf("%s%020d", bridgeSafetyEventPref, seq), ev)
+	if seq > bridgeSafetyEventLimit {
+		_ = CurrentStore().Delete([]byte(fmt.Sprintf("%s%020d", bridgeSafetyEventPref, seq-bridgeSafetyEventLimit)))
+	}
+	raw, _ := json.Marshal(ev)
+	Broadcast(TopicBridgeSafety, raw)
+	zap.L().Sugar().Warnw("bridge safety event", "type", ev.Type, "asset", ev.Asset, "amount", ev.Amount, "detail", ev.Detail)
+}
+
+// BridgeSafetyEvents returns alerts with a sequence number above since.
+func BridgeSafetyEvents(since uint64) ([]BridgeSafetyEvent, error) {
+	it := CurrentStore().Iterator([]byte(bridgeSafetyEventPref), nil)
+	defer it.Close()
+	var out []BridgeSafetyEvent
+	for it.Next() {
+		var ev BridgeSafetyEvent
+		if err := json.Unmarshal(it.Value(), &ev); err != nil {
+			return nil, err
+		}
+		if ev.Seq > since {
+			out = append(out, ev)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Seq < out[j].Seq })
+	return out, it.Error()
+}
+
+// windowOutflow sums what was released for asset within window, pruning
+// records older than it.
+func windowOutflow(asset string, window time.Duration, now time.Time) (uint64, error) {
+	prefix := bridgeOutflowPrefix + asset + ":"
+	it := CurrentStore().Iterator([]byte(prefix), nil)
+	defer it.Close()
+	cutoff := now.Add(-window).UnixNano()
+	var total uint64
+	var stale [][]byte
+	for it.Next() {
+		stamp, _, _ := strings.Cut(strings.TrimPrefix(string(it.Key()), prefix), ":")
+		ns, err := strconv.ParseInt(stamp, 10, 64)
+		if err != nil {
+			continue
+		}
+		if ns <= cutoff {
+			stale = append(stale, append([]byte(nil), it.Key()...))
+			continue
+		}
+		var amt uint64
+		if err := json.Unmarshal(it.Value(), &amt); err == nil {
+			total += amt
+		}
+	}
+	if err := it.Error(); err != nil {
+		return 0, err
+	}
+	for _, k := range stale {
+		_ = CurrentStore().Delete(k)
+	}
+	return total, nil
+}
+
+// bridgeGuard fails while the circuit breaker is tripped.
+func bridgeGuard() error {
+	bridgeSafetyMu.Lock()
+	defer bridgeSafetyMu.Unlock()
+	var br BridgeBreaker
+	if bridgeGet(bridgeBreakerKey, &br) && br.Paused {
+		return fmt.Errorf("%w: %s", ErrBridgePaused, br.Reason)
+	}
+	return nil
+}
+
+// admitBridgeOutflow checks a release against the breaker, the rate limits
+// and the anomaly score, and records it. The returned undo removes the
+// record if the release itself fails.
+func admitBridgeOutflow(a AssetRef, amount uint64) (func(), error) {
+	bridgeSafetyMu.Lock()
+	defer bridgeSafetyMu.Unlock()
+	var br BridgeBreaker
+	if bridgeGet(bridgeBreakerKey, &br) && br.Paused {
+		return nil, fmt.Errorf("%w: %s", ErrBridgePaused, br.Reason)
+	}
+	cfg := bridgeSafetyConfig()
+	asset := BridgeAssetKey(a)
+	now := time.Now()
+	for _, name := range []string{asset, bridgeGlobalAsset} {
+		lim := cfg.limitFor(name)
+		if lim.Max == 0 {
+			continue
+		}
+		used, err := windowOutflow(name, lim.Window, now)
+		if err != nil {
+			return nil, err
+		}
+		if used+amount > lim.Max {
+			reason := fmt.Sprintf("%s outflow limit %d per %s exceeded", name, lim.Max, lim.Window)
+			bridgeSafetyEvent(BridgeSafetyEvent{Type: "limit_exceeded", Asset: asset, Amount: amount, Detail: reason})
+			return nil, tripBridgeBreaker(reason)
+		}
+	}
+
+	var st bridgeOutflowStats
+	bridgeGet(bridgeStatsPrefix+asset, &st)
+	x := float64(amount)
+	if st.Samples >= cfg.AnomalyMinSample && cfg.AnomalyThreshold > 0 {
+		// a floor of a tenth of the mean keeps a perfectly regular history
+		// from flagging every slightly larger release
+		sd := math.Max(math.Sqrt(st.Var), st.Mean/10)
+		score := (x - st.Mean) / sd
+		if score > cfg.AnomalyThreshold {
+			reason := fmt.Sprintf("anomalous %s outflow of %d (score %.1f)", asset, amount, score)
+			bridgeSafetyEvent(BridgeSafetyEvent{Type: "anomaly", Asset: asset, Amount: amount, Score: score, Detail: reason})
+			return nil, tripBridgeBreaker(reason)
+		}
+	}
+	prev := st
+	if st.Samples == 0 {
+		st.Mean = x
+	} else {
+		d := x - st.Mean
+		st.Mean += bridgeAnomalyAlpha * d
+		st.Var = (1 - bridgeAnomalyAlpha) * (st.Var + bridgeAnomalyAlpha*d*d)
+	}
+	st.Samples++
+	if err := bridgeSet(bridgeStatsPrefix+asset, st); err != nil {
+		return nil, err
+	}
+	// the sequence number keeps outflows in the same nanosecond apart
+	var seq uint64
+	bridgeGet(bridgeOutflowSeq, &seq)
+	seq++
+	if err := bridgeSet(bridgeOutflowSeq, seq); err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, 2)
+	for _, name := range []string{asset, bridgeGlobalAsset} {
+		k := fmt.Sprintf("%s%s:%019d:%020d", bridgeOutflowPrefix, name, now.UnixNano(), seq)
+		if err := bridgeSet(k, amount); err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+	return func() {
+		bridgeSafetyMu.Lock()
+		defer bridgeSafetyMu.Unlock()
+		for _, k := range keys {
+			_ = CurrentStore().Delete([]byte(k))
+		}
+		_ = bridgeSet(bridgeStatsPrefix+asset, prev)
+	}, nil
+}
+
+// tripBridgeBreaker pauses the bridge and returns the ErrBridgePaused the
+// rejected operation fails with; bridgeSafetyMu must be held.
+func tripBridgeBreaker(reason string) error {
+	br := BridgeBreaker{Paused: true, Reason: reason, TrippedAt: time.Now().UTC(), Approvals: map[string]time.Time{}}
+	if err := bridgeSet(bridgeBreakerKey, br); err != nil {
+		return err
+	}
+	bridgeSafetyEvent(BridgeSafetyEvent{Type: "paused", Detail: reason})
+	return fmt.Errorf("%w: %s", ErrBridgePaused, reason)
+}
+
+// PauseBridge lets a single guardian trip the breaker manually. sig is the
+// guardian's signature over BridgeGuardianTypedData(BridgeActionPause,
+// guardian, reason, nonce).
+func PauseBridge(guardian Address, reason string, nonce uint64, sig []byte) error {
+	bridgeSafetyMu.Lock()
+	defer bridgeSafetyMu.Unlock()
+	var br BridgeBreaker
+	if bridgeGet(bridgeBreakerKey, &br) && br.Paused {
+		return errors.New("bridge already paused")
+	}
+	if err := spendGuardianNonce(bridgeSafetyConfig(), BridgeActionPause, guardian, reason, nonce, sig); err != nil {
+		return err
+	}
+	if err := tripBridgeBreaker(fmt.Sprintf("paused by guardian %s: %s", guardian.Hex(), reason)); !errors.Is(err, ErrBridgePaused) {
+		return err
+	}
+	return nil
+}
+
+// ApproveBridgeUnpause records a guardian's approval to resume the bridge
+// and unpauses once Threshold guardians approved. sig is the guardian's
+// signature over BridgeGuardianTypedData(BridgeActionUnpause, guardian, "",
+// nonce). It reports whether the bridge was resumed.
+func ApproveBridgeUnpause(guardian Address, nonce uint64, sig []byte) (bool, error) {
+	bridgeSafetyMu.Lock()
+	defer bridgeSafetyMu.Unlock()
+	cfg := bridgeSafetyConfig()
+	var br BridgeBreaker
+	if !bridgeGet(bridgeBreakerKey, &br) || !br.Paused {
+		return false, errors.New("bridge not paused")
+	}
+	if err := spendGuardianNonce(cfg, BridgeActionUnpause, guardian, "", nonce, sig); err != nil {
+		return false, err
+	}
+	if br.Approvals == nil {
+		br.Approvals = map[string]time.Time{}
+	}
+	br.Approvals[guardian.Hex()] = time.Now().UTC()
+	approved := 0
+	for _, g := range cfg.Guardians {
+		if _, ok := br.Approvals[g.Hex()]; ok {
+			approved++
+		}
+	}
+	bridgeSafetyEvent(BridgeSafetyEvent{Type: "unpause_approval", Detail: fmt.Sprintf("%s (%d/%d)", guardian.Hex(), approved, cfg.Threshold)})
+	if approved < cfg.Threshold {
+		return false, bridgeSet(bridgeBreakerKey, br)
+	}
+	if err := bridgeSet(bridgeBreakerKey, BridgeBreaker{}); err != nil {
+		return false, err
+	}
+	bridgeSafetyEvent(BridgeSafetyEvent{Type: "unpaused", Detail: br.Reason})
+	return true, nil
+}
+
+// BridgeSafety returns the configuration, breaker state and current window
+// usage of every configured limit.
+func BridgeSafety() (BridgeSafetyStatus, error) {
+	bridgeSafetyMu.Lock()
+	defer bridgeSafetyMu.Unlock()
+	st := BridgeSafetyStatus{Config: bridgeSafetyConfig(), Usage: map[string]uint64{}}
+	bridgeGet(bridgeBreakerKey, &st.Breaker)
+	now := time.Now()
+	names := []string{bridgeGlobalAsset}
+	for name := range st.Config.Assets {
+		names = append(names, name)
+	}
+	for _, name := range names {
+		used, err := windowOutflow(name, st.Config.limitFor(name).Window, now)
+		if err != nil {
+			return st, err
+		}
+		st.Usage[name] = used
+	}
+	return st, nil
+}
@@ -0,0 +1,128 @@
+package core
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+// newSafetyTest installs an empty store and returns three guardian keys.
+func newSafetyTest(t *testing.T) ([]ed25519.PrivateKey, []Address) {
+	t.Helper()
+	SetStore(NewInMemoryStore())
+	t.Cleanup(func() { SetStore(nil) })
+	var keys []ed25519.PrivateKey
+	var addrs []Address
+	for i := 0; i < 3; i++ {
+		pub, priv, _ := ed25519.GenerateKey(nil)
+		keys, addrs = append(keys, priv), append(addrs, pubKeyToAddress(pub))
+	}
+	return keys, addrs
+}
+
+func TestBridgeSafetyConfigNeedsGovernanceOrQuorum(t *testing.T) {
+	keys, guardians := newSafetyTest(t)
+	cfg := BridgeSafetyConfig{Guardians: guardians, Threshold: 2, Global: BridgeRateLimit{Max: 1_000}}
+
+	if err := SetBridgeSafetyConfig(cfg, nil); !errors.Is(err, ErrGuardianQuorum) {
+		t.Fatalf("first configuration without governance: %v", err)
+	}
+	raw, _ := json.Marshal(cfg)
+	if err := UpdateParam("bridge_safety_config", string(raw)); err != nil {
+		t.Fatalf("governance: %v", err)
+	}
+	if got := bridgeSafetyConfig(); got.Version != 1 || got.Global.Max != 1_000 {
+		t.Fatalf("config %+v", got)
+	}
+
+	next := cfg
+	next.Global.Max = 5_000
+	td := BridgeSafetyConfigTypedData(next, 1)
+	one := []BridgeGuardianSignature{{Guardian: guardians[0], Sig: signRegistration(t, keys[0], td)}}
+	// the same guardian twice and an outsider do not make a quorum
+	_, outsider, _ := ed25519.GenerateKey(nil)
+	weak := append(one, one[0], BridgeGuardianSignature{Guardian: guardians[1], Sig: signRegistration(t, outsider, td)})
+	if err := SetBridgeSafetyConfig(next, weak); !errors.Is(err, ErrGuardianQuorum) {
+		t.Fatalf("change without a quorum: %v", err)
+	}
+	quorum := append(one, BridgeGuardianSignature{Guardian: guardians[1], Sig: signRegistration(t, keys[1], td)})
+	if err := SetBridgeSafetyConfig(next, quorum); err != nil {
+		t.Fatalf("quorum change: %v", err)
+	}
+	if got := bridgeSafetyConfig(); got.Version != 2 || got.Global.Max != 5_000 {
+		t.Fatalf("config %+v", got)
+	}
+	// the signatures were for version 1
+	if err := SetBridgeSafetyConfig(next, quorum); !errors.Is(err, ErrGuardianQuorum) {
+		t.Fatalf("replayed change: %v", err)
+	}
+}
+
+func TestBridgeGuardiansSignPauseAndUnpause(t *testing.T) {
+	keys, guardians := newSafetyTest(t)
+	raw, _ := json.Marshal(BridgeSafetyConfig{Guardians: guardians, Threshold: 2})
+	if err := UpdateParam("bridge_safety_config", string(raw)); err != nil {
+		t.Fatal(err)
+	}
+	// sign returns guardian i's next nonce and its signature of action
+	sign := func(i int, action, reason string) (uint64, []byte) {
+		n := BridgeGuardianNonce(guardians[i])
+		return n, signRegistration(t, keys[i], BridgeGuardianTypedData(action, guardians[i], reason, n))
+	}
+
+	// a request naming a guardian it was not signed by
+	_, forged := sign(1, BridgeActionPause, "drill")
+	if err := PauseBridge(guardians[0], "drill", 0, forged); !errors.Is(err, ErrGuardianSignature) {
+		t.Fatalf("forged pause: %v", err)
+	}
+	if err := PauseBridge(guardians[0], "drill", 0, nil); !errors.Is(err, ErrGuardianSignature) {
+		t.Fatalf("unsigned pause: %v", err)
+	}
+	n, pause := sign(0, BridgeActionPause, "drill")
+	if err := PauseBridge(guardians[0], "drill", n, pause); err != nil {
+		t.Fatalf("pause: %v", err)
+	}
+	if err := bridgeGuard(); !errors.Is(err, ErrBridgePaused) {
+		t.Fatalf("guard: %v", err)
+	}
+
+	n, unpause := sign(0, BridgeActionUnpause, "")
+	if done, err := ApproveBridgeUnpause(guardians[0], n, unpause); err != nil || done {
+		t.Fatalf("first approval: %v %v", done, err)
+	}
+	// one guardian cannot approve twice by replaying its request
+	if _, err := ApproveBridgeUnpause(guardians[0], n, unpause); !errors.Is(err, ErrGuardianNonce) {
+		t.Fatalf("replayed approval: %v", err)
+	}
+	n, approval := sign(1, BridgeActionUnpause, "")
+	if done, err := ApproveBridgeUnpause(guardians[1], n, approval); err != nil || !done {
+		t.Fatalf("second approval: %v %v", done, err)
+	}
+	if err := bridgeGuard(); err != nil {
+		t.Fatalf("guard after unpause: %v", err)
+	}
+	if err := PauseBridge(guardians[0], "drill", BridgeGuardianNonce(guardians[0]), pause); !errors.Is(err, ErrGuardianSignature) {
+		t.Fatalf("pause signed for another nonce: %v", err)
+	}
+}
+
+func TestBridgeOutflowsInOneWindowAreAllCounted(t *testing.T) {
+	_, guardians := newSafetyTest(t)
+	raw, _ := json.Marshal(BridgeSafetyConfig{Guardians: guardians, Threshold: 1, Global: BridgeRateLimit{Max: 100}})
+	if err := UpdateParam("bridge_safety_config", string(raw)); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		if _, err := admitBridgeOutflow(AssetRef{Kind: AssetCoin}, 10); err != nil {
+			t.Fatalf("outflow %d: %v", i, err)
+		}
+	}
+	if _, err := admitBridgeOutflow(AssetRef{Kind: AssetCoin}, 1); !errors.Is(err, ErrBridgePaused) {
+		t.Fatalf("outflow over the limit: %v", err)
+	}
+	st, err := BridgeSafety()
+	if err != nil || st.Usage[bridgeGlobalAsset] != 100 {
+		t.Fatalf("usage %+v %v", st.Usage, err)
+	}
+}
@@ -8,8 +8,8 @@ import (
 	"sync"
 )
 
-// DAOStaking manages token staking for governance participation.
-// Balances are persisted in the ledger key/value store under the
+// DAOStaking manages token staking for governance participation. Staked
+// coins are held by the dao_staking module account. Balances are persisted in the ledger key/value store under the
 // prefix "dao:stake:". The total locked amount is tracked separately
 // for quick access by consensus or reward modules.
 
@@ -35,6 +35,8 @@ func InitDAOStaking(lg *log.Logger, led StateRW) {
 // StakingManager returns the singleton staking engine.
 func StakingManager() *DAOStaking { return stakingMgr }
 
+func daoStakingAccount() Address { return ModuleAddress("dao_staking") }
+
 const (
 	stakePrefix = "dao:stake:"
 	totalKey    = "dao:stake:total"
@@ -51,7 +53,7 @@ func (s *DAOStaking) Stake(addr Address, amt uint64) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if err := s.ledger.Transfer(addr, AddressZero, amt); err != nil {
+	if err := s.ledger.Transfer(addr, daoStakingAccount(), amt); err != nil {
 		return err
 	}
 
@@ -99,7 +101,7 @@ func (s *DAOStaking) Unstake(addr Address, amt uint64) error {
 		return err
 	}
 
-	if err := s.ledger.Transfer(AddressZero, addr, amt); err != nil {
+	if err := s.ledger.Transfer(daoStakingAccount(), addr, amt); err != nil {
 		return err
 	}
 	s.logger.Printf("unstake %d to %s", amt, addr.Short())
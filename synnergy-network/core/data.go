@@ -1,6 +1,7 @@
 package core
 
 import (
+	"crypto/ed25519"
 	"crypto/sha256"
 	"encoding/json"
 	"fmt"
@@ -309,7 +310,11 @@ func PushFeedSigned(oracleID string, value, sig []byte) error {
 		return err
 	}
 	if len(o.PubKey) > 0 {
-		ok, err := Verify(o.Algo, o.PubKey, value, sig)
+		var pub interface{} = o.PubKey
+		if o.Algo == AlgoEd25519 {
+			pub = ed25519.PublicKey(o.PubKey)
+		}
+		ok, err := Verify(o.Algo, pub, value, sig)
 		if err != nil || !ok {
 			if err == nil {
 				err = fmt.Errorf("signature invalid")
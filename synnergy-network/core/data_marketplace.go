@@ -0,0 +1,568 @@
+package core
+
+// data_marketplace.go – priced access to datasets registered through the
+// distribution module.
+//
+// Flow
+// ----
+// 1. **ListDataSet** – the dataset owner attaches a pricing model (one-off,
+//    subscription or per-query) and a marketplace fee in basis points.
+// 2. **PurchaseDataAccess** – the consumer signs a DataPurchase order over
+//    the listing, its price and a per-consumer nonce, then pays up-front
+//    (one-off and subscription) or binds an open state channel (per-query)
+//    and receives an opaque access token.
+// 3. **MeterDataAccess** – every query served against a token is counted. For
+//    per-query listings the fee accrues against the token until it is settled.
+// 4. **SettleDataUsage** – the consumer posts the latest co-signed channel
+//    state; the marketplace verifies it covers the metered amount and collects
+//    its share from the provider.
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// PricingModel selects how a dataset listing is charged.
+type PricingModel uint8
+
+const (
+	PricingOneOff PricingModel = iota + 1
+	PricingSubscription
+	PricingPerQuery
+)
+
+// String returns the canonical lowercase name of the pricing model.
+func (p PricingModel) String() string {
+	switch p {
+	case PricingOneOff:
+		return "oneoff"
+	case PricingSubscription:
+		return "subscription"
+	case PricingPerQuery:
+		return "perquery"
+	default:
+		return "unknown"
+	}
+}
+
+// ParsePricingModel converts a CLI/REST string to a PricingModel.
+func ParsePricingModel(s string) (PricingModel, error) {
+	switch s {
+	case "oneoff", "one-off":
+		return PricingOneOff, nil
+	case "subscription", "sub":
+		return PricingSubscription, nil
+	case "perquery", "per-query":
+		return PricingPerQuery, nil
+	}
+	return 0, fmt.Errorf("unknown pricing model %q", s)
+}
+
+// DataListing attaches a pricing model to a registered DataSet.
+type DataListing struct {
+	ID        string        `json:"id"`
+	DataSetID string        `json:"dataset_id"`
+	Provider  Address       `json:"provider"`
+	Model     PricingModel  `json:"model"`
+	Price     uint64        `json:"price"`            // one-off price, subscription price per period or fee per query
+	Period    time.Duration `json:"period,omitempty"` // subscription length
+	FeeBp     uint16        `json:"fee_bp"`           // marketplace share in basis points
+	Active    bool          `json:"active"`
+	Created   time.Time     `json:"created"`
+}
+
+// DataAccessToken grants a consumer access to a listed dataset.
+type DataAccessToken struct {
+	Token       string       `json:"token"`
+	ListingID   string       `json:"listing_id"`
+	DataSetID   string       `json:"dataset_id"`
+	Consumer    Address      `json:"consumer"`
+	Provider    Address      `json:"provider"`
+	Model       PricingModel `json:"model"`
+	Expires     time.Time    `json:"expires,omitempty"`
+	Channel     ChannelID    `json:"channel,omitempty"`
+	ChannelBase uint64       `json:"channel_base,omitempty"` // consumer balance in the channel at issuance
+	Queries     uint64       `json:"queries"`
+	Billed      uint64       `json:"billed"`  // cumulative metered amount
+	Settled     uint64       `json:"settled"` // cumulative amount proven via channel state
+	Revoked     bool         `json:"revoked"`
+	IssuedAt    time.Time    `json:"issued_at"`
+}
+
+// Outstanding returns the metered amount not yet covered by a channel state.
+func (t *DataAccessToken) Outstanding() uint64 { return t.Billed - t.Settled }
+
+const (
+	TopicDataListingCreated = "datamarket:listing"
+	TopicDataAccessIssued   = "datamarket:access"
+	TopicDataUsageSettled   = "datamarket:settled"
+
+	// MaxDataMarketFeeBp caps the marketplace share of any sale.
+	MaxDataMarketFeeBp = 2_000
+)
+
+var (
+	ErrDataAccessDenied  = errors.New("data access denied")
+	ErrDataAccessExpired = errors.New("data access token expired")
+	ErrUnsettledUsage    = errors.New("channel state does not cover metered usage")
+	ErrDataPurchaseSig   = errors.New("purchase not signed by consumer")
+	ErrDataPurchaseNonce = errors.New("purchase nonce mismatch")
+
+	dataMarketMu sync.Mutex
+)
+
+func dataListingKey(id string) []byte { return []byte(fmt.Sprintf("datamarket:listing:%s", id)) }
+func dataTokenKey(tok string) []byte  { return []byte(fmt.Sprintf("datamarket:token:%s", tok)) }
+func dataNonceKey(a Address) []byte   { return []byte("datamarket:nonce:" + a.Hex()) }
+
+// DataMarketAccount is the module account collecting marketplace fees.
+func DataMarketAccount() Address { return ModuleAddress("datamarket") }
+
+// ListDataSet publishes a pricing model for an existing dataset. Only the
+// dataset owner may list it.
+func ListDataSet(l DataListing) (*DataListing, error) {
+	ds, err := GetDataSet(l.DataSetID)
+	if err != nil {
+		return nil, err
+	}
+	if ds.Owner != l.Provider {
+		return nil, ErrDataAccessDenied
+	}
+	switch l.Model {
+	case PricingOneOff, PricingPerQuery:
+	case PricingSubscription:
+		if l.Period <= 0 {
+			return nil, errors.New("subscription period required")
+		}
+	default:
+		return nil, fmt.Errorf("invalid pricing model %d", l.Model)
+	}
+	if l.Price == 0 {
+		return nil, errors.New("price must be >0")
+	}
+	if l.FeeBp > MaxDataMarketFeeBp {
+		return nil, fmt.Errorf("fee exceeds %d bp", MaxDataMarketFeeBp)
+	}
+	if l.ID == "" {
+		l.ID = uuid.New().String()
+	}
+	l.Active = true
+	l.Created = time.Now().UTC()
+
+	raw, err := json.Marshal(l)
+	if err != nil {
+		return nil, err
+	}
+	if err := CurrentStore().Set(dataListingKey(l.ID), raw); err != nil {
+		return nil, err
+	}
+	_ = Broadcast(TopicDataListingCreated, raw)
+	zap.L().Sugar().Infof("dataset %s listed as %s (%s)", l.DataSetID, l.ID, l.Model)
+	return &l, nil
+}
+
+// GetDataListing returns a listing by ID.
+func GetDataListing(id string) (*DataListing, error) {
+	raw, err := CurrentStore().Get(dataListingKey(id))
+	if err != nil || raw == nil {
+		return nil, ErrNotFound
+	}
+	var l DataListing
+	if err := json.Unmarshal(raw, &l); err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+// ListDataListings returns all listings, optionally restricted to a dataset.
+func ListDataListings(datasetID string) ([]DataListing, error) {
+	it := CurrentStore().Iterator([]byte("datamarket:listing:"), nil)
+	defer it.Close()
+	var out []DataListing
+	for it.Next() {
+		var l DataListing
+		if err := json.Unmarshal(it.Value(), &l); err != nil {
+			continue
+		}
+		if datasetID == "" || l.DataSetID == datasetID {
+			out = append(out, l)
+		}
+	}
+	return out, it.Error()
+}
+
+// DelistDataSet deactivates a listing. Existing tokens stay valid.
+func DelistDataSet(id string, provider Address) error {
+	dataMarketMu.Lock()
+	defer dataMarketMu.Unlock()
+	l, err := GetDataListing(id)
+	if err != nil {
+		return err
+	}
+	if l.Provider != provider {
+		return ErrDataAccessDenied
+	}
+	l.Active = false
+	raw, _ := json.Marshal(l)
+	return CurrentStore().Set(dataListingKey(id), raw)
+}
+
+// splitDataRevenue returns the (provider, marketplace) shares of amount.
+func splitDataRevenue(amount uint64, feeBp uint16) (uint64, uint64) {
+	fee := amount * uint64(feeBp) / 10_000
+	return amount - fee, fee
+}
+
+// payDataListing charges the consumer directly on the ledger and splits the
+// payment between provider and marketplace.
+func payDataListing(l *DataListing, consumer Address) error {
+	led := CurrentLedger()
+	if led == nil {
+		return errors.New("ledger not initialised")
+	}
+	provShare, fee := splitDataRevenue(l.Price, l.FeeBp)
+	if err := led.Transfer(consumer, l.Provider, provShare); err != nil {
+		return err
+	}
+	if fee > 0 {
+		if err := led.Transfer(consumer, DataMarketAccount(), fee); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newDataAccessToken() (string, error) {
+	var b [24]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b[:]), nil
+}
+
+// DataPurchase is the order a consumer signs to buy access to a listing.
+// Price must equal the listing price, so a provider cannot raise it under a
+// pending order, and Nonce must be the consumer's next purchase nonce, so
+// an order is spent once.
+type DataPurchase struct {
+	ListingID string    `json:"listing_id"`
+	Consumer  Address   `json:"consumer"`
+	Price     uint64    `json:"price"`
+	Nonce     uint64    `json:"nonce"`
+	Channel   ChannelID `json:"channel,omitempty"` // per-query listings only
+}
+
+// DataPurchaseNonce returns the nonce the consumer's next order must carry.
+func DataPurchaseNonce(consumer Address) uint64 {
+	raw, err := CurrentStore().Get(dataNonceKey(consumer))
+	if err != nil || len(raw) != 8 {
+		return 0
+	}
+	return binary.BigEndian.Uint64(raw)
+}
+
+// DataPurchaseTypedData returns the document the consumer signs for p. It
+// is bound to the marketplace account and the permit chain ID.
+func DataPurchaseTypedData(p DataPurchase) TypedData {
+	permitMu.Lock()
+	chainID := new(big.Int).Set(permitChainID)
+	permitMu.Unlock()
+	return TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": {
+				{Name: "name", Type: "string"},
+				{Name: "version", Type: "string"},
+				{Name: "chainId", Type: "uint256"},
+				{Name: "verifyingContract", Type: "address"},
+			},
+			"DataPurchase": {
+				{Name: "listing", Type: "string"},
+				{Name: "consumer", Type: "address"},
+				{Name: "price", Type: "uint256"},
+				{Name: "nonce", Type: "uint256"},
+				{Name: "channel", Type: "bytes32"},
+			},
+		},
+		PrimaryType: "DataPurchase",
+		Domain: TypedDataDomain{
+			Name:              "Synnergy Data Market",
+			Version:           "1",
+			ChainId:           (*math.HexOrDecimal256)(chainID),
+			VerifyingContract: DataMarketAccount().Hex(),
+		},
+		Message: apitypes.TypedDataMessage{
+			"listing":  p.ListingID,
+			"consumer": p.Consumer.Hex(),
+			"price":    strconv.FormatUint(p.Price, 10),
+			"nonce":    strconv.FormatUint(p.Nonce, 10),
+			"channel":  "0x" + hex.EncodeToString(p.Channel[:]),
+		},
+	}
+}
+
+// PurchaseDataAccess buys access to a listing with an order signed by the
+// consumer; sig is the 96-byte signature ‖ public key over
+// DataPurchaseTypedData(p). Per-query listings require an open state
+// channel between consumer and provider which funds metering; the channel
+// is ignored for the other pricing models.
+func PurchaseDataAccess(p DataPurchase, sig []byte) (*DataAccessToken, error) {
+	dataMarketMu.Lock()
+	defer dataMarketMu.Unlock()
+
+	l, err := GetDataListing(p.ListingID)
+	if err != nil {
+		return nil, err
+	}
+	if !l.Active {
+		return nil, errors.New("listing inactive")
+	}
+	if p.Price != l.Price {
+		return nil, fmt.Errorf("order price %d does not match listing price %d", p.Price, l.Price)
+	}
+	if want := DataPurchaseNonce(p.Consumer); p.Nonce != want {
+		return nil, fmt.Errorf("%w: got %d want %d", ErrDataPurchaseNonce, p.Nonce, want)
+	}
+	ok, err := VerifyTypedData(DataPurchaseTypedData(p), sig, p.Consumer)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrDataPurchaseSig
+	}
+	// spend the order before paying so it cannot be replayed
+	nonce := make([]byte, 8)
+	binary.BigEndian.PutUint64(nonce, p.Nonce+1)
+	if err := CurrentStore().Set(dataNonceKey(p.Consumer), nonce); err != nil {
+		return nil, err
+	}
+	consumer, channel := p.Consumer, p.Channel
+
+	tok, err := newDataAccessToken()
+	if err != nil {
+		return nil, err
+	}
+	at := &DataAccessToken{
+		Token:     tok,
+		ListingID: l.ID,
+		DataSetID: l.DataSetID,
+		Consumer:  consumer,
+		Provider:  l.Provider,
+		Model:     l.Model,
+		IssuedAt:  time.Now().UTC(),
+	}
+
+	switch l.Model {
+	case PricingOneOff:
+		if err := payDataListing(l, consumer); err != nil {
+			return nil, err
+		}
+	case PricingSubscription:
+		if err := payDataListing(l, consumer); err != nil {
+			return nil, err
+		}
+		at.Expires = at.IssuedAt.Add(l.Period)
+	case PricingPerQuery:
+		eng := Channels()
+		if eng == nil {
+			return nil, errors.New("state channels not initialised")
+		}
+		ch, err := eng.GetChannel(channel)
+		if err != nil {
+			return nil, err
+		}
+		base, ok := channelBalanceOf(ch, consumer, l.Provider)
+		if !ok {
+			return nil, errors.New("channel must connect consumer and provider")
+		}
+		if ch.Closing != 0 || ch.Paused {
+			return nil, errors.New("channel not open")
+		}
+		if base < l.Price {
+			return nil, errors.New("channel balance below per-query fee")
+		}
+		at.Channel = channel
+		at.ChannelBase = base
+	}
+
+	if err := putDataAccessToken(at); err != nil {
+		return nil, err
+	}
+	payload, _ := json.Marshal(struct {
+		ListingID string  `json:"listing_id"`
+		Consumer  Address `json:"consumer"`
+	}{l.ID, consumer})
+	_ = Broadcast(TopicDataAccessIssued, payload)
+	return at, nil
+}
+
+// channelBalanceOf returns the consumer's balance in a channel shared with
+// provider.
+func channelBalanceOf(ch Channel, consumer, provider Address) (uint64, bool) {
+	switch {
+	case ch.PartyA == consumer && ch.PartyB == provider:
+		return ch.BalanceA, true
+	case ch.PartyB == consumer && ch.PartyA == provider:
+		return ch.BalanceB, true
+	}
+	return 0, false
+}
+
+func putDataAccessToken(at *DataAccessToken) error {
+	raw, err := json.Marshal(at)
+	if err != nil {
+		return err
+	}
+	return CurrentStore().Set(dataTokenKey(at.Token), raw)
+}
+
+// GetDataAccessToken loads an access token.
+func GetDataAccessToken(tok string) (*DataAccessToken, error) {
+	raw, err := CurrentStore().Get(dataTokenKey(tok))
+	if err != nil || raw == nil {
+		return nil, ErrNotFound
+	}
+	var at DataAccessToken
+	if err := json.Unmarshal(raw, &at); err != nil {
+		return nil, err
+	}
+	return &at, nil
+}
+
+// MeterDataAccess validates a token for one query and records usage. It
+// returns the dataset so the caller can serve its CID. Per-query tokens
+// refuse service once the unsettled amount would exceed the channel funds.
+func MeterDataAccess(tok string) (*DataAccessToken, DataSet, error) {
+	dataMarketMu.Lock()
+	defer dataMarketMu.Unlock()
+
+	at, err := GetDataAccessToken(tok)
+	if err != nil {
+		return nil, DataSet{}, err
+	}
+	if at.Revoked {
+		return nil, DataSet{}, ErrDataAccessDenied
+	}
+	if !at.Expires.IsZero() && time.Now().After(at.Expires) {
+		return nil, DataSet{}, ErrDataAccessExpired
+	}
+	if at.Model == PricingPerQuery {
+		l, err := GetDataListing(at.ListingID)
+		if err != nil {
+			return nil, DataSet{}, err
+		}
+		if at.Billed+l.Price > at.ChannelBase {
+			return nil, DataSet{}, ErrUnsettledUsage
+		}
+		at.Billed += l.Price
+	}
+	ds, err := GetDataSet(at.DataSetID)
+	if err != nil {
+		return nil, DataSet{}, err
+	}
+	at.Queries++
+	if err := putDataAccessToken(at); err != nil {
+		return nil, DataSet{}, err
+	}
+	return at, ds, nil
+}
+
+// SettleDataUsage accepts the latest co-signed channel state for a per-query
+// token. The state must show the consumer has moved at least the metered
+// amount to the provider. The marketplace fee on the newly settled amount is
+// collected from the provider on-chain.
+func SettleDataUsage(tok string, state SignedState) (*DataAccessToken, error) {
+	dataMarketMu.Lock()
+	defer dataMarketMu.Unlock()
+
+	at, err := GetDataAccessToken(tok)
+	if err != nil {
+		return nil, err
+	}
+	if at.Model != PricingPerQuery {
+		return nil, errors.New("token is not metered")
+	}
+	if state.Channel.ID != at.Channel {
+		return nil, errors.New("state is for a different channel")
+	}
+	if err := verifySigs(&state); err != nil {
+		return nil, err
+	}
+	bal, ok := channelBalanceOf(state.Channel, at.Consumer, at.Provider)
+	if !ok {
+		return nil, errors.New("channel parties mismatch")
+	}
+	if bal > at.ChannelBase || at.ChannelBase-bal < at.Billed {
+		return nil, ErrUnsettledUsage
+	}
+
+	l, err := GetDataListing(at.ListingID)
+	if err != nil {
+		return nil, err
+	}
+	newly := at.Billed - at.Settled
+	if _, fee := splitDataRevenue(newly, l.FeeBp); fee > 0 {
+		led := CurrentLedger()
+		if led == nil {
+			return nil, errors.New("ledger not initialised")
+		}
+		if err := led.Transfer(at.Provider, DataMarketAccount(), fee); err != nil {
+			return nil, err
+		}
+	}
+	at.Settled = at.Billed
+	if err := putDataAccessToken(at); err != nil {
+		return nil, err
+	}
+	payload, _ := json.Marshal(struct {
+		Token  string `json:"token"`
+		Amount uint64 `json:"amount"`
+	}{at.Token, newly})
+	_ = Broadcast(TopicDataUsageSettled, payload)
+	return at, nil
+}
+
+// RevokeDataAccess lets the provider revoke a token, e.g. after a consumer
+// refuses to settle metered usage.
+func RevokeDataAccess(tok string, provider Address) error {
+	dataMarketMu.Lock()
+	defer dataMarketMu.Unlock()
+	at, err := GetDataAccessToken(tok)
+	if err != nil {
+		return err
+	}
+	if at.Provider != provider {
+		return ErrDataAccessDenied
+	}
+	at.Revoked = true
+	return putDataAccessToken(at)
+}
+
+// ListDataAccessTokens returns the tokens held by a consumer.
+func ListDataAccessTokens(consumer Address) ([]DataAccessToken, error) {
+	it := CurrentStore().Iterator([]byte("datamarket:token:"), nil)
+	defer it.Close()
+	var out []DataAccessToken
+	for it.Next() {
+		var at DataAccessToken
+		if err := json.Unmarshal(it.Value(), &at); err != nil {
+			continue
+		}
+		if at.Consumer == consumer {
+			out = append(out, at)
+		}
+	}
+	return out, it.Error()
+}
@@ -0,0 +1,77 @@
+package core
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func signDataPurchase(t *testing.T, priv ed25519.PrivateKey, p DataPurchase) []byte {
+	t.Helper()
+	s, err := SignTypedData(priv, DataPurchaseTypedData(p))
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	sig, _ := hex.DecodeString(s.Sig)
+	return sig
+}
+
+func TestPurchaseDataAccessRequiresConsumerOrder(t *testing.T) {
+	cfg, _ := tmpLedgerConfig(t, nil)
+	led, err := NewLedger(cfg)
+	if err != nil {
+		t.Fatalf("ledger: %v", err)
+	}
+	prevLedger := globalLedger
+	globalLedger = led
+	SetStore(NewInMemoryStore())
+	t.Cleanup(func() { globalLedger = prevLedger; SetStore(nil) })
+
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	_, other, _ := ed25519.GenerateKey(nil)
+	consumer, provider := pubKeyToAddress(pub), Address{0xd1}
+	if err := led.Mint(consumer, 1_000); err != nil {
+		t.Fatalf("mint: %v", err)
+	}
+	l := DataListing{ID: "l1", DataSetID: "ds", Provider: provider, Model: PricingOneOff, Price: 100, Active: true}
+	raw, _ := json.Marshal(l)
+	if err := CurrentStore().Set(dataListingKey(l.ID), raw); err != nil {
+		t.Fatalf("listing: %v", err)
+	}
+
+	order := DataPurchase{ListingID: l.ID, Consumer: consumer, Price: l.Price, Nonce: DataPurchaseNonce(consumer)}
+
+	if _, err := PurchaseDataAccess(order, signDataPurchase(t, other, order)); !errors.Is(err, ErrDataPurchaseSig) {
+		t.Fatalf("order signed by another key: err=%v", err)
+	}
+	cheap := order
+	cheap.Price = 1
+	if _, err := PurchaseDataAccess(cheap, signDataPurchase(t, priv, cheap)); err == nil {
+		t.Fatal("order below listing price accepted")
+	}
+	tampered := order
+	tampered.ListingID = "l2"
+	if _, err := PurchaseDataAccess(order, signDataPurchase(t, priv, tampered)); !errors.Is(err, ErrDataPurchaseSig) {
+		t.Fatalf("signature over another order: err=%v", err)
+	}
+	if led.BalanceOf(consumer) != 1_000 {
+		t.Fatalf("rejected orders debited the consumer: %d", led.BalanceOf(consumer))
+	}
+
+	sig := signDataPurchase(t, priv, order)
+	at, err := PurchaseDataAccess(order, sig)
+	if err != nil {
+		t.Fatalf("purchase: %v", err)
+	}
+	if at.Consumer != consumer || led.BalanceOf(consumer) != 900 || led.BalanceOf(provider) != 100 {
+		t.Fatalf("token %+v consumer=%d provider=%d", at, led.BalanceOf(consumer), led.BalanceOf(provider))
+	}
+	if _, err := PurchaseDataAccess(order, sig); !errors.Is(err, ErrDataPurchaseNonce) {
+		t.Fatalf("replayed order: err=%v", err)
+	}
+	if led.BalanceOf(consumer) != 900 {
+		t.Fatalf("replay debited the consumer: %d", led.BalanceOf(consumer))
+	}
+}
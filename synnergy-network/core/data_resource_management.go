@@ -26,8 +26,8 @@ func (m *DataResourceManager) key(owner Address, k string) []byte {
 }
 
 // Store writes data under the given key and adjusts the gas limit for the owner.
-// Storage rent is charged via the ledger and an event is broadcast for
-// consensus replication.
+// A storage deposit is locked via the ledger (see storage_deposit.go) and an
+// event is broadcast for consensus replication.
 func (m *DataResourceManager) Store(owner Address, key string, data []byte, gas uint64) error {
 	if len(key) == 0 {
 		return fmt.Errorf("empty key")
@@ -40,7 +40,8 @@ func (m *DataResourceManager) Store(owner Address, key string, data []byte, gas
 	if err := store.Set(m.key(owner, key), data); err != nil {
 		return err
 	}
-	if err := led.ChargeStorageRent(owner, int64(len(data))); err != nil {
+	if err := led.LockStorageDeposit(owner, string(m.key(owner, key)), uint64(len(data))); err != nil {
+		_ = store.Delete(m.key(owner, key))
 		return err
 	}
 	m.alloc.Adjust(owner, gas)
@@ -76,6 +77,11 @@ func (m *DataResourceManager) Delete(owner Address, key string) error {
 	if err := store.Delete(m.key(owner, key)); err != nil {
 		return err
 	}
+	if led := CurrentLedger(); led != nil {
+		if err := led.ReleaseStorageDeposit(owner, string(m.key(owner, key))); err != nil {
+			return err
+		}
+	}
 	m.alloc.Adjust(owner, 0)
 	payload, _ := json.Marshal(struct {
 		Owner Address `json:"owner"`
@@ -2,9 +2,7 @@ package core
 
 import (
 	"encoding/json"
-	"fmt"
 	"sync"
-	"time"
 )
 
 // DeFiManager exposes basic decentralised finance helpers. The
@@ -38,49 +36,3 @@ func (dm *DeFiManager) load(key []byte, out any) error {
 func (dm *DeFiManager) mint(to Address, token string, amt uint64) error {
 	return dm.ledger.MintToken(to, token, amt)
 }
-
-// ------------------------------------------------------------------
-// Insurance
-// ------------------------------------------------------------------
-
-// DefiInsurancePolicy represents a basic on-chain insurance record.
-type DefiInsurancePolicy struct {
-	ID      Hash    `json:"id"`
-	Holder  Address `json:"holder"`
-	Premium uint64  `json:"premium"`
-	Payout  uint64  `json:"payout"`
-	Active  bool    `json:"active"`
-	Created int64   `json:"created"`
-}
-
-func (dm *DeFiManager) CreateInsurance(id Hash, holder Address, premium, payout uint64) error {
-	dm.mu.Lock()
-	defer dm.mu.Unlock()
-	k := append([]byte("ins:"), id[:]...)
-	if ok, _ := dm.ledger.HasState(k); ok {
-		return fmt.Errorf("exists")
-	}
-	pol := DefiInsurancePolicy{ID: id, Holder: holder, Premium: premium, Payout: payout, Active: true, Created: time.Now().Unix()}
-	if err := dm.ledger.Transfer(holder, BurnAddress, premium); err != nil {
-		return err
-	}
-	return dm.store(k, pol)
-}
-
-func (dm *DeFiManager) ClaimInsurance(id Hash) error {
-	dm.mu.Lock()
-	defer dm.mu.Unlock()
-	k := append([]byte("ins:"), id[:]...)
-	var pol DefiInsurancePolicy
-	if err := dm.load(k, &pol); err != nil {
-		return err
-	}
-	if !pol.Active {
-		return fmt.Errorf("not active")
-	}
-	pol.Active = false
-	if err := dm.mint(pol.Holder, "SYNTHRON", pol.Payout); err != nil {
-		return err
-	}
-	return dm.store(k, pol)
-}
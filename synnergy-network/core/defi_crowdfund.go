@@ -0,0 +1,404 @@
+package core
+
+// defi_crowdfund.go – escrowed crowdfunding with milestone releases.
+//
+// Contributions are held by the crowdfund module account until the campaign
+// is finalised. A campaign that misses its goal by the deadline refunds
+// every contributor in full. A funded campaign either releases everything
+// to the beneficiary or, when it was started with milestones, releases one
+// tranche per milestone after contributors approve it in a vote weighted by
+// their contribution. A milestone rejected CrowdfundMilestoneAttempts times
+// fails the campaign and the unreleased escrow is refunded pro rata.
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	cfPrefix        = "cf:fund:"
+	cfContribPrefix = "cf:contrib:"
+	cfSeqKey        = "cf:seq"
+
+	CrowdfundVotePeriod        = 7 * 24 * time.Hour
+	CrowdfundQuorumBps         = 2_000 // of the amount raised
+	CrowdfundMilestoneAttempts = 2
+)
+
+// CrowdfundStatus is the stage of a campaign.
+type CrowdfundStatus string
+
+const (
+	CrowdfundOpen      CrowdfundStatus = "open"
+	CrowdfundFunded    CrowdfundStatus = "funded" // milestones pending
+	CrowdfundCompleted CrowdfundStatus = "completed"
+	CrowdfundFailed    CrowdfundStatus = "failed"
+)
+
+// CrowdfundMilestone is a tranche released on contributor approval.
+type CrowdfundMilestone struct {
+	Description string `json:"description"`
+	ShareBps    uint64 `json:"share_bps"`
+	Released    bool   `json:"released"`
+	Amount      uint64 `json:"amount"`
+	Attempts    int    `json:"attempts"`
+	VoteEnds    int64  `json:"vote_ends,omitempty"`
+	Yes         uint64 `json:"yes"`
+	No          uint64 `json:"no"`
+	// Voters is keyed by hex address and reset with every vote.
+	Voters map[string]bool `json:"voters,omitempty"`
+}
+
+// Crowdfund is a campaign escrowing contributions until its goal and
+// deadline are settled.
+type Crowdfund struct {
+	ID          uint64               `json:"id"`
+	Title       string               `json:"title"`
+	Creator     Address              `json:"creator"`
+	Beneficiary Address              `json:"beneficiary"`
+	Goal        uint64               `json:"goal"`
+	Deadline    int64                `json:"deadline"`
+	Raised      uint64               `json:"raised"`
+	Released    uint64               `json:"released"`
+	Refunded    uint64               `json:"refunded"`
+	Backers     int                  `json:"backers"`
+	Milestones  []CrowdfundMilestone `json:"milestones,omitempty"`
+	Status      CrowdfundStatus      `json:"status"`
+	Created     int64                `json:"created"`
+}
+
+// CrowdfundContribution is one backer's escrowed total.
+type CrowdfundContribution struct {
+	Backer   Address `json:"backer"`
+	Amount   uint64  `json:"amount"`
+	Refunded uint64  `json:"refunded"`
+}
+
+func crowdfundAccount() Address { return ModuleAddress("crowdfund") }
+
+func cfKey(id uint64) []byte { return []byte(fmt.Sprintf("%s%010d", cfPrefix, id)) }
+func cfContribKey(id uint64, a Address) []byte {
+	return []byte(fmt.Sprintf("%s%010d:%s", cfContribPrefix, id, a.String()))
+}
+
+func (dm *DeFiManager) loadCrowdfund(id uint64) (Crowdfund, error) {
+	var cf Crowdfund
+	if err := dm.load(cfKey(id), &cf); err != nil {
+		return cf, fmt.Errorf("crowdfund %d not found", id)
+	}
+	return cf, nil
+}
+
+func (dm *DeFiManager) contributions(id uint64) ([]CrowdfundContribution, error) {
+	it := dm.ledger.PrefixIterator([]byte(fmt.Sprintf("%s%010d:", cfContribPrefix, id)))
+	var out []CrowdfundContribution
+	for it.Next() {
+		var c CrowdfundContribution
+		if err := json.Unmarshal(it.Value(), &c); err != nil {
+			return nil, err
+		}
+		out = append(out, c)
+	}
+	if err := it.Error(); err != nil {
+		return nil, err
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Backer.String() < out[j].Backer.String() })
+	return out, nil
+}
+
+// StartCrowdfund opens a campaign. Milestone shares must sum to 10000 bps;
+// without milestones the whole amount is released on success.
+func (dm *DeFiManager) StartCrowdfund(creator, beneficiary Address, title string, goal uint64, deadline time.Time, milestones []CrowdfundMilestone) (uint64, error) {
+	if strings.TrimSpace(title) == "" || goal == 0 {
+		return 0, errors.New("title and goal required")
+	}
+	if !deadline.After(time.Now()) {
+		return 0, errors.New("deadline must be in the future")
+	}
+	var total uint64
+	for i := range milestones {
+		if milestones[i].ShareBps == 0 {
+			return 0, fmt.Errorf("milestone %d has no share", i)
+		}
+		total += milestones[i].ShareBps
+		milestones[i] = CrowdfundMilestone{Description: milestones[i].Description, ShareBps: milestones[i].ShareBps}
+	}
+	if len(milestones) > 0 && total != 10_000 {
+		return 0, fmt.Errorf("milestone shares sum to %d bps, want 10000", total)
+	}
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	var seq uint64
+	_ = dm.load([]byte(cfSeqKey), &seq)
+	seq++
+	cf := Crowdfund{
+		ID:          seq,
+		Title:       title,
+		Creator:     creator,
+		Beneficiary: beneficiary,
+		Goal:        goal,
+		Deadline:    deadline.Unix(),
+		Milestones:  milestones,
+		Status:      CrowdfundOpen,
+		Created:     time.Now().Unix(),
+	}
+	if err := dm.store(cfKey(seq), cf); err != nil {
+		return 0, err
+	}
+	return seq, dm.store([]byte(cfSeqKey), seq)
+}
+
+// Contribute escrows amount from a backer until the campaign is finalised.
+func (dm *DeFiManager) Contribute(id uint64, from Address, amount uint64) error {
+	if amount == 0 {
+		return errors.New("amount zero")
+	}
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	cf, err := dm.loadCrowdfund(id)
+	if err != nil {
+		return err
+	}
+	if cf.Status != CrowdfundOpen || time.Now().Unix() >= cf.Deadline {
+		return errors.New("crowdfund closed")
+	}
+	if err := dm.ledger.Transfer(from, crowdfundAccount(), amount); err != nil {
+		return err
+	}
+	c := CrowdfundContribution{Backer: from}
+	if dm.load(cfContribKey(id, from), &c) != nil {
+		cf.Backers++
+	}
+	c.Amount += amount
+	cf.Raised += amount
+	if err := dm.store(cfContribKey(id, from), c); err != nil {
+		return err
+	}
+	return dm.store(cfKey(id), cf)
+}
+
+// FinalizeCrowdfund settles an open campaign after its deadline, or earlier
+// once the goal is met. A missed goal refunds every backer; a met goal
+// releases the funds, or marks the campaign funded when milestones govern
+// the release.
+func (dm *DeFiManager) FinalizeCrowdfund(id uint64) (CrowdfundStatus, error) {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	cf, err := dm.loadCrowdfund(id)
+	if err != nil {
+		return "", err
+	}
+	if cf.Status != CrowdfundOpen {
+		return cf.Status, fmt.Errorf("crowdfund already %s", cf.Status)
+	}
+	met := cf.Raised >= cf.Goal
+	if !met && time.Now().Unix() < cf.Deadline {
+		return cf.Status, errors.New("goal not met before the deadline")
+	}
+	switch {
+	case !met:
+		err = dm.refundCrowdfund(&cf)
+	case len(cf.Milestones) == 0:
+		err = dm.releaseCrowdfund(&cf, cf.Raised)
+		cf.Status = CrowdfundCompleted
+	default:
+		cf.Status = CrowdfundFunded
+	}
+	if err != nil {
+		return "", err
+	}
+	return cf.Status, dm.store(cfKey(id), cf)
+}
+
+func (dm *DeFiManager) releaseCrowdfund(cf *Crowdfund, amt uint64) error {
+	if err := dm.ledger.Transfer(crowdfundAccount(), cf.Beneficiary, amt); err != nil {
+		return err
+	}
+	cf.Released += amt
+	return nil
+}
+
+// refundCrowdfund returns the unreleased escrow to backers pro rata to
+// their contributions and fails the campaign. Rounding dust goes to the
+// last backer so the escrow is emptied.
+func (dm *DeFiManager) refundCrowdfund(cf *Crowdfund) error {
+	remaining := cf.Raised - cf.Released
+	contribs, err := dm.contributions(cf.ID)
+	if err != nil {
+		return err
+	}
+	left := remaining
+	for i, c := range contribs {
+		share := mulDivU64(c.Amount, remaining, cf.Raised).Uint64()
+		if i == len(contribs)-1 {
+			share = left
+		}
+		if share > 0 {
+			if err := dm.ledger.Transfer(crowdfundAccount(), c.Backer, share); err != nil {
+				return err
+			}
+		}
+		left -= share
+		c.Refunded = share
+		if err := dm.store(cfContribKey(cf.ID, c.Backer), c); err != nil {
+			return err
+		}
+	}
+	cf.Refunded = remaining
+	cf.Status = CrowdfundFailed
+	return nil
+}
+
+// RequestMilestone opens the contributor vote on the next unreleased
+// milestone. Only the creator may request a release.
+func (dm *DeFiManager) RequestMilestone(id uint64, creator Address) (int, error) {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	cf, err := dm.loadCrowdfund(id)
+	if err != nil {
+		return 0, err
+	}
+	if cf.Creator != creator {
+		return 0, ErrUnauthorized
+	}
+	if cf.Status != CrowdfundFunded {
+		return 0, fmt.Errorf("crowdfund is %s", cf.Status)
+	}
+	idx := nextMilestone(cf)
+	m := &cf.Milestones[idx]
+	if m.VoteEnds != 0 {
+		return idx, errors.New("milestone vote already open")
+	}
+	m.VoteEnds = time.Now().Add(CrowdfundVotePeriod).Unix()
+	m.Yes, m.No, m.Voters = 0, 0, map[string]bool{}
+	m.Attempts++
+	return idx, dm.store(cfKey(id), cf)
+}
+
+func nextMilestone(cf Crowdfund) int {
+	for i, m := range cf.Milestones {
+		if !m.Released {
+			return i
+		}
+	}
+	return len(cf.Milestones) - 1
+}
+
+// VoteMilestone records a backer's vote on the open milestone, weighted by
+// their contribution.
+func (dm *DeFiManager) VoteMilestone(id uint64, backer Address, approve bool) error {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	cf, err := dm.loadCrowdfund(id)
+	if err != nil {
+		return err
+	}
+	if cf.Status != CrowdfundFunded {
+		return fmt.Errorf("crowdfund is %s", cf.Status)
+	}
+	m := &cf.Milestones[nextMilestone(cf)]
+	if m.VoteEnds == 0 || time.Now().Unix() >= m.VoteEnds {
+		return errors.New("no milestone vote open")
+	}
+	var c CrowdfundContribution
+	if err := dm.load(cfContribKey(id, backer), &c); err != nil {
+		return ErrUnauthorized
+	}
+	if _, ok := m.Voters[backer.Hex()]; ok {
+		return errors.New("already voted")
+	}
+	if m.Voters == nil {
+		m.Voters = map[string]bool{}
+	}
+	m.Voters[backer.Hex()] = approve
+	if approve {
+		m.Yes += c.Amount
+	} else {
+		m.No += c.Amount
+	}
+	return dm.store(cfKey(id), cf)
+}
+
+// CloseMilestone tallies the open milestone vote once the period ends, or
+// as soon as approvals pass half the amount raised. An approved milestone
+// releases its tranche; the last tranche takes any rounding remainder. A
+// rejection reopens the milestone for another request until its attempts
+// run out, after which the campaign fails and is refunded.
+func (dm *DeFiManager) CloseMilestone(id uint64) (bool, error) {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	cf, err := dm.loadCrowdfund(id)
+	if err != nil {
+		return false, err
+	}
+	if cf.Status != CrowdfundFunded {
+		return false, fmt.Errorf("crowdfund is %s", cf.Status)
+	}
+	idx := nextMilestone(cf)
+	m := &cf.Milestones[idx]
+	if m.VoteEnds == 0 {
+		return false, errors.New("no milestone vote open")
+	}
+	majority := m.Yes*2 > cf.Raised
+	if !majority && time.Now().Unix() < m.VoteEnds {
+		return false, errors.New("vote still open")
+	}
+	quorum := m.Yes+m.No >= mulDivU64(cf.Raised, CrowdfundQuorumBps, 10_000).Uint64()
+	approved := majority || (quorum && m.Yes > m.No)
+	m.VoteEnds = 0
+	switch {
+	case approved:
+		amt := mulDivU64(cf.Raised, m.ShareBps, 10_000).Uint64()
+		if idx == len(cf.Milestones)-1 {
+			amt = cf.Raised - cf.Released
+		}
+		if err := dm.releaseCrowdfund(&cf, amt); err != nil {
+			return false, err
+		}
+		m.Released, m.Amount = true, amt
+		if idx == len(cf.Milestones)-1 {
+			cf.Status = CrowdfundCompleted
+		}
+	case m.Attempts >= CrowdfundMilestoneAttempts:
+		if err := dm.refundCrowdfund(&cf); err != nil {
+			return false, err
+		}
+	}
+	return approved, dm.store(cfKey(id), cf)
+}
+
+// CrowdfundOf returns a campaign with its contributions.
+func (dm *DeFiManager) CrowdfundOf(id uint64) (Crowdfund, []CrowdfundContribution, error) {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	cf, err := dm.loadCrowdfund(id)
+	if err != nil {
+		return cf, nil, err
+	}
+	contribs, err := dm.contributions(id)
+	return cf, contribs, err
+}
+
+// Crowdfunds lists every campaign ordered by ID.
+func (dm *DeFiManager) Crowdfunds() ([]Crowdfund, error) {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	it := dm.ledger.PrefixIterator([]byte(cfPrefix))
+	var out []Crowdfund
+	for it.Next() {
+		var cf Crowdfund
+		if err := json.Unmarshal(it.Value(), &cf); err != nil {
+			return nil, err
+		}
+		out = append(out, cf)
+	}
+	if err := it.Error(); err != nil {
+		return nil, err
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
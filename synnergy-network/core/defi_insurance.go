@@ -0,0 +1,717 @@
+package core
+
+// defi_insurance.go – underwritten insurance pools with assessed claims.
+//
+// Underwriters deposit native coin into a capital pool for pool shares.
+// Cover bought against the pool locks part of that capital until the policy
+// (plus the claim grace period) expires. Premiums are priced by utilisation
+// with a kinked rate model and are added to the pool capital, so they accrue
+// to underwriters through the share price; approved claims are paid from the
+// capital, so underwriters bear the losses the same way.
+//
+//	rate (annual bps) = BaseRateBps + SlopeBps·min(u, KinkBps) + JumpBps·max(u-KinkBps, 0)
+//	premium           = cover · rate · duration / year
+//
+// where u is the utilisation after the purchase.
+//
+// Claims are assessed by the pool's assessors, who stake separately. During
+// the voting period assessors vote with their stake; the claim is approved
+// when a quorum of assessor stake voted and approvals outweigh rejections.
+// Within the appeal window the policy holder (against a rejection) or an
+// underwriter (against an approval) may appeal, which hands the claim to a
+// panel of authority nodes whose majority is final. On settlement the claim
+// is paid or rejected and assessors who voted against the final outcome
+// lose InsuranceSlashBps of their stake to the pool capital.
+//
+// While a pool has open claims neither underwriters nor assessors can
+// withdraw, so the capital and votes backing a claim stay in place.
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	insPoolPrefix     = "ins:pool:"
+	insPolicyPrefix   = "ins:pol:"
+	insClaimPrefix    = "ins:claim:"
+	insUWPrefix       = "ins:uw:"
+	insAssessorPrefix = "ins:as:"
+	insPoolSeqKey     = "ins:seq"
+
+	InsuranceVotingPeriod = 72 * time.Hour
+	InsuranceAppealWindow = 48 * time.Hour
+	InsuranceClaimGrace   = 7 * 24 * time.Hour
+	InsuranceQuorumBps    = 2_000 // of assessor stake
+	InsuranceSlashBps     = 500
+	InsuranceAppealPanel  = 5
+
+	insYear = 365 * 24 * 60 * 60
+)
+
+// InsuranceClaimStatus is the stage of a claim.
+type InsuranceClaimStatus string
+
+const (
+	ClaimVoting   InsuranceClaimStatus = "voting"
+	ClaimDecided  InsuranceClaimStatus = "decided"
+	ClaimAppealed InsuranceClaimStatus = "appealed"
+	ClaimPaid     InsuranceClaimStatus = "paid"
+	ClaimRejected InsuranceClaimStatus = "rejected"
+)
+
+var (
+	ErrInsuranceOpenClaims = errors.New("pool has open claims")
+	ErrInsuranceCapacity   = errors.New("insufficient pool capacity")
+)
+
+// InsuranceRateModel prices cover by utilisation; all values are bps.
+type InsuranceRateModel struct {
+	BaseRateBps uint64 `json:"base_rate_bps"`
+	SlopeBps    uint64 `json:"slope_bps"`
+	KinkBps     uint64 `json:"kink_bps"`
+	JumpBps     uint64 `json:"jump_bps"`
+}
+
+// Validate checks the model.
+func (m InsuranceRateModel) Validate() error {
+	if m.KinkBps == 0 || m.KinkBps > 10_000 {
+		return errors.New("kink must be 1-10000 bps")
+	}
+	if m.BaseRateBps+m.SlopeBps+m.JumpBps == 0 {
+		return errors.New("rate model charges nothing")
+	}
+	return nil
+}
+
+// AnnualRateBps returns the annual premium rate at utilisation u (bps).
+func (m InsuranceRateModel) AnnualRateBps(u uint64) uint64 {
+	r := m.BaseRateBps + m.SlopeBps*min(u, m.KinkBps)/10_000
+	if u > m.KinkBps {
+		r += m.JumpBps * (u - m.KinkBps) / 10_000
+	}
+	return r
+}
+
+// InsurancePool is a capital pool backing cover products.
+type InsurancePool struct {
+	ID            uint64             `json:"id"`
+	Name          string             `json:"name"`
+	Rates         InsuranceRateModel `json:"rates"`
+	Capital       uint64             `json:"capital"`
+	TotalShares   uint64             `json:"total_shares"`
+	ActiveCover   uint64             `json:"active_cover"`
+	AssessorStake uint64             `json:"assessor_stake"`
+	OpenClaims    int                `json:"open_claims"`
+	Premiums      uint64             `json:"premiums"`
+	Payouts       uint64             `json:"payouts"`
+	Slashed       uint64             `json:"slashed"`
+	Created       int64              `json:"created"`
+}
+
+// Utilisation returns ActiveCover/Capital in bps.
+func (p InsurancePool) Utilisation() uint64 {
+	if p.Capital == 0 {
+		return 0
+	}
+	return mulDivU64(p.ActiveCover, 10_000, p.Capital).Uint64()
+}
+
+// InsurancePolicy is cover bought from a pool.
+type InsurancePolicy struct {
+	ID      Hash    `json:"id"`
+	Pool    uint64  `json:"pool"`
+	Holder  Address `json:"holder"`
+	Cover   uint64  `json:"cover"`
+	Premium uint64  `json:"premium"`
+	Start   int64   `json:"start"`
+	Expiry  int64   `json:"expiry"`
+	Active  bool    `json:"active"` // cover still locked
+	Claimed bool    `json:"claimed"`
+}
+
+// InsuranceClaim is a claim against a policy. Votes and panel votes are
+// keyed by hex address.
+type InsuranceClaim struct {
+	Policy     Hash                 `json:"policy"`
+	Pool       uint64               `json:"pool"`
+	Amount     uint64               `json:"amount"`
+	Evidence   string               `json:"evidence"`
+	Filed      int64                `json:"filed"`
+	VotingEnds int64                `json:"voting_ends"`
+	Votes      map[string]bool      `json:"votes"`
+	Weights    map[string]uint64    `json:"weights"`
+	Yes        uint64               `json:"yes"`
+	No         uint64               `json:"no"`
+	Approved   bool                 `json:"approved"`
+	AppealEnds int64                `json:"appeal_ends,omitempty"`
+	Appellant  *Address             `json:"appellant,omitempty"`
+	Panel      []Address            `json:"panel,omitempty"`
+	PanelVotes map[string]bool      `json:"panel_votes,omitempty"`
+	Status     InsuranceClaimStatus `json:"status"`
+	Paid       uint64               `json:"paid"`
+}
+
+func insuranceAccount() Address { return ModuleAddress("insurance") }
+
+func insPoolKey(id uint64) []byte { return []byte(fmt.Sprintf("%s%010d", insPoolPrefix, id)) }
+func insPolicyKey(id Hash) []byte { return append([]byte(insPolicyPrefix), id[:]...) }
+func insClaimKey(id Hash) []byte  { return append([]byte(insClaimPrefix), id[:]...) }
+func insUWKey(pool uint64, a Address) []byte {
+	return []byte(fmt.Sprintf("%s%010d:%s", insUWPrefix, pool, a.String()))
+}
+func insAssessorKey(pool uint64, a Address) []byte {
+	return []byte(fmt.Sprintf("%s%010d:%s", insAssessorPrefix, pool, a.String()))
+}
+
+func (dm *DeFiManager) loadUint(key []byte) uint64 {
+	var v uint64
+	_ = dm.load(key, &v)
+	return v
+}
+
+func (dm *DeFiManager) storeUint(key []byte, v uint64) error {
+	if v == 0 {
+		return dm.ledger.DeleteState(key)
+	}
+	return dm.store(key, v)
+}
+
+func (dm *DeFiManager) loadInsPool(id uint64) (InsurancePool, error) {
+	var p InsurancePool
+	if err := dm.load(insPoolKey(id), &p); err != nil {
+		return p, fmt.Errorf("insurance pool %d not found", id)
+	}
+	return p, nil
+}
+
+func (dm *DeFiManager) loadPolicy(id Hash) (InsurancePolicy, error) {
+	var p InsurancePolicy
+	if err := dm.load(insPolicyKey(id), &p); err != nil {
+		return p, fmt.Errorf("policy %x not found", id[:8])
+	}
+	return p, nil
+}
+
+func (dm *DeFiManager) loadClaim(id Hash) (InsuranceClaim, error) {
+	var c InsuranceClaim
+	if err := dm.load(insClaimKey(id), &c); err != nil {
+		return c, fmt.Errorf("no claim on policy %x", id[:8])
+	}
+	return c, nil
+}
+
+// CreateInsurancePool opens a capital pool with the given rate model.
+func (dm *DeFiManager) CreateInsurancePool(name string, rates InsuranceRateModel) (uint64, error) {
+	if strings.TrimSpace(name) == "" {
+		return 0, errors.New("name required")
+	}
+	if err := rates.Validate(); err != nil {
+		return 0, err
+	}
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	id := dm.loadUint([]byte(insPoolSeqKey)) + 1
+	p := InsurancePool{ID: id, Name: name, Rates: rates, Created: time.Now().Unix()}
+	if err := dm.store(insPoolKey(id), p); err != nil {
+		return 0, err
+	}
+	return id, dm.store([]byte(insPoolSeqKey), id)
+}
+
+// InsurancePools lists every pool ordered by ID.
+func (dm *DeFiManager) InsurancePools() ([]InsurancePool, error) {
+	it := dm.ledger.PrefixIterator([]byte(insPoolPrefix))
+	var out []InsurancePool
+	for it.Next() {
+		var p InsurancePool
+		if err := json.Unmarshal(it.Value(), &p); err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	if err := it.Error(); err != nil {
+		return nil, err
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
+
+// expirePolicies releases the cover of policies past expiry and grace.
+func (dm *DeFiManager) expirePolicies(p *InsurancePool, now int64) error {
+	it := dm.ledger.PrefixIterator([]byte(insPolicyPrefix))
+	for it.Next() {
+		var pol InsurancePolicy
+		if err := json.Unmarshal(it.Value(), &pol); err != nil {
+			return err
+		}
+		if pol.Pool != p.ID || !pol.Active || pol.Claimed || now < pol.Expiry+int64(InsuranceClaimGrace/time.Second) {
+			continue
+		}
+		pol.Active = false
+		p.ActiveCover -= min(pol.Cover, p.ActiveCover)
+		if err := dm.store(insPolicyKey(pol.ID), pol); err != nil {
+			return err
+		}
+	}
+	return it.Error()
+}
+
+// Underwrite deposits capital into a pool and returns the shares issued.
+func (dm *DeFiManager) Underwrite(pool uint64, from Address, amount uint64) (uint64, error) {
+	if amount == 0 {
+		return 0, errors.New("amount zero")
+	}
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	p, err := dm.loadInsPool(pool)
+	if err != nil {
+		return 0, err
+	}
+	shares := amount
+	if p.TotalShares > 0 {
+		if p.Capital == 0 {
+			return 0, errors.New("pool capital exhausted")
+		}
+		shares = mulDivU64(amount, p.TotalShares, p.Capital).Uint64()
+	}
+	if err := dm.ledger.Transfer(from, insuranceAccount(), amount); err != nil {
+		return 0, err
+	}
+	p.Capital += amount
+	p.TotalShares += shares
+	if err := dm.storeUint(insUWKey(pool, from), dm.loadUint(insUWKey(pool, from))+shares); err != nil {
+		return 0, err
+	}
+	return shares, dm.store(insPoolKey(pool), p)
+}
+
+// WithdrawCapital redeems pool shares for their share of capital, as long as
+// the pool has no open claims and the remaining capital covers active cover.
+func (dm *DeFiManager) WithdrawCapital(pool uint64, to Address, shares uint64) (uint64, error) {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	p, err := dm.loadInsPool(pool)
+	if err != nil {
+		return 0, err
+	}
+	held := dm.loadUint(insUWKey(pool, to))
+	if shares == 0 || shares > held {
+		return 0, errors.New("invalid shares")
+	}
+	if p.OpenClaims > 0 {
+		return 0, ErrInsuranceOpenClaims
+	}
+	if err := dm.expirePolicies(&p, time.Now().Unix()); err != nil {
+		return 0, err
+	}
+	amount := mulDivU64(shares, p.Capital, p.TotalShares).Uint64()
+	if p.Capital-amount < p.ActiveCover {
+		return 0, fmt.Errorf("%w: %d of capital backs active cover", ErrInsuranceCapacity, p.ActiveCover)
+	}
+	if err := dm.ledger.Transfer(insuranceAccount(), to, amount); err != nil {
+		return 0, err
+	}
+	p.Capital -= amount
+	p.TotalShares -= shares
+	if err := dm.storeUint(insUWKey(pool, to), held-shares); err != nil {
+		return 0, err
+	}
+	return amount, dm.store(insPoolKey(pool), p)
+}
+
+// StakeAssessor stakes coin to assess the pool's claims.
+func (dm *DeFiManager) StakeAssessor(pool uint64, from Address, amount uint64) error {
+	if amount == 0 {
+		return errors.New("amount zero")
+	}
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	p, err := dm.loadInsPool(pool)
+	if err != nil {
+		return err
+	}
+	if err := dm.ledger.Transfer(from, insuranceAccount(), amount); err != nil {
+		return err
+	}
+	p.AssessorStake += amount
+	if err := dm.storeUint(insAssessorKey(pool, from), dm.loadUint(insAssessorKey(pool, from))+amount); err != nil {
+		return err
+	}
+	return dm.store(insPoolKey(pool), p)
+}
+
+// UnstakeAssessor withdraws assessor stake while no claims are open.
+func (dm *DeFiManager) UnstakeAssessor(pool uint64, to Address, amount uint64) error {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	p, err := dm.loadInsPool(pool)
+	if err != nil {
+		return err
+	}
+	held := dm.loadUint(insAssessorKey(pool, to))
+	if amount == 0 || amount > held {
+		return errors.New("invalid amount")
+	}
+	if p.OpenClaims > 0 {
+		return ErrInsuranceOpenClaims
+	}
+	if err := dm.ledger.Transfer(insuranceAccount(), to, amount); err != nil {
+		return err
+	}
+	p.AssessorStake -= amount
+	if err := dm.storeUint(insAssessorKey(pool, to), held-amount); err != nil {
+		return err
+	}
+	return dm.store(insPoolKey(pool), p)
+}
+
+// QuotePremium prices cover for duration at the pool's utilisation after
+// the purchase.
+func (dm *DeFiManager) QuotePremium(pool, cover uint64, duration time.Duration) (uint64, error) {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	p, err := dm.loadInsPool(pool)
+	if err != nil {
+		return 0, err
+	}
+	if err := dm.expirePolicies(&p, time.Now().Unix()); err != nil {
+		return 0, err
+	}
+	return p.premium(cover, duration)
+}
+
+func (p InsurancePool) premium(cover uint64, duration time.Duration) (uint64, error) {
+	if cover == 0 || duration <= 0 {
+		return 0, errors.New("cover and duration required")
+	}
+	if p.Capital == 0 || p.ActiveCover+cover > p.Capital {
+		return 0, fmt.Errorf("%w: %d available", ErrInsuranceCapacity, p.Capital-min(p.ActiveCover, p.Capital))
+	}
+	u := mulDivU64(p.ActiveCover+cover, 10_000, p.Capital).Uint64()
+	prem := mulDivU64(cover, p.Rates.AnnualRateBps(u)*uint64(duration/time.Second), 10_000)
+	prem.Add(prem, u64(insYear-1)).Div(prem, u64(insYear))
+	return prem.Uint64(), nil
+}
+
+// CreateInsurance buys cover from a pool for holder, paying at most
+// maxPremium, and returns the premium charged.
+func (dm *DeFiManager) CreateInsurance(id Hash, pool uint64, holder Address, cover uint64, duration time.Duration, maxPremium uint64) (uint64, error) {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	if ok, _ := dm.ledger.HasState(insPolicyKey(id)); ok {
+		return 0, fmt.Errorf("exists")
+	}
+	p, err := dm.loadInsPool(pool)
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now()
+	if err := dm.expirePolicies(&p, now.Unix()); err != nil {
+		return 0, err
+	}
+	prem, err := p.premium(cover, duration)
+	if err != nil {
+		return 0, err
+	}
+	if prem > maxPremium {
+		return 0, fmt.Errorf("slippage: premium %d exceeds max %d", prem, maxPremium)
+	}
+	if err := dm.ledger.Transfer(holder, insuranceAccount(), prem); err != nil {
+		return 0, err
+	}
+	p.Capital += prem
+	p.Premiums += prem
+	p.ActiveCover += cover
+	pol := InsurancePolicy{
+		ID:      id,
+		Pool:    pool,
+		Holder:  holder,
+		Cover:   cover,
+		Premium: prem,
+		Start:   now.Unix(),
+		Expiry:  now.Add(duration).Unix(),
+		Active:  true,
+	}
+	if err := dm.store(insPolicyKey(id), pol); err != nil {
+		return 0, err
+	}
+	return prem, dm.store(insPoolKey(pool), p)
+}
+
+// ClaimInsurance files a claim for amount against the holder's policy and
+// opens assessor voting.
+func (dm *DeFiManager) ClaimInsurance(id Hash, claimant Address, amount uint64, evidence string) error {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	pol, err := dm.loadPolicy(id)
+	if err != nil {
+		return err
+	}
+	if pol.Holder != claimant {
+		return ErrUnauthorized
+	}
+	now := time.Now()
+	switch {
+	case !pol.Active || pol.Claimed:
+		return errors.New("policy not claimable")
+	case now.Unix() > pol.Expiry+int64(InsuranceClaimGrace/time.Second):
+		return errors.New("claim period over")
+	case amount == 0 || amount > pol.Cover:
+		return fmt.Errorf("claim must be 1-%d", pol.Cover)
+	}
+	if ok, _ := dm.ledger.HasState(insClaimKey(id)); ok {
+		return fmt.Errorf("claim already filed")
+	}
+	p, err := dm.loadInsPool(pol.Pool)
+	if err != nil {
+		return err
+	}
+	pol.Claimed = true
+	p.OpenClaims++
+	c := InsuranceClaim{
+		Policy:     id,
+		Pool:       pol.Pool,
+		Amount:     amount,
+		Evidence:   evidence,
+		Filed:      now.Unix(),
+		VotingEnds: now.Add(InsuranceVotingPeriod).Unix(),
+		Votes:      map[string]bool{},
+		Weights:    map[string]uint64{},
+		Status:     ClaimVoting,
+	}
+	if err := dm.store(insPolicyKey(id), pol); err != nil {
+		return err
+	}
+	if err := dm.store(insClaimKey(id), c); err != nil {
+		return err
+	}
+	return dm.store(insPoolKey(pol.Pool), p)
+}
+
+// AssessClaim records an assessor's stake-weighted vote.
+func (dm *DeFiManager) AssessClaim(id Hash, assessor Address, approve bool) error {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	c, err := dm.loadClaim(id)
+	if err != nil {
+		return err
+	}
+	if c.Status != ClaimVoting || time.Now().Unix() >= c.VotingEnds {
+		return errors.New("voting closed")
+	}
+	stake := dm.loadUint(insAssessorKey(c.Pool, assessor))
+	if stake == 0 {
+		return ErrUnauthorized
+	}
+	if _, ok := c.Votes[assessor.Hex()]; ok {
+		return fmt.Errorf("already voted")
+	}
+	c.Votes[assessor.Hex()] = approve
+	c.Weights[assessor.Hex()] = stake
+	if approve {
+		c.Yes += stake
+	} else {
+		c.No += stake
+	}
+	return dm.store(insClaimKey(id), c)
+}
+
+// DecideClaim closes assessor voting and opens the appeal window. Without
+// a quorum the claim is rejected.
+func (dm *DeFiManager) DecideClaim(id Hash) (bool, error) {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	c, err := dm.loadClaim(id)
+	if err != nil {
+		return false, err
+	}
+	now := time.Now()
+	if c.Status != ClaimVoting {
+		return false, fmt.Errorf("claim is %s", c.Status)
+	}
+	if now.Unix() < c.VotingEnds {
+		return false, errors.New("voting still open")
+	}
+	p, err := dm.loadInsPool(c.Pool)
+	if err != nil {
+		return false, err
+	}
+	quorum := c.Yes+c.No >= mulDivU64(p.AssessorStake, InsuranceQuorumBps, 10_000).Uint64() && c.Yes+c.No > 0
+	c.Approved = quorum && c.Yes > c.No
+	c.Status = ClaimDecided
+	c.AppealEnds = now.Add(InsuranceAppealWindow).Unix()
+	return c.Approved, dm.store(insClaimKey(id), c)
+}
+
+// AppealClaim hands a decided claim to an authority panel. The holder may
+// appeal a rejection and an underwriter of the pool an approval.
+func (dm *DeFiManager) AppealClaim(id Hash, appellant Address) ([]Address, error) {
+	auth := CurrentAuthoritySet()
+	if auth == nil {
+		return nil, errors.New("authority set not initialised")
+	}
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	c, err := dm.loadClaim(id)
+	if err != nil {
+		return nil, err
+	}
+	if c.Status != ClaimDecided || time.Now().Unix() >= c.AppealEnds {
+		return nil, errors.New("claim not open for appeal")
+	}
+	pol, err := dm.loadPolicy(id)
+	if err != nil {
+		return nil, err
+	}
+	if c.Approved && dm.loadUint(insUWKey(c.Pool, appellant)) == 0 {
+		return nil, fmt.Errorf("%w: only underwriters may appeal an approval", ErrUnauthorized)
+	}
+	if !c.Approved && appellant != pol.Holder {
+		return nil, fmt.Errorf("%w: only the holder may appeal a rejection", ErrUnauthorized)
+	}
+	cands, err := auth.RandomElectorate(InsuranceAppealPanel + 2)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range cands {
+		if a != pol.Holder && a != appellant && len(c.Panel) < InsuranceAppealPanel {
+			c.Panel = append(c.Panel, a)
+		}
+	}
+	if len(c.Panel) == 0 {
+		return nil, errors.New("no eligible authority nodes")
+	}
+	c.Appellant = &appellant
+	c.Status = ClaimAppealed
+	return c.Panel, dm.store(insClaimKey(id), c)
+}
+
+// RuleOnAppeal records a panel member's ruling; a panel majority settles
+// the claim.
+func (dm *DeFiManager) RuleOnAppeal(id Hash, member Address, approve bool) error {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	c, err := dm.loadClaim(id)
+	if err != nil {
+		return err
+	}
+	if c.Status != ClaimAppealed {
+		return errors.New("claim not under appeal")
+	}
+	seated := false
+	for _, a := range c.Panel {
+		seated = seated || a == member
+	}
+	if !seated {
+		return ErrUnauthorized
+	}
+	if _, ok := c.PanelVotes[member.Hex()]; ok {
+		return fmt.Errorf("ruling already recorded")
+	}
+	if c.PanelVotes == nil {
+		c.PanelVotes = map[string]bool{}
+	}
+	c.PanelVotes[member.Hex()] = approve
+	yes := 0
+	for _, v := range c.PanelVotes {
+		if v {
+			yes++
+		}
+	}
+	no := len(c.PanelVotes) - yes
+	switch {
+	case yes*2 > len(c.Panel):
+		return dm.settleClaim(&c, true)
+	case no*2 >= len(c.Panel):
+		return dm.settleClaim(&c, false)
+	}
+	return dm.store(insClaimKey(id), c)
+}
+
+// SettleClaim executes the assessors' decision once the appeal window has
+// passed without an appeal.
+func (dm *DeFiManager) SettleClaim(id Hash) error {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	c, err := dm.loadClaim(id)
+	if err != nil {
+		return err
+	}
+	if c.Status != ClaimDecided || time.Now().Unix() < c.AppealEnds {
+		return errors.New("claim not ready to settle")
+	}
+	return dm.settleClaim(&c, c.Approved)
+}
+
+// settleClaim pays or rejects the claim and slashes assessors who voted
+// against the outcome.
+func (dm *DeFiManager) settleClaim(c *InsuranceClaim, approved bool) error {
+	p, err := dm.loadInsPool(c.Pool)
+	if err != nil {
+		return err
+	}
+	pol, err := dm.loadPolicy(c.Policy)
+	if err != nil {
+		return err
+	}
+	for voter, vote := range c.Votes {
+		if vote == approved {
+			continue
+		}
+		addr, err := ParseAddress(strings.TrimPrefix(voter, "0x"))
+		if err != nil {
+			return err
+		}
+		stake := dm.loadUint(insAssessorKey(c.Pool, addr))
+		slash := min(mulDivU64(c.Weights[voter], InsuranceSlashBps, 10_000).Uint64(), stake)
+		if err := dm.storeUint(insAssessorKey(c.Pool, addr), stake-slash); err != nil {
+			return err
+		}
+		p.AssessorStake -= slash
+		p.Capital += slash
+		p.Slashed += slash
+	}
+	if approved {
+		c.Paid = min(c.Amount, p.Capital)
+		if err := dm.ledger.Transfer(insuranceAccount(), pol.Holder, c.Paid); err != nil {
+			return err
+		}
+		p.Capital -= c.Paid
+		p.Payouts += c.Paid
+		p.ActiveCover -= min(pol.Cover, p.ActiveCover)
+		pol.Active = false
+		c.Status = ClaimPaid
+	} else {
+		// the cover stays locked until the policy expires
+		pol.Claimed = false
+		c.Status = ClaimRejected
+	}
+	c.Approved = approved
+	p.OpenClaims--
+	if err := dm.store(insPolicyKey(pol.ID), pol); err != nil {
+		return err
+	}
+	if err := dm.store(insClaimKey(c.Policy), *c); err != nil {
+		return err
+	}
+	return dm.store(insPoolKey(p.ID), p)
+}
+
+// InsuranceClaimOf returns the claim filed against a policy.
+func (dm *DeFiManager) InsuranceClaimOf(id Hash) (InsuranceClaim, error) {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	return dm.loadClaim(id)
+}
+
+// InsurancePolicyOf returns a policy.
+func (dm *DeFiManager) InsurancePolicyOf(id Hash) (InsurancePolicy, error) {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	return dm.loadPolicy(id)
+}
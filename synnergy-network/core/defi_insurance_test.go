@@ -0,0 +1,281 @@
+package core
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+var testInsuranceRates = InsuranceRateModel{BaseRateBps: 200, SlopeBps: 1_000, KinkBps: 8_000, JumpBps: 5_000}
+
+type insuranceFixture struct {
+	dm       *DeFiManager
+	led      *Ledger
+	pool     uint64
+	uw       Address
+	holder   Address
+	policy   Hash
+	premium  uint64
+	yes, no  Address // assessors staking 600 and 400
+	stranger Address
+}
+
+// newInsuranceFixture opens a pool with 10_000 of capital, two assessors
+// and a year of 5_000 cover for the holder.
+func newInsuranceFixture(t *testing.T) *insuranceFixture {
+	t.Helper()
+	cfg, _ := tmpLedgerConfig(t, nil)
+	led, err := NewLedger(cfg)
+	if err != nil {
+		t.Fatalf("ledger: %v", err)
+	}
+	f := &insuranceFixture{
+		dm: NewDeFiManager(led), led: led,
+		uw: Address{0x01}, holder: Address{0x02}, yes: Address{0x03}, no: Address{0x04}, stranger: Address{0x05},
+		policy: Hash{0xaa},
+	}
+	for a, amt := range map[Address]uint64{f.uw: 10_000, f.holder: 1_000, f.yes: 600, f.no: 400} {
+		if err := led.Mint(a, amt); err != nil {
+			t.Fatalf("mint: %v", err)
+		}
+	}
+	if f.pool, err = f.dm.CreateInsurancePool("smart contract cover", testInsuranceRates); err != nil {
+		t.Fatalf("pool: %v", err)
+	}
+	if _, err := f.dm.Underwrite(f.pool, f.uw, 10_000); err != nil {
+		t.Fatalf("underwrite: %v", err)
+	}
+	if err := f.dm.StakeAssessor(f.pool, f.yes, 600); err != nil {
+		t.Fatalf("stake: %v", err)
+	}
+	if err := f.dm.StakeAssessor(f.pool, f.no, 400); err != nil {
+		t.Fatalf("stake: %v", err)
+	}
+	if f.premium, err = f.dm.CreateInsurance(f.policy, f.pool, f.holder, 5_000, 365*24*time.Hour, 1_000); err != nil {
+		t.Fatalf("cover: %v", err)
+	}
+	return f
+}
+
+// expire moves the claim's voting and appeal deadlines into the past.
+func (f *insuranceFixture) expire(t *testing.T, voting, appeal bool) {
+	t.Helper()
+	c, err := f.dm.loadClaim(f.policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	past := time.Now().Add(-time.Second).Unix()
+	if voting {
+		c.VotingEnds = past
+	}
+	if appeal {
+		c.AppealEnds = past
+	}
+	if err := f.dm.store(insClaimKey(f.policy), c); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func (f *insuranceFixture) poolState(t *testing.T) InsurancePool {
+	t.Helper()
+	p, err := f.dm.loadInsPool(f.pool)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestInsurancePremiumAndCapacity(t *testing.T) {
+	f := newInsuranceFixture(t)
+
+	// 5_000 of 10_000 covered: 200 + 1_000·50% = 700 bps a year
+	if f.premium != 350 {
+		t.Fatalf("premium %d, want 350", f.premium)
+	}
+	if got := testInsuranceRates.AnnualRateBps(9_000); got != 200+800+500 {
+		t.Fatalf("rate above the kink = %d", got)
+	}
+	p := f.poolState(t)
+	if p.Capital != 10_350 || p.ActiveCover != 5_000 || p.Premiums != 350 || f.led.BalanceOf(f.holder) != 650 {
+		t.Fatalf("pool %+v holder %d", p, f.led.BalanceOf(f.holder))
+	}
+
+	if _, err := f.dm.CreateInsurance(Hash{0xab}, f.pool, f.holder, 6_000, 24*time.Hour, 1_000); !errors.Is(err, ErrInsuranceCapacity) {
+		t.Fatalf("cover beyond capital: err=%v", err)
+	}
+	quote, err := f.dm.QuotePremium(f.pool, 1_000, 30*24*time.Hour)
+	if err != nil {
+		t.Fatalf("quote: %v", err)
+	}
+	if _, err := f.dm.CreateInsurance(Hash{0xab}, f.pool, f.holder, 1_000, 30*24*time.Hour, quote-1); err == nil {
+		t.Fatal("premium above the holder's maximum charged")
+	}
+
+	if _, err := f.dm.WithdrawCapital(f.pool, f.uw, 10_000); !errors.Is(err, ErrInsuranceCapacity) {
+		t.Fatalf("withdrawing capital that backs cover: err=%v", err)
+	}
+	got, err := f.dm.WithdrawCapital(f.pool, f.uw, 5_000)
+	if err != nil {
+		t.Fatalf("withdraw: %v", err)
+	}
+	// premiums accrue to underwriters through the share price
+	if got != 5_175 || f.led.BalanceOf(f.uw) != 5_175 {
+		t.Fatalf("withdrew %d, balance %d", got, f.led.BalanceOf(f.uw))
+	}
+}
+
+func TestInsuranceApprovedClaimPaysAndSlashesDissent(t *testing.T) {
+	f := newInsuranceFixture(t)
+	if err := f.dm.ClaimInsurance(f.policy, f.stranger, 3_000, "exploit tx"); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("claim by non-holder: err=%v", err)
+	}
+	if err := f.dm.ClaimInsurance(f.policy, f.holder, 5_001, "exploit tx"); err == nil {
+		t.Fatal("claim above cover accepted")
+	}
+	if err := f.dm.ClaimInsurance(f.policy, f.holder, 3_000, "exploit tx"); err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+
+	if err := f.dm.AssessClaim(f.policy, f.stranger, true); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("vote without stake: err=%v", err)
+	}
+	if err := f.dm.AssessClaim(f.policy, f.yes, true); err != nil {
+		t.Fatalf("vote: %v", err)
+	}
+	if err := f.dm.AssessClaim(f.policy, f.yes, true); err == nil {
+		t.Fatal("second vote accepted")
+	}
+	if err := f.dm.AssessClaim(f.policy, f.no, false); err != nil {
+		t.Fatalf("vote: %v", err)
+	}
+
+	// capital and votes stay put while the claim is open
+	if _, err := f.dm.WithdrawCapital(f.pool, f.uw, 1); !errors.Is(err, ErrInsuranceOpenClaims) {
+		t.Fatalf("withdraw during claim: err=%v", err)
+	}
+	if err := f.dm.UnstakeAssessor(f.pool, f.no, 400); !errors.Is(err, ErrInsuranceOpenClaims) {
+		t.Fatalf("unstake during claim: err=%v", err)
+	}
+
+	if _, err := f.dm.DecideClaim(f.policy); err == nil {
+		t.Fatal("claim decided while voting is open")
+	}
+	f.expire(t, true, false)
+	if ok, err := f.dm.DecideClaim(f.policy); err != nil || !ok {
+		t.Fatalf("decide: approved=%v err=%v", ok, err)
+	}
+	if err := f.dm.SettleClaim(f.policy); err == nil {
+		t.Fatal("claim settled inside the appeal window")
+	}
+	f.expire(t, false, true)
+	if err := f.dm.SettleClaim(f.policy); err != nil {
+		t.Fatalf("settle: %v", err)
+	}
+
+	if got := f.led.BalanceOf(f.holder); got != 650+3_000 {
+		t.Fatalf("holder balance %d", got)
+	}
+	p := f.poolState(t)
+	if p.Capital != 10_350-3_000+20 || p.Payouts != 3_000 || p.Slashed != 20 || p.AssessorStake != 980 ||
+		p.ActiveCover != 0 || p.OpenClaims != 0 {
+		t.Fatalf("pool %+v", p)
+	}
+	if f.dm.loadUint(insAssessorKey(f.pool, f.no)) != 380 || f.dm.loadUint(insAssessorKey(f.pool, f.yes)) != 600 {
+		t.Fatal("slash applied to the wrong assessor")
+	}
+	if c, _ := f.dm.InsuranceClaimOf(f.policy); c.Status != ClaimPaid || c.Paid != 3_000 {
+		t.Fatalf("claim %+v", c)
+	}
+	if err := f.dm.UnstakeAssessor(f.pool, f.no, 400); err == nil {
+		t.Fatal("slashed stake withdrawn")
+	}
+	if err := f.dm.UnstakeAssessor(f.pool, f.no, 380); err != nil || f.led.BalanceOf(f.no) != 380 {
+		t.Fatalf("unstake: %v balance %d", err, f.led.BalanceOf(f.no))
+	}
+}
+
+func TestInsuranceClaimWithoutQuorumIsRejected(t *testing.T) {
+	f := newInsuranceFixture(t)
+	if err := f.dm.ClaimInsurance(f.policy, f.holder, 1_000, "outage"); err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+	f.expire(t, true, false)
+	if ok, err := f.dm.DecideClaim(f.policy); err != nil || ok {
+		t.Fatalf("decide without votes: approved=%v err=%v", ok, err)
+	}
+	f.expire(t, false, true)
+	if err := f.dm.SettleClaim(f.policy); err != nil {
+		t.Fatalf("settle: %v", err)
+	}
+	pol, _ := f.dm.InsurancePolicyOf(f.policy)
+	p := f.poolState(t)
+	if !pol.Active || pol.Claimed || p.ActiveCover != 5_000 || p.Capital != 10_350 || p.OpenClaims != 0 {
+		t.Fatalf("rejected claim: policy %+v pool %+v", pol, p)
+	}
+	if f.led.BalanceOf(f.holder) != 650 {
+		t.Fatalf("rejected claim paid out: %d", f.led.BalanceOf(f.holder))
+	}
+}
+
+func TestInsuranceAppealOverturnsRejection(t *testing.T) {
+	f := newInsuranceFixture(t)
+	authState, _ := NewInMemory()
+	prev := globalAuth
+	globalAuth = NewAuthoritySet(nil, authState)
+	t.Cleanup(func() { globalAuth = prev })
+	for _, a := range []Address{{0xe1}, {0xe2}, {0xe3}} {
+		n := AuthorityNode{Addr: a, Wallet: a, Role: GovernmentNode, Active: true}
+		if err := authState.SetState(nodeKey(a), mustJSON(n)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := f.dm.ClaimInsurance(f.policy, f.holder, 2_000, "oracle failure"); err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+	if err := f.dm.AssessClaim(f.policy, f.no, false); err != nil {
+		t.Fatalf("vote: %v", err)
+	}
+	f.expire(t, true, false)
+	if ok, err := f.dm.DecideClaim(f.policy); err != nil || ok {
+		t.Fatalf("decide: approved=%v err=%v", ok, err)
+	}
+
+	if _, err := f.dm.AppealClaim(f.policy, f.uw); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("underwriter appealing a rejection: err=%v", err)
+	}
+	panel, err := f.dm.AppealClaim(f.policy, f.holder)
+	if err != nil {
+		t.Fatalf("appeal: %v", err)
+	}
+	if len(panel) != 3 {
+		t.Fatalf("panel %x", panel)
+	}
+	if err := f.dm.SettleClaim(f.policy); err == nil {
+		t.Fatal("appealed claim settled on the assessors' decision")
+	}
+	if err := f.dm.RuleOnAppeal(f.policy, f.stranger, true); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("ruling by non-member: err=%v", err)
+	}
+	if err := f.dm.RuleOnAppeal(f.policy, panel[0], true); err != nil {
+		t.Fatalf("rule: %v", err)
+	}
+	if err := f.dm.RuleOnAppeal(f.policy, panel[0], true); err == nil {
+		t.Fatal("second ruling by the same member accepted")
+	}
+	if err := f.dm.RuleOnAppeal(f.policy, panel[1], true); err != nil {
+		t.Fatalf("rule: %v", err)
+	}
+
+	c, _ := f.dm.InsuranceClaimOf(f.policy)
+	if c.Status != ClaimPaid || !c.Approved || f.led.BalanceOf(f.holder) != 650+2_000 {
+		t.Fatalf("claim %+v holder %d", c, f.led.BalanceOf(f.holder))
+	}
+	// the rejecting assessor voted against the final outcome
+	if got := f.dm.loadUint(insAssessorKey(f.pool, f.no)); got != 380 {
+		t.Fatalf("dissenting assessor stake %d", got)
+	}
+	if err := f.dm.RuleOnAppeal(f.policy, panel[2], false); err == nil {
+		t.Fatal("ruling accepted after settlement")
+	}
+}
@@ -0,0 +1,470 @@
+package core
+
+// defi_prediction.go – LMSR prediction markets with oracle resolution.
+//
+// Each market trades outcome shares against Hanson's logarithmic market
+// scoring rule. The market maker's cost function is
+//
+//	C(q) = b·ln Σ exp(q_i / b)
+//
+// and buying Δ shares of outcome i costs C(q + Δ·e_i) - C(q); the price of
+// an outcome is its softmax weight, so prices always sum to one. The
+// liquidity parameter b is fixed at creation and the creator funds the
+// maker's worst-case loss b·ln(n) up front. Outcome shares are ledger
+// tokens ("pm:<market id prefix>:<outcome>") so they can be transferred
+// between holders; a winning share redeems for one coin.
+//
+// Markets resolve from the oracle module once ResolveAfter has passed:
+//
+//   - PredictionPriceAbove markets are binary and read an OraclePrice;
+//     outcome 0 (yes) wins when the price is at or above Strike.
+//   - PredictionOracleOutcome markets read the winning outcome index as a
+//     decimal string.
+//
+// If the oracle cannot resolve the market by FallbackAfter, governance may
+// settle it with the prediction_resolve parameter, either naming the winner
+// or voiding the market, in which case every share redeems at the last
+// market price of its outcome. After resolution the creator reclaims
+// whatever the pool holds beyond what the outstanding shares can redeem.
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	predictionPrefix      = "pm:market:"
+	predictionMaxOutcomes = 16
+	predictionVoid        = -1
+	// defaultPredictionFallback is how long after ResolveAfter governance
+	// waits for the oracle before it may settle a market.
+	defaultPredictionFallback = 72 * time.Hour
+)
+
+// PredictionResolution selects how a market reads its oracle.
+type PredictionResolution string
+
+const (
+	PredictionPriceAbove    PredictionResolution = "price_above"
+	PredictionOracleOutcome PredictionResolution = "oracle_outcome"
+)
+
+var (
+	ErrPredictionClosed     = errors.New("prediction market closed")
+	ErrPredictionUnresolved = errors.New("prediction market not resolved")
+)
+
+// PredictionMarket is an LMSR market over a fixed set of outcomes.
+type PredictionMarket struct {
+	ID            Hash                 `json:"id"`
+	Creator       Address              `json:"creator"`
+	Question      string               `json:"question"`
+	Outcomes      []string             `json:"outcomes"`
+	Liquidity     uint64               `json:"liquidity"` // LMSR b
+	Shares        []uint64             `json:"shares"`    // outstanding q_i
+	Collected     uint64               `json:"collected"` // coins held by the market
+	OracleID      string               `json:"oracle_id"`
+	Resolution    PredictionResolution `json:"resolution"`
+	Strike        int64                `json:"strike,omitempty"` // OraclePriceScale units
+	ResolveAfter  int64                `json:"resolve_after"`
+	FallbackAfter int64                `json:"fallback_after"`
+	Resolved      bool                 `json:"resolved"`
+	Winner        int                  `json:"winner"` // predictionVoid when voided
+	FinalPrices   []float64            `json:"final_prices,omitempty"`
+	Created       int64                `json:"created"`
+}
+
+// PredictionSpec holds the creation parameters of a market.
+type PredictionSpec struct {
+	Question     string
+	Outcomes     []string
+	Liquidity    uint64
+	OracleID     string
+	Resolution   PredictionResolution
+	Strike       int64
+	ResolveAfter time.Time
+	// FallbackAfter defaults to ResolveAfter plus 72 hours.
+	FallbackAfter time.Time
+}
+
+func predictionAccount() Address { return ModuleAddress("prediction") }
+
+func predictionKey(id Hash) []byte { return append([]byte(predictionPrefix), id[:]...) }
+
+func predictionShareToken(id Hash, outcome int) string {
+	return fmt.Sprintf("pm:%x:%d", id[:8], outcome)
+}
+
+// lmsrCost evaluates C(q) with the log-sum-exp shift so large q/b cannot
+// overflow.
+func lmsrCost(q []uint64, b uint64) float64 {
+	bf := float64(b)
+	m := 0.0
+	for _, v := range q {
+		m = math.Max(m, float64(v))
+	}
+	sum := 0.0
+	for _, v := range q {
+		sum += math.Exp((float64(v) - m) / bf)
+	}
+	return m + bf*math.Log(sum)
+}
+
+// Prices returns the current price of every outcome.
+func (pm *PredictionMarket) Prices() []float64 {
+	bf := float64(pm.Liquidity)
+	m := 0.0
+	for _, v := range pm.Shares {
+		m = math.Max(m, float64(v))
+	}
+	out := make([]float64, len(pm.Shares))
+	sum := 0.0
+	for i, v := range pm.Shares {
+		out[i] = math.Exp((float64(v) - m) / bf)
+		sum += out[i]
+	}
+	for i := range out {
+		out[i] /= sum
+	}
+	return out
+}
+
+// tradeCost returns the cost of changing outcome i by delta shares; negative
+// for sales.
+func (pm *PredictionMarket) tradeCost(i int, delta int64) float64 {
+	next := append([]uint64(nil), pm.Shares...)
+	next[i] = uint64(int64(next[i]) + delta)
+	return lmsrCost(next, pm.Liquidity) - lmsrCost(pm.Shares, pm.Liquidity)
+}
+
+// QuoteBuy returns what buying amount shares of outcome i costs, rounded up.
+func (pm *PredictionMarket) QuoteBuy(i int, amount uint64) (uint64, error) {
+	if i < 0 || i >= len(pm.Outcomes) {
+		return 0, fmt.Errorf("outcome %d out of range", i)
+	}
+	return uint64(math.Ceil(pm.tradeCost(i, int64(amount)))), nil
+}
+
+// QuoteSell returns what selling amount shares of outcome i pays, rounded
+// down.
+func (pm *PredictionMarket) QuoteSell(i int, amount uint64) (uint64, error) {
+	if i < 0 || i >= len(pm.Outcomes) {
+		return 0, fmt.Errorf("outcome %d out of range", i)
+	}
+	if amount > pm.Shares[i] {
+		return 0, errors.New("exceeds outstanding shares")
+	}
+	return uint64(math.Floor(-pm.tradeCost(i, -int64(amount)))), nil
+}
+
+// redeemable is what the outstanding shares can claim once resolved.
+func (pm *PredictionMarket) redeemable() uint64 {
+	if !pm.Resolved {
+		return 0
+	}
+	if pm.Winner != predictionVoid {
+		return pm.Shares[pm.Winner]
+	}
+	total := 0.0
+	for i, q := range pm.Shares {
+		total += math.Floor(float64(q) * pm.FinalPrices[i])
+	}
+	return uint64(total)
+}
+
+func (dm *DeFiManager) loadPrediction(id Hash) (PredictionMarket, error) {
+	var pm PredictionMarket
+	if err := dm.load(predictionKey(id), &pm); err != nil {
+		return pm, fmt.Errorf("prediction market %x not found", id[:8])
+	}
+	return pm, nil
+}
+
+// CreatePrediction opens a market and takes the b·ln(n) subsidy from the
+// creator.
+func (dm *DeFiManager) CreatePrediction(id Hash, creator Address, spec PredictionSpec) error {
+	n := len(spec.Outcomes)
+	switch {
+	case strings.TrimSpace(spec.Question) == "":
+		return errors.New("question required")
+	case n < 2 || n > predictionMaxOutcomes:
+		return fmt.Errorf("markets need 2-%d outcomes", predictionMaxOutcomes)
+	case spec.Liquidity == 0:
+		return errors.New("liquidity required")
+	case spec.OracleID == "":
+		return errors.New("oracle required")
+	case spec.ResolveAfter.Before(time.Now()):
+		return errors.New("resolution time in the past")
+	}
+	switch spec.Resolution {
+	case PredictionPriceAbove:
+		if n != 2 {
+			return errors.New("price_above markets are binary")
+		}
+	case PredictionOracleOutcome:
+	default:
+		return fmt.Errorf("unknown resolution %q", spec.Resolution)
+	}
+	if spec.FallbackAfter.IsZero() {
+		spec.FallbackAfter = spec.ResolveAfter.Add(defaultPredictionFallback)
+	}
+	if spec.FallbackAfter.Before(spec.ResolveAfter) {
+		return errors.New("fallback before resolution time")
+	}
+
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	if ok, _ := dm.ledger.HasState(predictionKey(id)); ok {
+		return fmt.Errorf("exists")
+	}
+	subsidy := uint64(math.Ceil(float64(spec.Liquidity) * math.Log(float64(n))))
+	if err := dm.ledger.Transfer(creator, predictionAccount(), subsidy); err != nil {
+		return err
+	}
+	pm := PredictionMarket{
+		ID:            id,
+		Creator:       creator,
+		Question:      spec.Question,
+		Outcomes:      spec.Outcomes,
+		Liquidity:     spec.Liquidity,
+		Shares:        make([]uint64, n),
+		Collected:     subsidy,
+		OracleID:      spec.OracleID,
+		Resolution:    spec.Resolution,
+		Strike:        spec.Strike,
+		ResolveAfter:  spec.ResolveAfter.Unix(),
+		FallbackAfter: spec.FallbackAfter.Unix(),
+		Created:       time.Now().Unix(),
+	}
+	return dm.store(predictionKey(id), pm)
+}
+
+// BuyPrediction buys amount shares of an outcome, paying at most maxCost.
+func (dm *DeFiManager) BuyPrediction(id Hash, buyer Address, outcome int, amount, maxCost uint64) (uint64, error) {
+	if amount == 0 {
+		return 0, errors.New("amount zero")
+	}
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	pm, err := dm.loadPrediction(id)
+	if err != nil {
+		return 0, err
+	}
+	if pm.Resolved || time.Now().Unix() >= pm.ResolveAfter {
+		return 0, ErrPredictionClosed
+	}
+	cost, err := pm.QuoteBuy(outcome, amount)
+	if err != nil {
+		return 0, err
+	}
+	if cost > maxCost {
+		return 0, fmt.Errorf("slippage: cost %d exceeds max %d", cost, maxCost)
+	}
+	if err := dm.ledger.Transfer(buyer, predictionAccount(), cost); err != nil {
+		return 0, err
+	}
+	if err := dm.mint(buyer, predictionShareToken(id, outcome), amount); err != nil {
+		return 0, err
+	}
+	pm.Shares[outcome] += amount
+	pm.Collected += cost
+	return cost, dm.store(predictionKey(id), pm)
+}
+
+// SellPrediction sells amount shares of an outcome back to the market for
+// at least minPayout.
+func (dm *DeFiManager) SellPrediction(id Hash, seller Address, outcome int, amount, minPayout uint64) (uint64, error) {
+	if amount == 0 {
+		return 0, errors.New("amount zero")
+	}
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	pm, err := dm.loadPrediction(id)
+	if err != nil {
+		return 0, err
+	}
+	if pm.Resolved || time.Now().Unix() >= pm.ResolveAfter {
+		return 0, ErrPredictionClosed
+	}
+	payout, err := pm.QuoteSell(outcome, amount)
+	if err != nil {
+		return 0, err
+	}
+	if payout < minPayout {
+		return 0, fmt.Errorf("slippage: payout %d below min %d", payout, minPayout)
+	}
+	if err := dm.ledger.BurnToken(seller, predictionShareToken(id, outcome), amount); err != nil {
+		return 0, err
+	}
+	if err := dm.ledger.Transfer(predictionAccount(), seller, payout); err != nil {
+		return 0, err
+	}
+	pm.Shares[outcome] -= amount
+	pm.Collected -= payout
+	return payout, dm.store(predictionKey(id), pm)
+}
+
+// oracleOutcome reads the winning outcome from the market's oracle.
+func (pm *PredictionMarket) oracleOutcome() (int, error) {
+	raw, err := QueryOracle(pm.OracleID)
+	if err != nil {
+		return 0, err
+	}
+	switch pm.Resolution {
+	case PredictionPriceAbove:
+		var p OraclePrice
+		if err := json.Unmarshal(raw, &p); err != nil {
+			return 0, fmt.Errorf("oracle %s: %w", pm.OracleID, err)
+		}
+		if p.Timestamp < pm.ResolveAfter {
+			return 0, fmt.Errorf("oracle %s has no price after the resolution time", pm.OracleID)
+		}
+		if p.Price >= pm.Strike {
+			return 0, nil
+		}
+		return 1, nil
+	default:
+		v, err := strconv.Atoi(strings.TrimSpace(string(raw)))
+		if err != nil {
+			return 0, fmt.Errorf("oracle %s: %w", pm.OracleID, err)
+		}
+		if v < 0 || v >= len(pm.Outcomes) {
+			return 0, fmt.Errorf("oracle %s reported outcome %d out of range", pm.OracleID, v)
+		}
+		return v, nil
+	}
+}
+
+// settle records the result and returns the surplus to the creator.
+func (dm *DeFiManager) settle(pm *PredictionMarket, winner int) error {
+	pm.Resolved = true
+	pm.Winner = winner
+	pm.FinalPrices = pm.Prices()
+	if surplus := pm.Collected - min(pm.redeemable(), pm.Collected); surplus > 0 {
+		if err := dm.ledger.Transfer(predictionAccount(), pm.Creator, surplus); err != nil {
+			return err
+		}
+		pm.Collected -= surplus
+	}
+	return dm.store(predictionKey(pm.ID), *pm)
+}
+
+// ResolvePrediction settles a market from its oracle. Anyone may call it
+// once the resolution time has passed.
+func (dm *DeFiManager) ResolvePrediction(id Hash) (int, error) {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	pm, err := dm.loadPrediction(id)
+	if err != nil {
+		return 0, err
+	}
+	if pm.Resolved {
+		return pm.Winner, fmt.Errorf("already resolved")
+	}
+	if time.Now().Unix() < pm.ResolveAfter {
+		return 0, fmt.Errorf("market resolves after %s", time.Unix(pm.ResolveAfter, 0).UTC().Format(time.RFC3339))
+	}
+	winner, err := pm.oracleOutcome()
+	if err != nil {
+		return 0, err
+	}
+	return winner, dm.settle(&pm, winner)
+}
+
+// PredictionGovernanceResolution is the value of the prediction_resolve
+// governance parameter. Outcome -1 voids the market.
+type PredictionGovernanceResolution struct {
+	Market  string `json:"market"` // hex market ID
+	Outcome int    `json:"outcome"`
+}
+
+// resolvePredictionByGovernance is the governance fallback for markets the
+// oracle failed to resolve by FallbackAfter.
+func resolvePredictionByGovernance(value string) error {
+	var r PredictionGovernanceResolution
+	if err := json.Unmarshal([]byte(value), &r); err != nil {
+		return fmt.Errorf("invalid resolution: %w", err)
+	}
+	raw, err := hex.DecodeString(strings.TrimPrefix(r.Market, "0x"))
+	if err != nil || len(raw) != len(Hash{}) {
+		return errors.New("invalid market id")
+	}
+	var id Hash
+	copy(id[:], raw)
+	led := CurrentLedger()
+	if led == nil {
+		return errors.New("ledger not initialised")
+	}
+	dm := NewDeFiManager(led)
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	pm, err := dm.loadPrediction(id)
+	if err != nil {
+		return err
+	}
+	if pm.Resolved {
+		return fmt.Errorf("already resolved")
+	}
+	if time.Now().Unix() < pm.FallbackAfter {
+		return fmt.Errorf("oracle has until %s to resolve", time.Unix(pm.FallbackAfter, 0).UTC().Format(time.RFC3339))
+	}
+	if r.Outcome != predictionVoid && (r.Outcome < 0 || r.Outcome >= len(pm.Outcomes)) {
+		return fmt.Errorf("outcome %d out of range", r.Outcome)
+	}
+	return dm.settle(&pm, r.Outcome)
+}
+
+// ClaimPrediction redeems the holder's shares of a resolved market.
+func (dm *DeFiManager) ClaimPrediction(id Hash, holder Address) (uint64, error) {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	pm, err := dm.loadPrediction(id)
+	if err != nil {
+		return 0, err
+	}
+	if !pm.Resolved {
+		return 0, ErrPredictionUnresolved
+	}
+	var payout uint64
+	for i := range pm.Outcomes {
+		if pm.Winner != predictionVoid && i != pm.Winner {
+			continue
+		}
+		tok := predictionShareToken(id, i)
+		held := dm.ledger.MintedTokenBalance(holder, tok)
+		if held == 0 {
+			continue
+		}
+		if err := dm.ledger.BurnToken(holder, tok, held); err != nil {
+			return 0, err
+		}
+		if pm.Winner == predictionVoid {
+			payout += uint64(math.Floor(float64(held) * pm.FinalPrices[i]))
+		} else {
+			payout += held
+		}
+		pm.Shares[i] -= held
+	}
+	if payout == 0 {
+		return 0, errors.New("nothing to claim")
+	}
+	payout = min(payout, pm.Collected)
+	if err := dm.ledger.Transfer(predictionAccount(), holder, payout); err != nil {
+		return 0, err
+	}
+	pm.Collected -= payout
+	return payout, dm.store(predictionKey(id), pm)
+}
+
+// PredictionMarketOf returns a market.
+func (dm *DeFiManager) PredictionMarketOf(id Hash) (PredictionMarket, error) {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	return dm.loadPrediction(id)
+}
@@ -0,0 +1,543 @@
+package core
+
+// defi_synthetics.go – collateralised synthetic assets with a global debt pool.
+//
+// Minters lock native coin as collateral in the synthetics vault and mint
+// synths that track an oracle price. Debt is pooled: every mint issues debt
+// shares worth the minted value at current prices, and a minter owes their
+// share of the value of all outstanding synths, so debt moves with the whole
+// pool rather than with the synths the minter happens to hold.
+//
+//   - Minting requires the collateral ratio to stay at or above MinCRatioBps
+//     after the mint and charges MintFeeBps of the minted value, paid in
+//     collateral, into a fee pool claimable pro rata by debt share.
+//   - Minters below LiquidationCRatioBps can be liquidated: the liquidator
+//     burns synths to retire part of the debt and receives collateral worth
+//     that value plus LiquidationPenaltyBps.
+//
+// Prices are OraclePrice values (see oracle_management.go) read through
+// QueryOracle and rejected once older than MaxPriceAge seconds. Values are
+// expressed in quote units scaled by OraclePriceScale and computed with
+// big.Int. The parameters are governed through the synth_config parameter.
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	synthAssetPrefix  = "synth:asset:"
+	synthMinterPrefix = "synth:minter:"
+	synthPoolKey      = "synth:pool"
+	synthConfigKey    = "synth:config"
+
+	// synthFeeScale is the fixed-point scale of the fee-per-share accumulator.
+	synthFeeScale = 1_000_000_000_000_000_000
+)
+
+var (
+	ErrSynthUnknown       = errors.New("synthetic asset not found")
+	ErrSynthCRatio        = errors.New("collateral ratio too low")
+	ErrSynthNotLiquidable = errors.New("minter is sufficiently collateralised")
+	ErrSynthStalePrice    = errors.New("oracle price is stale")
+)
+
+// SynthConfig holds the governed parameters of the synthetics engine.
+type SynthConfig struct {
+	CollateralOracle      string `json:"collateral_oracle"`
+	MinCRatioBps          uint64 `json:"min_c_ratio_bps"`
+	LiquidationCRatioBps  uint64 `json:"liquidation_c_ratio_bps"`
+	LiquidationPenaltyBps uint64 `json:"liquidation_penalty_bps"`
+	MintFeeBps            uint64 `json:"mint_fee_bps"`
+	MaxPriceAge           int64  `json:"max_price_age"` // seconds
+}
+
+// DefaultSynthConfig returns the parameters used until governance sets them.
+func DefaultSynthConfig() SynthConfig {
+	return SynthConfig{
+		CollateralOracle:      "SYNN-USD",
+		MinCRatioBps:          40_000, // 400 %
+		LiquidationCRatioBps:  15_000, // 150 %
+		LiquidationPenaltyBps: 1_000,  // 10 %
+		MintFeeBps:            30,
+		MaxPriceAge:           3_600,
+	}
+}
+
+// Validate checks the configuration is usable.
+func (c SynthConfig) Validate() error {
+	switch {
+	case c.CollateralOracle == "":
+		return errors.New("collateral oracle required")
+	case c.LiquidationCRatioBps <= 10_000:
+		return errors.New("liquidation ratio must exceed 100%")
+	case c.MinCRatioBps < c.LiquidationCRatioBps:
+		return errors.New("minimum ratio below liquidation ratio")
+	case c.LiquidationPenaltyBps > 5_000:
+		return errors.New("liquidation penalty above 50%")
+	case c.MintFeeBps > 1_000:
+		return errors.New("mint fee above 10%")
+	case c.MaxPriceAge <= 0:
+		return errors.New("max price age must be positive")
+	}
+	return nil
+}
+
+// SyntheticAsset is a registered synth priced by an oracle.
+type SyntheticAsset struct {
+	Symbol   string `json:"symbol"`
+	OracleID string `json:"oracle_id"`
+	Supply   uint64 `json:"supply"`
+	Created  int64  `json:"created"`
+}
+
+// SynthMinter is a minter's collateral and share of the global debt pool.
+type SynthMinter struct {
+	Address    Address  `json:"address"`
+	Collateral uint64   `json:"collateral"`
+	DebtShares uint64   `json:"debt_shares"`
+	FeeDebt    *big.Int `json:"fee_debt"` // fee accumulator at last settlement
+	Claimable  uint64   `json:"claimable"`
+}
+
+// synthPool is the global debt pool.
+type synthPool struct {
+	TotalShares uint64   `json:"total_shares"`
+	FeePerShare *big.Int `json:"fee_per_share"` // scaled by synthFeeScale
+	FeesHeld    uint64   `json:"fees_held"`
+}
+
+// SynthPosition is a minter's position valued at current prices.
+type SynthPosition struct {
+	SynthMinter
+	CollateralValue uint64 `json:"collateral_value"`
+	Debt            uint64 `json:"debt"`
+	CRatioBps       uint64 `json:"c_ratio_bps"` // 0 when there is no debt
+	Liquidatable    bool   `json:"liquidatable"`
+}
+
+func synthAccount() Address { return ModuleAddress("synthetics") }
+
+func synthAssetKey(sym string) []byte  { return []byte(synthAssetPrefix + sym) }
+func synthMinterKey(a Address) []byte  { return []byte(synthMinterPrefix + a.String()) }
+func synthTokenID(sym string) string   { return "s" + sym }
+func normaliseSynth(sym string) string { return strings.ToUpper(strings.TrimSpace(sym)) }
+
+// mulDivU64 returns a·b/c without intermediate overflow.
+func mulDivU64(a, b, c uint64) *big.Int {
+	return new(big.Int).Div(new(big.Int).Mul(u64(a), u64(b)), u64(c))
+}
+
+// setSynthConfig stores the synthetics parameters. Only reached via governance.
+func setSynthConfig(value string) error {
+	var c SynthConfig
+	if err := json.Unmarshal([]byte(value), &c); err != nil {
+		return fmt.Errorf("invalid synth config: %w", err)
+	}
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	led := CurrentLedger()
+	if led == nil {
+		return errors.New("ledger not initialised")
+	}
+	raw, _ := json.Marshal(c)
+	return led.SetState([]byte(synthConfigKey), raw)
+}
+
+// SynthConfig returns the parameters in effect.
+func (dm *DeFiManager) SynthConfig() SynthConfig {
+	c := DefaultSynthConfig()
+	if raw, err := dm.ledger.GetState([]byte(synthConfigKey)); err == nil && len(raw) > 0 {
+		var stored SynthConfig
+		if json.Unmarshal(raw, &stored) == nil && stored.Validate() == nil {
+			c = stored
+		}
+	}
+	return c
+}
+
+// synthPrice reads a fresh oracle price.
+func (dm *DeFiManager) synthPrice(oracleID string, cfg SynthConfig) (uint64, error) {
+	raw, err := QueryOracle(oracleID)
+	if err != nil {
+		return 0, fmt.Errorf("oracle %s: %w", oracleID, err)
+	}
+	var p OraclePrice
+	if err := json.Unmarshal(raw, &p); err != nil {
+		return 0, fmt.Errorf("oracle %s: %w", oracleID, err)
+	}
+	if p.Price <= 0 {
+		return 0, fmt.Errorf("oracle %s: non-positive price", oracleID)
+	}
+	if time.Now().Unix()-p.Timestamp > cfg.MaxPriceAge {
+		return 0, fmt.Errorf("%w: %s", ErrSynthStalePrice, oracleID)
+	}
+	return uint64(p.Price), nil
+}
+
+func (dm *DeFiManager) loadSynthPool() synthPool {
+	var p synthPool
+	_ = dm.load([]byte(synthPoolKey), &p)
+	if p.FeePerShare == nil {
+		p.FeePerShare = new(big.Int)
+	}
+	return p
+}
+
+func (dm *DeFiManager) loadMinter(a Address) SynthMinter {
+	m := SynthMinter{Address: a}
+	_ = dm.load(synthMinterKey(a), &m)
+	if m.FeeDebt == nil {
+		m.FeeDebt = new(big.Int)
+	}
+	return m
+}
+
+func (dm *DeFiManager) loadSynth(sym string) (SyntheticAsset, error) {
+	var s SyntheticAsset
+	if err := dm.load(synthAssetKey(sym), &s); err != nil {
+		return s, fmt.Errorf("%w: %s", ErrSynthUnknown, sym)
+	}
+	return s, nil
+}
+
+// settleFees moves fees accrued since the minter's last settlement into
+// Claimable. It must run before DebtShares changes.
+func settleFees(m *SynthMinter, pool synthPool) {
+	owed := new(big.Int).Sub(pool.FeePerShare, m.FeeDebt)
+	owed.Mul(owed, u64(m.DebtShares)).Div(owed, big.NewInt(synthFeeScale))
+	m.Claimable += owed.Uint64()
+	m.FeeDebt = new(big.Int).Set(pool.FeePerShare)
+}
+
+// totalDebt values every outstanding synth at current prices.
+func (dm *DeFiManager) totalDebt(cfg SynthConfig) (uint64, error) {
+	synths, err := dm.Synthetics()
+	if err != nil {
+		return 0, err
+	}
+	total := new(big.Int)
+	for _, s := range synths {
+		if s.Supply == 0 {
+			continue
+		}
+		price, err := dm.synthPrice(s.OracleID, cfg)
+		if err != nil {
+			return 0, err
+		}
+		total.Add(total, mulDivU64(s.Supply, price, OraclePriceScale))
+	}
+	return total.Uint64(), nil
+}
+
+// debtOf converts debt shares into value given the pool and its total debt.
+func debtOf(shares uint64, pool synthPool, total uint64) uint64 {
+	if pool.TotalShares == 0 {
+		return 0
+	}
+	return mulDivU64(shares, total, pool.TotalShares).Uint64()
+}
+
+// sharesFor converts a value into debt shares, rounding up.
+func sharesFor(value uint64, pool synthPool, total uint64) uint64 {
+	if pool.TotalShares == 0 || total == 0 {
+		return value
+	}
+	n := new(big.Int).Mul(u64(value), u64(pool.TotalShares))
+	n.Add(n, u64(total-1))
+	return n.Div(n, u64(total)).Uint64()
+}
+
+func cRatioBps(collateralValue, debt uint64) uint64 {
+	if debt == 0 {
+		return 0
+	}
+	return mulDivU64(collateralValue, 10_000, debt).Uint64()
+}
+
+// CreateSynthetic registers a synth whose price is read from oracleID.
+func (dm *DeFiManager) CreateSynthetic(symbol, oracleID string) error {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	symbol = normaliseSynth(symbol)
+	if symbol == "" || oracleID == "" {
+		return errors.New("symbol and oracle required")
+	}
+	if ok, _ := dm.ledger.HasState(synthAssetKey(symbol)); ok {
+		return fmt.Errorf("exists")
+	}
+	if _, err := dm.synthPrice(oracleID, dm.SynthConfig()); err != nil {
+		return err
+	}
+	return dm.store(synthAssetKey(symbol), SyntheticAsset{Symbol: symbol, OracleID: oracleID, Created: time.Now().Unix()})
+}
+
+// Synthetics lists the registered synths ordered by symbol.
+func (dm *DeFiManager) Synthetics() ([]SyntheticAsset, error) {
+	it := dm.ledger.PrefixIterator([]byte(synthAssetPrefix))
+	var out []SyntheticAsset
+	for it.Next() {
+		var s SyntheticAsset
+		if err := json.Unmarshal(it.Value(), &s); err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	if err := it.Error(); err != nil {
+		return nil, err
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Symbol < out[j].Symbol })
+	return out, nil
+}
+
+// DepositCollateral locks native coin in the synthetics vault.
+func (dm *DeFiManager) DepositCollateral(minter Address, amount uint64) error {
+	if amount == 0 {
+		return errors.New("amount zero")
+	}
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	if err := dm.ledger.Transfer(minter, synthAccount(), amount); err != nil {
+		return err
+	}
+	m := dm.loadMinter(minter)
+	m.Collateral += amount
+	return dm.store(synthMinterKey(minter), m)
+}
+
+// WithdrawCollateral releases collateral as long as the minter stays at or
+// above the minimum collateral ratio.
+func (dm *DeFiManager) WithdrawCollateral(minter Address, amount uint64) error {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	m := dm.loadMinter(minter)
+	if amount == 0 || amount > m.Collateral {
+		return errors.New("invalid amount")
+	}
+	m.Collateral -= amount
+	if m.DebtShares > 0 {
+		cfg := dm.SynthConfig()
+		pos, err := dm.position(m, cfg)
+		if err != nil {
+			return err
+		}
+		if pos.CRatioBps < cfg.MinCRatioBps {
+			return fmt.Errorf("%w: %d bps after withdrawal, need %d", ErrSynthCRatio, pos.CRatioBps, cfg.MinCRatioBps)
+		}
+	}
+	if err := dm.ledger.Transfer(synthAccount(), minter, amount); err != nil {
+		return err
+	}
+	return dm.store(synthMinterKey(minter), m)
+}
+
+// MintSynthetic mints amount of a synth against the minter's collateral and
+// returns the fee charged in collateral.
+func (dm *DeFiManager) MintSynthetic(minter Address, symbol string, amount uint64) (uint64, error) {
+	if amount == 0 {
+		return 0, errors.New("amount zero")
+	}
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	cfg := dm.SynthConfig()
+	s, err := dm.loadSynth(normaliseSynth(symbol))
+	if err != nil {
+		return 0, err
+	}
+	price, err := dm.synthPrice(s.OracleID, cfg)
+	if err != nil {
+		return 0, err
+	}
+	collPrice, err := dm.synthPrice(cfg.CollateralOracle, cfg)
+	if err != nil {
+		return 0, err
+	}
+	total, err := dm.totalDebt(cfg)
+	if err != nil {
+		return 0, err
+	}
+	value := mulDivU64(amount, price, OraclePriceScale).Uint64()
+	if value == 0 {
+		return 0, errors.New("amount too small")
+	}
+	fee := mulDivU64(mulDivU64(value, cfg.MintFeeBps, 10_000).Uint64(), OraclePriceScale, collPrice).Uint64()
+
+	pool := dm.loadSynthPool()
+	m := dm.loadMinter(minter)
+	if fee > m.Collateral {
+		return 0, fmt.Errorf("%w: collateral cannot cover the mint fee", ErrSynthCRatio)
+	}
+	settleFees(&m, pool)
+	shares := sharesFor(value, pool, total)
+	debt := debtOf(m.DebtShares, pool, total) + value
+	m.Collateral -= fee
+	collValue := mulDivU64(m.Collateral, collPrice, OraclePriceScale).Uint64()
+	if r := cRatioBps(collValue, debt); r < cfg.MinCRatioBps {
+		return 0, fmt.Errorf("%w: %d bps after mint, need %d", ErrSynthCRatio, r, cfg.MinCRatioBps)
+	}
+
+	if err := dm.mint(minter, synthTokenID(s.Symbol), amount); err != nil {
+		return 0, err
+	}
+	m.DebtShares += shares
+	pool.TotalShares += shares
+	if fee > 0 {
+		// fees accrue to every share, the new ones included
+		inc := new(big.Int).Mul(u64(fee), big.NewInt(synthFeeScale))
+		pool.FeePerShare.Add(pool.FeePerShare, inc.Div(inc, u64(pool.TotalShares)))
+		pool.FeesHeld += fee
+	}
+	s.Supply += amount
+	if err := dm.store(synthAssetKey(s.Symbol), s); err != nil {
+		return 0, err
+	}
+	if err := dm.store(synthMinterKey(minter), m); err != nil {
+		return 0, err
+	}
+	return fee, dm.store([]byte(synthPoolKey), pool)
+}
+
+// burnDebt burns amount of the synth from holder and retires up to that
+// value of debt from m. It returns the value retired.
+func (dm *DeFiManager) burnDebt(holder Address, m *SynthMinter, s *SyntheticAsset, amount uint64, cfg SynthConfig) (uint64, error) {
+	price, err := dm.synthPrice(s.OracleID, cfg)
+	if err != nil {
+		return 0, err
+	}
+	total, err := dm.totalDebt(cfg)
+	if err != nil {
+		return 0, err
+	}
+	pool := dm.loadSynthPool()
+	settleFees(m, pool)
+	debt := debtOf(m.DebtShares, pool, total)
+	value := mulDivU64(amount, price, OraclePriceScale).Uint64()
+	if value > debt {
+		return 0, fmt.Errorf("burn worth %d exceeds debt %d", value, debt)
+	}
+	shares := m.DebtShares
+	if value < debt {
+		shares = mulDivU64(value, pool.TotalShares, total).Uint64()
+	}
+	if err := dm.ledger.BurnToken(holder, synthTokenID(s.Symbol), amount); err != nil {
+		return 0, err
+	}
+	m.DebtShares -= shares
+	pool.TotalShares -= shares
+	s.Supply -= amount
+	if err := dm.store(synthAssetKey(s.Symbol), *s); err != nil {
+		return 0, err
+	}
+	return value, dm.store([]byte(synthPoolKey), pool)
+}
+
+// BurnSynthetic burns the minter's synths to repay debt.
+func (dm *DeFiManager) BurnSynthetic(minter Address, symbol string, amount uint64) error {
+	if amount == 0 {
+		return errors.New("amount zero")
+	}
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	s, err := dm.loadSynth(normaliseSynth(symbol))
+	if err != nil {
+		return err
+	}
+	m := dm.loadMinter(minter)
+	if _, err := dm.burnDebt(minter, &m, &s, amount, dm.SynthConfig()); err != nil {
+		return err
+	}
+	return dm.store(synthMinterKey(minter), m)
+}
+
+// ClaimSyntheticFees pays the minter's accrued share of mint fees.
+func (dm *DeFiManager) ClaimSyntheticFees(minter Address) (uint64, error) {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	pool := dm.loadSynthPool()
+	m := dm.loadMinter(minter)
+	settleFees(&m, pool)
+	amt := min(m.Claimable, pool.FeesHeld)
+	if amt == 0 {
+		return 0, errors.New("nothing to claim")
+	}
+	if err := dm.ledger.Transfer(synthAccount(), minter, amt); err != nil {
+		return 0, err
+	}
+	m.Claimable -= amt
+	pool.FeesHeld -= amt
+	if err := dm.store(synthMinterKey(minter), m); err != nil {
+		return 0, err
+	}
+	return amt, dm.store([]byte(synthPoolKey), pool)
+}
+
+// LiquidateSynthetic lets liquidator burn amount of a synth against an
+// under-collateralised minter's debt in exchange for collateral worth the
+// retired debt plus the liquidation penalty. It returns the collateral paid.
+func (dm *DeFiManager) LiquidateSynthetic(liquidator, minter Address, symbol string, amount uint64) (uint64, error) {
+	if amount == 0 {
+		return 0, errors.New("amount zero")
+	}
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	cfg := dm.SynthConfig()
+	m := dm.loadMinter(minter)
+	pos, err := dm.position(m, cfg)
+	if err != nil {
+		return 0, err
+	}
+	if !pos.Liquidatable {
+		return 0, ErrSynthNotLiquidable
+	}
+	s, err := dm.loadSynth(normaliseSynth(symbol))
+	if err != nil {
+		return 0, err
+	}
+	collPrice, err := dm.synthPrice(cfg.CollateralOracle, cfg)
+	if err != nil {
+		return 0, err
+	}
+	value, err := dm.burnDebt(liquidator, &m, &s, amount, cfg)
+	if err != nil {
+		return 0, err
+	}
+	reward := mulDivU64(value, 10_000+cfg.LiquidationPenaltyBps, 10_000).Uint64()
+	seized := min(mulDivU64(reward, OraclePriceScale, collPrice).Uint64(), m.Collateral)
+	if err := dm.ledger.Transfer(synthAccount(), liquidator, seized); err != nil {
+		return 0, err
+	}
+	m.Collateral -= seized
+	return seized, dm.store(synthMinterKey(minter), m)
+}
+
+func (dm *DeFiManager) position(m SynthMinter, cfg SynthConfig) (SynthPosition, error) {
+	pos := SynthPosition{SynthMinter: m}
+	collPrice, err := dm.synthPrice(cfg.CollateralOracle, cfg)
+	if err != nil {
+		return pos, err
+	}
+	total, err := dm.totalDebt(cfg)
+	if err != nil {
+		return pos, err
+	}
+	pool := dm.loadSynthPool()
+	settleFees(&pos.SynthMinter, pool)
+	pos.CollateralValue = mulDivU64(m.Collateral, collPrice, OraclePriceScale).Uint64()
+	pos.Debt = debtOf(m.DebtShares, pool, total)
+	pos.CRatioBps = cRatioBps(pos.CollateralValue, pos.Debt)
+	pos.Liquidatable = pos.Debt > 0 && pos.CRatioBps < cfg.LiquidationCRatioBps
+	return pos, nil
+}
+
+// SynthPositionOf values a minter's position at current prices.
+func (dm *DeFiManager) SynthPositionOf(minter Address) (SynthPosition, error) {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	return dm.position(dm.loadMinter(minter), dm.SynthConfig())
+}
@@ -0,0 +1,528 @@
+package core
+
+// defi_yield_farms.go – per-block reward emissions across yield farms.
+//
+// A single emission controller mints RewardPerBlock reward tokens every
+// block and splits them between farms by allocation points. Inside a farm
+// rewards are shared by effective stake using the MasterChef accumulator:
+//
+//	accRewardPerShare += blocks·RewardPerBlock·alloc/totalAlloc / totalEffective
+//	pending            = effective·accRewardPerShare - rewardDebt
+//
+// Stakers may lock their stake for one of the governed lock tiers; a lock
+// multiplies the stake's effective weight until the first stake, harvest or
+// unstake after it expires. EmergencyWithdraw returns the staked tokens at
+// any time, lock or not, and forfeits all pending rewards.
+//
+// Farms are added and reweighted, and the emission rate and lock tiers are
+// changed, through governance parameters (farm_add, farm_alloc,
+// farm_emission, farm_lock_tiers). Every such change first brings all farms
+// up to date so past blocks are paid at the rate that applied to them.
+//
+// Farms stake tokens held in the ledger token balances (see MintToken); an
+// empty StakeToken stakes the native coin. Rewards are minted on harvest.
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	farmControllerKey = "farm:ctl"
+	farmPrefix        = "farm:pool:"
+	farmStakePrefix   = "farm:stake:"
+	farmRewardToken   = "SYNTHRON"
+	// farmAccScale is the fixed-point scale of accRewardPerShare.
+	farmAccScale = 1_000_000_000_000
+)
+
+// FarmLockTier multiplies the weight of stakes locked for at least Duration.
+type FarmLockTier struct {
+	Duration      int64  `json:"duration"` // seconds
+	MultiplierBps uint64 `json:"multiplier_bps"`
+}
+
+// FarmController is the global emission schedule.
+type FarmController struct {
+	RewardPerBlock  uint64         `json:"reward_per_block"`
+	TotalAllocPoint uint64         `json:"total_alloc_point"`
+	NextID          uint64         `json:"next_id"`
+	LockTiers       []FarmLockTier `json:"lock_tiers"`
+}
+
+func defaultFarmController() FarmController {
+	return FarmController{
+		RewardPerBlock: 10,
+		NextID:         1,
+		LockTiers: []FarmLockTier{
+			{Duration: 30 * 86_400, MultiplierBps: 12_500},
+			{Duration: 90 * 86_400, MultiplierBps: 15_000},
+			{Duration: 180 * 86_400, MultiplierBps: 20_000},
+			{Duration: 365 * 86_400, MultiplierBps: 25_000},
+		},
+	}
+}
+
+// YieldFarm is one farm of the emission controller.
+type YieldFarm struct {
+	ID                uint64   `json:"id"`
+	StakeToken        string   `json:"stake_token"` // "" = native coin
+	AllocPoint        uint64   `json:"alloc_point"`
+	LastRewardBlock   uint64   `json:"last_reward_block"`
+	AccRewardPerShare *big.Int `json:"acc_reward_per_share"`
+	TotalStaked       uint64   `json:"total_staked"`
+	TotalEffective    uint64   `json:"total_effective"`
+	Created           int64    `json:"created"`
+}
+
+// FarmStake is a staker's position in a farm.
+type FarmStake struct {
+	Farm          uint64   `json:"farm"`
+	Owner         Address  `json:"owner"`
+	Amount        uint64   `json:"amount"`
+	Effective     uint64   `json:"effective"`
+	MultiplierBps uint64   `json:"multiplier_bps"`
+	LockUntil     int64    `json:"lock_until"`
+	RewardDebt    *big.Int `json:"reward_debt"`
+}
+
+// FarmStakeView is a stake with its pending reward.
+type FarmStakeView struct {
+	FarmStake
+	Pending uint64 `json:"pending"`
+}
+
+func farmAccount() Address { return ModuleAddress("yield_farm") }
+
+func farmKey(id uint64) []byte { return []byte(fmt.Sprintf("%s%010d", farmPrefix, id)) }
+
+func farmStakeKey(id uint64, owner Address) []byte {
+	return []byte(fmt.Sprintf("%s%010d:%s", farmStakePrefix, id, owner.String()))
+}
+
+func (dm *DeFiManager) farmController() FarmController {
+	c := defaultFarmController()
+	_ = dm.load([]byte(farmControllerKey), &c)
+	return c
+}
+
+func (dm *DeFiManager) loadFarm(id uint64) (YieldFarm, error) {
+	var f YieldFarm
+	if err := dm.load(farmKey(id), &f); err != nil {
+		return f, fmt.Errorf("farm %d not found", id)
+	}
+	if f.AccRewardPerShare == nil {
+		f.AccRewardPerShare = new(big.Int)
+	}
+	return f, nil
+}
+
+func (dm *DeFiManager) loadFarmStake(id uint64, owner Address) FarmStake {
+	s := FarmStake{Farm: id, Owner: owner, MultiplierBps: 10_000}
+	_ = dm.load(farmStakeKey(id, owner), &s)
+	if s.RewardDebt == nil {
+		s.RewardDebt = new(big.Int)
+	}
+	return s
+}
+
+// YieldFarms lists every farm ordered by ID.
+func (dm *DeFiManager) YieldFarms() ([]YieldFarm, error) {
+	it := dm.ledger.PrefixIterator([]byte(farmPrefix))
+	var out []YieldFarm
+	for it.Next() {
+		var f YieldFarm
+		if err := json.Unmarshal(it.Value(), &f); err != nil {
+			return nil, err
+		}
+		out = append(out, f)
+	}
+	if err := it.Error(); err != nil {
+		return nil, err
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
+
+// accrue brings a farm's accumulator up to height without storing it.
+func (f *YieldFarm) accrue(c FarmController, height uint64) {
+	if height <= f.LastRewardBlock {
+		return
+	}
+	if f.TotalEffective > 0 && c.TotalAllocPoint > 0 {
+		reward := new(big.Int).Mul(u64(height-f.LastRewardBlock), u64(c.RewardPerBlock))
+		reward.Mul(reward, u64(f.AllocPoint)).Div(reward, u64(c.TotalAllocPoint))
+		reward.Mul(reward, big.NewInt(farmAccScale)).Div(reward, u64(f.TotalEffective))
+		f.AccRewardPerShare.Add(f.AccRewardPerShare, reward)
+	}
+	f.LastRewardBlock = height
+}
+
+func (s *FarmStake) pending(f YieldFarm) uint64 {
+	p := new(big.Int).Mul(u64(s.Effective), f.AccRewardPerShare)
+	p.Div(p, big.NewInt(farmAccScale)).Sub(p, s.RewardDebt)
+	if p.Sign() <= 0 {
+		return 0
+	}
+	return p.Uint64()
+}
+
+func (s *FarmStake) resetDebt(f YieldFarm) {
+	d := new(big.Int).Mul(u64(s.Effective), f.AccRewardPerShare)
+	s.RewardDebt = d.Div(d, big.NewInt(farmAccScale))
+}
+
+// massUpdateFarms accrues every farm at the current rate. It runs before any
+// change to the emission schedule.
+func (dm *DeFiManager) massUpdateFarms(c FarmController) error {
+	farms, err := dm.YieldFarms()
+	if err != nil {
+		return err
+	}
+	height := dm.ledger.LastHeight()
+	for _, f := range farms {
+		if f.AccRewardPerShare == nil {
+			f.AccRewardPerShare = new(big.Int)
+		}
+		f.accrue(c, height)
+		if err := dm.store(farmKey(f.ID), f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// StartYieldFarm adds a farm for stakeToken with the given allocation
+// points. Governance adds farms through farm_add.
+func (dm *DeFiManager) StartYieldFarm(stakeToken string, allocPoint uint64) (uint64, error) {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	c := dm.farmController()
+	if err := dm.massUpdateFarms(c); err != nil {
+		return 0, err
+	}
+	f := YieldFarm{
+		ID:                c.NextID,
+		StakeToken:        stakeToken,
+		AllocPoint:        allocPoint,
+		LastRewardBlock:   dm.ledger.LastHeight(),
+		AccRewardPerShare: new(big.Int),
+		Created:           time.Now().Unix(),
+	}
+	c.NextID++
+	c.TotalAllocPoint += allocPoint
+	if err := dm.store(farmKey(f.ID), f); err != nil {
+		return 0, err
+	}
+	return f.ID, dm.store([]byte(farmControllerKey), c)
+}
+
+// SetFarmAllocation changes a farm's allocation points.
+func (dm *DeFiManager) SetFarmAllocation(id, allocPoint uint64) error {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	c := dm.farmController()
+	if err := dm.massUpdateFarms(c); err != nil {
+		return err
+	}
+	f, err := dm.loadFarm(id)
+	if err != nil {
+		return err
+	}
+	c.TotalAllocPoint = c.TotalAllocPoint - f.AllocPoint + allocPoint
+	f.AllocPoint = allocPoint
+	if err := dm.store(farmKey(id), f); err != nil {
+		return err
+	}
+	return dm.store([]byte(farmControllerKey), c)
+}
+
+// SetFarmEmission changes the reward minted per block.
+func (dm *DeFiManager) SetFarmEmission(perBlock uint64) error {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	c := dm.farmController()
+	if err := dm.massUpdateFarms(c); err != nil {
+		return err
+	}
+	c.RewardPerBlock = perBlock
+	return dm.store([]byte(farmControllerKey), c)
+}
+
+// SetFarmLockTiers replaces the lock tiers. Existing stakes keep the
+// multiplier they locked in.
+func (dm *DeFiManager) SetFarmLockTiers(tiers []FarmLockTier) error {
+	for _, t := range tiers {
+		if t.Duration <= 0 || t.MultiplierBps < 10_000 || t.MultiplierBps > 50_000 {
+			return fmt.Errorf("invalid lock tier %+v", t)
+		}
+	}
+	sort.Slice(tiers, func(i, j int) bool { return tiers[i].Duration < tiers[j].Duration })
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	c := dm.farmController()
+	c.LockTiers = tiers
+	return dm.store([]byte(farmControllerKey), c)
+}
+
+// lockMultiplier returns the multiplier of the longest tier lock reaches.
+func (c FarmController) lockMultiplier(lock time.Duration) uint64 {
+	m := uint64(10_000)
+	for _, t := range c.LockTiers {
+		if int64(lock/time.Second) >= t.Duration {
+			m = t.MultiplierBps
+		}
+	}
+	return m
+}
+
+func (dm *DeFiManager) pullStake(f YieldFarm, from Address, amt uint64) error {
+	if f.StakeToken == "" {
+		return dm.ledger.Transfer(from, farmAccount(), amt)
+	}
+	if err := dm.ledger.BurnToken(from, f.StakeToken, amt); err != nil {
+		return err
+	}
+	return dm.ledger.MintToken(farmAccount(), f.StakeToken, amt)
+}
+
+func (dm *DeFiManager) pushStake(f YieldFarm, to Address, amt uint64) error {
+	if f.StakeToken == "" {
+		return dm.ledger.Transfer(farmAccount(), to, amt)
+	}
+	if err := dm.ledger.BurnToken(farmAccount(), f.StakeToken, amt); err != nil {
+		return err
+	}
+	return dm.ledger.MintToken(to, f.StakeToken, amt)
+}
+
+// harvest pays a stake's pending reward; the caller resets the debt.
+func (dm *DeFiManager) harvest(f YieldFarm, s *FarmStake) (uint64, error) {
+	p := s.pending(f)
+	if p == 0 {
+		return 0, nil
+	}
+	return p, dm.mint(s.Owner, farmRewardToken, p)
+}
+
+func (dm *DeFiManager) saveFarmStake(f YieldFarm, s FarmStake) error {
+	if err := dm.store(farmKey(f.ID), f); err != nil {
+		return err
+	}
+	if s.Amount == 0 {
+		return dm.ledger.DeleteState(farmStakeKey(f.ID, s.Owner))
+	}
+	return dm.store(farmStakeKey(f.ID, s.Owner), s)
+}
+
+// expireLock drops the boost of a lock that has run out.
+func (s *FarmStake) expireLock(now int64) {
+	if s.LockUntil > 0 && now >= s.LockUntil {
+		s.LockUntil, s.MultiplierBps = 0, 10_000
+	}
+}
+
+// reweight recomputes a stake's effective weight and the farm total.
+func (f *YieldFarm) reweight(s *FarmStake) {
+	f.TotalEffective -= s.Effective
+	s.Effective = s.Amount * s.MultiplierBps / 10_000
+	f.TotalEffective += s.Effective
+}
+
+// Stake adds amount to the owner's stake, harvesting pending rewards. A
+// non-zero lock locks the whole stake until now+lock (never shortening an
+// existing lock) and applies the matching tier multiplier.
+func (dm *DeFiManager) Stake(id uint64, owner Address, amount uint64, lock time.Duration) (uint64, error) {
+	if amount == 0 {
+		return 0, errors.New("amount zero")
+	}
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	c := dm.farmController()
+	f, err := dm.loadFarm(id)
+	if err != nil {
+		return 0, err
+	}
+	f.accrue(c, dm.ledger.LastHeight())
+	s := dm.loadFarmStake(id, owner)
+	paid, err := dm.harvest(f, &s)
+	if err != nil {
+		return 0, err
+	}
+	if err := dm.pullStake(f, owner, amount); err != nil {
+		return 0, err
+	}
+	s.Amount += amount
+	f.TotalStaked += amount
+	s.expireLock(time.Now().Unix())
+	if lock > 0 {
+		if until := time.Now().Add(lock).Unix(); until > s.LockUntil {
+			s.LockUntil = until
+		}
+		s.MultiplierBps = max(s.MultiplierBps, c.lockMultiplier(lock))
+	}
+	f.reweight(&s)
+	s.resetDebt(f)
+	return paid, dm.saveFarmStake(f, s)
+}
+
+// Unstake withdraws amount once the stake's lock has expired, harvesting
+// pending rewards.
+func (dm *DeFiManager) Unstake(id uint64, owner Address, amount uint64) (uint64, error) {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	c := dm.farmController()
+	f, err := dm.loadFarm(id)
+	if err != nil {
+		return 0, err
+	}
+	s := dm.loadFarmStake(id, owner)
+	if amount == 0 || amount > s.Amount {
+		return 0, errors.New("invalid amount")
+	}
+	if time.Now().Unix() < s.LockUntil {
+		return 0, fmt.Errorf("stake locked until %s", time.Unix(s.LockUntil, 0).UTC().Format(time.RFC3339))
+	}
+	f.accrue(c, dm.ledger.LastHeight())
+	paid, err := dm.harvest(f, &s)
+	if err != nil {
+		return 0, err
+	}
+	if err := dm.pushStake(f, owner, amount); err != nil {
+		return 0, err
+	}
+	s.Amount -= amount
+	f.TotalStaked -= amount
+	s.expireLock(time.Now().Unix())
+	f.reweight(&s)
+	s.resetDebt(f)
+	return paid, dm.saveFarmStake(f, s)
+}
+
+// Harvest pays the owner's pending rewards.
+func (dm *DeFiManager) Harvest(id uint64, owner Address) (uint64, error) {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	f, err := dm.loadFarm(id)
+	if err != nil {
+		return 0, err
+	}
+	f.accrue(dm.farmController(), dm.ledger.LastHeight())
+	s := dm.loadFarmStake(id, owner)
+	paid, err := dm.harvest(f, &s)
+	if err != nil {
+		return 0, err
+	}
+	if paid == 0 {
+		return 0, errors.New("nothing to harvest")
+	}
+	s.expireLock(time.Now().Unix())
+	f.reweight(&s)
+	s.resetDebt(f)
+	return paid, dm.saveFarmStake(f, s)
+}
+
+// EmergencyWithdraw returns the whole stake regardless of any lock and
+// forfeits pending rewards.
+func (dm *DeFiManager) EmergencyWithdraw(id uint64, owner Address) (uint64, error) {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	f, err := dm.loadFarm(id)
+	if err != nil {
+		return 0, err
+	}
+	f.accrue(dm.farmController(), dm.ledger.LastHeight())
+	s := dm.loadFarmStake(id, owner)
+	amt := s.Amount
+	if amt == 0 {
+		return 0, errors.New("no stake")
+	}
+	if err := dm.pushStake(f, owner, amt); err != nil {
+		return 0, err
+	}
+	f.TotalStaked -= amt
+	f.TotalEffective -= s.Effective
+	s.Amount, s.Effective = 0, 0
+	return amt, dm.saveFarmStake(f, s)
+}
+
+// FarmStakes returns the owner's stakes across all farms with pending
+// rewards at the current height.
+func (dm *DeFiManager) FarmStakes(owner Address) ([]FarmStakeView, error) {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	c := dm.farmController()
+	farms, err := dm.YieldFarms()
+	if err != nil {
+		return nil, err
+	}
+	height := dm.ledger.LastHeight()
+	var out []FarmStakeView
+	for _, f := range farms {
+		if f.AccRewardPerShare == nil {
+			f.AccRewardPerShare = new(big.Int)
+		}
+		s := dm.loadFarmStake(f.ID, owner)
+		if s.Amount == 0 {
+			continue
+		}
+		f.accrue(c, height)
+		out = append(out, FarmStakeView{FarmStake: s, Pending: s.pending(f)})
+	}
+	return out, nil
+}
+
+// FarmControllerState returns the emission schedule.
+func (dm *DeFiManager) FarmControllerState() FarmController {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	return dm.farmController()
+}
+
+// updateFarmParam applies the farm_* governance parameters.
+func updateFarmParam(key, value string) error {
+	led := CurrentLedger()
+	if led == nil {
+		return errors.New("ledger not initialised")
+	}
+	dm := NewDeFiManager(led)
+	switch key {
+	case "farm_emission":
+		v, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid uint: %w", err)
+		}
+		return dm.SetFarmEmission(v)
+	case "farm_add":
+		var req struct {
+			StakeToken string `json:"stake_token"`
+			AllocPoint uint64 `json:"alloc_point"`
+		}
+		if err := json.Unmarshal([]byte(value), &req); err != nil {
+			return fmt.Errorf("invalid farm: %w", err)
+		}
+		_, err := dm.StartYieldFarm(req.StakeToken, req.AllocPoint)
+		return err
+	case "farm_alloc":
+		var req struct {
+			Farm       uint64 `json:"farm"`
+			AllocPoint uint64 `json:"alloc_point"`
+		}
+		if err := json.Unmarshal([]byte(value), &req); err != nil {
+			return fmt.Errorf("invalid allocation: %w", err)
+		}
+		return dm.SetFarmAllocation(req.Farm, req.AllocPoint)
+	case "farm_lock_tiers":
+		var tiers []FarmLockTier
+		if err := json.Unmarshal([]byte(value), &tiers); err != nil {
+			return fmt.Errorf("invalid lock tiers: %w", err)
+		}
+		return dm.SetFarmLockTiers(tiers)
+	}
+	return fmt.Errorf("unknown param: %s", key)
+}
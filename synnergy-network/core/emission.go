@@ -0,0 +1,439 @@
+package core
+
+// emission.go – coin emission policy.
+//
+// Block rewards follow an EmissionPolicy kept in ledger state: a list of
+// schedules, each paying InitialReward per block from its StartHeight and
+// halving every HalvingPeriod blocks after it. The genesis schedule comes
+// from LedgerConfig.Emission (the "emission" section of genesis.json) and
+// defaults to InitialReward halving every RewardHalvingPeriod blocks.
+// Governance replaces the schedule with the emission_schedule parameter;
+// a new schedule must start above the head, so past rewards never change.
+//
+// Consensus reads the reward of each block from the policy and records what
+// it paid under emission:block:<height>. Because the schedule is part of
+// state, anyone can recompute the reward of a height, project the supply
+// curve with Project and check the recorded emission with VerifyEmission.
+//
+// Emission is bounded by MaxSupply. A schedule that would emit more than
+// MaxSupply over its lifetime is rejected, and a block whose reward would
+// take the coin supply above it pays only what is left; the rest is recorded
+// as capped.
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	emissionPolicyKey = "emission:policy"
+	emissionTotalKey  = "emission:total"
+
+	maxEmissionPoints = 10_000
+)
+
+func emissionBlockKey(height uint64) []byte {
+	return []byte(fmt.Sprintf("emission:block:%020d", height))
+}
+
+// ErrEmissionMismatch is returned when a recorded block emission differs
+// from the policy.
+var ErrEmissionMismatch = errors.New("block emission does not match policy")
+
+// EmissionSchedule pays InitialReward per block from StartHeight, halving
+// every HalvingPeriod blocks. Rewards are split MinerBps to the block
+// miner, StakerBps to the sub-block validators and the rest to the loan
+// pool.
+type EmissionSchedule struct {
+	StartHeight   uint64   `json:"start_height"`
+	InitialReward *big.Int `json:"initial_reward"`
+	HalvingPeriod uint64   `json:"halving_period"` // blocks
+	MinerBps      uint64   `json:"miner_bps"`
+	StakerBps     uint64   `json:"staker_bps"`
+}
+
+// DefaultEmissionSchedule is the genesis schedule used when none is
+// configured.
+func DefaultEmissionSchedule() EmissionSchedule {
+	return EmissionSchedule{
+		InitialReward: new(big.Int).Set(InitialReward),
+		HalvingPeriod: RewardHalvingPeriod,
+		MinerBps:      3000,
+		StakerBps:     3000,
+	}
+}
+
+// Validate checks that the schedule can be applied and paid: it must not
+// emit more than MaxSupply over its lifetime.
+func (s EmissionSchedule) Validate() error {
+	if s.InitialReward == nil || s.InitialReward.Sign() < 0 {
+		return NewError(CodeInvalidArgument, "emission", "initial_reward must be a non-negative integer")
+	}
+	if s.HalvingPeriod == 0 {
+		return NewError(CodeInvalidArgument, "emission", "halving_period must be positive")
+	}
+	if s.MinerBps > 10_000 || s.StakerBps > 10_000 || s.MinerBps+s.StakerBps > 10_000 {
+		return NewError(CodeInvalidArgument, "emission", "miner_bps + staker_bps exceeds 10000")
+	}
+	if total := s.Lifetime(); total.Cmp(new(big.Int).SetUint64(MaxSupply)) > 0 {
+		return NewError(CodeInvalidArgument, "emission", "schedule emits %s over its lifetime, above the supply cap %d", total, MaxSupply)
+	}
+	return nil
+}
+
+// Lifetime returns the total the schedule pays if it is never replaced.
+func (s EmissionSchedule) Lifetime() *big.Int {
+	total := new(big.Int)
+	for r := new(big.Int).Set(s.InitialReward); r.Sign() > 0; r.Rsh(r, 1) {
+		total.Add(total, r)
+	}
+	return total.Mul(total, new(big.Int).SetUint64(s.HalvingPeriod))
+}
+
+// RewardAt returns the reward of the block at height, which must not be
+// below StartHeight.
+func (s EmissionSchedule) RewardAt(height uint64) *big.Int {
+	halves := (height - s.StartHeight) / s.HalvingPeriod
+	if halves >= uint64(s.InitialReward.BitLen()) {
+		return new(big.Int)
+	}
+	return new(big.Int).Rsh(s.InitialReward, uint(halves))
+}
+
+// Split divides reward between the miner, the stakers and the loan pool.
+func (s EmissionSchedule) Split(reward *big.Int) (miner, stakers, loanPool *big.Int) {
+	miner = new(big.Int).Mul(reward, new(big.Int).SetUint64(s.MinerBps))
+	miner.Div(miner, big.NewInt(10_000))
+	stakers = new(big.Int).Mul(reward, new(big.Int).SetUint64(s.StakerBps))
+	stakers.Div(stakers, big.NewInt(10_000))
+	loanPool = new(big.Int).Sub(reward, miner)
+	loanPool.Sub(loanPool, stakers)
+	return miner, stakers, loanPool
+}
+
+// emittedBetween sums the rewards of heights from..to, which lie within the
+// schedule.
+func (s EmissionSchedule) emittedBetween(from, to uint64) *big.Int {
+	total := new(big.Int)
+	for h := from; h <= to; {
+		epochEnd := s.StartHeight + ((h-s.StartHeight)/s.HalvingPeriod+1)*s.HalvingPeriod - 1
+		if epochEnd > to || epochEnd < h {
+			epochEnd = to
+		}
+		r := s.RewardAt(h)
+		if r.Sign() == 0 {
+			break
+		}
+		total.Add(total, r.Mul(r, new(big.Int).SetUint64(epochEnd-h+1)))
+		if epochEnd == to {
+			break
+		}
+		h = epochEnd + 1
+	}
+	return total
+}
+
+// EmissionPolicy is the ordered list of schedules; each applies from its
+// StartHeight until the next one starts.
+type EmissionPolicy struct {
+	Schedules []EmissionSchedule `json:"schedules"`
+}
+
+// At returns the schedule in force at height.
+func (p EmissionPolicy) At(height uint64) EmissionSchedule {
+	s := p.Schedules[0]
+	for _, next := range p.Schedules[1:] {
+		if next.StartHeight > height {
+			break
+		}
+		s = next
+	}
+	return s
+}
+
+// RewardAt returns the reward of the block at height.
+func (p EmissionPolicy) RewardAt(height uint64) *big.Int {
+	return p.At(height).RewardAt(height)
+}
+
+// EmittedThrough returns the rewards of blocks 1..height; the genesis block
+// pays none.
+func (p EmissionPolicy) EmittedThrough(height uint64) *big.Int {
+	total := new(big.Int)
+	for i, s := range p.Schedules {
+		from := s.StartHeight
+		if from < 1 {
+			from = 1
+		}
+		to := height
+		if i+1 < len(p.Schedules) && p.Schedules[i+1].StartHeight-1 < to {
+			to = p.Schedules[i+1].StartHeight - 1
+		}
+		if from > to {
+			continue
+		}
+		total.Add(total, s.emittedBetween(from, to))
+	}
+	return total
+}
+
+// EmissionPoint is one point of a projected supply curve.
+type EmissionPoint struct {
+	Height     uint64   `json:"height"`
+	Reward     *big.Int `json:"reward"`
+	Cumulative *big.Int `json:"cumulative"` // emitted by blocks 1..Height
+}
+
+// Project samples the reward and cumulative emission every step blocks
+// from from to to.
+func (p EmissionPolicy) Project(from, to, step uint64) ([]EmissionPoint, error) {
+	if step == 0 || to < from {
+		return nil, NewError(CodeInvalidArgument, "emission", "need from <= to and a positive step")
+	}
+	if (to-from)/step >= maxEmissionPoints {
+		return nil, NewError(CodeInvalidArgument, "emission", "projection exceeds %d points", maxEmissionPoints)
+	}
+	var out []EmissionPoint
+	for h := from; ; h += step {
+		out = append(out, EmissionPoint{Height: h, Reward: p.RewardAt(h), Cumulative: p.EmittedThrough(h)})
+		if to-h < step {
+			break
+		}
+	}
+	return out, nil
+}
+
+// BlockEmission records the reward paid for a block. Reward is the
+// scheduled reward; the parts are what was minted and add up to it, less
+// Capped, the part withheld because the coin supply reached MaxSupply.
+type BlockEmission struct {
+	Height   uint64   `json:"height"`
+	Reward   *big.Int `json:"reward"`
+	Miner    *big.Int `json:"miner"`
+	Stakers  *big.Int `json:"stakers"`
+	LoanPool *big.Int `json:"loan_pool"`
+	Capped   *big.Int `json:"capped,omitempty"`
+}
+
+// Minted returns the sum of the parts.
+func (e BlockEmission) Minted() *big.Int {
+	m := new(big.Int).Add(e.Miner, e.Stakers)
+	return m.Add(m, e.LoanPool)
+}
+
+// EmissionPolicy returns the policy in effect.
+func (l *Ledger) EmissionPolicy() EmissionPolicy {
+	raw, err := l.GetState([]byte(emissionPolicyKey))
+	if err == nil && len(raw) > 0 {
+		var p EmissionPolicy
+		if json.Unmarshal(raw, &p) == nil && len(p.Schedules) > 0 {
+			return p
+		}
+	}
+	return EmissionPolicy{Schedules: []EmissionSchedule{DefaultEmissionSchedule()}}
+}
+
+// initEmission records the genesis schedule of a new ledger.
+func (l *Ledger) initEmission(s EmissionSchedule) error {
+	s.StartHeight = 0
+	if err := s.Validate(); err != nil {
+		return err
+	}
+	raw, _ := json.Marshal(EmissionPolicy{Schedules: []EmissionSchedule{s}})
+	return l.SetState([]byte(emissionPolicyKey), raw)
+}
+
+// LoadGenesisEmission reads the "emission" section of a genesis file. It
+// returns nil when the path is empty, the file does not exist or it has no
+// emission section, leaving the default schedule in force.
+func LoadGenesisEmission(path string) (*EmissionSchedule, error) {
+	if path == "" {
+		return nil, nil
+	}
+	raw, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var g struct {
+		Emission *EmissionSchedule `json:"emission"`
+	}
+	if err := json.Unmarshal(raw, &g); err != nil {
+		return nil, fmt.Errorf("decode genesis %s: %w", path, err)
+	}
+	if g.Emission != nil {
+		if err := g.Emission.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	return g.Emission, nil
+}
+
+// setEmissionSchedule applies the emission_schedule governance parameter:
+// a JSON EmissionSchedule starting above the head. Schedules that would
+// start at or after it are dropped.
+func setEmissionSchedule(value string) error {
+	var s EmissionSchedule
+	if err := json.Unmarshal([]byte(value), &s); err != nil {
+		return fmt.Errorf("invalid emission schedule: %w", err)
+	}
+	if err := s.Validate(); err != nil {
+		return err
+	}
+	led := CurrentLedger()
+	if led == nil {
+		return errors.New("ledger not initialised")
+	}
+	if head := led.LastHeight(); s.StartHeight <= head {
+		return NewError(CodeFailedPrecondition, "emission", "schedule must start above the head (%d)", head)
+	}
+	p := led.EmissionPolicy()
+	keep := p.Schedules[:0]
+	for _, old := range p.Schedules {
+		if old.StartHeight < s.StartHeight {
+			keep = append(keep, old)
+		}
+	}
+	p.Schedules = append(keep, s)
+	raw, _ := json.Marshal(p)
+	return led.SetState([]byte(emissionPolicyKey), raw)
+}
+
+// payEmission mints the reward of the block at height to its miner, the
+// validators of its sub-blocks and the loan pool, and records it. The parts
+// add up to the reward: what is left from dividing the staker share, and the
+// whole staker share of a block without validators, go to the loan pool. A
+// reward above the headroom under MaxSupply is cut to it. Nothing is minted
+// when a recipient cannot be credited.
+func (l *Ledger) payEmission(height uint64, miner []byte, validators [][]byte, loanPool Address) (BlockEmission, error) {
+	sched := l.EmissionPolicy().At(height)
+	e := BlockEmission{Height: height, Reward: sched.RewardAt(height), Stakers: new(big.Int), Capped: new(big.Int)}
+
+	l.mu.Lock()
+	t := l.Supply[Code]
+	headroom := new(big.Int)
+	if t.Burned <= t.Minted && t.Minted-t.Burned < MaxSupply {
+		headroom.SetUint64(MaxSupply - (t.Minted - t.Burned))
+	}
+	pay := new(big.Int).Set(e.Reward)
+	if pay.Cmp(headroom) > 0 {
+		e.Capped.Sub(pay, headroom)
+		pay.Set(headroom)
+	}
+	var stakerR *big.Int
+	e.Miner, stakerR, e.LoanPool = sched.Split(pay)
+
+	type credit struct {
+		to  Address
+		amt uint64
+	}
+	credits := []credit{{emissionAddress(miner), e.Miner.Uint64()}}
+	if len(validators) > 0 {
+		per := new(big.Int).Div(stakerR, big.NewInt(int64(len(validators))))
+		for _, v := range validators {
+			credits = append(credits, credit{emissionAddress(v), per.Uint64()})
+			e.Stakers.Add(e.Stakers, per)
+		}
+	}
+	e.LoanPool.Add(e.LoanPool, stakerR.Sub(stakerR, e.Stakers))
+	credits = append(credits, credit{loanPool, e.LoanPool.Uint64()})
+	for _, c := range credits {
+		if c.amt > 0 && c.to == AddressZero {
+			l.mu.Unlock()
+			return e, fmt.Errorf("emission at %d: %w", height, ErrZeroAddress)
+		}
+	}
+	for _, c := range credits {
+		if c.amt > 0 {
+			// within MaxSupply, so the supply cannot overflow
+			_ = l.mint(CoinKey(c.to), c.amt)
+		}
+	}
+	l.mu.Unlock()
+
+	if e.Capped.Sign() > 0 {
+		logrus.Warnf("emission at %d: %s of %s withheld at the supply cap", height, e.Capped, e.Reward)
+	}
+	if loanPool == LoanPoolAccount && e.LoanPool.Sign() > 0 {
+		recordTreasury(l, TreasuryBlockReward, AddressZero, e.LoanPool.Uint64(), "")
+	}
+	return e, l.recordEmission(e)
+}
+
+// emissionAddress is the account a block reward part is minted to, as
+// MintBig addresses it.
+func emissionAddress(b []byte) Address {
+	var a Address
+	copy(a[:], b)
+	return a
+}
+
+// recordEmission stores the emission of a block and adds it to the total.
+func (l *Ledger) recordEmission(e BlockEmission) error {
+	raw, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	total := new(big.Int).Add(l.TotalEmitted(), e.Minted())
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.State == nil {
+		l.State = make(map[string][]byte)
+	}
+	l.State[string(emissionBlockKey(e.Height))] = raw
+	l.State[emissionTotalKey] = []byte(total.String())
+	return nil
+}
+
+// BlockEmission returns the recorded emission of the block at height.
+func (l *Ledger) BlockEmission(height uint64) (BlockEmission, error) {
+	var e BlockEmission
+	raw, err := l.GetState(emissionBlockKey(height))
+	if err != nil {
+		return e, NewError(CodeNotFound, "emission", "no emission recorded at height %d", height)
+	}
+	if err := json.Unmarshal(raw, &e); err != nil {
+		return e, fmt.Errorf("decode emission: %w", err)
+	}
+	return e, nil
+}
+
+// TotalEmitted returns the sum of all recorded block emissions.
+func (l *Ledger) TotalEmitted() *big.Int {
+	total := new(big.Int)
+	if raw, err := l.GetState([]byte(emissionTotalKey)); err == nil {
+		total.SetString(string(raw), 10)
+	}
+	return total
+}
+
+// VerifyEmission checks that every emission recorded at heights from..to
+// pays exactly the policy's reward, less any part capped at MaxSupply.
+func (l *Ledger) VerifyEmission(from, to uint64) error {
+	p := l.EmissionPolicy()
+	it := l.PrefixIterator([]byte("emission:block:"))
+	for it.Next() {
+		var e BlockEmission
+		if err := json.Unmarshal(it.Value(), &e); err != nil {
+			return fmt.Errorf("decode emission %s: %w", it.Key(), err)
+		}
+		if e.Height < from || e.Height > to {
+			continue
+		}
+		paid := e.Minted()
+		if e.Capped != nil {
+			paid.Add(paid, e.Capped)
+		}
+		if want := p.RewardAt(e.Height); e.Reward.Cmp(want) != 0 || paid.Cmp(want) != 0 {
+			return fmt.Errorf("%w: height %d paid %s of %s, policy reward %s", ErrEmissionMismatch, e.Height, e.Minted(), e.Reward, want)
+		}
+	}
+	return nil
+}
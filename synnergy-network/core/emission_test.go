@@ -0,0 +1,219 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func testSchedule(start, reward, halving uint64) EmissionSchedule {
+	return EmissionSchedule{StartHeight: start, InitialReward: new(big.Int).SetUint64(reward),
+		HalvingPeriod: halving, MinerBps: 3000, StakerBps: 3000}
+}
+
+func TestEmissionScheduleRewardAt(t *testing.T) {
+	s := testSchedule(100, 1_000, 10)
+	for _, c := range []struct{ height, want uint64 }{
+		{100, 1_000}, {109, 1_000}, {110, 500}, {125, 250}, {190, 1}, {200, 0}, {10_000, 0},
+	} {
+		if got := s.RewardAt(c.height); got.Uint64() != c.want {
+			t.Errorf("RewardAt(%d) = %s want %d", c.height, got, c.want)
+		}
+	}
+	miner, stakers, loan := s.Split(big.NewInt(1_001))
+	if miner.Int64() != 300 || stakers.Int64() != 300 || loan.Int64() != 401 {
+		t.Fatalf("split of 1001: %s %s %s", miner, stakers, loan)
+	}
+}
+
+func TestEmittedThroughAcrossSchedules(t *testing.T) {
+	p := EmissionPolicy{Schedules: []EmissionSchedule{
+		testSchedule(0, 100, 10),
+		testSchedule(25, 40, 5),
+	}}
+	if got := p.RewardAt(24); got.Int64() != 25 {
+		t.Fatalf("last reward of the first schedule: %s", got)
+	}
+	if got := p.RewardAt(25); got.Int64() != 40 {
+		t.Fatalf("first reward of the second schedule: %s", got)
+	}
+	for _, h := range []uint64{0, 1, 9, 10, 24, 25, 29, 30, 60, 500} {
+		want := new(big.Int)
+		for i := uint64(1); i <= h; i++ {
+			want.Add(want, p.RewardAt(i))
+		}
+		if got := p.EmittedThrough(h); got.Cmp(want) != 0 {
+			t.Errorf("EmittedThrough(%d) = %s want %s", h, got, want)
+		}
+	}
+	// 9×100 + 10×50 + 5×25, then 5×40 + 20
+	if got := p.EmittedThrough(30); got.Int64() != 1_745 {
+		t.Fatalf("EmittedThrough(30) = %s", got)
+	}
+}
+
+func TestSetEmissionSchedule(t *testing.T) {
+	l := newDepositLedger(t)
+	prev := globalLedger
+	globalLedger = l
+	t.Cleanup(func() { globalLedger = prev })
+	l.Blocks = []*Block{{Header: BlockHeader{Height: 0}}, {Header: BlockHeader{Height: 1}}}
+
+	set := func(s EmissionSchedule) error {
+		raw, _ := json.Marshal(s)
+		return setEmissionSchedule(string(raw))
+	}
+	if err := setEmissionSchedule("{"); err == nil {
+		t.Fatal("malformed schedule accepted")
+	}
+	bad := testSchedule(10, 1, 10)
+	bad.MinerBps = 9_000
+	if err := set(bad); ErrorCodeOf(err) != CodeInvalidArgument {
+		t.Fatalf("split above 100%%: %v", err)
+	}
+	if err := set(testSchedule(1, 1, 10)); ErrorCodeOf(err) != CodeFailedPrecondition {
+		t.Fatalf("schedule starting at the head: %v", err)
+	}
+	if err := set(testSchedule(20, 10, 10)); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	if got := l.EmissionPolicy().RewardAt(20); got.Int64() != 10 {
+		t.Fatalf("reward after the change: %s", got)
+	}
+	if got := l.EmissionPolicy().RewardAt(19); got.Cmp(InitialReward) != 0 {
+		t.Fatalf("reward before the change: %s", got)
+	}
+	// a schedule starting earlier replaces the pending one
+	if err := set(testSchedule(15, 7, 10)); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	p := l.EmissionPolicy()
+	if len(p.Schedules) != 2 || p.Schedules[1].StartHeight != 15 || p.RewardAt(30).Int64() != 3 {
+		t.Fatalf("policy %+v", p)
+	}
+}
+
+func TestPayEmissionPaysTheWholeRewardUpToMaxSupply(t *testing.T) {
+	l := newDepositLedger(t)
+	if err := l.initEmission(testSchedule(0, 1_001, 100)); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	miner, v1, v2, pool := Address{0xa1}, Address{0xa2}, Address{0xa3}, Address{0xa4}
+
+	e, err := l.payEmission(1, miner[:], [][]byte{v1[:], v2[:]}, pool)
+	if err != nil {
+		t.Fatalf("pay: %v", err)
+	}
+	if l.BalanceOf(miner) != 300 || l.BalanceOf(v1) != 150 || l.BalanceOf(v2) != 150 || l.BalanceOf(pool) != 401 {
+		t.Fatalf("balances %d %d %d %d", l.BalanceOf(miner), l.BalanceOf(v1), l.BalanceOf(v2), l.BalanceOf(pool))
+	}
+	// without validators the staker share goes to the loan pool
+	if e, err = l.payEmission(2, miner[:], nil, pool); err != nil || e.LoanPool.Int64() != 701 {
+		t.Fatalf("block without validators: %+v %v", e, err)
+	}
+	if got := l.TotalEmitted(); got.Int64() != 2_002 || got.Uint64() != l.AssetSupply(Code) {
+		t.Fatalf("recorded total %s, minted supply %d", got, l.AssetSupply(Code))
+	}
+	if err := l.VerifyEmission(0, 10); err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+
+	// a recipient that cannot be credited fails the whole reward
+	before := l.AssetSupply(Code)
+	if _, err := l.payEmission(3, nil, nil, pool); !errors.Is(err, ErrZeroAddress) {
+		t.Fatalf("reward to the zero address: %v", err)
+	}
+	if l.AssetSupply(Code) != before {
+		t.Fatal("part of a failed reward was minted")
+	}
+
+	// emission stops at MaxSupply
+	if err := l.Mint(Address{0xb1}, MaxSupply-before-500); err != nil {
+		t.Fatal(err)
+	}
+	if e, err = l.payEmission(3, miner[:], nil, pool); err != nil || e.Minted().Int64() != 500 || e.Capped.Int64() != 501 {
+		t.Fatalf("reward at the cap: %+v %v", e, err)
+	}
+	if e, err = l.payEmission(4, miner[:], nil, pool); err != nil || e.Minted().Sign() != 0 || e.Capped.Int64() != 1_001 {
+		t.Fatalf("reward above the cap: %+v %v", e, err)
+	}
+	if l.AssetSupply(Code) != MaxSupply {
+		t.Fatalf("supply %d", l.AssetSupply(Code))
+	}
+	if err := l.VerifyEmission(0, 10); err != nil {
+		t.Fatalf("verify capped blocks: %v", err)
+	}
+
+	// a block that paid less than its reward without reaching the cap
+	short := BlockEmission{Height: 5, Reward: big.NewInt(1_001), Miner: big.NewInt(1), Stakers: new(big.Int), LoanPool: new(big.Int)}
+	if err := l.recordEmission(short); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.VerifyEmission(5, 5); !errors.Is(err, ErrEmissionMismatch) {
+		t.Fatalf("verify underpaid block: %v", err)
+	}
+	// blocks recorded a reward the policy now in state does not schedule
+	if err := l.initEmission(testSchedule(0, 1_000, 100)); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.VerifyEmission(0, 4); !errors.Is(err, ErrEmissionMismatch) {
+		t.Fatalf("verify against a changed policy: %v", err)
+	}
+}
+
+func TestEmissionSchedulesMustBePayable(t *testing.T) {
+	if err := DefaultEmissionSchedule().Validate(); err != nil {
+		t.Fatalf("default schedule: %v", err)
+	}
+	for name, s := range map[string]EmissionSchedule{
+		"reward above the cap":   testSchedule(0, MaxSupply+1, 1),
+		"lifetime above the cap": testSchedule(0, 1_000, MaxSupply/1_000),
+		"overflowing split":      {InitialReward: big.NewInt(1), HalvingPeriod: 1, MinerBps: math.MaxUint64, StakerBps: 2},
+	} {
+		if err := s.Validate(); ErrorCodeOf(err) != CodeInvalidArgument {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+
+	// the sample genesis allocates and emits within MaxSupply
+	path := filepath.Join("..", "cmd", "config", "genesis.json")
+	s, err := LoadGenesisEmission(path)
+	if err != nil || s == nil {
+		t.Fatalf("genesis emission: %v", err)
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var g struct {
+		InitialBalances map[string]uint64 `json:"initial_balances"`
+	}
+	if err := json.Unmarshal(raw, &g); err != nil {
+		t.Fatal(err)
+	}
+	total := s.Lifetime()
+	for _, v := range g.InitialBalances {
+		total.Add(total, new(big.Int).SetUint64(v))
+	}
+	if total.Cmp(new(big.Int).SetUint64(MaxSupply)) > 0 {
+		t.Fatalf("genesis balances and emission total %s, above %d", total, MaxSupply)
+	}
+}
+
+func TestMintBigRejectsAmountsOutsideUint64(t *testing.T) {
+	l := newDepositLedger(t)
+	addr := Address{0xb1}
+	if err := l.MintBig(addr[:], new(big.Int).Lsh(big.NewInt(1), 64)); ErrorCodeOf(err) != CodeInvalidArgument {
+		t.Fatalf("mint of 2^64: %v", err)
+	}
+	if err := l.MintBig(addr[:], big.NewInt(-1)); ErrorCodeOf(err) != CodeInvalidArgument {
+		t.Fatalf("negative mint: %v", err)
+	}
+	if err := l.MintBig(addr[:], big.NewInt(5)); err != nil || l.BalanceOf(addr) != 5 {
+		t.Fatalf("mint: %v balance %d", err, l.BalanceOf(addr))
+	}
+}
@@ -156,6 +156,9 @@ func (r *EmploymentRegistry) PaySalary(id string) error {
 	if c.Paid {
 		return errors.New("already paid")
 	}
+	if _, err := r.loadStream(id); err == nil {
+		return errors.New("salary is streamed; use ClaimSalary")
+	}
 	total := uint64(c.HoursWorked) * c.SalaryPerHour
 	if err := r.led.Transfer(c.Employer, c.Employee, total); err != nil {
 		return err
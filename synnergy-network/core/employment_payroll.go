@@ -0,0 +1,366 @@
+package core
+
+// employment_payroll.go – continuous salary streaming for employment contracts.
+//
+// Once both parties have signed a contract the employer may start a salary
+// stream. Salary accrues every second between the contract start and end at
+// RatePerSecond and the employee can claim the accrued amount at any time.
+// Each claim withholds the configured percentages (in basis points) and
+// routes them to the designated authority addresses, pays the net amount to
+// the employee and issues a payslip. Payslips are pinned through the storage
+// module when it is available and always indexed on the ledger.
+//
+// Terminating a stream settles everything accrued up to that moment and
+// closes the contract.
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Withholding routes a share of each salary claim to an authority.
+type Withholding struct {
+	Label     string  `json:"label"`
+	Authority Address `json:"authority"`
+	Bps       uint64  `json:"bps"`
+}
+
+// SalaryStream tracks continuous salary accrual for a contract.
+type SalaryStream struct {
+	JobID         string        `json:"job_id"`
+	Employer      Address       `json:"employer"`
+	Employee      Address       `json:"employee"`
+	RatePerSecond uint64        `json:"rate_per_second"`
+	Withholdings  []Withholding `json:"withholdings,omitempty"`
+	Start         time.Time     `json:"start"`
+	End           time.Time     `json:"end"`
+	LastClaim     time.Time     `json:"last_claim"`
+	Claimed       uint64        `json:"claimed"`
+	Withheld      uint64        `json:"withheld"`
+	Active        bool          `json:"active"`
+	TerminatedAt  time.Time     `json:"terminated_at,omitempty"`
+}
+
+// PayslipLine is one withholding entry on a payslip.
+type PayslipLine struct {
+	Label     string  `json:"label"`
+	Authority Address `json:"authority"`
+	Amount    uint64  `json:"amount"`
+}
+
+// Payslip documents one salary settlement.
+type Payslip struct {
+	JobID       string        `json:"job_id"`
+	Employer    Address       `json:"employer"`
+	Employee    Address       `json:"employee"`
+	PeriodStart time.Time     `json:"period_start"`
+	PeriodEnd   time.Time     `json:"period_end"`
+	Gross       uint64        `json:"gross"`
+	Withholding []PayslipLine `json:"withholding,omitempty"`
+	Net         uint64        `json:"net"`
+	Final       bool          `json:"final,omitempty"`
+	CID         string        `json:"cid,omitempty"`
+	Issued      time.Time     `json:"issued"`
+}
+
+func streamKey(id string) []byte     { return []byte("payroll:stream:" + id) }
+func payslipPrefix(id string) []byte { return []byte("payroll:slip:" + id + ":") }
+func payslipKey(id string, t time.Time) []byte {
+	return append(payslipPrefix(id), fmt.Sprintf("%020d", t.UnixNano())...)
+}
+
+func validateWithholdings(ws []Withholding) error {
+	var total uint64
+	for _, w := range ws {
+		if w.Bps == 0 {
+			return fmt.Errorf("withholding %q has zero rate", w.Label)
+		}
+		if w.Authority == AddressZero {
+			return fmt.Errorf("withholding %q has no authority address", w.Label)
+		}
+		total += w.Bps
+	}
+	if total > bpsDenom {
+		return fmt.Errorf("withholding totals %d bps, above %d", total, bpsDenom)
+	}
+	return nil
+}
+
+func (r *EmploymentRegistry) loadStream(id string) (*SalaryStream, error) {
+	raw, err := r.led.GetState(streamKey(id))
+	if err != nil || len(raw) == 0 {
+		return nil, ErrNotFound
+	}
+	var s SalaryStream
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *EmploymentRegistry) saveStream(s *SalaryStream) error {
+	b, _ := json.Marshal(s)
+	return r.led.SetState(streamKey(s.JobID), b)
+}
+
+func (r *EmploymentRegistry) loadJob(id string) (*EmploymentContract, error) {
+	raw, err := r.led.GetState([]byte(id))
+	if err != nil || len(raw) == 0 {
+		return nil, ErrNotFound
+	}
+	var c EmploymentContract
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// StartSalaryStream converts a signed contract into a salary stream paying
+// ratePerSecond. Only the employer may start it.
+func (r *EmploymentRegistry) StartSalaryStream(id string, employer Address, ratePerSecond uint64, ws []Withholding) (*SalaryStream, error) {
+	if ratePerSecond == 0 {
+		return nil, errors.New("rate must be >0")
+	}
+	if err := validateWithholdings(ws); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c, err := r.loadJob(id)
+	if err != nil {
+		return nil, err
+	}
+	if c.Employer != employer {
+		return nil, ErrUnauthorized
+	}
+	if !c.EmployerSign || !c.EmployeeSign {
+		return nil, errors.New("contract not signed by both parties")
+	}
+	if c.Paid {
+		return nil, errors.New("contract closed")
+	}
+	if _, err := r.loadStream(id); err == nil {
+		return nil, errors.New("stream already exists")
+	}
+	s := &SalaryStream{
+		JobID:         id,
+		Employer:      c.Employer,
+		Employee:      c.Employee,
+		RatePerSecond: ratePerSecond,
+		Withholdings:  ws,
+		Start:         c.Start,
+		End:           c.End,
+		LastClaim:     c.Start,
+		Active:        true,
+	}
+	if err := r.saveStream(s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// SetWithholdings replaces the withholding schedule of a stream. Amounts
+// already accrued are settled under the new schedule on the next claim.
+func (r *EmploymentRegistry) SetWithholdings(id string, employer Address, ws []Withholding) error {
+	if err := validateWithholdings(ws); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, err := r.loadStream(id)
+	if err != nil {
+		return err
+	}
+	if s.Employer != employer {
+		return ErrUnauthorized
+	}
+	if !s.Active {
+		return errors.New("stream terminated")
+	}
+	s.Withholdings = ws
+	return r.saveStream(s)
+}
+
+// accrualEnd is the last instant salary accrues for s at now.
+func (s *SalaryStream) accrualEnd(now time.Time) time.Time {
+	end := now
+	if s.End.Before(end) {
+		end = s.End
+	}
+	if !s.TerminatedAt.IsZero() && s.TerminatedAt.Before(end) {
+		end = s.TerminatedAt
+	}
+	return end
+}
+
+// Accrued returns the unclaimed gross salary of s at now.
+func (s *SalaryStream) Accrued(now time.Time) uint64 {
+	end := s.accrualEnd(now)
+	if !end.After(s.LastClaim) {
+		return 0
+	}
+	return uint64(end.Sub(s.LastClaim)/time.Second) * s.RatePerSecond
+}
+
+// StreamAccrued returns the claimable gross salary of a stream.
+func (r *EmploymentRegistry) StreamAccrued(id string) (uint64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	s, err := r.loadStream(id)
+	if err != nil {
+		return 0, err
+	}
+	return s.Accrued(time.Now().UTC()), nil
+}
+
+// GetSalaryStream returns a stream by job ID.
+func (r *EmploymentRegistry) GetSalaryStream(id string) (*SalaryStream, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.loadStream(id)
+}
+
+// ClaimSalary pays the employee everything accrued so far, net of
+// withholding, and returns the payslip.
+func (r *EmploymentRegistry) ClaimSalary(id string, employee Address) (*Payslip, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, err := r.loadStream(id)
+	if err != nil {
+		return nil, err
+	}
+	if s.Employee != employee {
+		return nil, ErrUnauthorized
+	}
+	if !s.Active {
+		return nil, errors.New("stream terminated")
+	}
+	return r.settle(s, time.Now().UTC(), false)
+}
+
+// TerminateSalaryStream settles the accrued salary and closes the stream and
+// its contract. Either party may terminate.
+func (r *EmploymentRegistry) TerminateSalaryStream(id string, caller Address) (*Payslip, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, err := r.loadStream(id)
+	if err != nil {
+		return nil, err
+	}
+	if caller != s.Employer && caller != s.Employee {
+		return nil, ErrUnauthorized
+	}
+	if !s.Active {
+		return nil, errors.New("stream terminated")
+	}
+	now := time.Now().UTC()
+	s.TerminatedAt = now
+	slip, err := r.settle(s, now, true)
+	if err != nil {
+		return nil, err
+	}
+	c, err := r.loadJob(id)
+	if err != nil {
+		return nil, err
+	}
+	c.Paid = true
+	if now.Before(c.End) {
+		c.End = now
+	}
+	b, _ := json.Marshal(c)
+	if err := r.led.SetState([]byte(id), b); err != nil {
+		return nil, err
+	}
+	return slip, nil
+}
+
+// settle pays the accrued salary of s up to now. The stream is saved with
+// Active cleared when final is set.
+func (r *EmploymentRegistry) settle(s *SalaryStream, now time.Time, final bool) (*Payslip, error) {
+	gross := s.Accrued(now)
+	if gross == 0 && !final {
+		return nil, errors.New("nothing accrued")
+	}
+	end := s.accrualEnd(now)
+	if end.Before(s.LastClaim) {
+		end = s.LastClaim
+	}
+	// only whole seconds accrue; carry the fraction to the next claim
+	end = s.LastClaim.Add(end.Sub(s.LastClaim).Truncate(time.Second))
+	slip := &Payslip{
+		JobID:       s.JobID,
+		Employer:    s.Employer,
+		Employee:    s.Employee,
+		PeriodStart: s.LastClaim,
+		PeriodEnd:   end,
+		Gross:       gross,
+		Final:       final,
+		Issued:      now,
+	}
+	net := gross
+	for _, w := range s.Withholdings {
+		amt := gross/bpsDenom*w.Bps + gross%bpsDenom*w.Bps/bpsDenom
+		if amt == 0 {
+			continue
+		}
+		if err := r.led.Transfer(s.Employer, w.Authority, amt); err != nil {
+			return nil, fmt.Errorf("withholding %q: %w", w.Label, err)
+		}
+		slip.Withholding = append(slip.Withholding, PayslipLine{Label: w.Label, Authority: w.Authority, Amount: amt})
+		net -= amt
+	}
+	if net > 0 {
+		if err := r.led.Transfer(s.Employer, s.Employee, net); err != nil {
+			return nil, err
+		}
+	}
+	slip.Net = net
+	s.LastClaim = end
+	s.Claimed += net
+	s.Withheld += gross - net
+	if final {
+		s.Active = false
+	}
+	if err := r.saveStream(s); err != nil {
+		return nil, err
+	}
+	if err := r.storePayslip(slip); err != nil {
+		return nil, err
+	}
+	return slip, nil
+}
+
+// storePayslip pins the payslip through the storage module when available
+// and indexes it on the ledger.
+func (r *EmploymentRegistry) storePayslip(p *Payslip) error {
+	raw, _ := json.Marshal(p)
+	if IPFS() != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		cid, err := AddFile(ctx, raw, p.Employer)
+		cancel()
+		if err == nil {
+			p.CID = cid
+			raw, _ = json.Marshal(p)
+		}
+	}
+	return r.led.SetState(payslipKey(p.JobID, p.Issued), raw)
+}
+
+// Payslips lists the payslips of a contract, oldest first.
+func (r *EmploymentRegistry) Payslips(id string) ([]Payslip, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	it := r.led.PrefixIterator(payslipPrefix(id))
+	var out []Payslip
+	for it.Next() {
+		var p Payslip
+		if err := json.Unmarshal(it.Value(), &p); err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	return out, it.Error()
+}
@@ -0,0 +1,49 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSalaryStreamAccrual(t *testing.T) {
+	start := time.Unix(1_700_000_000, 0).UTC()
+	s := &SalaryStream{
+		RatePerSecond: 3,
+		Start:         start,
+		End:           start.Add(time.Hour),
+		LastClaim:     start,
+		Active:        true,
+	}
+
+	if got := s.Accrued(start.Add(-time.Minute)); got != 0 {
+		t.Fatalf("accrued before start = %d, want 0", got)
+	}
+	if got := s.Accrued(start.Add(10*time.Second + 900*time.Millisecond)); got != 30 {
+		t.Fatalf("accrued after 10.9s = %d, want 30", got)
+	}
+	if got := s.Accrued(start.Add(2 * time.Hour)); got != 3*3600 {
+		t.Fatalf("accrual must stop at contract end, got %d", got)
+	}
+
+	s.TerminatedAt = start.Add(time.Minute)
+	if got := s.Accrued(start.Add(2 * time.Hour)); got != 3*60 {
+		t.Fatalf("accrual must stop at termination, got %d", got)
+	}
+}
+
+func TestValidateWithholdings(t *testing.T) {
+	auth := Address{1}
+	ok := []Withholding{{Label: "income", Authority: auth, Bps: 2000}, {Label: "social", Authority: auth, Bps: 800}}
+	if err := validateWithholdings(ok); err != nil {
+		t.Fatalf("valid schedule rejected: %v", err)
+	}
+	for name, ws := range map[string][]Withholding{
+		"over 100%":    {{Label: "a", Authority: auth, Bps: 6000}, {Label: "b", Authority: auth, Bps: 5000}},
+		"zero rate":    {{Label: "a", Authority: auth}},
+		"no authority": {{Label: "a", Bps: 100}},
+	} {
+		if err := validateWithholdings(ws); err == nil {
+			t.Fatalf("%s: expected error", name)
+		}
+	}
+}
@@ -0,0 +1,481 @@
+package core
+
+// error_codes.go – shared error taxonomy for daemons, HTTP servers and the CLI.
+//
+// Every failure that leaves a process is described by an *Error: a stable
+// Code from the list below, the Module that raised it, a human-readable
+// Message, whether the same request may succeed if Retryable later, and an
+// optional Details map. SDKs switch on Code; the message is for people and
+// may change between releases.
+//
+// Sentinel errors of the core packages are mapped to codes by errorTable.
+// ClassifyError walks the error chain, so wrapped sentinels
+// (fmt.Errorf("%w: …", ErrNotFound)) keep their code.
+//
+// Wire format, used both as an HTTP body and as the "error" member of the
+// JSON-over-TCP daemon protocol:
+//
+//	{"error": {"code": "NOT_FOUND", "module": "ledger",
+//	           "message": "resource not found", "retryable": false,
+//	           "details": {"height": 12}}}
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+// ErrorCode is a stable, machine-readable failure class.
+type ErrorCode string
+
+const (
+	CodeInvalidArgument    ErrorCode = "INVALID_ARGUMENT"
+	CodeNotFound           ErrorCode = "NOT_FOUND"
+	CodeAlreadyExists      ErrorCode = "ALREADY_EXISTS"
+	CodeUnauthenticated    ErrorCode = "UNAUTHENTICATED"
+	CodePermissionDenied   ErrorCode = "PERMISSION_DENIED"
+	CodeInsufficientFunds  ErrorCode = "INSUFFICIENT_FUNDS"
+	CodeFailedPrecondition ErrorCode = "FAILED_PRECONDITION"
+	CodeConflict           ErrorCode = "CONFLICT"
+	CodeRateLimited        ErrorCode = "RATE_LIMITED"
+	CodeRejected           ErrorCode = "REJECTED"
+	CodeTimeout            ErrorCode = "TIMEOUT"
+	CodeUnavailable        ErrorCode = "UNAVAILABLE"
+	CodeInternal           ErrorCode = "INTERNAL"
+)
+
+// ErrInsufficientBalance is returned when an account cannot cover a debit.
+var ErrInsufficientBalance = errors.New("insufficient balance")
+
+// codeStatus maps each code to the HTTP status servers answer with.
+var codeStatus = map[ErrorCode]int{
+	CodeInvalidArgument:    http.StatusBadRequest,
+	CodeNotFound:           http.StatusNotFound,
+	CodeAlreadyExists:      http.StatusConflict,
+	CodeUnauthenticated:    http.StatusUnauthorized,
+	CodePermissionDenied:   http.StatusForbidden,
+	CodeInsufficientFunds:  http.StatusPaymentRequired,
+	CodeFailedPrecondition: http.StatusPreconditionFailed,
+	CodeConflict:           http.StatusConflict,
+	CodeRateLimited:        http.StatusTooManyRequests,
+	CodeRejected:           http.StatusUnprocessableEntity,
+	CodeTimeout:            http.StatusGatewayTimeout,
+	CodeUnavailable:        http.StatusServiceUnavailable,
+	CodeInternal:           http.StatusInternalServerError,
+}
+
+// Error is the structured error carried across process boundaries.
+type Error struct {
+	Code      ErrorCode              `json:"code"`
+	Module    string                 `json:"module"`
+	Message   string                 `json:"message"`
+	Retryable bool                   `json:"retryable"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+
+	cause error
+}
+
+// NewError builds an *Error with a formatted message. Retryable defaults to
+// the usual behaviour of code.
+func NewError(code ErrorCode, module, format string, args ...interface{}) *Error {
+	return &Error{Code: code, Module: module, Message: fmt.Sprintf(format, args...), Retryable: retryableCode(code)}
+}
+
+func (e *Error) Error() string {
+	if e.Module == "" {
+		return e.Message
+	}
+	return e.Module + ": " + e.Message
+}
+
+// Unwrap returns the error e was classified from, if any.
+func (e *Error) Unwrap() error { return e.cause }
+
+// Is matches another *Error with the same code, so callers can test
+// errors.Is(err, &Error{Code: CodeNotFound}).
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code && (t.Module == "" || t.Module == e.Module)
+}
+
+// WithDetail sets one details entry and returns e.
+func (e *Error) WithDetail(key string, value interface{}) *Error {
+	if e.Details == nil {
+		e.Details = make(map[string]interface{})
+	}
+	e.Details[key] = value
+	return e
+}
+
+// HTTPStatus returns the status code servers use for e.
+func (e *Error) HTTPStatus() int {
+	if s, ok := codeStatus[e.Code]; ok {
+		return s
+	}
+	return http.StatusInternalServerError
+}
+
+func retryableCode(c ErrorCode) bool {
+	switch c {
+	case CodeRateLimited, CodeTimeout, CodeUnavailable:
+		return true
+	}
+	return false
+}
+
+// errorMapping assigns a code to a core sentinel error.
+type errorMapping struct {
+	err       error
+	code      ErrorCode
+	module    string
+	retryable bool
+}
+
+// errorTable maps the exported sentinel errors of core. Entries are checked
+// in order with errors.Is. Retryable marks failures that clear by
+// themselves, e.g. a cooldown or timelock running out.
+var errorTable = []errorMapping{
+	// generic
+	{ErrNotFound, CodeNotFound, "core", false},
+	{ErrInsufficientBalance, CodeInsufficientFunds, "ledger", false},
+	{ErrUnauthorized, CodePermissionDenied, "crosschain", false},
+	{ErrInvalidLedger, CodeInvalidArgument, "ledger", false},
+	{ErrInvalidNode, CodeInvalidArgument, "network", false},
+
+	// addresses
+	{ErrAddressFormat, CodeInvalidArgument, "address", false},
+	{ErrAddressChecksum, CodeInvalidArgument, "address", false},
+	{ErrAddressNetwork, CodeInvalidArgument, "address", false},
+	{ErrAddressInUse, CodeAlreadyExists, "vm", false},
+
+	// ledger and state
+	{ErrFenced, CodeUnavailable, "ledger", true},
+	{ErrNotArchive, CodeFailedPrecondition, "ledger", false},
+	{ErrSnapshotRoot, CodeRejected, "ledger", false},
+	{ErrUTXOJournalTooShort, CodeFailedPrecondition, "ledger", false},
+	{ErrNotProposer, CodePermissionDenied, "consensus", false},
+	{ErrBeaconMismatch, CodeRejected, "consensus", false},
+
+	// virtual machine and contracts
+	{ErrMemoryLimit, CodeRejected, "vm", false},
+	{ErrStackUnderflow, CodeRejected, "vm", false},
+	{ErrStop, CodeInternal, "vm", false},
+	{ErrContractPaused, CodeFailedPrecondition, "contracts", true},
+	{ErrNotUpgradeAdmin, CodePermissionDenied, "contracts", false},
+
+	// security and compliance
+	{ErrAddrBlocked, CodePermissionDenied, "security", false},
+	{ErrIPBlocked, CodePermissionDenied, "security", false},
+	{ErrTokenBlocked, CodePermissionDenied, "security", false},
+	{ErrAddressSanctioned, CodePermissionDenied, "sanctions", false},
+	{ErrAddressFrozen, CodePermissionDenied, "sanctions", false},
+	{ErrTxHighRisk, CodeRejected, "compliance", false},
+	{ErrTxQuarantined, CodeFailedPrecondition, "compliance", true},
+	{ErrCredentialRevoked, CodePermissionDenied, "compliance", false},
+	{ErrIssuerExpired, CodePermissionDenied, "compliance", false},
+	{ErrIssuerJurisdiction, CodePermissionDenied, "compliance", false},
+	{ErrIssuerRevoked, CodePermissionDenied, "compliance", false},
+	{ErrIssuerUnknown, CodeNotFound, "compliance", false},
+	{ErrErasureDone, CodeAlreadyExists, "gdpr", false},
+	{ErrErasureSignature, CodeUnauthenticated, "gdpr", false},
+	{ErrDataAccessDenied, CodePermissionDenied, "data", false},
+	{ErrDataAccessExpired, CodePermissionDenied, "data", false},
+	{ErrBioMismatch, CodeUnauthenticated, "biometrics", false},
+
+	// accounts and wallets
+	{ErrSessionKeyExpired, CodeUnauthenticated, "wallet", false},
+	{ErrSessionKeyPolicy, CodePermissionDenied, "wallet", false},
+	{ErrNotGuardian, CodePermissionDenied, "recovery", false},
+	{ErrRecoveryFactor, CodeUnauthenticated, "recovery", false},
+	{ErrRecoveryLocked, CodePermissionDenied, "recovery", false},
+	{ErrRecoverySignature, CodeUnauthenticated, "recovery", false},
+	{ErrRecoveryTimelock, CodeFailedPrecondition, "recovery", true},
+
+	// cross-chain and sidechains
+	{ErrBridgePaused, CodeUnavailable, "bridge", true},
+	{ErrNotBridgeGuardian, CodePermissionDenied, "bridge", false},
+	{ErrInvalidProof, CodeRejected, "bridge", false},
+	{ErrNoActiveConnection, CodeUnavailable, "crosschain", true},
+	{ErrCCSNNotFound, CodeNotFound, "crosschain", false},
+	{ErrSidechainFrozen, CodeUnavailable, "sidechain", true},
+
+	// governance and DAOs
+	{ErrAlreadyQueued, CodeAlreadyExists, "governance", false},
+	{ErrNotQueued, CodeFailedPrecondition, "governance", false},
+	{ErrNotReady, CodeFailedPrecondition, "governance", true},
+	{ErrExpired, CodeFailedPrecondition, "governance", false},
+	{ErrDAOExists, CodeAlreadyExists, "dao", false},
+	{ErrDAONotFound, CodeNotFound, "dao", false},
+	{ErrMemberExists, CodeAlreadyExists, "dao", false},
+	{ErrMemberMissing, CodeNotFound, "dao", false},
+	{ErrACMemberExists, CodeAlreadyExists, "dao", false},
+	{ErrACMemberNotFound, CodeNotFound, "dao", false},
+	{ErrACNotTokenHolder, CodePermissionDenied, "dao", false},
+
+	// markets and DeFi
+	{ErrAuctionEnded, CodeFailedPrecondition, "marketplace", false},
+	{ErrAuctionRunning, CodeFailedPrecondition, "marketplace", true},
+	{ErrBidTooLow, CodeInvalidArgument, "marketplace", false},
+	{ErrInvalidState, CodeFailedPrecondition, "marketplace", false},
+	{ErrEscrowClosed, CodeFailedPrecondition, "escrow", false},
+	{ErrEscrowDisputed, CodeFailedPrecondition, "escrow", true},
+	{ErrFlashLoanNotRepaid, CodeRejected, "amm", false},
+	{ErrPoolLocked, CodeConflict, "amm", true},
+	{ErrSynthCRatio, CodeFailedPrecondition, "synthetics", false},
+	{ErrSynthNotLiquidable, CodeFailedPrecondition, "synthetics", false},
+	{ErrSynthStalePrice, CodeUnavailable, "synthetics", true},
+	{ErrSynthUnknown, CodeNotFound, "synthetics", false},
+	{ErrPredictionClosed, CodeFailedPrecondition, "prediction", false},
+	{ErrPredictionUnresolved, CodeFailedPrecondition, "prediction", true},
+	{ErrInsuranceCapacity, CodeFailedPrecondition, "insurance", false},
+	{ErrInsuranceOpenClaims, CodeFailedPrecondition, "insurance", true},
+	{ErrShareRestricted, CodePermissionDenied, "securities", false},
+	{ErrPropertyFractional, CodeFailedPrecondition, "realestate", false},
+
+	// name service
+	{ErrNameInvalid, CodeInvalidArgument, "sns", false},
+	{ErrNameNotFound, CodeNotFound, "sns", false},
+	{ErrNameExpired, CodeFailedPrecondition, "sns", false},
+	{ErrNameTaken, CodeAlreadyExists, "sns", false},
+	{ErrNameNotOwner, CodePermissionDenied, "sns", false},
+	{ErrNameNoAddress, CodeNotFound, "sns", false},
+	{ErrNameAuctionOpen, CodeFailedPrecondition, "sns", true},
+	{ErrNameBadPhase, CodeFailedPrecondition, "sns", false},
+	{ErrNameBadReveal, CodeInvalidArgument, "sns", false},
+	{ErrAirdropNotFound, CodeNotFound, "airdrop", false},
+	{ErrAirdropClaimed, CodeAlreadyExists, "airdrop", false},
+	{ErrAirdropProof, CodeInvalidArgument, "airdrop", false},
+	{ErrAirdropExpired, CodeFailedPrecondition, "airdrop", false},
+	{ErrAirdropActive, CodeFailedPrecondition, "airdrop", true},
+	{ErrStreamNotFound, CodeNotFound, "streams", false},
+	{ErrStreamClosed, CodeFailedPrecondition, "streams", false},
+	{ErrStreamNotParty, CodePermissionDenied, "streams", false},
+	{ErrStreamNoFunds, CodeFailedPrecondition, "streams", true},
+	{ErrInvoiceNotFound, CodeNotFound, "invoices", false},
+	{ErrWebhookSignature, CodeUnauthenticated, "webhooks", false},
+	{ErrWebhookTarget, CodeInvalidArgument, "webhooks", false},
+	{ErrRegistrationSig, CodeUnauthenticated, "webhooks", false},
+	{ErrRegistrationNonce, CodeConflict, "webhooks", false},
+	{ErrDestinationTagRequired, CodeInvalidArgument, "exchange", false},
+	{ErrMemoTooLong, CodeInvalidArgument, "exchange", false},
+	{ErrWatchNotFound, CodeNotFound, "watch", false},
+	{ErrNonceSenderUnknown, CodeNotFound, "nonce", false},
+	{ErrNonceNotReserved, CodeFailedPrecondition, "nonce", false},
+	{ErrNonceBumpCapped, CodeFailedPrecondition, "nonce", false},
+
+	// assets and registries
+	{ErrAssetExists, CodeAlreadyExists, "assets", false},
+	{ErrAssetNotFound, CodeNotFound, "assets", false},
+	{ErrIPAssetExists, CodeAlreadyExists, "ip", false},
+	{ErrIPAssetNotFound, CodeNotFound, "ip", false},
+	{ErrSensorNotFound, CodeNotFound, "sensors", false},
+	{ErrNoEndpoint, CodeFailedPrecondition, "sensors", false},
+	{ErrMeterSignature, CodeUnauthenticated, "energy", false},
+	{ErrMeterUnknown, CodeNotFound, "energy", false},
+	{ErrReadingReplay, CodeAlreadyExists, "energy", false},
+	{ErrReadingStale, CodeInvalidArgument, "energy", false},
+	{ErrCustodySignature, CodeUnauthenticated, "supplychain", false},
+	{ErrBreakGlassApprovals, CodePermissionDenied, "healthcare", false},
+	{ErrHealthGrantExpired, CodePermissionDenied, "healthcare", false},
+	{ErrCharityReportMismatch, CodeRejected, "charity", false},
+	{ErrGameMoveSignature, CodeUnauthenticated, "gaming", false},
+	{ErrGameMoveValid, CodeRejected, "gaming", false},
+	{ErrInferenceState, CodeFailedPrecondition, "ai", false},
+	{ErrUnsettledUsage, CodeFailedPrecondition, "resources", false},
+	{ErrUsageSignature, CodeUnauthenticated, "resources", false},
+	{ErrTypedDataSignature, CodeUnauthenticated, "typeddata", false},
+	{ErrPermitSignature, CodeUnauthenticated, "permit", false},
+	{ErrPermitExpired, CodeFailedPrecondition, "permit", false},
+	{ErrPermitNonce, CodeConflict, "permit", false},
+	{ErrStorageNotReclaimable, CodeFailedPrecondition, "storage", false},
+	{ErrTxTooLarge, CodeRejected, "limits", false},
+	{ErrCodeTooLarge, CodeRejected, "limits", false},
+	{ErrTooManyStateWrites, CodeRejected, "limits", false},
+	{ErrTooManyLogs, CodeRejected, "limits", false},
+	{ErrBlockGasExceeded, CodeRejected, "limits", false},
+	{ErrBloomMismatch, CodeRejected, "logs", false},
+
+	// rate limits
+	{ErrFaucetCooldown, CodeRateLimited, "faucet", true},
+	{ErrFaucetCaptcha, CodeUnauthenticated, "faucet", false},
+	{ErrFaucetChallenge, CodeInvalidArgument, "faucet", false},
+	{ErrRateLimited, CodeRateLimited, "content", true},
+	{ErrContentHidden, CodePermissionDenied, "content", false},
+}
+
+// ClassifyError converts err into an *Error. An *Error anywhere in the
+// chain is returned as is; mapped sentinels, context errors and network
+// timeouts get their code; anything else is CodeInternal. The message is
+// always err.Error(), so no context added by wrapping is lost.
+func ClassifyError(err error) *Error {
+	if err == nil {
+		return nil
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+	out := &Error{Code: CodeInternal, Module: "core", Message: err.Error(), cause: err}
+	for _, m := range errorTable {
+		if errors.Is(err, m.err) {
+			out.Code, out.Module, out.Retryable = m.code, m.module, m.retryable
+			return out
+		}
+	}
+	var ne net.Error
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		out.Code, out.Retryable = CodeTimeout, true
+	case errors.Is(err, context.Canceled):
+		out.Code = CodeUnavailable
+	case errors.As(err, &ne) && ne.Timeout():
+		out.Code, out.Module, out.Retryable = CodeTimeout, "network", true
+	case errors.As(err, new(*net.OpError)):
+		out.Code, out.Module, out.Retryable = CodeUnavailable, "network", true
+	}
+	return out
+}
+
+// ErrorCodeOf returns the code ClassifyError assigns to err, or "" for nil.
+func ErrorCodeOf(err error) ErrorCode {
+	if e := ClassifyError(err); e != nil {
+		return e.Code
+	}
+	return ""
+}
+
+// codeForStatus is the fallback code for errors a handler rejected with an
+// explicit status but that are not otherwise classified (e.g. a JSON decode
+// error answered with 400).
+func codeForStatus(status int) ErrorCode {
+	switch status {
+	case http.StatusBadRequest:
+		return CodeInvalidArgument
+	case http.StatusUnauthorized:
+		return CodeUnauthenticated
+	case http.StatusForbidden:
+		return CodePermissionDenied
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusPaymentRequired:
+		return CodeInsufficientFunds
+	case http.StatusPreconditionFailed:
+		return CodeFailedPrecondition
+	case http.StatusUnprocessableEntity:
+		return CodeRejected
+	case http.StatusTooManyRequests:
+		return CodeRateLimited
+	case http.StatusServiceUnavailable, http.StatusBadGateway:
+		return CodeUnavailable
+	case http.StatusGatewayTimeout, http.StatusRequestTimeout:
+		return CodeTimeout
+	}
+	return CodeInternal
+}
+
+// ErrorEnvelope is the body of every error response.
+type ErrorEnvelope struct {
+	Error *Error `json:"error"`
+}
+
+// WriteHTTPError answers with the JSON envelope of err. A non-zero status
+// overrides the status derived from the code; for otherwise unclassified
+// errors it also selects the code. module names the server for such
+// errors and may be empty.
+func WriteHTTPError(w http.ResponseWriter, status int, module string, err error) {
+	e := ClassifyError(err)
+	if e.Code == CodeInternal && e.cause != nil {
+		c := *e
+		if status != 0 {
+			c.Code = codeForStatus(status)
+			c.Retryable = retryableCode(c.Code)
+		}
+		if module != "" {
+			c.Module = module
+		}
+		e = &c
+	}
+	if status == 0 {
+		status = e.HTTPStatus()
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(ErrorEnvelope{Error: e})
+}
+
+// WireError is the "error" member of daemon responses. It marshals as the
+// structured error and decodes both that form and the plain strings sent
+// by daemons that predate it.
+type WireError struct {
+	*Error
+}
+
+// WireErrorOf wraps err for a daemon response; nil stays empty.
+func WireErrorOf(err error) WireError { return WireError{ClassifyError(err)} }
+
+// Err returns the decoded error, or nil when the response carried none.
+func (w WireError) Err() error {
+	if w.Error == nil {
+		return nil
+	}
+	return w.Error
+}
+
+func (w WireError) MarshalJSON() ([]byte, error) {
+	if w.Error == nil {
+		return []byte("null"), nil
+	}
+	return json.Marshal(w.Error)
+}
+
+func (w *WireError) UnmarshalJSON(b []byte) error {
+	switch {
+	case string(b) == "null":
+		w.Error = nil
+		return nil
+	case len(b) > 0 && b[0] == '"':
+		var msg string
+		if err := json.Unmarshal(b, &msg); err != nil {
+			return err
+		}
+		if msg == "" {
+			w.Error = nil
+			return nil
+		}
+		w.Error = &Error{Code: CodeInternal, Message: msg}
+		return nil
+	}
+	var e Error
+	if err := json.Unmarshal(b, &e); err != nil {
+		return err
+	}
+	w.Error = &e
+	return nil
+}
+
+// DecodeErrorBody extracts the error of an HTTP error response. Bodies
+// that are not an envelope become an *Error whose code follows status and
+// whose message is the body text.
+func DecodeErrorBody(status int, body []byte) *Error {
+	var env struct {
+		Error WireError `json:"error"`
+	}
+	if json.Unmarshal(body, &env) == nil && env.Error.Error != nil {
+		return env.Error.Error
+	}
+	msg := string(body)
+	if len(msg) > 0 && msg[len(msg)-1] == '\n' {
+		msg = msg[:len(msg)-1]
+	}
+	if msg == "" {
+		msg = http.StatusText(status)
+	}
+	code := codeForStatus(status)
+	return &Error{Code: code, Message: msg, Retryable: retryableCode(code)}
+}
@@ -1,14 +1,52 @@
 package core
 
+// escrow.go – milestone escrows with dispute arbitration.
+//
+// The creator funds an escrow made of one or more milestones, each paying a
+// fixed amount to a payee. The creator releases milestones as work is
+// delivered. Cancelling requires the consent of the creator and every payee.
+// Once the optional deadline passes, whatever has not been released is
+// refunded to the creator (EscrowRefundExpired / StartEscrowExpiry).
+//
+// Either side may raise a dispute, which freezes the escrow and draws an
+// arbitration panel from the active authority nodes. Panel members vote the
+// share of the unreleased amount, in basis points, that goes to the payees;
+// when a majority has voted the median ruling is executed and the rest is
+// refunded to the creator.
+//
+// Every state change is appended to the escrow's History and broadcast on
+// "escrow:event".
+
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// Escrow lifecycle states.
+const (
+	EscrowActive    = "active"
+	EscrowDisputed  = "disputed"
+	EscrowReleased  = "released"
+	EscrowCancelled = "cancelled"
+	EscrowRefunded  = "refunded"
+	EscrowResolved  = "resolved"
+)
+
+// DefaultEscrowPanelSize is the number of arbiters drawn for a dispute.
+const DefaultEscrowPanelSize = 3
+
+var (
+	ErrEscrowClosed   = errors.New("escrow closed")
+	ErrEscrowDisputed = errors.New("escrow under dispute")
+)
+
 // EscrowParty defines a recipient and amount in an escrow agreement.
 type EscrowParty struct {
 	Address Address `json:"address"`
@@ -16,14 +54,47 @@ type EscrowParty struct {
 	Paid    bool    `json:"paid"`
 }
 
+// EscrowMilestone is one deliverable paid to Payee on release.
+type EscrowMilestone struct {
+	Description string    `json:"description,omitempty"`
+	Payee       Address   `json:"payee"`
+	Amount      uint64    `json:"amount"`
+	Released    bool      `json:"released"`
+	ReleasedAt  time.Time `json:"released_at,omitempty"`
+}
+
+// EscrowEvent is one entry of an escrow's history.
+type EscrowEvent struct {
+	Time   time.Time `json:"time"`
+	Type   string    `json:"type"`
+	Actor  Address   `json:"actor"`
+	Detail string    `json:"detail,omitempty"`
+}
+
+// EscrowDispute records an arbitration.
+type EscrowDispute struct {
+	RaisedBy Address           `json:"raised_by"`
+	Reason   string            `json:"reason"`
+	RaisedAt time.Time         `json:"raised_at"`
+	Panel    []Address         `json:"panel"`
+	Votes    map[string]uint16 `json:"votes"` // arbiter hex -> payee share bps
+	Ruling   *uint16           `json:"ruling,omitempty"`
+}
+
 // Escrow represents a multi-party escrow contract managed by the core.
 type EscrowContract struct {
-	ID        string        `json:"id"`
-	Creator   Address       `json:"creator"`
-	Parties   []EscrowParty `json:"parties"`
-	Balance   uint64        `json:"balance"`
-	Released  bool          `json:"released"`
-	CreatedAt time.Time     `json:"created_at"`
+	ID         string            `json:"id"`
+	Creator    Address           `json:"creator"`
+	Parties    []EscrowParty     `json:"parties"`
+	Milestones []EscrowMilestone `json:"milestones"`
+	Balance    uint64            `json:"balance"`
+	Released   bool              `json:"released"`
+	Status     string            `json:"status"`
+	Deadline   time.Time         `json:"deadline,omitempty"`
+	Consents   []Address         `json:"cancel_consents,omitempty"`
+	Dispute    *EscrowDispute    `json:"dispute,omitempty"`
+	History    []EscrowEvent     `json:"history"`
+	CreatedAt  time.Time         `json:"created_at"`
 }
 
 var escrowMu sync.Mutex
@@ -33,43 +104,68 @@ func escrowKey(id string) []byte {
 	return []byte(fmt.Sprintf("escrow:%s", id))
 }
 
+func escrowAccount() Address { return ModuleAddress("escrow") }
+
 // EscrowCreate initialises a new escrow and transfers the total amount from
-// the caller to the escrow module account.
+// the caller to the escrow module account. Each party becomes one milestone
+// and there is no deadline.
 func EscrowCreate(ctx *Context, parties []EscrowParty) (*EscrowContract, error) {
-	if len(parties) == 0 {
-		return nil, fmt.Errorf("no parties supplied")
+	ms := make([]EscrowMilestone, len(parties))
+	for i, p := range parties {
+		ms[i] = EscrowMilestone{Payee: p.Address, Amount: p.Amount}
+	}
+	return EscrowCreateMilestones(ctx, ms, time.Time{})
+}
+
+// EscrowCreateMilestones funds an escrow with the given milestones. A zero
+// deadline disables the automatic refund.
+func EscrowCreateMilestones(ctx *Context, milestones []EscrowMilestone, deadline time.Time) (*EscrowContract, error) {
+	if len(milestones) == 0 {
+		return nil, fmt.Errorf("no milestones supplied")
+	}
+	now := time.Now().UTC()
+	if !deadline.IsZero() && !deadline.After(now) {
+		return nil, fmt.Errorf("deadline must be in the future")
 	}
 
 	var total uint64
-	for _, p := range parties {
-		if p.Amount == 0 {
-			return nil, fmt.Errorf("party amount must be >0")
+	for i, m := range milestones {
+		if m.Amount == 0 {
+			return nil, fmt.Errorf("milestone %d amount must be >0", i)
+		}
+		if m.Payee == AddressZero || m.Payee == ctx.Caller {
+			return nil, fmt.Errorf("milestone %d has invalid payee", i)
 		}
-		total += p.Amount
+		milestones[i].Released = false
+		total += m.Amount
 	}
 
 	esc := &EscrowContract{
-		ID:        uuid.New().String(),
-		Creator:   ctx.Caller,
-		Parties:   parties,
-		Balance:   total,
-		Released:  false,
-		CreatedAt: time.Now().UTC(),
+		ID:         uuid.New().String(),
+		Creator:    ctx.Caller,
+		Milestones: milestones,
+		Balance:    total,
+		Status:     EscrowActive,
+		Deadline:   deadline,
+		CreatedAt:  now,
 	}
+	esc.syncParties()
 
-	escrowAccount := ModuleAddress("escrow")
-	if err := Transfer(ctx, AssetRef{Kind: AssetCoin}, ctx.Caller, escrowAccount, total); err != nil {
+	if err := Transfer(ctx, AssetRef{Kind: AssetCoin}, ctx.Caller, escrowAccount(), total); err != nil {
 		return nil, err
 	}
-
-	data, _ := json.Marshal(esc)
-	if err := CurrentStore().Set(escrowKey(esc.ID), data); err != nil {
+	esc.record("created", ctx.Caller, fmt.Sprintf("%d milestone(s), total %d", len(milestones), total))
+	escrowMu.Lock()
+	defer escrowMu.Unlock()
+	if err := saveEscrow(esc); err != nil {
 		return nil, err
 	}
 	return esc, nil
 }
 
 // EscrowDeposit adds additional funds to an existing escrow from the caller.
+// Funds beyond the milestone amounts are returned to the creator when the
+// escrow closes.
 func EscrowDeposit(ctx *Context, id string, amount uint64) error {
 	if amount == 0 {
 		return fmt.Errorf("amount must be >0")
@@ -77,95 +173,277 @@ func EscrowDeposit(ctx *Context, id string, amount uint64) error {
 	escrowMu.Lock()
 	defer escrowMu.Unlock()
 
-	raw, err := CurrentStore().Get(escrowKey(id))
-	if err != nil || raw == nil {
-		return fmt.Errorf("escrow not found")
-	}
-	var esc EscrowContract
-	if err := json.Unmarshal(raw, &esc); err != nil {
+	esc, err := loadEscrow(id)
+	if err != nil {
 		return err
 	}
-	if esc.Released {
-		return fmt.Errorf("escrow already released")
+	if esc.closed() {
+		return ErrEscrowClosed
 	}
-	escrowAccount := ModuleAddress("escrow")
-	if err := Transfer(ctx, AssetRef{Kind: AssetCoin}, ctx.Caller, escrowAccount, amount); err != nil {
+	if err := Transfer(ctx, AssetRef{Kind: AssetCoin}, ctx.Caller, escrowAccount(), amount); err != nil {
 		return err
 	}
 	esc.Balance += amount
-	data, _ := json.Marshal(&esc)
-	return CurrentStore().Set(escrowKey(id), data)
+	esc.record("deposit", ctx.Caller, fmt.Sprintf("%d", amount))
+	return saveEscrow(esc)
 }
 
-// EscrowRelease transfers escrow funds to all parties according to their
-// allocations. Funds are sent from the escrow module account.
-func EscrowRelease(ctx *Context, id string) error {
+// EscrowReleaseMilestone pays out milestone idx. Only the creator may
+// release.
+func EscrowReleaseMilestone(ctx *Context, id string, idx int) error {
 	escrowMu.Lock()
 	defer escrowMu.Unlock()
 
-	raw, err := CurrentStore().Get(escrowKey(id))
-	if err != nil || raw == nil {
-		return fmt.Errorf("escrow not found")
+	esc, err := loadEscrow(id)
+	if err != nil {
+		return err
 	}
-	var esc EscrowContract
-	if err := json.Unmarshal(raw, &esc); err != nil {
+	if err := esc.checkActive(); err != nil {
+		return err
+	}
+	if ctx.Caller != esc.Creator {
+		return ErrUnauthorized
+	}
+	if idx < 0 || idx >= len(esc.Milestones) {
+		return fmt.Errorf("milestone %d out of range", idx)
+	}
+	if esc.Milestones[idx].Released {
+		return fmt.Errorf("milestone %d already released", idx)
+	}
+	if err := esc.payMilestone(ctx, idx); err != nil {
 		return err
 	}
-	if esc.Released {
-		return fmt.Errorf("already released")
+	if esc.remaining() == 0 {
+		if err := esc.close(ctx, EscrowReleased, ctx.Caller); err != nil {
+			return err
+		}
 	}
+	return saveEscrow(esc)
+}
+
+// EscrowRelease releases every outstanding milestone. Only the creator may
+// release.
+func EscrowRelease(ctx *Context, id string) error {
+	escrowMu.Lock()
+	defer escrowMu.Unlock()
 
-	escrowAccount := ModuleAddress("escrow")
-	for i, p := range esc.Parties {
-		if p.Paid {
+	esc, err := loadEscrow(id)
+	if err != nil {
+		return err
+	}
+	if err := esc.checkActive(); err != nil {
+		return err
+	}
+	if ctx.Caller != esc.Creator {
+		return ErrUnauthorized
+	}
+	for i := range esc.Milestones {
+		if esc.Milestones[i].Released {
 			continue
 		}
-		if err := Transfer(ctx, AssetRef{Kind: AssetCoin}, escrowAccount, p.Address, p.Amount); err != nil {
+		if err := esc.payMilestone(ctx, i); err != nil {
 			return err
 		}
-		esc.Parties[i].Paid = true
 	}
-	esc.Released = true
-	data, _ := json.Marshal(&esc)
-	return CurrentStore().Set(escrowKey(id), data)
+	if err := esc.close(ctx, EscrowReleased, ctx.Caller); err != nil {
+		return err
+	}
+	return saveEscrow(esc)
 }
 
-// EscrowCancel refunds the remaining balance back to the creator if the escrow
-// has not been released yet.
+// EscrowCancel records the caller's consent to cancel. Once the creator and
+// every payee have consented, the remaining balance is refunded to the
+// creator.
 func EscrowCancel(ctx *Context, id string) error {
 	escrowMu.Lock()
 	defer escrowMu.Unlock()
 
-	raw, err := CurrentStore().Get(escrowKey(id))
-	if err != nil || raw == nil {
-		return fmt.Errorf("escrow not found")
+	esc, err := loadEscrow(id)
+	if err != nil {
+		return err
 	}
-	var esc EscrowContract
-	if err := json.Unmarshal(raw, &esc); err != nil {
+	if err := esc.checkActive(); err != nil {
 		return err
 	}
-	if esc.Released {
-		return fmt.Errorf("cannot cancel released escrow")
+	if !esc.isParty(ctx.Caller) {
+		return ErrUnauthorized
+	}
+	for _, a := range esc.Consents {
+		if a == ctx.Caller {
+			return fmt.Errorf("consent already recorded")
+		}
+	}
+	esc.Consents = append(esc.Consents, ctx.Caller)
+	esc.record("cancel_consent", ctx.Caller, "")
+	if esc.allConsented() {
+		if err := esc.close(ctx, EscrowCancelled, ctx.Caller); err != nil {
+			return err
+		}
 	}
+	return saveEscrow(esc)
+}
+
+// EscrowRaiseDispute freezes the escrow and draws an arbitration panel of
+// panelSize active authority nodes that are not parties to it.
+func EscrowRaiseDispute(ctx *Context, id, reason string, panelSize int) (*EscrowDispute, error) {
+	if panelSize <= 0 {
+		panelSize = DefaultEscrowPanelSize
+	}
+	auth := CurrentAuthoritySet()
+	if auth == nil {
+		return nil, errors.New("authority set not initialised")
+	}
+	escrowMu.Lock()
+	defer escrowMu.Unlock()
 
-	escrowAccount := ModuleAddress("escrow")
-	if err := Transfer(ctx, AssetRef{Kind: AssetCoin}, escrowAccount, esc.Creator, esc.Balance); err != nil {
+	esc, err := loadEscrow(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := esc.checkActive(); err != nil {
+		return nil, err
+	}
+	if !esc.isParty(ctx.Caller) {
+		return nil, ErrUnauthorized
+	}
+	cands, err := auth.RandomElectorate(panelSize + len(esc.Parties) + 1)
+	if err != nil {
+		return nil, err
+	}
+	var panel []Address
+	for _, a := range cands {
+		if !esc.isParty(a) && len(panel) < panelSize {
+			panel = append(panel, a)
+		}
+	}
+	if len(panel) == 0 {
+		return nil, errors.New("no eligible arbiters")
+	}
+	esc.Status = EscrowDisputed
+	esc.Dispute = &EscrowDispute{
+		RaisedBy: ctx.Caller,
+		Reason:   reason,
+		RaisedAt: time.Now().UTC(),
+		Panel:    panel,
+		Votes:    make(map[string]uint16),
+	}
+	esc.record("dispute", ctx.Caller, reason)
+	if err := saveEscrow(esc); err != nil {
+		return nil, err
+	}
+	return esc.Dispute, nil
+}
+
+// EscrowArbitrate records a panel member's ruling: the share, in basis
+// points, of the unreleased milestone amounts awarded to the payees. When a
+// majority of the panel has voted the median ruling is executed.
+func EscrowArbitrate(ctx *Context, id string, payeeBps uint16) error {
+	if payeeBps > 10_000 {
+		return fmt.Errorf("ruling %d bps exceeds 10000", payeeBps)
+	}
+	escrowMu.Lock()
+	defer escrowMu.Unlock()
+
+	esc, err := loadEscrow(id)
+	if err != nil {
 		return err
 	}
-	return CurrentStore().Delete(escrowKey(id))
+	if esc.Status != EscrowDisputed || esc.Dispute == nil {
+		return errors.New("escrow not under dispute")
+	}
+	d := esc.Dispute
+	member := false
+	for _, a := range d.Panel {
+		if a == ctx.Caller {
+			member = true
+			break
+		}
+	}
+	if !member {
+		return ErrUnauthorized
+	}
+	if _, ok := d.Votes[ctx.Caller.Hex()]; ok {
+		return fmt.Errorf("ruling already recorded")
+	}
+	d.Votes[ctx.Caller.Hex()] = payeeBps
+	esc.record("ruling", ctx.Caller, fmt.Sprintf("%d bps to payees", payeeBps))
+
+	if len(d.Votes)*2 > len(d.Panel) {
+		votes := make([]int, 0, len(d.Votes))
+		for _, v := range d.Votes {
+			votes = append(votes, int(v))
+		}
+		sort.Ints(votes)
+		ruling := uint16(votes[(len(votes)-1)/2])
+		d.Ruling = &ruling
+		if err := esc.settle(ctx, ruling); err != nil {
+			return err
+		}
+		if err := esc.close(ctx, EscrowResolved, ctx.Caller); err != nil {
+			return err
+		}
+	}
+	return saveEscrow(esc)
+}
+
+// EscrowRefundExpired refunds every active escrow whose deadline has passed
+// and returns their IDs.
+func EscrowRefundExpired(ctx *Context, now time.Time) ([]string, error) {
+	list, err := EscrowList()
+	if err != nil {
+		return nil, err
+	}
+	escrowMu.Lock()
+	defer escrowMu.Unlock()
+	var out []string
+	for i := range list {
+		esc := &list[i]
+		if esc.Status != EscrowActive || esc.Deadline.IsZero() || now.Before(esc.Deadline) {
+			continue
+		}
+		if err := esc.close(ctx, EscrowRefunded, escrowAccount()); err != nil {
+			return out, err
+		}
+		if err := saveEscrow(esc); err != nil {
+			return out, err
+		}
+		out = append(out, esc.ID)
+	}
+	return out, nil
+}
+
+// StartEscrowExpiry runs EscrowRefundExpired every interval until ctx is
+// cancelled.
+func StartEscrowExpiry(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	go func() {
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-t.C:
+				_, _ = EscrowRefundExpired(&Context{}, now.UTC())
+			}
+		}
+	}()
 }
 
 // EscrowGet returns details for an escrow by ID.
 func EscrowGet(id string) (*EscrowContract, error) {
-	raw, err := CurrentStore().Get(escrowKey(id))
-	if err != nil || raw == nil {
-		return nil, fmt.Errorf("escrow not found")
-	}
-	var esc EscrowContract
-	if err := json.Unmarshal(raw, &esc); err != nil {
+	return loadEscrow(id)
+}
+
+// EscrowHistory returns the event history of an escrow.
+func EscrowHistory(id string) ([]EscrowEvent, error) {
+	esc, err := loadEscrow(id)
+	if err != nil {
 		return nil, err
 	}
-	return &esc, nil
+	return esc.History, nil
 }
 
 // EscrowList lists all escrows currently stored.
@@ -181,3 +459,196 @@ func EscrowList() ([]EscrowContract, error) {
 	}
 	return out, it.Error()
 }
+
+//---------------------------------------------------------------------
+// internals
+//---------------------------------------------------------------------
+
+func loadEscrow(id string) (*EscrowContract, error) {
+	raw, err := CurrentStore().Get(escrowKey(id))
+	if err != nil || raw == nil {
+		return nil, fmt.Errorf("escrow not found")
+	}
+	var esc EscrowContract
+	if err := json.Unmarshal(raw, &esc); err != nil {
+		return nil, err
+	}
+	if len(esc.Milestones) == 0 {
+		// escrows created before milestones: one milestone per party
+		for _, p := range esc.Parties {
+			esc.Milestones = append(esc.Milestones, EscrowMilestone{Payee: p.Address, Amount: p.Amount, Released: p.Paid})
+		}
+	}
+	if esc.Status == "" {
+		esc.Status = EscrowActive
+		if esc.Released {
+			esc.Status = EscrowReleased
+		}
+	}
+	return &esc, nil
+}
+
+func saveEscrow(esc *EscrowContract) error {
+	data, _ := json.Marshal(esc)
+	return CurrentStore().Set(escrowKey(esc.ID), data)
+}
+
+func (esc *EscrowContract) record(typ string, actor Address, detail string) {
+	ev := EscrowEvent{Time: time.Now().UTC(), Type: typ, Actor: actor, Detail: detail}
+	esc.History = append(esc.History, ev)
+	Broadcast("escrow:event", mustJSON(struct {
+		ID string `json:"id"`
+		EscrowEvent
+	}{esc.ID, ev}))
+	publishEvent(TopicEscrow, currentHeight(), EscrowEventData{EscrowID: esc.ID, EscrowEvent: ev})
+}
+
+func (esc *EscrowContract) closed() bool {
+	switch esc.Status {
+	case EscrowActive, EscrowDisputed:
+		return false
+	}
+	return true
+}
+
+func (esc *EscrowContract) checkActive() error {
+	if esc.Status == EscrowDisputed {
+		return ErrEscrowDisputed
+	}
+	if esc.closed() {
+		return ErrEscrowClosed
+	}
+	return nil
+}
+
+func (esc *EscrowContract) isParty(a Address) bool {
+	if a == esc.Creator {
+		return true
+	}
+	for _, p := range esc.Parties {
+		if p.Address == a {
+			return true
+		}
+	}
+	return false
+}
+
+func (esc *EscrowContract) allConsented() bool {
+	seen := make(map[Address]bool, len(esc.Consents))
+	for _, a := range esc.Consents {
+		seen[a] = true
+	}
+	if !seen[esc.Creator] {
+		return false
+	}
+	for _, p := range esc.Parties {
+		if !seen[p.Address] {
+			return false
+		}
+	}
+	return true
+}
+
+// remaining is the sum of unreleased milestone amounts.
+func (esc *EscrowContract) remaining() uint64 {
+	var n uint64
+	for _, m := range esc.Milestones {
+		if !m.Released {
+			n += m.Amount
+		}
+	}
+	return n
+}
+
+// syncParties derives the per-payee totals from the milestones.
+func (esc *EscrowContract) syncParties() {
+	idx := make(map[Address]int)
+	esc.Parties = esc.Parties[:0]
+	for _, m := range esc.Milestones {
+		i, ok := idx[m.Payee]
+		if !ok {
+			i = len(esc.Parties)
+			idx[m.Payee] = i
+			esc.Parties = append(esc.Parties, EscrowParty{Address: m.Payee, Paid: true})
+		}
+		esc.Parties[i].Amount += m.Amount
+		if !m.Released {
+			esc.Parties[i].Paid = false
+		}
+	}
+}
+
+func (esc *EscrowContract) payMilestone(ctx *Context, idx int) error {
+	m := &esc.Milestones[idx]
+	if err := Transfer(ctx, AssetRef{Kind: AssetCoin}, escrowAccount(), m.Payee, m.Amount); err != nil {
+		return err
+	}
+	m.Released = true
+	m.ReleasedAt = time.Now().UTC()
+	esc.Balance -= m.Amount
+	esc.syncParties()
+	esc.record("milestone_released", ctx.Caller, fmt.Sprintf("#%d %d to %s", idx, m.Amount, m.Payee.Hex()))
+	return nil
+}
+
+// settle pays payeeBps of each unreleased milestone to its payee. The
+// remainder stays in the balance for close to refund.
+func (esc *EscrowContract) settle(ctx *Context, payeeBps uint16) error {
+	for i := range esc.Milestones {
+		m := &esc.Milestones[i]
+		if m.Released {
+			continue
+		}
+		amt := m.Amount * uint64(payeeBps) / 10_000
+		if amt > 0 {
+			if err := Transfer(ctx, AssetRef{Kind: AssetCoin}, escrowAccount(), m.Payee, amt); err != nil {
+				return err
+			}
+			esc.Balance -= amt
+		}
+		m.Released = true
+		m.ReleasedAt = time.Now().UTC()
+		esc.record("milestone_settled", ctx.Caller, fmt.Sprintf("#%d %d of %d to %s", i, amt, m.Amount, m.Payee.Hex()))
+	}
+	esc.syncParties()
+	return nil
+}
+
+// close refunds any remaining balance to the creator and moves the escrow to
+// a terminal status.
+func (esc *EscrowContract) close(ctx *Context, status string, actor Address) error {
+	if esc.Balance > 0 {
+		if err := Transfer(ctx, AssetRef{Kind: AssetCoin}, escrowAccount(), esc.Creator, esc.Balance); err != nil {
+			return err
+		}
+		esc.record("refund", actor, fmt.Sprintf("%d to %s", esc.Balance, esc.Creator.Hex()))
+		esc.Balance = 0
+	}
+	esc.Status = status
+	esc.Released = status == EscrowReleased || status == EscrowResolved
+	esc.record(status, actor, "")
+	return nil
+}
+
+func init() { RegisterInvariant("escrow", "locked_funds", checkEscrowLockedFunds) }
+
+// checkEscrowLockedFunds verifies that the escrow module account holds
+// exactly the sum of the balances of all escrows.
+func checkEscrowLockedFunds() error {
+	led := CurrentLedger()
+	if led == nil || CurrentStore() == nil {
+		return nil
+	}
+	escs, err := EscrowList()
+	if err != nil {
+		return err
+	}
+	var total uint64
+	for _, e := range escs {
+		total += e.Balance
+	}
+	if held := led.CoinBalance(escrowAccount()); held != total {
+		return fmt.Errorf("escrow account holds %d, escrows account for %d", held, total)
+	}
+	return nil
+}
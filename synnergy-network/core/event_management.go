@@ -2,6 +2,7 @@ package core
 
 import (
 	"crypto/sha256"
+	"encoding/binary"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
@@ -9,8 +10,22 @@ import (
 	"time"
 )
 
+// Built-in event topics. Modules publish typed payloads on these topics;
+// contracts publish on TopicContract. Any other topic carries raw data
+// emitted through Emit.
+const (
+	TopicBlock      = "block"
+	TopicTx         = "tx"
+	TopicGovernance = "governance"
+	TopicAMM        = "amm"
+	TopicEscrow     = "escrow"
+	TopicContract   = "contract"
+)
+
 // Event represents a ledger anchored notification emitted by various modules.
+// Seq numbers every event in emission order starting at 1.
 type Event struct {
+	Seq       uint64 `json:"seq"`
 	ID        string `json:"id"`
 	Type      string `json:"type"`
 	Data      []byte `json:"data"`
@@ -18,10 +33,61 @@ type Event struct {
 	Timestamp int64  `json:"ts"`
 }
 
+// BlockEventData is published on TopicBlock for every applied block.
+type BlockEventData struct {
+	Height uint64 `json:"height"`
+	Hash   string `json:"hash"`
+	Txs    int    `json:"txs"`
+}
+
+// TxEventData is published on TopicTx for every transaction of a block.
+type TxEventData struct {
+	Hash   string  `json:"hash"`
+	From   Address `json:"from"`
+	To     Address `json:"to"`
+	Amount uint64  `json:"amount"`
+	Height uint64  `json:"height"`
+}
+
+// GovernanceEventData is published on TopicGovernance.
+type GovernanceEventData struct {
+	ProposalID string  `json:"proposal_id"`
+	Action     string  `json:"action"`
+	Actor      Address `json:"actor,omitempty"`
+	Approve    bool    `json:"approve,omitempty"`
+}
+
+// AMMEventData is published on TopicAMM for swaps and liquidity changes.
+type AMMEventData struct {
+	Action    string  `json:"action"`
+	Pool      PoolID  `json:"pool,omitempty"`
+	Trader    Address `json:"trader"`
+	TokenIn   TokenID `json:"token_in,omitempty"`
+	TokenOut  TokenID `json:"token_out,omitempty"`
+	AmountIn  uint64  `json:"amount_in,omitempty"`
+	AmountOut uint64  `json:"amount_out,omitempty"`
+}
+
+// EscrowEventData is published on TopicEscrow for every escrow history
+// entry.
+type EscrowEventData struct {
+	EscrowID string `json:"escrow_id"`
+	EscrowEvent
+}
+
+// ContractEventData is a custom event emitted by a smart contract.
+type ContractEventData struct {
+	Contract Address `json:"contract"`
+	Name     string  `json:"name"`
+	Data     []byte  `json:"data"`
+	TxHash   Hash    `json:"tx_hash"`
+}
+
 // EventManager persists events in the ledger state and broadcasts them over the network.
 type EventManager struct {
 	mu     sync.RWMutex
 	ledger StateRW
+	subs   map[*eventSub]struct{}
 }
 
 var (
@@ -30,27 +96,133 @@ var (
 )
 
 // InitEvents initialises a global event manager backed by the provided ledger.
-func InitEvents(l StateRW) { evtOnce.Do(func() { evtMgr = &EventManager{ledger: l} }) }
+func InitEvents(l StateRW) {
+	evtOnce.Do(func() { evtMgr = &EventManager{ledger: l, subs: make(map[*eventSub]struct{})} })
+}
 
 // Events returns the active global event manager.
 func Events() *EventManager { return evtMgr }
 
-// Emit records an event under a deterministic key and broadcasts it. The returned
-// ID can be used to retrieve the event later.
+var eventHeadKey = []byte("evhead")
+
+func eventSeqKey(seq uint64) []byte { return []byte(fmt.Sprintf("evseq:%020d", seq)) }
+
+// Head returns the sequence number of the latest event.
+func (m *EventManager) Head() uint64 {
+	if m == nil || m.ledger == nil {
+		return 0
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.head()
+}
+
+func (m *EventManager) head() uint64 {
+	raw, err := m.ledger.GetState(eventHeadKey)
+	if err != nil || len(raw) != 8 {
+		return 0
+	}
+	return binary.BigEndian.Uint64(raw)
+}
+
+// Emit records an event under the next sequence number and broadcasts it.
+// The returned ID can be used to retrieve the event later.
 func (m *EventManager) Emit(ctx *Context, typ string, data []byte) (string, error) {
+	ev, err := m.emit(ctx, typ, data)
+	if err != nil {
+		return "", err
+	}
+	return ev.ID, nil
+}
+
+// Publish emits payload JSON encoded on topic.
+func (m *EventManager) Publish(ctx *Context, topic string, payload interface{}) (Event, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return Event{}, err
+	}
+	return m.emit(ctx, topic, data)
+}
+
+func (m *EventManager) emit(ctx *Context, typ string, data []byte) (Event, error) {
 	if m == nil || m.ledger == nil {
-		return "", fmt.Errorf("event manager not initialised")
+		return Event{}, fmt.Errorf("event manager not initialised")
+	}
+	if typ == "" {
+		return Event{}, fmt.Errorf("event type required")
+	}
+	m.mu.Lock()
+	seq := m.head() + 1
+	var sb [8]byte
+	binary.BigEndian.PutUint64(sb[:], seq)
+	h := sha256.Sum256(append(append([]byte(typ), data...), sb[:]...))
+	ev := Event{Seq: seq, ID: hex.EncodeToString(h[:]), Type: typ, Data: data, Timestamp: time.Now().Unix()}
+	if ctx != nil {
+		ev.Height = ctx.BlockHeight
 	}
-	h := sha256.Sum256(append([]byte(typ), data...))
-	id := hex.EncodeToString(h[:])
-	ev := Event{ID: id, Type: typ, Data: data, Height: ctx.BlockHeight, Timestamp: time.Now().Unix()}
 	blob, _ := json.Marshal(ev)
-	key := []byte(fmt.Sprintf("event:%s:%s", typ, id))
+	if err := m.ledger.SetState(eventSeqKey(seq), blob); err != nil {
+		m.mu.Unlock()
+		return Event{}, err
+	}
+	key := []byte(fmt.Sprintf("event:%s:%s", typ, ev.ID))
 	if err := m.ledger.SetState(key, blob); err != nil {
-		return "", err
+		m.mu.Unlock()
+		return Event{}, err
 	}
+	if err := m.ledger.SetState(eventHeadKey, sb[:]); err != nil {
+		m.mu.Unlock()
+		return Event{}, err
+	}
+	for s := range m.subs {
+		s.notify()
+	}
+	m.mu.Unlock()
 	_ = Broadcast("event:"+typ, blob)
-	return id, nil
+	return ev, nil
+}
+
+// Range returns up to limit events starting at sequence number from, in
+// order, optionally restricted to topics. Pass limit <=0 for no limit.
+func (m *EventManager) Range(from uint64, limit int, topics []string) ([]Event, error) {
+	if m == nil || m.ledger == nil {
+		return nil, fmt.Errorf("event manager not initialised")
+	}
+	if from == 0 {
+		from = 1
+	}
+	want := topicSet(topics)
+	head := m.Head()
+	var out []Event
+	for seq := from; seq <= head; seq++ {
+		raw, err := m.ledger.GetState(eventSeqKey(seq))
+		if err != nil || len(raw) == 0 {
+			continue
+		}
+		var ev Event
+		if err := json.Unmarshal(raw, &ev); err != nil {
+			return nil, err
+		}
+		if want != nil && !want[ev.Type] {
+			continue
+		}
+		out = append(out, ev)
+		if limit > 0 && len(out) >= limit {
+			break
+		}
+	}
+	return out, nil
+}
+
+func topicSet(topics []string) map[string]bool {
+	if len(topics) == 0 {
+		return nil
+	}
+	set := make(map[string]bool, len(topics))
+	for _, t := range topics {
+		set[t] = true
+	}
+	return set
 }
 
 // List returns up to limit events of the given type in arbitrary order. Pass
@@ -89,3 +261,33 @@ func (m *EventManager) Get(typ, id string) (Event, error) {
 	}
 	return ev, nil
 }
+
+// publishEvent publishes payload on topic through the global event manager
+// if one is running. Module hooks use it so that events stay optional.
+func publishEvent(topic string, height uint64, payload interface{}) {
+	if evtMgr == nil {
+		return
+	}
+	_, _ = evtMgr.Publish(&Context{BlockHeight: height}, topic, payload)
+}
+
+// currentHeight returns the height of the current ledger, or 0.
+func currentHeight() uint64 {
+	if led := CurrentLedger(); led != nil {
+		return led.LastHeight()
+	}
+	return 0
+}
+
+// EmitContractEvent records a custom event emitted by contract during the
+// transaction described by ctx.
+func EmitContractEvent(ctx *Context, contract Address, name string, data []byte) (Event, error) {
+	if name == "" {
+		return Event{}, fmt.Errorf("event name required")
+	}
+	ev := ContractEventData{Contract: contract, Name: name, Data: data}
+	if ctx != nil {
+		ev.TxHash = ctx.TxHash
+	}
+	return evtMgr.Publish(ctx, TopicContract, ev)
+}
@@ -0,0 +1,314 @@
+package core
+
+// event_stream.go – event subscriptions, streaming transports and the
+// contract event indexer.
+//
+// A subscription reads the persisted event log from a starting sequence
+// number and then follows new events as they are emitted, so a consumer
+// that remembers the last sequence it processed can reconnect and resume
+// without gaps. Subscriptions are exposed over WebSocket (EventWebSocket)
+// and gRPC server streaming (RegisterEventStreamServer). The gRPC service
+// is defined by hand and uses a JSON codec, so clients select it with
+// grpc.CallContentSubtype("json").
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+)
+
+// EventFilter selects the events of a subscription. From is the first
+// sequence number delivered; 0 follows new events only.
+type EventFilter struct {
+	From   uint64   `json:"from"`
+	Topics []string `json:"topics,omitempty"`
+}
+
+type eventSub struct {
+	wake chan struct{}
+}
+
+func (s *eventSub) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// eventReplayBatch bounds how many events a subscription reads at once.
+const eventReplayBatch = 256
+
+// Subscribe streams events matching f until ctx is cancelled. The channel
+// is closed when the subscription ends.
+func (m *EventManager) Subscribe(ctx context.Context, f EventFilter) (<-chan Event, error) {
+	if m == nil || m.ledger == nil {
+		return nil, fmt.Errorf("event manager not initialised")
+	}
+	sub := &eventSub{wake: make(chan struct{}, 1)}
+	m.mu.Lock()
+	m.subs[sub] = struct{}{}
+	cursor := f.From
+	if cursor == 0 {
+		cursor = m.head() + 1
+	}
+	m.mu.Unlock()
+
+	want := topicSet(f.Topics)
+	out := make(chan Event, eventReplayBatch)
+	go func() {
+		defer func() {
+			m.mu.Lock()
+			delete(m.subs, sub)
+			m.mu.Unlock()
+			close(out)
+		}()
+		for {
+			evs, err := m.Range(cursor, eventReplayBatch, nil)
+			if err != nil {
+				logrus.Warnf("events: subscription read: %v", err)
+				return
+			}
+			for _, ev := range evs {
+				cursor = ev.Seq + 1
+				if want != nil && !want[ev.Type] {
+					continue
+				}
+				select {
+				case out <- ev:
+				case <-ctx.Done():
+					return
+				}
+			}
+			if len(evs) == eventReplayBatch {
+				continue
+			}
+			select {
+			case <-sub.wake:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+// ---------------------------------------------------------------------
+// WebSocket
+// ---------------------------------------------------------------------
+
+var eventUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 4096,
+	CheckOrigin:     func(*http.Request) bool { return true },
+}
+
+// parseEventFilter reads from=<seq> and topics=<a,b> query parameters.
+func parseEventFilter(r *http.Request) (EventFilter, error) {
+	var f EventFilter
+	q := r.URL.Query()
+	if v := q.Get("from"); v != "" {
+		n, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return f, fmt.Errorf("invalid from")
+		}
+		f.From = n
+	}
+	if v := q.Get("topics"); v != "" {
+		f.Topics = strings.Split(v, ",")
+	}
+	return f, nil
+}
+
+// EventWebSocket upgrades the request and streams matching events as JSON
+// text messages. Clients resume by reconnecting with from set to the last
+// received sequence number plus one.
+func (m *EventManager) EventWebSocket(w http.ResponseWriter, r *http.Request) {
+	f, err := parseEventFilter(r)
+	if err != nil {
+		WriteHTTPError(w, http.StatusBadRequest, "events", err)
+		return
+	}
+	conn, err := eventUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	// clear deadlines inherited from the HTTP server timeouts
+	_ = conn.SetReadDeadline(time.Time{})
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	// the read loop only detects the client going away
+	go func() {
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				cancel()
+				return
+			}
+		}
+	}()
+	evs, err := m.Subscribe(ctx, f)
+	if err != nil {
+		_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseInternalServerErr, err.Error()))
+		return
+	}
+	for ev := range evs {
+		_ = conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+		if err := conn.WriteJSON(ev); err != nil {
+			return
+		}
+	}
+}
+
+// ---------------------------------------------------------------------
+// gRPC
+// ---------------------------------------------------------------------
+
+type eventJSONCodec struct{}
+
+func (eventJSONCodec) Marshal(v interface{}) ([]byte, error)      { return json.Marshal(v) }
+func (eventJSONCodec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+func (eventJSONCodec) Name() string                               { return "json" }
+
+func init() { encoding.RegisterCodec(eventJSONCodec{}) }
+
+// EventStreamServiceName is the fully qualified gRPC service name.
+const EventStreamServiceName = "synnergy.events.EventStream"
+
+var eventStreamDesc = grpc.ServiceDesc{
+	ServiceName: EventStreamServiceName,
+	HandlerType: (*interface{})(nil),
+	Streams: []grpc.StreamDesc{{
+		StreamName:    "Subscribe",
+		Handler:       eventStreamHandler,
+		ServerStreams: true,
+	}},
+}
+
+func eventStreamHandler(srv interface{}, stream grpc.ServerStream) error {
+	m, _ := srv.(*EventManager)
+	var f EventFilter
+	if err := stream.RecvMsg(&f); err != nil {
+		return err
+	}
+	evs, err := m.Subscribe(stream.Context(), f)
+	if err != nil {
+		return err
+	}
+	for ev := range evs {
+		if err := stream.SendMsg(&ev); err != nil {
+			return err
+		}
+	}
+	return stream.Context().Err()
+}
+
+// RegisterEventStreamServer registers the event stream service on s.
+func RegisterEventStreamServer(s *grpc.Server, m *EventManager) {
+	s.RegisterService(&eventStreamDesc, m)
+}
+
+// SubscribeEventsGRPC opens an event stream on conn.
+func SubscribeEventsGRPC(ctx context.Context, conn *grpc.ClientConn, f EventFilter) (<-chan Event, error) {
+	stream, err := conn.NewStream(ctx, &eventStreamDesc.Streams[0], "/"+EventStreamServiceName+"/Subscribe",
+		grpc.CallContentSubtype("json"))
+	if err != nil {
+		return nil, err
+	}
+	if err := stream.SendMsg(&f); err != nil {
+		return nil, err
+	}
+	if err := stream.CloseSend(); err != nil {
+		return nil, err
+	}
+	out := make(chan Event, eventReplayBatch)
+	go func() {
+		defer close(out)
+		for {
+			var ev Event
+			if err := stream.RecvMsg(&ev); err != nil {
+				return
+			}
+			out <- ev
+		}
+	}()
+	return out, nil
+}
+
+// ---------------------------------------------------------------------
+// Contract event indexer
+// ---------------------------------------------------------------------
+
+var eventIndexCursorKey = []byte("evidx:cursor")
+
+func contractEventIndexPrefix(a Address) []byte {
+	return []byte("evidx:contract:" + a.Hex() + ":")
+}
+
+// StartEventIndexer indexes contract events by contract address. It
+// resumes from the last indexed sequence number after a restart.
+func StartEventIndexer(ctx context.Context, m *EventManager) error {
+	if m == nil || m.ledger == nil {
+		return fmt.Errorf("event manager not initialised")
+	}
+	from := uint64(1)
+	if raw, err := m.ledger.GetState(eventIndexCursorKey); err == nil && len(raw) > 0 {
+		if n, err := strconv.ParseUint(string(raw), 10, 64); err == nil {
+			from = n + 1
+		}
+	}
+	evs, err := m.Subscribe(ctx, EventFilter{From: from})
+	if err != nil {
+		return err
+	}
+	go func() {
+		for ev := range evs {
+			if ev.Type == TopicContract {
+				var ce ContractEventData
+				if err := json.Unmarshal(ev.Data, &ce); err == nil {
+					key := append(contractEventIndexPrefix(ce.Contract), fmt.Sprintf("%020d", ev.Seq)...)
+					_ = m.ledger.SetState(key, []byte(strconv.FormatUint(ev.Seq, 10)))
+				}
+			}
+			_ = m.ledger.SetState(eventIndexCursorKey, []byte(strconv.FormatUint(ev.Seq, 10)))
+		}
+	}()
+	return nil
+}
+
+// ContractEvents returns up to limit indexed events emitted by contract, in
+// order. Pass limit <=0 for all of them.
+func (m *EventManager) ContractEvents(contract Address, limit int) ([]Event, error) {
+	if m == nil || m.ledger == nil {
+		return nil, fmt.Errorf("event manager not initialised")
+	}
+	it := m.ledger.PrefixIterator(contractEventIndexPrefix(contract))
+	var out []Event
+	for it.Next() {
+		seq, err := strconv.ParseUint(string(it.Value()), 10, 64)
+		if err != nil {
+			continue
+		}
+		raw, err := m.ledger.GetState(eventSeqKey(seq))
+		if err != nil || len(raw) == 0 {
+			continue
+		}
+		var ev Event
+		if err := json.Unmarshal(raw, &ev); err != nil {
+			return nil, err
+		}
+		out = append(out, ev)
+		if limit > 0 && len(out) >= limit {
+			break
+		}
+	}
+	return out, it.Error()
+}
@@ -0,0 +1,329 @@
+package core
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/holiman/uint256"
+)
+
+// Differential tests: every EVM-equivalent handler in utility_functions.go
+// is run side by side with go-ethereum's interpreter on the same operands,
+// and must produce the same word and charge the same base gas.
+//
+// Operands are listed in EVM order, args[0] being the top of the EVM stack.
+// Most handlers take their operands in push order instead (opSUB computes
+// b - a with a on top), so those cases are marked rev and see the operands
+// reversed.
+
+// diffCases is the number of random operand sets per opcode; edge values
+// are always included on top.
+const diffCases = 2000
+
+// gethEVM runs snippets on go-ethereum's interpreter and records the gas
+// charged for the opcode under test.
+type gethEVM struct {
+	evm    *vm.EVM
+	target vm.OpCode
+	cost   uint64
+}
+
+func newGethEVM() *gethEVM {
+	g := &gethEVM{}
+	hooks := &tracing.Hooks{OnOpcode: func(pc uint64, op byte, gas, cost uint64, scope tracing.OpContext, rData []byte, depth int, err error) {
+		if vm.OpCode(op) == g.target {
+			g.cost = cost
+		}
+	}}
+	blk := vm.BlockContext{BlockNumber: big.NewInt(1), Time: 1, Difficulty: new(big.Int), Random: &common.Hash{}}
+	g.evm = vm.NewEVM(blk, vm.TxContext{}, nil, params.MergedTestChainConfig, vm.Config{Tracer: hooks})
+	return g
+}
+
+// run executes code with input as call data and returns the output and the
+// gas charged for op.
+func (g *gethEVM) run(t *testing.T, op vm.OpCode, code, input []byte) ([]byte, uint64) {
+	t.Helper()
+	g.target, g.cost = op, 0
+	self := vm.AccountRef(common.Address{0xee})
+	c := vm.NewContract(vm.AccountRef(common.Address{0xca}), self, new(uint256.Int), 10_000_000)
+	c.Code = code
+	ret, err := g.evm.Interpreter().Run(c, input, false)
+	if err != nil {
+		t.Fatalf("geth %s: %v", op, err)
+	}
+	return ret, g.cost
+}
+
+// pushes assembles PUSH32 instructions leaving args[0] on top of the stack.
+func pushes(args []*big.Int) []byte {
+	var code []byte
+	for i := len(args) - 1; i >= 0; i-- {
+		code = append(code, byte(vm.PUSH32))
+		code = append(code, common.LeftPadBytes(args[i].Bytes(), 32)...)
+	}
+	return code
+}
+
+// returnTop stores the top of the stack at memory offset 0 and returns it.
+var returnTop = []byte{
+	byte(vm.PUSH1), 0, byte(vm.MSTORE),
+	byte(vm.PUSH1), 32, byte(vm.PUSH1), 0, byte(vm.RETURN),
+}
+
+// runOurs pushes args so that args[0] is on top, or at the bottom when rev
+// is set, runs fn and returns the resulting word.
+func runOurs(t *testing.T, fn func(*VMContext) error, args []*big.Int, mem []byte, rev bool) *big.Int {
+	t.Helper()
+	ctx := &VMContext{Context: Context{Stack: &Stack{}}, Memory: NewMemory()}
+	ctx.Memory.Write(0, mem)
+	for i := range args {
+		j := len(args) - 1 - i
+		if rev {
+			j = i
+		}
+		ctx.Stack.Push(new(big.Int).Set(args[j]))
+	}
+	if err := ExecOp(ctx, fn); err != nil {
+		t.Fatalf("%v", err)
+	}
+	return ctx.Stack.Pop()
+}
+
+func word(x *big.Int) []byte {
+	if x.Sign() < 0 || x.BitLen() > 256 {
+		return []byte("out of range: " + x.String())
+	}
+	return common.LeftPadBytes(x.Bytes(), 32)
+}
+
+// gasOf returns the dispatcher's base cost for a catalogue entry.
+func gasOf(t *testing.T, name string) uint64 {
+	t.Helper()
+	mu.RLock()
+	op, ok := nameToOp[name]
+	mu.RUnlock()
+	if !ok {
+		t.Fatalf("%s missing from the opcode catalogue", name)
+	}
+	return GasCost(op)
+}
+
+// edgeWords are operands where signed and modular arithmetic usually breaks.
+var edgeWords = func() []*big.Int {
+	var out []*big.Int
+	for _, v := range []int64{0, 1, 2, 3, 7, 8, 31, 32, 255, 256} {
+		out = append(out, big.NewInt(v))
+	}
+	for _, bits := range []uint{63, 64, 127, 128, 255} {
+		p := new(big.Int).Lsh(big.NewInt(1), bits)
+		out = append(out, p, new(big.Int).Sub(p, big.NewInt(1)))
+	}
+	for _, v := range []int64{1, 2, 3, 255} {
+		out = append(out, new(big.Int).Sub(two256, big.NewInt(v))) // -v
+	}
+	return out
+}()
+
+func randWord(r *rand.Rand) *big.Int {
+	if r.Intn(4) == 0 {
+		return new(big.Int).Set(edgeWords[r.Intn(len(edgeWords))])
+	}
+	b := make([]byte, 1+r.Intn(32))
+	r.Read(b)
+	x := new(big.Int).SetBytes(b)
+	if r.Intn(3) == 0 {
+		x.Sub(two256, x).And(x, mask256) // negative in two's complement
+	}
+	return x
+}
+
+func TestEVMDifferentialStackOps(t *testing.T) {
+	cases := []struct {
+		name  string // opcode catalogue name
+		fn    func(*VMContext) error
+		op    vm.OpCode
+		arity int
+		rev   bool
+		// dyn is the dynamic gas go-ethereum adds on top of the base cost.
+		dyn func(args []*big.Int) uint64
+	}{
+		{"opADD", opADD, vm.ADD, 2, true, nil},
+		{"opMUL", opMUL, vm.MUL, 2, true, nil},
+		{"opSUB", opSUB, vm.SUB, 2, true, nil},
+		{"OpDIV", opDIV, vm.DIV, 2, true, nil},
+		{"opSDIV", opSDIV, vm.SDIV, 2, true, nil},
+		{"opMOD", opMOD, vm.MOD, 2, true, nil},
+		{"opSMOD", opSMOD, vm.SMOD, 2, true, nil},
+		{"opADDMOD", opADDMOD, vm.ADDMOD, 3, true, nil},
+		{"opMULMOD", opMULMOD, vm.MULMOD, 3, true, nil},
+		{"opEXP", opEXP, vm.EXP, 2, true, func(a []*big.Int) uint64 {
+			return params.ExpByteEIP158 * uint64((a[1].BitLen()+7)/8)
+		}},
+		{"opSIGNEXTEND", opSIGNEXTEND, vm.SIGNEXTEND, 2, false, nil},
+		{"opLT", opLT, vm.LT, 2, true, nil},
+		{"opGT", opGT, vm.GT, 2, true, nil},
+		{"opSLT", opSLT, vm.SLT, 2, true, nil},
+		{"opSGT", opSGT, vm.SGT, 2, true, nil},
+		{"opEQ", opEQ, vm.EQ, 2, true, nil},
+		{"opISZERO", opISZERO, vm.ISZERO, 1, true, nil},
+		{"opAND", opAND, vm.AND, 2, true, nil},
+		{"opOR", opOR, vm.OR, 2, true, nil},
+		{"opXOR", opXOR, vm.XOR, 2, true, nil},
+		{"opNOT", opNOT, vm.NOT, 1, true, nil},
+		{"opBYTE", opBYTE, vm.BYTE, 2, false, nil},
+		{"opSHL", opSHL, vm.SHL, 2, false, nil},
+		{"opSHR", opSHR, vm.SHR, 2, false, nil},
+		{"opSAR", opSAR, vm.SAR, 2, false, nil},
+	}
+	n := diffCases
+	if testing.Short() {
+		n = 200
+	}
+	geth := newGethEVM()
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := rand.New(rand.NewSource(int64(tc.op)))
+			base := gasOf(t, tc.name)
+			var sets [][]*big.Int
+			for _, e := range edgeWords {
+				for _, f := range edgeWords {
+					sets = append(sets, []*big.Int{e, f, edgeWords[(len(sets))%len(edgeWords)]}[:tc.arity])
+				}
+			}
+			for i := 0; i < n; i++ {
+				args := make([]*big.Int, tc.arity)
+				for j := range args {
+					args[j] = randWord(r)
+				}
+				sets = append(sets, args)
+			}
+			failures := 0
+			for _, args := range sets {
+				code := append(append(pushes(args), byte(tc.op)), returnTop...)
+				want, cost := geth.run(t, tc.op, code, nil)
+				got := word(runOurs(t, tc.fn, args, nil, tc.rev))
+				if !bytes.Equal(got, want) {
+					t.Errorf("%s(%x): got %x, want %x", tc.op, args, got, want)
+					if failures++; failures == 5 {
+						t.FailNow()
+					}
+				}
+				if tc.dyn != nil {
+					cost -= tc.dyn(args)
+				}
+				if cost != base {
+					t.Fatalf("%s: base gas %d, go-ethereum charges %d", tc.name, base, cost)
+				}
+			}
+		})
+	}
+}
+
+func TestEVMDifferentialKeccak256(t *testing.T) {
+	geth := newGethEVM()
+	r := rand.New(rand.NewSource(int64(vm.KECCAK256)))
+	base := gasOf(t, "opKECCAK256")
+	n := diffCases
+	if testing.Short() {
+		n = 200
+	}
+	for i := 0; i < n; i++ {
+		data := make([]byte, r.Intn(200))
+		r.Read(data)
+		off := r.Intn(len(data) + 1)
+		size := r.Intn(len(data) - off + 1)
+		args := []*big.Int{big.NewInt(int64(off)), big.NewInt(int64(size))}
+
+		// Copy the call data to memory, then hash a window of it.
+		code := []byte{byte(vm.CALLDATASIZE), byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.CALLDATACOPY)}
+		code = append(append(append(code, pushes(args)...), byte(vm.KECCAK256)), returnTop...)
+		want, cost := geth.run(t, vm.KECCAK256, code, data)
+		got := word(runOurs(t, opKECCAK256, args, data, true))
+		if !bytes.Equal(got, want) {
+			t.Fatalf("KECCAK256(off %d, size %d): got %x, want %x", off, size, got, want)
+		}
+		if dyn := params.Keccak256WordGas * uint64((size+31)/32); cost-dyn != base {
+			t.Fatalf("opKECCAK256: base gas %d, go-ethereum charges %d", base, cost-dyn)
+		}
+	}
+}
+
+// The hash and signature opcodes are precompiles on Ethereum; their output
+// must match the precompile run on the same input.
+func TestEVMDifferentialPrecompiles(t *testing.T) {
+	pre := vm.PrecompiledContractsCancun
+	r := rand.New(rand.NewSource(1))
+	for _, tc := range []struct {
+		name string
+		fn   func(*VMContext) error
+		addr byte
+	}{
+		{"opSHA256", opSHA256, 2},
+		{"opRIPEMD160", opRIPEMD160, 3},
+	} {
+		p := pre[common.BytesToAddress([]byte{tc.addr})]
+		for i := 0; i < diffCases/10; i++ {
+			data := make([]byte, r.Intn(300))
+			r.Read(data)
+			want, err := p.Run(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			args := []*big.Int{new(big.Int), big.NewInt(int64(len(data)))}
+			if got := word(runOurs(t, tc.fn, args, data, true)); !bytes.Equal(got, want) {
+				t.Fatalf("%s(%d bytes): got %x, want %x", tc.name, len(data), got, want)
+			}
+		}
+	}
+
+	ecrec := pre[common.BytesToAddress([]byte{1})]
+	keys := make([]*ecdsa.PrivateKey, 4)
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+	}
+	for i := 0; i < diffCases/4; i++ {
+		hash := make([]byte, 32)
+		r.Read(hash)
+		sig, err := crypto.Sign(hash, keys[r.Intn(len(keys))])
+		if err != nil {
+			t.Fatal(err)
+		}
+		h := new(big.Int).SetBytes(hash)
+		v := big.NewInt(int64(sig[64]) + 27)
+		rr := new(big.Int).SetBytes(sig[:32])
+		s := new(big.Int).SetBytes(sig[32:64])
+		switch r.Intn(6) {
+		case 0:
+			v.SetInt64(int64(sig[64])) // raw recovery id, rejected on Ethereum
+		case 1:
+			v.Add(v, new(big.Int).Lsh(big.NewInt(1), 200)) // high bits set
+		case 2:
+			s.Set(randWord(r))
+		case 3:
+			rr.SetInt64(0)
+		}
+		in := make([]byte, 0, 128)
+		for _, x := range []*big.Int{h, v, rr, s} {
+			in = append(in, common.LeftPadBytes(x.Bytes(), 32)...)
+		}
+		want, err := ecrec.Run(in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want = common.LeftPadBytes(want, 32) // nil on failure
+		// Stack layout: s on top, then r, v and hash.
+		got := word(runOurs(t, opECRECOVER, []*big.Int{s, rr, v, h}, nil, false))
+		if !bytes.Equal(got, want) {
+			t.Fatalf("ECRECOVER(v %x r %x s %x): got %x, want %x", v, rr, s, got, want)
+		}
+	}
+}
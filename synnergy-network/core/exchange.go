@@ -0,0 +1,216 @@
+package core
+
+// exchange.go – exchange integration: destination tags and deposit sweeps.
+//
+// Exchanges credit deposits to one hot address and tell customers apart by
+// the transaction's DestinationTag, or by its Memo. An address can demand a
+// tag, in which case untagged payments to it are rejected by the pool
+// instead of arriving unattributable. ScanDeposits lists what a range of
+// blocks paid to the hot address with the tags and memos to credit.
+//
+// Exchanges that hand out one address per customer instead consolidate them
+// into cold storage with a sweep. PlanSweep turns the balances of the
+// deposit addresses into one transfer per address that moves everything
+// except the fee, skips addresses whose balance does not cover the fee plus
+// a minimum, and groups the transfers into batches submitted together (see
+// sdk.Client.Sweep).
+//
+// Ledger layout:
+//   exchange:reqtag:<addr>   -> 1 while addr requires a destination tag
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"sort"
+	"time"
+)
+
+// MaxTxMemo is the longest memo a transaction may carry, in bytes.
+const MaxTxMemo = 256
+
+// DefaultSweepBatch is the number of transfers PlanSweep puts in a batch
+// unless told otherwise; it matches the nodes' batch endpoint limit.
+const DefaultSweepBatch = 100
+
+var (
+	ErrDestinationTagRequired = errors.New("destination tag required")
+	ErrMemoTooLong            = errors.New("transaction memo too long")
+)
+
+func requireTagKey(addr Address) []byte { return []byte("exchange:reqtag:" + addr.Hex()) }
+
+// SetRequireDestinationTag makes payments to addr require a destination tag,
+// or lifts the requirement.
+func SetRequireDestinationTag(addr Address, on bool) error {
+	if !on {
+		return CurrentStore().Delete(requireTagKey(addr))
+	}
+	return CurrentStore().Set(requireTagKey(addr), []byte{1})
+}
+
+// RequiresDestinationTag reports whether payments to addr need a tag.
+func RequiresDestinationTag(addr Address) bool {
+	v, err := CurrentStore().Get(requireTagKey(addr))
+	return err == nil && len(v) > 0
+}
+
+// CheckDestinationTag rejects over-long memos and untagged payments to
+// addresses that require a tag.
+func CheckDestinationTag(tx *Transaction) error {
+	if len(tx.Memo) > MaxTxMemo {
+		return fmt.Errorf("%w: %d bytes, max %d", ErrMemoTooLong, len(tx.Memo), MaxTxMemo)
+	}
+	if tx.DestinationTag != 0 || tx.Memo != "" {
+		return nil
+	}
+	if tx.Value > 0 && RequiresDestinationTag(tx.To) {
+		return fmt.Errorf("%w: %s", ErrDestinationTagRequired, tx.To.Hex())
+	}
+	for _, tr := range tx.TokenTransfers {
+		if RequiresDestinationTag(tr.To) {
+			return fmt.Errorf("%w: %s", ErrDestinationTagRequired, tr.To.Hex())
+		}
+	}
+	return nil
+}
+
+// ExchangeDeposit is a payment to a hot address.
+type ExchangeDeposit struct {
+	TxHash string    `json:"tx_hash"`
+	Height uint64    `json:"height"`
+	Time   time.Time `json:"time"`
+	From   Address   `json:"from"`
+	Token  TokenID   `json:"token"` // 0 for the coin
+	Amount uint64    `json:"amount"`
+	Tag    uint64    `json:"tag,omitempty"`
+	Memo   string    `json:"memo,omitempty"`
+}
+
+// ScanDeposits returns the coin and token payments to hot in blocks from
+// through to, inclusive, in chain order.
+func ScanDeposits(src BlockSource, hot Address, from, to uint64) ([]ExchangeDeposit, error) {
+	var out []ExchangeDeposit
+	for h := from; h <= to; h++ {
+		b, err := src.GetBlock(h)
+		if err != nil {
+			return nil, fmt.Errorf("block %d: %w", h, err)
+		}
+		at := time.UnixMilli(b.Header.Timestamp).UTC()
+		for _, tx := range b.Transactions {
+			d := ExchangeDeposit{TxHash: tx.IDHex(), Height: h, Time: at, From: tx.From,
+				Tag: tx.DestinationTag, Memo: tx.Memo}
+			if tx.To == hot && tx.Value > 0 {
+				d.Amount = tx.Value
+				out = append(out, d)
+			}
+			for _, tr := range tx.TokenTransfers {
+				if tr.To == hot && tr.Amount > 0 {
+					d.From, d.Token, d.Amount = tr.From, tr.Token, tr.Amount
+					out = append(out, d)
+				}
+			}
+		}
+	}
+	return out, nil
+}
+
+// SweepSource is a deposit address with its balance and next nonce.
+type SweepSource struct {
+	Address Address `json:"address"`
+	Balance uint64  `json:"balance"`
+	Nonce   uint64  `json:"nonce"`
+}
+
+// SweepOptions configure PlanSweep.
+type SweepOptions struct {
+	Cold      Address `json:"cold"`
+	GasLimit  uint64  `json:"gas_limit"`  // per transfer
+	GasPrice  uint64  `json:"gas_price"`  // per gas unit
+	MinAmount uint64  `json:"min_amount"` // smallest amount worth moving after fees
+	BatchSize int     `json:"batch_size"` // default DefaultSweepBatch
+}
+
+// SweepTransfer moves a deposit address's balance, less the fee, to cold
+// storage.
+type SweepTransfer struct {
+	From   Address `json:"from"`
+	Amount uint64  `json:"amount"`
+	Fee    uint64  `json:"fee"`
+	Nonce  uint64  `json:"nonce"`
+}
+
+// SweepSkip is a deposit address left alone and why.
+type SweepSkip struct {
+	Address Address `json:"address"`
+	Balance uint64  `json:"balance"`
+	Reason  string  `json:"reason"`
+}
+
+// SweepPlan is the set of transfers of a sweep.
+type SweepPlan struct {
+	Cold      Address         `json:"cold"`
+	GasLimit  uint64          `json:"gas_limit"`
+	GasPrice  uint64          `json:"gas_price"`
+	BatchSize int             `json:"batch_size"`
+	Transfers []SweepTransfer `json:"transfers"`
+	Skipped   []SweepSkip     `json:"skipped,omitempty"`
+	Total     uint64          `json:"total"`
+	Fees      uint64          `json:"fees"`
+}
+
+// PlanSweep plans moving the balances of sources to opts.Cold. Each
+// transfer leaves exactly the fee behind so the address is emptied. The
+// largest balances come first, so a sweep cut short has moved the most.
+func PlanSweep(sources []SweepSource, opts SweepOptions) (*SweepPlan, error) {
+	if opts.Cold == AddressZero {
+		return nil, NewError(CodeInvalidArgument, "exchange", "cold address required")
+	}
+	if opts.GasLimit == 0 || opts.GasPrice == 0 {
+		return nil, NewError(CodeInvalidArgument, "exchange", "gas limit and price must be >0")
+	}
+	hi, fee := bits.Mul64(opts.GasLimit, opts.GasPrice)
+	if hi != 0 {
+		return nil, NewError(CodeInvalidArgument, "exchange", "sweep fee overflows")
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultSweepBatch
+	}
+	p := &SweepPlan{Cold: opts.Cold, GasLimit: opts.GasLimit, GasPrice: opts.GasPrice, BatchSize: opts.BatchSize}
+	seen := make(map[Address]bool, len(sources))
+	for _, s := range sources {
+		switch {
+		case seen[s.Address]:
+			continue
+		case s.Address == opts.Cold:
+			p.Skipped = append(p.Skipped, SweepSkip{s.Address, s.Balance, "cold address"})
+		case s.Balance <= fee || s.Balance-fee < opts.MinAmount:
+			p.Skipped = append(p.Skipped, SweepSkip{s.Address, s.Balance, "balance does not cover fee and minimum"})
+		default:
+			p.Transfers = append(p.Transfers, SweepTransfer{From: s.Address, Amount: s.Balance - fee, Fee: fee, Nonce: s.Nonce})
+			p.Total += s.Balance - fee
+			p.Fees += fee
+		}
+		seen[s.Address] = true
+	}
+	sort.SliceStable(p.Transfers, func(i, j int) bool { return p.Transfers[i].Amount > p.Transfers[j].Amount })
+	return p, nil
+}
+
+// Batches splits the transfers into groups of at most BatchSize.
+func (p *SweepPlan) Batches() [][]SweepTransfer {
+	n := p.BatchSize
+	if n <= 0 {
+		n = DefaultSweepBatch
+	}
+	var out [][]SweepTransfer
+	for t := p.Transfers; len(t) > 0; {
+		k := n
+		if k > len(t) {
+			k = len(t)
+		}
+		out = append(out, t[:k])
+		t = t[k:]
+	}
+	return out
+}
@@ -23,6 +23,7 @@ type ExecutionManager struct {
 
 	header BlockHeader
 	txs    []*Transaction
+	logs   []Log
 }
 
 // NewExecutionManager wires an execution manager with the given ledger and VM.
@@ -36,9 +37,11 @@ func (em *ExecutionManager) BeginBlock(height uint64) {
 	defer em.mu.Unlock()
 	em.header = BlockHeader{Height: height, Timestamp: time.Now().UnixMilli()}
 	em.txs = em.txs[:0]
+	em.logs = nil
 }
 
-// ExecuteTx runs a transaction through the VM and records it if successful.
+// ExecuteTx runs a transaction through the VM and records it and its logs if
+// successful.
 func (em *ExecutionManager) ExecuteTx(tx *Transaction) error {
 	em.mu.Lock()
 	defer em.mu.Unlock()
@@ -58,21 +61,30 @@ func (em *ExecutionManager) ExecuteTx(tx *Transaction) error {
 		State:       em.ledger,
 	}}
 
-	if _, err := em.vm.Execute(tx.Payload, ctx); err != nil {
+	rec, err := em.vm.Execute(tx.Payload, ctx)
+	if err != nil {
 		return err
 	}
 	em.txs = append(em.txs, tx)
+	if rec != nil {
+		for _, lg := range rec.Logs {
+			lg.BlockHeight, lg.TxHash = em.header.Height, tx.Hash
+			em.logs = append(em.logs, lg)
+		}
+	}
 	return nil
 }
 
-// FinalizeBlock writes the collected transactions to the ledger and returns the
-// resulting block structure.
+// FinalizeBlock writes the collected transactions and their logs bloom to the
+// ledger and returns the resulting block structure.
 func (em *ExecutionManager) FinalizeBlock() (*Block, error) {
 	em.mu.Lock()
 	defer em.mu.Unlock()
 
+	em.header.LogsBloom = LogsBloom(em.logs)
 	block := &Block{
 		Header:       em.header,
+		Body:         BlockBody{Logs: em.logs},
 		Transactions: em.txs,
 	}
 	if err := em.ledger.AddBlock(block); err != nil {
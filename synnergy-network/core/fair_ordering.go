@@ -0,0 +1,382 @@
+package core
+
+// fair_ordering.go – receipt-time fair ordering for sub-blocks.
+//
+// In OrderingFair mode every validator signs a receipt with the time it
+// first saw a transaction and gossips it on the "txreceipt" topic. The
+// proposer orders a sub-block by the median receipt time of each
+// transaction (ties broken by hash) and ships the receipts in the body, so
+// any node can recompute the order with VerifyFairOrder. A proposer that
+// wants to front-run a transaction would have to get its own transaction
+// seen earlier by a majority of validators, not merely place it first.
+//
+// Transactions with fewer than MinReceipts receipts are held back for a
+// later sub-block until MaxWait has passed since they were first seen
+// locally; after that they are ordered on the receipts available.
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// OrderingMode selects how a proposer orders sub-block transactions.
+type OrderingMode uint8
+
+const (
+	OrderingPool OrderingMode = iota // order as picked from the pool
+	OrderingFair                     // median validator receipt time
+)
+
+func (m OrderingMode) String() string {
+	if m == OrderingFair {
+		return "fair"
+	}
+	return "pool"
+}
+
+// ParseOrderingMode accepts "pool" or "fair".
+func ParseOrderingMode(s string) (OrderingMode, error) {
+	switch s {
+	case "pool", "":
+		return OrderingPool, nil
+	case "fair":
+		return OrderingFair, nil
+	}
+	return OrderingPool, fmt.Errorf("unknown ordering mode %q", s)
+}
+
+// FairOrderingConfig configures sub-block ordering.
+type FairOrderingConfig struct {
+	Mode        OrderingMode
+	MinReceipts int           // validator receipts a tx needs before it is ordered (0 = 1)
+	MaxWait     time.Duration // longest a tx is held waiting for receipts (0 = 2s)
+}
+
+// receiptTTL bounds how long receipts for never-included transactions are
+// kept.
+const receiptTTL = 10 * time.Minute
+
+// TxReceipt is a validator's signed statement of when it first saw a
+// transaction.
+type TxReceipt struct {
+	TxHash    Hash    `json:"tx"`
+	Validator Address `json:"validator"`
+	SeenAt    int64   `json:"seen_at"` // unix ms
+	Sig       []byte  `json:"sig"`
+}
+
+func (r TxReceipt) digest() []byte {
+	h := sha256.New()
+	h.Write([]byte("txreceipt"))
+	h.Write(r.TxHash[:])
+	var ts [8]byte
+	binary.BigEndian.PutUint64(ts[:], uint64(r.SeenAt))
+	h.Write(ts[:])
+	return h.Sum(nil)
+}
+
+// SignTxReceipt issues a receipt for txHash seen at seenAt (unix ms).
+func SignTxReceipt(key *ecdsa.PrivateKey, txHash Hash, seenAt int64) (TxReceipt, error) {
+	r := TxReceipt{
+		TxHash:    txHash,
+		Validator: FromCommon(crypto.PubkeyToAddress(key.PublicKey)),
+		SeenAt:    seenAt,
+	}
+	sig, err := crypto.Sign(r.digest(), key)
+	if err != nil {
+		return TxReceipt{}, err
+	}
+	r.Sig = sig
+	return r, nil
+}
+
+// Verify checks that the receipt was signed by its Validator.
+func (r TxReceipt) Verify() error {
+	pub, err := crypto.Ecrecover(r.digest(), r.Sig)
+	if err != nil {
+		return err
+	}
+	addr, err := AddressFromPubKey(pub)
+	if err != nil {
+		return err
+	}
+	if addr != r.Validator {
+		return errors.New("receipt signer mismatch")
+	}
+	return nil
+}
+
+// ReceiptBook collects receipts per transaction, one per validator.
+type ReceiptBook struct {
+	mu        sync.Mutex
+	byTx      map[Hash]map[Address]TxReceipt
+	firstSeen map[Hash]time.Time
+}
+
+// NewReceiptBook returns an empty book.
+func NewReceiptBook() *ReceiptBook {
+	return &ReceiptBook{
+		byTx:      make(map[Hash]map[Address]TxReceipt),
+		firstSeen: make(map[Hash]time.Time),
+	}
+}
+
+// Add verifies r and stores it. A validator's first receipt for a
+// transaction wins; later ones are ignored.
+func (b *ReceiptBook) Add(r TxReceipt) error {
+	if err := r.Verify(); err != nil {
+		return err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	m := b.byTx[r.TxHash]
+	if m == nil {
+		m = make(map[Address]TxReceipt)
+		b.byTx[r.TxHash] = m
+		b.firstSeen[r.TxHash] = time.Now()
+	}
+	if _, ok := m[r.Validator]; !ok {
+		m[r.Validator] = r
+	}
+	return nil
+}
+
+// Receipts returns the receipts for h ordered by validator address.
+func (b *ReceiptBook) Receipts(h Hash) []TxReceipt {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	out := make([]TxReceipt, 0, len(b.byTx[h]))
+	for _, r := range b.byTx[h] {
+		out = append(out, r)
+	}
+	sort.Slice(out, func(i, j int) bool { return bytes.Compare(out[i].Validator[:], out[j].Validator[:]) < 0 })
+	return out
+}
+
+func (b *ReceiptBook) waited(h Hash) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if t, ok := b.firstSeen[h]; ok {
+		return time.Since(t)
+	}
+	return 0
+}
+
+// Forget drops the receipts for the given transactions and any older than
+// receiptTTL.
+func (b *ReceiptBook) Forget(hs ...Hash) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, h := range hs {
+		delete(b.byTx, h)
+		delete(b.firstSeen, h)
+	}
+	for h, t := range b.firstSeen {
+		if time.Since(t) > receiptTTL {
+			delete(b.byTx, h)
+			delete(b.firstSeen, h)
+		}
+	}
+}
+
+// medianSeen returns the lower median receipt time.
+func medianSeen(rs []TxReceipt) int64 {
+	ts := make([]int64, len(rs))
+	for i, r := range rs {
+		ts[i] = r.SeenAt
+	}
+	sort.Slice(ts, func(i, j int) bool { return ts[i] < ts[j] })
+	return ts[(len(ts)-1)/2]
+}
+
+func fairLess(ti, tj int64, hi, hj Hash) bool {
+	if ti != tj {
+		return ti < tj
+	}
+	return bytes.Compare(hi[:], hj[:]) < 0
+}
+
+// SetFairOrdering configures sub-block ordering. key signs this node's
+// receipts; with a nil key the node only collects receipts from others.
+func (sc *SynnergyConsensus) SetFairOrdering(cfg FairOrderingConfig, key *ecdsa.PrivateKey) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.ordering = cfg
+	sc.receiptKey = key
+	if sc.receipts == nil {
+		sc.receipts = NewReceiptBook()
+	}
+}
+
+func (sc *SynnergyConsensus) fairConfig() FairOrderingConfig {
+	sc.mu.Lock()
+	cfg := sc.ordering
+	sc.mu.Unlock()
+	if cfg.MinReceipts <= 0 {
+		cfg.MinReceipts = 1
+	}
+	if cfg.MaxWait <= 0 {
+		cfg.MaxWait = 2 * time.Second
+	}
+	return cfg
+}
+
+// ObserveTx records that this node has seen tx now, issuing and gossiping
+// a receipt when a receipt key is configured.
+func (sc *SynnergyConsensus) ObserveTx(tx *Transaction) error {
+	sc.mu.Lock()
+	key, book := sc.receiptKey, sc.receipts
+	sc.mu.Unlock()
+	if key == nil || book == nil {
+		return nil
+	}
+	r, err := SignTxReceipt(key, tx.Hash, time.Now().UnixMilli())
+	if err != nil {
+		return err
+	}
+	if err := book.Add(r); err != nil {
+		return err
+	}
+	if b, ok := sc.p2p.(broadcaster); ok {
+		return b.Broadcast("txreceipt", r)
+	}
+	return nil
+}
+
+// handleTxReceipt stores receipts gossiped by other validators. With a
+// validator set configured, receipts from non-validators are dropped.
+func (sc *SynnergyConsensus) handleTxReceipt(msg InboundMsg) {
+	var rs []TxReceipt
+	if err := json.Unmarshal(msg.Payload, &rs); err != nil {
+		var r TxReceipt
+		if json.Unmarshal(msg.Payload, &r) != nil {
+			return
+		}
+		rs = []TxReceipt{r}
+	}
+	sc.mu.Lock()
+	book, vm := sc.receipts, sc.validators
+	sc.mu.Unlock()
+	if book == nil {
+		return
+	}
+	for _, r := range rs {
+		if vm != nil && !vm.IsValidator(r.Validator) {
+			continue
+		}
+		_ = book.Add(r)
+	}
+}
+
+// fairOrder orders raw transactions by median receipt time. Transactions
+// still waiting for receipts are held for the next proposal.
+func (sc *SynnergyConsensus) fairOrder(raw [][]byte, cfg FairOrderingConfig) ([][]byte, []TxReceipt) {
+	type entry struct {
+		raw  []byte
+		hash Hash
+		at   int64
+		rs   []TxReceipt
+	}
+	sc.mu.Lock()
+	book := sc.receipts
+	sc.mu.Unlock()
+	if book == nil {
+		return raw, nil
+	}
+	var ready []entry
+	var held [][]byte
+	for _, b := range raw {
+		var tx Transaction
+		if err := json.Unmarshal(b, &tx); err != nil {
+			continue
+		}
+		rs := book.Receipts(tx.Hash)
+		if len(rs) == 0 {
+			_ = sc.ObserveTx(&tx) // first sighting: stamp it now
+			rs = book.Receipts(tx.Hash)
+		}
+		if len(rs) == 0 || (len(rs) < cfg.MinReceipts && book.waited(tx.Hash) < cfg.MaxWait) {
+			held = append(held, b)
+			continue
+		}
+		ready = append(ready, entry{raw: b, hash: tx.Hash, at: medianSeen(rs), rs: rs})
+	}
+	sort.Slice(ready, func(i, j int) bool { return fairLess(ready[i].at, ready[j].at, ready[i].hash, ready[j].hash) })
+
+	sc.mu.Lock()
+	sc.held = append(sc.held, held...)
+	sc.mu.Unlock()
+
+	out := make([][]byte, len(ready))
+	var receipts []TxReceipt
+	hashes := make([]Hash, len(ready))
+	for i, e := range ready {
+		out[i] = e.raw
+		receipts = append(receipts, e.rs...)
+		hashes[i] = e.hash
+	}
+	book.Forget(hashes...)
+	return out, receipts
+}
+
+// takeHeld returns transactions held back by fair ordering.
+func (sc *SynnergyConsensus) takeHeld() [][]byte {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	held := sc.held
+	sc.held = nil
+	return held
+}
+
+// VerifyFairOrder checks that a sub-block is in fair order: every
+// transaction carries at least one valid receipt in the body and the
+// transactions are sorted by median receipt time, ties by hash. A non-nil
+// isValidator restricts which receipts count.
+func VerifyFairOrder(sb *SubBlock, isValidator func(Address) bool) error {
+	byTx := make(map[Hash][]TxReceipt)
+	seen := make(map[Hash]map[Address]bool)
+	for _, r := range sb.Body.Receipts {
+		if isValidator != nil && !isValidator(r.Validator) {
+			continue
+		}
+		if seen[r.TxHash][r.Validator] {
+			return fmt.Errorf("duplicate receipt from %s", r.Validator.Hex())
+		}
+		if err := r.Verify(); err != nil {
+			return fmt.Errorf("receipt from %s: %w", r.Validator.Hex(), err)
+		}
+		if seen[r.TxHash] == nil {
+			seen[r.TxHash] = make(map[Address]bool)
+		}
+		seen[r.TxHash][r.Validator] = true
+		byTx[r.TxHash] = append(byTx[r.TxHash], r)
+	}
+	var prevAt int64
+	var prev Hash
+	for i, b := range sb.Body.Transactions {
+		var tx Transaction
+		if err := json.Unmarshal(b, &tx); err != nil {
+			return fmt.Errorf("tx %d: %w", i, err)
+		}
+		rs := byTx[tx.Hash]
+		if len(rs) == 0 {
+			return fmt.Errorf("tx %s has no receipts", tx.IDHex())
+		}
+		at := medianSeen(rs)
+		if i > 0 && fairLess(at, prevAt, tx.Hash, prev) {
+			return fmt.Errorf("tx %s out of fair order", tx.IDHex())
+		}
+		prevAt, prev = at, tx.Hash
+	}
+	return nil
+}
@@ -1,14 +1,70 @@
 package core
 
+// faucet.go – testnet faucet.
+//
+// A claim must carry either a solved proof-of-work challenge or a CAPTCHA
+// token accepted by the configured CaptchaVerifier. Challenges are bound to
+// the claiming address and can be used once. Cooldowns are enforced per
+// address and per client IP and are persisted in the ledger so a restart
+// does not reset them:
+//   faucet:addr:<addr hex>   -> unix seconds of last dispense
+//   faucet:ip:<ip>           -> unix seconds of last dispense
+
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"math/bits"
 	"sync"
 	"time"
 
 	"github.com/sirupsen/logrus"
 )
 
+var (
+	ErrFaucetCooldown  = errors.New("faucet: cooldown active")
+	ErrFaucetChallenge = errors.New("faucet: challenge invalid or expired")
+	ErrFaucetCaptcha   = errors.New("faucet: captcha rejected")
+)
+
+// FaucetChallengeTTL bounds how long an issued challenge may be solved.
+const FaucetChallengeTTL = 10 * time.Minute
+
+// CaptchaVerifier validates a CAPTCHA response token for a client IP.
+type CaptchaVerifier interface {
+	VerifyCaptcha(token, ip string) error
+}
+
+// FaucetChallenge is a proof-of-work puzzle. A solution is any string S such
+// that sha256(Nonce || ":" || address hex || ":" || S) has at least
+// Difficulty leading zero bits.
+type FaucetChallenge struct {
+	Nonce      string    `json:"nonce"`
+	Difficulty uint8     `json:"difficulty"`
+	Expires    time.Time `json:"expires"`
+}
+
+// FaucetClaim is a request for funds.
+type FaucetClaim struct {
+	To       Address `json:"to"`
+	IP       string  `json:"ip,omitempty"`
+	Nonce    string  `json:"nonce,omitempty"`
+	Solution string  `json:"solution,omitempty"`
+	Captcha  string  `json:"captcha,omitempty"`
+}
+
+// FaucetGrant describes a successful dispense.
+type FaucetGrant struct {
+	To        Address   `json:"to"`
+	Token     TokenID   `json:"token"`
+	Amount    uint64    `json:"amount"`
+	Time      time.Time `json:"time"`
+	NextClaim time.Time `json:"next_claim"`
+}
+
 // FaucetAccount is the default funding account used by the faucet.
 var FaucetAccount Address
 
@@ -28,8 +84,12 @@ type Faucet struct {
 	amount   uint64        // amount per request
 	cooldown time.Duration // minimum time between requests per address
 
-	mu   sync.Mutex
-	last map[Address]time.Time
+	ipCooldown time.Duration // minimum time between requests per IP
+	difficulty uint8         // proof-of-work leading zero bits
+	captcha    CaptchaVerifier
+
+	mu         sync.Mutex
+	challenges map[string]time.Time
 }
 
 // NewFaucet creates a new faucet bound to the given ledger. The faucet
@@ -44,46 +104,172 @@ func NewFaucet(lg *logrus.Logger, led *Ledger, token TokenID, amount uint64, coo
 		token:    token,
 		amount:   amount,
 		cooldown: cooldown,
-		last:     make(map[Address]time.Time),
+
+		ipCooldown: cooldown,
+		difficulty: 20,
+		challenges: make(map[string]time.Time),
 	}
 }
 
 // Request sends faucet funds to the specified address if the cooldown
 // period has elapsed. It returns an error if the faucet balance is
-// insufficient or if rate limiting blocks the request.
+// insufficient or if rate limiting blocks the request. Request performs no
+// challenge check and is meant for trusted callers such as the CLI.
 func (f *Faucet) Request(to Address) error {
 	if f == nil || f.ledger == nil {
 		return errors.New("faucet not initialised")
 	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, err := f.dispense(to, "")
+	return err
+}
 
+// Challenge issues a proof-of-work challenge.
+func (f *Faucet) Challenge() (FaucetChallenge, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return FaucetChallenge{}, err
+	}
 	f.mu.Lock()
 	defer f.mu.Unlock()
+	now := time.Now().UTC()
+	for n, exp := range f.challenges {
+		if now.After(exp) {
+			delete(f.challenges, n)
+		}
+	}
+	ch := FaucetChallenge{Nonce: hex.EncodeToString(b[:]), Difficulty: f.difficulty, Expires: now.Add(FaucetChallengeTTL)}
+	f.challenges[ch.Nonce] = ch.Expires
+	return ch, nil
+}
+
+// Claim dispenses funds after checking the challenge or CAPTCHA and the
+// address and IP cooldowns.
+func (f *Faucet) Claim(c FaucetClaim) (*FaucetGrant, error) {
+	if f == nil || f.ledger == nil {
+		return nil, errors.New("faucet not initialised")
+	}
+	if c.To == AddressZero {
+		return nil, errors.New("faucet: recipient required")
+	}
+	if c.Captcha != "" {
+		f.mu.Lock()
+		cv := f.captcha
+		f.mu.Unlock()
+		if cv == nil {
+			return nil, fmt.Errorf("%w: captcha not configured", ErrFaucetCaptcha)
+		}
+		if err := cv.VerifyCaptcha(c.Captcha, c.IP); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrFaucetCaptcha, err)
+		}
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if c.Captcha == "" {
+		exp, ok := f.challenges[c.Nonce]
+		if !ok || time.Now().After(exp) || !FaucetSolutionValid(c.Nonce, c.To, c.Solution, f.difficulty) {
+			return nil, ErrFaucetChallenge
+		}
+		delete(f.challenges, c.Nonce)
+	}
+	return f.dispense(c.To, c.IP)
+}
+
+// FaucetSolutionValid checks a proof-of-work solution.
+func FaucetSolutionValid(nonce string, to Address, solution string, difficulty uint8) bool {
+	h := sha256.Sum256([]byte(nonce + ":" + to.Hex() + ":" + solution))
+	zeros := 0
+	for _, b := range h {
+		if b != 0 {
+			zeros += bits.LeadingZeros8(b)
+			break
+		}
+		zeros += 8
+	}
+	return zeros >= int(difficulty)
+}
 
+// dispense transfers the drip amount and records the cooldowns. f.mu must be
+// held.
+func (f *Faucet) dispense(to Address, ip string) (*FaucetGrant, error) {
 	now := time.Now().UTC()
-	if ts, ok := f.last[to]; ok {
-		if now.Sub(ts) < f.cooldown {
-			wait := f.cooldown - now.Sub(ts)
-			return fmt.Errorf("faucet: cooldown %s remaining", wait)
+	if wait := f.remaining(faucetAddrKey(to), f.cooldown, now); wait > 0 {
+		return nil, fmt.Errorf("%w: %s remaining", ErrFaucetCooldown, wait)
+	}
+	if ip != "" {
+		if wait := f.remaining(faucetIPKey(ip), f.ipCooldown, now); wait > 0 {
+			return nil, fmt.Errorf("%w: %s remaining for %s", ErrFaucetCooldown, wait, ip)
 		}
 	}
 
 	if f.token == 0 {
 		if err := f.ledger.Transfer(FaucetAccount, to, f.amount); err != nil {
-			return err
+			return nil, err
 		}
 	} else {
 		tok, ok := GetToken(f.token)
 		if !ok {
-			return fmt.Errorf("token %d not found", f.token)
+			return nil, fmt.Errorf("token %d not found", f.token)
 		}
 		if err := tok.Transfer(FaucetAccount, to, f.amount); err != nil {
-			return err
+			return nil, err
 		}
 	}
 
-	f.last[to] = now
-	f.logger.WithFields(logrus.Fields{"to": to.String(), "amount": f.amount}).Info("faucet dispense")
-	return nil
+	var ts [8]byte
+	binary.BigEndian.PutUint64(ts[:], uint64(now.Unix()))
+	f.ledger.SetState(faucetAddrKey(to), ts[:])
+	if ip != "" {
+		f.ledger.SetState(faucetIPKey(ip), ts[:])
+	}
+	f.logger.WithFields(logrus.Fields{"to": to.String(), "ip": ip, "amount": f.amount}).Info("faucet dispense")
+	return &FaucetGrant{To: to, Token: f.token, Amount: f.amount, Time: now, NextClaim: now.Add(f.cooldown)}, nil
+}
+
+func (f *Faucet) remaining(key []byte, cooldown time.Duration, now time.Time) time.Duration {
+	raw, err := f.ledger.GetState(key)
+	if err != nil || len(raw) != 8 {
+		return 0
+	}
+	last := time.Unix(int64(binary.BigEndian.Uint64(raw)), 0)
+	if wait := cooldown - now.Sub(last); wait > 0 {
+		return wait
+	}
+	return 0
+}
+
+func faucetAddrKey(a Address) []byte { return []byte("faucet:addr:" + a.Hex()) }
+
+func faucetIPKey(ip string) []byte { return []byte("faucet:ip:" + ip) }
+
+// TopUp moves amt from an operator account into the faucet.
+func (f *Faucet) TopUp(from Address, amt uint64) error {
+	return f.move(from, FaucetAccount, amt)
+}
+
+// Withdraw moves amt out of the faucet to an operator account.
+func (f *Faucet) Withdraw(to Address, amt uint64) error {
+	return f.move(FaucetAccount, to, amt)
+}
+
+func (f *Faucet) move(from, to Address, amt uint64) error {
+	if f == nil || f.ledger == nil {
+		return errors.New("faucet not initialised")
+	}
+	if amt == 0 {
+		return errors.New("amount must be >0")
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.token == 0 {
+		return f.ledger.Transfer(from, to, amt)
+	}
+	tok, ok := GetToken(f.token)
+	if !ok {
+		return fmt.Errorf("token %d not found", f.token)
+	}
+	return tok.Transfer(from, to, amt)
 }
 
 // Balance returns the current balance held by the faucet account.
@@ -114,3 +300,49 @@ func (f *Faucet) SetCooldown(d time.Duration) {
 	f.cooldown = d
 	f.mu.Unlock()
 }
+
+// SetIPCooldown modifies the cooldown between requests from one IP.
+func (f *Faucet) SetIPCooldown(d time.Duration) {
+	f.mu.Lock()
+	f.ipCooldown = d
+	f.mu.Unlock()
+}
+
+// SetDifficulty sets the proof-of-work difficulty in leading zero bits.
+func (f *Faucet) SetDifficulty(n uint8) {
+	f.mu.Lock()
+	f.difficulty = n
+	f.mu.Unlock()
+}
+
+// SetCaptchaVerifier enables CAPTCHA tokens as an alternative to
+// proof-of-work.
+func (f *Faucet) SetCaptchaVerifier(v CaptchaVerifier) {
+	f.mu.Lock()
+	f.captcha = v
+	f.mu.Unlock()
+}
+
+// FaucetSettings is a snapshot of the faucet configuration.
+type FaucetSettings struct {
+	Token      TokenID       `json:"token"`
+	Amount     uint64        `json:"amount"`
+	Cooldown   time.Duration `json:"cooldown"`
+	IPCooldown time.Duration `json:"ip_cooldown"`
+	Difficulty uint8         `json:"difficulty"`
+	Captcha    bool          `json:"captcha"`
+}
+
+// Settings returns the current configuration.
+func (f *Faucet) Settings() FaucetSettings {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return FaucetSettings{
+		Token:      f.token,
+		Amount:     f.amount,
+		Cooldown:   f.cooldown,
+		IPCooldown: f.ipCooldown,
+		Difficulty: f.difficulty,
+		Captcha:    f.captcha != nil,
+	}
+}
@@ -0,0 +1,187 @@
+package core
+
+// fee_oracle.go – gas price estimation from recent block inclusion.
+//
+// The FeeOracle samples the last blocks of a BlockSource. For each block it
+// records how full it was (gas limits of its transactions over the
+// governance block_gas_limit) and the lowest gas price it included. A
+// block below FeeFullThreshold had room for any transaction, so the price
+// that got into it is the floor; only fuller blocks set a real threshold.
+//
+// Samples are grouped into fullness buckets. An estimate uses the bucket
+// the latest blocks fall into, so a busy chain is priced from busy blocks.
+// A transaction paying price p gets into one block of the bucket with
+// probability q, the share of its samples with a threshold at or below p,
+// and into one of the next n blocks with probability 1-(1-q)^n. EstimateFee
+// returns the lowest sampled threshold reaching FeeConfidence within the
+// target, i.e. the 1-(1-FeeConfidence)^(1/n) quantile.
+
+import (
+	"math"
+	"sort"
+	"sync"
+)
+
+const (
+	// DefaultFeeWindow is the number of recent blocks an oracle samples.
+	DefaultFeeWindow = 200
+	// DefaultFeeTarget is the inclusion target, in blocks, when none is given.
+	DefaultFeeTarget = 3
+	// FeeConfidence is the inclusion probability estimates aim for.
+	FeeConfidence = 0.95
+	// FeeFullThreshold is the fullness above which a block was contended.
+	FeeFullThreshold = 0.5
+	// MinGasPrice is the floor price of an uncontended block.
+	MinGasPrice uint64 = 1
+	// feeRecentBlocks is how many of the newest blocks pick the bucket.
+	feeRecentBlocks = 5
+	// feeMinSamples is the bucket size below which all samples are used.
+	feeMinSamples = 10
+)
+
+// Fee buckets by block fullness.
+const (
+	FeeBucketLow  = "low"  // below FeeFullThreshold
+	FeeBucketHigh = "high" // FeeFullThreshold to 90%
+	FeeBucketFull = "full" // 90% and above
+)
+
+// FeeEstimate is a gas price likely to be included within TargetBlocks.
+type FeeEstimate struct {
+	TargetBlocks int     `json:"target_blocks"`
+	GasPrice     uint64  `json:"gas_price"`
+	Confidence   float64 `json:"confidence"`
+	Bucket       string  `json:"bucket"`
+	Fullness     float64 `json:"fullness"` // mean of the latest blocks
+	Samples      int     `json:"samples"`
+	Height       uint64  `json:"height"`
+}
+
+type feeSample struct {
+	height    uint64
+	fullness  float64
+	threshold uint64
+}
+
+// FeeOracle estimates gas prices from the blocks of a source.
+type FeeOracle struct {
+	src    BlockSource
+	window int
+
+	mu      sync.Mutex
+	samples []feeSample // oldest first
+}
+
+// NewFeeOracle returns an oracle sampling the last window blocks of src;
+// window <= 0 means DefaultFeeWindow.
+func NewFeeOracle(src BlockSource, window int) *FeeOracle {
+	if window <= 0 {
+		window = DefaultFeeWindow
+	}
+	return &FeeOracle{src: src, window: window}
+}
+
+// sync samples the blocks added since the last call.
+func (o *FeeOracle) sync() error {
+	head := o.src.LastHeight()
+	var next uint64
+	if n := len(o.samples); n > 0 {
+		next = o.samples[n-1].height + 1
+		if next > head+1 {
+			// the chain was rebuilt shorter; resample it
+			o.samples, next = nil, 0
+		}
+	}
+	if head+1 > uint64(o.window) && next < head+1-uint64(o.window) {
+		next = head + 1 - uint64(o.window)
+	}
+	for h := next; h <= head; h++ {
+		b, err := o.src.GetBlock(h)
+		if err != nil {
+			return err
+		}
+		o.samples = append(o.samples, sampleBlock(b))
+	}
+	if len(o.samples) > o.window {
+		o.samples = append([]feeSample(nil), o.samples[len(o.samples)-o.window:]...)
+	}
+	return nil
+}
+
+func sampleBlock(b *Block) feeSample {
+	s := feeSample{height: b.Header.Height, threshold: MinGasPrice}
+	var gas uint64
+	low := uint64(math.MaxUint64)
+	for _, tx := range b.Transactions {
+		gas += tx.GasLimit
+		if tx.GasPrice < low {
+			low = tx.GasPrice
+		}
+	}
+	if blockGasLimit > 0 {
+		s.fullness = math.Min(float64(gas)/float64(blockGasLimit), 1)
+	}
+	if s.fullness >= FeeFullThreshold && low > MinGasPrice && low != math.MaxUint64 {
+		s.threshold = low
+	}
+	return s
+}
+
+func feeBucket(fullness float64) string {
+	switch {
+	case fullness >= 0.9:
+		return FeeBucketFull
+	case fullness >= FeeFullThreshold:
+		return FeeBucketHigh
+	default:
+		return FeeBucketLow
+	}
+}
+
+// EstimateFee returns the gas price a transaction should pay to be
+// included within targetBlocks blocks with FeeConfidence.
+func (o *FeeOracle) EstimateFee(targetBlocks int) (*FeeEstimate, error) {
+	if targetBlocks < 1 {
+		return nil, NewError(CodeInvalidArgument, "fees", "target blocks must be >=1")
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if err := o.sync(); err != nil {
+		return nil, err
+	}
+	est := &FeeEstimate{TargetBlocks: targetBlocks, GasPrice: MinGasPrice, Confidence: FeeConfidence, Bucket: FeeBucketLow}
+	if len(o.samples) == 0 {
+		return est, nil
+	}
+	est.Height = o.samples[len(o.samples)-1].height
+	recent := o.samples
+	if len(recent) > feeRecentBlocks {
+		recent = recent[len(recent)-feeRecentBlocks:]
+	}
+	for _, s := range recent {
+		est.Fullness += s.fullness
+	}
+	est.Fullness /= float64(len(recent))
+	est.Bucket = feeBucket(est.Fullness)
+
+	var prices []uint64
+	for _, s := range o.samples {
+		if feeBucket(s.fullness) == est.Bucket {
+			prices = append(prices, s.threshold)
+		}
+	}
+	if len(prices) < feeMinSamples {
+		prices = prices[:0]
+		for _, s := range o.samples {
+			prices = append(prices, s.threshold)
+		}
+	}
+	sort.Slice(prices, func(i, j int) bool { return prices[i] < prices[j] })
+	q := 1 - math.Pow(1-FeeConfidence, 1/float64(targetBlocks))
+	i := int(math.Ceil(q*float64(len(prices)))) - 1
+	if i < 0 {
+		i = 0
+	}
+	est.GasPrice, est.Samples = prices[i], len(prices)
+	return est, nil
+}
@@ -12,8 +12,9 @@ import (
 
 // ForumEngine manages on-chain discussion threads and comments.
 type ForumEngine struct {
-	led StateRW
-	mu  sync.RWMutex
+	led   StateRW
+	guard *ContentGuard
+	mu    sync.RWMutex
 }
 
 var (
@@ -22,7 +23,9 @@ var (
 )
 
 // InitForum initialises the global forum engine with a ledger backend.
-func InitForum(led StateRW) { forumOnce.Do(func() { forum = &ForumEngine{led: led} }) }
+func InitForum(led StateRW) {
+	forumOnce.Do(func() { forum = &ForumEngine{led: led, guard: NewContentGuard(led)} })
+}
 
 // Forum returns the singleton forum engine.
 func Forum() *ForumEngine { return forum }
@@ -62,6 +65,9 @@ func (f *ForumEngine) CreateThread(author Address, title, body string) (Hash, er
 	t := Thread{Creator: author, Title: title, Body: body, CreatedAt: time.Now().Unix()}
 	sum := sha256.Sum256([]byte(fmt.Sprintf("%x-%d-%s", author, t.CreatedAt, title)))
 	t.ID = sum
+	if err := f.guard.AdmitPost(ContentThread, hex.EncodeToString(t.ID[:]), author); err != nil {
+		return Hash{}, err
+	}
 	b, err := json.Marshal(t)
 	if err != nil {
 		return Hash{}, err
@@ -81,6 +87,9 @@ func (f *ForumEngine) GetThread(id Hash) (*Thread, error) {
 	if data == nil {
 		return nil, fmt.Errorf("thread %x not found", id)
 	}
+	if f.guard.IsHidden(ContentThread, hex.EncodeToString(id[:])) {
+		return nil, ErrContentHidden
+	}
 	var t Thread
 	if err := json.Unmarshal(data, &t); err != nil {
 		return nil, err
@@ -88,7 +97,7 @@ func (f *ForumEngine) GetThread(id Hash) (*Thread, error) {
 	return &t, nil
 }
 
-// ListThreads returns all existing threads.
+// ListThreads returns all threads not hidden by moderation.
 func (f *ForumEngine) ListThreads() ([]Thread, error) {
 	it := f.led.PrefixIterator([]byte("forum:thread:"))
 	var out []Thread
@@ -97,6 +106,9 @@ func (f *ForumEngine) ListThreads() ([]Thread, error) {
 		if err := json.Unmarshal(it.Value(), &t); err != nil {
 			return nil, err
 		}
+		if f.guard.IsHidden(ContentThread, hex.EncodeToString(t.ID[:])) {
+			continue
+		}
 		out = append(out, t)
 	}
 	return out, it.Error()
@@ -113,6 +125,9 @@ func (f *ForumEngine) AddComment(tid Hash, author Address, body string) (Hash, e
 	c := Comment{ThreadID: tid, Author: author, Body: body, CreatedAt: time.Now().Unix()}
 	sum := sha256.Sum256([]byte(fmt.Sprintf("%x-%d-%s", author, c.CreatedAt, body)))
 	c.ID = sum
+	if err := f.guard.AdmitPost(ContentComment, hex.EncodeToString(c.ID[:]), author); err != nil {
+		return Hash{}, err
+	}
 	b, err := json.Marshal(c)
 	if err != nil {
 		return Hash{}, err
@@ -123,7 +138,7 @@ func (f *ForumEngine) AddComment(tid Hash, author Address, body string) (Hash, e
 	return c.ID, nil
 }
 
-// ListComments returns all comments for a thread.
+// ListComments returns the visible comments of a thread.
 func (f *ForumEngine) ListComments(tid Hash) ([]Comment, error) {
 	prefix := []byte("forum:comment:" + hex.EncodeToString(tid[:]) + ":")
 	it := f.led.PrefixIterator(prefix)
@@ -133,11 +148,44 @@ func (f *ForumEngine) ListComments(tid Hash) ([]Comment, error) {
 		if err := json.Unmarshal(it.Value(), &c); err != nil {
 			return nil, err
 		}
+		if f.guard.IsHidden(ContentComment, hex.EncodeToString(c.ID[:])) {
+			continue
+		}
 		out = append(out, c)
 	}
 	return out, it.Error()
 }
 
+// FlagThread reports a thread. It returns true once the thread is hidden.
+func (f *ForumEngine) FlagThread(id Hash, flagger Address, reason string) (bool, error) {
+	t, err := f.GetThread(id)
+	if err != nil {
+		return false, err
+	}
+	return f.guard.Flag(ContentThread, hex.EncodeToString(id[:]), t.Creator, flagger, reason)
+}
+
+// FlagComment reports a comment. It returns true once the comment is hidden.
+func (f *ForumEngine) FlagComment(tid, cid Hash, flagger Address, reason string) (bool, error) {
+	data, err := f.led.GetState(f.keyComment(tid, cid))
+	if err != nil || data == nil {
+		return false, fmt.Errorf("comment %x not found", cid)
+	}
+	var c Comment
+	if err := json.Unmarshal(data, &c); err != nil {
+		return false, err
+	}
+	return f.guard.Flag(ContentComment, hex.EncodeToString(cid[:]), c.Author, flagger, reason)
+}
+
+// ReleaseThreadStake refunds the stake of a thread after its lock period.
+func (f *ForumEngine) ReleaseThreadStake(id Hash, owner Address) error {
+	return f.guard.ReleaseStake(ContentThread, hex.EncodeToString(id[:]), owner)
+}
+
+// Guard returns the content guard of the forum.
+func (f *ForumEngine) Guard() *ContentGuard { return f.guard }
+
 // ForumCreateThread is exposed as a VM opcode.
 func ForumCreateThread(author Address, title, body string) (Hash, error) {
 	if forum == nil {
@@ -177,3 +225,27 @@ func ForumListComments(tid Hash) ([]Comment, error) {
 	}
 	return forum.ListComments(tid)
 }
+
+// ForumFlagThread flags a thread via opcode.
+func ForumFlagThread(id Hash, flagger Address, reason string) (bool, error) {
+	if forum == nil {
+		return false, errors.New("forum not initialised")
+	}
+	return forum.FlagThread(id, flagger, reason)
+}
+
+// ForumFlagComment flags a comment via opcode.
+func ForumFlagComment(tid, cid Hash, flagger Address, reason string) (bool, error) {
+	if forum == nil {
+		return false, errors.New("forum not initialised")
+	}
+	return forum.FlagComment(tid, cid, flagger, reason)
+}
+
+// ForumReleaseStake refunds a thread stake via opcode.
+func ForumReleaseStake(id Hash, owner Address) error {
+	if forum == nil {
+		return errors.New("forum not initialised")
+	}
+	return forum.ReleaseThreadStake(id, owner)
+}
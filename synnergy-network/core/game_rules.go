@@ -0,0 +1,179 @@
+package core
+
+// game_rules.go – WASM rules contracts for turn-based games.
+//
+// A rules contract is a pure WASM module without imports. It exports
+//
+//	memory
+//	alloc(len i32) -> ptr i32
+//	apply(statePtr, stateLen, movePtr, moveLen, player i32) -> i64
+//
+// apply returns (ptr << 32 | len) of a result buffer laid out as
+//
+//	byte 0   status: 0 invalid move, 1 game continues, 2 game over
+//	byte 1   winner player index when over, 0xFF for a draw
+//	byte 2.. the new game state
+//
+// Rules contracts are stored by sha256 code hash. Because they run without
+// host imports the same state and move always produce the same result, which
+// lets disputes be settled by re-executing a single transition on chain.
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/wasmerio/wasmer-go/wasmer"
+)
+
+// Rules contract result codes.
+const (
+	RulesInvalid  byte = 0
+	RulesContinue byte = 1
+	RulesGameOver byte = 2
+
+	// RulesDraw is the winner index reported for a drawn game.
+	RulesDraw byte = 0xFF
+)
+
+// MaxRulesCodeSize bounds the size of a rules contract.
+const MaxRulesCodeSize = 256 << 10
+
+// RulesResult is the decoded outcome of one apply call.
+type RulesResult struct {
+	Status byte   `json:"status"`
+	Winner byte   `json:"winner"`
+	State  []byte `json:"state"`
+}
+
+func gameRulesKey(hash string) []byte { return []byte("gamerules:" + hash) }
+
+// RegisterGameRules validates and stores a rules contract and returns its
+// code hash.
+func RegisterGameRules(code []byte) (string, error) {
+	if gameLedger == nil {
+		return "", errors.New("gaming: ledger not initialised")
+	}
+	if len(code) == 0 || len(code) > MaxRulesCodeSize {
+		return "", fmt.Errorf("rules code must be 1..%d bytes", MaxRulesCodeSize)
+	}
+	if err := checkRulesModule(code); err != nil {
+		return "", err
+	}
+	h := sha256.Sum256(code)
+	hash := hex.EncodeToString(h[:])
+	if err := gameLedger.SetState(gameRulesKey(hash), code); err != nil {
+		return "", err
+	}
+	return hash, nil
+}
+
+func loadGameRules(hash string) ([]byte, error) {
+	code, err := gameLedger.GetState(gameRulesKey(hash))
+	if err != nil || len(code) == 0 {
+		return nil, fmt.Errorf("rules contract %s not found", hash)
+	}
+	return code, nil
+}
+
+// checkRulesModule rejects modules that import host functions or miss the
+// required exports.
+func checkRulesModule(code []byte) error {
+	store := wasmer.NewStore(wasmer.NewEngine())
+	mod, err := wasmer.NewModule(store, code)
+	if err != nil {
+		return err
+	}
+	if len(mod.Imports()) != 0 {
+		return errors.New("rules contract must not import host functions")
+	}
+	want := map[string]bool{"memory": false, "alloc": false, "apply": false}
+	for _, e := range mod.Exports() {
+		if _, ok := want[e.Name()]; ok {
+			want[e.Name()] = true
+		}
+	}
+	for name, ok := range want {
+		if !ok {
+			return fmt.Errorf("rules contract must export %s", name)
+		}
+	}
+	return nil
+}
+
+// ExecuteGameRules runs apply of the rules contract identified by hash.
+func ExecuteGameRules(hash string, state, move []byte, player int) (*RulesResult, error) {
+	code, err := loadGameRules(hash)
+	if err != nil {
+		return nil, err
+	}
+	return runRules(code, state, move, player)
+}
+
+func runRules(code, state, move []byte, player int) (*RulesResult, error) {
+	store := wasmer.NewStore(wasmer.NewEngine())
+	mod, err := wasmer.NewModule(store, code)
+	if err != nil {
+		return nil, err
+	}
+	inst, err := wasmer.NewInstance(mod, wasmer.NewImportObject())
+	if err != nil {
+		return nil, err
+	}
+	mem, err := inst.Exports.GetMemory("memory")
+	if err != nil {
+		return nil, err
+	}
+	alloc, err := inst.Exports.GetFunction("alloc")
+	if err != nil {
+		return nil, err
+	}
+	apply, err := inst.Exports.GetFunction("apply")
+	if err != nil {
+		return nil, err
+	}
+	put := func(b []byte) (int32, error) {
+		v, err := alloc(int32(len(b)))
+		if err != nil {
+			return 0, err
+		}
+		ptr, ok := v.(int32)
+		if !ok {
+			return 0, errors.New("alloc must return i32")
+		}
+		data := mem.Data()
+		if ptr < 0 || int(ptr)+len(b) > len(data) {
+			return 0, errors.New("alloc returned out-of-bounds pointer")
+		}
+		copy(data[ptr:], b)
+		return ptr, nil
+	}
+	sp, err := put(state)
+	if err != nil {
+		return nil, err
+	}
+	mp, err := put(move)
+	if err != nil {
+		return nil, err
+	}
+	v, err := apply(sp, int32(len(state)), mp, int32(len(move)), int32(player))
+	if err != nil {
+		return nil, fmt.Errorf("rules trap: %w", err)
+	}
+	packed, ok := v.(int64)
+	if !ok {
+		return nil, errors.New("apply must return i64")
+	}
+	ptr, n := uint32(uint64(packed)>>32), uint32(packed)
+	data := mem.Data()
+	if n < 2 || uint64(ptr)+uint64(n) > uint64(len(data)) {
+		return nil, errors.New("apply returned out-of-bounds result")
+	}
+	out := data[ptr : ptr+n]
+	res := &RulesResult{Status: out[0], Winner: out[1], State: append([]byte(nil), out[2:]...)}
+	if res.Status > RulesGameOver {
+		return nil, fmt.Errorf("unknown rules status %d", res.Status)
+	}
+	return res, nil
+}
@@ -0,0 +1,589 @@
+package core
+
+// game_turns.go – verifiable turn-based games.
+//
+// A turn game binds a WASM rules contract (see game_rules.go), a fixed number
+// of seats and a stake every player escrows into the gaming module account.
+// Players take turns in seat order. Each move is a signed state transition:
+// the mover runs the rules locally and submits the move, the resulting state
+// and the claimed outcome, signed over GameMoveMessage. The chain only checks
+// turn order, the state hash chain and the signature.
+//
+// Any other player may dispute a move within the game's dispute window. The
+// chain then re-executes the rules contract on the prior state; if the
+// result differs from what the mover claimed, the mover forfeits and the pot
+// is split among the remaining players. A move claiming game over starts the
+// dispute window, after which FinalizeTurnGame pays the winner, or refunds
+// everybody on a draw.
+//
+// Finished games update per-player standings used by GameLeaderboard.
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Turn game states.
+const (
+	TurnGameOpen      = "open"
+	TurnGameActive    = "active"
+	TurnGameEnding    = "ending"
+	TurnGameFinished  = "finished"
+	TurnGameForfeited = "forfeited"
+	TurnGameCancelled = "cancelled"
+)
+
+// DefaultGameDisputeWindow applies when a game is created without one.
+const DefaultGameDisputeWindow = 10 * time.Minute
+
+var (
+	// ErrGameMoveValid is returned when a disputed move re-executes to the
+	// claimed result.
+	ErrGameMoveValid = errors.New("disputed move is valid")
+	// ErrGameMoveSignature is returned for moves not signed by the mover.
+	ErrGameMoveSignature = errors.New("invalid move signature")
+)
+
+var turnGameMu sync.Mutex
+
+// TurnGame is a staked turn-based game governed by a rules contract.
+type TurnGame struct {
+	ID            string        `json:"id"`
+	Rules         string        `json:"rules"`
+	Creator       Address       `json:"creator"`
+	Seats         int           `json:"seats"`
+	Players       []Address     `json:"players"`
+	Stake         uint64        `json:"stake"`
+	InitialState  []byte        `json:"initial_state"`
+	State         []byte        `json:"state"`
+	StateHash     string        `json:"state_hash"`
+	Turn          uint64        `json:"turn"`
+	Status        string        `json:"status"`
+	Winner        byte          `json:"winner"`
+	DisputeWindow time.Duration `json:"dispute_window"`
+	EndsAt        time.Time     `json:"ends_at,omitempty"`
+	Created       time.Time     `json:"created"`
+}
+
+// GameMove is one signed state transition.
+type GameMove struct {
+	GameID    string    `json:"game_id"`
+	Turn      uint64    `json:"turn"`
+	Player    Address   `json:"player"`
+	Move      []byte    `json:"move"`
+	PrevHash  string    `json:"prev_hash"`
+	NewState  []byte    `json:"new_state"`
+	Status    byte      `json:"status"`
+	Winner    byte      `json:"winner"`
+	PubKey    []byte    `json:"pub_key"`
+	Sig       []byte    `json:"sig"`
+	Submitted time.Time `json:"submitted"`
+}
+
+// GameDispute records the outcome of a dispute.
+type GameDispute struct {
+	GameID     string       `json:"game_id"`
+	Turn       uint64       `json:"turn"`
+	Challenger Address      `json:"challenger"`
+	Cheater    Address      `json:"cheater"`
+	Expected   *RulesResult `json:"expected"`
+	Resolved   time.Time    `json:"resolved"`
+}
+
+// GameStanding aggregates a player's finished games.
+type GameStanding struct {
+	Player   Address `json:"player"`
+	Played   uint64  `json:"played"`
+	Wins     uint64  `json:"wins"`
+	Losses   uint64  `json:"losses"`
+	Draws    uint64  `json:"draws"`
+	Forfeits uint64  `json:"forfeits"`
+	Winnings uint64  `json:"winnings"`
+}
+
+type gameStatsReader interface {
+	GetState(key []byte) ([]byte, error)
+	PrefixIterator(prefix []byte) StateIterator
+}
+
+func gamingAccount() Address          { return ModuleAddress("gaming") }
+func turnGameKey(id string) []byte    { return []byte("turngame:" + id) }
+func gameMovePrefix(id string) []byte { return []byte("gamemove:" + id + ":") }
+func gameStatsKey(a Address) []byte   { return []byte("gamestats:" + a.Hex()) }
+func gameDisputeKey(id string) []byte { return []byte("gamedispute:" + id) }
+func gameMoveKey(id string, turn uint64) []byte {
+	return append(gameMovePrefix(id), fmt.Sprintf("%020d", turn)...)
+}
+
+func gameStateHash(state []byte) string {
+	h := sha256.Sum256(state)
+	return hex.EncodeToString(h[:])
+}
+
+// GameMoveMessage is the payload a player signs to submit a move.
+func GameMoveMessage(id string, turn uint64, prevHash string, newState, move []byte, status, winner byte) []byte {
+	mh := sha256.Sum256(move)
+	return []byte("synnergy-game-move:" + id + ":" + strconv.FormatUint(turn, 10) + ":" + prevHash + ":" +
+		gameStateHash(newState) + ":" + strconv.Itoa(int(status)) + ":" + strconv.Itoa(int(winner)) + ":" + hex.EncodeToString(mh[:]))
+}
+
+func loadTurnGame(id string) (*TurnGame, error) {
+	raw, err := gameLedger.GetState(turnGameKey(id))
+	if err != nil || len(raw) == 0 {
+		return nil, fmt.Errorf("game %s not found", id)
+	}
+	var g TurnGame
+	if err := json.Unmarshal(raw, &g); err != nil {
+		return nil, err
+	}
+	return &g, nil
+}
+
+func saveTurnGame(g *TurnGame) error {
+	return gameLedger.SetState(turnGameKey(g.ID), gJSON(g))
+}
+
+// CreateTurnGame opens a game for seats players using the given rules
+// contract. The creator takes seat 0 and escrows stake.
+func CreateTurnGame(creator Address, rules string, stake uint64, seats int, initial []byte, window time.Duration) (*TurnGame, error) {
+	if gameLedger == nil {
+		return nil, errors.New("gaming: ledger not initialised")
+	}
+	if seats < 2 || seats > 255 {
+		return nil, errors.New("seats must be between 2 and 255")
+	}
+	if _, err := loadGameRules(rules); err != nil {
+		return nil, err
+	}
+	if window <= 0 {
+		window = DefaultGameDisputeWindow
+	}
+	turnGameMu.Lock()
+	defer turnGameMu.Unlock()
+	if stake > 0 {
+		if err := gameLedger.Transfer(creator, gamingAccount(), stake); err != nil {
+			return nil, err
+		}
+	}
+	g := &TurnGame{
+		ID:            uuid.New().String(),
+		Rules:         rules,
+		Creator:       creator,
+		Seats:         seats,
+		Players:       []Address{creator},
+		Stake:         stake,
+		InitialState:  initial,
+		State:         initial,
+		StateHash:     gameStateHash(initial),
+		Status:        TurnGameOpen,
+		DisputeWindow: window,
+		Created:       time.Now().UTC(),
+	}
+	if err := saveTurnGame(g); err != nil {
+		return nil, err
+	}
+	Broadcast("game_create", gJSON(g))
+	return g, nil
+}
+
+// JoinTurnGame escrows the stake and seats player. The game starts when all
+// seats are taken.
+func JoinTurnGame(id string, player Address) (*TurnGame, error) {
+	if gameLedger == nil {
+		return nil, errors.New("gaming: ledger not initialised")
+	}
+	turnGameMu.Lock()
+	defer turnGameMu.Unlock()
+	g, err := loadTurnGame(id)
+	if err != nil {
+		return nil, err
+	}
+	if g.Status != TurnGameOpen {
+		return nil, errors.New("game is not open")
+	}
+	for _, p := range g.Players {
+		if p == player {
+			return nil, errors.New("already seated")
+		}
+	}
+	if g.Stake > 0 {
+		if err := gameLedger.Transfer(player, gamingAccount(), g.Stake); err != nil {
+			return nil, err
+		}
+	}
+	g.Players = append(g.Players, player)
+	if len(g.Players) == g.Seats {
+		g.Status = TurnGameActive
+	}
+	if err := saveTurnGame(g); err != nil {
+		return nil, err
+	}
+	Broadcast("game_join", gJSON(g))
+	return g, nil
+}
+
+// CancelTurnGame refunds every seated player of a game that has not started.
+// Only the creator may cancel.
+func CancelTurnGame(id string, creator Address) error {
+	if gameLedger == nil {
+		return errors.New("gaming: ledger not initialised")
+	}
+	turnGameMu.Lock()
+	defer turnGameMu.Unlock()
+	g, err := loadTurnGame(id)
+	if err != nil {
+		return err
+	}
+	if g.Creator != creator {
+		return ErrUnauthorized
+	}
+	if g.Status != TurnGameOpen {
+		return errors.New("game already started")
+	}
+	for _, p := range g.Players {
+		if g.Stake > 0 {
+			if err := gameLedger.Transfer(gamingAccount(), p, g.Stake); err != nil {
+				return err
+			}
+		}
+	}
+	g.Status = TurnGameCancelled
+	return saveTurnGame(g)
+}
+
+// SubmitGameMove records a signed state transition for the player whose turn
+// it is.
+func SubmitGameMove(m GameMove) (*TurnGame, error) {
+	if gameLedger == nil {
+		return nil, errors.New("gaming: ledger not initialised")
+	}
+	if m.Status != RulesContinue && m.Status != RulesGameOver {
+		return nil, errors.New("move must continue or end the game")
+	}
+	if len(m.PubKey) != ed25519.PublicKeySize || Ed25519Address(m.PubKey) != m.Player ||
+		!ed25519.Verify(m.PubKey, GameMoveMessage(m.GameID, m.Turn, m.PrevHash, m.NewState, m.Move, m.Status, m.Winner), m.Sig) {
+		return nil, ErrGameMoveSignature
+	}
+	turnGameMu.Lock()
+	defer turnGameMu.Unlock()
+	g, err := loadTurnGame(m.GameID)
+	if err != nil {
+		return nil, err
+	}
+	if g.Status != TurnGameActive {
+		return nil, fmt.Errorf("game is %s", g.Status)
+	}
+	if m.Turn != g.Turn {
+		return nil, fmt.Errorf("expected turn %d", g.Turn)
+	}
+	if g.Players[g.Turn%uint64(len(g.Players))] != m.Player {
+		return nil, errors.New("not your turn")
+	}
+	if m.PrevHash != g.StateHash {
+		return nil, errors.New("move does not extend the current state")
+	}
+	if m.Status == RulesGameOver && m.Winner != RulesDraw && int(m.Winner) >= len(g.Players) {
+		return nil, errors.New("winner index out of range")
+	}
+	m.Submitted = time.Now().UTC()
+	if err := gameLedger.SetState(gameMoveKey(g.ID, m.Turn), gJSON(m)); err != nil {
+		return nil, err
+	}
+	g.State = m.NewState
+	g.StateHash = gameStateHash(m.NewState)
+	g.Turn++
+	if m.Status == RulesGameOver {
+		g.Status = TurnGameEnding
+		g.Winner = m.Winner
+		g.EndsAt = m.Submitted.Add(g.DisputeWindow)
+	}
+	if err := saveTurnGame(g); err != nil {
+		return nil, err
+	}
+	Broadcast("game_move", gJSON(m))
+	return g, nil
+}
+
+// PrepareGameMove runs the rules contract locally against the current state
+// of a game and returns the resulting move signed with priv, ready for
+// SubmitGameMove.
+func PrepareGameMove(id string, move []byte, priv ed25519.PrivateKey) (*GameMove, error) {
+	pub, ok := priv.Public().(ed25519.PublicKey)
+	if !ok {
+		return nil, errors.New("invalid private key")
+	}
+	g, err := GetTurnGame(id)
+	if err != nil {
+		return nil, err
+	}
+	player := Ed25519Address(pub)
+	seat := seatOf(g, player)
+	if seat < 0 {
+		return nil, errors.New("key is not seated in the game")
+	}
+	res, err := ExecuteGameRules(g.Rules, g.State, move, seat)
+	if err != nil {
+		return nil, err
+	}
+	if res.Status == RulesInvalid {
+		return nil, errors.New("move rejected by rules contract")
+	}
+	m := &GameMove{
+		GameID:   id,
+		Turn:     g.Turn,
+		Player:   player,
+		Move:     move,
+		PrevHash: g.StateHash,
+		NewState: res.State,
+		Status:   res.Status,
+		Winner:   res.Winner,
+		PubKey:   pub,
+	}
+	m.Sig = ed25519.Sign(priv, GameMoveMessage(id, m.Turn, m.PrevHash, m.NewState, m.Move, m.Status, m.Winner))
+	return m, nil
+}
+
+// GameMoves returns the moves of a game in turn order.
+func GameMoves(id string) ([]GameMove, error) {
+	if gameLedger == nil {
+		return nil, errors.New("gaming: ledger not initialised")
+	}
+	it := gameLedger.PrefixIterator(gameMovePrefix(id))
+	var out []GameMove
+	for it.Next() {
+		var m GameMove
+		if err := json.Unmarshal(it.Value(), &m); err != nil {
+			return nil, err
+		}
+		out = append(out, m)
+	}
+	return out, it.Error()
+}
+
+func loadGameMove(id string, turn uint64) (*GameMove, error) {
+	raw, err := gameLedger.GetState(gameMoveKey(id, turn))
+	if err != nil || len(raw) == 0 {
+		return nil, fmt.Errorf("move %d not found", turn)
+	}
+	var m GameMove
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// DisputeGameMove re-executes the rules contract for move turn. If the
+// result differs from the mover's claim the mover forfeits and the pot is
+// split among the other players; otherwise ErrGameMoveValid is returned.
+func DisputeGameMove(id string, turn uint64, challenger Address) (*GameDispute, error) {
+	if gameLedger == nil {
+		return nil, errors.New("gaming: ledger not initialised")
+	}
+	turnGameMu.Lock()
+	defer turnGameMu.Unlock()
+	g, err := loadTurnGame(id)
+	if err != nil {
+		return nil, err
+	}
+	if g.Status != TurnGameActive && g.Status != TurnGameEnding {
+		return nil, fmt.Errorf("game is %s", g.Status)
+	}
+	m, err := loadGameMove(id, turn)
+	if err != nil {
+		return nil, err
+	}
+	if challenger == m.Player || seatOf(g, challenger) < 0 {
+		return nil, errors.New("only another player may dispute a move")
+	}
+	if time.Now().After(m.Submitted.Add(g.DisputeWindow)) {
+		return nil, errors.New("dispute window closed")
+	}
+	prev := g.InitialState
+	if turn > 0 {
+		pm, err := loadGameMove(id, turn-1)
+		if err != nil {
+			return nil, err
+		}
+		prev = pm.NewState
+	}
+	res, err := ExecuteGameRules(g.Rules, prev, m.Move, seatOf(g, m.Player))
+	if err != nil {
+		return nil, err
+	}
+	if res.Status == m.Status && bytes.Equal(res.State, m.NewState) &&
+		(res.Status != RulesGameOver || res.Winner == m.Winner) {
+		return nil, ErrGameMoveValid
+	}
+	if err := forfeitGame(g, m.Player); err != nil {
+		return nil, err
+	}
+	d := &GameDispute{GameID: id, Turn: turn, Challenger: challenger, Cheater: m.Player, Expected: res, Resolved: time.Now().UTC()}
+	if err := gameLedger.SetState(gameDisputeKey(id), gJSON(d)); err != nil {
+		return nil, err
+	}
+	Broadcast("game_dispute", gJSON(d))
+	return d, nil
+}
+
+func seatOf(g *TurnGame, a Address) int {
+	for i, p := range g.Players {
+		if p == a {
+			return i
+		}
+	}
+	return -1
+}
+
+// forfeitGame splits the pot among everyone except cheater.
+func forfeitGame(g *TurnGame, cheater Address) error {
+	pot := g.Stake * uint64(len(g.Players))
+	honest := len(g.Players) - 1
+	share := pot / uint64(honest)
+	rem := pot % uint64(honest)
+	for _, p := range g.Players {
+		if p == cheater {
+			if err := updateGameStanding(p, func(s *GameStanding) { s.Losses++; s.Forfeits++ }); err != nil {
+				return err
+			}
+			continue
+		}
+		amt := share
+		if rem > 0 {
+			amt++
+			rem--
+		}
+		if amt > 0 {
+			if err := gameLedger.Transfer(gamingAccount(), p, amt); err != nil {
+				return err
+			}
+		}
+		if err := updateGameStanding(p, func(s *GameStanding) { s.Wins++; s.Winnings += amt }); err != nil {
+			return err
+		}
+	}
+	g.Status = TurnGameForfeited
+	return saveTurnGame(g)
+}
+
+// FinalizeTurnGame settles a finished game once its dispute window has
+// passed: the winner takes the pot, or every player is refunded on a draw.
+func FinalizeTurnGame(id string) (*TurnGame, error) {
+	if gameLedger == nil {
+		return nil, errors.New("gaming: ledger not initialised")
+	}
+	turnGameMu.Lock()
+	defer turnGameMu.Unlock()
+	g, err := loadTurnGame(id)
+	if err != nil {
+		return nil, err
+	}
+	if g.Status != TurnGameEnding {
+		return nil, fmt.Errorf("game is %s", g.Status)
+	}
+	if time.Now().Before(g.EndsAt) {
+		return nil, fmt.Errorf("dispute window open until %s", g.EndsAt.Format(time.RFC3339))
+	}
+	pot := g.Stake * uint64(len(g.Players))
+	for i, p := range g.Players {
+		var err error
+		switch {
+		case g.Winner == RulesDraw:
+			if g.Stake > 0 {
+				if err = gameLedger.Transfer(gamingAccount(), p, g.Stake); err != nil {
+					return nil, err
+				}
+			}
+			err = updateGameStanding(p, func(s *GameStanding) { s.Draws++ })
+		case i == int(g.Winner):
+			if pot > 0 {
+				if err = gameLedger.Transfer(gamingAccount(), p, pot); err != nil {
+					return nil, err
+				}
+			}
+			err = updateGameStanding(p, func(s *GameStanding) { s.Wins++; s.Winnings += pot })
+		default:
+			err = updateGameStanding(p, func(s *GameStanding) { s.Losses++ })
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	g.Status = TurnGameFinished
+	if err := saveTurnGame(g); err != nil {
+		return nil, err
+	}
+	Broadcast("game_finish", gJSON(g))
+	return g, nil
+}
+
+// GetTurnGame returns a turn game by ID.
+func GetTurnGame(id string) (*TurnGame, error) {
+	if gameLedger == nil {
+		return nil, errors.New("gaming: ledger not initialised")
+	}
+	return loadTurnGame(id)
+}
+
+func updateGameStanding(a Address, fn func(*GameStanding)) error {
+	s, err := PlayerStanding(gameLedger, a)
+	if err != nil {
+		return err
+	}
+	s.Played++
+	fn(s)
+	return gameLedger.SetState(gameStatsKey(a), gJSON(s))
+}
+
+// PlayerStanding returns the standing of one player.
+func PlayerStanding(led gameStatsReader, a Address) (*GameStanding, error) {
+	s := &GameStanding{Player: a}
+	raw, err := led.GetState(gameStatsKey(a))
+	if err != nil || len(raw) == 0 {
+		return s, nil
+	}
+	if err := json.Unmarshal(raw, s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// GameLeaderboard ranks players by wins, then winnings. A positive limit
+// truncates the result.
+func GameLeaderboard(led gameStatsReader, limit int) ([]GameStanding, error) {
+	it := led.PrefixIterator([]byte("gamestats:"))
+	var out []GameStanding
+	for it.Next() {
+		var s GameStanding
+		if err := json.Unmarshal(it.Value(), &s); err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	if err := it.Error(); err != nil {
+		return nil, err
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Wins != out[j].Wins {
+			return out[i].Wins > out[j].Wins
+		}
+		if out[i].Winnings != out[j].Winnings {
+			return out[i].Winnings > out[j].Winnings
+		}
+		return out[i].Player.Hex() < out[j].Player.Hex()
+	})
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
@@ -11,7 +11,8 @@ import (
 )
 
 // Game represents a simple on-chain gaming session. All funds are escrowed
-// into AddressZero until FinishGame releases them to the winner.
+// in the game escrow module account until FinishGame releases them to the
+// winner.
 // The module is intentionally lightweight and can be extended by smart
 // contracts for more advanced logic.
 
@@ -31,6 +32,8 @@ var (
 	gameStore  = make(map[string]*Game)
 )
 
+func gameEscrowAccount() Address { return ModuleAddress("game_escrow") }
+
 // InitGaming attaches a ledger implementation used for escrow transfers and
 // state persistence. It must be called before any game functions are used.
 func InitGaming(led StateRW) {
@@ -40,7 +43,7 @@ func InitGaming(led StateRW) {
 }
 
 // CreateGame initialises a new game with the given stake and creator.
-// The stake amount is transferred to AddressZero for escrow.
+// The stake amount is transferred to the game escrow account.
 func CreateGame(creator Address, stake uint64) (Game, error) {
 	if gameLedger == nil {
 		return Game{}, errors.New("gaming: ledger not initialised")
@@ -49,7 +52,7 @@ func CreateGame(creator Address, stake uint64) (Game, error) {
 	g := &Game{ID: id, Creator: creator, Stake: stake, Created: time.Now().UTC()}
 
 	if stake > 0 {
-		if err := gameLedger.Transfer(creator, AddressZero, stake); err != nil {
+		if err := gameLedger.Transfer(creator, gameEscrowAccount(), stake); err != nil {
 			return Game{}, err
 		}
 	}
@@ -90,7 +93,7 @@ func JoinGame(id string, player Address) error {
 		}
 	}
 	if g.Stake > 0 {
-		if err := gameLedger.Transfer(player, AddressZero, g.Stake); err != nil {
+		if err := gameLedger.Transfer(player, gameEscrowAccount(), g.Stake); err != nil {
 			gameMu.Unlock()
 			return err
 		}
@@ -129,7 +132,7 @@ func FinishGame(id string, winner Address) (Game, error) {
 	gameMu.Unlock()
 
 	if total > 0 {
-		if err := gameLedger.Transfer(AddressZero, winner, total); err != nil {
+		if err := gameLedger.Transfer(gameEscrowAccount(), winner, total); err != nil {
 			return Game{}, err
 		}
 	}
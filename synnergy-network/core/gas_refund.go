@@ -0,0 +1,165 @@
+package core
+
+// gas_refund.go – EVM-style storage pricing and refund accounting.
+//
+// On top of the static per-opcode cost, storage reads and writes are priced
+// by a warm/cold access model tracked per transaction (EIP-2929) and writes
+// follow net gas metering against the slot's value at the start of the
+// transaction (EIP-2200). Clearing a slot and self-destructing a contract
+// accrue refunds; at the end of execution the refund is capped at
+// 1/MaxRefundQuotient of the gas used (EIP-3529) and subtracted.
+
+import "fmt"
+
+const (
+	ColdSloadCost       uint64 = 2100  // first access to a slot in a tx
+	WarmStorageReadCost uint64 = 100   // subsequent accesses
+	SstoreSetGas        uint64 = 20000 // zero → non-zero
+	SstoreResetGas      uint64 = 2900  // non-zero → other value (cold part excluded)
+	SstoreClearsRefund  uint64 = 4800  // non-zero → zero
+	SelfDestructRefund  uint64 = 24000 // once per contract per tx
+	MaxRefundQuotient   uint64 = 5     // refund ≤ used / MaxRefundQuotient
+)
+
+// accessSet is the per-transaction warm slot set together with the value
+// each slot held when the transaction first touched it.
+type accessSet struct {
+	original  map[string][]byte
+	destroyed map[Address]bool
+}
+
+func newAccessSet() *accessSet {
+	return &accessSet{original: make(map[string][]byte), destroyed: make(map[Address]bool)}
+}
+
+func slotKey(ns, key []byte) string {
+	return fmt.Sprintf("%x/%x", ns, key)
+}
+
+// BeginTx resets the access set and refund counter for a new transaction.
+func (g *GasMeter) BeginTx() {
+	g.access = newAccessSet()
+	g.refund = 0
+}
+
+// Charge consumes a dynamic amount of gas.
+func (g *GasMeter) Charge(amount uint64) error {
+	if amount > g.limit-g.used {
+		return fmt.Errorf("out-of-gas (%d/%d)", g.used+amount, g.limit)
+	}
+	g.used += amount
+	return nil
+}
+
+// touch marks a slot warm, remembering cur as its original value, and
+// returns the access cost and the original value.
+func (g *GasMeter) touch(ns, key, cur []byte) (uint64, []byte) {
+	if g.access == nil {
+		g.access = newAccessSet()
+	}
+	k := slotKey(ns, key)
+	if orig, ok := g.access.original[k]; ok {
+		return 0, orig
+	}
+	orig := append([]byte(nil), cur...)
+	g.access.original[k] = orig
+	return ColdSloadCost, orig
+}
+
+// ChargeSLoad prices a storage read of a slot holding cur.
+func (g *GasMeter) ChargeSLoad(ns, key, cur []byte) error {
+	cold, _ := g.touch(ns, key, cur)
+	if cold > 0 {
+		return g.Charge(cold)
+	}
+	return g.Charge(WarmStorageReadCost)
+}
+
+// ChargeSStore prices a write of val to a slot currently holding cur and
+// adjusts the refund counter.
+func (g *GasMeter) ChargeSStore(ns, key, cur, val []byte) error {
+	cold, orig := g.touch(ns, key, cur)
+	cost := cold
+	switch {
+	case sameSlot(cur, val):
+		cost += WarmStorageReadCost
+	case sameSlot(orig, cur): // clean slot
+		if isZeroSlot(orig) {
+			cost += SstoreSetGas
+		} else {
+			cost += SstoreResetGas
+			if isZeroSlot(val) {
+				g.refund += SstoreClearsRefund
+			}
+		}
+	default: // dirty slot
+		cost += WarmStorageReadCost
+		if !isZeroSlot(orig) {
+			if isZeroSlot(cur) {
+				g.subRefund(SstoreClearsRefund)
+			} else if isZeroSlot(val) {
+				g.refund += SstoreClearsRefund
+			}
+		}
+		if sameSlot(orig, val) {
+			if isZeroSlot(orig) {
+				g.refund += SstoreSetGas - WarmStorageReadCost
+			} else {
+				g.refund += SstoreResetGas - WarmStorageReadCost
+			}
+		}
+	}
+	return g.Charge(cost)
+}
+
+// RefundSelfDestruct accrues the self-destruct refund once per contract.
+func (g *GasMeter) RefundSelfDestruct(contract Address) {
+	if g.access == nil {
+		g.access = newAccessSet()
+	}
+	if g.access.destroyed[contract] {
+		return
+	}
+	g.access.destroyed[contract] = true
+	g.refund += SelfDestructRefund
+}
+
+func (g *GasMeter) subRefund(n uint64) {
+	if n > g.refund {
+		g.refund = 0
+		return
+	}
+	g.refund -= n
+}
+
+// Refund returns the refund accrued so far, before capping.
+func (g *GasMeter) Refund() uint64 { return g.refund }
+
+// Settle applies the capped refund to the gas used and returns the net gas
+// used and the refund granted. It is called once when execution ends.
+func (g *GasMeter) Settle() (used, refunded uint64) {
+	refunded = g.refund
+	if max := g.used / MaxRefundQuotient; refunded > max {
+		refunded = max
+	}
+	g.used -= refunded
+	g.refund = 0
+	return g.used, refunded
+}
+
+// sameSlot compares slot values, treating missing and all-zero as equal.
+func sameSlot(a, b []byte) bool {
+	if isZeroSlot(a) || isZeroSlot(b) {
+		return isZeroSlot(a) && isZeroSlot(b)
+	}
+	return string(a) == string(b)
+}
+
+func isZeroSlot(v []byte) bool {
+	for _, b := range v {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
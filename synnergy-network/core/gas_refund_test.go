@@ -0,0 +1,112 @@
+package core
+
+import "testing"
+
+// TestSStoreMatchesEIP3529Vectors replays the SSTORE test cases of
+// EIP-3529. Each case writes a warm slot of the given original value two or
+// three times; the reference gas counts 3 per PUSH, which the meter does
+// not see, so it is taken off.
+func TestSStoreMatchesEIP3529Vectors(t *testing.T) {
+	for _, tc := range []struct {
+		code         string
+		used, refund uint64
+		original     byte
+		stores       []byte
+	}{
+		{code: "0x60006000556000600055", used: 212, original: 0, stores: []byte{0, 0}},
+		{code: "0x60006000556001600055", used: 20112, original: 0, stores: []byte{0, 1}},
+		{code: "0x60016000556000600055", used: 20112, refund: 19900, original: 0, stores: []byte{1, 0}},
+		{code: "0x60016000556002600055", used: 20112, original: 0, stores: []byte{1, 2}},
+		{code: "0x60016000556001600055", used: 20112, original: 0, stores: []byte{1, 1}},
+		{code: "0x60006000556000600055", used: 3012, refund: 4800, original: 1, stores: []byte{0, 0}},
+		{code: "0x60006000556001600055", used: 3012, refund: 2800, original: 1, stores: []byte{0, 1}},
+		{code: "0x60006000556002600055", used: 3012, original: 1, stores: []byte{0, 2}},
+		{code: "0x60026000556000600055", used: 3012, refund: 4800, original: 1, stores: []byte{2, 0}},
+		{code: "0x60026000556003600055", used: 3012, original: 1, stores: []byte{2, 3}},
+		{code: "0x60026000556001600055", used: 3012, refund: 2800, original: 1, stores: []byte{2, 1}},
+		{code: "0x60026000556002600055", used: 3012, original: 1, stores: []byte{2, 2}},
+		{code: "0x60016000556000600055", used: 3012, refund: 4800, original: 1, stores: []byte{1, 0}},
+		{code: "0x60016000556002600055", used: 3012, original: 1, stores: []byte{1, 2}},
+		{code: "0x60016000556001600055", used: 212, original: 1, stores: []byte{1, 1}},
+		{code: "0x600160005560006000556001600055", used: 40118, refund: 19900, original: 0, stores: []byte{1, 0, 1}},
+		{code: "0x600060005560016000556000600055", used: 5918, refund: 7600, original: 1, stores: []byte{0, 1, 0}},
+	} {
+		g := NewGasMeter(1_000_000)
+		g.BeginTx()
+		ns, key := []byte("c"), []byte{0}
+		cur := []byte{tc.original}
+		g.touch(ns, key, cur) // the vectors run against a warm slot
+		for _, v := range tc.stores {
+			val := []byte{v}
+			if err := g.ChargeSStore(ns, key, cur, val); err != nil {
+				t.Fatalf("%s: %v", tc.code, err)
+			}
+			cur = val
+		}
+		pushes := uint64(2 * len(tc.stores))
+		if want := tc.used - 3*pushes; g.used != want || g.Refund() != tc.refund {
+			t.Errorf("%s: gas %d refund %d, want %d %d", tc.code, g.used, g.Refund(), want, tc.refund)
+		}
+	}
+}
+
+func TestColdAccessAndRefundCap(t *testing.T) {
+	g := NewGasMeter(1_000_000)
+	g.BeginTx()
+	ns := []byte("c")
+	if err := g.ChargeSLoad(ns, []byte{1}, nil); err != nil || g.used != ColdSloadCost {
+		t.Fatalf("cold SLOAD: %d %v", g.used, err)
+	}
+	if err := g.ChargeSLoad(ns, []byte{1}, nil); err != nil || g.used != ColdSloadCost+WarmStorageReadCost {
+		t.Fatalf("warm SLOAD: %d %v", g.used, err)
+	}
+	g.BeginTx()
+	g.used = 0
+	if err := g.ChargeSStore(ns, []byte{2}, nil, []byte{1}); err != nil || g.used != ColdSloadCost+SstoreSetGas {
+		t.Fatalf("cold SSTORE 0→1: %d %v", g.used, err)
+	}
+
+	// 0→1→0 refunds 19 900, capped at a fifth of the gas used
+	if err := g.ChargeSStore(ns, []byte{2}, []byte{1}, nil); err != nil || g.Refund() != 19_900 {
+		t.Fatalf("SSTORE 1→0: refund %d %v", g.Refund(), err)
+	}
+	used := g.used
+	net, refunded := g.Settle()
+	if refunded != used/MaxRefundQuotient || net != used-refunded || g.Refund() != 0 {
+		t.Fatalf("settle: net %d refunded %d of %d used", net, refunded, used)
+	}
+
+	// below the cap the whole refund is granted
+	g = NewGasMeter(1_000_000)
+	g.BeginTx()
+	if err := g.Charge(100_000); err != nil {
+		t.Fatal(err)
+	}
+	g.touch(ns, []byte{3}, []byte{1})
+	if err := g.ChargeSStore(ns, []byte{3}, []byte{1}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if net, refunded := g.Settle(); refunded != SstoreClearsRefund || net != 100_000+SstoreResetGas-SstoreClearsRefund {
+		t.Fatalf("settle below the cap: net %d refunded %d", net, refunded)
+	}
+
+	g = NewGasMeter(ColdSloadCost + SstoreSetGas - 1)
+	if err := g.ChargeSStore(ns, []byte{4}, nil, []byte{1}); err == nil {
+		t.Fatal("store beyond the limit accepted")
+	}
+}
+
+func TestSelfDestructRefundsOncePerContract(t *testing.T) {
+	g := NewGasMeter(1_000_000)
+	g.BeginTx()
+	g.RefundSelfDestruct(Address{1})
+	g.RefundSelfDestruct(Address{1})
+	g.RefundSelfDestruct(Address{2})
+	if g.Refund() != 2*SelfDestructRefund {
+		t.Fatalf("refund %d", g.Refund())
+	}
+	g.BeginTx()
+	if g.Refund() != 0 {
+		t.Fatalf("refund carried into the next tx: %d", g.Refund())
+	}
+}
@@ -38,6 +38,18 @@ var categoryGas = map[byte]uint64{
 	// Remaining categories fall back to DefaultGasCost.
 }
 
+// evmGas prices the EVM-equivalent handlers in utility_functions.go at their
+// Ethereum base cost so contracts ported from Solidity keep their gas
+// profile. evm_diff_test.go checks these against go-ethereum.
+var evmGas = map[string]uint64{
+	"opADD": 3, "opMUL": 5, "opSUB": 3, "OpDIV": 5, "opSDIV": 5,
+	"opMOD": 5, "opSMOD": 5, "opADDMOD": 8, "opMULMOD": 8, "opEXP": 10,
+	"opSIGNEXTEND": 5, "opLT": 3, "opGT": 3, "opSLT": 3, "opSGT": 3,
+	"opEQ": 3, "opISZERO": 3, "opAND": 3, "opOR": 3, "opXOR": 3,
+	"opNOT": 3, "opBYTE": 3, "opSHL": 3, "opSHR": 3, "opSAR": 3,
+	"opKECCAK256": 30,
+}
+
 // initGasTable builds the runtime gas table using the (deduplicated) opcode
 // catalogue assembled in opcode_dispatcher.go.
 func initGasTable() {
@@ -48,6 +60,9 @@ func initGasTable() {
 		if !ok {
 			cost = DefaultGasCost
 		}
+		if c, ok := evmGas[entry.name]; ok {
+			cost = c
+		}
 		gasTable[entry.op] = cost
 	}
 }
@@ -0,0 +1,289 @@
+package core
+
+// gdpr_erasure.go – right-to-erasure workflow for personal data.
+//
+// Personal data never lives in ledger state. Payloads are pinned through the
+// IPFS storage service and only their hash and CID are recorded on-chain.
+// An erasure request must be signed by the data subject, or by a regulator,
+// with an ed25519 key matching the signer's address. Executing the request
+// unpins and evicts every blob, replaces each record with a tombstone and
+// issues an erasure certificate that auditors can fetch by request ID.
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrErasureSignature = errors.New("erasure request signature invalid")
+	ErrErasureDone      = errors.New("erasure request already executed")
+)
+
+// PersonalDataRecord references an off-chain personal data blob.
+type PersonalDataRecord struct {
+	Subject    Address   `json:"subject"`
+	Controller Address   `json:"controller"`
+	Category   string    `json:"category"`
+	Hash       [32]byte  `json:"hash"`
+	CID        string    `json:"cid"`
+	Stored     time.Time `json:"stored"`
+}
+
+// ErasureStatus tracks an erasure request through the workflow.
+type ErasureStatus string
+
+const (
+	ErasurePending   ErasureStatus = "pending"
+	ErasureCompleted ErasureStatus = "completed"
+)
+
+// ErasureRequest is a validated request to erase a subject's data.
+type ErasureRequest struct {
+	ID        string        `json:"id"`
+	Subject   Address       `json:"subject"`
+	Requester Address       `json:"requester"`
+	Reason    string        `json:"reason"`
+	Status    ErasureStatus `json:"status"`
+	Created   time.Time     `json:"created"`
+	Completed time.Time     `json:"completed,omitempty"`
+}
+
+// ErasureTombstone replaces a personal data record once it is erased.
+type ErasureTombstone struct {
+	Hash      [32]byte  `json:"hash"`
+	Subject   Address   `json:"subject"`
+	RequestID string    `json:"request_id"`
+	ErasedAt  time.Time `json:"erased_at"`
+}
+
+// ErasureCertificate attests that every record of a subject was erased.
+// Digest commits to all other fields so auditors can detect tampering.
+type ErasureCertificate struct {
+	RequestID  string     `json:"request_id"`
+	Subject    Address    `json:"subject"`
+	Requester  Address    `json:"requester"`
+	Tombstones [][32]byte `json:"tombstones"`
+	Unpinned   []string   `json:"unpinned"`
+	Failed     []string   `json:"failed,omitempty"` // CIDs the gateway could not unpin
+	KYCErased  bool       `json:"kyc_erased"`
+	IssuedAt   time.Time  `json:"issued_at"`
+	Digest     [32]byte   `json:"digest"`
+}
+
+func personalDataPrefix(subject Address) string {
+	return "gdpr:data:" + hex.EncodeToString(subject[:]) + ":"
+}
+
+func personalDataKey(subject Address, h [32]byte) []byte {
+	return []byte(personalDataPrefix(subject) + hex.EncodeToString(h[:]))
+}
+
+func tombstoneKey(h [32]byte) []byte  { return []byte("gdpr:tombstone:" + hex.EncodeToString(h[:])) }
+func erasureReqKey(id string) []byte  { return []byte("gdpr:request:" + id) }
+func erasureCertKey(id string) []byte { return []byte("gdpr:cert:" + id) }
+
+// ErasureMessage is the payload a requester signs to ask for erasure.
+func ErasureMessage(subject Address, timestamp int64) []byte {
+	return []byte("synnergy-erase:" + hex.EncodeToString(subject[:]) + ":" + strconv.FormatInt(timestamp, 10))
+}
+
+// StorePersonalData pins payload off-chain and records its hash and CID.
+func StorePersonalData(ctx context.Context, subject, controller Address, category string, payload []byte) (*PersonalDataRecord, error) {
+	if len(payload) == 0 {
+		return nil, errors.New("empty payload")
+	}
+	h := sha256.Sum256(payload)
+	if raw, _ := CurrentStore().Get(tombstoneKey(h)); len(raw) > 0 {
+		return nil, errors.New("payload was erased and may not be stored again")
+	}
+	cid, err := AddFile(ctx, payload, controller)
+	if err != nil {
+		return nil, fmt.Errorf("pin: %w", err)
+	}
+	rec := &PersonalDataRecord{
+		Subject:    subject,
+		Controller: controller,
+		Category:   category,
+		Hash:       h,
+		CID:        cid,
+		Stored:     time.Now().UTC(),
+	}
+	raw, _ := json.Marshal(rec)
+	if err := CurrentStore().Set(personalDataKey(subject, h), raw); err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
+
+// ListPersonalData returns the live personal data records of a subject.
+func ListPersonalData(subject Address) ([]PersonalDataRecord, error) {
+	it := CurrentStore().Iterator([]byte(personalDataPrefix(subject)), nil)
+	defer it.Close()
+	var out []PersonalDataRecord
+	for it.Next() {
+		var r PersonalDataRecord
+		if err := json.Unmarshal(it.Value(), &r); err != nil {
+			return nil, err
+		}
+		out = append(out, r)
+	}
+	return out, it.Error()
+}
+
+// RequestErasure validates the signed request and queues it. The signer is
+// derived from pub and must be the subject or hold the regulator role. The
+// signed timestamp must be within five minutes of now.
+func RequestErasure(subject Address, reason string, timestamp int64, pub ed25519.PublicKey, sig []byte) (*ErasureRequest, error) {
+	if len(pub) != ed25519.PublicKeySize || !ed25519.Verify(pub, ErasureMessage(subject, timestamp), sig) {
+		return nil, ErrErasureSignature
+	}
+	if d := time.Since(time.Unix(timestamp, 0)); d > 5*time.Minute || d < -5*time.Minute {
+		return nil, fmt.Errorf("%w: stale timestamp", ErrErasureSignature)
+	}
+	signer := Ed25519Address(pub)
+	if signer != subject && !IsRegulator(signer) {
+		return nil, ErrUnauthorized
+	}
+	req := &ErasureRequest{
+		ID:        uuid.New().String(),
+		Subject:   subject,
+		Requester: signer,
+		Reason:    reason,
+		Status:    ErasurePending,
+		Created:   time.Now().UTC(),
+	}
+	if err := putErasureRequest(req); err != nil {
+		return nil, err
+	}
+	gdprAudit(subject, "gdpr_erasure_requested", map[string]string{"request": req.ID, "requester": signer.Hex()})
+	return req, nil
+}
+
+// ExecuteErasure unpins every blob of the subject, writes tombstones and
+// issues the erasure certificate. Records whose blob could not be unpinned
+// are still tombstoned and the CID is listed under Failed for follow-up.
+func ExecuteErasure(ctx context.Context, id string) (*ErasureCertificate, error) {
+	req, err := GetErasureRequest(id)
+	if err != nil {
+		return nil, err
+	}
+	if req.Status != ErasurePending {
+		return nil, ErrErasureDone
+	}
+	recs, err := ListPersonalData(req.Subject)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	cert := &ErasureCertificate{RequestID: id, Subject: req.Subject, Requester: req.Requester, IssuedAt: now}
+	for _, r := range recs {
+		if err := UnpinFile(ctx, r.CID); err != nil {
+			cert.Failed = append(cert.Failed, r.CID)
+		} else {
+			cert.Unpinned = append(cert.Unpinned, r.CID)
+		}
+		ts := ErasureTombstone{Hash: r.Hash, Subject: r.Subject, RequestID: id, ErasedAt: now}
+		raw, _ := json.Marshal(ts)
+		if err := CurrentStore().Set(tombstoneKey(r.Hash), raw); err != nil {
+			return nil, err
+		}
+		if err := CurrentStore().Delete(personalDataKey(r.Subject, r.Hash)); err != nil {
+			return nil, err
+		}
+		cert.Tombstones = append(cert.Tombstones, r.Hash)
+	}
+	if c := Compliance(); c != nil {
+		cert.KYCErased = c.EraseData(req.Subject) == nil
+	}
+	cert.Digest = cert.digest()
+
+	raw, _ := json.Marshal(cert)
+	if err := CurrentStore().Set(erasureCertKey(id), raw); err != nil {
+		return nil, err
+	}
+	req.Status = ErasureCompleted
+	req.Completed = now
+	if err := putErasureRequest(req); err != nil {
+		return nil, err
+	}
+	gdprAudit(req.Subject, "gdpr_erasure_completed", map[string]string{
+		"request":    id,
+		"tombstones": strconv.Itoa(len(cert.Tombstones)),
+		"failed":     strings.Join(cert.Failed, ","),
+		"digest":     hex.EncodeToString(cert.Digest[:]),
+	})
+	Broadcast("gdpr:erased", raw)
+	return cert, nil
+}
+
+// GetErasureRequest fetches an erasure request by ID.
+func GetErasureRequest(id string) (*ErasureRequest, error) {
+	raw, err := CurrentStore().Get(erasureReqKey(id))
+	if err != nil || raw == nil {
+		return nil, ErrNotFound
+	}
+	var req ErasureRequest
+	if err := json.Unmarshal(raw, &req); err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+// GetErasureCertificate returns the certificate issued for a request.
+func GetErasureCertificate(id string) (*ErasureCertificate, error) {
+	raw, err := CurrentStore().Get(erasureCertKey(id))
+	if err != nil || raw == nil {
+		return nil, ErrNotFound
+	}
+	var cert ErasureCertificate
+	if err := json.Unmarshal(raw, &cert); err != nil {
+		return nil, err
+	}
+	return &cert, nil
+}
+
+// VerifyErasureCertificate recomputes the certificate digest.
+func VerifyErasureCertificate(cert *ErasureCertificate) bool {
+	return cert != nil && cert.digest() == cert.Digest
+}
+
+// GetTombstone returns the tombstone for an erased payload hash.
+func GetTombstone(h [32]byte) (*ErasureTombstone, error) {
+	raw, err := CurrentStore().Get(tombstoneKey(h))
+	if err != nil || raw == nil {
+		return nil, ErrNotFound
+	}
+	var ts ErasureTombstone
+	if err := json.Unmarshal(raw, &ts); err != nil {
+		return nil, err
+	}
+	return &ts, nil
+}
+
+func (c *ErasureCertificate) digest() [32]byte {
+	cp := *c
+	cp.Digest = [32]byte{}
+	raw, _ := json.Marshal(cp)
+	return sha256.Sum256(raw)
+}
+
+func putErasureRequest(req *ErasureRequest) error {
+	raw, _ := json.Marshal(req)
+	return CurrentStore().Set(erasureReqKey(req.ID), raw)
+}
+
+func gdprAudit(addr Address, event string, meta map[string]string) {
+	if am := AuditManagerInstance(); am != nil {
+		_ = am.Log(addr, event, meta)
+	}
+}
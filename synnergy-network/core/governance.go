@@ -37,6 +37,59 @@ func UpdateParam(key, value string) error {
 		}
 		blockGasLimit = v
 		return nil
+	case "ai_delist_model":
+		h, err := ParseModelHash(value)
+		if err != nil {
+			return err
+		}
+		_, err = DelistModel(h)
+		return err
+	case "kyc_issuer_add":
+		c := Compliance()
+		if c == nil {
+			return errors.New("compliance engine not initialised")
+		}
+		var iss KYCIssuer
+		if err := json.Unmarshal([]byte(value), &iss); err != nil {
+			return fmt.Errorf("invalid issuer: %w", err)
+		}
+		return c.RegisterIssuer(iss)
+	case "kyc_issuer_revoke":
+		c := Compliance()
+		if c == nil {
+			return errors.New("compliance engine not initialised")
+		}
+		pk, err := hex.DecodeString(value)
+		if err != nil {
+			return fmt.Errorf("invalid issuer pubkey: %w", err)
+		}
+		return c.RevokeIssuer(Address{}, pk, "governance")
+	case "eff_max_boost_bps", "eff_throttle_bps", "eff_throttle_strikes":
+		return updateEfficiencyParam(key, value)
+	case "amm_flash_fee_bps":
+		return setFlashFeeBps(value)
+	case "amm_fee_switch":
+		return setFeeSwitch(value)
+	case "synth_config":
+		return setSynthConfig(value)
+	case "prediction_resolve":
+		return resolvePredictionByGovernance(value)
+	case "farm_emission", "farm_add", "farm_alloc", "farm_lock_tiers":
+		return updateFarmParam(key, value)
+	case "max_tx_payload", "max_code_size", "max_logs_per_tx", "max_state_writes_per_tx":
+		return updateResourceLimit(key, value)
+	case "storage_policy":
+		return setStoragePolicy(value)
+	case "contract_pause":
+		return governancePause(value, true)
+	case "contract_resume":
+		return governancePause(value, false)
+	case "emission_schedule":
+		return setEmissionSchedule(value)
+	case "bridge_safety_config":
+		return setBridgeSafetyConfigByGovernance(value)
+	case "authority_deregister":
+		return deregisterByGovernance(value)
 	default:
 		return fmt.Errorf("unknown param: %s", key)
 	}
@@ -96,14 +149,9 @@ func quorumReached(p *GovProposal) bool {
 	return approves*2 > total
 }
 
+// ParseAddress accepts bech32 and legacy hex addresses; see DecodeAddress.
 func ParseAddress(s string) (Address, error) {
-	b, err := hex.DecodeString(s)
-	if err != nil || len(b) != 20 {
-		return AddressZero, fmt.Errorf("invalid address: %s", s)
-	}
-	var a Address
-	copy(a[:], b)
-	return a, nil
+	return DecodeAddress(s)
 }
 
 // ProposeChange submits a new governance proposal
@@ -248,6 +296,7 @@ func SubmitProposal(p *GovProposal) error {
 
 	// Broadcast proposal event
 	Broadcast("dao:proposal", raw)
+	publishEvent(TopicGovernance, currentHeight(), GovernanceEventData{ProposalID: p.ID, Action: "submitted", Actor: p.Creator})
 	logger.Infof("Proposal %s registered", p.ID)
 	return nil
 }
@@ -313,6 +362,7 @@ func CastVote(v *Vote) error {
 		return err
 	}
 
+	publishEvent(TopicGovernance, currentHeight(), GovernanceEventData{ProposalID: p.ID, Action: "vote", Actor: v.Voter, Approve: v.Approve})
 	logger.Infof("Vote recorded: %s approves=%v", v.Voter, v.Approve)
 	return nil
 }
@@ -343,14 +393,18 @@ func ExecuteProposal(id string) error {
 		return ErrNotReady
 	}
 
+	action := "executed"
 	if !quorumReached(&p) {
 		logger.Infof("Proposal %s failed quorum", id)
 		p.Executed = true
+		action = "rejected"
 	} else {
 		logger.Infof("Proposal %s passed, executing", id)
 		// Example treasury transfer: coin.Transfer from DAO treasury to creator
 		// Here, proposal execution logic would be extended per proposal type
-		// For now, emit event
+		if err := applyParams(p.Changes); err != nil {
+			logger.Warnf("Proposal %s changes not applied: %v", id, err)
+		}
 	}
 	p.Executed = true
 
@@ -361,6 +415,7 @@ func ExecuteProposal(id string) error {
 	}
 
 	Broadcast("dao:executed", updated)
+	publishEvent(TopicGovernance, currentHeight(), GovernanceEventData{ProposalID: id, Action: action})
 	return nil
 }
 
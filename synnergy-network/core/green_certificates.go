@@ -0,0 +1,260 @@
+package core
+
+// green_certificates.go – oracle-attested energy data and certificate export.
+//
+// Energy, carbon and offset figures are only accepted when signed by a meter
+// oracle: an oracle from the oracle registry (RegisterOracle) with a public
+// key, bound to one validator by a regulator. Each MeterReading is signed
+// over MeterReadingMessage and stored once; replays are rejected.
+//
+// Certify issues a GreenCertificate per validator with a score tier and an
+// expiry. Expired certificates no longer count for CertificateOf or
+// ShouldThrottle. ExportGreenCertificate wraps a certificate in signed JSON
+// for external ESG reporting; VerifyGreenCertificateExport checks it.
+//
+// Ledger layout:
+//   green:meter:<oracleID>   -> MeterOracle
+//   usage:<sha256(msg)>      -> UsageRecord
+//   offset:<sha256(msg)>     -> OffsetRecord
+//   cert:<addr bytes>        -> GreenCertificate
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// GreenCertValidity is how long an issued certificate remains valid.
+	GreenCertValidity = 30 * 24 * time.Hour
+	// GreenThrottleScore is the score below which validators are throttled.
+	GreenThrottleScore = -0.5
+	// GreenCertExportFormat identifies the signed export format.
+	GreenCertExportFormat = "synnergy-green-cert/v1"
+)
+
+var (
+	ErrMeterUnknown   = errors.New("meter oracle not registered")
+	ErrMeterSignature = errors.New("meter reading signature invalid")
+	ErrReadingReplay  = errors.New("meter reading already recorded")
+	ErrReadingStale   = errors.New("meter reading timestamp out of range")
+)
+
+// MeterOracle binds a registered oracle to the validator whose energy use it
+// measures.
+type MeterOracle struct {
+	OracleID   string    `json:"oracle_id"`
+	Validator  Address   `json:"validator"`
+	Regulator  Address   `json:"regulator"`
+	Registered time.Time `json:"registered"`
+}
+
+// MeterReading is a signed measurement for one period. Usage readings carry
+// EnergyKWh and CarbonKg, offset readings carry OffsetKg.
+type MeterReading struct {
+	OracleID    string  `json:"oracle_id"`
+	Validator   Address `json:"validator"`
+	EnergyKWh   float64 `json:"energy_kwh,omitempty"`
+	CarbonKg    float64 `json:"carbon_kg,omitempty"`
+	OffsetKg    float64 `json:"offset_kg,omitempty"`
+	PeriodStart int64   `json:"period_start"`
+	PeriodEnd   int64   `json:"period_end"`
+	Timestamp   int64   `json:"ts"`
+}
+
+// MeterReadingMessage is the payload a meter oracle signs.
+func MeterReadingMessage(r MeterReading) []byte {
+	b, _ := json.Marshal(r)
+	return append([]byte("synnergy-meter:"), b...)
+}
+
+// GreenCertificate is the certificate issued to a validator by Certify.
+type GreenCertificate struct {
+	ID        string      `json:"id"`
+	Validator Address     `json:"validator"`
+	Cert      Certificate `json:"cert"`
+	Score     float64     `json:"score"`
+	EnergyKWh float64     `json:"energy_kwh"`
+	CarbonKg  float64     `json:"carbon_kg"`
+	OffsetKg  float64     `json:"offset_kg"`
+	Readings  int         `json:"readings"`
+	Oracles   []string    `json:"oracles,omitempty"`
+	TS        int64       `json:"ts"`
+	Expires   int64       `json:"expires"`
+}
+
+// Expired reports whether the certificate is past its expiry at now.
+func (c *GreenCertificate) Expired(now time.Time) bool {
+	return c.Expires > 0 && now.Unix() >= c.Expires
+}
+
+// SignedGreenCertificate is the portable export of a certificate.
+type SignedGreenCertificate struct {
+	Format      string           `json:"format"`
+	Certificate GreenCertificate `json:"certificate"`
+	Signer      string           `json:"signer"`
+	Signature   string           `json:"signature"`
+}
+
+func meterKey(oracleID string) []byte { return []byte("green:meter:" + oracleID) }
+
+func greenCertKey(addr Address) []byte { return append([]byte("cert:"), addr.Bytes()...) }
+
+// offsetMintedKey marks an offset record as tokenised into carbon credits.
+// Tokenised offsets belong to the credit holders and no longer count towards
+// the validator's own certificate.
+func offsetMintedKey(id []byte) []byte {
+	return []byte("ccs:minted:" + hex.EncodeToString(id))
+}
+
+// RegisterMeterOracle binds oracleID to validator. The oracle must exist in
+// the oracle registry with a public key and caller must be a regulator.
+func (g *GreenTechEngine) RegisterMeterOracle(caller Address, oracleID string, validator Address) (*MeterOracle, error) {
+	if !IsRegulator(caller) {
+		return nil, ErrUnauthorized
+	}
+	o, err := meterOracleConfig(oracleID)
+	if err != nil {
+		return nil, err
+	}
+	if len(o.PubKey) == 0 {
+		return nil, fmt.Errorf("oracle %s has no public key", oracleID)
+	}
+	m := &MeterOracle{OracleID: oracleID, Validator: validator, Regulator: caller, Registered: time.Now().UTC()}
+	raw, _ := json.Marshal(m)
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if err := g.led.SetState(meterKey(oracleID), raw); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// RemoveMeterOracle unbinds a meter oracle.
+func (g *GreenTechEngine) RemoveMeterOracle(caller Address, oracleID string) error {
+	if !IsRegulator(caller) {
+		return ErrUnauthorized
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.led.DeleteState(meterKey(oracleID))
+}
+
+// MeterOracle returns the meter binding of oracleID.
+func (g *GreenTechEngine) MeterOracle(oracleID string) (*MeterOracle, error) {
+	raw, err := g.led.GetState(meterKey(oracleID))
+	if err != nil || len(raw) == 0 {
+		return nil, ErrMeterUnknown
+	}
+	var m MeterOracle
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// verifyReading checks the meter binding, signature and timestamp of r and
+// returns its storage suffix.
+func (g *GreenTechEngine) verifyReading(r MeterReading, sig []byte) ([]byte, error) {
+	m, err := g.MeterOracle(r.OracleID)
+	if err != nil {
+		return nil, err
+	}
+	if m.Validator != r.Validator {
+		return nil, fmt.Errorf("%w: oracle %s does not meter %s", ErrMeterUnknown, r.OracleID, r.Validator.Hex())
+	}
+	o, err := meterOracleConfig(r.OracleID)
+	if err != nil {
+		return nil, err
+	}
+	msg := MeterReadingMessage(r)
+	var pub interface{} = o.PubKey
+	if o.Algo == AlgoEd25519 {
+		pub = ed25519.PublicKey(o.PubKey)
+	}
+	if ok, err := Verify(o.Algo, pub, msg, sig); err != nil || !ok {
+		return nil, ErrMeterSignature
+	}
+	now := time.Now()
+	ts := time.Unix(r.Timestamp, 0)
+	if ts.After(now.Add(5*time.Minute)) || now.Sub(ts) > GreenCertValidity {
+		return nil, ErrReadingStale
+	}
+	if r.PeriodEnd < r.PeriodStart {
+		return nil, errors.New("reading period ends before it starts")
+	}
+	h := sha256.Sum256(msg)
+	return h[:], nil
+}
+
+// ExportGreenCertificate signs the current certificate of addr with key.
+func (g *GreenTechEngine) ExportGreenCertificate(addr Address, key ed25519.PrivateKey) (*SignedGreenCertificate, error) {
+	c, err := g.Certificate(addr)
+	if err != nil {
+		return nil, err
+	}
+	if c.Expired(time.Now()) {
+		return nil, fmt.Errorf("certificate %s expired", c.ID)
+	}
+	body, _ := json.Marshal(c)
+	return &SignedGreenCertificate{
+		Format:      GreenCertExportFormat,
+		Certificate: *c,
+		Signer:      hex.EncodeToString(key.Public().(ed25519.PublicKey)),
+		Signature:   hex.EncodeToString(ed25519.Sign(key, body)),
+	}, nil
+}
+
+// VerifyGreenCertificateExport checks the signature of an exported
+// certificate. Callers decide whether the signer is trusted.
+func VerifyGreenCertificateExport(exp *SignedGreenCertificate) error {
+	if exp.Format != GreenCertExportFormat {
+		return fmt.Errorf("unsupported format %q", exp.Format)
+	}
+	pub, err := hex.DecodeString(exp.Signer)
+	if err != nil || len(pub) != ed25519.PublicKeySize {
+		return errors.New("invalid signer key")
+	}
+	sig, err := hex.DecodeString(exp.Signature)
+	if err != nil {
+		return errors.New("invalid signature encoding")
+	}
+	body, _ := json.Marshal(exp.Certificate)
+	if !ed25519.Verify(pub, body, sig) {
+		return errors.New("certificate signature invalid")
+	}
+	return nil
+}
+
+func greenTier(score float64) Certificate {
+	switch {
+	case score >= 0.5:
+		return CertGold
+	case score >= 0.0:
+		return CertSilver
+	case score >= -0.25:
+		return CertBronze
+	default:
+		return CertNone
+	}
+}
+
+func meterOracleConfig(oracleID string) (*Oracle, error) {
+	st := CurrentStore()
+	if st == nil {
+		return nil, errors.New("oracle store not initialised")
+	}
+	raw, err := st.Get([]byte(fmt.Sprintf("oracle:config:%s", oracleID)))
+	if err != nil || len(raw) == 0 {
+		return nil, fmt.Errorf("%w: oracle %s", ErrNotFound, oracleID)
+	}
+	var o Oracle
+	if err := json.Unmarshal(raw, &o); err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
@@ -12,16 +12,22 @@ package core
 //      Bronze ≥‑0.25  (≤25 % over emissions)
 //      None   <‑0.25  (high emitter)
 // • **Certify()** recomputes certificates each epoch, stores under `cert:`.
+//   Certificates expire after GreenCertValidity.
 // • **ShouldThrottle(addr)** returns true if score < ‑0.5 (heavy emitter) –
 //   consensus engine can reduce rewards or exclude leader rotation.
 //
+// Additions: usage and offset figures must be signed by a registered meter
+// oracle; see green_certificates.go.
+//
 // Dependencies: common + ledger + sync + time + json + sha256.
 // -----------------------------------------------------------------------------
 
 import (
 	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"sync"
 	"time"
 )
@@ -48,29 +54,41 @@ func InitGreenTech(led StateRW) { once.Do(func() { green = &GreenTechEngine{led:
 func Green() *GreenTechEngine   { return green }
 
 //---------------------------------------------------------------------
-// Recorders
+// Recorders – readings must be signed by the validator's meter oracle
 //---------------------------------------------------------------------
 
-func (g *GreenTechEngine) RecordUsage(v Address, energyKWh, carbonKg float64) error {
-	if energyKWh <= 0 || carbonKg <= 0 {
+func (g *GreenTechEngine) RecordUsage(r MeterReading, sig []byte) error {
+	if r.EnergyKWh <= 0 || r.CarbonKg <= 0 {
 		return errors.New("usage >0")
 	}
-	rec := UsageRecord{v, energyKWh, carbonKg, time.Now().Unix()}
-	b, _ := json.Marshal(rec)
-	h := sha256.Sum256(b)
-	g.led.SetState(append([]byte("usage:"), h[:]...), b)
-	return nil
+	id, err := g.verifyReading(r, sig)
+	if err != nil {
+		return err
+	}
+	rec := UsageRecord{r.Validator, r.EnergyKWh, r.CarbonKg, r.Timestamp, r.OracleID}
+	return g.putReading(append([]byte("usage:"), id...), rec)
 }
 
-func (g *GreenTechEngine) RecordOffset(v Address, offsetKg float64) error {
-	if offsetKg <= 0 {
+func (g *GreenTechEngine) RecordOffset(r MeterReading, sig []byte) error {
+	if r.OffsetKg <= 0 {
 		return errors.New("offset>0")
 	}
-	rec := OffsetRecord{v, offsetKg, time.Now().Unix()}
+	id, err := g.verifyReading(r, sig)
+	if err != nil {
+		return err
+	}
+	rec := OffsetRecord{r.Validator, r.OffsetKg, r.Timestamp, r.OracleID}
+	return g.putReading(append([]byte("offset:"), id...), rec)
+}
+
+func (g *GreenTechEngine) putReading(key []byte, rec interface{}) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if ok, _ := g.led.HasState(key); ok {
+		return ErrReadingReplay
+	}
 	b, _ := json.Marshal(rec)
-	h := sha256.Sum256(b)
-	g.led.SetState(append([]byte("offset:"), h[:]...), b)
-	return nil
+	return g.led.SetState(key, b)
 }
 
 //---------------------------------------------------------------------
@@ -78,49 +96,65 @@ func (g *GreenTechEngine) RecordOffset(v Address, offsetKg float64) error {
 //---------------------------------------------------------------------
 
 func (g *GreenTechEngine) Certify() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
 	sums := make(map[Address]*nodeSummary)
+	certs := make(map[Address]*GreenCertificate)
+	summary := func(a Address) (*nodeSummary, *GreenCertificate) {
+		s := sums[a]
+		if s == nil {
+			s = &nodeSummary{}
+			sums[a] = s
+			certs[a] = &GreenCertificate{Validator: a}
+		}
+		return s, certs[a]
+	}
+	addOracle := func(c *GreenCertificate, id string) {
+		for _, o := range c.Oracles {
+			if o == id {
+				return
+			}
+		}
+		c.Oracles = append(c.Oracles, id)
+	}
 	iter := g.led.PrefixIterator([]byte("usage:"))
 	for iter.Next() {
 		var u UsageRecord
 		_ = json.Unmarshal(iter.Value(), &u)
-		s := sums[u.Validator]
-		if s == nil {
-			s = &nodeSummary{}
-			sums[u.Validator] = s
-		}
+		s, c := summary(u.Validator)
 		s.Energy += u.EnergyKWh
 		s.Emitted += u.CarbonKg
+		c.Readings++
+		addOracle(c, u.Oracle)
 	}
 	iter = g.led.PrefixIterator([]byte("offset:"))
 	for iter.Next() {
+		if ok, _ := g.led.HasState(offsetMintedKey(iter.Key()[len("offset:"):])); ok {
+			continue // tokenised as carbon credits
+		}
 		var o OffsetRecord
 		_ = json.Unmarshal(iter.Value(), &o)
-		s := sums[o.Validator]
-		if s == nil {
-			s = &nodeSummary{}
-			sums[o.Validator] = s
-		}
+		s, c := summary(o.Validator)
 		s.Offset += o.OffsetKg
+		c.Readings++
+		addOracle(c, o.Oracle)
 	}
+	now := time.Now()
 	for addr, s := range sums {
-		s.Score = (s.Offset - s.Emitted) / s.Emitted
-		switch {
-		case s.Score >= 0.5:
-			s.Cert = CertGold
-		case s.Score >= 0.0:
-			s.Cert = CertSilver
-		case s.Score >= -0.25:
-			s.Cert = CertBronze
-		default:
-			s.Cert = CertNone
+		if s.Emitted <= 0 {
+			continue // offsets alone do not produce a score
 		}
-		key := append([]byte("cert:"), addr.Bytes()...)
-		blob, _ := json.Marshal(struct {
-			Score float64     `json:"score"`
-			Cert  Certificate `json:"cert"`
-			TS    int64       `json:"ts"`
-		}{s.Score, s.Cert, time.Now().Unix()})
-		g.led.SetState(key, blob)
+		s.Score = (s.Offset - s.Emitted) / s.Emitted
+		s.Cert = greenTier(s.Score)
+		c := certs[addr]
+		c.Cert, c.Score = s.Cert, s.Score
+		c.EnergyKWh, c.CarbonKg, c.OffsetKg = s.Energy, s.Emitted, s.Offset
+		c.TS = now.Unix()
+		c.Expires = now.Add(GreenCertValidity).Unix()
+		id := sha256.Sum256([]byte(fmt.Sprintf("%s:%d:%d", addr.Hex(), c.TS, c.Readings)))
+		c.ID = hex.EncodeToString(id[:16])
+		blob, _ := json.Marshal(c)
+		g.led.SetState(greenCertKey(addr), blob)
 	}
 }
 
@@ -128,30 +162,36 @@ func (g *GreenTechEngine) Certify() {
 // Public getters
 //---------------------------------------------------------------------
 
-func (g *GreenTechEngine) CertificateOf(addr Address) Certificate {
-	key := append([]byte("cert:"), addr.Bytes()...)
-	blob, _ := g.led.GetState(key)
+// Certificate returns the latest certificate issued to addr, expired or not.
+func (g *GreenTechEngine) Certificate(addr Address) (*GreenCertificate, error) {
+	blob, _ := g.led.GetState(greenCertKey(addr))
 	if len(blob) == 0 {
-		return CertNone
+		return nil, ErrNotFound
 	}
-	var tmp struct {
-		Cert Certificate `json:"cert"`
+	var c GreenCertificate
+	if err := json.Unmarshal(blob, &c); err != nil {
+		return nil, err
 	}
-	_ = json.Unmarshal(blob, &tmp)
-	return tmp.Cert
+	c.Validator = addr
+	return &c, nil
 }
 
+func (g *GreenTechEngine) CertificateOf(addr Address) Certificate {
+	c, err := g.Certificate(addr)
+	if err != nil || c.Expired(time.Now()) {
+		return CertNone
+	}
+	return c.Cert
+}
+
+// ShouldThrottle reports whether addr holds a valid certificate whose score
+// marks it as a heavy emitter. Validator scheduling skips such nodes.
 func (g *GreenTechEngine) ShouldThrottle(addr Address) bool {
-	key := append([]byte("cert:"), addr.Bytes()...)
-	blob, _ := g.led.GetState(key)
-	if len(blob) == 0 {
+	c, err := g.Certificate(addr)
+	if err != nil || c.Expired(time.Now()) {
 		return false
 	}
-	var tmp struct {
-		Score float64 `json:"score"`
-	}
-	_ = json.Unmarshal(blob, &tmp)
-	return tmp.Score < -0.5
+	return c.Score < GreenThrottleScore
 }
 
 // ListCertificates returns all stored certificates with their scores. It is
@@ -159,6 +199,7 @@ func (g *GreenTechEngine) ShouldThrottle(addr Address) bool {
 func (g *GreenTechEngine) ListCertificates() ([]CertificateInfo, error) {
 	iter := g.led.PrefixIterator([]byte("cert:"))
 	var list []CertificateInfo
+	now := time.Now()
 	for iter.Next() {
 		key := iter.Key()
 		if len(key) < len("cert:")+20 {
@@ -167,14 +208,11 @@ func (g *GreenTechEngine) ListCertificates() ([]CertificateInfo, error) {
 		var addr Address
 		copy(addr[:], key[len("cert:"):len("cert:")+20])
 
-		var tmp struct {
-			Score float64     `json:"score"`
-			Cert  Certificate `json:"cert"`
-		}
-		if err := json.Unmarshal(iter.Value(), &tmp); err != nil {
+		var c GreenCertificate
+		if err := json.Unmarshal(iter.Value(), &c); err != nil {
 			continue
 		}
-		list = append(list, CertificateInfo{Address: addr, Score: tmp.Score, Cert: tmp.Cert})
+		list = append(list, CertificateInfo{Address: addr, Score: c.Score, Cert: c.Cert, Expires: c.Expires, Expired: c.Expired(now)})
 	}
 	return list, nil
 }
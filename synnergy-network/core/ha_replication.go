@@ -0,0 +1,517 @@
+package core
+
+// ha_replication.go – primary → standby WAL streaming for HighAvailability.
+//
+// The primary listens for standbys and streams its WAL records, the JSON
+// encoded blocks it appends to ledger.wal, in height order. Standbys apply
+// each record through Ledger.AddBlock, so they hold an identical chain and
+// WAL of their own. The channel is authenticated with an HMAC over a shared
+// secret: both sides prove knowledge of the secret against a fresh nonce in
+// the handshake and every record carries a MAC.
+//
+// Split-brain is prevented with a fencing epoch. HA_Promote turns a
+// following standby into the primary under epoch+1 and tells the old
+// primary so; a primary that meets a higher epoch fences its ledger, which
+// then refuses new blocks, and standbys refuse streams from a primary whose
+// epoch is older than the one they have seen.
+
+import (
+	"bufio"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ErrFenced is returned by the ledger of a primary that has been replaced.
+var ErrFenced = errors.New("ledger fenced: a newer primary has been promoted")
+
+var errStalePrimary = errors.New("replication: primary epoch is older than ours")
+
+// HA roles.
+const (
+	HARolePrimary = "primary"
+	HARoleStandby = "standby"
+	HARoleFenced  = "fenced"
+)
+
+// HAReplicationConfig configures WAL streaming. Self identifies this node in
+// the standby set; Secret is shared by the primary and its standbys.
+// EpochPath, when set, persists the fencing epoch across restarts.
+type HAReplicationConfig struct {
+	Self      Address
+	Secret    []byte
+	EpochPath string
+	Heartbeat time.Duration
+	Timeout   time.Duration
+}
+
+func (c *HAReplicationConfig) defaults() {
+	if c.Heartbeat <= 0 {
+		c.Heartbeat = 2 * time.Second
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = 5 * c.Heartbeat
+	}
+}
+
+// HAStatus describes this node's replication state.
+type HAStatus struct {
+	Role    string `json:"role"`
+	Epoch   uint64 `json:"epoch"`
+	Height  uint64 `json:"height"`
+	Primary string `json:"primary,omitempty"`
+}
+
+// replication wire messages, one JSON object per line.
+type replChallenge struct {
+	Nonce []byte `json:"nonce"`
+}
+
+type replHello struct {
+	Node  Address `json:"node"`
+	From  uint64  `json:"from"`
+	Epoch uint64  `json:"epoch"`
+	Fence bool    `json:"fence,omitempty"`
+	MAC   []byte  `json:"mac"`
+}
+
+type replAccept struct {
+	Epoch uint64 `json:"epoch"`
+	Error string `json:"error,omitempty"`
+	MAC   []byte `json:"mac"`
+}
+
+// walRecord carries one WAL entry; Seq is the block height. Records with
+// no data are heartbeats.
+type walRecord struct {
+	Seq   uint64 `json:"seq"`
+	Epoch uint64 `json:"epoch"`
+	Data  []byte `json:"data,omitempty"`
+	MAC   []byte `json:"mac"`
+}
+
+func (ha *HighAvailability) mac(parts ...[]byte) []byte {
+	m := hmac.New(sha256.New, ha.cfg.Secret)
+	for _, p := range parts {
+		m.Write(binary.BigEndian.AppendUint32(nil, uint32(len(p))))
+		m.Write(p)
+	}
+	return m.Sum(nil)
+}
+
+func beU64(v uint64) []byte { return binary.BigEndian.AppendUint64(nil, v) }
+
+func (h replHello) signed(ha *HighAvailability, nonce []byte) []byte {
+	fence := byte(0)
+	if h.Fence {
+		fence = 1
+	}
+	return ha.mac([]byte("standby"), nonce, h.Node[:], beU64(h.From), beU64(h.Epoch), []byte{fence})
+}
+
+func (r walRecord) signed(ha *HighAvailability) []byte {
+	return ha.mac([]byte("wal"), beU64(r.Seq), beU64(r.Epoch), r.Data)
+}
+
+// EnableReplication configures WAL streaming and loads the fencing epoch.
+func (ha *HighAvailability) EnableReplication(cfg HAReplicationConfig) error {
+	if len(cfg.Secret) == 0 {
+		return errors.New("replication secret required")
+	}
+	cfg.defaults()
+	var epoch uint64
+	if cfg.EpochPath != "" {
+		raw, err := os.ReadFile(cfg.EpochPath)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if len(raw) > 0 {
+			if epoch, err = strconv.ParseUint(strings.TrimSpace(string(raw)), 10, 64); err != nil {
+				return fmt.Errorf("fencing epoch: %w", err)
+			}
+		}
+	}
+	ha.mu.Lock()
+	ha.cfg, ha.epoch = cfg, epoch
+	if ha.role == "" {
+		ha.role = HARolePrimary
+	}
+	ha.mu.Unlock()
+	return nil
+}
+
+// setEpoch raises the fencing epoch and persists it. The caller holds ha.mu.
+func (ha *HighAvailability) setEpoch(e uint64) error {
+	if e <= ha.epoch {
+		return nil
+	}
+	ha.epoch = e
+	if ha.cfg.EpochPath == "" {
+		return nil
+	}
+	tmp := ha.cfg.EpochPath + ".tmp"
+	if err := os.WriteFile(tmp, []byte(strconv.FormatUint(e, 10)), 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, ha.cfg.EpochPath)
+}
+
+// fence stops this node acting as primary after a newer epoch was seen.
+func (ha *HighAvailability) fence(epoch uint64) {
+	ha.mu.Lock()
+	if err := ha.setEpoch(epoch); err != nil {
+		logrus.Errorf("ha: persist epoch: %v", err)
+	}
+	ha.role = HARoleFenced
+	ha.mu.Unlock()
+	ha.ledger.setFenced(true)
+	logrus.Warnf("ha: fenced by epoch %d; this node no longer accepts blocks", epoch)
+}
+
+// HA_Status reports the role, fencing epoch and height of this node.
+func (ha *HighAvailability) HA_Status() HAStatus {
+	ha.mu.RLock()
+	defer ha.mu.RUnlock()
+	return HAStatus{Role: ha.role, Epoch: ha.epoch, Height: ha.ledger.LastHeight(), Primary: ha.primary}
+}
+
+// ---------------------------------------------------------------------
+// Primary
+// ---------------------------------------------------------------------
+
+// HA_StreamWAL serves WAL streams to standbys on addr until ctx is done.
+// When standbys are registered only those may connect.
+func (ha *HighAvailability) HA_StreamWAL(ctx context.Context, addr string) error {
+	if len(ha.cfg.Secret) == 0 {
+		return errors.New("replication not enabled")
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	go func() {
+		<-ctx.Done()
+		ln.Close()
+	}()
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		go func() {
+			defer conn.Close()
+			if err := ha.serveStandby(ctx, conn); err != nil {
+				logrus.WithField("peer", conn.RemoteAddr()).Warnf("ha: wal stream: %v", err)
+			}
+		}()
+	}
+}
+
+func (ha *HighAvailability) serveStandby(ctx context.Context, conn net.Conn) error {
+	enc := json.NewEncoder(conn)
+	rd := bufio.NewReader(conn)
+	nonce := make([]byte, 32)
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	if err := enc.Encode(replChallenge{Nonce: nonce}); err != nil {
+		return err
+	}
+	_ = conn.SetReadDeadline(time.Now().Add(ha.cfg.Timeout))
+	var hello replHello
+	if err := readLine(rd, &hello); err != nil {
+		return err
+	}
+	_ = conn.SetReadDeadline(time.Time{})
+	if !hmac.Equal(hello.MAC, hello.signed(ha, nonce)) {
+		return errors.New("standby failed authentication")
+	}
+	ha.mu.RLock()
+	_, known := ha.standby[hello.Node]
+	open := len(ha.standby) == 0
+	epoch, role := ha.epoch, ha.role
+	ha.mu.RUnlock()
+	if !known && !open {
+		return enc.Encode(replAccept{Error: "standby not registered"})
+	}
+	if hello.Epoch > epoch {
+		ha.fence(hello.Epoch)
+		return enc.Encode(replAccept{Error: ErrFenced.Error()})
+	}
+	if role != HARolePrimary {
+		return enc.Encode(replAccept{Error: "not the primary (" + role + ")"})
+	}
+	if err := enc.Encode(replAccept{Epoch: epoch, MAC: ha.mac([]byte("primary"), nonce, beU64(epoch))}); err != nil {
+		return err
+	}
+	logrus.Infof("ha: standby %s following from height %d", hello.Node.Short(), hello.From)
+
+	wake, stop := ha.ledger.watchWAL()
+	defer stop()
+	hb := time.NewTicker(ha.cfg.Heartbeat)
+	defer hb.Stop()
+	next := hello.From
+	for {
+		for {
+			data, ok, err := ha.ledger.walRecordAt(next)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				break
+			}
+			rec := walRecord{Seq: next, Epoch: epoch, Data: data}
+			rec.MAC = rec.signed(ha)
+			if err := enc.Encode(rec); err != nil {
+				return err
+			}
+			next++
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-wake:
+		case <-hb.C:
+			ha.mu.RLock()
+			fenced := ha.role != HARolePrimary
+			ha.mu.RUnlock()
+			if fenced {
+				return ErrFenced
+			}
+			rec := walRecord{Epoch: epoch}
+			rec.MAC = rec.signed(ha)
+			if err := enc.Encode(rec); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// ---------------------------------------------------------------------
+// Standby
+// ---------------------------------------------------------------------
+
+// HA_Follow connects to the primary at addr and applies its WAL records
+// in order until ctx is cancelled, the primary goes silent or HA_Promote
+// makes this node the primary.
+func (ha *HighAvailability) HA_Follow(ctx context.Context, addr string) error {
+	if len(ha.cfg.Secret) == 0 {
+		return errors.New("replication not enabled")
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	ha.mu.Lock()
+	if ha.following != nil {
+		ha.mu.Unlock()
+		return errors.New("already following a primary")
+	}
+	ha.role, ha.primary, ha.following = HARoleStandby, addr, cancel
+	ha.mu.Unlock()
+	defer func() {
+		ha.mu.Lock()
+		ha.following = nil
+		ha.mu.Unlock()
+	}()
+
+	d := net.Dialer{Timeout: ha.cfg.Timeout}
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+	epoch, rd, err := ha.handshake(conn, false)
+	if err != nil {
+		return err
+	}
+	for {
+		_ = conn.SetReadDeadline(time.Now().Add(ha.cfg.Timeout))
+		var rec walRecord
+		if err := readLine(rd, &rec); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("primary lost: %w", err)
+		}
+		if !hmac.Equal(rec.MAC, rec.signed(ha)) {
+			return errors.New("wal record failed authentication")
+		}
+		if rec.Epoch != epoch {
+			return errStalePrimary
+		}
+		if len(rec.Data) == 0 {
+			continue
+		}
+		var blk Block
+		if err := json.Unmarshal(rec.Data, &blk); err != nil {
+			return fmt.Errorf("wal record %d: %w", rec.Seq, err)
+		}
+		if blk.Header.Height != rec.Seq {
+			return fmt.Errorf("wal record %d carries block %d", rec.Seq, blk.Header.Height)
+		}
+		if err := ha.ledger.AddBlock(&blk); err != nil {
+			return fmt.Errorf("apply wal record %d: %w", rec.Seq, err)
+		}
+	}
+}
+
+// handshake authenticates this standby to the primary on conn and checks
+// the primary's reply. With fence set it announces a newer epoch instead.
+func (ha *HighAvailability) handshake(conn net.Conn, fence bool) (uint64, *bufio.Reader, error) {
+	rd := bufio.NewReader(conn)
+	_ = conn.SetDeadline(time.Now().Add(ha.cfg.Timeout))
+	defer conn.SetDeadline(time.Time{})
+	var ch replChallenge
+	if err := readLine(rd, &ch); err != nil {
+		return 0, nil, err
+	}
+	ha.mu.RLock()
+	hello := replHello{Node: ha.cfg.Self, From: ha.ledger.nextHeight(), Epoch: ha.epoch, Fence: fence}
+	ha.mu.RUnlock()
+	hello.MAC = hello.signed(ha, ch.Nonce)
+	if err := json.NewEncoder(conn).Encode(hello); err != nil {
+		return 0, nil, err
+	}
+	var acc replAccept
+	if err := readLine(rd, &acc); err != nil {
+		return 0, nil, err
+	}
+	if acc.Error != "" {
+		return 0, nil, fmt.Errorf("primary refused: %s", acc.Error)
+	}
+	if !hmac.Equal(acc.MAC, ha.mac([]byte("primary"), ch.Nonce, beU64(acc.Epoch))) {
+		return 0, nil, errors.New("primary failed authentication")
+	}
+	if acc.Epoch < hello.Epoch {
+		return 0, nil, errStalePrimary
+	}
+	ha.mu.Lock()
+	err := ha.setEpoch(acc.Epoch)
+	ha.mu.Unlock()
+	return acc.Epoch, rd, err
+}
+
+// promoteSelf makes this standby the primary under a new epoch and fences
+// the primary it was following, if that primary is still reachable.
+func (ha *HighAvailability) promoteSelf() error {
+	ha.mu.Lock()
+	if ha.following != nil {
+		ha.following()
+		ha.following = nil
+	}
+	old := ha.primary
+	if err := ha.setEpoch(ha.epoch + 1); err != nil {
+		ha.mu.Unlock()
+		return err
+	}
+	ha.role, ha.primary = HARolePrimary, ""
+	epoch := ha.epoch
+	ha.mu.Unlock()
+	ha.ledger.setFenced(false)
+	logrus.Infof("ha: promoted to primary at epoch %d", epoch)
+
+	if old == "" {
+		return nil
+	}
+	conn, err := net.DialTimeout("tcp", old, ha.cfg.Timeout)
+	if err != nil {
+		logrus.Warnf("ha: old primary %s unreachable (%v); standbys will refuse it by epoch", old, err)
+		return nil
+	}
+	defer conn.Close()
+	if _, _, err := ha.handshake(conn, true); err != nil && !strings.Contains(err.Error(), ErrFenced.Error()) {
+		logrus.Warnf("ha: fence %s: %v", old, err)
+	}
+	return nil
+}
+
+func readLine(rd *bufio.Reader, v any) error {
+	line, err := rd.ReadBytes('\n')
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(line, v)
+}
+
+// ---------------------------------------------------------------------
+// Ledger hooks
+// ---------------------------------------------------------------------
+
+// watchWAL returns a channel signalled after every WAL append.
+func (l *Ledger) watchWAL() (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+	l.mu.Lock()
+	if l.walWatchers == nil {
+		l.walWatchers = make(map[chan struct{}]struct{})
+	}
+	l.walWatchers[ch] = struct{}{}
+	l.mu.Unlock()
+	return ch, func() {
+		l.mu.Lock()
+		delete(l.walWatchers, ch)
+		l.mu.Unlock()
+	}
+}
+
+// notifyWAL wakes the WAL streams. The caller holds l.mu.
+func (l *Ledger) notifyWAL() {
+	for ch := range l.walWatchers {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// walRecordAt returns the WAL record of the block at height, or false if
+// the chain has not reached it yet.
+func (l *Ledger) walRecordAt(height uint64) ([]byte, bool, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if len(l.Blocks) == 0 {
+		return nil, false, nil
+	}
+	first := l.Blocks[0].Header.Height
+	if height < first {
+		return nil, false, fmt.Errorf("height %d is pruned (first retained %d); bootstrap the standby from a snapshot", height, first)
+	}
+	if height-first >= uint64(len(l.Blocks)) {
+		return nil, false, nil
+	}
+	data, err := json.Marshal(l.Blocks[height-first])
+	return data, err == nil, err
+}
+
+// nextHeight is the height of the next block the ledger will accept.
+func (l *Ledger) nextHeight() uint64 {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if n := len(l.Blocks); n > 0 {
+		return l.Blocks[n-1].Header.Height + 1
+	}
+	return 0
+}
+
+func (l *Ledger) setFenced(v bool) {
+	l.mu.Lock()
+	l.fenced = v
+	l.mu.Unlock()
+}
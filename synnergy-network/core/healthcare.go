@@ -16,6 +16,7 @@ type HealthRecord struct {
 	Patient   Address `json:"patient"`
 	Provider  Address `json:"provider"`
 	CID       string  `json:"cid"`
+	KeyHash   string  `json:"key_hash,omitempty"`
 	CreatedAt int64   `json:"created_at"`
 }
 
@@ -64,7 +65,11 @@ func GrantAccess(patient, provider Address) error {
 	if ok, _ := hc.led.HasState(keyPatient(patient)); !ok {
 		return errors.New("patient unknown")
 	}
-	return hc.led.SetState(keyAccess(patient, provider), []byte{1})
+	if err := hc.led.SetState(keyAccess(patient, provider), []byte{1}); err != nil {
+		return err
+	}
+	logHealthAccess(patient, "", patient, "provider_grant", "normal", map[string]string{"provider": provider.Hex()})
+	return nil
 }
 
 // RevokeAccess removes a provider from the patient's allow list.
@@ -72,7 +77,11 @@ func RevokeAccess(patient, provider Address) error {
 	if hc == nil {
 		return errors.New("healthcare not initialised")
 	}
-	return hc.led.DeleteState(keyAccess(patient, provider))
+	if err := hc.led.DeleteState(keyAccess(patient, provider)); err != nil {
+		return err
+	}
+	logHealthAccess(patient, "", patient, "provider_revoke", "normal", map[string]string{"provider": provider.Hex()})
+	return nil
 }
 
 // AddHealthRecord stores a CID referencing encrypted medical data.
@@ -96,6 +105,7 @@ func AddHealthRecord(patient, provider Address, cid string) (string, error) {
 		return "", err
 	}
 	_ = hc.led.Transfer(provider, patient, 1)
+	logHealthAccess(patient, id, provider, "upload", "normal", map[string]string{"cid": cid})
 	return id, nil
 }
 
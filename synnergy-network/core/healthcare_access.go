@@ -0,0 +1,467 @@
+package core
+
+// healthcare_access.go – encrypted record keys, delegated access and
+// break-glass emergency access.
+//
+// Record contents live off-chain encrypted with a random per-record AES-GCM
+// key (EncryptHealthData). Only sha256(key) is stored on chain. Participants
+// register an X25519 public key; access grants carry the record key sealed
+// to the grantee's key together with an expiry.
+//
+// Break-glass access lets emergency staff reach a record without the
+// patient's consent. It needs signatures from two distinct authority nodes
+// over BreakGlassMessage, creates a short-lived grant and always emits a
+// high-priority audit event on "health:alert". Because the chain never sees
+// the plain key, a break-glass grant is fulfilled by any key holder (usually
+// the uploading provider) via FulfilHealthGrant.
+//
+// Every grant, revocation, key fetch and break-glass event touching a
+// patient's records is appended to the patient's access log.
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/nacl/box"
+)
+
+// BreakGlassTTL bounds the lifetime of an emergency grant.
+const BreakGlassTTL = 24 * time.Hour
+
+var (
+	// ErrHealthGrantExpired is returned when a grant has expired or was revoked.
+	ErrHealthGrantExpired = errors.New("access grant expired")
+	// ErrBreakGlassApprovals is returned when break-glass access lacks two
+	// valid authority signatures.
+	ErrBreakGlassApprovals = errors.New("break-glass requires two authority signatures")
+)
+
+// HealthKeyGrant gives Grantee access to one record until Expires.
+type HealthKeyGrant struct {
+	RecordID   string  `json:"record_id"`
+	Patient    Address `json:"patient"`
+	Grantee    Address `json:"grantee"`
+	WrappedKey []byte  `json:"wrapped_key,omitempty"`
+	Expires    int64   `json:"expires"`
+	GrantedBy  Address `json:"granted_by"`
+	GrantedAt  int64   `json:"granted_at"`
+	BreakGlass bool    `json:"break_glass,omitempty"`
+	Reason     string  `json:"reason,omitempty"`
+}
+
+// BreakGlassApproval is one authority signature over BreakGlassMessage.
+type BreakGlassApproval struct {
+	PubKey []byte `json:"pub_key"`
+	Sig    []byte `json:"sig"`
+}
+
+// HealthAccessEvent is one entry of a patient's access log.
+type HealthAccessEvent struct {
+	Patient  Address           `json:"patient"`
+	RecordID string            `json:"record_id,omitempty"`
+	Actor    Address           `json:"actor"`
+	Action   string            `json:"action"`
+	Priority string            `json:"priority"`
+	Time     int64             `json:"time"`
+	Meta     map[string]string `json:"meta,omitempty"`
+}
+
+func keyHealthPub(a Address) []byte { return []byte("health:pubkey:" + hex.EncodeToString(a[:])) }
+func keyHealthGrant(p Address, rec string, g Address) []byte {
+	return []byte("health:grant:" + hex.EncodeToString(p[:]) + ":" + rec + ":" + hex.EncodeToString(g[:]))
+}
+func prefixHealthGrant(p Address, rec string) []byte {
+	return []byte("health:grant:" + hex.EncodeToString(p[:]) + ":" + rec + ":")
+}
+func prefixHealthLog(p Address) []byte { return []byte("health:log:" + hex.EncodeToString(p[:]) + ":") }
+
+// EncryptHealthData encrypts a record with a fresh AES-256-GCM key and
+// returns the ciphertext (nonce prefixed) and the key.
+func EncryptHealthData(plain []byte) ([]byte, []byte, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, nil, err
+	}
+	gcm, err := healthGCM(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, nil, err
+	}
+	return gcm.Seal(nonce, nonce, plain, nil), key, nil
+}
+
+// DecryptHealthData reverses EncryptHealthData.
+func DecryptHealthData(ciphertext, key []byte) ([]byte, error) {
+	gcm, err := healthGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < gcm.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	n := gcm.NonceSize()
+	return gcm.Open(nil, ciphertext[:n], ciphertext[n:], nil)
+}
+
+func healthGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// RegisterHealthKey stores the X25519 public key used to seal record keys
+// for addr.
+func RegisterHealthKey(addr Address, pub [32]byte) error {
+	if hc == nil {
+		return errors.New("healthcare not initialised")
+	}
+	return hc.led.SetState(keyHealthPub(addr), pub[:])
+}
+
+func healthPubKey(addr Address) (*[32]byte, error) {
+	raw, err := hc.led.GetState(keyHealthPub(addr))
+	if err != nil || len(raw) != 32 {
+		return nil, fmt.Errorf("no encryption key registered for %s", addr.Hex())
+	}
+	var pub [32]byte
+	copy(pub[:], raw)
+	return &pub, nil
+}
+
+func sealHealthKey(to Address, key []byte) ([]byte, error) {
+	pub, err := healthPubKey(to)
+	if err != nil {
+		return nil, err
+	}
+	return box.SealAnonymous(nil, key, pub, rand.Reader)
+}
+
+// OpenHealthKey unseals a wrapped record key with the grantee's X25519 key
+// pair.
+func OpenHealthKey(wrapped []byte, pub, priv *[32]byte) ([]byte, error) {
+	key, ok := box.OpenAnonymous(nil, wrapped, pub, priv)
+	if !ok {
+		return nil, errors.New("cannot open wrapped key")
+	}
+	return key, nil
+}
+
+func getHealthRecord(patient Address, id string) (*HealthRecord, error) {
+	raw, err := hc.led.GetState(keyRecord(patient, id))
+	if err != nil || len(raw) == 0 {
+		return nil, ErrNotFound
+	}
+	var rec HealthRecord
+	if err := json.Unmarshal(raw, &rec); err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
+
+func checkRecordKey(rec *HealthRecord, key []byte) error {
+	h := sha256.Sum256(key)
+	if rec.KeyHash == "" || hex.EncodeToString(h[:]) != rec.KeyHash {
+		return errors.New("record key does not match")
+	}
+	return nil
+}
+
+// AddEncryptedHealthRecord stores a record whose off-chain content is
+// encrypted with key. The key is sealed to the patient and, when it differs,
+// the provider so both can always recover it.
+func AddEncryptedHealthRecord(patient, provider Address, cid string, key []byte) (string, error) {
+	if len(key) != 32 {
+		return "", errors.New("record key must be 32 bytes")
+	}
+	id, err := AddHealthRecord(patient, provider, cid)
+	if err != nil {
+		return "", err
+	}
+	rec, err := getHealthRecord(patient, id)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.Sum256(key)
+	rec.KeyHash = hex.EncodeToString(h[:])
+	blob, _ := json.Marshal(rec)
+	if err := hc.led.SetState(keyRecord(patient, id), blob); err != nil {
+		return "", err
+	}
+	holders := []Address{patient}
+	if provider != patient {
+		holders = append(holders, provider)
+	}
+	for _, a := range holders {
+		if _, err := healthPubKey(a); err != nil {
+			continue
+		}
+		if _, err := putHealthGrant(patient, id, a, provider, key, 0, false, ""); err != nil {
+			return "", err
+		}
+	}
+	return id, nil
+}
+
+func putHealthGrant(patient Address, recordID string, grantee, by Address, key []byte, expires int64, breakGlass bool, reason string) (*HealthKeyGrant, error) {
+	g := &HealthKeyGrant{
+		RecordID:   recordID,
+		Patient:    patient,
+		Grantee:    grantee,
+		Expires:    expires,
+		GrantedBy:  by,
+		GrantedAt:  time.Now().Unix(),
+		BreakGlass: breakGlass,
+		Reason:     reason,
+	}
+	if key != nil {
+		wrapped, err := sealHealthKey(grantee, key)
+		if err != nil {
+			return nil, err
+		}
+		g.WrappedKey = wrapped
+	}
+	blob, _ := json.Marshal(g)
+	if err := hc.led.SetState(keyHealthGrant(patient, recordID, grantee), blob); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+func getHealthGrant(patient Address, recordID string, grantee Address) (*HealthKeyGrant, error) {
+	raw, err := hc.led.GetState(keyHealthGrant(patient, recordID, grantee))
+	if err != nil || len(raw) == 0 {
+		return nil, ErrHealthGrantExpired
+	}
+	var g HealthKeyGrant
+	if err := json.Unmarshal(raw, &g); err != nil {
+		return nil, err
+	}
+	if g.Expires != 0 && time.Now().Unix() >= g.Expires {
+		return nil, ErrHealthGrantExpired
+	}
+	return &g, nil
+}
+
+// GrantRecordAccess seals key to grantee until expires. Only the patient may
+// delegate access. A zero expires never expires.
+func GrantRecordAccess(patient, grantee Address, recordID string, key []byte, expires time.Time) (*HealthKeyGrant, error) {
+	if hc == nil {
+		return nil, errors.New("healthcare not initialised")
+	}
+	rec, err := getHealthRecord(patient, recordID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkRecordKey(rec, key); err != nil {
+		return nil, err
+	}
+	var exp int64
+	if !expires.IsZero() {
+		if !expires.After(time.Now()) {
+			return nil, errors.New("expiry must be in the future")
+		}
+		exp = expires.Unix()
+	}
+	g, err := putHealthGrant(patient, recordID, grantee, patient, key, exp, false, "")
+	if err != nil {
+		return nil, err
+	}
+	logHealthAccess(patient, recordID, patient, "grant", "normal", map[string]string{
+		"grantee": grantee.Hex(), "expires": strconv.FormatInt(exp, 10)})
+	return g, nil
+}
+
+// RevokeRecordAccess removes grantee's grant on a record.
+func RevokeRecordAccess(patient, grantee Address, recordID string) error {
+	if hc == nil {
+		return errors.New("healthcare not initialised")
+	}
+	if err := hc.led.DeleteState(keyHealthGrant(patient, recordID, grantee)); err != nil {
+		return err
+	}
+	logHealthAccess(patient, recordID, patient, "revoke", "normal", map[string]string{"grantee": grantee.Hex()})
+	return nil
+}
+
+// FetchRecordKey returns requester's wrapped key for a record if the grant is
+// live and logs the access.
+func FetchRecordKey(patient, requester Address, recordID string) (*HealthKeyGrant, error) {
+	if hc == nil {
+		return nil, errors.New("healthcare not initialised")
+	}
+	g, err := getHealthGrant(patient, recordID, requester)
+	if err != nil {
+		return nil, err
+	}
+	if len(g.WrappedKey) == 0 {
+		return nil, errors.New("grant awaiting key from a record key holder")
+	}
+	prio := "normal"
+	if g.BreakGlass {
+		prio = "high"
+	}
+	logHealthAccess(patient, recordID, requester, "key_fetch", prio, nil)
+	return g, nil
+}
+
+// FulfilHealthGrant seals key into a live grant that has no key yet, such as
+// a break-glass grant. Any holder of the record key may fulfil it.
+func FulfilHealthGrant(patient, grantee, by Address, recordID string, key []byte) error {
+	if hc == nil {
+		return errors.New("healthcare not initialised")
+	}
+	rec, err := getHealthRecord(patient, recordID)
+	if err != nil {
+		return err
+	}
+	if err := checkRecordKey(rec, key); err != nil {
+		return err
+	}
+	g, err := getHealthGrant(patient, recordID, grantee)
+	if err != nil {
+		return err
+	}
+	if len(g.WrappedKey) != 0 {
+		return errors.New("grant already holds a key")
+	}
+	if g.WrappedKey, err = sealHealthKey(grantee, key); err != nil {
+		return err
+	}
+	blob, _ := json.Marshal(g)
+	if err := hc.led.SetState(keyHealthGrant(patient, recordID, grantee), blob); err != nil {
+		return err
+	}
+	logHealthAccess(patient, recordID, by, "grant_fulfilled", "normal", map[string]string{"grantee": grantee.Hex()})
+	return nil
+}
+
+// RecordGrants lists the grants of a record, including expired ones.
+func RecordGrants(patient Address, recordID string) ([]HealthKeyGrant, error) {
+	if hc == nil {
+		return nil, errors.New("healthcare not initialised")
+	}
+	it := hc.led.PrefixIterator(prefixHealthGrant(patient, recordID))
+	var out []HealthKeyGrant
+	for it.Next() {
+		var g HealthKeyGrant
+		if err := json.Unmarshal(it.Value(), &g); err == nil {
+			out = append(out, g)
+		}
+	}
+	return out, it.Error()
+}
+
+// BreakGlassMessage is the payload authority nodes sign to approve
+// emergency access by requester.
+func BreakGlassMessage(patient Address, recordID string, requester Address, timestamp int64) []byte {
+	return []byte("synnergy-break-glass:" + patient.Hex() + ":" + recordID + ":" + requester.Hex() + ":" + strconv.FormatInt(timestamp, 10))
+}
+
+// BreakGlassAccess opens emergency access for requester. approvals must hold
+// signatures from two distinct authority nodes over BreakGlassMessage with a
+// timestamp within five minutes of now.
+func BreakGlassAccess(patient Address, recordID string, requester Address, reason string, timestamp int64, approvals []BreakGlassApproval) (*HealthKeyGrant, error) {
+	if hc == nil {
+		return nil, errors.New("healthcare not initialised")
+	}
+	if reason == "" {
+		return nil, errors.New("reason required")
+	}
+	if d := time.Since(time.Unix(timestamp, 0)); d > 5*time.Minute || d < -5*time.Minute {
+		return nil, fmt.Errorf("%w: stale timestamp", ErrBreakGlassApprovals)
+	}
+	if _, err := getHealthRecord(patient, recordID); err != nil {
+		return nil, err
+	}
+	auth := CurrentAuthoritySet()
+	if auth == nil {
+		return nil, errors.New("authority set not initialised")
+	}
+	msg := BreakGlassMessage(patient, recordID, requester, timestamp)
+	signers := make(map[Address]bool)
+	for _, a := range approvals {
+		if len(a.PubKey) != ed25519.PublicKeySize || !ed25519.Verify(a.PubKey, msg, a.Sig) {
+			continue
+		}
+		if addr := Ed25519Address(a.PubKey); auth.IsAuthority(addr) {
+			signers[addr] = true
+		}
+	}
+	if len(signers) < 2 {
+		return nil, ErrBreakGlassApprovals
+	}
+	g, err := putHealthGrant(patient, recordID, requester, requester, nil, time.Now().Add(BreakGlassTTL).Unix(), true, reason)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for s := range signers {
+		names = append(names, s.Hex())
+	}
+	sort.Strings(names)
+	meta := map[string]string{"requester": requester.Hex(), "reason": reason, "authorities": strings.Join(names, ",")}
+	logHealthAccess(patient, recordID, requester, "break_glass", "high", meta)
+	return g, nil
+}
+
+// logHealthAccess appends an access event to the patient's log, mirrors it
+// to the audit manager and broadcasts it. High-priority events are also
+// broadcast on "health:alert".
+func logHealthAccess(patient Address, recordID string, actor Address, action, priority string, meta map[string]string) {
+	ev := HealthAccessEvent{
+		Patient:  patient,
+		RecordID: recordID,
+		Actor:    actor,
+		Action:   action,
+		Priority: priority,
+		Time:     time.Now().UnixNano(),
+		Meta:     meta,
+	}
+	raw, _ := json.Marshal(ev)
+	key := append(prefixHealthLog(patient), fmt.Sprintf("%020d", ev.Time)...)
+	_ = hc.led.SetState(key, raw)
+	if am := AuditManagerInstance(); am != nil {
+		m := map[string]string{"record": recordID, "actor": actor.Hex(), "priority": priority}
+		for k, v := range meta {
+			m[k] = v
+		}
+		_ = am.Log(patient, "health_"+action, m)
+	}
+	_ = Broadcast("health:audit", raw)
+	if priority == "high" {
+		_ = Broadcast("health:alert", raw)
+	}
+}
+
+// HealthAccessLog returns every access event touching the patient's
+// records, oldest first.
+func HealthAccessLog(patient Address) ([]HealthAccessEvent, error) {
+	if hc == nil {
+		return nil, errors.New("healthcare not initialised")
+	}
+	it := hc.led.PrefixIterator(prefixHealthLog(patient))
+	var out []HealthAccessEvent
+	for it.Next() {
+		var ev HealthAccessEvent
+		if err := json.Unmarshal(it.Value(), &ev); err == nil {
+			out = append(out, ev)
+		}
+	}
+	return out, it.Error()
+}
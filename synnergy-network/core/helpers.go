@@ -23,6 +23,7 @@ func InitLedger(path string) error {
 		globalLedger, err = OpenLedger(path)
 		if err == nil {
 			InitTxDistributor(globalLedger)
+			_, err = InitSanctionsRegistry(globalLedger)
 		}
 	})
 	return err
@@ -18,6 +18,13 @@ type HighAvailability struct {
 	changer ViewChanger
 	mu      sync.RWMutex
 	standby map[Address]struct{}
+
+	// WAL replication state, see ha_replication.go.
+	cfg       HAReplicationConfig
+	epoch     uint64
+	role      string
+	primary   string
+	following context.CancelFunc
 }
 
 // NewHighAvailability wires the HA service. Replicator or ViewChanger may be
@@ -67,11 +74,23 @@ func (ha *HighAvailability) HA_Sync(ctx context.Context) error {
 
 // HA_Promote triggers a view change so the provided standby becomes the leader.
 // The address must already be registered. The actual leader election is
-// performed by the consensus/ViewChanger implementation.
+// performed by the consensus/ViewChanger implementation. Promoting this
+// node while it follows a primary's WAL stream also takes over replication
+// under a new fencing epoch.
 func (ha *HighAvailability) HA_Promote(addr Address) error {
 	ha.mu.RLock()
 	_, ok := ha.standby[addr]
+	self := ha.role == HARoleStandby && addr == ha.cfg.Self
 	ha.mu.RUnlock()
+	if self {
+		if err := ha.promoteSelf(); err != nil {
+			return err
+		}
+		if ha.changer != nil {
+			ha.changer.ProposeViewChange("promote standby")
+		}
+		return nil
+	}
 	if !ok {
 		return fmt.Errorf("standby not registered")
 	}
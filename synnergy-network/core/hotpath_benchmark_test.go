@@ -0,0 +1,215 @@
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"log"
+	"path/filepath"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/wasmerio/wasmer-go/wasmer"
+)
+
+// Benchmarks for the ledger and VM hot paths. `make bench-compare` runs
+// them against the stored baseline and fails on regressions; keep names
+// stable so results stay comparable across commits.
+
+// quietLogs silences per-block and per-opcode logging for the duration of
+// a benchmark.
+func quietLogs(b *testing.B) {
+	out, stdOut := logrus.StandardLogger().Out, log.Writer()
+	logrus.SetOutput(io.Discard)
+	log.SetOutput(io.Discard)
+	b.Cleanup(func() {
+		logrus.SetOutput(out)
+		log.SetOutput(stdOut)
+	})
+}
+
+func benchLedger(b *testing.B) *Ledger {
+	dir := b.TempDir()
+	led, err := NewLedger(LedgerConfig{
+		WALPath:          filepath.Join(dir, "wal.log"),
+		SnapshotPath:     filepath.Join(dir, "snap.json"),
+		SnapshotInterval: 1 << 30,
+		ArchivePath:      filepath.Join(dir, "archive.gz"),
+	})
+	if err != nil {
+		b.Fatalf("ledger: %v", err)
+	}
+	return led
+}
+
+// benchBlock builds a block of n transfers, each spending one UTXO, paying
+// two outputs and touching one state key.
+func benchBlock(height uint64, n int) *Block {
+	blk := &Block{Header: BlockHeader{Height: height}}
+	for i := 0; i < n; i++ {
+		var from, to Address
+		binary.BigEndian.PutUint64(from[:], height)
+		binary.BigEndian.PutUint64(to[8:], uint64(i))
+		tx := &Transaction{
+			From:   from,
+			To:     to,
+			Value:  uint64(i + 1),
+			Nonce:  height,
+			Inputs: []TxInput{{TxID: sha256.Sum256(to[:]), Index: 0}},
+			Outputs: []TxOutput{
+				{Address: to, Amount: uint64(i + 1)},
+				{Address: from, Amount: 1},
+			},
+			StateChanges: map[string][]byte{fmt.Sprintf("acct:%x", to): {byte(i)}},
+		}
+		tx.HashTx()
+		blk.Transactions = append(blk.Transactions, tx)
+	}
+	return blk
+}
+
+func BenchmarkLedgerApplyBlock(b *testing.B) {
+	for _, n := range []int{10, 100} {
+		b.Run(fmt.Sprintf("txs=%d", n), func(b *testing.B) {
+			quietLogs(b)
+			led := benchLedger(b)
+			blocks := make([]*Block, b.N)
+			for i := range blocks {
+				blocks[i] = benchBlock(uint64(i), n)
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := led.applyBlock(blocks[i], false); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func benchLeaves(n int) [][]byte {
+	leaves := make([][]byte, n)
+	for i := range leaves {
+		h := sha256.Sum256([]byte{byte(i), byte(i >> 8), byte(i >> 16)})
+		leaves[i] = h[:]
+	}
+	return leaves
+}
+
+func BenchmarkMerkleRoot(b *testing.B) {
+	for _, n := range []int{64, 1024, 8192} {
+		leaves := benchLeaves(n)
+		b.Run(fmt.Sprintf("rollup/leaves=%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := MerkleRoot(leaves); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("security/leaves=%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := ComputeMerkleRoot(leaves); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkAMMSwapOut measures the pricing step of AMM.Swap for both curves.
+func BenchmarkAMMSwapOut(b *testing.B) {
+	pools := []struct {
+		name string
+		pool *Pool
+	}{
+		{"constant_product", &Pool{kind: PoolConstantProduct, feeBps: defaultFeeBps}},
+		{"stable_swap", &Pool{kind: PoolStableSwap, amp: 100, feeBps: 4}},
+	}
+	for _, p := range pools {
+		b.Run(p.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				in := uint64(1_000 + i%10_000)
+				if _, err := p.pool.outGivenIn(50_000_000, 49_000_000, in); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// Reference light-VM contracts: a counter that loads, increments and stores
+// a slot, and an emitter that logs one event per call.
+var (
+	lightCounter = []byte{
+		byte(PUSH), 1, 'n', byte(LOAD),
+		byte(PUSH), 1, 1, byte(ADD),
+		byte(PUSH), 1, 'n', byte(STORE),
+		byte(PUSH), 1, 1, byte(RET),
+	}
+	lightEmitter = []byte{
+		byte(PUSH), 8, 't', 'r', 'a', 'n', 's', 'f', 'e', 'r', byte(LOG),
+		byte(PUSH), 1, 1, byte(RET),
+	}
+)
+
+func BenchmarkLightVMExecute(b *testing.B) {
+	for _, tc := range []struct {
+		name string
+		code []byte
+	}{{"counter", lightCounter}, {"emitter", lightEmitter}} {
+		b.Run(tc.name, func(b *testing.B) {
+			quietLogs(b)
+			st, _ := NewInMemory()
+			ctx := &VMContext{GasLimit: 10_000_000}
+			if err := st.Set(ctx.TxHash[:], []byte("n"), []byte{0}); err != nil {
+				b.Fatal(err)
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				rec, err := NewLightVM(st, NewGasMeter(10_000_000)).Execute(tc.code, ctx)
+				if err != nil || !rec.Status {
+					b.Fatalf("execute: %v %+v", err, rec)
+				}
+			}
+		})
+	}
+}
+
+// heavyLoop is a reference WASM contract whose _start counts to 1000,
+// storing the counter to linear memory on every iteration.
+var heavyLoop = []byte{
+	0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00, // magic, version
+	0x01, 0x04, 0x01, 0x60, 0x00, 0x00, // type: () -> ()
+	0x03, 0x02, 0x01, 0x00, // func 0
+	0x05, 0x03, 0x01, 0x00, 0x01, // memory, 1 page
+	0x07, 0x13, 0x02, // exports
+	0x06, 'm', 'e', 'm', 'o', 'r', 'y', 0x02, 0x00,
+	0x06, '_', 's', 't', 'a', 'r', 't', 0x00, 0x00,
+	0x0a, 0x24, 0x01, 0x22, // code
+	0x01, 0x01, 0x7f, // local i32
+	0x02, 0x40, 0x03, 0x40, // block, loop
+	0x20, 0x00, 0x41, 0xe8, 0x07, 0x4e, 0x0d, 0x01, // br_if i >= 1000
+	0x41, 0x00, 0x20, 0x00, 0x36, 0x02, 0x00, // mem[0] = i
+	0x20, 0x00, 0x41, 0x01, 0x6a, 0x21, 0x00, // i++
+	0x0c, 0x00, 0x0b, 0x0b, 0x0b,
+}
+
+func BenchmarkHeavyVMExecute(b *testing.B) {
+	quietLogs(b)
+	st, _ := NewInMemory()
+	engine := wasmer.NewEngine()
+	ctx := &VMContext{GasLimit: 10_000_000}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		rec, err := NewHeavyVM(st, NewGasMeter(10_000_000), engine).Execute(heavyLoop, ctx)
+		if err != nil || !rec.Status {
+			b.Fatalf("execute: %v %+v", err, rec)
+		}
+	}
+}
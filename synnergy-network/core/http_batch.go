@@ -0,0 +1,212 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// BatchConfig bounds the batch endpoint of an HTTP API. Zero fields take
+// the defaults of DefaultBatchConfig.
+type BatchConfig struct {
+	MaxCalls     int   // calls accepted per batch
+	Concurrency  int   // calls executed at once
+	MaxBodyBytes int64 // size of the whole batch request
+}
+
+// DefaultBatchConfig returns the limits used when none are configured.
+func DefaultBatchConfig() BatchConfig {
+	return BatchConfig{MaxCalls: 100, Concurrency: 8, MaxBodyBytes: 4 << 20}
+}
+
+func (c BatchConfig) withDefaults() BatchConfig {
+	d := DefaultBatchConfig()
+	if c.MaxCalls <= 0 {
+		c.MaxCalls = d.MaxCalls
+	}
+	if c.Concurrency <= 0 {
+		c.Concurrency = d.Concurrency
+	}
+	if c.MaxBodyBytes <= 0 {
+		c.MaxBodyBytes = d.MaxBodyBytes
+	}
+	return c
+}
+
+// BatchCall is one entry of a batch request. Method defaults to GET; Body is
+// sent as the JSON body of the call. ID is echoed back unchanged so clients
+// can match results without relying on order.
+type BatchCall struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Method string          `json:"method,omitempty"`
+	Path   string          `json:"path"`
+	Body   json.RawMessage `json:"body,omitempty"`
+}
+
+// BatchResult is the outcome of a BatchCall: the HTTP status and JSON body
+// the call would have received on its own. Failed calls carry the shared
+// error envelope in Body; they do not fail the batch.
+type BatchResult struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Status int             `json:"status"`
+	Body   json.RawMessage `json:"body,omitempty"`
+}
+
+type batchCtxKey struct{}
+
+// BatchHandler serves POST requests whose body is a JSON array of BatchCall
+// by dispatching every call to next, at most cfg.Concurrency at a time, and
+// answering with the BatchResult array in request order. Batches larger
+// than cfg.MaxCalls are rejected whole with status 413; calls may only
+// use GET or POST and may not nest another batch. Request headers such as
+// Authorization are forwarded to every call; conditional headers are not,
+// so every call answers with a full body.
+func BatchHandler(next http.Handler, cfg BatchConfig, module string) http.Handler {
+	cfg = cfg.withDefaults()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		if r.Context().Value(batchCtxKey{}) != nil {
+			WriteHTTPError(w, http.StatusBadRequest, module, NewError(CodeInvalidArgument, module, "batches cannot be nested"))
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, cfg.MaxBodyBytes)
+		defer r.Body.Close()
+		var calls []BatchCall
+		if err := json.NewDecoder(r.Body).Decode(&calls); err != nil {
+			var mbe *http.MaxBytesError
+			if errors.As(err, &mbe) {
+				WriteHTTPError(w, http.StatusRequestEntityTooLarge, module, NewError(CodeInvalidArgument, module, "batch exceeds %d bytes", cfg.MaxBodyBytes))
+				return
+			}
+			WriteHTTPError(w, http.StatusBadRequest, module, NewError(CodeInvalidArgument, module, "batch must be a JSON array of calls: %v", err))
+			return
+		}
+		if len(calls) == 0 {
+			WriteHTTPError(w, http.StatusBadRequest, module, NewError(CodeInvalidArgument, module, "empty batch"))
+			return
+		}
+		if len(calls) > cfg.MaxCalls {
+			WriteHTTPError(w, http.StatusRequestEntityTooLarge, module,
+				NewError(CodeInvalidArgument, module, "batch of %d calls exceeds the limit of %d", len(calls), cfg.MaxCalls).WithDetail("max_calls", cfg.MaxCalls))
+			return
+		}
+		ctx := context.WithValue(r.Context(), batchCtxKey{}, true)
+		results := make([]BatchResult, len(calls))
+		sem := make(chan struct{}, cfg.Concurrency)
+		var wg sync.WaitGroup
+		for i := range calls {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func(i int) {
+				defer func() { <-sem; wg.Done() }()
+				results[i] = runBatchCall(ctx, next, r, calls[i], module)
+			}(i)
+		}
+		wg.Wait()
+		writeJSON(w, results)
+	})
+}
+
+// runBatchCall executes c against next as a request derived from the batch
+// request parent and captures its response.
+func runBatchCall(ctx context.Context, next http.Handler, parent *http.Request, c BatchCall, module string) BatchResult {
+	res := BatchResult{ID: c.ID}
+	method := strings.ToUpper(c.Method)
+	if method == "" {
+		method = http.MethodGet
+	}
+	u, err := url.ParseRequestURI(c.Path)
+	switch {
+	case method != http.MethodGet && method != http.MethodPost:
+		err = NewError(CodeInvalidArgument, module, "method %s not allowed in a batch", c.Method)
+	case err != nil || !strings.HasPrefix(c.Path, "/"):
+		err = NewError(CodeInvalidArgument, module, "invalid path %q", c.Path)
+	}
+	if err != nil {
+		res.Status = http.StatusBadRequest
+		res.Body, _ = json.Marshal(ErrorEnvelope{Error: ClassifyError(err)})
+		return res
+	}
+	req := (&http.Request{
+		Method:        method,
+		URL:           u,
+		Proto:         parent.Proto,
+		ProtoMajor:    parent.ProtoMajor,
+		ProtoMinor:    parent.ProtoMinor,
+		Header:        http.Header{},
+		Body:          io.NopCloser(bytes.NewReader(c.Body)),
+		ContentLength: int64(len(c.Body)),
+		Host:          parent.Host,
+		RemoteAddr:    parent.RemoteAddr,
+		RequestURI:    c.Path,
+	}).WithContext(ctx)
+	for k, v := range parent.Header {
+		switch k {
+		case "Content-Length", "If-None-Match", "If-Modified-Since":
+		default:
+			req.Header[k] = v
+		}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	rec := &batchRecorder{header: http.Header{}}
+	func() {
+		defer func() {
+			if p := recover(); p != nil {
+				rec.reset(http.StatusInternalServerError)
+				_ = json.NewEncoder(&rec.body).Encode(ErrorEnvelope{Error: NewError(CodeInternal, module, "call panicked: %v", p)})
+			}
+		}()
+		next.ServeHTTP(rec, req)
+	}()
+	res.Status = rec.status
+	if res.Status == 0 {
+		res.Status = http.StatusOK
+	}
+	body := bytes.TrimSpace(rec.body.Bytes())
+	switch {
+	case len(body) == 0:
+	case json.Valid(body):
+		res.Body = body
+	default:
+		res.Body, _ = json.Marshal(string(body))
+	}
+	return res
+}
+
+// batchRecorder is the http.ResponseWriter handed to batched calls.
+// Streaming endpoints that need to hijack the connection fail cleanly
+// because it does not implement http.Hijacker.
+type batchRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (b *batchRecorder) Header() http.Header { return b.header }
+
+func (b *batchRecorder) WriteHeader(status int) {
+	if b.status == 0 {
+		b.status = status
+	}
+}
+
+func (b *batchRecorder) Write(p []byte) (int, error) {
+	if b.status == 0 {
+		b.status = http.StatusOK
+	}
+	return b.body.Write(p)
+}
+
+func (b *batchRecorder) reset(status int) {
+	b.status = status
+	b.body.Reset()
+}
@@ -0,0 +1,189 @@
+package core
+
+// invariants.go – cross-module state invariants.
+//
+// Modules register named checks with RegisterInvariant, usually from an
+// init function in the module's own file. A check inspects live state and
+// returns an error describing the first inconsistency it finds, e.g. a
+// token whose recorded supply differs from the sum of its balances.
+//
+// RunInvariants executes the registered checks on demand (`synnergy
+// doctor`); StartInvariantVerifier runs them in the background every N
+// blocks and calls a halt hook when one fails.
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// InvariantCheck inspects state and reports a violation as an error.
+type InvariantCheck func() error
+
+// Invariant is a registered check. Its full name is "<module>/<name>".
+type Invariant struct {
+	Module string
+	Name   string
+	Check  InvariantCheck
+}
+
+// FullName returns the "<module>/<name>" identifier of the invariant.
+func (inv Invariant) FullName() string { return inv.Module + "/" + inv.Name }
+
+// InvariantResult is the outcome of one check.
+type InvariantResult struct {
+	Invariant string        `json:"invariant"`
+	OK        bool          `json:"ok"`
+	Error     string        `json:"error,omitempty"`
+	Took      time.Duration `json:"took"`
+}
+
+// InvariantReport summarises one run over the registered invariants.
+type InvariantReport struct {
+	Height     uint64            `json:"height"`
+	Time       time.Time         `json:"time"`
+	Results    []InvariantResult `json:"results"`
+	Violations int               `json:"violations"`
+}
+
+// OK reports whether every check passed.
+func (r InvariantReport) OK() bool { return r.Violations == 0 }
+
+// Failed returns the results of the checks that did not pass.
+func (r InvariantReport) Failed() []InvariantResult {
+	var out []InvariantResult
+	for _, res := range r.Results {
+		if !res.OK {
+			out = append(out, res)
+		}
+	}
+	return out
+}
+
+var (
+	invariantMu sync.RWMutex
+	invariants  = make(map[string]Invariant)
+)
+
+// RegisterInvariant adds a check under module/name, replacing any check
+// previously registered with the same name.
+func RegisterInvariant(module, name string, check InvariantCheck) {
+	inv := Invariant{Module: module, Name: name, Check: check}
+	invariantMu.Lock()
+	invariants[inv.FullName()] = inv
+	invariantMu.Unlock()
+}
+
+// Invariants lists the registered checks sorted by full name.
+func Invariants() []Invariant {
+	invariantMu.RLock()
+	out := make([]Invariant, 0, len(invariants))
+	for _, inv := range invariants {
+		out = append(out, inv)
+	}
+	invariantMu.RUnlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].FullName() < out[j].FullName() })
+	return out
+}
+
+// RunInvariants executes the registered checks. When filters are given only
+// invariants whose module or full name matches one of them run. A panicking
+// check counts as a violation.
+func RunInvariants(filters ...string) InvariantReport {
+	rep := InvariantReport{Height: currentHeight(), Time: time.Now().UTC()}
+	for _, inv := range Invariants() {
+		if !invariantSelected(inv, filters) {
+			continue
+		}
+		start := time.Now()
+		err := runInvariant(inv)
+		res := InvariantResult{Invariant: inv.FullName(), OK: err == nil, Took: time.Since(start)}
+		if err != nil {
+			res.Error = err.Error()
+			rep.Violations++
+		}
+		rep.Results = append(rep.Results, res)
+	}
+	return rep
+}
+
+func invariantSelected(inv Invariant, filters []string) bool {
+	if len(filters) == 0 {
+		return true
+	}
+	for _, f := range filters {
+		if f == inv.Module || f == inv.FullName() {
+			return true
+		}
+	}
+	return false
+}
+
+func runInvariant(inv Invariant) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("check panicked: %v", r)
+		}
+	}()
+	return inv.Check()
+}
+
+// InvariantVerifierConfig controls the background verifier. Every is the
+// block interval between runs; Poll is how often the chain height is read.
+type InvariantVerifierConfig struct {
+	Every uint64
+	Poll  time.Duration
+	Halt  bool
+}
+
+// StartInvariantVerifier runs the registered invariants each time the chain
+// height advances by cfg.Every blocks. Violations are logged and broadcast
+// on "invariant:violation"; when cfg.Halt is set onHalt is called with the
+// failing report and the verifier stops.
+func StartInvariantVerifier(ctx context.Context, cfg InvariantVerifierConfig, onHalt func(InvariantReport)) {
+	if cfg.Every == 0 {
+		return
+	}
+	if cfg.Poll <= 0 {
+		cfg.Poll = 5 * time.Second
+	}
+	go func() {
+		ticker := time.NewTicker(cfg.Poll)
+		defer ticker.Stop()
+		last := currentHeight()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				h := currentHeight()
+				if h < last+cfg.Every {
+					continue
+				}
+				last = h
+				rep := RunInvariants()
+				if rep.OK() {
+					continue
+				}
+				names := make([]string, 0, rep.Violations)
+				for _, res := range rep.Failed() {
+					names = append(names, res.Invariant)
+					logrus.Errorf("invariant %s violated at height %d: %s", res.Invariant, rep.Height, res.Error)
+				}
+				_ = Broadcast("invariant:violation", []byte(strings.Join(names, ",")))
+				if cfg.Halt {
+					logrus.Errorf("halting after %d invariant violations at height %d", rep.Violations, rep.Height)
+					if onHalt != nil {
+						onHalt(rep)
+					}
+					return
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,537 @@
+package core
+
+// invoices.go – payment requests and payment detection webhooks.
+//
+// A merchant creates an invoice for an amount of the coin or a token with a
+// request signed as described in webhook_registration.go. The invoice
+// carries a reference, sha256(id ‖ memo), that the payer puts in the
+// transaction payload. The payment URI encodes everything a wallet needs and
+// doubles as the QR payload:
+//
+//	synnergy:<pay-to>?amount=<n>&token=<id>&ref=<hex>&exp=<unix>&invoice=<id>
+//
+// An InvoiceWatcher scans new blocks from a BlockSource. A transfer to the
+// pay-to address of at least the amount, carrying the reference, in a block
+// before the expiry marks the invoice detected; once the block is buried
+// under the invoice's confirmation depth it is paid. Detection is undone
+// if the block is replaced by a reorganisation.
+//
+// Each state change queues one webhook event with a deterministic ID, so a
+// change is never queued twice. Events are signed as described in
+// webhook_signing.go and retried with exponential backoff until the
+// endpoint answers 2xx.
+//
+// Ledger layout:
+//   invoice:inv:<id>         -> invoice and webhook secret
+//   invoice:ref:<ref hex>    -> invoice ID
+//   invoice:hook:<event id>  -> webhook delivery
+//   invoice:scan             -> last scanned height
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// Invoice states.
+const (
+	InvoiceOpen     = "open"
+	InvoiceDetected = "detected"
+	InvoicePaid     = "paid"
+	InvoiceExpired  = "expired"
+)
+
+// Webhook event types.
+const (
+	InvoiceEventDetected = "invoice.detected"
+	InvoiceEventPaid     = "invoice.paid"
+	InvoiceEventExpired  = "invoice.expired"
+)
+
+const (
+	// InvoiceDefaultConfirmations is the depth used when a request sets none.
+	InvoiceDefaultConfirmations = 6
+	// InvoiceDefaultTTL is the validity used when a request sets none.
+	InvoiceDefaultTTL = time.Hour
+	// invoiceWebhookAttempts bounds the deliveries of one event.
+	invoiceWebhookAttempts = 12
+)
+
+var ErrInvoiceNotFound = errors.New("invoice not found")
+
+var invoiceMu sync.Mutex
+
+// InvoiceRequest describes an invoice to create.
+type InvoiceRequest struct {
+	Merchant      Address       `json:"merchant"`
+	PayTo         Address       `json:"pay_to"` // default: the merchant
+	Amount        uint64        `json:"amount"`
+	Token         TokenID       `json:"token"` // 0 for the coin
+	Memo          string        `json:"memo,omitempty"`
+	TTL           time.Duration `json:"ttl"`           // default InvoiceDefaultTTL
+	Confirmations uint64        `json:"confirmations"` // default InvoiceDefaultConfirmations
+	Webhook       string        `json:"webhook,omitempty"`
+	Secret        string        `json:"secret,omitempty"` // HMAC key for webhook payloads
+	Nonce         uint64        `json:"nonce"`            // the merchant's RegistrationNonce
+}
+
+// Invoice is a payment request and its detection state.
+type Invoice struct {
+	ID            string    `json:"id"`
+	Merchant      Address   `json:"merchant"`
+	PayTo         Address   `json:"pay_to"`
+	Amount        uint64    `json:"amount"`
+	Token         TokenID   `json:"token"`
+	MemoHash      string    `json:"memo_hash"` // payment reference, hex
+	Created       time.Time `json:"created"`
+	Expires       time.Time `json:"expires"`
+	Confirmations uint64    `json:"confirmations"`
+	Webhook       string    `json:"webhook,omitempty"`
+	Status        string    `json:"status"`
+	TxHash        string    `json:"tx_hash,omitempty"`
+	Height        uint64    `json:"height,omitempty"`
+	Received      uint64    `json:"received,omitempty"`
+	URI           string    `json:"uri"`
+}
+
+// invoiceRecord keeps the webhook secret out of API responses.
+type invoiceRecord struct {
+	Invoice
+	Secret string `json:"secret,omitempty"`
+}
+
+// InvoiceEvent is the body of a webhook call.
+type InvoiceEvent struct {
+	ID      string    `json:"id"`
+	Type    string    `json:"type"`
+	Created time.Time `json:"created"`
+	Invoice Invoice   `json:"invoice"`
+}
+
+// invoiceDelivery is a queued webhook call.
+type invoiceDelivery struct {
+	EventID   string    `json:"event_id"`
+	Seq       uint64    `json:"seq"` // queue order; events go out in it
+	Type      string    `json:"type"`
+	Invoice   string    `json:"invoice"`
+	URL       string    `json:"url"`
+	Body      []byte    `json:"body"`
+	Attempts  int       `json:"attempts"`
+	Next      time.Time `json:"next"`
+	Delivered bool      `json:"delivered"`
+	LastError string    `json:"last_error,omitempty"`
+}
+
+// BlockSource supplies blocks to an InvoiceWatcher; *Ledger is one.
+type BlockSource interface {
+	LastHeight() uint64
+	GetBlock(height uint64) (*Block, error)
+}
+
+func invoiceKey(id string) []byte          { return []byte("invoice:inv:" + id) }
+func invoiceRefKey(ref string) []byte      { return []byte("invoice:ref:" + ref) }
+func invoiceHookKey(eventID string) []byte { return []byte("invoice:hook:" + eventID) }
+
+// InvoiceReference returns the payment reference of an invoice.
+func InvoiceReference(id, memo string) Hash {
+	return sha256.Sum256([]byte(id + "\x00" + memo))
+}
+
+// PaymentURI returns the payment URI of inv, also used as its QR payload.
+func (inv *Invoice) PaymentURI() string {
+	q := url.Values{}
+	q.Set("amount", strconv.FormatUint(inv.Amount, 10))
+	if inv.Token != 0 {
+		q.Set("token", strconv.FormatUint(uint64(inv.Token), 10))
+	}
+	q.Set("ref", inv.MemoHash)
+	q.Set("exp", strconv.FormatInt(inv.Expires.Unix(), 10))
+	q.Set("invoice", inv.ID)
+	return "synnergy:" + inv.PayTo.Hex() + "?" + q.Encode()
+}
+
+// InvoiceRequestTypedData returns the document the merchant signs to
+// create the invoice req describes. The webhook secret is not part of it.
+func InvoiceRequestTypedData(req InvoiceRequest) TypedData {
+	return registrationTypedData("InvoiceRequest", []apitypes.Type{
+		{Name: "merchant", Type: "address"},
+		{Name: "payTo", Type: "address"},
+		{Name: "amount", Type: "uint256"},
+		{Name: "token", Type: "uint256"},
+		{Name: "memo", Type: "string"},
+		{Name: "ttl", Type: "uint256"},
+		{Name: "confirmations", Type: "uint256"},
+		{Name: "webhook", Type: "string"},
+		{Name: "nonce", Type: "uint256"},
+	}, apitypes.TypedDataMessage{
+		"merchant":      req.Merchant.Hex(),
+		"payTo":         req.PayTo.Hex(),
+		"amount":        strconv.FormatUint(req.Amount, 10),
+		"token":         strconv.FormatUint(uint64(req.Token), 10),
+		"memo":          req.Memo,
+		"ttl":           strconv.FormatInt(int64(req.TTL/time.Second), 10),
+		"confirmations": strconv.FormatUint(req.Confirmations, 10),
+		"webhook":       req.Webhook,
+		"nonce":         strconv.FormatUint(req.Nonce, 10),
+	})
+}
+
+// CreateInvoice validates req and stores a new open invoice. sig is the
+// merchant's 96-byte signature ‖ public key over InvoiceRequestTypedData(req).
+func CreateInvoice(req InvoiceRequest, sig []byte) (*Invoice, error) {
+	if req.Merchant == AddressZero {
+		return nil, NewError(CodeInvalidArgument, "invoices", "merchant required")
+	}
+	if req.Amount == 0 {
+		return nil, NewError(CodeInvalidArgument, "invoices", "amount must be >0")
+	}
+	if req.TTL < 0 || req.TTL%time.Second != 0 {
+		return nil, NewError(CodeInvalidArgument, "invoices", "ttl must be whole seconds")
+	}
+	if req.Webhook != "" {
+		if err := ValidateWebhookURL(req.Webhook); err != nil {
+			return nil, err
+		}
+		if req.Secret == "" {
+			return nil, NewError(CodeInvalidArgument, "invoices", "webhook secret required")
+		}
+	}
+	if err := spendRegistration(req.Merchant, req.Nonce, InvoiceRequestTypedData(req), sig); err != nil {
+		return nil, err
+	}
+	if req.PayTo == AddressZero {
+		req.PayTo = req.Merchant
+	}
+	if req.TTL <= 0 {
+		req.TTL = InvoiceDefaultTTL
+	}
+	if req.Confirmations == 0 {
+		req.Confirmations = InvoiceDefaultConfirmations
+	}
+	now := time.Now().UTC()
+	inv := Invoice{
+		ID:            uuid.New().String(),
+		Merchant:      req.Merchant,
+		PayTo:         req.PayTo,
+		Amount:        req.Amount,
+		Token:         req.Token,
+		Created:       now,
+		Expires:       now.Add(req.TTL),
+		Confirmations: req.Confirmations,
+		Webhook:       req.Webhook,
+		Status:        InvoiceOpen,
+	}
+	ref := InvoiceReference(inv.ID, req.Memo)
+	inv.MemoHash = hex.EncodeToString(ref[:])
+	inv.URI = inv.PaymentURI()
+
+	invoiceMu.Lock()
+	defer invoiceMu.Unlock()
+	if err := saveInvoice(&invoiceRecord{Invoice: inv, Secret: req.Secret}); err != nil {
+		return nil, err
+	}
+	if err := CurrentStore().Set(invoiceRefKey(inv.MemoHash), []byte(inv.ID)); err != nil {
+		return nil, err
+	}
+	return &inv, nil
+}
+
+// GetInvoice returns an invoice by ID.
+func GetInvoice(id string) (*Invoice, error) {
+	rec, err := loadInvoice(id)
+	if err != nil {
+		return nil, err
+	}
+	return &rec.Invoice, nil
+}
+
+// ListInvoices returns the invoices of merchant, or all invoices when
+// merchant is the zero address.
+func ListInvoices(merchant Address) ([]Invoice, error) {
+	it := CurrentStore().Iterator([]byte("invoice:inv:"), nil)
+	defer it.Close()
+	var out []Invoice
+	for it.Next() {
+		var rec invoiceRecord
+		if err := json.Unmarshal(it.Value(), &rec); err != nil {
+			continue
+		}
+		if merchant == AddressZero || rec.Merchant == merchant {
+			out = append(out, rec.Invoice)
+		}
+	}
+	return out, it.Error()
+}
+
+func loadInvoice(id string) (*invoiceRecord, error) {
+	raw, err := CurrentStore().Get(invoiceKey(id))
+	if err != nil || raw == nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvoiceNotFound, id)
+	}
+	var rec invoiceRecord
+	if err := json.Unmarshal(raw, &rec); err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
+
+func saveInvoice(rec *invoiceRecord) error {
+	data, _ := json.Marshal(rec)
+	return CurrentStore().Set(invoiceKey(rec.ID), data)
+}
+
+// InvoiceWatcher detects invoice payments in new blocks and delivers the
+// webhooks.
+type InvoiceWatcher struct {
+	src    BlockSource
+	client *http.Client
+	logger *logrus.Entry
+}
+
+// NewInvoiceWatcher returns a watcher reading blocks from src.
+func NewInvoiceWatcher(src BlockSource) *InvoiceWatcher {
+	return &InvoiceWatcher{
+		src:    src,
+		client: newWebhookClient(),
+		logger: ModuleLogger("invoices"),
+	}
+}
+
+// Start scans and delivers every interval until ctx is cancelled.
+func (w *InvoiceWatcher) Start(ctx context.Context, interval time.Duration) {
+	go func() {
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-t.C:
+				if err := w.Scan(now.UTC()); err != nil {
+					w.logger.Warnf("scan: %v", err)
+				}
+				w.Deliver(ctx, now.UTC())
+			}
+		}
+	}()
+}
+
+// Scan matches the blocks added since the last scan against open invoices,
+// then confirms, reverts and expires invoices as of now.
+func (w *InvoiceWatcher) Scan(now time.Time) error {
+	invoiceMu.Lock()
+	defer invoiceMu.Unlock()
+	head := w.src.LastHeight()
+	// the first scan starts at the head; invoices are paid after creation
+	from := head
+	if raw, err := CurrentStore().Get([]byte("invoice:scan")); err == nil && len(raw) == 8 {
+		from = binary.BigEndian.Uint64(raw) + 1
+	}
+	for h := from; h <= head; h++ {
+		b, err := w.src.GetBlock(h)
+		if err != nil {
+			return fmt.Errorf("block %d: %w", h, err)
+		}
+		w.matchBlock(b)
+		if err := CurrentStore().Set([]byte("invoice:scan"), uint64ToBytes(h)); err != nil {
+			return err
+		}
+	}
+
+	list, err := w.pending()
+	if err != nil {
+		return err
+	}
+	for _, rec := range list {
+		switch rec.Status {
+		case InvoiceDetected:
+			included, err := w.stillIncluded(rec)
+			if err != nil {
+				w.logger.Warnf("invoice %s: %v", rec.ID, err)
+				continue
+			}
+			if !included {
+				w.logger.Infof("invoice %s: tx %s left the chain, reopening", rec.ID, rec.TxHash)
+				rec.Status, rec.TxHash, rec.Height, rec.Received = InvoiceOpen, "", 0, 0
+				_ = saveInvoice(rec)
+				continue
+			}
+			if head+1 >= rec.Height+rec.Confirmations {
+				rec.Status = InvoicePaid
+				w.transition(rec, InvoiceEventPaid, now)
+			}
+		case InvoiceOpen:
+			if !now.Before(rec.Expires) {
+				rec.Status = InvoiceExpired
+				w.transition(rec, InvoiceEventExpired, now)
+			}
+		}
+	}
+	return nil
+}
+
+// pending loads the invoices still waiting for a payment or confirmation.
+func (w *InvoiceWatcher) pending() ([]*invoiceRecord, error) {
+	it := CurrentStore().Iterator([]byte("invoice:inv:"), nil)
+	defer it.Close()
+	var out []*invoiceRecord
+	for it.Next() {
+		var rec invoiceRecord
+		if err := json.Unmarshal(it.Value(), &rec); err != nil {
+			continue
+		}
+		if rec.Status == InvoiceOpen || rec.Status == InvoiceDetected {
+			out = append(out, &rec)
+		}
+	}
+	return out, it.Error()
+}
+
+func (w *InvoiceWatcher) matchBlock(b *Block) {
+	var at time.Time
+	if b.Header.Timestamp != 0 {
+		at = time.UnixMilli(b.Header.Timestamp).UTC()
+	}
+	for _, tx := range b.Transactions {
+		if len(tx.Payload) != len(Hash{}) {
+			continue
+		}
+		raw, err := CurrentStore().Get(invoiceRefKey(hex.EncodeToString(tx.Payload)))
+		if err != nil || raw == nil {
+			continue
+		}
+		rec, err := loadInvoice(string(raw))
+		if err != nil || rec.Status != InvoiceOpen {
+			continue
+		}
+		if !at.IsZero() && !at.Before(rec.Expires) {
+			continue
+		}
+		paid := invoicePaidBy(rec, tx)
+		if paid < rec.Amount {
+			continue
+		}
+		rec.Status = InvoiceDetected
+		rec.TxHash, rec.Height, rec.Received = tx.IDHex(), b.Header.Height, paid
+		w.transition(rec, InvoiceEventDetected, at)
+	}
+}
+
+// invoicePaidBy sums what tx pays to the invoice's address in its asset.
+func invoicePaidBy(rec *invoiceRecord, tx *Transaction) uint64 {
+	if rec.Token == 0 {
+		if tx.To == rec.PayTo {
+			return tx.Value
+		}
+		return 0
+	}
+	var sum uint64
+	for _, tr := range tx.TokenTransfers {
+		if tr.To == rec.PayTo && tr.Token == rec.Token {
+			sum += tr.Amount
+		}
+	}
+	return sum
+}
+
+// stillIncluded reports whether the detected transaction is still in the
+// block it was seen in.
+func (w *InvoiceWatcher) stillIncluded(rec *invoiceRecord) (bool, error) {
+	b, err := w.src.GetBlock(rec.Height)
+	if err != nil {
+		return false, fmt.Errorf("block %d: %w", rec.Height, err)
+	}
+	for _, tx := range b.Transactions {
+		if tx.IDHex() == rec.TxHash {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// transition saves rec and queues its webhook event, once per event ID.
+func (w *InvoiceWatcher) transition(rec *invoiceRecord, typ string, at time.Time) {
+	if at.IsZero() {
+		at = time.Now().UTC()
+	}
+	if err := saveInvoice(rec); err != nil {
+		w.logger.Warnf("invoice %s: %v", rec.ID, err)
+		return
+	}
+	Broadcast("invoice:"+strings.TrimPrefix(typ, "invoice."), mustJSON(rec.Invoice))
+	if rec.Webhook == "" {
+		return
+	}
+	// a reorg can detect the same invoice twice; the tx keeps the ID apart
+	id := rec.ID + ":" + typ
+	if typ != InvoiceEventExpired {
+		id += ":" + rec.TxHash
+	}
+	if raw, err := CurrentStore().Get(invoiceHookKey(id)); err == nil && raw != nil {
+		return
+	}
+	var seq uint64
+	if raw, err := CurrentStore().Get([]byte("invoice:hookseq")); err == nil && len(raw) == 8 {
+		seq = binary.BigEndian.Uint64(raw) + 1
+	}
+	if err := CurrentStore().Set([]byte("invoice:hookseq"), uint64ToBytes(seq)); err != nil {
+		w.logger.Warnf("invoice %s: queue %s: %v", rec.ID, typ, err)
+		return
+	}
+	body, _ := json.Marshal(InvoiceEvent{ID: id, Type: typ, Created: at, Invoice: rec.Invoice})
+	d := invoiceDelivery{EventID: id, Seq: seq, Type: typ, Invoice: rec.ID, URL: rec.Webhook, Body: body, Next: at}
+	raw, _ := json.Marshal(d)
+	if err := CurrentStore().Set(invoiceHookKey(id), raw); err != nil {
+		w.logger.Warnf("invoice %s: queue %s: %v", rec.ID, typ, err)
+	}
+}
+
+// Deliver POSTs the webhook events due at now in the order they were
+// queued. Failed calls are retried with exponential backoff up to
+// invoiceWebhookAttempts times.
+func (w *InvoiceWatcher) Deliver(ctx context.Context, now time.Time) {
+	it := CurrentStore().Iterator([]byte("invoice:hook:"), nil)
+	var due []invoiceDelivery
+	for it.Next() {
+		var d invoiceDelivery
+		if err := json.Unmarshal(it.Value(), &d); err == nil && !d.Delivered &&
+			d.Attempts < invoiceWebhookAttempts && !now.Before(d.Next) {
+			due = append(due, d)
+		}
+	}
+	it.Close()
+	// the store orders deliveries by event ID; merchants expect an invoice's
+	// events in the order they happened
+	sort.Slice(due, func(i, j int) bool { return due[i].Seq < due[j].Seq })
+	for _, d := range due {
+		rec, err := loadInvoice(d.Invoice)
+		if err != nil {
+			continue
+		}
+		d.Attempts++
+		if err := postWebhook(ctx, w.client, d.URL, rec.Secret, d.EventID, d.Type, d.Body, now); err != nil {
+			d.LastError = err.Error()
+			d.Next = now.Add(time.Duration(1<<uint(d.Attempts-1)) * 10 * time.Second)
+			w.logger.Warnf("invoice %s: webhook %s attempt %d: %v", d.Invoice, d.Type, d.Attempts, err)
+		} else {
+			d.Delivered, d.LastError = true, ""
+		}
+		raw, _ := json.Marshal(d)
+		_ = CurrentStore().Set(invoiceHookKey(d.EventID), raw)
+	}
+}
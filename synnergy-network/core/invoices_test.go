@@ -0,0 +1,226 @@
+package core
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// testChain is a BlockSource over a slice of blocks indexed by height.
+type testChain struct {
+	blocks []*Block
+}
+
+func (c *testChain) LastHeight() uint64 { return uint64(len(c.blocks) - 1) }
+
+func (c *testChain) GetBlock(h uint64) (*Block, error) {
+	if h >= uint64(len(c.blocks)) {
+		return nil, fmt.Errorf("no block %d", h)
+	}
+	return c.blocks[h], nil
+}
+
+func (c *testChain) add(at time.Time, txs ...*Transaction) {
+	c.blocks = append(c.blocks, &Block{
+		Header:       BlockHeader{Height: uint64(len(c.blocks)), Timestamp: at.UnixMilli()},
+		Transactions: txs,
+	})
+}
+
+func newInvoiceTest(t *testing.T) (ed25519.PrivateKey, Address) {
+	t.Helper()
+	SetStore(NewInMemoryStore())
+	t.Cleanup(func() { SetStore(nil) })
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	return priv, pubKeyToAddress(pub)
+}
+
+func TestCreateInvoiceRequiresMerchantSignature(t *testing.T) {
+	priv, merchant := newInvoiceTest(t)
+	_, other, _ := ed25519.GenerateKey(nil)
+	req := InvoiceRequest{Merchant: merchant, Amount: 100, Webhook: "https://shop.example.com/hook", Secret: "k"}
+
+	if _, err := CreateInvoice(req, nil); !errors.Is(err, ErrRegistrationSig) {
+		t.Fatalf("unsigned request: %v", err)
+	}
+	if _, err := CreateInvoice(req, signRegistration(t, other, InvoiceRequestTypedData(req))); !errors.Is(err, ErrRegistrationSig) {
+		t.Fatalf("request signed by another key: %v", err)
+	}
+	redirected := req
+	redirected.Webhook = "https://attacker.example.net/hook"
+	if _, err := CreateInvoice(redirected, signRegistration(t, priv, InvoiceRequestTypedData(req))); !errors.Is(err, ErrRegistrationSig) {
+		t.Fatalf("request with a webhook the merchant did not sign: %v", err)
+	}
+	internal := req
+	internal.Webhook = "http://169.254.169.254/latest/meta-data"
+	if _, err := CreateInvoice(internal, signRegistration(t, priv, InvoiceRequestTypedData(internal))); !errors.Is(err, ErrWebhookTarget) {
+		t.Fatalf("signed request for an internal webhook: %v", err)
+	}
+	if RegistrationNonce(merchant) != 0 {
+		t.Fatal("rejected requests spent the merchant's nonce")
+	}
+
+	sig := signRegistration(t, priv, InvoiceRequestTypedData(req))
+	inv, err := CreateInvoice(req, sig)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if inv.Merchant != merchant || inv.PayTo != merchant || inv.Status != InvoiceOpen ||
+		inv.Confirmations != InvoiceDefaultConfirmations || inv.Expires.Sub(inv.Created) != InvoiceDefaultTTL {
+		t.Fatalf("invoice %+v", inv)
+	}
+	if _, err := CreateInvoice(req, sig); !errors.Is(err, ErrRegistrationNonce) {
+		t.Fatalf("replayed request: %v", err)
+	}
+	got, err := GetInvoice(inv.ID)
+	if err != nil || got.URI != inv.PaymentURI() {
+		t.Fatalf("get: %+v %v", got, err)
+	}
+	if raw, _ := json.Marshal(got); containsSecret(raw) {
+		t.Fatalf("invoice response leaks the webhook secret: %s", raw)
+	}
+	list, err := ListInvoices(merchant)
+	if err != nil || len(list) != 1 {
+		t.Fatalf("list: %v %v", list, err)
+	}
+	if list, _ := ListInvoices(Address{0x01}); len(list) != 0 {
+		t.Fatalf("another merchant's list: %v", list)
+	}
+}
+
+func containsSecret(raw []byte) bool {
+	var m map[string]interface{}
+	_ = json.Unmarshal(raw, &m)
+	_, ok := m["secret"]
+	return ok
+}
+
+func TestInvoiceWatcherDetectsConfirmsAndNotifies(t *testing.T) {
+	priv, merchant := newInvoiceTest(t)
+	start := time.Now().UTC()
+	chain := &testChain{}
+	chain.add(start)
+
+	req := InvoiceRequest{Merchant: merchant, Amount: 100, Confirmations: 2,
+		Webhook: "https://shop.example.com/hook", Secret: "whsec"}
+	inv, err := CreateInvoice(req, signRegistration(t, priv, InvoiceRequestTypedData(req)))
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	ref, _ := hex.DecodeString(inv.MemoHash)
+	hooks := &hookRecorder{status: 200}
+	w := NewInvoiceWatcher(chain)
+	w.client = hooks.client()
+	if err := w.Scan(start); err != nil {
+		t.Fatalf("first scan: %v", err)
+	}
+
+	// an underpayment and a payment without the reference do not count
+	chain.add(start,
+		&Transaction{Hash: Hash{1}, To: merchant, Value: 99, Payload: ref},
+		&Transaction{Hash: Hash{2}, To: merchant, Value: 100},
+	)
+	if err := w.Scan(start); err != nil {
+		t.Fatalf("scan: %v", err)
+	}
+	if got, _ := GetInvoice(inv.ID); got.Status != InvoiceOpen {
+		t.Fatalf("status %s after non-matching payments", got.Status)
+	}
+
+	pay := &Transaction{Hash: Hash{3}, To: merchant, Value: 150, Payload: ref}
+	chain.add(start, pay)
+	_ = w.Scan(start)
+	got, _ := GetInvoice(inv.ID)
+	if got.Status != InvoiceDetected || got.Height != 2 || got.Received != 150 || got.TxHash != pay.IDHex() {
+		t.Fatalf("after payment: %+v", got)
+	}
+	chain.add(start)
+	_ = w.Scan(start)
+	if got, _ := GetInvoice(inv.ID); got.Status != InvoicePaid {
+		t.Fatalf("status %s at depth 2", got.Status)
+	}
+
+	w.Deliver(context.Background(), start)
+	w.Deliver(context.Background(), start.Add(time.Hour))
+	if len(hooks.calls) != 2 {
+		t.Fatalf("%d webhook calls, want 2", len(hooks.calls))
+	}
+	for i, typ := range []string{InvoiceEventDetected, InvoiceEventPaid} {
+		c := hooks.calls[i]
+		if c.url != req.Webhook || c.header.Get("X-Synnergy-Event") != typ {
+			t.Fatalf("call %d: %s %s", i, c.url, c.header.Get("X-Synnergy-Event"))
+		}
+		if err := VerifyWebhook("whsec", c.header.Get("X-Synnergy-Timestamp"), c.header.Get("X-Synnergy-Signature"), c.body, 365*24*time.Hour); err != nil {
+			t.Fatalf("call %d signature: %v", i, err)
+		}
+		var ev InvoiceEvent
+		if err := json.Unmarshal(c.body, &ev); err != nil || ev.ID != c.header.Get("X-Synnergy-Event-Id") || ev.Invoice.ID != inv.ID {
+			t.Fatalf("call %d body %s: %v", i, c.body, err)
+		}
+	}
+}
+
+func TestInvoiceWatcherReorgAndExpiry(t *testing.T) {
+	priv, merchant := newInvoiceTest(t)
+	start := time.Now().UTC()
+	chain := &testChain{}
+	chain.add(start)
+
+	req := InvoiceRequest{Merchant: merchant, Amount: 10, TTL: time.Minute,
+		Webhook: "https://shop.example.com/hook", Secret: "whsec"}
+	inv, err := CreateInvoice(req, signRegistration(t, priv, InvoiceRequestTypedData(req)))
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	ref, _ := hex.DecodeString(inv.MemoHash)
+	hooks := &hookRecorder{status: 500}
+	w := NewInvoiceWatcher(chain)
+	w.client = hooks.client()
+	_ = w.Scan(start)
+
+	chain.add(start, &Transaction{Hash: Hash{7}, To: merchant, Value: 10, Payload: ref})
+	_ = w.Scan(start)
+	if got, _ := GetInvoice(inv.ID); got.Status != InvoiceDetected {
+		t.Fatalf("status %s after payment", got.Status)
+	}
+	// the paying block is replaced before it is confirmed
+	chain.blocks[1] = &Block{Header: BlockHeader{Height: 1, Timestamp: start.UnixMilli()}}
+	_ = w.Scan(start)
+	if got, _ := GetInvoice(inv.ID); got.Status != InvoiceOpen || got.TxHash != "" {
+		t.Fatalf("after reorg: %+v", got)
+	}
+
+	_ = w.Scan(start.Add(2 * time.Minute))
+	if got, _ := GetInvoice(inv.ID); got.Status != InvoiceExpired {
+		t.Fatalf("status %s after expiry", got.Status)
+	}
+	// a payment after expiry does not reopen it
+	chain.add(start.Add(3*time.Minute), &Transaction{Hash: Hash{8}, To: merchant, Value: 10, Payload: ref})
+	_ = w.Scan(start.Add(3 * time.Minute))
+	if got, _ := GetInvoice(inv.ID); got.Status != InvoiceExpired {
+		t.Fatalf("status %s after late payment", got.Status)
+	}
+
+	// failing endpoints are retried with backoff, not on every pass
+	now := start.Add(time.Hour)
+	w.Deliver(context.Background(), now)
+	n := len(hooks.calls)
+	if n != 2 {
+		t.Fatalf("%d calls, want detected and expired", n)
+	}
+	w.Deliver(context.Background(), now.Add(time.Second))
+	if len(hooks.calls) != n {
+		t.Fatalf("retried before the backoff: %d calls, then %d", n, len(hooks.calls))
+	}
+	hooks.status = 204
+	w.Deliver(context.Background(), now.Add(time.Minute))
+	w.Deliver(context.Background(), now.Add(time.Hour))
+	if len(hooks.calls) != 2*n {
+		t.Fatalf("%d calls after the endpoint recovered, want %d", len(hooks.calls), 2*n)
+	}
+}
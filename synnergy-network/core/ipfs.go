@@ -3,8 +3,6 @@ package core
 import (
 	"context"
 	"errors"
-	"fmt"
-	"io"
 	"net/http"
 	"sync"
 
@@ -79,23 +77,9 @@ func UnpinFile(ctx context.Context, cid string) error {
 	if ipfsSvc == nil {
 		return errors.New("ipfs service not initialised")
 	}
-	url := fmt.Sprintf("%s/api/v0/pin/rm?arg=%s", ipfsSvc.gateway, cid)
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
-	if err != nil {
-		return err
-	}
-	resp, err := ipfsSvc.client.Do(req)
-	if err != nil {
-		if resp != nil {
-			resp.Body.Close()
-		}
+	if err := ipfsSvc.storage.unpinGateway(ctx, cid); err != nil {
 		return err
 	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		b, _ := io.ReadAll(io.LimitReader(resp.Body, 128))
-		return fmt.Errorf("gateway unpin %d: %s", resp.StatusCode, string(b))
-	}
 	_ = Broadcast("ipfs:unpin", []byte(cid))
 	ipfsSvc.logger.Infof("ipfs unpinned %s", cid)
 	return nil
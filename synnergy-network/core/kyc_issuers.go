@@ -0,0 +1,316 @@
+package core
+
+// kyc_issuers.go – governance-managed registry of KYC credential issuers.
+//
+// Issuer keys (compressed secp256k1) are admitted through a governance
+// proposal carrying the "kyc_issuer_add" change and carry a list of
+// jurisdictions they may attest for and an optional expiry. Issuers may be
+// revoked by governance or, in an emergency, by a regulator. Individual
+// credentials are revoked by their issuer with a signed revocation message.
+// ValidateKYC consults the registry; IsKYCVerified is the read path used by
+// contracts (opcode) and the txpool screener.
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+var (
+	ErrIssuerUnknown      = errors.New("kyc issuer not registered")
+	ErrIssuerRevoked      = errors.New("kyc issuer revoked")
+	ErrIssuerExpired      = errors.New("kyc issuer key expired")
+	ErrIssuerJurisdiction = errors.New("kyc issuer not accredited for jurisdiction")
+	ErrCredentialRevoked  = errors.New("kyc credential revoked")
+)
+
+// KYCIssuer is a registered credential issuer.
+type KYCIssuer struct {
+	PubKey        []byte    `json:"pubkey"` // 33-byte compressed secp256k1
+	Name          string    `json:"name"`
+	Jurisdictions []string  `json:"jurisdictions,omitempty"` // ISO country codes; empty = any
+	Expires       int64     `json:"expires,omitempty"`       // unix seconds; 0 = never
+	Approved      time.Time `json:"approved"`
+	Revoked       bool      `json:"revoked"`
+	RevokedAt     time.Time `json:"revoked_at,omitempty"`
+	RevokeReason  string    `json:"revoke_reason,omitempty"`
+}
+
+// KYCCredential is the on-chain record of an accepted KYC document. Only
+// the document digest is kept; personal data stays with the issuer.
+type KYCCredential struct {
+	ID          [32]byte  `json:"id"`
+	Address     Address   `json:"address"`
+	Issuer      string    `json:"issuer"` // hex pubkey
+	CountryCode string    `json:"cc"`
+	IssuedAt    int64     `json:"issued_at"`
+	Validated   time.Time `json:"validated"`
+}
+
+// KYCRevocation marks a single credential as revoked by its issuer.
+type KYCRevocation struct {
+	Credential [32]byte  `json:"credential"`
+	Issuer     string    `json:"issuer"`
+	Reason     string    `json:"reason,omitempty"`
+	Time       time.Time `json:"time"`
+}
+
+func kycIssuerKey(pk []byte) []byte  { return []byte("kycreg:issuer:" + hex.EncodeToString(pk)) }
+func kycCredKey(addr Address) []byte { return []byte("kycreg:cred:" + addr.Hex()) }
+func kycRevokedKey(id [32]byte) []byte {
+	return []byte("kycreg:revoked:" + hex.EncodeToString(id[:]))
+}
+
+// KYCCredentialID is the digest issuers sign; it also identifies the
+// credential in revocation lists.
+func KYCCredentialID(doc *KYCDocument) [32]byte {
+	raw, _ := json.Marshal(struct {
+		Address     Address
+		CountryCode string
+		IDHash      [32]byte
+		IssuedAt    int64
+	}{doc.Address, doc.CountryCode, doc.IDHash, doc.IssuedAt})
+	return sha256.Sum256(raw)
+}
+
+// KYCRevocationMessage is the payload an issuer signs to revoke a credential.
+func KYCRevocationMessage(id [32]byte) []byte {
+	return []byte("synnergy-kyc-revoke:" + hex.EncodeToString(id[:]))
+}
+
+// ProposeKYCIssuer submits a governance proposal admitting iss to the
+// registry. The issuer becomes active once the proposal is executed.
+func ProposeKYCIssuer(creator Address, iss KYCIssuer, description string) (string, error) {
+	if _, err := secp256k1.ParsePubKey(iss.PubKey); err != nil || len(iss.PubKey) != 33 {
+		return "", errors.New("issuer pubkey must be 33-byte compressed secp256k1")
+	}
+	if iss.Name == "" {
+		return "", errors.New("issuer name required")
+	}
+	raw, _ := json.Marshal(iss)
+	if description == "" {
+		description = fmt.Sprintf("admit KYC issuer %s (%s)", iss.Name, strings.Join(iss.Jurisdictions, ","))
+	}
+	p := &GovProposal{
+		Creator:     creator,
+		Changes:     map[string]string{"kyc_issuer_add": string(raw)},
+		Votes:       make(map[string]bool),
+		Description: description,
+	}
+	if err := SubmitProposal(p); err != nil {
+		return "", err
+	}
+	return p.ID, nil
+}
+
+// ProposeKYCIssuerRevocation submits a governance proposal revoking an issuer.
+func ProposeKYCIssuerRevocation(creator Address, pk []byte, reason string) (string, error) {
+	p := &GovProposal{
+		Creator:     creator,
+		Changes:     map[string]string{"kyc_issuer_revoke": hex.EncodeToString(pk)},
+		Votes:       make(map[string]bool),
+		Description: "revoke KYC issuer " + hex.EncodeToString(pk) + ": " + reason,
+	}
+	if err := SubmitProposal(p); err != nil {
+		return "", err
+	}
+	return p.ID, nil
+}
+
+// RegisterIssuer stores iss as active. It is invoked when a governance
+// proposal carrying "kyc_issuer_add" is executed.
+func (c *ComplianceEngine) RegisterIssuer(iss KYCIssuer) error {
+	if _, err := secp256k1.ParsePubKey(iss.PubKey); err != nil || len(iss.PubKey) != 33 {
+		return errors.New("invalid issuer pubkey")
+	}
+	for i, j := range iss.Jurisdictions {
+		iss.Jurisdictions[i] = strings.ToUpper(strings.TrimSpace(j))
+	}
+	iss.Approved = time.Now().UTC()
+	iss.Revoked, iss.RevokedAt, iss.RevokeReason = false, time.Time{}, ""
+	raw, _ := json.Marshal(iss)
+	if err := c.ledger.SetState(kycIssuerKey(iss.PubKey), raw); err != nil {
+		return err
+	}
+	Broadcast("kyc:issuer_added", raw)
+	return nil
+}
+
+// RevokeIssuer disables an issuer. Every credential it issued stops
+// verifying. Governance calls this with a zero regulator; otherwise the
+// caller must hold the regulator role.
+func (c *ComplianceEngine) RevokeIssuer(regulator Address, pk []byte, reason string) error {
+	if regulator != (Address{}) && !IsRegulator(regulator) {
+		return ErrUnauthorized
+	}
+	iss, err := c.Issuer(pk)
+	if err != nil {
+		return err
+	}
+	iss.Revoked = true
+	iss.RevokedAt = time.Now().UTC()
+	iss.RevokeReason = reason
+	raw, _ := json.Marshal(iss)
+	if err := c.ledger.SetState(kycIssuerKey(pk), raw); err != nil {
+		return err
+	}
+	if am := AuditManagerInstance(); am != nil {
+		_ = am.Log(regulator, "kyc_issuer_revoked", map[string]string{"issuer": hex.EncodeToString(pk), "reason": reason})
+	}
+	Broadcast("kyc:issuer_revoked", raw)
+	return nil
+}
+
+// Issuer fetches a registered issuer.
+func (c *ComplianceEngine) Issuer(pk []byte) (*KYCIssuer, error) {
+	raw, err := c.ledger.GetState(kycIssuerKey(pk))
+	if err != nil || len(raw) == 0 {
+		return nil, ErrIssuerUnknown
+	}
+	var iss KYCIssuer
+	if err := json.Unmarshal(raw, &iss); err != nil {
+		return nil, err
+	}
+	return &iss, nil
+}
+
+// Issuers lists every registered issuer, including revoked ones.
+func (c *ComplianceEngine) Issuers() ([]KYCIssuer, error) {
+	it := c.ledger.PrefixIterator([]byte("kycreg:issuer:"))
+	var out []KYCIssuer
+	for it.Next() {
+		var iss KYCIssuer
+		if err := json.Unmarshal(it.Value(), &iss); err != nil {
+			return nil, err
+		}
+		out = append(out, iss)
+	}
+	return out, it.Error()
+}
+
+// checkIssuer decides whether pk may attest for country at issuedAt.
+// Keys from the static trusted list passed to InitCompliance are accepted
+// for any jurisdiction unless the registry has revoked them.
+func (c *ComplianceEngine) checkIssuer(pk []byte, country string, issuedAt int64) error {
+	iss, err := c.Issuer(pk)
+	if errors.Is(err, ErrIssuerUnknown) {
+		var key [33]byte
+		copy(key[:], pk)
+		if _, ok := c.allowed[key]; ok {
+			return nil
+		}
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	if iss.Revoked {
+		return ErrIssuerRevoked
+	}
+	if iss.Expires > 0 && issuedAt >= iss.Expires {
+		return ErrIssuerExpired
+	}
+	if len(iss.Jurisdictions) == 0 {
+		return nil
+	}
+	for _, j := range iss.Jurisdictions {
+		if strings.EqualFold(j, country) {
+			return nil
+		}
+	}
+	return ErrIssuerJurisdiction
+}
+
+// RevokeCredential adds a credential to the revocation list. sig must be a
+// 64-byte r||s signature by the issuing key over KYCRevocationMessage(id).
+func (c *ComplianceEngine) RevokeCredential(id [32]byte, issuerPK, sig []byte, reason string) error {
+	pk, err := secp256k1.ParsePubKey(issuerPK)
+	if err != nil {
+		return errors.New("invalid issuer pubkey")
+	}
+	r, s, err := decodeSig(sig)
+	if err != nil {
+		return err
+	}
+	h := sha256.Sum256(KYCRevocationMessage(id))
+	if !ecdsa.Verify(pk.ToECDSA(), h[:], r, s) {
+		return errors.New("invalid signature")
+	}
+	rev := KYCRevocation{Credential: id, Issuer: hex.EncodeToString(issuerPK), Reason: reason, Time: time.Now().UTC()}
+	raw, _ := json.Marshal(rev)
+	if err := c.ledger.SetState(kycRevokedKey(id), raw); err != nil {
+		return err
+	}
+	Broadcast("kyc:credential_revoked", raw)
+	return nil
+}
+
+// IsCredentialRevoked reports whether id is on the revocation list.
+func (c *ComplianceEngine) IsCredentialRevoked(id [32]byte) bool {
+	raw, err := c.ledger.GetState(kycRevokedKey(id))
+	return err == nil && len(raw) > 0
+}
+
+// Revocations returns the revocation list, optionally filtered by issuer.
+func (c *ComplianceEngine) Revocations(issuerPK []byte) ([]KYCRevocation, error) {
+	want := hex.EncodeToString(issuerPK)
+	it := c.ledger.PrefixIterator([]byte("kycreg:revoked:"))
+	var out []KYCRevocation
+	for it.Next() {
+		var rev KYCRevocation
+		if err := json.Unmarshal(it.Value(), &rev); err != nil {
+			return nil, err
+		}
+		if len(issuerPK) == 0 || rev.Issuer == want {
+			out = append(out, rev)
+		}
+	}
+	return out, it.Error()
+}
+
+// Credential returns the KYC credential recorded for addr.
+func (c *ComplianceEngine) Credential(addr Address) (*KYCCredential, error) {
+	raw, err := c.ledger.GetState(kycCredKey(addr))
+	if err != nil || len(raw) == 0 {
+		return nil, ErrNotFound
+	}
+	var cred KYCCredential
+	if err := json.Unmarshal(raw, &cred); err != nil {
+		return nil, err
+	}
+	return &cred, nil
+}
+
+// CheckKYC returns nil when addr holds a credential that is not erased,
+// not revoked and whose issuer is still in good standing.
+func (c *ComplianceEngine) CheckKYC(addr Address) error {
+	if blob, _ := c.ledger.GetState(kycKey(addr)); len(blob) != sha256.Size {
+		return errors.New("no KYC")
+	}
+	cred, err := c.Credential(addr)
+	if err != nil {
+		return errors.New("no KYC")
+	}
+	if c.IsCredentialRevoked(cred.ID) {
+		return ErrCredentialRevoked
+	}
+	pk, err := hex.DecodeString(cred.Issuer)
+	if err != nil {
+		return err
+	}
+	return c.checkIssuer(pk, cred.CountryCode, cred.IssuedAt)
+}
+
+// IsKYCVerified reports whether addr currently passes CheckKYC. It returns
+// false when the compliance engine is not initialised.
+func IsKYCVerified(addr Address) bool {
+	c := Compliance()
+	return c != nil && c.ledger != nil && c.CheckKYC(addr) == nil
+}
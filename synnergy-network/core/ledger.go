@@ -14,6 +14,7 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"time"
 )
 
 // NewLedger initializes a ledger, replaying an existing WAL and optionally
@@ -39,9 +40,11 @@ func NewLedger(cfg LedgerConfig) (l *Ledger, err error) {
 		blockIndex:       make(map[Hash]*Block),
 		State:            make(map[string][]byte),
 		UTXO:             make(map[string]UTXO),
+		utxoIdx:          newUTXOIndex(),
 		TxPool:           make(map[string]*Transaction),
 		Contracts:        make(map[string]Contract),
-		TokenBalances:    make(map[string]uint64),
+		Balances:         make(map[BalanceKey]uint64),
+		Supply:           make(map[LedgerAsset]SupplyTotals),
 		lpBalances:       make(map[Address]map[PoolID]uint64),
 		nonces:           make(map[Address]uint64),
 		NodeLocations:    make(map[NodeID]Location),
@@ -51,6 +54,16 @@ func NewLedger(cfg LedgerConfig) (l *Ledger, err error) {
 		archivePath:      cfg.ArchivePath,
 		pruneInterval:    cfg.PruneInterval,
 	}
+	if cfg.StateHistoryPath != "" {
+		if l.archive, err = openStateArchive(cfg.StateHistoryPath); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.Emission != nil {
+		if err = l.initEmission(*cfg.Emission); err != nil {
+			return nil, fmt.Errorf("genesis emission: %w", err)
+		}
+	}
 	if cfg.GenesisBlock != nil {
 		if err = l.applyBlock(cfg.GenesisBlock, false); err != nil {
 			return nil, err
@@ -80,23 +93,41 @@ func NewLedger(cfg LedgerConfig) (l *Ledger, err error) {
 // parameter is treated as a directory containing `ledger.snap` and `ledger.wal`.
 // If no snapshot exists, an empty ledger is created.
 func OpenLedger(path string) (*Ledger, error) {
+	return openLedger(path, "")
+}
+
+// OpenArchiveLedger is OpenLedger for archive nodes: every state version is
+// journaled to `state.history` in path so StateAt can serve any height.
+func OpenArchiveLedger(path string) (*Ledger, error) {
+	return openLedger(path, filepath.Join(path, "state.history"))
+}
+
+func openLedger(path, history string) (*Ledger, error) {
 	snap := filepath.Join(path, "ledger.snap")
 	wal := filepath.Join(path, "ledger.wal")
 
 	var genesis *Block
 	l := &Ledger{}
 
-	if f, err := os.Open(snap); err == nil {
-		defer f.Close()
-		if err := json.NewDecoder(f).Decode(l); err != nil {
+	if data, err := os.ReadFile(snap); err == nil {
+		if err := json.Unmarshal(data, l); err != nil {
+			return nil, fmt.Errorf("decode snapshot: %w", err)
+		}
+		// snapshots written before typed balance keys
+		var legacy struct{ TokenBalances map[string]uint64 }
+		if err := json.Unmarshal(data, &legacy); err != nil {
 			return nil, fmt.Errorf("decode snapshot: %w", err)
 		}
+		if err := l.adoptLegacyBalances(legacy.TokenBalances); err != nil {
+			return nil, fmt.Errorf("migrate snapshot balances: %w", err)
+		}
+		l.backfillSupply()
 		l.snapshotPath = snap
 	} else if !os.IsNotExist(err) {
 		return nil, fmt.Errorf("open snapshot: %w", err)
 	}
 
-	cfg := LedgerConfig{WALPath: wal, SnapshotPath: snap, GenesisBlock: genesis}
+	cfg := LedgerConfig{WALPath: wal, SnapshotPath: snap, GenesisBlock: genesis, StateHistoryPath: history}
 	if l.Blocks != nil {
 		// ledger restored from snapshot; reuse existing blocks/state
 		cfg.GenesisBlock = nil
@@ -109,11 +140,18 @@ func OpenLedger(path string) (*Ledger, error) {
 	if l.Blocks != nil {
 		// copy restored data into loaded ledger
 		loaded.Blocks = l.Blocks
+		for _, b := range l.Blocks {
+			loaded.blockIndex[b.Hash()] = b
+		}
 		loaded.State = l.State
 		loaded.UTXO = l.UTXO
+		loaded.utxoIdx = nil // reindex the restored set
+		loaded.utxoIndex()
 		loaded.TxPool = l.TxPool
+		loaded.poolIdx = nil
 		loaded.Contracts = l.Contracts
-		loaded.TokenBalances = l.TokenBalances
+		loaded.Balances = l.Balances
+		loaded.Supply = l.Supply
 		loaded.NodeLocations = l.NodeLocations
 	}
 	InitTxDistributor(loaded)
@@ -149,16 +187,19 @@ func (l *Ledger) AppendBlock(blk *Block) error {
 	return nil
 }
 
-func (l *Ledger) MintBig(addr []byte, amount *big.Int) {
+// MintBig credits amount of the coin to addr. Balances are uint64, so an
+// amount outside that range credits nothing and returns an error rather
+// than being truncated.
+func (l *Ledger) MintBig(addr []byte, amount *big.Int) error {
+	if amount.Sign() < 0 || !amount.IsUint64() {
+		return NewError(CodeInvalidArgument, "ledger", "mint amount %s out of range", amount)
+	}
 	l.mu.Lock()
 	defer l.mu.Unlock()
 
-	key := string(addr) // ⚠️ make sure this is safe as a map key
-	if l.TokenBalances == nil {
-		l.TokenBalances = make(map[string]uint64)
-	}
-
-	l.TokenBalances[key] += amount.Uint64()
+	var a Address
+	copy(a[:], addr)
+	return l.mint(CoinKey(a), amount.Uint64())
 }
 
 func (l *Ledger) EmitApproval(tokenID TokenID, owner, spender Address, amount uint64) {
@@ -211,12 +252,51 @@ func (l *Ledger) TokenBalance(tid TokenID, addr Address) uint64 {
 // applyBlock appends a block and updates sub-ledgers; if persist is true,
 // it writes to the WAL and performs snapshots.
 func (l *Ledger) applyBlock(block *Block, persist bool) error {
-	// 1. Height check
-	expected := uint64(len(l.Blocks))
+	if l.fenced {
+		return ErrFenced
+	}
+	// 1. Height check – ledgers booted from a pruned or imported snapshot
+	// start at the height of their first retained block.
+	expected := uint64(0)
+	if n := len(l.Blocks); n > 0 {
+		expected = l.Blocks[n-1].Header.Height + 1
+	}
 	if block.Header.Height != expected {
 		return fmt.Errorf("invalid block height: expected %d, got %d",
 			expected, block.Header.Height)
 	}
+	if err := block.VerifyLogsBloom(); err != nil {
+		return fmt.Errorf("block %d: %w", block.Header.Height, err)
+	}
+	// Resource limits apply to new blocks; replayed ones were checked
+	// under the limits of their time.
+	if persist {
+		if err := CurrentResourceLimits().CheckBlockLimits(block.Transactions); err != nil {
+			return fmt.Errorf("block %d: %w", block.Header.Height, err)
+		}
+	}
+
+	// Token transfers, sanctions, multicall envelopes and session key
+	// policies are checked before anything is applied so a block that
+	// overdraws, pays a listed account, carries a malformed batch or
+	// exceeds a session key is rejected whole.
+	if err := l.checkTokenTransfers(block.Transactions); err != nil {
+		return fmt.Errorf("block %d: %w", block.Header.Height, err)
+	}
+	for _, tx := range block.Transactions {
+		if err := checkTxSanctions(heldState{l}, tx); err != nil {
+			return fmt.Errorf("block %d: tx %s: %w", block.Header.Height, tx.IDHex(), err)
+		}
+		if tx.Type == TxMulticall {
+			if err := ValidateMulticall(tx); err != nil {
+				return fmt.Errorf("block %d: tx %s: %w", block.Header.Height, tx.IDHex(), err)
+			}
+		}
+	}
+	charges, err := l.sessionCharges(block.Transactions, time.UnixMilli(block.Header.Timestamp), persist)
+	if err != nil {
+		return fmt.Errorf("block %d: %w", block.Header.Height, err)
+	}
 
 	// 2. Append to canonical chain
 	l.Blocks = append(l.Blocks, block)
@@ -224,21 +304,23 @@ func (l *Ledger) applyBlock(block *Block, persist bool) error {
 	l.blockIndex[h] = block
 
 	// 3. Process each transaction
-	for _, tx := range block.Transactions {
+	undo := &utxoUndo{height: block.Header.Height}
+	txDiffs := make([]StateDiff, 0, len(block.Transactions))
+	spenders := tokenSpenders(block.Transactions)
+	for i, tx := range block.Transactions {
 		txIDHex := tx.IDHex() // hex string for map keys / logs
+		diff := l.beginTxDiff(tx)
 
 		// ---- UTXO updates ---------------------------------------------------
 		for _, in := range tx.Inputs {
-			key := fmt.Sprintf("%x:%d", in.TxID, in.Index)
-			delete(l.UTXO, key)
+			l.spendUTXO(utxoKey(in.TxID, in.Index), undo)
 		}
 		for idx, out := range tx.Outputs {
-			key := fmt.Sprintf("%x:%d", tx.ID(), idx)
-			l.UTXO[key] = UTXO{
+			l.addUTXO(utxoKey(tx.ID(), uint32(idx)), UTXO{
 				TxID:   tx.ID(),
 				Index:  uint32(idx),
 				Output: out,
-			}
+			}, undo)
 		}
 
 		// ---- State storage updates -----------------------------------------
@@ -247,20 +329,27 @@ func (l *Ledger) applyBlock(block *Block, persist bool) error {
 		}
 
 		// ---- Remove from mem-pool ------------------------------------------
+		// along with any other transaction for the nonce it spent
 		delete(l.TxPool, txIDHex)
+		l.dropPoolSlot(tx)
 
 		// ---- Contract deployment -------------------------------------------
 		if tx.Contract != nil {
 			addrHex := fmt.Sprintf("%x", tx.Contract.Address)
 			l.Contracts[addrHex] = *tx.Contract
+			ABIs().Register(tx.Contract.ABI)
 		}
 
 		// ---- Token transfers -----------------------------------------------
 		for _, tr := range tx.TokenTransfers {
-			fromHex := fmt.Sprintf("%x", tr.From)
-			toHex := fmt.Sprintf("%x", tr.To)
-			l.TokenBalances[fromHex] -= tr.Amount
-			l.TokenBalances[toHex] += tr.Amount
+			if err := l.move(CoinKey(tr.From), tr.To, tr.Amount); err != nil {
+				return fmt.Errorf("tx %s: token transfer: %w", txIDHex, err)
+			}
+		}
+
+		// ---- Multicall -----------------------------------------------------
+		if tx.Type == TxMulticall {
+			l.executeMulticall(tx, i, block.Header.Height, spenders, diff)
 		}
 
 		// ---- Fee distribution ----------------------------------------
@@ -271,9 +360,27 @@ func (l *Ledger) applyBlock(block *Block, persist bool) error {
 				logrus.Warnf("fee distribution: %v", err)
 			}
 		}
+		txDiffs = append(txDiffs, diff.finish(l, block.Header.Height))
+	}
+
+	l.chargeSessionKeys(charges)
+	l.utxoIndex().record(undo)
+	hexHash := h.Hex()
+	for i := range txDiffs {
+		txDiffs[i].BlockHash = hexHash
+	}
+	l.stateDiffLog().add(&BlockStateDiff{
+		StateDiff: mergeStateDiffs(block.Header.Height, hexHash, txDiffs),
+		Txs:       txDiffs,
+		Source:    "execution",
+	})
+
+	// 4. Historical state (archive mode) -------------------------------------
+	if err := l.recordBlock(block.Header.Height); err != nil {
+		return err
 	}
 
-	// 4. Persistence & snapshots ---------------------------------------------
+	// 5. Persistence & snapshots ---------------------------------------------
 	if persist {
 		data, err := json.Marshal(block)
 		if err != nil {
@@ -283,6 +390,7 @@ func (l *Ledger) applyBlock(block *Block, persist bool) error {
 			return fmt.Errorf("write WAL: %w", err)
 		}
 		_ = l.walFile.Sync()
+		l.notifyWAL()
 
 		if l.snapshotInterval > 0 && len(l.Blocks)%l.snapshotInterval == 0 {
 			if err := l.snapshot(); err != nil {
@@ -294,15 +402,63 @@ func (l *Ledger) applyBlock(block *Block, persist bool) error {
 		}
 	}
 
+	l.invalidateReadView(block.Header.Height)
 	logrus.Infof("Block %d applied; total blocks %d", block.Header.Height, len(l.Blocks))
 	return nil
 }
 
+// checkTokenTransfers replays the token transfers of txs in order against
+// the current coin balances and fails on the first that overdraws its
+// sender. The caller holds l.mu.
+func (l *Ledger) checkTokenTransfers(txs []*Transaction) error {
+	pending := make(map[Address]uint64)
+	balance := func(a Address) uint64 {
+		if v, ok := pending[a]; ok {
+			return v
+		}
+		return l.Balances[CoinKey(a)]
+	}
+	for _, tx := range txs {
+		for i, tr := range tx.TokenTransfers {
+			if tr.From == AddressZero {
+				return fmt.Errorf("tx %s transfer %d: %w", tx.IDHex(), i, ErrZeroAddress)
+			}
+			have := balance(tr.From)
+			if have < tr.Amount {
+				return fmt.Errorf("tx %s transfer %d: %w: %s holds %d, sends %d",
+					tx.IDHex(), i, ErrInsufficientBalance, tr.From.Hex(), have, tr.Amount)
+			}
+			pending[tr.From] = have - tr.Amount
+			if tr.To != AddressZero {
+				pending[tr.To] = balance(tr.To) + tr.Amount
+			}
+		}
+	}
+	return nil
+}
+
 // AddBlock is the external entrypoint to append a block.
 func (l *Ledger) AddBlock(block *Block) error {
 	l.mu.Lock()
-	defer l.mu.Unlock()
-	return l.applyBlock(block, true)
+	err := l.applyBlock(block, true)
+	l.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	// events are published and the authority applier ticked outside the
+	// ledger lock because both write back into ledger state
+	emitBlockEvents(block)
+	tickAuthorityApplier(l, block)
+	return nil
+}
+
+// emitBlockEvents publishes the block and its transactions on the event bus.
+func emitBlockEvents(block *Block) {
+	h := block.Header.Height
+	publishEvent(TopicBlock, h, BlockEventData{Height: h, Hash: block.Hash().Hex(), Txs: len(block.Transactions)})
+	for _, tx := range block.Transactions {
+		publishEvent(TopicTx, h, TxEventData{Hash: tx.IDHex(), From: tx.From, To: tx.To, Amount: tx.Value, Height: h})
+	}
 }
 
 // RebuildChain resets the ledger and replays the supplied blocks as the new
@@ -317,9 +473,12 @@ func (l *Ledger) RebuildChain(blocks []*Block) error {
 	l.blockIndex = make(map[Hash]*Block)
 	l.State = make(map[string][]byte)
 	l.UTXO = make(map[string]UTXO)
+	l.utxoIdx = newUTXOIndex()
 	l.TxPool = make(map[string]*Transaction)
+	l.poolIdx = nil
 	l.Contracts = make(map[string]Contract)
-	l.TokenBalances = make(map[string]uint64)
+	l.Balances = make(map[BalanceKey]uint64)
+	l.Supply = make(map[LedgerAsset]SupplyTotals)
 	l.logs = nil
 	l.lpBalances = make(map[Address]map[PoolID]uint64)
 	l.nonces = make(map[Address]uint64)
@@ -327,6 +486,12 @@ func (l *Ledger) RebuildChain(blocks []*Block) error {
 	l.pendingSubBlocks = nil
 	l.holoData = make(map[Hash][]byte)
 	l.tokens = make(map[TokenID]Token)
+	l.stateDiffs = nil
+	if l.archive != nil {
+		if err := l.archive.reset(); err != nil {
+			return err
+		}
+	}
 
 	for i, blk := range blocks {
 		if err := l.applyBlock(blk, false); err != nil {
@@ -457,16 +622,19 @@ func (l *Ledger) rewriteWAL() error {
 func (l *Ledger) StateRoot() Hash {
 	l.mu.RLock()
 	defer l.mu.RUnlock()
+	return stateRoot(l.State)
+}
 
-	keys := make([]string, 0, len(l.State))
-	for k := range l.State {
+func stateRoot(state map[string][]byte) Hash {
+	keys := make([]string, 0, len(state))
+	for k := range state {
 		keys = append(keys, k)
 	}
 	sort.Strings(keys)
 	h := sha256.New()
 	for _, k := range keys {
 		h.Write([]byte(k))
-		h.Write(l.State[k])
+		h.Write(state[k])
 	}
 	var out Hash
 	copy(out[:], h.Sum(nil))
@@ -483,25 +651,87 @@ func (l *Ledger) GetBlock(height uint64) (*Block, error) {
 	return l.Blocks[height], nil
 }
 
-// GetUTXO returns UTXOs for an address.
+// GetUTXO returns all UTXOs for an address. Large sets should be read with
+// UTXOPage instead.
 func (l *Ledger) GetUTXO(address []byte) []UTXO {
 	l.mu.RLock()
 	defer l.mu.RUnlock()
 	var res []UTXO
-	addrHex := fmt.Sprintf("%x", address)
-	for _, utxo := range l.UTXO {
-		if fmt.Sprintf("%x", utxo.Output.PubKeyHash) == addrHex {
-			res = append(res, utxo)
-		}
+	for _, key := range l.ownerKeys(fmt.Sprintf("%x", address)) {
+		res = append(res, l.UTXO[key])
 	}
 	return res
 }
 
-// AddToPool adds a transaction to the pool.
+// MinReplacementBump is the percentage by which a transaction must raise the
+// gas price of the pooled transaction with its sender and nonce to replace
+// it.
+const MinReplacementBump = 10
+
+// MinReplacementGasPrice returns the lowest gas price that replaces a pooled
+// transaction paying price: MinReplacementBump percent more, rounded up,
+// and at least one more.
+func MinReplacementGasPrice(price uint64) uint64 {
+	bump := price/100*MinReplacementBump + (price%100*MinReplacementBump+99)/100
+	if bump == 0 {
+		bump = 1
+	}
+	if price > ^uint64(0)-bump {
+		return ^uint64(0)
+	}
+	return price + bump
+}
+
+// poolSlot is the sender and nonce a pooled transaction occupies.
+type poolSlot struct {
+	from  Address
+	nonce uint64
+}
+
+// poolIndex returns the pool IDs by slot, building it from TxPool when the
+// pool was replaced wholesale. The caller holds l.mu.
+func (l *Ledger) poolIndex() map[poolSlot]string {
+	if l.poolIdx == nil {
+		l.poolIdx = make(map[poolSlot]string, len(l.TxPool))
+		for id, tx := range l.TxPool {
+			l.poolIdx[poolSlot{tx.From, tx.Nonce}] = id
+		}
+	}
+	return l.poolIdx
+}
+
+// dropPoolSlot removes the pooled transaction, if any, holding the sender
+// and nonce of tx, which has been included. The caller holds l.mu.
+func (l *Ledger) dropPoolSlot(tx *Transaction) {
+	slot := poolSlot{tx.From, tx.Nonce}
+	if id, ok := l.poolIndex()[slot]; ok {
+		delete(l.TxPool, id)
+		delete(l.poolIdx, slot)
+	}
+}
+
+// AddToPool adds a transaction to the pool. A transaction with the sender
+// and nonce of a pooled one replaces it if it pays at least
+// MinReplacementGasPrice of the pooled one's gas price and is dropped
+// otherwise.
 func (l *Ledger) AddToPool(tx *Transaction) {
 	l.mu.Lock()
 	defer l.mu.Unlock()
-	l.TxPool[fmt.Sprintf("%x", tx.ID())] = tx
+	idx := l.poolIndex()
+	slot := poolSlot{tx.From, tx.Nonce}
+	if id, ok := idx[slot]; ok {
+		if p, live := l.TxPool[id]; live && p.Hash != tx.Hash {
+			if tx.GasPrice < MinReplacementGasPrice(p.GasPrice) {
+				logrus.Infof("Kept transaction %x over underpriced replacement %x", p.ID(), tx.ID())
+				return
+			}
+			delete(l.TxPool, id)
+			logrus.Infof("Replaced transaction %x in pool", p.ID())
+		}
+	}
+	id := fmt.Sprintf("%x", tx.ID())
+	l.TxPool[id] = tx
+	idx[slot] = id
 	logrus.Infof("Added transaction %x to pool", tx.ID())
 }
 
@@ -536,11 +766,11 @@ func (l *Ledger) GetContract(address []byte) (*Contract, error) {
 	return &c, nil
 }
 
-// BalanceOf returns token balance.
+// BalanceOf returns the native coin balance.
 func (l *Ledger) BalanceOf(address Address) uint64 {
 	l.mu.RLock()
 	defer l.mu.RUnlock()
-	return l.TokenBalances[address.String()+":"+Code]
+	return l.Balances[CoinKey(address)]
 }
 
 // Snapshot returns JSON state of ledger.
@@ -572,8 +802,13 @@ func (l *Ledger) BlockByHash(h Hash) (*Block, error) {
 // ImportBlock appends a block to the chain and persists it.
 func (l *Ledger) ImportBlock(b *Block) error {
 	l.mu.Lock()
-	defer l.mu.Unlock()
-	return l.applyBlock(b, true)
+	err := l.applyBlock(b, true)
+	l.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	tickAuthorityApplier(l, b)
+	return nil
 }
 
 // DecodeBlockRLP decodes an RLP encoded block.
@@ -604,20 +839,35 @@ func (l *Ledger) MintToken(addr Address, tokenID string, amount uint64) error {
 		return fmt.Errorf("mint amount must be positive")
 	}
 
-	// Initialize balance map if missing
-	if l.TokenBalances == nil {
-		l.TokenBalances = make(map[string]uint64)
+	if err := l.mint(TokenKey(LedgerAsset(tokenID), addr), amount); err != nil {
+		return err
 	}
 
-	key := fmt.Sprintf("%s:%s", addr.String(), tokenID)
-	l.TokenBalances[key] += amount
-
 	// Log the minting event (optional if you use structured logging)
 	logrus.Infof("Minted %d of token %s to address %s", amount, tokenID, addr.String())
 
 	return nil
 }
 
+// BurnToken removes the specified amount of a token from a wallet's balance.
+func (l *Ledger) BurnToken(addr Address, tokenID string, amount uint64) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := l.burn(TokenKey(LedgerAsset(tokenID), addr), amount); err != nil {
+		return fmt.Errorf("insufficient %s balance", tokenID)
+	}
+	return nil
+}
+
+// MintedTokenBalance returns a wallet's balance of a token minted with
+// MintToken.
+func (l *Ledger) MintedTokenBalance(addr Address, tokenID string) uint64 {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.Balances[TokenKey(LedgerAsset(tokenID), addr)]
+}
+
 func (l *Ledger) LastSubBlockHeight() uint64 {
 	l.mu.RLock()
 	defer l.mu.RUnlock()
@@ -675,36 +925,32 @@ func (l *Ledger) AppendSubBlock(sb *SubBlock) error {
 	return nil
 }
 
+// Transfer moves coins between accounts. A transfer to AddressZero burns
+// them.
 func (l *Ledger) Transfer(from, to Address, amount uint64) error {
+	if err := checkSanctionsIn(l, from, to); err != nil {
+		return err
+	}
 	l.mu.Lock()
 	defer l.mu.Unlock()
 
-	if l.TokenBalances[from.String()] < amount {
-		return fmt.Errorf("insufficient balance")
-	}
-
-	l.TokenBalances[from.String()] -= amount
-	l.TokenBalances[to.String()] += amount
-	return nil
+	return l.move(CoinKey(from), to, amount)
 }
 
 func (l *Ledger) Mint(to Address, amount uint64) error {
 	l.mu.Lock()
 	defer l.mu.Unlock()
 
-	l.TokenBalances[to.String()] += amount
-	return nil
+	return l.mint(CoinKey(to), amount)
 }
 
 func (l *Ledger) Burn(from Address, amount uint64) error {
 	l.mu.Lock()
 	defer l.mu.Unlock()
 
-	if l.TokenBalances[from.String()] < amount {
-		return fmt.Errorf("insufficient balance to burn")
+	if err := l.burn(CoinKey(from), amount); err != nil {
+		return fmt.Errorf("%w to burn", err)
 	}
-
-	l.TokenBalances[from.String()] -= amount
 	return nil
 }
 
@@ -810,6 +1056,25 @@ func (l *Ledger) BurnLP(addr Address, pool PoolID, amt uint64) error {
 	return nil
 }
 
+// LPSupply sums the LP balances of every holder of pool.
+func (l *Ledger) LPSupply(pool PoolID) uint64 {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	var total uint64
+	for _, pools := range l.lpBalances {
+		total += pools[pool]
+	}
+	return total
+}
+
+// CoinBalance returns the native coin balance moved by Transfer, Mint and
+// Burn; the same entry BalanceOf reads.
+func (l *Ledger) CoinBalance(addr Address) uint64 {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.Balances[CoinKey(addr)]
+}
+
 func (l *Ledger) NonceOf(addr Address) uint64 {
 	l.mu.RLock()
 	defer l.mu.RUnlock()
@@ -839,6 +1104,9 @@ func (l *Ledger) Call(from, to Address, input []byte, value *big.Int, gas uint64
 	if value == nil {
 		value = big.NewInt(0)
 	}
+	if contractPaused(l, to) {
+		return nil, ErrContractPaused
+	}
 
 	l.mu.RLock()
 	c, ok := l.Contracts[to.String()]
@@ -884,15 +1152,6 @@ func (l *Ledger) Call(from, to Address, input []byte, value *big.Int, gas uint64
 	return ms.Call(from, to, input, value, gas)
 }
 
-func (l *Ledger) ChargeStorageRent(addr Address, bytes int64) error {
-	if bytes <= 0 {
-		return nil
-	}
-	cost := uint64(bytes)
-	zero := AddressZero
-	return l.Transfer(addr, zero, cost)
-}
-
 // SetNodeLocation stores geolocation information for a node.
 func (l *Ledger) SetNodeLocation(id NodeID, loc Location) {
 	l.mu.Lock()
@@ -924,8 +1183,15 @@ func (l *Ledger) AllNodeLocations() map[NodeID]Location {
 
 // Close releases any underlying resources such as the WAL file.
 func (l *Ledger) Close() error {
-	if l == nil || l.walFile == nil {
+	if l == nil {
 		return nil
 	}
+	l.stopReadViews()
+	if l.walFile == nil {
+		return nil
+	}
+	if l.archive != nil {
+		_ = l.archive.journal.Close()
+	}
 	return l.walFile.Close()
 }
@@ -0,0 +1,349 @@
+package core
+
+// ledger_archive.go – historical state for archive nodes.
+//
+// In archive mode the ledger keeps every version of its key/value state,
+// coin and token balances, account nonces and contract code in a versioned
+// KV: after each block the live maps are diffed against the previous
+// version and the changes are stored under the block height and appended to
+// a journal on disk, so history survives restarts. Ledger.StateAt(h)
+// returns a read-only view of the state as it stood after block h, against
+// which balances, raw state and eth_call-style contract reads can be served.
+//
+// Writes made between blocks are attributed to the next block. Token
+// metadata is not versioned; historical calls see the current registry.
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrNotArchive is returned by StateAt on ledgers that keep no history.
+var ErrNotArchive = errors.New("ledger is not running in archive mode")
+
+// Versioned KV namespaces.
+const (
+	archState    = "s:"
+	archBalance  = "b:"
+	archNonce    = "n:"
+	archContract = "c:"
+)
+
+type stateVersion struct {
+	Height  uint64 `json:"h"`
+	Value   []byte `json:"v,omitempty"`
+	Deleted bool   `json:"d,omitempty"`
+}
+
+type archiveChange struct {
+	Key     string `json:"k"`
+	Value   []byte `json:"v,omitempty"`
+	Deleted bool   `json:"d,omitempty"`
+}
+
+type archiveRecord struct {
+	Height  uint64          `json:"h"`
+	Changes []archiveChange `json:"c"`
+}
+
+// stateArchive is the versioned KV backing archive mode. versions holds the
+// history of each namespaced key in ascending height order; latest mirrors
+// the newest version of every live key and is what the next block is
+// diffed against.
+type stateArchive struct {
+	mu       sync.RWMutex
+	versions map[string][]stateVersion
+	latest   map[string][]byte
+	head     uint64
+	started  bool
+	journal  *os.File
+}
+
+// openStateArchive loads the journal at path, creating it if needed.
+func openStateArchive(path string) (*stateArchive, error) {
+	a := &stateArchive{versions: make(map[string][]stateVersion), latest: make(map[string][]byte)}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("open state history: %w", err)
+	}
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 1<<20), 1<<30)
+	for sc.Scan() {
+		var rec archiveRecord
+		if err := json.Unmarshal(sc.Bytes(), &rec); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("state history: %w", err)
+		}
+		a.apply(rec)
+	}
+	if err := sc.Err(); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("state history: %w", err)
+	}
+	a.journal = f
+	return a, nil
+}
+
+func (a *stateArchive) apply(rec archiveRecord) {
+	for _, c := range rec.Changes {
+		a.versions[c.Key] = append(a.versions[c.Key], stateVersion{Height: rec.Height, Value: c.Value, Deleted: c.Deleted})
+		if c.Deleted {
+			delete(a.latest, c.Key)
+		} else {
+			a.latest[c.Key] = c.Value
+		}
+	}
+	a.head, a.started = rec.Height, true
+}
+
+// flatten renders the ledger's versioned maps into namespaced KV form.
+func (l *Ledger) flatten() map[string][]byte {
+	out := make(map[string][]byte, len(l.State)+len(l.Balances)+len(l.nonces)+len(l.Contracts))
+	for k, v := range l.State {
+		out[archState+k] = v
+	}
+	for k, v := range l.Balances {
+		out[archBalance+k.String()] = binary.BigEndian.AppendUint64(nil, v)
+	}
+	for k, v := range l.nonces {
+		out[archNonce+k.String()] = binary.BigEndian.AppendUint64(nil, v)
+	}
+	for k, c := range l.Contracts {
+		raw, _ := json.Marshal(c)
+		out[archContract+k] = raw
+	}
+	return out
+}
+
+// recordBlock diffs the ledger against the previous version and stores the
+// changes under height. Heights already in the journal are skipped so that
+// WAL replay on start-up does not duplicate history. The caller holds l.mu.
+func (l *Ledger) recordBlock(height uint64) error {
+	a := l.archive
+	if a == nil {
+		return nil
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.started && height <= a.head {
+		return nil
+	}
+	cur := l.flatten()
+	rec := archiveRecord{Height: height}
+	for k, v := range cur {
+		if old, ok := a.latest[k]; !ok || string(old) != string(v) {
+			rec.Changes = append(rec.Changes, archiveChange{Key: k, Value: append([]byte(nil), v...)})
+		}
+	}
+	for k := range a.latest {
+		if _, ok := cur[k]; !ok {
+			rec.Changes = append(rec.Changes, archiveChange{Key: k, Deleted: true})
+		}
+	}
+	sort.Slice(rec.Changes, func(i, j int) bool { return rec.Changes[i].Key < rec.Changes[j].Key })
+	raw, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	if _, err := a.journal.Write(append(raw, '\n')); err != nil {
+		return fmt.Errorf("write state history: %w", err)
+	}
+	a.apply(rec)
+	return nil
+}
+
+// reset discards all history; used when the chain is rebuilt after a fork.
+func (a *stateArchive) reset() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err := a.journal.Truncate(0); err != nil {
+		return err
+	}
+	a.versions = make(map[string][]stateVersion)
+	a.latest = make(map[string][]byte)
+	a.head, a.started = 0, false
+	return nil
+}
+
+// get returns the value of key as of height.
+func (a *stateArchive) get(key string, height uint64) ([]byte, bool) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	vs := a.versions[key]
+	i := sort.Search(len(vs), func(i int) bool { return vs[i].Height > height })
+	if i == 0 || vs[i-1].Deleted {
+		return nil, false
+	}
+	return vs[i-1].Value, true
+}
+
+// keysAt lists the keys under prefix that were live at height.
+func (a *stateArchive) keysAt(prefix string, height uint64) []string {
+	a.mu.RLock()
+	var keys []string
+	for k := range a.versions {
+		if strings.HasPrefix(k, prefix) {
+			keys = append(keys, k)
+		}
+	}
+	a.mu.RUnlock()
+	out := keys[:0]
+	for _, k := range keys {
+		if _, ok := a.get(k, height); ok {
+			out = append(out, k)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Archive reports whether the ledger keeps historical state.
+func (l *Ledger) Archive() bool { return l.archive != nil }
+
+// StateAt returns a read-only view of the ledger state after block height.
+func (l *Ledger) StateAt(height uint64) (*StateView, error) {
+	if l.archive == nil {
+		return nil, ErrNotArchive
+	}
+	l.archive.mu.RLock()
+	head, started := l.archive.head, l.archive.started
+	l.archive.mu.RUnlock()
+	if !started || height > head {
+		return nil, fmt.Errorf("height %d not archived (head %d)", height, head)
+	}
+	return &StateView{height: height, arch: l.archive, ledger: l}, nil
+}
+
+// StateView is the ledger state as it stood after a past block. It
+// implements the read side of StateRW; writes are rejected.
+type StateView struct {
+	height uint64
+	arch   *stateArchive
+	ledger *Ledger
+}
+
+var errReadOnlyView = errors.New("historical state is read-only")
+
+// Height returns the block height the view reflects.
+func (v *StateView) Height() uint64 { return v.height }
+
+func (v *StateView) GetState(key []byte) ([]byte, error) {
+	val, ok := v.arch.get(archState+string(key), v.height)
+	if !ok {
+		return nil, fmt.Errorf("state key not found")
+	}
+	return append([]byte(nil), val...), nil
+}
+
+func (v *StateView) HasState(key []byte) (bool, error) {
+	_, ok := v.arch.get(archState+string(key), v.height)
+	return ok, nil
+}
+
+func (v *StateView) SetState(key, value []byte) error { return errReadOnlyView }
+func (v *StateView) DeleteState(key []byte) error     { return errReadOnlyView }
+
+func (v *StateView) PrefixIterator(prefix []byte) StateIterator {
+	it := &memIter{idx: -1}
+	for _, k := range v.arch.keysAt(archState+string(prefix), v.height) {
+		val, _ := v.arch.get(k, v.height)
+		it.keys = append(it.keys, []byte(strings.TrimPrefix(k, archState)))
+		it.values = append(it.values, val)
+	}
+	return it
+}
+
+func (v *StateView) uint64At(key string) uint64 {
+	raw, ok := v.arch.get(key, v.height)
+	if !ok || len(raw) != 8 {
+		return 0
+	}
+	return binary.BigEndian.Uint64(raw)
+}
+
+// CoinBalance returns the native coin balance of addr at the view height.
+func (v *StateView) CoinBalance(addr Address) uint64 { return v.BalanceOf(addr) }
+
+// BalanceOf returns the native coin balance, as Ledger.BalanceOf. Heights
+// journaled before typed balance keys kept part of it under the bare
+// address; the two are summed as MigrateBalances does.
+func (v *StateView) BalanceOf(addr Address) uint64 {
+	return v.uint64At(archBalance+CoinKey(addr).String()) + v.uint64At(archBalance+addr.String())
+}
+
+// NonceOf returns the account nonce at the view height.
+func (v *StateView) NonceOf(addr Address) uint64 {
+	return v.uint64At(archNonce + addr.String())
+}
+
+// GetContract returns the contract deployed at address at the view height.
+func (v *StateView) GetContract(address []byte) (*Contract, error) {
+	raw, ok := v.arch.get(archContract+fmt.Sprintf("%x", address), v.height)
+	if !ok {
+		return nil, fmt.Errorf("contract %x not found at height %d", address, v.height)
+	}
+	var c Contract
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// Call executes a contract read against the view, like Ledger.Call does
+// against the live state. Side effects are discarded.
+func (v *StateView) Call(from, to Address, input []byte, value *big.Int, gas uint64) ([]byte, error) {
+	if value == nil {
+		value = big.NewInt(0)
+	}
+	ms, err := v.sandbox(to)
+	if err != nil {
+		return nil, err
+	}
+	return ms.Call(from, to, input, value, gas)
+}
+
+// sandbox builds an in-memory state holding the view's storage, nonces and
+// the code of the contract at to, for executing calls against the past.
+func (v *StateView) sandbox(to Address) (*memState, error) {
+	c, err := v.GetContract(to[:])
+	if err != nil {
+		return nil, err
+	}
+	state := make(map[string][]byte)
+	for _, k := range v.arch.keysAt(archState, v.height) {
+		val, _ := v.arch.get(k, v.height)
+		state[strings.TrimPrefix(k, archState)] = append([]byte(nil), val...)
+	}
+	nonces := make(map[Address]uint64)
+	for _, k := range v.arch.keysAt(archNonce, v.height) {
+		if addr, err := ParseAddress(strings.TrimPrefix(k, archNonce)); err == nil {
+			nonces[addr] = v.uint64At(k)
+		}
+	}
+	tokens := make(map[TokenID]Token)
+	if v.ledger != nil {
+		v.ledger.mu.RLock()
+		for k, t := range v.ledger.tokens {
+			tokens[k] = t
+		}
+		v.ledger.mu.RUnlock()
+	}
+	ms := &memState{
+		data:       state,
+		balances:   make(map[Address]uint64),
+		lpBalances: make(map[Address]map[PoolID]uint64),
+		contracts:  map[Address][]byte{to: append([]byte(nil), c.Bytecode...)},
+		tokens:     tokens,
+		codeHashes: make(map[Address]Hash),
+		nonces:     nonces,
+	}
+	return ms, nil
+}
@@ -0,0 +1,408 @@
+package core
+
+// ledger_reindex.go – rebuild derived indexes from the canonical chain.
+//
+// Derived indexes live in the ledger's key/value state under their own
+// prefixes and can always be recomputed from the blocks: the transaction
+// index (hash → position, address → transactions), token holder lists,
+// per-block analytics aggregates and the contract log index. Reindex drops
+// the selected indexes and replays the retained block history in batches;
+// every batch is committed together with a cursor so an interrupted run can
+// resume. Contract logs are not part of the block body, so the log index is
+// replayed from the persisted event log, which is written in block order.
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Derived index names accepted by Reindex.
+const (
+	IndexTxs       = "txs"
+	IndexLogs      = "logs"
+	IndexHolders   = "holders"
+	IndexAnalytics = "analytics"
+)
+
+// DefaultReindexBatch is the number of blocks (or events) per commit.
+const DefaultReindexBatch = 500
+
+// ReindexOptions selects what Reindex rebuilds. An empty Indexes list
+// rebuilds every index. With Resume set, indexes continue from the cursor
+// of an interrupted run instead of starting over.
+type ReindexOptions struct {
+	Indexes  []string
+	Batch    int
+	Resume   bool
+	Progress func(ReindexProgress)
+}
+
+// ReindexProgress is reported after every committed batch.
+type ReindexProgress struct {
+	Index   string `json:"index"`
+	Cursor  uint64 `json:"cursor"`
+	Target  uint64 `json:"target"`
+	Entries int    `json:"entries"`
+}
+
+// BlockAnalytics is the analytics aggregate stored for each block.
+type BlockAnalytics struct {
+	Height         uint64 `json:"height"`
+	Timestamp      int64  `json:"timestamp"`
+	Txs            int    `json:"txs"`
+	Value          uint64 `json:"value"`
+	Fees           uint64 `json:"fees"`
+	TokenTransfers int    `json:"token_transfers"`
+}
+
+// ChainStats accumulates BlockAnalytics over the indexed history.
+type ChainStats struct {
+	Blocks         uint64 `json:"blocks"`
+	Txs            uint64 `json:"txs"`
+	Value          uint64 `json:"value"`
+	Fees           uint64 `json:"fees"`
+	TokenTransfers uint64 `json:"token_transfers"`
+	Addresses      uint64 `json:"addresses"`
+}
+
+// TxLocation is where a transaction sits in the canonical chain.
+type TxLocation struct {
+	Height uint64 `json:"height"`
+	Index  int    `json:"index"`
+}
+
+var (
+	txIndexHashPrefix   = "txidx:hash:"
+	txIndexAddrPrefix   = "txidx:addr:"
+	holderIndexPrefix   = "holders:"
+	analyticsBlockKey   = "analytics:block:"
+	analyticsTotalKey   = "analytics:total"
+	analyticsSeenPrefix = "analytics:addr:"
+)
+
+func reindexCursorKey(index string) string { return "reindex:" + index + ":cursor" }
+
+func holderKey(token TokenID, addr Address) string {
+	return fmt.Sprintf("%s%d:%s", holderIndexPrefix, token, addr.Hex())
+}
+
+// blockIndexer folds one block into an index's pending writes. Writes of a
+// batch are read back through the batch so later blocks see earlier ones.
+type blockIndexer struct {
+	prefixes []string
+	apply    func(b *reindexBatch, blk *Block)
+}
+
+var blockIndexers = map[string]blockIndexer{
+	IndexTxs:       {prefixes: []string{txIndexHashPrefix, txIndexAddrPrefix}, apply: indexBlockTxs},
+	IndexHolders:   {prefixes: []string{holderIndexPrefix}, apply: indexBlockHolders},
+	IndexAnalytics: {prefixes: []string{analyticsBlockKey, analyticsTotalKey, analyticsSeenPrefix}, apply: indexBlockAnalytics},
+}
+
+// ReindexNames lists every index Reindex can rebuild.
+func ReindexNames() []string {
+	return []string{IndexTxs, IndexLogs, IndexHolders, IndexAnalytics}
+}
+
+// reindexBatch buffers the writes of one batch over the ledger state.
+type reindexBatch struct {
+	l      *Ledger
+	writes map[string][]byte // nil value = delete
+}
+
+func (b *reindexBatch) get(key string) ([]byte, bool) {
+	if v, ok := b.writes[key]; ok {
+		return v, v != nil
+	}
+	v, ok := b.l.State[key]
+	return v, ok
+}
+
+func (b *reindexBatch) set(key string, v []byte) { b.writes[key] = v }
+func (b *reindexBatch) del(key string)           { b.writes[key] = nil }
+
+func (b *reindexBatch) uint64(key string) uint64 {
+	raw, ok := b.get(key)
+	if !ok || len(raw) != 8 {
+		return 0
+	}
+	return binary.BigEndian.Uint64(raw)
+}
+
+func (b *reindexBatch) setUint64(key string, v uint64) {
+	if v == 0 {
+		b.del(key)
+		return
+	}
+	b.set(key, binary.BigEndian.AppendUint64(nil, v))
+}
+
+func indexBlockTxs(b *reindexBatch, blk *Block) {
+	h := blk.Header.Height
+	for i, tx := range blk.Transactions {
+		id := tx.IDHex()
+		loc, _ := json.Marshal(TxLocation{Height: h, Index: i})
+		b.set(txIndexHashPrefix+id, loc)
+		pos := fmt.Sprintf(":%020d:%06d", h, i)
+		b.set(txIndexAddrPrefix+tx.From.Hex()+pos, []byte(id))
+		if tx.To != tx.From {
+			b.set(txIndexAddrPrefix+tx.To.Hex()+pos, []byte(id))
+		}
+	}
+}
+
+func indexBlockHolders(b *reindexBatch, blk *Block) {
+	for _, tx := range blk.Transactions {
+		for _, tr := range tx.TokenTransfers {
+			from, to := holderKey(tr.Token, tr.From), holderKey(tr.Token, tr.To)
+			if bal := b.uint64(from); bal > tr.Amount {
+				b.setUint64(from, bal-tr.Amount)
+			} else {
+				b.del(from)
+			}
+			b.setUint64(to, b.uint64(to)+tr.Amount)
+		}
+	}
+}
+
+func indexBlockAnalytics(b *reindexBatch, blk *Block) {
+	st := BlockAnalytics{Height: blk.Header.Height, Timestamp: blk.Header.Timestamp, Txs: len(blk.Transactions)}
+	var total ChainStats
+	if raw, ok := b.get(analyticsTotalKey); ok {
+		_ = json.Unmarshal(raw, &total)
+	}
+	seen := func(a Address) {
+		k := analyticsSeenPrefix + a.Hex()
+		if _, ok := b.get(k); !ok {
+			b.set(k, []byte{1})
+			total.Addresses++
+		}
+	}
+	for _, tx := range blk.Transactions {
+		st.Value += tx.Value
+		st.Fees += tx.GasLimit * tx.GasPrice
+		st.TokenTransfers += len(tx.TokenTransfers)
+		seen(tx.From)
+		seen(tx.To)
+	}
+	raw, _ := json.Marshal(st)
+	b.set(fmt.Sprintf("%s%020d", analyticsBlockKey, st.Height), raw)
+	total.Blocks++
+	total.Txs += uint64(st.Txs)
+	total.Value += st.Value
+	total.Fees += st.Fees
+	total.TokenTransfers += uint64(st.TokenTransfers)
+	raw, _ = json.Marshal(total)
+	b.set(analyticsTotalKey, raw)
+}
+
+// Reindex rebuilds the selected derived indexes from the canonical chain.
+func (l *Ledger) Reindex(opts ReindexOptions) error {
+	names := opts.Indexes
+	if len(names) == 0 {
+		names = ReindexNames()
+	}
+	for _, n := range names {
+		if _, ok := blockIndexers[n]; !ok && n != IndexLogs {
+			return fmt.Errorf("unknown index %q (want one of %s)", n, strings.Join(ReindexNames(), ", "))
+		}
+	}
+	if opts.Batch <= 0 {
+		opts.Batch = DefaultReindexBatch
+	}
+	l.mu.RLock()
+	blocks := append([]*Block(nil), l.Blocks...)
+	l.mu.RUnlock()
+
+	for _, n := range names {
+		var err error
+		if n == IndexLogs {
+			err = l.reindexLogs(opts)
+		} else {
+			err = l.reindexBlocks(n, blockIndexers[n], blocks, opts)
+		}
+		if err != nil {
+			return fmt.Errorf("reindex %s: %w", n, err)
+		}
+	}
+	return nil
+}
+
+// reindexBlocks replays blocks through ix. The cursor holds the last
+// committed height plus one, so zero means nothing has been indexed.
+func (l *Ledger) reindexBlocks(name string, ix blockIndexer, blocks []*Block, opts ReindexOptions) error {
+	if len(blocks) == 0 {
+		return nil
+	}
+	if name == IndexHolders && blocks[0].Header.Height > 0 {
+		return fmt.Errorf("history is pruned below height %d; holder balances cannot be replayed", blocks[0].Header.Height)
+	}
+	start := 0
+	if opts.Resume {
+		if next := l.reindexCursor(name); next > 0 {
+			i := sort.Search(len(blocks), func(i int) bool { return blocks[i].Header.Height >= next })
+			start = i
+		}
+	} else if err := l.dropIndex(name, ix.prefixes); err != nil {
+		return err
+	}
+	target := blocks[len(blocks)-1].Header.Height
+	for i := start; i < len(blocks); i += opts.Batch {
+		end := i + opts.Batch
+		if end > len(blocks) {
+			end = len(blocks)
+		}
+		b := &reindexBatch{l: l, writes: make(map[string][]byte)}
+		l.mu.RLock()
+		for _, blk := range blocks[i:end] {
+			ix.apply(b, blk)
+		}
+		l.mu.RUnlock()
+		last := blocks[end-1].Header.Height
+		if err := l.commitReindex(name, b, last+1); err != nil {
+			return err
+		}
+		if opts.Progress != nil {
+			opts.Progress(ReindexProgress{Index: name, Cursor: last, Target: target, Entries: len(b.writes)})
+		}
+	}
+	return nil
+}
+
+// reindexLogs rebuilds the contract event index from the event log and
+// moves the live indexer's cursor along with it.
+func (l *Ledger) reindexLogs(opts ReindexOptions) error {
+	head := uint64(0)
+	if raw, err := l.GetState(eventHeadKey); err == nil && len(raw) == 8 {
+		head = binary.BigEndian.Uint64(raw)
+	}
+	from := uint64(1)
+	if opts.Resume {
+		if next := l.reindexCursor(IndexLogs); next > 0 {
+			from = next
+		}
+	} else if err := l.dropIndex(IndexLogs, []string{"evidx:"}); err != nil {
+		return err
+	}
+	for seq := from; seq <= head; {
+		b := &reindexBatch{l: l, writes: make(map[string][]byte)}
+		end := seq + uint64(opts.Batch)
+		for ; seq < end && seq <= head; seq++ {
+			raw, err := l.GetState(eventSeqKey(seq))
+			if err != nil {
+				continue
+			}
+			var ev Event
+			if err := json.Unmarshal(raw, &ev); err != nil {
+				return fmt.Errorf("event %d: %w", seq, err)
+			}
+			if ev.Type != TopicContract {
+				continue
+			}
+			var ce ContractEventData
+			if err := json.Unmarshal(ev.Data, &ce); err == nil {
+				key := string(contractEventIndexPrefix(ce.Contract)) + fmt.Sprintf("%020d", ev.Seq)
+				b.set(key, []byte(strconv.FormatUint(ev.Seq, 10)))
+			}
+		}
+		b.set(string(eventIndexCursorKey), []byte(strconv.FormatUint(seq-1, 10)))
+		if err := l.commitReindex(IndexLogs, b, seq); err != nil {
+			return err
+		}
+		if opts.Progress != nil {
+			opts.Progress(ReindexProgress{Index: IndexLogs, Cursor: seq - 1, Target: head, Entries: len(b.writes)})
+		}
+	}
+	return nil
+}
+
+func (l *Ledger) reindexCursor(name string) uint64 {
+	raw, err := l.GetState([]byte(reindexCursorKey(name)))
+	if err != nil {
+		return 0
+	}
+	n, _ := strconv.ParseUint(string(raw), 10, 64)
+	return n
+}
+
+// dropIndex deletes every key of an index and its cursor.
+func (l *Ledger) dropIndex(name string, prefixes []string) error {
+	b := &reindexBatch{l: l, writes: make(map[string][]byte)}
+	l.mu.RLock()
+	for k := range l.State {
+		for _, p := range prefixes {
+			if strings.HasPrefix(k, p) {
+				b.del(k)
+				break
+			}
+		}
+	}
+	l.mu.RUnlock()
+	return l.commitReindex(name, b, 0)
+}
+
+// commitReindex applies a batch and its cursor to the state and persists
+// them with a snapshot, so a crash loses at most the batch in flight.
+func (l *Ledger) commitReindex(name string, b *reindexBatch, next uint64) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for k, v := range b.writes {
+		if v == nil {
+			delete(l.State, k)
+		} else {
+			l.State[k] = v
+		}
+	}
+	if next == 0 {
+		delete(l.State, reindexCursorKey(name))
+	} else {
+		l.State[reindexCursorKey(name)] = []byte(strconv.FormatUint(next, 10))
+	}
+	if l.snapshotPath == "" || l.walFile == nil {
+		return nil
+	}
+	if err := l.snapshot(); err != nil {
+		return fmt.Errorf("commit batch: %w", err)
+	}
+	return nil
+}
+
+// LookupTx returns a transaction from the tx index.
+func (l *Ledger) LookupTx(h Hash) (*Transaction, TxLocation, error) {
+	var loc TxLocation
+	raw, err := l.GetState([]byte(txIndexHashPrefix + hex.EncodeToString(h[:])))
+	if err != nil {
+		return nil, loc, ErrNotFound
+	}
+	if err := json.Unmarshal(raw, &loc); err != nil {
+		return nil, loc, err
+	}
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	for _, blk := range l.Blocks {
+		if blk.Header.Height != loc.Height {
+			continue
+		}
+		if loc.Index < len(blk.Transactions) {
+			return blk.Transactions[loc.Index], loc, nil
+		}
+		break
+	}
+	return nil, loc, errors.New("indexed transaction is not in the retained chain; reindex txs")
+}
+
+// ChainStatistics returns the analytics aggregate over the indexed history.
+func (l *Ledger) ChainStatistics() (ChainStats, error) {
+	var st ChainStats
+	raw, err := l.GetState([]byte(analyticsTotalKey))
+	if err != nil {
+		return st, nil
+	}
+	return st, json.Unmarshal(raw, &st)
+}
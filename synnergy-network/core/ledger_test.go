@@ -194,3 +194,54 @@ func TestStateRootDeterministic(t *testing.T) {
 		t.Fatalf("state roots mismatch")
 	}
 }
+
+//-------------------------------------------------------------
+// Test pool replacement by sender and nonce
+//-------------------------------------------------------------
+
+func TestMinReplacementGasPrice(t *testing.T) {
+	for price, want := range map[uint64]uint64{0: 1, 1: 2, 9: 10, 10: 11, 100: 110, 101: 112, ^uint64(0) - 1: ^uint64(0)} {
+		if got := MinReplacementGasPrice(price); got != want {
+			t.Errorf("MinReplacementGasPrice(%d) = %d want %d", price, got, want)
+		}
+	}
+}
+
+func TestAddToPoolReplacement(t *testing.T) {
+	config, cleanup := tmpLedgerConfig(t, nil)
+	defer cleanup()
+	l, err := NewLedger(config)
+	if err != nil {
+		t.Fatalf("ledger: %v", err)
+	}
+	from := Address{0x01}
+	orig := &Transaction{Hash: Hash{1}, From: from, Nonce: 3, GasPrice: 100}
+	l.AddToPool(orig)
+	l.AddToPool(&Transaction{Hash: Hash{2}, From: from, Nonce: 4, GasPrice: 1})
+
+	l.AddToPool(&Transaction{Hash: Hash{3}, From: from, Nonce: 3, GasPrice: 109})
+	if len(l.TxPool) != 2 || l.TxPool[orig.IDHex()] == nil {
+		t.Fatalf("replacement under the minimum bump was pooled: %v", l.TxPool)
+	}
+	repl := &Transaction{Hash: Hash{4}, From: from, Nonce: 3, GasPrice: 110}
+	l.AddToPool(repl)
+	if len(l.TxPool) != 2 || l.TxPool[orig.IDHex()] != nil || l.TxPool[repl.IDHex()] == nil {
+		t.Fatalf("replacement at the minimum bump not taken: %v", l.TxPool)
+	}
+
+	// a pool replaced wholesale, as on restore, is indexed again
+	l.TxPool = map[string]*Transaction{repl.IDHex(): repl}
+	l.poolIdx = nil
+	l.AddToPool(&Transaction{Hash: Hash{5}, From: from, Nonce: 3, GasPrice: 111})
+	if len(l.TxPool) != 1 || l.TxPool[repl.IDHex()] == nil {
+		t.Fatalf("restored pool: %v", l.TxPool)
+	}
+
+	// including any transaction for the nonce drops the pooled one
+	l.mu.Lock()
+	l.dropPoolSlot(&Transaction{Hash: Hash{6}, From: from, Nonce: 3})
+	l.mu.Unlock()
+	if len(l.TxPool) != 0 {
+		t.Fatalf("pool after the nonce was spent: %v", l.TxPool)
+	}
+}
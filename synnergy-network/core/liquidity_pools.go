@@ -2,7 +2,8 @@ package core
 
 // Constant-product Automated Market Maker (AMM) for Synnergy Network.
 //
-// * Pools are 2-token (A/B) constant-k model (x*y=k).
+// * Pools are 2-token (A/B) constant-k model (x*y=k), or stable-swap for
+//   pegged pairs (see stable_swap.go).
 // * Fees: 30 bps (0.30 %). Configurable; fee-cut is split per `FeeRates`.
 // * Functions ensure atomicity via `ledger.Snapshot()` – state rollbacks on error.
 // * Fee share destined for LoanPoolAccount (see loanpool.go) using ledger.Transfer.
@@ -56,18 +57,28 @@ func Manager() *AMM { return ammMgr }
 // Pool lifecycle
 //---------------------------------------------------------------------
 
-func (a *AMM) CreatePool(tokA, tokB TokenID, fee uint16) (PoolID, error) {
+// CreatePool opens a pool for tokA/tokB. An optional PoolSpec selects the
+// pricing invariant; pools are constant-product by default.
+func (a *AMM) CreatePool(tokA, tokB TokenID, fee uint16, spec ...PoolSpec) (PoolID, error) {
 	if fee == 0 {
 		fee = defaultFeeBps
 	}
+	var ps PoolSpec
+	if len(spec) > 0 {
+		ps = spec[0]
+	}
+	ps, err := ps.validate()
+	if err != nil {
+		return 0, err
+	}
 	a.mu.Lock()
 	defer a.mu.Unlock()
 	pid := a.nextID
 	a.nextID++
-	p := &Pool{ID: pid, tokenA: tokA, tokenB: tokB, feeBps: fee}
+	p := &Pool{ID: pid, tokenA: tokA, tokenB: tokB, feeBps: fee, kind: ps.Type, amp: ps.Amp}
 	a.pools[pid] = p
 	registerPoolForRouting(p)
-	a.logger.Printf("pool %d created %v/%v fee %d bps", pid, tokA, tokB, fee)
+	a.logger.Printf("pool %d created %v/%v fee %d bps %s", pid, tokA, tokB, fee, ps.Type)
 	return pid, nil
 }
 
@@ -83,6 +94,9 @@ func (a *AMM) AddLiquidity(p PoolID, provider Address, amtA, amtB uint64) (minte
 	if amtA == 0 || amtB == 0 {
 		return 0, errors.New("amount zero")
 	}
+	if pool.isLocked() {
+		return 0, ErrPoolLocked
+	}
 
 	return minted, a.ledger.Snapshot(func() error {
 		// transfer assets from provider to pool account
@@ -95,7 +109,13 @@ func (a *AMM) AddLiquidity(p PoolID, provider Address, amtA, amtB uint64) (minte
 		}
 
 		// compute LP to mint
-		if pool.totalLP == 0 {
+		if pool.kind == PoolStableSwap {
+			// imbalance fees stay in the reserves
+			var err error
+			if minted, _, _, err = pool.stableMint(amtA, amtB); err != nil {
+				return err
+			}
+		} else if pool.totalLP == 0 {
 			minted = uint64(math.Sqrt(float64(amtA * amtB)))
 		} else {
 			minted = min(amtA*pool.totalLP/pool.resA, amtB*pool.totalLP/pool.resB)
@@ -105,7 +125,7 @@ func (a *AMM) AddLiquidity(p PoolID, provider Address, amtA, amtB uint64) (minte
 		pool.resB += amtB
 		// credit LP tokens (internal accounting – LP token itself not ERC-20 yet)
 		a.ledger.MintLP(provider, p, minted)
-		return nil
+		return a.recordLPEntry(p, provider, amtA, amtB, minted)
 	})
 }
 
@@ -132,6 +152,9 @@ func (a *AMM) Swap(p PoolID, trader Address, tokenIn TokenID, amountIn, minOut u
 	if amountIn == 0 {
 		return 0, errors.New("amount zero")
 	}
+	if pool.isLocked() {
+		return 0, ErrPoolLocked
+	}
 
 	var amountOut uint64
 	err := a.ledger.Snapshot(func() error {
@@ -142,9 +165,10 @@ func (a *AMM) Swap(p PoolID, trader Address, tokenIn TokenID, amountIn, minOut u
 		fee := amountIn * uint64(pool.feeBps) / 10_000
 		amountInMinusFee := amountIn - fee
 
-		// constant product
-		k := (*resIn + amountInMinusFee) * (*resOut)
-		amountOut = *resOut - k/(*resIn)
+		var err error
+		if amountOut, err = pool.outGivenIn(*resIn, *resOut, amountInMinusFee); err != nil {
+			return err
+		}
 		if amountOut < minOut {
 			return errors.New("slippage")
 		}
@@ -157,6 +181,9 @@ func (a *AMM) Swap(p PoolID, trader Address, tokenIn TokenID, amountIn, minOut u
 		lpFee := fee * (10_000 - loanPoolFeeShareBps) / 10_000
 		loanFee := fee - lpFee
 		*resIn += lpFee // stays in pool benefiting LPs
+		if err := a.distributeLPFee(pool, tokenIn, resIn, lpFee); err != nil {
+			return err
+		}
 		// send to loanpool treasury
 		if err := transferToken(tokenIn, poolAccount(p), LoanPoolAccount, loanFee); err != nil {
 			return err
@@ -187,6 +214,9 @@ func (a *AMM) RemoveLiquidity(p PoolID, provider Address, lpAmount uint64) (amtA
 	if lpAmount == 0 {
 		return 0, 0, errors.New("zero LP")
 	}
+	if pool.isLocked() {
+		return 0, 0, ErrPoolLocked
+	}
 
 	err = a.ledger.Snapshot(func() error {
 		total := pool.totalLP
@@ -208,7 +238,7 @@ func (a *AMM) RemoveLiquidity(p PoolID, provider Address, lpAmount uint64) (amtA
 		if err := transferToken(pool.tokenB, poolAccount(p), provider, amtB); err != nil {
 			return err
 		}
-		return nil
+		return a.recordLPExit(p, provider, lpAmount, amtA, amtB)
 	})
 	return
 }
@@ -249,7 +279,7 @@ func poolAccount(p PoolID) Address {
 	// deterministic: 0x5000....PID
 	var a Address
 	copy(a[:18], []byte{0x50, 0x4F, 0x4F, 0x4C}) // "POOL"
-	binary.BigEndian.PutUint32(a[18:], uint32(p))
+	binary.BigEndian.PutUint32(a[16:], uint32(p))
 	return a
 }
 
@@ -267,3 +297,53 @@ func min(a, b uint64) uint64 {
 	}
 	return b
 }
+
+//---------------------------------------------------------------------
+// Invariants
+//---------------------------------------------------------------------
+
+func init() {
+	RegisterInvariant("amm", "lp_supply", checkPoolLPSupply)
+	RegisterInvariant("amm", "reserves", checkPoolReserves)
+}
+
+// checkPoolLPSupply verifies that the LP balances held in the ledger sum to
+// each pool's recorded LP supply.
+func checkPoolLPSupply() error {
+	a := Manager()
+	if a == nil {
+		return nil
+	}
+	lp, ok := a.ledger.(interface{ LPSupply(PoolID) uint64 })
+	if !ok {
+		return nil
+	}
+	for _, p := range a.Snapshot() {
+		if held := lp.LPSupply(p.ID); held != p.TotalLP {
+			return fmt.Errorf("pool %d: LP balances sum to %d, pool records %d", p.ID, held, p.TotalLP)
+		}
+	}
+	return nil
+}
+
+// checkPoolReserves verifies that every pool account holds at least the
+// reserves the pool accounts for.
+func checkPoolReserves() error {
+	a := Manager()
+	if a == nil {
+		return nil
+	}
+	for _, p := range a.Snapshot() {
+		acct := poolAccount(p.ID)
+		for _, r := range []reserve{{p.TokenA, p.ResA}, {p.TokenB, p.ResB}} {
+			tok, ok := GetToken(r.token)
+			if !ok {
+				return fmt.Errorf("pool %d: token %d not registered", p.ID, r.token)
+			}
+			if held := tok.BalanceOf(acct); held < r.bal {
+				return fmt.Errorf("pool %d: account holds %d of token %d, reserve is %d", p.ID, held, r.token, r.bal)
+			}
+		}
+	}
+	return nil
+}
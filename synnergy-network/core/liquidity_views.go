@@ -9,6 +9,13 @@ type PoolView struct {
 	ResB    uint64
 	TotalLP uint64
 	FeeBps  uint16
+	Type    PoolType
+	Amp     uint64 // stable-swap amplification, 0 for constant product
+	// Protocol fee switch accounting: fees taken by the protocol and LP
+	// minted to the treasury.
+	ProtocolFeeA uint64
+	ProtocolFeeB uint64
+	ProtocolLP   uint64
 }
 
 // Snapshot returns a slice of PoolView describing all pools managed by the AMM.
@@ -26,6 +33,12 @@ func (a *AMM) Snapshot() []PoolView {
 			ResB:    p.resB,
 			TotalLP: p.totalLP,
 			FeeBps:  p.feeBps,
+			Type:    p.kind,
+			Amp:     p.amp,
+
+			ProtocolFeeA: p.protoFeeA,
+			ProtocolFeeB: p.protoFeeB,
+			ProtocolLP:   p.protoLP,
 		}
 		p.mu.RUnlock()
 		out = append(out, pv)
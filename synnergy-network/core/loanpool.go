@@ -26,7 +26,6 @@ import (
 	"errors"
 	"fmt"
 	"log"
-	"strings"
 	"sync"
 	"time"
 )
@@ -167,21 +166,10 @@ func NewLoanPool(lg *log.Logger, led StateRW, auth electorateSelector, cfg *Loan
 	return lp
 }
 
-func StringToAddress(hexStr string) (Address, error) {
-	var addr Address
-
-	// Strip "0x" prefix if present
-	hexStr = strings.TrimPrefix(hexStr, "0x")
-	data, err := hex.DecodeString(hexStr)
-	if err != nil {
-		return addr, err
-	}
-	if len(data) != 20 {
-		return addr, errors.New("invalid address length")
-	}
-
-	copy(addr[:], data)
-	return addr, nil
+// StringToAddress accepts bech32 and legacy hex addresses; see
+// DecodeAddress.
+func StringToAddress(s string) (Address, error) {
+	return DecodeAddress(s)
 }
 
 //---------------------------------------------------------------------
@@ -397,9 +385,14 @@ func (lp *LoanPool) Disburse(id Hash) error {
 	}
 	fee := p.Amount / 20 // 5% authority fee
 	payout := p.Amount - fee
+	cat := TreasuryGrant
+	if p.Type == StandardLoan {
+		cat = TreasuryLoan
+	}
 	if err := lp.ledger.Transfer(LoanPoolAccount, p.Recipient, payout); err != nil {
 		return err
 	}
+	recordTreasury(lp.ledger, cat, p.Recipient, payout, id.Hex())
 	if fee > 0 {
 		elect, err := lp.auth.RandomElectorate(5)
 		if err == nil && len(elect) > 0 {
@@ -407,12 +400,16 @@ func (lp *LoanPool) Disburse(id Hash) error {
 			for _, a := range elect {
 				node, err := lp.auth.GetAuthority(a)
 				if err == nil && node.Wallet != AddressZero {
-					_ = lp.ledger.Transfer(LoanPoolAccount, node.Wallet, share)
+					if lp.ledger.Transfer(LoanPoolAccount, node.Wallet, share) == nil {
+						recordTreasury(lp.ledger, TreasuryAuthorityFee, node.Wallet, share, id.Hex())
+					}
 				}
 			}
 		} else {
 			// fallback – refund fee to recipient if electorate unavailable
-			_ = lp.ledger.Transfer(LoanPoolAccount, p.Recipient, fee)
+			if lp.ledger.Transfer(LoanPoolAccount, p.Recipient, fee) == nil {
+				recordTreasury(lp.ledger, cat, p.Recipient, fee, id.Hex())
+			}
 		}
 	}
 	p.Status = Executed
@@ -471,7 +468,11 @@ func (lp *LoanPool) redistribute(now time.Time) {
 		return
 	}
 	// send to CharityPoolAccount as ecosystem support
-	_ = lp.ledger.Transfer(LoanPoolAccount, CharityPoolAccount, amt)
+	if err := lp.ledger.Transfer(LoanPoolAccount, CharityPoolAccount, amt); err != nil {
+		lp.logger.Printf("redistribution failed: %v", err)
+		return
+	}
+	recordTreasury(lp.ledger, TreasuryRedistribution, CharityPoolAccount, amt, "")
 	lp.logger.Printf("redistributed %d wei from loan pool to charity", amt)
 }
 
@@ -157,6 +157,7 @@ func (lp *LoanPoolApply) Disburse(id Hash) error {
 	if err := lp.ledger.Transfer(LoanPoolAccount, app.Applicant, app.Amount); err != nil {
 		return err
 	}
+	recordTreasury(lp.ledger, TreasuryLoan, app.Applicant, app.Amount, id.Hex())
 	app.Status = LoanFunded
 	app.FundedAt = time.Now().Unix()
 	lp.ledger.SetState(lp.key(id), mustJSON(app))
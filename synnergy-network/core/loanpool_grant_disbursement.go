@@ -68,6 +68,7 @@ func (gd *GrantDisbursement) ReleaseGrant(id Hash) error {
 	if err := gd.ledger.Transfer(LoanPoolAccount, g.Recipient, g.Amount); err != nil {
 		return err
 	}
+	recordTreasury(gd.ledger, TreasuryGrant, g.Recipient, g.Amount, id.Hex())
 	g.Released = true
 	g.ReleasedAt = time.Now().Unix()
 	if err := gd.ledger.SetState(gd.key(id), g.Marshal()); err != nil {
@@ -0,0 +1,219 @@
+package core
+
+// loanpool_treasury.go – LoanPool treasury accounting.
+//
+// Every coin movement into or out of LoanPoolAccount is recorded as a
+// categorised TreasuryEntry under loanpool:treasury:<seq>. Inflows are the
+// loan pool share of transaction fees and of block rewards; outflows are
+// grants, loans, the authority fee taken from disbursements and the
+// periodic surplus redistribution. Each entry carries the treasury balance
+// after it, so the entries double as the balance history. A TreasuryFlowReport
+// aggregates a time range by category for the explorer and
+// `synnergy loanpool report`.
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// TreasuryCategory classifies a treasury entry.
+type TreasuryCategory string
+
+const (
+	TreasuryFeeShare       TreasuryCategory = "fee_share"
+	TreasuryBlockReward    TreasuryCategory = "block_reward"
+	TreasuryGrant          TreasuryCategory = "grant"
+	TreasuryLoan           TreasuryCategory = "loan"
+	TreasuryAuthorityFee   TreasuryCategory = "authority_fee"
+	TreasuryRedistribution TreasuryCategory = "redistribution"
+)
+
+// Inflow reports whether entries of the category credit the treasury.
+func (c TreasuryCategory) Inflow() bool {
+	return c == TreasuryFeeShare || c == TreasuryBlockReward
+}
+
+// TreasuryEntry is one recorded treasury movement.
+type TreasuryEntry struct {
+	Seq          uint64           `json:"seq"`
+	Height       uint64           `json:"height"`
+	Time         int64            `json:"time_unix"`
+	Category     TreasuryCategory `json:"category"`
+	Amount       uint64           `json:"amount"`
+	Balance      uint64           `json:"balance"` // treasury balance after the entry
+	Counterparty Address          `json:"counterparty"`
+	Ref          string           `json:"ref,omitempty"` // proposal, grant or application ID
+}
+
+// TreasuryFlowReport summarises the treasury entries of a time range.
+type TreasuryFlowReport struct {
+	From       int64                       `json:"from_unix"`
+	To         int64                       `json:"to_unix"`
+	Opening    uint64                      `json:"opening_balance"`
+	Closing    uint64                      `json:"closing_balance"`
+	Inflows    uint64                      `json:"inflows"`
+	Outflows   uint64                      `json:"outflows"`
+	Categories map[TreasuryCategory]uint64 `json:"categories"`
+	Entries    int                         `json:"entries"`
+}
+
+type treasuryStore interface {
+	GetState(key []byte) ([]byte, error)
+	SetState(key, value []byte) error
+	BalanceOf(addr Address) uint64
+}
+
+type treasuryReader interface {
+	PrefixIterator(prefix []byte) StateIterator
+}
+
+const treasurySeqKey = "loanpool:treasury-seq"
+
+var treasuryMu sync.Mutex
+
+func treasuryEntryKey(seq uint64) []byte {
+	return []byte(fmt.Sprintf("loanpool:treasury:%020d", seq))
+}
+
+// RecordTreasuryFlow records amt of category moved between the treasury and
+// counterparty. It is called after the transfer so the entry carries the
+// resulting balance. Zero amounts are not recorded.
+func RecordTreasuryFlow(st treasuryStore, cat TreasuryCategory, counterparty Address, amt uint64, ref string) error {
+	if amt == 0 {
+		return nil
+	}
+	treasuryMu.Lock()
+	defer treasuryMu.Unlock()
+
+	var seq uint64
+	if raw, err := st.GetState([]byte(treasurySeqKey)); err == nil && len(raw) == 8 {
+		seq = binary.BigEndian.Uint64(raw)
+	}
+	seq++
+	e := TreasuryEntry{
+		Seq:          seq,
+		Time:         time.Now().Unix(),
+		Category:     cat,
+		Amount:       amt,
+		Balance:      st.BalanceOf(LoanPoolAccount),
+		Counterparty: counterparty,
+		Ref:          ref,
+	}
+	if h, ok := st.(interface{ LastHeight() uint64 }); ok {
+		e.Height = h.LastHeight()
+	}
+	raw, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if err := st.SetState(treasuryEntryKey(seq), raw); err != nil {
+		return err
+	}
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, seq)
+	return st.SetState([]byte(treasurySeqKey), buf)
+}
+
+// recordTreasury is RecordTreasuryFlow for paths that already moved the
+// funds; a failure to record is logged rather than undoing the transfer.
+func recordTreasury(st treasuryStore, cat TreasuryCategory, counterparty Address, amt uint64, ref string) {
+	if err := RecordTreasuryFlow(st, cat, counterparty, amt, ref); err != nil {
+		logrus.Warnf("loanpool treasury: record %s of %d: %v", cat, amt, err)
+	}
+}
+
+// TreasuryEntries returns the entries recorded between from and to (unix
+// seconds, inclusive) in order.
+func TreasuryEntries(st treasuryReader, from, to int64) ([]TreasuryEntry, error) {
+	all, err := treasuryEntries(st)
+	if err != nil {
+		return nil, err
+	}
+	out := []TreasuryEntry{}
+	for _, e := range all {
+		if e.Time >= from && e.Time <= to {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+func treasuryEntries(st treasuryReader) ([]TreasuryEntry, error) {
+	it := st.PrefixIterator([]byte("loanpool:treasury:"))
+	var out []TreasuryEntry
+	for it.Next() {
+		var e TreasuryEntry
+		if err := json.Unmarshal(it.Value(), &e); err != nil {
+			return nil, err
+		}
+		out = append(out, e)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Seq < out[j].Seq })
+	return out, nil
+}
+
+// GetTreasuryFlowReport aggregates the entries between from and to (unix
+// seconds, inclusive). The opening balance is the balance after the last
+// earlier entry, or before the first entry when there is none.
+func GetTreasuryFlowReport(st treasuryReader, from, to int64) (*TreasuryFlowReport, error) {
+	all, err := treasuryEntries(st)
+	if err != nil {
+		return nil, err
+	}
+	rep := &TreasuryFlowReport{From: from, To: to, Categories: make(map[TreasuryCategory]uint64)}
+	opened := false
+	for _, e := range all {
+		if e.Time < from {
+			rep.Opening, opened = e.Balance, true
+			continue
+		}
+		if e.Time > to {
+			break
+		}
+		if !opened {
+			rep.Opening, opened = balanceBefore(e), true
+		}
+		rep.Entries++
+		rep.Categories[e.Category] += e.Amount
+		if e.Category.Inflow() {
+			rep.Inflows += e.Amount
+		} else {
+			rep.Outflows += e.Amount
+		}
+		rep.Closing = e.Balance
+	}
+	if rep.Entries == 0 {
+		rep.Closing = rep.Opening
+	}
+	return rep, nil
+}
+
+func balanceBefore(e TreasuryEntry) uint64 {
+	if e.Category.Inflow() {
+		return e.Balance - e.Amount
+	}
+	return e.Balance + e.Amount
+}
+
+// ParseTreasuryTime parses a report bound given as unix seconds, RFC 3339
+// or a 2006-01-02 date (UTC midnight).
+func ParseTreasuryTime(s string) (int64, error) {
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return n, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t.Unix(), nil
+	}
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return 0, NewError(CodeInvalidArgument, "loanpool", "invalid time %q", s)
+	}
+	return t.Unix(), nil
+}
@@ -0,0 +1,71 @@
+package core
+
+import (
+	"bytes"
+	"sort"
+	"testing"
+)
+
+type treasuryMemStore struct {
+	state map[string][]byte
+	bal   uint64
+}
+
+func (m *treasuryMemStore) GetState(k []byte) ([]byte, error) { return m.state[string(k)], nil }
+func (m *treasuryMemStore) SetState(k, v []byte) error        { m.state[string(k)] = v; return nil }
+func (m *treasuryMemStore) BalanceOf(Address) uint64          { return m.bal }
+
+func (m *treasuryMemStore) PrefixIterator(prefix []byte) StateIterator {
+	it := &memIter{idx: -1}
+	for k := range m.state {
+		if bytes.HasPrefix([]byte(k), prefix) {
+			it.keys = append(it.keys, []byte(k))
+		}
+	}
+	sort.Slice(it.keys, func(i, j int) bool { return bytes.Compare(it.keys[i], it.keys[j]) < 0 })
+	for _, k := range it.keys {
+		it.values = append(it.values, m.state[string(k)])
+	}
+	return it
+}
+
+func TestTreasuryFlowReport(t *testing.T) {
+	st := &treasuryMemStore{state: make(map[string][]byte), bal: 100}
+	flow := func(cat TreasuryCategory, amt uint64) {
+		if cat.Inflow() {
+			st.bal += amt
+		} else {
+			st.bal -= amt
+		}
+		if err := RecordTreasuryFlow(st, cat, Address{1}, amt, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	flow(TreasuryFeeShare, 50)
+	flow(TreasuryBlockReward, 200)
+	flow(TreasuryGrant, 120)
+	flow(TreasuryFeeShare, 30)
+	flow(TreasuryRedistribution, 60)
+	flow(TreasuryLoan, 0) // not recorded
+
+	entries, err := TreasuryEntries(st, 0, 1<<62)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 5 || entries[2].Category != TreasuryGrant || entries[2].Balance != 230 {
+		t.Fatalf("entries = %+v", entries)
+	}
+	rep, err := GetTreasuryFlowReport(st, 0, 1<<62)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.Opening != 100 || rep.Closing != 200 || rep.Inflows != 280 || rep.Outflows != 180 || rep.Entries != 5 {
+		t.Fatalf("report = %+v", rep)
+	}
+	if rep.Categories[TreasuryFeeShare] != 80 || rep.Categories[TreasuryRedistribution] != 60 {
+		t.Fatalf("categories = %v", rep.Categories)
+	}
+	if rep, _ := GetTreasuryFlowReport(st, 1<<62, 1<<62); rep.Entries != 0 || rep.Opening != 200 || rep.Closing != 200 {
+		t.Fatalf("empty period = %+v", rep)
+	}
+}
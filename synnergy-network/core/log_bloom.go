@@ -0,0 +1,215 @@
+package core
+
+// log_bloom.go – per-block log bloom filters and log queries.
+//
+// Every block carries the logs its transactions emitted in Body.Logs and a
+// 2048-bit bloom filter over their contract addresses and topics in
+// Header.LogsBloom. Each address or topic sets three bits taken from its
+// SHA-256. A filter never misses an item that was added, so FilterLogs only
+// opens blocks whose bloom may contain the queried addresses and topics and
+// skips the rest of a long range without reading its logs.
+//
+// Imported blocks must carry the bloom of their own logs; applyBlock rejects
+// a block whose header bloom differs from the one rebuilt from its body.
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// BloomLength is the size of a log bloom in bytes.
+const BloomLength = 256
+
+// ErrBloomMismatch is returned for a block whose header bloom does not
+// match its logs.
+var ErrBloomMismatch = errors.New("logs bloom mismatch")
+
+// Bloom is a 2048-bit bloom filter over log addresses and topics.
+type Bloom [BloomLength]byte
+
+// bloomBits returns the three bit positions of data.
+func bloomBits(data []byte) [3]uint {
+	h := sha256.Sum256(data)
+	var bits [3]uint
+	for i := range bits {
+		bits[i] = (uint(h[2*i])<<8 | uint(h[2*i+1])) & (BloomLength*8 - 1)
+	}
+	return bits
+}
+
+// Add sets the bits of data.
+func (b *Bloom) Add(data []byte) {
+	for _, bit := range bloomBits(data) {
+		b[BloomLength-1-bit/8] |= 1 << (bit % 8)
+	}
+}
+
+// Test reports whether data may have been added. False positives are
+// possible, false negatives are not.
+func (b Bloom) Test(data []byte) bool {
+	for _, bit := range bloomBits(data) {
+		if b[BloomLength-1-bit/8]&(1<<(bit%8)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// AddLog adds the address and topics of lg.
+func (b *Bloom) AddLog(lg *Log) {
+	b.Add(lg.Address[:])
+	for _, t := range lg.Topics {
+		b.Add(t[:])
+	}
+}
+
+// LogsBloom returns the bloom of logs.
+func LogsBloom(logs []Log) Bloom {
+	var b Bloom
+	for i := range logs {
+		b.AddLog(&logs[i])
+	}
+	return b
+}
+
+// MarshalText encodes the bloom as hex.
+func (b Bloom) MarshalText() ([]byte, error) {
+	return []byte(hex.EncodeToString(b[:])), nil
+}
+
+// UnmarshalText decodes a hex bloom.
+func (b *Bloom) UnmarshalText(text []byte) error {
+	if hex.DecodedLen(len(text)) != BloomLength {
+		return fmt.Errorf("bloom must be %d bytes", BloomLength)
+	}
+	_, err := hex.Decode(b[:], text)
+	return err
+}
+
+// VerifyLogsBloom checks that the header bloom is the bloom of the block's
+// logs.
+func (b *Block) VerifyLogsBloom() error {
+	if LogsBloom(b.Body.Logs) != b.Header.LogsBloom {
+		return ErrBloomMismatch
+	}
+	return nil
+}
+
+// LogFilter selects logs by block range, contract and topics. An empty
+// Addresses matches every contract. Topics[i] lists the accepted values of
+// the i-th topic; an empty position matches any value.
+type LogFilter struct {
+	FromBlock uint64          `json:"from_block"`
+	ToBlock   uint64          `json:"to_block"` // 0 = latest
+	Addresses []Address       `json:"addresses,omitempty"`
+	Topics    [][]common.Hash `json:"topics,omitempty"`
+}
+
+// MayContain reports whether a block with bloom b can hold a log matching f.
+func (f *LogFilter) MayContain(b Bloom) bool {
+	if len(f.Addresses) > 0 {
+		hit := false
+		for _, a := range f.Addresses {
+			if b.Test(a[:]) {
+				hit = true
+				break
+			}
+		}
+		if !hit {
+			return false
+		}
+	}
+	for _, alts := range f.Topics {
+		if len(alts) == 0 {
+			continue
+		}
+		hit := false
+		for _, t := range alts {
+			if b.Test(t[:]) {
+				hit = true
+				break
+			}
+		}
+		if !hit {
+			return false
+		}
+	}
+	return true
+}
+
+// Matches reports whether lg satisfies f, ignoring the block range.
+func (f *LogFilter) Matches(lg *Log) bool {
+	if len(f.Addresses) > 0 {
+		hit := false
+		for _, a := range f.Addresses {
+			if a == lg.Address {
+				hit = true
+				break
+			}
+		}
+		if !hit {
+			return false
+		}
+	}
+	if len(f.Topics) > len(lg.Topics) {
+		return false
+	}
+	for i, alts := range f.Topics {
+		if len(alts) == 0 {
+			continue
+		}
+		hit := false
+		for _, t := range alts {
+			if t == lg.Topics[i] {
+				hit = true
+				break
+			}
+		}
+		if !hit {
+			return false
+		}
+	}
+	return true
+}
+
+// FilterLogs returns the logs of retained blocks matching f, in chain
+// order. Blocks whose bloom rules out a match are not scanned.
+func (l *Ledger) FilterLogs(f LogFilter) ([]Log, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return filterLogs(l.Blocks, f)
+}
+
+// FilterLogs is Ledger.FilterLogs over the blocks of the view.
+func (v *ReadView) FilterLogs(f LogFilter) ([]Log, error) {
+	return filterLogs(v.blocks, f)
+}
+
+func filterLogs(blocks []*Block, f LogFilter) ([]Log, error) {
+	if f.ToBlock != 0 && f.ToBlock < f.FromBlock {
+		return nil, NewError(CodeInvalidArgument, "logs", "to_block %d before from_block %d", f.ToBlock, f.FromBlock)
+	}
+	var out []Log
+	for _, blk := range blocks {
+		h := blk.Header.Height
+		if h < f.FromBlock {
+			continue
+		}
+		if f.ToBlock != 0 && h > f.ToBlock {
+			break
+		}
+		if len(blk.Body.Logs) == 0 || !f.MayContain(blk.Header.LogsBloom) {
+			continue
+		}
+		for i := range blk.Body.Logs {
+			if f.Matches(&blk.Body.Logs[i]) {
+				out = append(out, blk.Body.Logs[i])
+			}
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,115 @@
+package core
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/sirupsen/logrus"
+
+	"synnergy-network/pkg/logging"
+)
+
+// Module loggers live in pkg/logging so servers that do not link the node
+// share them; the functions below forward to it. Every subsystem logs
+// through ModuleLogger(name), which tags entries with a "module" field and
+// has its own level so operators can turn one module up to debug without
+// flooding the rest. Levels come from the environment at start-up and can
+// be changed at runtime through SetModuleLevel or the /log/levels endpoint:
+//
+//	LOG_LEVEL=info                       default level of every module
+//	LOG_LEVELS=consensus=debug,p2p=warn  per-module overrides
+//	LOG_FORMAT=json                      JSON lines instead of text
+
+// ModuleLogger returns the logger of module. Entries carry module=<name>.
+func ModuleLogger(module string) *logrus.Entry { return logging.ModuleLogger(module) }
+
+// ContextLogger returns the logger of module for work done on behalf of
+// ctx; entries carry the request ID stored by WithRequestID, if any.
+func ContextLogger(ctx context.Context, module string) *logrus.Entry {
+	return logging.ContextLogger(ctx, module)
+}
+
+// SetModuleLevel changes the level of module at runtime. An empty module
+// sets the default level, which applies to every module without an
+// override of its own.
+func SetModuleLevel(module string, lvl logrus.Level) { logging.SetModuleLevel(module, lvl) }
+
+// SetLogOutput redirects every module logger to w.
+func SetLogOutput(w io.Writer) { logging.SetOutput(w) }
+
+// LogLevels is the body of GET /log/levels: the default level and the
+// effective level of every module that has logged or been configured.
+type LogLevels = logging.Levels
+
+// ModuleLevels reports the current log levels.
+func ModuleLevels() LogLevels { return logging.ModuleLevels() }
+
+// SetLogLevelRequest is the body of PUT /log/levels. An empty Module sets
+// the default level.
+type SetLogLevelRequest struct {
+	Module string `json:"module"`
+	Level  string `json:"level"`
+}
+
+// LogLevelHandler serves the log level control API:
+//
+//	GET /log/levels   current levels (LogLevels)
+//	PUT /log/levels   change one level (SetLogLevelRequest)
+//
+// When LOG_ADMIN_TOKEN is set, changes require it as a bearer token.
+func LogLevelHandler(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodGet:
+		writeJSON(w, ModuleLevels())
+	case http.MethodPut, http.MethodPost:
+		if tok := os.Getenv("LOG_ADMIN_TOKEN"); tok != "" &&
+			subtle.ConstantTimeCompare([]byte(req.Header.Get("Authorization")), []byte("Bearer "+tok)) != 1 {
+			WriteHTTPError(w, http.StatusUnauthorized, "log", NewError(CodeUnauthenticated, "log", "admin token required"))
+			return
+		}
+		req.Body = http.MaxBytesReader(w, req.Body, 1<<16)
+		defer req.Body.Close()
+		var body SetLogLevelRequest
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			WriteHTTPError(w, http.StatusBadRequest, "log", err)
+			return
+		}
+		lvl, err := logrus.ParseLevel(body.Level)
+		if err != nil {
+			WriteHTTPError(w, http.StatusBadRequest, "log", NewError(CodeInvalidArgument, "log", "%v", err))
+			return
+		}
+		SetModuleLevel(body.Module, lvl)
+		ContextLogger(req.Context(), "log").WithFields(logrus.Fields{"target": body.Module, "level": lvl.String()}).Info("log level changed")
+		writeJSON(w, ModuleLevels())
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// RequestIDHeader carries the request ID between services and back to the
+// client.
+const RequestIDHeader = logging.RequestIDHeader
+
+// WithRequestID stores a request ID in ctx.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return logging.WithRequestID(ctx, id)
+}
+
+// RequestIDFrom returns the request ID stored in ctx, or "".
+func RequestIDFrom(ctx context.Context) string { return logging.RequestIDFrom(ctx) }
+
+// NewRequestID returns a random 16-byte hex identifier.
+func NewRequestID() string { return logging.NewRequestID() }
+
+// RequestLogger returns middleware that assigns every request an ID,
+// stores it in the request context for ContextLogger, echoes it in the
+// X-Request-ID response header and logs one access line per request on the
+// logger of module.
+func RequestLogger(module string) func(http.Handler) http.Handler {
+	return logging.RequestLogger(module)
+}
@@ -0,0 +1,439 @@
+package core
+
+// lp_positions.go – liquidity provider position tracking.
+//
+// Every AddLiquidity / RemoveLiquidity updates the provider's position in the
+// pool: LP units held, the cost basis still in the pool and the amounts taken
+// out. Swap fees that stay in the pool are accounted through a per-pool fee
+// growth accumulator (fees per LP unit, as in Uniswap v2 style fee tracking)
+// so each position knows the fees it accrued without iterating providers.
+//
+// Values are expressed in units of token B using the current pool price
+// (resB/resA). Impermanent loss compares the position value net of fees with
+// holding the remaining cost basis. Pool APR is sampled periodically from the
+// fee growth and stored as history.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	lpPositionPrefix = "amm:lppos:"
+	lpPoolFeePrefix  = "amm:lpfee:"
+	lpAPRPrefix      = "amm:lpapr:"
+	secondsPerYear   = 365 * 24 * 3600
+)
+
+// LPPositionEvent is one entry or exit of a position.
+type LPPositionEvent struct {
+	Action  string `json:"action"` // add | remove
+	LP      uint64 `json:"lp"`
+	AmountA uint64 `json:"amount_a"`
+	AmountB uint64 `json:"amount_b"`
+	Height  uint64 `json:"height"`
+	Time    int64  `json:"time"`
+}
+
+// LPPosition is the persisted state of a provider's stake in a pool.
+type LPPosition struct {
+	Pool     PoolID  `json:"pool"`
+	Provider Address `json:"provider"`
+	LP       uint64  `json:"lp"`
+	// BasisA/B is the deposit still attributed to the open LP units; exits
+	// reduce it pro rata.
+	BasisA     uint64 `json:"basis_a"`
+	BasisB     uint64 `json:"basis_b"`
+	WithdrawnA uint64 `json:"withdrawn_a"`
+	WithdrawnB uint64 `json:"withdrawn_b"`
+	// FeesA/B are fees settled at the last checkpoint; GrowthA/B is the pool
+	// fee growth at that checkpoint.
+	FeesA    float64           `json:"fees_a"`
+	FeesB    float64           `json:"fees_b"`
+	GrowthA  float64           `json:"growth_a"`
+	GrowthB  float64           `json:"growth_b"`
+	OpenedAt int64             `json:"opened_at"`
+	History  []LPPositionEvent `json:"history"`
+}
+
+// lpPoolFees is the fee accumulator of a pool.
+type lpPoolFees struct {
+	GrowthA float64 `json:"growth_a"` // token A fees per LP unit
+	GrowthB float64 `json:"growth_b"`
+	TotalA  uint64  `json:"total_a"`
+	TotalB  uint64  `json:"total_b"`
+}
+
+// LPPositionView is a position valued at current pool state.
+type LPPositionView struct {
+	LPPosition
+	Open     bool    `json:"open"`
+	Share    float64 `json:"share"` // fraction of pool LP supply
+	CurrentA uint64  `json:"current_a"`
+	CurrentB uint64  `json:"current_b"`
+	// Value, HoldValue and FeeValue are in token B units.
+	Value     float64 `json:"value"`
+	HoldValue float64 `json:"hold_value"`
+	FeeValue  float64 `json:"fee_value"`
+	// ImpermanentLoss is (value - fees) / hold - 1; negative means loss.
+	ImpermanentLoss float64 `json:"impermanent_loss"`
+	APR             float64 `json:"apr"`
+}
+
+// PoolAPRSample is one point of a pool's APR history.
+type PoolAPRSample struct {
+	Pool    PoolID  `json:"pool"`
+	Time    int64   `json:"time"`
+	Height  uint64  `json:"height"`
+	GrowthA float64 `json:"growth_a"`
+	GrowthB float64 `json:"growth_b"`
+	ResA    uint64  `json:"res_a"`
+	ResB    uint64  `json:"res_b"`
+	TotalLP uint64  `json:"total_lp"`
+	// APR is the annualised fee yield since the previous sample.
+	APR float64 `json:"apr"`
+}
+
+func lpPositionKey(pid PoolID, provider Address) []byte {
+	return []byte(fmt.Sprintf("%s%010d:%s", lpPositionPrefix, pid, provider.Hex()))
+}
+
+func lpPoolFeeKey(pid PoolID) []byte {
+	return []byte(fmt.Sprintf("%s%010d", lpPoolFeePrefix, pid))
+}
+
+func lpAPRKey(pid PoolID, ts int64) []byte {
+	return []byte(fmt.Sprintf("%s%010d:%020d", lpAPRPrefix, pid, ts))
+}
+
+// lpState returns the store positions live in, or nil for an AMM started
+// without a ledger.
+func (a *AMM) lpState() StateRW {
+	if a.ledger == nil {
+		return nil
+	}
+	return a.ledger
+}
+
+// readViewer is implemented by stores that publish read snapshots.
+type readViewer interface {
+	ReadView() *ReadView
+}
+
+// lpReadState returns the store position queries read: the published read
+// view when the store has one, so queries do not contend with block import.
+func (a *AMM) lpReadState() StateReader {
+	if rv, ok := a.ledger.(readViewer); ok {
+		return rv.ReadView()
+	}
+	return a.lpState()
+}
+
+func lpGet(st StateReader, key []byte, v interface{}) (bool, error) {
+	raw, err := st.GetState(key)
+	if err != nil || len(raw) == 0 {
+		return false, err
+	}
+	return true, json.Unmarshal(raw, v)
+}
+
+func lpPut(st StateRW, key []byte, v interface{}) error {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return st.SetState(key, raw)
+}
+
+func (a *AMM) poolFees(st StateReader, pid PoolID) (lpPoolFees, error) {
+	var f lpPoolFees
+	_, err := lpGet(st, lpPoolFeeKey(pid), &f)
+	return f, err
+}
+
+// settle moves fees accrued since the last checkpoint into FeesA/B.
+func (p *LPPosition) settle(f lpPoolFees) {
+	p.FeesA += float64(p.LP) * (f.GrowthA - p.GrowthA)
+	p.FeesB += float64(p.LP) * (f.GrowthB - p.GrowthB)
+	p.GrowthA, p.GrowthB = f.GrowthA, f.GrowthB
+}
+
+// recordLPEntry updates the provider position after minting LP units.
+// Called inside the AddLiquidity ledger snapshot.
+func (a *AMM) recordLPEntry(pid PoolID, provider Address, amtA, amtB, minted uint64) error {
+	st := a.lpState()
+	if st == nil {
+		return nil
+	}
+	f, err := a.poolFees(st, pid)
+	if err != nil {
+		return err
+	}
+	pos := LPPosition{Pool: pid, Provider: provider}
+	if _, err := lpGet(st, lpPositionKey(pid, provider), &pos); err != nil {
+		return err
+	}
+	pos.settle(f)
+	if pos.LP == 0 {
+		pos.OpenedAt = time.Now().Unix()
+	}
+	pos.LP += minted
+	pos.BasisA += amtA
+	pos.BasisB += amtB
+	pos.History = append(pos.History, LPPositionEvent{Action: "add", LP: minted, AmountA: amtA, AmountB: amtB, Height: currentHeight(), Time: time.Now().Unix()})
+	return lpPut(st, lpPositionKey(pid, provider), pos)
+}
+
+// recordLPExit updates the provider position after burning LP units.
+// Called inside the RemoveLiquidity ledger snapshot.
+func (a *AMM) recordLPExit(pid PoolID, provider Address, burned, amtA, amtB uint64) error {
+	st := a.lpState()
+	if st == nil {
+		return nil
+	}
+	f, err := a.poolFees(st, pid)
+	if err != nil {
+		return err
+	}
+	var pos LPPosition
+	found, err := lpGet(st, lpPositionKey(pid, provider), &pos)
+	if err != nil {
+		return err
+	}
+	if !found {
+		// LP units minted before tracking started; open an untracked basis.
+		pos = LPPosition{Pool: pid, Provider: provider, LP: burned, GrowthA: f.GrowthA, GrowthB: f.GrowthB, OpenedAt: time.Now().Unix()}
+	}
+	pos.settle(f)
+	if burned > pos.LP {
+		burned = pos.LP
+	}
+	if pos.LP > 0 {
+		pos.BasisA -= pos.BasisA * burned / pos.LP
+		pos.BasisB -= pos.BasisB * burned / pos.LP
+	}
+	pos.LP -= burned
+	pos.WithdrawnA += amtA
+	pos.WithdrawnB += amtB
+	pos.History = append(pos.History, LPPositionEvent{Action: "remove", LP: burned, AmountA: amtA, AmountB: amtB, Height: currentHeight(), Time: time.Now().Unix()})
+	return lpPut(st, lpPositionKey(pid, provider), pos)
+}
+
+// recordLPFee adds the LP share of a swap fee to the pool fee growth. It is
+// called after the fee has been added to the reserves.
+func (a *AMM) recordLPFee(pool *Pool, tokenIn TokenID, lpFee uint64) error {
+	st := a.lpState()
+	if st == nil || lpFee == 0 || pool.totalLP == 0 {
+		return nil
+	}
+	f, err := a.poolFees(st, pool.ID)
+	if err != nil {
+		return err
+	}
+	perLP := float64(lpFee) / float64(pool.totalLP)
+	if tokenIn == pool.tokenA {
+		f.GrowthA += perLP
+		f.TotalA += lpFee
+	} else {
+		f.GrowthB += perLP
+		f.TotalB += lpFee
+	}
+	return lpPut(st, lpPoolFeeKey(pool.ID), f)
+}
+
+// LPPositions returns every position of provider, valued at current pool
+// state and sorted by pool.
+func LPPositions(provider Address) ([]LPPositionView, error) {
+	a := Manager()
+	if a == nil {
+		return nil, fmt.Errorf("AMM not initialised")
+	}
+	st := a.lpReadState()
+	if st == nil {
+		return nil, fmt.Errorf("AMM has no state store")
+	}
+	suffix := ":" + provider.Hex()
+	it := st.PrefixIterator([]byte(lpPositionPrefix))
+	var out []LPPositionView
+	for it.Next() {
+		if !strings.HasSuffix(string(it.Key()), suffix) {
+			continue
+		}
+		var pos LPPosition
+		if err := json.Unmarshal(it.Value(), &pos); err != nil {
+			return nil, err
+		}
+		v, err := a.valuePosition(st, pos)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+	if err := it.Error(); err != nil {
+		return nil, err
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Pool < out[j].Pool })
+	return out, nil
+}
+
+// LPPositionOf returns the position of provider in a single pool.
+func LPPositionOf(pid PoolID, provider Address) (LPPositionView, error) {
+	a := Manager()
+	if a == nil {
+		return LPPositionView{}, fmt.Errorf("AMM not initialised")
+	}
+	st := a.lpState()
+	if st == nil {
+		return LPPositionView{}, fmt.Errorf("AMM has no state store")
+	}
+	var pos LPPosition
+	found, err := lpGet(st, lpPositionKey(pid, provider), &pos)
+	if err != nil {
+		return LPPositionView{}, err
+	}
+	if !found {
+		return LPPositionView{}, ErrNotFound
+	}
+	return a.valuePosition(st, pos)
+}
+
+func (a *AMM) valuePosition(st StateReader, pos LPPosition) (LPPositionView, error) {
+	f, err := a.poolFees(st, pos.Pool)
+	if err != nil {
+		return LPPositionView{}, err
+	}
+	pos.settle(f)
+	v := LPPositionView{LPPosition: pos, Open: pos.LP > 0}
+	pool, err := a.Pool(pos.Pool)
+	if err != nil {
+		return v, nil
+	}
+	pool.mu.RLock()
+	resA, resB, total := pool.resA, pool.resB, pool.totalLP
+	pool.mu.RUnlock()
+	if total == 0 || resA == 0 || pos.LP == 0 {
+		return v, nil
+	}
+	price := float64(resB) / float64(resA)
+	v.Share = float64(pos.LP) / float64(total)
+	v.CurrentA = uint64(v.Share * float64(resA))
+	v.CurrentB = uint64(v.Share * float64(resB))
+	v.Value = float64(v.CurrentA)*price + float64(v.CurrentB)
+	v.HoldValue = float64(pos.BasisA)*price + float64(pos.BasisB)
+	v.FeeValue = pos.FeesA*price + pos.FeesB
+	if v.HoldValue > 0 {
+		v.ImpermanentLoss = (v.Value-v.FeeValue)/v.HoldValue - 1
+		if age := time.Now().Unix() - pos.OpenedAt; age > 0 {
+			v.APR = v.FeeValue / v.HoldValue * secondsPerYear / float64(age)
+		}
+	}
+	return v, nil
+}
+
+// RecordPoolAPRSamples stores an APR sample for every pool and returns the
+// samples written.
+func RecordPoolAPRSamples() ([]PoolAPRSample, error) {
+	a := Manager()
+	if a == nil {
+		return nil, nil
+	}
+	st := a.lpState()
+	if st == nil {
+		return nil, nil
+	}
+	now := time.Now().Unix()
+	var out []PoolAPRSample
+	for _, p := range a.Snapshot() {
+		f, err := a.poolFees(st, p.ID)
+		if err != nil {
+			return out, err
+		}
+		s := PoolAPRSample{Pool: p.ID, Time: now, Height: currentHeight(), GrowthA: f.GrowthA, GrowthB: f.GrowthB, ResA: p.ResA, ResB: p.ResB, TotalLP: p.TotalLP}
+		if prev, ok, err := lastAPRSample(st, p.ID); err != nil {
+			return out, err
+		} else if ok && now > prev.Time && p.ResA > 0 && p.TotalLP > 0 {
+			price := float64(p.ResB) / float64(p.ResA)
+			feePerLP := (f.GrowthA-prev.GrowthA)*price + (f.GrowthB - prev.GrowthB)
+			valuePerLP := (float64(p.ResA)*price + float64(p.ResB)) / float64(p.TotalLP)
+			if valuePerLP > 0 {
+				s.APR = feePerLP / valuePerLP * secondsPerYear / float64(now-prev.Time)
+			}
+		}
+		if err := lpPut(st, lpAPRKey(p.ID, now), s); err != nil {
+			return out, err
+		}
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+func lastAPRSample(st StateReader, pid PoolID) (PoolAPRSample, bool, error) {
+	hist, err := poolAPRHistory(st, pid)
+	if err != nil || len(hist) == 0 {
+		return PoolAPRSample{}, false, err
+	}
+	return hist[len(hist)-1], true, nil
+}
+
+func poolAPRHistory(st StateReader, pid PoolID) ([]PoolAPRSample, error) {
+	it := st.PrefixIterator([]byte(fmt.Sprintf("%s%010d:", lpAPRPrefix, pid)))
+	var out []PoolAPRSample
+	for it.Next() {
+		var s PoolAPRSample
+		if err := json.Unmarshal(it.Value(), &s); err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Time < out[j].Time })
+	return out, it.Error()
+}
+
+// PoolAPRHistory returns up to limit of the most recent APR samples of a
+// pool, oldest first. Pass limit <= 0 for all of them.
+func PoolAPRHistory(pid PoolID, limit int) ([]PoolAPRSample, error) {
+	a := Manager()
+	if a == nil {
+		return nil, fmt.Errorf("AMM not initialised")
+	}
+	st := a.lpReadState()
+	if st == nil {
+		return nil, fmt.Errorf("AMM has no state store")
+	}
+	hist, err := poolAPRHistory(st, pid)
+	if err != nil {
+		return nil, err
+	}
+	if limit > 0 && len(hist) > limit {
+		hist = hist[len(hist)-limit:]
+	}
+	return hist, nil
+}
+
+// StartLPAPRSampler records pool APR samples every interval until ctx is
+// cancelled.
+func StartLPAPRSampler(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	go func() {
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+				if _, err := RecordPoolAPRSamples(); err != nil {
+					zap.L().Sugar().Warnf("pool APR sampling: %v", err)
+				}
+			}
+		}
+	}()
+}
@@ -9,7 +9,8 @@ import (
 	"go.uber.org/zap"
 )
 
-// MarketListing represents a generic item listed for sale on chain.
+// MarketListing represents a generic item listed for sale on chain. For
+// auctions Price is the start price; see marketplace_auctions.go.
 type MarketListing struct {
 	ID        string            `json:"id"`
 	Seller    Address           `json:"seller"`
@@ -18,6 +19,17 @@ type MarketListing struct {
 	CreatedAt time.Time         `json:"created_at"`
 	Sold      bool              `json:"sold"`
 	Buyer     Address           `json:"buyer"`
+
+	Kind            string      `json:"kind,omitempty"`
+	Item            *MarketItem `json:"item,omitempty"`
+	RoyaltyBps      uint16      `json:"royalty_bps,omitempty"`
+	EndsAt          time.Time   `json:"ends_at,omitempty"`
+	ReservePrice    uint64      `json:"reserve_price,omitempty"`
+	MinIncrementBps uint16      `json:"min_increment_bps,omitempty"`
+	HighBid         uint64      `json:"high_bid,omitempty"`
+	HighBidder      Address     `json:"high_bidder,omitempty"`
+	Settled         bool        `json:"settled"`
+	Sale            *MarketSale `json:"sale,omitempty"`
 }
 
 // MarketDeal tracks a purchase backed by escrow.
@@ -52,6 +64,9 @@ func CreateMarketListing(l *MarketListing) error {
 		l.ID = uuid.New().String()
 	}
 	l.CreatedAt = time.Now().UTC()
+	if err := prepareListing(l); err != nil {
+		return err
+	}
 	return saveMarketListing(l)
 }
 
@@ -99,6 +114,12 @@ func CancelListing(id string) error {
 	if l.Sold {
 		return fmt.Errorf("cannot cancel sold listing")
 	}
+	if l.HighBid > 0 {
+		return fmt.Errorf("cannot cancel auction with bids")
+	}
+	if err := returnItem(l); err != nil {
+		return err
+	}
 	key := fmt.Sprintf("market:list:%s", id)
 	return CurrentStore().Delete([]byte(key))
 }
@@ -114,6 +135,9 @@ func PurchaseItem(ctx *Context, listingID string, buyer Address) (*MarketDeal, e
 	if l.Sold {
 		return nil, fmt.Errorf("listing already sold")
 	}
+	if l.Kind != "" && l.Kind != ListingFixed {
+		return nil, fmt.Errorf("listing %s is an auction; use PlaceBid", l.ID)
+	}
 
 	escrowAcc := ModuleAddress("marketplace")
 	if err := Transfer(ctx, AssetRef{Kind: AssetCoin}, buyer, escrowAcc, l.Price); err != nil {
@@ -157,7 +181,11 @@ func PurchaseItem(ctx *Context, listingID string, buyer Address) (*MarketDeal, e
 }
 
 // ReleaseFunds releases an escrow to the seller and marks the deal closed.
+// Creator royalties are deducted and any NFT is delivered to the buyer.
 func ReleaseFunds(ctx *Context, escrowID string) error {
+	marketMu.Lock()
+	defer marketMu.Unlock()
+
 	key := fmt.Sprintf("market:escrow:%s", escrowID)
 	raw, err := CurrentStore().Get([]byte(key))
 	if err != nil || raw == nil {
@@ -171,34 +199,52 @@ func ReleaseFunds(ctx *Context, escrowID string) error {
 		return fmt.Errorf("escrow in invalid state")
 	}
 
-	escrowAcc := ModuleAddress("marketplace")
-	if err := Transfer(ctx, AssetRef{Kind: AssetCoin}, escrowAcc, esc.Seller, esc.Amount); err != nil {
-		return err
-	}
-
-	esc.State = "released"
-	upd, _ := json.Marshal(&esc)
-	if err := CurrentStore().Set([]byte(key), upd); err != nil {
-		return err
-	}
-
+	var deal *MarketDeal
+	var dealKey []byte
 	it := CurrentStore().Iterator([]byte("market:deal:"), nil)
-	defer it.Close()
 	for it.Next() {
 		var d MarketDeal
 		if err := json.Unmarshal(it.Value(), &d); err != nil {
 			continue
 		}
 		if d.EscrowID == escrowID && !d.Closed {
-			d.Closed = true
-			now := time.Now().UTC()
-			d.ClosedAt = &now
-			buf, _ := json.Marshal(&d)
-			_ = CurrentStore().Set(it.Key(), buf)
+			deal, dealKey = &d, append([]byte(nil), it.Key()...)
 			break
 		}
 	}
+	it.Close()
+
+	var listing *MarketListing
+	if deal != nil {
+		listing, _ = GetMarketListing(deal.ListingID)
+	}
+	if listing != nil {
+		if err := completeSale(ctx, listing, esc.Buyer, esc.Amount); err != nil {
+			return err
+		}
+		if err := saveMarketListing(listing); err != nil {
+			return err
+		}
+	} else {
+		escrowAcc := ModuleAddress("marketplace")
+		if err := Transfer(ctx, AssetRef{Kind: AssetCoin}, escrowAcc, esc.Seller, esc.Amount); err != nil {
+			return err
+		}
+	}
+
+	esc.State = "released"
+	upd, _ := json.Marshal(&esc)
+	if err := CurrentStore().Set([]byte(key), upd); err != nil {
+		return err
+	}
 
+	if deal != nil {
+		deal.Closed = true
+		now := time.Now().UTC()
+		deal.ClosedAt = &now
+		buf, _ := json.Marshal(deal)
+		_ = CurrentStore().Set(dealKey, buf)
+	}
 	return nil
 }
 
@@ -0,0 +1,393 @@
+package core
+
+// marketplace_auctions.go – auctions, bid escrow and royalties for the
+// marketplace.
+//
+// Listings are fixed-price (PurchaseItem), English auctions (ascending bids,
+// highest bid at EndsAt wins) or Dutch auctions (price falls linearly from
+// Price to ReservePrice until EndsAt; the first bid at or above the current
+// price wins immediately).
+//
+// Bid amounts are held by the marketplace module account. An outbid bidder is
+// refunded as soon as a higher bid arrives. StartMarketSettlement settles
+// ended auctions on a schedule; SettleAuction can also be called directly.
+//
+// A listing may carry an NFT (MarketItem). The NFT is held by the marketplace
+// until the sale settles. The first listing of an NFT fixes its creator
+// royalty; every later sale pays that share to the creator and the rest to
+// the seller.
+//
+// Ledger layout:
+//   market:bid:<listing>:<unix nanos>   -> MarketBid
+//   market:royalty:<token>:<nft>        -> MarketRoyalty
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Listing kinds.
+const (
+	ListingFixed   = "fixed"
+	ListingEnglish = "english"
+	ListingDutch   = "dutch"
+)
+
+// MaxRoyaltyBps caps creator royalties at 25%.
+const MaxRoyaltyBps = 2_500
+
+// DefaultMinIncrementBps is the minimum English auction raise (5%).
+const DefaultMinIncrementBps = 500
+
+var (
+	ErrAuctionEnded   = errors.New("auction ended")
+	ErrAuctionRunning = errors.New("auction still running")
+	ErrBidTooLow      = errors.New("bid too low")
+)
+
+var marketMu sync.Mutex
+
+// MarketItem identifies an NFT sold through a listing.
+type MarketItem struct {
+	Token TokenID `json:"token"`
+	NFTID uint64  `json:"nft_id"`
+}
+
+// MarketRoyalty is the creator royalty attached to an NFT.
+type MarketRoyalty struct {
+	Creator Address `json:"creator"`
+	Bps     uint16  `json:"bps"`
+}
+
+// MarketBid is one bid in a listing's history.
+type MarketBid struct {
+	ListingID string    `json:"listing_id"`
+	Bidder    Address   `json:"bidder"`
+	Amount    uint64    `json:"amount"`
+	Time      time.Time `json:"time"`
+	Refunded  bool      `json:"refunded"`
+}
+
+// MarketSale records how a settled sale was paid out.
+type MarketSale struct {
+	Buyer    Address   `json:"buyer"`
+	Price    uint64    `json:"price"`
+	Royalty  uint64    `json:"royalty"`
+	Creator  Address   `json:"creator,omitempty"`
+	Proceeds uint64    `json:"proceeds"`
+	Time     time.Time `json:"time"`
+}
+
+func marketAccount() Address { return ModuleAddress("marketplace") }
+
+func marketBidKey(listingID string, t time.Time) []byte {
+	return []byte(fmt.Sprintf("market:bid:%s:%020d", listingID, t.UnixNano()))
+}
+
+func marketRoyaltyKey(it MarketItem) []byte {
+	return []byte(fmt.Sprintf("market:royalty:%d:%d", it.Token, it.NFTID))
+}
+
+// GetMarketRoyalty returns the royalty registered for an NFT.
+func GetMarketRoyalty(it MarketItem) (*MarketRoyalty, error) {
+	raw, err := CurrentStore().Get(marketRoyaltyKey(it))
+	if err != nil || raw == nil {
+		return nil, ErrNotFound
+	}
+	var r MarketRoyalty
+	if err := json.Unmarshal(raw, &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// DutchPrice returns the current price of a Dutch auction at now.
+func (l *MarketListing) DutchPrice(now time.Time) uint64 {
+	if !now.After(l.CreatedAt) {
+		return l.Price
+	}
+	if !now.Before(l.EndsAt) {
+		return l.ReservePrice
+	}
+	span := l.EndsAt.Sub(l.CreatedAt)
+	drop := float64(l.Price-l.ReservePrice) * float64(now.Sub(l.CreatedAt)) / float64(span)
+	return l.Price - uint64(drop)
+}
+
+// prepareListing validates kind specific fields, escrows the NFT and
+// registers its royalty.
+func prepareListing(l *MarketListing) error {
+	switch l.Kind {
+	case "":
+		l.Kind = ListingFixed
+	case ListingFixed:
+	case ListingEnglish:
+		if l.MinIncrementBps == 0 {
+			l.MinIncrementBps = DefaultMinIncrementBps
+		}
+	case ListingDutch:
+		if l.ReservePrice >= l.Price {
+			return fmt.Errorf("reserve price must be below start price")
+		}
+	default:
+		return fmt.Errorf("unknown listing kind %q", l.Kind)
+	}
+	if l.Kind != ListingFixed && !l.EndsAt.After(l.CreatedAt) {
+		return fmt.Errorf("auction end must be in the future")
+	}
+	if l.Item == nil {
+		return nil
+	}
+	if l.RoyaltyBps > MaxRoyaltyBps {
+		return fmt.Errorf("royalty %d bps exceeds %d", l.RoyaltyBps, MaxRoyaltyBps)
+	}
+	tok, ok := GetToken(l.Item.Token)
+	if !ok {
+		return fmt.Errorf("token %d not found", l.Item.Token)
+	}
+	if err := tok.Transfer(l.Seller, marketAccount(), l.Item.NFTID); err != nil {
+		return fmt.Errorf("escrow item: %w", err)
+	}
+	if _, err := GetMarketRoyalty(*l.Item); errors.Is(err, ErrNotFound) && l.RoyaltyBps > 0 {
+		raw, _ := json.Marshal(MarketRoyalty{Creator: l.Seller, Bps: l.RoyaltyBps})
+		if err := CurrentStore().Set(marketRoyaltyKey(*l.Item), raw); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// returnItem hands an escrowed NFT back to the seller.
+func returnItem(l *MarketListing) error {
+	if l.Item == nil {
+		return nil
+	}
+	tok, ok := GetToken(l.Item.Token)
+	if !ok {
+		return fmt.Errorf("token %d not found", l.Item.Token)
+	}
+	return tok.Transfer(marketAccount(), l.Seller, l.Item.NFTID)
+}
+
+// completeSale pays the seller and creator from the marketplace account and
+// delivers the item to the buyer.
+func completeSale(ctx *Context, l *MarketListing, buyer Address, price uint64) error {
+	sale := &MarketSale{Buyer: buyer, Price: price, Proceeds: price, Time: time.Now().UTC()}
+	if l.Item != nil {
+		if r, err := GetMarketRoyalty(*l.Item); err == nil && r.Creator != l.Seller {
+			sale.Creator = r.Creator
+			sale.Royalty = price * uint64(r.Bps) / 10_000
+			sale.Proceeds -= sale.Royalty
+		}
+	}
+	if sale.Royalty > 0 {
+		if err := Transfer(ctx, AssetRef{Kind: AssetCoin}, marketAccount(), sale.Creator, sale.Royalty); err != nil {
+			return err
+		}
+	}
+	if err := Transfer(ctx, AssetRef{Kind: AssetCoin}, marketAccount(), l.Seller, sale.Proceeds); err != nil {
+		return err
+	}
+	if l.Item != nil {
+		tok, ok := GetToken(l.Item.Token)
+		if !ok {
+			return fmt.Errorf("token %d not found", l.Item.Token)
+		}
+		if err := tok.Transfer(marketAccount(), buyer, l.Item.NFTID); err != nil {
+			return err
+		}
+	}
+	l.Sold = true
+	l.Buyer = buyer
+	l.Settled = true
+	l.Sale = sale
+	Broadcast("market:sale", mustJSON(struct {
+		ListingID string `json:"listing_id"`
+		*MarketSale
+	}{l.ID, sale}))
+	return nil
+}
+
+// PlaceBid bids amount on an auction listing. For English auctions the bid
+// must meet the start price and beat the highest bid by MinIncrementBps; the
+// previous highest bidder is refunded. For Dutch auctions a bid at or above
+// the current price buys the item at that price.
+func PlaceBid(ctx *Context, listingID string, bidder Address, amount uint64) (*MarketBid, error) {
+	marketMu.Lock()
+	defer marketMu.Unlock()
+
+	l, err := GetMarketListing(listingID)
+	if err != nil {
+		return nil, err
+	}
+	if l.Kind != ListingEnglish && l.Kind != ListingDutch {
+		return nil, fmt.Errorf("listing %s is not an auction", l.ID)
+	}
+	now := time.Now().UTC()
+	if l.Settled || l.Sold || !now.Before(l.EndsAt) {
+		return nil, ErrAuctionEnded
+	}
+	if bidder == l.Seller {
+		return nil, fmt.Errorf("seller cannot bid")
+	}
+	bid := &MarketBid{ListingID: l.ID, Bidder: bidder, Amount: amount, Time: now}
+
+	switch l.Kind {
+	case ListingEnglish:
+		floor := l.Price
+		if l.HighBid > 0 {
+			floor = l.HighBid + l.HighBid*uint64(l.MinIncrementBps)/10_000
+			if floor == l.HighBid {
+				floor++
+			}
+		}
+		if amount < floor {
+			return nil, fmt.Errorf("%w: minimum %d", ErrBidTooLow, floor)
+		}
+		if err := Transfer(ctx, AssetRef{Kind: AssetCoin}, bidder, marketAccount(), amount); err != nil {
+			return nil, err
+		}
+		if l.HighBid > 0 {
+			if err := Transfer(ctx, AssetRef{Kind: AssetCoin}, marketAccount(), l.HighBidder, l.HighBid); err != nil {
+				return nil, err
+			}
+			markRefunded(l.ID, l.HighBidder, l.HighBid)
+		}
+		l.HighBid, l.HighBidder = amount, bidder
+	case ListingDutch:
+		price := l.DutchPrice(now)
+		if amount < price {
+			return nil, fmt.Errorf("%w: current price %d", ErrBidTooLow, price)
+		}
+		bid.Amount = price
+		if err := Transfer(ctx, AssetRef{Kind: AssetCoin}, bidder, marketAccount(), price); err != nil {
+			return nil, err
+		}
+		l.HighBid, l.HighBidder = price, bidder
+		if err := completeSale(ctx, l, bidder, price); err != nil {
+			return nil, err
+		}
+	}
+
+	raw, _ := json.Marshal(bid)
+	if err := CurrentStore().Set(marketBidKey(l.ID, now), raw); err != nil {
+		return nil, err
+	}
+	if err := saveMarketListing(l); err != nil {
+		return nil, err
+	}
+	Broadcast("market:bid", raw)
+	return bid, nil
+}
+
+func markRefunded(listingID string, bidder Address, amount uint64) {
+	bids, _ := ListMarketBids(listingID)
+	for i := len(bids) - 1; i >= 0; i-- {
+		b := bids[i]
+		if b.Bidder == bidder && b.Amount == amount && !b.Refunded {
+			b.Refunded = true
+			raw, _ := json.Marshal(b)
+			_ = CurrentStore().Set(marketBidKey(listingID, b.Time), raw)
+			return
+		}
+	}
+}
+
+// ListMarketBids returns the bid history of a listing, oldest first.
+func ListMarketBids(listingID string) ([]MarketBid, error) {
+	it := CurrentStore().Iterator([]byte("market:bid:"+listingID+":"), nil)
+	defer it.Close()
+	var out []MarketBid
+	for it.Next() {
+		var b MarketBid
+		if err := json.Unmarshal(it.Value(), &b); err != nil {
+			continue
+		}
+		out = append(out, b)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Time.Before(out[j].Time) })
+	return out, it.Error()
+}
+
+// SettleAuction closes an ended auction: the highest English bid wins,
+// otherwise the item returns to the seller.
+func SettleAuction(ctx *Context, listingID string) error {
+	marketMu.Lock()
+	defer marketMu.Unlock()
+	l, err := GetMarketListing(listingID)
+	if err != nil {
+		return err
+	}
+	return settleListing(ctx, l, time.Now().UTC())
+}
+
+func settleListing(ctx *Context, l *MarketListing, now time.Time) error {
+	if l.Kind != ListingEnglish && l.Kind != ListingDutch {
+		return fmt.Errorf("listing %s is not an auction", l.ID)
+	}
+	if l.Settled {
+		return nil
+	}
+	if now.Before(l.EndsAt) {
+		return ErrAuctionRunning
+	}
+	if l.Kind == ListingEnglish && l.HighBid > 0 {
+		if err := completeSale(ctx, l, l.HighBidder, l.HighBid); err != nil {
+			return err
+		}
+	} else {
+		if err := returnItem(l); err != nil {
+			return err
+		}
+		l.Settled = true
+	}
+	return saveMarketListing(l)
+}
+
+// SettleEndedAuctions settles every auction that has ended by now and
+// returns the settled listing IDs.
+func SettleEndedAuctions(ctx *Context, now time.Time) ([]string, error) {
+	list, err := ListMarketListings(nil)
+	if err != nil {
+		return nil, err
+	}
+	marketMu.Lock()
+	defer marketMu.Unlock()
+	var out []string
+	for i := range list {
+		l := &list[i]
+		if l.Settled || (l.Kind != ListingEnglish && l.Kind != ListingDutch) || now.Before(l.EndsAt) {
+			continue
+		}
+		if err := settleListing(ctx, l, now); err != nil {
+			return out, err
+		}
+		out = append(out, l.ID)
+	}
+	return out, nil
+}
+
+// StartMarketSettlement runs SettleEndedAuctions every interval until ctx is
+// cancelled.
+func StartMarketSettlement(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	go func() {
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-t.C:
+				_, _ = SettleEndedAuctions(&Context{}, now.UTC())
+			}
+		}
+	}()
+}
@@ -0,0 +1,109 @@
+package core
+
+// merkle_accumulator.go – append-only Merkle tree with O(log n) updates.
+//
+// MerkleAccumulator keeps every level of the tree BuildMerkleTree would
+// build over the leaves appended so far. Appending a leaf recomputes only
+// the nodes on its path to the root, pairing the last node of an odd level
+// with itself until its sibling arrives, so roots and proofs always equal
+// those of BuildMerkleTree and MerkleProof over the same leaves and verify
+// with VerifyMerklePath. Rollup batch transaction roots and audit
+// checkpoint roots are accumulated this way.
+
+import (
+	"crypto/sha256"
+	"errors"
+	"sync"
+)
+
+// MerkleAccumulator is an append-only Merkle tree. The zero value is empty
+// and ready to use.
+type MerkleAccumulator struct {
+	mu     sync.RWMutex
+	levels [][][32]byte // levels[0] are the leaf hashes; the last level holds the root
+}
+
+// Append hashes leaf into the tree and returns its index.
+func (a *MerkleAccumulator) Append(leaf []byte) uint32 {
+	return a.AppendHash(sha256.Sum256(leaf))
+}
+
+// AppendHash adds an already hashed leaf and returns its index.
+func (a *MerkleAccumulator) AppendHash(h [32]byte) uint32 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if len(a.levels) == 0 {
+		a.levels = [][][32]byte{nil}
+	}
+	a.levels[0] = append(a.levels[0], h)
+	idx := len(a.levels[0]) - 1
+	i := idx
+	for k := 0; len(a.levels[k]) > 1; k++ {
+		lvl := a.levels[k]
+		p := i / 2
+		left, right := lvl[2*p], lvl[2*p]
+		if 2*p+1 < len(lvl) {
+			right = lvl[2*p+1]
+		}
+		if k+1 == len(a.levels) {
+			a.levels = append(a.levels, nil)
+		}
+		parent := hashPair(left, right)
+		if p < len(a.levels[k+1]) {
+			a.levels[k+1][p] = parent
+		} else {
+			a.levels[k+1] = append(a.levels[k+1], parent)
+		}
+		i = p
+	}
+	return uint32(idx)
+}
+
+// Len returns the number of leaves.
+func (a *MerkleAccumulator) Len() int {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if len(a.levels) == 0 {
+		return 0
+	}
+	return len(a.levels[0])
+}
+
+// Root returns the current root.
+func (a *MerkleAccumulator) Root() ([32]byte, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if len(a.levels) == 0 {
+		return [32]byte{}, errors.New("no leaves")
+	}
+	return a.levels[len(a.levels)-1][0], nil
+}
+
+// Proof returns the sibling path of leaf index, leaf upwards, against the
+// current root.
+func (a *MerkleAccumulator) Proof(index uint32) ([][]byte, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if len(a.levels) == 0 || int(index) >= len(a.levels[0]) {
+		return nil, errors.New("index out of range")
+	}
+	proof := make([][]byte, 0, len(a.levels)-1)
+	i := int(index)
+	for _, lvl := range a.levels[:len(a.levels)-1] {
+		sib := i ^ 1
+		if sib >= len(lvl) {
+			sib = i
+		}
+		node := lvl[sib]
+		proof = append(proof, node[:])
+		i /= 2
+	}
+	return proof, nil
+}
+
+// Reset empties the accumulator.
+func (a *MerkleAccumulator) Reset() {
+	a.mu.Lock()
+	a.levels = nil
+	a.mu.Unlock()
+}
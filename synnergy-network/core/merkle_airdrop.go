@@ -0,0 +1,335 @@
+package core
+
+// merkle_airdrop.go – Merkle airdrops.
+//
+// A creator publishes the Merkle root of a list of (address, amount)
+// entries and escrows the total in the "airdrop" module account. Each
+// recipient claims its own entry with a proof; nothing is sent until then,
+// so a drop to thousands of holders costs one transaction to publish.
+// After the expiry anyone may sweep what was not claimed back to the
+// drop's treasury.
+//
+// Entries use the leaf encoding of holder snapshots, SnapshotLeaf(address,
+// amount), so the root printed by `tokens snapshot --merkle` can be
+// published as is. BuildAirdropTree builds the same tree from an arbitrary
+// list, for example one scaled pro rata with AirdropEntriesFromSnapshot.
+//
+// Ledger layout:
+//   airdrop:seq                    -> last drop ID
+//   airdrop:drop:<id>              -> MerkleAirdrop
+//   airdrop:claimed:<id>:<index>   -> claim marker
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrAirdropNotFound = errors.New("airdrop not found")
+	ErrAirdropClaimed  = errors.New("airdrop entry already claimed")
+	ErrAirdropProof    = errors.New("airdrop proof invalid")
+	ErrAirdropExpired  = errors.New("airdrop expired")
+	ErrAirdropActive   = errors.New("airdrop still claimable")
+)
+
+var airdropMu sync.Mutex
+
+// AirdropConfig describes a drop to publish.
+type AirdropConfig struct {
+	Root     string    `json:"root"` // hex Merkle root of the entries
+	Asset    AssetRef  `json:"asset"`
+	Total    uint64    `json:"total"`    // sum of all entries, escrowed on publish
+	Entries  int       `json:"entries"`  // informational
+	Expires  time.Time `json:"expires"`  // claims close, sweeping opens
+	Treasury Address   `json:"treasury"` // receives the unclaimed rest; defaults to the creator
+}
+
+// MerkleAirdrop is a published drop.
+type MerkleAirdrop struct {
+	ID uint64 `json:"id"`
+	AirdropConfig
+	Creator   Address   `json:"creator"`
+	Published time.Time `json:"published"`
+	Claimed   uint64    `json:"claimed"`
+	Claims    int       `json:"claims"`
+	Swept     uint64    `json:"swept,omitempty"`
+	Closed    bool      `json:"closed"`
+}
+
+// Remaining is the escrowed amount not yet claimed or swept.
+func (a *MerkleAirdrop) Remaining() uint64 { return a.Total - a.Claimed - a.Swept }
+
+// AirdropEntry is one leaf of an airdrop tree.
+type AirdropEntry struct {
+	Index   int      `json:"index"`
+	Address string   `json:"address"`
+	Amount  uint64   `json:"amount"`
+	Proof   []string `json:"proof,omitempty"` // hex, leaf level first
+}
+
+// AirdropTree is the full entry list of a drop with the proofs recipients
+// need to claim.
+type AirdropTree struct {
+	Root    string         `json:"root"`
+	Total   uint64         `json:"total"`
+	Entries []AirdropEntry `json:"entries"`
+}
+
+// Entry returns the entry of addr.
+func (t *AirdropTree) Entry(addr Address) (*AirdropEntry, bool) {
+	for i := range t.Entries {
+		if a, err := DecodeAddress(t.Entries[i].Address); err == nil && a == addr {
+			return &t.Entries[i], true
+		}
+	}
+	return nil, false
+}
+
+func airdropAccount() Address { return ModuleAddress("airdrop") }
+
+func airdropKey(id uint64) []byte { return []byte(fmt.Sprintf("airdrop:drop:%d", id)) }
+
+func airdropClaimKey(id uint64, index uint32) []byte {
+	return []byte(fmt.Sprintf("airdrop:claimed:%d:%d", id, index))
+}
+
+// merkleProofs builds the tree of leaves with BuildMerkleTree and returns the
+// root and the hex proof of every leaf.
+func merkleProofs(leaves [][]byte) ([32]byte, [][]string, error) {
+	tree, err := BuildMerkleTree(leaves)
+	if err != nil {
+		return [32]byte{}, nil, err
+	}
+	proofs := make([][]string, len(leaves))
+	for i := range leaves {
+		idx := i
+		for _, level := range tree[:len(tree)-1] {
+			sib := level[idx^1]
+			proofs[i] = append(proofs[i], hex.EncodeToString(sib[:]))
+			idx /= 2
+		}
+	}
+	return tree[len(tree)-1][0], proofs, nil
+}
+
+// BuildAirdropTree indexes entries in order and computes the root and
+// proofs.
+func BuildAirdropTree(entries []AirdropEntry) (*AirdropTree, error) {
+	if len(entries) == 0 {
+		return nil, errors.New("airdrop: no entries")
+	}
+	t := &AirdropTree{Entries: make([]AirdropEntry, len(entries))}
+	leaves := make([][]byte, len(entries))
+	for i, e := range entries {
+		a, err := DecodeAddress(e.Address)
+		if err != nil {
+			return nil, fmt.Errorf("entry %d: %w", i, err)
+		}
+		if t.Total+e.Amount < t.Total {
+			return nil, errors.New("airdrop: total overflows")
+		}
+		t.Total += e.Amount
+		leaves[i] = SnapshotLeaf(a, e.Amount)
+		t.Entries[i] = AirdropEntry{Index: i, Address: a.Hex(), Amount: e.Amount}
+	}
+	root, proofs, err := merkleProofs(leaves)
+	if err != nil {
+		return nil, err
+	}
+	t.Root = hex.EncodeToString(root[:])
+	for i := range t.Entries {
+		t.Entries[i].Proof = proofs[i]
+	}
+	return t, nil
+}
+
+// AirdropEntriesFromSnapshot turns a holder snapshot into airdrop entries.
+// With total 0 each holder receives its balance; otherwise total is split
+// pro rata to balances, rounding down, and holders whose share rounds to
+// zero are left out.
+func AirdropEntriesFromSnapshot(snap *HolderSnapshot, total uint64) []AirdropEntry {
+	out := make([]AirdropEntry, 0, len(snap.Holders))
+	for _, h := range snap.Holders {
+		amt := h.Balance
+		if total > 0 && snap.Total > 0 {
+			share := new(big.Int).Mul(new(big.Int).SetUint64(h.Balance), new(big.Int).SetUint64(total))
+			amt = share.Div(share, new(big.Int).SetUint64(snap.Total)).Uint64()
+		}
+		if amt > 0 {
+			out = append(out, AirdropEntry{Address: h.Address, Amount: amt})
+		}
+	}
+	return out
+}
+
+// GetAirdrop returns a published drop.
+func GetAirdrop(id uint64) (*MerkleAirdrop, error) {
+	var a MerkleAirdrop
+	if err := airdropLoad(airdropKey(id), &a); err != nil {
+		return nil, fmt.Errorf("%w: %d", ErrAirdropNotFound, id)
+	}
+	return &a, nil
+}
+
+// AirdropClaimed reports whether entry index of drop id has been claimed.
+func AirdropClaimed(id uint64, index uint32) bool {
+	raw, err := CurrentStore().Get(airdropClaimKey(id, index))
+	return err == nil && raw != nil
+}
+
+// PublishAirdrop escrows cfg.Total from creator and opens the drop.
+func PublishAirdrop(ctx *Context, creator Address, cfg AirdropConfig) (*MerkleAirdrop, error) {
+	airdropMu.Lock()
+	defer airdropMu.Unlock()
+	return publishAirdrop(ctx, creator, cfg, time.Now().UTC())
+}
+
+func publishAirdrop(ctx *Context, creator Address, cfg AirdropConfig, now time.Time) (*MerkleAirdrop, error) {
+	if _, err := airdropRoot(cfg.Root); err != nil {
+		return nil, err
+	}
+	if cfg.Total == 0 {
+		return nil, errors.New("airdrop: total must be positive")
+	}
+	if !cfg.Expires.After(now) {
+		return nil, errors.New("airdrop: expiry must be in the future")
+	}
+	if cfg.Treasury == AddressZero {
+		cfg.Treasury = creator
+	}
+	cfg.Root = strings.ToLower(strings.TrimPrefix(cfg.Root, "0x"))
+
+	var id uint64
+	if raw, err := CurrentStore().Get([]byte("airdrop:seq")); err == nil && len(raw) == 8 {
+		id = binary.BigEndian.Uint64(raw)
+	}
+	id++
+	if err := Transfer(ctx, cfg.Asset, creator, airdropAccount(), cfg.Total); err != nil {
+		return nil, err
+	}
+	a := &MerkleAirdrop{ID: id, AirdropConfig: cfg, Creator: creator, Published: now}
+	if err := CurrentStore().Set([]byte("airdrop:seq"), uint64ToBytes(id)); err != nil {
+		return nil, err
+	}
+	if err := airdropStore(airdropKey(id), a); err != nil {
+		return nil, err
+	}
+	Broadcast("airdrop:publish", mustJSON(a))
+	return a, nil
+}
+
+// ClaimAirdrop pays entry index of drop id to claimant after checking the
+// proof. Anyone may submit a claim; the funds always go to the address in
+// the entry.
+func ClaimAirdrop(ctx *Context, id uint64, index uint32, claimant Address, amount uint64, proof [][]byte) error {
+	airdropMu.Lock()
+	defer airdropMu.Unlock()
+	return claimAirdrop(ctx, id, index, claimant, amount, proof, time.Now().UTC())
+}
+
+func claimAirdrop(ctx *Context, id uint64, index uint32, claimant Address, amount uint64, proof [][]byte, now time.Time) error {
+	a, err := GetAirdrop(id)
+	if err != nil {
+		return err
+	}
+	if a.Closed || !now.Before(a.Expires) {
+		return fmt.Errorf("%w: claims closed %s", ErrAirdropExpired, a.Expires.Format(time.RFC3339))
+	}
+	if AirdropClaimed(id, index) {
+		return fmt.Errorf("%w: entry %d", ErrAirdropClaimed, index)
+	}
+	root, err := airdropRoot(a.Root)
+	if err != nil {
+		return err
+	}
+	if !VerifyMerklePath(root, SnapshotLeaf(claimant, amount), proof, index) {
+		return ErrAirdropProof
+	}
+	if amount > a.Remaining() {
+		return fmt.Errorf("airdrop %d: claim %d exceeds remaining %d", id, amount, a.Remaining())
+	}
+	if err := Transfer(ctx, a.Asset, airdropAccount(), claimant, amount); err != nil {
+		return err
+	}
+	a.Claimed += amount
+	a.Claims++
+	if err := CurrentStore().Set(airdropClaimKey(id, index), []byte{1}); err != nil {
+		return err
+	}
+	if err := airdropStore(airdropKey(id), a); err != nil {
+		return err
+	}
+	Broadcast("airdrop:claim", mustJSON(struct {
+		ID       uint64  `json:"id"`
+		Index    uint32  `json:"index"`
+		Claimant Address `json:"claimant"`
+		Amount   uint64  `json:"amount"`
+	}{id, index, claimant, amount}))
+	return nil
+}
+
+// SweepAirdrop closes an expired drop and returns the unclaimed rest to its
+// treasury. Anyone may call it.
+func SweepAirdrop(ctx *Context, id uint64) (uint64, error) {
+	airdropMu.Lock()
+	defer airdropMu.Unlock()
+	return sweepAirdrop(ctx, id, time.Now().UTC())
+}
+
+func sweepAirdrop(ctx *Context, id uint64, now time.Time) (uint64, error) {
+	a, err := GetAirdrop(id)
+	if err != nil {
+		return 0, err
+	}
+	if a.Closed {
+		return 0, fmt.Errorf("airdrop %d already swept", id)
+	}
+	if now.Before(a.Expires) {
+		return 0, fmt.Errorf("%w: until %s", ErrAirdropActive, a.Expires.Format(time.RFC3339))
+	}
+	rest := a.Remaining()
+	if rest > 0 {
+		if err := Transfer(ctx, a.Asset, airdropAccount(), a.Treasury, rest); err != nil {
+			return 0, err
+		}
+	}
+	a.Swept, a.Closed = rest, true
+	if err := airdropStore(airdropKey(id), a); err != nil {
+		return 0, err
+	}
+	Broadcast("airdrop:sweep", mustJSON(a))
+	return rest, nil
+}
+
+func airdropLoad(key []byte, v interface{}) error {
+	raw, err := CurrentStore().Get(key)
+	if err != nil || raw == nil {
+		return ErrNotFound
+	}
+	return json.Unmarshal(raw, v)
+}
+
+func airdropStore(key []byte, v interface{}) error {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return CurrentStore().Set(key, raw)
+}
+
+func airdropRoot(s string) ([32]byte, error) {
+	var root [32]byte
+	b, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil || len(b) != len(root) {
+		return root, fmt.Errorf("airdrop: root must be %d bytes of hex", len(root))
+	}
+	copy(root[:], b)
+	return root, nil
+}
@@ -4,11 +4,49 @@ import (
 	"bytes"
 	"crypto/sha256"
 	"errors"
+	"runtime"
+	"sync"
 )
 
+// merkleParallelMin is the level width from which nodes are hashed by
+// several goroutines.
+const merkleParallelMin = 4096
+
+// parallelFor calls fn over [0, n) split into one chunk per CPU, or inline
+// for small n.
+func parallelFor(n int, fn func(lo, hi int)) {
+	workers := runtime.GOMAXPROCS(0)
+	if n < merkleParallelMin || workers < 2 {
+		fn(0, n)
+		return
+	}
+	chunk := (n + workers - 1) / workers
+	var wg sync.WaitGroup
+	for lo := 0; lo < n; lo += chunk {
+		hi := lo + chunk
+		if hi > n {
+			hi = n
+		}
+		wg.Add(1)
+		go func(lo, hi int) {
+			defer wg.Done()
+			fn(lo, hi)
+		}(lo, hi)
+	}
+	wg.Wait()
+}
+
+// hashPair is the parent of two nodes: SHA-256(left ‖ right).
+func hashPair(left, right [32]byte) [32]byte {
+	var buf [64]byte
+	copy(buf[:32], left[:])
+	copy(buf[32:], right[:])
+	return sha256.Sum256(buf[:])
+}
+
 // BuildMerkleTree returns the level-by-level nodes of a Merkle tree built from
 // the provided leaves. Each leaf is hashed using SHA-256. The last slice
-// contains the single root hash.
+// contains the single root hash. Wide levels are hashed in parallel.
 func BuildMerkleTree(leaves [][]byte) ([][][32]byte, error) {
 	if len(leaves) == 0 {
 		return nil, errors.New("no leaves")
@@ -16,20 +54,26 @@ func BuildMerkleTree(leaves [][]byte) ([][][32]byte, error) {
 
 	// first level: hashed leaves
 	level := make([][32]byte, len(leaves))
-	for i, l := range leaves {
-		level[i] = sha256.Sum256(l)
-	}
+	parallelFor(len(leaves), func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			level[i] = sha256.Sum256(leaves[i])
+		}
+	})
 
 	tree := [][][32]byte{level}
 
 	for len(level) > 1 {
 		if len(level)%2 == 1 {
+			// pad in place so proofs can reference the duplicated node
 			level = append(level, level[len(level)-1])
+			tree[len(tree)-1] = level
 		}
 		next := make([][32]byte, len(level)/2)
-		for i := 0; i < len(level); i += 2 {
-			next[i/2] = sha256.Sum256(append(level[i][:], level[i+1][:]...))
-		}
+		parallelFor(len(next), func(lo, hi int) {
+			for i := lo; i < hi; i++ {
+				next[i] = hashPair(level[2*i], level[2*i+1])
+			}
+		})
 		tree = append(tree, next)
 		level = next
 	}
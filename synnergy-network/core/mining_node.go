@@ -40,7 +40,7 @@ func NewMiningNode(cfg *MiningNodeConfig) (*MiningNode, error) {
 		return nil, err
 	}
 
-	pool := NewTxPool(nil, led, nil, nil, 0)
+	pool := NewTxPool(nil, led, nil, nil, nil, 0)
 
 	netAdapter := newNetworkAdapter(n)
 	cons, err := NewConsensus(logrus.New(), led, netAdapter, nil, pool, nil)
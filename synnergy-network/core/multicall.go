@@ -0,0 +1,347 @@
+package core
+
+// multicall.go – atomic batches of calls and transfers.
+//
+// A TxMulticall transaction carries an ordered list of calls. A call with
+// data executes the contract at To, sending Value along; a call without
+// data is a plain coin transfer. The calls run in order from the sender and
+// all-or-nothing: the first call that fails or reverts stops the batch and
+// every state change made by the earlier calls is rolled back.
+//
+// Each call is charged multicallCallGas on top of the gas it uses and may
+// cap its own gas with GasLimit; the rest of the transaction's gas is
+// available otherwise. The combined receipt totals the gas, collects the
+// logs of all calls and lists the receipt of every call that ran. On
+// failure its error names the failing call and Revert carries that call's
+// decoded revert reason.
+//
+// Blocks execute multicalls against a copy of the ledger state and commit
+// its changes only when the whole batch succeeds; a failed batch stays in
+// the block, paying its fee, and changes nothing else.
+//
+// WASM contracts batch their own internal calls with host_multicall.
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/sirupsen/logrus"
+)
+
+// MaxMulticallCalls bounds the calls in one multicall.
+const MaxMulticallCalls = 64
+
+// multicallCallGas is the intrinsic gas of each call in a multicall.
+const multicallCallGas uint64 = 700
+
+// Call is one step of a multicall.
+type Call struct {
+	To       Address `json:"to"`
+	Value    uint64  `json:"value,omitempty"`
+	Data     []byte  `json:"data,omitempty"`      // contract input; empty for a transfer
+	GasLimit uint64  `json:"gas_limit,omitempty"` // 0 uses the gas left
+}
+
+// CallResult is the receipt of one call of a multicall.
+type CallResult struct {
+	Index   int     `json:"index"`
+	Receipt Receipt `json:"receipt"`
+}
+
+// multicallState is the state a multicall runs against.
+type multicallState interface {
+	Snapshot(func() error) error
+	Transfer(from, to Address, amount uint64) error
+	Call(from, to Address, input []byte, value *big.Int, gas uint64) ([]byte, error)
+}
+
+// stateSnapshotter is implemented by states whose Snapshot holds a lock
+// that calls back into the state would wait on; the batch then runs
+// against the view it is given.
+type stateSnapshotter interface {
+	snapshotState(func(multicallState) error) error
+}
+
+// receiptExecutor is implemented by states that report the full receipt of
+// a contract call, with gas used and logs.
+type receiptExecutor interface {
+	execute(from, to Address, input []byte, value *big.Int, gas uint64, tracer VMTracer) (*Receipt, error)
+}
+
+// ValidateMulticall checks the envelope of a multicall transaction.
+func ValidateMulticall(tx *Transaction) error {
+	if n := len(tx.Calls); n == 0 || n > MaxMulticallCalls {
+		return NewError(CodeInvalidArgument, "multicall", "%d calls, want 1 to %d", n, MaxMulticallCalls)
+	}
+	if tx.Value != 0 {
+		return NewError(CodeInvalidArgument, "multicall", "value must be set per call")
+	}
+	if min := uint64(len(tx.Calls)) * multicallCallGas; tx.GasLimit < min {
+		return NewError(CodeInvalidArgument, "multicall", "gas limit %d below intrinsic %d", tx.GasLimit, min)
+	}
+	return nil
+}
+
+// ExecuteMulticall runs calls from from atomically within gas and returns
+// the combined receipt. A failed batch is reported both as the error and in
+// the failed receipt; its state changes are rolled back.
+func ExecuteMulticall(st multicallState, from Address, calls []Call, gas uint64, tracer VMTracer) (*Receipt, error) {
+	if n := len(calls); n == 0 || n > MaxMulticallCalls {
+		return nil, NewError(CodeInvalidArgument, "multicall", "%d calls, want 1 to %d", n, MaxMulticallCalls)
+	}
+	rec := &Receipt{Status: true, Calls: make([]CallResult, 0, len(calls))}
+	run := func(st multicallState) error {
+		for i, c := range calls {
+			if gas-rec.GasUsed < multicallCallGas {
+				rec.Status, rec.Error = false, fmt.Sprintf("call %d: out of gas", i)
+				return fmt.Errorf("multicall call %d: out of gas", i)
+			}
+			rec.GasUsed += multicallCallGas
+			limit := gas - rec.GasUsed
+			if c.GasLimit != 0 && c.GasLimit < limit {
+				limit = c.GasLimit
+			}
+			sub, err := runCall(st, from, c, limit, tracer)
+			if err == nil && !sub.Status {
+				err = errors.New(sub.Error)
+			}
+			if err != nil {
+				sub.Status = false
+				if sub.Error == "" {
+					sub.Error = err.Error()
+				}
+			}
+			rec.GasUsed += sub.GasUsed
+			rec.Calls = append(rec.Calls, CallResult{Index: i, Receipt: *sub})
+			if err != nil {
+				rec.Status, rec.Error, rec.Revert = false, fmt.Sprintf("call %d: %s", i, sub.Error), sub.Revert
+				return fmt.Errorf("multicall call %d: %w", i, err)
+			}
+			if len(sub.Logs) > 0 {
+				if err := checkLogLimit(len(rec.Logs) + len(sub.Logs) - 1); err != nil {
+					rec.Status, rec.Error = false, fmt.Sprintf("call %d: %v", i, err)
+					return err
+				}
+			}
+			rec.Logs = append(rec.Logs, sub.Logs...)
+		}
+		return nil
+	}
+	var err error
+	if s, ok := st.(stateSnapshotter); ok {
+		err = s.snapshotState(run)
+	} else {
+		err = st.Snapshot(func() error { return run(st) })
+	}
+	if err != nil {
+		rec.Logs = nil
+	}
+	return rec, err
+}
+
+// runCall performs one call and returns its receipt, never nil.
+func runCall(st multicallState, from Address, c Call, gas uint64, tracer VMTracer) (*Receipt, error) {
+	if c.Value > 0 {
+		if err := st.Transfer(from, c.To, c.Value); err != nil {
+			return &Receipt{Error: err.Error()}, err
+		}
+	}
+	if len(c.Data) == 0 {
+		return &Receipt{Status: true}, nil
+	}
+	value := new(big.Int).SetUint64(c.Value)
+	if ex, ok := st.(receiptExecutor); ok {
+		rec, err := ex.execute(from, c.To, c.Data, value, gas, tracer)
+		if rec == nil {
+			rec = &Receipt{}
+		}
+		return rec, err
+	}
+	out, err := st.Call(from, c.To, c.Data, value, gas)
+	if err != nil {
+		return &Receipt{GasUsed: gas, Error: err.Error(), Revert: RevertOf(err)}, err
+	}
+	return &Receipt{Status: true, GasUsed: gas, ReturnData: out}, nil
+}
+
+// tokenSpenders maps each sender of a token transfer in txs to the index of
+// the last transaction spending from it.
+func tokenSpenders(txs []*Transaction) map[Address]int {
+	last := make(map[Address]int)
+	for i, tx := range txs {
+		for _, tr := range tx.TokenTransfers {
+			last[tr.From] = i
+		}
+	}
+	return last
+}
+
+// executeMulticall runs the multicall tx, the idx-th transaction of the
+// block being applied at height, and commits its state writes, coin
+// movements, deployed contracts and logs if every call succeeds, adding
+// what they overwrite to diff. The calls run in a memState holding a copy
+// of the ledger state and the coin balances of the sender and the called
+// accounts. A batch that would create or destroy coins, or draw down an
+// account a later token transfer of the block spends from (those were
+// checked against the balances before the block), fails as a whole. The
+// caller holds l.mu.
+func (l *Ledger) executeMulticall(tx *Transaction, idx int, height uint64, spenders map[Address]int, diff *txDiffRecorder) *Receipt {
+	ms := &memState{
+		data:       make(map[string][]byte, len(l.State)),
+		balances:   make(map[Address]uint64),
+		lpBalances: make(map[Address]map[PoolID]uint64),
+		contracts:  make(map[Address][]byte),
+		tokens:     make(map[TokenID]Token, len(l.tokens)),
+		codeHashes: make(map[Address]Hash),
+		nonces:     make(map[Address]uint64, len(l.nonces)),
+	}
+	for k, v := range l.State {
+		ms.data[k] = append([]byte(nil), v...)
+	}
+	for a, n := range l.nonces {
+		ms.nonces[a] = n
+	}
+	for id, t := range l.tokens {
+		ms.tokens[id] = t
+	}
+	before := map[Address]uint64{tx.From: l.Balances[CoinKey(tx.From)]}
+	for _, c := range tx.Calls {
+		before[c.To] = l.Balances[CoinKey(c.To)]
+		if ct, ok := l.Contracts[fmt.Sprintf("%x", c.To)]; ok {
+			ms.contracts[c.To] = append([]byte(nil), ct.Bytecode...)
+		}
+	}
+	for a, b := range before {
+		ms.balances[a] = b
+	}
+
+	rec, err := ExecuteMulticall(ms, tx.From, tx.Calls, tx.GasLimit, nil)
+	if err == nil {
+		err = l.commitMulticall(tx, idx, height, ms, before, spenders, diff)
+		if err != nil {
+			rec.Status, rec.Error, rec.Logs = false, err.Error(), nil
+		}
+	}
+	if err != nil {
+		logrus.Warnf("block %d: multicall %s failed: %v", height, tx.IDHex(), err)
+	}
+	return rec
+}
+
+// commitMulticall writes the changes a successful batch made in ms to the
+// ledger. The caller holds l.mu.
+func (l *Ledger) commitMulticall(tx *Transaction, idx int, height uint64, ms *memState, before map[Address]uint64, spenders map[Address]int, diff *txDiffRecorder) error {
+	var credited, debited uint64
+	addrs := make([]Address, 0, len(ms.balances))
+	for a, after := range ms.balances {
+		was := before[a]
+		switch {
+		case after > was:
+			if a == AddressZero {
+				return NewError(CodeFailedPrecondition, "multicall", "pays the zero address")
+			}
+			credited += after - was
+		case after < was:
+			if last, ok := spenders[a]; ok && last > idx {
+				return NewError(CodeFailedPrecondition, "multicall", "draws down %s, which a later token transfer spends from", a.Hex())
+			}
+			debited += was - after
+		default:
+			continue
+		}
+		addrs = append(addrs, a)
+	}
+	if credited != debited {
+		return NewError(CodeFailedPrecondition, "multicall", "credits %d but debits %d", credited, debited)
+	}
+	var keys []string
+	for k, v := range ms.data {
+		if old, ok := l.State[k]; !ok || !bytes.Equal(old, v) {
+			keys = append(keys, k)
+		}
+	}
+	for k := range l.State {
+		if _, ok := ms.data[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	var deployed []Address
+	for a := range ms.contracts {
+		if _, ok := l.Contracts[fmt.Sprintf("%x", a)]; !ok {
+			deployed = append(deployed, a)
+		}
+	}
+	diff.track(l, addrs, keys, deployed)
+
+	for _, a := range addrs {
+		if after, was := ms.balances[a], before[a]; after < was {
+			if err := l.debit(CoinKey(a), was-after); err != nil {
+				return err
+			}
+		}
+	}
+	for _, a := range addrs {
+		if after, was := ms.balances[a], before[a]; after > was {
+			l.credit(CoinKey(a), after-was)
+		}
+	}
+	for _, k := range keys {
+		if v, ok := ms.data[k]; ok {
+			l.State[k] = v
+		} else {
+			delete(l.State, k)
+		}
+	}
+	for _, a := range deployed {
+		l.Contracts[fmt.Sprintf("%x", a)] = Contract{Address: a, DeployTxHash: tx.Hash, DeployBlock: height, Bytecode: ms.contracts[a]}
+	}
+	l.logs = append(l.logs, ms.logs...)
+	return nil
+}
+
+// DecodeCalls parses the binary call list host_multicall takes: for each
+// call the 20-byte target, then big-endian value (8 bytes), gas limit
+// (8 bytes) and data length (4 bytes), followed by the data.
+func DecodeCalls(b []byte) ([]Call, error) {
+	var calls []Call
+	for len(b) > 0 {
+		if len(b) < 40 {
+			return nil, NewError(CodeInvalidArgument, "multicall", "truncated call %d", len(calls))
+		}
+		var c Call
+		copy(c.To[:], b[:20])
+		c.Value = binary.BigEndian.Uint64(b[20:28])
+		c.GasLimit = binary.BigEndian.Uint64(b[28:36])
+		n := binary.BigEndian.Uint32(b[36:40])
+		b = b[40:]
+		if uint64(n) > uint64(len(b)) {
+			return nil, NewError(CodeInvalidArgument, "multicall", "truncated data of call %d", len(calls))
+		}
+		if n > 0 {
+			c.Data = append([]byte(nil), b[:n]...)
+		}
+		b = b[n:]
+		calls = append(calls, c)
+		if len(calls) > MaxMulticallCalls {
+			return nil, NewError(CodeInvalidArgument, "multicall", "more than %d calls", MaxMulticallCalls)
+		}
+	}
+	return calls, nil
+}
+
+// EncodeCalls is the inverse of DecodeCalls.
+func EncodeCalls(calls []Call) []byte {
+	var out []byte
+	for _, c := range calls {
+		var hdr [40]byte
+		copy(hdr[:20], c.To[:])
+		binary.BigEndian.PutUint64(hdr[20:28], c.Value)
+		binary.BigEndian.PutUint64(hdr[28:36], c.GasLimit)
+		binary.BigEndian.PutUint32(hdr[36:40], uint32(len(c.Data)))
+		out = append(append(out, hdr[:]...), c.Data...)
+	}
+	return out
+}
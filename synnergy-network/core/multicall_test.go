@@ -0,0 +1,157 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// storeThenRevert stores 0x07 under key 0x01 and reverts, padded so
+// SelectVM picks the light interpreter.
+func storeThenRevert() []byte {
+	code := []byte{
+		byte(PUSH), 1, 0x07,
+		byte(PUSH), 1, 0x01,
+		byte(STORE),
+		byte(PUSH), 1, 'x',
+		byte(REVERT),
+	}
+	return append(code, make([]byte, 120-len(code))...)
+}
+
+func TestCallsEncodingRoundTrips(t *testing.T) {
+	calls := []Call{
+		{To: Address{0x01}, Value: 5},
+		{To: Address{0x02}, Data: []byte{0xde, 0xad}, GasLimit: 21_000},
+	}
+	got, err := DecodeCalls(EncodeCalls(calls))
+	if err != nil || len(got) != 2 || got[0].To != calls[0].To || got[0].Value != 5 || got[0].Data != nil ||
+		got[1].To != calls[1].To || got[1].GasLimit != 21_000 || !bytes.Equal(got[1].Data, calls[1].Data) {
+		t.Fatalf("round trip: %+v %v", got, err)
+	}
+	enc := EncodeCalls(calls)
+	for _, cut := range []int{1, 39, len(enc) - 1} {
+		if _, err := DecodeCalls(enc[:cut]); ErrorCodeOf(err) != CodeInvalidArgument {
+			t.Errorf("truncated at %d: %v", cut, err)
+		}
+	}
+	if _, err := DecodeCalls(EncodeCalls(make([]Call, MaxMulticallCalls+1))); ErrorCodeOf(err) != CodeInvalidArgument {
+		t.Fatalf("%d calls: %v", MaxMulticallCalls+1, err)
+	}
+}
+
+func TestValidateMulticall(t *testing.T) {
+	one := []Call{{To: Address{0x01}}}
+	for name, tx := range map[string]*Transaction{
+		"no calls":       {GasLimit: multicallCallGas},
+		"too many calls": {Calls: make([]Call, MaxMulticallCalls+1), GasLimit: (MaxMulticallCalls + 1) * multicallCallGas},
+		"value":          {Calls: one, Value: 1, GasLimit: multicallCallGas},
+		"gas":            {Calls: one, GasLimit: multicallCallGas - 1},
+	} {
+		if err := ValidateMulticall(tx); ErrorCodeOf(err) != CodeInvalidArgument {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+	if err := ValidateMulticall(&Transaction{Calls: one, GasLimit: multicallCallGas}); err != nil {
+		t.Fatalf("valid multicall: %v", err)
+	}
+}
+
+func TestBlockMulticallCommitsOrRevertsAsAWhole(t *testing.T) {
+	l := newDepositLedger(t)
+	from, shop, stored, reverting := Address{0xa1}, Address{0xb1}, Address{0xc1}, Address{0xc2}
+	l.Contracts[fmt.Sprintf("%x", stored)] = Contract{Address: stored, Bytecode: storeAndReturn()}
+	l.Contracts[fmt.Sprintf("%x", reverting)] = Contract{Address: reverting, Bytecode: storeThenRevert()}
+	if err := l.Mint(from, 1_000); err != nil {
+		t.Fatal(err)
+	}
+	block := func(txs ...*Transaction) error {
+		return l.AddBlock(&Block{
+			Header:       BlockHeader{Height: uint64(len(l.Blocks)), Timestamp: time.Now().UnixMilli()},
+			Transactions: txs,
+		})
+	}
+	batch := func(calls ...Call) *Transaction {
+		return &Transaction{Type: TxMulticall, From: from, Calls: calls, GasLimit: 1_000_000}
+	}
+	keys := len(l.State)
+
+	// the call reverts after writing: the write and the transfer before it
+	// are rolled back and the block still applies
+	failed := batch(Call{To: shop, Value: 100}, Call{To: reverting, Data: []byte{1}})
+	if err := block(failed); err != nil {
+		t.Fatalf("block with a failing batch: %v", err)
+	}
+	if l.BalanceOf(from) != 1_000 || l.BalanceOf(shop) != 0 || len(l.State) != keys {
+		t.Fatalf("failed batch changed state: %d %d, %d keys", l.BalanceOf(from), l.BalanceOf(shop), len(l.State))
+	}
+	if rec := l.executeMulticall(failed, 0, 1, nil, l.beginTxDiff(failed)); rec.Status || len(rec.Calls) != 2 || rec.Revert == nil {
+		t.Fatalf("receipt of the failed batch: %+v", rec)
+	}
+
+	if err := block(batch(Call{To: shop, Value: 100}, Call{To: stored, Data: []byte{1}})); err != nil {
+		t.Fatalf("block: %v", err)
+	}
+	if l.BalanceOf(from) != 900 || l.BalanceOf(shop) != 100 || len(l.State) != keys+1 {
+		t.Fatalf("batch not committed: %d %d, %d keys", l.BalanceOf(from), l.BalanceOf(shop), len(l.State))
+	}
+	diff, err := l.BlockStateDiff(uint64(len(l.Blocks) - 1))
+	if err != nil || len(diff.Accounts) != 2 || len(diff.Storage) != 1 {
+		t.Fatalf("diff of the batch: %+v %v", diff, err)
+	}
+
+	// a batch may not spend coins a later transfer of the block relies on
+	spend := batch(Call{To: shop, Value: 900})
+	transfer := &Transaction{Type: TxPayment, From: from, To: shop,
+		TokenTransfers: []TokenTransfer{{From: from, To: shop, Amount: 900}}}
+	if err := block(spend, transfer); err != nil {
+		t.Fatalf("block: %v", err)
+	}
+	if l.BalanceOf(from) != 0 || l.BalanceOf(shop) != 1_000 {
+		t.Fatalf("balances %d %d", l.BalanceOf(from), l.BalanceOf(shop))
+	}
+
+	if err := block(&Transaction{Type: TxMulticall, From: from}); ErrorCodeOf(err) != CodeInvalidArgument {
+		t.Fatalf("block with an empty batch: %v", err)
+	}
+}
+
+func TestMemStateSnapshotHoldsItsLock(t *testing.T) {
+	st, _ := NewInMemory()
+	m := st.(*memState)
+	a, b := Address{0x01}, Address{0x02}
+	m.balances[a] = 100
+
+	done := make(chan error, 1)
+	err := m.snapshotState(func(v multicallState) error {
+		if err := v.Transfer(a, b, 60); err != nil {
+			return err
+		}
+		go func() { done <- m.Transfer(a, b, 30) }()
+		select {
+		case <-done:
+			t.Error("transfer ran during the snapshot")
+		case <-time.After(20 * time.Millisecond):
+		}
+		return errors.New("revert")
+	})
+	if err == nil {
+		t.Fatal("snapshot reported success")
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("transfer after the snapshot: %v", err)
+	}
+	if m.balances[a] != 70 || m.balances[b] != 30 {
+		t.Fatalf("balances %d %d", m.balances[a], m.balances[b])
+	}
+
+	rec, err := ExecuteMulticall(m, a, []Call{{To: b, Value: 10}, {To: b, Value: 100}}, 10*multicallCallGas, nil)
+	if err == nil || rec.Status || rec.Error == "" || m.balances[a] != 70 {
+		t.Fatalf("overdrawing batch: %+v %v, balance %d", rec, err, m.balances[a])
+	}
+	if rec, err := ExecuteMulticall(m, a, []Call{{To: b, Value: 10}}, multicallCallGas, nil); err != nil || !rec.Status || m.balances[b] != 40 {
+		t.Fatalf("batch: %+v %v", rec, err)
+	}
+}
@@ -0,0 +1,546 @@
+package core
+
+// name_service.go – Synnergy Name Service (SNS).
+//
+// SNS maps human-readable names under ".syn" ("alice.syn") to a payment
+// address, a content CID and free-form text records, and lets an address
+// claim one name as its reverse (display) name.
+//
+// Names are allocated through sealed-bid second-price auctions:
+//
+//  1. StartNameAuction opens bidding on a name nobody holds.
+//  2. For NameCommitPeriod bidders call CommitNameBid with
+//     NameBidCommitment(name, bidder, amount, salt) and a deposit of at
+//     least their bid. Only the deposit is public, so overpaying the deposit
+//     hides the real bid.
+//  3. For NameRevealPeriod bidders call RevealNameBid with amount and salt.
+//  4. FinalizeNameAuction registers the name to the highest revealed bid at
+//     the second-highest revealed price (NameMinPrice if it was the only
+//     valid bid). Losing and excess deposits are refunded; deposits of bids
+//     that were never revealed are forfeited.
+//
+// Payments and forfeits accrue to the "sns" module account. A registration
+// lasts NameRegistrationPeriod; anyone may pay RenewName to extend it. Once
+// expired, the name stops resolving and only its owner may renew it during
+// NameGracePeriod, after which it can be auctioned again.
+//
+// Ledger layout:
+//   sns:name:<name>          -> NameRecord
+//   sns:auction:<name>       -> NameAuction
+//   sns:reverse:<addr hex>   -> name
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ipfs/go-cid"
+)
+
+// NameTLD is the suffix of every SNS name.
+const NameTLD = ".syn"
+
+const (
+	NameCommitPeriod       = 72 * time.Hour
+	NameRevealPeriod       = 48 * time.Hour
+	NameRegistrationPeriod = 365 * 24 * time.Hour
+	NameGracePeriod        = 90 * 24 * time.Hour
+	// NameMinPrice is the reserve price of an auction.
+	NameMinPrice uint64 = 1_000
+	// NameRenewalFee is charged per NameRegistrationPeriod of renewal.
+	NameRenewalFee uint64 = 1_000
+	// maxNameTextRecords bounds the text records of one name.
+	maxNameTextRecords = 32
+)
+
+var (
+	ErrNameInvalid     = errors.New("invalid name")
+	ErrNameNotFound    = errors.New("name not registered")
+	ErrNameExpired     = errors.New("name expired")
+	ErrNameTaken       = errors.New("name not available")
+	ErrNameNotOwner    = errors.New("not the name owner")
+	ErrNameNoAddress   = errors.New("name has no address record")
+	ErrNameAuctionOpen = errors.New("name auction still open")
+	ErrNameBadPhase    = errors.New("name auction not in this phase")
+	ErrNameBadReveal   = errors.New("reveal does not match commitment")
+)
+
+var nameLabel = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{1,61}[a-z0-9])$`)
+
+var snsMu sync.Mutex
+
+// NameRecord is a registered name and its resolver records.
+type NameRecord struct {
+	Name       string            `json:"name"`
+	Owner      Address           `json:"owner"`
+	Addr       Address           `json:"addr"`
+	Content    string            `json:"content,omitempty"`
+	Text       map[string]string `json:"text,omitempty"`
+	Registered time.Time         `json:"registered"`
+	Expires    time.Time         `json:"expires"`
+}
+
+// Active reports whether the registration is unexpired at now.
+func (r *NameRecord) Active(now time.Time) bool { return now.Before(r.Expires) }
+
+// NameBid is one sealed bid. Amount is only known once revealed.
+type NameBid struct {
+	Bidder     Address   `json:"bidder"`
+	Commitment Hash      `json:"commitment"`
+	Deposit    uint64    `json:"deposit"`
+	Committed  time.Time `json:"committed"`
+	Revealed   bool      `json:"revealed"`
+	Amount     uint64    `json:"amount,omitempty"`
+}
+
+// NameAuction is the sealed-bid auction of a name.
+type NameAuction struct {
+	Name      string     `json:"name"`
+	Started   time.Time  `json:"started"`
+	RevealAt  time.Time  `json:"reveal_at"`
+	EndsAt    time.Time  `json:"ends_at"`
+	Bids      []*NameBid `json:"bids"`
+	Finalized bool       `json:"finalized"`
+	Winner    Address    `json:"winner,omitempty"`
+	Price     uint64     `json:"price,omitempty"`
+}
+
+func (a *NameAuction) bid(bidder Address) *NameBid {
+	for _, b := range a.Bids {
+		if b.Bidder == bidder {
+			return b
+		}
+	}
+	return nil
+}
+
+func snsAccount() Address { return ModuleAddress("sns") }
+
+func snsNameKey(name string) []byte    { return []byte("sns:name:" + name) }
+func snsAuctionKey(name string) []byte { return []byte("sns:auction:" + name) }
+func snsReverseKey(a Address) []byte   { return []byte("sns:reverse:" + a.Hex()) }
+
+// NormalizeName lower-cases name, appends NameTLD when missing and checks
+// that the label is 3–63 characters of a-z, 0-9 and inner hyphens.
+func NormalizeName(name string) (string, error) {
+	n := strings.ToLower(strings.TrimSpace(name))
+	label := strings.TrimSuffix(n, NameTLD)
+	if !nameLabel.MatchString(label) {
+		return "", fmt.Errorf("%w: %q", ErrNameInvalid, name)
+	}
+	return label + NameTLD, nil
+}
+
+// IsName reports whether s looks like an SNS name rather than an address.
+func IsName(s string) bool {
+	return strings.HasSuffix(strings.ToLower(strings.TrimSpace(s)), NameTLD)
+}
+
+// NameBidCommitment returns the commitment CommitNameBid expects:
+// keccak256(name ‖ bidder ‖ amount ‖ salt).
+func NameBidCommitment(name string, bidder Address, amount uint64, salt Hash) (Hash, error) {
+	n, err := NormalizeName(name)
+	if err != nil {
+		return Hash{}, err
+	}
+	var amt [8]byte
+	binary.BigEndian.PutUint64(amt[:], amount)
+	var h Hash
+	copy(h[:], crypto.Keccak256([]byte(n), bidder[:], amt[:], salt[:]))
+	return h, nil
+}
+
+func snsLoad(key []byte, v interface{}) error {
+	raw, err := CurrentStore().Get(key)
+	if err != nil || raw == nil {
+		return ErrNotFound
+	}
+	return json.Unmarshal(raw, v)
+}
+
+func snsStore(key []byte, v interface{}) error {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return CurrentStore().Set(key, raw)
+}
+
+// LookupName returns the record of name, expired or not.
+func LookupName(name string) (*NameRecord, error) {
+	n, err := NormalizeName(name)
+	if err != nil {
+		return nil, err
+	}
+	var r NameRecord
+	if err := snsLoad(snsNameKey(n), &r); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrNameNotFound, n)
+	}
+	return &r, nil
+}
+
+// GetNameAuction returns the current or last auction of name.
+func GetNameAuction(name string) (*NameAuction, error) {
+	n, err := NormalizeName(name)
+	if err != nil {
+		return nil, err
+	}
+	var a NameAuction
+	if err := snsLoad(snsAuctionKey(n), &a); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// ResolveName returns the address record of an active name.
+func ResolveName(name string) (Address, error) {
+	r, err := LookupName(name)
+	if err != nil {
+		return AddressZero, err
+	}
+	if !r.Active(time.Now().UTC()) {
+		return AddressZero, fmt.Errorf("%w: %s", ErrNameExpired, r.Name)
+	}
+	if r.Addr == AddressZero {
+		return AddressZero, fmt.Errorf("%w: %s", ErrNameNoAddress, r.Name)
+	}
+	return r.Addr, nil
+}
+
+// ResolveRecipient accepts an SNS name, a bech32 address or a legacy hex
+// address. Wallets and the CLI use it wherever a recipient is entered.
+func ResolveRecipient(s string) (Address, error) {
+	if IsName(s) {
+		return ResolveName(s)
+	}
+	return DecodeAddress(s)
+}
+
+// ReverseName returns the reverse name of addr. It is only reported while
+// the name is active and still resolves to addr.
+func ReverseName(addr Address) (string, bool) {
+	raw, err := CurrentStore().Get(snsReverseKey(addr))
+	if err != nil || raw == nil {
+		return "", false
+	}
+	if a, err := ResolveName(string(raw)); err != nil || a != addr {
+		return "", false
+	}
+	return string(raw), true
+}
+
+// StartNameAuction opens a sealed-bid auction for name.
+func StartNameAuction(name string) (*NameAuction, error) {
+	snsMu.Lock()
+	defer snsMu.Unlock()
+	return startNameAuction(name, time.Now().UTC())
+}
+
+func startNameAuction(name string, now time.Time) (*NameAuction, error) {
+	n, err := NormalizeName(name)
+	if err != nil {
+		return nil, err
+	}
+	var r NameRecord
+	if snsLoad(snsNameKey(n), &r) == nil && now.Before(r.Expires.Add(NameGracePeriod)) {
+		return nil, fmt.Errorf("%w: %s is registered until %s", ErrNameTaken, n, r.Expires.Format(time.RFC3339))
+	}
+	var prev NameAuction
+	if snsLoad(snsAuctionKey(n), &prev) == nil && !prev.Finalized {
+		return nil, fmt.Errorf("%w: %s", ErrNameAuctionOpen, n)
+	}
+	a := &NameAuction{
+		Name:     n,
+		Started:  now,
+		RevealAt: now.Add(NameCommitPeriod),
+		EndsAt:   now.Add(NameCommitPeriod + NameRevealPeriod),
+	}
+	if err := snsStore(snsAuctionKey(n), a); err != nil {
+		return nil, err
+	}
+	Broadcast("sns:auction", mustJSON(a))
+	return a, nil
+}
+
+// CommitNameBid places a sealed bid, escrowing deposit from bidder. A
+// bidder may bid once per auction.
+func CommitNameBid(ctx *Context, name string, bidder Address, commitment Hash, deposit uint64) error {
+	snsMu.Lock()
+	defer snsMu.Unlock()
+	return commitNameBid(ctx, name, bidder, commitment, deposit, time.Now().UTC())
+}
+
+func commitNameBid(ctx *Context, name string, bidder Address, commitment Hash, deposit uint64, now time.Time) error {
+	a, err := GetNameAuction(name)
+	if err != nil {
+		return fmt.Errorf("no auction for %s: %w", name, err)
+	}
+	if a.Finalized || !now.Before(a.RevealAt) {
+		return fmt.Errorf("%w: commit window closed", ErrNameBadPhase)
+	}
+	if a.bid(bidder) != nil {
+		return fmt.Errorf("%s already bid on %s", bidder.Hex(), a.Name)
+	}
+	if deposit < NameMinPrice {
+		return fmt.Errorf("%w: deposit below minimum %d", ErrBidTooLow, NameMinPrice)
+	}
+	if err := Transfer(ctx, AssetRef{Kind: AssetCoin}, bidder, snsAccount(), deposit); err != nil {
+		return err
+	}
+	a.Bids = append(a.Bids, &NameBid{Bidder: bidder, Commitment: commitment, Deposit: deposit, Committed: now})
+	return snsStore(snsAuctionKey(a.Name), a)
+}
+
+// RevealNameBid opens a sealed bid. A revealed amount above the deposit or
+// below NameMinPrice is recorded but cannot win.
+func RevealNameBid(name string, bidder Address, amount uint64, salt Hash) error {
+	snsMu.Lock()
+	defer snsMu.Unlock()
+	return revealNameBid(name, bidder, amount, salt, time.Now().UTC())
+}
+
+func revealNameBid(name string, bidder Address, amount uint64, salt Hash, now time.Time) error {
+	a, err := GetNameAuction(name)
+	if err != nil {
+		return fmt.Errorf("no auction for %s: %w", name, err)
+	}
+	if a.Finalized || now.Before(a.RevealAt) || !now.Before(a.EndsAt) {
+		return fmt.Errorf("%w: reveal window is %s to %s", ErrNameBadPhase,
+			a.RevealAt.Format(time.RFC3339), a.EndsAt.Format(time.RFC3339))
+	}
+	b := a.bid(bidder)
+	if b == nil || b.Revealed {
+		return fmt.Errorf("no sealed bid from %s", bidder.Hex())
+	}
+	want, _ := NameBidCommitment(a.Name, bidder, amount, salt)
+	if want != b.Commitment {
+		return ErrNameBadReveal
+	}
+	b.Revealed, b.Amount = true, amount
+	return snsStore(snsAuctionKey(a.Name), a)
+}
+
+// FinalizeNameAuction settles an auction whose reveal window has closed and
+// registers the name to the winner, if any. Anyone may call it.
+func FinalizeNameAuction(ctx *Context, name string) (*NameRecord, error) {
+	snsMu.Lock()
+	defer snsMu.Unlock()
+	return finalizeNameAuction(ctx, name, time.Now().UTC())
+}
+
+func finalizeNameAuction(ctx *Context, name string, now time.Time) (*NameRecord, error) {
+	a, err := GetNameAuction(name)
+	if err != nil {
+		return nil, fmt.Errorf("no auction for %s: %w", name, err)
+	}
+	if a.Finalized {
+		return nil, fmt.Errorf("%w: auction already finalized", ErrNameBadPhase)
+	}
+	if now.Before(a.EndsAt) {
+		return nil, fmt.Errorf("%w: reveal window ends %s", ErrNameAuctionOpen, a.EndsAt.Format(time.RFC3339))
+	}
+
+	// highest valid bid wins, earliest commit breaks ties
+	var win *NameBid
+	price := NameMinPrice
+	for _, b := range a.Bids {
+		if !b.Revealed || b.Amount < NameMinPrice || b.Amount > b.Deposit {
+			continue
+		}
+		switch {
+		case win == nil:
+			win = b
+		case b.Amount > win.Amount:
+			price = win.Amount
+			win = b
+		case b.Amount > price:
+			price = b.Amount
+		}
+	}
+
+	coin := AssetRef{Kind: AssetCoin}
+	for _, b := range a.Bids {
+		refund := uint64(0)
+		switch {
+		case b == win:
+			refund = b.Deposit - price
+		case b.Revealed:
+			refund = b.Deposit
+		}
+		if refund > 0 {
+			if err := Transfer(ctx, coin, snsAccount(), b.Bidder, refund); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	a.Finalized = true
+	var rec *NameRecord
+	if win != nil {
+		a.Winner, a.Price = win.Bidder, price
+		rec = &NameRecord{
+			Name:       a.Name,
+			Owner:      win.Bidder,
+			Addr:       win.Bidder,
+			Registered: now,
+			Expires:    now.Add(NameRegistrationPeriod),
+		}
+		if err := snsStore(snsNameKey(a.Name), rec); err != nil {
+			return nil, err
+		}
+		Broadcast("sns:register", mustJSON(rec))
+	}
+	if err := snsStore(snsAuctionKey(a.Name), a); err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
+
+// RenewName extends a registration by periods × NameRegistrationPeriod,
+// charging payer NameRenewalFee per period. During the grace period only
+// the owner may renew; an expired registration restarts from now.
+func RenewName(ctx *Context, name string, payer Address, periods uint32) (*NameRecord, error) {
+	snsMu.Lock()
+	defer snsMu.Unlock()
+	return renewName(ctx, name, payer, periods, time.Now().UTC())
+}
+
+func renewName(ctx *Context, name string, payer Address, periods uint32, now time.Time) (*NameRecord, error) {
+	if periods == 0 {
+		return nil, fmt.Errorf("renewal periods must be positive")
+	}
+	r, err := LookupName(name)
+	if err != nil {
+		return nil, err
+	}
+	if !now.Before(r.Expires.Add(NameGracePeriod)) {
+		return nil, fmt.Errorf("%w: grace period over, %s must be auctioned", ErrNameExpired, r.Name)
+	}
+	if !r.Active(now) && payer != r.Owner {
+		return nil, fmt.Errorf("%w: only the owner may renew during the grace period", ErrNameNotOwner)
+	}
+	if err := Transfer(ctx, AssetRef{Kind: AssetCoin}, payer, snsAccount(), NameRenewalFee*uint64(periods)); err != nil {
+		return nil, err
+	}
+	from := r.Expires
+	if from.Before(now) {
+		from = now
+	}
+	r.Expires = from.Add(time.Duration(periods) * NameRegistrationPeriod)
+	if err := snsStore(snsNameKey(r.Name), r); err != nil {
+		return nil, err
+	}
+	Broadcast("sns:renew", mustJSON(r))
+	return r, nil
+}
+
+// ownedName loads name and checks that owner holds an active registration.
+func ownedName(name string, owner Address, now time.Time) (*NameRecord, error) {
+	r, err := LookupName(name)
+	if err != nil {
+		return nil, err
+	}
+	if r.Owner != owner {
+		return nil, ErrNameNotOwner
+	}
+	if !r.Active(now) {
+		return nil, fmt.Errorf("%w: %s", ErrNameExpired, r.Name)
+	}
+	return r, nil
+}
+
+// updateName applies fn to a name held by owner and persists the result.
+func updateName(name string, owner Address, fn func(*NameRecord) error) (*NameRecord, error) {
+	snsMu.Lock()
+	defer snsMu.Unlock()
+	r, err := ownedName(name, owner, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	if err := fn(r); err != nil {
+		return nil, err
+	}
+	if err := snsStore(snsNameKey(r.Name), r); err != nil {
+		return nil, err
+	}
+	Broadcast("sns:update", mustJSON(r))
+	return r, nil
+}
+
+// TransferName hands ownership of name to a new owner. Resolver records are
+// kept; the new owner usually updates them with SetNameAddr.
+func TransferName(name string, owner, to Address) (*NameRecord, error) {
+	return updateName(name, owner, func(r *NameRecord) error {
+		if to == AddressZero {
+			return fmt.Errorf("cannot transfer to the zero address")
+		}
+		r.Owner = to
+		return nil
+	})
+}
+
+// SetNameAddr sets the address name resolves to. The zero address clears
+// the record.
+func SetNameAddr(name string, owner, addr Address) (*NameRecord, error) {
+	return updateName(name, owner, func(r *NameRecord) error {
+		r.Addr = addr
+		return nil
+	})
+}
+
+// SetNameContent sets the content CID of name; an empty CID clears it.
+func SetNameContent(name string, owner Address, content string) (*NameRecord, error) {
+	return updateName(name, owner, func(r *NameRecord) error {
+		if content != "" {
+			if _, err := cid.Decode(content); err != nil {
+				return fmt.Errorf("invalid content CID: %w", err)
+			}
+		}
+		r.Content = content
+		return nil
+	})
+}
+
+// SetNameText sets a text record of name; an empty value deletes it.
+func SetNameText(name string, owner Address, key, value string) (*NameRecord, error) {
+	return updateName(name, owner, func(r *NameRecord) error {
+		if key == "" {
+			return fmt.Errorf("text record key required")
+		}
+		if value == "" {
+			delete(r.Text, key)
+			return nil
+		}
+		if r.Text == nil {
+			r.Text = make(map[string]string)
+		}
+		if _, ok := r.Text[key]; !ok && len(r.Text) >= maxNameTextRecords {
+			return fmt.Errorf("at most %d text records per name", maxNameTextRecords)
+		}
+		r.Text[key] = value
+		return nil
+	})
+}
+
+// SetReverseName makes name the display name of addr. The name must
+// currently resolve to addr.
+func SetReverseName(addr Address, name string) error {
+	n, err := NormalizeName(name)
+	if err != nil {
+		return err
+	}
+	resolved, err := ResolveName(n)
+	if err != nil {
+		return err
+	}
+	if resolved != addr {
+		return fmt.Errorf("%s does not resolve to %s", n, addr.Hex())
+	}
+	return CurrentStore().Set(snsReverseKey(addr), []byte(n))
+}
@@ -238,8 +238,10 @@ func SetBroadcaster(fn BroadcasterFunc) {
 	broadcastMu.Unlock()
 }
 
-// Broadcast sends data using the configured broadcaster.
+// Broadcast sends data using the configured broadcaster. Workflows with a
+// matching event trigger are started as well.
 func Broadcast(topic string, data []byte) error {
+	notifyWorkflowEvent(topic, data)
 	broadcastMu.RLock()
 	fn := broadcastHook
 	broadcastMu.RUnlock()
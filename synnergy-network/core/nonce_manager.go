@@ -0,0 +1,525 @@
+package core
+
+// nonce_manager.go – nonce allocation for senders with many transactions in
+// flight.
+//
+// A sender whose transactions are built concurrently cannot read its next
+// nonce from the node for each one: two builders see the same value, and a
+// transaction that never reaches the pool leaves a gap that blocks every
+// later one. The NonceManager hands out nonces instead:
+//
+//	reserve   nonces are leased to a caller for ReserveTTL; released and
+//	          expired leases are handed out again, lowest first
+//	submit    a signed transaction carrying a leased nonce is sent to the
+//	          node and tracked until the node's nonce passes it
+//	sync      on every tick the manager reads the sender's nonce from the
+//	          node, forgets settled transactions and reports
+//	          gaps    free nonces below a leased or in-flight one
+//	          stuck   the lowest in-flight transaction, unconfirmed after
+//	                  StuckAfter
+//
+// Senders registered with a TxSigner get both fixed automatically when
+// AutoFix is set: a gap is filled with an empty payment to the sender itself
+// and a stuck transaction is re-signed with its gas price raised by
+// BumpPercent, up to MaxGasPrice. The pool only replaces a transaction with
+// one of the same sender and nonce paying MinReplacementBump percent more
+// (see Ledger.AddToPool), so neither does the manager, and bumps raise the
+// price by at least that much.
+//
+// State is kept in memory; after a restart the manager starts again from
+// the node's nonce.
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// NonceNode is what a NonceManager needs of a node.
+type NonceNode interface {
+	// NextNonce returns the nonce the next included transaction of addr
+	// must carry.
+	NextNonce(addr Address) (uint64, error)
+	SendTx(tx *Transaction) error
+}
+
+// TxSigner signs a transaction for its sender, setting Hash and Sig.
+type TxSigner func(tx *Transaction) error
+
+// NonceConfig tunes a NonceManager; zero fields take the defaults.
+type NonceConfig struct {
+	ReserveTTL  time.Duration `json:"reserve_ttl"`   // default 2m
+	StuckAfter  time.Duration `json:"stuck_after"`   // default 1m
+	BumpPercent uint64        `json:"bump_percent"`  // default 20
+	MaxGasPrice uint64        `json:"max_gas_price"` // 0: no cap
+	MaxReserve  int           `json:"max_reserve"`   // per call, default 1000
+}
+
+var (
+	ErrNonceSenderUnknown = errors.New("nonce sender not registered")
+	ErrNonceNotReserved   = errors.New("nonce not reserved")
+	ErrNonceBumpCapped    = errors.New("gas price at bump cap")
+)
+
+// NonceReservation is a lease of nonces.
+type NonceReservation struct {
+	Sender  Address   `json:"sender"`
+	Nonces  []uint64  `json:"nonces"`
+	Expires time.Time `json:"expires"`
+}
+
+// InFlightTx is a submitted transaction the node has not confirmed yet.
+type InFlightTx struct {
+	Nonce     uint64    `json:"nonce"`
+	Hash      string    `json:"hash"`
+	GasPrice  uint64    `json:"gas_price"`
+	Submitted time.Time `json:"submitted"`
+	LastSent  time.Time `json:"last_sent"`
+	Replaced  []string  `json:"replaced,omitempty"` // earlier hashes, oldest first
+	Stuck     bool      `json:"stuck"`
+}
+
+// NonceSenderState is a snapshot of a sender.
+type NonceSenderState struct {
+	Sender    Address      `json:"sender"`
+	Confirmed uint64       `json:"confirmed"` // node's next nonce at the last sync
+	Next      uint64       `json:"next"`      // next nonce never handed out
+	Reserved  []uint64     `json:"reserved"`
+	InFlight  []InFlightTx `json:"in_flight"`
+	Gaps      []uint64     `json:"gaps"`
+	Signer    bool         `json:"signer"`
+	AutoFix   bool         `json:"auto_fix"`
+	Synced    time.Time    `json:"synced"`
+}
+
+type nonceSender struct {
+	addr      Address
+	confirmed uint64
+	next      uint64
+	free      []uint64 // sorted, below next
+	reserved  map[uint64]time.Time
+	inflight  map[uint64]*nonceTx
+	signer    TxSigner
+	autoFix   bool
+	synced    time.Time
+}
+
+type nonceTx struct {
+	tx   *Transaction
+	info InFlightTx
+}
+
+// NonceManager allocates nonces and tracks in-flight transactions.
+type NonceManager struct {
+	node   NonceNode
+	cfg    NonceConfig
+	logger *logrus.Entry
+
+	mu      sync.Mutex
+	senders map[Address]*nonceSender
+}
+
+// NewNonceManager returns a manager sending through node.
+func NewNonceManager(node NonceNode, cfg NonceConfig) *NonceManager {
+	if cfg.ReserveTTL <= 0 {
+		cfg.ReserveTTL = 2 * time.Minute
+	}
+	if cfg.StuckAfter <= 0 {
+		cfg.StuckAfter = time.Minute
+	}
+	if cfg.BumpPercent == 0 {
+		cfg.BumpPercent = 20
+	}
+	if cfg.MaxReserve <= 0 {
+		cfg.MaxReserve = 1000
+	}
+	return &NonceManager{node: node, cfg: cfg, logger: ModuleLogger("nonce"),
+		senders: make(map[Address]*nonceSender)}
+}
+
+// Register adds a sender, or updates its signer and AutoFix. signer may be
+// nil for senders that sign their own transactions; such senders get gaps
+// and stuck transactions reported but not fixed.
+func (m *NonceManager) Register(addr Address, signer TxSigner, autoFix bool) (*NonceSenderState, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, err := m.sender(addr, true)
+	if err != nil {
+		return nil, err
+	}
+	s.signer, s.autoFix = signer, autoFix && signer != nil
+	return s.state(), nil
+}
+
+// sender returns the state of addr, starting it from the node's nonce when
+// create is set. The caller holds m.mu.
+func (m *NonceManager) sender(addr Address, create bool) (*nonceSender, error) {
+	if s, ok := m.senders[addr]; ok {
+		return s, nil
+	}
+	if !create {
+		return nil, fmt.Errorf("%w: %s", ErrNonceSenderUnknown, addr.Hex())
+	}
+	n, err := m.node.NextNonce(addr)
+	if err != nil {
+		return nil, err
+	}
+	s := &nonceSender{addr: addr, confirmed: n, next: n, synced: time.Now().UTC(),
+		reserved: make(map[uint64]time.Time), inflight: make(map[uint64]*nonceTx)}
+	m.senders[addr] = s
+	return s, nil
+}
+
+// Reserve leases count nonces of addr, reusing released ones first.
+// Unregistered senders are registered without a signer.
+func (m *NonceManager) Reserve(addr Address, count int) (*NonceReservation, error) {
+	if count < 1 || count > m.cfg.MaxReserve {
+		return nil, NewError(CodeInvalidArgument, "nonce", "count must be 1-%d", m.cfg.MaxReserve)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, err := m.sender(addr, true)
+	if err != nil {
+		return nil, err
+	}
+	exp := time.Now().UTC().Add(m.cfg.ReserveTTL)
+	r := &NonceReservation{Sender: addr, Nonces: s.take(count), Expires: exp}
+	for _, n := range r.Nonces {
+		s.reserved[n] = exp
+	}
+	return r, nil
+}
+
+func (s *nonceSender) take(count int) []uint64 {
+	out := make([]uint64, 0, count)
+	for len(out) < count && len(s.free) > 0 {
+		out = append(out, s.free[0])
+		s.free = s.free[1:]
+	}
+	for len(out) < count {
+		out = append(out, s.next)
+		s.next++
+	}
+	return out
+}
+
+// Release returns leased nonces that will not be used.
+func (m *NonceManager) Release(addr Address, nonces []uint64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, err := m.sender(addr, false)
+	if err != nil {
+		return err
+	}
+	for _, n := range nonces {
+		if _, ok := s.reserved[n]; !ok {
+			return fmt.Errorf("%w: %d", ErrNonceNotReserved, n)
+		}
+	}
+	for _, n := range nonces {
+		delete(s.reserved, n)
+		s.release(n)
+	}
+	return nil
+}
+
+func (s *nonceSender) release(n uint64) {
+	if n < s.confirmed {
+		return
+	}
+	i := sort.Search(len(s.free), func(i int) bool { return s.free[i] >= n })
+	if i < len(s.free) && s.free[i] == n {
+		return
+	}
+	s.free = append(s.free, 0)
+	copy(s.free[i+1:], s.free[i:])
+	s.free[i] = n
+}
+
+// Submit sends a signed transaction carrying a nonce leased to its sender
+// and tracks it. A transaction for an in-flight nonce replaces it and must
+// pay at least MinReplacementGasPrice of its gas price. The lease is kept if the node refuses the
+// transaction.
+func (m *NonceManager) Submit(tx *Transaction) (*InFlightTx, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, err := m.sender(tx.From, false)
+	if err != nil {
+		return nil, err
+	}
+	return m.submit(s, tx, time.Now().UTC())
+}
+
+func (m *NonceManager) submit(s *nonceSender, tx *Transaction, now time.Time) (*InFlightTx, error) {
+	prev, replacing := s.inflight[tx.Nonce]
+	if _, ok := s.reserved[tx.Nonce]; !ok && !replacing {
+		return nil, fmt.Errorf("%w: %d", ErrNonceNotReserved, tx.Nonce)
+	}
+	if replacing {
+		if min := MinReplacementGasPrice(prev.tx.GasPrice); tx.GasPrice < min {
+			return nil, NewError(CodeFailedPrecondition, "nonce",
+				"replacement of nonce %d must pay at least %d", tx.Nonce, min)
+		}
+	}
+	if err := m.node.SendTx(tx); err != nil {
+		return nil, err
+	}
+	delete(s.reserved, tx.Nonce)
+	it := &nonceTx{tx: tx, info: InFlightTx{Nonce: tx.Nonce, Hash: tx.Hash.Hex(), GasPrice: tx.GasPrice,
+		Submitted: now, LastSent: now}}
+	if replacing {
+		it.info.Submitted = prev.info.Submitted
+		it.info.Replaced = append(append([]string(nil), prev.info.Replaced...), prev.info.Hash)
+	}
+	s.inflight[tx.Nonce] = it
+	info := it.info
+	return &info, nil
+}
+
+// Send leases a nonce for tx.From, signs tx with the sender's signer and
+// submits it. The nonce is released again if signing or sending fails.
+func (m *NonceManager) Send(tx *Transaction) (*InFlightTx, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, err := m.sender(tx.From, false)
+	if err != nil {
+		return nil, err
+	}
+	if s.signer == nil {
+		return nil, NewError(CodeFailedPrecondition, "nonce", "sender %s has no signer", s.addr.Hex())
+	}
+	n := s.take(1)[0]
+	s.reserved[n] = time.Now().UTC().Add(m.cfg.ReserveTTL)
+	tx.Nonce = n
+	err = s.signer(tx)
+	var info *InFlightTx
+	if err == nil {
+		info, err = m.submit(s, tx, time.Now().UTC())
+	}
+	if err != nil {
+		delete(s.reserved, n)
+		s.release(n)
+		return nil, err
+	}
+	return info, nil
+}
+
+// Bump re-signs the in-flight transaction at nonce with its gas price
+// raised by BumpPercent and sends it as a replacement.
+func (m *NonceManager) Bump(addr Address, nonce uint64) (*InFlightTx, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, err := m.sender(addr, false)
+	if err != nil {
+		return nil, err
+	}
+	return m.bump(s, nonce, time.Now().UTC())
+}
+
+func (m *NonceManager) bump(s *nonceSender, nonce uint64, now time.Time) (*InFlightTx, error) {
+	it, ok := s.inflight[nonce]
+	if !ok {
+		return nil, NewError(CodeNotFound, "nonce", "no transaction in flight at nonce %d", nonce)
+	}
+	if s.signer == nil {
+		return nil, NewError(CodeFailedPrecondition, "nonce", "sender %s has no signer", s.addr.Hex())
+	}
+	min := MinReplacementGasPrice(it.tx.GasPrice)
+	price := it.tx.GasPrice + it.tx.GasPrice*m.cfg.BumpPercent/100
+	if price < min {
+		price = min
+	}
+	if m.cfg.MaxGasPrice > 0 && price > m.cfg.MaxGasPrice {
+		price = m.cfg.MaxGasPrice
+	}
+	if price < min {
+		return nil, fmt.Errorf("%w: nonce %d pays %d", ErrNonceBumpCapped, nonce, it.tx.GasPrice)
+	}
+	tx := *it.tx
+	tx.GasPrice, tx.Sig = price, nil
+	if err := s.signer(&tx); err != nil {
+		return nil, err
+	}
+	return m.submit(s, &tx, now)
+}
+
+// Sync refreshes every sender from the node, forgetting settled
+// transactions and expired leases and, for AutoFix senders, filling gaps
+// and bumping stuck transactions.
+func (m *NonceManager) Sync() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now().UTC()
+	var errs []error
+	for _, s := range m.senders {
+		if err := m.sync(s, now); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", s.addr.Hex(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (m *NonceManager) sync(s *nonceSender, now time.Time) error {
+	n, err := m.node.NextNonce(s.addr)
+	if err != nil {
+		return err
+	}
+	s.confirmed, s.synced = n, now
+	if s.next < n {
+		// transactions were sent around the manager
+		s.next = n
+	}
+	for k := range s.inflight {
+		if k < n {
+			delete(s.inflight, k)
+		}
+	}
+	for k, exp := range s.reserved {
+		switch {
+		case k < n:
+			delete(s.reserved, k)
+		case now.After(exp):
+			delete(s.reserved, k)
+			s.release(k)
+		}
+	}
+	i := sort.Search(len(s.free), func(i int) bool { return s.free[i] >= n })
+	s.free = s.free[i:]
+
+	if !s.autoFix {
+		return nil
+	}
+	var errs []error
+	for _, g := range s.gaps() {
+		fill := &Transaction{Type: TxPayment, From: s.addr, To: s.addr, Nonce: g,
+			GasLimit: 21000, GasPrice: 1, Timestamp: now.UnixMilli()}
+		if it := s.lowestInFlight(); it != nil {
+			fill.GasLimit, fill.GasPrice = it.tx.GasLimit, it.tx.GasPrice
+		}
+		s.free = s.free[1:]
+		s.reserved[g] = now.Add(m.cfg.ReserveTTL)
+		err := s.signer(fill)
+		if err == nil {
+			_, err = m.submit(s, fill, now)
+		}
+		if err != nil {
+			delete(s.reserved, g)
+			s.release(g)
+			errs = append(errs, fmt.Errorf("fill gap %d: %w", g, err))
+			break
+		}
+		m.logger.WithFields(logrus.Fields{"sender": s.addr.Hex(), "nonce": g}).Info("filled nonce gap")
+	}
+	if it := s.inflight[n]; it != nil && now.Sub(it.info.LastSent) >= m.cfg.StuckAfter {
+		info, err := m.bump(s, n, now)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("bump %d: %w", n, err))
+		} else {
+			m.logger.WithFields(logrus.Fields{"sender": s.addr.Hex(), "nonce": n, "gas_price": info.GasPrice}).
+				Info("replaced stuck transaction")
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// gaps returns the free nonces below the highest leased or in-flight one.
+func (s *nonceSender) gaps() []uint64 {
+	var top uint64
+	used := false
+	for k := range s.reserved {
+		if !used || k > top {
+			top, used = k, true
+		}
+	}
+	for k := range s.inflight {
+		if !used || k > top {
+			top, used = k, true
+		}
+	}
+	var out []uint64
+	for _, f := range s.free {
+		if used && f < top {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+func (s *nonceSender) lowestInFlight() *nonceTx {
+	var low *nonceTx
+	for _, it := range s.inflight {
+		if low == nil || it.tx.Nonce < low.tx.Nonce {
+			low = it
+		}
+	}
+	return low
+}
+
+func (s *nonceSender) state() *NonceSenderState {
+	st := &NonceSenderState{Sender: s.addr, Confirmed: s.confirmed, Next: s.next,
+		Reserved: []uint64{}, InFlight: []InFlightTx{}, Gaps: s.gaps(),
+		Signer: s.signer != nil, AutoFix: s.autoFix, Synced: s.synced}
+	for k := range s.reserved {
+		st.Reserved = append(st.Reserved, k)
+	}
+	sort.Slice(st.Reserved, func(i, j int) bool { return st.Reserved[i] < st.Reserved[j] })
+	if st.Gaps == nil {
+		st.Gaps = []uint64{}
+	}
+	return st
+}
+
+// State returns a snapshot of addr with its in-flight transactions in
+// nonce order. Only the lowest can be stuck: the others wait for it.
+func (m *NonceManager) State(addr Address) (*NonceSenderState, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, err := m.sender(addr, false)
+	if err != nil {
+		return nil, err
+	}
+	st := s.state()
+	now := time.Now().UTC()
+	for _, it := range s.inflight {
+		info := it.info
+		info.Stuck = it.tx.Nonce == s.confirmed && now.Sub(info.LastSent) >= m.cfg.StuckAfter
+		st.InFlight = append(st.InFlight, info)
+	}
+	sort.Slice(st.InFlight, func(i, j int) bool { return st.InFlight[i].Nonce < st.InFlight[j].Nonce })
+	return st, nil
+}
+
+// Senders lists the registered senders.
+func (m *NonceManager) Senders() []Address {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make([]Address, 0, len(m.senders))
+	for a := range m.senders {
+		out = append(out, a)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Hex() < out[j].Hex() })
+	return out
+}
+
+// Start syncs every interval until ctx is done.
+func (m *NonceManager) Start(ctx context.Context, interval time.Duration) {
+	go func() {
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+				if err := m.Sync(); err != nil {
+					m.logger.Warnf("sync: %v", err)
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,95 @@
+package core
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// testNonceNode is a node whose confirmed nonce the test advances.
+type testNonceNode struct {
+	nonce uint64
+	sent  []*Transaction
+}
+
+func (n *testNonceNode) NextNonce(Address) (uint64, error) { return n.nonce, nil }
+
+func (n *testNonceNode) SendTx(tx *Transaction) error {
+	n.sent = append(n.sent, tx)
+	return nil
+}
+
+func TestNonceManagerReserveSubmitAndReplace(t *testing.T) {
+	node := &testNonceNode{nonce: 5}
+	m := NewNonceManager(node, NonceConfig{})
+	from := Address{0x01}
+
+	res, err := m.Reserve(from, 3)
+	if err != nil || len(res.Nonces) != 3 || res.Nonces[0] != 5 || res.Nonces[2] != 7 {
+		t.Fatalf("reserve: %+v %v", res, err)
+	}
+	if err := m.Release(from, []uint64{6}); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	if again, _ := m.Reserve(from, 1); again.Nonces[0] != 6 {
+		t.Fatalf("released nonce not handed out first: %v", again.Nonces)
+	}
+	if _, err := m.Submit(&Transaction{Hash: Hash{9}, From: from, Nonce: 8, GasPrice: 100}); !errors.Is(err, ErrNonceNotReserved) {
+		t.Fatalf("submit of an unleased nonce: %v", err)
+	}
+	if _, err := m.Submit(&Transaction{Hash: Hash{1}, From: from, Nonce: 5, GasPrice: 100}); err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+
+	// a replacement must clear the pool's minimum bump
+	if _, err := m.Submit(&Transaction{Hash: Hash{2}, From: from, Nonce: 5, GasPrice: 109}); ErrorCodeOf(err) != CodeFailedPrecondition {
+		t.Fatalf("underpriced replacement: %v", err)
+	}
+	info, err := m.Submit(&Transaction{Hash: Hash{3}, From: from, Nonce: 5, GasPrice: 110})
+	if err != nil || len(info.Replaced) != 1 || info.Replaced[0] != (Hash{1}).Hex() {
+		t.Fatalf("replacement: %+v %v", info, err)
+	}
+	if len(node.sent) != 2 {
+		t.Fatalf("%d transactions sent, want 2", len(node.sent))
+	}
+
+	node.nonce = 6
+	if err := m.Sync(); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	st, _ := m.State(from)
+	if st.Confirmed != 6 || len(st.InFlight) != 0 || st.Signer || st.AutoFix {
+		t.Fatalf("state after confirmation: %+v", st)
+	}
+}
+
+func TestNonceManagerBumpsStuckTransactionsOfSigners(t *testing.T) {
+	node := &testNonceNode{}
+	m := NewNonceManager(node, NonceConfig{StuckAfter: time.Nanosecond, BumpPercent: 5, MaxGasPrice: 120})
+	from := Address{0x02}
+	signed := 0
+	if _, err := m.Register(from, func(tx *Transaction) error {
+		signed++
+		tx.Hash = Hash{byte(signed)}
+		return nil
+	}, true); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	if _, err := m.Send(&Transaction{From: from, GasPrice: 100}); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	// BumpPercent below the pool's minimum is raised to it
+	time.Sleep(time.Millisecond)
+	if err := m.Sync(); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if got := node.sent[len(node.sent)-1].GasPrice; got != 110 {
+		t.Fatalf("bumped gas price %d want 110", got)
+	}
+	// the next bump would pass MaxGasPrice before clearing the minimum
+	time.Sleep(time.Millisecond)
+	if _, err := m.Bump(from, 0); !errors.Is(err, ErrNonceBumpCapped) {
+		t.Fatalf("bump over the cap: %v", err)
+	}
+}
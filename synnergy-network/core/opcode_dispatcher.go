@@ -32,6 +32,8 @@ import (
 	"fmt"
 	"log"
 	"sync"
+
+	"github.com/sirupsen/logrus"
 )
 
 // ────────────────────────────────────────────────────────────────────────────
@@ -101,8 +103,12 @@ func Register(op Opcode, fn OpcodeFunc) {
 	opcodeTable[op] = fn
 }
 
-// Dispatch is called by the VM executor for every instruction.
-func Dispatch(ctx OpContext, op Opcode) error {
+// Dispatch is called by the VM executor for every instruction. Stack
+// underflow and memory limit panics raised by the handler are returned as
+// errors.
+func Dispatch(ctx OpContext, op Opcode) (err error) {
+	defer recoverVM(&err)
+
 	mu.RLock()
 	fn, ok := opcodeTable[op]
 	mu.RUnlock()
@@ -110,6 +116,10 @@ func Dispatch(ctx OpContext, op Opcode) error {
 	if !ok {
 		return fmt.Errorf("unknown opcode 0x%06X", op)
 	}
+	// Circuit breaker: paused contracts may only issue admin opcodes.
+	if err := checkPaused(ctx, op); err != nil {
+		return err
+	}
 	// Pre-charge gas (base only – dynamic part inside fn)
 	if err := ctx.Gas(GasCost(Opcode(op))); err != nil {
 		return err
@@ -205,6 +215,27 @@ func wrap(name string) OpcodeFunc {
 //	                                 0x1E Immutability
 //	                                 0x1E Warehouse
 //	                                 0x1E Gaming
+//	                                 0x1E NameService
+//	                                 0x1E Airdrop
+//	                                 0x1E PaymentStreams
+//	                                 0x1E Invoices
+//	                                 0x1E Exchange
+//	                                 0x1E AddressWatch
+//	                                 0x1E FeeOracle
+//	                                 0x1E NonceManager
+//	                                 0x1E Simulation
+//	                                 0x1E RevertReasons
+//	                                 0x1E TypedData
+//	                                 0x1E Permits
+//	                                 0x1E Multicall
+//	                                 0x1E StorageDeposits
+//	                                 0x1E MerkleAccumulator
+//	                                 0x1E LogBloom
+//	                                 0x1E ReadViews
+//	                                 0x1E Balances
+//	                                 0x1E SupplyAccounting
+//	                                 0x1E Emission
+//	                                 0x1E LoanPoolTreasury
 //                                       0x1F Historical
 //	0x1E Assets//				0x1E Event
 
@@ -237,15 +268,29 @@ var catalogue = []struct {
 	{"GetModelListing", 0x01000D},
 	{"ListModelListings", 0x01000E},
 	{"UpdateListingPrice", 0x01000F},
+	{"GetModelUsage", 0x01000F},
+	{"GetCreatorEarnings", 0x01000F},
 	{"RemoveListing", 0x010010},
 	{"InferModel", 0x010001},
 	{"AnalyseTransactions", 0x010002},
+	{"RegisterModelHost", 0x010003},
+	{"RequestInference", 0x010004},
+	{"SubmitInferenceResult", 0x010005},
+	{"FinalizeInference", 0x010006},
+	{"DisputeInference", 0x010007},
+	{"SubmitReexecution", 0x010008},
+	{"GetInferenceRequest", 0x010009},
+	{"SubmitGroundTruth", 0x01000A},
+	{"EvaluateDrift", 0x01000B},
+	{"DriftReportFor", 0x01000C},
+	{"UnregisterModelHost", 0x010011},
 	{"SwapExactIn", 0x020001},
 	{"AMM_AddLiquidity", 0x020002},
 	{"AMM_RemoveLiquidity", 0x020003},
 	{"Quote", 0x020004},
 	{"AllPairs", 0x020005},
 	{"InitPoolsFromFile", 0x020006},
+	{"AMM_FlashLoan", 0x020007},
 	{"NewAuthoritySet", 0x030001},
 	{"RecordVote", 0x030002},
 	{"RegisterCandidate", 0x030003},
@@ -281,6 +326,12 @@ var catalogue = []struct {
 	{"BankNode_ConnectFinNet", 0x030014},
 	{"BankNode_UpdateRules", 0x030015},
 	{"BankNode_SubmitTx", 0x030016},
+	{"Applier_AttachDocument", 0x030017},
+	{"Applier_Comment", 0x030018},
+	{"Applier_Comments", 0x030019},
+	{"Applier_Tenure", 0x03001A},
+	{"Applier_Tenures", 0x03001B},
+	{"Applier_Deregister", 0x03001C},
 	{"NewCharityPool", 0x040001},
 	{"Charity_Deposit", 0x040002},
 	{"Charity_Register", 0x040003},
@@ -291,6 +342,10 @@ var catalogue = []struct {
 	{"Charity_Donate", 0x040008},
 	{"Charity_WithdrawInternal", 0x040009},
 	{"Charity_Balances", 0x04000A},
+	{"Charity_CycleReport", 0x04000B},
+	{"Charity_CycleReports", 0x04000C},
+	{"Charity_VerifyCycle", 0x04000D},
+	{"BeaconRandomness", 0x04000E},
 	{"NewCoin", 0x050001},
 	{"Coin_Mint", 0x050002},
 	{"Coin_TotalSupply", 0x050003},
@@ -300,6 +355,16 @@ var catalogue = []struct {
 	{"InitCompliance", 0x060001},
 	{"Compliance_ValidateKYC", 0x060002},
 	{"EraseData", 0x060003},
+	{"StorePersonalData", 0x060019},
+	{"RequestErasure", 0x06001A},
+	{"ExecuteErasure", 0x06001B},
+	{"GetErasureCertificate", 0x06001C},
+	{"GetTombstone", 0x06001D},
+	{"ProposeKYCIssuer", 0x06001E},
+	{"KYC_RevokeIssuer", 0x06001F},
+	{"KYC_Issuer", 0x060020},
+	{"KYC_RevokeCredential", 0x060021},
+	{"KYC_IsVerified", 0x060022},
 	{"RecordFraudSignal", 0x060004},
 	{"Compliance_LogAudit", 0x060005},
 	{"Compliance_AuditTrail", 0x060006},
@@ -309,6 +374,9 @@ var catalogue = []struct {
 	{"Audit_Log", 0x06000A},
 	{"Audit_Events", 0x06000B},
 	{"Audit_Close", 0x06000C},
+	{"Audit_Checkpoint", 0x06001E},
+	{"Audit_Prove", 0x06001F},
+	{"Audit_VerifyProof", 0x060020},
 	{"InitComplianceManager", 0x060009},
 	{"SuspendAccount", 0x06000A},
 	{"ResumeAccount", 0x06000B},
@@ -317,6 +385,14 @@ var catalogue = []struct {
 	{"RemoveWhitelist", 0x06000E},
 	{"IsWhitelisted", 0x06000F},
 	{"Compliance_ReviewTx", 0x060010},
+	{"ScreenTx", 0x060011},
+	{"PendingReview", 0x060012},
+	{"Sanctions_Blacklist", 0x060013},
+	{"Sanctions_Unblacklist", 0x060014},
+	{"Sanctions_Freeze", 0x060015},
+	{"Sanctions_Unfreeze", 0x060016},
+	{"Sanctions_Status", 0x060017},
+	{"CheckSanctions", 0x060018},
 	{"AnalyzeAnomaly", 0x060009},
 	{"FlagAnomalyTx", 0x06000A},
 	{"Pick", 0x070001},
@@ -345,6 +421,16 @@ var catalogue = []struct {
 	{"ComputeDemand", 0x070014},
 	{"ComputeStakeConcentration", 0x070015},
 	{"AdjustConsensus", 0x070016},
+	{"ComputeBlockFullness", 0x07001F},
+	{"ComputePoolDepth", 0x070020},
+	{"ComputeStakeGini", 0x070021},
+	{"RunTelemetry", 0x070022},
+	{"WeightHistory", 0x070023},
+	{"SetSubBlockPipeline", 0x070024},
+	{"GossipPoSVote", 0x070025},
+	{"SetFairOrdering", 0x070026},
+	{"ObserveTx", 0x070027},
+	{"VerifyFairOrder", 0x070028},
 	{"AdjustStake", 0x070013},
 	{"PenalizeValidator", 0x070014},
 	{"RegisterValidator", 0x070013},
@@ -372,6 +458,12 @@ var catalogue = []struct {
 	{"ResumeContract", 0x080007},
 	{"UpgradeContract", 0x080008},
 	{"ContractInfo", 0x080009},
+	{"SetUpgradeAdmin", 0x08000A},
+	{"UpgradeAdminOf", 0x08000B},
+	{"UpgradeHistory", 0x08000C},
+	{"IsContractPaused", 0x08000D},
+	{"ContractPauseLog", 0x08000E},
+	{"PausedContracts", 0x08000F},
 	{"RegisterBridge", 0x090001},
 	{"AssertRelayer", 0x090002},
 	{"Iterator", 0x090003},
@@ -398,6 +490,11 @@ var catalogue = []struct {
 	{"CompleteBridgeTransfer", 0x090008},
 	{"GetBridgeTransfer", 0x090009},
 	{"ListBridgeTransfers", 0x09000A},
+	{"SetBridgeSafetyConfig", 0x09000C},
+	{"PauseBridge", 0x09000D},
+	{"ApproveBridgeUnpause", 0x09000E},
+	{"BridgeSafety", 0x09000F},
+	{"BridgeSafetyEvents", 0x090010},
 	{"RegisterNode", 0x0A0001},
 	{"UploadAsset", 0x0A0002},
 	{"Data_Pin", 0x0A0003},
@@ -418,6 +515,12 @@ var catalogue = []struct {
 	{"GetDataSet", 0x0A000E},
 	{"ListDataSets", 0x0A000F},
 	{"HasAccess", 0x0A0010},
+	{"ListDataSet", 0x0A0011},
+	{"DelistDataSet", 0x0A0012},
+	{"PurchaseDataAccess", 0x0A0013},
+	{"MeterDataAccess", 0x0A0014},
+	{"SettleDataUsage", 0x0A0015},
+	{"RevokeDataAccess", 0x0A0016},
 	{"UpdateOracleSource", 0x0A000C},
 	{"RemoveOracle", 0x0A000D},
 	{"GetOracleMetrics", 0x0A000E},
@@ -469,6 +572,13 @@ var catalogue = []struct {
 	{"DR_BackupNow", 0x0B0016},
 	{"DR_Restore", 0x0B0017},
 	{"DR_Verify", 0x0B0018},
+	{"HA_StreamWAL", 0x0B0019},
+	{"HA_Follow", 0x0B001A},
+	{"HA_Status", 0x0B001B},
+	{"Backup_Full", 0x0B001C},
+	{"Backup_Incremental", 0x0B001D},
+	{"Backup_VerifyLatest", 0x0B001E},
+	{"Backup_Prune", 0x0B001F},
 	{"UpdateParam", 0x0C0001},
 	{"ProposeChange", 0x0C0002},
 	{"VoteChange", 0x0C0003},
@@ -542,6 +652,11 @@ var catalogue = []struct {
 	{"CertificateOf", 0x0D0006},
 	{"ShouldThrottle", 0x0D0007},
 	{"ListCertificates", 0x0D0008},
+	{"RegisterMeterOracle", 0x0D0009},
+	{"RemoveMeterOracle", 0x0D000A},
+	{"GreenCertificate", 0x0D000B},
+	{"ExportGreenCertificate", 0x0D000C},
+	{"VerifyGreenCertificateExport", 0x0D000D},
 	{"NewLedger", 0x0E0001},
 	{"GetPendingSubBlocks", 0x0E0002},
 	{"LastBlockHash", 0x0E0003},
@@ -577,6 +692,7 @@ var catalogue = []struct {
 	{"Account_Delete", 0x0E001D},
 	{"Account_Balance", 0x0E001E},
 	{"Account_Transfer", 0x0E001F},
+	{"StateAt", 0x0E0020},
 	{"InitAMM", 0x0F0001},
 	{"Manager", 0x0F0002},
 	{"CreatePool", 0x0F0003},
@@ -840,6 +956,13 @@ var catalogue = []struct {
 	{"ResumeSidechain", 0x16000D},
 	{"UpdateSidechainValidators", 0x16000E},
 	{"RemoveSidechain", 0x16000F},
+	{"SetCheckpointInterval", 0x160010},
+	{"SubmitCheckpoint", 0x160011},
+	{"GetCheckpoint", 0x160012},
+	{"ListCheckpoints", 0x160013},
+	{"FinalizeCheckpoints", 0x160014},
+	{"DisputeCheckpoint", 0x160015},
+	{"ResolveDispute", 0x160016},
 	{"InitStateChannels", 0x170001},
 	{"Channels", 0x170002},
 	{"OpenChannel", 0x170003},
@@ -1186,6 +1309,7 @@ var catalogue = []struct {
 	{"UtilitiesTransfer", 0x1B0051},
 	{"UtilitiesMint", 0x1B0052},
 	{"UtilitiesBurn", 0x1B0053},
+	{"opCREATE2", 0x1B0054},
 	{"VM_Burn", 0x1C0001},
 	{"BurnLP", 0x1C0002},
 	{"MintLP", 0x1C0003},
@@ -1238,6 +1362,11 @@ var catalogue = []struct {
 	{"VM_SandboxReset", 0x1C0032},
 	{"VM_SandboxStatus", 0x1C0033},
 	{"VM_SandboxList", 0x1C0034},
+	{"ChargeSLoad", 0x1C0035},
+	{"ChargeSStore", 0x1C0036},
+	{"RefundSelfDestruct", 0x1C0037},
+	{"SettleGas", 0x1C0038},
+	{"CreateContract2", 0x1C0039},
 	{"NewRandomWallet", 0x1D0001},
 	{"WalletFromMnemonic", 0x1D0002},
 	{"NewHDWalletFromSeed", 0x1D0003},
@@ -1304,6 +1433,12 @@ var catalogue = []struct {
 	{"Plasma_Withdraw", 0x1E0003},
 	{"Plasma_SubmitBlock", 0x1E0004},
 	{"Plasma_GetBlock", 0x1E0005},
+	{"Plasma_SubmitCommitment", 0x1E0007},
+	{"Plasma_StartOutputExit", 0x1E0008},
+	{"Plasma_ChallengeExit", 0x1E0009},
+	{"Plasma_ProcessExits", 0x1E000A},
+	{"Plasma_TriggerMassExit", 0x1E000B},
+	{"Plasma_ExitQueue", 0x1E000C},
 	{"SetQuota", 0x1E0001},
 	{"GetQuota", 0x1E0002},
 	{"ChargeResources", 0x1E0003},
@@ -1353,11 +1488,21 @@ var catalogue = []struct {
 	{"InitEnergyEfficiency", 0x1E0001},
 	{"AddVerification", 0x1E0008},
 	{"ListVerifications", 0x1E0009},
+	{"Carbon_MintableOffsets", 0x1E000A},
+	{"Carbon_MintFromOffset", 0x1E000B},
+	{"Carbon_Retire", 0x1E000C},
+	{"Carbon_Retirements", 0x1E000D},
+	{"Carbon_Registry", 0x1E000E},
+	{"Carbon_Reconcile", 0x1E000F},
 	{"EnergyEff", 0x1E0002},
 	{"RecordStats", 0x1E0003},
 	{"EfficiencyOf", 0x1E0004},
 	{"NetworkAverage", 0x1E0005},
 	{"ListEfficiency", 0x1E0006},
+	{"EffPolicy", 0x1E0010},
+	{"EffShouldThrottle", 0x1E0011},
+	{"ProposerWeights", 0x1E0012},
+	{"ProposerFor", 0x1E0013},
 	{"NewEnergyNode", 0x1E0020},
 	{"EnergyNodeStart", 0x1E0021},
 	{"EnergyNodeStop", 0x1E0022},
@@ -1371,6 +1516,13 @@ var catalogue = []struct {
 	{"ListResourceListings", 0x1E0005},
 	{"GetResourceDeal", 0x1E0006},
 	{"ListResourceDeals", 0x1E0007},
+	{"AdvertiseResources", 0x1E0008},
+	{"OpenResourceLease", 0x1E0009},
+	{"SubmitUsageReport", 0x1E000A},
+	{"SettleResourceLeases", 0x1E000B},
+	{"DisputeResourceLease", 0x1E000C},
+	{"ArbitrateResourceLease", 0x1E000D},
+	{"TerminateResourceLease", 0x1E000E},
 	{"NewFinalizationManager", 0x1E0001},
 	{"FinalizeBlock", 0x1E0002},
 	{"FinalizeBatchManaged", 0x1E0003},
@@ -1393,6 +1545,27 @@ var catalogue = []struct {
 	{"DeFi_CreateSynthetic", 0x1E0010},
 	{"DeFi_MintSynthetic", 0x1E0011},
 	{"DeFi_BurnSynthetic", 0x1E0012},
+	{"DeFi_DepositCollateral", 0x1E0013},
+	{"DeFi_WithdrawCollateral", 0x1E0014},
+	{"DeFi_ClaimSyntheticFees", 0x1E0015},
+	{"DeFi_LiquidateSynthetic", 0x1E0016},
+	{"DeFi_SellPrediction", 0x1E0017},
+	{"DeFi_ClaimPrediction", 0x1E0018},
+	{"DeFi_Harvest", 0x1E0019},
+	{"DeFi_EmergencyWithdraw", 0x1E001A},
+	{"DeFi_CreateInsurancePool", 0x1E001B},
+	{"DeFi_Underwrite", 0x1E001C},
+	{"DeFi_WithdrawCapital", 0x1E001D},
+	{"DeFi_StakeAssessor", 0x1E001E},
+	{"DeFi_UnstakeAssessor", 0x1E001F},
+	{"DeFi_AssessClaim", 0x1E0020},
+	{"DeFi_DecideClaim", 0x1E0021},
+	{"DeFi_AppealClaim", 0x1E0022},
+	{"DeFi_RuleOnAppeal", 0x1E0023},
+	{"DeFi_SettleClaim", 0x1E0024},
+	{"DeFi_RequestMilestone", 0x1E0025},
+	{"DeFi_VoteMilestone", 0x1E0026},
+	{"DeFi_CloseMilestone", 0x1E0027},
 	{"RegisterIDWallet", 0x1D0007},
 	{"IsIDWalletRegistered", 0x1D0008},
 	{"NewOffChainWallet", 0x1D0007},
@@ -1403,6 +1576,14 @@ var catalogue = []struct {
 	{"BroadcastSignedTx", 0x1D000C},
 	{"RegisterRecovery", 0x1D0007},
 	{"RecoverAccount", 0x1D0008},
+	{"InitiateRecovery", 0x1D0009},
+	{"ApproveRecovery", 0x1D000A},
+	{"CancelRecovery", 0x1D000B},
+	{"AccountSigner", 0x1D000C},
+	{"Session_Authorise", 0x1D000D},
+	{"Session_Revoke", 0x1D000E},
+	{"Session_List", 0x1D000F},
+	{"Session_Usage", 0x1D0010},
 	{"BinaryTreeNew", 0x1E0001},
 	{"BinaryTreeInsert", 0x1E0002},
 	{"BinaryTreeSearch", 0x1E0003},
@@ -1428,6 +1609,8 @@ var catalogue = []struct {
 	{"ClosePoll", 0x1E0003},
 	{"GetPoll", 0x1E0004},
 	{"ListPolls", 0x1E0005},
+	{"FlagPoll", 0x1E0006},
+	{"ReleasePollStake", 0x1E0007},
 	{"InitFeedback", 0x1E0001},
 	{"Feedback_Submit", 0x1E0002},
 	{"Feedback_Get", 0x1E0003},
@@ -1438,6 +1621,14 @@ var catalogue = []struct {
 	{"ForumListThreads", 0x1E0003},
 	{"ForumAddComment", 0x1E0004},
 	{"ForumListComments", 0x1E0005},
+	{"ForumFlagThread", 0x1E0006},
+	{"ForumFlagComment", 0x1E0007},
+	{"ForumReleaseStake", 0x1E0008},
+	{"HideContent", 0x1E0009},
+	{"RestoreContent", 0x1E000A},
+	{"ContentFlags", 0x1E000B},
+	{"SetContentPolicy", 0x1E000C},
+	{"ModerationHistory", 0x1E000D},
 	{"CompressLedger", 0x1E0001},
 	{"DecompressLedger", 0x1E0002},
 	{"SaveCompressedSnapshot", 0x1E0003},
@@ -1445,6 +1636,10 @@ var catalogue = []struct {
 	{"Bio_Enroll", 0x1E0001},
 	{"Bio_Verify", 0x1E0002},
 	{"Bio_Delete", 0x1E0003},
+	{"Bio_DeriveKey", 0x1E0004},
+	{"Bio_RegisterRecovery", 0x1E0006},
+	{"Bio_RecoverAccount", 0x1E0007},
+	{"GetRecoveryRecord", 0x1E0005},
 	{"BSN_Register", 0x1E0011},
 	{"BSN_VerifyTx", 0x1E0012},
 	{"BSN_Remove", 0x1E0013},
@@ -1459,12 +1654,27 @@ var catalogue = []struct {
 	{"Swarm_Start", 0x1E0005},
 	{"Swarm_Stop", 0x1E0006},
 	{"Swarm_Peers", 0x1E0007},
+	{"NewSwarmController", 0x1E0008},
+	{"Swarm_StartAll", 0x1E0009},
+	{"Swarm_StopAll", 0x1E000A},
+	{"Swarm_RollingUpgrade", 0x1E000B},
+	{"Swarm_Status", 0x1E000C},
+	{"Swarm_RestartNode", 0x1E000D},
 	{"NewWorkflow", 0x1E0001},
 	{"AddWorkflowAction", 0x1E0002},
 	{"SetWorkflowTrigger", 0x1E0003},
 	{"SetWebhook", 0x1E0004},
 	{"ExecuteWorkflow", 0x1E0005},
 	{"ListWorkflows", 0x1E0006},
+	{"AddWorkflowStep", 0x1E0007},
+	{"SetWorkflowRetry", 0x1E0008},
+	{"SetWorkflowSecret", 0x1E0009},
+	{"GetWorkflow", 0x1E000A},
+	{"RunWorkflow", 0x1E000B},
+	{"ResumeWorkflowRun", 0x1E000C},
+	{"GetWorkflowRun", 0x1E000D},
+	{"ListWorkflowRuns", 0x1E000E},
+	{"WorkflowWebhook", 0x1E000F},
 	{"CreateWallet", 0x1D0007},
 	{"ImportWallet", 0x1D0008},
 	{"WalletBalance", 0x1D0009},
@@ -1482,22 +1692,48 @@ var catalogue = []struct {
 	{"RegisterRentalAgreement", 0x1E0005},
 	{"PayRent", 0x1E0006},
 	{"TerminateRentalAgreement", 0x1E0007},
+	{"FractionaliseProperty", 0x1E0008},
+	{"SetShareRules", 0x1E0009},
+	{"TransferShares", 0x1E000A},
+	{"ShareHolders", 0x1E000B},
+	{"DepositRent", 0x1E000C},
+	{"DistributeRent", 0x1E000D},
+	{"DistributeDueRent", 0x1E000E},
+	{"RentDistributions", 0x1E000F},
 	{"InitEvents", 0x1E0001},
 	{"EmitEvent", 0x1E0002},
 	{"GetEvent", 0x1E0003},
 	{"ListEvents", 0x1E0004},
+	{"PublishEvent", 0x1E0005},
+	{"EventRange", 0x1E0006},
+	{"EventHead", 0x1E0007},
+	{"SubscribeEvents", 0x1E0008},
+	{"EmitContractEvent", 0x1E0009},
+	{"ContractEvents", 0x1E000A},
 	{"InitEmployment", 0x1E0001},
 	{"CreateJob", 0x1E0002},
 	{"SignJob", 0x1E0003},
 	{"RecordWork", 0x1E0004},
 	{"PaySalary", 0x1E0005},
 	{"GetJob", 0x1E0006},
+	{"StartSalaryStream", 0x1E0007},
+	{"SetWithholdings", 0x1E0008},
+	{"StreamAccrued", 0x1E0009},
+	{"ClaimSalary", 0x1E000A},
+	{"TerminateSalaryStream", 0x1E000B},
+	{"Payslips", 0x1E000C},
 	{"EscrowCreate", 0x1E0001},
 	{"EscrowDeposit", 0x1E0002},
 	{"EscrowRelease", 0x1E0003},
 	{"EscrowCancel", 0x1E0004},
 	{"EscrowGet", 0x1E0005},
 	{"EscrowList", 0x1E0006},
+	{"EscrowCreateMilestones", 0x1E0007},
+	{"EscrowReleaseMilestone", 0x1E0008},
+	{"EscrowRaiseDispute", 0x1E0009},
+	{"EscrowArbitrate", 0x1E000A},
+	{"EscrowRefundExpired", 0x1E000B},
+	{"EscrowHistory", 0x1E000C},
 	{"CreateMarketListing", 0x1E0001},
 	{"PurchaseItem", 0x1E0002},
 	{"CancelListing", 0x1E0003},
@@ -1506,21 +1742,131 @@ var catalogue = []struct {
 	{"ListMarketListings", 0x1E0006},
 	{"GetMarketDeal", 0x1E0007},
 	{"ListMarketDeals", 0x1E0008},
+	{"PlaceBid", 0x1E0009},
+	{"ListMarketBids", 0x1E000A},
+	{"SettleAuction", 0x1E000B},
+	{"SettleEndedAuctions", 0x1E000C},
+	{"GetMarketRoyalty", 0x1E000D},
+	{"StartNameAuction", 0x1E0001},
+	{"CommitNameBid", 0x1E0002},
+	{"RevealNameBid", 0x1E0003},
+	{"FinalizeNameAuction", 0x1E0004},
+	{"RenewName", 0x1E0005},
+	{"TransferName", 0x1E0006},
+	{"SetNameAddr", 0x1E0007},
+	{"SetNameContent", 0x1E0008},
+	{"SetNameText", 0x1E0009},
+	{"SetReverseName", 0x1E000A},
+	{"LookupName", 0x1E000B},
+	{"ResolveName", 0x1E000C},
+	{"ReverseName", 0x1E000D},
+	{"Airdrop_Publish", 0x1E0001},
+	{"Airdrop_Claim", 0x1E0002},
+	{"Airdrop_Sweep", 0x1E0003},
+	{"Airdrop_Info", 0x1E0004},
+	{"Stream_Create", 0x1E0001},
+	{"Stream_Withdraw", 0x1E0002},
+	{"Stream_Cancel", 0x1E0003},
+	{"Stream_Info", 0x1E0004},
+	{"Stream_List", 0x1E0005},
+	{"Invoice_Create", 0x1E0001},
+	{"Invoice_Info", 0x1E0002},
+	{"Invoice_List", 0x1E0003},
+	{"Exchange_RequireTag", 0x1E0001},
+	{"Exchange_ScanDeposits", 0x1E0002},
+	{"Exchange_PlanSweep", 0x1E0003},
+	{"Watch_Create", 0x1E0001},
+	{"Watch_Delete", 0x1E0002},
+	{"Watch_Info", 0x1E0003},
+	{"Watch_List", 0x1E0004},
+	{"Watch_Notifications", 0x1E0005},
+	{"Fee_Estimate", 0x1E0001},
+	{"Nonce_Register", 0x1E0001},
+	{"Nonce_Reserve", 0x1E0002},
+	{"Nonce_Release", 0x1E0003},
+	{"Nonce_Submit", 0x1E0004},
+	{"Nonce_Send", 0x1E0005},
+	{"Nonce_Bump", 0x1E0006},
+	{"Nonce_State", 0x1E0007},
+	{"Simulate_Tx", 0x1E0001},
+	{"Revert_Decode", 0x1E0001},
+	{"ABI_RegisterErrors", 0x1E0002},
+	{"ABI_Errors", 0x1E0003},
+	{"TypedData_Hash", 0x1E0001},
+	{"TypedData_Sign", 0x1E0002},
+	{"TypedData_Verify", 0x1E0003},
+	{"TypedData_DomainSeparator", 0x1E0004},
+	{"Token_Permit", 0x1E0001},
+	{"Token_PermitNonce", 0x1E0002},
+	{"AMM_SwapWithPermit", 0x1E0003},
+	{"Multicall_Execute", 0x1E0001},
+	{"Multicall_Validate", 0x1E0002},
+	{"StorageDeposit_Lock", 0x1E0001},
+	{"StorageDeposit_Release", 0x1E0002},
+	{"StorageDeposit_TopUp", 0x1E0003},
+	{"StorageDeposit_Sweep", 0x1E0004},
+	{"StorageDeposit_Purge", 0x1E0005},
+	{"Merkle_Append", 0x1E0001},
+	{"Merkle_Root", 0x1E0002},
+	{"Merkle_Proof", 0x1E0003},
+	{"Rollup_TxProof", 0x1E0004},
+	{"LogsBloom", 0x1E0001},
+	{"FilterLogs", 0x1E0002},
+	{"VerifyLogsBloom", 0x1E0003},
+	{"Ledger_ReadView", 0x1E0001},
+	{"ReadViewStats", 0x1E0002},
+	{"MigrateBalances", 0x1E0001},
+	{"CheckBalanceInvariants", 0x1E0002},
+	{"AssetSupply", 0x1E0003},
+	{"Ledger_SupplyOf", 0x1E0001},
+	{"Ledger_CirculatingSupply", 0x1E0002},
+	{"Ledger_TotalBurned", 0x1E0003},
+	{"TokenSupply", 0x1E0004},
+	{"Ledger_EmissionPolicy", 0x1E0001},
+	{"EmissionPolicy_Project", 0x1E0002},
+	{"Ledger_BlockEmission", 0x1E0003},
+	{"Ledger_TotalEmitted", 0x1E0004},
+	{"Ledger_VerifyEmission", 0x1E0005},
+	{"RecordTreasuryFlow", 0x1E0001},
+	{"TreasuryEntries", 0x1E0002},
+	{"GetTreasuryFlowReport", 0x1E0003},
 	{"NewFaucet", 0x1E0001},
 	{"Faucet_Request", 0x1E0002},
 	{"Faucet_Balance", 0x1E0003},
 	{"Faucet_SetAmount", 0x1E0004},
 	{"Faucet_SetCooldown", 0x1E0005},
+	{"Faucet_Challenge", 0x1E0006},
+	{"Faucet_Claim", 0x1E0007},
+	{"Faucet_TopUp", 0x1E0008},
+	{"Faucet_Withdraw", 0x1E0009},
+	{"Faucet_SetIPCooldown", 0x1E000A},
+	{"Faucet_SetDifficulty", 0x1E000B},
 	{"RegisterItem", 0x1E0001},
 	{"UpdateLocation", 0x1E0002},
 	{"MarkStatus", 0x1E0003},
 	{"GetItem", 0x1E0004},
+	{"SupplyChildren", 0x1E0005},
+	{"TransferCustody", 0x1E0006},
+	{"CustodyChain", 0x1E0007},
+	{"AttachSupplySensor", 0x1E0008},
+	{"RecordSupplyReading", 0x1E0009},
+	{"PollSupplySensors", 0x1E000A},
+	{"SupplyAlerts", 0x1E000B},
+	{"Provenance", 0x1E000C},
 	{"InitHealthcare", 0x1E0001},
 	{"RegisterPatient", 0x1E0002},
 	{"AddHealthRecord", 0x1E0003},
 	{"GrantAccess", 0x1E0004},
 	{"RevokeAccess", 0x1E0005},
 	{"ListHealthRecords", 0x1E0006},
+	{"RegisterHealthKey", 0x1E0007},
+	{"AddEncryptedHealthRecord", 0x1E0008},
+	{"GrantRecordAccess", 0x1E0009},
+	{"RevokeRecordAccess", 0x1E000A},
+	{"FetchRecordKey", 0x1E000B},
+	{"FulfilHealthGrant", 0x1E000C},
+	{"BreakGlassAccess", 0x1E000D},
+	{"HealthAccessLog", 0x1E000E},
 	{"Assets_Register", 0x1E0001},
 	{"Assets_Transfer", 0x1E0002},
 	{"Assets_Get", 0x1E0003},
@@ -1550,6 +1896,16 @@ var catalogue = []struct {
 	{"FinishGame", 0x1E0003},
 	{"GetGame", 0x1E0004},
 	{"ListGames", 0x1E0005},
+	{"RegisterGameRules", 0x1E0006},
+	{"CreateTurnGame", 0x1E0007},
+	{"JoinTurnGame", 0x1E0008},
+	{"CancelTurnGame", 0x1E0009},
+	{"SubmitGameMove", 0x1E000A},
+	{"DisputeGameMove", 0x1E000B},
+	{"FinalizeTurnGame", 0x1E000C},
+	{"GetTurnGame", 0x1E000D},
+	{"GameMoves", 0x1E000E},
+	{"GameLeaderboard", 0x1E000F},
 	{"SYN1401_Issue", 0x1E0001},
 	{"SYN1401_Accrue", 0x1E0002},
 	{"SYN1401_Redeem", 0x1E0003},
@@ -1607,8 +1963,12 @@ var catalogue = []struct {
 // init normalises the opcode catalogue, assigning sequential identifiers per
 // category and wiring handlers into the dispatcher.  The catalogue as committed
 // may contain duplicated numeric values; this routine deterministically
-// re-numbers them to guarantee uniqueness at runtime.
+// re-numbers them to guarantee uniqueness at runtime.  The resulting table is
+// only logged at debug level (LOG_LEVELS=opcodes=debug).
 func init() {
+	lg := ModuleLogger("opcodes")
+	dump := lg.Logger.IsLevelEnabled(logrus.DebugLevel)
+
 	// next keeps track of the next ordinal for each category byte.
 	next := make(map[byte]uint32)
 
@@ -1624,15 +1984,16 @@ func init() {
 		nameToOp[entry.name] = op
 		Register(op, wrap(entry.name))
 
-		bin := []byte{byte(op >> 16), byte(op >> 8), byte(op)}
-		log.Printf("[OPCODES] %-32s = %08b = 0x%06X",
-			entry.name, bin, op)
+		if dump {
+			bin := []byte{byte(op >> 16), byte(op >> 8), byte(op)}
+			lg.Debugf("%-32s = %08b = 0x%06X", entry.name, bin, op)
+		}
 	}
 
 	// Build the gas table once opcodes have been normalised.
 	initGasTable()
 
-	log.Printf("[OPCODES] %d opcodes registered; %d gas-priced", len(opcodeTable), len(gasTable))
+	lg.Debugf("%d opcodes registered; %d gas-priced", len(opcodeTable), len(gasTable))
 }
 
 // Hex returns the canonical hexadecimal representation (upper-case, 6 digits).
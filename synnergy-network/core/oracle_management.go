@@ -1,6 +1,7 @@
 package core
 
 import (
+	"crypto/ed25519"
 	"encoding/json"
 	"fmt"
 	"time"
@@ -108,3 +109,52 @@ func RemoveOracle(id string) error {
 	_ = CurrentStore().Delete([]byte(metricsKey(id)))
 	return nil
 }
+
+// OraclePriceScale is the fixed-point scale used for OraclePrice values.
+const OraclePriceScale = 100_000_000
+
+// OraclePrice is the value format pushed by price-feed daemons. Prices are
+// fixed-point integers scaled by OraclePriceScale so that every node decodes
+// the same value.
+type OraclePrice struct {
+	Pair      string `json:"pair"`
+	Price     int64  `json:"price"`
+	Sources   int    `json:"sources"`
+	Timestamp int64  `json:"ts"`
+}
+
+// OracleFeedCall is the argument payload of a PushFeedSigned transaction.
+type OracleFeedCall struct {
+	OracleID string `json:"oracle_id"`
+	Value    []byte `json:"value"`
+	Sig      []byte `json:"sig"`
+}
+
+// NewOracleFeedTx builds a signed contract-call transaction that invokes
+// PushFeedSigned for oracleID. The value is signed with the oracle key so it
+// verifies against the registered public key, and the transaction itself is
+// signed with the same key in the wallet's sig||pub layout.
+func NewOracleFeedTx(oracleID string, value []byte, priv ed25519.PrivateKey, nonce uint64) (*Transaction, error) {
+	op, err := ToBytecode("PushFeedSigned")
+	if err != nil {
+		return nil, err
+	}
+	args, err := json.Marshal(OracleFeedCall{OracleID: oracleID, Value: value, Sig: ed25519.Sign(priv, value)})
+	if err != nil {
+		return nil, err
+	}
+	pub := priv.Public().(ed25519.PublicKey)
+	tx := &Transaction{
+		Type:      TxContractCall,
+		From:      Ed25519Address(pub),
+		Nonce:     nonce,
+		Payload:   append(op, args...),
+		Timestamp: time.Now().UnixMilli(),
+	}
+	hash := tx.HashTx()
+	signed := make([]byte, 96)
+	copy(signed[:64], ed25519.Sign(priv, hash[:]))
+	copy(signed[64:], pub)
+	tx.Sig = signed
+	return tx, nil
+}
@@ -0,0 +1,254 @@
+package core
+
+// payment_streams.go – per-second payment streams.
+//
+// A sender deposits RatePerSecond × duration of a coin or token into the
+// "streams" module account. The deposit vests to the recipient second by
+// second between Start and End, and the recipient may withdraw whatever has
+// vested at any time. Either party may cancel: the recipient receives the
+// vested but unwithdrawn part and the sender is refunded the rest, so a
+// cancelled stream settles pro rata to the time it ran.
+//
+// Ledger layout:
+//   paystream:<id>   -> PaymentStream
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/bits"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Payment stream states.
+const (
+	StreamActive    = "active"
+	StreamCancelled = "cancelled"
+	StreamDepleted  = "depleted"
+)
+
+var (
+	ErrStreamNotFound = errors.New("payment stream not found")
+	ErrStreamClosed   = errors.New("payment stream closed")
+	ErrStreamNotParty = errors.New("not a party to the payment stream")
+	ErrStreamNoFunds  = errors.New("nothing to withdraw from the payment stream")
+)
+
+var paymentStreamMu sync.Mutex
+
+// PaymentStream is a deposit vesting to a recipient every second.
+type PaymentStream struct {
+	ID            string    `json:"id"`
+	Sender        Address   `json:"sender"`
+	Recipient     Address   `json:"recipient"`
+	Asset         AssetRef  `json:"asset"`
+	RatePerSecond uint64    `json:"rate_per_second"`
+	Deposit       uint64    `json:"deposit"`
+	Start         time.Time `json:"start"`
+	End           time.Time `json:"end"`
+	Withdrawn     uint64    `json:"withdrawn"`
+	Refunded      uint64    `json:"refunded,omitempty"`
+	Status        string    `json:"status"`
+	CancelledAt   time.Time `json:"cancelled_at,omitempty"`
+}
+
+// Vested returns the part of the deposit vested at now.
+func (s *PaymentStream) Vested(now time.Time) uint64 {
+	end := now
+	if s.End.Before(end) {
+		end = s.End
+	}
+	if !s.CancelledAt.IsZero() && s.CancelledAt.Before(end) {
+		end = s.CancelledAt
+	}
+	if !end.After(s.Start) {
+		return 0
+	}
+	secs := uint64(end.Sub(s.Start) / time.Second)
+	hi, v := bits.Mul64(secs, s.RatePerSecond)
+	if hi != 0 || v > s.Deposit {
+		return s.Deposit
+	}
+	return v
+}
+
+// Withdrawable returns what the recipient may withdraw at now.
+func (s *PaymentStream) Withdrawable(now time.Time) uint64 {
+	return s.Vested(now) - s.Withdrawn
+}
+
+func paymentStreamAccount() Address { return ModuleAddress("streams") }
+
+func paymentStreamKey(id string) []byte { return []byte("paystream:" + id) }
+
+// CreatePaymentStream streams rate per second of asset from ctx.Caller to
+// recipient for duration, starting at start or now when start is zero. The
+// whole deposit is escrowed up front.
+func CreatePaymentStream(ctx *Context, recipient Address, asset AssetRef, rate uint64, duration time.Duration, start time.Time) (*PaymentStream, error) {
+	now := time.Now().UTC()
+	if start.IsZero() {
+		start = now
+	}
+	if recipient == AddressZero || recipient == ctx.Caller {
+		return nil, NewError(CodeInvalidArgument, "streams", "stream recipient must differ from the sender")
+	}
+	if rate == 0 {
+		return nil, NewError(CodeInvalidArgument, "streams", "stream rate must be >0")
+	}
+	if duration < time.Second {
+		return nil, NewError(CodeInvalidArgument, "streams", "stream duration must be at least one second")
+	}
+	if start.Before(now.Add(-time.Second)) {
+		return nil, NewError(CodeInvalidArgument, "streams", "stream cannot start in the past")
+	}
+	secs := uint64(duration / time.Second)
+	hi, deposit := bits.Mul64(secs, rate)
+	if hi != 0 {
+		return nil, NewError(CodeInvalidArgument, "streams", "stream deposit overflows")
+	}
+	s := &PaymentStream{
+		ID:            uuid.New().String(),
+		Sender:        ctx.Caller,
+		Recipient:     recipient,
+		Asset:         asset,
+		RatePerSecond: rate,
+		Deposit:       deposit,
+		Start:         start.UTC(),
+		End:           start.UTC().Add(time.Duration(secs) * time.Second),
+		Status:        StreamActive,
+	}
+	paymentStreamMu.Lock()
+	defer paymentStreamMu.Unlock()
+	if err := Transfer(ctx, asset, ctx.Caller, paymentStreamAccount(), deposit); err != nil {
+		return nil, err
+	}
+	if err := savePaymentStream(s); err != nil {
+		return nil, err
+	}
+	Broadcast("stream:create", mustJSON(s))
+	return s, nil
+}
+
+// WithdrawFromStream pays amount of the vested balance to the recipient;
+// amount 0 withdraws everything vested so far.
+func WithdrawFromStream(ctx *Context, id string, amount uint64) (*PaymentStream, error) {
+	paymentStreamMu.Lock()
+	defer paymentStreamMu.Unlock()
+	return withdrawFromStream(ctx, id, amount, time.Now().UTC())
+}
+
+func withdrawFromStream(ctx *Context, id string, amount uint64, now time.Time) (*PaymentStream, error) {
+	s, err := GetPaymentStream(id)
+	if err != nil {
+		return nil, err
+	}
+	if ctx.Caller != s.Recipient {
+		return nil, ErrStreamNotParty
+	}
+	avail := s.Withdrawable(now)
+	if amount == 0 {
+		amount = avail
+	}
+	if amount == 0 {
+		return nil, ErrStreamNoFunds
+	}
+	if amount > avail {
+		return nil, fmt.Errorf("%w: %d requested, %d vested", ErrStreamNoFunds, amount, avail)
+	}
+	if err := Transfer(ctx, s.Asset, paymentStreamAccount(), s.Recipient, amount); err != nil {
+		return nil, err
+	}
+	s.Withdrawn += amount
+	if s.Status == StreamActive && s.Withdrawn == s.Deposit {
+		s.Status = StreamDepleted
+	}
+	if err := savePaymentStream(s); err != nil {
+		return nil, err
+	}
+	Broadcast("stream:withdraw", mustJSON(struct {
+		ID     string `json:"id"`
+		Amount uint64 `json:"amount"`
+	}{s.ID, amount}))
+	return s, nil
+}
+
+// CancelPaymentStream stops a stream. The recipient receives what has vested
+// and not been withdrawn; the sender is refunded the unvested rest. Either
+// party may cancel.
+func CancelPaymentStream(ctx *Context, id string) (*PaymentStream, error) {
+	paymentStreamMu.Lock()
+	defer paymentStreamMu.Unlock()
+	return cancelPaymentStream(ctx, id, time.Now().UTC())
+}
+
+func cancelPaymentStream(ctx *Context, id string, now time.Time) (*PaymentStream, error) {
+	s, err := GetPaymentStream(id)
+	if err != nil {
+		return nil, err
+	}
+	if ctx.Caller != s.Sender && ctx.Caller != s.Recipient {
+		return nil, ErrStreamNotParty
+	}
+	if s.Status != StreamActive || !now.Before(s.End) {
+		return nil, fmt.Errorf("%w: %s", ErrStreamClosed, s.ID)
+	}
+	s.CancelledAt = now
+	vested := s.Vested(now)
+	if owed := vested - s.Withdrawn; owed > 0 {
+		if err := Transfer(ctx, s.Asset, paymentStreamAccount(), s.Recipient, owed); err != nil {
+			return nil, err
+		}
+		s.Withdrawn = vested
+	}
+	if refund := s.Deposit - vested; refund > 0 {
+		if err := Transfer(ctx, s.Asset, paymentStreamAccount(), s.Sender, refund); err != nil {
+			return nil, err
+		}
+		s.Refunded = refund
+	}
+	s.Status = StreamCancelled
+	if err := savePaymentStream(s); err != nil {
+		return nil, err
+	}
+	Broadcast("stream:cancel", mustJSON(s))
+	return s, nil
+}
+
+// GetPaymentStream returns a stream by ID.
+func GetPaymentStream(id string) (*PaymentStream, error) {
+	raw, err := CurrentStore().Get(paymentStreamKey(id))
+	if err != nil || raw == nil {
+		return nil, fmt.Errorf("%w: %s", ErrStreamNotFound, id)
+	}
+	var s PaymentStream
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// ListPaymentStreams returns the streams addr sends or receives, or every
+// stream when addr is the zero address.
+func ListPaymentStreams(addr Address) ([]PaymentStream, error) {
+	it := CurrentStore().Iterator([]byte("paystream:"), nil)
+	defer it.Close()
+	var out []PaymentStream
+	for it.Next() {
+		var s PaymentStream
+		if err := json.Unmarshal(it.Value(), &s); err != nil {
+			continue
+		}
+		if addr == AddressZero || s.Sender == addr || s.Recipient == addr {
+			out = append(out, s)
+		}
+	}
+	return out, it.Error()
+}
+
+func savePaymentStream(s *PaymentStream) error {
+	data, _ := json.Marshal(s)
+	return CurrentStore().Set(paymentStreamKey(s.ID), data)
+}
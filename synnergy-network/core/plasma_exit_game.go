@@ -0,0 +1,501 @@
+package core
+
+// plasma_exit_game.go – exit protocol for the plasma bridge.
+//
+// The operator commits the merkle root of every child-chain block. Users
+// leave the child chain by exiting an output they own: the exit names the
+// output position, carries the transaction that created it plus a merkle
+// inclusion proof against the committed root, and locks a bond. During the
+// challenge period anyone can cancel the exit by proving, again with an
+// inclusion proof, that a committed transaction spends the output; the
+// challenger receives the bond. Exits are paid strictly in priority order
+// (older positions first), so a later output can never be paid ahead of an
+// earlier one that is still in its challenge period.
+//
+// If the operator stops publishing commitments for longer than
+// OperatorTimeout anyone may switch the bridge into mass-exit mode. New
+// deposits and commitments are then refused and every exit, old or new,
+// shares a single extended challenge window that starts when the mode was
+// entered, after which the queue is drained in priority order.
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Output positions encode block height, transaction index and output index
+// as blk*PlasmaBlockOffset + tx*PlasmaTxOffset + out.
+const (
+	PlasmaBlockOffset = 1_000_000_000
+	PlasmaTxOffset    = 10_000
+)
+
+// PlasmaOutputPosition encodes the position of an output.
+func PlasmaOutputPosition(blk uint64, tx, out uint32) uint64 {
+	return blk*PlasmaBlockOffset + uint64(tx)*PlasmaTxOffset + uint64(out)
+}
+
+// DecodePlasmaPosition splits a position into block, tx and output index.
+func DecodePlasmaPosition(pos uint64) (blk uint64, tx, out uint32) {
+	return pos / PlasmaBlockOffset, uint32(pos % PlasmaBlockOffset / PlasmaTxOffset), uint32(pos % PlasmaTxOffset)
+}
+
+// PlasmaOutput is a child-chain output.
+type PlasmaOutput struct {
+	Owner  Address `json:"owner"`
+	Token  TokenID `json:"token"`
+	Amount uint64  `json:"amount"`
+}
+
+// PlasmaTx is a child-chain transaction. Inputs are output positions.
+// Merkle leaves are the JSON encoding of the transaction.
+type PlasmaTx struct {
+	Inputs  []uint64       `json:"inputs,omitempty"`
+	Outputs []PlasmaOutput `json:"outputs"`
+}
+
+// PlasmaCommitment is a child-chain block root published on the root chain.
+type PlasmaCommitment struct {
+	Height    uint64   `json:"height"`
+	Root      [32]byte `json:"root"`
+	Deposit   bool     `json:"deposit,omitempty"`
+	Timestamp int64    `json:"ts"`
+}
+
+// PlasmaExitClaim is an exit of a single output.
+type PlasmaExitClaim struct {
+	Position   uint64       `json:"position"`
+	Owner      Address      `json:"owner"`
+	Output     PlasmaOutput `json:"output"`
+	Bond       uint64       `json:"bond"`
+	StartedAt  int64        `json:"started_at"`
+	ExitableAt int64        `json:"exitable_at"`
+	Status     string       `json:"status"`
+	Challenger Address      `json:"challenger,omitempty"`
+}
+
+// Plasma exit states.
+const (
+	PlasmaExitPending    = "pending"
+	PlasmaExitFinalized  = "finalized"
+	PlasmaExitChallenged = "challenged"
+)
+
+// PlasmaExitParams configures the exit game.
+type PlasmaExitParams struct {
+	ChallengePeriod time.Duration `json:"challenge_period"`
+	MassExitPeriod  time.Duration `json:"mass_exit_period"`
+	OperatorTimeout time.Duration `json:"operator_timeout"`
+	ExitBond        uint64        `json:"exit_bond"`
+}
+
+// DefaultPlasmaExitParams returns the default exit game parameters.
+func DefaultPlasmaExitParams() PlasmaExitParams {
+	return PlasmaExitParams{
+		ChallengePeriod: 7 * 24 * time.Hour,
+		MassExitPeriod:  14 * 24 * time.Hour,
+		OperatorTimeout: 24 * time.Hour,
+		ExitBond:        100,
+	}
+}
+
+// PlasmaExitStatus summarises the state of the exit game.
+type PlasmaExitStatus struct {
+	Height       uint64 `json:"height"`
+	LastCommit   int64  `json:"last_commit"`
+	MassExit     bool   `json:"mass_exit"`
+	MassExitFrom int64  `json:"mass_exit_from,omitempty"`
+	Pending      int    `json:"pending_exits"`
+}
+
+// PlasmaExitGame runs the exit protocol over the plasma bridge accounts.
+type PlasmaExitGame struct {
+	mu       sync.Mutex
+	led      plasmaState
+	operator Address
+	params   PlasmaExitParams
+	now      func() time.Time
+}
+
+// plasmaState is the ledger state and balances the exit game settles on.
+type plasmaState interface {
+	GetState(key []byte) ([]byte, error)
+	SetState(key, value []byte) error
+	HasState(key []byte) (bool, error)
+	PrefixIterator(prefix []byte) StateIterator
+	Transfer(from, to Address, amount uint64) error
+}
+
+var (
+	plasmaExitOnce sync.Once
+	plasmaExitGame *PlasmaExitGame
+)
+
+// InitPlasmaExitGame configures the global exit game. Zero params fall back
+// to DefaultPlasmaExitParams.
+func InitPlasmaExitGame(led plasmaState, operator Address, p PlasmaExitParams) {
+	plasmaExitOnce.Do(func() { plasmaExitGame = NewPlasmaExitGame(led, operator, p) })
+}
+
+// PlasmaExits returns the global exit game.
+func PlasmaExits() *PlasmaExitGame { return plasmaExitGame }
+
+// NewPlasmaExitGame creates an exit game bound to led.
+func NewPlasmaExitGame(led plasmaState, operator Address, p PlasmaExitParams) *PlasmaExitGame {
+	def := DefaultPlasmaExitParams()
+	if p.ChallengePeriod <= 0 {
+		p.ChallengePeriod = def.ChallengePeriod
+	}
+	if p.MassExitPeriod <= 0 {
+		p.MassExitPeriod = def.MassExitPeriod
+	}
+	if p.OperatorTimeout <= 0 {
+		p.OperatorTimeout = def.OperatorTimeout
+	}
+	return &PlasmaExitGame{led: led, operator: operator, params: p, now: time.Now}
+}
+
+// plasmaExitBondAccount holds exit bonds until exits resolve.
+func plasmaExitBondAccount() Address { return ModuleAddress("plasma_exit") }
+
+var (
+	plasmaHeightKey   = []byte("plasma:xg:height")
+	plasmaLastKey     = []byte("plasma:xg:last")
+	plasmaMassExitKey = []byte("plasma:xg:mass")
+)
+
+func plasmaCommitKey(h uint64) []byte  { return []byte(fmt.Sprintf("plasma:xg:commit:%020d", h)) }
+func plasmaClaimKey(pos uint64) []byte { return []byte(fmt.Sprintf("plasma:xg:exit:%020d", pos)) }
+
+func (g *PlasmaExitGame) getUint(key []byte) uint64 {
+	raw, err := g.led.GetState(key)
+	if err != nil || len(raw) != 8 {
+		return 0
+	}
+	return binary.BigEndian.Uint64(raw)
+}
+
+func (g *PlasmaExitGame) putUint(key []byte, v uint64) error {
+	return g.led.SetState(key, uint64ToBytes(v))
+}
+
+// massExitFrom returns when mass-exit mode began, or 0.
+func (g *PlasmaExitGame) massExitFrom() int64 { return int64(g.getUint(plasmaMassExitKey)) }
+
+// commit stores a new block commitment. Callers hold g.mu.
+func (g *PlasmaExitGame) commit(root [32]byte, deposit bool) (PlasmaCommitment, error) {
+	if g.massExitFrom() != 0 {
+		return PlasmaCommitment{}, errors.New("plasma: mass exit in progress")
+	}
+	now := g.now().Unix()
+	c := PlasmaCommitment{Height: g.getUint(plasmaHeightKey) + 1, Root: root, Deposit: deposit, Timestamp: now}
+	raw, _ := json.Marshal(c)
+	if err := g.led.SetState(plasmaCommitKey(c.Height), raw); err != nil {
+		return PlasmaCommitment{}, err
+	}
+	if err := g.putUint(plasmaHeightKey, c.Height); err != nil {
+		return PlasmaCommitment{}, err
+	}
+	if !deposit {
+		if err := g.putUint(plasmaLastKey, uint64(now)); err != nil {
+			return PlasmaCommitment{}, err
+		}
+	}
+	return c, nil
+}
+
+// SubmitCommitment publishes the root of the next child-chain block. Only
+// the operator may commit.
+func (g *PlasmaExitGame) SubmitCommitment(caller Address, root [32]byte) (PlasmaCommitment, error) {
+	if caller != g.operator {
+		return PlasmaCommitment{}, ErrUnauthorized
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.commit(root, false)
+}
+
+// Commitment returns the commitment at height h.
+func (g *PlasmaExitGame) Commitment(h uint64) (PlasmaCommitment, error) {
+	raw, err := g.led.GetState(plasmaCommitKey(h))
+	if err != nil || len(raw) == 0 {
+		return PlasmaCommitment{}, ErrNotFound
+	}
+	var c PlasmaCommitment
+	err = json.Unmarshal(raw, &c)
+	return c, err
+}
+
+// Deposit locks amount of token in the bridge and creates a deposit block
+// holding a single output owned by from. The output is at position
+// PlasmaOutputPosition(height, 0, 0).
+func (g *PlasmaExitGame) Deposit(from Address, token TokenID, amount uint64) (PlasmaCommitment, PlasmaTx, error) {
+	if amount == 0 {
+		return PlasmaCommitment{}, PlasmaTx{}, errors.New("zero amount")
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.massExitFrom() != 0 {
+		return PlasmaCommitment{}, PlasmaTx{}, errors.New("plasma: mass exit in progress")
+	}
+	tok, ok := GetToken(token)
+	if !ok {
+		return PlasmaCommitment{}, PlasmaTx{}, errors.New("token unknown")
+	}
+	if err := tok.Transfer(from, plasmaBridgeAccount(token), amount); err != nil {
+		return PlasmaCommitment{}, PlasmaTx{}, err
+	}
+	tx := PlasmaTx{Outputs: []PlasmaOutput{{Owner: from, Token: token, Amount: amount}}}
+	leaf, _ := json.Marshal(tx)
+	_, root, err := MerkleProof([][]byte{leaf}, 0)
+	if err != nil {
+		return PlasmaCommitment{}, PlasmaTx{}, err
+	}
+	c, err := g.commit(root, true)
+	return c, tx, err
+}
+
+// verifyInclusion checks that tx is the transaction at (blk, idx) of a
+// committed block.
+func (g *PlasmaExitGame) verifyInclusion(blk uint64, idx uint32, tx PlasmaTx, proof [][]byte) error {
+	c, err := g.Commitment(blk)
+	if err != nil {
+		return fmt.Errorf("plasma: block %d not committed", blk)
+	}
+	leaf, _ := json.Marshal(tx)
+	if !VerifyMerklePath(c.Root, leaf, proof, idx) {
+		return errors.New("plasma: invalid inclusion proof")
+	}
+	return nil
+}
+
+// checkOperator switches to mass-exit mode when the operator has been
+// silent for longer than OperatorTimeout. Callers hold g.mu.
+func (g *PlasmaExitGame) checkOperator() (bool, error) {
+	if g.massExitFrom() != 0 {
+		return true, nil
+	}
+	last := int64(g.getUint(plasmaLastKey))
+	now := g.now().Unix()
+	if last == 0 || now-last <= int64(g.params.OperatorTimeout/time.Second) {
+		return false, nil
+	}
+	if err := g.putUint(plasmaMassExitKey, uint64(now)); err != nil {
+		return false, err
+	}
+	_ = Broadcast("plasma:mass_exit", uint64ToBytes(uint64(now)))
+	return true, nil
+}
+
+// TriggerMassExit enters mass-exit mode if the operator has missed its
+// commitment deadline. It reports whether the mode is active.
+func (g *PlasmaExitGame) TriggerMassExit() (bool, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.checkOperator()
+}
+
+// exitableAt returns when an exit started now may be paid.
+func (g *PlasmaExitGame) exitableAt(start int64) int64 {
+	if from := g.massExitFrom(); from != 0 {
+		if start < from {
+			start = from
+		}
+		return start + int64(g.params.MassExitPeriod/time.Second)
+	}
+	return start + int64(g.params.ChallengePeriod/time.Second)
+}
+
+// StartExit starts the exit of the output at pos, created by tx and proven
+// by proof against the committed root of its block. The owner locks the
+// exit bond.
+func (g *PlasmaExitGame) StartExit(owner Address, pos uint64, tx PlasmaTx, proof [][]byte) (PlasmaExitClaim, error) {
+	blk, idx, out := DecodePlasmaPosition(pos)
+	if int(out) >= len(tx.Outputs) {
+		return PlasmaExitClaim{}, errors.New("plasma: output index out of range")
+	}
+	o := tx.Outputs[out]
+	if o.Owner != owner {
+		return PlasmaExitClaim{}, ErrUnauthorized
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if _, err := g.checkOperator(); err != nil {
+		return PlasmaExitClaim{}, err
+	}
+	if ok, _ := g.led.HasState(plasmaClaimKey(pos)); ok {
+		return PlasmaExitClaim{}, errors.New("plasma: output already exited")
+	}
+	if err := g.verifyInclusion(blk, idx, tx, proof); err != nil {
+		return PlasmaExitClaim{}, err
+	}
+	if g.params.ExitBond > 0 {
+		if err := g.led.Transfer(owner, plasmaExitBondAccount(), g.params.ExitBond); err != nil {
+			return PlasmaExitClaim{}, fmt.Errorf("plasma: exit bond: %w", err)
+		}
+	}
+	now := g.now().Unix()
+	ex := PlasmaExitClaim{
+		Position:   pos,
+		Owner:      owner,
+		Output:     o,
+		Bond:       g.params.ExitBond,
+		StartedAt:  now,
+		ExitableAt: g.exitableAt(now),
+		Status:     PlasmaExitPending,
+	}
+	if err := g.saveClaim(ex); err != nil {
+		return PlasmaExitClaim{}, err
+	}
+	_ = Broadcast("plasma:exit_started", plasmaJSON(ex))
+	return ex, nil
+}
+
+func (g *PlasmaExitGame) saveClaim(ex PlasmaExitClaim) error {
+	raw, _ := json.Marshal(ex)
+	return g.led.SetState(plasmaClaimKey(ex.Position), raw)
+}
+
+// Exit returns the exit of the output at pos.
+func (g *PlasmaExitGame) Exit(pos uint64) (PlasmaExitClaim, error) {
+	raw, err := g.led.GetState(plasmaClaimKey(pos))
+	if err != nil || len(raw) == 0 {
+		return PlasmaExitClaim{}, ErrNotFound
+	}
+	var ex PlasmaExitClaim
+	err = json.Unmarshal(raw, &ex)
+	return ex, err
+}
+
+// ChallengeExit cancels a pending exit by proving that spend, included at
+// spendPos's block and transaction index, consumes the exiting output.
+// The exit bond is paid to the challenger.
+func (g *PlasmaExitGame) ChallengeExit(challenger Address, pos uint64, spend PlasmaTx, spendPos uint64, proof [][]byte) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	ex, err := g.Exit(pos)
+	if err != nil {
+		return err
+	}
+	if ex.Status != PlasmaExitPending {
+		return fmt.Errorf("plasma: exit is %s", ex.Status)
+	}
+	if g.now().Unix() >= ex.ExitableAt {
+		return errors.New("plasma: challenge period over")
+	}
+	spends := false
+	for _, in := range spend.Inputs {
+		if in == pos {
+			spends = true
+			break
+		}
+	}
+	if !spends {
+		return errors.New("plasma: transaction does not spend the exiting output")
+	}
+	blk, idx, _ := DecodePlasmaPosition(spendPos)
+	if err := g.verifyInclusion(blk, idx, spend, proof); err != nil {
+		return err
+	}
+	if ex.Bond > 0 {
+		if err := g.led.Transfer(plasmaExitBondAccount(), challenger, ex.Bond); err != nil {
+			return err
+		}
+	}
+	ex.Status = PlasmaExitChallenged
+	ex.Challenger = challenger
+	if err := g.saveClaim(ex); err != nil {
+		return err
+	}
+	_ = Broadcast("plasma:exit_challenged", plasmaJSON(ex))
+	return nil
+}
+
+// ExitQueue returns pending exits in priority order.
+func (g *PlasmaExitGame) ExitQueue() ([]PlasmaExitClaim, error) {
+	it := g.led.PrefixIterator([]byte("plasma:xg:exit:"))
+	var out []PlasmaExitClaim
+	for it.Next() {
+		var ex PlasmaExitClaim
+		if err := json.Unmarshal(it.Value(), &ex); err != nil {
+			continue
+		}
+		if ex.Status == PlasmaExitPending {
+			out = append(out, ex)
+		}
+	}
+	if err := it.Error(); err != nil {
+		return nil, err
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Position < out[j].Position })
+	return out, nil
+}
+
+// ProcessExits pays up to limit matured exits in priority order and returns
+// them. Processing stops at the first exit still in its challenge period
+// so that exits are never paid out of order. Pass limit <=0 for no limit.
+func (g *PlasmaExitGame) ProcessExits(limit int) ([]PlasmaExitClaim, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if _, err := g.checkOperator(); err != nil {
+		return nil, err
+	}
+	queue, err := g.ExitQueue()
+	if err != nil {
+		return nil, err
+	}
+	now := g.now().Unix()
+	var paid []PlasmaExitClaim
+	for _, ex := range queue {
+		if limit > 0 && len(paid) >= limit {
+			break
+		}
+		if ex.ExitableAt > now {
+			break
+		}
+		tok, ok := GetToken(ex.Output.Token)
+		if !ok {
+			return paid, errors.New("token unknown")
+		}
+		if err := tok.Transfer(plasmaBridgeAccount(ex.Output.Token), ex.Owner, ex.Output.Amount); err != nil {
+			return paid, err
+		}
+		if ex.Bond > 0 {
+			if err := g.led.Transfer(plasmaExitBondAccount(), ex.Owner, ex.Bond); err != nil {
+				return paid, err
+			}
+		}
+		ex.Status = PlasmaExitFinalized
+		if err := g.saveClaim(ex); err != nil {
+			return paid, err
+		}
+		paid = append(paid, ex)
+	}
+	if len(paid) > 0 {
+		_ = Broadcast("plasma:exits_processed", plasmaJSON(paid))
+	}
+	return paid, nil
+}
+
+// Status returns the height, operator liveness and queue size.
+func (g *PlasmaExitGame) Status() (PlasmaExitStatus, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	q, err := g.ExitQueue()
+	if err != nil {
+		return PlasmaExitStatus{}, err
+	}
+	from := g.massExitFrom()
+	return PlasmaExitStatus{
+		Height:       g.getUint(plasmaHeightKey),
+		LastCommit:   int64(g.getUint(plasmaLastKey)),
+		MassExit:     from != 0,
+		MassExitFrom: from,
+		Pending:      len(q),
+	}, nil
+}
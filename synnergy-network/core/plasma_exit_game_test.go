@@ -0,0 +1,158 @@
+package core
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+// plasmaTestState is the subset of StateRW used by the exit game.
+type plasmaTestState struct {
+	StateRW
+	kv map[string][]byte
+}
+
+func (s *plasmaTestState) GetState(k []byte) ([]byte, error) { return s.kv[string(k)], nil }
+func (s *plasmaTestState) SetState(k, v []byte) error        { s.kv[string(k)] = v; return nil }
+func (s *plasmaTestState) HasState(k []byte) (bool, error) {
+	_, ok := s.kv[string(k)]
+	return ok, nil
+}
+func (s *plasmaTestState) PrefixIterator(prefix []byte) StateIterator {
+	it := &plasmaTestIter{idx: -1}
+	for k, v := range s.kv {
+		if strings.HasPrefix(k, string(prefix)) {
+			it.keys = append(it.keys, k)
+			it.vals = append(it.vals, v)
+		}
+	}
+	return it
+}
+
+type plasmaTestIter struct {
+	keys []string
+	vals [][]byte
+	idx  int
+}
+
+func (it *plasmaTestIter) Next() bool    { it.idx++; return it.idx < len(it.keys) }
+func (it *plasmaTestIter) Key() []byte   { return []byte(it.keys[it.idx]) }
+func (it *plasmaTestIter) Value() []byte { return it.vals[it.idx] }
+func (it *plasmaTestIter) Error() error  { return nil }
+
+func plasmaTestBlock(t *testing.T, g *PlasmaExitGame, op Address, txs ...PlasmaTx) [][][]byte {
+	t.Helper()
+	leaves := make([][]byte, len(txs))
+	for i, tx := range txs {
+		leaves[i], _ = json.Marshal(tx)
+	}
+	proofs := make([][][]byte, len(txs))
+	var root [32]byte
+	for i := range txs {
+		p, r, err := MerkleProof(leaves, uint32(i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		proofs[i], root = p, r
+	}
+	if _, err := g.SubmitCommitment(op, root); err != nil {
+		t.Fatal(err)
+	}
+	return proofs
+}
+
+func TestPlasmaPositionRoundTrip(t *testing.T) {
+	pos := PlasmaOutputPosition(42, 17, 3)
+	blk, tx, out := DecodePlasmaPosition(pos)
+	if blk != 42 || tx != 17 || out != 3 {
+		t.Fatalf("decoded (%d,%d,%d), want (42,17,3)", blk, tx, out)
+	}
+	if PlasmaOutputPosition(1, 9999, 9999) >= PlasmaOutputPosition(2, 0, 0) {
+		t.Fatal("positions of an earlier block must sort first")
+	}
+}
+
+func TestPlasmaExitChallengeAndPriority(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	op, alice, bob := Address{1}, Address{2}, Address{3}
+	g := NewPlasmaExitGame(&plasmaTestState{kv: map[string][]byte{}}, op, PlasmaExitParams{ChallengePeriod: time.Hour})
+	g.now = func() time.Time { return now }
+
+	pay := PlasmaTx{Inputs: []uint64{PlasmaOutputPosition(9, 0, 0)}, Outputs: []PlasmaOutput{{Owner: alice, Amount: 5}, {Owner: bob, Amount: 7}}}
+	other := PlasmaTx{Outputs: []PlasmaOutput{{Owner: bob, Amount: 1}}}
+	proofs := plasmaTestBlock(t, g, op, other, pay)
+
+	if _, err := g.StartExit(alice, PlasmaOutputPosition(1, 1, 1), pay, proofs[1]); err != ErrUnauthorized {
+		t.Fatalf("exit of someone else's output: err = %v", err)
+	}
+	if _, err := g.StartExit(alice, PlasmaOutputPosition(1, 0, 0), pay, proofs[1]); err == nil {
+		t.Fatal("exit with proof for the wrong index must fail")
+	}
+	if _, err := g.StartExit(bob, PlasmaOutputPosition(1, 1, 1), pay, proofs[1]); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.StartExit(alice, PlasmaOutputPosition(1, 1, 0), pay, proofs[1]); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.StartExit(alice, PlasmaOutputPosition(1, 1, 0), pay, proofs[1]); err == nil {
+		t.Fatal("double exit must fail")
+	}
+
+	q, err := g.ExitQueue()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(q) != 2 || q[0].Position != PlasmaOutputPosition(1, 1, 0) {
+		t.Fatalf("queue not in priority order: %+v", q)
+	}
+
+	// alice's output is spent in block 2
+	spend := PlasmaTx{Inputs: []uint64{PlasmaOutputPosition(1, 1, 0)}, Outputs: []PlasmaOutput{{Owner: bob, Amount: 5}}}
+	sp := plasmaTestBlock(t, g, op, spend)
+	if err := g.ChallengeExit(bob, PlasmaOutputPosition(1, 1, 1), spend, PlasmaOutputPosition(2, 0, 0), sp[0]); err == nil {
+		t.Fatal("challenge with a tx not spending the output must fail")
+	}
+	if err := g.ChallengeExit(bob, PlasmaOutputPosition(1, 1, 0), spend, PlasmaOutputPosition(2, 0, 0), sp[0]); err != nil {
+		t.Fatal(err)
+	}
+	ex, _ := g.Exit(PlasmaOutputPosition(1, 1, 0))
+	if ex.Status != PlasmaExitChallenged || ex.Challenger != bob {
+		t.Fatalf("exit after challenge: %+v", ex)
+	}
+	if q, _ = g.ExitQueue(); len(q) != 1 {
+		t.Fatalf("challenged exit still queued: %+v", q)
+	}
+}
+
+func TestPlasmaMassExit(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	op, alice := Address{1}, Address{2}
+	g := NewPlasmaExitGame(&plasmaTestState{kv: map[string][]byte{}}, op, PlasmaExitParams{
+		ChallengePeriod: time.Hour,
+		MassExitPeriod:  10 * time.Hour,
+		OperatorTimeout: 2 * time.Hour,
+	})
+	g.now = func() time.Time { return now }
+	tx := PlasmaTx{Outputs: []PlasmaOutput{{Owner: alice, Amount: 5}}}
+	proofs := plasmaTestBlock(t, g, op, tx)
+
+	now = now.Add(time.Hour)
+	if on, _ := g.TriggerMassExit(); on {
+		t.Fatal("mass exit triggered while the operator is live")
+	}
+	now = now.Add(2 * time.Hour)
+	if on, _ := g.TriggerMassExit(); !on {
+		t.Fatal("mass exit not triggered after operator timeout")
+	}
+	if _, err := g.SubmitCommitment(op, [32]byte{1}); err == nil {
+		t.Fatal("commitments must be refused during mass exit")
+	}
+	ex, err := g.StartExit(alice, PlasmaOutputPosition(1, 0, 0), tx, proofs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := now.Add(10 * time.Hour).Unix(); ex.ExitableAt != want {
+		t.Fatalf("exitable at %d, want %d", ex.ExitableAt, want)
+	}
+}
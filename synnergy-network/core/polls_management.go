@@ -22,6 +22,16 @@ type Poll struct {
 
 const pollPrefix = "poll:" // key prefix in the KV store
 
+// pollGuard returns the content guard polls are moderated by. Stakes and
+// moderation state live on the current ledger.
+func pollGuard() (*ContentGuard, error) {
+	led := CurrentLedger()
+	if led == nil {
+		return nil, fmt.Errorf("ledger not initialised")
+	}
+	return NewContentGuard(led), nil
+}
+
 // CreatePoll registers a new poll. Duration defines how long voting is open.
 func CreatePoll(question string, options []string, creator Address, duration time.Duration) (Poll, error) {
 	if question == "" || len(options) < 2 {
@@ -40,6 +50,13 @@ func CreatePoll(question string, options []string, creator Address, duration tim
 		Creator:  creator,
 		Deadline: time.Now().Add(duration),
 	}
+	guard, err := pollGuard()
+	if err != nil {
+		return Poll{}, err
+	}
+	if err := guard.AdmitPost(ContentPoll, p.ID, creator); err != nil {
+		return Poll{}, err
+	}
 	raw, err := json.Marshal(p)
 	if err != nil {
 		return Poll{}, err
@@ -66,6 +83,9 @@ func VotePoll(id string, voter Address, option int) error {
 	if p.Closed || time.Now().After(p.Deadline) {
 		return fmt.Errorf("poll closed")
 	}
+	if guard, err := pollGuard(); err == nil && guard.IsHidden(ContentPoll, id) {
+		return ErrContentHidden
+	}
 	addr := voter.Hex()
 	if p.Voters[addr] {
 		return fmt.Errorf("already voted")
@@ -121,19 +141,27 @@ func GetPoll(id string) (Poll, error) {
 	if err := json.Unmarshal(raw, &p); err != nil {
 		return Poll{}, err
 	}
+	if guard, err := pollGuard(); err == nil && guard.IsHidden(ContentPoll, id) {
+		return Poll{}, ErrContentHidden
+	}
 	return p, nil
 }
 
-// ListPolls returns all polls in the store.
+// ListPolls returns all polls in the store that are not hidden by
+// moderation.
 func ListPolls() ([]Poll, error) {
 	if CurrentStore() == nil {
 		return nil, fmt.Errorf("kv store not initialised")
 	}
+	guard, _ := pollGuard()
 	it := CurrentStore().Iterator([]byte(pollPrefix), nil)
 	var out []Poll
 	for it.Next() {
 		var p Poll
 		if err := json.Unmarshal(it.Value(), &p); err == nil {
+			if guard != nil && guard.IsHidden(ContentPoll, p.ID) {
+				continue
+			}
 			out = append(out, p)
 		}
 	}
@@ -142,3 +170,26 @@ func ListPolls() ([]Poll, error) {
 	}
 	return out, it.Close()
 }
+
+// FlagPoll reports a poll. It returns true once the poll is hidden.
+func FlagPoll(id string, flagger Address, reason string) (bool, error) {
+	p, err := GetPoll(id)
+	if err != nil {
+		return false, err
+	}
+	guard, err := pollGuard()
+	if err != nil {
+		return false, err
+	}
+	return guard.Flag(ContentPoll, id, p.Creator, flagger, reason)
+}
+
+// ReleasePollStake refunds the creation stake of a poll after its lock
+// period.
+func ReleasePollStake(id string, owner Address) error {
+	guard, err := pollGuard()
+	if err != nil {
+		return err
+	}
+	return guard.ReleaseStake(ContentPoll, id, owner)
+}
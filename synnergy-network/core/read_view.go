@@ -0,0 +1,281 @@
+package core
+
+// read_view.go – snapshot-isolated reads for API serving.
+//
+// Explorer, API node and DEX queries read the ledger through a ReadView: an
+// immutable copy of the key/value state, balances, nonces, contracts and
+// chain as they stood after one block. Applying a block only records the
+// new head and wakes a refresher, which copies the ledger under its read
+// lock and swaps the published view. Readers load the view atomically, so a
+// query never waits for block import and import never waits for a query; a
+// view may trail the head while the refresher catches up.
+//
+// A view is a full copy of the ledger's maps, not a structural share:
+// values are shared with the ledger, which replaces rather than mutates
+// them, but every key costs a map entry, so a rebuild is O(state) and holds
+// the read lock for its duration. The refresher therefore rebuilds at most
+// once per readViewInterval; blocks applied in between coalesce into the
+// next rebuild. Ledger.Close stops the refresher, leaving the last view
+// readable.
+//
+// Views are built once something asks for one; nodes that serve no reads
+// pay nothing. ReadViewStats reports how far the published view trails the
+// head, in blocks and in time since it fell behind, and the health logger
+// exports both as gauges.
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// StateReader is the read side of StateRW, implemented by the ledger,
+// historical StateViews and ReadViews.
+type StateReader interface {
+	GetState(key []byte) ([]byte, error)
+	HasState(key []byte) (bool, error)
+	PrefixIterator(prefix []byte) StateIterator
+}
+
+var errReadView = errors.New("read view is read-only")
+
+// readViewInterval is the least time between two rebuilds of the view.
+var readViewInterval = 250 * time.Millisecond
+
+// readViews publishes the ledger's ReadViews. The zero value is inactive.
+type readViews struct {
+	once        sync.Once
+	mu          sync.Mutex // orders starting and stopping the refresher
+	stop        chan struct{}
+	stopped     bool
+	active      atomic.Bool
+	kick        chan struct{} // set before active
+	cur         atomic.Pointer[ReadView]
+	head        atomic.Uint64 // height of the last applied block
+	behindSince atomic.Int64  // unix nanos the view fell behind, 0 if current
+}
+
+// ReadView is a consistent, read-only snapshot of the ledger after a block.
+type ReadView struct {
+	height    uint64
+	takenAt   time.Time
+	state     map[string][]byte
+	balances  map[BalanceKey]uint64
+	nonces    map[Address]uint64
+	contracts map[string]Contract
+	blocks    []*Block
+	index     map[Hash]*Block
+}
+
+// ReadViewStats describes the published read view.
+type ReadViewStats struct {
+	Active    bool          `json:"active"`
+	Height    uint64        `json:"height"`
+	Head      uint64        `json:"head"`
+	LagBlocks uint64        `json:"lag_blocks"`
+	Age       time.Duration `json:"age"` // time the view has trailed the head
+}
+
+// ReadView returns the latest published snapshot of the ledger. The first
+// call builds one synchronously and starts the refresher.
+func (l *Ledger) ReadView() *ReadView {
+	v := &l.views
+	v.once.Do(func() {
+		v.kick = make(chan struct{}, 1)
+		v.active.Store(true)
+		v.cur.Store(l.buildReadView())
+		v.mu.Lock()
+		defer v.mu.Unlock()
+		if !v.stopped {
+			v.stop = make(chan struct{})
+			go l.refreshReadViews(v.stop, readViewInterval)
+		}
+	})
+	return v.cur.Load()
+}
+
+// stopReadViews stops the refresher. The published view stays readable
+// but is no longer refreshed.
+func (l *Ledger) stopReadViews() {
+	v := &l.views
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.stopped {
+		return
+	}
+	v.stopped = true
+	if v.stop != nil {
+		close(v.stop)
+	}
+}
+
+// ReadViewStats reports the lag of the published view behind the head.
+func (l *Ledger) ReadViewStats() ReadViewStats {
+	v := &l.views
+	st := ReadViewStats{Head: v.head.Load()}
+	cur := v.cur.Load()
+	if cur == nil {
+		return st
+	}
+	st.Active, st.Height = true, cur.height
+	if st.Head > cur.height {
+		st.LagBlocks = st.Head - cur.height
+	}
+	if since := v.behindSince.Load(); since != 0 {
+		st.Age = time.Since(time.Unix(0, since))
+	}
+	return st
+}
+
+// invalidateReadView records a new head and wakes the refresher. The caller
+// holds l.mu; it does no copying itself.
+func (l *Ledger) invalidateReadView(height uint64) {
+	v := &l.views
+	v.head.Store(height)
+	if !v.active.Load() {
+		return
+	}
+	v.behindSince.CompareAndSwap(0, time.Now().UnixNano())
+	select {
+	case v.kick <- struct{}{}:
+	default:
+	}
+}
+
+func (l *Ledger) refreshReadViews(stop <-chan struct{}, interval time.Duration) {
+	v := &l.views
+	for {
+		select {
+		case <-stop:
+			return
+		case <-v.kick:
+		}
+		rv := l.buildReadView()
+		v.cur.Store(rv)
+		if rv.height >= v.head.Load() {
+			v.behindSince.Store(0)
+		}
+		select {
+		case <-stop:
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+// buildReadView copies the ledger under its read lock, in time linear in
+// the size of the state.
+func (l *Ledger) buildReadView() *ReadView {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	rv := &ReadView{
+		takenAt:   time.Now(),
+		state:     make(map[string][]byte, len(l.State)),
+		balances:  make(map[BalanceKey]uint64, len(l.Balances)),
+		nonces:    make(map[Address]uint64, len(l.nonces)),
+		contracts: make(map[string]Contract, len(l.Contracts)),
+		blocks:    l.Blocks[:len(l.Blocks):len(l.Blocks)],
+		index:     make(map[Hash]*Block, len(l.blockIndex)),
+	}
+	if n := len(l.Blocks); n > 0 {
+		rv.height = l.Blocks[n-1].Header.Height
+	}
+	for k, val := range l.State {
+		rv.state[k] = val
+	}
+	for k, val := range l.Balances {
+		rv.balances[k] = val
+	}
+	for k, val := range l.nonces {
+		rv.nonces[k] = val
+	}
+	for k, c := range l.Contracts {
+		rv.contracts[k] = c
+	}
+	for h, b := range l.blockIndex {
+		rv.index[h] = b
+	}
+	return rv
+}
+
+// Height returns the height of the last block the view includes.
+func (v *ReadView) Height() uint64 { return v.height }
+
+// TakenAt returns when the view was copied.
+func (v *ReadView) TakenAt() time.Time { return v.takenAt }
+
+func (v *ReadView) GetState(key []byte) ([]byte, error) {
+	val, ok := v.state[string(key)]
+	if !ok {
+		return nil, fmt.Errorf("state key not found")
+	}
+	return append([]byte(nil), val...), nil
+}
+
+func (v *ReadView) HasState(key []byte) (bool, error) {
+	_, ok := v.state[string(key)]
+	return ok, nil
+}
+
+func (v *ReadView) SetState(key, value []byte) error { return errReadView }
+func (v *ReadView) DeleteState(key []byte) error     { return errReadView }
+
+func (v *ReadView) PrefixIterator(prefix []byte) StateIterator {
+	it := &memIter{idx: -1}
+	var keys []string
+	for k := range v.state {
+		if strings.HasPrefix(k, string(prefix)) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		it.keys = append(it.keys, []byte(k))
+		it.values = append(it.values, v.state[k])
+	}
+	return it
+}
+
+// BalanceOf returns the native coin balance, as Ledger.BalanceOf.
+func (v *ReadView) BalanceOf(addr Address) uint64 {
+	return v.balances[CoinKey(addr)]
+}
+
+// NonceOf returns the account nonce.
+func (v *ReadView) NonceOf(addr Address) uint64 { return v.nonces[addr] }
+
+// GetContract returns the contract deployed at address.
+func (v *ReadView) GetContract(address []byte) (*Contract, error) {
+	c, ok := v.contracts[fmt.Sprintf("%x", address)]
+	if !ok {
+		return nil, fmt.Errorf("contract %x not found", address)
+	}
+	return &c, nil
+}
+
+// Blocks returns the retained blocks in chain order. The slice must not be
+// modified.
+func (v *ReadView) Blocks() []*Block { return v.blocks }
+
+// GetBlock returns the retained block at height.
+func (v *ReadView) GetBlock(height uint64) (*Block, error) {
+	if len(v.blocks) > 0 {
+		if first := v.blocks[0].Header.Height; height >= first && height-first < uint64(len(v.blocks)) {
+			return v.blocks[height-first], nil
+		}
+	}
+	return nil, fmt.Errorf("block %d not found", height)
+}
+
+// BlockByHash returns a retained block by hash.
+func (v *ReadView) BlockByHash(h Hash) (*Block, error) {
+	b, ok := v.index[h]
+	if !ok {
+		return nil, fmt.Errorf("block %s not found", h.Hex())
+	}
+	return b, nil
+}
@@ -0,0 +1,113 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+// appendTestBlock applies an empty block at height h as far as read views
+// are concerned.
+func appendTestBlock(l *Ledger, h uint64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.Blocks = append(l.Blocks, &Block{Header: BlockHeader{Height: h}})
+	l.invalidateReadView(h)
+}
+
+func setReadViewInterval(t *testing.T, d time.Duration) {
+	t.Helper()
+	prev := readViewInterval
+	readViewInterval = d
+	t.Cleanup(func() { readViewInterval = prev })
+}
+
+func waitReadView(l *Ledger, height uint64, within time.Duration) bool {
+	deadline := time.Now().Add(within)
+	for time.Now().Before(deadline) {
+		if l.ReadView().Height() >= height {
+			return true
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return false
+}
+
+func TestReadViewIsACopyAndFollowsTheHead(t *testing.T) {
+	setReadViewInterval(t, 5*time.Millisecond)
+	l := newDepositLedger(t)
+	_ = l.SetState([]byte("k"), []byte("v1"))
+	appendTestBlock(l, 0)
+
+	rv := l.ReadView()
+	_ = l.SetState([]byte("k"), []byte("v2"))
+	_ = l.SetState([]byte("new"), []byte("x"))
+	if got, _ := rv.GetState([]byte("k")); string(got) != "v1" {
+		t.Fatalf("view saw a later write: %q", got)
+	}
+	if ok, _ := rv.HasState([]byte("new")); ok {
+		t.Fatal("view saw a key written after it was taken")
+	}
+	if err := rv.SetState([]byte("k"), nil); err == nil {
+		t.Fatal("write through a read view succeeded")
+	}
+
+	appendTestBlock(l, 1)
+	if !waitReadView(l, 1, time.Second) {
+		t.Fatalf("view stuck at %d", l.ReadView().Height())
+	}
+	if got, _ := l.ReadView().GetState([]byte("k")); string(got) != "v2" {
+		t.Fatalf("refreshed view: %q", got)
+	}
+	if st := l.ReadViewStats(); st.LagBlocks != 0 || st.Height != 1 {
+		t.Fatalf("stats after catching up: %+v", st)
+	}
+
+	if err := l.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	appendTestBlock(l, 2)
+	if waitReadView(l, 2, 50*time.Millisecond) {
+		t.Fatal("view refreshed after the ledger was closed")
+	}
+	if got := l.ReadView().Height(); got != 1 {
+		t.Fatalf("view after close at %d", got)
+	}
+}
+
+func TestReadViewRebuildsAtMostOncePerInterval(t *testing.T) {
+	setReadViewInterval(t, time.Hour)
+	l := newDepositLedger(t)
+	defer l.Close()
+	appendTestBlock(l, 0)
+	l.ReadView()
+
+	appendTestBlock(l, 1)
+	if !waitReadView(l, 1, time.Second) {
+		t.Fatal("first block after the view was not picked up")
+	}
+	// further blocks wait for the interval and coalesce into one rebuild
+	for h := uint64(2); h <= 5; h++ {
+		appendTestBlock(l, h)
+	}
+	time.Sleep(20 * time.Millisecond)
+	st := l.ReadViewStats()
+	if st.Height != 1 || st.Head != 5 || st.LagBlocks != 4 || st.Age <= 0 {
+		t.Fatalf("stats within the interval: %+v", st)
+	}
+}
+
+func TestCloseBeforeReadViewStartsNoRefresher(t *testing.T) {
+	setReadViewInterval(t, time.Millisecond)
+	l := newDepositLedger(t)
+	appendTestBlock(l, 0)
+	if err := l.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if l.ReadView().Height() != 0 {
+		t.Fatal("view of a closed ledger")
+	}
+	appendTestBlock(l, 1)
+	if waitReadView(l, 1, 20*time.Millisecond) {
+		t.Fatal("view of a closed ledger refreshed")
+	}
+}
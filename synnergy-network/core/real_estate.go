@@ -39,7 +39,8 @@ func RegisterProperty(p *Property) error {
 	return nil
 }
 
-// TransferProperty changes ownership of a registered property.
+// TransferProperty changes ownership of a registered property. Fractionalised
+// properties change hands through TransferShares instead.
 func TransferProperty(id string, from, to Address) error {
 	key := fmt.Sprintf("realestate:prop:%s", id)
 	raw, err := CurrentStore().Get([]byte(key))
@@ -53,6 +54,9 @@ func TransferProperty(id string, from, to Address) error {
 	if p.Owner != from {
 		return ErrUnauthorized
 	}
+	if isFractional(id) {
+		return ErrPropertyFractional
+	}
 	p.Owner = to
 	updated, _ := json.Marshal(&p)
 	if err := CurrentStore().Set([]byte(key), updated); err != nil {
@@ -0,0 +1,421 @@
+package core
+
+// real_estate_shares.go – fractional ownership of registered properties.
+//
+// A property owner may fractionalise a property into a fixed number of
+// fungible shares. Shares of different properties are kept apart, so the
+// property ID acts as the asset ID of a semi-fungible token. Once
+// fractionalised the property can no longer be transferred as a whole.
+//
+// Rent is deposited in SYNN into the real estate module account and paid out
+// pro-rata to the current shareholders once per RentPeriod. Remainders that
+// cannot be split evenly are carried over to the next distribution.
+// StartRentDistribution runs the payout on a ticker.
+//
+// Share transfers are subject to the per-property ShareRules configured by
+// the issuer. When KYCRequired is set both parties must hold a valid KYC
+// credential.
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// ErrPropertyFractional is returned when a whole-property operation is
+	// attempted on a fractionalised property.
+	ErrPropertyFractional = errors.New("property is fractionalised")
+	// ErrShareRestricted is returned when a share transfer violates the
+	// property's transfer rules.
+	ErrShareRestricted = errors.New("share transfer restricted")
+)
+
+var realEstateMu sync.Mutex
+
+// ShareRules configures the transfer restrictions of a property's shares.
+type ShareRules struct {
+	// KYCRequired restricts transfers to KYC verified holders.
+	KYCRequired bool `json:"kyc_required"`
+	// MaxHolders caps the number of distinct shareholders. Zero is unlimited.
+	MaxHolders int `json:"max_holders"`
+}
+
+// PropertyShares describes a fractionalised property.
+type PropertyShares struct {
+	PropertyID       string        `json:"property_id"`
+	Issuer           Address       `json:"issuer"`
+	TotalShares      uint64        `json:"total_shares"`
+	Rules            ShareRules    `json:"rules"`
+	RentPeriod       time.Duration `json:"rent_period"`
+	PendingRent      uint64        `json:"pending_rent"`
+	LastDistribution time.Time     `json:"last_distribution"`
+	CreatedAt        time.Time     `json:"created_at"`
+}
+
+// ShareHolding is a shareholder's balance in a property.
+type ShareHolding struct {
+	Holder Address `json:"holder"`
+	Shares uint64  `json:"shares"`
+}
+
+// RentDistribution records one pro-rata rent payout.
+type RentDistribution struct {
+	PropertyID string            `json:"property_id"`
+	Amount     uint64            `json:"amount"`
+	CarryOver  uint64            `json:"carry_over"`
+	Payouts    map[string]uint64 `json:"payouts"`
+	Time       time.Time         `json:"time"`
+}
+
+func realEstateAccount() Address { return ModuleAddress("realestate") }
+
+func sharesKey(id string) []byte { return []byte(fmt.Sprintf("realestate:shares:%s", id)) }
+
+func shareBalancePrefix(id string) []byte {
+	return []byte(fmt.Sprintf("realestate:share:%s:", id))
+}
+
+func shareBalanceKey(id string, addr Address) []byte {
+	return append(shareBalancePrefix(id), addr.Hex()...)
+}
+
+func rentDistPrefix(id string) []byte { return []byte(fmt.Sprintf("realestate:dist:%s:", id)) }
+
+func loadPropertyShares(id string) (*PropertyShares, error) {
+	raw, err := CurrentStore().Get(sharesKey(id))
+	if err != nil || raw == nil {
+		return nil, ErrNotFound
+	}
+	var ps PropertyShares
+	if err := json.Unmarshal(raw, &ps); err != nil {
+		return nil, err
+	}
+	return &ps, nil
+}
+
+func savePropertyShares(ps *PropertyShares) error {
+	raw, err := json.Marshal(ps)
+	if err != nil {
+		return err
+	}
+	return CurrentStore().Set(sharesKey(ps.PropertyID), raw)
+}
+
+func isFractional(id string) bool {
+	raw, err := CurrentStore().Get(sharesKey(id))
+	return err == nil && raw != nil
+}
+
+func shareBalance(id string, addr Address) uint64 {
+	raw, err := CurrentStore().Get(shareBalanceKey(id, addr))
+	if err != nil || raw == nil {
+		return 0
+	}
+	var n uint64
+	if err := json.Unmarshal(raw, &n); err != nil {
+		return 0
+	}
+	return n
+}
+
+func setShareBalance(id string, addr Address, n uint64) error {
+	if n == 0 {
+		return CurrentStore().Delete(shareBalanceKey(id, addr))
+	}
+	raw, _ := json.Marshal(n)
+	return CurrentStore().Set(shareBalanceKey(id, addr), raw)
+}
+
+// FractionaliseProperty splits property id into total shares credited to its
+// owner. Rent deposited for the property is distributed every period.
+func FractionaliseProperty(id string, owner Address, total uint64, period time.Duration, rules ShareRules) (*PropertyShares, error) {
+	if total == 0 {
+		return nil, errors.New("total shares must be >0")
+	}
+	if period <= 0 {
+		return nil, errors.New("rent period must be >0")
+	}
+	if rules.MaxHolders < 0 {
+		return nil, errors.New("max holders must be >= 0")
+	}
+	realEstateMu.Lock()
+	defer realEstateMu.Unlock()
+	p, err := GetProperty(id)
+	if err != nil {
+		return nil, err
+	}
+	if p.Owner != owner {
+		return nil, ErrUnauthorized
+	}
+	if isFractional(id) {
+		return nil, ErrPropertyFractional
+	}
+	if rules.KYCRequired && !IsKYCVerified(owner) {
+		return nil, fmt.Errorf("%w: issuer not KYC verified", ErrShareRestricted)
+	}
+	now := time.Now().UTC()
+	ps := &PropertyShares{
+		PropertyID:       id,
+		Issuer:           owner,
+		TotalShares:      total,
+		Rules:            rules,
+		RentPeriod:       period,
+		LastDistribution: now,
+		CreatedAt:        now,
+	}
+	if err := setShareBalance(id, owner, total); err != nil {
+		return nil, err
+	}
+	if err := savePropertyShares(ps); err != nil {
+		return nil, err
+	}
+	logrus.WithField("prop", id).Info("property fractionalised")
+	return ps, nil
+}
+
+// SetShareRules replaces the transfer rules of a property. Only the issuer
+// may change them.
+func SetShareRules(id string, caller Address, rules ShareRules) error {
+	if rules.MaxHolders < 0 {
+		return errors.New("max holders must be >= 0")
+	}
+	realEstateMu.Lock()
+	defer realEstateMu.Unlock()
+	ps, err := loadPropertyShares(id)
+	if err != nil {
+		return err
+	}
+	if ps.Issuer != caller {
+		return ErrUnauthorized
+	}
+	ps.Rules = rules
+	return savePropertyShares(ps)
+}
+
+// GetPropertyShares returns the fractionalisation record of a property.
+func GetPropertyShares(id string) (*PropertyShares, error) {
+	return loadPropertyShares(id)
+}
+
+// ShareBalance returns the shares of property id held by addr.
+func ShareBalance(id string, addr Address) uint64 {
+	return shareBalance(id, addr)
+}
+
+// ShareHolders lists the shareholders of a property ordered by address.
+func ShareHolders(id string) ([]ShareHolding, error) {
+	it := CurrentStore().Iterator(shareBalancePrefix(id), nil)
+	var res []ShareHolding
+	for it.Next() {
+		var n uint64
+		if err := json.Unmarshal(it.Value(), &n); err != nil {
+			return nil, err
+		}
+		addr, err := StringToAddress(string(it.Key()[len(shareBalancePrefix(id)):]))
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, ShareHolding{Holder: addr, Shares: n})
+	}
+	if err := it.Error(); err != nil {
+		return nil, err
+	}
+	if err := it.Close(); err != nil {
+		return nil, err
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Holder.Hex() < res[j].Holder.Hex() })
+	return res, nil
+}
+
+// TransferShares moves amount shares of property id between holders subject
+// to the property's transfer rules.
+func TransferShares(id string, from, to Address, amount uint64) error {
+	if amount == 0 {
+		return errors.New("amount must be >0")
+	}
+	if from == to {
+		return errors.New("cannot transfer to self")
+	}
+	realEstateMu.Lock()
+	defer realEstateMu.Unlock()
+	ps, err := loadPropertyShares(id)
+	if err != nil {
+		return err
+	}
+	if ps.Rules.KYCRequired {
+		if !IsKYCVerified(from) {
+			return fmt.Errorf("%w: sender not KYC verified", ErrShareRestricted)
+		}
+		if !IsKYCVerified(to) {
+			return fmt.Errorf("%w: recipient not KYC verified", ErrShareRestricted)
+		}
+	}
+	fromBal := shareBalance(id, from)
+	if fromBal < amount {
+		return errors.New("insufficient shares")
+	}
+	toBal := shareBalance(id, to)
+	if ps.Rules.MaxHolders > 0 && toBal == 0 && fromBal > amount {
+		holders, err := ShareHolders(id)
+		if err != nil {
+			return err
+		}
+		if len(holders) >= ps.Rules.MaxHolders {
+			return fmt.Errorf("%w: holder limit %d reached", ErrShareRestricted, ps.Rules.MaxHolders)
+		}
+	}
+	if err := setShareBalance(id, from, fromBal-amount); err != nil {
+		return err
+	}
+	if err := setShareBalance(id, to, toBal+amount); err != nil {
+		return err
+	}
+	logrus.WithFields(logrus.Fields{"prop": id, "amount": amount}).Info("property shares transferred")
+	return nil
+}
+
+// DepositRent moves amount SYNN from payer into the module account where it
+// is held until the next distribution of property id.
+func DepositRent(ctx *Context, id string, payer Address, amount uint64) error {
+	if amount == 0 {
+		return errors.New("amount must be >0")
+	}
+	realEstateMu.Lock()
+	defer realEstateMu.Unlock()
+	ps, err := loadPropertyShares(id)
+	if err != nil {
+		return err
+	}
+	if err := Transfer(ctx, AssetRef{Kind: AssetCoin}, payer, realEstateAccount(), amount); err != nil {
+		return err
+	}
+	ps.PendingRent += amount
+	return savePropertyShares(ps)
+}
+
+// DistributeRent pays the pending rent of property id to its shareholders
+// pro-rata, regardless of the rent period.
+func DistributeRent(ctx *Context, id string) (*RentDistribution, error) {
+	realEstateMu.Lock()
+	defer realEstateMu.Unlock()
+	ps, err := loadPropertyShares(id)
+	if err != nil {
+		return nil, err
+	}
+	return distributeRent(ctx, ps, time.Now().UTC())
+}
+
+func distributeRent(ctx *Context, ps *PropertyShares, now time.Time) (*RentDistribution, error) {
+	holders, err := ShareHolders(ps.PropertyID)
+	if err != nil {
+		return nil, err
+	}
+	dist := &RentDistribution{PropertyID: ps.PropertyID, Payouts: make(map[string]uint64), Time: now}
+	pool := ps.PendingRent
+	for _, h := range holders {
+		// pool*shares/total without overflowing on large pools
+		amt := pool/ps.TotalShares*h.Shares + pool%ps.TotalShares*h.Shares/ps.TotalShares
+		if amt == 0 {
+			continue
+		}
+		if err := Transfer(ctx, AssetRef{Kind: AssetCoin}, realEstateAccount(), h.Holder, amt); err != nil {
+			return nil, err
+		}
+		dist.Payouts[h.Holder.Hex()] = amt
+		dist.Amount += amt
+	}
+	dist.CarryOver = pool - dist.Amount
+	ps.PendingRent = dist.CarryOver
+	ps.LastDistribution = now
+	if err := savePropertyShares(ps); err != nil {
+		return nil, err
+	}
+	raw, _ := json.Marshal(dist)
+	key := append(rentDistPrefix(ps.PropertyID), fmt.Sprintf("%020d", now.UnixNano())...)
+	if err := CurrentStore().Set(key, raw); err != nil {
+		return nil, err
+	}
+	if dist.Amount > 0 {
+		logrus.WithFields(logrus.Fields{"prop": ps.PropertyID, "amount": dist.Amount}).Info("rent distributed")
+	}
+	return dist, nil
+}
+
+// DistributeDueRent distributes rent for every property whose rent period
+// has elapsed at now and returns the property IDs that were paid out.
+func DistributeDueRent(ctx *Context, now time.Time) ([]string, error) {
+	realEstateMu.Lock()
+	defer realEstateMu.Unlock()
+	it := CurrentStore().Iterator([]byte("realestate:shares:"), nil)
+	var due []*PropertyShares
+	for it.Next() {
+		var ps PropertyShares
+		if err := json.Unmarshal(it.Value(), &ps); err != nil {
+			continue
+		}
+		if !now.Before(ps.LastDistribution.Add(ps.RentPeriod)) {
+			due = append(due, &ps)
+		}
+	}
+	if err := it.Error(); err != nil {
+		return nil, err
+	}
+	if err := it.Close(); err != nil {
+		return nil, err
+	}
+	var out []string
+	for _, ps := range due {
+		if _, err := distributeRent(ctx, ps, now); err != nil {
+			return out, err
+		}
+		out = append(out, ps.PropertyID)
+	}
+	return out, nil
+}
+
+// StartRentDistribution runs DistributeDueRent every interval until ctx is
+// cancelled.
+func StartRentDistribution(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	go func() {
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-t.C:
+				_, _ = DistributeDueRent(&Context{}, now.UTC())
+			}
+		}
+	}()
+}
+
+// RentDistributions returns the payout history of a property, oldest first.
+func RentDistributions(id string) ([]RentDistribution, error) {
+	it := CurrentStore().Iterator(rentDistPrefix(id), nil)
+	var res []RentDistribution
+	for it.Next() {
+		var d RentDistribution
+		if err := json.Unmarshal(it.Value(), &d); err != nil {
+			return nil, err
+		}
+		res = append(res, d)
+	}
+	if err := it.Error(); err != nil {
+		return nil, err
+	}
+	if err := it.Close(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
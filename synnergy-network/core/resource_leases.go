@@ -0,0 +1,601 @@
+package core
+
+// resource_leases.go - metered compute leases for the resource marketplace.
+//
+// Providers advertise CPU, RAM and storage capacity with a price per unit
+// per block. A client leases part of that capacity by locking a deposit in
+// the market escrow account. Every block the reserved price is streamed
+// from the escrow to the provider; usage above the reservation is billed
+// from co-signed usage reports. When the escrow can no longer cover a block
+// the lease terminates automatically and the capacity is released. Either
+// party may dispute a lease, which pauses payments until an authority node
+// arbitrates and optionally refunds the client.
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// ResourceSpec is an amount of compute resources.
+type ResourceSpec struct {
+	CPU       uint64 `json:"cpu"`        // cores
+	RAMMB     uint64 `json:"ram_mb"`     // megabytes
+	StorageGB uint64 `json:"storage_gb"` // gigabytes
+}
+
+func (s ResourceSpec) fits(in ResourceSpec) bool {
+	return s.CPU <= in.CPU && s.RAMMB <= in.RAMMB && s.StorageGB <= in.StorageGB
+}
+
+func (s ResourceSpec) add(o ResourceSpec) ResourceSpec {
+	return ResourceSpec{s.CPU + o.CPU, s.RAMMB + o.RAMMB, s.StorageGB + o.StorageGB}
+}
+
+func (s ResourceSpec) sub(o ResourceSpec) ResourceSpec {
+	clamp := func(a, b uint64) uint64 {
+		if b > a {
+			return 0
+		}
+		return a - b
+	}
+	return ResourceSpec{clamp(s.CPU, o.CPU), clamp(s.RAMMB, o.RAMMB), clamp(s.StorageGB, o.StorageGB)}
+}
+
+// cost prices s at rates (per unit per block).
+func (s ResourceSpec) cost(rates ResourceSpec) uint64 {
+	return s.CPU*rates.CPU + s.RAMMB*rates.RAMMB + s.StorageGB*rates.StorageGB
+}
+
+// ResourceOffer is a provider's advertised capacity and per-block rates.
+type ResourceOffer struct {
+	ID        string       `json:"id"`
+	Provider  Address      `json:"provider"`
+	Capacity  ResourceSpec `json:"capacity"`
+	Allocated ResourceSpec `json:"allocated"`
+	Rates     ResourceSpec `json:"rates"` // price per unit per block
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+// Available returns the capacity not yet leased.
+func (o *ResourceOffer) Available() ResourceSpec { return o.Capacity.sub(o.Allocated) }
+
+// Lease states.
+const (
+	LeaseActive     = "active"
+	LeaseDisputed   = "disputed"
+	LeaseExhausted  = "exhausted"
+	LeaseTerminated = "terminated"
+)
+
+// ResourceLease is a metered rental of part of an offer.
+type ResourceLease struct {
+	ID            string        `json:"id"`
+	OfferID       string        `json:"offer_id"`
+	Provider      Address       `json:"provider"`
+	Client        Address       `json:"client"`
+	Spec          ResourceSpec  `json:"spec"`
+	Rates         ResourceSpec  `json:"rates"`
+	PricePerBlock uint64        `json:"price_per_block"`
+	Escrow        uint64        `json:"escrow"`
+	Paid          uint64        `json:"paid"`
+	StartHeight   uint64        `json:"start_height"`
+	PaidThrough   uint64        `json:"paid_through"`
+	ReportedTo    uint64        `json:"reported_to"`
+	Status        string        `json:"status"`
+	Dispute       *LeaseDispute `json:"dispute,omitempty"`
+	EndedAt       *time.Time    `json:"ended_at,omitempty"`
+}
+
+// UsageReport is measured usage over blocks (From, To]. It must be signed
+// by both the provider and the client.
+type UsageReport struct {
+	LeaseID     string            `json:"lease_id"`
+	From        uint64            `json:"from"`
+	To          uint64            `json:"to"`
+	Used        ResourceSpec      `json:"used"` // peak usage over the range
+	ProviderPub ed25519.PublicKey `json:"provider_pub"`
+	ProviderSig []byte            `json:"provider_sig"`
+	ClientPub   ed25519.PublicKey `json:"client_pub"`
+	ClientSig   []byte            `json:"client_sig"`
+	Overage     uint64            `json:"overage"`
+}
+
+// LeaseDispute records an open or resolved dispute.
+type LeaseDispute struct {
+	By         Address   `json:"by"`
+	Reason     string    `json:"reason"`
+	OpenedAt   time.Time `json:"opened_at"`
+	Arbitrator Address   `json:"arbitrator,omitempty"`
+	Refund     uint64    `json:"refund,omitempty"`
+	Resolution string    `json:"resolution,omitempty"`
+}
+
+// ErrUsageSignature is returned for usage reports not signed by both parties.
+var ErrUsageSignature = errors.New("usage report must be signed by provider and client")
+
+var leaseMu sync.Mutex
+
+func resourceMarketAccount() Address { return ModuleAddress("resource_market") }
+
+func resourceOfferKey(id string) []byte { return []byte("resource:offer:" + id) }
+func resourceLeaseKey(id string) []byte { return []byte("resource:lease:" + id) }
+func resourceUsageKey(id string, to uint64) []byte {
+	return []byte(fmt.Sprintf("resource:usage:%s:%020d", id, to))
+}
+
+func putResourceJSON(key []byte, v interface{}) error {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return CurrentStore().Set(key, raw)
+}
+
+// AdvertiseResources publishes a provider's capacity and per-block rates.
+func AdvertiseResources(provider Address, capacity, rates ResourceSpec) (*ResourceOffer, error) {
+	if capacity == (ResourceSpec{}) {
+		return nil, errors.New("capacity required")
+	}
+	o := &ResourceOffer{ID: uuid.New().String(), Provider: provider, Capacity: capacity, Rates: rates, CreatedAt: time.Now().UTC()}
+	if err := putResourceJSON(resourceOfferKey(o.ID), o); err != nil {
+		return nil, err
+	}
+	zap.L().Sugar().Infof("resource offer advertised: %s", o.ID)
+	return o, nil
+}
+
+// UpdateResourceCapacity changes the advertised capacity. It cannot drop
+// below what is already leased.
+func UpdateResourceCapacity(provider Address, offerID string, capacity ResourceSpec) error {
+	leaseMu.Lock()
+	defer leaseMu.Unlock()
+	o, err := GetResourceOffer(offerID)
+	if err != nil {
+		return err
+	}
+	if o.Provider != provider {
+		return ErrUnauthorized
+	}
+	if !o.Allocated.fits(capacity) {
+		return errors.New("capacity below leased resources")
+	}
+	o.Capacity = capacity
+	return putResourceJSON(resourceOfferKey(o.ID), o)
+}
+
+// GetResourceOffer fetches an offer by ID.
+func GetResourceOffer(id string) (*ResourceOffer, error) {
+	raw, err := CurrentStore().Get(resourceOfferKey(id))
+	if err != nil || raw == nil {
+		return nil, ErrNotFound
+	}
+	var o ResourceOffer
+	if err := json.Unmarshal(raw, &o); err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+// ListResourceOffers returns all offers, optionally for one provider.
+func ListResourceOffers(provider *Address) ([]ResourceOffer, error) {
+	iter := CurrentStore().Iterator([]byte("resource:offer:"), nil)
+	defer iter.Close()
+	var out []ResourceOffer
+	for iter.Next() {
+		var o ResourceOffer
+		if err := json.Unmarshal(iter.Value(), &o); err != nil {
+			continue
+		}
+		if provider != nil && o.Provider != *provider {
+			continue
+		}
+		out = append(out, o)
+	}
+	return out, iter.Error()
+}
+
+// OpenResourceLease reserves spec from an offer and locks deposit in escrow.
+// The deposit must cover at least one block.
+func OpenResourceLease(client Address, offerID string, spec ResourceSpec, deposit uint64) (*ResourceLease, error) {
+	if spec == (ResourceSpec{}) {
+		return nil, errors.New("lease must reserve resources")
+	}
+	leaseMu.Lock()
+	defer leaseMu.Unlock()
+	o, err := GetResourceOffer(offerID)
+	if err != nil {
+		return nil, err
+	}
+	if !spec.fits(o.Available()) {
+		return nil, errors.New("insufficient provider capacity")
+	}
+	price := spec.cost(o.Rates)
+	if deposit < price || deposit == 0 {
+		return nil, fmt.Errorf("deposit must cover at least one block (%d)", price)
+	}
+	if err := Transfer(&Context{}, AssetRef{Kind: AssetCoin}, client, resourceMarketAccount(), deposit); err != nil {
+		return nil, err
+	}
+	h := currentHeight()
+	l := &ResourceLease{
+		ID:            uuid.New().String(),
+		OfferID:       o.ID,
+		Provider:      o.Provider,
+		Client:        client,
+		Spec:          spec,
+		Rates:         o.Rates,
+		PricePerBlock: price,
+		Escrow:        deposit,
+		StartHeight:   h,
+		PaidThrough:   h,
+		ReportedTo:    h,
+		Status:        LeaseActive,
+	}
+	o.Allocated = o.Allocated.add(spec)
+	if err := putResourceJSON(resourceOfferKey(o.ID), o); err != nil {
+		return nil, err
+	}
+	if err := putResourceJSON(resourceLeaseKey(l.ID), l); err != nil {
+		return nil, err
+	}
+	zap.L().Sugar().Infof("resource lease opened: %s", l.ID)
+	return l, nil
+}
+
+// GetResourceLease fetches a lease by ID.
+func GetResourceLease(id string) (*ResourceLease, error) {
+	raw, err := CurrentStore().Get(resourceLeaseKey(id))
+	if err != nil || raw == nil {
+		return nil, ErrNotFound
+	}
+	var l ResourceLease
+	if err := json.Unmarshal(raw, &l); err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+// ListResourceLeases returns leases filtered by provider and/or client.
+func ListResourceLeases(provider, client *Address) ([]ResourceLease, error) {
+	iter := CurrentStore().Iterator([]byte("resource:lease:"), nil)
+	defer iter.Close()
+	var out []ResourceLease
+	for iter.Next() {
+		var l ResourceLease
+		if err := json.Unmarshal(iter.Value(), &l); err != nil {
+			continue
+		}
+		if provider != nil && l.Provider != *provider {
+			continue
+		}
+		if client != nil && l.Client != *client {
+			continue
+		}
+		out = append(out, l)
+	}
+	return out, iter.Error()
+}
+
+// TopUpResourceLease adds funds to an active lease's escrow.
+func TopUpResourceLease(client Address, id string, amount uint64) error {
+	leaseMu.Lock()
+	defer leaseMu.Unlock()
+	l, err := GetResourceLease(id)
+	if err != nil {
+		return err
+	}
+	if l.Client != client {
+		return ErrUnauthorized
+	}
+	if l.Status != LeaseActive && l.Status != LeaseDisputed {
+		return fmt.Errorf("lease is %s", l.Status)
+	}
+	if err := Transfer(&Context{}, AssetRef{Kind: AssetCoin}, client, resourceMarketAccount(), amount); err != nil {
+		return err
+	}
+	l.Escrow += amount
+	return putResourceJSON(resourceLeaseKey(l.ID), l)
+}
+
+// payProvider moves amount from the lease escrow to the provider.
+func payProvider(l *ResourceLease, amount uint64) error {
+	if amount == 0 {
+		return nil
+	}
+	if err := Transfer(&Context{}, AssetRef{Kind: AssetCoin}, resourceMarketAccount(), l.Provider, amount); err != nil {
+		return err
+	}
+	l.Escrow -= amount
+	l.Paid += amount
+	return nil
+}
+
+// endLease releases the reserved capacity and refunds the remaining escrow.
+func endLease(l *ResourceLease, status string) error {
+	if l.Escrow > 0 {
+		if err := Transfer(&Context{}, AssetRef{Kind: AssetCoin}, resourceMarketAccount(), l.Client, l.Escrow); err != nil {
+			return err
+		}
+		l.Escrow = 0
+	}
+	if o, err := GetResourceOffer(l.OfferID); err == nil {
+		o.Allocated = o.Allocated.sub(l.Spec)
+		if err := putResourceJSON(resourceOfferKey(o.ID), o); err != nil {
+			return err
+		}
+	}
+	now := time.Now().UTC()
+	l.Status = status
+	l.EndedAt = &now
+	return nil
+}
+
+// settleLease pays the provider for blocks up to height. Disputed leases are
+// not paid. Callers hold leaseMu.
+func settleLease(l *ResourceLease, height uint64) error {
+	if l.Status != LeaseActive || height <= l.PaidThrough {
+		return nil
+	}
+	blocks := height - l.PaidThrough
+	affordable := blocks
+	if l.PricePerBlock > 0 && l.Escrow/l.PricePerBlock < blocks {
+		affordable = l.Escrow / l.PricePerBlock
+	}
+	if err := payProvider(l, affordable*l.PricePerBlock); err != nil {
+		return err
+	}
+	l.PaidThrough += affordable
+	if affordable < blocks || (l.PricePerBlock > 0 && l.Escrow < l.PricePerBlock) {
+		zap.L().Sugar().Infof("resource lease %s exhausted at height %d", l.ID, l.PaidThrough)
+		return endLease(l, LeaseExhausted)
+	}
+	return nil
+}
+
+// SettleResourceLeases pays every active lease up to height and terminates
+// leases whose escrow is exhausted. It returns the number of leases touched.
+func SettleResourceLeases(height uint64) (int, error) {
+	leases, err := ListResourceLeases(nil, nil)
+	if err != nil {
+		return 0, err
+	}
+	leaseMu.Lock()
+	defer leaseMu.Unlock()
+	n := 0
+	for i := range leases {
+		l := &leases[i]
+		if l.Status != LeaseActive || height <= l.PaidThrough {
+			continue
+		}
+		if err := settleLease(l, height); err != nil {
+			return n, err
+		}
+		if err := putResourceJSON(resourceLeaseKey(l.ID), l); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+// StartResourceLeaseSettlement settles leases at the current ledger height
+// every interval until ctx is cancelled.
+func StartResourceLeaseSettlement(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	go func() {
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+				if h := currentHeight(); h > 0 {
+					if _, err := SettleResourceLeases(h); err != nil {
+						zap.L().Sugar().Warnf("resource lease settlement: %v", err)
+					}
+				}
+			}
+		}
+	}()
+}
+
+// UsageReportMessage is the message both parties sign for a usage report.
+func UsageReportMessage(leaseID string, from, to uint64, used ResourceSpec) []byte {
+	return []byte("synnergy-resource-usage:" + leaseID + ":" + strconv.FormatUint(from, 10) + ":" +
+		strconv.FormatUint(to, 10) + ":" + strconv.FormatUint(used.CPU, 10) + ":" +
+		strconv.FormatUint(used.RAMMB, 10) + ":" + strconv.FormatUint(used.StorageGB, 10))
+}
+
+// SignUsageReport adds the signature of priv to r.
+func SignUsageReport(r *UsageReport, priv ed25519.PrivateKey, asProvider bool) error {
+	pub, ok := priv.Public().(ed25519.PublicKey)
+	if !ok {
+		return errors.New("invalid private key")
+	}
+	sig := ed25519.Sign(priv, UsageReportMessage(r.LeaseID, r.From, r.To, r.Used))
+	if asProvider {
+		r.ProviderPub, r.ProviderSig = pub, sig
+	} else {
+		r.ClientPub, r.ClientSig = pub, sig
+	}
+	return nil
+}
+
+// SubmitUsageReport records a co-signed usage report. Reports must be
+// contiguous and may not extend past the settled height. Usage above the
+// lease reservation is billed at the offer rates for every block of the
+// range; if the escrow cannot cover the overage the lease is exhausted.
+func SubmitUsageReport(r UsageReport) (*ResourceLease, error) {
+	leaseMu.Lock()
+	defer leaseMu.Unlock()
+	l, err := GetResourceLease(r.LeaseID)
+	if err != nil {
+		return nil, err
+	}
+	msg := UsageReportMessage(r.LeaseID, r.From, r.To, r.Used)
+	if len(r.ProviderPub) != ed25519.PublicKeySize || Ed25519Address(r.ProviderPub) != l.Provider ||
+		!ed25519.Verify(r.ProviderPub, msg, r.ProviderSig) ||
+		len(r.ClientPub) != ed25519.PublicKeySize || Ed25519Address(r.ClientPub) != l.Client ||
+		!ed25519.Verify(r.ClientPub, msg, r.ClientSig) {
+		return nil, ErrUsageSignature
+	}
+	if l.Status != LeaseActive {
+		return nil, fmt.Errorf("lease is %s", l.Status)
+	}
+	if r.From != l.ReportedTo || r.To <= r.From {
+		return nil, fmt.Errorf("report must start at block %d", l.ReportedTo)
+	}
+	if r.To > l.PaidThrough {
+		return nil, fmt.Errorf("report extends past settled block %d", l.PaidThrough)
+	}
+	over := r.Used.sub(l.Spec).cost(l.Rates) * (r.To - r.From)
+	charge := over
+	if charge > l.Escrow {
+		charge = l.Escrow
+	}
+	if err := payProvider(l, charge); err != nil {
+		return nil, err
+	}
+	r.Overage = charge
+	l.ReportedTo = r.To
+	if charge < over || l.Escrow < l.PricePerBlock {
+		if err := endLease(l, LeaseExhausted); err != nil {
+			return nil, err
+		}
+	}
+	if err := putResourceJSON(resourceUsageKey(l.ID, r.To), r); err != nil {
+		return nil, err
+	}
+	if err := putResourceJSON(resourceLeaseKey(l.ID), l); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// LeaseUsageReports returns the usage reports of a lease in block order.
+func LeaseUsageReports(id string) ([]UsageReport, error) {
+	iter := CurrentStore().Iterator([]byte("resource:usage:"+id+":"), nil)
+	defer iter.Close()
+	var out []UsageReport
+	for iter.Next() {
+		var r UsageReport
+		if err := json.Unmarshal(iter.Value(), &r); err != nil {
+			continue
+		}
+		out = append(out, r)
+	}
+	return out, iter.Error()
+}
+
+// TerminateResourceLease ends a lease at the client's or provider's request
+// after settling up to the current height. The remaining escrow is refunded.
+func TerminateResourceLease(caller Address, id string) (*ResourceLease, error) {
+	leaseMu.Lock()
+	defer leaseMu.Unlock()
+	l, err := GetResourceLease(id)
+	if err != nil {
+		return nil, err
+	}
+	if caller != l.Client && caller != l.Provider {
+		return nil, ErrUnauthorized
+	}
+	if l.Status != LeaseActive {
+		return nil, fmt.Errorf("lease is %s", l.Status)
+	}
+	if err := settleLease(l, currentHeight()); err != nil {
+		return nil, err
+	}
+	if l.Status == LeaseActive {
+		if err := endLease(l, LeaseTerminated); err != nil {
+			return nil, err
+		}
+	}
+	return l, putResourceJSON(resourceLeaseKey(l.ID), l)
+}
+
+// DisputeResourceLease pauses payments on a lease until it is arbitrated.
+// Blocks up to the current height are settled first.
+func DisputeResourceLease(caller Address, id, reason string) (*ResourceLease, error) {
+	leaseMu.Lock()
+	defer leaseMu.Unlock()
+	l, err := GetResourceLease(id)
+	if err != nil {
+		return nil, err
+	}
+	if caller != l.Client && caller != l.Provider {
+		return nil, ErrUnauthorized
+	}
+	if l.Status != LeaseActive {
+		return nil, fmt.Errorf("lease is %s", l.Status)
+	}
+	if err := settleLease(l, currentHeight()); err != nil {
+		return nil, err
+	}
+	if l.Status != LeaseActive {
+		return l, putResourceJSON(resourceLeaseKey(l.ID), l)
+	}
+	l.Status = LeaseDisputed
+	l.Dispute = &LeaseDispute{By: caller, Reason: reason, OpenedAt: time.Now().UTC()}
+	return l, putResourceJSON(resourceLeaseKey(l.ID), l)
+}
+
+// ArbitrateResourceLease resolves a dispute. Only authority nodes may
+// arbitrate. refund is returned to the client from the escrow; if
+// terminate is set the lease ends, otherwise it resumes from the current
+// height without charging for the disputed blocks.
+func ArbitrateResourceLease(arbitrator Address, id string, refund uint64, terminate bool, resolution string) (*ResourceLease, error) {
+	if set := CurrentAuthoritySet(); set == nil || !set.IsAuthority(arbitrator) {
+		return nil, ErrUnauthorized
+	}
+	leaseMu.Lock()
+	defer leaseMu.Unlock()
+	l, err := GetResourceLease(id)
+	if err != nil {
+		return nil, err
+	}
+	if l.Status != LeaseDisputed || l.Dispute == nil {
+		return nil, errors.New("lease is not disputed")
+	}
+	if refund > l.Escrow {
+		return nil, fmt.Errorf("refund exceeds escrow %d", l.Escrow)
+	}
+	if refund > 0 {
+		if err := Transfer(&Context{}, AssetRef{Kind: AssetCoin}, resourceMarketAccount(), l.Client, refund); err != nil {
+			return nil, err
+		}
+		l.Escrow -= refund
+	}
+	l.Dispute.Arbitrator = arbitrator
+	l.Dispute.Refund = refund
+	l.Dispute.Resolution = resolution
+	h := currentHeight()
+	if h > l.PaidThrough {
+		l.PaidThrough = h
+	}
+	if l.ReportedTo < l.PaidThrough {
+		l.ReportedTo = l.PaidThrough
+	}
+	l.Status = LeaseActive
+	if terminate || l.Escrow < l.PricePerBlock {
+		status := LeaseTerminated
+		if !terminate {
+			status = LeaseExhausted
+		}
+		if err := endLease(l, status); err != nil {
+			return nil, err
+		}
+	}
+	return l, putResourceJSON(resourceLeaseKey(l.ID), l)
+}
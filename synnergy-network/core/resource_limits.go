@@ -0,0 +1,155 @@
+package core
+
+// resource_limits.go – per-transaction and per-block resource limits.
+//
+// Limits keep a proposer from filling a block with transactions that are
+// cheap to build and expensive to process:
+//
+//   - MaxTxPayload bounds the input bytes of a transaction: its payload,
+//     encrypted payload and multicall data.
+//   - MaxCodeSize bounds deployed contract code.
+//   - MaxStateWritesPerTx bounds the state changes a transaction carries.
+//   - MaxLogsPerTx bounds the logs one execution may emit; the VMs fail the
+//     call that emits one too many.
+//   - BlockGasLimit bounds the gas limit of a single transaction and the sum
+//     over a sub-block or block.
+//
+// Transactions are checked on admission to the pool (TxPool.ValidateTx),
+// sub-blocks when proposed and collected, and blocks when appended. Blocks
+// replayed from the WAL or a snapshot are not re-checked: they were valid
+// under the limits of their time.
+//
+// The limits are governance parameters: block_gas_limit, max_tx_payload,
+// max_code_size, max_logs_per_tx and max_state_writes_per_tx.
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+)
+
+// Resource limit errors.
+var (
+	ErrTxTooLarge         = errors.New("transaction payload too large")
+	ErrCodeTooLarge       = errors.New("contract code too large")
+	ErrTooManyStateWrites = errors.New("too many state writes")
+	ErrTooManyLogs        = errors.New("too many logs")
+	ErrBlockGasExceeded   = errors.New("block gas limit exceeded")
+)
+
+// ResourceLimits are the enforced transaction and block limits.
+type ResourceLimits struct {
+	MaxTxPayload        int    `json:"max_tx_payload"`
+	MaxCodeSize         int    `json:"max_code_size"`
+	MaxLogsPerTx        int    `json:"max_logs_per_tx"`
+	MaxStateWritesPerTx int    `json:"max_state_writes_per_tx"`
+	BlockGasLimit       uint64 `json:"block_gas_limit"`
+}
+
+var (
+	limitsMu       sync.RWMutex
+	resourceLimits = ResourceLimits{
+		MaxTxPayload:        128 << 10,
+		MaxCodeSize:         256 << 10,
+		MaxLogsPerTx:        1024,
+		MaxStateWritesPerTx: 1024,
+	}
+)
+
+// CurrentResourceLimits returns the limits in effect.
+func CurrentResourceLimits() ResourceLimits {
+	limitsMu.RLock()
+	lim := resourceLimits
+	limitsMu.RUnlock()
+	lim.BlockGasLimit = blockGasLimit
+	return lim
+}
+
+// updateResourceLimit applies the max_* governance parameters.
+func updateResourceLimit(key, value string) error {
+	v, err := strconv.Atoi(value)
+	if err != nil || v <= 0 {
+		return fmt.Errorf("invalid limit %q: must be a positive integer", value)
+	}
+	limitsMu.Lock()
+	defer limitsMu.Unlock()
+	switch key {
+	case "max_tx_payload":
+		resourceLimits.MaxTxPayload = v
+	case "max_code_size":
+		resourceLimits.MaxCodeSize = v
+	case "max_logs_per_tx":
+		resourceLimits.MaxLogsPerTx = v
+	case "max_state_writes_per_tx":
+		resourceLimits.MaxStateWritesPerTx = v
+	default:
+		return fmt.Errorf("unknown param: %s", key)
+	}
+	return nil
+}
+
+// TxPayloadSize is the input size MaxTxPayload bounds.
+func TxPayloadSize(tx *Transaction) int {
+	n := len(tx.Payload) + len(tx.EncryptedPayload)
+	for _, c := range tx.Calls {
+		n += len(c.Data)
+	}
+	return n
+}
+
+// CheckTxLimits checks tx against the per-transaction limits.
+func (lim ResourceLimits) CheckTxLimits(tx *Transaction) error {
+	if n := TxPayloadSize(tx); n > lim.MaxTxPayload {
+		return fmt.Errorf("%w: %d bytes, limit %d", ErrTxTooLarge, n, lim.MaxTxPayload)
+	}
+	if tx.Contract != nil {
+		if n := len(tx.Contract.Bytecode); n > lim.MaxCodeSize {
+			return fmt.Errorf("%w: %d bytes, limit %d", ErrCodeTooLarge, n, lim.MaxCodeSize)
+		}
+	}
+	if n := len(tx.StateChanges); n > lim.MaxStateWritesPerTx {
+		return fmt.Errorf("%w: %d, limit %d", ErrTooManyStateWrites, n, lim.MaxStateWritesPerTx)
+	}
+	if lim.BlockGasLimit > 0 && tx.GasLimit > lim.BlockGasLimit {
+		return fmt.Errorf("%w: tx gas limit %d, block limit %d", ErrBlockGasExceeded, tx.GasLimit, lim.BlockGasLimit)
+	}
+	return nil
+}
+
+// CheckBlockLimits checks every transaction and the total gas of txs.
+func (lim ResourceLimits) CheckBlockLimits(txs []*Transaction) error {
+	var gas uint64
+	for i, tx := range txs {
+		if err := lim.CheckTxLimits(tx); err != nil {
+			return fmt.Errorf("tx %d: %w", i, err)
+		}
+		gas += tx.GasLimit
+		if lim.BlockGasLimit > 0 && gas > lim.BlockGasLimit {
+			return fmt.Errorf("%w: %d > %d at tx %d", ErrBlockGasExceeded, gas, lim.BlockGasLimit, i)
+		}
+	}
+	return nil
+}
+
+// CheckSubBlockLimits decodes the transactions of sb and checks them.
+func (lim ResourceLimits) CheckSubBlockLimits(sb *SubBlock) error {
+	txs := make([]*Transaction, 0, len(sb.Body.Transactions))
+	for i, raw := range sb.Body.Transactions {
+		var tx Transaction
+		if err := json.Unmarshal(raw, &tx); err != nil {
+			return fmt.Errorf("tx %d: %w", i, err)
+		}
+		txs = append(txs, &tx)
+	}
+	return lim.CheckBlockLimits(txs)
+}
+
+// checkLogLimit fails the emission of a log when n logs already exist.
+func checkLogLimit(n int) error {
+	if max := CurrentResourceLimits().MaxLogsPerTx; n >= max {
+		return fmt.Errorf("%w: limit %d", ErrTooManyLogs, max)
+	}
+	return nil
+}
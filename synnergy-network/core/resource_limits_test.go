@@ -0,0 +1,53 @@
+package core
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestResourceLimits(t *testing.T) {
+	lim := ResourceLimits{MaxTxPayload: 8, MaxCodeSize: 4, MaxLogsPerTx: 2, MaxStateWritesPerTx: 1, BlockGasLimit: 100}
+	cases := []struct {
+		tx   *Transaction
+		want error
+	}{
+		{&Transaction{Payload: make([]byte, 8), GasLimit: 100}, nil},
+		{&Transaction{Payload: make([]byte, 4), Calls: []Call{{Data: make([]byte, 5)}}}, ErrTxTooLarge},
+		{&Transaction{Contract: &Contract{Bytecode: make([]byte, 5)}}, ErrCodeTooLarge},
+		{&Transaction{StateChanges: map[string][]byte{"a": nil, "b": nil}}, ErrTooManyStateWrites},
+		{&Transaction{GasLimit: 101}, ErrBlockGasExceeded},
+	}
+	for i, tc := range cases {
+		if err := lim.CheckTxLimits(tc.tx); !errors.Is(err, tc.want) {
+			t.Fatalf("case %d: got %v want %v", i, err, tc.want)
+		}
+	}
+	txs := []*Transaction{{GasLimit: 60}, {GasLimit: 40}}
+	if err := lim.CheckBlockLimits(txs); err != nil {
+		t.Fatalf("block at limit: %v", err)
+	}
+	if err := lim.CheckBlockLimits(append(txs, &Transaction{GasLimit: 1})); !errors.Is(err, ErrBlockGasExceeded) {
+		t.Fatalf("block over limit: %v", err)
+	}
+}
+
+func TestResourceLimitGovernance(t *testing.T) {
+	saved := CurrentResourceLimits()
+	defer func() {
+		limitsMu.Lock()
+		resourceLimits = saved
+		limitsMu.Unlock()
+	}()
+	if err := UpdateParam("max_logs_per_tx", "1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkLogLimit(0); err != nil {
+		t.Fatalf("first log: %v", err)
+	}
+	if err := checkLogLimit(1); !errors.Is(err, ErrTooManyLogs) {
+		t.Fatalf("second log: %v", err)
+	}
+	if err := UpdateParam("max_code_size", "0"); err == nil {
+		t.Fatal("zero limit accepted")
+	}
+}
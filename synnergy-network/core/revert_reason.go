@@ -0,0 +1,223 @@
+package core
+
+// revert_reason.go – decoding of revert payloads.
+//
+// A contract aborts with a payload: light-VM code with the REVERT opcode,
+// which returns the value on top of the stack, and WASM code by calling
+// host_revert. Payloads follow the Solidity convention. Error(string)
+// (selector 0x08c379a0) carries a message and Panic(uint256) (0x4e487b71)
+// a panic code; any other selector is a custom error, decoded with the
+// signatures of the ABI registry. Payloads without a known selector are
+// shown as text when printable and as hex otherwise.
+//
+// Failed receipts carry the decoded RevertReason, and its Message is the
+// human-readable reason that simulations, traces, the CLI and the explorer
+// show in place of the bare VM error.
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Revert payload kinds.
+const (
+	RevertKindError  = "error"  // Error(string)
+	RevertKindPanic  = "panic"  // Panic(uint256)
+	RevertKindCustom = "custom" // custom error found in the ABI registry
+	RevertKindRaw    = "raw"    // anything else
+)
+
+var (
+	revertErrorSelector = crypto.Keccak256([]byte("Error(string)"))[:4]
+	revertPanicSelector = crypto.Keccak256([]byte("Panic(uint256)"))[:4]
+)
+
+// RevertReason is a decoded revert payload.
+type RevertReason struct {
+	Kind      string `json:"kind"`
+	Message   string `json:"message"`             // human-readable reason
+	Selector  string `json:"selector,omitempty"`  // hex, first four bytes
+	Signature string `json:"signature,omitempty"` // of a custom error
+	// Args are the decoded custom error arguments, by name where the ABI
+	// names them.
+	Args      []string `json:"args,omitempty"`
+	PanicCode *uint64  `json:"panic_code,omitempty"`
+	Data      string   `json:"data,omitempty"` // hex payload
+}
+
+// ABIRegistry resolves custom error selectors to their ABI definitions.
+type ABIRegistry struct {
+	mu     sync.RWMutex
+	errors map[[4]byte]abi.Error
+}
+
+// NewABIRegistry returns an empty registry.
+func NewABIRegistry() *ABIRegistry {
+	return &ABIRegistry{errors: make(map[[4]byte]abi.Error)}
+}
+
+var abiRegistry = NewABIRegistry()
+
+// ABIs returns the global registry used to decode revert payloads. The
+// ledger adds the ABI of every contract it deploys.
+func ABIs() *ABIRegistry { return abiRegistry }
+
+// Register adds the custom errors of a and returns how many it defines.
+func (r *ABIRegistry) Register(a abi.ABI) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, e := range a.Errors {
+		var sel [4]byte
+		copy(sel[:], e.ID[:4])
+		r.errors[sel] = e
+	}
+	return len(a.Errors)
+}
+
+// RegisterJSON parses a JSON ABI and registers its custom errors.
+func (r *ABIRegistry) RegisterJSON(data []byte) (int, error) {
+	a, err := abi.JSON(bytes.NewReader(data))
+	if err != nil {
+		return 0, NewError(CodeInvalidArgument, "abi", "invalid ABI: %v", err)
+	}
+	return r.Register(a), nil
+}
+
+// Errors lists the signatures of the registered custom errors.
+func (r *ABIRegistry) Errors() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]string, 0, len(r.errors))
+	for _, e := range r.errors {
+		out = append(out, e.Sig)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// RevertOf returns the decoded revert payload err carries, or nil when err
+// is not a revert.
+func RevertOf(err error) *RevertReason {
+	var rev *revertError
+	if errors.As(err, &rev) {
+		return rev.Reason()
+	}
+	return nil
+}
+
+// DecodeRevert decodes data with the global ABI registry.
+func DecodeRevert(data []byte) *RevertReason { return abiRegistry.Decode(data) }
+
+// Decode turns a revert payload into a RevertReason. It never fails:
+// payloads it cannot decode are reported as raw.
+func (r *ABIRegistry) Decode(data []byte) *RevertReason {
+	rr := &RevertReason{Kind: RevertKindRaw, Data: hex.EncodeToString(data)}
+	if len(data) >= 4 {
+		rr.Selector = hex.EncodeToString(data[:4])
+		switch {
+		case bytes.Equal(data[:4], revertErrorSelector):
+			if msg, err := abi.UnpackRevert(data); err == nil {
+				rr.Kind, rr.Message = RevertKindError, msg
+				return rr
+			}
+		case bytes.Equal(data[:4], revertPanicSelector):
+			if code, ok := unpackPanicCode(data[4:]); ok {
+				desc, _ := abi.UnpackRevert(data)
+				rr.Kind, rr.PanicCode = RevertKindPanic, &code
+				rr.Message = fmt.Sprintf("panic 0x%02x: %s", code, desc)
+				return rr
+			}
+		default:
+			if r.decodeCustom(data, rr) {
+				return rr
+			}
+		}
+	}
+	switch {
+	case len(data) == 0:
+		rr.Message = "reverted without a reason"
+	case printable(data):
+		rr.Message = string(data)
+	default:
+		rr.Message = "reverted with data 0x" + rr.Data
+	}
+	return rr
+}
+
+func (r *ABIRegistry) decodeCustom(data []byte, rr *RevertReason) bool {
+	var sel [4]byte
+	copy(sel[:], data[:4])
+	r.mu.RLock()
+	e, ok := r.errors[sel]
+	r.mu.RUnlock()
+	if !ok {
+		return false
+	}
+	vals, err := e.Inputs.Unpack(data[4:])
+	if err != nil {
+		return false
+	}
+	rr.Kind, rr.Signature = RevertKindCustom, e.Sig
+	for i, v := range vals {
+		arg := formatABIValue(v)
+		if name := e.Inputs[i].Name; name != "" {
+			arg = name + ": " + arg
+		}
+		rr.Args = append(rr.Args, arg)
+	}
+	rr.Message = e.Name + "(" + strings.Join(rr.Args, ", ") + ")"
+	return true
+}
+
+func unpackPanicCode(data []byte) (uint64, bool) {
+	if len(data) != 32 {
+		return 0, false
+	}
+	code := new(big.Int).SetBytes(data)
+	return code.Uint64(), code.IsUint64()
+}
+
+// formatABIValue renders a decoded ABI argument; byte values print as hex.
+func formatABIValue(v interface{}) string {
+	switch x := v.(type) {
+	case []byte:
+		return "0x" + hex.EncodeToString(x)
+	case fmt.Stringer:
+		return x.String()
+	}
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Array && rv.Type().Elem().Kind() == reflect.Uint8 {
+		b := make([]byte, rv.Len())
+		reflect.Copy(reflect.ValueOf(b), rv)
+		return "0x" + hex.EncodeToString(b)
+	}
+	if b, err := json.Marshal(v); err == nil {
+		return string(b)
+	}
+	return fmt.Sprint(v)
+}
+
+// printable reports whether data reads as a plain text message.
+func printable(data []byte) bool {
+	if !utf8.Valid(data) {
+		return false
+	}
+	for _, r := range string(data) {
+		if !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,71 @@
+package core
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func packRevert(t *testing.T, sig string, types []string, vals ...interface{}) []byte {
+	t.Helper()
+	var args abi.Arguments
+	for _, ty := range types {
+		typ, err := abi.NewType(ty, "", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		args = append(args, abi.Argument{Type: typ})
+	}
+	enc, err := args.Pack(vals...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return append(crypto.Keccak256([]byte(sig))[:4], enc...)
+}
+
+func TestDecodeRevertPayloads(t *testing.T) {
+	reg := NewABIRegistry()
+	if _, err := reg.RegisterJSON([]byte(`[{"type":"error","name":"InsufficientBalance","inputs":[{"name":"have","type":"uint256"},{"name":"want","type":"uint256"}]}]`)); err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		data []byte
+		kind string
+		msg  string
+	}{
+		{packRevert(t, "Error(string)", []string{"string"}, "not owner"), RevertKindError, "not owner"},
+		{packRevert(t, "Panic(uint256)", []string{"uint256"}, big.NewInt(0x11)), RevertKindPanic, "panic 0x11: arithmetic underflow or overflow"},
+		{packRevert(t, "InsufficientBalance(uint256,uint256)", []string{"uint256", "uint256"}, big.NewInt(1), big.NewInt(2)),
+			RevertKindCustom, "InsufficientBalance(have: 1, want: 2)"},
+		{[]byte("paused"), RevertKindRaw, "paused"},
+		{[]byte{0xde, 0xad, 0xbe, 0xef, 0x00}, RevertKindRaw, "reverted with data 0xdeadbeef00"},
+		{nil, RevertKindRaw, "reverted without a reason"},
+	}
+	for _, tc := range cases {
+		rr := reg.Decode(tc.data)
+		if rr.Kind != tc.kind || rr.Message != tc.msg {
+			t.Fatalf("decode %x = %s %q, want %s %q", tc.data, rr.Kind, rr.Message, tc.kind, tc.msg)
+		}
+	}
+}
+
+func TestLightRevertSetsReceiptReason(t *testing.T) {
+	st, _ := NewInMemory()
+	payload := packRevert(t, "Error(string)", []string{"string"}, "not owner")
+	code := append([]byte{byte(PUSH), byte(len(payload))}, payload...)
+	code = append(code, byte(REVERT))
+	sl := &StructLogger{}
+	rec, err := NewLightVM(st, NewGasMeter(1_000_000)).Execute(code, &VMContext{Tracer: sl})
+	if err == nil || rec.Status {
+		t.Fatalf("expected revert, got %v %+v", err, rec)
+	}
+	if rec.Revert == nil || rec.Revert.Message != "not owner" || !strings.HasSuffix(rec.Error, "not owner") {
+		t.Fatalf("receipt = %+v", rec)
+	}
+	if r := sl.Result().(*StructLogResult); r.Revert == nil || r.Revert.Message != "not owner" {
+		t.Fatalf("trace = %+v", r)
+	}
+}
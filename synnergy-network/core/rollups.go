@@ -82,7 +82,11 @@ func (ag *Aggregator) SubmitBatch(submitter Address, txs [][]byte, preStateRoot
 		return 0, errors.New("empty batch")
 	}
 
-	txRoot := merkleRoot(txs)
+	var acc MerkleAccumulator
+	for _, tx := range txs {
+		acc.Append(tx)
+	}
+	txRoot, _ := acc.Root()
 	// execute transactions in roll‑up VM (simplified – assume deterministic)
 	stateRoot := executeRollupState(preStateRoot, txs)
 	hdr := BatchHeader{BatchID: id, ParentID: id - 1, TxRoot: txRoot, StateRoot: stateRoot, Submitter: submitter, Timestamp: time.Now().Unix()}
@@ -120,7 +124,7 @@ func (ag *Aggregator) SubmitFraudProof(fp FraudProof) error {
 	if err != nil {
 		return err
 	}
-	if !VerifyMerkleProof(hdr.TxRoot[:], txData, fp.Proof, fp.TxIndex) {
+	if !VerifyMerklePath(hdr.TxRoot, txData, fp.Proof, fp.TxIndex) {
 		return errors.New("invalid merkle proof")
 	}
 
@@ -172,16 +176,6 @@ func executeRollupState(prev [32]byte, txs [][]byte) [32]byte {
 	return out
 }
 
-func merkleRoot(leaves [][]byte) [32]byte {
-	root, err := MerkleRoot(leaves)
-	if err != nil {
-		// Safe fallback: return zero hash or handle gracefully
-		var empty [32]byte
-		return empty
-	}
-	return root
-}
-
 // MerkleRoot computes the Merkle root of a slice of transaction hashes (or other byte slices).
 // Returns [32]byte root hash or an error if input is empty.
 func MerkleRoot(hashes [][]byte) ([32]byte, error) {
@@ -244,6 +238,23 @@ func (ag *Aggregator) BatchState(id uint64) BatchState {
 	return BatchState(raw[0])
 }
 
+// TxProof returns the Merkle path of transaction idx of batch id against the
+// batch TxRoot, as a FraudProof carries it.
+func (ag *Aggregator) TxProof(id uint64, idx uint32) ([][]byte, error) {
+	if _, err := ag.BatchHeader(id); err != nil {
+		return nil, err
+	}
+	var acc MerkleAccumulator
+	for i := uint32(0); ; i++ {
+		tx, err := ag.fetchTxFromBatch(id, i)
+		if err != nil {
+			break
+		}
+		acc.Append(tx)
+	}
+	return acc.Proof(idx)
+}
+
 func (ag *Aggregator) fetchTxFromBatch(id uint64, idx uint32) ([]byte, error) {
 	key := txKey(id, idx)
 	v, _ := ag.led.GetState(key)
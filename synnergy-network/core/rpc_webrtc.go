@@ -3,6 +3,7 @@ package core
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"sync"
@@ -37,10 +38,12 @@ func NewRPCWebRTC(ledger *Ledger, cons *SynnergyConsensus) *RPCWebRTC {
 	}
 }
 
-// RPC_Serve starts an HTTP server exposing basic RPC endpoints.
+// RPC_Serve starts an HTTP server exposing basic RPC endpoints. POST /batch
+// submits several transactions in one request.
 func (r *RPCWebRTC) RPC_Serve(addr string) error {
 	mux := http.NewServeMux()
 	mux.HandleFunc("/tx", r.handleTx)
+	mux.Handle("/batch", BatchHandler(mux, DefaultBatchConfig(), "rpc"))
 	r.srv = &http.Server{Addr: addr, Handler: mux}
 	return r.srv.ListenAndServe()
 }
@@ -114,11 +117,11 @@ func (r *RPCWebRTC) handleTx(w http.ResponseWriter, req *http.Request) {
 	}
 	var tx Transaction
 	if err := json.NewDecoder(req.Body).Decode(&tx); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		WriteHTTPError(w, http.StatusBadRequest, "rpc", err)
 		return
 	}
 	if r.ledger == nil {
-		http.Error(w, "ledger not initialised", http.StatusInternalServerError)
+		WriteHTTPError(w, http.StatusInternalServerError, "rpc", errors.New("ledger not initialised"))
 		return
 	}
 	r.ledger.AddToPool(&tx)
@@ -0,0 +1,311 @@
+package core
+
+// sanctions_registry.go - enforcement registry maintained by regulators.
+//
+// Addresses holding the "regulator" role (see AccessController) may
+// blacklist or freeze accounts. Actions and the resulting standing of each
+// address are ledger state, so every node replaying the chain enforces the
+// same list: transfers involving a listed address are refused at execution
+// time by the ledger, block application and the generic Transfer helper,
+// whether or not the registry has been initialised on that node. The tx
+// pool refuses such transactions on admission. Every action is also written
+// to the audit trail so the full enforcement history of an address can be
+// reconstructed.
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RoleRegulator is the access-control role allowed to maintain the registry.
+const RoleRegulator = "regulator"
+
+var (
+	ErrAddressSanctioned = errors.New("address is sanctioned")
+	ErrAddressFrozen     = errors.New("address is frozen")
+)
+
+// EnforcementKind enumerates registry actions.
+type EnforcementKind string
+
+const (
+	EnforceBlacklist   EnforcementKind = "blacklist"
+	EnforceUnblacklist EnforcementKind = "unblacklist"
+	EnforceFreeze      EnforcementKind = "freeze"
+	EnforceUnfreeze    EnforcementKind = "unfreeze"
+)
+
+// EnforcementAction is a single regulator decision against an address.
+type EnforcementAction struct {
+	ID        string          `json:"id"`
+	Kind      EnforcementKind `json:"kind"`
+	Target    Address         `json:"target"`
+	Regulator Address         `json:"regulator"`
+	Reason    string          `json:"reason"`
+	Reference string          `json:"reference,omitempty"` // external case / list reference
+	Time      time.Time       `json:"time"`
+}
+
+// EnforcementStatus is the current standing of an address.
+type EnforcementStatus struct {
+	Address     Address   `json:"address"`
+	Blacklisted bool      `json:"blacklisted"`
+	Frozen      bool      `json:"frozen"`
+	Since       time.Time `json:"since,omitempty"`
+	LastAction  string    `json:"last_action,omitempty"`
+}
+
+// SanctionsRegistry records regulator actions on the ledger.
+type SanctionsRegistry struct {
+	mu  sync.Mutex
+	led *Ledger
+	ac  *AccessController
+}
+
+// sanctionsState is the state enforcement standings are read from.
+type sanctionsState interface {
+	GetState(key []byte) ([]byte, error)
+}
+
+func sanctionsStatusKey(a Address) []byte { return []byte("sanctions:status:" + a.Hex()) }
+
+var (
+	sanctionsOnce sync.Once
+	sanctions     *SanctionsRegistry
+)
+
+// InitSanctionsRegistry binds the registry to the ledger. InitLedger calls
+// it during node start-up; subsequent calls are ignored.
+func InitSanctionsRegistry(led *Ledger) (*SanctionsRegistry, error) {
+	if led == nil {
+		return nil, errors.New("sanctions registry: ledger not initialised")
+	}
+	sanctionsOnce.Do(func() {
+		sanctions = &SanctionsRegistry{led: led, ac: NewAccessController(led)}
+	})
+	return sanctions, nil
+}
+
+// Sanctions returns the global registry or nil if not initialised.
+func Sanctions() *SanctionsRegistry { return sanctions }
+
+// CheckSanctions returns an error when either party of a transfer is
+// blacklisted or frozen on the current ledger.
+func CheckSanctions(from, to Address) error {
+	if r := Sanctions(); r != nil {
+		return checkSanctionsIn(r.led, from, to)
+	}
+	if l := CurrentLedger(); l != nil {
+		return checkSanctionsIn(l, from, to)
+	}
+	return nil
+}
+
+// CheckTxSanctions returns an error when a transaction moves value to or
+// from a blacklisted or frozen address on the current ledger.
+func CheckTxSanctions(tx *Transaction) error {
+	if r := Sanctions(); r != nil {
+		return checkTxSanctions(r.led, tx)
+	}
+	if l := CurrentLedger(); l != nil {
+		return checkTxSanctions(l, tx)
+	}
+	return nil
+}
+
+// checkTxSanctions checks every party of tx against st: the sender and
+// recipient, both sides of each token transfer and the target of each
+// multicall call.
+func checkTxSanctions(st sanctionsState, tx *Transaction) error {
+	if err := checkSanctionsIn(st, tx.From, tx.To); err != nil {
+		return err
+	}
+	for _, tr := range tx.TokenTransfers {
+		if err := checkSanctionsIn(st, tr.From, tr.To); err != nil {
+			return err
+		}
+	}
+	for _, c := range tx.Calls {
+		if err := checkSanctionsIn(st, tx.From, c.To); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// heldState reads the state of a ledger whose lock the caller holds.
+type heldState struct{ l *Ledger }
+
+func (s heldState) GetState(key []byte) ([]byte, error) { return s.l.State[string(key)], nil }
+
+// checkSanctionsIn checks both parties of a transfer against st.
+func checkSanctionsIn(state sanctionsState, from, to Address) error {
+	for _, a := range [2]Address{from, to} {
+		st := sanctionStatus(state, a)
+		if st.Blacklisted {
+			return fmt.Errorf("%w: %s", ErrAddressSanctioned, a.Hex())
+		}
+		if st.Frozen {
+			return fmt.Errorf("%w: %s", ErrAddressFrozen, a.Hex())
+		}
+	}
+	return nil
+}
+
+// IsRegulator reports whether addr holds the regulator role, falling back
+// to the ledger when the registry has not been initialised.
+func IsRegulator(addr Address) bool {
+	if r := Sanctions(); r != nil {
+		return r.IsRegulator(addr)
+	}
+	if l := CurrentLedger(); l != nil {
+		return NewAccessController(l).HasRole(addr, RoleRegulator)
+	}
+	return false
+}
+
+// IsRegulator reports whether addr may maintain the registry.
+func (r *SanctionsRegistry) IsRegulator(addr Address) bool {
+	return r.ac.HasRole(addr, RoleRegulator)
+}
+
+// Blacklist lists target as sanctioned.
+func (r *SanctionsRegistry) Blacklist(regulator, target Address, reason, ref string) (*EnforcementAction, error) {
+	return r.apply(EnforceBlacklist, regulator, target, reason, ref)
+}
+
+// Unblacklist removes target from the sanctions list.
+func (r *SanctionsRegistry) Unblacklist(regulator, target Address, reason, ref string) (*EnforcementAction, error) {
+	return r.apply(EnforceUnblacklist, regulator, target, reason, ref)
+}
+
+// Freeze blocks all transfers to and from target.
+func (r *SanctionsRegistry) Freeze(regulator, target Address, reason, ref string) (*EnforcementAction, error) {
+	return r.apply(EnforceFreeze, regulator, target, reason, ref)
+}
+
+// Unfreeze lifts a freeze on target.
+func (r *SanctionsRegistry) Unfreeze(regulator, target Address, reason, ref string) (*EnforcementAction, error) {
+	return r.apply(EnforceUnfreeze, regulator, target, reason, ref)
+}
+
+// Status returns the current standing of addr.
+func (r *SanctionsRegistry) Status(addr Address) EnforcementStatus {
+	return sanctionStatus(r.led, addr)
+}
+
+// sanctionStatus reads the standing of addr from st. Addresses without a
+// record are in good standing.
+func sanctionStatus(st sanctionsState, addr Address) EnforcementStatus {
+	out := EnforcementStatus{Address: addr}
+	raw, err := st.GetState(sanctionsStatusKey(addr))
+	if err != nil || len(raw) == 0 {
+		return out
+	}
+	if json.Unmarshal(raw, &out) != nil {
+		// an unreadable record must not lift a sanction
+		return EnforcementStatus{Address: addr, Blacklisted: true}
+	}
+	return out
+}
+
+// Listed returns every address that is currently blacklisted or frozen.
+func (r *SanctionsRegistry) Listed() []EnforcementStatus {
+	it := r.led.PrefixIterator([]byte("sanctions:status:"))
+	var out []EnforcementStatus
+	for it.Next() {
+		var st EnforcementStatus
+		if err := json.Unmarshal(it.Value(), &st); err != nil {
+			continue
+		}
+		if st.Blacklisted || st.Frozen {
+			out = append(out, st)
+		}
+	}
+	return out
+}
+
+// Actions returns the enforcement history for target in chronological order.
+func (r *SanctionsRegistry) Actions(target Address) ([]EnforcementAction, error) {
+	it := r.led.PrefixIterator([]byte("sanctions:action:" + target.Hex() + ":"))
+	var keys []string
+	raw := make(map[string][]byte)
+	for it.Next() {
+		k := string(it.Key())
+		keys = append(keys, k)
+		raw[k] = append([]byte(nil), it.Value()...)
+	}
+	if err := it.Error(); err != nil {
+		return nil, err
+	}
+	// keys carry the action time, so they sort oldest first
+	sort.Strings(keys)
+	out := make([]EnforcementAction, 0, len(keys))
+	for _, k := range keys {
+		var a EnforcementAction
+		if err := json.Unmarshal(raw[k], &a); err != nil {
+			return nil, err
+		}
+		out = append(out, a)
+	}
+	return out, nil
+}
+
+func (r *SanctionsRegistry) apply(kind EnforcementKind, regulator, target Address, reason, ref string) (*EnforcementAction, error) {
+	if !r.IsRegulator(regulator) {
+		return nil, ErrUnauthorized
+	}
+	if reason == "" {
+		return nil, errors.New("reason required")
+	}
+	act := &EnforcementAction{
+		ID:        uuid.New().String(),
+		Kind:      kind,
+		Target:    target,
+		Regulator: regulator,
+		Reason:    reason,
+		Reference: ref,
+		Time:      time.Now().UTC(),
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	st := r.Status(target)
+	switch kind {
+	case EnforceBlacklist:
+		st.Blacklisted = true
+	case EnforceUnblacklist:
+		st.Blacklisted = false
+	case EnforceFreeze:
+		st.Frozen = true
+	case EnforceUnfreeze:
+		st.Frozen = false
+	}
+	st.Since = act.Time
+	st.LastAction = act.ID
+
+	raw, _ := json.Marshal(act)
+	key := fmt.Sprintf("sanctions:action:%s:%020d:%s", target.Hex(), act.Time.UnixNano(), act.ID)
+	if err := r.led.SetState([]byte(key), raw); err != nil {
+		return nil, err
+	}
+	stRaw, _ := json.Marshal(st)
+	if err := r.led.SetState(sanctionsStatusKey(target), stRaw); err != nil {
+		return nil, err
+	}
+	if am := AuditManagerInstance(); am != nil {
+		_ = am.Log(target, "sanctions_"+string(kind), map[string]string{
+			"regulator": regulator.Hex(),
+			"reason":    reason,
+			"reference": ref,
+			"action":    act.ID,
+		})
+	}
+	return act, nil
+}
@@ -0,0 +1,149 @@
+package core
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestSanctionsAreLedgerState(t *testing.T) {
+	cfg, _ := tmpLedgerConfig(t, nil)
+	led, err := NewLedger(cfg)
+	if err != nil {
+		t.Fatalf("ledger: %v", err)
+	}
+	regulator, target, peer := Address{0x4e}, Address{0x7a}, Address{0x9e}
+	if err := NewAccessController(led).GrantRole(regulator, RoleRegulator); err != nil {
+		t.Fatalf("grant: %v", err)
+	}
+	if err := led.Mint(target, 100); err != nil {
+		t.Fatalf("mint: %v", err)
+	}
+	reg := &SanctionsRegistry{led: led, ac: NewAccessController(led)}
+
+	if _, err := reg.Blacklist(peer, target, "not a regulator", ""); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("blacklist by non-regulator: err=%v", err)
+	}
+	if _, err := reg.Blacklist(regulator, target, "", ""); err == nil {
+		t.Fatal("blacklist without a reason accepted")
+	}
+	if _, err := reg.Blacklist(regulator, target, "OFAC SDN", "case-1"); err != nil {
+		t.Fatalf("blacklist: %v", err)
+	}
+
+	// the ledger enforces the list without any registry in memory
+	if err := led.Transfer(target, peer, 10); !errors.Is(err, ErrAddressSanctioned) {
+		t.Fatalf("transfer from blacklisted: err=%v", err)
+	}
+	if err := led.Transfer(peer, target, 0); !errors.Is(err, ErrAddressSanctioned) {
+		t.Fatalf("transfer to blacklisted: err=%v", err)
+	}
+
+	// a second registry over the same state sees the same standing
+	other := &SanctionsRegistry{led: led, ac: NewAccessController(led)}
+	if st := other.Status(target); !st.Blacklisted || st.Frozen {
+		t.Fatalf("status seen by another registry: %+v", st)
+	}
+	if got := other.Listed(); len(got) != 1 || got[0].Address != target {
+		t.Fatalf("listed %+v", got)
+	}
+
+	if _, err := reg.Unblacklist(regulator, target, "delisted", "case-1"); err != nil {
+		t.Fatalf("unblacklist: %v", err)
+	}
+	if _, err := reg.Freeze(regulator, target, "court order", "case-2"); err != nil {
+		t.Fatalf("freeze: %v", err)
+	}
+	if err := led.Transfer(target, peer, 10); !errors.Is(err, ErrAddressFrozen) {
+		t.Fatalf("transfer from frozen: err=%v", err)
+	}
+	if _, err := other.Unfreeze(regulator, target, "released", "case-2"); err != nil {
+		t.Fatalf("unfreeze: %v", err)
+	}
+	if err := led.Transfer(target, peer, 10); err != nil {
+		t.Fatalf("transfer after release: %v", err)
+	}
+	if len(reg.Listed()) != 0 {
+		t.Fatalf("listed after release: %+v", reg.Listed())
+	}
+
+	acts, err := reg.Actions(target)
+	if err != nil {
+		t.Fatalf("actions: %v", err)
+	}
+	want := []EnforcementKind{EnforceBlacklist, EnforceUnblacklist, EnforceFreeze, EnforceUnfreeze}
+	if len(acts) != len(want) {
+		t.Fatalf("actions %+v", acts)
+	}
+	for i, k := range want {
+		if acts[i].Kind != k || acts[i].Regulator != regulator {
+			t.Fatalf("action %d = %+v, want %s", i, acts[i], k)
+		}
+	}
+}
+
+func TestSanctionStatusFailsClosed(t *testing.T) {
+	st, _ := NewInMemory()
+	addr := Address{0x01}
+	if got := sanctionStatus(st, addr); got.Blacklisted || got.Frozen {
+		t.Fatalf("unlisted address sanctioned: %+v", got)
+	}
+	_ = st.SetState(sanctionsStatusKey(addr), []byte("{corrupt"))
+	if err := checkSanctionsIn(st, addr, Address{0x02}); !errors.Is(err, ErrAddressSanctioned) {
+		t.Fatalf("corrupt record: err=%v", err)
+	}
+}
+
+func TestBlocksAndPoolRefuseSanctionedParties(t *testing.T) {
+	led := newDepositLedger(t)
+	prevLedger, prevRegistry := globalLedger, sanctions
+	globalLedger, sanctions = led, nil
+	t.Cleanup(func() { globalLedger, sanctions = prevLedger, prevRegistry })
+
+	regulator, target, peer := Address{0x4e}, Address{0x7a}, Address{0x9e}
+	if err := NewAccessController(led).GrantRole(regulator, RoleRegulator); err != nil {
+		t.Fatalf("grant: %v", err)
+	}
+	if err := led.Mint(peer, 100); err != nil {
+		t.Fatalf("mint: %v", err)
+	}
+	reg := &SanctionsRegistry{led: led, ac: NewAccessController(led)}
+	if _, err := reg.Blacklist(regulator, target, "OFAC SDN", "case-1"); err != nil {
+		t.Fatalf("blacklist: %v", err)
+	}
+
+	for name, tx := range map[string]*Transaction{
+		"token transfer": {TokenTransfers: []TokenTransfer{{From: peer, To: target, Amount: 10}}},
+		"multicall":      {Type: TxMulticall, From: peer, Calls: []Call{{To: Address{0x01}}, {To: target, Value: 10}}},
+	} {
+		blk := &Block{Header: BlockHeader{Height: 0}, Transactions: []*Transaction{tx}}
+		if err := led.applyBlock(blk, false); !errors.Is(err, ErrAddressSanctioned) {
+			t.Fatalf("%s: block paying a blacklisted address: %v", name, err)
+		}
+		if len(led.Blocks) != 0 || led.BalanceOf(peer) != 100 {
+			t.Fatalf("%s: refused block changed state", name)
+		}
+	}
+
+	// the pool refuses the transaction before it is gossiped
+	priv, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx := &Transaction{Type: TxPayment, To: target, Value: 1, GasLimit: 21_000, GasPrice: 1}
+	if err := tx.Sign(priv); err != nil {
+		t.Fatal(err)
+	}
+	tp := NewTxPool(nil, nil, nil, nil, nil, 0)
+	if err := tp.ValidateTx(tx); !errors.Is(err, ErrAddressSanctioned) {
+		t.Fatalf("pool admitted a payment to a blacklisted address: %v", err)
+	}
+	tx = &Transaction{Type: TxPayment, To: peer, Value: 1, GasLimit: 21_000, GasPrice: 1}
+	if err := tx.Sign(priv); err != nil {
+		t.Fatal(err)
+	}
+	if err := tp.ValidateTx(tx); err != nil {
+		t.Fatalf("payment to an unlisted address: %v", err)
+	}
+}
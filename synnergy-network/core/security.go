@@ -304,29 +304,37 @@ func ComputeMerkleRoot(leaves [][]byte) ([]byte, error) {
 	if len(leaves) == 0 {
 		return nil, errors.New("no leaves")
 	}
-	sort.SliceStable(leaves, func(i, j int) bool { return bytes.Compare(leaves[i], leaves[j]) < 0 })
-
-	level := make([][]byte, len(leaves))
-	for i, l := range leaves {
-		h := sha256.Sum256(l)
-		hh := sha256.Sum256(h[:])
-		level[i] = hh[:]
-	}
+	// sort a copy so the caller's slice keeps its order
+	sorted := append([][]byte(nil), leaves...)
+	sort.SliceStable(sorted, func(i, j int) bool { return bytes.Compare(sorted[i], sorted[j]) < 0 })
+
+	dsha := func(b []byte) [32]byte {
+		h := sha256.Sum256(b)
+		return sha256.Sum256(h[:])
+	}
+	level := make([][32]byte, len(sorted))
+	parallelFor(len(sorted), func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			level[i] = dsha(sorted[i])
+		}
+	})
 	for len(level) > 1 {
 		if len(level)%2 == 1 {
 			level = append(level, level[len(level)-1]) // duplicate last
 		}
-		var next [][]byte
-		for i := 0; i < len(level); i += 2 {
-			pair := append(level[i], level[i+1]...)
-			h := sha256.Sum256(pair)
-			hh := sha256.Sum256(h[:])
-			next = append(next, hh[:])
-		}
+		next := make([][32]byte, len(level)/2)
+		parallelFor(len(next), func(lo, hi int) {
+			var pair [64]byte
+			for i := lo; i < hi; i++ {
+				copy(pair[:32], level[2*i][:])
+				copy(pair[32:], level[2*i+1][:])
+				next[i] = dsha(pair[:])
+			}
+		})
 		level = next
 	}
 	root := make([]byte, 32)
-	copy(root, level[0])
+	copy(root, level[0][:])
 	return root, nil
 }
 
@@ -443,56 +451,86 @@ func NewZeroTrustTLSConfig(certPath, keyPath, caPath string, pinnedFingerprint [
 
 // AuditEvent represents a single immutable audit log entry.
 type AuditEvent struct {
+	Seq       uint64            `json:"seq"`
 	Timestamp int64             `json:"ts"`
 	Event     string            `json:"evt"`
 	Meta      map[string]string `json:"meta,omitempty"`
 	Hash      []byte            `json:"hash"`
 }
 
-// AuditTrail manages write-once audit logs with optional ledger anchoring.
+// AuditTrail manages write-once audit logs. Entry hashes are batched into
+// Merkle trees and the roots are checkpointed to the ledger (see
+// audit_checkpoint.go).
 type AuditTrail struct {
-	mu     sync.Mutex
-	file   *os.File
-	ledger *Ledger
+	mu        sync.Mutex
+	file      *os.File
+	ledger    *Ledger
+	seq       uint64            // sequence number of the next entry
+	pending   MerkleAccumulator // entry hashes since the last checkpoint
+	firstSeq  uint64            // sequence of the first pending entry
+	batchSize int
 }
 
 // NewAuditTrail creates or opens an append-only log file. If ledger is non-nil
-// each entry hash is also stored on-chain for tamper evidence.
+// batch roots are checkpointed on-chain for tamper evidence.
 func NewAuditTrail(path string, ledger *Ledger) (*AuditTrail, error) {
-	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o600)
 	if err != nil {
 		return nil, err
 	}
-	return &AuditTrail{file: f, ledger: ledger}, nil
+	a := &AuditTrail{file: f, ledger: ledger, batchSize: DefaultAuditBatchSize}
+	if err := a.recover(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return a, nil
 }
 
-// Log writes an audit entry to disk and records its hash in the ledger.
+// Log writes an audit entry to disk. Once a full batch has accumulated the
+// batch is checkpointed.
 func (a *AuditTrail) Log(event string, meta map[string]string) error {
 	if a == nil || a.file == nil {
 		return errors.New("audit trail not initialised")
 	}
-	ev := AuditEvent{Timestamp: time.Now().Unix(), Event: event, Meta: meta}
-	raw, err := json.Marshal(ev)
-	if err != nil {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err := a.appendLocked(event, meta); err != nil {
+		return err
+	}
+	if a.batchSize > 0 && a.pending.Len() >= a.batchSize {
+		_, err := a.checkpointLocked()
 		return err
 	}
-	h := sha256.Sum256(raw)
+	return nil
+}
+
+// appendLocked hashes and writes a single entry. Caller holds a.mu.
+func (a *AuditTrail) appendLocked(event string, meta map[string]string) error {
+	ev := AuditEvent{Seq: a.seq, Timestamp: time.Now().Unix(), Event: event, Meta: meta}
+	h := ev.digest()
 	ev.Hash = h[:]
-	blob, _ := json.Marshal(ev)
-	a.mu.Lock()
-	defer a.mu.Unlock()
+	blob, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
 	if _, err := a.file.Write(append(blob, '\n')); err != nil {
 		return err
 	}
-	if a.ledger != nil {
-		key := append([]byte("audit:"), h[:]...)
-		if err := a.ledger.SetState(key, h[:]); err != nil {
-			return err
-		}
+	if a.pending.Len() == 0 {
+		a.firstSeq = ev.Seq
 	}
+	a.pending.Append(ev.Hash)
+	a.seq++
 	return nil
 }
 
+// digest returns the entry hash, computed over the entry without its hash.
+func (ev AuditEvent) digest() [32]byte {
+	ev.Hash = nil
+	raw, _ := json.Marshal(ev)
+	return sha256.Sum256(raw)
+}
+
 // Report reads all audit entries from the log file.
 func (a *AuditTrail) Report() ([]AuditEvent, error) {
 	if a == nil || a.file == nil {
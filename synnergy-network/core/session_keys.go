@@ -0,0 +1,519 @@
+package core
+
+// session_keys.go – scoped sub-keys for wallets.
+//
+// An account's signer may authorise a session key: a separate signing key
+// that can send transactions on the account's behalf within a policy. The
+// policy bounds the key by expiry, per-transaction value, a daily allowance
+// (UTC days) and optionally by recipient contracts and payload opcodes.
+// The value of a transaction is everything it moves out of the account: its
+// own value, each multicall call and each token transfer, and every
+// recipient among them must be allowed.
+//
+// Transactions whose signature recovers to a session key pass VerifySig;
+// TxPool.ValidateTx checks the policy and AddTx reserves the value against
+// the allowance in memory. The allowance is only charged in ledger state
+// when the block carrying the transaction is applied, against the block's
+// day, and the policy in state is enforced again then, so a key revoked or
+// expired while its transactions wait in the pool cannot spend. Reservations
+// of transactions that never execute lapse at the end of their day.
+// Session keys can never authorise further keys or change recovery settings
+// since those require the account signer itself.
+//
+// Ledger layout:
+//   session:key:<account>:<key>    -> SessionKeyPolicy
+//   session:usage:<account>:<key>  -> SessionKeyUsage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrSessionKeyExpired = errors.New("session key expired")
+	ErrSessionKeyPolicy  = errors.New("transaction violates session key policy")
+)
+
+// SessionKeyPolicy scopes what a session key may do for an account. Zero
+// limits and empty allow-lists mean "unrestricted" for that dimension.
+type SessionKeyPolicy struct {
+	Account          Address   `json:"account"`
+	Key              Address   `json:"key"`
+	Label            string    `json:"label,omitempty"`
+	Expires          time.Time `json:"expires"`
+	MaxPerTx         uint64    `json:"max_per_tx,omitempty"`
+	DailyAllowance   uint64    `json:"daily_allowance,omitempty"`
+	AllowedContracts []Address `json:"allowed_contracts,omitempty"`
+	AllowedOpcodes   []string  `json:"allowed_opcodes,omitempty"`
+	Created          time.Time `json:"created"`
+}
+
+// SessionKeyUsage tracks the allowance consumed on a UTC day.
+type SessionKeyUsage struct {
+	Day   string `json:"day"`
+	Spent uint64 `json:"spent"`
+}
+
+// SessionKeyManager holds authorised session keys. Policies are cached in
+// memory because they are consulted on every signature check.
+type SessionKeyManager struct {
+	mu       sync.RWMutex
+	led      *Ledger
+	policies map[Address]map[Address]*SessionKeyPolicy
+	usage    map[Address]map[Address]*SessionKeyUsage // as charged in state
+	pending  map[Hash]sessionCharge                   // admitted, not yet executed
+}
+
+// sessionCharge is the value a transaction signed by a session key spends
+// from the key's allowance on a day.
+type sessionCharge struct {
+	tx      Hash
+	account Address
+	key     Address
+	day     string
+	amount  uint64
+}
+
+var (
+	sessionOnce sync.Once
+	sessionMgr  *SessionKeyManager
+)
+
+// InitSessionKeys loads session key policies from the ledger. Subsequent
+// calls are ignored.
+func InitSessionKeys(led *Ledger) (*SessionKeyManager, error) {
+	var err error
+	sessionOnce.Do(func() {
+		m := &SessionKeyManager{
+			led:      led,
+			policies: make(map[Address]map[Address]*SessionKeyPolicy),
+			usage:    make(map[Address]map[Address]*SessionKeyUsage),
+			pending:  make(map[Hash]sessionCharge),
+		}
+		if err = m.load(); err == nil {
+			sessionMgr = m
+		}
+	})
+	return sessionMgr, err
+}
+
+// SessionKeys returns the global manager or nil if not initialised.
+func SessionKeys() *SessionKeyManager { return sessionMgr }
+
+// IsSessionKey reports whether key is an unexpired session key of account.
+func IsSessionKey(account, key Address) bool {
+	m := SessionKeys()
+	if m == nil {
+		return false
+	}
+	p, ok := m.Policy(account, key)
+	return ok && time.Now().Before(p.Expires)
+}
+
+func sessionKeyKey(account, key Address) []byte {
+	return []byte("session:key:" + account.Hex() + ":" + key.Hex())
+}
+
+func sessionUsageKey(account, key Address) []byte {
+	return []byte("session:usage:" + account.Hex() + ":" + key.Hex())
+}
+
+// Authorise registers p. caller must be the account's current signer and
+// the policy must expire in the future.
+func (m *SessionKeyManager) Authorise(caller Address, p SessionKeyPolicy) (*SessionKeyPolicy, error) {
+	if caller != AccountSigner(p.Account) {
+		return nil, ErrUnauthorized
+	}
+	if p.Key == AddressZero || p.Key == p.Account || p.Key == caller {
+		return nil, errors.New("session key must differ from the account signer")
+	}
+	if !p.Expires.After(time.Now()) {
+		return nil, errors.New("session key expiry must be in the future")
+	}
+	for _, name := range p.AllowedOpcodes {
+		if _, ok := opcodeByName(name); !ok {
+			return nil, fmt.Errorf("unknown opcode %q", name)
+		}
+	}
+	p.Created = time.Now().UTC()
+	raw, _ := json.Marshal(p)
+	if err := m.led.SetState(sessionKeyKey(p.Account, p.Key), raw); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	if m.policies[p.Account] == nil {
+		m.policies[p.Account] = make(map[Address]*SessionKeyPolicy)
+	}
+	m.policies[p.Account][p.Key] = &p
+	m.mu.Unlock()
+	sessionAudit(p.Account, "session_key_authorised", map[string]string{
+		"key":     p.Key.Hex(),
+		"expires": p.Expires.Format(time.RFC3339),
+	})
+	return &p, nil
+}
+
+// Revoke removes a session key. caller must be the account's signer.
+func (m *SessionKeyManager) Revoke(caller, account, key Address) error {
+	if caller != AccountSigner(account) {
+		return ErrUnauthorized
+	}
+	m.mu.Lock()
+	_, ok := m.policies[account][key]
+	delete(m.policies[account], key)
+	delete(m.usage[account], key)
+	for h, c := range m.pending {
+		if c.account == account && c.key == key {
+			delete(m.pending, h)
+		}
+	}
+	m.mu.Unlock()
+	if !ok {
+		return ErrNotFound
+	}
+	if err := m.led.DeleteState(sessionKeyKey(account, key)); err != nil {
+		return err
+	}
+	_ = m.led.DeleteState(sessionUsageKey(account, key))
+	sessionAudit(account, "session_key_revoked", map[string]string{"key": key.Hex()})
+	return nil
+}
+
+// Policy returns the policy of key for account.
+func (m *SessionKeyManager) Policy(account, key Address) (SessionKeyPolicy, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	p, ok := m.policies[account][key]
+	if !ok {
+		return SessionKeyPolicy{}, false
+	}
+	return *p, true
+}
+
+// List returns every session key of account.
+func (m *SessionKeyManager) List(account Address) []SessionKeyPolicy {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make([]SessionKeyPolicy, 0, len(m.policies[account]))
+	for _, p := range m.policies[account] {
+		out = append(out, *p)
+	}
+	return out
+}
+
+// Usage returns the allowance charged to key today by executed
+// transactions.
+func (m *SessionKeyManager) Usage(account, key Address) SessionKeyUsage {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	today := sessionDay(time.Now())
+	if u, ok := m.usage[account][key]; ok && u.Day == today {
+		return *u
+	}
+	return SessionKeyUsage{Day: today}
+}
+
+// CheckTx verifies tx, signed by key, against the key's policy without
+// reserving allowance.
+func (m *SessionKeyManager) CheckTx(tx *Transaction, key Address) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	_, err := m.checkLocked(tx, key, time.Now())
+	return err
+}
+
+// ReserveTx re-checks the policy and reserves the value of tx against the
+// daily allowance until the transaction executes. It is called when the
+// pool admits the transaction and writes no ledger state.
+func (m *SessionKeyManager) ReserveTx(tx *Transaction, key Address) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	c, err := m.checkLocked(tx, key, now)
+	if err != nil {
+		return err
+	}
+	for h, p := range m.pending {
+		if p.day != c.day {
+			delete(m.pending, h)
+		}
+	}
+	if m.policies[tx.From][key].DailyAllowance > 0 {
+		m.pending[tx.Hash] = c
+	}
+	return nil
+}
+
+// checkLocked checks tx against the cached policy of key, counting the
+// allowance charged in state and reserved by pooled transactions.
+func (m *SessionKeyManager) checkLocked(tx *Transaction, key Address, now time.Time) (sessionCharge, error) {
+	p, ok := m.policies[tx.From][key]
+	if !ok {
+		return sessionCharge{}, ErrUnauthorized
+	}
+	day := sessionDay(now)
+	var spent uint64
+	if u, ok := m.usage[tx.From][key]; ok && u.Day == day {
+		spent = u.Spent
+	}
+	for h, c := range m.pending {
+		if h != tx.Hash && c.account == tx.From && c.key == key && c.day == day {
+			spent += c.amount
+		}
+	}
+	return checkSessionPolicy(p, tx, now, spent)
+}
+
+// executed records charges applied by a block on the ledger the manager
+// serves and releases their reservations.
+func (m *SessionKeyManager) executed(l *Ledger, charges []sessionCharge) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, c := range charges {
+		delete(m.pending, c.tx)
+		if l != m.led {
+			continue
+		}
+		if m.usage[c.account] == nil {
+			m.usage[c.account] = make(map[Address]*SessionKeyUsage)
+		}
+		u, ok := m.usage[c.account][c.key]
+		if !ok || u.Day != c.day {
+			u = &SessionKeyUsage{Day: c.day}
+			m.usage[c.account][c.key] = u
+		}
+		u.Spent += c.amount
+	}
+}
+
+// checkSessionPolicy checks tx against p at now, with spent already
+// charged to the allowance that day, and returns what tx would charge.
+func checkSessionPolicy(p *SessionKeyPolicy, tx *Transaction, now time.Time, spent uint64) (sessionCharge, error) {
+	c := sessionCharge{tx: tx.Hash, account: tx.From, key: p.Key, day: sessionDay(now)}
+	if !now.Before(p.Expires) {
+		return c, ErrSessionKeyExpired
+	}
+	var targets []Address
+	if tx.Type != TxMulticall {
+		targets = append(targets, tx.To)
+	}
+	add := func(v uint64) error {
+		if v > math.MaxUint64-c.amount {
+			return fmt.Errorf("%w: value overflows", ErrSessionKeyPolicy)
+		}
+		c.amount += v
+		return nil
+	}
+	if err := add(tx.Value); err != nil {
+		return c, err
+	}
+	for _, call := range tx.Calls {
+		if err := add(call.Value); err != nil {
+			return c, err
+		}
+		targets = append(targets, call.To)
+	}
+	for _, tr := range tx.TokenTransfers {
+		if tr.From != tx.From {
+			return c, fmt.Errorf("%w: transfer from %s", ErrSessionKeyPolicy, tr.From.Hex())
+		}
+		if err := add(tr.Amount); err != nil {
+			return c, err
+		}
+		targets = append(targets, tr.To)
+	}
+	if p.MaxPerTx > 0 && c.amount > p.MaxPerTx {
+		return c, fmt.Errorf("%w: value %d exceeds per-tx limit %d", ErrSessionKeyPolicy, c.amount, p.MaxPerTx)
+	}
+	if p.DailyAllowance > 0 && (spent > p.DailyAllowance || c.amount > p.DailyAllowance-spent) {
+		return c, fmt.Errorf("%w: daily allowance %d exhausted (spent %d)", ErrSessionKeyPolicy, p.DailyAllowance, spent)
+	}
+	if len(p.AllowedContracts) > 0 {
+		for _, to := range targets {
+			if !p.allowsContract(to) {
+				return c, fmt.Errorf("%w: recipient %s not allowed", ErrSessionKeyPolicy, to.Hex())
+			}
+		}
+	}
+	if len(p.AllowedOpcodes) > 0 {
+		if len(tx.Payload)%3 != 0 {
+			return c, fmt.Errorf("%w: malformed payload", ErrSessionKeyPolicy)
+		}
+		for i := 0; i < len(tx.Payload); i += 3 {
+			op := MustParseOpcode(tx.Payload[i : i+3])
+			if !p.allowsOpcode(op) {
+				return c, fmt.Errorf("%w: opcode %s not allowed", ErrSessionKeyPolicy, op.Hex())
+			}
+		}
+	}
+	return c, nil
+}
+
+// sessionCharges checks the transactions of a block signed by session keys
+// against the policies in state at the block time and returns the charges
+// to apply. Replayed blocks are not re-checked: they charge what they spent
+// under the policies of their time. The caller holds l.mu.
+func (l *Ledger) sessionCharges(txs []*Transaction, at time.Time, enforce bool) ([]sessionCharge, error) {
+	var out []sessionCharge
+	spent := make(map[string]uint64)
+	for _, tx := range txs {
+		if len(tx.Sig) == 0 {
+			continue
+		}
+		signer, err := tx.Signer()
+		if err != nil || signer == AccountSigner(tx.From) {
+			continue
+		}
+		var p SessionKeyPolicy
+		raw := l.State[string(sessionKeyKey(tx.From, signer))]
+		if len(raw) == 0 || json.Unmarshal(raw, &p) != nil {
+			if enforce {
+				return nil, fmt.Errorf("tx %s: %w: signer %s is not a session key of %s",
+					tx.IDHex(), ErrUnauthorized, signer.Hex(), tx.From.Hex())
+			}
+			continue
+		}
+		uk := string(sessionUsageKey(tx.From, signer))
+		used, ok := spent[uk]
+		if !ok {
+			var u SessionKeyUsage
+			if json.Unmarshal(l.State[uk], &u) == nil && u.Day == sessionDay(at) {
+				used = u.Spent
+			}
+		}
+		c, err := checkSessionPolicy(&p, tx, at, used)
+		if err != nil {
+			if enforce {
+				return nil, fmt.Errorf("tx %s: %w", tx.IDHex(), err)
+			}
+			continue
+		}
+		if p.DailyAllowance == 0 {
+			continue
+		}
+		spent[uk] = used + c.amount
+		out = append(out, c)
+	}
+	return out, nil
+}
+
+// chargeSessionKeys writes the charges of an applied block to state and
+// hands them to the session key manager. The caller holds l.mu.
+func (l *Ledger) chargeSessionKeys(charges []sessionCharge) {
+	if len(charges) == 0 {
+		return
+	}
+	for _, c := range charges {
+		k := string(sessionUsageKey(c.account, c.key))
+		var u SessionKeyUsage
+		if json.Unmarshal(l.State[k], &u) != nil || u.Day != c.day {
+			u = SessionKeyUsage{Day: c.day}
+		}
+		u.Spent += c.amount
+		raw, _ := json.Marshal(u)
+		l.State[k] = raw
+	}
+	if m := SessionKeys(); m != nil {
+		m.executed(l, charges)
+	}
+}
+
+// revokeSessionKeys removes every session key of account from led and the
+// manager, without the signer check of Revoke, and returns how many were
+// removed. Social recovery calls it when it rotates the account's signer.
+func revokeSessionKeys(led *Ledger, account Address) (int, error) {
+	prefix := "session:key:" + account.Hex() + ":"
+	it := led.PrefixIterator([]byte(prefix))
+	var keys []string
+	for it.Next() {
+		keys = append(keys, string(it.Key()))
+	}
+	if err := it.Error(); err != nil {
+		return 0, err
+	}
+	for _, k := range keys {
+		if err := led.DeleteState([]byte(k)); err != nil {
+			return 0, err
+		}
+		_ = led.DeleteState([]byte("session:usage:" + strings.TrimPrefix(k, "session:key:")))
+	}
+	if m := SessionKeys(); m != nil {
+		m.mu.Lock()
+		delete(m.policies, account)
+		delete(m.usage, account)
+		for h, c := range m.pending {
+			if c.account == account {
+				delete(m.pending, h)
+			}
+		}
+		m.mu.Unlock()
+	}
+	if len(keys) > 0 {
+		sessionAudit(account, "session_keys_revoked", map[string]string{"count": fmt.Sprint(len(keys))})
+	}
+	return len(keys), nil
+}
+
+func sessionDay(t time.Time) string { return t.UTC().Format("2006-01-02") }
+
+func (m *SessionKeyManager) load() error {
+	it := m.led.PrefixIterator([]byte("session:key:"))
+	for it.Next() {
+		var p SessionKeyPolicy
+		if err := json.Unmarshal(it.Value(), &p); err != nil {
+			return err
+		}
+		if m.policies[p.Account] == nil {
+			m.policies[p.Account] = make(map[Address]*SessionKeyPolicy)
+		}
+		m.policies[p.Account][p.Key] = &p
+		raw, _ := m.led.GetState(sessionUsageKey(p.Account, p.Key))
+		if len(raw) > 0 {
+			var u SessionKeyUsage
+			if err := json.Unmarshal(raw, &u); err == nil {
+				if m.usage[p.Account] == nil {
+					m.usage[p.Account] = make(map[Address]*SessionKeyUsage)
+				}
+				m.usage[p.Account][p.Key] = &u
+			}
+		}
+	}
+	return it.Error()
+}
+
+func (p *SessionKeyPolicy) allowsContract(a Address) bool {
+	for _, c := range p.AllowedContracts {
+		if c == a {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *SessionKeyPolicy) allowsOpcode(op Opcode) bool {
+	for _, name := range p.AllowedOpcodes {
+		if o, ok := opcodeByName(name); ok && o == op {
+			return true
+		}
+	}
+	return false
+}
+
+// opcodeByName resolves a catalogue name to its opcode.
+func opcodeByName(name string) (Opcode, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	op, ok := nameToOp[name]
+	return op, ok
+}
+
+func sessionAudit(addr Address, event string, meta map[string]string) {
+	if am := AuditManagerInstance(); am != nil {
+		_ = am.Log(addr, event, meta)
+	}
+}
@@ -0,0 +1,181 @@
+package core
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+type sessionTest struct {
+	led     *Ledger
+	m       *SessionKeyManager
+	account Address
+	priv    *ecdsa.PrivateKey
+	key     Address
+	shop    Address
+}
+
+// newSessionTest authorises a session key for a funded account and makes
+// its manager the global one.
+func newSessionTest(t *testing.T, p SessionKeyPolicy) *sessionTest {
+	t.Helper()
+	led := newDepositLedger(t)
+	priv, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	st := &sessionTest{
+		led:     led,
+		account: Address{0xac},
+		priv:    priv,
+		key:     FromCommon(crypto.PubkeyToAddress(priv.PublicKey)),
+		shop:    Address{0x5b},
+		m: &SessionKeyManager{
+			led:      led,
+			policies: make(map[Address]map[Address]*SessionKeyPolicy),
+			usage:    make(map[Address]map[Address]*SessionKeyUsage),
+			pending:  make(map[Hash]sessionCharge),
+		},
+	}
+	prev := sessionMgr
+	sessionMgr = st.m
+	t.Cleanup(func() { sessionMgr = prev })
+	if err := led.Mint(st.account, 1_000); err != nil {
+		t.Fatal(err)
+	}
+	p.Account, p.Key = st.account, st.key
+	if p.Expires.IsZero() {
+		p.Expires = time.Now().Add(time.Hour)
+	}
+	if _, err := st.m.Authorise(st.account, p); err != nil {
+		t.Fatalf("authorise: %v", err)
+	}
+	return st
+}
+
+// signed signs tx for the account with the session key.
+func (st *sessionTest) signed(t *testing.T, tx *Transaction) *Transaction {
+	t.Helper()
+	tx.From = st.account
+	tx.HashTx()
+	sig, err := crypto.Sign(tx.Hash[:], st.priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx.Sig = sig
+	return tx
+}
+
+// block applies txs as the next block at time at.
+func (st *sessionTest) block(at time.Time, txs ...*Transaction) error {
+	return st.led.AddBlock(&Block{
+		Header:       BlockHeader{Height: uint64(len(st.led.Blocks)), Timestamp: at.UnixMilli()},
+		Transactions: txs,
+	})
+}
+
+func TestSessionKeyAllowanceIsReservedInThePoolAndChargedAtExecution(t *testing.T) {
+	st := newSessionTest(t, SessionKeyPolicy{MaxPerTx: 60, DailyAllowance: 100})
+	tp := NewTxPool(nil, nil, nil, nil, nil, 0)
+
+	pay := st.signed(t, &Transaction{Type: TxPayment, To: st.shop, Value: 60})
+	if err := tp.AddTx(pay); err != nil {
+		t.Fatalf("admit: %v", err)
+	}
+	if ok, _ := st.led.HasState(sessionUsageKey(st.account, st.key)); ok {
+		t.Fatal("pool admission wrote usage to ledger state")
+	}
+	// the calls of a multicall add up against the allowance
+	batch := st.signed(t, &Transaction{Type: TxMulticall, GasLimit: 2 * multicallCallGas,
+		Calls: []Call{{To: st.shop, Value: 30}, {To: st.shop, Value: 20}}})
+	if err := tp.AddTx(batch); !errors.Is(err, ErrSessionKeyPolicy) {
+		t.Fatalf("multicall over the remaining allowance: %v", err)
+	}
+	transfer := st.signed(t, &Transaction{Type: TxPayment, To: st.shop,
+		TokenTransfers: []TokenTransfer{{From: st.account, To: st.shop, Amount: 40}}})
+	if err := tp.AddTx(transfer); err != nil {
+		t.Fatalf("admit transfer: %v", err)
+	}
+	over := st.signed(t, &Transaction{Type: TxPayment, To: st.shop, Value: 61})
+	if err := st.m.CheckTx(over, st.key); !errors.Is(err, ErrSessionKeyPolicy) {
+		t.Fatalf("value above the per-tx limit: %v", err)
+	}
+
+	if err := st.block(time.Now(), transfer); err != nil {
+		t.Fatalf("block: %v", err)
+	}
+	if u := st.m.Usage(st.account, st.key); u.Spent != 40 {
+		t.Fatalf("usage after the first block: %+v", u)
+	}
+	if err := st.block(time.Now(), pay); err != nil {
+		t.Fatalf("block: %v", err)
+	}
+	if u := st.m.Usage(st.account, st.key); u.Spent != 100 || len(st.m.pending) != 0 {
+		t.Fatalf("usage %+v, %d reservations left", u, len(st.m.pending))
+	}
+	if ok, _ := st.led.HasState(sessionUsageKey(st.account, st.key)); !ok {
+		t.Fatal("executed spend not charged in ledger state")
+	}
+	last := st.signed(t, &Transaction{Type: TxPayment, To: st.shop, Value: 1})
+	if err := tp.AddTx(last); !errors.Is(err, ErrSessionKeyPolicy) {
+		t.Fatalf("spend after the allowance was exhausted: %v", err)
+	}
+	// a block carrying it anyway is refused
+	if err := st.block(time.Now(), last); !errors.Is(err, ErrSessionKeyPolicy) {
+		t.Fatalf("block exceeding the allowance: %v", err)
+	}
+}
+
+func TestSessionKeyPolicyCoversEveryTarget(t *testing.T) {
+	st := newSessionTest(t, SessionKeyPolicy{})
+	st.m.policies[st.account][st.key].AllowedContracts = []Address{st.shop}
+	other := Address{0x0b}
+
+	for name, tx := range map[string]*Transaction{
+		"recipient":      {To: other, Value: 1},
+		"token transfer": {To: st.shop, TokenTransfers: []TokenTransfer{{From: st.account, To: other, Amount: 1}}},
+		"multicall call": {Type: TxMulticall, Calls: []Call{{To: st.shop}, {To: other, Data: []byte{1}}}},
+		"foreign funds":  {To: st.shop, TokenTransfers: []TokenTransfer{{From: other, To: st.shop, Amount: 1}}},
+	} {
+		if err := st.m.CheckTx(st.signed(t, tx), st.key); !errors.Is(err, ErrSessionKeyPolicy) {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+	ok := st.signed(t, &Transaction{Type: TxMulticall, Calls: []Call{{To: st.shop, Value: 5}},
+		TokenTransfers: []TokenTransfer{{From: st.account, To: st.shop, Amount: 1}}})
+	if err := st.m.CheckTx(ok, st.key); err != nil {
+		t.Fatalf("allowed targets: %v", err)
+	}
+}
+
+func TestSessionKeyRevokedOrExpiredBeforeExecutionCannotSpend(t *testing.T) {
+	st := newSessionTest(t, SessionKeyPolicy{DailyAllowance: 100})
+	tp := NewTxPool(nil, nil, nil, nil, nil, 0)
+
+	pay := st.signed(t, &Transaction{Type: TxPayment, To: st.shop, Value: 10})
+	if err := tp.AddTx(pay); err != nil {
+		t.Fatalf("admit: %v", err)
+	}
+	// the key expires while its transaction waits for a block
+	if err := st.block(time.Now().Add(2*time.Hour), pay); !errors.Is(err, ErrSessionKeyExpired) {
+		t.Fatalf("block after expiry: %v", err)
+	}
+	if err := st.m.Revoke(st.account, st.account, st.key); err != nil {
+		t.Fatalf("revoke: %v", err)
+	}
+	if len(st.m.pending) != 0 {
+		t.Fatal("revocation left reservations behind")
+	}
+	if err := st.block(time.Now(), pay); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("block after revocation: %v", err)
+	}
+	if len(st.led.Blocks) != 0 || st.led.BalanceOf(st.account) != 1_000 {
+		t.Fatal("refused blocks changed state")
+	}
+	if err := tp.AddTx(st.signed(t, &Transaction{Type: TxPayment, To: st.shop, Value: 1})); err == nil {
+		t.Fatal("revoked key admitted to the pool")
+	}
+}
@@ -0,0 +1,411 @@
+package core
+
+// sidechain_checkpoints.go – periodic side-chain checkpoints with fraud
+// escalation.
+//
+// Every CheckpointEvery side-chain blocks the validator set must commit a
+// checkpoint (height, header hash, state root) signed with its aggregate BLS
+// key; SubmitHeader refuses to run past a missing checkpoint. Checkpoints are
+// anchored in main-chain state with the main-chain height they were
+// accepted at and stay challengeable for SidechainChallengeWindow.
+//
+// A dispute either carries a verifiable proof – a validly signed header
+// conflicting with the one the checkpoint covers (equivocation), which
+// invalidates the checkpoint at once – or evidence of an invalid state
+// transition that L1 cannot check itself, which escalates the dispute to the
+// authority nodes. While any dispute is pending the chain is frozen:
+// deposits, withdrawals, headers and checkpoints are refused. An invalidated
+// checkpoint rolls the chain back to the previous checkpoint and pauses it
+// for governance review.
+//
+// Withdrawals are only honoured against headers covered by a finalised
+// checkpoint.
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+const (
+	DefaultCheckpointInterval = 100
+	SidechainChallengeWindow  = 24 * time.Hour
+)
+
+// CheckpointStatus is the lifecycle stage of a checkpoint.
+type CheckpointStatus string
+
+const (
+	CheckpointPending     CheckpointStatus = "pending"
+	CheckpointDisputed    CheckpointStatus = "disputed"
+	CheckpointFinalized   CheckpointStatus = "finalized"
+	CheckpointInvalidated CheckpointStatus = "invalidated"
+)
+
+// Dispute kinds.
+const (
+	DisputeEquivocation = "equivocation"  // conflicting signed header, verified on L1
+	DisputeInvalidState = "invalid_state" // escalated to authority nodes
+)
+
+var ErrSidechainFrozen = errors.New("sidechain frozen by pending dispute")
+
+// CheckpointDispute records a challenge against a checkpoint.
+type CheckpointDispute struct {
+	Challenger  Address          `json:"challenger"`
+	Kind        string           `json:"kind"`
+	Conflicting *SidechainHeader `json:"conflicting,omitempty"`
+	Evidence    []byte           `json:"evidence,omitempty"`
+	Filed       int64            `json:"filed"`
+	Resolver    *Address         `json:"resolver,omitempty"`
+	Fraud       bool             `json:"fraud"`
+	Resolved    int64            `json:"resolved,omitempty"`
+}
+
+// SidechainCheckpoint commits a side-chain header to main-chain state.
+type SidechainCheckpoint struct {
+	ChainID       SidechainID        `json:"chain_id"`
+	Height        uint64             `json:"height"`
+	HeaderHash    Hash               `json:"header_hash"`
+	StateRoot     [32]byte           `json:"state_root"`
+	SigAgg        []byte             `json:"agg_sig"`
+	AnchorHeight  uint64             `json:"anchor_height"`
+	Submitted     int64              `json:"submitted"`
+	ChallengeEnds int64              `json:"challenge_ends"`
+	Status        CheckpointStatus   `json:"status"`
+	Dispute       *CheckpointDispute `json:"dispute,omitempty"`
+}
+
+// CheckpointDigest is the message the validator set signs for a checkpoint.
+func CheckpointDigest(chain SidechainID, height uint64, header Hash, stateRoot [32]byte) [32]byte {
+	buf := append([]byte("synnergy-sc-checkpoint"), uint32ToBytes(uint32(chain))...)
+	buf = append(buf, uint64ToBytes(height)...)
+	buf = append(buf, header[:]...)
+	buf = append(buf, stateRoot[:]...)
+	return sha256.Sum256(buf)
+}
+
+func checkpointKey(id SidechainID, h uint64) []byte {
+	b := append(uint32ToBytes(uint32(id)), uint64ToBytes(h)...)
+	return append([]byte("sc:ckpt:"), b...)
+}
+
+func (m Sidechain) checkpointInterval() uint64 {
+	if m.CheckpointEvery == 0 {
+		return DefaultCheckpointInterval
+	}
+	return m.CheckpointEvery
+}
+
+// frozen reports whether bridge traffic for the chain must be refused.
+func (m Sidechain) frozen() error {
+	if m.OpenDisputes > 0 {
+		return fmt.Errorf("%w: %d open", ErrSidechainFrozen, m.OpenDisputes)
+	}
+	return nil
+}
+
+// SigningHash is the hash validators sign for a header: the header with
+// its aggregate signature cleared. Checkpoints commit to it as well.
+func (h SidechainHeader) SigningHash() Hash {
+	h.SigAgg = nil
+	return hashHeader(mustJSON(h))
+}
+
+func mainChainHeight() uint64 {
+	if led := CurrentLedger(); led != nil {
+		return led.LastHeight()
+	}
+	return 0
+}
+
+// SetCheckpointInterval changes how many blocks may pass between
+// checkpoints. It takes effect from the next checkpoint.
+func (sc *SidechainCoordinator) SetCheckpointInterval(id SidechainID, every uint64) error {
+	if every == 0 {
+		return errors.New("interval must be positive")
+	}
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	meta, err := sc.getMeta(id)
+	if err != nil {
+		return err
+	}
+	meta.CheckpointEvery = every
+	return sc.Ledger.SetState(metaKey(id), mustJSON(meta))
+}
+
+// SubmitCheckpoint commits the next mandatory checkpoint. The header at the
+// checkpoint height must already be synced and the commitment must carry
+// the validators' aggregate signature over CheckpointDigest.
+func (sc *SidechainCoordinator) SubmitCheckpoint(cp SidechainCheckpoint) (SidechainCheckpoint, error) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	meta, err := sc.getMeta(cp.ChainID)
+	if err != nil {
+		return cp, err
+	}
+	if meta.Paused {
+		return cp, errors.New("sidechain paused")
+	}
+	if err := meta.frozen(); err != nil {
+		return cp, err
+	}
+	if want := meta.LastCheckpoint + meta.checkpointInterval(); cp.Height != want {
+		return cp, fmt.Errorf("next checkpoint is at height %d", want)
+	}
+	hdr, err := sc.GetHeader(cp.ChainID, cp.Height)
+	if err != nil {
+		return cp, err
+	}
+	if cp.HeaderHash != hdr.SigningHash() || cp.StateRoot != hdr.StateRoot {
+		return cp, errors.New("checkpoint does not match the synced header")
+	}
+	digest := CheckpointDigest(cp.ChainID, cp.Height, cp.HeaderHash, cp.StateRoot)
+	if !VerifyAggregateSig(meta.Validators, cp.SigAgg, digest[:]) {
+		return cp, errors.New("bad aggregate sig")
+	}
+	now := time.Now()
+	cp.AnchorHeight = mainChainHeight()
+	cp.Submitted = now.Unix()
+	cp.ChallengeEnds = now.Add(SidechainChallengeWindow).Unix()
+	cp.Status = CheckpointPending
+	cp.Dispute = nil
+	if err := sc.Ledger.SetState(checkpointKey(cp.ChainID, cp.Height), mustJSON(cp)); err != nil {
+		return cp, err
+	}
+	meta.LastCheckpoint = cp.Height
+	if err := sc.Ledger.SetState(metaKey(cp.ChainID), mustJSON(meta)); err != nil {
+		return cp, err
+	}
+	sc.Net.Broadcast("sidechain_checkpoint", mustJSON(cp))
+	return cp, nil
+}
+
+// GetCheckpoint returns the checkpoint at height.
+func (sc *SidechainCoordinator) GetCheckpoint(id SidechainID, height uint64) (SidechainCheckpoint, error) {
+	raw, _ := sc.Ledger.GetState(checkpointKey(id, height))
+	if len(raw) == 0 {
+		return SidechainCheckpoint{}, errors.New("checkpoint not found")
+	}
+	var cp SidechainCheckpoint
+	if err := json.Unmarshal(raw, &cp); err != nil {
+		return SidechainCheckpoint{}, err
+	}
+	return cp, nil
+}
+
+// ListCheckpoints returns a chain's checkpoints in height order.
+func (sc *SidechainCoordinator) ListCheckpoints(id SidechainID) ([]SidechainCheckpoint, error) {
+	it := sc.Ledger.PrefixIterator(append([]byte("sc:ckpt:"), uint32ToBytes(uint32(id))...))
+	var out []SidechainCheckpoint
+	for it.Next() {
+		var cp SidechainCheckpoint
+		if err := json.Unmarshal(it.Value(), &cp); err != nil {
+			return nil, err
+		}
+		out = append(out, cp)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Height < out[j].Height })
+	return out, nil
+}
+
+// FinalizeCheckpoints finalises, in height order, every pending checkpoint
+// whose challenge window has passed, stopping at the first that has not.
+// It returns the chain's finalised height.
+func (sc *SidechainCoordinator) FinalizeCheckpoints(id SidechainID) (uint64, error) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	return sc.finalizeCheckpoints(id)
+}
+
+func (sc *SidechainCoordinator) finalizeCheckpoints(id SidechainID) (uint64, error) {
+	meta, err := sc.getMeta(id)
+	if err != nil {
+		return 0, err
+	}
+	cps, err := sc.ListCheckpoints(id)
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now().Unix()
+	for _, cp := range cps {
+		if cp.Height <= meta.FinalizedHeight || cp.Status == CheckpointInvalidated {
+			continue
+		}
+		if cp.Status != CheckpointPending || now < cp.ChallengeEnds {
+			break
+		}
+		cp.Status = CheckpointFinalized
+		if err := sc.Ledger.SetState(checkpointKey(id, cp.Height), mustJSON(cp)); err != nil {
+			return meta.FinalizedHeight, err
+		}
+		meta.FinalizedHeight = cp.Height
+	}
+	return meta.FinalizedHeight, sc.Ledger.SetState(metaKey(id), mustJSON(meta))
+}
+
+// DisputeCheckpoint challenges a pending checkpoint within its window.
+// Equivocation disputes must carry a conflicting header signed by the
+// validator set at a height the checkpoint covers; a valid one invalidates
+// the checkpoint immediately and an invalid one is rejected. Invalid-state
+// disputes carry off-chain evidence, freeze the chain and await an
+// authority ruling. It reports whether the checkpoint was invalidated.
+func (sc *SidechainCoordinator) DisputeCheckpoint(id SidechainID, height uint64, challenger Address, kind string, conflicting *SidechainHeader, evidence []byte) (bool, error) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	meta, err := sc.getMeta(id)
+	if err != nil {
+		return false, err
+	}
+	cp, err := sc.GetCheckpoint(id, height)
+	if err != nil {
+		return false, err
+	}
+	if cp.Status != CheckpointPending || time.Now().Unix() >= cp.ChallengeEnds {
+		return false, errors.New("checkpoint not open to challenge")
+	}
+	d := &CheckpointDispute{Challenger: challenger, Kind: kind, Conflicting: conflicting, Evidence: evidence, Filed: time.Now().Unix()}
+	switch kind {
+	case DisputeEquivocation:
+		if err := sc.verifyEquivocation(meta, cp, conflicting); err != nil {
+			return false, fmt.Errorf("equivocation proof rejected: %w", err)
+		}
+		d.Fraud, d.Resolved = true, d.Filed
+		cp.Dispute = d
+		return true, sc.invalidateCheckpoint(&meta, cp)
+	case DisputeInvalidState:
+		if len(evidence) == 0 {
+			return false, errors.New("evidence required")
+		}
+		cp.Status, cp.Dispute = CheckpointDisputed, d
+		meta.OpenDisputes++
+		if err := sc.Ledger.SetState(checkpointKey(id, height), mustJSON(cp)); err != nil {
+			return false, err
+		}
+		sc.Net.Broadcast("sidechain_dispute", mustJSON(cp))
+		return false, sc.Ledger.SetState(metaKey(id), mustJSON(meta))
+	default:
+		return false, fmt.Errorf("unknown dispute kind %q", kind)
+	}
+}
+
+// verifyEquivocation checks that h is a validator-signed header, within the
+// range the checkpoint covers, that differs from the synced one.
+func (sc *SidechainCoordinator) verifyEquivocation(meta Sidechain, cp SidechainCheckpoint, h *SidechainHeader) error {
+	if h == nil || h.ChainID != cp.ChainID {
+		return errors.New("conflicting header required")
+	}
+	if h.Height > cp.Height || h.Height+meta.checkpointInterval() <= cp.Height {
+		return errors.New("header outside the checkpointed range")
+	}
+	hash := h.SigningHash()
+	if !VerifyAggregateSig(meta.Validators, h.SigAgg, hash[:]) {
+		return errors.New("header not signed by the validator set")
+	}
+	synced, err := sc.GetHeader(cp.ChainID, h.Height)
+	if err != nil {
+		return err
+	}
+	if synced.SigningHash() == hash {
+		return errors.New("header matches the synced header")
+	}
+	return nil
+}
+
+// ResolveDispute records an authority node's ruling on an escalated
+// dispute. Fraud invalidates the checkpoint; otherwise it returns to
+// pending and finalises once its window has passed.
+func (sc *SidechainCoordinator) ResolveDispute(id SidechainID, height uint64, resolver Address, fraud bool) error {
+	auth := CurrentAuthoritySet()
+	if auth == nil || !auth.IsAuthority(resolver) {
+		return ErrUnauthorized
+	}
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	meta, err := sc.getMeta(id)
+	if err != nil {
+		return err
+	}
+	cp, err := sc.GetCheckpoint(id, height)
+	if err != nil {
+		return err
+	}
+	if cp.Status != CheckpointDisputed {
+		return errors.New("checkpoint not disputed")
+	}
+	cp.Dispute.Resolver, cp.Dispute.Fraud, cp.Dispute.Resolved = &resolver, fraud, time.Now().Unix()
+	meta.OpenDisputes--
+	if fraud {
+		return sc.invalidateCheckpoint(&meta, cp)
+	}
+	cp.Status = CheckpointPending
+	if err := sc.Ledger.SetState(checkpointKey(id, height), mustJSON(cp)); err != nil {
+		return err
+	}
+	return sc.Ledger.SetState(metaKey(id), mustJSON(meta))
+}
+
+// invalidateCheckpoint rolls the chain back to the checkpoint before cp,
+// discarding later headers and checkpoints, and pauses it for review.
+func (sc *SidechainCoordinator) invalidateCheckpoint(meta *Sidechain, cp SidechainCheckpoint) error {
+	cps, err := sc.ListCheckpoints(meta.ID)
+	if err != nil {
+		return err
+	}
+	var prev uint64
+	for _, c := range cps {
+		switch {
+		case c.Height < cp.Height && c.Status != CheckpointInvalidated:
+			prev = c.Height
+		case c.Height > cp.Height && c.Status == CheckpointDisputed:
+			meta.OpenDisputes--
+			fallthrough
+		case c.Height > cp.Height:
+			c.Status = CheckpointInvalidated
+			if err := sc.Ledger.SetState(checkpointKey(meta.ID, c.Height), mustJSON(c)); err != nil {
+				return err
+			}
+		}
+	}
+	cp.Status = CheckpointInvalidated
+	if err := sc.Ledger.SetState(checkpointKey(meta.ID, cp.Height), mustJSON(cp)); err != nil {
+		return err
+	}
+	for h := meta.LastHeight; h > prev; h-- {
+		_ = sc.Ledger.DeleteState(headerKey(meta.ID, h))
+	}
+	meta.LastHeight, meta.LastCheckpoint = prev, prev
+	meta.LastRoot = [32]byte{}
+	if hdr, err := sc.GetHeader(meta.ID, prev); err == nil {
+		meta.LastRoot = hdr.StateRoot
+	}
+	meta.Paused = true
+	sc.Net.Broadcast("sidechain_checkpoint_invalidated", mustJSON(cp))
+	return sc.Ledger.SetState(metaKey(meta.ID), mustJSON(*meta))
+}
+
+// checkpointed reports whether a withdrawal header is covered by a
+// finalised checkpoint and matches the synced header at its height.
+func (sc *SidechainCoordinator) checkpointed(h SidechainHeader) error {
+	sc.mu.Lock()
+	final, err := sc.finalizeCheckpoints(h.ChainID)
+	sc.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if h.Height > final {
+		return fmt.Errorf("header %d not covered by a finalised checkpoint (finalised to %d)", h.Height, final)
+	}
+	synced, err := sc.GetHeader(h.ChainID, h.Height)
+	if err != nil {
+		return err
+	}
+	if synced.SigningHash() != h.SigningHash() {
+		return errors.New("header differs from the checkpointed chain")
+	}
+	return nil
+}
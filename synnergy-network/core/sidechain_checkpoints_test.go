@@ -0,0 +1,154 @@
+package core
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	bls "github.com/herumi/bls-eth-go-binary/bls"
+)
+
+// scTestState is the subset of StateRW used by the sidechain coordinator.
+type scTestState struct {
+	StateRW
+	kv map[string][]byte
+}
+
+func (s *scTestState) GetState(k []byte) ([]byte, error) { return s.kv[string(k)], nil }
+func (s *scTestState) SetState(k, v []byte) error        { s.kv[string(k)] = v; return nil }
+func (s *scTestState) DeleteState(k []byte) error        { delete(s.kv, string(k)); return nil }
+func (s *scTestState) HasState(k []byte) (bool, error) {
+	_, ok := s.kv[string(k)]
+	return ok, nil
+}
+func (s *scTestState) PrefixIterator(prefix []byte) StateIterator {
+	it := &plasmaTestIter{idx: -1}
+	for k, v := range s.kv {
+		if strings.HasPrefix(k, string(prefix)) {
+			it.keys = append(it.keys, k)
+			it.vals = append(it.vals, v)
+		}
+	}
+	return it
+}
+
+type scTestChain struct {
+	t  *testing.T
+	sc *SidechainCoordinator
+	sk bls.SecretKey
+}
+
+func newSCTestChain(t *testing.T, every uint64) *scTestChain {
+	c := &scTestChain{t: t, sc: &SidechainCoordinator{Ledger: &scTestState{kv: map[string][]byte{}}}}
+	c.sk.SetByCSPRNG()
+	if err := c.sc.Register(1, "test", 67, [][]byte{c.sk.GetPublicKey().Serialize()}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.sc.SetCheckpointInterval(1, every); err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func (c *scTestChain) header(h uint64, fork byte) SidechainHeader {
+	hdr := SidechainHeader{ChainID: 1, Height: h, StateRoot: [32]byte{fork, byte(h)}, TxRoot: [32]byte{byte(h)}}
+	hash := hdr.SigningHash()
+	hdr.SigAgg = c.sk.SignByte(hash[:]).Serialize()
+	return hdr
+}
+
+func (c *scTestChain) sync(from, to uint64) {
+	c.t.Helper()
+	for h := from; h <= to; h++ {
+		if err := c.sc.SubmitHeader(c.header(h, 0)); err != nil {
+			c.t.Fatalf("header %d: %v", h, err)
+		}
+	}
+}
+
+func (c *scTestChain) checkpoint(h uint64) {
+	c.t.Helper()
+	hdr, err := c.sc.GetHeader(1, h)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	cp := SidechainCheckpoint{ChainID: 1, Height: h, HeaderHash: hdr.SigningHash(), StateRoot: hdr.StateRoot}
+	d := CheckpointDigest(1, h, cp.HeaderHash, cp.StateRoot)
+	cp.SigAgg = c.sk.SignByte(d[:]).Serialize()
+	if _, err := c.sc.SubmitCheckpoint(cp); err != nil {
+		c.t.Fatalf("checkpoint %d: %v", h, err)
+	}
+}
+
+func TestSidechainCheckpointGate(t *testing.T) {
+	c := newSCTestChain(t, 3)
+	c.sync(1, 3)
+	if err := c.sc.SubmitHeader(c.header(4, 0)); err == nil {
+		t.Fatal("header past a missing checkpoint accepted")
+	}
+	c.checkpoint(3)
+	c.sync(4, 6)
+
+	if err := c.sc.checkpointed(c.header(2, 0)); err == nil {
+		t.Fatal("withdraw header accepted inside the challenge window")
+	}
+	cp, _ := c.sc.GetCheckpoint(1, 3)
+	cp.ChallengeEnds = 0
+	c.sc.Ledger.SetState(checkpointKey(1, 3), mustJSON(cp))
+	if h, err := c.sc.FinalizeCheckpoints(1); err != nil || h != 3 {
+		t.Fatalf("finalised to %d: %v", h, err)
+	}
+	if err := c.sc.checkpointed(c.header(2, 0)); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.sc.checkpointed(c.header(2, 1)); err == nil {
+		t.Fatal("forked header accepted for withdrawal")
+	}
+}
+
+func TestSidechainDisputes(t *testing.T) {
+	c := newSCTestChain(t, 3)
+	c.sync(1, 3)
+	c.checkpoint(3)
+	c.sync(4, 6)
+	c.checkpoint(6)
+
+	// Invalid-state disputes freeze the bridge until resolved.
+	if _, err := c.sc.DisputeCheckpoint(1, 6, Address{9}, DisputeInvalidState, nil, []byte("fraud")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.sc.Deposit(1, Address{1}, nil, 1, 1); !errors.Is(err, ErrSidechainFrozen) {
+		t.Fatalf("deposit during dispute: %v", err)
+	}
+	if err := c.sc.SubmitHeader(c.header(7, 0)); err == nil {
+		t.Fatal("header accepted during dispute")
+	}
+
+	// An identical header is not proof of equivocation.
+	c2 := newSCTestChain(t, 3)
+	c2.sync(1, 3)
+	c2.checkpoint(3)
+	same := c2.header(2, 0)
+	if _, err := c2.sc.DisputeCheckpoint(1, 3, Address{9}, DisputeEquivocation, &same, nil); err == nil {
+		t.Fatal("matching header accepted as equivocation")
+	}
+
+	// A conflicting signed header invalidates the checkpoint and rolls back.
+	c2.sync(4, 6)
+	c2.checkpoint(6)
+	fork := c2.header(5, 1)
+	ok, err := c2.sc.DisputeCheckpoint(1, 6, Address{9}, DisputeEquivocation, &fork, nil)
+	if err != nil || !ok {
+		t.Fatalf("equivocation: %v %v", ok, err)
+	}
+	meta, _ := c2.sc.GetMeta(1)
+	if meta.LastHeight != 3 || meta.LastCheckpoint != 3 || !meta.Paused {
+		t.Fatalf("meta after rollback: %+v", meta)
+	}
+	if cp, _ := c2.sc.GetCheckpoint(1, 6); cp.Status != CheckpointInvalidated {
+		t.Fatalf("status %s", cp.Status)
+	}
+	if _, err := c2.sc.GetHeader(1, 4); err == nil {
+		t.Fatal("rolled-back header still stored")
+	}
+}
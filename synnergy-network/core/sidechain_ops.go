@@ -52,8 +52,8 @@ func (sc *SidechainCoordinator) UpdateSidechainValidators(id SidechainID, thresh
 	return sc.Ledger.SetState(metaKey(id), mustJSON(meta))
 }
 
-// RemoveSidechain deletes all metadata and pending deposits/headers for the
-// given sidechain. This operation is irreversible.
+// RemoveSidechain deletes all metadata, checkpoints and pending
+// deposits/headers for the given sidechain. This operation is irreversible.
 func (sc *SidechainCoordinator) RemoveSidechain(id SidechainID) error {
 	sc.mu.Lock()
 	defer sc.mu.Unlock()
@@ -73,5 +73,10 @@ func (sc *SidechainCoordinator) RemoveSidechain(id SidechainID) error {
 	for it.Next() {
 		_ = sc.Ledger.DeleteState(it.Key())
 	}
+	ckptPrefix := append([]byte("sc:ckpt:"), uint32ToBytes(uint32(id))...)
+	it = sc.Ledger.PrefixIterator(ckptPrefix)
+	for it.Next() {
+		_ = sc.Ledger.DeleteState(it.Key())
+	}
 	return nil
 }
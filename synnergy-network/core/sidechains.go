@@ -7,7 +7,8 @@ package core
 //   • Allow application‑specific roll‑ups or EVM chains to interoperate with the
 //     Synnergy L1 without bloating core consensus.
 //   • Bridge is *optimistic*: validator threshold signatures on each side‑chain
-//     header; fraud proofs handled by off‑chain challenge courts (future work).
+//     header, with periodic checkpoints open to fraud disputes
+//     (sidechain_checkpoints.go).
 //
 // Key Concepts
 // ------------
@@ -64,7 +65,7 @@ func (sc *SidechainCoordinator) Register(id SidechainID, name string, threshold
 	if threshold == 0 || threshold > 100 {
 		return errors.New("invalid threshold")
 	}
-	meta := Sidechain{ID: id, Name: name, Threshold: threshold, Validators: validators, Registered: time.Now().Unix()}
+	meta := Sidechain{ID: id, Name: name, Threshold: threshold, Validators: validators, Registered: time.Now().Unix(), CheckpointEvery: DefaultCheckpointInterval}
 	if exists, _ := sc.Ledger.HasState(metaKey(id)); exists {
 		return errors.New("duplicate id")
 	}
@@ -82,16 +83,20 @@ func (sc *SidechainCoordinator) SubmitHeader(h SidechainHeader) error {
 		return err
 	}
 
+	if meta.Paused {
+		return errors.New("sidechain paused")
+	}
+	if err := meta.frozen(); err != nil {
+		return err
+	}
 	if h.Height != meta.LastHeight+1 {
 		return fmt.Errorf("non‑sequential height: got %d want %d", h.Height, meta.LastHeight+1)
 	}
-
-	hdrBytes, err := json.Marshal(h)
-	if err != nil {
-		return fmt.Errorf("failed to encode header: %w", err)
+	if due := meta.LastCheckpoint + meta.checkpointInterval(); h.Height > due {
+		return fmt.Errorf("checkpoint at height %d required before further headers", due)
 	}
 
-	hdrHash := hashHeader(hdrBytes)
+	hdrHash := h.SigningHash()
 	if !VerifyAggregateSig(meta.Validators, h.SigAgg, hdrHash[:]) {
 		return errors.New("bad aggregate sig")
 	}
@@ -112,6 +117,13 @@ func (sc *SidechainCoordinator) Deposit(chain SidechainID, from Address, to []by
 	if amount == 0 {
 		return DepositReceipt{}, errors.New("zero amount")
 	}
+	meta, err := sc.getMeta(chain)
+	if err != nil {
+		return DepositReceipt{}, err
+	}
+	if err := meta.frozen(); err != nil {
+		return DepositReceipt{}, err
+	}
 	// escrow: transfer from user to bridge account
 	bridgeAcct := sidechainBridgeAccount(chain, token)
 	tok, ok := GetToken(token)
@@ -179,17 +191,19 @@ func (sc *SidechainCoordinator) VerifyWithdraw(p WithdrawProof) error {
 	if err != nil {
 		return err
 	}
-
-	hdrBytes, err := json.Marshal(p.Header)
-	if err != nil {
-		return fmt.Errorf("failed to encode header: %w", err)
+	if err := meta.frozen(); err != nil {
+		return err
 	}
 
-	hdrHash := hashHeader(hdrBytes)
+	hdrHash := p.Header.SigningHash()
 	if !VerifyAggregateSig(meta.Validators, p.Header.SigAgg, hdrHash[:]) {
 		return errors.New("sig")
 	}
 
+	if err := sc.checkpointed(p.Header); err != nil {
+		return err
+	}
+
 	// 2. Merkle proof inclusion
 	if !VerifyMerkleProof(p.Header.TxRoot[:], p.TxData, p.Proof, p.TxIndex) {
 		return errors.New("merkle fail")
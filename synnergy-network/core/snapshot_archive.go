@@ -0,0 +1,328 @@
+package core
+
+// snapshot_archive.go – portable ledger snapshots for node migration.
+//
+// ExportSnapshot writes a gzip-compressed tar archive holding three entries:
+//
+//	manifest.json  format version, height, head hash, state root and the
+//	               SHA-256 of every other entry
+//	state.json     state, UTXO set, contracts and coin balances
+//	headers.json   the most recent block headers, oldest first
+//
+// ImportSnapshot verifies the entry checksums, the header chain, the state
+// root recorded in the manifest and the state root the operator trusts, and
+// then writes a ledger directory (ledger.snap plus an empty ledger.wal) that
+// OpenLedger boots from. Only headers are carried, so the new node continues
+// from the snapshot height without the historic block bodies.
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// SnapshotFormatVersion is the archive format written by ExportSnapshot.
+const SnapshotFormatVersion = 1
+
+// DefaultSnapshotHeaders is how many recent headers an export carries.
+const DefaultSnapshotHeaders = 256
+
+const (
+	snapshotManifestEntry = "manifest.json"
+	snapshotStateEntry    = "state.json"
+	snapshotHeadersEntry  = "headers.json"
+	snapshotMaxEntry      = 4 << 30
+)
+
+// ErrSnapshotRoot is returned when an archive does not match the trusted
+// state root.
+var ErrSnapshotRoot = errors.New("snapshot state root mismatch")
+
+// SnapshotManifest describes a portable snapshot archive.
+type SnapshotManifest struct {
+	Version   int               `json:"version"`
+	Height    uint64            `json:"height"`
+	BlockHash string            `json:"block_hash"`
+	StateRoot string            `json:"state_root"`
+	Headers   int               `json:"headers"`
+	CreatedAt time.Time         `json:"created_at"`
+	Checksums map[string]string `json:"checksums"` // entry -> sha256 hex
+}
+
+// snapshotState is the portable subset of the ledger persisted by a node.
+type snapshotState struct {
+	State         map[string][]byte            `json:"state"`
+	UTXO          map[string]UTXO              `json:"utxo"`
+	Contracts     map[string]Contract          `json:"contracts"`
+	Balances      map[BalanceKey]uint64        `json:"balances"`
+	Supply        map[LedgerAsset]SupplyTotals `json:"supply,omitempty"`
+	TokenBalances map[string]uint64            `json:"token_balances,omitempty"` // archives written before typed keys
+	NodeLocations map[NodeID]Location          `json:"node_locations,omitempty"`
+}
+
+// ExportSnapshot writes the ledger state at height together with up to
+// headers recent block headers to w. The ledger only keeps state at its
+// head, so height must be 0 (meaning the head) or the current height.
+func (l *Ledger) ExportSnapshot(w io.Writer, height uint64, headers int) (*SnapshotManifest, error) {
+	if headers <= 0 {
+		headers = DefaultSnapshotHeaders
+	}
+	l.mu.RLock()
+	if len(l.Blocks) == 0 {
+		l.mu.RUnlock()
+		return nil, fmt.Errorf("ledger has no blocks")
+	}
+	head := l.Blocks[len(l.Blocks)-1]
+	if height != 0 && height != head.Header.Height {
+		l.mu.RUnlock()
+		return nil, fmt.Errorf("state is only available at head height %d", head.Header.Height)
+	}
+	start := len(l.Blocks) - headers
+	if start < 0 {
+		start = 0
+	}
+	hdrs := make([]BlockHeader, 0, len(l.Blocks)-start)
+	for _, b := range l.Blocks[start:] {
+		hdrs = append(hdrs, b.Header)
+	}
+	stateRaw, err := json.Marshal(snapshotState{
+		State:         l.State,
+		UTXO:          l.UTXO,
+		Contracts:     l.Contracts,
+		Balances:      l.Balances,
+		Supply:        l.Supply,
+		NodeLocations: l.NodeLocations,
+	})
+	root := stateRoot(l.State)
+	l.mu.RUnlock()
+	if err != nil {
+		return nil, fmt.Errorf("encode state: %w", err)
+	}
+	hdrRaw, err := json.Marshal(hdrs)
+	if err != nil {
+		return nil, fmt.Errorf("encode headers: %w", err)
+	}
+
+	headHash := head.Hash()
+	man := &SnapshotManifest{
+		Version:   SnapshotFormatVersion,
+		Height:    head.Header.Height,
+		BlockHash: hex.EncodeToString(headHash[:]),
+		StateRoot: hex.EncodeToString(root[:]),
+		Headers:   len(hdrs),
+		CreatedAt: time.Now().UTC(),
+		Checksums: map[string]string{
+			snapshotStateEntry:   snapshotChecksum(stateRaw),
+			snapshotHeadersEntry: snapshotChecksum(hdrRaw),
+		},
+	}
+	manRaw, err := json.MarshalIndent(man, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	for _, e := range []struct {
+		name string
+		data []byte
+	}{
+		{snapshotManifestEntry, manRaw},
+		{snapshotStateEntry, stateRaw},
+		{snapshotHeadersEntry, hdrRaw},
+	} {
+		hdr := &tar.Header{Name: e.name, Mode: 0o600, Size: int64(len(e.data)), ModTime: man.CreatedAt}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return nil, err
+		}
+		if _, err := tw.Write(e.data); err != nil {
+			return nil, err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return man, nil
+}
+
+// ExportSnapshotFile writes a snapshot archive to path and returns its
+// manifest and the SHA-256 of the archive file.
+func (l *Ledger) ExportSnapshotFile(path string, height uint64, headers int) (*SnapshotManifest, string, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return nil, "", err
+	}
+	h := sha256.New()
+	man, err := l.ExportSnapshot(io.MultiWriter(f, h), height, headers)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		_ = os.Remove(path)
+		return nil, "", err
+	}
+	return man, hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// ReadSnapshotManifest verifies an archive and returns its manifest without
+// importing it.
+func ReadSnapshotManifest(path string) (*SnapshotManifest, error) {
+	man, _, _, err := readSnapshotArchive(path)
+	return man, err
+}
+
+// ImportSnapshot verifies the archive at path against trustedRoot and writes
+// a ledger directory at dir from which OpenLedger boots a node at the
+// snapshot height. dir must not already contain a ledger.
+func ImportSnapshot(path, dir string, trustedRoot Hash) (*SnapshotManifest, error) {
+	man, st, hdrs, err := readSnapshotArchive(path)
+	if err != nil {
+		return nil, err
+	}
+	if want := hex.EncodeToString(trustedRoot[:]); man.StateRoot != want {
+		return nil, fmt.Errorf("%w: archive %s, trusted %s", ErrSnapshotRoot, man.StateRoot, want)
+	}
+
+	snap := filepath.Join(dir, "ledger.snap")
+	wal := filepath.Join(dir, "ledger.wal")
+	for _, p := range []string{snap, wal} {
+		if _, err := os.Stat(p); err == nil {
+			return nil, fmt.Errorf("%s already exists", p)
+		}
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+
+	blocks := make([]*Block, len(hdrs))
+	for i := range hdrs {
+		blocks[i] = &Block{Header: hdrs[i]}
+	}
+	l := &Ledger{
+		Blocks:        blocks,
+		State:         st.State,
+		UTXO:          st.UTXO,
+		TxPool:        make(map[string]*Transaction),
+		Contracts:     st.Contracts,
+		Balances:      st.Balances,
+		Supply:        st.Supply,
+		NodeLocations: st.NodeLocations,
+	}
+	if err := l.adoptLegacyBalances(st.TokenBalances); err != nil {
+		return nil, fmt.Errorf("migrate snapshot balances: %w", err)
+	}
+	l.backfillSupply()
+	raw, err := json.Marshal(l)
+	if err != nil {
+		return nil, err
+	}
+	tmp := snap + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0o600); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmp, snap); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(wal, nil, 0o600); err != nil {
+		return nil, err
+	}
+	return man, nil
+}
+
+// readSnapshotArchive decodes and fully verifies an archive: entry
+// checksums, header linkage, head hash and the manifest state root.
+func readSnapshotArchive(path string) (*SnapshotManifest, *snapshotState, []BlockHeader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("not a snapshot archive: %w", err)
+	}
+	defer gz.Close()
+
+	entries := make(map[string][]byte)
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("read archive: %w", err)
+		}
+		if hdr.Size > snapshotMaxEntry {
+			return nil, nil, nil, fmt.Errorf("entry %s too large", hdr.Name)
+		}
+		data, err := io.ReadAll(io.LimitReader(tr, snapshotMaxEntry))
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		entries[hdr.Name] = data
+	}
+
+	var man SnapshotManifest
+	if err := json.Unmarshal(entries[snapshotManifestEntry], &man); err != nil {
+		return nil, nil, nil, fmt.Errorf("manifest: %w", err)
+	}
+	if man.Version != SnapshotFormatVersion {
+		return nil, nil, nil, fmt.Errorf("unsupported snapshot version %d", man.Version)
+	}
+	for _, name := range []string{snapshotStateEntry, snapshotHeadersEntry} {
+		data, ok := entries[name]
+		if !ok {
+			return nil, nil, nil, fmt.Errorf("archive missing %s", name)
+		}
+		if sum := snapshotChecksum(data); sum != man.Checksums[name] {
+			return nil, nil, nil, fmt.Errorf("%s checksum mismatch", name)
+		}
+	}
+
+	var hdrs []BlockHeader
+	if err := json.Unmarshal(entries[snapshotHeadersEntry], &hdrs); err != nil {
+		return nil, nil, nil, fmt.Errorf("headers: %w", err)
+	}
+	if len(hdrs) == 0 || len(hdrs) != man.Headers {
+		return nil, nil, nil, fmt.Errorf("archive has %d headers, manifest says %d", len(hdrs), man.Headers)
+	}
+	for i := 1; i < len(hdrs); i++ {
+		prev := (&Block{Header: hdrs[i-1]}).Hash()
+		if hdrs[i].Height != hdrs[i-1].Height+1 || !bytes.Equal(hdrs[i].PrevHash, prev[:]) {
+			return nil, nil, nil, fmt.Errorf("header chain broken at height %d", hdrs[i].Height)
+		}
+	}
+	last := hdrs[len(hdrs)-1]
+	headHash := (&Block{Header: last}).Hash()
+	if last.Height != man.Height || hex.EncodeToString(headHash[:]) != man.BlockHash {
+		return nil, nil, nil, fmt.Errorf("head header does not match manifest")
+	}
+
+	var st snapshotState
+	if err := json.Unmarshal(entries[snapshotStateEntry], &st); err != nil {
+		return nil, nil, nil, fmt.Errorf("state: %w", err)
+	}
+	root := stateRoot(st.State)
+	if hex.EncodeToString(root[:]) != man.StateRoot {
+		return nil, nil, nil, fmt.Errorf("%w: state hashes to %x, manifest says %s", ErrSnapshotRoot, root, man.StateRoot)
+	}
+	return &man, &st, hdrs, nil
+}
+
+func snapshotChecksum(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,408 @@
+package core
+
+// social_recovery.go – guardian-based account recovery.
+//
+// An account owner registers N guardian addresses, an M-of-N approval
+// threshold and a recovery delay. Any guardian may open a request naming a
+// new signing address; once M current guardians approve, the request is
+// timelocked for the configured delay, during which the account's current
+// signer can cancel it. After the delay the request is executed and the
+// account's signing key is rotated: transactions for the account must from
+// then on be signed by the new key (see AccountSigner and VerifySig). Every
+// session key of the account is revoked with the rotation, since they were
+// authorised by the key being replaced.
+//
+// Ledger layout:
+//   socialrec:config:<account>      -> SocialRecoveryConfig
+//   socialrec:request:<id>          -> SocialRecoveryRequest
+//   socialrec:signer:<account>      -> SignerRotation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultRecoveryDelay applies when an owner registers without a delay.
+const DefaultRecoveryDelay = 48 * time.Hour
+
+var (
+	ErrNotGuardian      = errors.New("caller is not a guardian of the account")
+	ErrRecoveryTimelock = errors.New("recovery timelock has not elapsed")
+)
+
+// SocialRecoveryConfig is an account's guardian set.
+type SocialRecoveryConfig struct {
+	Account   Address       `json:"account"`
+	Guardians []Address     `json:"guardians"`
+	Threshold int           `json:"threshold"`
+	Delay     time.Duration `json:"delay"`
+	Updated   time.Time     `json:"updated"`
+}
+
+// RecoveryStatus tracks a social recovery request.
+type RecoveryStatus string
+
+const (
+	RecoveryPending   RecoveryStatus = "pending"
+	RecoveryApproved  RecoveryStatus = "approved" // threshold met, timelocked
+	RecoveryExecuted  RecoveryStatus = "executed"
+	RecoveryCancelled RecoveryStatus = "cancelled"
+)
+
+// SocialRecoveryRequest asks to rotate an account's signer to NewSigner.
+type SocialRecoveryRequest struct {
+	ID        string               `json:"id"`
+	Account   Address              `json:"account"`
+	NewSigner Address              `json:"new_signer"`
+	Initiator Address              `json:"initiator"`
+	Approvals map[string]time.Time `json:"approvals"` // guardian hex -> time
+	Status    RecoveryStatus       `json:"status"`
+	Created   time.Time            `json:"created"`
+	UnlockAt  time.Time            `json:"unlock_at,omitempty"`
+	Finalised time.Time            `json:"finalised,omitempty"`
+}
+
+// SignerRotation records the signer an account was rotated to.
+type SignerRotation struct {
+	Account Address   `json:"account"`
+	Signer  Address   `json:"signer"`
+	Request string    `json:"request"`
+	Time    time.Time `json:"time"`
+}
+
+// SocialRecovery manages guardian sets, requests and rotated signers.
+// Rotated signers are cached in memory so signature checks never take the
+// ledger lock.
+type SocialRecovery struct {
+	mu      sync.RWMutex
+	led     *Ledger
+	signers map[Address]Address
+	reqMu   sync.Mutex // serialises read-modify-write of requests
+}
+
+var (
+	socialRecOnce sync.Once
+	socialRec     *SocialRecovery
+)
+
+// InitSocialRecovery loads rotated signers from the ledger. Subsequent calls
+// are ignored.
+func InitSocialRecovery(led *Ledger) (*SocialRecovery, error) {
+	var err error
+	socialRecOnce.Do(func() {
+		s := &SocialRecovery{led: led, signers: make(map[Address]Address)}
+		if err = s.load(); err == nil {
+			socialRec = s
+		}
+	})
+	return socialRec, err
+}
+
+// SocialRecoveryManager returns the global manager or nil if not initialised.
+func SocialRecoveryManager() *SocialRecovery { return socialRec }
+
+// AccountSigner returns the address whose key currently signs for addr. It
+// is addr itself unless the account has been recovered.
+func AccountSigner(addr Address) Address {
+	if s := SocialRecoveryManager(); s != nil {
+		return s.Signer(addr)
+	}
+	return addr
+}
+
+func socialConfigKey(a Address) []byte  { return []byte("socialrec:config:" + a.Hex()) }
+func socialRequestKey(id string) []byte { return []byte("socialrec:request:" + id) }
+func socialSignerKey(a Address) []byte  { return []byte("socialrec:signer:" + a.Hex()) }
+
+// Signer returns the current signer of account.
+func (s *SocialRecovery) Signer(account Address) Address {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if a, ok := s.signers[account]; ok {
+		return a
+	}
+	return account
+}
+
+// RegisterRecovery sets the guardian set of account. caller must be the
+// account's current signer.
+func (s *SocialRecovery) RegisterRecovery(caller, account Address, guardians []Address, threshold int, delay time.Duration) (*SocialRecoveryConfig, error) {
+	if caller != s.Signer(account) {
+		return nil, ErrUnauthorized
+	}
+	seen := make(map[Address]struct{}, len(guardians))
+	for _, g := range guardians {
+		if g == AddressZero || g == account || g == caller {
+			return nil, fmt.Errorf("invalid guardian %s", g.Hex())
+		}
+		if _, dup := seen[g]; dup {
+			return nil, fmt.Errorf("duplicate guardian %s", g.Hex())
+		}
+		seen[g] = struct{}{}
+	}
+	if threshold < 1 || threshold > len(guardians) {
+		return nil, fmt.Errorf("threshold must be between 1 and %d", len(guardians))
+	}
+	if delay <= 0 {
+		delay = DefaultRecoveryDelay
+	}
+	cfg := &SocialRecoveryConfig{
+		Account:   account,
+		Guardians: guardians,
+		Threshold: threshold,
+		Delay:     delay,
+		Updated:   time.Now().UTC(),
+	}
+	raw, _ := json.Marshal(cfg)
+	if err := s.led.SetState(socialConfigKey(account), raw); err != nil {
+		return nil, err
+	}
+	recoveryAudit(account, "social_recovery_registered", map[string]string{
+		"guardians": fmt.Sprint(len(guardians)),
+		"threshold": fmt.Sprint(threshold),
+		"delay":     delay.String(),
+	})
+	return cfg, nil
+}
+
+// Config returns the guardian set of account.
+func (s *SocialRecovery) Config(account Address) (*SocialRecoveryConfig, error) {
+	raw, err := s.led.GetState(socialConfigKey(account))
+	if err != nil || len(raw) == 0 {
+		return nil, ErrNotFound
+	}
+	var cfg SocialRecoveryConfig
+	if err := json.Unmarshal(raw, &cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// InitiateRecovery opens a request to rotate account's signer to newSigner.
+// The initiating guardian's approval is recorded immediately.
+func (s *SocialRecovery) InitiateRecovery(guardian, account, newSigner Address) (*SocialRecoveryRequest, error) {
+	cfg, err := s.Config(account)
+	if err != nil {
+		return nil, err
+	}
+	if !cfg.isGuardian(guardian) {
+		return nil, ErrNotGuardian
+	}
+	if newSigner == AddressZero || newSigner == s.Signer(account) {
+		return nil, errors.New("new signer must differ from the current signer")
+	}
+	s.reqMu.Lock()
+	defer s.reqMu.Unlock()
+	req := &SocialRecoveryRequest{
+		ID:        uuid.New().String(),
+		Account:   account,
+		NewSigner: newSigner,
+		Initiator: guardian,
+		Approvals: map[string]time.Time{},
+		Status:    RecoveryPending,
+		Created:   time.Now().UTC(),
+	}
+	if err := s.approve(cfg, req, guardian); err != nil {
+		return nil, err
+	}
+	Broadcast("recovery:initiated", mustJSON(req))
+	return req, nil
+}
+
+// ApproveRecovery records guardian's approval. When the threshold is met the
+// request becomes executable after the configured delay.
+func (s *SocialRecovery) ApproveRecovery(guardian Address, id string) (*SocialRecoveryRequest, error) {
+	s.reqMu.Lock()
+	defer s.reqMu.Unlock()
+	req, err := s.Request(id)
+	if err != nil {
+		return nil, err
+	}
+	if req.Status != RecoveryPending && req.Status != RecoveryApproved {
+		return nil, ErrInvalidState
+	}
+	cfg, err := s.Config(req.Account)
+	if err != nil {
+		return nil, err
+	}
+	if !cfg.isGuardian(guardian) {
+		return nil, ErrNotGuardian
+	}
+	if err := s.approve(cfg, req, guardian); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// CancelRecovery aborts a request. Only the account's current signer may
+// cancel, which lets the owner veto a hostile recovery during the delay.
+func (s *SocialRecovery) CancelRecovery(caller Address, id string) error {
+	s.reqMu.Lock()
+	defer s.reqMu.Unlock()
+	req, err := s.Request(id)
+	if err != nil {
+		return err
+	}
+	if caller != s.Signer(req.Account) {
+		return ErrUnauthorized
+	}
+	if req.Status == RecoveryExecuted || req.Status == RecoveryCancelled {
+		return ErrInvalidState
+	}
+	req.Status = RecoveryCancelled
+	req.Finalised = time.Now().UTC()
+	if err := s.putRequest(req); err != nil {
+		return err
+	}
+	recoveryAudit(req.Account, "social_recovery_cancelled", map[string]string{"request": id})
+	return nil
+}
+
+// RecoverAccount executes an approved request once its timelock elapsed,
+// revokes the account's session keys and rotates its signer. Approvals are
+// recounted against the current guardian set so removed guardians no longer
+// count.
+func (s *SocialRecovery) RecoverAccount(id string) (*SocialRecoveryRequest, error) {
+	s.reqMu.Lock()
+	defer s.reqMu.Unlock()
+	req, err := s.Request(id)
+	if err != nil {
+		return nil, err
+	}
+	if req.Status != RecoveryApproved {
+		return nil, ErrInvalidState
+	}
+	cfg, err := s.Config(req.Account)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.approvals(req) < cfg.Threshold {
+		return nil, fmt.Errorf("%w: approvals below threshold", ErrInvalidState)
+	}
+	now := time.Now().UTC()
+	if now.Before(req.UnlockAt) {
+		return nil, fmt.Errorf("%w: unlocks at %s", ErrRecoveryTimelock, req.UnlockAt.Format(time.RFC3339))
+	}
+	revoked, err := revokeSessionKeys(s.led, req.Account)
+	if err != nil {
+		return nil, fmt.Errorf("revoke session keys: %w", err)
+	}
+	rot := SignerRotation{Account: req.Account, Signer: req.NewSigner, Request: id, Time: now}
+	if err := s.led.SetState(socialSignerKey(req.Account), mustJSON(rot)); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	s.signers[req.Account] = req.NewSigner
+	s.mu.Unlock()
+
+	req.Status = RecoveryExecuted
+	req.Finalised = now
+	if err := s.putRequest(req); err != nil {
+		return nil, err
+	}
+	recoveryAudit(req.Account, "social_recovery_executed", map[string]string{
+		"request":      id,
+		"new_signer":   req.NewSigner.Hex(),
+		"session_keys": fmt.Sprint(revoked),
+	})
+	Broadcast("recovery:executed", mustJSON(req))
+	return req, nil
+}
+
+// Request fetches a recovery request by ID.
+func (s *SocialRecovery) Request(id string) (*SocialRecoveryRequest, error) {
+	raw, err := s.led.GetState(socialRequestKey(id))
+	if err != nil || len(raw) == 0 {
+		return nil, ErrNotFound
+	}
+	var req SocialRecoveryRequest
+	if err := json.Unmarshal(raw, &req); err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+// Requests lists every recovery request for account.
+func (s *SocialRecovery) Requests(account Address) ([]SocialRecoveryRequest, error) {
+	it := s.led.PrefixIterator([]byte("socialrec:request:"))
+	var out []SocialRecoveryRequest
+	for it.Next() {
+		var req SocialRecoveryRequest
+		if err := json.Unmarshal(it.Value(), &req); err != nil {
+			return nil, err
+		}
+		if req.Account == account {
+			out = append(out, req)
+		}
+	}
+	return out, it.Error()
+}
+
+// approve records guardian's approval of req. The caller holds s.reqMu from
+// reading req until it is written back.
+func (s *SocialRecovery) approve(cfg *SocialRecoveryConfig, req *SocialRecoveryRequest, guardian Address) error {
+	if _, ok := req.Approvals[guardian.Hex()]; ok {
+		return errors.New("guardian already approved")
+	}
+	now := time.Now().UTC()
+	req.Approvals[guardian.Hex()] = now
+	if req.Status == RecoveryPending && cfg.approvals(req) >= cfg.Threshold {
+		req.Status = RecoveryApproved
+		req.UnlockAt = now.Add(cfg.Delay)
+	}
+	if err := s.putRequest(req); err != nil {
+		return err
+	}
+	recoveryAudit(req.Account, "social_recovery_approved", map[string]string{
+		"request":  req.ID,
+		"guardian": guardian.Hex(),
+	})
+	return nil
+}
+
+func (s *SocialRecovery) putRequest(req *SocialRecoveryRequest) error {
+	raw, _ := json.Marshal(req)
+	return s.led.SetState(socialRequestKey(req.ID), raw)
+}
+
+func (s *SocialRecovery) load() error {
+	it := s.led.PrefixIterator([]byte("socialrec:signer:"))
+	for it.Next() {
+		var rot SignerRotation
+		if err := json.Unmarshal(it.Value(), &rot); err != nil {
+			return err
+		}
+		s.signers[rot.Account] = rot.Signer
+	}
+	return it.Error()
+}
+
+func (c *SocialRecoveryConfig) isGuardian(a Address) bool {
+	for _, g := range c.Guardians {
+		if g == a {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *SocialRecoveryConfig) approvals(req *SocialRecoveryRequest) int {
+	n := 0
+	for _, g := range c.Guardians {
+		if _, ok := req.Approvals[g.Hex()]; ok {
+			n++
+		}
+	}
+	return n
+}
+
+func recoveryAudit(addr Address, event string, meta map[string]string) {
+	if am := AuditManagerInstance(); am != nil {
+		_ = am.Log(addr, event, meta)
+	}
+}
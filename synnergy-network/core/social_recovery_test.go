@@ -0,0 +1,156 @@
+package core
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newRecoveryTest registers guardians for owner with the given threshold
+// and makes the manager the global one.
+func newRecoveryTest(t *testing.T, owner Address, guardians []Address, threshold int) *SocialRecovery {
+	t.Helper()
+	s := &SocialRecovery{led: newDepositLedger(t), signers: make(map[Address]Address)}
+	prev := socialRec
+	socialRec = s
+	t.Cleanup(func() { socialRec = prev })
+	if _, err := s.RegisterRecovery(owner, owner, guardians, threshold, time.Hour); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	return s
+}
+
+// unlock moves the timelock of request id into the past.
+func unlock(t *testing.T, s *SocialRecovery, id string) {
+	t.Helper()
+	req, err := s.Request(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.UnlockAt = time.Now().Add(-time.Second)
+	if err := s.putRequest(req); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRecoveryNeedsThresholdAndDelayAndRevokesSessionKeys(t *testing.T) {
+	owner, newSigner := Address{0x01}, Address{0x02}
+	g1, g2, g3 := Address{0x11}, Address{0x12}, Address{0x13}
+	s := newRecoveryTest(t, owner, []Address{g1, g2, g3}, 2)
+
+	m := &SessionKeyManager{led: s.led, policies: make(map[Address]map[Address]*SessionKeyPolicy),
+		usage: make(map[Address]map[Address]*SessionKeyUsage), pending: make(map[Hash]sessionCharge)}
+	prev := sessionMgr
+	sessionMgr = m
+	t.Cleanup(func() { sessionMgr = prev })
+	key := Address{0x5e}
+	if _, err := m.Authorise(owner, SessionKeyPolicy{Account: owner, Key: key, Expires: time.Now().Add(time.Hour)}); err != nil {
+		t.Fatalf("authorise: %v", err)
+	}
+
+	req, err := s.InitiateRecovery(g1, owner, newSigner)
+	if err != nil {
+		t.Fatalf("initiate: %v", err)
+	}
+	if _, err := s.RecoverAccount(req.ID); !errors.Is(err, ErrInvalidState) {
+		t.Fatalf("recovery below the threshold: %v", err)
+	}
+	if _, err := s.ApproveRecovery(Address{0x99}, req.ID); !errors.Is(err, ErrNotGuardian) {
+		t.Fatalf("approval by a stranger: %v", err)
+	}
+	if _, err := s.ApproveRecovery(g1, req.ID); err == nil {
+		t.Fatal("second approval by the same guardian accepted")
+	}
+	req, err = s.ApproveRecovery(g2, req.ID)
+	if err != nil {
+		t.Fatalf("approve: %v", err)
+	}
+	if req.Status != RecoveryApproved || time.Until(req.UnlockAt) < 59*time.Minute {
+		t.Fatalf("request at the threshold: %+v", req)
+	}
+	if _, err := s.RecoverAccount(req.ID); !errors.Is(err, ErrRecoveryTimelock) {
+		t.Fatalf("recovery during the delay: %v", err)
+	}
+	if AccountSigner(owner) != owner || !IsSessionKey(owner, key) {
+		t.Fatal("account changed before the recovery executed")
+	}
+
+	unlock(t, s, req.ID)
+	if req, err = s.RecoverAccount(req.ID); err != nil || req.Status != RecoveryExecuted {
+		t.Fatalf("recover: %+v %v", req, err)
+	}
+	if AccountSigner(owner) != newSigner {
+		t.Fatalf("signer after recovery: %s", AccountSigner(owner).Hex())
+	}
+	if IsSessionKey(owner, key) || len(m.List(owner)) != 0 {
+		t.Fatal("session key of the old signer survived the recovery")
+	}
+	if ok, _ := s.led.HasState(sessionKeyKey(owner, key)); ok {
+		t.Fatal("session key left in ledger state")
+	}
+	if _, err := s.RecoverAccount(req.ID); !errors.Is(err, ErrInvalidState) {
+		t.Fatalf("request executed twice: %v", err)
+	}
+}
+
+func TestOwnerVetoesRecoveryDuringTheDelay(t *testing.T) {
+	owner := Address{0x01}
+	g1, g2 := Address{0x11}, Address{0x12}
+	s := newRecoveryTest(t, owner, []Address{g1, g2}, 2)
+
+	req, err := s.InitiateRecovery(g1, owner, Address{0x02})
+	if err != nil {
+		t.Fatalf("initiate: %v", err)
+	}
+	if _, err := s.ApproveRecovery(g2, req.ID); err != nil {
+		t.Fatalf("approve: %v", err)
+	}
+	if err := s.CancelRecovery(g1, req.ID); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("cancel by a guardian: %v", err)
+	}
+	if err := s.CancelRecovery(owner, req.ID); err != nil {
+		t.Fatalf("veto: %v", err)
+	}
+	unlock(t, s, req.ID)
+	if _, err := s.RecoverAccount(req.ID); !errors.Is(err, ErrInvalidState) {
+		t.Fatalf("cancelled request executed: %v", err)
+	}
+	if err := s.CancelRecovery(owner, req.ID); !errors.Is(err, ErrInvalidState) {
+		t.Fatalf("cancelled twice: %v", err)
+	}
+	if AccountSigner(owner) != owner {
+		t.Fatal("vetoed recovery rotated the signer")
+	}
+}
+
+func TestConcurrentApprovalsAreAllRecorded(t *testing.T) {
+	owner := Address{0x01}
+	guardians := make([]Address, 32)
+	for i := range guardians {
+		guardians[i] = Address{0x10, byte(i)}
+	}
+	s := newRecoveryTest(t, owner, guardians, len(guardians))
+	req, err := s.InitiateRecovery(guardians[0], owner, Address{0x02})
+	if err != nil {
+		t.Fatalf("initiate: %v", err)
+	}
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for _, g := range guardians[1:] {
+		wg.Add(1)
+		go func(g Address) {
+			defer wg.Done()
+			<-start
+			if _, err := s.ApproveRecovery(g, req.ID); err != nil {
+				t.Errorf("approve %s: %v", g.Hex(), err)
+			}
+		}(g)
+	}
+	close(start)
+	wg.Wait()
+	req, _ = s.Request(req.ID)
+	if len(req.Approvals) != len(guardians) || req.Status != RecoveryApproved {
+		t.Fatalf("%d of %d approvals recorded, status %s", len(req.Approvals), len(guardians), req.Status)
+	}
+}
@@ -0,0 +1,268 @@
+package core
+
+// stable_swap.go – Curve-style stable-swap invariant for pegged pairs.
+//
+// Stable pools price along the StableSwap invariant
+//
+//	A·n^n·Σx + D = A·D·n^n + D^(n+1) / (n^n·Πx)     (n = 2)
+//
+// which behaves like a constant-sum curve near the peg and like constant
+// product far from it. The amplification coefficient A sets how flat the
+// curve is. LP supply tracks D: deposits mint in proportion to the D they
+// add, and the part of a deposit or withdrawal that moves the pool away from
+// its current ratio pays an imbalance fee of feeBps·n/(4(n-1)) on the
+// deviation, which stays in the pool for liquidity providers.
+//
+// All invariant maths uses big.Int so large reserves cannot overflow.
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// PoolType selects the pricing invariant of a pool.
+type PoolType uint8
+
+const (
+	// PoolConstantProduct prices with x·y = k.
+	PoolConstantProduct PoolType = iota
+	// PoolStableSwap prices with the amplified stable-swap invariant.
+	PoolStableSwap
+)
+
+const (
+	stableCoins     = 2
+	stableMaxIter   = 255
+	minAmp          = 1
+	maxAmp          = 10_000
+	defaultStableAm = 100
+)
+
+// String implements fmt.Stringer.
+func (t PoolType) String() string {
+	switch t {
+	case PoolConstantProduct:
+		return "constant_product"
+	case PoolStableSwap:
+		return "stable_swap"
+	}
+	return fmt.Sprintf("PoolType(%d)", uint8(t))
+}
+
+// ParsePoolType accepts "constant_product"/"cp" and "stable_swap"/"stable".
+func ParsePoolType(s string) (PoolType, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "constant_product", "cp", "xyk":
+		return PoolConstantProduct, nil
+	case "stable_swap", "stable", "stableswap":
+		return PoolStableSwap, nil
+	}
+	return 0, fmt.Errorf("unknown pool type %q", s)
+}
+
+// PoolSpec selects the pool type in CreatePool. Amp is the amplification
+// coefficient of stable-swap pools (defaults to 100).
+type PoolSpec struct {
+	Type PoolType
+	Amp  uint64
+}
+
+func (s PoolSpec) validate() (PoolSpec, error) {
+	switch s.Type {
+	case PoolConstantProduct:
+		s.Amp = 0
+	case PoolStableSwap:
+		if s.Amp == 0 {
+			s.Amp = defaultStableAm
+		}
+		if s.Amp < minAmp || s.Amp > maxAmp {
+			return s, fmt.Errorf("amplification must be %d-%d", minAmp, maxAmp)
+		}
+	default:
+		return s, fmt.Errorf("unknown pool type %d", s.Type)
+	}
+	return s, nil
+}
+
+// outGivenIn returns the output of a swap of dx (after fee) into a pool
+// with reserves resIn/resOut.
+func (p *Pool) outGivenIn(resIn, resOut, dx uint64) (uint64, error) {
+	if resIn == 0 || resOut == 0 {
+		return 0, errors.New("empty pool")
+	}
+	if p.kind != PoolStableSwap {
+		// x·y = k  →  out = resOut - ⌈resIn·resOut/(resIn+dx)⌉
+		k := new(big.Int).Mul(u64(resIn), u64(resOut))
+		den := u64(resIn + dx)
+		newOut := k.Add(k, den).Sub(k, big.NewInt(1)).Div(k, den)
+		return resOut - newOut.Uint64(), nil
+	}
+	d := stableD(p.amp, u64(resIn), u64(resOut))
+	y := stableY(p.amp, u64(resIn+dx), d)
+	out := new(big.Int).Sub(u64(resOut), y)
+	out.Sub(out, big.NewInt(1)) // round in favour of the pool
+	if out.Sign() <= 0 {
+		return 0, nil
+	}
+	return out.Uint64(), nil
+}
+
+// stableMint computes the LP minted for depositing amtA/amtB into a
+// stable pool and the imbalance fee charged on each side.
+func (p *Pool) stableMint(amtA, amtB uint64) (minted, feeA, feeB uint64, err error) {
+	oldA, oldB := u64(p.resA), u64(p.resB)
+	newA, newB := u64(p.resA+amtA), u64(p.resB+amtB)
+	d1 := stableD(p.amp, newA, newB)
+	if p.totalLP == 0 {
+		return d1.Uint64(), 0, 0, nil
+	}
+	d0 := stableD(p.amp, oldA, oldB)
+	if d0.Sign() == 0 {
+		return 0, 0, 0, errors.New("empty pool")
+	}
+	feeA = p.imbalanceFee(d0, d1, oldA, newA)
+	feeB = p.imbalanceFee(d0, d1, oldB, newB)
+	d2 := stableD(p.amp, newA.Sub(newA, u64(feeA)), newB.Sub(newB, u64(feeB)))
+	if d2.Cmp(d0) <= 0 {
+		return 0, feeA, feeB, errors.New("deposit adds no liquidity")
+	}
+	m := new(big.Int).Mul(u64(p.totalLP), d2.Sub(d2, d0))
+	return m.Div(m, d0).Uint64(), feeA, feeB, nil
+}
+
+// stableBurn computes the LP burnt for withdrawing exactly amtA/amtB from a
+// stable pool, including imbalance fees.
+func (p *Pool) stableBurn(amtA, amtB uint64) (burn, feeA, feeB uint64, err error) {
+	if amtA > p.resA || amtB > p.resB {
+		return 0, 0, 0, errors.New("insufficient reserves")
+	}
+	oldA, oldB := u64(p.resA), u64(p.resB)
+	newA, newB := u64(p.resA-amtA), u64(p.resB-amtB)
+	d0 := stableD(p.amp, oldA, oldB)
+	if d0.Sign() == 0 || p.totalLP == 0 {
+		return 0, 0, 0, errors.New("empty pool")
+	}
+	d1 := stableD(p.amp, newA, newB)
+	feeA = p.imbalanceFee(d0, d1, oldA, newA)
+	feeB = p.imbalanceFee(d0, d1, oldB, newB)
+	if newA.Cmp(u64(feeA)) < 0 || newB.Cmp(u64(feeB)) < 0 {
+		return 0, 0, 0, errors.New("insufficient reserves")
+	}
+	d2 := stableD(p.amp, newA.Sub(newA, u64(feeA)), newB.Sub(newB, u64(feeB)))
+	b := new(big.Int).Mul(u64(p.totalLP), new(big.Int).Sub(d0, d2))
+	b.Div(b, d0)
+	return b.Uint64() + 1, feeA, feeB, nil // round up against the provider
+}
+
+// imbalanceFee charges feeBps·n/(4(n-1)) on how far a balance moved from
+// the ideal balance that keeps the pool ratio unchanged.
+func (p *Pool) imbalanceFee(d0, d1, oldBal, newBal *big.Int) uint64 {
+	ideal := new(big.Int).Mul(d1, oldBal)
+	ideal.Div(ideal, d0)
+	diff := ideal.Sub(ideal, newBal)
+	diff.Abs(diff)
+	bps := uint64(p.feeBps) * stableCoins / (4 * (stableCoins - 1))
+	diff.Mul(diff, u64(bps))
+	return diff.Div(diff, big.NewInt(10_000)).Uint64()
+}
+
+// stableD solves the invariant for D by Newton's method.
+func stableD(amp uint64, x, y *big.Int) *big.Int {
+	n := big.NewInt(stableCoins)
+	s := new(big.Int).Add(x, y)
+	if s.Sign() == 0 || x.Sign() == 0 || y.Sign() == 0 {
+		return s
+	}
+	ann := new(big.Int).Mul(u64(amp), big.NewInt(stableCoins*stableCoins))
+	d := new(big.Int).Set(s)
+	for i := 0; i < stableMaxIter; i++ {
+		// dP = D^(n+1) / (n^n·x·y)
+		dp := new(big.Int).Set(d)
+		dp.Mul(dp, d).Div(dp, new(big.Int).Mul(x, n))
+		dp.Mul(dp, d).Div(dp, new(big.Int).Mul(y, n))
+		prev := new(big.Int).Set(d)
+		// D = (Ann·S + dP·n)·D / ((Ann-1)·D + (n+1)·dP)
+		num := new(big.Int).Mul(ann, s)
+		num.Add(num, new(big.Int).Mul(dp, n)).Mul(num, d)
+		den := new(big.Int).Mul(new(big.Int).Sub(ann, big.NewInt(1)), d)
+		den.Add(den, new(big.Int).Mul(big.NewInt(stableCoins+1), dp))
+		d = num.Div(num, den)
+		if new(big.Int).Sub(d, prev).CmpAbs(big.NewInt(1)) <= 0 {
+			break
+		}
+	}
+	return d
+}
+
+// stableY returns the balance of the other coin that keeps the invariant D
+// when one balance is x.
+func stableY(amp uint64, x, d *big.Int) *big.Int {
+	n := big.NewInt(stableCoins)
+	ann := new(big.Int).Mul(u64(amp), big.NewInt(stableCoins*stableCoins))
+	// c = D^(n+1) / (n^n·x·Ann), b = x + D/Ann
+	c := new(big.Int).Set(d)
+	c.Mul(c, d).Div(c, new(big.Int).Mul(x, n))
+	c.Mul(c, d).Div(c, new(big.Int).Mul(ann, n))
+	b := new(big.Int).Div(d, ann)
+	b.Add(b, x)
+	y := new(big.Int).Set(d)
+	for i := 0; i < stableMaxIter; i++ {
+		prev := new(big.Int).Set(y)
+		// y = (y² + c) / (2y + b - D)
+		num := new(big.Int).Mul(y, y)
+		num.Add(num, c)
+		den := new(big.Int).Mul(y, big.NewInt(2))
+		den.Add(den, b).Sub(den, d)
+		y = num.Div(num, den)
+		if new(big.Int).Sub(y, prev).CmpAbs(big.NewInt(1)) <= 0 {
+			break
+		}
+	}
+	return y
+}
+
+func u64(v uint64) *big.Int { return new(big.Int).SetUint64(v) }
+
+// RemoveLiquidityImbalance withdraws exact amounts of both tokens from a
+// stable pool, burning at most maxBurn LP units including imbalance fees.
+func (a *AMM) RemoveLiquidityImbalance(p PoolID, provider Address, amtA, amtB, maxBurn uint64) (burned uint64, err error) {
+	pool, ok := a.pools[p]
+	if !ok {
+		return 0, errors.New("pool not found")
+	}
+	if pool.kind != PoolStableSwap {
+		return 0, errors.New("imbalanced withdrawals need a stable-swap pool")
+	}
+	if amtA == 0 && amtB == 0 {
+		return 0, errors.New("amount zero")
+	}
+	if pool.isLocked() {
+		return 0, ErrPoolLocked
+	}
+	err = a.ledger.Snapshot(func() error {
+		// imbalance fees stay in the reserves
+		var err error
+		if burned, _, _, err = pool.stableBurn(amtA, amtB); err != nil {
+			return err
+		}
+		if burned > maxBurn {
+			return fmt.Errorf("slippage: burn %d exceeds max %d", burned, maxBurn)
+		}
+		if err := a.ledger.BurnLP(provider, p, burned); err != nil {
+			return err
+		}
+		pool.resA -= amtA
+		pool.resB -= amtB
+		pool.totalLP -= burned
+		if err := transferToken(pool.tokenA, poolAccount(p), provider, amtA); err != nil {
+			return err
+		}
+		if err := transferToken(pool.tokenB, poolAccount(p), provider, amtB); err != nil {
+			return err
+		}
+		return a.recordLPExit(p, provider, burned, amtA, amtB)
+	})
+	return burned, err
+}
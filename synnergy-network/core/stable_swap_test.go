@@ -0,0 +1,56 @@
+package core
+
+import "testing"
+
+func TestStableSwapOutNearPeg(t *testing.T) {
+	stable := &Pool{kind: PoolStableSwap, amp: 100, feeBps: 4}
+	cp := &Pool{kind: PoolConstantProduct}
+
+	sOut, err := stable.outGivenIn(1_000_000, 1_000_000, 100_000)
+	if err != nil {
+		t.Fatalf("stable: %v", err)
+	}
+	cOut, err := cp.outGivenIn(1_000_000, 1_000_000, 100_000)
+	if err != nil {
+		t.Fatalf("constant product: %v", err)
+	}
+	if sOut <= cOut || sOut > 100_000 {
+		t.Fatalf("stable out %d should beat constant product %d near the peg", sOut, cOut)
+	}
+	if cOut != 90_909 {
+		t.Fatalf("constant product out %d, want 90909", cOut)
+	}
+}
+
+func TestStableSwapImbalanceFees(t *testing.T) {
+	p := &Pool{kind: PoolStableSwap, amp: 100, feeBps: 100}
+	minted, _, _, err := p.stableMint(1_000_000, 1_000_000)
+	if err != nil {
+		t.Fatalf("initial mint: %v", err)
+	}
+	p.resA, p.resB, p.totalLP = 1_000_000, 1_000_000, minted
+
+	balanced, feeA, feeB, err := p.stableMint(1_000, 1_000)
+	if err != nil || feeA != 0 || feeB != 0 {
+		t.Fatalf("balanced deposit: minted %d fees %d/%d err %v", balanced, feeA, feeB, err)
+	}
+	oneSided, feeA, feeB, err := p.stableMint(2_000, 0)
+	if err != nil {
+		t.Fatalf("one-sided deposit: %v", err)
+	}
+	if feeA == 0 || feeB == 0 || oneSided >= balanced {
+		t.Fatalf("one-sided deposit minted %d (balanced %d) with fees %d/%d", oneSided, balanced, feeA, feeB)
+	}
+
+	burn, _, _, err := p.stableBurn(1_000, 1_000)
+	if err != nil {
+		t.Fatalf("burn: %v", err)
+	}
+	imbBurn, _, _, err := p.stableBurn(2_000, 0)
+	if err != nil {
+		t.Fatalf("imbalanced burn: %v", err)
+	}
+	if imbBurn <= burn {
+		t.Fatalf("imbalanced withdrawal burned %d, balanced %d", imbBurn, burn)
+	}
+}
@@ -50,8 +50,17 @@ var (
 	chEng    *ChannelEngine
 )
 
-func InitStateChannels(led StateRW) { chanOnce.Do(func() { chEng = &ChannelEngine{led: led} }) }
-func Channels() *ChannelEngine      { return chEng }
+// channelState is the ledger state channels are kept in. *Ledger and any
+// StateRW satisfy it.
+type channelState interface {
+	GetState(key []byte) ([]byte, error)
+	SetState(key, value []byte) error
+	DeleteState(key []byte) error
+	PrefixIterator(prefix []byte) StateIterator
+}
+
+func InitStateChannels(led channelState) { chanOnce.Do(func() { chEng = &ChannelEngine{led: led} }) }
+func Channels() *ChannelEngine           { return chEng }
 
 //---------------------------------------------------------------------
 // OpenChannel – both parties must have approved token transfer.
@@ -263,7 +272,7 @@ func (e *ChannelEngine) GetChannel(id ChannelID) (Channel, error) {
 // corrupt entries are skipped but the iterator error is returned.
 func (e *ChannelEngine) ListChannels() ([]Channel, error) {
 	var chans []Channel
-	err := e.led.Snapshot(func() error {
+	list := func() error {
 		it := e.led.PrefixIterator([]byte("chan:"))
 		for it.Next() {
 			var c Channel
@@ -275,7 +284,14 @@ func (e *ChannelEngine) ListChannels() ([]Channel, error) {
 			return ierr.Error()
 		}
 		return nil
-	})
+	}
+	// read from a consistent view when the state offers one
+	var err error
+	if s, ok := e.led.(interface{ Snapshot(func() error) error }); ok {
+		err = s.Snapshot(list)
+	} else {
+		err = list()
+	}
 	return chans, err
 }
 
@@ -0,0 +1,376 @@
+package core
+
+// state_diff.go – per-block and per-transaction state diffs.
+//
+// applyBlock records, for every transaction, the values of the state it is
+// about to touch and compares them with the values it leaves behind: coin
+// balances of the accounts involved, raw storage keys, contracts and
+// UTXOs. The diffs of the most recent blocks are kept in memory; older
+// blocks fall back to the archive journal on archive nodes, which yields
+// the block-level diff but not its split per transaction.
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// stateDiffRetention is the number of recent blocks whose diffs are kept.
+const stateDiffRetention = 1024
+
+// AccountDiff is the coin balance change of one account.
+type AccountDiff struct {
+	Address string `json:"address"`
+	Before  uint64 `json:"before"`
+	After   uint64 `json:"after"`
+	Delta   int64  `json:"delta"`
+}
+
+// StorageDiff is the change of one raw state key. Before is empty for
+// created keys and After for deleted ones.
+type StorageDiff struct {
+	Key     string `json:"key"`              // hex
+	Before  string `json:"before,omitempty"` // hex
+	After   string `json:"after,omitempty"`  // hex
+	Created bool   `json:"created,omitempty"`
+	Deleted bool   `json:"deleted,omitempty"`
+}
+
+// StateDiff is the state changed by a transaction or a whole block.
+type StateDiff struct {
+	Height             uint64        `json:"height"`
+	BlockHash          string        `json:"block_hash,omitempty"`
+	TxHash             string        `json:"tx_hash,omitempty"`
+	Accounts           []AccountDiff `json:"accounts"`
+	Storage            []StorageDiff `json:"storage"`
+	ContractsCreated   []string      `json:"contracts_created,omitempty"`
+	ContractsDestroyed []string      `json:"contracts_destroyed,omitempty"`
+	UTXOsSpent         []string      `json:"utxos_spent,omitempty"`
+	UTXOsCreated       []string      `json:"utxos_created,omitempty"`
+}
+
+// BlockStateDiff is the diff of a block: the net change over the block and,
+// when still retained, the diff of each transaction in block order.
+type BlockStateDiff struct {
+	StateDiff
+	Txs []StateDiff `json:"txs,omitempty"`
+	// Source is "execution" for diffs recorded while applying the block
+	// and "archive" for diffs rebuilt from historical state.
+	Source string `json:"source"`
+}
+
+// stateDiffLog keeps the diffs of recent blocks by height.
+type stateDiffLog struct {
+	blocks map[uint64]*BlockStateDiff
+	order  []uint64
+}
+
+func (l *Ledger) stateDiffLog() *stateDiffLog {
+	if l.stateDiffs == nil {
+		l.stateDiffs = &stateDiffLog{blocks: make(map[uint64]*BlockStateDiff)}
+	}
+	return l.stateDiffs
+}
+
+func (d *stateDiffLog) add(b *BlockStateDiff) {
+	if _, ok := d.blocks[b.Height]; !ok {
+		d.order = append(d.order, b.Height)
+	}
+	d.blocks[b.Height] = b
+	for len(d.order) > stateDiffRetention {
+		delete(d.blocks, d.order[0])
+		d.order = d.order[1:]
+	}
+}
+
+// txDiffRecorder holds the values a transaction is about to overwrite.
+type txDiffRecorder struct {
+	tx        *Transaction
+	balances  map[Address]uint64
+	storage   map[string][]byte
+	existed   map[string]bool
+	contracts map[string]bool
+}
+
+// beginTxDiff snapshots the state tx will touch. The caller holds l.mu.
+func (l *Ledger) beginTxDiff(tx *Transaction) *txDiffRecorder {
+	r := &txDiffRecorder{
+		tx:        tx,
+		balances:  make(map[Address]uint64),
+		storage:   make(map[string][]byte, len(tx.StateChanges)),
+		existed:   make(map[string]bool, len(tx.StateChanges)),
+		contracts: make(map[string]bool),
+	}
+	touch := func(a Address) {
+		if _, ok := r.balances[a]; !ok {
+			r.balances[a] = l.Balances[CoinKey(a)]
+		}
+	}
+	touch(tx.From)
+	touch(tx.To)
+	for _, tr := range tx.TokenTransfers {
+		touch(tr.From)
+		touch(tr.To)
+	}
+	for _, out := range tx.Outputs {
+		touch(out.Address)
+	}
+	for k := range tx.StateChanges {
+		v, ok := l.State[k]
+		r.storage[k], r.existed[k] = v, ok
+	}
+	to := fmt.Sprintf("%x", tx.To)
+	_, r.contracts[to] = l.Contracts[to]
+	if tx.Contract != nil {
+		a := fmt.Sprintf("%x", tx.Contract.Address)
+		_, r.contracts[a] = l.Contracts[a]
+	}
+	return r
+}
+
+// track adds accounts, state keys and contracts the transaction changes
+// beyond those beginTxDiff found in it, such as the writes of a multicall.
+// The caller holds l.mu.
+func (r *txDiffRecorder) track(l *Ledger, addrs []Address, keys []string, contracts []Address) {
+	for _, a := range addrs {
+		if _, ok := r.balances[a]; !ok {
+			r.balances[a] = l.Balances[CoinKey(a)]
+		}
+	}
+	for _, k := range keys {
+		if _, ok := r.storage[k]; !ok {
+			v, had := l.State[k]
+			r.storage[k], r.existed[k] = v, had
+		}
+	}
+	for _, a := range contracts {
+		h := fmt.Sprintf("%x", a)
+		if _, ok := r.contracts[h]; !ok {
+			_, r.contracts[h] = l.Contracts[h]
+		}
+	}
+}
+
+// finish compares the snapshot with the state after the transaction. The
+// caller holds l.mu.
+func (r *txDiffRecorder) finish(l *Ledger, height uint64) StateDiff {
+	tx := r.tx
+	d := StateDiff{Height: height, TxHash: tx.ID().Hex(), Accounts: []AccountDiff{}, Storage: []StorageDiff{}}
+	for a, before := range r.balances {
+		after := l.Balances[CoinKey(a)]
+		if after != before {
+			d.Accounts = append(d.Accounts, AccountDiff{Address: a.Hex(), Before: before, After: after, Delta: int64(after) - int64(before)})
+		}
+	}
+	for _, k := range sortedKeys(r.storage) {
+		after, ok := l.State[k]
+		sd := StorageDiff{Key: hex.EncodeToString([]byte(k)), Before: hex.EncodeToString(r.storage[k]), After: hex.EncodeToString(after)}
+		switch {
+		case !r.existed[k] && ok:
+			sd.Created = true
+		case r.existed[k] && !ok:
+			sd.Deleted = true
+		case string(r.storage[k]) == string(after):
+			continue
+		}
+		d.Storage = append(d.Storage, sd)
+	}
+	for a, had := range r.contracts {
+		_, has := l.Contracts[a]
+		switch {
+		case !had && has:
+			d.ContractsCreated = append(d.ContractsCreated, "0x"+a)
+		case had && !has:
+			d.ContractsDestroyed = append(d.ContractsDestroyed, "0x"+a)
+		}
+	}
+	for _, in := range tx.Inputs {
+		d.UTXOsSpent = append(d.UTXOsSpent, utxoKey(in.TxID, in.Index))
+	}
+	for i := range tx.Outputs {
+		d.UTXOsCreated = append(d.UTXOsCreated, utxoKey(tx.ID(), uint32(i)))
+	}
+	d.sort()
+	return d
+}
+
+func (d *StateDiff) sort() {
+	sort.Slice(d.Accounts, func(i, j int) bool { return d.Accounts[i].Address < d.Accounts[j].Address })
+	sort.Slice(d.Storage, func(i, j int) bool { return d.Storage[i].Key < d.Storage[j].Key })
+	sort.Strings(d.ContractsCreated)
+	sort.Strings(d.ContractsDestroyed)
+}
+
+// mergeStateDiffs folds the transaction diffs of a block into its net diff:
+// the first before and the last after of every account and key, dropping
+// changes that cancel out.
+func mergeStateDiffs(height uint64, hash string, txs []StateDiff) StateDiff {
+	out := StateDiff{Height: height, BlockHash: hash, Accounts: []AccountDiff{}, Storage: []StorageDiff{}}
+	accts := make(map[string]*AccountDiff)
+	keys := make(map[string]*StorageDiff)
+	var aOrder, kOrder []string
+	created := make(map[string]bool)
+	for _, d := range txs {
+		for _, a := range d.Accounts {
+			if cur, ok := accts[a.Address]; ok {
+				cur.After = a.After
+			} else {
+				c := a
+				accts[a.Address] = &c
+				aOrder = append(aOrder, a.Address)
+			}
+		}
+		for _, s := range d.Storage {
+			if cur, ok := keys[s.Key]; ok {
+				cur.After, cur.Deleted = s.After, s.Deleted
+			} else {
+				c := s
+				keys[s.Key] = &c
+				kOrder = append(kOrder, s.Key)
+			}
+		}
+		for _, c := range d.ContractsCreated {
+			created[c] = true
+			out.ContractsCreated = append(out.ContractsCreated, c)
+		}
+		for _, c := range d.ContractsDestroyed {
+			if !created[c] {
+				out.ContractsDestroyed = append(out.ContractsDestroyed, c)
+			}
+		}
+		out.UTXOsSpent = append(out.UTXOsSpent, d.UTXOsSpent...)
+		out.UTXOsCreated = append(out.UTXOsCreated, d.UTXOsCreated...)
+	}
+	for _, a := range aOrder {
+		c := accts[a]
+		if c.After == c.Before {
+			continue
+		}
+		c.Delta = int64(c.After) - int64(c.Before)
+		out.Accounts = append(out.Accounts, *c)
+	}
+	for _, k := range kOrder {
+		c := keys[k]
+		if c.Created && c.Deleted || c.Before == c.After && !c.Created && !c.Deleted {
+			continue
+		}
+		out.Storage = append(out.Storage, *c)
+	}
+	out.sort()
+	return out
+}
+
+// blockAt returns the retained block at height. The caller holds l.mu.
+func (l *Ledger) blockAt(height uint64) *Block {
+	for i := len(l.Blocks) - 1; i >= 0; i-- {
+		if b := l.Blocks[i]; b.Header.Height == height {
+			return b
+		} else if b.Header.Height < height {
+			break
+		}
+	}
+	return nil
+}
+
+// BlockStateDiff returns the state diff of the block at height.
+func (l *Ledger) BlockStateDiff(height uint64) (*BlockStateDiff, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if l.stateDiffs != nil {
+		if d, ok := l.stateDiffs.blocks[height]; ok {
+			return d, nil
+		}
+	}
+	if l.archive == nil {
+		return nil, NewError(CodeNotFound, "ledger", "no state diff for block %d: only the last %d blocks are kept without an archive", height, stateDiffRetention)
+	}
+	d, err := l.archive.diffAt(height)
+	if err != nil {
+		return nil, err
+	}
+	if b := l.blockAt(height); b != nil {
+		d.BlockHash = b.Hash().Hex()
+	}
+	return d, nil
+}
+
+// TxStateDiff returns the state diff of the transaction h. Per-transaction
+// diffs are available for the retained recent blocks only.
+func (l *Ledger) TxStateDiff(h Hash) (*StateDiff, error) {
+	_, loc, err := l.LookupTx(h)
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if l.stateDiffs == nil {
+		return nil, NewError(CodeNotFound, "ledger", "no state diff for transaction %x", h)
+	}
+	want := h.Hex()
+	if err == nil {
+		if d, ok := l.stateDiffs.blocks[loc.Height]; ok && loc.Index < len(d.Txs) && d.Txs[loc.Index].TxHash == want {
+			return &d.Txs[loc.Index], nil
+		}
+	}
+	for i := len(l.stateDiffs.order) - 1; i >= 0; i-- {
+		d := l.stateDiffs.blocks[l.stateDiffs.order[i]]
+		for j := range d.Txs {
+			if d.Txs[j].TxHash == want {
+				return &d.Txs[j], nil
+			}
+		}
+	}
+	return nil, NewError(CodeNotFound, "ledger", "no state diff for transaction %x: only the last %d blocks are kept", h, stateDiffRetention)
+}
+
+// diffAt rebuilds the net diff of a block from the versions recorded at
+// height.
+func (a *stateArchive) diffAt(height uint64) (*BlockStateDiff, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if !a.started || height > a.head {
+		return nil, NewError(CodeNotFound, "ledger", "no history for block %d", height)
+	}
+	d := &BlockStateDiff{Source: "archive", StateDiff: StateDiff{Height: height, Accounts: []AccountDiff{}, Storage: []StorageDiff{}}}
+	for key, vs := range a.versions {
+		i := sort.Search(len(vs), func(i int) bool { return vs[i].Height >= height })
+		if i == len(vs) || vs[i].Height != height {
+			continue
+		}
+		var prev *stateVersion
+		if i > 0 && !vs[i-1].Deleted {
+			prev = &vs[i-1]
+		}
+		cur := vs[i]
+		switch {
+		case strings.HasPrefix(key, archState):
+			sd := StorageDiff{Key: hex.EncodeToString([]byte(strings.TrimPrefix(key, archState))), Created: prev == nil, Deleted: cur.Deleted}
+			if prev != nil {
+				sd.Before = hex.EncodeToString(prev.Value)
+			}
+			if !cur.Deleted {
+				sd.After = hex.EncodeToString(cur.Value)
+			}
+			d.Storage = append(d.Storage, sd)
+		case strings.HasPrefix(key, archBalance):
+			ad := AccountDiff{Address: "0x" + strings.TrimPrefix(key, archBalance)}
+			if prev != nil && len(prev.Value) == 8 {
+				ad.Before = binary.BigEndian.Uint64(prev.Value)
+			}
+			if !cur.Deleted && len(cur.Value) == 8 {
+				ad.After = binary.BigEndian.Uint64(cur.Value)
+			}
+			ad.Delta = int64(ad.After) - int64(ad.Before)
+			d.Accounts = append(d.Accounts, ad)
+		case strings.HasPrefix(key, archContract):
+			addr := "0x" + strings.TrimPrefix(key, archContract)
+			switch {
+			case cur.Deleted:
+				d.ContractsDestroyed = append(d.ContractsDestroyed, addr)
+			case prev == nil:
+				d.ContractsCreated = append(d.ContractsCreated, addr)
+			}
+		}
+	}
+	d.sort()
+	return d, nil
+}
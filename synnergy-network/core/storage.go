@@ -19,6 +19,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"sync"
 	"time"
 )
 
@@ -69,6 +70,25 @@ func (l *diskLRU) put(cid string, data []byte) error {
 	return nil
 }
 
+// remove drops a CID from the cache and deletes its file.
+func (l *diskLRU) remove(cid string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	ent, ok := l.index[cid]
+	if !ok {
+		return
+	}
+	_ = os.Remove(ent.path)
+	delete(l.index, cid)
+	for i, e := range l.order {
+		if e == ent {
+			l.order = append(l.order[:i], l.order[i+1:]...)
+			break
+		}
+	}
+}
+
 func (l *diskLRU) get(cid string) ([]byte, bool) {
 	l.mu.Lock()
 	defer l.mu.Unlock()
@@ -117,7 +137,10 @@ func NewStorage(cfg *StorageConfig, lg *logrus.Logger, led MeteredState) (*Stora
 // Public API — Pin & Retrieve
 // -----------------------------------------------------------------------------
 
-// Pin uploads data to IPFS gateway, returns CID and byte-length.
+// Pin uploads data to IPFS gateway, returns CID and byte-length. With a
+// ledger the payer locks the storage deposit for the bytes first (see
+// ChargeStorageRent) and keeps it until Unpin; a payer pinning content it
+// already holds pays nothing more.
 func (s *Storage) Pin(ctx context.Context, data []byte, payer Address) (string, int64, error) {
 	// Compute deterministic CID locally.
 	encodedMH, err := mh.Sum(data, mh.SHA2_256, -1)
@@ -126,16 +149,40 @@ func (s *Storage) Pin(ctx context.Context, data []byte, payer Address) (string,
 	}
 	c := cid.NewCidV1(cid.Raw, encodedMH)
 	cidStr := c.String() // ← String() gives lower-case Base32-CIDv1
+	size := int64(len(data))
 
+	if s.ledger != nil {
+		held, err := s.holdPin(cidStr, payer, size)
+		if err != nil {
+			return "", 0, err
+		}
+		if held {
+			return cidStr, size, nil
+		}
+	}
+	if err := s.upload(ctx, cidStr, data); err != nil {
+		if s.ledger != nil {
+			if _, rerr := s.dropPin(cidStr, payer); rerr != nil {
+				s.logger.Printf("storage deposit refund failed: %v", rerr)
+			}
+		}
+		return "", 0, err
+	}
+	s.logger.Printf("pinned CID %s (%d bytes)", cidStr, len(data))
+	return cidStr, size, nil
+}
+
+// upload sends data to the gateway's pinset unless it is cached.
+func (s *Storage) upload(ctx context.Context, cidStr string, data []byte) error {
 	// Already cached?
 	if _, ok := s.cache.get(cidStr); ok {
-		return cidStr, int64(len(data)), nil
+		return nil
 	}
 
 	// ----------------- pin via gateway -----------------
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.pinEndpoint, bytes.NewReader(data))
 	if err != nil {
-		return "", 0, err
+		return err
 	}
 	req.Header.Set("Content-Type", "application/octet-stream")
 
@@ -144,12 +191,12 @@ func (s *Storage) Pin(ctx context.Context, data []byte, payer Address) (string,
 		if resp != nil {
 			resp.Body.Close()
 		}
-		return "", 0, err
+		return err
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
 		b, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
-		return "", 0, fmt.Errorf("gateway pin %d: %s", resp.StatusCode, string(b))
+		return fmt.Errorf("gateway pin %d: %s", resp.StatusCode, string(b))
 	}
 
 	var meta struct {
@@ -157,24 +204,136 @@ func (s *Storage) Pin(ctx context.Context, data []byte, payer Address) (string,
 		Size string `json:"size"`
 	}
 	if err := json.NewDecoder(resp.Body).Decode(&meta); err != nil {
-		return "", 0, fmt.Errorf("decode: %w", err)
+		return fmt.Errorf("decode: %w", err)
 	}
 	if meta.Hash != cidStr {
-		return "", 0, errors.New("cid mismatch between local and gateway")
+		return errors.New("cid mismatch between local and gateway")
 	}
 
 	// Cache locally (best-effort).
 	_ = s.cache.put(cidStr, data)
+	return nil
+}
 
-	// Charge gas if ledger provided.
+// Unpin releases payer's pin of cidStr and refunds its storage deposit.
+// Content stays in the gateway's pinset while other payers hold it.
+func (s *Storage) Unpin(ctx context.Context, cidStr string, payer Address) error {
 	if s.ledger != nil {
-		if err := s.ledger.ChargeStorageRent(payer, int64(len(data))); err != nil {
-			s.logger.Printf("storage rent charge failed: %v", err)
+		last, err := s.dropPin(cidStr, payer)
+		if err != nil {
+			return err
+		}
+		if !last {
+			return nil
 		}
 	}
+	if err := s.unpinGateway(ctx, cidStr); err != nil {
+		return err
+	}
+	s.logger.Printf("unpinned CID %s", cidStr)
+	return nil
+}
 
-	s.logger.Printf("pinned CID %s (%d bytes)", cidStr, len(data))
-	return cidStr, int64(len(data)), nil
+// unpinGateway removes cidStr from the gateway's pinset and the cache.
+func (s *Storage) unpinGateway(ctx context.Context, cidStr string) error {
+	url := fmt.Sprintf("%s/api/v0/pin/rm?arg=%s", s.cfg.IPFSGateway, cidStr)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 128))
+		return fmt.Errorf("gateway unpin %d: %s", resp.StatusCode, string(b))
+	}
+	s.cache.remove(cidStr)
+	return nil
+}
+
+// storagePin is who holds a deposit for pinned content, kept in ledger
+// state under storagePinKey.
+type storagePin struct {
+	Size   int64     `json:"size"`
+	Payers []Address `json:"payers"`
+}
+
+func storagePinKey(cidStr string) []byte { return []byte("storage:pin:" + cidStr) }
+
+// pinMu serialises updates of pin records.
+var pinMu sync.Mutex
+
+func (s *Storage) pinRecord(cidStr string) (storagePin, error) {
+	var rec storagePin
+	raw, err := s.ledger.GetState(storagePinKey(cidStr))
+	if err != nil || len(raw) == 0 {
+		return rec, nil
+	}
+	if err := json.Unmarshal(raw, &rec); err != nil {
+		return rec, fmt.Errorf("decode pin %s: %w", cidStr, err)
+	}
+	return rec, nil
+}
+
+// holdPin records payer as a holder of cidStr, locking the deposit for size
+// bytes. It reports whether payer held it already.
+func (s *Storage) holdPin(cidStr string, payer Address, size int64) (bool, error) {
+	pinMu.Lock()
+	defer pinMu.Unlock()
+	rec, err := s.pinRecord(cidStr)
+	if err != nil {
+		return false, err
+	}
+	for _, p := range rec.Payers {
+		if p == payer {
+			return true, nil
+		}
+	}
+	if err := s.ledger.ChargeStorageRent(payer, size); err != nil {
+		return false, fmt.Errorf("storage deposit: %w", err)
+	}
+	rec.Size, rec.Payers = size, append(rec.Payers, payer)
+	raw, _ := json.Marshal(rec)
+	if err := s.ledger.SetState(storagePinKey(cidStr), raw); err != nil {
+		_ = s.ledger.ChargeStorageRent(payer, -size)
+		return false, err
+	}
+	return false, nil
+}
+
+// dropPin removes payer from the holders of cidStr and refunds its deposit.
+// It reports whether no holder is left.
+func (s *Storage) dropPin(cidStr string, payer Address) (bool, error) {
+	pinMu.Lock()
+	defer pinMu.Unlock()
+	rec, err := s.pinRecord(cidStr)
+	if err != nil {
+		return false, err
+	}
+	i := 0
+	for i < len(rec.Payers) && rec.Payers[i] != payer {
+		i++
+	}
+	if i == len(rec.Payers) {
+		return false, NewError(CodeNotFound, "storage", "%s holds no pin of %s", payer.Hex(), cidStr)
+	}
+	rec.Payers = append(rec.Payers[:i], rec.Payers[i+1:]...)
+	if len(rec.Payers) == 0 {
+		err = s.ledger.DeleteState(storagePinKey(cidStr))
+	} else {
+		raw, _ := json.Marshal(rec)
+		err = s.ledger.SetState(storagePinKey(cidStr), raw)
+	}
+	if err != nil {
+		return false, err
+	}
+	if err := s.ledger.ChargeStorageRent(payer, -rec.Size); err != nil {
+		return false, err
+	}
+	return len(rec.Payers) == 0, nil
 }
 
 // Retrieve returns data for CID (cache → gateway fallback).
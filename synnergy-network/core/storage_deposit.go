@@ -0,0 +1,323 @@
+package core
+
+// storage_deposit.go – storage deposits and reclamation of underfunded state.
+//
+// Stored bytes are paid for with a refundable deposit rather than burnt
+// rent. Writing state locks DepositPerByte for every byte in
+// StorageDepositAccount; shrinking or deleting it refunds the difference.
+// Each owner has a StorageAccount recording its entries, total bytes and
+// locked deposit.
+//
+// An account whose deposit is below what its bytes require – because
+// governance raised the rate after the data was written – is underfunded.
+// SweepStorage records when each account became underfunded; TopUpStorage
+// restores it. One that stays underfunded for ReclaimAfter seconds is
+// reclaimable: anyone may PurgeStorage it, which deletes its keyed entries,
+// pays the purger ReaperRewardBps of the deposit and burns the rest.
+//
+// The policy is governed through the storage_policy parameter with a JSON
+// StoragePolicy value.
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrStorageNotReclaimable is returned when purging an account that is
+// funded or has not been underfunded for long enough.
+var ErrStorageNotReclaimable = errors.New("storage account not reclaimable")
+
+// StorageDepositAccount holds the locked storage deposits.
+var StorageDepositAccount = ModuleAddress("storage_deposit")
+
+const (
+	storagePolicyKey  = "storage:policy"
+	storageAcctPrefix = "storage:acct:"
+
+	maxReaperRewardBps = 5_000
+)
+
+// StoragePolicy is the governed storage deposit configuration.
+type StoragePolicy struct {
+	DepositPerByte  uint64 `json:"deposit_per_byte"`
+	ReclaimAfter    int64  `json:"reclaim_after"` // seconds underfunded before purge
+	ReaperRewardBps uint16 `json:"reaper_reward_bps"`
+}
+
+// DefaultStoragePolicy is in effect until governance sets one.
+func DefaultStoragePolicy() StoragePolicy {
+	return StoragePolicy{DepositPerByte: 1, ReclaimAfter: int64(30 * 24 * time.Hour / time.Second), ReaperRewardBps: 1_000}
+}
+
+// Validate checks the policy parameters.
+func (p StoragePolicy) Validate() error {
+	if p.ReclaimAfter <= 0 {
+		return errors.New("reclaim_after must be positive")
+	}
+	if p.ReaperRewardBps > maxReaperRewardBps {
+		return fmt.Errorf("reaper reward must be at most %d bps", maxReaperRewardBps)
+	}
+	return nil
+}
+
+// StorageAccount is the storage an owner pays a deposit for.
+type StorageAccount struct {
+	Owner      Address           `json:"owner"`
+	Bytes      uint64            `json:"bytes"`
+	Deposit    uint64            `json:"deposit"`
+	Entries    map[string]uint64 `json:"entries,omitempty"` // state key -> bytes
+	LowSince   int64             `json:"low_since,omitempty"`
+	LastActive int64             `json:"last_active"`
+}
+
+// Required is the deposit the account's bytes need under p.
+func (a StorageAccount) Required(p StoragePolicy) uint64 {
+	return a.Bytes * p.DepositPerByte
+}
+
+// StoragePurge reports a reclaimed account.
+type StoragePurge struct {
+	Owner     Address `json:"owner"`
+	Entries   int     `json:"entries"`
+	Bytes     uint64  `json:"bytes"`
+	Forfeited uint64  `json:"forfeited"`
+	Reward    uint64  `json:"reward"`
+}
+
+// storageMu serialises deposit accounting; ledger calls lock separately.
+var storageMu sync.Mutex
+
+func storageAcctKey(owner Address) []byte {
+	return []byte(storageAcctPrefix + owner.Hex())
+}
+
+// setStoragePolicy stores the policy. Only reached via governance.
+func setStoragePolicy(value string) error {
+	var p StoragePolicy
+	if err := json.Unmarshal([]byte(value), &p); err != nil {
+		return fmt.Errorf("invalid storage policy: %w", err)
+	}
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	led := CurrentLedger()
+	if led == nil {
+		return errors.New("ledger not initialised")
+	}
+	raw, _ := json.Marshal(p)
+	return led.SetState([]byte(storagePolicyKey), raw)
+}
+
+// StoragePolicy returns the policy in effect.
+func (l *Ledger) StoragePolicy() StoragePolicy {
+	raw, err := l.GetState([]byte(storagePolicyKey))
+	if err == nil && len(raw) > 0 {
+		var p StoragePolicy
+		if json.Unmarshal(raw, &p) == nil {
+			return p
+		}
+	}
+	return DefaultStoragePolicy()
+}
+
+// StorageAccount returns owner's storage account; an owner storing nothing
+// has an empty one.
+func (l *Ledger) StorageAccount(owner Address) (StorageAccount, error) {
+	raw, err := l.GetState(storageAcctKey(owner))
+	if err != nil || len(raw) == 0 {
+		return StorageAccount{Owner: owner}, nil
+	}
+	var a StorageAccount
+	if err := json.Unmarshal(raw, &a); err != nil {
+		return a, fmt.Errorf("decode storage account: %w", err)
+	}
+	return a, nil
+}
+
+func (l *Ledger) putStorageAccount(a StorageAccount) error {
+	if a.Bytes == 0 && a.Deposit == 0 {
+		return l.DeleteState(storageAcctKey(a.Owner))
+	}
+	raw, _ := json.Marshal(a)
+	return l.SetState(storageAcctKey(a.Owner), raw)
+}
+
+// settleStorage moves the deposit of a to what its bytes require, locking
+// the shortfall from the owner or refunding the excess. storageMu must be
+// held.
+func (l *Ledger) settleStorage(a *StorageAccount, p StoragePolicy, now time.Time) error {
+	need := a.Required(p)
+	switch {
+	case need > a.Deposit:
+		if err := l.Transfer(a.Owner, StorageDepositAccount, need-a.Deposit); err != nil {
+			return NewError(CodeInsufficientFunds, "storage", "deposit of %d for %d bytes: %v", need-a.Deposit, a.Bytes, err)
+		}
+	case need < a.Deposit:
+		if err := l.Transfer(StorageDepositAccount, a.Owner, a.Deposit-need); err != nil {
+			return err
+		}
+	}
+	a.Deposit, a.LowSince, a.LastActive = need, 0, now.Unix()
+	return l.putStorageAccount(*a)
+}
+
+// LockStorageDeposit records that owner stores size bytes under key and
+// settles the deposit, refunding it when the entry shrinks.
+func (l *Ledger) LockStorageDeposit(owner Address, key string, size uint64) error {
+	storageMu.Lock()
+	defer storageMu.Unlock()
+	a, err := l.StorageAccount(owner)
+	if err != nil {
+		return err
+	}
+	if a.Entries == nil {
+		a.Entries = make(map[string]uint64)
+	}
+	a.Bytes = a.Bytes - a.Entries[key] + size
+	a.Entries[key] = size
+	return l.settleStorage(&a, l.StoragePolicy(), time.Now())
+}
+
+// ReleaseStorageDeposit drops owner's entry under key and refunds its
+// deposit.
+func (l *Ledger) ReleaseStorageDeposit(owner Address, key string) error {
+	storageMu.Lock()
+	defer storageMu.Unlock()
+	a, err := l.StorageAccount(owner)
+	if err != nil {
+		return err
+	}
+	size, ok := a.Entries[key]
+	if !ok {
+		return nil
+	}
+	delete(a.Entries, key)
+	a.Bytes -= size
+	return l.settleStorage(&a, l.StoragePolicy(), time.Now())
+}
+
+// ChargeStorageRent locks the deposit for bytes stored by addr outside the
+// keyed state, such as pinned content; negative bytes release it.
+func (l *Ledger) ChargeStorageRent(addr Address, bytes int64) error {
+	if bytes == 0 {
+		return nil
+	}
+	storageMu.Lock()
+	defer storageMu.Unlock()
+	a, err := l.StorageAccount(addr)
+	if err != nil {
+		return err
+	}
+	if bytes > 0 {
+		a.Bytes += uint64(bytes)
+	} else {
+		keyed := uint64(0)
+		for _, n := range a.Entries {
+			keyed += n
+		}
+		if free := a.Bytes - keyed; uint64(-bytes) > free {
+			bytes = -int64(free)
+		}
+		a.Bytes -= uint64(-bytes)
+	}
+	return l.settleStorage(&a, l.StoragePolicy(), time.Now())
+}
+
+// TopUpStorage locks what owner's deposit falls short of the current rate,
+// making the account no longer reclaimable.
+func (l *Ledger) TopUpStorage(owner Address) (StorageAccount, error) {
+	storageMu.Lock()
+	defer storageMu.Unlock()
+	a, err := l.StorageAccount(owner)
+	if err != nil {
+		return a, err
+	}
+	if a.Deposit >= a.Required(l.StoragePolicy()) {
+		return a, nil
+	}
+	err = l.settleStorage(&a, l.StoragePolicy(), time.Now())
+	return a, err
+}
+
+// SweepStorage marks accounts that have become underfunded and clears the
+// mark of those funded again. It returns the accounts reclaimable at now,
+// sorted.
+func (l *Ledger) SweepStorage(now time.Time) ([]Address, error) {
+	storageMu.Lock()
+	defer storageMu.Unlock()
+	p := l.StoragePolicy()
+	it := l.PrefixIterator([]byte(storageAcctPrefix))
+	var out []Address
+	for it.Next() {
+		var a StorageAccount
+		if err := json.Unmarshal(it.Value(), &a); err != nil {
+			return nil, fmt.Errorf("decode %s: %w", strings.TrimPrefix(string(it.Key()), storageAcctPrefix), err)
+		}
+		low := a.Deposit < a.Required(p)
+		switch {
+		case low && a.LowSince == 0:
+			a.LowSince = now.Unix()
+		case !low && a.LowSince != 0:
+			a.LowSince = 0
+		default:
+			if low && now.Unix()-a.LowSince >= p.ReclaimAfter {
+				out = append(out, a.Owner)
+			}
+			continue
+		}
+		if err := l.putStorageAccount(a); err != nil {
+			return nil, err
+		}
+	}
+	if err := it.Error(); err != nil {
+		return nil, err
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Hex() < out[j].Hex() })
+	return out, nil
+}
+
+// PurgeStorage reclaims an account underfunded for ReclaimAfter: its keyed
+// entries are deleted from the ledger state and the node store, reaper is
+// paid ReaperRewardBps of the deposit and the rest is burnt.
+func (l *Ledger) PurgeStorage(owner, reaper Address, now time.Time) (*StoragePurge, error) {
+	storageMu.Lock()
+	defer storageMu.Unlock()
+	p := l.StoragePolicy()
+	a, err := l.StorageAccount(owner)
+	if err != nil {
+		return nil, err
+	}
+	if a.Deposit >= a.Required(p) || a.LowSince == 0 || now.Unix()-a.LowSince < p.ReclaimAfter {
+		return nil, ErrStorageNotReclaimable
+	}
+	store := CurrentStore()
+	for key := range a.Entries {
+		if err := l.DeleteState([]byte(key)); err != nil {
+			return nil, err
+		}
+		if store != nil {
+			_ = store.Delete([]byte(key))
+		}
+	}
+	res := &StoragePurge{Owner: owner, Entries: len(a.Entries), Bytes: a.Bytes, Forfeited: a.Deposit}
+	res.Reward = a.Deposit * uint64(p.ReaperRewardBps) / 10_000
+	if res.Reward > 0 {
+		if err := l.Transfer(StorageDepositAccount, reaper, res.Reward); err != nil {
+			return nil, err
+		}
+	}
+	if burn := a.Deposit - res.Reward; burn > 0 {
+		if err := l.Burn(StorageDepositAccount, burn); err != nil {
+			return nil, err
+		}
+	}
+	if err := l.DeleteState(storageAcctKey(owner)); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
@@ -0,0 +1,124 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func newDepositLedger(t *testing.T) *Ledger {
+	t.Helper()
+	cfg, _ := tmpLedgerConfig(t, nil)
+	l, err := NewLedger(cfg)
+	if err != nil {
+		t.Fatalf("ledger: %v", err)
+	}
+	return l
+}
+
+func setTestStoragePolicy(t *testing.T, l *Ledger, p StoragePolicy) {
+	t.Helper()
+	raw, _ := json.Marshal(p)
+	if err := l.SetState([]byte(storagePolicyKey), raw); err != nil {
+		t.Fatalf("policy: %v", err)
+	}
+}
+
+func TestStorageDepositLockAndRefund(t *testing.T) {
+	l := newDepositLedger(t)
+	owner := Address{0x01}
+	_ = l.Mint(owner, 1_000)
+
+	if err := l.LockStorageDeposit(owner, "k", 100); err != nil {
+		t.Fatalf("lock: %v", err)
+	}
+	if l.BalanceOf(owner) != 900 || l.BalanceOf(StorageDepositAccount) != 100 {
+		t.Fatalf("after lock: owner %d deposits %d", l.BalanceOf(owner), l.BalanceOf(StorageDepositAccount))
+	}
+	// shrinking the entry refunds the difference
+	if err := l.LockStorageDeposit(owner, "k", 40); err != nil {
+		t.Fatalf("shrink: %v", err)
+	}
+	if l.BalanceOf(owner) != 960 {
+		t.Fatalf("after shrink: owner %d", l.BalanceOf(owner))
+	}
+	// unkeyed bytes are charged and released; a release never reaches the
+	// keyed bytes
+	if err := l.ChargeStorageRent(owner, 50); err != nil {
+		t.Fatalf("charge: %v", err)
+	}
+	if err := l.ChargeStorageRent(owner, -80); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	a, _ := l.StorageAccount(owner)
+	if a.Bytes != 40 || a.Deposit != 40 || l.BalanceOf(owner) != 960 {
+		t.Fatalf("account %+v owner %d", a, l.BalanceOf(owner))
+	}
+	if err := l.ReleaseStorageDeposit(owner, "k"); err != nil {
+		t.Fatalf("release entry: %v", err)
+	}
+	if ok, _ := l.HasState(storageAcctKey(owner)); ok || l.BalanceOf(owner) != 1_000 || l.BalanceOf(StorageDepositAccount) != 0 {
+		t.Fatalf("after release: account kept %v, owner %d", ok, l.BalanceOf(owner))
+	}
+
+	poor := Address{0x02}
+	if err := l.LockStorageDeposit(poor, "k", 10); ErrorCodeOf(err) != CodeInsufficientFunds {
+		t.Fatalf("lock without funds: %v", err)
+	}
+	if ok, _ := l.HasState(storageAcctKey(poor)); ok {
+		t.Fatal("unfunded lock recorded an account")
+	}
+}
+
+func TestStorageSweepTopUpAndPurge(t *testing.T) {
+	l := newDepositLedger(t)
+	owner, funded, reaper := Address{0x01}, Address{0x02}, Address{0x03}
+	_ = l.Mint(owner, 100)
+	_ = l.Mint(funded, 1_000)
+	for _, a := range []Address{owner, funded} {
+		key := "data:" + a.Hex()
+		_ = l.SetState([]byte(key), []byte("x"))
+		if err := l.LockStorageDeposit(a, key, 100); err != nil {
+			t.Fatalf("lock: %v", err)
+		}
+	}
+
+	// governance doubles the rate: both accounts are now underfunded
+	setTestStoragePolicy(t, l, StoragePolicy{DepositPerByte: 2, ReclaimAfter: 60, ReaperRewardBps: 1_000})
+	start := time.Unix(1_700_000_000, 0)
+	if out, err := l.SweepStorage(start); err != nil || len(out) != 0 {
+		t.Fatalf("first sweep: %v %v", out, err)
+	}
+	if _, err := l.TopUpStorage(funded); err != nil {
+		t.Fatalf("top up: %v", err)
+	}
+	if _, err := l.PurgeStorage(owner, reaper, start.Add(59*time.Second)); !errors.Is(err, ErrStorageNotReclaimable) {
+		t.Fatalf("purge before ReclaimAfter: %v", err)
+	}
+	out, err := l.SweepStorage(start.Add(time.Minute))
+	if err != nil || len(out) != 1 || out[0] != owner {
+		t.Fatalf("sweep after ReclaimAfter: %v %v", out, err)
+	}
+	if _, err := l.PurgeStorage(funded, reaper, start.Add(time.Minute)); !errors.Is(err, ErrStorageNotReclaimable) {
+		t.Fatalf("purge of a topped-up account: %v", err)
+	}
+
+	res, err := l.PurgeStorage(owner, reaper, start.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("purge: %v", err)
+	}
+	if res.Entries != 1 || res.Forfeited != 100 || res.Reward != 10 || l.BalanceOf(reaper) != 10 {
+		t.Fatalf("purge %+v reaper %d", res, l.BalanceOf(reaper))
+	}
+	if ok, _ := l.HasState([]byte("data:" + owner.Hex())); ok {
+		t.Fatal("purged entry still stored")
+	}
+	if ok, _ := l.HasState(storageAcctKey(owner)); ok {
+		t.Fatal("purged account still stored")
+	}
+	// the topped-up deposit is all that stays locked
+	if l.BalanceOf(StorageDepositAccount) != 200 {
+		t.Fatalf("deposits %d want 200", l.BalanceOf(StorageDepositAccount))
+	}
+}
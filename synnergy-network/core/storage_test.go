@@ -0,0 +1,141 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	mh "github.com/multiformats/go-multihash"
+	"github.com/sirupsen/logrus"
+)
+
+// meteredLedger is the MeteredState Storage is given: pin records and
+// deposits go to the ledger, and nothing else is expected to be called.
+type meteredLedger struct {
+	StateRW
+	l *Ledger
+}
+
+func (m meteredLedger) GetState(k []byte) ([]byte, error) { return m.l.GetState(k) }
+func (m meteredLedger) SetState(k, v []byte) error        { return m.l.SetState(k, v) }
+func (m meteredLedger) DeleteState(k []byte) error        { return m.l.DeleteState(k) }
+func (m meteredLedger) Charge(Address, uint64) error      { return nil }
+func (m meteredLedger) ChargeStorageRent(a Address, n int64) error {
+	return m.l.ChargeStorageRent(a, n)
+}
+
+// testGateway answers IPFS add and pin/rm calls, failing adds when fail
+// is set.
+type testGateway struct {
+	adds, removes atomic.Int32
+	fail          atomic.Bool
+}
+
+func (g *testGateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/api/v0/add":
+		g.adds.Add(1)
+		if g.fail.Load() {
+			http.Error(w, "down", http.StatusBadGateway)
+			return
+		}
+		data, _ := io.ReadAll(r.Body)
+		sum, _ := mh.Sum(data, mh.SHA2_256, -1)
+		_ = json.NewEncoder(w).Encode(map[string]string{"hash": cid.NewCidV1(cid.Raw, sum).String()})
+	case "/api/v0/pin/rm":
+		g.removes.Add(1)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func newTestStorage(t *testing.T) (*Storage, *Ledger, *testGateway) {
+	t.Helper()
+	gw := &testGateway{}
+	srv := httptest.NewServer(gw)
+	t.Cleanup(srv.Close)
+	l := newDepositLedger(t)
+	s, err := NewStorage(&StorageConfig{CacheDir: t.TempDir(), IPFSGateway: srv.URL, GatewayTimeout: 5 * time.Second},
+		logrus.New(), meteredLedger{l: l})
+	if err != nil {
+		t.Fatalf("storage: %v", err)
+	}
+	return s, l, gw
+}
+
+func TestPinLocksDepositUntilUnpinned(t *testing.T) {
+	s, l, gw := newTestStorage(t)
+	ctx := context.Background()
+	alice, bob := Address{0x0a}, Address{0x0b}
+	_ = l.Mint(alice, 1_000)
+	_ = l.Mint(bob, 1_000)
+	data := make([]byte, 300)
+
+	id, size, err := s.Pin(ctx, data, alice)
+	if err != nil || size != 300 {
+		t.Fatalf("pin: %s %d %v", id, size, err)
+	}
+	if l.BalanceOf(alice) != 700 || l.BalanceOf(StorageDepositAccount) != 300 {
+		t.Fatalf("after pin: alice %d deposits %d", l.BalanceOf(alice), l.BalanceOf(StorageDepositAccount))
+	}
+	// pinning content already held costs nothing more
+	if _, _, err := s.Pin(ctx, data, alice); err != nil || l.BalanceOf(alice) != 700 {
+		t.Fatalf("repeat pin: %v alice %d", err, l.BalanceOf(alice))
+	}
+	if _, _, err := s.Pin(ctx, data, bob); err != nil || l.BalanceOf(bob) != 700 {
+		t.Fatalf("second payer: %v bob %d", err, l.BalanceOf(bob))
+	}
+
+	if err := s.Unpin(ctx, id, alice); err != nil {
+		t.Fatalf("unpin: %v", err)
+	}
+	if l.BalanceOf(alice) != 1_000 || gw.removes.Load() != 0 {
+		t.Fatalf("after first unpin: alice %d, %d gateway removals", l.BalanceOf(alice), gw.removes.Load())
+	}
+	if err := s.Unpin(ctx, id, alice); ErrorCodeOf(err) != CodeNotFound {
+		t.Fatalf("second unpin by the same payer: %v", err)
+	}
+	if err := s.Unpin(ctx, id, bob); err != nil {
+		t.Fatalf("last unpin: %v", err)
+	}
+	if l.BalanceOf(bob) != 1_000 || l.BalanceOf(StorageDepositAccount) != 0 || gw.removes.Load() != 1 {
+		t.Fatalf("after last unpin: bob %d deposits %d, %d gateway removals",
+			l.BalanceOf(bob), l.BalanceOf(StorageDepositAccount), gw.removes.Load())
+	}
+}
+
+func TestPinFailsWithoutDepositAndRefundsFailedUploads(t *testing.T) {
+	s, l, gw := newTestStorage(t)
+	ctx := context.Background()
+	payer := Address{0x0c}
+	_ = l.Mint(payer, 100)
+
+	if _, _, err := s.Pin(ctx, make([]byte, 101), payer); ErrorCodeOf(err) != CodeInsufficientFunds {
+		t.Fatalf("pin without the deposit: %v", err)
+	}
+	if gw.adds.Load() != 0 {
+		t.Fatal("content uploaded without a deposit")
+	}
+
+	gw.fail.Store(true)
+	if _, _, err := s.Pin(ctx, make([]byte, 50), payer); err == nil {
+		t.Fatal("pin succeeded while the gateway failed")
+	}
+	if l.BalanceOf(payer) != 100 || l.BalanceOf(StorageDepositAccount) != 0 {
+		t.Fatalf("failed upload kept the deposit: payer %d", l.BalanceOf(payer))
+	}
+	gw.fail.Store(false)
+	id, _, err := s.Pin(ctx, make([]byte, 50), payer)
+	if err != nil {
+		t.Fatalf("pin after the gateway recovered: %v", err)
+	}
+	if err := s.Unpin(ctx, id, Address{0x0d}); ErrorCodeOf(err) != CodeNotFound {
+		t.Fatalf("unpin by a payer without a pin: %v", err)
+	}
+}
@@ -0,0 +1,207 @@
+package core
+
+// supply.go – mint and burn accounting.
+//
+// Every asset has one burn path. Ledger assets, the coin and tokens minted
+// with MintToken, are burned by Burn and BurnToken, and a Transfer to
+// AddressZero is the same burn: the amount leaves the sender and is added
+// to the asset's burned total instead of being credited to an address that
+// no one can spend from. Registry tokens burn through their Burn method and
+// BaseToken.Transfer routes transfers to AddressZero there too. Escrows use
+// module accounts (ModuleAddress), never the zero address.
+//
+// The ledger records the lifetime minted and burned totals of each asset in
+// Supply, so minted - burned must always equal the sum of its balances;
+// the ledger/supply invariant checks that. Ledgers written before supply
+// accounting are back-filled on load, with balances held by AddressZero
+// counted as burned.
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ErrZeroAddress is returned when AddressZero is the source of a transfer
+// or the recipient of a mint.
+var ErrZeroAddress = errors.New("zero address")
+
+// SupplyTotals are the lifetime mint and burn totals of a ledger asset.
+type SupplyTotals struct {
+	Minted uint64 `json:"minted"`
+	Burned uint64 `json:"burned"`
+}
+
+// SupplyStats describes the supply of an asset.
+type SupplyStats struct {
+	Asset       string `json:"asset"`
+	Minted      uint64 `json:"minted"`
+	Burned      uint64 `json:"burned"`
+	Circulating uint64 `json:"circulating"` // minted - burned
+}
+
+// mint credits amt of the asset of k and records it as minted. The caller
+// holds l.mu.
+func (l *Ledger) mint(k BalanceKey, amt uint64) error {
+	if k.Addr == AddressZero {
+		return ErrZeroAddress
+	}
+	t := l.Supply[k.Token]
+	if amt > math.MaxUint64-t.Minted {
+		return fmt.Errorf("%w: minted supply of %s overflows", ErrBalanceInvariant, k.Token)
+	}
+	t.Minted += amt
+	l.setSupply(k.Token, t)
+	l.credit(k, amt)
+	return nil
+}
+
+// burn debits amt of the asset of k and records it as burned. It is the
+// only path by which ledger supply shrinks. The caller holds l.mu.
+func (l *Ledger) burn(k BalanceKey, amt uint64) error {
+	if err := l.debit(k, amt); err != nil {
+		return err
+	}
+	l.recordBurn(k.Token, amt)
+	return nil
+}
+
+func (l *Ledger) recordBurn(token LedgerAsset, amt uint64) {
+	t := l.Supply[token]
+	t.Burned += amt
+	l.setSupply(token, t)
+}
+
+func (l *Ledger) setSupply(token LedgerAsset, t SupplyTotals) {
+	if l.Supply == nil {
+		l.Supply = make(map[LedgerAsset]SupplyTotals)
+	}
+	l.Supply[token] = t
+}
+
+// move transfers amt of the asset of from to to, burning it when to is
+// AddressZero. The caller holds l.mu.
+func (l *Ledger) move(from BalanceKey, to Address, amt uint64) error {
+	if from.Addr == AddressZero {
+		return ErrZeroAddress
+	}
+	if to == AddressZero {
+		return l.burn(from, amt)
+	}
+	if err := l.debit(from, amt); err != nil {
+		return err
+	}
+	l.credit(TokenKey(from.Token, to), amt)
+	return nil
+}
+
+// backfillSupply initialises the totals of assets the ledger holds but has
+// no supply record for, as after loading a ledger written before supply
+// accounting. Balances held by AddressZero are burned. The caller holds
+// l.mu or owns l exclusively.
+func (l *Ledger) backfillSupply() {
+	held := make(map[LedgerAsset]uint64)
+	for k, v := range l.Balances {
+		if _, ok := l.Supply[k.Token]; !ok {
+			held[k.Token] += v
+		}
+	}
+	for token, total := range held {
+		zero := TokenKey(token, AddressZero)
+		burned := l.Balances[zero]
+		delete(l.Balances, zero)
+		l.setSupply(token, SupplyTotals{Minted: total, Burned: burned})
+		logrus.Infof("supply: back-filled %s: %d minted, %d burned", token, total, burned)
+	}
+}
+
+// SupplyOf returns the supply of a ledger asset.
+func (l *Ledger) SupplyOf(asset LedgerAsset) SupplyStats {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	t := l.Supply[asset]
+	return SupplyStats{Asset: string(asset), Minted: t.Minted, Burned: t.Burned, Circulating: t.Minted - t.Burned}
+}
+
+// CirculatingSupply returns the minted, unburned supply of a ledger asset.
+func (l *Ledger) CirculatingSupply(asset LedgerAsset) uint64 { return l.SupplyOf(asset).Circulating }
+
+// TotalBurned returns the amount of a ledger asset burned so far.
+func (l *Ledger) TotalBurned(asset LedgerAsset) uint64 { return l.SupplyOf(asset).Burned }
+
+// checkSupply verifies that every asset's minted - burned equals the sum of
+// its balances and that AddressZero holds nothing. The caller holds l.mu.
+func (l *Ledger) checkSupply() error {
+	held := make(map[LedgerAsset]uint64)
+	for k, v := range l.Balances {
+		if k.Addr == AddressZero && v > 0 {
+			return fmt.Errorf("%w: zero address holds %d %s", ErrBalanceInvariant, v, k.Token)
+		}
+		held[k.Token] += v
+	}
+	for token, t := range l.Supply {
+		if t.Burned > t.Minted || t.Minted-t.Burned != held[token] {
+			return fmt.Errorf("%w: %s minted %d, burned %d, held %d", ErrBalanceInvariant, token, t.Minted, t.Burned, held[token])
+		}
+		delete(held, token)
+	}
+	for token, v := range held {
+		if v > 0 {
+			return fmt.Errorf("%w: %d %s held without a supply record", ErrBalanceInvariant, v, token)
+		}
+	}
+	return nil
+}
+
+// AssetSupplyStats returns the supply of a ledger asset of the current
+// ledger or of a registry token, given by symbol or numeric ID.
+func AssetSupplyStats(asset string) (SupplyStats, error) {
+	if id, ok := registryTokenID(asset); ok {
+		return TokenSupply(id)
+	}
+	led := CurrentLedger()
+	if led == nil {
+		return SupplyStats{}, NewError(CodeUnavailable, "supply", "ledger not initialised")
+	}
+	return led.SupplyOf(LedgerAsset(asset)), nil
+}
+
+// TokenSupply returns the supply of a registry token.
+func TokenSupply(id TokenID) (SupplyStats, error) {
+	t, ok := GetToken(id)
+	if !ok {
+		return SupplyStats{}, ErrNotFound
+	}
+	s, ok := t.(interface{ Supply() SupplyStats })
+	if !ok {
+		return SupplyStats{}, fmt.Errorf("token %d does not account its supply", id)
+	}
+	return s.Supply(), nil
+}
+
+// CirculatingSupply returns the circulating supply of an asset, as
+// AssetSupplyStats.
+func CirculatingSupply(asset string) (uint64, error) {
+	s, err := AssetSupplyStats(asset)
+	return s.Circulating, err
+}
+
+// TotalBurned returns the burned amount of an asset, as AssetSupplyStats.
+func TotalBurned(asset string) (uint64, error) {
+	s, err := AssetSupplyStats(asset)
+	return s.Burned, err
+}
+
+func init() { RegisterInvariant("ledger", "supply", checkLedgerSupply) }
+
+// checkLedgerSupply runs the balance and supply checks on the current
+// ledger.
+func checkLedgerSupply() error {
+	led := CurrentLedger()
+	if led == nil {
+		return nil
+	}
+	return led.CheckBalanceInvariants()
+}
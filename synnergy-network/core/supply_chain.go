@@ -8,22 +8,36 @@ import (
 	"time"
 )
 
-// SupplyItem represents a tracked asset in the supply chain.
+// SupplyItem represents a tracked asset in the supply chain. Items form a
+// hierarchy: a batch may contain lots which in turn contain units. Custody
+// of a parent moves its children with it.
 type SupplyItem struct {
-	ID          string    `json:"id"`
-	Description string    `json:"description"`
-	Owner       Address   `json:"owner"`
-	Location    string    `json:"location"`
-	Status      string    `json:"status"`
-	Updated     time.Time `json:"updated"`
+	ID          string         `json:"id"`
+	Description string         `json:"description"`
+	Owner       Address        `json:"owner"`
+	Location    string         `json:"location"`
+	Status      string         `json:"status"`
+	Kind        string         `json:"kind,omitempty"`
+	Parent      string         `json:"parent,omitempty"`
+	Sensors     []SupplySensor `json:"sensors,omitempty"`
+	Updated     time.Time      `json:"updated"`
 }
 
 var supplyMu sync.RWMutex
 
 // RegisterItem stores a new SupplyItem on the ledger and broadcasts the event.
+// When Parent is set the parent must already exist.
 func RegisterItem(item SupplyItem) error {
+	if item.ID == "" {
+		return errors.New("item id required")
+	}
 	supplyMu.Lock()
 	defer supplyMu.Unlock()
+	if item.Parent != "" {
+		if _, err := fetchItem(item.Parent); err != nil {
+			return fmt.Errorf("parent %s: %w", item.Parent, err)
+		}
+	}
 	key := fmt.Sprintf("supply:item:%s", item.ID)
 	if _, err := CurrentStore().Get([]byte(key)); err == nil {
 		return fmt.Errorf("item %s already exists", item.ID)
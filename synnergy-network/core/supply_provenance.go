@@ -0,0 +1,440 @@
+package core
+
+// supply_provenance.go – custody chain and sensor attestation for supply items.
+//
+// Custody of an item changes hands only when both the current holder and the
+// recipient sign CustodyMessage with their ed25519 keys. Each accepted
+// transfer is stored as a CustodyRecord with a per-item sequence number so a
+// signed handover cannot be replayed. Transferring a batch also moves every
+// descendant lot or unit still held by the sender.
+//
+// Sensors registered with the Sensors module can be attached to an item
+// together with min/max thresholds. Readings are JSON objects of numeric
+// metrics such as {"temperature": 4.2, "humidity": 61}. Every reading is
+// stored against the item and any metric outside its thresholds raises a
+// SupplyAlert, broadcast on "supply_alert".
+//
+// Provenance returns an item's ancestors, the signed custody chain of the
+// item and its ancestors, sensor readings and alerts.
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// ErrCustodySignature is returned when a custody transfer is not signed by
+// both parties.
+var ErrCustodySignature = errors.New("invalid custody signature")
+
+// SupplySensor attaches a sensor to an item with per-metric thresholds.
+type SupplySensor struct {
+	SensorID   string            `json:"sensor_id"`
+	Thresholds []SupplyThreshold `json:"thresholds,omitempty"`
+}
+
+// SupplyThreshold bounds one reading metric to [Min, Max].
+type SupplyThreshold struct {
+	Metric string  `json:"metric"`
+	Min    float64 `json:"min"`
+	Max    float64 `json:"max"`
+}
+
+// CustodyRecord is one signed hand-over of an item.
+type CustodyRecord struct {
+	ItemID     string    `json:"item_id"`
+	Seq        uint64    `json:"seq"`
+	From       Address   `json:"from"`
+	To         Address   `json:"to"`
+	Location   string    `json:"location"`
+	Timestamp  int64     `json:"timestamp"`
+	FromPubKey []byte    `json:"from_pub_key"`
+	FromSig    []byte    `json:"from_sig"`
+	ToPubKey   []byte    `json:"to_pub_key"`
+	ToSig      []byte    `json:"to_sig"`
+	Recorded   time.Time `json:"recorded"`
+}
+
+// SupplyReading is a sensor reading attached to an item.
+type SupplyReading struct {
+	ItemID   string             `json:"item_id"`
+	SensorID string             `json:"sensor_id"`
+	Values   map[string]float64 `json:"values"`
+	Breaches []string           `json:"breaches,omitempty"`
+	Time     time.Time          `json:"time"`
+}
+
+// SupplyAlert records a threshold breach.
+type SupplyAlert struct {
+	ItemID   string    `json:"item_id"`
+	SensorID string    `json:"sensor_id"`
+	Metric   string    `json:"metric"`
+	Value    float64   `json:"value"`
+	Min      float64   `json:"min"`
+	Max      float64   `json:"max"`
+	Time     time.Time `json:"time"`
+}
+
+// ProvenanceReport is the full history of an item.
+type ProvenanceReport struct {
+	Item      SupplyItem      `json:"item"`
+	Ancestors []SupplyItem    `json:"ancestors,omitempty"`
+	Custody   []CustodyRecord `json:"custody"`
+	Readings  []SupplyReading `json:"readings"`
+	Alerts    []SupplyAlert   `json:"alerts"`
+	Verified  bool            `json:"verified"`
+}
+
+func custodyPrefix(id string) []byte { return []byte(fmt.Sprintf("supply:custody:%s:", id)) }
+func readingPrefix(id string) []byte { return []byte(fmt.Sprintf("supply:reading:%s:", id)) }
+func alertPrefix(id string) []byte   { return []byte(fmt.Sprintf("supply:alert:%s:", id)) }
+
+// CustodyMessage is the payload both parties sign to hand over item id.
+func CustodyMessage(id string, seq uint64, from, to Address, location string, timestamp int64) []byte {
+	return []byte("synnergy-custody:" + id + ":" + strconv.FormatUint(seq, 10) + ":" +
+		from.Hex() + ":" + to.Hex() + ":" + location + ":" + strconv.FormatInt(timestamp, 10))
+}
+
+func (r *CustodyRecord) verify() error {
+	msg := CustodyMessage(r.ItemID, r.Seq, r.From, r.To, r.Location, r.Timestamp)
+	for _, p := range []struct {
+		addr Address
+		pub  []byte
+		sig  []byte
+	}{{r.From, r.FromPubKey, r.FromSig}, {r.To, r.ToPubKey, r.ToSig}} {
+		if len(p.pub) != ed25519.PublicKeySize || Ed25519Address(p.pub) != p.addr ||
+			!ed25519.Verify(p.pub, msg, p.sig) {
+			return fmt.Errorf("%w for %s", ErrCustodySignature, p.addr.Hex())
+		}
+	}
+	return nil
+}
+
+func listCustody(id string) ([]CustodyRecord, error) {
+	it := CurrentStore().Iterator(custodyPrefix(id), nil)
+	defer it.Close()
+	var out []CustodyRecord
+	for it.Next() {
+		var r CustodyRecord
+		if err := json.Unmarshal(it.Value(), &r); err != nil {
+			return nil, err
+		}
+		out = append(out, r)
+	}
+	return out, it.Error()
+}
+
+// TransferCustody applies a hand-over signed by both parties. rec.Seq must
+// equal the number of earlier transfers of the item and rec.From must be the
+// current owner.
+func TransferCustody(rec CustodyRecord) error {
+	if rec.From == rec.To {
+		return errors.New("cannot transfer custody to self")
+	}
+	if d := time.Since(time.Unix(rec.Timestamp, 0)); d > time.Hour || d < -5*time.Minute {
+		return fmt.Errorf("%w: stale timestamp", ErrCustodySignature)
+	}
+	if err := rec.verify(); err != nil {
+		return err
+	}
+	supplyMu.Lock()
+	defer supplyMu.Unlock()
+	item, err := fetchItem(rec.ItemID)
+	if err != nil {
+		return err
+	}
+	if item.Owner != rec.From {
+		return ErrUnauthorized
+	}
+	chain, err := listCustody(rec.ItemID)
+	if err != nil {
+		return err
+	}
+	if rec.Seq != uint64(len(chain)) {
+		return fmt.Errorf("custody sequence %d, expected %d", rec.Seq, len(chain))
+	}
+	rec.Recorded = time.Now().UTC()
+	raw, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	key := append(custodyPrefix(rec.ItemID), fmt.Sprintf("%020d", rec.Seq)...)
+	if err := CurrentStore().Set(key, raw); err != nil {
+		return err
+	}
+	if err := moveCustody(item, rec.From, rec.To, rec.Location, rec.Recorded); err != nil {
+		return err
+	}
+	return Broadcast("supply_custody", raw)
+}
+
+// moveCustody reassigns item and every descendant held by from.
+func moveCustody(item *SupplyItem, from, to Address, location string, now time.Time) error {
+	item.Owner = to
+	if location != "" {
+		item.Location = location
+	}
+	item.Updated = now
+	if err := saveItem(*item); err != nil {
+		return err
+	}
+	children, err := supplyChildren(item.ID)
+	if err != nil {
+		return err
+	}
+	for i := range children {
+		if children[i].Owner != from {
+			continue
+		}
+		if err := moveCustody(&children[i], from, to, location, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func supplyChildren(id string) ([]SupplyItem, error) {
+	it := CurrentStore().Iterator([]byte("supply:item:"), nil)
+	defer it.Close()
+	var out []SupplyItem
+	for it.Next() {
+		var item SupplyItem
+		if err := json.Unmarshal(it.Value(), &item); err != nil {
+			continue
+		}
+		if item.Parent == id {
+			out = append(out, item)
+		}
+	}
+	return out, it.Error()
+}
+
+// SupplyChildren returns the direct children of a batch or lot.
+func SupplyChildren(id string) ([]SupplyItem, error) {
+	supplyMu.RLock()
+	defer supplyMu.RUnlock()
+	return supplyChildren(id)
+}
+
+// CustodyChain returns the signed custody records of an item in order.
+func CustodyChain(id string) ([]CustodyRecord, error) {
+	supplyMu.RLock()
+	defer supplyMu.RUnlock()
+	return listCustody(id)
+}
+
+// AttachSupplySensor links a registered sensor to an item. Attaching the same
+// sensor again replaces its thresholds.
+func AttachSupplySensor(id string, s SupplySensor) error {
+	if _, err := GetSensor(s.SensorID); err != nil {
+		return err
+	}
+	for _, t := range s.Thresholds {
+		if t.Metric == "" {
+			return errors.New("threshold metric required")
+		}
+		if t.Min > t.Max {
+			return fmt.Errorf("threshold %s: min above max", t.Metric)
+		}
+	}
+	supplyMu.Lock()
+	defer supplyMu.Unlock()
+	item, err := fetchItem(id)
+	if err != nil {
+		return err
+	}
+	replaced := false
+	for i := range item.Sensors {
+		if item.Sensors[i].SensorID == s.SensorID {
+			item.Sensors[i] = s
+			replaced = true
+		}
+	}
+	if !replaced {
+		item.Sensors = append(item.Sensors, s)
+	}
+	item.Updated = time.Now().UTC()
+	return saveItem(*item)
+}
+
+// RecordSupplyReading stores value through the Sensors module and attaches
+// it to item id, raising an alert for every breached threshold.
+func RecordSupplyReading(id, sensorID string, value []byte) (*SupplyReading, error) {
+	var values map[string]float64
+	if err := json.Unmarshal(value, &values); err != nil {
+		return nil, fmt.Errorf("reading must be a JSON object of numbers: %w", err)
+	}
+	supplyMu.Lock()
+	defer supplyMu.Unlock()
+	item, err := fetchItem(id)
+	if err != nil {
+		return nil, err
+	}
+	var att *SupplySensor
+	for i := range item.Sensors {
+		if item.Sensors[i].SensorID == sensorID {
+			att = &item.Sensors[i]
+		}
+	}
+	if att == nil {
+		return nil, fmt.Errorf("sensor %s not attached to %s", sensorID, id)
+	}
+	if err := UpdateSensorValue(sensorID, value); err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	rd := &SupplyReading{ItemID: id, SensorID: sensorID, Values: values, Time: now}
+	suffix := fmt.Sprintf("%020d", now.UnixNano())
+	for _, t := range att.Thresholds {
+		v, ok := values[t.Metric]
+		if !ok || (v >= t.Min && v <= t.Max) {
+			continue
+		}
+		rd.Breaches = append(rd.Breaches, t.Metric)
+		alert := SupplyAlert{ItemID: id, SensorID: sensorID, Metric: t.Metric, Value: v, Min: t.Min, Max: t.Max, Time: now}
+		raw, _ := json.Marshal(alert)
+		if err := CurrentStore().Set(append(alertPrefix(id), suffix+":"+t.Metric...), raw); err != nil {
+			return nil, err
+		}
+		_ = Broadcast("supply_alert", raw)
+	}
+	raw, _ := json.Marshal(rd)
+	if err := CurrentStore().Set(append(readingPrefix(id), suffix+":"+sensorID...), raw); err != nil {
+		return nil, err
+	}
+	return rd, nil
+}
+
+// PollSupplySensors polls every sensor attached to item id and records the
+// readings.
+func PollSupplySensors(id string) ([]SupplyReading, error) {
+	item, err := GetItem(id)
+	if err != nil {
+		return nil, err
+	}
+	var out []SupplyReading
+	for _, s := range item.Sensors {
+		data, err := PollSensor(s.SensorID)
+		if err != nil {
+			return out, fmt.Errorf("sensor %s: %w", s.SensorID, err)
+		}
+		rd, err := RecordSupplyReading(id, s.SensorID, data)
+		if err != nil {
+			return out, err
+		}
+		out = append(out, *rd)
+	}
+	return out, nil
+}
+
+func listSupplyReadings(id string) ([]SupplyReading, error) {
+	it := CurrentStore().Iterator(readingPrefix(id), nil)
+	defer it.Close()
+	var out []SupplyReading
+	for it.Next() {
+		var rd SupplyReading
+		if err := json.Unmarshal(it.Value(), &rd); err != nil {
+			return nil, err
+		}
+		out = append(out, rd)
+	}
+	return out, it.Error()
+}
+
+func listSupplyAlerts(id string) ([]SupplyAlert, error) {
+	it := CurrentStore().Iterator(alertPrefix(id), nil)
+	defer it.Close()
+	var out []SupplyAlert
+	for it.Next() {
+		var a SupplyAlert
+		if err := json.Unmarshal(it.Value(), &a); err != nil {
+			return nil, err
+		}
+		out = append(out, a)
+	}
+	return out, it.Error()
+}
+
+// SupplyAlerts returns the threshold breaches recorded for an item.
+func SupplyAlerts(id string) ([]SupplyAlert, error) {
+	supplyMu.RLock()
+	defer supplyMu.RUnlock()
+	return listSupplyAlerts(id)
+}
+
+// Provenance assembles the custody chain, readings and alerts of an item and
+// its ancestors. Verified reports whether every custody signature checks out
+// and each item's chain is continuous.
+func Provenance(id string) (*ProvenanceReport, error) {
+	supplyMu.RLock()
+	defer supplyMu.RUnlock()
+	item, err := fetchItem(id)
+	if err != nil {
+		return nil, err
+	}
+	rep := &ProvenanceReport{Item: *item, Verified: true}
+	seen := map[string]bool{id: true}
+	for p := item.Parent; p != "" && !seen[p]; {
+		seen[p] = true
+		anc, err := fetchItem(p)
+		if err != nil {
+			return nil, err
+		}
+		rep.Ancestors = append(rep.Ancestors, *anc)
+		p = anc.Parent
+	}
+	ids := []string{id}
+	for _, a := range rep.Ancestors {
+		ids = append(ids, a.ID)
+	}
+	for _, iid := range ids {
+		chain, err := listCustody(iid)
+		if err != nil {
+			return nil, err
+		}
+		for i := range chain {
+			if chain[i].verify() != nil || (i > 0 && chain[i].From != chain[i-1].To) {
+				rep.Verified = false
+			}
+		}
+		rep.Custody = append(rep.Custody, chain...)
+		readings, err := listSupplyReadings(iid)
+		if err != nil {
+			return nil, err
+		}
+		rep.Readings = append(rep.Readings, readings...)
+		alerts, err := listSupplyAlerts(iid)
+		if err != nil {
+			return nil, err
+		}
+		rep.Alerts = append(rep.Alerts, alerts...)
+	}
+	sort.SliceStable(rep.Custody, func(i, j int) bool { return rep.Custody[i].Recorded.Before(rep.Custody[j].Recorded) })
+	sort.SliceStable(rep.Readings, func(i, j int) bool { return rep.Readings[i].Time.Before(rep.Readings[j].Time) })
+	sort.SliceStable(rep.Alerts, func(i, j int) bool { return rep.Alerts[i].Time.Before(rep.Alerts[j].Time) })
+	return rep, nil
+}
+
+// SignCustody signs the hand-over described by rec with priv and fills in
+// the matching public key and signature for the sender or the recipient.
+func SignCustody(rec *CustodyRecord, priv ed25519.PrivateKey) error {
+	pub, ok := priv.Public().(ed25519.PublicKey)
+	if !ok {
+		return errors.New("invalid private key")
+	}
+	sig := ed25519.Sign(priv, CustodyMessage(rec.ItemID, rec.Seq, rec.From, rec.To, rec.Location, rec.Timestamp))
+	switch Ed25519Address(pub) {
+	case rec.From:
+		rec.FromPubKey, rec.FromSig = pub, sig
+	case rec.To:
+		rec.ToPubKey, rec.ToSig = pub, sig
+	default:
+		return fmt.Errorf("key %s is not a party to the transfer", hex.EncodeToString(pub))
+	}
+	return nil
+}
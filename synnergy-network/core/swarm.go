@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"sync"
+
+	"github.com/sirupsen/logrus"
 )
 
 // Swarm orchestrates multiple network nodes that share a ledger and optional
@@ -14,6 +16,7 @@ type Swarm struct {
 	ledger    *Ledger
 	consensus *SynnergyConsensus
 	nodes     map[NodeID]*Node
+	fleet     *SwarmController
 	mu        sync.RWMutex
 }
 
@@ -75,18 +78,44 @@ func (s *Swarm) Peers() []NodeID {
 	return ids
 }
 
-// Start launches the consensus engine if configured.
+// SetFleet attaches a controller for node processes managed by the swarm.
+func (s *Swarm) SetFleet(c *SwarmController) {
+	s.mu.Lock()
+	s.fleet = c
+	s.mu.Unlock()
+}
+
+// Fleet returns the attached process controller, or nil.
+func (s *Swarm) Fleet() *SwarmController {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.fleet
+}
+
+// Start launches the consensus engine if configured and starts the node
+// processes of the fleet with the configured stagger.
 func (s *Swarm) Start(ctx context.Context) {
 	if s.consensus != nil {
 		s.consensus.Start(ctx)
 	}
+	if f := s.Fleet(); f != nil {
+		if err := f.StartAll(ctx); err != nil {
+			logrus.Errorf("swarm: %v", err)
+		}
+	}
 }
 
-// Stop stops the consensus engine and closes all nodes.
+// Stop stops the consensus engine, the fleet's node processes and closes
+// all nodes.
 func (s *Swarm) Stop() {
 	if s.consensus != nil && s.consensus.cancel != nil {
 		s.consensus.cancel()
 	}
+	if f := s.Fleet(); f != nil {
+		if err := f.StopAll(context.Background()); err != nil {
+			logrus.Errorf("swarm: %v", err)
+		}
+	}
 	s.mu.Lock()
 	for id, n := range s.nodes {
 		_ = n.Close()
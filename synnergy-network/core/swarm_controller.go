@@ -0,0 +1,554 @@
+package core
+
+// swarm_controller.go – supervises a fleet of local node processes.
+//
+// The controller starts each configured node binary as a child process,
+// restarts crashed nodes with exponential backoff and performs rolling
+// binary upgrades one node at a time. A node counts as healthy once its
+// API /status endpoint answers (and reports the expected version during an
+// upgrade); an upgrade that does not become healthy is rolled back and the
+// rollout stops so the rest of the fleet keeps running the old binary.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// SwarmNodeSpec describes one node process of the fleet.
+type SwarmNodeSpec struct {
+	ID      NodeID   `json:"id"`
+	Binary  string   `json:"binary"`
+	Args    []string `json:"args,omitempty"`
+	Env     []string `json:"env,omitempty"`
+	Dir     string   `json:"dir,omitempty"`
+	APIAddr string   `json:"api_addr"` // host:port of the node's API server
+}
+
+// SwarmPolicy tunes the controller's timings.
+type SwarmPolicy struct {
+	Stagger       time.Duration `json:"stagger"`        // delay between node starts/stops
+	StopTimeout   time.Duration `json:"stop_timeout"`   // SIGTERM grace period before SIGKILL
+	HealthTimeout time.Duration `json:"health_timeout"` // how long a node may take to become healthy
+	MinBackoff    time.Duration `json:"min_backoff"`
+	MaxBackoff    time.Duration `json:"max_backoff"`
+	StableAfter   time.Duration `json:"stable_after"` // uptime that resets the restart backoff
+}
+
+// DefaultSwarmPolicy returns the policy used when none is configured.
+func DefaultSwarmPolicy() SwarmPolicy {
+	return SwarmPolicy{
+		Stagger:       2 * time.Second,
+		StopTimeout:   15 * time.Second,
+		HealthTimeout: time.Minute,
+		MinBackoff:    time.Second,
+		MaxBackoff:    time.Minute,
+		StableAfter:   5 * time.Minute,
+	}
+}
+
+// Swarm node process states.
+const (
+	SwarmNodeStopped   = "stopped"
+	SwarmNodeRunning   = "running"
+	SwarmNodeCrashed   = "crashed"
+	SwarmNodeUpgrading = "upgrading"
+)
+
+// SwarmNodeState is the status of a node process as reported by Status.
+type SwarmNodeState struct {
+	ID        NodeID    `json:"id"`
+	State     string    `json:"state"`
+	PID       int       `json:"pid,omitempty"`
+	Binary    string    `json:"binary"`
+	Restarts  int       `json:"restarts"`
+	StartedAt time.Time `json:"started_at,omitempty"`
+	LastError string    `json:"last_error,omitempty"`
+	Healthy   bool      `json:"healthy"`
+	Height    uint64    `json:"height"`
+	Peers     int       `json:"peers"`
+	Version   string    `json:"version,omitempty"`
+}
+
+type swarmProc struct {
+	spec      SwarmNodeSpec
+	cmd       *exec.Cmd
+	done      chan struct{}
+	state     string
+	wanted    bool // false once a stop was requested
+	restarts  int
+	backoff   time.Duration
+	startedAt time.Time
+	lastErr   string
+}
+
+// SwarmController manages the lifecycle of a fleet of node processes.
+type SwarmController struct {
+	mu     sync.Mutex
+	policy SwarmPolicy
+	nodes  map[NodeID]*swarmProc
+	order  []NodeID
+	client *http.Client
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// NewSwarmController creates a controller with the given policy. Zero
+// fields fall back to DefaultSwarmPolicy.
+func NewSwarmController(p SwarmPolicy) *SwarmController {
+	def := DefaultSwarmPolicy()
+	if p.Stagger <= 0 {
+		p.Stagger = def.Stagger
+	}
+	if p.StopTimeout <= 0 {
+		p.StopTimeout = def.StopTimeout
+	}
+	if p.HealthTimeout <= 0 {
+		p.HealthTimeout = def.HealthTimeout
+	}
+	if p.MinBackoff <= 0 {
+		p.MinBackoff = def.MinBackoff
+	}
+	if p.MaxBackoff < p.MinBackoff {
+		p.MaxBackoff = def.MaxBackoff
+	}
+	if p.StableAfter <= 0 {
+		p.StableAfter = def.StableAfter
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &SwarmController{
+		policy: p,
+		nodes:  make(map[NodeID]*swarmProc),
+		client: &http.Client{Timeout: 3 * time.Second},
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Policy returns the active controller policy.
+func (c *SwarmController) Policy() SwarmPolicy { return c.policy }
+
+// AddNode registers a node process. It is not started until Start or
+// StartAll is called.
+func (c *SwarmController) AddNode(spec SwarmNodeSpec) error {
+	if spec.ID == "" || spec.Binary == "" {
+		return fmt.Errorf("swarm: node id and binary required")
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.nodes[spec.ID]; ok {
+		return fmt.Errorf("swarm: node %s already exists", spec.ID)
+	}
+	c.nodes[spec.ID] = &swarmProc{spec: spec, state: SwarmNodeStopped, backoff: c.policy.MinBackoff}
+	c.order = append(c.order, spec.ID)
+	return nil
+}
+
+// RemoveNode stops the node process and forgets it.
+func (c *SwarmController) RemoveNode(id NodeID) error {
+	if err := c.Stop(id); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.nodes, id)
+	for i, n := range c.order {
+		if n == id {
+			c.order = append(c.order[:i], c.order[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// Start launches the node process and supervises it until Stop.
+func (c *SwarmController) Start(id NodeID) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	p, ok := c.nodes[id]
+	if !ok {
+		return fmt.Errorf("swarm: node %s not found", id)
+	}
+	if p.cmd != nil {
+		return nil
+	}
+	p.wanted = true
+	return c.spawn(p)
+}
+
+// spawn starts the process of p. Callers hold c.mu.
+func (c *SwarmController) spawn(p *swarmProc) error {
+	cmd := exec.Command(p.spec.Binary, p.spec.Args...)
+	cmd.Dir = p.spec.Dir
+	cmd.Env = append(os.Environ(), p.spec.Env...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		p.state = SwarmNodeCrashed
+		p.lastErr = err.Error()
+		return err
+	}
+	p.cmd = cmd
+	p.done = make(chan struct{})
+	p.startedAt = time.Now()
+	if p.state != SwarmNodeUpgrading {
+		p.state = SwarmNodeRunning
+	}
+	logrus.Infof("swarm: started %s (pid %d)", p.spec.ID, cmd.Process.Pid)
+	go c.supervise(p, cmd, p.done)
+	return nil
+}
+
+// supervise waits for the process to exit and restarts it with backoff if
+// the exit was not requested.
+func (c *SwarmController) supervise(p *swarmProc, cmd *exec.Cmd, done chan struct{}) {
+	err := cmd.Wait()
+	close(done)
+
+	c.mu.Lock()
+	if p.cmd != cmd {
+		c.mu.Unlock()
+		return
+	}
+	p.cmd = nil
+	if !p.wanted {
+		p.state = SwarmNodeStopped
+		c.mu.Unlock()
+		return
+	}
+	p.state = SwarmNodeCrashed
+	if err != nil {
+		p.lastErr = err.Error()
+	} else {
+		p.lastErr = "exited"
+	}
+	if time.Since(p.startedAt) >= c.policy.StableAfter {
+		p.backoff = c.policy.MinBackoff
+	}
+	delay, reason := p.backoff, p.lastErr
+	p.backoff *= 2
+	if p.backoff > c.policy.MaxBackoff {
+		p.backoff = c.policy.MaxBackoff
+	}
+	c.mu.Unlock()
+
+	logrus.Warnf("swarm: %s exited (%s), restarting in %s", p.spec.ID, reason, delay)
+	select {
+	case <-time.After(delay):
+	case <-c.ctx.Done():
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !p.wanted || p.cmd != nil {
+		return
+	}
+	p.restarts++
+	if err := c.spawn(p); err != nil {
+		logrus.Errorf("swarm: restart %s: %v", p.spec.ID, err)
+	}
+}
+
+// Stop terminates the node process, escalating to SIGKILL after the stop
+// timeout. The node is not restarted.
+func (c *SwarmController) Stop(id NodeID) error {
+	c.mu.Lock()
+	p, ok := c.nodes[id]
+	if !ok {
+		c.mu.Unlock()
+		return fmt.Errorf("swarm: node %s not found", id)
+	}
+	p.wanted = false
+	cmd, done := p.cmd, p.done
+	c.mu.Unlock()
+	if cmd == nil {
+		return nil
+	}
+
+	_ = cmd.Process.Signal(syscall.SIGTERM)
+	select {
+	case <-done:
+	case <-time.After(c.policy.StopTimeout):
+		logrus.Warnf("swarm: %s did not stop in %s, killing", id, c.policy.StopTimeout)
+		_ = cmd.Process.Kill()
+		<-done
+	}
+	return nil
+}
+
+// Nodes returns the node IDs in registration order.
+func (c *SwarmController) Nodes() []NodeID {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]NodeID(nil), c.order...)
+}
+
+// StartAll starts every node in registration order, waiting the stagger
+// delay between nodes.
+func (c *SwarmController) StartAll(ctx context.Context) error {
+	for i, id := range c.Nodes() {
+		if i > 0 {
+			if err := sleepCtx(ctx, c.policy.Stagger); err != nil {
+				return err
+			}
+		}
+		if err := c.Start(id); err != nil {
+			return fmt.Errorf("swarm: start %s: %w", id, err)
+		}
+	}
+	return nil
+}
+
+// StopAll stops every node in reverse registration order, waiting the
+// stagger delay between nodes.
+func (c *SwarmController) StopAll(ctx context.Context) error {
+	ids := c.Nodes()
+	for i := len(ids) - 1; i >= 0; i-- {
+		if i < len(ids)-1 {
+			if err := sleepCtx(ctx, c.policy.Stagger); err != nil {
+				return err
+			}
+		}
+		if err := c.Stop(ids[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close stops all nodes without staggering and ends supervision.
+func (c *SwarmController) Close() {
+	var wg sync.WaitGroup
+	for _, id := range c.Nodes() {
+		wg.Add(1)
+		go func(id NodeID) {
+			defer wg.Done()
+			_ = c.Stop(id)
+		}(id)
+	}
+	wg.Wait()
+	c.cancel()
+}
+
+// probe queries the node's /status endpoint.
+func (c *SwarmController) probe(ctx context.Context, spec SwarmNodeSpec) (NodeStatus, error) {
+	var st NodeStatus
+	if spec.APIAddr == "" {
+		return st, fmt.Errorf("no api address")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+spec.APIAddr+"/status", nil)
+	if err != nil {
+		return st, err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return st, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return st, fmt.Errorf("status %d", resp.StatusCode)
+	}
+	err = json.NewDecoder(resp.Body).Decode(&st)
+	return st, err
+}
+
+// waitHealthy polls the node until it answers /status with the wanted
+// version ("" accepts any) or the health timeout passes.
+func (c *SwarmController) waitHealthy(ctx context.Context, spec SwarmNodeSpec, version string) error {
+	ctx, cancel := context.WithTimeout(ctx, c.policy.HealthTimeout)
+	defer cancel()
+	var lastErr error
+	for {
+		st, err := c.probe(ctx, spec)
+		switch {
+		case err != nil:
+			lastErr = err
+		case version != "" && st.Version != version:
+			lastErr = fmt.Errorf("running version %s, want %s", st.Version, version)
+		default:
+			return nil
+		}
+		if err := sleepCtx(ctx, time.Second); err != nil {
+			return fmt.Errorf("swarm: %s not healthy: %v", spec.ID, lastErr)
+		}
+	}
+}
+
+// RollingUpgrade replaces the binary of every node, one at a time. Each
+// node is stopped, restarted on the new binary and must become healthy
+// (reporting version, if set) before the next node is touched. A node that
+// fails the health gate is rolled back to its previous binary and the
+// upgrade stops with an error.
+func (c *SwarmController) RollingUpgrade(ctx context.Context, binary, version string) error {
+	if binary == "" {
+		return fmt.Errorf("swarm: binary required")
+	}
+	if _, err := os.Stat(binary); err != nil {
+		return fmt.Errorf("swarm: %w", err)
+	}
+	for i, id := range c.Nodes() {
+		if i > 0 {
+			if err := sleepCtx(ctx, c.policy.Stagger); err != nil {
+				return err
+			}
+		}
+		if err := c.upgradeNode(ctx, id, binary, version); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *SwarmController) upgradeNode(ctx context.Context, id NodeID, binary, version string) error {
+	c.mu.Lock()
+	p, ok := c.nodes[id]
+	if !ok {
+		c.mu.Unlock()
+		return fmt.Errorf("swarm: node %s not found", id)
+	}
+	old := p.spec.Binary
+	c.mu.Unlock()
+	if old == binary {
+		return nil
+	}
+
+	logrus.Infof("swarm: upgrading %s to %s", id, binary)
+	if err := c.Stop(id); err != nil {
+		return err
+	}
+	if err := c.restartWith(p, binary); err != nil {
+		_ = c.restartWith(p, old)
+		return fmt.Errorf("swarm: upgrade %s: %w", id, err)
+	}
+	if err := c.waitHealthy(ctx, p.spec, version); err != nil {
+		logrus.Warnf("swarm: %s failed health gate, rolling back: %v", id, err)
+		_ = c.Stop(id)
+		if rerr := c.restartWith(p, old); rerr != nil {
+			return fmt.Errorf("swarm: upgrade %s: %v; rollback failed: %v", id, err, rerr)
+		}
+		return fmt.Errorf("swarm: upgrade %s rolled back: %w", id, err)
+	}
+	c.mu.Lock()
+	p.state = SwarmNodeRunning
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *SwarmController) restartWith(p *swarmProc, binary string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	p.spec.Binary = binary
+	p.state = SwarmNodeUpgrading
+	p.wanted = true
+	p.backoff = c.policy.MinBackoff
+	return c.spawn(p)
+}
+
+// Status returns the process state of every node together with the
+// height, peer count and version reported by its API.
+func (c *SwarmController) Status(ctx context.Context) []SwarmNodeState {
+	c.mu.Lock()
+	out := make([]SwarmNodeState, 0, len(c.order))
+	specs := make([]SwarmNodeSpec, 0, len(c.order))
+	for _, id := range c.order {
+		p := c.nodes[id]
+		st := SwarmNodeState{
+			ID:        id,
+			State:     p.state,
+			Binary:    p.spec.Binary,
+			Restarts:  p.restarts,
+			StartedAt: p.startedAt,
+			LastError: p.lastErr,
+		}
+		if p.cmd != nil {
+			st.PID = p.cmd.Process.Pid
+		}
+		out = append(out, st)
+		specs = append(specs, p.spec)
+	}
+	c.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for i := range out {
+		if out[i].PID == 0 {
+			continue
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if ns, err := c.probe(ctx, specs[i]); err == nil {
+				out[i].Healthy = true
+				out[i].Height = ns.Height
+				out[i].Peers = ns.Peers
+				out[i].Version = ns.Version
+			}
+		}(i)
+	}
+	wg.Wait()
+	return out
+}
+
+// ServeHTTP exposes the controller API:
+//
+//	GET  /status              per-node state, height, peers and version
+//	POST /upgrade             {"binary":..,"version":..} rolling upgrade
+//	POST /nodes/{id}/restart  stop and start one node
+func (c *SwarmController) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.URL.Path == "/status" && r.Method == http.MethodGet:
+		writeJSON(w, c.Status(r.Context()))
+	case r.URL.Path == "/upgrade" && r.Method == http.MethodPost:
+		var req struct {
+			Binary  string `json:"binary"`
+			Version string `json:"version"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			WriteHTTPError(w, http.StatusBadRequest, "swarm", err)
+			return
+		}
+		if err := c.RollingUpgrade(r.Context(), req.Binary, req.Version); err != nil {
+			WriteHTTPError(w, http.StatusConflict, "swarm", err)
+			return
+		}
+		writeJSON(w, c.Status(r.Context()))
+	case strings.HasPrefix(r.URL.Path, "/nodes/") && r.Method == http.MethodPost:
+		id, action, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/nodes/"), "/")
+		if action != "restart" {
+			http.NotFound(w, r)
+			return
+		}
+		if err := c.Stop(NodeID(id)); err != nil {
+			WriteHTTPError(w, http.StatusNotFound, "swarm", err)
+			return
+		}
+		if err := c.Start(NodeID(id)); err != nil {
+			WriteHTTPError(w, http.StatusInternalServerError, "swarm", err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
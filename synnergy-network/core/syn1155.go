@@ -29,6 +29,7 @@ type SYN1155Token struct {
 	balances  map[uint64]map[Address]uint64
 	approvals map[Address]map[Address]bool
 	hooks     []ReceiveHook
+	burned    uint64
 }
 
 // NewSYN1155Token creates a new multi asset token
@@ -154,10 +155,18 @@ func (t *SYN1155Token) BurnAsset(from Address, id uint64, amount uint64) error {
 	}
 	t.balances[id][from] -= amount
 	t.meta.TotalSupply -= amount
+	t.burned += amount
 	t.ledger.EmitTransfer(t.id, from, AddressZero, amount)
 	return nil
 }
 
+// Supply reports the supply across all asset IDs, as BaseToken.Supply.
+func (t *SYN1155Token) Supply() SupplyStats {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return SupplyStats{Asset: t.meta.Symbol, Minted: t.meta.TotalSupply + t.burned, Burned: t.burned, Circulating: t.meta.TotalSupply}
+}
+
 // RegisterHook attaches a callback for token reception
 func (t *SYN1155Token) RegisterHook(h ReceiveHook) {
 	t.mu.Lock()
@@ -90,9 +90,11 @@ func (s *SupplyFinanceToken) ListDocuments() []FinancialDocument {
 	return out
 }
 
+func syn2100LiquidityAccount() Address { return ModuleAddress("syn2100_liquidity") }
+
 // AddLiquidity allows suppliers or investors to add tokens to a liquidity pool.
 func (s *SupplyFinanceToken) AddLiquidity(from Address, amount uint64) error {
-	if err := s.BaseToken.Transfer(from, AddressZero, amount); err != nil {
+	if err := s.BaseToken.Transfer(from, syn2100LiquidityAccount(), amount); err != nil {
 		return err
 	}
 	s.liqLock.Lock()
@@ -111,7 +113,7 @@ func (s *SupplyFinanceToken) RemoveLiquidity(to Address, amount uint64) error {
 	}
 	s.liquidity[to] -= amount
 	s.liqLock.Unlock()
-	return s.BaseToken.Transfer(AddressZero, to, amount)
+	return s.BaseToken.Transfer(syn2100LiquidityAccount(), to, amount)
 }
 
 // LiquidityOf returns the amount a provider has supplied to the pool.
@@ -121,6 +121,7 @@ func (t *SYN721Token) Burn(from Address, nftID uint64) error {
 	delete(t.metaStore, nftID)
 	delete(t.approvals, nftID)
 	t.meta.TotalSupply--
+	t.burned++
 	if t.BaseToken.ledger != nil {
 		t.BaseToken.ledger.EmitTransfer(t.BaseToken.id, from, AddressZero, 1)
 	}
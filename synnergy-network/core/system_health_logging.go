@@ -17,14 +17,17 @@ import (
 
 // Metrics captures a snapshot of network and node health statistics.
 type Metrics struct {
-	Height        uint64 `json:"height"`
-	LastHash      string `json:"last_hash"`
-	PendingTx     int    `json:"pending_tx"`
-	PeerCount     int    `json:"peer_count"`
-	TotalSupply   uint64 `json:"total_supply"`
-	MemAlloc      uint64 `json:"mem_alloc"`
-	NumGoroutines int    `json:"goroutines"`
-	Timestamp     int64  `json:"timestamp"`
+	Height        uint64  `json:"height"`
+	LastHash      string  `json:"last_hash"`
+	PendingTx     int     `json:"pending_tx"`
+	PeerCount     int     `json:"peer_count"`
+	TotalSupply   uint64  `json:"total_supply"`
+	TotalBurned   uint64  `json:"total_burned"`
+	MemAlloc      uint64  `json:"mem_alloc"`
+	NumGoroutines int     `json:"goroutines"`
+	ReadViewLag   uint64  `json:"read_view_lag"`
+	ReadViewAge   float64 `json:"read_view_age"` // seconds
+	Timestamp     int64   `json:"timestamp"`
 }
 
 // HealthLogger provides simple system monitoring and structured logging.
@@ -43,9 +46,12 @@ type HealthLogger struct {
 	pendingTxGauge   prometheus.Gauge
 	peerCountGauge   prometheus.Gauge
 	totalSupplyGauge prometheus.Gauge
+	totalBurnedGauge prometheus.Gauge
 	memAllocGauge    prometheus.Gauge
 	goroutinesGauge  prometheus.Gauge
 	errorCounter     prometheus.Counter
+	viewLagGauge     prometheus.Gauge
+	viewAgeGauge     prometheus.Gauge
 }
 
 // NewHealthLogger configures a HealthLogger writing JSON logs to the given path.
@@ -77,6 +83,10 @@ func NewHealthLogger(l *Ledger, n *Node, c *Coin, tp *TxPool, path string) (*Hea
 		Name: "synnergy_total_supply",
 		Help: "Total supply of the native coin",
 	})
+	h.totalBurnedGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "synnergy_total_burned",
+		Help: "Native coin burned so far",
+	})
 	h.memAllocGauge = prometheus.NewGauge(prometheus.GaugeOpts{
 		Name: "synnergy_mem_alloc_bytes",
 		Help: "Current memory allocation in bytes",
@@ -89,15 +99,26 @@ func NewHealthLogger(l *Ledger, n *Node, c *Coin, tp *TxPool, path string) (*Hea
 		Name: "synnergy_log_errors_total",
 		Help: "Total number of error events logged",
 	})
+	h.viewLagGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "synnergy_read_view_lag_blocks",
+		Help: "Blocks the read view served to API queries trails the head",
+	})
+	h.viewAgeGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "synnergy_read_view_age_seconds",
+		Help: "Seconds the read view served to API queries has trailed the head",
+	})
 
 	reg.MustRegister(
 		h.heightGauge,
 		h.pendingTxGauge,
 		h.peerCountGauge,
 		h.totalSupplyGauge,
+		h.totalBurnedGauge,
 		h.memAllocGauge,
 		h.goroutinesGauge,
 		h.errorCounter,
+		h.viewLagGauge,
+		h.viewAgeGauge,
 	)
 
 	return h, nil
@@ -149,6 +170,9 @@ func (h *HealthLogger) MetricsSnapshot() Metrics {
 		hash := h.ledger.LastBlockHash()
 		m.LastHash = hex.EncodeToString(hash[:])
 		m.PendingTx = len(h.ledger.TxPool)
+		rv := h.ledger.ReadViewStats()
+		m.ReadViewLag, m.ReadViewAge = rv.LagBlocks, rv.Age.Seconds()
+		m.TotalBurned = h.ledger.TotalBurned(Code)
 	}
 	if h.txpool != nil {
 		m.PendingTx = len(h.txpool.Snapshot())
@@ -169,8 +193,11 @@ func (h *HealthLogger) RecordMetrics() {
 	h.pendingTxGauge.Set(float64(m.PendingTx))
 	h.peerCountGauge.Set(float64(m.PeerCount))
 	h.totalSupplyGauge.Set(float64(m.TotalSupply))
+	h.totalBurnedGauge.Set(float64(m.TotalBurned))
 	h.memAllocGauge.Set(float64(m.MemAlloc))
 	h.goroutinesGauge.Set(float64(m.NumGoroutines))
+	h.viewLagGauge.Set(float64(m.ReadViewLag))
+	h.viewAgeGauge.Set(m.ReadViewAge)
 	h.LogEvent(logrus.InfoLevel, "metrics recorded")
 }
 
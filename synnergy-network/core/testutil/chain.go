@@ -0,0 +1,552 @@
+// Package testutil is an in-memory chain for unit-testing Synnergy
+// contracts without a running node.
+//
+// A Chain holds accounts, contract code and storage in memory and executes
+// calls on the light (bytecode) or heavy (WASM) VM. Tests can deploy code,
+// call it as any sender, mine blocks and move the clock, snapshot and
+// revert state, and assert on balances, storage and emitted events:
+//
+//	c := testutil.NewChain(t)
+//	alice := c.Account("alice")
+//	c.Fund(alice, 1_000)
+//	addr := c.MustDeploy(alice, wasm)
+//	c.MustCall(alice, addr, "deposit", nil)
+//	c.AssertEvent(addr, testutil.Topic("Deposited"))
+//	c.Mine(10)
+//
+// Storage written by a contract persists across calls to it. A call whose
+// receipt reports failure is reverted, including any value it carried.
+package testutil
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/wasmerio/wasmer-go/wasmer"
+	core "synnergy-network/core"
+)
+
+// Defaults for a new chain.
+const (
+	DefaultGasLimit  uint64 = 10_000_000
+	DefaultBlockTime        = 5 * time.Second
+)
+
+var wasmMagic = []byte("\x00asm")
+
+// Event is a log emitted by a contract call.
+type Event struct {
+	Height   uint64        `json:"height"`
+	TxIndex  int           `json:"tx_index"`
+	Contract core.Address  `json:"contract"`
+	Topics   []common.Hash `json:"topics"`
+	Data     []byte        `json:"data"`
+}
+
+// Topic returns the first topic host_emit_topics records for an event
+// named name.
+func Topic(name string) common.Hash {
+	return common.BytesToHash(crypto.Keccak256([]byte(name)))
+}
+
+// Tx is a contract call.
+type Tx struct {
+	From   core.Address
+	To     core.Address
+	Method string
+	Args   []byte
+	Value  uint64
+	Gas    uint64 // 0 = the chain's gas limit
+}
+
+// Result is the outcome of a call.
+type Result struct {
+	*core.Receipt
+	Events []Event
+}
+
+// Option configures a Chain.
+type Option func(*Chain)
+
+// WithChainID sets the chain ID reported to contracts (default 1).
+func WithChainID(id int64) Option { return func(c *Chain) { c.chainID = big.NewInt(id) } }
+
+// WithGenesisTime sets the time of the genesis block.
+func WithGenesisTime(t time.Time) Option { return func(c *Chain) { c.now = t } }
+
+// WithBlockTime sets how far Mine moves the clock per block.
+func WithBlockTime(d time.Duration) Option { return func(c *Chain) { c.blockTime = d } }
+
+// WithGasLimit sets the gas limit used for calls that do not set one.
+func WithGasLimit(gas uint64) Option { return func(c *Chain) { c.gasLimit = gas } }
+
+// Chain is an in-memory chain. It implements core.ChainContext so contracts
+// see its block height, time and chain ID.
+type Chain struct {
+	t     testing.TB
+	state *State
+
+	mu        sync.Mutex
+	height    uint64
+	now       time.Time
+	blockTime time.Duration
+	chainID   *big.Int
+	gasLimit  uint64
+	txIndex   int
+	events    []Event
+	snaps     []snapshot
+	engine    *wasmer.Engine
+}
+
+type snapshot struct {
+	w       *world
+	height  uint64
+	now     time.Time
+	txIndex int
+	events  int
+}
+
+var _ core.ChainContext = (*Chain)(nil)
+
+// NewChain returns an empty chain at height 0. t may be nil outside tests,
+// in which case failed assertions panic.
+func NewChain(t testing.TB, opts ...Option) *Chain {
+	c := &Chain{
+		t:         t,
+		now:       time.Unix(1_700_000_000, 0).UTC(),
+		blockTime: DefaultBlockTime,
+		chainID:   big.NewInt(1),
+		gasLimit:  DefaultGasLimit,
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	c.state = &State{w: newWorld(), chain: c}
+	return c
+}
+
+// State exposes the chain state, e.g. to wire it into a core component.
+func (c *Chain) State() *State { return c.state }
+
+func (c *Chain) fatalf(format string, args ...interface{}) {
+	if c.t == nil {
+		panic(fmt.Sprintf(format, args...))
+	}
+	c.t.Helper()
+	c.t.Fatalf(format, args...)
+}
+
+//---------------------------------------------------------------------
+// core.ChainContext
+//---------------------------------------------------------------------
+
+func (c *Chain) BlockNumber() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.height
+}
+
+func (c *Chain) Time() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return uint64(c.now.Unix())
+}
+
+func (c *Chain) Difficulty() *big.Int { return big.NewInt(0) }
+func (c *Chain) GasLimit() uint64     { return c.gasLimit }
+func (c *Chain) ChainID() *big.Int    { return new(big.Int).Set(c.chainID) }
+
+// BlockHash is a deterministic stand-in derived from the chain ID and n.
+func (c *Chain) BlockHash(n uint64) common.Hash {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], n)
+	return common.BytesToHash(crypto.Keccak256(c.chainID.Bytes(), buf[:]))
+}
+
+//---------------------------------------------------------------------
+// Blocks and time
+//---------------------------------------------------------------------
+
+// Height returns the current block height.
+func (c *Chain) Height() uint64 { return c.BlockNumber() }
+
+// Now returns the current block time.
+func (c *Chain) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Mine advances n blocks, moving the clock by the block time for each.
+func (c *Chain) Mine(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.height += uint64(n)
+	c.now = c.now.Add(time.Duration(n) * c.blockTime)
+	c.txIndex = 0
+}
+
+// AdvanceTime moves the clock forward without mining.
+func (c *Chain) AdvanceTime(d time.Duration) {
+	if d < 0 {
+		c.fatalf("testutil: cannot move time backwards by %s", d)
+		return
+	}
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	c.mu.Unlock()
+}
+
+// SetTime moves the clock to t, which must not be before the current time.
+func (c *Chain) SetTime(t time.Time) { c.AdvanceTime(t.Sub(c.Now())) }
+
+//---------------------------------------------------------------------
+// Snapshots
+//---------------------------------------------------------------------
+
+// Snapshot records the state, height, time and events and returns an id
+// for Revert.
+func (c *Chain) Snapshot() int {
+	w := c.state.save()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.snaps = append(c.snaps, snapshot{w: w, height: c.height, now: c.now, txIndex: c.txIndex, events: len(c.events)})
+	return len(c.snaps) - 1
+}
+
+// Revert restores snapshot id. It and any later snapshots are discarded.
+func (c *Chain) Revert(id int) error {
+	c.mu.Lock()
+	if id < 0 || id >= len(c.snaps) {
+		c.mu.Unlock()
+		return fmt.Errorf("testutil: unknown snapshot %d", id)
+	}
+	s := c.snaps[id]
+	c.snaps = c.snaps[:id]
+	c.height, c.now, c.txIndex = s.height, s.now, s.txIndex
+	c.events = c.events[:s.events]
+	c.mu.Unlock()
+	c.state.restore(s.w)
+	return nil
+}
+
+//---------------------------------------------------------------------
+// Accounts
+//---------------------------------------------------------------------
+
+// Account returns a deterministic address for a test account name.
+func (c *Chain) Account(name string) core.Address {
+	h := sha256.Sum256([]byte("testutil:" + name))
+	var a core.Address
+	copy(a[:], h[:])
+	return a
+}
+
+// Fund credits addr with amount.
+func (c *Chain) Fund(addr core.Address, amount uint64) { _ = c.state.Mint(addr, amount) }
+
+// FundToken credits addr with amount of token id.
+func (c *Chain) FundToken(id core.TokenID, addr core.Address, amount uint64) {
+	bal, _ := c.state.GetTokenBalance(addr, id)
+	_ = c.state.SetTokenBalance(addr, id, bal+amount)
+}
+
+// Balance returns the native balance of addr.
+func (c *Chain) Balance(addr core.Address) uint64 { return c.state.BalanceOf(addr) }
+
+// TokenBalance returns addr's balance of token id.
+func (c *Chain) TokenBalance(id core.TokenID, addr core.Address) uint64 {
+	bal, _ := c.state.GetTokenBalance(addr, id)
+	return bal
+}
+
+//---------------------------------------------------------------------
+// Deployment
+//---------------------------------------------------------------------
+
+// Deploy stores code at an address derived from the deployer's nonce.
+// WASM modules run on the heavy VM; anything else on the light VM.
+func (c *Chain) Deploy(from core.Address, code []byte) (core.Address, error) {
+	return c.deploy(from, code, nil)
+}
+
+// DeploySalted stores code at core.Create2Address(from, salt, code).
+func (c *Chain) DeploySalted(from core.Address, salt core.Hash, code []byte) (core.Address, error) {
+	return c.deploy(from, code, &salt)
+}
+
+// MustDeploy is Deploy failing the test on error.
+func (c *Chain) MustDeploy(from core.Address, code []byte) core.Address {
+	addr, err := c.Deploy(from, code)
+	if err != nil {
+		c.fatalf("testutil: deploy: %v", err)
+	}
+	return addr
+}
+
+func (c *Chain) deploy(from core.Address, code []byte, salt *core.Hash) (core.Address, error) {
+	if len(code) == 0 {
+		return core.Address{}, errors.New("empty contract bytecode")
+	}
+	s := c.state
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var addr core.Address
+	if salt != nil {
+		addr = core.Create2Address(from, *salt, code)
+	} else {
+		h := crypto.Keccak256(append(from[:], byte(s.w.nonces[from])))
+		copy(addr[:], h[:20])
+	}
+	if len(s.w.code[addr]) > 0 {
+		return addr, core.ErrAddressInUse
+	}
+	s.w.code[addr] = append([]byte(nil), code...)
+	s.w.nonces[from]++
+	return addr, nil
+}
+
+func (c *Chain) transferValue(from, to core.Address, value *big.Int) error {
+	if value == nil || value.Sign() == 0 {
+		return nil
+	}
+	if !value.IsUint64() {
+		return errors.New("value out of range")
+	}
+	return c.state.Transfer(from, to, value.Uint64())
+}
+
+//---------------------------------------------------------------------
+// Execution
+//---------------------------------------------------------------------
+
+// call is one VM execution: code is the address whose code runs and to
+// the address whose storage and balance it acts on.
+type call struct {
+	from, to, code core.Address
+	method         string
+	input          []byte
+	value          *big.Int
+	gas            uint64
+}
+
+// storageNS is the namespace the VMs key a contract's storage by
+// (VMContext.TxHash); using the contract address makes storage persist
+// across calls.
+func storageNS(addr core.Address) [32]byte {
+	var ns [32]byte
+	copy(ns[12:], addr[:])
+	return ns
+}
+
+// run executes a call, reverting its state changes when it fails.
+func (c *Chain) run(cl call) (*core.Receipt, error) {
+	code := c.state.GetCode(cl.code)
+	if len(code) == 0 {
+		return nil, fmt.Errorf("no contract at %s", cl.code.Hex())
+	}
+	gas := cl.gas
+	if gas == 0 {
+		gas = c.gasLimit
+	}
+	c.mu.Lock()
+	height, now := c.height, c.now
+	if c.engine == nil && bytes.HasPrefix(code, wasmMagic) {
+		c.engine = wasmer.NewEngine()
+	}
+	engine := c.engine
+	c.mu.Unlock()
+
+	saved := c.state.save()
+	if err := c.transferValue(cl.from, cl.to, cl.value); err != nil {
+		return nil, err
+	}
+
+	meter := core.NewGasMeter(gas)
+	var vm core.VM
+	if bytes.HasPrefix(code, wasmMagic) {
+		vm = core.NewHeavyVM(c.state, meter, engine)
+	} else {
+		vm = core.NewLightVM(c.state, meter)
+	}
+	value := cl.value
+	if value == nil {
+		value = new(big.Int)
+	}
+	ctx := &core.VMContext{
+		Caller:   common.BytesToAddress(cl.from[:]),
+		Origin:   common.BytesToAddress(cl.from[:]),
+		TxHash:   storageNS(cl.to),
+		GasLimit: gas,
+		Context: core.Context{
+			BlockHeight: height,
+			Caller:      cl.from,
+			TxOrigin:    cl.from,
+			Timestamp:   now.Unix(),
+			Contract:    cl.to,
+			Value:       value,
+			GasLimit:    gas,
+			Method:      cl.method,
+			Args:        cl.input,
+		},
+		Memory:   core.NewMemory(),
+		State:    c.state,
+		Chain:    c,
+		GasMeter: meter,
+		Code:     code,
+	}
+	rec, err := vm.Execute(code, ctx)
+	if err != nil || rec == nil || !rec.Status {
+		c.state.restore(saved)
+	}
+	if err != nil {
+		return rec, err
+	}
+	return rec, nil
+}
+
+// Call executes a transaction against the chain. A failed call is
+// reverted and reported through the receipt's Status and Error.
+func (c *Chain) Call(tx Tx) (*Result, error) {
+	rec, err := c.run(call{
+		from:   tx.From,
+		to:     tx.To,
+		code:   tx.To,
+		method: tx.Method,
+		input:  tx.Args,
+		value:  new(big.Int).SetUint64(tx.Value),
+		gas:    tx.Gas,
+	})
+	if rec == nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	res := &Result{Receipt: rec}
+	if rec.Status {
+		for _, l := range rec.Logs {
+			ev := Event{Height: c.height, TxIndex: c.txIndex, Contract: l.Address, Topics: l.Topics, Data: l.Data}
+			if ev.Contract == (core.Address{}) {
+				ev.Contract = tx.To
+			}
+			res.Events = append(res.Events, ev)
+		}
+		c.events = append(c.events, res.Events...)
+	}
+	c.txIndex++
+	c.mu.Unlock()
+	return res, err
+}
+
+// MustCall calls method on to as from and fails the test unless the call
+// succeeds.
+func (c *Chain) MustCall(from, to core.Address, method string, args []byte) *Result {
+	res, err := c.Call(Tx{From: from, To: to, Method: method, Args: args})
+	switch {
+	case err != nil:
+		c.fatalf("testutil: call %s on %s: %v", method, to.Hex(), err)
+	case !res.Status:
+		c.fatalf("testutil: call %s on %s failed: %s", method, to.Hex(), res.Error)
+	}
+	return res
+}
+
+// Storage reads key from a contract's storage; nil when unset.
+func (c *Chain) Storage(contract core.Address, key []byte) []byte {
+	ns := storageNS(contract)
+	v, err := c.state.Get(ns[:], key)
+	if err != nil {
+		return nil
+	}
+	return v
+}
+
+// Events returns the events emitted so far, optionally only those of the
+// given contracts.
+func (c *Chain) Events(contracts ...core.Address) []Event {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var out []Event
+	for _, ev := range c.events {
+		if len(contracts) == 0 || containsAddr(contracts, ev.Contract) {
+			out = append(out, ev)
+		}
+	}
+	return out
+}
+
+func containsAddr(list []core.Address, a core.Address) bool {
+	for _, x := range list {
+		if x == a {
+			return true
+		}
+	}
+	return false
+}
+
+//---------------------------------------------------------------------
+// Assertions
+//---------------------------------------------------------------------
+
+// AssertBalance fails the test unless addr holds want.
+func (c *Chain) AssertBalance(addr core.Address, want uint64) {
+	if got := c.Balance(addr); got != want {
+		c.fatalf("testutil: balance of %s = %d, want %d", addr.Hex(), got, want)
+	}
+}
+
+// AssertTokenBalance fails the test unless addr holds want of token id.
+func (c *Chain) AssertTokenBalance(id core.TokenID, addr core.Address, want uint64) {
+	if got := c.TokenBalance(id, addr); got != want {
+		c.fatalf("testutil: token %d balance of %s = %d, want %d", id, addr.Hex(), got, want)
+	}
+}
+
+// AssertStorage fails the test unless key in contract's storage holds want.
+func (c *Chain) AssertStorage(contract core.Address, key, want []byte) {
+	if got := c.Storage(contract, key); !bytes.Equal(got, want) {
+		c.fatalf("testutil: storage %x of %s = %x, want %x", key, contract.Hex(), got, want)
+	}
+}
+
+// AssertRevert fails the test unless res reverted with the decoded reason
+// want, e.g. an Error(string) message or a custom error such as
+// "InsufficientBalance(have: 1, want: 2)".
+func (c *Chain) AssertRevert(res *Result, want string) {
+	switch {
+	case res.Status:
+		c.fatalf("testutil: call succeeded, want revert %q", want)
+	case res.Revert == nil:
+		c.fatalf("testutil: call failed without reverting: %s", res.Error)
+	case res.Revert.Message != want:
+		c.fatalf("testutil: reverted with %q, want %q", res.Revert.Message, want)
+	}
+}
+
+// AssertEvent fails the test unless contract emitted an event whose first
+// topic is topic, and returns the latest such event.
+func (c *Chain) AssertEvent(contract core.Address, topic common.Hash) Event {
+	evs := c.Events(contract)
+	for i := len(evs) - 1; i >= 0; i-- {
+		if len(evs[i].Topics) > 0 && evs[i].Topics[0] == topic {
+			return evs[i]
+		}
+	}
+	c.fatalf("testutil: %s emitted no event with topic %s", contract.Hex(), topic.Hex())
+	return Event{}
+}
+
+// AssertNoEvents fails the test if contract emitted any event.
+func (c *Chain) AssertNoEvents(contract core.Address) {
+	if evs := c.Events(contract); len(evs) > 0 {
+		c.fatalf("testutil: %s emitted %d events, want none", contract.Hex(), len(evs))
+	}
+}
@@ -0,0 +1,126 @@
+package testutil
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	core "synnergy-network/core"
+)
+
+// light assembles light-VM bytecode: each []byte operand is a PUSH.
+func light(parts ...interface{}) []byte {
+	var b []byte
+	for _, p := range parts {
+		switch v := p.(type) {
+		case core.Opcode:
+			b = append(b, byte(v))
+		case []byte:
+			b = append(b, byte(core.PUSH), byte(len(v)))
+			b = append(b, v...)
+		}
+	}
+	return b
+}
+
+func TestChainCallPersistsStorageAndEvents(t *testing.T) {
+	c := NewChain(t)
+	alice := c.Account("alice")
+	prog := light([]byte{42}, []byte("k"), core.STORE, []byte("hello"), core.LOG, []byte{1}, core.RET)
+	addr := c.MustDeploy(alice, prog)
+
+	res := c.MustCall(alice, addr, "set", nil)
+	if !bytes.Equal(res.ReturnData, []byte{1}) {
+		t.Fatalf("return data = %x", res.ReturnData)
+	}
+	c.AssertStorage(addr, []byte("k"), []byte{42})
+	if evs := c.Events(addr); len(evs) != 1 || string(evs[0].Data) != "hello" {
+		t.Fatalf("events = %+v", evs)
+	}
+
+	// Storage is namespaced per contract.
+	other := c.MustDeploy(alice, light([]byte("k"), core.LOAD, core.RET))
+	if res, _ := c.Call(Tx{From: alice, To: other}); res.Status {
+		t.Fatal("second contract read the first one's storage")
+	}
+}
+
+func TestChainFailedCallReverts(t *testing.T) {
+	c := NewChain(t)
+	alice := c.Account("alice")
+	c.Fund(alice, 100)
+	// Stores, then loads a missing key and fails.
+	addr := c.MustDeploy(alice, light([]byte{7}, []byte("k"), core.STORE, []byte("missing"), core.LOAD, core.RET))
+
+	res, err := c.Call(Tx{From: alice, To: addr, Value: 40})
+	if err == nil || res.Status {
+		t.Fatal("expected the call to fail")
+	}
+	c.AssertStorage(addr, []byte("k"), nil)
+	c.AssertBalance(alice, 100)
+	c.AssertBalance(addr, 0)
+	c.AssertNoEvents(addr)
+}
+
+func TestChainValueTransfer(t *testing.T) {
+	c := NewChain(t)
+	alice := c.Account("alice")
+	c.Fund(alice, 100)
+	addr := c.MustDeploy(alice, light([]byte{1}, core.RET))
+	if _, err := c.Call(Tx{From: alice, To: addr, Value: 30}); err != nil {
+		t.Fatal(err)
+	}
+	c.AssertBalance(alice, 70)
+	c.AssertBalance(addr, 30)
+	if _, err := c.Call(Tx{From: alice, To: addr, Value: 71}); err == nil {
+		t.Fatal("overdrawn call succeeded")
+	}
+}
+
+func TestChainSnapshotRevertAndTimeTravel(t *testing.T) {
+	start := time.Unix(1_000, 0)
+	c := NewChain(t, WithGenesisTime(start), WithBlockTime(10*time.Second))
+	alice := c.Account("alice")
+	c.Fund(alice, 5)
+
+	snap := c.Snapshot()
+	c.Mine(3)
+	c.AdvanceTime(time.Minute)
+	c.Fund(alice, 5)
+	addr := c.MustDeploy(alice, light([]byte("x"), core.LOG, []byte{1}, core.RET))
+	c.MustCall(alice, addr, "", nil)
+
+	if c.Height() != 3 || !c.Now().Equal(start.Add(90*time.Second)) {
+		t.Fatalf("height %d time %s", c.Height(), c.Now())
+	}
+	if err := c.Revert(snap); err != nil {
+		t.Fatal(err)
+	}
+	if c.Height() != 0 || !c.Now().Equal(start) {
+		t.Fatalf("after revert: height %d time %s", c.Height(), c.Now())
+	}
+	c.AssertBalance(alice, 5)
+	if len(c.State().GetCode(addr)) != 0 || len(c.Events()) != 0 {
+		t.Fatal("revert kept the deployment")
+	}
+	if err := c.Revert(snap); err == nil {
+		t.Fatal("a snapshot can only be reverted once")
+	}
+}
+
+func TestChainDeploySalted(t *testing.T) {
+	c := NewChain(t)
+	alice := c.Account("alice")
+	code := light([]byte{1}, core.RET)
+	salt := core.Hash{1}
+	addr, err := c.DeploySalted(alice, salt, code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if addr != core.Create2Address(alice, salt, code) {
+		t.Fatal("salted address mismatch")
+	}
+	if _, err := c.DeploySalted(alice, salt, code); err != core.ErrAddressInUse {
+		t.Fatalf("redeploy err = %v", err)
+	}
+}
@@ -0,0 +1,394 @@
+package testutil
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+
+	core "synnergy-network/core"
+)
+
+// world is the mutable content of a State. It is copied wholesale for
+// snapshots; test chains are small enough that this stays cheap.
+type world struct {
+	data     map[string][]byte
+	balances map[core.Address]uint64
+	tokens   map[core.TokenID]map[core.Address]uint64
+	lp       map[core.Address]map[core.PoolID]uint64
+	code     map[core.Address][]byte
+	nonces   map[core.Address]uint64
+	logs     []*core.Log
+}
+
+func newWorld() *world {
+	return &world{
+		data:     make(map[string][]byte),
+		balances: make(map[core.Address]uint64),
+		tokens:   make(map[core.TokenID]map[core.Address]uint64),
+		lp:       make(map[core.Address]map[core.PoolID]uint64),
+		code:     make(map[core.Address][]byte),
+		nonces:   make(map[core.Address]uint64),
+	}
+}
+
+func (w *world) clone() *world {
+	c := newWorld()
+	for k, v := range w.data {
+		c.data[k] = append([]byte(nil), v...)
+	}
+	for a, v := range w.balances {
+		c.balances[a] = v
+	}
+	for id, m := range w.tokens {
+		cp := make(map[core.Address]uint64, len(m))
+		for a, v := range m {
+			cp[a] = v
+		}
+		c.tokens[id] = cp
+	}
+	for a, m := range w.lp {
+		cp := make(map[core.PoolID]uint64, len(m))
+		for id, v := range m {
+			cp[id] = v
+		}
+		c.lp[a] = cp
+	}
+	for a, v := range w.code {
+		c.code[a] = append([]byte(nil), v...)
+	}
+	for a, v := range w.nonces {
+		c.nonces[a] = v
+	}
+	c.logs = append([]*core.Log(nil), w.logs...)
+	return c
+}
+
+// State is an in-memory core.StateRW backing a Chain. Contract calls made
+// through the state (opCALL, opCREATE and friends) are executed by the
+// owning chain.
+type State struct {
+	mu    sync.RWMutex
+	w     *world
+	chain *Chain
+}
+
+var _ core.StateRW = (*State)(nil)
+
+func (s *State) save() *world {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.w.clone()
+}
+
+func (s *State) restore(w *world) {
+	s.mu.Lock()
+	s.w = w
+	s.mu.Unlock()
+}
+
+//---------------------------------------------------------------------
+// Raw key/value state
+//---------------------------------------------------------------------
+
+func (s *State) GetState(key []byte) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	v, ok := s.w.data[string(key)]
+	if !ok {
+		return nil, errors.New("key not found")
+	}
+	return append([]byte(nil), v...), nil
+}
+
+func (s *State) SetState(key, value []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.w.data[string(key)] = append([]byte(nil), value...)
+	return nil
+}
+
+func (s *State) DeleteState(key []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.w.data, string(key))
+	return nil
+}
+
+func (s *State) HasState(key []byte) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.w.data[string(key)]
+	return ok, nil
+}
+
+// PrefixIterator iterates keys with the given prefix in lexical order.
+func (s *State) PrefixIterator(prefix []byte) core.StateIterator {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	it := &iterator{idx: -1}
+	for k, v := range s.w.data {
+		if bytes.HasPrefix([]byte(k), prefix) {
+			it.keys = append(it.keys, []byte(k))
+			it.vals = append(it.vals, append([]byte(nil), v...))
+		}
+	}
+	sort.Sort(it)
+	return it
+}
+
+type iterator struct {
+	keys, vals [][]byte
+	idx        int
+}
+
+func (it *iterator) Len() int           { return len(it.keys) }
+func (it *iterator) Less(i, j int) bool { return bytes.Compare(it.keys[i], it.keys[j]) < 0 }
+func (it *iterator) Swap(i, j int) {
+	it.keys[i], it.keys[j] = it.keys[j], it.keys[i]
+	it.vals[i], it.vals[j] = it.vals[j], it.vals[i]
+}
+func (it *iterator) Next() bool    { it.idx++; return it.idx < len(it.keys) }
+func (it *iterator) Key() []byte   { return it.keys[it.idx] }
+func (it *iterator) Value() []byte { return it.vals[it.idx] }
+func (it *iterator) Error() error  { return nil }
+
+func slot(ns, key []byte) string {
+	return hex.EncodeToString(ns) + "|" + hex.EncodeToString(key)
+}
+
+// Get reads contract storage in namespace ns.
+func (s *State) Get(ns, key []byte) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	v, ok := s.w.data[slot(ns, key)]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return append([]byte(nil), v...), nil
+}
+
+// Set writes contract storage in namespace ns.
+func (s *State) Set(ns, key, val []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.w.data[slot(ns, key)] = append([]byte(nil), val...)
+	return nil
+}
+
+// Snapshot runs fn and rolls the state back if it returns an error.
+func (s *State) Snapshot(fn func() error) error {
+	saved := s.save()
+	if err := fn(); err != nil {
+		s.restore(saved)
+		return err
+	}
+	return nil
+}
+
+//---------------------------------------------------------------------
+// Balances and tokens
+//---------------------------------------------------------------------
+
+func (s *State) BalanceOf(addr core.Address) uint64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.w.balances[addr]
+}
+
+func (s *State) NonceOf(addr core.Address) uint64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.w.nonces[addr]
+}
+
+func (s *State) IsIDTokenHolder(addr core.Address) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.w.balances[addr]
+	return ok
+}
+
+func (s *State) Transfer(from, to core.Address, amount uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.w.balances[from] < amount {
+		return fmt.Errorf("insufficient balance: have %d, need %d", s.w.balances[from], amount)
+	}
+	s.w.balances[from] -= amount
+	s.w.balances[to] += amount
+	return nil
+}
+
+func (s *State) Mint(addr core.Address, amount uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.w.balances[addr] += amount
+	return nil
+}
+
+func (s *State) MintToken(addr core.Address, amount uint64) error { return s.Mint(addr, amount) }
+
+func (s *State) Burn(addr core.Address, amount uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.w.balances[addr] < amount {
+		return fmt.Errorf("insufficient balance: have %d, need %d", s.w.balances[addr], amount)
+	}
+	s.w.balances[addr] -= amount
+	return nil
+}
+
+func (s *State) MintLP(to core.Address, pool core.PoolID, amt uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.w.lp[to] == nil {
+		s.w.lp[to] = make(map[core.PoolID]uint64)
+	}
+	s.w.lp[to][pool] += amt
+	return nil
+}
+
+func (s *State) BurnLP(from core.Address, pool core.PoolID, amt uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.w.lp[from][pool] < amt {
+		return errors.New("insufficient LP balance")
+	}
+	s.w.lp[from][pool] -= amt
+	return nil
+}
+
+// GetToken is not supported: test chains track token balances only.
+func (s *State) GetToken(id core.TokenID) (core.Token, error) {
+	return nil, fmt.Errorf("token 0x%08X not registered on the test chain", uint32(id))
+}
+
+func (s *State) GetTokenBalance(addr core.Address, id core.TokenID) (uint64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.w.tokens[id][addr], nil
+}
+
+func (s *State) SetTokenBalance(addr core.Address, id core.TokenID, amount uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.w.tokens[id] == nil {
+		s.w.tokens[id] = make(map[core.Address]uint64)
+	}
+	s.w.tokens[id][addr] = amount
+	return nil
+}
+
+func (s *State) GetTokenSupply(id core.TokenID) (uint64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var total uint64
+	for _, v := range s.w.tokens[id] {
+		total += v
+	}
+	return total, nil
+}
+
+//---------------------------------------------------------------------
+// Code
+//---------------------------------------------------------------------
+
+func (s *State) GetCode(addr core.Address) []byte {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]byte(nil), s.w.code[addr]...)
+}
+
+func (s *State) GetCodeHash(addr core.Address) core.Hash {
+	return core.Hash(sha256.Sum256(s.GetCode(addr)))
+}
+
+func (s *State) GetContract(addr core.Address) (*core.Contract, error) {
+	code := s.GetCode(addr)
+	if len(code) == 0 {
+		return nil, errors.New("contract not found")
+	}
+	return &core.Contract{Address: addr, Bytecode: code}, nil
+}
+
+func (s *State) AddLog(l *core.Log) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.w.logs = append(s.w.logs, l)
+}
+
+func (s *State) SelfDestruct(contract, beneficiary core.Address) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.w.balances[beneficiary] += s.w.balances[contract]
+	delete(s.w.balances, contract)
+	delete(s.w.code, contract)
+}
+
+//---------------------------------------------------------------------
+// Nested execution, delegated to the chain
+//---------------------------------------------------------------------
+
+func (s *State) CreateContract(caller core.Address, code []byte, value *big.Int, gas uint64) (core.Address, []byte, bool, error) {
+	addr, err := s.chain.deploy(caller, code, nil)
+	if err != nil {
+		return addr, nil, false, err
+	}
+	return addr, nil, true, s.chain.transferValue(caller, addr, value)
+}
+
+// CreateContract2 deploys at core.Create2Address(caller, salt, code).
+func (s *State) CreateContract2(caller core.Address, code []byte, salt core.Hash, value *big.Int, gas uint64) (core.Address, []byte, bool, error) {
+	addr, err := s.chain.deploy(caller, code, &salt)
+	if err != nil {
+		return addr, nil, false, err
+	}
+	return addr, nil, true, s.chain.transferValue(caller, addr, value)
+}
+
+func (s *State) Call(from, to core.Address, input []byte, value *big.Int, gas uint64) ([]byte, error) {
+	rec, err := s.chain.run(call{from: from, to: to, code: to, input: input, value: value, gas: gas})
+	if err != nil {
+		return nil, err
+	}
+	return rec.ReturnData, nil
+}
+
+func (s *State) CallContract(from, to core.Address, input []byte, value *big.Int, gas uint64) ([]byte, bool, error) {
+	rec, err := s.chain.run(call{from: from, to: to, code: to, input: input, value: value, gas: gas})
+	if err != nil {
+		return nil, false, err
+	}
+	return rec.ReturnData, rec.Status, nil
+}
+
+// CallCode runs to's code against from's storage.
+func (s *State) CallCode(from, to core.Address, input []byte, value *big.Int, gas uint64) ([]byte, bool, error) {
+	rec, err := s.chain.run(call{from: from, to: from, code: to, input: input, value: value, gas: gas})
+	if err != nil {
+		return nil, false, err
+	}
+	return rec.ReturnData, rec.Status, nil
+}
+
+// DelegateCall runs to's code against from's storage.
+func (s *State) DelegateCall(from, to core.Address, input []byte, value *big.Int, gas uint64) error {
+	_, err := s.chain.run(call{from: from, to: from, code: to, input: input, gas: gas})
+	return err
+}
+
+// StaticCall runs to's code and discards any state it changes.
+func (s *State) StaticCall(from, to core.Address, input []byte, gas uint64) ([]byte, bool, error) {
+	saved := s.save()
+	defer s.restore(saved)
+	rec, err := s.chain.run(call{from: from, to: to, code: to, input: input, gas: gas})
+	if err != nil {
+		return nil, false, err
+	}
+	return rec.ReturnData, rec.Status, nil
+}
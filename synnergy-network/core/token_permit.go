@@ -0,0 +1,327 @@
+package core
+
+// token_permit.go – gasless token approvals.
+//
+// A permit is an allowance the owner signs off-chain as typed structured
+// data (see typed_data.go) instead of sending an approve transaction:
+//
+//	Permit(address owner,address spender,uint256 value,uint256 nonce,uint256 deadline)
+//
+// in the domain {name: token name, version "1", chainId, verifyingContract:
+// TokenAddress(token)}. Anyone holding the signature can submit it; the
+// allowance is set if the signature is the owner's, the deadline has not
+// passed and the nonce is the owner's next permit nonce for the token. Each
+// accepted permit increments that nonce, so a signature is good once.
+//
+// SwapExactInWithPermit lets a relayer or the DEX front end approve and
+// swap in one step: the permit names AMMRouterAccount as spender and the
+// swap spends from the resulting allowance. A failed swap leaves the permit
+// unused.
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// Permit errors.
+var (
+	ErrPermitExpired   = errors.New("permit deadline passed")
+	ErrPermitNonce     = errors.New("permit nonce mismatch")
+	ErrPermitSignature = errors.New("permit not signed by owner")
+)
+
+// AMMRouterAccount is the spender permits for SwapExactInWithPermit name.
+var AMMRouterAccount = ModuleAddress("amm_router")
+
+// Permit is an allowance signed off-chain by its owner.
+type Permit struct {
+	Token    TokenID `json:"token"`
+	Owner    Address `json:"owner"`
+	Spender  Address `json:"spender"`
+	Value    uint64  `json:"value"`
+	Nonce    uint64  `json:"nonce"`
+	Deadline int64   `json:"deadline"` // unix seconds
+}
+
+var (
+	permitMu      sync.Mutex
+	permitNonces  = make(map[TokenID]map[Address]uint64)
+	permitChainID = big.NewInt(1215)
+)
+
+// SetPermitChainID sets the chain ID permit domains are bound to, the
+// network.chain_id of the node configuration. It defaults to mainnet's.
+func SetPermitChainID(id int64) {
+	permitMu.Lock()
+	permitChainID = big.NewInt(id)
+	permitMu.Unlock()
+}
+
+// TokenAddress is the address standing for token id as the verifying
+// contract of its permits.
+func TokenAddress(id TokenID) Address {
+	return ModuleAddress(fmt.Sprintf("token:%d", id))
+}
+
+// PermitNonce returns the nonce owner's next permit for token must carry.
+func PermitNonce(id TokenID, owner Address) uint64 {
+	permitMu.Lock()
+	defer permitMu.Unlock()
+	return permitNonces[id][owner]
+}
+
+// PermitTypedData returns the document the owner signs for p.
+func PermitTypedData(p Permit) (TypedData, error) {
+	tok, ok := GetToken(p.Token)
+	if !ok {
+		return TypedData{}, NewError(CodeNotFound, "permit", "token %d not found", p.Token)
+	}
+	permitMu.Lock()
+	chainID := new(big.Int).Set(permitChainID)
+	permitMu.Unlock()
+	return permitTypedData(p, tok.Meta().Name, chainID), nil
+}
+
+func permitTypedData(p Permit, name string, chainID *big.Int) TypedData {
+	return TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": {
+				{Name: "name", Type: "string"},
+				{Name: "version", Type: "string"},
+				{Name: "chainId", Type: "uint256"},
+				{Name: "verifyingContract", Type: "address"},
+			},
+			"Permit": {
+				{Name: "owner", Type: "address"},
+				{Name: "spender", Type: "address"},
+				{Name: "value", Type: "uint256"},
+				{Name: "nonce", Type: "uint256"},
+				{Name: "deadline", Type: "uint256"},
+			},
+		},
+		PrimaryType: "Permit",
+		Domain: TypedDataDomain{
+			Name:              name,
+			Version:           "1",
+			ChainId:           (*math.HexOrDecimal256)(chainID),
+			VerifyingContract: TokenAddress(p.Token).Hex(),
+		},
+		Message: apitypes.TypedDataMessage{
+			"owner":    p.Owner.Hex(),
+			"spender":  p.Spender.Hex(),
+			"value":    strconv.FormatUint(p.Value, 10),
+			"nonce":    strconv.FormatUint(p.Nonce, 10),
+			"deadline": strconv.FormatInt(p.Deadline, 10),
+		},
+	}
+}
+
+// SignPermit signs p with the owner's ed25519 key at (account, index),
+// filling in the owner and the current nonce.
+func (w *HDWallet) SignPermit(p Permit, account, index uint32) (Permit, *TypedDataSignature, error) {
+	owner, err := w.NewAddress(account, index)
+	if err != nil {
+		return p, nil, err
+	}
+	p.Owner, p.Nonce = owner, PermitNonce(p.Token, owner)
+	td, err := PermitTypedData(p)
+	if err != nil {
+		return p, nil, err
+	}
+	sig, err := w.SignTypedData(td, account, index)
+	return p, sig, err
+}
+
+// checkPermitLocked validates p and sig against the current nonce and
+// returns the token. permitMu must be held.
+func checkPermitLocked(p Permit, sig []byte, now time.Time) (Token, error) {
+	tok, ok := GetToken(p.Token)
+	if !ok {
+		return nil, NewError(CodeNotFound, "permit", "token %d not found", p.Token)
+	}
+	if now.Unix() > p.Deadline {
+		return nil, ErrPermitExpired
+	}
+	if want := permitNonces[p.Token][p.Owner]; p.Nonce != want {
+		return nil, fmt.Errorf("%w: got %d want %d", ErrPermitNonce, p.Nonce, want)
+	}
+	ok, err := VerifyTypedData(permitTypedData(p, tok.Meta().Name, permitChainID), sig, p.Owner)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrPermitSignature
+	}
+	return tok, nil
+}
+
+func usePermitLocked(p Permit) {
+	if permitNonces[p.Token] == nil {
+		permitNonces[p.Token] = make(map[Address]uint64)
+	}
+	permitNonces[p.Token][p.Owner]++
+}
+
+// ApplyPermit sets the allowance p grants. sig is the owner's signature over
+// PermitTypedData(p) in the 96-byte signature ‖ public key layout.
+func ApplyPermit(p Permit, sig []byte) error {
+	permitMu.Lock()
+	defer permitMu.Unlock()
+	tok, err := checkPermitLocked(p, sig, time.Now())
+	if err != nil {
+		return err
+	}
+	if err := tok.Approve(p.Owner, p.Spender, p.Value); err != nil {
+		return err
+	}
+	usePermitLocked(p)
+	return nil
+}
+
+// Permit applies a signed permit to its token.
+func (tm *TokenManager) Permit(p Permit, sig []byte) error {
+	return ApplyPermit(p, sig)
+}
+
+// SwapExactInWithPermit swaps amtIn of the permit's token from its owner
+// along the best route, spending the allowance the permit grants
+// AMMRouterAccount. The rest of the allowance stays approved; a failed swap
+// does not consume the permit.
+func SwapExactInWithPermit(p Permit, sig []byte, amtIn uint64, tokenOut TokenID, minOut uint64, maxHops int) (uint64, error) {
+	if p.Spender != AMMRouterAccount {
+		return 0, NewError(CodeInvalidArgument, "permit", "spender must be the AMM router %s", AMMRouterAccount.Hex())
+	}
+	if amtIn > p.Value {
+		return 0, NewError(CodeInsufficientFunds, "permit", "amount %d exceeds permitted %d", amtIn, p.Value)
+	}
+	permitMu.Lock()
+	defer permitMu.Unlock()
+	tok, err := checkPermitLocked(p, sig, time.Now())
+	if err != nil {
+		return 0, err
+	}
+	out, err := SwapExactIn(p.Owner, p.Token, amtIn, tokenOut, minOut, maxHops)
+	if err != nil {
+		return 0, err
+	}
+	if err := tok.Approve(p.Owner, p.Spender, p.Value-amtIn); err != nil {
+		return 0, err
+	}
+	usePermitLocked(p)
+	return out, nil
+}
+
+// PermitRequest is the body of POST /tokens/permit. Addresses are hex or
+// bech32 and sig is hex. With Swap set the permit is spent on a swap
+// through SwapExactInWithPermit instead of only being applied.
+type PermitRequest struct {
+	Token    TokenID     `json:"token"`
+	Owner    string      `json:"owner"`
+	Spender  string      `json:"spender"`
+	Value    uint64      `json:"value"`
+	Nonce    uint64      `json:"nonce"`
+	Deadline int64       `json:"deadline"`
+	Sig      string      `json:"sig"`
+	Swap     *PermitSwap `json:"swap,omitempty"`
+}
+
+// PermitSwap is the swap a PermitRequest pays for.
+type PermitSwap struct {
+	AmountIn uint64  `json:"amount_in"`
+	TokenOut TokenID `json:"token_out"`
+	MinOut   uint64  `json:"min_out"`
+	MaxHops  int     `json:"max_hops,omitempty"` // default 4
+}
+
+// PermitResult reports the allowance left after a submitted permit.
+type PermitResult struct {
+	Allowance uint64 `json:"allowance"`
+	Nonce     uint64 `json:"nonce"` // next permit nonce of the owner
+	AmountOut uint64 `json:"amount_out,omitempty"`
+}
+
+// PermitInfo is what a wallet needs to build a permit for an owner. With a
+// spender TypedData is the document to sign, e.g. with
+// /api/wallet/sign-typed.
+type PermitInfo struct {
+	Nonce             uint64     `json:"nonce"`
+	Name              string     `json:"name"`
+	ChainID           string     `json:"chain_id"`
+	VerifyingContract string     `json:"verifying_contract"`
+	Router            string     `json:"router"`
+	TypedData         *TypedData `json:"typed_data,omitempty"`
+}
+
+// GetPermitInfo returns the permit nonce and domain of owner for token and,
+// when spender is set, the typed data of a permit with the next nonce.
+func GetPermitInfo(id TokenID, owner Address, spender *Address, value uint64, deadline int64) (*PermitInfo, error) {
+	tok, ok := GetToken(id)
+	if !ok {
+		return nil, NewError(CodeNotFound, "permit", "token %d not found", id)
+	}
+	permitMu.Lock()
+	defer permitMu.Unlock()
+	info := &PermitInfo{
+		Nonce:             permitNonces[id][owner],
+		Name:              tok.Meta().Name,
+		ChainID:           permitChainID.String(),
+		VerifyingContract: TokenAddress(id).Hex(),
+		Router:            AMMRouterAccount.Hex(),
+	}
+	if spender != nil {
+		p := Permit{Token: id, Owner: owner, Spender: *spender, Value: value, Nonce: info.Nonce, Deadline: deadline}
+		td := permitTypedData(p, info.Name, new(big.Int).Set(permitChainID))
+		info.TypedData = &td
+	}
+	return info, nil
+}
+
+// Permit decodes the request into a permit and its signature.
+func (r PermitRequest) Permit() (Permit, []byte, error) {
+	p := Permit{Token: r.Token, Value: r.Value, Nonce: r.Nonce, Deadline: r.Deadline}
+	var err error
+	if p.Owner, err = DecodeAddress(r.Owner); err != nil {
+		return p, nil, NewError(CodeInvalidArgument, "permit", "owner: %v", err)
+	}
+	if p.Spender, err = DecodeAddress(r.Spender); err != nil {
+		return p, nil, NewError(CodeInvalidArgument, "permit", "spender: %v", err)
+	}
+	sig, err := hex.DecodeString(trimHex(r.Sig))
+	if err != nil {
+		return p, nil, NewError(CodeInvalidArgument, "permit", "sig: %v", err)
+	}
+	return p, sig, nil
+}
+
+// SubmitPermit applies the permit in r, or spends it on r.Swap.
+func SubmitPermit(r PermitRequest) (*PermitResult, error) {
+	p, sig, err := r.Permit()
+	if err != nil {
+		return nil, err
+	}
+	res := &PermitResult{}
+	if r.Swap != nil {
+		hops := r.Swap.MaxHops
+		if hops <= 0 {
+			hops = 4
+		}
+		if res.AmountOut, err = SwapExactInWithPermit(p, sig, r.Swap.AmountIn, r.Swap.TokenOut, r.Swap.MinOut, hops); err != nil {
+			return nil, err
+		}
+	} else if err := ApplyPermit(p, sig); err != nil {
+		return nil, err
+	}
+	if tok, ok := GetToken(p.Token); ok {
+		res.Allowance = tok.Allowance(p.Owner, p.Spender)
+	}
+	res.Nonce = PermitNonce(p.Token, p.Owner)
+	return res, nil
+}
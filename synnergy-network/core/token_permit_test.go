@@ -0,0 +1,159 @@
+package core
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
+	"testing"
+	"time"
+)
+
+// newPermitTest registers a token for permits and returns it with an
+// owner's key and address.
+func newPermitTest(t *testing.T, id TokenID) (*BaseToken, ed25519.PrivateKey, Address) {
+	t.Helper()
+	tok := &BaseToken{id: id, meta: Metadata{Name: "Permit Test", Symbol: "PMT"}, balances: NewBalanceTable()}
+	RegisterToken(tok)
+	t.Cleanup(func() {
+		tokenRegMu.Lock()
+		delete(tokenRegistry, id)
+		tokenRegMu.Unlock()
+		permitMu.Lock()
+		delete(permitNonces, id)
+		permitMu.Unlock()
+	})
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	return tok, priv, pubKeyToAddress(pub)
+}
+
+func signPermit(t *testing.T, priv ed25519.PrivateKey, p Permit) []byte {
+	t.Helper()
+	td, err := PermitTypedData(p)
+	if err != nil {
+		t.Fatalf("typed data: %v", err)
+	}
+	s, err := SignTypedData(priv, td)
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	sig, _ := hex.DecodeString(s.Sig)
+	return sig
+}
+
+func TestApplyPermit(t *testing.T) {
+	tok, priv, owner := newPermitTest(t, 0x7e570001)
+	_, other, _ := ed25519.GenerateKey(nil)
+	spender := Address{0x5e}
+	p := Permit{Token: tok.ID(), Owner: owner, Spender: spender, Value: 500, Deadline: time.Now().Add(time.Hour).Unix()}
+
+	if err := ApplyPermit(p, signPermit(t, other, p)); !errors.Is(err, ErrPermitSignature) {
+		t.Fatalf("permit signed by another key: %v", err)
+	}
+	raised := p
+	raised.Value = 5_000
+	if err := ApplyPermit(raised, signPermit(t, priv, p)); !errors.Is(err, ErrPermitSignature) {
+		t.Fatalf("permit with a value the owner did not sign: %v", err)
+	}
+	expired := p
+	expired.Deadline = time.Now().Add(-time.Second).Unix()
+	if err := ApplyPermit(expired, signPermit(t, priv, expired)); !errors.Is(err, ErrPermitExpired) {
+		t.Fatalf("expired permit: %v", err)
+	}
+	ahead := p
+	ahead.Nonce = 1
+	if err := ApplyPermit(ahead, signPermit(t, priv, ahead)); !errors.Is(err, ErrPermitNonce) {
+		t.Fatalf("permit with a future nonce: %v", err)
+	}
+	if tok.Allowance(owner, spender) != 0 || PermitNonce(tok.ID(), owner) != 0 {
+		t.Fatal("rejected permits changed the allowance or nonce")
+	}
+
+	sig := signPermit(t, priv, p)
+	if err := ApplyPermit(p, sig); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if tok.Allowance(owner, spender) != 500 || PermitNonce(tok.ID(), owner) != 1 {
+		t.Fatalf("allowance %d nonce %d", tok.Allowance(owner, spender), PermitNonce(tok.ID(), owner))
+	}
+	if err := ApplyPermit(p, sig); !errors.Is(err, ErrPermitNonce) {
+		t.Fatalf("replayed permit: %v", err)
+	}
+}
+
+func TestPermitIsBoundToChainAndToken(t *testing.T) {
+	tok, priv, owner := newPermitTest(t, 0x7e570002)
+	otherTok, _, _ := newPermitTest(t, 0x7e570003)
+	p := Permit{Token: tok.ID(), Owner: owner, Spender: Address{0x5e}, Value: 1, Deadline: time.Now().Add(time.Hour).Unix()}
+	sig := signPermit(t, priv, p)
+
+	moved := p
+	moved.Token = otherTok.ID()
+	if err := ApplyPermit(moved, sig); !errors.Is(err, ErrPermitSignature) {
+		t.Fatalf("permit moved to another token: %v", err)
+	}
+	SetPermitChainID(7)
+	t.Cleanup(func() { SetPermitChainID(1215) })
+	if err := ApplyPermit(p, sig); !errors.Is(err, ErrPermitSignature) {
+		t.Fatalf("permit replayed on another chain: %v", err)
+	}
+}
+
+func TestSwapWithPermitChecksBeforeSpending(t *testing.T) {
+	tok, priv, owner := newPermitTest(t, 0x7e570004)
+	deadline := time.Now().Add(time.Hour).Unix()
+
+	other := Permit{Token: tok.ID(), Owner: owner, Spender: Address{0x5e}, Value: 100, Deadline: deadline}
+	if _, err := SwapExactInWithPermit(other, signPermit(t, priv, other), 50, 0x7e57ffff, 0, 4); ErrorCodeOf(err) != CodeInvalidArgument {
+		t.Fatalf("permit for another spender: %v", err)
+	}
+	p := Permit{Token: tok.ID(), Owner: owner, Spender: AMMRouterAccount, Value: 100, Deadline: deadline}
+	sig := signPermit(t, priv, p)
+	if _, err := SwapExactInWithPermit(p, sig, 101, 0x7e57ffff, 0, 4); ErrorCodeOf(err) != CodeInsufficientFunds {
+		t.Fatalf("swap above the permitted value: %v", err)
+	}
+	// no pool trades the token, so the swap fails and the permit stays unused
+	if _, err := SwapExactInWithPermit(p, sig, 50, 0x7e57ffff, 0, 4); err == nil {
+		t.Fatal("swap without a route succeeded")
+	}
+	if PermitNonce(tok.ID(), owner) != 0 || tok.Allowance(owner, AMMRouterAccount) != 0 {
+		t.Fatal("failed swap consumed the permit")
+	}
+	if err := ApplyPermit(p, sig); err != nil {
+		t.Fatalf("permit after the failed swap: %v", err)
+	}
+}
+
+func TestSubmitPermitRequest(t *testing.T) {
+	tok, priv, owner := newPermitTest(t, 0x7e570005)
+	spender := Address{0x5e}
+	p := Permit{Token: tok.ID(), Owner: owner, Spender: spender, Value: 42, Deadline: time.Now().Add(time.Hour).Unix()}
+	req := PermitRequest{Token: p.Token, Owner: owner.Hex(), Spender: spender.Hex(), Value: p.Value,
+		Deadline: p.Deadline, Sig: "0x" + hex.EncodeToString(signPermit(t, priv, p))}
+
+	bad := req
+	bad.Sig = "zz"
+	if _, err := SubmitPermit(bad); ErrorCodeOf(err) != CodeInvalidArgument {
+		t.Fatalf("malformed signature: %v", err)
+	}
+	res, err := SubmitPermit(req)
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	if res.Allowance != 42 || res.Nonce != 1 {
+		t.Fatalf("result %+v", res)
+	}
+
+	info, err := GetPermitInfo(tok.ID(), owner, &spender, 7, p.Deadline)
+	if err != nil || info.Nonce != 1 || info.TypedData == nil || info.VerifyingContract != TokenAddress(tok.ID()).Hex() {
+		t.Fatalf("info %+v %v", info, err)
+	}
+	next := Permit{Token: tok.ID(), Owner: owner, Spender: spender, Value: 7, Nonce: 1, Deadline: p.Deadline}
+	s, err := SignTypedData(priv, *info.TypedData)
+	if err != nil {
+		t.Fatalf("sign info document: %v", err)
+	}
+	sig, _ := hex.DecodeString(s.Sig)
+	if err := ApplyPermit(next, sig); err != nil || tok.Allowance(owner, spender) != 7 {
+		t.Fatalf("permit signed from the info document: %v", err)
+	}
+}
@@ -0,0 +1,168 @@
+package core
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Holder snapshots list every holder of a token with its balance at a
+// height, for airdrops and governance. Balances of tokens held in the
+// ledger ("<addr>:<SYMBOL>" entries, including the chain coin Code) can be
+// read at any height on archive nodes and at the head otherwise; tokens
+// that only live in the token registry are available at the head.
+//
+// The optional Merkle root commits to the holder list so claim contracts
+// can verify an entitlement: leaf i is SnapshotLeaf(address, balance) of
+// the i-th holder, the tree is built by BuildMerkleTree and a proof checks
+// with VerifyMerklePath(root, leaf, proof, i).
+
+// SnapshotOptions filters a holder snapshot.
+type SnapshotOptions struct {
+	Exclude    []Address // e.g. treasury, bridge and exchange wallets
+	MinBalance uint64    // holders below are left out
+	Merkle     bool      // compute the root and per-holder proofs
+}
+
+// SnapshotHolder is one entry of a holder snapshot.
+type SnapshotHolder struct {
+	Index   int      `json:"index"`
+	Address string   `json:"address"`
+	Balance uint64   `json:"balance"`
+	Proof   []string `json:"proof,omitempty"` // hex, leaf level first
+}
+
+// HolderSnapshot is the holder list of a token at a height, largest
+// balance first.
+type HolderSnapshot struct {
+	Token      string           `json:"token"`
+	Height     uint64           `json:"height"`
+	Holders    []SnapshotHolder `json:"holders"`
+	Total      uint64           `json:"total"`
+	Excluded   int              `json:"excluded"`
+	MerkleRoot string           `json:"merkle_root,omitempty"`
+}
+
+// SnapshotLeaf encodes a holder entry as a Merkle leaf: the 20-byte address
+// followed by the balance as a big-endian uint64.
+func SnapshotLeaf(addr Address, balance uint64) []byte {
+	return binary.BigEndian.AppendUint64(append([]byte(nil), addr[:]...), balance)
+}
+
+// TokenSnapshot builds the holder list of token, given by symbol or
+// registry ID, as of height.
+func (l *Ledger) TokenSnapshot(token string, height uint64, opts SnapshotOptions) (*HolderSnapshot, error) {
+	if token == "" {
+		return nil, NewError(CodeInvalidArgument, "ledger", "token required")
+	}
+	head := l.LastHeight()
+	if height > head {
+		return nil, NewError(CodeInvalidArgument, "ledger", "height %d is above the head %d", height, head)
+	}
+	holders, err := l.ledgerHolders(token, height, head)
+	if err != nil {
+		return nil, err
+	}
+	if len(holders) == 0 {
+		if holders, err = registryHolders(token, height, head); err != nil {
+			return nil, err
+		}
+	}
+
+	excluded := make(map[Address]bool, len(opts.Exclude))
+	for _, a := range opts.Exclude {
+		excluded[a] = true
+	}
+	snap := &HolderSnapshot{Token: token, Height: height, Holders: []SnapshotHolder{}}
+	kept := holders[:0]
+	for _, h := range holders {
+		switch {
+		case excluded[h.Address]:
+			snap.Excluded++
+		case h.Balance == 0 || h.Balance < opts.MinBalance:
+		default:
+			kept = append(kept, h)
+		}
+	}
+	sort.Slice(kept, func(i, j int) bool {
+		if kept[i].Balance != kept[j].Balance {
+			return kept[i].Balance > kept[j].Balance
+		}
+		return bytes.Compare(kept[i].Address[:], kept[j].Address[:]) < 0
+	})
+	for i, h := range kept {
+		snap.Holders = append(snap.Holders, SnapshotHolder{Index: i, Address: h.Address.Hex(), Balance: h.Balance})
+		snap.Total += h.Balance
+	}
+	if opts.Merkle && len(kept) > 0 {
+		leaves := make([][]byte, len(kept))
+		for i, h := range kept {
+			leaves[i] = SnapshotLeaf(h.Address, h.Balance)
+		}
+		root, proofs, err := merkleProofs(leaves)
+		if err != nil {
+			return nil, err
+		}
+		snap.MerkleRoot = hex.EncodeToString(root[:])
+		for i := range snap.Holders {
+			snap.Holders[i].Proof = proofs[i]
+		}
+	}
+	return snap, nil
+}
+
+// ledgerHolders reads the ledger balances of token, from the live map at
+// the head and from the archive below it.
+func (l *Ledger) ledgerHolders(token string, height, head uint64) ([]TokenHolder, error) {
+	var out []TokenHolder
+	if height == head {
+		l.mu.RLock()
+		for k, v := range l.Balances {
+			if k.Token == LedgerAsset(token) {
+				out = append(out, TokenHolder{Address: k.Addr, Balance: v})
+			}
+		}
+		l.mu.RUnlock()
+		return out, nil
+	}
+	view, err := l.StateAt(height)
+	if err != nil {
+		return nil, err
+	}
+	for _, k := range view.arch.keysAt(archBalance, height) {
+		if key, err := ParseBalanceKey(strings.TrimPrefix(k, archBalance)); err == nil && key.Token == LedgerAsset(token) {
+			out = append(out, TokenHolder{Address: key.Addr, Balance: view.uint64At(k)})
+		}
+	}
+	return out, nil
+}
+
+// registryHolders lists the holders of a registry token, which keeps no
+// history.
+func registryHolders(token string, height, head uint64) ([]TokenHolder, error) {
+	id, ok := registryTokenID(token)
+	if !ok {
+		return nil, nil
+	}
+	if height != head {
+		return nil, NewError(CodeFailedPrecondition, "ledger", "token %s keeps no history; snapshot it at the head (%d)", token, head)
+	}
+	return TokenHolders(id)
+}
+
+func registryTokenID(token string) (TokenID, bool) {
+	for _, t := range GetRegistryTokens() {
+		if strings.EqualFold(t.Meta().Symbol, token) {
+			return t.ID(), true
+		}
+	}
+	if n, err := strconv.ParseUint(token, 10, 32); err == nil {
+		if _, ok := GetToken(TokenID(n)); ok {
+			return TokenID(n), true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,49 @@
+package core
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+func TestTokenSnapshotProofsVerify(t *testing.T) {
+	a, b, c, d := Address{1}, Address{2}, Address{3}, Address{4}
+	l := &Ledger{Balances: map[BalanceKey]uint64{
+		TokenKey("SYN", a): 50,
+		TokenKey("SYN", b): 300,
+		TokenKey("SYN", c): 50,
+		TokenKey("SYN", d): 1000, // excluded treasury
+		TokenKey("ABC", a): 7,
+		CoinKey(a):         9,
+	}}
+	snap, err := l.TokenSnapshot("SYN", 0, SnapshotOptions{Exclude: []Address{d}, Merkle: true})
+	if err != nil {
+		t.Fatalf("snapshot: %v", err)
+	}
+	want := []Address{b, a, c}
+	if len(snap.Holders) != len(want) || snap.Total != 400 || snap.Excluded != 1 {
+		t.Fatalf("snapshot = %+v", snap)
+	}
+	raw, _ := hex.DecodeString(snap.MerkleRoot)
+	var root [32]byte
+	copy(root[:], raw)
+	for i, h := range snap.Holders {
+		if h.Address != want[i].Hex() {
+			t.Fatalf("holder %d = %s, want %s", i, h.Address, want[i].Hex())
+		}
+		proof := make([][]byte, len(h.Proof))
+		for j, p := range h.Proof {
+			proof[j], _ = hex.DecodeString(p)
+		}
+		if !VerifyMerklePath(root, SnapshotLeaf(want[i], h.Balance), proof, uint32(h.Index)) {
+			t.Fatalf("proof of holder %d does not verify", i)
+		}
+	}
+
+	snap, err = l.TokenSnapshot("SYN", 0, SnapshotOptions{MinBalance: 100})
+	if err != nil || len(snap.Holders) != 2 || snap.MerkleRoot != "" {
+		t.Fatalf("min balance snapshot = %+v, %v", snap, err)
+	}
+	if _, err := l.TokenSnapshot("SYN", 5, SnapshotOptions{}); err == nil {
+		t.Fatal("expected error above the head")
+	}
+}
@@ -33,6 +33,8 @@ func NewSYN600Token(bt *BaseToken, led StateRW) *SYN600Token {
 	return &SYN600Token{BaseToken: bt, ledger: led}
 }
 
+func syn600StakeAccount() Address { return ModuleAddress("syn600_stake") }
+
 func (t *SYN600Token) stakeKey(a Address) []byte  { return []byte(syn600StakePrefix + a.String()) }
 func (t *SYN600Token) engageKey(a Address) []byte { return []byte(engagePrefix + a.String()) }
 
@@ -41,7 +43,7 @@ func (t *SYN600Token) Stake(addr Address, amt uint64, dur time.Duration) error {
 	if amt == 0 {
 		return fmt.Errorf("amount must be >0")
 	}
-	if err := t.Transfer(addr, AddressZero, amt); err != nil {
+	if err := t.Transfer(addr, syn600StakeAccount(), amt); err != nil {
 		return err
 	}
 	rec := StakeRecord{Amount: amt, Unlock: time.Now().Add(dur).Unix()}
@@ -61,7 +63,7 @@ func (t *SYN600Token) Unstake(addr Address) error {
 		return fmt.Errorf("stake still locked")
 	}
 	_ = t.ledger.DeleteState(t.stakeKey(addr))
-	return t.Transfer(AddressZero, addr, rec.Amount)
+	return t.Transfer(syn600StakeAccount(), addr, rec.Amount)
 }
 
 // AddEngagement increases the engagement score for the given user.
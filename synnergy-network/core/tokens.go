@@ -8,6 +8,7 @@ package core
 // simple in-memory registry used by the TokenManager.
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"sort"
@@ -239,6 +240,7 @@ type BaseToken struct {
 	meta      Metadata
 	balances  *BalanceTable
 	allowance map[Address]map[Address]uint64
+	burned    uint64 // lifetime burns; see Supply
 	mu        sync.RWMutex
 	// The ledger and gas calculator are kept for compatibility with existing
 	// code but are not used directly by this minimal implementation.
@@ -260,11 +262,18 @@ func (b *BaseToken) BalanceOf(a Address) uint64 {
 	return b.balances.Get(b.id, a)
 }
 
-// Transfer moves funds between accounts.
+// Transfer moves funds between accounts. A transfer to AddressZero burns
+// the amount.
 func (b *BaseToken) Transfer(from, to Address, amount uint64) error {
 	if b.balances == nil {
 		return fmt.Errorf("balances not initialised")
 	}
+	if from == AddressZero {
+		return ErrZeroAddress
+	}
+	if to == AddressZero {
+		return b.Burn(from, amount)
+	}
 	if err := b.balances.Sub(b.id, from, amount); err != nil {
 		return err
 	}
@@ -320,9 +329,21 @@ func (b *BaseToken) Burn(from Address, amount uint64) error {
 	if b.meta.TotalSupply >= amount {
 		b.meta.TotalSupply -= amount
 	}
+	b.burned += amount
 	return nil
 }
 
+// Supply reports the token's supply. Everything not burned is in
+// circulation, so the minted total is the current supply plus burns.
+func (b *BaseToken) Supply() SupplyStats {
+	return SupplyStats{
+		Asset:       b.meta.Symbol,
+		Minted:      b.meta.TotalSupply + b.burned,
+		Burned:      b.burned,
+		Circulating: b.meta.TotalSupply,
+	}
+}
+
 // -----------------------------------------------------------------------------
 // Token registry and factory
 // -----------------------------------------------------------------------------
@@ -393,6 +414,83 @@ func (Factory) Create(meta Metadata, init map[Address]uint64) (Token, error) {
 	return bt, nil
 }
 
+// -----------------------------------------------------------------------------
+// Invariants
+// -----------------------------------------------------------------------------
+
+func init() { RegisterInvariant("tokens", "supply", checkTokenSupply) }
+
+// balanceSum totals every balance recorded for the token.
+func (b *BaseToken) balanceSum() uint64 {
+	if b.balances == nil {
+		return 0
+	}
+	b.balances.mu.RLock()
+	defer b.balances.mu.RUnlock()
+	var total uint64
+	for _, v := range b.balances.balances[b.id] {
+		total += v
+	}
+	return total
+}
+
+// TokenHolder is an address with a non-zero balance of a token.
+type TokenHolder struct {
+	Address Address `json:"address"`
+	Balance uint64  `json:"balance"`
+}
+
+// holders lists the non-zero balances of the token, largest first.
+func (b *BaseToken) holders() []TokenHolder {
+	if b.balances == nil {
+		return nil
+	}
+	b.balances.mu.RLock()
+	out := make([]TokenHolder, 0, len(b.balances.balances[b.id]))
+	for a, v := range b.balances.balances[b.id] {
+		if v > 0 {
+			out = append(out, TokenHolder{Address: a, Balance: v})
+		}
+	}
+	b.balances.mu.RUnlock()
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Balance != out[j].Balance {
+			return out[i].Balance > out[j].Balance
+		}
+		return bytes.Compare(out[i].Address[:], out[j].Address[:]) < 0
+	})
+	return out
+}
+
+// TokenHolders returns the holders of a registered token, largest balance
+// first.
+func TokenHolders(id TokenID) ([]TokenHolder, error) {
+	t, ok := GetToken(id)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	h, ok := t.(interface{ holders() []TokenHolder })
+	if !ok {
+		return nil, fmt.Errorf("token %d does not expose its holders", id)
+	}
+	return h.holders(), nil
+}
+
+// checkTokenSupply verifies that the total supply of every registered token
+// built on BaseToken equals the sum of its balances.
+func checkTokenSupply() error {
+	for _, t := range GetRegistryTokens() {
+		bs, ok := t.(interface{ balanceSum() uint64 })
+		if !ok {
+			continue
+		}
+		if sum, supply := bs.balanceSum(), t.Meta().TotalSupply; sum != supply {
+			return fmt.Errorf("token %d (%s): balances sum to %d, supply is %d", t.ID(), t.Meta().Symbol, sum, supply)
+		}
+	}
+	return nil
+}
+
 // -----------------------------------------------------------------------------
 // End of file
 // -----------------------------------------------------------------------------
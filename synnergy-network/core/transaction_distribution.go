@@ -129,6 +129,7 @@ func (d *TxDistributor) DistributeFees(from Address, minerPk []byte, fee uint64)
 	if err := d.ledger.Transfer(from, LoanPoolAccount, loanPoolShare); err != nil {
 		return err
 	}
+	recordTreasury(d.ledger, TreasuryFeeShare, from, loanPoolShare, "")
 	if err := d.ledger.Transfer(from, CharityPoolAccount, charityShare); err != nil {
 		return err
 	}
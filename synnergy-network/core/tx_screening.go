@@ -0,0 +1,373 @@
+package core
+
+// tx_screening.go - risk screening of transactions at mem-pool admission.
+//
+// Every transaction entering the pool is scored against a small set of
+// configurable rules: sender velocity, amount z-score (via AnomalyDetector),
+// blacklisted or suspended counterparties and optionally the AI anomaly
+// model. Low-risk transactions are admitted, high-risk ones rejected and the
+// band in between is quarantined until an authority node reviews it through
+// Compliance_ReviewTx. Every non-zero decision is written to the audit trail.
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrTxQuarantined = errors.New("transaction quarantined for compliance review")
+	ErrTxHighRisk    = errors.New("transaction rejected by risk screening")
+)
+
+// ScreeningRules configures the admission screener. Weights are added for
+// every rule a transaction trips and the sum is capped at 1.
+type ScreeningRules struct {
+	VelocityWindow time.Duration `json:"velocity_window"`
+	MaxTxPerWindow int           `json:"max_tx_per_window"`
+	VelocityWeight float64       `json:"velocity_weight"`
+
+	AmountZScore float64 `json:"amount_zscore"`
+	AmountWeight float64 `json:"amount_weight"`
+
+	Blacklist       []Address `json:"blacklist"`
+	BlacklistWeight float64   `json:"blacklist_weight"`
+
+	UseAIModel bool    `json:"use_ai_model"`
+	AIWeight   float64 `json:"ai_weight"`
+
+	RequireKYC bool    `json:"require_kyc"`
+	KYCWeight  float64 `json:"kyc_weight"`
+
+	QuarantineScore float64 `json:"quarantine_score"`
+	RejectScore     float64 `json:"reject_score"`
+}
+
+// DefaultScreeningRules returns conservative defaults: blacklisted
+// counterparties are rejected outright while bursts and outlier amounts are
+// held for review.
+func DefaultScreeningRules() ScreeningRules {
+	return ScreeningRules{
+		VelocityWindow:  time.Minute,
+		MaxTxPerWindow:  30,
+		VelocityWeight:  0.4,
+		AmountZScore:    4,
+		AmountWeight:    0.5,
+		BlacklistWeight: 1,
+		AIWeight:        0.5,
+		KYCWeight:       1,
+		QuarantineScore: 0.5,
+		RejectScore:     1,
+	}
+}
+
+// ScreeningDecision is the outcome of screening a single transaction.
+type ScreeningDecision string
+
+const (
+	ScreenAdmit      ScreeningDecision = "admit"
+	ScreenQuarantine ScreeningDecision = "quarantine"
+	ScreenReject     ScreeningDecision = "reject"
+	ScreenApproved   ScreeningDecision = "approved"
+	ScreenDenied     ScreeningDecision = "denied"
+)
+
+// ScreeningResult records the score and the rules a transaction tripped.
+type ScreeningResult struct {
+	Tx       Hash              `json:"tx"`
+	Score    float64           `json:"score"`
+	Reasons  []string          `json:"reasons,omitempty"`
+	Decision ScreeningDecision `json:"decision"`
+	Time     int64             `json:"time"`
+}
+
+// QuarantinedTx is a transaction awaiting authority review.
+type QuarantinedTx struct {
+	Tx       *Transaction      `json:"tx"`
+	Result   ScreeningResult   `json:"result"`
+	Reviewer Address           `json:"reviewer,omitempty"`
+	Decision ScreeningDecision `json:"decision"`
+	Reviewed int64             `json:"reviewed,omitempty"`
+}
+
+// TxScreener scores transactions before they enter the pool.
+type TxScreener struct {
+	mu        sync.Mutex
+	ledger    *Ledger
+	authority *AuthoritySet
+	rules     ScreeningRules
+	blacklist map[Address]struct{}
+	amounts   *AnomalyDetector
+	velocity  map[Address][]time.Time
+	approved  map[Hash]struct{}
+	release   func(*Transaction) error
+}
+
+var (
+	screenOnce sync.Once
+	screener   *TxScreener
+)
+
+// InitTxScreener initialises the global screener. Subsequent calls are ignored.
+func InitTxScreener(led *Ledger, rules ScreeningRules) *TxScreener {
+	screenOnce.Do(func() {
+		screener = NewTxScreener(led, rules)
+	})
+	return screener
+}
+
+// CurrentScreener returns the global screener or nil when disabled.
+func CurrentScreener() *TxScreener { return screener }
+
+// NewTxScreener constructs a screener with the given rules.
+func NewTxScreener(led *Ledger, rules ScreeningRules) *TxScreener {
+	s := &TxScreener{
+		ledger:    led,
+		authority: CurrentSet(),
+		amounts:   NewAnomalyDetector(),
+		velocity:  make(map[Address][]time.Time),
+		approved:  make(map[Hash]struct{}),
+		blacklist: make(map[Address]struct{}),
+	}
+	s.SetRules(rules)
+	return s
+}
+
+// SetRules replaces the active rule set.
+func (s *TxScreener) SetRules(r ScreeningRules) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rules = r
+	s.blacklist = make(map[Address]struct{}, len(r.Blacklist))
+	for _, a := range r.Blacklist {
+		s.blacklist[a] = struct{}{}
+	}
+}
+
+// Rules returns the active rule set.
+func (s *TxScreener) Rules() ScreeningRules {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rules
+}
+
+// Blacklist adds a counterparty to the screening blacklist.
+func (s *TxScreener) Blacklist(a Address) {
+	s.mu.Lock()
+	s.blacklist[a] = struct{}{}
+	s.rules.Blacklist = append(s.rules.Blacklist, a)
+	s.mu.Unlock()
+}
+
+// Score evaluates tx against the rules without side effects on the
+// quarantine. Velocity and amount statistics are updated.
+func (s *TxScreener) Score(tx *Transaction) ScreeningResult {
+	s.mu.Lock()
+	r := s.rules
+	now := time.Now()
+	res := ScreeningResult{Tx: tx.HashTx(), Time: now.Unix()}
+
+	if r.MaxTxPerWindow > 0 && r.VelocityWindow > 0 {
+		cutoff := now.Add(-r.VelocityWindow)
+		hist := s.velocity[tx.From][:0]
+		for _, t := range s.velocity[tx.From] {
+			if t.After(cutoff) {
+				hist = append(hist, t)
+			}
+		}
+		hist = append(hist, now)
+		s.velocity[tx.From] = hist
+		if len(hist) > r.MaxTxPerWindow {
+			res.Score += r.VelocityWeight
+			res.Reasons = append(res.Reasons, fmt.Sprintf("velocity %d/%s", len(hist), r.VelocityWindow))
+		}
+	}
+	_, fromBL := s.blacklist[tx.From]
+	_, toBL := s.blacklist[tx.To]
+	s.mu.Unlock()
+
+	if r.AmountZScore > 0 && tx.Value > 0 {
+		if z := s.amounts.Score(float64(tx.Value)); z > r.AmountZScore {
+			res.Score += r.AmountWeight
+			res.Reasons = append(res.Reasons, fmt.Sprintf("amount z-score %.2f", z))
+		}
+		s.amounts.Update(float64(tx.Value))
+	}
+
+	if cm := ComplianceMgmt(); cm != nil {
+		fromBL = fromBL || (cm.IsSuspended(tx.From) && !cm.IsWhitelisted(tx.From))
+		toBL = toBL || (cm.IsSuspended(tx.To) && !cm.IsWhitelisted(tx.To))
+	}
+	if fromBL || toBL {
+		res.Score += r.BlacklistWeight
+		res.Reasons = append(res.Reasons, "blacklisted counterparty")
+	}
+
+	if r.RequireKYC && !IsKYCVerified(tx.From) {
+		res.Score += r.KYCWeight
+		res.Reasons = append(res.Reasons, "sender lacks valid KYC credential")
+	}
+
+	if r.UseAIModel {
+		if ai := AI(); ai != nil {
+			if p, err := ai.PredictAnomaly(tx); err == nil {
+				res.Score += r.AIWeight * float64(p)
+				if p >= 0.5 {
+					res.Reasons = append(res.Reasons, fmt.Sprintf("ai anomaly %.2f", p))
+				}
+			}
+		}
+	}
+
+	if res.Score > 1 {
+		res.Score = 1
+	}
+	switch {
+	case r.RejectScore > 0 && res.Score >= r.RejectScore:
+		res.Decision = ScreenReject
+	case r.QuarantineScore > 0 && res.Score >= r.QuarantineScore:
+		res.Decision = ScreenQuarantine
+	default:
+		res.Decision = ScreenAdmit
+	}
+	return res
+}
+
+// Screen scores tx and applies the decision. It returns nil when the
+// transaction may enter the pool, ErrTxQuarantined when it is held for
+// review and ErrTxHighRisk when it is rejected.
+func (s *TxScreener) Screen(tx *Transaction) error {
+	if s == nil || tx == nil {
+		return nil
+	}
+	h := tx.HashTx()
+	s.mu.Lock()
+	_, ok := s.approved[h]
+	delete(s.approved, h)
+	s.mu.Unlock()
+	if ok {
+		return nil
+	}
+
+	res := s.Score(tx)
+	if res.Score > 0 {
+		s.audit(tx.From, "tx_screen_"+string(res.Decision), res)
+	}
+	switch res.Decision {
+	case ScreenReject:
+		return fmt.Errorf("%w: %s", ErrTxHighRisk, strings.Join(res.Reasons, ", "))
+	case ScreenQuarantine:
+		q := QuarantinedTx{Tx: tx, Result: res, Decision: ScreenQuarantine}
+		if err := s.store(&q); err != nil {
+			return err
+		}
+		Broadcast("compliance:quarantine", mustJSON(q))
+		return ErrTxQuarantined
+	}
+	return nil
+}
+
+// ReviewTx resolves a quarantined transaction. Only authority nodes may
+// review. Approved transactions are re-submitted to the attached pool.
+func (s *TxScreener) ReviewTx(h Hash, reviewer Address, approve bool) (*QuarantinedTx, error) {
+	if s.authority == nil || !s.authority.IsMember(reviewer) {
+		return nil, ErrUnauthorized
+	}
+	q, err := s.Quarantined(h)
+	if err != nil {
+		return nil, err
+	}
+	if q.Decision != ScreenQuarantine {
+		return nil, ErrInvalidState
+	}
+	q.Reviewer = reviewer
+	q.Reviewed = time.Now().Unix()
+	q.Decision = ScreenDenied
+	if approve {
+		q.Decision = ScreenApproved
+	}
+	if err := s.store(q); err != nil {
+		return nil, err
+	}
+	s.audit(reviewer, "tx_review_"+string(q.Decision), q.Result)
+	if !approve {
+		return q, nil
+	}
+	s.mu.Lock()
+	s.approved[h] = struct{}{}
+	release := s.release
+	s.mu.Unlock()
+	if release != nil {
+		if err := release(q.Tx); err != nil {
+			return q, fmt.Errorf("resubmit: %w", err)
+		}
+	}
+	return q, nil
+}
+
+// Quarantined returns a quarantine record by transaction hash.
+func (s *TxScreener) Quarantined(h Hash) (*QuarantinedTx, error) {
+	raw, err := s.ledger.GetState(quarantineKey(h))
+	if err != nil || len(raw) == 0 {
+		return nil, ErrNotFound
+	}
+	var q QuarantinedTx
+	if err := json.Unmarshal(raw, &q); err != nil {
+		return nil, err
+	}
+	return &q, nil
+}
+
+// PendingReview lists transactions still awaiting a review decision.
+func (s *TxScreener) PendingReview() ([]QuarantinedTx, error) {
+	it := s.ledger.PrefixIterator([]byte("screen:quarantine:"))
+	var out []QuarantinedTx
+	for it.Next() {
+		var q QuarantinedTx
+		if err := json.Unmarshal(it.Value(), &q); err != nil {
+			return nil, err
+		}
+		if q.Decision == ScreenQuarantine {
+			out = append(out, q)
+		}
+	}
+	return out, it.Error()
+}
+
+func (s *TxScreener) store(q *QuarantinedTx) error {
+	raw, err := json.Marshal(q)
+	if err != nil {
+		return err
+	}
+	return s.ledger.SetState(quarantineKey(q.Result.Tx), raw)
+}
+
+func (s *TxScreener) audit(addr Address, event string, res ScreeningResult) {
+	am := AuditManagerInstance()
+	if am == nil {
+		return
+	}
+	_ = am.Log(addr, event, map[string]string{
+		"tx":      hex.EncodeToString(res.Tx[:]),
+		"score":   fmt.Sprintf("%.3f", res.Score),
+		"reasons": strings.Join(res.Reasons, "; "),
+	})
+}
+
+func quarantineKey(h Hash) []byte {
+	return []byte("screen:quarantine:" + hex.EncodeToString(h[:]))
+}
+
+// ComplianceReviewTx is the VM entry point for authority review of a
+// quarantined transaction.
+func ComplianceReviewTx(h Hash, reviewer Address, approve bool) (*QuarantinedTx, error) {
+	s := CurrentScreener()
+	if s == nil {
+		return nil, errors.New("tx screening not enabled")
+	}
+	return s.ReviewTx(h, reviewer, approve)
+}
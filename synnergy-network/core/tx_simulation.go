@@ -0,0 +1,333 @@
+package core
+
+// tx_simulation.go – dry-run execution of transactions.
+//
+// SimulateTx runs a transaction against a copy of the ledger state, at the
+// head or, on archive nodes, after a past block, and reports what applying
+// it would do: the receipt with gas used, logs and the revert reason of a
+// failed contract call, the fee and the state diff. Nothing is committed.
+// The transaction need not be signed, so wallets can show the outcome
+// before asking for a signature.
+//
+// Overrides replace an account's balance, nonce or contract code and raw
+// state keys for the one simulation, e.g. to try a call from an address
+// that is not funded yet. Pool admission checks (nonce, funds, destination
+// tag) run against the overridden state; a transaction failing them is
+// reported as rejected without being executed.
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"math/bits"
+)
+
+// StateOverride replaces parts of an account's state for one simulation.
+type StateOverride struct {
+	Balance *uint64           `json:"balance,omitempty"`
+	Nonce   *uint64           `json:"nonce,omitempty"`
+	Code    string            `json:"code,omitempty"`    // hex contract bytecode
+	Storage map[string]string `json:"storage,omitempty"` // hex state key -> hex value, "" deletes
+}
+
+// SimulateOptions select the state a transaction is simulated against.
+type SimulateOptions struct {
+	// Height simulates against the state after that block; nil is the head.
+	// Past heights need an archive node.
+	Height *uint64 `json:"height,omitempty"`
+	// Overrides are keyed by hex or bech32 address.
+	Overrides map[string]StateOverride `json:"overrides,omitempty"`
+}
+
+// SimulateRequest is the body of POST /tx/simulate.
+type SimulateRequest struct {
+	Tx Transaction `json:"tx"`
+	SimulateOptions
+}
+
+// SimulationResult is the expected outcome of a transaction.
+type SimulationResult struct {
+	Height  uint64  `json:"height"`
+	Receipt Receipt `json:"receipt"`
+	Fee     uint64  `json:"fee"`
+	// Rejected is why the pool would refuse the transaction; it was not
+	// executed.
+	Rejected string `json:"rejected,omitempty"`
+	// RevertReason is why an executed contract call failed: the decoded
+	// revert message, or the VM error when the call did not revert.
+	RevertReason string    `json:"revert_reason,omitempty"`
+	Diff         StateDiff `json:"diff"`
+}
+
+// simState is the sandbox a simulation runs in and the values it started
+// from.
+type simState struct {
+	ms       *memState
+	height   uint64
+	base     map[string][]byte
+	balances map[Address]uint64
+}
+
+// SimulateTx executes tx against a copy of the state without committing.
+func (l *Ledger) SimulateTx(tx *Transaction, opts SimulateOptions) (*SimulationResult, error) {
+	if tx == nil {
+		return nil, NewError(CodeInvalidArgument, "simulate", "transaction required")
+	}
+	touched := []Address{tx.From, tx.To}
+	for _, tr := range tx.TokenTransfers {
+		touched = append(touched, tr.From, tr.To)
+	}
+	for _, c := range tx.Calls {
+		touched = append(touched, c.To)
+	}
+	overrides := make(map[Address]StateOverride, len(opts.Overrides))
+	for k, o := range opts.Overrides {
+		a, err := DecodeAddress(k)
+		if err != nil {
+			return nil, NewError(CodeInvalidArgument, "simulate", "override %q: %v", k, err)
+		}
+		overrides[a] = o
+		touched = append(touched, a)
+	}
+	code := []Address{tx.To}
+	if tx.Contract != nil {
+		code = append(code, tx.Contract.Address)
+	}
+	for _, c := range tx.Calls {
+		code = append(code, c.To)
+	}
+	st, err := l.simulationState(opts.Height, code, touched)
+	if err != nil {
+		return nil, err
+	}
+	if err := st.override(overrides); err != nil {
+		return nil, err
+	}
+	return st.run(tx), nil
+}
+
+// simulationState copies the state at height, or the head, into a sandbox
+// holding the coin balances of touched and the contracts deployed at code.
+func (l *Ledger) simulationState(height *uint64, code, touched []Address) (*simState, error) {
+	st := &simState{balances: make(map[Address]uint64)}
+	ms := &memState{
+		data:       make(map[string][]byte),
+		balances:   make(map[Address]uint64),
+		lpBalances: make(map[Address]map[PoolID]uint64),
+		contracts:  make(map[Address][]byte),
+		tokens:     make(map[TokenID]Token),
+		codeHashes: make(map[Address]Hash),
+		nonces:     make(map[Address]uint64),
+	}
+	st.ms = ms
+
+	if height != nil && *height < l.LastHeight() {
+		v, err := l.StateAt(*height)
+		if err != nil {
+			return nil, err
+		}
+		st.height = *height
+		for _, k := range v.arch.keysAt(archState, v.height) {
+			val, _ := v.arch.get(k, v.height)
+			ms.data[k[len(archState):]] = append([]byte(nil), val...)
+		}
+		for _, k := range v.arch.keysAt(archNonce, v.height) {
+			if a, err := ParseAddress(k[len(archNonce):]); err == nil {
+				ms.nonces[a] = v.uint64At(k)
+			}
+		}
+		for _, a := range touched {
+			ms.balances[a] = v.CoinBalance(a)
+		}
+		for _, a := range code {
+			if c, err := v.GetContract(a[:]); err == nil {
+				ms.contracts[a] = append([]byte(nil), c.Bytecode...)
+			}
+		}
+	} else {
+		st.height = l.LastHeight()
+		l.mu.RLock()
+		for k, val := range l.State {
+			ms.data[k] = append([]byte(nil), val...)
+		}
+		for a, n := range l.nonces {
+			ms.nonces[a] = n
+		}
+		for _, a := range touched {
+			ms.balances[a] = l.Balances[CoinKey(a)]
+		}
+		for _, a := range code {
+			if c, ok := l.Contracts[fmt.Sprintf("%x", a)]; ok {
+				ms.contracts[a] = append([]byte(nil), c.Bytecode...)
+			}
+		}
+		l.mu.RUnlock()
+	}
+	l.mu.RLock()
+	for k, t := range l.tokens {
+		ms.tokens[k] = t
+	}
+	l.mu.RUnlock()
+	return st, nil
+}
+
+func (st *simState) override(overrides map[Address]StateOverride) error {
+	ms := st.ms
+	for a, o := range overrides {
+		if o.Balance != nil {
+			ms.balances[a] = *o.Balance
+		}
+		if o.Nonce != nil {
+			ms.nonces[a] = *o.Nonce
+		}
+		if o.Code != "" {
+			code, err := hex.DecodeString(trimHex(o.Code))
+			if err != nil {
+				return NewError(CodeInvalidArgument, "simulate", "override %s code: %v", a.Hex(), err)
+			}
+			ms.contracts[a] = code
+		}
+		for k, v := range o.Storage {
+			key, err := hex.DecodeString(trimHex(k))
+			if err != nil {
+				return NewError(CodeInvalidArgument, "simulate", "override storage key %q: %v", k, err)
+			}
+			if v == "" {
+				delete(ms.data, string(key))
+				continue
+			}
+			val, err := hex.DecodeString(trimHex(v))
+			if err != nil {
+				return NewError(CodeInvalidArgument, "simulate", "override storage %q: %v", k, err)
+			}
+			ms.data[string(key)] = val
+		}
+	}
+	st.base = make(map[string][]byte, len(ms.data))
+	for k, v := range ms.data {
+		st.base[k] = v
+	}
+	for a, b := range ms.balances {
+		st.balances[a] = b
+	}
+	return nil
+}
+
+func trimHex(s string) string {
+	if len(s) >= 2 && s[0] == '0' && (s[1] == 'x' || s[1] == 'X') {
+		return s[2:]
+	}
+	return s
+}
+
+// run applies tx to the sandbox the way a block would.
+func (st *simState) run(tx *Transaction) *SimulationResult {
+	ms := st.ms
+	res := &SimulationResult{Height: st.height, Receipt: Receipt{Status: true}}
+	res.Diff = StateDiff{Height: st.height, TxHash: tx.ID().Hex(), Accounts: []AccountDiff{}, Storage: []StorageDiff{}}
+	reject := func(format string, args ...interface{}) *SimulationResult {
+		res.Receipt = Receipt{Status: false, Error: fmt.Sprintf(format, args...)}
+		res.Rejected = res.Receipt.Error
+		return res
+	}
+
+	if err := CheckDestinationTag(tx); err != nil {
+		return reject("%v", err)
+	}
+	if tx.Type == TxMulticall {
+		if err := ValidateMulticall(tx); err != nil {
+			return reject("%v", err)
+		}
+	}
+	if want := ms.nonces[tx.From]; tx.Nonce != want {
+		return reject("nonce mismatch: got %d want %d", tx.Nonce, want)
+	}
+	hi, fee := bits.Mul64(tx.GasLimit, tx.GasPrice)
+	cost, carry := bits.Add64(fee, tx.Value, 0)
+	if hi != 0 || carry != 0 {
+		return reject("fee overflows")
+	}
+	if bal := ms.balances[tx.From]; bal < cost {
+		return reject("insufficient funds: balance %d < cost %d", bal, cost)
+	}
+	res.Fee = fee
+	ms.balances[tx.From] -= fee
+
+	switch {
+	case tx.Type == TxContractCall:
+		rec, err := ms.execute(tx.From, tx.To, tx.Payload, new(big.Int).SetUint64(tx.Value), tx.GasLimit, nil)
+		if rec == nil {
+			rec = &Receipt{Status: false}
+		}
+		if err != nil && rec.Error == "" {
+			rec.Error = err.Error()
+		}
+		if !rec.Status {
+			res.RevertReason = rec.Error
+			if rec.Revert != nil {
+				res.RevertReason = rec.Revert.Message
+			}
+		}
+		res.Receipt = *rec
+	case tx.Type == TxMulticall:
+		rec, _ := ExecuteMulticall(ms, tx.From, tx.Calls, tx.GasLimit, nil)
+		if !rec.Status {
+			res.RevertReason = rec.Error
+			if rec.Revert != nil {
+				res.RevertReason = rec.Revert.Message
+			}
+		}
+		res.Receipt = *rec
+	case tx.Value > 0:
+		ms.balances[tx.From] -= tx.Value
+		ms.balances[tx.To] += tx.Value
+	}
+	if res.Receipt.Status {
+		for k, v := range tx.StateChanges {
+			ms.data[k] = v
+		}
+		for _, tr := range tx.TokenTransfers {
+			ms.balances[tr.From] -= tr.Amount
+			ms.balances[tr.To] += tr.Amount
+		}
+		if tx.Contract != nil {
+			if _, ok := ms.contracts[tx.Contract.Address]; !ok {
+				res.Diff.ContractsCreated = append(res.Diff.ContractsCreated, tx.Contract.Address.Hex())
+			}
+		}
+		for _, in := range tx.Inputs {
+			res.Diff.UTXOsSpent = append(res.Diff.UTXOsSpent, utxoKey(in.TxID, in.Index))
+		}
+		for i := range tx.Outputs {
+			res.Diff.UTXOsCreated = append(res.Diff.UTXOsCreated, utxoKey(tx.ID(), uint32(i)))
+		}
+	}
+	st.diff(&res.Diff)
+	return res
+}
+
+// diff records the balances and keys the run changed.
+func (st *simState) diff(d *StateDiff) {
+	ms := st.ms
+	for a, after := range ms.balances {
+		before := st.balances[a]
+		if after != before {
+			d.Accounts = append(d.Accounts, AccountDiff{Address: a.Hex(), Before: before, After: after, Delta: int64(after) - int64(before)})
+		}
+	}
+	for k, after := range ms.data {
+		before, existed := st.base[k]
+		if existed && string(before) == string(after) {
+			continue
+		}
+		d.Storage = append(d.Storage, StorageDiff{Key: hex.EncodeToString([]byte(k)),
+			Before: hex.EncodeToString(before), After: hex.EncodeToString(after), Created: !existed})
+	}
+	for k, before := range st.base {
+		if _, ok := ms.data[k]; !ok {
+			d.Storage = append(d.Storage, StorageDiff{Key: hex.EncodeToString([]byte(k)),
+				Before: hex.EncodeToString(before), Deleted: true})
+		}
+	}
+	d.sort()
+}
@@ -20,6 +20,9 @@ const (
 	// TxReversal denotes an authority-approved reversal of a previous
 	// transaction. The recipient refunds the sender minus a protocol fee.
 	TxReversal
+	// TxMulticall executes an ordered list of calls and transfers
+	// atomically.
+	TxMulticall
 
 
 )
@@ -0,0 +1,327 @@
+package core
+
+// txpool.go – transaction signing, validation and the mem-pool. Shared by
+// both builds; only the hashing in transactions.go differs under the tokens
+// tag.
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// -----------------------------------------------------------------------------
+// Tx signing / verification
+// -----------------------------------------------------------------------------
+
+func (tx *Transaction) Sign(priv *ecdsa.PrivateKey) error {
+	if priv == nil {
+		return errors.New("nil privkey")
+	}
+	tx.HashTx()
+
+	sig, err := crypto.Sign(tx.Hash[:], priv) // 65-byte {R||S||V}
+	if err != nil {
+		return err
+	}
+	tx.Sig = sig
+	tx.From = FromCommon(crypto.PubkeyToAddress(priv.PublicKey))
+	return nil
+}
+
+// Signer recovers the address whose key produced tx.Sig.
+func (tx *Transaction) Signer() (Address, error) {
+	if len(tx.Sig) != 65 {
+		return AddressZero, errors.New("missing or malformed sig")
+	}
+
+	pubKey, err := crypto.SigToPub(tx.Hash[:], tx.Sig)
+	if err != nil {
+		return AddressZero, err
+	}
+	if !crypto.VerifySignature(
+		crypto.FromECDSAPub(pubKey),
+		tx.Hash[:],
+		tx.Sig[:64], // R||S
+	) {
+		return AddressZero, errors.New("verify fail")
+	}
+	return FromCommon(crypto.PubkeyToAddress(*pubKey)), nil
+}
+
+// VerifySig checks that tx is signed by the sender's current signer or by
+// one of its session keys. Session key policies are enforced by the pool.
+func (tx *Transaction) VerifySig() error {
+	signer, err := tx.Signer()
+	if err != nil {
+		return err
+	}
+	// accounts recovered through guardians are signed by their rotated key
+	if signer != AccountSigner(tx.From) && !IsSessionKey(tx.From, signer) {
+		return errors.New("sender mismatch")
+	}
+	return nil
+}
+
+// -----------------------------------------------------------------------------
+// TxPool.ValidateTx – authority signatures for TxReversal
+// -----------------------------------------------------------------------------
+
+func (tp *TxPool) ValidateTx(tx *Transaction) error {
+	if err := tx.VerifySig(); err != nil {
+		return err
+	}
+	if err := CheckDestinationTag(tx); err != nil {
+		return err
+	}
+	if err := CheckTxSanctions(tx); err != nil {
+		return err
+	}
+	if err := CurrentResourceLimits().CheckTxLimits(tx); err != nil {
+		return err
+	}
+	if signer, _ := tx.Signer(); signer != AccountSigner(tx.From) {
+		if err := SessionKeys().CheckTx(tx, signer); err != nil {
+			return err
+		}
+	}
+	if fw := CurrentFirewall(); fw != nil {
+		if err := fw.CheckTx(tx); err != nil {
+			return err
+		}
+	}
+	tp.mu.RLock()
+	sc := tp.screener
+	tp.mu.RUnlock()
+	if err := sc.Screen(tx); err != nil {
+		return err
+	}
+	// … other checks omitted …
+
+	if tx.Type == TxReversal {
+		if len(tx.AuthSigs) < 3 {
+			return errors.New("need 3 authority sigs")
+		}
+		for _, sig := range tx.AuthSigs {
+			if len(sig) != 65 {
+				return errors.New("malformed authority sig")
+			}
+			pub, err := crypto.SigToPub(tx.Hash[:], sig)
+			if err != nil {
+				return err
+			}
+			if !crypto.VerifySignature(
+				crypto.FromECDSAPub(pub),
+				tx.Hash[:],
+				sig[:64],
+			) {
+				return errors.New("invalid authority sig")
+			}
+			addr := FromCommon(crypto.PubkeyToAddress(*pub))
+			if !tp.authority.IsAuthority(addr) {
+				return fmt.Errorf("sig %x not authority", addr)
+			}
+		}
+	}
+	if tx.Type == TxMulticall {
+		if err := ValidateMulticall(tx); err != nil {
+			return err
+		}
+	}
+
+	// … remaining validation …
+	return nil
+}
+
+// -----------------------------------------------------------------------------
+// txItem / txPriorityQueue – highest priority first, ties by hash
+// -----------------------------------------------------------------------------
+
+type txItem struct {
+	tx    *Transaction
+	pr    float64
+	index int
+}
+
+type txPriorityQueue []*txItem
+
+func (pq txPriorityQueue) Len() int { return len(pq) }
+func (pq txPriorityQueue) Less(i, j int) bool {
+	if pq[i].pr != pq[j].pr {
+		return pq[i].pr > pq[j].pr
+	}
+	return bytes.Compare(pq[i].tx.Hash[:], pq[j].tx.Hash[:]) < 0
+}
+func (pq txPriorityQueue) Swap(i, j int) {
+	pq[i], pq[j] = pq[j], pq[i]
+	pq[i].index, pq[j].index = i, j
+}
+func (pq *txPriorityQueue) Push(x interface{}) { *pq = append(*pq, x.(*txItem)) }
+func (pq *txPriorityQueue) Pop() interface{} {
+	old := *pq
+	n := len(old)
+	it := old[n-1]
+	*pq = old[:n-1]
+	return it
+}
+
+// -----------------------------------------------------------------------------
+// TxPool skeleton – minimal fields & ctor compile-ready
+// -----------------------------------------------------------------------------
+
+// txBroadcaster gossips admitted transactions; *Node satisfies it.
+type txBroadcaster interface {
+	Broadcast(topic string, data []byte) error
+}
+
+func NewTxPool(
+	lg *log.Logger, // ← unused for now
+	led ReadOnlyState,
+	auth *AuthoritySet,
+	gasCalc GasCalculator,
+	net txBroadcaster,
+	maxBytes int, // ← unused for now
+) *TxPool {
+
+	return &TxPool{
+		ledger:    led,
+		gasCalc:   gasCalc,
+		net:       net,
+		authority: auth,
+
+		// types must match the struct definition:
+		lookup: make(map[Hash]*Transaction),
+		queue:  make([]*Transaction, 0),
+	}
+}
+
+// -----------------------------------------------------------------------------
+// TxPool operations
+// -----------------------------------------------------------------------------
+
+// AddTx validates and inserts a new transaction into the mem-pool.
+// The caller is responsible for providing a signed transaction.
+// Duplicate transactions are rejected. Basic balance and nonce checks
+// are performed against the attached ledger.
+func (tp *TxPool) AddTx(tx *Transaction) error {
+	if tx == nil {
+		return errors.New("nil transaction")
+	}
+	if err := tp.ValidateTx(tx); err != nil {
+		return err
+	}
+
+	tp.mu.Lock()
+	defer tp.mu.Unlock()
+
+	if _, exists := tp.lookup[tx.Hash]; exists {
+		return fmt.Errorf("tx %s already in pool", tx.IDHex())
+	}
+
+	if tp.ledger != nil {
+		expNonce := tp.ledger.NonceOf(tx.From)
+		if tx.Nonce != expNonce {
+			return fmt.Errorf("nonce mismatch: got %d want %d", tx.Nonce, expNonce)
+		}
+		bal := tp.ledger.BalanceOf(tx.From)
+		gas, err := tp.gasCalc.Estimate(tx.Payload)
+		if err != nil {
+			return fmt.Errorf("gas estimate: %w", err)
+		}
+		cost := tx.Value + gas*tx.GasPrice
+		if bal < cost {
+			return fmt.Errorf("insufficient funds: balance %d < cost %d", bal, cost)
+		}
+	}
+
+	if signer, _ := tx.Signer(); signer != AccountSigner(tx.From) {
+		if err := SessionKeys().ReserveTx(tx, signer); err != nil {
+			return err
+		}
+	}
+
+	tp.lookup[tx.Hash] = tx
+	tp.queue = append(tp.queue, tx)
+
+	if tp.net != nil {
+		if data, err := json.Marshal(tx); err == nil {
+			_ = tp.net.Broadcast("tx:new", data)
+		}
+	}
+	return nil
+}
+
+// EnableScreening attaches s to the pool so AddTx screens incoming
+// transactions and reviewed ones are re-admitted.
+func (tp *TxPool) EnableScreening(s *TxScreener) {
+	tp.mu.Lock()
+	tp.screener = s
+	tp.mu.Unlock()
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.authority = tp.authority
+	s.release = tp.AddTx
+	s.mu.Unlock()
+}
+
+// Pick removes up to max transactions from the pool and returns their
+// serialized form for inclusion in a block. Transactions are returned in
+// FIFO order.
+func (tp *TxPool) Pick(max int) [][]byte {
+	tp.mu.Lock()
+	defer tp.mu.Unlock()
+
+	if max <= 0 || max > len(tp.queue) {
+		max = len(tp.queue)
+	}
+	out := make([][]byte, 0, max)
+	for i := 0; i < max; i++ {
+		tx := tp.queue[0]
+		tp.queue = tp.queue[1:]
+		delete(tp.lookup, tx.Hash)
+		blob, _ := json.Marshal(tx)
+		out = append(out, blob)
+	}
+	return out
+}
+
+// Len returns the number of pending transactions.
+func (tp *TxPool) Len() int {
+	tp.mu.RLock()
+	defer tp.mu.RUnlock()
+	return len(tp.queue)
+}
+
+// Snapshot returns a copy of all pending transactions for inspection.
+func (tp *TxPool) Snapshot() []*Transaction {
+	if tp == nil {
+		return nil
+	}
+
+	tp.mu.RLock()
+	defer tp.mu.RUnlock()
+
+	if len(tp.queue) == 0 {
+		return nil
+	}
+
+	list := make([]*Transaction, len(tp.queue))
+	copy(list, tp.queue)
+	return list
+}
+
+// Run keeps the pool alive until the context is cancelled.  This is a hook for
+// future background processing (timeouts, rebroadcast, etc.).
+func (tp *TxPool) Run(ctx context.Context) {
+	<-ctx.Done()
+}
+
+func (a *AuthoritySet) ActiveAddresses() []Address { return nil }
@@ -0,0 +1,31 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// BenchmarkTxPoolAddTx measures mem-pool admission: signature recovery,
+// validation and insertion of pre-signed transfers.
+func BenchmarkTxPoolAddTx(b *testing.B) {
+	priv, err := crypto.GenerateKey()
+	if err != nil {
+		b.Fatal(err)
+	}
+	txs := make([]*Transaction, b.N)
+	for i := range txs {
+		txs[i] = &Transaction{Type: TxPayment, Value: 1, GasLimit: 21_000, GasPrice: 1, Nonce: uint64(i)}
+		if err := txs[i].Sign(priv); err != nil {
+			b.Fatal(err)
+		}
+	}
+	tp := NewTxPool(nil, nil, nil, nil, nil, 0)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for _, tx := range txs {
+		if err := tp.AddTx(tx); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
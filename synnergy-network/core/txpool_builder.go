@@ -0,0 +1,286 @@
+package core
+
+// txpool_builder.go – block-builder transaction selection.
+//
+// PickTxs selects the transactions for a block so that a miner or validator
+// maximises fee revenue deterministically: given the same pool, bundles and
+// options every node builds the same list.
+//
+//  1. Bundles (externally supplied transaction groups, e.g. from searchers)
+//     are placed first, by lane and then by effective tip per gas. A bundle
+//     is included whole or not at all; it is rejected when one of its
+//     transactions conflicts with one already selected (same hash, or the
+//     sender's nonce already used), breaks its sender's nonce sequence,
+//     pays less than the base fee or does not fit the gas limits.
+//  2. The public pool fills the rest. Each sender's transactions form a
+//     queue in nonce order; the head with the highest effective tip is
+//     taken next (ties broken by hash). A sender is dropped at a nonce gap,
+//     or when its next transaction would exceed the block or account gas
+//     limit.
+//
+// The effective tip of a transaction is GasPrice - BaseFee per unit of gas;
+// transactions below the base fee are never selected.
+
+import (
+	"bytes"
+	"container/heap"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// BlockBuilderOptions bounds a PickTxs selection.
+type BlockBuilderOptions struct {
+	MaxTxs          int        // 0 = no limit
+	GasLimit        uint64     // block gas budget (0 = governance block_gas_limit)
+	AccountGasLimit uint64     // gas any one sender may use (0 = no limit)
+	BaseFee         uint64     // per-gas fee burned before the tip
+	Bundles         []TxBundle // in addition to bundles submitted to the pool
+}
+
+// BuiltBlock is the outcome of PickTxs.
+type BuiltBlock struct {
+	Txs      []*Transaction    `json:"txs"`
+	GasUsed  uint64            `json:"gas_used"`
+	Tips     uint64            `json:"tips"`
+	Bundles  []string          `json:"bundles,omitempty"`
+	Rejected map[string]string `json:"rejected,omitempty"` // bundle ID → reason
+}
+
+// SubmitBundle validates a bundle's transactions and queues it for the
+// next PickTxs call.
+func (tp *TxPool) SubmitBundle(b TxBundle) error {
+	if b.ID == "" || len(b.Txs) == 0 {
+		return errors.New("bundle needs an id and transactions")
+	}
+	for _, tx := range b.Txs {
+		if err := tx.VerifySig(); err != nil {
+			return fmt.Errorf("bundle %s: tx %s: %w", b.ID, tx.IDHex(), err)
+		}
+	}
+	tp.mu.Lock()
+	defer tp.mu.Unlock()
+	for _, q := range tp.bundles {
+		if q.ID == b.ID {
+			return fmt.Errorf("bundle %s already queued", b.ID)
+		}
+	}
+	tp.bundles = append(tp.bundles, b)
+	return nil
+}
+
+// effectiveTip returns the per-gas tip over baseFee and whether the
+// transaction pays the base fee at all.
+func effectiveTip(tx *Transaction, baseFee uint64) (uint64, bool) {
+	if tx.GasPrice < baseFee {
+		return 0, false
+	}
+	return tx.GasPrice - baseFee, true
+}
+
+// blockSelection tracks the running state of a PickTxs call.
+type blockSelection struct {
+	opts     BlockBuilderOptions
+	nonces   map[Address]uint64
+	gasBy    map[Address]uint64
+	included map[Hash]bool
+	out      BuiltBlock
+}
+
+func (s *blockSelection) full() bool {
+	return s.opts.MaxTxs > 0 && len(s.out.Txs) >= s.opts.MaxTxs
+}
+
+// check reports why tx cannot follow the current selection, given the
+// extra gas and nonces already claimed by earlier txs of the same bundle.
+func (s *blockSelection) check(tx *Transaction, gas uint64, acct map[Address]uint64, nonces map[Address]uint64) error {
+	if s.included[tx.Hash] {
+		return fmt.Errorf("tx %s already selected", tx.IDHex())
+	}
+	if _, ok := effectiveTip(tx, s.opts.BaseFee); !ok {
+		return fmt.Errorf("tx %s pays below the base fee", tx.IDHex())
+	}
+	if want := nonces[tx.From]; tx.Nonce != want {
+		return fmt.Errorf("tx %s nonce %d, want %d", tx.IDHex(), tx.Nonce, want)
+	}
+	if s.out.GasUsed+gas+tx.GasLimit > s.opts.GasLimit {
+		return fmt.Errorf("tx %s exceeds the block gas limit", tx.IDHex())
+	}
+	if l := s.opts.AccountGasLimit; l > 0 && s.gasBy[tx.From]+acct[tx.From]+tx.GasLimit > l {
+		return fmt.Errorf("tx %s exceeds the account gas limit", tx.IDHex())
+	}
+	return nil
+}
+
+func (s *blockSelection) add(tx *Transaction) {
+	tip, _ := effectiveTip(tx, s.opts.BaseFee)
+	s.out.Txs = append(s.out.Txs, tx)
+	s.out.GasUsed += tx.GasLimit
+	s.out.Tips += tip * tx.GasLimit
+	s.gasBy[tx.From] += tx.GasLimit
+	s.nonces[tx.From] = tx.Nonce + 1
+	s.included[tx.Hash] = true
+}
+
+// tryBundle adds every transaction of b or none of them.
+func (s *blockSelection) tryBundle(b TxBundle) error {
+	if s.opts.MaxTxs > 0 && len(s.out.Txs)+len(b.Txs) > s.opts.MaxTxs {
+		return errors.New("bundle exceeds the transaction limit")
+	}
+	nonces := make(map[Address]uint64)
+	for a, n := range s.nonces {
+		nonces[a] = n
+	}
+	acct := make(map[Address]uint64)
+	seen := make(map[Hash]bool)
+	var gas uint64
+	for _, tx := range b.Txs {
+		if seen[tx.Hash] {
+			return fmt.Errorf("tx %s repeated in bundle", tx.IDHex())
+		}
+		if err := s.check(tx, gas, acct, nonces); err != nil {
+			return err
+		}
+		seen[tx.Hash] = true
+		nonces[tx.From] = tx.Nonce + 1
+		acct[tx.From] += tx.GasLimit
+		gas += tx.GasLimit
+	}
+	for _, tx := range b.Txs {
+		s.add(tx)
+	}
+	s.out.Bundles = append(s.out.Bundles, b.ID)
+	return nil
+}
+
+func bundleTipPerGas(b TxBundle, baseFee uint64) float64 {
+	var tips, gas uint64
+	for _, tx := range b.Txs {
+		tip, _ := effectiveTip(tx, baseFee)
+		tips += tip * tx.GasLimit
+		gas += tx.GasLimit
+	}
+	if gas == 0 {
+		return 0
+	}
+	return float64(tips) / float64(gas)
+}
+
+// PickTxs removes the selected transactions and bundles from the pool and
+// returns them in block order. Rejected pool bundles are dropped; their
+// reasons are reported in BuiltBlock.Rejected.
+func (tp *TxPool) PickTxs(opts BlockBuilderOptions) (*BuiltBlock, error) {
+	if opts.GasLimit == 0 {
+		opts.GasLimit = blockGasLimit
+	}
+	tp.mu.Lock()
+	defer tp.mu.Unlock()
+
+	s := &blockSelection{
+		opts:     opts,
+		nonces:   make(map[Address]uint64),
+		gasBy:    make(map[Address]uint64),
+		included: make(map[Hash]bool),
+	}
+
+	// Per-sender queues in nonce order; the expected first nonce comes
+	// from the ledger when one is attached, else from the lowest pending.
+	queues := make(map[Address][]*Transaction)
+	for _, tx := range tp.queue {
+		queues[tx.From] = append(queues[tx.From], tx)
+	}
+	bundles := append(append([]TxBundle(nil), tp.bundles...), opts.Bundles...)
+	for _, b := range bundles {
+		for _, tx := range b.Txs {
+			if _, ok := queues[tx.From]; !ok {
+				queues[tx.From] = nil
+			}
+		}
+	}
+	for addr, q := range queues {
+		sort.SliceStable(q, func(i, j int) bool { return q[i].Nonce < q[j].Nonce })
+		switch {
+		case tp.ledger != nil:
+			s.nonces[addr] = tp.ledger.NonceOf(addr)
+		case len(q) > 0:
+			s.nonces[addr] = q[0].Nonce
+		default:
+			s.nonces[addr] = ^uint64(0)
+			for _, b := range bundles {
+				for _, tx := range b.Txs {
+					if tx.From == addr && tx.Nonce < s.nonces[addr] {
+						s.nonces[addr] = tx.Nonce
+					}
+				}
+			}
+		}
+	}
+
+	// 1. Priority lanes.
+	sort.SliceStable(bundles, func(i, j int) bool {
+		if bundles[i].Lane != bundles[j].Lane {
+			return bundles[i].Lane < bundles[j].Lane
+		}
+		ti, tj := bundleTipPerGas(bundles[i], opts.BaseFee), bundleTipPerGas(bundles[j], opts.BaseFee)
+		if ti != tj {
+			return ti > tj
+		}
+		return bundles[i].ID < bundles[j].ID
+	})
+	for _, b := range bundles {
+		if err := s.tryBundle(b); err != nil {
+			if s.out.Rejected == nil {
+				s.out.Rejected = make(map[string]string)
+			}
+			s.out.Rejected[b.ID] = err.Error()
+		}
+	}
+	tp.bundles = nil
+
+	// 2. Public pool, best effective tip first.
+	pq := &txPriorityQueue{}
+	next := func(addr Address) {
+		q := queues[addr]
+		for len(q) > 0 && (s.included[q[0].Hash] || q[0].Nonce < s.nonces[addr]) {
+			q = q[1:] // already selected through a bundle, or superseded
+		}
+		queues[addr] = q
+		if len(q) == 0 || q[0].Nonce != s.nonces[addr] {
+			return // empty or nonce gap
+		}
+		tip, ok := effectiveTip(q[0], opts.BaseFee)
+		if !ok {
+			return
+		}
+		heap.Push(pq, &txItem{tx: q[0], pr: float64(tip)})
+	}
+	addrs := make([]Address, 0, len(queues))
+	for a := range queues {
+		addrs = append(addrs, a)
+	}
+	sort.Slice(addrs, func(i, j int) bool { return bytes.Compare(addrs[i][:], addrs[j][:]) < 0 })
+	for _, a := range addrs {
+		next(a)
+	}
+	for pq.Len() > 0 && !s.full() {
+		it := heap.Pop(pq).(*txItem)
+		if s.check(it.tx, 0, nil, s.nonces) != nil {
+			continue // gas limits: drop the sender
+		}
+		s.add(it.tx)
+		queues[it.tx.From] = queues[it.tx.From][1:]
+		next(it.tx.From)
+	}
+
+	// Remove what was selected from the pool.
+	kept := tp.queue[:0]
+	for _, tx := range tp.queue {
+		if s.included[tx.Hash] {
+			delete(tp.lookup, tx.Hash)
+			continue
+		}
+		kept = append(kept, tx)
+	}
+	tp.queue = kept
+	return &s.out, nil
+}
@@ -0,0 +1,181 @@
+package core
+
+// typed_data.go – signing of typed structured data.
+//
+// Off-chain messages such as exchange orders, governance votes and token
+// permits are signed as typed data: struct type definitions, the primary
+// type, a domain and the message, in the JSON form of EIP-712. The signed
+// digest is keccak256(0x19 0x01 ‖ domainSeparator ‖ hashStruct(message))
+// with the EIP-712 encoding, so a signature is bound to the application
+// (name, version), the chain (chainId) and the contract (verifyingContract)
+// it was made for and cannot be replayed against another. Domains must set
+// a name and a chain ID; the EIP712Domain type is derived from the fields
+// set when the document does not declare it.
+//
+// Signatures use the wallet's ed25519 keys in the transaction layout, the
+// 64-byte signature followed by the 32-byte public key, so a verifier needs
+// only the signer's address. Contracts check them with host_verify_typed,
+// after building the digest from host_domain_separator and their own
+// struct hash.
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// TypedData is a typed structured data document in the EIP-712 JSON form:
+// types, primaryType, domain and message.
+type TypedData = apitypes.TypedData
+
+// TypedDataDomain binds a signature to an application, chain and contract.
+type TypedDataDomain = apitypes.TypedDataDomain
+
+// ErrTypedDataSignature is returned for a malformed or invalid signature.
+var ErrTypedDataSignature = errors.New("typed data signature invalid")
+
+// TypedDataSignature is a signature over typed data; fields are hex.
+type TypedDataSignature struct {
+	Digest string `json:"digest"`
+	Signer string `json:"signer"`
+	Sig    string `json:"sig"` // 64-byte signature ‖ 32-byte public key
+}
+
+var typedDomainType = crypto.Keccak256Hash([]byte("EIP712Domain(string name,string version,uint256 chainId,address verifyingContract)"))
+
+// TypedDataHash returns the digest a signature over td signs.
+func TypedDataHash(td TypedData) (Hash, error) {
+	if td.Domain.Name == "" || td.Domain.ChainId == nil {
+		return Hash{}, NewError(CodeInvalidArgument, "typeddata", "domain name and chainId required")
+	}
+	if vc := td.Domain.VerifyingContract; vc != "" {
+		a, err := DecodeAddress(vc)
+		if err != nil {
+			return Hash{}, NewError(CodeInvalidArgument, "typeddata", "verifyingContract: %v", err)
+		}
+		td.Domain.VerifyingContract = common.Address(a).Hex()
+	}
+	if _, ok := td.Types["EIP712Domain"]; !ok {
+		types := make(apitypes.Types, len(td.Types)+1)
+		for k, v := range td.Types {
+			types[k] = v
+		}
+		types["EIP712Domain"] = domainFields(td.Domain)
+		td.Types = types
+	}
+	digest, _, err := apitypes.TypedDataAndHash(td)
+	if err != nil {
+		return Hash{}, NewError(CodeInvalidArgument, "typeddata", "%v", err)
+	}
+	var h Hash
+	copy(h[:], digest)
+	return h, nil
+}
+
+// domainFields declares the EIP712Domain fields d sets, in canonical order.
+func domainFields(d TypedDataDomain) []apitypes.Type {
+	var out []apitypes.Type
+	if d.Name != "" {
+		out = append(out, apitypes.Type{Name: "name", Type: "string"})
+	}
+	if d.Version != "" {
+		out = append(out, apitypes.Type{Name: "version", Type: "string"})
+	}
+	if d.ChainId != nil {
+		out = append(out, apitypes.Type{Name: "chainId", Type: "uint256"})
+	}
+	if d.VerifyingContract != "" {
+		out = append(out, apitypes.Type{Name: "verifyingContract", Type: "address"})
+	}
+	if d.Salt != "" {
+		out = append(out, apitypes.Type{Name: "salt", Type: "bytes32"})
+	}
+	return out
+}
+
+// TypedDomainSeparator returns the separator of the domain with all four
+// fields set, as contracts compute it for themselves.
+func TypedDomainSeparator(name, version string, chainID *big.Int, contract Address) Hash {
+	var addr [32]byte
+	copy(addr[12:], contract[:])
+	return Hash(crypto.Keccak256Hash(
+		typedDomainType[:],
+		crypto.Keccak256([]byte(name)),
+		crypto.Keccak256([]byte(version)),
+		common.BigToHash(chainID).Bytes(),
+		addr[:],
+	))
+}
+
+// TypedDigest combines a domain separator and a struct hash into the
+// digest that is signed.
+func TypedDigest(domainSeparator, structHash Hash) Hash {
+	return Hash(crypto.Keccak256Hash([]byte{0x19, 0x01}, domainSeparator[:], structHash[:]))
+}
+
+// SignTypedData signs td with an ed25519 key.
+func SignTypedData(priv ed25519.PrivateKey, td TypedData) (*TypedDataSignature, error) {
+	if len(priv) != ed25519.PrivateKeySize {
+		return nil, NewError(CodeInvalidArgument, "typeddata", "invalid ed25519 private key")
+	}
+	digest, err := TypedDataHash(td)
+	if err != nil {
+		return nil, err
+	}
+	pub := priv.Public().(ed25519.PublicKey)
+	sig := make([]byte, 0, ed25519.SignatureSize+ed25519.PublicKeySize)
+	sig = append(sig, ed25519.Sign(priv, digest[:])...)
+	sig = append(sig, pub...)
+	return &TypedDataSignature{
+		Digest: hex.EncodeToString(digest[:]),
+		Signer: pubKeyToAddress(pub).Hex(),
+		Sig:    hex.EncodeToString(sig),
+	}, nil
+}
+
+// SignTypedData signs td with the key at (account, index).
+func (w *HDWallet) SignTypedData(td TypedData, account, index uint32) (*TypedDataSignature, error) {
+	priv, _, err := w.PrivateKey(account, index)
+	if err != nil {
+		return nil, err
+	}
+	defer Wipe(priv)
+	s, err := SignTypedData(priv, td)
+	if err != nil {
+		return nil, err
+	}
+	if w.logger != nil {
+		w.logger.Printf("signed typed data %s by %s (account %d idx %d)", td.PrimaryType, s.Signer, account, index)
+	}
+	return s, nil
+}
+
+// RecoverTypedDigest checks sig over digest and returns the signer.
+func RecoverTypedDigest(digest Hash, sig []byte) (Address, error) {
+	if len(sig) != ed25519.SignatureSize+ed25519.PublicKeySize {
+		return AddressZero, ErrTypedDataSignature
+	}
+	pub := ed25519.PublicKey(sig[ed25519.SignatureSize:])
+	if !ed25519.Verify(pub, digest[:], sig[:ed25519.SignatureSize]) {
+		return AddressZero, ErrTypedDataSignature
+	}
+	return pubKeyToAddress(pub), nil
+}
+
+// VerifyTypedData reports whether sig is signer's signature over td.
+func VerifyTypedData(td TypedData, sig []byte, signer Address) (bool, error) {
+	digest, err := TypedDataHash(td)
+	if err != nil {
+		return false, err
+	}
+	got, err := RecoverTypedDigest(digest, sig)
+	if err != nil {
+		return false, nil
+	}
+	return got == signer, nil
+}
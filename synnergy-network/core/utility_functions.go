@@ -5,12 +5,14 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"math"
+	"math/big"
+
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 	"golang.org/x/crypto/blake2b"
 	"golang.org/x/crypto/ripemd160"
 	"golang.org/x/crypto/sha3"
-	"math/big"
 )
 
 // Short returns a shortened hex version of the hash (e.g. first 4 + last 4).
@@ -85,7 +87,7 @@ func opDIV(ctx *VMContext) error {
 // OpDIV is exported for opcode dispatch tables and simply delegates to opDIV.
 func OpDIV(ctx *VMContext) error { return opDIV(ctx) }
 
-// opSDIV implements signed division: if a == 0 push 0, else trunc(b / a) with sign.
+// opSDIV implements signed division: if a == 0 push 0, else b / a truncated toward zero.
 func opSDIV(ctx *VMContext) error {
 	a := ctx.Stack.Pop()
 	b := ctx.Stack.Pop()
@@ -95,7 +97,7 @@ func opSDIV(ctx *VMContext) error {
 	}
 	bs := toSigned(b)
 	as := toSigned(a)
-	quot := new(big.Int).Div(bs, as)
+	quot := new(big.Int).Quo(bs, as)
 	if quot.Sign() < 0 {
 		quot.Add(quot, two256)
 	}
@@ -184,7 +186,7 @@ func opEXP(ctx *VMContext) error {
 func opSIGNEXTEND(ctx *VMContext) error {
 	iBI := ctx.Stack.Pop()
 	valBI := ctx.Stack.Pop()
-	i := iBI.Uint64()
+	i := clampUint64(iBI)
 	val := new(big.Int).And(valBI, mask256)
 	if i >= 32 {
 		ctx.Stack.Push(val)
@@ -323,7 +325,7 @@ func opNOT(ctx *VMContext) error {
 func opBYTE(ctx *VMContext) error {
 	nBI := ctx.Stack.Pop()
 	valBI := ctx.Stack.Pop()
-	n := nBI.Uint64()
+	n := clampUint64(nBI)
 	if n >= 32 {
 		ctx.Stack.Push(big.NewInt(0))
 		return nil
@@ -340,7 +342,7 @@ func opBYTE(ctx *VMContext) error {
 func opSHL(ctx *VMContext) error {
 	shiftBI := ctx.Stack.Pop()
 	valBI := ctx.Stack.Pop()
-	shift := shiftBI.Uint64()
+	shift := clampUint64(shiftBI)
 	if shift >= 256 {
 		ctx.Stack.Push(big.NewInt(0))
 		return nil
@@ -355,7 +357,7 @@ func opSHL(ctx *VMContext) error {
 func opSHR(ctx *VMContext) error {
 	shiftBI := ctx.Stack.Pop()
 	valBI := ctx.Stack.Pop()
-	shift := shiftBI.Uint64()
+	shift := clampUint64(shiftBI)
 	if shift >= 256 {
 		ctx.Stack.Push(big.NewInt(0))
 		return nil
@@ -370,7 +372,7 @@ func opSHR(ctx *VMContext) error {
 func opSAR(ctx *VMContext) error {
 	shiftBI := ctx.Stack.Pop()
 	valBI := ctx.Stack.Pop()
-	shift := shiftBI.Uint64()
+	shift := clampUint64(shiftBI)
 	val := new(big.Int).And(valBI, mask256)
 	signed := toSigned(val)
 	if shift >= 256 {
@@ -390,6 +392,15 @@ func opSAR(ctx *VMContext) error {
 	return nil
 }
 
+// clampUint64 returns x as a uint64, saturating at MaxUint64 so that
+// operands of 2^64 and above still count as out of range.
+func clampUint64(x *big.Int) uint64 {
+	if !x.IsUint64() {
+		return math.MaxUint64
+	}
+	return x.Uint64()
+}
+
 // toSigned converts an unsigned 256-bit big.Int to its signed two's-complement equivalent.
 func toSigned(x *big.Int) *big.Int {
 	if x.Cmp(two255) >= 0 {
@@ -412,15 +423,20 @@ func opECRECOVER(ctx *VMContext) error {
 	vBI := ctx.Stack.Pop()
 	hBI := ctx.Stack.Pop()
 
+	// As with the Ethereum precompile, v must be exactly 27 or 28 and r, s
+	// must lie in [1, secp256k1n).
+	if !vBI.IsUint64() || (vBI.Uint64() != 27 && vBI.Uint64() != 28) {
+		ctx.Stack.Push(big.NewInt(0))
+		return nil
+	}
+	v := byte(vBI.Uint64() - 27)
+	if !crypto.ValidateSignatureValues(v, rBI, sBI, false) {
+		ctx.Stack.Push(big.NewInt(0))
+		return nil
+	}
 	hash := common.LeftPadBytes(hBI.Bytes(), 32)
 	r := common.LeftPadBytes(rBI.Bytes(), 32)
 	s := common.LeftPadBytes(sBI.Bytes(), 32)
-	v := byte(vBI.Uint64())
-
-	// Normalize v to {0,1}
-	if v >= 27 {
-		v -= 27
-	}
 	sig := append(append(r, s...), v)
 
 	pubkey, err := crypto.SigToPub(hash, sig)
@@ -461,6 +477,9 @@ func opEXTCODECOPY(ctx *VMContext) error {
 	addr := Address(bytesToAddress(addrBI.Bytes())) // convert
 
 	code := ctx.State.GetCode(addr)
+	if err := checkMemRange(memOffset, length); err != nil {
+		return err
+	}
 	data := make([]byte, length)
 	for i := uint64(0); i < length; i++ {
 		if idx := codeOffset + i; idx < uint64(len(code)) {
@@ -493,6 +512,9 @@ func opRETURNDATACOPY(ctx *VMContext) error {
 	memOffset := ctx.Stack.Pop().Uint64()
 
 	ret := ctx.LastReturnData
+	if err := checkMemRange(memOffset, length); err != nil {
+		return err
+	}
 	data := make([]byte, length)
 	for i := uint64(0); i < length; i++ {
 		if idx := dataOffset + i; idx < uint64(len(ret)) {
@@ -554,6 +576,9 @@ func opCALLDATACOPY(ctx *VMContext) error {
 	length := ctx.Stack.Pop().Uint64()
 	dataOffset := ctx.Stack.Pop().Uint64()
 	memOffset := ctx.Stack.Pop().Uint64()
+	if err := checkMemRange(memOffset, length); err != nil {
+		return err
+	}
 	data := make([]byte, length)
 	for i := uint64(0); i < length; i++ {
 		if idx := dataOffset + i; idx < uint64(len(ctx.Args)) {
@@ -575,6 +600,9 @@ func opCODECOPY(ctx *VMContext) error {
 	length := ctx.Stack.Pop().Uint64()
 	codeOffset := ctx.Stack.Pop().Uint64()
 	memOffset := ctx.Stack.Pop().Uint64()
+	if err := checkMemRange(memOffset, length); err != nil {
+		return err
+	}
 	data := make([]byte, length)
 	for i := uint64(0); i < length; i++ {
 		if idx := codeOffset + i; idx < uint64(len(ctx.Code)) {
@@ -654,9 +682,14 @@ func (e *returnError) ReturnData() []byte { return e.Data }
 // revertError implements a revert with data payload.
 type revertError struct{ Data []byte }
 
-func (e *revertError) Error() string      { return "vm: revert" }
+func (e *revertError) Error() string {
+	return "vm: execution reverted: " + e.Reason().Message
+}
 func (e *revertError) ReturnData() []byte { return e.Data }
 
+// Reason decodes the payload, see revert_reason.go.
+func (e *revertError) Reason() *RevertReason { return DecodeRevert(e.Data) }
+
 // opSHA256 computes SHA-256 over [offset, offset+size) in memory.
 func opSHA256(ctx *VMContext) error {
 	size := ctx.Stack.Pop().Uint64()
@@ -799,6 +832,31 @@ func opCREATE(ctx *VMContext) error {
 	}
 	return nil
 }
+
+// opCREATE2 deploys at Create2Address(contract, salt, code). It pushes 0
+// when the state does not support salted deployment or the address is
+// taken.
+func opCREATE2(ctx *VMContext) error {
+	value := ctx.Stack.Pop()
+	size := ctx.Stack.Pop().Uint64()
+	offset := ctx.Stack.Pop().Uint64()
+	gas := ctx.Stack.Pop().Uint64()
+	var salt Hash
+	ctx.Stack.Pop().FillBytes(salt[:])
+	code := ctx.Memory.Read(offset, size)
+	sc, ok := ctx.State.(saltedCreator)
+	if !ok {
+		ctx.Stack.Push(big.NewInt(0))
+		return nil
+	}
+	addr, _, ok, _ := sc.CreateContract2(ctx.Contract, code, salt, value, gas)
+	if ok {
+		ctx.Stack.Push(new(big.Int).SetBytes(addr.Bytes()))
+	} else {
+		ctx.Stack.Push(big.NewInt(0))
+	}
+	return nil
+}
 func opCALL(ctx *VMContext) error     { return call(ctx, false) }
 func opCALLCODE(ctx *VMContext) error { return call(ctx, true) }
 
@@ -880,6 +938,9 @@ func opSTOP(ctx *VMContext) error { return ErrStop }
 func opSELFDESTRUCT(ctx *VMContext) error {
 	ben := BytesToAddress(ctx.Stack.Pop().Bytes())
 	ctx.State.SelfDestruct(ctx.Contract, ben)
+	if ctx.GasMeter != nil {
+		ctx.GasMeter.RefundSelfDestruct(ctx.Contract)
+	}
 	return ErrStop
 }
 
@@ -900,7 +961,18 @@ var (
 	TokenLedger = make(map[TokenID]*BaseToken)
 )
 
+// Transfer moves an asset between accounts. Transfers to AddressZero are
+// burns and take the Burn path.
 func Transfer(ctx *Context, asset AssetRef, from, to Address, amount uint64) error {
+	if err := CheckSanctions(from, to); err != nil {
+		return err
+	}
+	if from == AddressZero {
+		return ErrZeroAddress
+	}
+	if to == AddressZero {
+		return Burn(ctx, asset, from, amount)
+	}
 	switch asset.Kind {
 	case AssetCoin:
 		return ctx.State.Transfer(from, to, amount) // ✅ fixed
@@ -0,0 +1,279 @@
+package core
+
+// utxo_index.go – secondary indexes over Ledger.UTXO.
+//
+// The UTXO map stays the source of truth (it is what snapshots persist); the
+// index is rebuilt from it on boot and maintained incrementally by
+// applyBlock:
+//
+//	byOwner   owner hex → sorted UTXO keys, backing cursor pagination
+//	balances  LRU of per-owner sums, invalidated when an owner's set changes
+//	journal   per-block list of created and spent outputs, newest last, so
+//	          RewindUTXO can undo recent blocks during a reorg
+//
+// An output's owner is its PubKeyHash, falling back to its Address when no
+// hash is set.
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+)
+
+const (
+	// utxoJournalDepth is how many recent blocks RewindUTXO can undo.
+	utxoJournalDepth = 256
+	// utxoBalanceCacheSize bounds the number of cached owner balances.
+	utxoBalanceCacheSize = 65_536
+	// DefaultUTXOPageSize is used when UTXOPage is called without a limit.
+	DefaultUTXOPageSize = 100
+	// MaxUTXOPageSize caps a single page.
+	MaxUTXOPageSize = 1_000
+)
+
+// ErrUTXOJournalTooShort is returned when a rewind reaches past the journal.
+var ErrUTXOJournalTooShort = errors.New("utxo journal does not reach requested height")
+
+// utxoOp is one journaled change; spent ops carry the output they removed.
+type utxoOp struct {
+	key   string
+	utxo  UTXO
+	spent bool
+}
+
+// utxoUndo holds the changes one block made to the UTXO set, in order.
+type utxoUndo struct {
+	height uint64
+	ops    []utxoOp
+}
+
+type utxoIndex struct {
+	byOwner  map[string][]string
+	balances *lru.Cache[string, uint64]
+	journal  []*utxoUndo
+	// floor is the lowest height RewindUTXO can reach once blocks have
+	// been applied outside the journal (before a rebuild or trimmed off).
+	floor    uint64
+	hasFloor bool
+}
+
+func newUTXOIndex() *utxoIndex {
+	cache, _ := lru.New[string, uint64](utxoBalanceCacheSize)
+	return &utxoIndex{byOwner: make(map[string][]string), balances: cache}
+}
+
+// buildUTXOIndex indexes an existing UTXO set, e.g. one restored from a
+// snapshot. The journal starts empty.
+func buildUTXOIndex(set map[string]UTXO) *utxoIndex {
+	idx := newUTXOIndex()
+	for key, u := range set {
+		owner := utxoOwner(u.Output)
+		idx.byOwner[owner] = append(idx.byOwner[owner], key)
+	}
+	for _, keys := range idx.byOwner {
+		sort.Strings(keys)
+	}
+	return idx
+}
+
+func utxoKey(txID Hash, index uint32) string {
+	return fmt.Sprintf("%x:%d", txID, index)
+}
+
+func utxoOwner(out TxOutput) string {
+	if len(out.PubKeyHash) > 0 {
+		return hex.EncodeToString(out.PubKeyHash)
+	}
+	return hex.EncodeToString(out.Address[:])
+}
+
+func (idx *utxoIndex) insert(key string, u UTXO) {
+	owner := utxoOwner(u.Output)
+	keys := idx.byOwner[owner]
+	i := sort.SearchStrings(keys, key)
+	if i < len(keys) && keys[i] == key {
+		return
+	}
+	keys = append(keys, "")
+	copy(keys[i+1:], keys[i:])
+	keys[i] = key
+	idx.byOwner[owner] = keys
+	idx.balances.Remove(owner)
+}
+
+func (idx *utxoIndex) delete(key string, u UTXO) {
+	owner := utxoOwner(u.Output)
+	keys := idx.byOwner[owner]
+	i := sort.SearchStrings(keys, key)
+	if i == len(keys) || keys[i] != key {
+		return
+	}
+	if len(keys) == 1 {
+		delete(idx.byOwner, owner)
+	} else {
+		idx.byOwner[owner] = append(keys[:i], keys[i+1:]...)
+	}
+	idx.balances.Remove(owner)
+}
+
+func (idx *utxoIndex) record(undo *utxoUndo) {
+	if len(undo.ops) == 0 {
+		return
+	}
+	idx.journal = append(idx.journal, undo)
+	if n := len(idx.journal) - utxoJournalDepth; n > 0 {
+		idx.setFloor(idx.journal[n-1].height)
+		idx.journal = append(idx.journal[:0:0], idx.journal[n:]...)
+	}
+}
+
+func (idx *utxoIndex) setFloor(height uint64) {
+	if !idx.hasFloor || height > idx.floor {
+		idx.floor, idx.hasFloor = height, true
+	}
+}
+
+// utxoIndex returns the ledger's index, building it from the UTXO set on
+// first use. Callers must hold the write lock.
+func (l *Ledger) utxoIndex() *utxoIndex {
+	if l.utxoIdx == nil {
+		l.utxoIdx = buildUTXOIndex(l.UTXO)
+		if n := len(l.Blocks); n > 0 {
+			l.utxoIdx.setFloor(l.Blocks[n-1].Header.Height)
+		}
+	}
+	return l.utxoIdx
+}
+
+// addUTXO inserts an output and journals the creation.
+func (l *Ledger) addUTXO(key string, u UTXO, undo *utxoUndo) {
+	l.UTXO[key] = u
+	l.utxoIndex().insert(key, u)
+	undo.ops = append(undo.ops, utxoOp{key: key, utxo: u})
+}
+
+// spendUTXO removes an output and journals it so a rewind can restore it.
+func (l *Ledger) spendUTXO(key string, undo *utxoUndo) {
+	u, ok := l.UTXO[key]
+	if !ok {
+		return
+	}
+	delete(l.UTXO, key)
+	l.utxoIndex().delete(key, u)
+	undo.ops = append(undo.ops, utxoOp{key: key, utxo: u, spent: true})
+}
+
+// RewindUTXO undoes the UTXO changes of every journaled block above height,
+// restoring spent outputs and removing created ones. Only the UTXO set and
+// its indexes are rewound; reorg handlers re-apply the new branch afterwards.
+// ErrUTXOJournalTooShort is returned, with nothing changed, when the
+// journal no longer covers the blocks above height.
+func (l *Ledger) RewindUTXO(height uint64) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	idx := l.utxoIndex()
+	if idx.hasFloor && height < idx.floor {
+		return fmt.Errorf("%w: rewind to %d, journal starts above %d", ErrUTXOJournalTooShort, height, idx.floor)
+	}
+
+	for len(idx.journal) > 0 {
+		undo := idx.journal[len(idx.journal)-1]
+		if undo.height <= height {
+			break
+		}
+		for i := len(undo.ops) - 1; i >= 0; i-- {
+			op := undo.ops[i]
+			if op.spent {
+				l.UTXO[op.key] = op.utxo
+				idx.insert(op.key, op.utxo)
+			} else {
+				delete(l.UTXO, op.key)
+				idx.delete(op.key, op.utxo)
+			}
+		}
+		idx.journal = idx.journal[:len(idx.journal)-1]
+	}
+	return nil
+}
+
+// UTXOPage returns up to limit UTXOs owned by address in key order,
+// starting after cursor, plus the cursor for the next page. The returned
+// cursor is empty once the last page has been served. A limit of zero or
+// less selects DefaultUTXOPageSize; larger limits are capped at
+// MaxUTXOPageSize.
+func (l *Ledger) UTXOPage(address []byte, cursor string, limit int) ([]UTXO, string) {
+	if limit <= 0 {
+		limit = DefaultUTXOPageSize
+	}
+	if limit > MaxUTXOPageSize {
+		limit = MaxUTXOPageSize
+	}
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	keys := l.ownerKeys(hex.EncodeToString(address))
+	i := 0
+	if cursor != "" {
+		i = sort.SearchStrings(keys, cursor)
+		if i < len(keys) && keys[i] == cursor {
+			i++
+		}
+	}
+	end := i + limit
+	if end > len(keys) {
+		end = len(keys)
+	}
+	page := make([]UTXO, 0, end-i)
+	for _, key := range keys[i:end] {
+		page = append(page, l.UTXO[key])
+	}
+	next := ""
+	if end < len(keys) {
+		next = keys[end-1]
+	}
+	return page, next
+}
+
+// UTXOBalance returns the total amount held in UTXOs owned by address.
+// Results are cached until the owner's outputs change.
+func (l *Ledger) UTXOBalance(address []byte) uint64 {
+	owner := hex.EncodeToString(address)
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if l.utxoIdx != nil {
+		if bal, ok := l.utxoIdx.balances.Get(owner); ok {
+			return bal
+		}
+	}
+	var bal uint64
+	for _, key := range l.ownerKeys(owner) {
+		bal += l.UTXO[key].Output.Amount
+	}
+	if l.utxoIdx != nil {
+		// added under the read lock so a concurrent block cannot
+		// invalidate the entry before it is stored
+		l.utxoIdx.balances.Add(owner, bal)
+	}
+	return bal
+}
+
+// ownerKeys returns the sorted UTXO keys of owner. Ledgers assembled
+// without an index (e.g. struct literals in tools) fall back to a scan.
+func (l *Ledger) ownerKeys(owner string) []string {
+	if l.utxoIdx != nil {
+		return l.utxoIdx.byOwner[owner]
+	}
+	var keys []string
+	for key, u := range l.UTXO {
+		if utxoOwner(u.Output) == owner {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
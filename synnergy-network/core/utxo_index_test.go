@@ -0,0 +1,76 @@
+package core
+
+import (
+	"testing"
+)
+
+func utxoTestTx(nonce uint64, ins []TxInput, outs ...TxOutput) *Transaction {
+	tx := &Transaction{Nonce: nonce, Inputs: ins, Outputs: outs}
+	tx.HashTx()
+	return tx
+}
+
+func TestUTXOIndexPaginationBalanceAndRewind(t *testing.T) {
+	cfg, _ := tmpLedgerConfig(t, nil)
+	led, err := NewLedger(cfg)
+	if err != nil {
+		t.Fatalf("init err: %v", err)
+	}
+	alice, bob := []byte{0xa1}, []byte{0xb0}
+
+	fund := utxoTestTx(0, nil,
+		TxOutput{PubKeyHash: alice, Amount: 10},
+		TxOutput{PubKeyHash: alice, Amount: 20},
+		TxOutput{PubKeyHash: alice, Amount: 30},
+		TxOutput{PubKeyHash: bob, Amount: 5},
+	)
+	if err := led.applyBlock(&Block{Header: BlockHeader{Height: 0}, Transactions: []*Transaction{fund}}, false); err != nil {
+		t.Fatalf("block 0: %v", err)
+	}
+
+	// page through alice's outputs two at a time
+	var seen []UTXO
+	cursor := ""
+	for pages := 0; ; pages++ {
+		page, next := led.UTXOPage(alice, cursor, 2)
+		seen = append(seen, page...)
+		if next == "" {
+			if pages != 1 {
+				t.Fatalf("pages=%d want 2", pages+1)
+			}
+			break
+		}
+		cursor = next
+	}
+	if len(seen) != 3 || len(led.GetUTXO(alice)) != 3 {
+		t.Fatalf("alice utxos=%d want 3", len(seen))
+	}
+	if got := led.UTXOBalance(alice); got != 60 {
+		t.Fatalf("alice balance=%d want 60", got)
+	}
+
+	// spend alice's first output to bob; the cached balance must refresh
+	spend := utxoTestTx(1, []TxInput{{TxID: fund.ID(), Index: 0}}, TxOutput{PubKeyHash: bob, Amount: 10})
+	if err := led.applyBlock(&Block{Header: BlockHeader{Height: 1}, Transactions: []*Transaction{spend}}, false); err != nil {
+		t.Fatalf("block 1: %v", err)
+	}
+	if got := led.UTXOBalance(alice); got != 50 {
+		t.Fatalf("alice balance after spend=%d want 50", got)
+	}
+	if got := led.UTXOBalance(bob); got != 15 {
+		t.Fatalf("bob balance after spend=%d want 15", got)
+	}
+
+	if err := led.RewindUTXO(0); err != nil {
+		t.Fatalf("rewind: %v", err)
+	}
+	if got := led.UTXOBalance(alice); got != 60 {
+		t.Fatalf("alice balance after rewind=%d want 60", got)
+	}
+	if got := led.UTXOBalance(bob); got != 5 {
+		t.Fatalf("bob balance after rewind=%d want 5", got)
+	}
+	if _, ok := led.UTXO[utxoKey(fund.ID(), 0)]; !ok {
+		t.Fatal("spent output not restored by rewind")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"sync"
+	"time"
 
 	"github.com/sirupsen/logrus"
 )
@@ -25,6 +26,8 @@ type ValidatorNode struct {
 	usePoH bool
 	usePoS bool
 	usePoW bool
+
+	invariants InvariantVerifierConfig
 }
 
 // ValidatorNodeConfig aggregates the required configuration sections.
@@ -58,6 +61,8 @@ func NewValidatorNode(cfg ValidatorNodeConfig) (*ValidatorNode, error) {
 		return nil, err
 	}
 
+	cons.SetValidatorManager(mgr)
+
 	base := NewBaseNode(&NodeAdapter{n})
 	vn := &ValidatorNode{
 		BaseNode:  base,
@@ -80,6 +85,15 @@ func (vn *ValidatorNode) Start() {
 	defer vn.mu.Unlock()
 	go vn.ListenAndServe()
 	vn.cons.Start(vn.ctx)
+	StartMarketSettlement(vn.ctx, time.Minute)
+	StartRentDistribution(vn.ctx, time.Minute)
+	StartResourceLeaseSettlement(vn.ctx, 5*time.Second)
+	StartWorkflowRuntime(vn.ctx, 5*time.Second)
+	StartLPAPRSampler(vn.ctx, time.Hour)
+	if err := StartEventIndexer(vn.ctx, Events()); err != nil {
+		logrus.Warnf("event indexer not started: %v", err)
+	}
+	StartInvariantVerifier(vn.ctx, vn.invariants, func(InvariantReport) { vn.cancel() })
 }
 
 // Stop gracefully shuts down the node services.
@@ -99,6 +113,15 @@ func (vn *ValidatorNode) EnablePoS(b bool) { vn.mu.Lock(); vn.usePoS = b; vn.mu.
 // EnablePoW toggles the Proof of Work component.
 func (vn *ValidatorNode) EnablePoW(b bool) { vn.mu.Lock(); vn.usePoW = b; vn.mu.Unlock() }
 
+// EnableInvariantChecks runs the registered invariants every `every` blocks
+// once the node starts. With halt set a violation stops consensus and the
+// background services.
+func (vn *ValidatorNode) EnableInvariantChecks(every uint64, halt bool) {
+	vn.mu.Lock()
+	vn.invariants = InvariantVerifierConfig{Every: every, Halt: halt}
+	vn.mu.Unlock()
+}
+
 // ValidateTx verifies a transaction using the consensus tx pool.
 func (vn *ValidatorNode) ValidateTx(txBytes []byte) error {
 	tx, err := DecodeTransaction(txBytes)
@@ -114,10 +137,10 @@ func (vn *ValidatorNode) ProposeBlock() error {
 	return err
 }
 
-// VoteBlock records a PoS vote for the given block header hash.
+// VoteBlock records a PoS vote for the given block header hash and gossips
+// it to peers in the next vote batch.
 func (vn *ValidatorNode) VoteBlock(hash []byte, sig []byte) error {
-	vn.led.RecordPoSVote(hash, sig)
-	return nil
+	return vn.cons.GossipPoSVote(hash, sig)
 }
 
 // DecodeTransaction converts JSON encoded bytes into a Transaction structure.
@@ -166,3 +189,6 @@ func (vn *ValidatorNode) List(activeOnly bool) ([]ValidatorInfo, error) {
 
 // IsValidator checks whether the address is an active validator.
 func (vn *ValidatorNode) IsValidator(addr Address) bool { return vn.mgr.IsValidator(addr) }
+
+// Schedulable lists validators eligible for block production.
+func (vn *ValidatorNode) Schedulable() ([]ValidatorInfo, error) { return vn.mgr.Schedulable() }
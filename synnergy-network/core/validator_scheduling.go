@@ -0,0 +1,283 @@
+package core
+
+// validator_scheduling.go – energy-efficiency aware sub-block proposer selection.
+//
+// Each schedulable validator is weighted by its stake. Validators whose
+// transactions-per-kWh score is above the network average receive a boost
+// proportional to how far above the average they are, capped at
+// MaxBoostBps. Validators whose last ThrottleStrikes efficiency records were
+// all below ThrottleBps of the network average are treated as chronic
+// under-performers: EfficiencyEngine.ShouldThrottle reports them and
+// ValidatorManager.Schedulable drops them, just like heavy emitters.
+//
+// The policy is stored on the ledger under "effpolicy:current" and is only
+// changed through governance parameters (see UpdateParam):
+//   eff_max_boost_bps, eff_throttle_bps, eff_throttle_strikes
+//
+// The proposer for a sub-block height is drawn from the weighted set using
+// the randomness beacon, so every node computes the same proposer.
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+const bpsDenom = 10_000
+
+var effPolicyKey = []byte("effpolicy:current")
+
+// ErrNotProposer is returned when the local validator is not the selected
+// proposer for a sub-block height.
+var ErrNotProposer = errors.New("not the scheduled proposer")
+
+// EfficiencyPolicy holds the governed scheduling parameters.
+type EfficiencyPolicy struct {
+	// MaxBoostBps caps the extra weight granted to efficient validators.
+	MaxBoostBps uint64 `json:"max_boost_bps"`
+	// ThrottleBps is the fraction of the network average below which a
+	// record counts as a strike.
+	ThrottleBps uint64 `json:"throttle_bps"`
+	// ThrottleStrikes is the number of consecutive strikes before a
+	// validator is throttled. Zero disables throttling.
+	ThrottleStrikes int `json:"throttle_strikes"`
+}
+
+// DefaultEfficiencyPolicy grants at most a 10% boost and throttles
+// validators whose last three records were below half the network average.
+var DefaultEfficiencyPolicy = EfficiencyPolicy{MaxBoostBps: 1_000, ThrottleBps: 5_000, ThrottleStrikes: 3}
+
+// Validate checks the policy bounds. Boosts are capped at 50% to keep stake
+// the dominant factor.
+func (p EfficiencyPolicy) Validate() error {
+	if p.MaxBoostBps > bpsDenom/2 {
+		return fmt.Errorf("max boost %d bps exceeds %d", p.MaxBoostBps, bpsDenom/2)
+	}
+	if p.ThrottleBps >= bpsDenom {
+		return fmt.Errorf("throttle threshold %d bps must be below %d", p.ThrottleBps, bpsDenom)
+	}
+	if p.ThrottleStrikes < 0 {
+		return errors.New("throttle strikes must be >= 0")
+	}
+	return nil
+}
+
+// ProposerWeight is the scheduling weight of one validator.
+type ProposerWeight struct {
+	Addr          Address `json:"addr"`
+	Stake         uint64  `json:"stake"`
+	Efficiency    float64 `json:"efficiency"`
+	MultiplierBps uint64  `json:"multiplier_bps"`
+	Weight        uint64  `json:"weight"`
+}
+
+// Policy returns the active scheduling policy.
+func (e *EfficiencyEngine) Policy() EfficiencyPolicy {
+	raw, err := e.led.GetState(effPolicyKey)
+	if err != nil || len(raw) == 0 {
+		return DefaultEfficiencyPolicy
+	}
+	var p EfficiencyPolicy
+	if err := json.Unmarshal(raw, &p); err != nil {
+		return DefaultEfficiencyPolicy
+	}
+	return p
+}
+
+// SetPolicy stores a new scheduling policy. It is invoked by governance.
+func (e *EfficiencyEngine) SetPolicy(p EfficiencyPolicy) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	raw, _ := json.Marshal(p)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.led.SetState(effPolicyKey, raw)
+}
+
+// MultiplierBps returns the weight multiplier of v in basis points. Validators
+// at or below the network average, or without records, get bpsDenom.
+func (e *EfficiencyEngine) MultiplierBps(v Address) (uint64, error) {
+	avg, err := e.NetworkAverage()
+	if err != nil || avg == 0 {
+		return bpsDenom, err
+	}
+	score, err := e.EfficiencyOf(v)
+	if err != nil || score <= avg {
+		return bpsDenom, err
+	}
+	boost := uint64((score/avg - 1) * bpsDenom)
+	if ceil := e.Policy().MaxBoostBps; boost > ceil {
+		boost = ceil
+	}
+	return bpsDenom + boost, nil
+}
+
+// ShouldThrottle reports whether v is a chronic under-performer: each of its
+// last ThrottleStrikes records scored below ThrottleBps of the network
+// average.
+func (e *EfficiencyEngine) ShouldThrottle(v Address) bool {
+	p := e.Policy()
+	if p.ThrottleStrikes == 0 {
+		return false
+	}
+	avg, err := e.NetworkAverage()
+	if err != nil || avg == 0 {
+		return false
+	}
+	var recs []EfficiencyRecord
+	iter := e.led.PrefixIterator([]byte("eff:"))
+	for iter.Next() {
+		var rec EfficiencyRecord
+		if err := json.Unmarshal(iter.Value(), &rec); err != nil {
+			continue
+		}
+		if rec.Validator == v && rec.EnergyKWh > 0 {
+			recs = append(recs, rec)
+		}
+	}
+	if len(recs) < p.ThrottleStrikes {
+		return false
+	}
+	sort.Slice(recs, func(i, j int) bool { return recs[i].Timestamp > recs[j].Timestamp })
+	limit := avg * float64(p.ThrottleBps) / bpsDenom
+	for _, rec := range recs[:p.ThrottleStrikes] {
+		if float64(rec.TxCount)/rec.EnergyKWh >= limit {
+			return false
+		}
+	}
+	return true
+}
+
+// ProposerWeights returns the weights of all schedulable validators ordered
+// by address. Without an efficiency engine the weight equals the stake.
+func (vm *ValidatorManager) ProposerWeights() ([]ProposerWeight, error) {
+	list, err := vm.Schedulable()
+	if err != nil {
+		return nil, err
+	}
+	eff := EnergyEff()
+	out := make([]ProposerWeight, 0, len(list))
+	for _, v := range list {
+		w := ProposerWeight{Addr: v.Addr, Stake: v.Stake, MultiplierBps: bpsDenom}
+		if eff != nil {
+			if w.Efficiency, err = eff.EfficiencyOf(v.Addr); err != nil {
+				return nil, err
+			}
+			if w.MultiplierBps, err = eff.MultiplierBps(v.Addr); err != nil {
+				return nil, err
+			}
+		}
+		w.Weight = v.Stake / bpsDenom * w.MultiplierBps
+		w.Weight += v.Stake % bpsDenom * w.MultiplierBps / bpsDenom
+		out = append(out, w)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Addr.Hex() < out[j].Addr.Hex() })
+	return out, nil
+}
+
+// ProposerFor selects the sub-block proposer for height from the weighted
+// validator set using seed.
+func (vm *ValidatorManager) ProposerFor(height uint64, seed Hash) (Address, error) {
+	weights, err := vm.ProposerWeights()
+	if err != nil {
+		return AddressZero, err
+	}
+	return SelectProposer(height, seed, weights)
+}
+
+// SelectProposer draws one validator with probability proportional to its
+// weight. weights must be in a canonical order.
+func SelectProposer(height uint64, seed Hash, weights []ProposerWeight) (Address, error) {
+	var total uint64
+	for _, w := range weights {
+		total += w.Weight
+	}
+	if total == 0 {
+		return AddressZero, errors.New("no schedulable validators")
+	}
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], height)
+	h := sha256.Sum256(append(seed[:], buf[:]...))
+	target := binary.BigEndian.Uint64(h[:8]) % total
+	for _, w := range weights {
+		if target < w.Weight {
+			return w.Addr, nil
+		}
+		target -= w.Weight
+	}
+	return weights[len(weights)-1].Addr, nil
+}
+
+// SetValidatorManager enables proposer scheduling: ProposeSubBlock then only
+// proposes when the local validator is selected for the next height.
+func (sc *SynnergyConsensus) SetValidatorManager(vm *ValidatorManager) {
+	sc.mu.Lock()
+	sc.validators = vm
+	sc.mu.Unlock()
+}
+
+// checkProposer returns ErrNotProposer unless the local validator is the
+// scheduled proposer of the next sub-block.
+func (sc *SynnergyConsensus) checkProposer() error {
+	sc.mu.Lock()
+	vm, height := sc.validators, sc.nextSubHeight
+	sc.mu.Unlock()
+	if vm == nil {
+		return nil
+	}
+	var seed Hash
+	if out, err := NewLedgerBeacon(sc.ledger).Latest(); err == nil {
+		seed = out.Value
+	}
+	want, err := vm.ProposerFor(height, seed)
+	if err != nil {
+		return err
+	}
+	auth, ok := sc.auth.(authorityAdapter)
+	if !ok {
+		return errors.New("consensus has no authority adapter")
+	}
+	self, err := AddressFromPubKey(auth.ValidatorPubKey("pos"))
+	if err != nil {
+		return err
+	}
+	if self != want {
+		return ErrNotProposer
+	}
+	return nil
+}
+
+// updateEfficiencyParam applies one governed scheduling parameter.
+func updateEfficiencyParam(key, value string) error {
+	e := EnergyEff()
+	if e == nil {
+		return errors.New("energy efficiency engine not initialised")
+	}
+	p := e.Policy()
+	switch key {
+	case "eff_max_boost_bps", "eff_throttle_bps":
+		v, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid uint: %w", err)
+		}
+		if key == "eff_max_boost_bps" {
+			p.MaxBoostBps = v
+		} else {
+			p.ThrottleBps = v
+		}
+	case "eff_throttle_strikes":
+		v, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid int: %w", err)
+		}
+		p.ThrottleStrikes = v
+	default:
+		return fmt.Errorf("unknown param: %s", key)
+	}
+	return e.SetPolicy(p)
+}
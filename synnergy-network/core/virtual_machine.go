@@ -35,6 +35,7 @@ const (
 	LOAD
 	LOG
 	RET
+	REVERT
 )
 
 //---------------------------------------------------------------------
@@ -359,6 +360,12 @@ type LinearMemory struct {
 }
 
 func (m *LinearMemory) Read(offset, size uint64) []byte {
+	if err := checkMemRange(offset, size); err != nil {
+		panic(err)
+	}
+	if size == 0 {
+		return nil // zero-size accesses do not expand memory
+	}
 	end := offset + size
 	if end > uint64(len(m.data)) {
 		// Extend with zeroes
@@ -370,6 +377,12 @@ func (m *LinearMemory) Read(offset, size uint64) []byte {
 }
 
 func (m *LinearMemory) Write(offset uint64, data []byte) {
+	if err := checkMemRange(offset, uint64(len(data))); err != nil {
+		panic(err)
+	}
+	if len(data) == 0 {
+		return
+	}
 	end := offset + uint64(len(data))
 	if end > uint64(len(m.data)) {
 		newData := make([]byte, end)
@@ -384,10 +397,27 @@ func (m *LinearMemory) Len() int {
 }
 
 func (m *memState) Call(from, to Address, input []byte, value *big.Int, gas uint64) ([]byte, error) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+	return m.call(from, to, input, value, gas, nil)
+}
 
+// call executes the contract at to, reporting to tracer when it is set.
+func (m *memState) call(from, to Address, input []byte, value *big.Int, gas uint64, tracer VMTracer) ([]byte, error) {
+	receipt, err := m.execute(from, to, input, value, gas, tracer)
+	if err != nil {
+		return nil, err
+	}
+	return receipt.ReturnData, nil
+}
+
+// execute is call returning the whole receipt. A fault is reported both as
+// the error and, when the VM got to run, in a failed receipt charging the
+// gas consumed up to the fault.
+func (m *memState) execute(from, to Address, input []byte, value *big.Int, gas uint64, tracer VMTracer) (*Receipt, error) {
+	// Only the code lookup is locked: the VM reads and writes storage
+	// through the state's own locking methods.
+	m.mu.RLock()
 	code := m.contracts[to]
+	m.mu.RUnlock()
 	if len(code) == 0 {
 		return nil, fmt.Errorf("contract not found at %x", to)
 	}
@@ -402,6 +432,7 @@ func (m *memState) Call(from, to Address, input []byte, value *big.Int, gas uint
 		State:    wrapper,
 		Memory:   NewMemory(),
 		GasMeter: NewGasMeter(gas),
+		Tracer:   tracer,
 	}
 
 	vmType := SelectVM(code)
@@ -419,12 +450,18 @@ func (m *memState) Call(from, to Address, input []byte, value *big.Int, gas uint
 		return nil, fmt.Errorf("unknown VM type selected")
 	}
 
-	receipt, err := vm.Execute(code, ctx)
+	if tracer != nil {
+		tracer.CaptureStart(from, to, input, gas)
+	}
+	receipt, err := vm.Execute(code, ctx) // reports the end to tracer
 	if err != nil {
-		return nil, fmt.Errorf("%s VM execution failed: %w", vmType, err)
+		if receipt != nil && !receipt.Status && receipt.GasUsed == 0 {
+			receipt.GasUsed = gas - ctx.GasMeter.Remaining()
+		}
+		return receipt, fmt.Errorf("%s VM execution failed: %w", vmType, err)
 	}
 
-	return receipt.ReturnData, nil
+	return receipt, nil
 }
 
 type memStateWrapper struct {
@@ -454,11 +491,18 @@ func (m *memState) CreateContract(caller Address, code []byte, value *big.Int, g
 	addrBytes := crypto.Keccak256(rlp)
 	var contractAddr Address
 	copy(contractAddr[:], addrBytes[:20])
+	m.nonces[caller]++
+
+	return m.deployAt(caller, contractAddr, code, gas)
+}
 
+// deployAt stores code at contractAddr and runs it as the constructor; the
+// code returned by the constructor becomes the contract's runtime code.
+// Callers hold m.mu.
+func (m *memState) deployAt(caller, contractAddr Address, code []byte, gas uint64) (Address, []byte, bool, error) {
 	m.contracts[contractAddr] = code
 	codeHash := sha256.Sum256(code)
 	m.codeHashes[contractAddr] = codeHash
-	m.nonces[caller]++
 
 	commonCaller := common.BytesToAddress(caller[:])
 	txHash := sha256.Sum256(append(caller[:], code...))
@@ -605,8 +649,26 @@ func (it *memIterator) Error() error {
 	return it.err
 }
 
+// Snapshot runs fn with the state locked and restores it if fn fails. fn
+// must not call back into m; callers that need to, such as a multicall
+// executing contracts, use snapshotState.
 func (m *memState) Snapshot(fn func() error) error {
+	return m.snapshotView(func(*memState) error { return fn() })
+}
+
+// snapshotState runs fn against a view of the state as a multicallState.
+func (m *memState) snapshotState(fn func(multicallState) error) error {
+	return m.snapshotView(func(v *memState) error { return fn(v) })
+}
+
+// snapshotView runs fn against a view sharing m's maps while m.mu stays
+// held, so other goroutines see the state from before fn or after it and
+// never in between. The view has a lock of its own, letting fn call back
+// into it. The state is restored if fn fails and takes the view's
+// otherwise.
+func (m *memState) snapshotView(fn func(*memState) error) error {
 	m.mu.Lock()
+	defer m.mu.Unlock()
 	origData := make(map[string][]byte, len(m.data))
 	for k, v := range m.data {
 		origData[k] = append([]byte(nil), v...)
@@ -640,8 +702,17 @@ func (m *memState) Snapshot(fn func() error) error {
 		origTokens[id] = t
 	}
 	origLogs := append([]*Log(nil), m.logs...)
-	err := fn()
-	if err != nil {
+	view := &memState{
+		data:       m.data,
+		balances:   m.balances,
+		lpBalances: m.lpBalances,
+		logs:       m.logs,
+		contracts:  m.contracts,
+		tokens:     m.tokens,
+		codeHashes: m.codeHashes,
+		nonces:     m.nonces,
+	}
+	if err := fn(view); err != nil {
 		m.data = origData
 		m.balances = origBalances
 		m.lpBalances = origLP
@@ -650,9 +721,17 @@ func (m *memState) Snapshot(fn func() error) error {
 		m.nonces = origNonces
 		m.tokens = origTokens
 		m.logs = origLogs
-	}
-	m.mu.Unlock()
-	return err
+		return err
+	}
+	m.data = view.data
+	m.balances = view.balances
+	m.lpBalances = view.lpBalances
+	m.contracts = view.contracts
+	m.codeHashes = view.codeHashes
+	m.nonces = view.nonces
+	m.tokens = view.tokens
+	m.logs = view.logs
+	return nil
 }
 
 func (m *memState) NonceOf(addr Address) uint64 {
@@ -764,6 +843,7 @@ type VMContext struct {
 	GasMeter       *GasMeter
 	LastReturnData []byte
 	Code           []byte
+	Tracer         VMTracer // optional, see vm_tracer.go
 }
 
 // Memory is the linear byte‐array your opcodes read from and write to.
@@ -787,18 +867,25 @@ type ChainContext interface {
 }
 
 type Log struct {
-	Address   Address       `json:"address"` // <- Add this
-	Topics    []common.Hash `json:"topics"`  // <- Add this
-	Data      []byte        `json:"data"`
-	BlockTime int64         `json:"block_time"`
+	Address     Address       `json:"address"` // <- Add this
+	Topics      []common.Hash `json:"topics"`  // <- Add this
+	Data        []byte        `json:"data"`
+	BlockTime   int64         `json:"block_time"`
+	BlockHeight uint64        `json:"block_height,omitempty"` // set once included in a block
+	TxHash      Hash          `json:"tx_hash,omitempty"`
 }
 
 type Receipt struct {
 	Status     bool   `json:"status"`
 	GasUsed    uint64 `json:"gas_used"`
+	GasRefund  uint64 `json:"gas_refund,omitempty"`
 	ReturnData []byte `json:"return_data,omitempty"`
 	Logs       []Log  `json:"logs,omitempty"`
 	Error      string `json:"error,omitempty"`
+	// Revert is the decoded payload of a reverted execution.
+	Revert *RevertReason `json:"revert,omitempty"`
+	// Calls are the receipts of the calls of a multicall, in order.
+	Calls []CallResult `json:"calls,omitempty"`
 }
 
 //---------------------------------------------------------------------
@@ -807,8 +894,10 @@ type Receipt struct {
 
 // GasMeter tracks gas usage and enforces the execution gas limit.
 type GasMeter struct {
-	used  uint64 // gas consumed so far
-	limit uint64 // total gas available
+	used   uint64     // gas consumed so far
+	limit  uint64     // total gas available
+	refund uint64     // refund accrued this tx, see gas_refund.go
+	access *accessSet // warm slots touched this tx
 }
 
 // NewGasMeter constructs a GasMeter with the given gas limit.
@@ -839,7 +928,7 @@ func (g *GasMeter) Remaining() uint64 {
 
 func (g *GasMeter) Consume(op Opcode) error {
 	c := GasCost(op)
-	if g.used+c > g.limit {
+	if c > g.limit-g.used {
 		return fmt.Errorf("out-of-gas (%d/%d)", g.used+c, g.limit)
 	}
 	g.used += c
@@ -890,10 +979,31 @@ func NewHeavyVM(led StateRW, gas *GasMeter, engine *wasmer.Engine) VM {
 //---------------------------------------------------------------------
 
 func (vm *SuperLightVM) Execute(bc []byte, ctx *VMContext) (*Receipt, error) {
+	rec := &Receipt{Status: true, GasUsed: 0}
 	if sha256.Sum256(bc) != ctx.TxHash {
-		return &Receipt{Status: false, Error: "tx hash mismatch"}, nil
+		rec = &Receipt{Status: false, Error: "tx hash mismatch"}
+	}
+	endTrace(ctx, rec, nil)
+	return rec, nil
+}
+
+// endTrace reports the end of an execution to the tracer of ctx, if any.
+// Every VM calls it once on each exit path, faults and reverts included.
+func endTrace(ctx *VMContext, rec *Receipt, err error) {
+	if ctx == nil || ctx.Tracer == nil {
+		return
+	}
+	var (
+		out  []byte
+		used uint64
+	)
+	if rec != nil {
+		out, used = rec.ReturnData, rec.GasUsed
+		if err == nil && !rec.Status {
+			err = errors.New(rec.Error)
+		}
 	}
-	return &Receipt{Status: true, GasUsed: 0}, nil
+	ctx.Tracer.CaptureEnd(out, used, err)
 }
 
 //---------------------------------------------------------------------
@@ -901,11 +1011,18 @@ func (vm *SuperLightVM) Execute(bc []byte, ctx *VMContext) (*Receipt, error) {
 //---------------------------------------------------------------------
 
 func (vm *LightVM) Execute(b []byte, ctx *VMContext) (*Receipt, error) {
+	rec, err := vm.run(b, ctx)
+	endTrace(ctx, rec, err)
+	return rec, err
+}
+
+func (vm *LightVM) run(b []byte, ctx *VMContext) (*Receipt, error) {
 	rec := &Receipt{Status: true}
 	stack := make([][]byte, 0, 16)
 	pc := 0
 	meter := vm.gas
 	store := vm.led
+	meter.BeginTx()
 
 	push := func(d []byte) { stack = append(stack, d) }
 	pop := func() ([]byte, error) {
@@ -917,22 +1034,37 @@ func (vm *LightVM) Execute(b []byte, ctx *VMContext) (*Receipt, error) {
 		return v, nil
 	}
 
+	var step *VMStep
+	if ctx.Tracer != nil && ctx.Memory != nil {
+		ctx.Memory = &tracingMemory{Memory: ctx.Memory, step: &step}
+	}
+	fault := func(rec *Receipt, err error) (*Receipt, error) {
+		if step != nil {
+			step.Error = err.Error()
+		}
+		return fail(rec, err)
+	}
+
 	for pc < len(b) {
 		op := Opcode(b[pc])
+		if ctx.Tracer != nil {
+			step = &VMStep{PC: uint64(pc), Op: lightOpName(op), Gas: meter.Remaining(), GasCost: GasCost(op), Depth: 1, Stack: stackHex(stack)}
+			ctx.Tracer.CaptureState(step)
+		}
 		pc++
 		if err := meter.Consume(op); err != nil {
-			return fail(rec, err)
+			return fault(rec, err)
 		}
 
 		switch op {
 		case PUSH:
 			if pc >= len(b) {
-				return fail(rec, errors.New("missing length byte"))
+				return fault(rec, errors.New("missing length byte"))
 			}
 			l := int(b[pc])
 			pc++
 			if pc+l > len(b) {
-				return fail(rec, errors.New("push out of bounds"))
+				return fault(rec, errors.New("push out of bounds"))
 			}
 			push(b[pc : pc+l])
 			pc += l
@@ -940,42 +1072,53 @@ func (vm *LightVM) Execute(b []byte, ctx *VMContext) (*Receipt, error) {
 		case ADD:
 			a, err := pop()
 			if err != nil {
-				return fail(rec, err)
+				return fault(rec, err)
 			}
 			b1, err := pop()
 			if err != nil {
-				return fail(rec, err)
+				return fault(rec, err)
 			}
 			push(AddBigInts(a, b1))
 
 		case STORE:
 			key, err := pop()
 			if err != nil {
-				return fail(rec, err)
+				return fault(rec, err)
 			}
 			val, err := pop()
 			if err != nil {
-				return fail(rec, err)
+				return fault(rec, err)
+			}
+			cur, _ := store.Get(ctx.TxHash[:], key)
+			if err := meter.ChargeSStore(ctx.TxHash[:], key, cur, val); err != nil {
+				return fault(rec, err)
 			}
 			if err := store.Set(ctx.TxHash[:], key, val); err != nil {
-				return fail(rec, err)
+				return fault(rec, err)
 			}
+			step.recordStorage(key, val)
 
 		case LOAD:
 			key, err := pop()
 			if err != nil {
-				return fail(rec, err)
+				return fault(rec, err)
 			}
 			val, err := store.Get(ctx.TxHash[:], key)
 			if err != nil {
-				return fail(rec, err)
+				return fault(rec, err)
+			}
+			if err := meter.ChargeSLoad(ctx.TxHash[:], key, val); err != nil {
+				return fault(rec, err)
 			}
 			push(val)
 
 		case LOG:
 			msg, err := pop()
 			if err != nil {
-				return fail(rec, err)
+				return fault(rec, err)
+			}
+			if err := checkLogLimit(len(rec.Logs)); err != nil {
+				return fault(rec, err)
 			}
 			rec.Logs = append(rec.Logs, Log{
 				BlockTime: time.Now().Unix(),
@@ -986,14 +1129,19 @@ func (vm *LightVM) Execute(b []byte, ctx *VMContext) (*Receipt, error) {
 		case RET:
 			rd, _ := pop()
 			rec.ReturnData = rd
-			rec.GasUsed = meter.used
+			rec.GasUsed, rec.GasRefund = meter.Settle()
 			return rec, nil
 
+		case REVERT:
+			rd, _ := pop()
+			rec.GasUsed, rec.GasRefund = meter.Settle()
+			return fault(rec, &revertError{Data: rd})
+
 		default:
-			return fail(rec, fmt.Errorf("unknown opcode 0x%02X", op))
+			return fault(rec, fmt.Errorf("unknown opcode 0x%02X", op))
 		}
 	}
-	rec.GasUsed = meter.used
+	rec.GasUsed, rec.GasRefund = meter.Settle()
 	return rec, nil
 }
 
@@ -1007,9 +1155,17 @@ type hostCtx struct {
 	gas   *GasMeter
 	tx    *VMContext
 	rec   *Receipt
+	// revert is set when the contract called host_revert.
+	revert *revertError
 }
 
 func (vm *HeavyVM) Execute(code []byte, ctx *VMContext) (*Receipt, error) {
+	rec, err := vm.run(code, ctx)
+	endTrace(ctx, rec, err)
+	return rec, err
+}
+
+func (vm *HeavyVM) run(code []byte, ctx *VMContext) (*Receipt, error) {
 	rec := &Receipt{Status: true}
 
 	store := wasmer.NewStore(vm.engine) // ← you already have this
@@ -1018,6 +1174,7 @@ func (vm *HeavyVM) Execute(code []byte, ctx *VMContext) (*Receipt, error) {
 		return nil, err
 	}
 
+	vm.gas.BeginTx()
 	hctx := &hostCtx{store: vm.led, gas: vm.gas, tx: ctx, rec: rec}
 
 	imports := registerHost(store, hctx) // ← pass store **and** hctx
@@ -1038,11 +1195,15 @@ func (vm *HeavyVM) Execute(code []byte, ctx *VMContext) (*Receipt, error) {
 		return nil, errors.New("_start function required")
 	}
 	if _, err = start(); err != nil {
+		if hctx.revert != nil {
+			rec.GasUsed, rec.GasRefund = vm.gas.Settle()
+			return fail(rec, hctx.revert)
+		}
 		rec.Status = false
 		rec.Error = err.Error()
 	}
 
-	rec.GasUsed = vm.gas.used
+	rec.GasUsed, rec.GasRefund = vm.gas.Settle()
 	return rec, nil
 }
 
@@ -1106,6 +1267,11 @@ func registerHost(store *wasmer.Store, h *hostCtx) *wasmer.ImportObject {
 			if err != nil {
 				return []wasmer.Value{wasmer.NewI32(-1)}, nil
 			}
+			if err := h.gas.ChargeSLoad(h.tx.TxHash[:], key, val); err != nil {
+				h.rec.Status = false
+				h.rec.Error = err.Error()
+				return []wasmer.Value{wasmer.NewI32(-1)}, nil
+			}
 			write(dPtr, val)
 			return []wasmer.Value{wasmer.NewI32(int32(len(val)))}, nil
 		},
@@ -1132,6 +1298,12 @@ func registerHost(store *wasmer.Store, h *hostCtx) *wasmer.ImportObject {
 			kPtr, kLen, vPtr, vLen := args[0].I32(), args[1].I32(), args[2].I32(), args[3].I32()
 			key := read(kPtr, kLen)
 			val := read(vPtr, vLen)
+			cur, _ := h.store.Get(h.tx.TxHash[:], key)
+			if err := h.gas.ChargeSStore(h.tx.TxHash[:], key, cur, val); err != nil {
+				h.rec.Status = false
+				h.rec.Error = err.Error()
+				return []wasmer.Value{wasmer.NewI32(-1)}, nil
+			}
 			if err := h.store.Set(h.tx.TxHash[:], key, val); err != nil {
 				h.rec.Status = false
 				h.rec.Error = err.Error()
@@ -1158,6 +1330,9 @@ func registerHost(store *wasmer.Store, h *hostCtx) *wasmer.ImportObject {
 		func(args []wasmer.Value) ([]wasmer.Value, error) {
 			p, l := args[0].I32(), args[1].I32()
 			msg := read(p, l)
+			if err := checkLogLimit(len(h.rec.Logs)); err != nil {
+				return nil, err
+			}
 			h.rec.Logs = append(h.rec.Logs, Log{
 				BlockTime: time.Now().Unix(),
 				Topics:    []common.Hash{common.BytesToHash(h.tx.TxHash[:])},
@@ -1168,13 +1343,49 @@ func registerHost(store *wasmer.Store, h *hostCtx) *wasmer.ImportObject {
 		},
 	)
 
-	// Register all functions under the "env" namespace.
-	imports.Register("env", map[string]wasmer.IntoExtern{
+	// -----------------------------------------------------------------
+	// host_emit(namePtr,nameLen,dataPtr,dataLen) -> i32
+	// -----------------------------------------------------------------
+	hostEmit := wasmer.NewFunction(
+		store,
+		wasmer.NewFunctionType(
+			wasmer.NewValueTypes(
+				wasmer.ValueKind(wasmer.I32),
+				wasmer.ValueKind(wasmer.I32),
+				wasmer.ValueKind(wasmer.I32),
+				wasmer.ValueKind(wasmer.I32),
+			),
+			wasmer.NewValueTypes(
+				wasmer.ValueKind(wasmer.I32),
+			),
+		),
+		func(args []wasmer.Value) ([]wasmer.Value, error) {
+			name := read(args[0].I32(), args[1].I32())
+			data := read(args[2].I32(), args[3].I32())
+			ctx := h.tx.Context
+			ctx.TxHash = Hash(h.tx.TxHash)
+			if _, err := EmitContractEvent(&ctx, ctx.Contract, string(name), data); err != nil {
+				return []wasmer.Value{wasmer.NewI32(-1)}, nil
+			}
+			return []wasmer.Value{wasmer.NewI32(0)}, nil
+		},
+	)
+
+	// Register the v1 functions under the "env" namespace and the v2 ABI
+	// (v1 plus the SDK functions) under "synnergy_v2"; see wasm_host_abi.go.
+	v1 := map[string]wasmer.IntoExtern{
 		"host_consume_gas": hostConsumeGas,
 		"host_read":        hostRead,
 		"host_write":       hostWrite,
 		"host_log":         hostLog,
-	})
+		"host_emit":        hostEmit,
+	}
+	imports.Register(HostABIV1Module, v1)
+	v2 := registerHostV2(store, h)
+	for name, fn := range v1 {
+		v2[name] = fn
+	}
+	imports.Register(HostABIV2Module, v2)
 
 	return imports
 }
@@ -1188,7 +1399,7 @@ var limiter = rate.NewLimiter(200, 100) // 200 req/s, burst 100
 func limit(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if !limiter.Allow() {
-			http.Error(w, "rate limit", http.StatusTooManyRequests)
+			WriteHTTPError(w, http.StatusTooManyRequests, "vm", errors.New("rate limit"))
 			return
 		}
 		next.ServeHTTP(w, r)
@@ -1229,17 +1440,17 @@ func main() {
 			Ctx  VMContext `json:"ctx"`
 		}
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			WriteHTTPError(w, http.StatusBadRequest, "vm", err)
 			return
 		}
 		code, err := hex.DecodeString(req.Code)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			WriteHTTPError(w, http.StatusBadRequest, "vm", err)
 			return
 		}
 		rec, err := engine.Execute(code, &req.Ctx)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			WriteHTTPError(w, http.StatusInternalServerError, "vm", err)
 			return
 		}
 		w.Header().Set("Content-Type", "application/json")
@@ -1262,5 +1473,10 @@ func main() {
 func fail(rec *Receipt, err error) (*Receipt, error) {
 	rec.Status = false
 	rec.Error = err.Error()
+	var rev *revertError
+	if errors.As(err, &rev) {
+		rec.ReturnData = rev.Data
+		rec.Revert = rev.Reason()
+	}
 	return rec, err
 }
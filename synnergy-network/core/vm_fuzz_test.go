@@ -0,0 +1,192 @@
+package core
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Fuzz targets for the VM interpreters and the opcode dispatcher. Run one
+// with e.g.
+//
+//	go test ./core -run '^$' -fuzz FuzzEVMOps
+//
+// Crashers found by the fuzzer land in testdata/fuzz/<target>/ and are
+// replayed by every plain `go test` run as regression cases.
+
+const fuzzGas = 1_000_000
+
+type fuzzChain struct{}
+
+func (fuzzChain) BlockNumber() uint64                 { return 1 }
+func (fuzzChain) Time() uint64                        { return 1 }
+func (fuzzChain) Difficulty() *big.Int                { return big.NewInt(0) }
+func (fuzzChain) GasLimit() uint64                    { return fuzzGas }
+func (fuzzChain) ChainID() *big.Int                   { return big.NewInt(1) }
+func (fuzzChain) BlockHash(number uint64) common.Hash { return common.Hash{} }
+
+// evmFuzzOps are the handlers FuzzEVMOps drives. Calls and creation are
+// left out: they re-enter the state, which is exercised separately.
+var evmFuzzOps = []func(*VMContext) error{
+	opADD, opMUL, opSUB, opDIV, opSDIV, opMOD, opSMOD, opADDMOD, opMULMOD,
+	opEXP, opSIGNEXTEND, opLT, opGT, opSLT, opSGT, opEQ, opISZERO, opAND,
+	opOR, opXOR, opNOT, opBYTE, opSHL, opSHR, opSAR, opECRECOVER,
+	opEXTCODESIZE, opEXTCODECOPY, opEXTCODEHASH, opRETURNDATASIZE,
+	opRETURNDATACOPY, opMLOAD, opMSTORE, opMSTORE8, opCALLDATALOAD,
+	opCALLDATASIZE, opCALLDATACOPY, opCODESIZE, opCODECOPY, opJUMP, opJUMPI,
+	opPC, opMSIZE, opGAS, opJUMPDEST, opSHA256, opKECCAK256, opRIPEMD160,
+	opBLAKE2B256, opADDRESS, opCALLER, opORIGIN, opCALLVALUE, opGASPRICE,
+	opNUMBER, opTIMESTAMP, opDIFFICULTY, opGASLIMIT, opCHAINID, opBLOCKHASH,
+	opBALANCE, opSELFBALANCE, opLOG0, opLOG1, opLOG2, opLOG3, opLOG4,
+	opRETURN, opREVERT, opSTOP,
+}
+
+// runEVMFuzz interprets code: 0x00–0x0F pushes the next byte, 0x10–0x1F
+// pushes the next 32 bytes as a word and b >= 0x20 runs evmFuzzOps[b-0x20],
+// wrapping around the table.
+func runEVMFuzz(t *testing.T, code, calldata []byte) {
+	st, _ := NewInMemory()
+	meter := NewGasMeter(fuzzGas)
+	ctx := &VMContext{
+		Context:  Context{Stack: &Stack{}, Args: calldata, Value: new(big.Int)},
+		Memory:   NewMemory(),
+		State:    st,
+		Chain:    fuzzChain{},
+		GasMeter: meter,
+		Code:     code,
+	}
+	for pc := 0; pc < len(code); pc++ {
+		b := code[pc]
+		switch {
+		case b < 0x10:
+			var v byte
+			if pc+1 < len(code) {
+				pc++
+				v = code[pc]
+			}
+			ctx.Stack.Push(new(big.Int).SetUint64(uint64(v)))
+			continue
+		case b < 0x20:
+			end := pc + 33
+			if end > len(code) {
+				end = len(code)
+			}
+			ctx.Stack.Push(new(big.Int).SetBytes(code[pc+1 : end]))
+			pc = end - 1
+			continue
+		}
+		if err := meter.Charge(3); err != nil {
+			return
+		}
+		ctx.PC = uint64(pc)
+		before := ctx.Memory.Len()
+		err := ExecOp(ctx, evmFuzzOps[int(b-0x20)%len(evmFuzzOps)])
+		if uint64(ctx.Memory.Len()) > MaxVMMemory {
+			t.Fatalf("memory grew to %d bytes", ctx.Memory.Len())
+		}
+		// Memory expansion is paid per word, as on the EVM, so one input
+		// cannot keep reallocating the full MaxVMMemory.
+		if grown := ctx.Memory.Len() - before; grown > 0 && meter.Charge(3*uint64(grown+31)/32) != nil {
+			return
+		}
+		if meter.Remaining() > fuzzGas {
+			t.Fatalf("gas underflow: remaining %d of %d", meter.Remaining(), fuzzGas)
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func FuzzEVMOps(f *testing.F) {
+	f.Add([]byte{0x20}, []byte(nil))                                     // ADD on an empty stack
+	f.Add([]byte{0x01, 0x02, 0x01, 0x03, 0x20}, []byte(nil))             // 2 + 3
+	f.Add(append([]byte{0x10}, make([]byte, 32)...), []byte{1, 2, 3, 4}) // 32-byte word
+	f.Fuzz(func(t *testing.T, code, calldata []byte) {
+		runEVMFuzz(t, code, calldata)
+	})
+}
+
+func FuzzLightVM(f *testing.F) {
+	f.Add([]byte{byte(PUSH), 1, 42, byte(PUSH), 1, 'k', byte(STORE), byte(PUSH), 1, 1, byte(RET)})
+	f.Add([]byte{byte(ADD)})
+	f.Add([]byte{byte(PUSH), 200})
+	f.Fuzz(func(t *testing.T, code []byte) {
+		st, _ := NewInMemory()
+		meter := NewGasMeter(fuzzGas)
+		rec, err := NewLightVM(st, meter).Execute(code, &VMContext{GasLimit: fuzzGas})
+		if rec == nil {
+			t.Fatalf("nil receipt (err %v)", err)
+		}
+		if err != nil && rec.Status {
+			t.Fatalf("error %v with successful receipt", err)
+		}
+		if meter.Remaining() > fuzzGas || rec.GasUsed > fuzzGas {
+			t.Fatalf("gas accounting: used %d remaining %d", rec.GasUsed, meter.Remaining())
+		}
+	})
+}
+
+func FuzzDispatch(f *testing.F) {
+	f.Add(uint32(0x1B0004), uint64(0))
+	f.Add(uint32(0x1B0004), uint64(1_000_000))
+	f.Add(uint32(0xFFFFFF), uint64(1))
+	f.Fuzz(func(t *testing.T, op uint32, gas uint64) {
+		ctx := &Context{GasLimit: gas}
+		_ = Dispatch(ctx, Opcode(op))
+		if ctx.GasLimit > gas {
+			t.Fatalf("gas underflow: %d left of %d", ctx.GasLimit, gas)
+		}
+	})
+}
+
+// Regression cases for the panics the fuzzers found before ExecOp.
+
+func TestExecOpStackUnderflow(t *testing.T) {
+	ctx := &VMContext{Context: Context{Stack: &Stack{}}}
+	if err := ExecOp(ctx, opADD); !errors.Is(err, ErrStackUnderflow) {
+		t.Fatalf("err = %v, want ErrStackUnderflow", err)
+	}
+}
+
+func TestExecOpMemoryLimit(t *testing.T) {
+	for name, fn := range map[string]func(*VMContext) error{
+		"MLOAD":        opMLOAD,
+		"CALLDATACOPY": opCALLDATACOPY,
+	} {
+		huge := new(big.Int).SetUint64(1 << 62)
+		st := &Stack{}
+		st.Push(huge) // CALLDATACOPY memOffset
+		st.Push(big.NewInt(0))
+		st.Push(huge) // CALLDATACOPY length, MLOAD offset
+		ctx := &VMContext{Context: Context{Stack: st}, Memory: NewMemory()}
+		if err := ExecOp(ctx, fn); !errors.Is(err, ErrMemoryLimit) {
+			t.Errorf("%s: err = %v, want ErrMemoryLimit", name, err)
+		}
+	}
+
+	// a zero-length copy may name any offset and leaves memory untouched
+	st := &Stack{}
+	st.Push(new(big.Int).SetUint64(^uint64(0))) // memOffset
+	st.Push(big.NewInt(0))
+	st.Push(big.NewInt(0)) // length
+	ctx := &VMContext{Context: Context{Stack: st}, Memory: NewMemory()}
+	if err := ExecOp(ctx, opCALLDATACOPY); err != nil || ctx.Memory.Len() != 0 {
+		t.Fatalf("zero-length CALLDATACOPY: err = %v, memory %d bytes", err, ctx.Memory.Len())
+	}
+}
+
+func TestGasMeterChargeOverflow(t *testing.T) {
+	g := NewGasMeter(100)
+	if err := g.Charge(10); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Charge(^uint64(0) - 5); err == nil {
+		t.Fatal("overflowing charge accepted")
+	}
+	if g.Remaining() != 90 {
+		t.Fatalf("remaining = %d, want 90", g.Remaining())
+	}
+}
@@ -0,0 +1,60 @@
+package core
+
+// vm_limits.go – execution limits for the EVM-compatible opcode handlers.
+//
+// The op* handlers in utility_functions.go pop operands with Stack.Pop,
+// which panics on underflow, and address linear memory with offsets and
+// lengths taken straight from the stack. ExecOp is the boundary that turns
+// those conditions into errors: it recovers ErrStackUnderflow and
+// ErrMemoryLimit panics, and memory is capped at MaxVMMemory so a single
+// operand cannot make the node allocate gigabytes. Dispatch recovers the
+// same panics from registered handlers.
+
+import (
+	"errors"
+	"fmt"
+)
+
+// MaxVMMemory bounds a contract's linear memory in bytes.
+const MaxVMMemory uint64 = 32 << 20
+
+var (
+	// ErrStackUnderflow is raised when an opcode pops an empty stack.
+	ErrStackUnderflow = errors.New("vm: stack underflow")
+	// ErrMemoryLimit is raised when an access would grow memory past
+	// MaxVMMemory.
+	ErrMemoryLimit = errors.New("vm: memory limit exceeded")
+)
+
+// checkMemRange reports whether [offset, offset+size) fits in MaxVMMemory.
+func checkMemRange(offset, size uint64) error {
+	if size == 0 {
+		return nil // empty ranges touch no memory, whatever the offset
+	}
+	end := offset + size
+	if end < offset || end > MaxVMMemory {
+		return fmt.Errorf("%w: [%d,+%d)", ErrMemoryLimit, offset, size)
+	}
+	return nil
+}
+
+// recoverVM converts the VM's panics into *err. Panics other than stack
+// underflow and memory limits are re-raised.
+func recoverVM(err *error) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	if e, ok := r.(error); ok && (errors.Is(e, ErrStackUnderflow) || errors.Is(e, ErrMemoryLimit)) {
+		*err = e
+		return
+	}
+	panic(r)
+}
+
+// ExecOp runs a single opcode handler, returning stack underflow and memory
+// limit violations as errors instead of panicking.
+func ExecOp(ctx *VMContext, fn func(*VMContext) error) (err error) {
+	defer recoverVM(&err)
+	return fn(ctx)
+}
@@ -0,0 +1,272 @@
+package core
+
+import (
+	"encoding/hex"
+	"math/big"
+	"sort"
+	"sync"
+)
+
+// VMTracer observes contract execution. A tracer is attached through
+// VMContext.Tracer; the light interpreter reports every opcode to it, while
+// the heavy (WASM) VM only reports the start and end of the call because
+// compiled code cannot be stepped. The caller reports the start; the VM
+// reports the end from Execute on every exit path, reverts and faults
+// included.
+//
+// The step passed to CaptureState describes the machine before the opcode
+// runs. The VM completes it with storage writes, memory writes and the
+// fault, if any, once the opcode has executed, so tracers may keep the
+// pointer but must not read those fields before the next call.
+type VMTracer interface {
+	CaptureStart(from, to Address, input []byte, gas uint64)
+	CaptureState(step *VMStep)
+	CaptureEnd(output []byte, gasUsed uint64, err error)
+	// Result returns the JSON-encodable trace collected so far.
+	Result() interface{}
+}
+
+// VMStep is one executed opcode.
+type VMStep struct {
+	PC      uint64            `json:"pc"`
+	Op      string            `json:"op"`
+	Gas     uint64            `json:"gas"`      // remaining before the opcode
+	GasCost uint64            `json:"gas_cost"` // charged for the opcode
+	Depth   int               `json:"depth"`
+	Stack   []string          `json:"stack,omitempty"`   // hex, top of stack last
+	Memory  []MemoryWrite     `json:"memory,omitempty"`  // writes made by the opcode
+	Storage map[string]string `json:"storage,omitempty"` // hex key -> hex value written
+	Error   string            `json:"error,omitempty"`
+}
+
+// MemoryWrite is a region of VM memory changed by one step.
+type MemoryWrite struct {
+	Offset uint64 `json:"offset"`
+	Data   string `json:"data"` // hex
+}
+
+// recordStorage notes a storage write performed by the step.
+func (s *VMStep) recordStorage(key, val []byte) {
+	if s == nil {
+		return
+	}
+	if s.Storage == nil {
+		s.Storage = make(map[string]string)
+	}
+	s.Storage[hex.EncodeToString(key)] = hex.EncodeToString(val)
+}
+
+// Tracer names accepted by NewTracer.
+const (
+	TracerStructLogger = "structLogger"
+	TracerCall         = "callTracer"
+)
+
+// NewTracer returns a fresh tracer by name. An empty name selects the
+// struct logger.
+func NewTracer(name string) (VMTracer, error) {
+	switch name {
+	case "", TracerStructLogger:
+		return &StructLogger{StackLimit: 16}, nil
+	case TracerCall:
+		return &CallTracer{}, nil
+	default:
+		return nil, NewError(CodeInvalidArgument, "vm", "unknown tracer %q", name)
+	}
+}
+
+// StructLogger records every step of an execution.
+type StructLogger struct {
+	// StackLimit bounds the number of stack entries, counted from the top,
+	// copied into each step; zero copies the whole stack.
+	StackLimit int
+
+	mu     sync.Mutex
+	steps  []*VMStep
+	gas    uint64
+	output []byte
+	err    error
+}
+
+// StructLogResult is the trace produced by StructLogger.
+type StructLogResult struct {
+	Gas         uint64    `json:"gas"`
+	Failed      bool      `json:"failed"`
+	Error       string    `json:"error,omitempty"`
+	ReturnValue string    `json:"return_value"`
+	StructLogs  []*VMStep `json:"struct_logs"`
+	// Revert is the decoded reason of a reverted execution.
+	Revert *RevertReason `json:"revert,omitempty"`
+}
+
+func (s *StructLogger) CaptureStart(Address, Address, []byte, uint64) {}
+
+func (s *StructLogger) CaptureState(step *VMStep) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.StackLimit > 0 && len(step.Stack) > s.StackLimit {
+		step.Stack = step.Stack[len(step.Stack)-s.StackLimit:]
+	}
+	s.steps = append(s.steps, step)
+}
+
+func (s *StructLogger) CaptureEnd(output []byte, gasUsed uint64, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.output, s.gas, s.err = output, gasUsed, err
+}
+
+func (s *StructLogger) Result() interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	res := &StructLogResult{
+		Gas:         s.gas,
+		Failed:      s.err != nil,
+		ReturnValue: hex.EncodeToString(s.output),
+		StructLogs:  append([]*VMStep{}, s.steps...),
+	}
+	if s.err != nil {
+		res.Error = s.err.Error()
+		res.Revert = RevertOf(s.err)
+	}
+	return res
+}
+
+// CallFrame is a contract call as reported by CallTracer.
+type CallFrame struct {
+	Type    string            `json:"type"`
+	From    string            `json:"from"`
+	To      string            `json:"to"`
+	Input   string            `json:"input"`
+	Output  string            `json:"output,omitempty"`
+	Gas     uint64            `json:"gas"`
+	GasUsed uint64            `json:"gas_used"`
+	Error   string            `json:"error,omitempty"`
+	Revert  *RevertReason     `json:"revert,omitempty"`
+	Storage map[string]string `json:"storage,omitempty"` // final values written by the call
+}
+
+// CallTracer summarises an execution as a call frame: who called what with
+// which input, the outcome, the gas used and the storage it wrote, without
+// the per-step detail of StructLogger.
+type CallTracer struct {
+	mu      sync.Mutex
+	frame   *CallFrame
+	pending []*VMStep
+}
+
+func (c *CallTracer) CaptureStart(from, to Address, input []byte, gas uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.frame = &CallFrame{Type: "CALL", From: from.Hex(), To: to.Hex(), Input: hex.EncodeToString(input), Gas: gas}
+}
+
+func (c *CallTracer) CaptureState(step *VMStep) {
+	// Storage is filled in after the opcode runs, so keep the step and
+	// collect its writes at the end.
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.frame == nil {
+		return
+	}
+	c.pending = append(c.pending, step)
+}
+
+func (c *CallTracer) CaptureEnd(output []byte, gasUsed uint64, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.frame == nil {
+		c.frame = &CallFrame{Type: "CALL"}
+	}
+	c.frame.Output = hex.EncodeToString(output)
+	c.frame.GasUsed = gasUsed
+	if err != nil {
+		c.frame.Error = err.Error()
+		c.frame.Revert = RevertOf(err)
+	}
+	for _, st := range c.pending {
+		for k, v := range st.Storage {
+			if c.frame.Storage == nil {
+				c.frame.Storage = make(map[string]string)
+			}
+			c.frame.Storage[k] = v
+		}
+	}
+	c.pending = nil
+}
+
+func (c *CallTracer) Result() interface{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.frame
+}
+
+// stackHex renders a VM stack for a trace step, bottom first.
+func stackHex(stack [][]byte) []string {
+	out := make([]string, len(stack))
+	for i, v := range stack {
+		out[i] = hex.EncodeToString(v)
+	}
+	return out
+}
+
+// tracingMemory wraps VM memory and attributes every write to the current
+// step.
+type tracingMemory struct {
+	Memory
+	step **VMStep
+}
+
+func (m *tracingMemory) Write(offset uint64, data []byte) {
+	m.Memory.Write(offset, data)
+	if st := *m.step; st != nil {
+		st.Memory = append(st.Memory, MemoryWrite{Offset: offset, Data: hex.EncodeToString(data)})
+	}
+}
+
+// lightOpName returns the mnemonic of a light-interpreter opcode.
+func lightOpName(op Opcode) string {
+	switch op {
+	case PUSH:
+		return "PUSH"
+	case ADD:
+		return "ADD"
+	case STORE:
+		return "STORE"
+	case LOAD:
+		return "LOAD"
+	case LOG:
+		return "LOG"
+	case RET:
+		return "RET"
+	case REVERT:
+		return "REVERT"
+	}
+	return op.String()
+}
+
+// TraceCall re-executes a contract call against the state at the view's
+// height, reporting every step to tracer. The call runs in a sandbox built
+// from the archive, so nothing it writes reaches the ledger.
+func (v *StateView) TraceCall(from, to Address, input []byte, value *big.Int, gas uint64, tracer VMTracer) ([]byte, error) {
+	ms, err := v.sandbox(to)
+	if err != nil {
+		tracer.CaptureStart(from, to, input, gas)
+		tracer.CaptureEnd(nil, 0, err)
+		return nil, err
+	}
+	if value == nil {
+		value = big.NewInt(0)
+	}
+	return ms.call(from, to, input, value, gas, tracer)
+}
+
+// sortedKeys returns the keys of m in order.
+func sortedKeys(m map[string][]byte) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,81 @@
+package core
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math/big"
+	"testing"
+)
+
+// storeAndReturn stores 0x07 under key 0x01 and returns 0x2a, padded so
+// SelectVM picks the light interpreter.
+func storeAndReturn() []byte {
+	code := []byte{
+		byte(PUSH), 1, 0x07,
+		byte(PUSH), 1, 0x01,
+		byte(STORE),
+		byte(PUSH), 1, 0x2a,
+		byte(RET),
+	}
+	return append(code, make([]byte, 120-len(code))...)
+}
+
+func TestStructLoggerRecordsLightSteps(t *testing.T) {
+	st, _ := NewInMemory()
+	sl := &StructLogger{}
+	vm := NewLightVM(st, NewGasMeter(1_000_000))
+	rec, err := vm.Execute(storeAndReturn(), &VMContext{TxHash: [32]byte{1}, Tracer: sl})
+	if err != nil || !bytes.Equal(rec.ReturnData, []byte{0x2a}) {
+		t.Fatalf("execute: %v %+v", err, rec)
+	}
+	steps := sl.Result().(*StructLogResult).StructLogs
+	ops := ""
+	for _, s := range steps {
+		ops += s.Op + " "
+	}
+	if ops != "PUSH PUSH STORE PUSH RET " {
+		t.Fatalf("ops = %q", ops)
+	}
+	store := steps[2]
+	if store.PC != 6 || len(store.Stack) != 2 || store.Stack[1] != "01" {
+		t.Fatalf("store step = %+v", store)
+	}
+	if store.Storage["01"] != "07" {
+		t.Fatalf("storage writes = %v", store.Storage)
+	}
+	if steps[3].Gas > store.Gas {
+		t.Fatalf("gas grew from %d to %d", store.Gas, steps[3].Gas)
+	}
+}
+
+func TestStructLoggerMarksFault(t *testing.T) {
+	st, _ := NewInMemory()
+	sl := &StructLogger{}
+	vm := NewLightVM(st, NewGasMeter(1_000_000))
+	if _, err := vm.Execute([]byte{byte(ADD)}, &VMContext{Tracer: sl}); err == nil {
+		t.Fatal("expected stack underflow")
+	}
+	steps := sl.Result().(*StructLogResult).StructLogs
+	if len(steps) != 1 || steps[0].Error != "stack underflow" {
+		t.Fatalf("steps = %+v", steps)
+	}
+}
+
+func TestCallTracerSummarisesCall(t *testing.T) {
+	st, _ := NewInMemory()
+	m := st.(*memState)
+	to := Address{0xcc}
+	m.contracts[to] = storeAndReturn()
+	ct, _ := NewTracer(TracerCall)
+	out, err := m.call(Address{0xaa}, to, []byte{0x01}, big.NewInt(0), 1_000_000, ct)
+	if err != nil || !bytes.Equal(out, []byte{0x2a}) {
+		t.Fatalf("call: %v %x", err, out)
+	}
+	f := ct.Result().(*CallFrame)
+	if f.To != to.Hex() || f.Input != "01" || f.Output != hex.EncodeToString(out) || f.Error != "" {
+		t.Fatalf("frame = %+v", f)
+	}
+	if f.Storage["01"] != "07" {
+		t.Fatalf("frame storage = %v", f.Storage)
+	}
+}
@@ -204,6 +204,10 @@ func pubKeyToAddress(pub ed25519.PublicKey) Address {
 	return out
 }
 
+// Ed25519Address exposes the account address derivation for external
+// signers such as daemons holding a raw key instead of an HD wallet.
+func Ed25519Address(pub ed25519.PublicKey) Address { return pubKeyToAddress(pub) }
+
 // NewAddress derives account+index and returns its Address.
 func (w *HDWallet) NewAddress(account, index uint32) (Address, error) {
 	_, pub, err := w.PrivateKey(account, index)
@@ -0,0 +1,447 @@
+package core
+
+// wasm_host_abi.go – versioned host ABI for heavy-VM (WASM) contracts.
+//
+// Version 1 is the original "env" import module: host_consume_gas,
+// host_read, host_write, host_log and host_emit. It is frozen so deployed
+// contracts keep linking against it unchanged.
+//
+// Version 2 is the "synnergy_v2" import module. It re-exports the v1
+// functions under the same names and adds:
+//
+//	host_abi_version() -> i32
+//	host_balance(addrPtr) -> i64
+//	host_transfer(toPtr, amount wasmI64) -> i32
+//	host_token_balance(token wasmI32, addrPtr) -> i64
+//	host_token_transfer(token wasmI32, toPtr, amount wasmI64) -> i32
+//	host_emit_topics(namePtr, nameLen, topicsPtr, nTopics, dataPtr, dataLen) -> i32
+//	host_block_height() -> i64
+//	host_timestamp() -> i64
+//	host_chain_id() -> i64
+//	host_caller(dstPtr)
+//	host_origin(dstPtr)
+//	host_self(dstPtr)
+//	host_keccak256(ptr, len, dstPtr)
+//	host_sha256(ptr, len, dstPtr)
+//	host_ecrecover(hashPtr, sigPtr, dstPtr) -> i32
+//	host_verify_ed25519(pubPtr, msgPtr, msgLen, sigPtr) -> i32
+//	host_domain_separator(namePtr, nameLen, versionPtr, versionLen, dstPtr)
+//	host_verify_typed(domainPtr, structPtr, sigPtr, dstPtr) -> i32
+//	host_multicall(ptr, len) -> i32
+//	host_revert(ptr, len)
+//
+// Addresses are 20 bytes, hashes and topics 32 bytes, secp256k1 signatures
+// 65 bytes (r‖s‖v) and ed25519 keys/signatures 32/64 bytes. Functions
+// returning i32 report 0 (or 1 for a valid signature) on success and -1 on
+// failure. Value transfers move funds out of the executing contract.
+// host_domain_separator writes the typed data domain separator of the
+// executing contract on this chain; host_verify_typed checks a 96-byte
+// signature (signature ‖ public key) over the digest of a domain separator
+// and struct hash and writes the signer's address (see typed_data.go).
+// host_multicall runs the calls encoded at [ptr, ptr+len) (see DecodeCalls)
+// from the executing contract, all-or-nothing; their gas is charged to it.
+// host_revert aborts the call with the payload at [ptr, ptr+len), which is
+// decoded as the revert reason (see revert_reason.go).
+// Out-of-bounds memory access traps the instance.
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/wasmerio/wasmer-go/wasmer"
+)
+
+// Host ABI import modules and the latest version.
+const (
+	HostABIV1Module = "env"
+	HostABIV2Module = "synnergy_v2"
+	HostABIVersion  = 2
+)
+
+// Gas charged by the v2 host functions, on top of any host_consume_gas
+// calls the contract makes itself.
+const (
+	hostContextGas    uint64 = 2
+	hostBalanceGas    uint64 = 100
+	hostTransferGas   uint64 = 9000
+	hostEmitGas       uint64 = 375
+	hostEmitTopicGas  uint64 = 375
+	hostEmitByteGas   uint64 = 8
+	hostHashGas       uint64 = 30
+	hostHashWordGas   uint64 = 6
+	hostEcrecoverGas  uint64 = 3000
+	hostEd25519Gas    uint64 = 2000
+	hostMaxEmitTopics        = 4
+)
+
+var (
+	wasmI32 = wasmer.ValueKind(wasmer.I32)
+	wasmI64 = wasmer.ValueKind(wasmer.I64)
+)
+
+// hostFn wraps a host callback with the given parameter and result kinds.
+func hostFn(store *wasmer.Store, params, results []wasmer.ValueKind, fn func([]wasmer.Value) ([]wasmer.Value, error)) *wasmer.Function {
+	return wasmer.NewFunction(store, wasmer.NewFunctionType(wasmer.NewValueTypes(params...), wasmer.NewValueTypes(results...)), fn)
+}
+
+func kinds(k ...wasmer.ValueKind) []wasmer.ValueKind { return k }
+
+// slice returns the guest memory slice [ptr, ptr+ln) or an error that traps
+// the instance.
+func (h *hostCtx) slice(ptr, ln int32) ([]byte, error) {
+	data := h.mem.Data()
+	if ptr < 0 || ln < 0 || int(ptr)+int(ln) > len(data) {
+		return nil, fmt.Errorf("host: memory access [%d,+%d) out of bounds", ptr, ln)
+	}
+	return data[ptr : ptr+ln], nil
+}
+
+func (h *hostCtx) readAddr(ptr int32) (Address, error) {
+	var a Address
+	b, err := h.slice(ptr, int32(len(a)))
+	if err != nil {
+		return a, err
+	}
+	copy(a[:], b)
+	return a, nil
+}
+
+func (h *hostCtx) put(ptr int32, data []byte) error {
+	dst, err := h.slice(ptr, int32(len(data)))
+	if err != nil {
+		return err
+	}
+	copy(dst, data)
+	return nil
+}
+
+// charge consumes gas, marking the receipt failed when the meter runs out.
+func (h *hostCtx) charge(n uint64) bool {
+	if err := h.gas.Charge(n); err != nil {
+		h.rec.Status = false
+		h.rec.Error = err.Error()
+		return false
+	}
+	return true
+}
+
+func hashGas(n int32) uint64 { return hostHashGas + hostHashWordGas*uint64((n+31)/32) }
+
+func (h *hostCtx) blockHeight() uint64 {
+	if h.tx.Chain != nil {
+		return h.tx.Chain.BlockNumber()
+	}
+	return h.tx.BlockHeight
+}
+
+func (h *hostCtx) blockTime() int64 {
+	if h.tx.Chain != nil {
+		return int64(h.tx.Chain.Time())
+	}
+	return h.tx.Timestamp
+}
+
+func (h *hostCtx) chainID() int64 {
+	if h.tx.Chain != nil {
+		if id := h.tx.Chain.ChainID(); id != nil {
+			return id.Int64()
+		}
+	}
+	return 0
+}
+
+var (
+	hostOK   = []wasmer.Value{wasmer.NewI32(0)}
+	hostFail = []wasmer.Value{wasmer.NewI32(-1)}
+)
+
+// registerHostV2 returns the functions the v2 module adds to v1.
+func registerHostV2(store *wasmer.Store, h *hostCtx) map[string]wasmer.IntoExtern {
+	none := kinds()
+	addrOut := func(get func() Address) *wasmer.Function {
+		return hostFn(store, kinds(wasmI32), none, func(args []wasmer.Value) ([]wasmer.Value, error) {
+			if !h.charge(hostContextGas) {
+				return nil, nil
+			}
+			a := get()
+			return nil, h.put(args[0].I32(), a[:])
+		})
+	}
+	i64Out := func(get func() int64) *wasmer.Function {
+		return hostFn(store, none, kinds(wasmI64), func([]wasmer.Value) ([]wasmer.Value, error) {
+			h.charge(hostContextGas)
+			return []wasmer.Value{wasmer.NewI64(get())}, nil
+		})
+	}
+	hashFn := func(sum func([]byte) []byte) *wasmer.Function {
+		return hostFn(store, kinds(wasmI32, wasmI32, wasmI32), none, func(args []wasmer.Value) ([]wasmer.Value, error) {
+			in, err := h.slice(args[0].I32(), args[1].I32())
+			if err != nil {
+				return nil, err
+			}
+			if !h.charge(hashGas(args[1].I32())) {
+				return nil, nil
+			}
+			return nil, h.put(args[2].I32(), sum(in))
+		})
+	}
+
+	return map[string]wasmer.IntoExtern{
+		"host_abi_version": hostFn(store, none, kinds(wasmI32), func([]wasmer.Value) ([]wasmer.Value, error) {
+			return []wasmer.Value{wasmer.NewI32(HostABIVersion)}, nil
+		}),
+
+		// Balances and transfers
+		"host_balance": hostFn(store, kinds(wasmI32), kinds(wasmI64), func(args []wasmer.Value) ([]wasmer.Value, error) {
+			a, err := h.readAddr(args[0].I32())
+			if err != nil {
+				return nil, err
+			}
+			if !h.charge(hostBalanceGas) {
+				return []wasmer.Value{wasmer.NewI64(0)}, nil
+			}
+			return []wasmer.Value{wasmer.NewI64(int64(h.store.BalanceOf(a)))}, nil
+		}),
+		"host_transfer": hostFn(store, kinds(wasmI32, wasmI64), kinds(wasmI32), func(args []wasmer.Value) ([]wasmer.Value, error) {
+			to, err := h.readAddr(args[0].I32())
+			if err != nil {
+				return nil, err
+			}
+			amt := args[1].I64()
+			if amt < 0 || !h.charge(hostTransferGas) {
+				return hostFail, nil
+			}
+			if err := h.store.Transfer(h.tx.Contract, to, uint64(amt)); err != nil {
+				return hostFail, nil
+			}
+			return hostOK, nil
+		}),
+		"host_token_balance": hostFn(store, kinds(wasmI32, wasmI32), kinds(wasmI64), func(args []wasmer.Value) ([]wasmer.Value, error) {
+			a, err := h.readAddr(args[1].I32())
+			if err != nil {
+				return nil, err
+			}
+			if !h.charge(hostBalanceGas) {
+				return []wasmer.Value{wasmer.NewI64(0)}, nil
+			}
+			bal, err := h.store.GetTokenBalance(a, TokenID(uint32(args[0].I32())))
+			if err != nil {
+				return []wasmer.Value{wasmer.NewI64(0)}, nil
+			}
+			return []wasmer.Value{wasmer.NewI64(int64(bal))}, nil
+		}),
+		"host_token_transfer": hostFn(store, kinds(wasmI32, wasmI32, wasmI64), kinds(wasmI32), func(args []wasmer.Value) ([]wasmer.Value, error) {
+			id := TokenID(uint32(args[0].I32()))
+			to, err := h.readAddr(args[1].I32())
+			if err != nil {
+				return nil, err
+			}
+			amt := args[2].I64()
+			if amt < 0 || !h.charge(hostTransferGas) {
+				return hostFail, nil
+			}
+			from := h.tx.Contract
+			if from == to {
+				return hostOK, nil
+			}
+			fb, err := h.store.GetTokenBalance(from, id)
+			if err != nil || fb < uint64(amt) {
+				return hostFail, nil
+			}
+			tb, _ := h.store.GetTokenBalance(to, id)
+			if err := h.store.SetTokenBalance(from, id, fb-uint64(amt)); err != nil {
+				return hostFail, nil
+			}
+			if err := h.store.SetTokenBalance(to, id, tb+uint64(amt)); err != nil {
+				return hostFail, nil
+			}
+			return hostOK, nil
+		}),
+
+		// Events
+		"host_emit_topics": hostFn(store, kinds(wasmI32, wasmI32, wasmI32, wasmI32, wasmI32, wasmI32), kinds(wasmI32), func(args []wasmer.Value) ([]wasmer.Value, error) {
+			n := args[3].I32()
+			if n < 0 || n > hostMaxEmitTopics {
+				return hostFail, nil
+			}
+			name, err := h.slice(args[0].I32(), args[1].I32())
+			if err != nil {
+				return nil, err
+			}
+			raw, err := h.slice(args[2].I32(), n*32)
+			if err != nil {
+				return nil, err
+			}
+			data, err := h.slice(args[4].I32(), args[5].I32())
+			if err != nil {
+				return nil, err
+			}
+			if !h.charge(hostEmitGas + hostEmitTopicGas*uint64(n) + hostEmitByteGas*uint64(len(data))) {
+				return hostFail, nil
+			}
+			topics := make([]common.Hash, 0, n+1)
+			topics = append(topics, common.BytesToHash(crypto.Keccak256(name)))
+			for i := int32(0); i < n; i++ {
+				topics = append(topics, common.BytesToHash(raw[i*32:(i+1)*32]))
+			}
+			if err := checkLogLimit(len(h.rec.Logs)); err != nil {
+				return nil, err
+			}
+			data = append([]byte(nil), data...)
+			h.rec.Logs = append(h.rec.Logs, Log{
+				Address:   h.tx.Contract,
+				Topics:    topics,
+				Data:      data,
+				BlockTime: h.blockTime(),
+			})
+			ctx := h.tx.Context
+			ctx.TxHash = Hash(h.tx.TxHash)
+			if _, err := EmitContractEvent(&ctx, ctx.Contract, string(name), data); err != nil {
+				return hostFail, nil
+			}
+			return hostOK, nil
+		}),
+
+		// Block and call context
+		"host_block_height": i64Out(func() int64 { return int64(h.blockHeight()) }),
+		"host_timestamp":    i64Out(h.blockTime),
+		"host_chain_id":     i64Out(h.chainID),
+		"host_caller": addrOut(func() Address {
+			if h.tx.Caller != (common.Address{}) {
+				return FromCommon(h.tx.Caller)
+			}
+			return h.tx.Context.Caller
+		}),
+		"host_origin": addrOut(func() Address {
+			if h.tx.Origin != (common.Address{}) {
+				return FromCommon(h.tx.Origin)
+			}
+			return h.tx.TxOrigin
+		}),
+		"host_self": addrOut(func() Address { return h.tx.Contract }),
+
+		// Hashing and signatures
+		"host_keccak256": hashFn(func(b []byte) []byte { return crypto.Keccak256(b) }),
+		"host_sha256":    hashFn(func(b []byte) []byte { s := sha256.Sum256(b); return s[:] }),
+		"host_ecrecover": hostFn(store, kinds(wasmI32, wasmI32, wasmI32), kinds(wasmI32), func(args []wasmer.Value) ([]wasmer.Value, error) {
+			hash, err := h.slice(args[0].I32(), 32)
+			if err != nil {
+				return nil, err
+			}
+			sig, err := h.slice(args[1].I32(), 65)
+			if err != nil {
+				return nil, err
+			}
+			if !h.charge(hostEcrecoverGas) {
+				return hostFail, nil
+			}
+			pub, err := crypto.Ecrecover(hash, sig)
+			if err != nil {
+				return hostFail, nil
+			}
+			addr, err := AddressFromPubKey(pub)
+			if err != nil {
+				return hostFail, nil
+			}
+			return hostOK, h.put(args[2].I32(), addr[:])
+		}),
+		"host_verify_ed25519": hostFn(store, kinds(wasmI32, wasmI32, wasmI32, wasmI32), kinds(wasmI32), func(args []wasmer.Value) ([]wasmer.Value, error) {
+			pub, err := h.slice(args[0].I32(), ed25519.PublicKeySize)
+			if err != nil {
+				return nil, err
+			}
+			msg, err := h.slice(args[1].I32(), args[2].I32())
+			if err != nil {
+				return nil, err
+			}
+			sig, err := h.slice(args[3].I32(), ed25519.SignatureSize)
+			if err != nil {
+				return nil, err
+			}
+			if !h.charge(hostEd25519Gas) {
+				return hostFail, nil
+			}
+			if ed25519.Verify(ed25519.PublicKey(pub), msg, sig) {
+				return []wasmer.Value{wasmer.NewI32(1)}, nil
+			}
+			return []wasmer.Value{wasmer.NewI32(0)}, nil
+		}),
+		"host_domain_separator": hostFn(store, kinds(wasmI32, wasmI32, wasmI32, wasmI32, wasmI32), none, func(args []wasmer.Value) ([]wasmer.Value, error) {
+			name, err := h.slice(args[0].I32(), args[1].I32())
+			if err != nil {
+				return nil, err
+			}
+			version, err := h.slice(args[2].I32(), args[3].I32())
+			if err != nil {
+				return nil, err
+			}
+			if !h.charge(hashGas(args[1].I32()+args[3].I32()) + hostHashGas*2) {
+				return nil, nil
+			}
+			sep := TypedDomainSeparator(string(name), string(version), big.NewInt(h.chainID()), h.tx.Contract)
+			return nil, h.put(args[4].I32(), sep[:])
+		}),
+		"host_verify_typed": hostFn(store, kinds(wasmI32, wasmI32, wasmI32, wasmI32), kinds(wasmI32), func(args []wasmer.Value) ([]wasmer.Value, error) {
+			var domain, structHash Hash
+			b, err := h.slice(args[0].I32(), 32)
+			if err != nil {
+				return nil, err
+			}
+			copy(domain[:], b)
+			if b, err = h.slice(args[1].I32(), 32); err != nil {
+				return nil, err
+			}
+			copy(structHash[:], b)
+			sig, err := h.slice(args[2].I32(), ed25519.SignatureSize+ed25519.PublicKeySize)
+			if err != nil {
+				return nil, err
+			}
+			if !h.charge(hashGas(66) + hostEd25519Gas) {
+				return hostFail, nil
+			}
+			signer, err := RecoverTypedDigest(TypedDigest(domain, structHash), sig)
+			if err != nil {
+				return hostFail, nil
+			}
+			return hostOK, h.put(args[3].I32(), signer[:])
+		}),
+
+		// Execution control
+		"host_multicall": hostFn(store, kinds(wasmI32, wasmI32), kinds(wasmI32), func(args []wasmer.Value) ([]wasmer.Value, error) {
+			raw, err := h.slice(args[0].I32(), args[1].I32())
+			if err != nil {
+				return nil, err
+			}
+			calls, err := DecodeCalls(raw)
+			if err != nil || len(calls) == 0 {
+				return hostFail, nil
+			}
+			rec, err := ExecuteMulticall(h.store, h.tx.Contract, calls, h.gas.Remaining(), h.tx.Tracer)
+			if rec != nil && !h.charge(rec.GasUsed) {
+				return hostFail, nil
+			}
+			if err != nil {
+				return hostFail, nil
+			}
+			if len(rec.Logs) > 0 {
+				if err := checkLogLimit(len(h.rec.Logs) + len(rec.Logs) - 1); err != nil {
+					return nil, err
+				}
+			}
+			h.rec.Logs = append(h.rec.Logs, rec.Logs...)
+			return hostOK, nil
+		}),
+		"host_revert": hostFn(store, kinds(wasmI32, wasmI32), none, func(args []wasmer.Value) ([]wasmer.Value, error) {
+			data, err := h.slice(args[0].I32(), args[1].I32())
+			if err != nil {
+				return nil, err
+			}
+			h.revert = &revertError{Data: append([]byte(nil), data...)}
+			return nil, h.revert
+		}),
+	}
+}
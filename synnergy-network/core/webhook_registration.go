@@ -0,0 +1,151 @@
+package core
+
+// webhook_registration.go – who may register a webhook, and where it may
+// point.
+//
+// Invoices and address watches make the node POST to a URL of the
+// registrant's choosing, so both are registered with a request signed as
+// typed data by its owner (the merchant of an invoice, the owner of a
+// watch). The document is bound to the webhooks module account and the
+// permit chain ID and carries the owner's next registration nonce, so a
+// signed request creates one registration only.
+//
+// Webhook targets must be public: URLs naming localhost or a loopback,
+// private, link-local or otherwise non-public IP are refused when the
+// registration is created, and the client delivering the calls refuses to
+// connect to such addresses whatever the host name resolves to at the time.
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+var (
+	ErrWebhookTarget     = errors.New("webhook target not allowed")
+	ErrRegistrationSig   = errors.New("registration not signed by owner")
+	ErrRegistrationNonce = errors.New("registration nonce mismatch")
+
+	registrationMu sync.Mutex
+	// sharedAddressSpace is the carrier-grade NAT range of RFC 6598.
+	sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0).To4(), Mask: net.CIDRMask(10, 32)}
+)
+
+func registrationNonceKey(a Address) []byte { return []byte("webhook:nonce:" + a.Hex()) }
+
+// WebhookAccount is the module account registration documents are bound to.
+func WebhookAccount() Address { return ModuleAddress("webhooks") }
+
+// RegistrationNonce returns the nonce owner's next signed invoice or watch
+// request must carry.
+func RegistrationNonce(owner Address) uint64 {
+	raw, err := CurrentStore().Get(registrationNonceKey(owner))
+	if err != nil || len(raw) != 8 {
+		return 0
+	}
+	return binary.BigEndian.Uint64(raw)
+}
+
+// registrationTypedData returns the document of a registration request of
+// type primary with the given fields, in order, and message.
+func registrationTypedData(primary string, fields []apitypes.Type, msg apitypes.TypedDataMessage) TypedData {
+	permitMu.Lock()
+	chainID := new(big.Int).Set(permitChainID)
+	permitMu.Unlock()
+	return TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": {
+				{Name: "name", Type: "string"},
+				{Name: "version", Type: "string"},
+				{Name: "chainId", Type: "uint256"},
+				{Name: "verifyingContract", Type: "address"},
+			},
+			primary: fields,
+		},
+		PrimaryType: primary,
+		Domain: TypedDataDomain{
+			Name:              "Synnergy Webhooks",
+			Version:           "1",
+			ChainId:           (*math.HexOrDecimal256)(chainID),
+			VerifyingContract: WebhookAccount().Hex(),
+		},
+		Message: msg,
+	}
+}
+
+// spendRegistration checks that sig is owner's signature over td and that
+// nonce is owner's next registration nonce, then consumes the nonce.
+func spendRegistration(owner Address, nonce uint64, td TypedData, sig []byte) error {
+	registrationMu.Lock()
+	defer registrationMu.Unlock()
+	if want := RegistrationNonce(owner); nonce != want {
+		return fmt.Errorf("%w: got %d want %d", ErrRegistrationNonce, nonce, want)
+	}
+	ok, err := VerifyTypedData(td, sig, owner)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrRegistrationSig
+	}
+	next := make([]byte, 8)
+	binary.BigEndian.PutUint64(next, nonce+1)
+	return CurrentStore().Set(registrationNonceKey(owner), next)
+}
+
+// ValidateWebhookURL checks that raw is an http(s) URL whose host is not
+// localhost or a non-public IP literal.
+func ValidateWebhookURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return fmt.Errorf("%w: must be an http(s) URL", ErrWebhookTarget)
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf("%w: %s", ErrWebhookTarget, host)
+	}
+	if ip := net.ParseIP(host); ip != nil && !publicIP(ip) {
+		return fmt.Errorf("%w: %s is not a public address", ErrWebhookTarget, ip)
+	}
+	return nil
+}
+
+// publicIP reports whether ip is a globally routable unicast address.
+func publicIP(ip net.IP) bool {
+	return ip.IsGlobalUnicast() && !ip.IsPrivate() && !sharedAddressSpace.Contains(ip)
+}
+
+// newWebhookClient returns the client webhook calls are made with. It only
+// connects to public addresses, checked after name resolution, does not use
+// a proxy and does not follow redirects.
+func newWebhookClient() *http.Client {
+	d := &net.Dialer{
+		Timeout: 5 * time.Second,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !publicIP(ip) {
+				return fmt.Errorf("%w: %s is not a public address", ErrWebhookTarget, host)
+			}
+			return nil
+		},
+	}
+	return &http.Client{
+		Timeout:       10 * time.Second,
+		Transport:     &http.Transport{DialContext: d.DialContext, TLSHandshakeTimeout: 5 * time.Second},
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+}
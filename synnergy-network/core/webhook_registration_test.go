@@ -0,0 +1,94 @@
+package core
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func signRegistration(t *testing.T, priv ed25519.PrivateKey, td TypedData) []byte {
+	t.Helper()
+	s, err := SignTypedData(priv, td)
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	sig, _ := hex.DecodeString(s.Sig)
+	return sig
+}
+
+// hookCall is a webhook call captured by hookRecorder.
+type hookCall struct {
+	url    string
+	header http.Header
+	body   []byte
+}
+
+// hookRecorder stands in for the network of a webhook client, answering
+// every call with status.
+type hookRecorder struct {
+	mu     sync.Mutex
+	status int
+	calls  []hookCall
+}
+
+func (h *hookRecorder) RoundTrip(r *http.Request) (*http.Response, error) {
+	body, _ := io.ReadAll(r.Body)
+	h.mu.Lock()
+	h.calls = append(h.calls, hookCall{url: r.URL.String(), header: r.Header.Clone(), body: body})
+	status := h.status
+	h.mu.Unlock()
+	return &http.Response{StatusCode: status, Body: io.NopCloser(http.NoBody), Header: http.Header{}, Request: r}, nil
+}
+
+func (h *hookRecorder) client() *http.Client { return &http.Client{Transport: h} }
+
+func TestValidateWebhookURL(t *testing.T) {
+	for _, u := range []string{
+		"https://hooks.example.com/pay",
+		"http://203.0.113.7:8080/x",
+		"https://[2001:db8::1]/x",
+	} {
+		if err := ValidateWebhookURL(u); err != nil {
+			t.Errorf("%s refused: %v", u, err)
+		}
+	}
+	for _, u := range []string{
+		"ftp://hooks.example.com/",
+		"https:///nohost",
+		"http://localhost:8080/",
+		"http://api.localhost/",
+		"http://127.0.0.1/",
+		"http://[::1]/",
+		"http://10.1.2.3/",
+		"http://172.16.0.1/",
+		"http://192.168.1.1/",
+		"http://169.254.169.254/latest/meta-data",
+		"http://[fe80::1]/",
+		"http://[fd00::1]/",
+		"http://100.64.0.1/",
+		"http://0.0.0.0/",
+		"http://224.0.0.1/",
+	} {
+		if err := ValidateWebhookURL(u); !errors.Is(err, ErrWebhookTarget) {
+			t.Errorf("%s accepted: %v", u, err)
+		}
+	}
+}
+
+func TestWebhookClientRefusesPrivateAddresses(t *testing.T) {
+	reached := false
+	srv := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) { reached = true }))
+	defer srv.Close()
+	// the server listens on loopback, as a host name resolving there would
+	err := postWebhook(context.Background(), newWebhookClient(), srv.URL, "s", "ev", "t", []byte("{}"), time.Now())
+	if !errors.Is(err, ErrWebhookTarget) || reached {
+		t.Fatalf("loopback call: err=%v reached=%v", err, reached)
+	}
+}
@@ -1,23 +1,118 @@
 package core
 
 import (
+	"encoding/json"
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 )
 
-// Workflow represents a sequence of opcode names executed in order.
+// Workflow is a DAG of opcode invocations. Steps run once all of their
+// dependencies have finished and their condition holds. Actions lists the
+// opcode names in the order they were added.
 type Workflow struct {
-	ID      string
-	Actions []string
-	Trigger string
-	Webhook string
+	ID       string         `json:"id"`
+	Actions  []string       `json:"actions"`
+	Steps    []WorkflowStep `json:"steps"`
+	Trigger  string         `json:"trigger,omitempty"`
+	Webhook  string         `json:"webhook,omitempty"`
+	Secret   string         `json:"secret,omitempty"`
+	Retry    RetryPolicy    `json:"retry"`
+	GasLimit uint64         `json:"gas_limit"`
+
+	// LastHeight is the block height that last fired a height trigger.
+	LastHeight uint64 `json:"last_height,omitempty"`
+}
+
+// WorkflowStep is one opcode invocation of a workflow.
+//
+// Condition is one of
+//
+//	"" or "success"   all dependencies succeeded (default)
+//	"failure"         at least one dependency failed
+//	"always"          dependencies finished in any state
+//	"input.k==v"      run input k equals v and dependencies succeeded
+//	"input.k!=v"      run input k differs from v and dependencies succeeded
+type WorkflowStep struct {
+	ID        string       `json:"id"`
+	Op        string       `json:"op"`
+	DependsOn []string     `json:"depends_on,omitempty"`
+	Condition string       `json:"condition,omitempty"`
+	Retry     *RetryPolicy `json:"retry,omitempty"`
+}
+
+// RetryPolicy controls how often a failing step is attempted. The delay
+// doubles after each attempt up to MaxBackoff.
+type RetryPolicy struct {
+	MaxAttempts int           `json:"max_attempts"`
+	Backoff     time.Duration `json:"backoff"`
+	MaxBackoff  time.Duration `json:"max_backoff,omitempty"`
 }
 
+// WorkflowTrigger is the parsed form of Workflow.Trigger:
+//
+//	"manual"         only run on request (default)
+//	"every:<n>"      every n blocks
+//	"height:<n>"     once at block height n
+//	"event:<topic>"  whenever topic is broadcast
+//	"webhook"        on a signed inbound webhook
+type WorkflowTrigger struct {
+	Kind   string `json:"kind"`
+	Blocks uint64 `json:"blocks,omitempty"`
+	Topic  string `json:"topic,omitempty"`
+}
+
+// DefaultWorkflowGas is the gas limit of workflows created without one.
+const DefaultWorkflowGas = 1_000_000
+
 var (
 	workflows   = make(map[string]*Workflow)
 	workflowsMu sync.RWMutex
 )
 
+func workflowKey(id string) []byte { return []byte("workflow:def:" + id) }
+
+// saveWorkflow persists wf when a KV store is configured. Callers hold
+// workflowsMu.
+func saveWorkflow(wf *Workflow) error {
+	if CurrentStore() == nil {
+		return nil
+	}
+	raw, err := json.Marshal(wf)
+	if err != nil {
+		return err
+	}
+	return CurrentStore().Set(workflowKey(wf.ID), raw)
+}
+
+// ParseWorkflowTrigger validates a trigger expression.
+func ParseWorkflowTrigger(s string) (WorkflowTrigger, error) {
+	switch {
+	case s == "" || s == "manual":
+		return WorkflowTrigger{Kind: "manual"}, nil
+	case s == "webhook":
+		return WorkflowTrigger{Kind: "webhook"}, nil
+	case strings.HasPrefix(s, "event:"):
+		topic := strings.TrimPrefix(s, "event:")
+		if topic == "" {
+			return WorkflowTrigger{}, fmt.Errorf("event trigger needs a topic")
+		}
+		return WorkflowTrigger{Kind: "event", Topic: topic}, nil
+	case strings.HasPrefix(s, "every:"), strings.HasPrefix(s, "height:"):
+		kind, num, _ := strings.Cut(s, ":")
+		n, err := strconv.ParseUint(num, 10, 64)
+		if err != nil || n == 0 {
+			return WorkflowTrigger{}, fmt.Errorf("invalid block count in trigger %q", s)
+		}
+		return WorkflowTrigger{Kind: kind, Blocks: n}, nil
+	default:
+		return WorkflowTrigger{}, fmt.Errorf("unknown trigger %q", s)
+	}
+}
+
 // NewWorkflow creates a new workflow identified by id.
 func NewWorkflow(id string) (*Workflow, error) {
 	workflowsMu.Lock()
@@ -25,12 +120,16 @@ func NewWorkflow(id string) (*Workflow, error) {
 	if _, exists := workflows[id]; exists {
 		return nil, fmt.Errorf("workflow %s already exists", id)
 	}
-	wf := &Workflow{ID: id}
+	wf := &Workflow{ID: id, Retry: RetryPolicy{MaxAttempts: 1}, GasLimit: DefaultWorkflowGas}
+	if err := saveWorkflow(wf); err != nil {
+		return nil, err
+	}
 	workflows[id] = wf
 	return wf, nil
 }
 
-// AddWorkflowAction appends an opcode name to the workflow.
+// AddWorkflowAction appends an opcode name to the workflow. The new step
+// depends on the previously added one so actions run in order.
 func AddWorkflowAction(id, fn string) error {
 	workflowsMu.Lock()
 	defer workflowsMu.Unlock()
@@ -38,15 +137,58 @@ func AddWorkflowAction(id, fn string) error {
 	if !ok {
 		return fmt.Errorf("workflow %s not found", id)
 	}
-	if _, ok := nameToOp[fn]; !ok {
-		return fmt.Errorf("unknown function %s", fn)
+	step := WorkflowStep{ID: fmt.Sprintf("step%d", len(wf.Steps)+1), Op: fn}
+	if n := len(wf.Steps); n > 0 {
+		step.DependsOn = []string{wf.Steps[n-1].ID}
 	}
-	wf.Actions = append(wf.Actions, fn)
-	return nil
+	return addStep(wf, step)
+}
+
+// AddWorkflowStep adds a step to the workflow DAG. Dependencies must refer
+// to steps added earlier, which keeps the graph acyclic.
+func AddWorkflowStep(id string, step WorkflowStep) error {
+	workflowsMu.Lock()
+	defer workflowsMu.Unlock()
+	wf, ok := workflows[id]
+	if !ok {
+		return fmt.Errorf("workflow %s not found", id)
+	}
+	return addStep(wf, step)
+}
+
+func addStep(wf *Workflow, step WorkflowStep) error {
+	if step.ID == "" {
+		return fmt.Errorf("step id required")
+	}
+	if _, ok := nameToOp[step.Op]; !ok {
+		return fmt.Errorf("unknown function %s", step.Op)
+	}
+	if _, _, err := parseStepCondition(step.Condition); err != nil {
+		return err
+	}
+	known := make(map[string]bool, len(wf.Steps))
+	for _, s := range wf.Steps {
+		known[s.ID] = true
+	}
+	if known[step.ID] {
+		return fmt.Errorf("step %s already exists", step.ID)
+	}
+	for _, d := range step.DependsOn {
+		if !known[d] {
+			return fmt.Errorf("step %s depends on unknown step %s", step.ID, d)
+		}
+	}
+	wf.Steps = append(wf.Steps, step)
+	wf.Actions = append(wf.Actions, step.Op)
+	return saveWorkflow(wf)
 }
 
-// SetWorkflowTrigger sets a cron expression or event trigger for the workflow.
+// SetWorkflowTrigger sets the block, event or webhook trigger of the
+// workflow. See WorkflowTrigger for the accepted forms.
 func SetWorkflowTrigger(id, trigger string) error {
+	if _, err := ParseWorkflowTrigger(trigger); err != nil {
+		return err
+	}
 	workflowsMu.Lock()
 	defer workflowsMu.Unlock()
 	wf, ok := workflows[id]
@@ -54,7 +196,8 @@ func SetWorkflowTrigger(id, trigger string) error {
 		return fmt.Errorf("workflow %s not found", id)
 	}
 	wf.Trigger = trigger
-	return nil
+	wf.LastHeight = 0
+	return saveWorkflow(wf)
 }
 
 // SetWebhook registers a webhook URL to be called after execution.
@@ -66,25 +209,61 @@ func SetWebhook(id, url string) error {
 		return fmt.Errorf("workflow %s not found", id)
 	}
 	wf.Webhook = url
-	return nil
+	return saveWorkflow(wf)
 }
 
-// ExecuteWorkflow executes each action sequentially using the provided context.
-func ExecuteWorkflow(ctx OpContext, id string) error {
-	workflowsMu.RLock()
+// SetWorkflowSecret sets the HMAC secret inbound webhooks are signed with.
+func SetWorkflowSecret(id, secret string) error {
+	workflowsMu.Lock()
+	defer workflowsMu.Unlock()
 	wf, ok := workflows[id]
-	workflowsMu.RUnlock()
 	if !ok {
 		return fmt.Errorf("workflow %s not found", id)
 	}
-	for _, fn := range wf.Actions {
-		op, ok := nameToOp[fn]
-		if !ok {
-			return fmt.Errorf("unknown function %s", fn)
-		}
-		if err := Dispatch(ctx, op); err != nil {
-			return fmt.Errorf("execute %s: %w", fn, err)
-		}
+	wf.Secret = secret
+	return saveWorkflow(wf)
+}
+
+// SetWorkflowRetry sets the default retry policy of the workflow's steps.
+func SetWorkflowRetry(id string, p RetryPolicy) error {
+	if p.MaxAttempts < 1 {
+		return fmt.Errorf("max attempts must be >=1")
+	}
+	workflowsMu.Lock()
+	defer workflowsMu.Unlock()
+	wf, ok := workflows[id]
+	if !ok {
+		return fmt.Errorf("workflow %s not found", id)
+	}
+	wf.Retry = p
+	return saveWorkflow(wf)
+}
+
+// GetWorkflow returns a copy of the workflow definition.
+func GetWorkflow(id string) (*Workflow, error) {
+	workflowsMu.RLock()
+	defer workflowsMu.RUnlock()
+	wf, ok := workflows[id]
+	if !ok {
+		return nil, fmt.Errorf("workflow %s not found", id)
+	}
+	cp := *wf
+	cp.Actions = append([]string(nil), wf.Actions...)
+	cp.Steps = append([]WorkflowStep(nil), wf.Steps...)
+	cp.Secret = ""
+	return &cp, nil
+}
+
+// ExecuteWorkflow runs the workflow once using the provided context and
+// returns the error of the first failed step. Steps still waiting for a
+// retry are resumed by the workflow runtime.
+func ExecuteWorkflow(ctx OpContext, id string) error {
+	run, err := RunWorkflow(ctx, id, "manual", nil)
+	if err != nil {
+		return err
+	}
+	if run.Status == RunFailed {
+		return fmt.Errorf("workflow %s: %s", id, run.Error)
 	}
 	return nil
 }
@@ -97,5 +276,29 @@ func ListWorkflows() []string {
 	for id := range workflows {
 		ids = append(ids, id)
 	}
+	sort.Strings(ids)
 	return ids
 }
+
+// LoadWorkflows restores workflow definitions and runs from the KV store.
+func LoadWorkflows() error {
+	if CurrentStore() == nil {
+		return fmt.Errorf("kv store not initialised")
+	}
+	it := CurrentStore().Iterator([]byte("workflow:def:"), nil)
+	workflowsMu.Lock()
+	for it.Next() {
+		var wf Workflow
+		if err := json.Unmarshal(it.Value(), &wf); err == nil {
+			workflows[wf.ID] = &wf
+		}
+	}
+	workflowsMu.Unlock()
+	if err := it.Error(); err != nil {
+		return err
+	}
+	if err := it.Close(); err != nil {
+		return err
+	}
+	return loadWorkflowRuns()
+}
@@ -0,0 +1,510 @@
+package core
+
+// workflow_runtime.go – execution of workflow runs.
+//
+// A run records the state of every step of one workflow execution. Steps
+// become ready once their dependencies are finished; a ready step whose
+// condition does not hold is skipped. Failing steps are retried according to
+// their retry policy with exponential backoff. Runs waiting for a retry stay
+// in the running state and are resumed by the runtime loop, also after a
+// restart because runs are persisted to the KV store.
+//
+// The runtime loop fires block height triggers, Broadcast fires event
+// triggers, and WorkflowWebhook handles signed inbound webhooks. When a run
+// finishes its JSON is POSTed to the workflow's outbound webhook, if any.
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Run and step states.
+const (
+	RunRunning   = "running"
+	RunSucceeded = "succeeded"
+	RunFailed    = "failed"
+
+	StepPending   = "pending"
+	StepRetrying  = "retrying"
+	StepSucceeded = "succeeded"
+	StepFailed    = "failed"
+	StepSkipped   = "skipped"
+)
+
+// WorkflowRun is one execution of a workflow.
+type WorkflowRun struct {
+	ID       string                 `json:"id"`
+	Workflow string                 `json:"workflow"`
+	Trigger  string                 `json:"trigger"`
+	Input    map[string]string      `json:"input,omitempty"`
+	Status   string                 `json:"status"`
+	Steps    map[string]*StepResult `json:"steps"`
+	Error    string                 `json:"error,omitempty"`
+	Started  time.Time              `json:"started"`
+	Finished time.Time              `json:"finished,omitempty"`
+}
+
+// StepResult tracks one step of a run.
+type StepResult struct {
+	Status      string    `json:"status"`
+	Attempts    int       `json:"attempts"`
+	LastError   string    `json:"last_error,omitempty"`
+	NextAttempt time.Time `json:"next_attempt,omitempty"`
+	Finished    time.Time `json:"finished,omitempty"`
+}
+
+var (
+	workflowRuns = make(map[string]*WorkflowRun)
+	// workflowRunsMu guards the run index and serialises step execution.
+	workflowRunsMu sync.Mutex
+)
+
+func workflowRunPrefix(wf string) []byte { return []byte("workflow:run:" + wf + ":") }
+func workflowRunKey(r *WorkflowRun) []byte {
+	return append(workflowRunPrefix(r.Workflow), r.ID...)
+}
+
+func saveWorkflowRun(r *WorkflowRun) {
+	if CurrentStore() == nil {
+		return
+	}
+	raw, _ := json.Marshal(r)
+	if err := CurrentStore().Set(workflowRunKey(r), raw); err != nil {
+		logrus.Warnf("workflow: persist run %s: %v", r.ID, err)
+	}
+}
+
+func loadWorkflowRuns() error {
+	it := CurrentStore().Iterator([]byte("workflow:run:"), nil)
+	workflowRunsMu.Lock()
+	for it.Next() {
+		var r WorkflowRun
+		if err := json.Unmarshal(it.Value(), &r); err == nil {
+			workflowRuns[r.ID] = &r
+		}
+	}
+	workflowRunsMu.Unlock()
+	if err := it.Error(); err != nil {
+		return err
+	}
+	return it.Close()
+}
+
+// parseStepCondition splits a condition into kind and, for input
+// conditions, the key and expected value.
+func parseStepCondition(c string) (string, [2]string, error) {
+	switch c {
+	case "", "success":
+		return "success", [2]string{}, nil
+	case "failure", "always":
+		return c, [2]string{}, nil
+	}
+	if !strings.HasPrefix(c, "input.") {
+		return "", [2]string{}, fmt.Errorf("unknown condition %q", c)
+	}
+	expr := strings.TrimPrefix(c, "input.")
+	if k, v, ok := strings.Cut(expr, "!="); ok && k != "" {
+		return "ne", [2]string{k, v}, nil
+	}
+	if k, v, ok := strings.Cut(expr, "=="); ok && k != "" {
+		return "eq", [2]string{k, v}, nil
+	}
+	return "", [2]string{}, fmt.Errorf("unknown condition %q", c)
+}
+
+// stepReady reports whether all dependencies of s are finished and, if so,
+// whether its condition holds.
+func stepReady(s WorkflowStep, run *WorkflowRun) (ready, ok bool) {
+	anyFailed, allOK := false, true
+	for _, d := range s.DependsOn {
+		switch run.Steps[d].Status {
+		case StepSucceeded:
+		case StepFailed:
+			anyFailed, allOK = true, false
+		case StepSkipped:
+			allOK = false
+		default:
+			return false, false
+		}
+	}
+	kind, kv, _ := parseStepCondition(s.Condition)
+	switch kind {
+	case "always":
+		return true, true
+	case "failure":
+		return true, anyFailed
+	case "eq":
+		return true, allOK && run.Input[kv[0]] == kv[1]
+	case "ne":
+		return true, allOK && run.Input[kv[0]] != kv[1]
+	default:
+		return true, allOK
+	}
+}
+
+func retryDelay(p RetryPolicy, attempts int) time.Duration {
+	d := p.Backoff
+	for i := 1; i < attempts && d > 0; i++ {
+		d *= 2
+		if p.MaxBackoff > 0 && d >= p.MaxBackoff {
+			return p.MaxBackoff
+		}
+	}
+	return d
+}
+
+// advanceRun executes every step that is ready at now and updates the run
+// status. It returns true when the run reached a final state.
+func advanceRun(ctx OpContext, wf *Workflow, run *WorkflowRun, now time.Time) bool {
+	workflowRunsMu.Lock()
+	defer workflowRunsMu.Unlock()
+	for progressed := true; progressed; {
+		progressed = false
+		for _, s := range wf.Steps {
+			res := run.Steps[s.ID]
+			if res.Status != StepPending && res.Status != StepRetrying {
+				continue
+			}
+			ready, exec := stepReady(s, run)
+			if !ready {
+				continue
+			}
+			if !exec {
+				res.Status, res.Finished = StepSkipped, now
+				progressed = true
+				continue
+			}
+			if now.Before(res.NextAttempt) {
+				continue
+			}
+			res.Attempts++
+			err := dispatchWorkflowStep(ctx, s.Op)
+			if err == nil {
+				res.Status, res.LastError, res.Finished = StepSucceeded, "", now
+				progressed = true
+				continue
+			}
+			res.LastError = err.Error()
+			policy := wf.Retry
+			if s.Retry != nil {
+				policy = *s.Retry
+			}
+			if res.Attempts < policy.MaxAttempts {
+				res.Status = StepRetrying
+				res.NextAttempt = now.Add(retryDelay(policy, res.Attempts))
+				continue
+			}
+			res.Status, res.Finished = StepFailed, now
+			progressed = true
+		}
+	}
+	status := RunSucceeded
+	var failed []string
+	for _, s := range wf.Steps {
+		switch run.Steps[s.ID].Status {
+		case StepPending, StepRetrying:
+			status = RunRunning
+		case StepFailed:
+			failed = append(failed, s.ID+": "+run.Steps[s.ID].LastError)
+		}
+	}
+	if status != RunRunning && len(failed) > 0 {
+		status = RunFailed
+		run.Error = strings.Join(failed, "; ")
+	}
+	run.Status = status
+	if status != RunRunning {
+		run.Finished = now
+	}
+	saveWorkflowRun(run)
+	return status != RunRunning
+}
+
+func dispatchWorkflowStep(ctx OpContext, name string) error {
+	op, ok := nameToOp[name]
+	if !ok {
+		return fmt.Errorf("unknown function %s", name)
+	}
+	return Dispatch(ctx, op)
+}
+
+// RunWorkflow starts a run of workflow id and executes every step that is
+// ready. A nil ctx runs the workflow with a fresh context holding its gas
+// limit.
+func RunWorkflow(ctx OpContext, id, trigger string, input map[string]string) (*WorkflowRun, error) {
+	wf, err := GetWorkflow(id)
+	if err != nil {
+		return nil, err
+	}
+	if len(wf.Steps) == 0 {
+		return nil, fmt.Errorf("workflow %s has no steps", id)
+	}
+	if ctx == nil {
+		ctx = &Context{GasLimit: wf.GasLimit}
+	}
+	now := time.Now().UTC()
+	run := &WorkflowRun{
+		ID:       fmt.Sprintf("%s-%d", id, now.UnixNano()),
+		Workflow: id,
+		Trigger:  trigger,
+		Input:    input,
+		Status:   RunRunning,
+		Steps:    make(map[string]*StepResult, len(wf.Steps)),
+		Started:  now,
+	}
+	for _, s := range wf.Steps {
+		run.Steps[s.ID] = &StepResult{Status: StepPending}
+	}
+	workflowRunsMu.Lock()
+	workflowRuns[run.ID] = run
+	workflowRunsMu.Unlock()
+	return finishAdvance(ctx, wf, run, now), nil
+}
+
+// ResumeWorkflowRun executes the steps of a running run whose retry delay
+// has passed.
+func ResumeWorkflowRun(ctx OpContext, runID string) (*WorkflowRun, error) {
+	workflowRunsMu.Lock()
+	run, ok := workflowRuns[runID]
+	var status string
+	if ok {
+		status = run.Status
+	}
+	workflowRunsMu.Unlock()
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if status != RunRunning {
+		return nil, fmt.Errorf("run %s already %s", runID, status)
+	}
+	wf, err := GetWorkflow(run.Workflow)
+	if err != nil {
+		return nil, err
+	}
+	if ctx == nil {
+		ctx = &Context{GasLimit: wf.GasLimit}
+	}
+	return finishAdvance(ctx, wf, run, time.Now().UTC()), nil
+}
+
+// finishAdvance advances run, notifies the webhook once it is final and
+// returns a snapshot.
+func finishAdvance(ctx OpContext, wf *Workflow, run *WorkflowRun, now time.Time) *WorkflowRun {
+	done := advanceRun(ctx, wf, run, now)
+	workflowRunsMu.Lock()
+	snap := copyRun(run)
+	workflowRunsMu.Unlock()
+	if done {
+		notifyWorkflowWebhook(wf, snap)
+	}
+	return snap
+}
+
+func copyRun(r *WorkflowRun) *WorkflowRun {
+	raw, _ := json.Marshal(r)
+	var cp WorkflowRun
+	_ = json.Unmarshal(raw, &cp)
+	return &cp
+}
+
+// GetWorkflowRun returns a run by ID.
+func GetWorkflowRun(runID string) (*WorkflowRun, error) {
+	workflowRunsMu.Lock()
+	defer workflowRunsMu.Unlock()
+	r, ok := workflowRuns[runID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return copyRun(r), nil
+}
+
+// ListWorkflowRuns returns the runs of a workflow, newest first.
+func ListWorkflowRuns(id string) []*WorkflowRun {
+	workflowRunsMu.Lock()
+	defer workflowRunsMu.Unlock()
+	var out []*WorkflowRun
+	for _, r := range workflowRuns {
+		if r.Workflow == id {
+			out = append(out, copyRun(r))
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Started.After(out[j].Started) })
+	return out
+}
+
+// WorkflowWebhookSignature returns the hex HMAC-SHA256 of body under secret
+// as expected by WorkflowWebhook.
+func WorkflowWebhookSignature(secret string, body []byte) string {
+	m := hmac.New(sha256.New, []byte(secret))
+	m.Write(body)
+	return hex.EncodeToString(m.Sum(nil))
+}
+
+// WorkflowWebhook starts a run of a webhook-triggered workflow. body must
+// be signed with the workflow secret; a JSON object body becomes the run
+// input.
+func WorkflowWebhook(id string, body []byte, sig string) (*WorkflowRun, error) {
+	workflowsMu.RLock()
+	wf, ok := workflows[id]
+	var trigger, secret string
+	if ok {
+		trigger, secret = wf.Trigger, wf.Secret
+	}
+	workflowsMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("workflow %s not found", id)
+	}
+	if trigger != "webhook" {
+		return nil, errors.New("workflow is not webhook triggered")
+	}
+	if secret == "" {
+		return nil, errors.New("webhook secret not configured")
+	}
+	want := WorkflowWebhookSignature(secret, body)
+	if !hmac.Equal([]byte(want), []byte(strings.TrimPrefix(sig, "sha256="))) {
+		return nil, ErrUnauthorized
+	}
+	var input map[string]string
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &input); err != nil {
+			input = map[string]string{"body": string(body)}
+		}
+	}
+	return RunWorkflow(nil, id, "webhook", input)
+}
+
+// notifyWorkflowEvent starts runs of the workflows triggered by topic.
+func notifyWorkflowEvent(topic string, data []byte) {
+	want := "event:" + topic
+	var ids []string
+	workflowsMu.RLock()
+	for id, wf := range workflows {
+		if wf.Trigger == want {
+			ids = append(ids, id)
+		}
+	}
+	workflowsMu.RUnlock()
+	if len(ids) == 0 {
+		return
+	}
+	input := map[string]string{"topic": topic, "data": hex.EncodeToString(data)}
+	go func() {
+		for _, id := range ids {
+			if _, err := RunWorkflow(nil, id, "event", input); err != nil {
+				logrus.Warnf("workflow %s: event %s: %v", id, topic, err)
+			}
+		}
+	}()
+}
+
+// fireHeightTriggers starts the workflows whose block trigger is due at
+// height.
+func fireHeightTriggers(height uint64) {
+	var due []string
+	workflowsMu.Lock()
+	for id, wf := range workflows {
+		t, err := ParseWorkflowTrigger(wf.Trigger)
+		if err != nil {
+			continue
+		}
+		fire := false
+		switch t.Kind {
+		case "every":
+			fire = height/t.Blocks > wf.LastHeight/t.Blocks
+		case "height":
+			fire = height >= t.Blocks && wf.LastHeight < t.Blocks
+		}
+		if fire {
+			wf.LastHeight = height
+			_ = saveWorkflow(wf)
+			due = append(due, id)
+		}
+	}
+	workflowsMu.Unlock()
+	for _, id := range due {
+		input := map[string]string{"height": fmt.Sprint(height)}
+		if _, err := RunWorkflow(nil, id, "height", input); err != nil {
+			logrus.Warnf("workflow %s: height %d: %v", id, height, err)
+		}
+	}
+}
+
+// resumeDueRuns advances every running run with a retry due at now.
+func resumeDueRuns(now time.Time) {
+	var due []string
+	workflowRunsMu.Lock()
+	for id, r := range workflowRuns {
+		if r.Status != RunRunning {
+			continue
+		}
+		for _, s := range r.Steps {
+			if s.Status == StepRetrying && !now.Before(s.NextAttempt) {
+				due = append(due, id)
+				break
+			}
+		}
+	}
+	workflowRunsMu.Unlock()
+	for _, id := range due {
+		if _, err := ResumeWorkflowRun(nil, id); err != nil {
+			logrus.Warnf("workflow run %s: %v", id, err)
+		}
+	}
+}
+
+// StartWorkflowRuntime fires block height triggers and resumes runs waiting
+// for a retry every interval until ctx is cancelled.
+func StartWorkflowRuntime(ctx context.Context, interval time.Duration) {
+	go func() {
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-t.C:
+				if led := CurrentLedger(); led != nil {
+					fireHeightTriggers(led.LastHeight())
+				}
+				resumeDueRuns(now.UTC())
+			}
+		}
+	}()
+}
+
+// notifyWorkflowWebhook POSTs a finished run to the workflow webhook.
+func notifyWorkflowWebhook(wf *Workflow, run *WorkflowRun) {
+	if wf.Webhook == "" {
+		return
+	}
+	raw, _ := json.Marshal(run)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, wf.Webhook, bytes.NewReader(raw))
+		if err != nil {
+			logrus.Warnf("workflow %s: webhook: %v", wf.ID, err)
+			return
+		}
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			logrus.Warnf("workflow %s: webhook: %v", wf.ID, err)
+			return
+		}
+		resp.Body.Close()
+	}()
+}
@@ -0,0 +1,201 @@
+package core
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// scriptedOps is an OpContext whose calls fail a set number of times.
+type scriptedOps struct {
+	fails map[string]int
+	calls []string
+}
+
+func (s *scriptedOps) Call(name string) error {
+	s.calls = append(s.calls, name)
+	if s.fails[name] > 0 {
+		s.fails[name]--
+		return errors.New(name + " failed")
+	}
+	return nil
+}
+
+func (s *scriptedOps) Gas(uint64) error { return nil }
+
+func newTestWorkflow(t *testing.T, id string, steps ...WorkflowStep) {
+	t.Helper()
+	SetStore(NewInMemoryStore())
+	t.Cleanup(func() {
+		workflowsMu.Lock()
+		delete(workflows, id)
+		workflowsMu.Unlock()
+		workflowRunsMu.Lock()
+		for rid, r := range workflowRuns {
+			if r.Workflow == id {
+				delete(workflowRuns, rid)
+			}
+		}
+		workflowRunsMu.Unlock()
+		SetStore(nil)
+	})
+	if _, err := NewWorkflow(id); err != nil {
+		t.Fatalf("new workflow: %v", err)
+	}
+	for _, s := range steps {
+		if err := AddWorkflowStep(id, s); err != nil {
+			t.Fatalf("add step %s: %v", s.ID, err)
+		}
+	}
+}
+
+func TestWorkflowRunFollowsDAGConditions(t *testing.T) {
+	newTestWorkflow(t, "wf-dag",
+		WorkflowStep{ID: "fetch", Op: "GetInferenceRequest"},
+		WorkflowStep{ID: "pay", Op: "FinalizeInference", DependsOn: []string{"fetch"}},
+		WorkflowStep{ID: "dispute", Op: "DisputeInference", DependsOn: []string{"fetch"}, Condition: "failure"},
+		WorkflowStep{ID: "fast", Op: "RequestInference", DependsOn: []string{"fetch"}, Condition: "input.mode==fast"},
+		WorkflowStep{ID: "report", Op: "DriftReportFor", DependsOn: []string{"pay", "dispute"}, Condition: "always"},
+	)
+	ops := &scriptedOps{}
+	run, err := RunWorkflow(ops, "wf-dag", "manual", map[string]string{"mode": "slow"})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	want := map[string]string{
+		"fetch": StepSucceeded, "pay": StepSucceeded, "dispute": StepSkipped,
+		"fast": StepSkipped, "report": StepSucceeded,
+	}
+	for id, st := range want {
+		if got := run.Steps[id].Status; got != st {
+			t.Fatalf("step %s = %s, want %s", id, got, st)
+		}
+	}
+	if run.Status != RunSucceeded || len(ops.calls) != 3 || ops.calls[0] != "GetInferenceRequest" {
+		t.Fatalf("run %s calls %v", run.Status, ops.calls)
+	}
+
+	// a failed dependency takes the failure branch and fails the run
+	ops = &scriptedOps{fails: map[string]int{"GetInferenceRequest": 1}}
+	run, _ = RunWorkflow(ops, "wf-dag", "manual", map[string]string{"mode": "fast"})
+	if run.Steps["pay"].Status != StepSkipped || run.Steps["dispute"].Status != StepSucceeded ||
+		run.Steps["fast"].Status != StepSkipped || run.Steps["report"].Status != StepSucceeded {
+		t.Fatalf("failure branch: %+v", run.Steps)
+	}
+	if run.Status != RunFailed || run.Error == "" {
+		t.Fatalf("run %s error %q", run.Status, run.Error)
+	}
+}
+
+func TestWorkflowStepRetriesWithBackoff(t *testing.T) {
+	p := RetryPolicy{MaxAttempts: 4, Backoff: time.Second, MaxBackoff: 3 * time.Second}
+	for attempts, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 3 * time.Second} {
+		if got := retryDelay(p, attempts); got != want {
+			t.Fatalf("delay after %d attempts = %v, want %v", attempts, got, want)
+		}
+	}
+
+	newTestWorkflow(t, "wf-retry", WorkflowStep{ID: "s", Op: "FinalizeInference", Retry: &p})
+	ops := &scriptedOps{fails: map[string]int{"FinalizeInference": 2}}
+	snap, err := RunWorkflow(ops, "wf-retry", "manual", nil)
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if snap.Status != RunRunning || snap.Steps["s"].Status != StepRetrying || snap.Steps["s"].Attempts != 1 {
+		t.Fatalf("after first attempt: %s %+v", snap.Status, snap.Steps["s"])
+	}
+	if snap, _ = ResumeWorkflowRun(ops, snap.ID); snap.Steps["s"].Attempts != 1 {
+		t.Fatal("step retried before its backoff elapsed")
+	}
+
+	wf, _ := GetWorkflow("wf-retry")
+	workflowRunsMu.Lock()
+	run := workflowRuns[snap.ID]
+	start := run.Steps["s"].NextAttempt
+	workflowRunsMu.Unlock()
+	if advanceRun(ops, wf, run, start) {
+		t.Fatal("run finished while the step still fails")
+	}
+	if run.Steps["s"].NextAttempt != start.Add(2*time.Second) {
+		t.Fatalf("second backoff: next attempt %v after %v", run.Steps["s"].NextAttempt, start)
+	}
+	if !advanceRun(ops, wf, run, start.Add(2*time.Second)) || run.Status != RunSucceeded || run.Steps["s"].Attempts != 3 {
+		t.Fatalf("third attempt: %s %+v", run.Status, run.Steps["s"])
+	}
+
+	// runs survive a restart through the store
+	workflowRunsMu.Lock()
+	delete(workflowRuns, snap.ID)
+	workflowRunsMu.Unlock()
+	if err := loadWorkflowRuns(); err != nil {
+		t.Fatalf("load runs: %v", err)
+	}
+	if got, err := GetWorkflowRun(snap.ID); err != nil || got.Status != RunSucceeded {
+		t.Fatalf("reloaded run %+v err %v", got, err)
+	}
+}
+
+func TestWorkflowRetryExhaustionFailsRun(t *testing.T) {
+	newTestWorkflow(t, "wf-exhaust", WorkflowStep{ID: "s", Op: "FinalizeInference"})
+	if err := SetWorkflowRetry("wf-exhaust", RetryPolicy{MaxAttempts: 2}); err != nil {
+		t.Fatal(err)
+	}
+	ops := &scriptedOps{fails: map[string]int{"FinalizeInference": 5}}
+	run, _ := RunWorkflow(ops, "wf-exhaust", "manual", nil)
+	if run.Status != RunRunning {
+		t.Fatalf("run %s after first failure", run.Status)
+	}
+	run, _ = ResumeWorkflowRun(ops, run.ID)
+	if run.Status != RunFailed || run.Steps["s"].Attempts != 2 || len(ops.calls) != 2 {
+		t.Fatalf("run %s step %+v calls %d", run.Status, run.Steps["s"], len(ops.calls))
+	}
+}
+
+func TestWorkflowWebhookRequiresSignature(t *testing.T) {
+	newTestWorkflow(t, "wf-hook", WorkflowStep{ID: "s", Op: "DriftReportFor"})
+	body := []byte(`{"order":"42"}`)
+	if _, err := WorkflowWebhook("wf-hook", body, ""); err == nil {
+		t.Fatal("manual workflow started by webhook")
+	}
+	if err := SetWorkflowTrigger("wf-hook", "webhook"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := WorkflowWebhook("wf-hook", body, "sha256=00"); err == nil {
+		t.Fatal("webhook accepted without a configured secret")
+	}
+	if err := SetWorkflowSecret("wf-hook", "s3cret"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := WorkflowWebhook("wf-hook", body, WorkflowWebhookSignature("other", body)); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("wrong secret: err=%v", err)
+	}
+	run, err := WorkflowWebhook("wf-hook", body, "sha256="+WorkflowWebhookSignature("s3cret", body))
+	if err != nil {
+		t.Fatalf("signed webhook: %v", err)
+	}
+	if run.Trigger != "webhook" || run.Input["order"] != "42" {
+		t.Fatalf("run %+v", run)
+	}
+}
+
+func TestWorkflowHeightTriggers(t *testing.T) {
+	newTestWorkflow(t, "wf-every", WorkflowStep{ID: "s", Op: "DriftReportFor"})
+	if err := SetWorkflowTrigger("wf-every", "every:10"); err != nil {
+		t.Fatal(err)
+	}
+	for _, h := range []uint64{5, 10, 15, 19, 20, 20} {
+		fireHeightTriggers(h)
+	}
+	runs := ListWorkflowRuns("wf-every")
+	if len(runs) != 2 {
+		t.Fatalf("every:10 over heights 5..20 fired %d runs, want 2", len(runs))
+	}
+	for _, r := range runs {
+		if r.Trigger != "height" || (r.Input["height"] != "10" && r.Input["height"] != "20") {
+			t.Fatalf("run %+v", r)
+		}
+	}
+	if _, err := ParseWorkflowTrigger("every:0"); err == nil {
+		t.Fatal("every:0 accepted")
+	}
+}
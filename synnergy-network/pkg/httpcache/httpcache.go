@@ -0,0 +1,245 @@
+// Package httpcache adds HTTP caching to JSON read APIs. Handlers declare
+// how long their response stays valid with Immutable, MaxAge, Revalidate or
+// NoStore; the Cache middleware gives every GET response a strong ETag,
+// answers conditional requests with 304 Not Modified and keeps recent
+// responses in memory so repeated reads skip the ledger:
+//
+//	immutable       finalised blocks, transactions and receipts; kept until
+//	                evicted
+//	revalidate      head data such as the latest blocks or balances; kept
+//	                only while Config.Version reports the same chain head
+//	no-store        mempool and per-user data; never kept
+//
+// See Version for the module's semantic version.
+package httpcache
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ETag returns the strong entity tag of body.
+func ETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// NotModified reports whether the If-None-Match header of r matches etag,
+// using the weak comparison RFC 9110 prescribes for that header.
+func NotModified(r *http.Request, etag string) bool {
+	inm := r.Header.Get("If-None-Match")
+	if inm == "" {
+		return false
+	}
+	for _, t := range strings.Split(inm, ",") {
+		t = strings.TrimSpace(t)
+		if t == "*" || strings.TrimPrefix(t, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// Immutable marks the response as never changing.
+func Immutable(w http.ResponseWriter) {
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+}
+
+// MaxAge lets clients reuse the response for d without asking again.
+func MaxAge(w http.ResponseWriter, d time.Duration) {
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(d.Seconds())))
+}
+
+// Revalidate requires clients to check the ETag before reusing the
+// response. It is the default for responses that set no policy.
+func Revalidate(w http.ResponseWriter) {
+	w.Header().Set("Cache-Control", "no-cache")
+}
+
+// NoStore forbids caching the response anywhere.
+func NoStore(w http.ResponseWriter) {
+	w.Header().Set("Cache-Control", "no-store")
+}
+
+// Config tunes a Cache. Zero fields take the defaults.
+type Config struct {
+	MaxEntries   int // responses kept in memory, default 1024
+	MaxBodyBytes int // larger responses are not kept, default 256 KiB
+	// Version returns the current chain head. When set, responses that are
+	// not immutable are kept until it changes; otherwise only immutable
+	// responses are kept.
+	Version func() uint64
+}
+
+// Cache is the caching middleware of an HTTP API. It is safe for
+// concurrent use.
+type Cache struct {
+	cfg Config
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+}
+
+type entry struct {
+	key       string
+	header    http.Header
+	body      []byte
+	immutable bool
+	version   uint64
+}
+
+// New creates a cache.
+func New(cfg Config) *Cache {
+	if cfg.MaxEntries <= 0 {
+		cfg.MaxEntries = 1024
+	}
+	if cfg.MaxBodyBytes <= 0 {
+		cfg.MaxBodyBytes = 256 << 10
+	}
+	return &Cache{cfg: cfg, entries: make(map[string]*list.Element), lru: list.New()}
+}
+
+// Len returns the number of responses held.
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+// Purge drops every held response, e.g. after a reorganisation below the
+// finality depth.
+func (c *Cache) Purge() {
+	c.mu.Lock()
+	c.entries = make(map[string]*list.Element)
+	c.lru.Init()
+	c.mu.Unlock()
+}
+
+// Middleware serves GET requests from the cache when possible and records
+// successful responses otherwise. Other methods pass through untouched.
+func (c *Cache) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			next.ServeHTTP(w, r)
+			return
+		}
+		key := r.URL.RequestURI()
+		if e := c.get(key); e != nil {
+			c.write(w, r, e.header, e.body)
+			return
+		}
+		var ver uint64
+		if c.cfg.Version != nil {
+			ver = c.cfg.Version()
+		}
+		rec := &recorder{header: http.Header{}}
+		next.ServeHTTP(rec, r)
+		if rec.status == 0 {
+			rec.status = http.StatusOK
+		}
+		if rec.status != http.StatusOK {
+			copyHeader(w.Header(), rec.header)
+			w.WriteHeader(rec.status)
+			_, _ = w.Write(rec.body.Bytes())
+			return
+		}
+		h := rec.header
+		if h.Get("ETag") == "" {
+			h.Set("ETag", ETag(rec.body.Bytes()))
+		}
+		if h.Get("Cache-Control") == "" {
+			h.Set("Cache-Control", "no-cache")
+		}
+		c.put(key, h, rec.body.Bytes(), ver)
+		c.write(w, r, h, rec.body.Bytes())
+	})
+}
+
+func (c *Cache) write(w http.ResponseWriter, r *http.Request, h http.Header, body []byte) {
+	copyHeader(w.Header(), h)
+	if NotModified(r, h.Get("ETag")) {
+		w.Header().Del("Content-Length")
+		w.Header().Del("Content-Type")
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	_, _ = w.Write(body)
+}
+
+func (c *Cache) get(key string) *entry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		return nil
+	}
+	e := el.Value.(*entry)
+	if !e.immutable && (c.cfg.Version == nil || c.cfg.Version() != e.version) {
+		c.lru.Remove(el)
+		delete(c.entries, key)
+		return nil
+	}
+	c.lru.MoveToFront(el)
+	return e
+}
+
+// put keeps a response; ver is the chain head observed before the handler
+// ran, so a head change during the request invalidates the entry.
+func (c *Cache) put(key string, h http.Header, body []byte, ver uint64) {
+	cc := h.Get("Cache-Control")
+	immutable := strings.Contains(cc, "immutable")
+	switch {
+	case len(body) > c.cfg.MaxBodyBytes, strings.Contains(cc, "no-store"), strings.Contains(cc, "private"):
+		return
+	case !immutable && c.cfg.Version == nil:
+		return
+	}
+	e := &entry{key: key, header: h.Clone(), body: append([]byte(nil), body...), immutable: immutable, version: ver}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[key]; ok {
+		c.lru.Remove(el)
+	}
+	c.entries[key] = c.lru.PushFront(e)
+	for c.lru.Len() > c.cfg.MaxEntries {
+		old := c.lru.Back()
+		c.lru.Remove(old)
+		delete(c.entries, old.Value.(*entry).key)
+	}
+}
+
+func copyHeader(dst, src http.Header) {
+	for k, v := range src {
+		dst[k] = v
+	}
+}
+
+// recorder buffers a handler's response.
+type recorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (r *recorder) Header() http.Header { return r.header }
+
+func (r *recorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+}
+
+func (r *recorder) Write(p []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.body.Write(p)
+}
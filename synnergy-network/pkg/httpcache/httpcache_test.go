@@ -0,0 +1,207 @@
+package httpcache
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// testAPI serves the path as body under the policy named by its first
+// segment and counts the requests that reach it.
+type testAPI struct {
+	calls int32
+	// during runs inside the handler, e.g. to move the head mid-request
+	during func()
+}
+
+func (a *testAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	atomic.AddInt32(&a.calls, 1)
+	if a.during != nil {
+		a.during()
+	}
+	switch strings.Split(strings.TrimPrefix(r.URL.Path, "/"), "/")[0] {
+	case "immutable":
+		Immutable(w)
+	case "nostore":
+		NoStore(w)
+	case "private":
+		w.Header().Set("Cache-Control", "private, max-age=60")
+	case "missing":
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write([]byte(`"` + r.URL.Path + `"`))
+}
+
+func get(t *testing.T, h http.Handler, path, inm string) *httptest.ResponseRecorder {
+	t.Helper()
+	r := httptest.NewRequest(http.MethodGet, path, nil)
+	if inm != "" {
+		r.Header.Set("If-None-Match", inm)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestNotModified(t *testing.T) {
+	etag := ETag([]byte("body"))
+	cases := []struct {
+		inm  string
+		want bool
+	}{
+		{"", false},
+		{etag, true},
+		{"W/" + etag, true},
+		{`"other", ` + etag, true},
+		{"*", true},
+		{`"other"`, false},
+	}
+	for _, c := range cases {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		if c.inm != "" {
+			r.Header.Set("If-None-Match", c.inm)
+		}
+		if got := NotModified(r, etag); got != c.want {
+			t.Errorf("If-None-Match %q: got %v, want %v", c.inm, got, c.want)
+		}
+	}
+}
+
+func TestMiddlewareConditionalRequests(t *testing.T) {
+	api := &testAPI{}
+	h := New(Config{}).Middleware(api)
+
+	w := get(t, h, "/head", "")
+	etag := w.Header().Get("ETag")
+	if w.Code != http.StatusOK || etag != ETag([]byte(`"/head"`)) {
+		t.Fatalf("first read: %d etag %q", w.Code, etag)
+	}
+	if cc := w.Header().Get("Cache-Control"); cc != "no-cache" {
+		t.Fatalf("default policy %q, want no-cache", cc)
+	}
+	w = get(t, h, "/head", etag)
+	if w.Code != http.StatusNotModified || w.Body.Len() != 0 || w.Header().Get("Content-Type") != "" {
+		t.Fatalf("conditional read: %d %q content-type %q", w.Code, w.Body.String(), w.Header().Get("Content-Type"))
+	}
+	if w = get(t, h, "/head", `"stale"`); w.Code != http.StatusOK || w.Body.String() != `"/head"` {
+		t.Fatalf("stale tag: %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestMiddlewareHoldsByPolicy(t *testing.T) {
+	var head uint64 = 1
+	api := &testAPI{}
+	c := New(Config{Version: func() uint64 { return atomic.LoadUint64(&head) }})
+	h := c.Middleware(api)
+
+	for _, p := range []string{"/immutable/1", "/head", "/nostore", "/private", "/missing"} {
+		get(t, h, p, "")
+		get(t, h, p, "")
+	}
+	// immutable and head responses are served once; the others twice
+	if n := atomic.LoadInt32(&api.calls); n != 8 {
+		t.Fatalf("handler ran %d times, want 8", n)
+	}
+	if c.Len() != 2 {
+		t.Fatalf("held %d responses, want 2", c.Len())
+	}
+	if w := get(t, h, "/missing", ""); w.Code != http.StatusNotFound {
+		t.Fatalf("error response rewritten to %d", w.Code)
+	}
+
+	atomic.StoreUint64(&head, 2)
+	atomic.StoreInt32(&api.calls, 0)
+	get(t, h, "/immutable/1", "")
+	get(t, h, "/head", "")
+	if n := atomic.LoadInt32(&api.calls); n != 1 {
+		t.Fatalf("after a new head the handler ran %d times, want 1 (head only)", n)
+	}
+}
+
+func TestMiddlewareWithoutVersionHoldsOnlyImmutable(t *testing.T) {
+	api := &testAPI{}
+	c := New(Config{})
+	h := c.Middleware(api)
+	for i := 0; i < 3; i++ {
+		get(t, h, "/immutable/1", "")
+		get(t, h, "/head", "")
+	}
+	if n := atomic.LoadInt32(&api.calls); n != 4 {
+		t.Fatalf("handler ran %d times, want 4", n)
+	}
+	if c.Len() != 1 {
+		t.Fatalf("held %d responses, want 1", c.Len())
+	}
+}
+
+func TestMiddlewareHeadChangeDuringRequest(t *testing.T) {
+	var head uint64 = 1
+	api := &testAPI{}
+	api.during = func() { atomic.AddUint64(&head, 1) }
+	h := New(Config{Version: func() uint64 { return atomic.LoadUint64(&head) }}).Middleware(api)
+
+	get(t, h, "/head", "")
+	api.during = nil
+	get(t, h, "/head", "")
+	// the first response was computed while the head moved and must not be
+	// served for the new head
+	if n := atomic.LoadInt32(&api.calls); n != 2 {
+		t.Fatalf("handler ran %d times, want 2", n)
+	}
+}
+
+func TestMiddlewareEvictionAndLimits(t *testing.T) {
+	api := &testAPI{}
+	c := New(Config{MaxEntries: 2, MaxBodyBytes: 16})
+	h := c.Middleware(api)
+
+	get(t, h, "/immutable/1", "")
+	get(t, h, "/immutable/2", "")
+	get(t, h, "/immutable/1", "") // refresh 1 so 2 is the oldest
+	get(t, h, "/immutable/3", "")
+	if c.Len() != 2 {
+		t.Fatalf("held %d responses, want 2", c.Len())
+	}
+	atomic.StoreInt32(&api.calls, 0)
+	get(t, h, "/immutable/1", "")
+	get(t, h, "/immutable/3", "")
+	if n := atomic.LoadInt32(&api.calls); n != 0 {
+		t.Fatalf("recent responses were evicted (%d handler runs)", n)
+	}
+	get(t, h, "/immutable/2", "")
+	if n := atomic.LoadInt32(&api.calls); n != 1 {
+		t.Fatalf("least recently used response still held")
+	}
+
+	atomic.StoreInt32(&api.calls, 0)
+	get(t, h, "/immutable/long-path-body", "")
+	get(t, h, "/immutable/long-path-body", "")
+	if n := atomic.LoadInt32(&api.calls); n != 2 {
+		t.Fatalf("body above MaxBodyBytes was held")
+	}
+
+	c.Purge()
+	if c.Len() != 0 {
+		t.Fatalf("Purge left %d responses", c.Len())
+	}
+}
+
+func TestMiddlewarePassesOtherMethods(t *testing.T) {
+	api := &testAPI{}
+	c := New(Config{})
+	h := c.Middleware(api)
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/immutable/1", nil))
+		if w.Header().Get("ETag") != "" {
+			t.Fatalf("POST response got an ETag")
+		}
+	}
+	if n := atomic.LoadInt32(&api.calls); n != 2 || c.Len() != 0 {
+		t.Fatalf("POST cached: %d handler runs, %d held", n, c.Len())
+	}
+}
@@ -0,0 +1,4 @@
+package httpcache
+
+// Version is the semantic version of the httpcache package.
+const Version = "v0.1.0"
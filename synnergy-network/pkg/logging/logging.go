@@ -0,0 +1,250 @@
+// Package logging provides the per-module structured loggers and request
+// IDs shared by the node and the HTTP servers. Every subsystem logs through
+// ModuleLogger(name), which tags entries with a "module" field and has its
+// own level so operators can turn one module up to debug without flooding
+// the rest. Levels come from the environment at start-up and can be
+// changed at runtime through SetModuleLevel:
+//
+//	LOG_LEVEL=info                       default level of every module
+//	LOG_LEVELS=consensus=debug,p2p=warn  per-module overrides
+//	LOG_FORMAT=json                      JSON lines instead of text
+//
+// RequestLogger tags each HTTP request with an ID that ContextLogger adds
+// to the entries logged on its behalf. The package does not depend on the
+// node, so servers that do not link it can share the loggers.
+//
+// See Version for the module's semantic version.
+package logging
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+type logRegistry struct {
+	mu        sync.Mutex
+	out       io.Writer
+	formatter logrus.Formatter
+	def       logrus.Level
+	overrides map[string]logrus.Level
+	loggers   map[string]*logrus.Logger
+}
+
+var (
+	logsOnce sync.Once
+	logs     *logRegistry
+)
+
+func logRegistryInstance() *logRegistry {
+	logsOnce.Do(func() {
+		logs = &logRegistry{
+			out:       os.Stderr,
+			formatter: &logrus.TextFormatter{FullTimestamp: true},
+			def:       logrus.InfoLevel,
+			overrides: make(map[string]logrus.Level),
+			loggers:   make(map[string]*logrus.Logger),
+		}
+		if strings.EqualFold(os.Getenv("LOG_FORMAT"), "json") {
+			logs.formatter = &logrus.JSONFormatter{}
+		}
+		if lvl, err := logrus.ParseLevel(os.Getenv("LOG_LEVEL")); err == nil {
+			logs.def = lvl
+		}
+		for _, kv := range strings.Split(os.Getenv("LOG_LEVELS"), ",") {
+			mod, l, ok := strings.Cut(strings.TrimSpace(kv), "=")
+			if !ok {
+				continue
+			}
+			if lvl, err := logrus.ParseLevel(l); err == nil {
+				logs.overrides[strings.TrimSpace(mod)] = lvl
+			}
+		}
+	})
+	return logs
+}
+
+func (r *logRegistry) logger(module string) *logrus.Logger {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if lg, ok := r.loggers[module]; ok {
+		return lg
+	}
+	lg := logrus.New()
+	lg.SetOutput(r.out)
+	lg.SetFormatter(r.formatter)
+	lg.SetLevel(r.levelLocked(module))
+	r.loggers[module] = lg
+	return lg
+}
+
+func (r *logRegistry) levelLocked(module string) logrus.Level {
+	if lvl, ok := r.overrides[module]; ok {
+		return lvl
+	}
+	return r.def
+}
+
+// ModuleLogger returns the logger of module. Entries carry module=<name>.
+func ModuleLogger(module string) *logrus.Entry {
+	return logRegistryInstance().logger(module).WithField("module", module)
+}
+
+// ContextLogger returns the logger of module for work done on behalf of
+// ctx; entries carry the request ID stored by WithRequestID, if any.
+func ContextLogger(ctx context.Context, module string) *logrus.Entry {
+	e := ModuleLogger(module)
+	if id := RequestIDFrom(ctx); id != "" {
+		e = e.WithField("request_id", id)
+	}
+	return e
+}
+
+// SetModuleLevel changes the level of module at runtime. An empty module
+// sets the default level, which applies to every module without an
+// override of its own.
+func SetModuleLevel(module string, lvl logrus.Level) {
+	r := logRegistryInstance()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if module == "" {
+		r.def = lvl
+	} else {
+		r.overrides[module] = lvl
+	}
+	for name, lg := range r.loggers {
+		lg.SetLevel(r.levelLocked(name))
+	}
+}
+
+// SetOutput redirects every module logger to w.
+func SetOutput(w io.Writer) {
+	r := logRegistryInstance()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.out = w
+	for _, lg := range r.loggers {
+		lg.SetOutput(w)
+	}
+}
+
+// Levels reports the default level and the effective level of every
+// module that has logged or been configured.
+type Levels struct {
+	Default string            `json:"default"`
+	Modules map[string]string `json:"modules"`
+}
+
+// ModuleLevels reports the current log levels.
+func ModuleLevels() Levels {
+	r := logRegistryInstance()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := Levels{Default: r.def.String(), Modules: make(map[string]string)}
+	for name := range r.loggers {
+		out.Modules[name] = r.levelLocked(name).String()
+	}
+	for name, lvl := range r.overrides {
+		out.Modules[name] = lvl.String()
+	}
+	return out
+}
+
+type requestIDKey struct{}
+
+// RequestIDHeader carries the request ID between services and back to the
+// client.
+const RequestIDHeader = "X-Request-ID"
+
+// WithRequestID stores a request ID in ctx.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFrom returns the request ID stored in ctx, or "".
+func RequestIDFrom(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// NewRequestID returns a random 16-byte hex identifier.
+func NewRequestID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// requestIDOf takes the caller's X-Request-ID or the trace ID of a W3C
+// traceparent header so logs line up across services, and generates one
+// otherwise.
+func requestIDOf(r *http.Request) string {
+	if id := r.Header.Get(RequestIDHeader); id != "" && len(id) <= 128 {
+		return id
+	}
+	if tp := strings.Split(r.Header.Get("traceparent"), "-"); len(tp) == 4 && len(tp[1]) == 32 {
+		return tp[1]
+	}
+	return NewRequestID()
+}
+
+// RequestLogger returns middleware that assigns every request an ID,
+// stores it in the request context for ContextLogger, echoes it in the
+// X-Request-ID response header and logs one access line per request on the
+// logger of module.
+func RequestLogger(module string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := requestIDOf(r)
+			w.Header().Set(RequestIDHeader, id)
+			r = r.WithContext(WithRequestID(r.Context(), id))
+			start := time.Now()
+			sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(sw, r)
+			ContextLogger(r.Context(), module).WithFields(logrus.Fields{
+				"method":   r.Method,
+				"path":     r.URL.Path,
+				"status":   sw.status,
+				"duration": time.Since(start).String(),
+			}).Info("request")
+		})
+	}
+}
+
+// statusWriter records the status code written by a handler. It forwards
+// Hijack and Flush so WebSocket and streaming endpoints keep working.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (s *statusWriter) WriteHeader(code int) {
+	s.status = code
+	s.ResponseWriter.WriteHeader(code)
+}
+
+func (s *statusWriter) Unwrap() http.ResponseWriter { return s.ResponseWriter }
+
+func (s *statusWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := s.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response does not support hijacking")
+	}
+	return h.Hijack()
+}
+
+func (s *statusWriter) Flush() {
+	if f, ok := s.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
@@ -0,0 +1,58 @@
+package logging
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestRequestLoggerTagsRequests(t *testing.T) {
+	var buf bytes.Buffer
+	SetOutput(&buf)
+	defer SetOutput(os.Stderr)
+
+	var seen string
+	h := RequestLogger("logtest")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = RequestIDFrom(r.Context())
+		w.WriteHeader(http.StatusTeapot)
+	}))
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/x", nil)
+	req.Header.Set(RequestIDHeader, "abc")
+	h.ServeHTTP(rec, req)
+	if seen != "abc" || rec.Header().Get(RequestIDHeader) != "abc" {
+		t.Fatalf("request id %q, header %q", seen, rec.Header().Get(RequestIDHeader))
+	}
+	if line := buf.String(); !strings.Contains(line, "request_id=abc") || !strings.Contains(line, "status=418") {
+		t.Fatalf("access line %q", line)
+	}
+
+	rec = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/x", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	h.ServeHTTP(rec, req)
+	if seen != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Fatalf("trace id not used: %q", seen)
+	}
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/x", nil))
+	if len(seen) != 32 {
+		t.Fatalf("generated id %q", seen)
+	}
+}
+
+func TestModuleLevels(t *testing.T) {
+	SetModuleLevel("leveltest", logrus.DebugLevel)
+	if !ModuleLogger("leveltest").Logger.IsLevelEnabled(logrus.DebugLevel) {
+		t.Fatal("override not applied")
+	}
+	if got := ModuleLevels().Modules["leveltest"]; got != "debug" {
+		t.Fatalf("reported level %q", got)
+	}
+}
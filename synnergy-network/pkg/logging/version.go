@@ -0,0 +1,4 @@
+package logging
+
+// Version is the semantic version of the logging package.
+const Version = "v0.1.0"
@@ -0,0 +1,257 @@
+// Package openapi builds OpenAPI 3 documents from route tables declared next
+// to HTTP handlers and validates incoming requests against the same tables.
+//
+// A route table is the single annotation of an API: its parameters, request
+// body and response types. Schemas are derived from the Go types by
+// reflection, so the document follows the JSON the handlers actually read and
+// emit. Request body fields are constrained with a `validate` struct tag:
+//
+//	required        the field must be present
+//	min=N, max=N    numeric bounds
+//	enum=a|b|c      allowed string values
+//	format=F        address, hex or duration
+//
+// See Version for the module's semantic version.
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	core "synnergy-network/core"
+)
+
+// Param describes a path or query parameter. Type is an OpenAPI scalar type:
+// string, integer, number or boolean.
+type Param struct {
+	Name     string
+	In       string // "path" or "query"
+	Desc     string
+	Type     string
+	Required bool
+}
+
+// Route annotates one operation. Request and Response are zero values of
+// the body types, or nil when there is no body.
+type Route struct {
+	Method   string
+	Path     string // gorilla/mux style template, e.g. /api/bridges/{id}
+	Summary  string
+	Params   []Param
+	Request  interface{}
+	Response interface{}
+	// Status is the success status code; defaults to 200, or 204 when
+	// Response is nil.
+	Status int
+	// Handler serves the route; routers that mount from the table use it.
+	Handler http.HandlerFunc
+}
+
+// Spec is an API described by its route table.
+type Spec struct {
+	Title   string
+	Version string
+	Routes  []Route
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// Document returns the OpenAPI 3 document of s.
+func (s Spec) Document() map[string]interface{} {
+	schemas := map[string]interface{}{}
+	paths := map[string]interface{}{}
+	errResp := map[string]interface{}{
+		"description": "Error",
+		"content": map[string]interface{}{"application/json": map[string]interface{}{
+			"schema": SchemaOf(reflect.TypeOf(core.ErrorEnvelope{}), schemas),
+		}},
+	}
+	for _, rt := range s.Routes {
+		op := map[string]interface{}{
+			"summary":    rt.Summary,
+			"parameters": paramsDoc(rt.Params),
+		}
+		if rt.Request != nil {
+			op["requestBody"] = map[string]interface{}{
+				"required": true,
+				"content": map[string]interface{}{"application/json": map[string]interface{}{
+					"schema": SchemaOf(reflect.TypeOf(rt.Request), schemas),
+				}},
+			}
+		}
+		ok := map[string]interface{}{"description": http.StatusText(rt.status())}
+		if rt.Response != nil {
+			ok["content"] = map[string]interface{}{"application/json": map[string]interface{}{
+				"schema": SchemaOf(reflect.TypeOf(rt.Response), schemas),
+			}}
+		}
+		op["responses"] = map[string]interface{}{
+			strconv.Itoa(rt.status()): ok,
+			"default":                 errResp,
+		}
+		item, _ := paths[rt.Path].(map[string]interface{})
+		if item == nil {
+			item = map[string]interface{}{}
+			paths[rt.Path] = item
+		}
+		item[strings.ToLower(rt.Method)] = op
+	}
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":   s.Title,
+			"version": s.Version,
+		},
+		"paths":      paths,
+		"components": map[string]interface{}{"schemas": schemas},
+	}
+}
+
+func (rt Route) status() int {
+	switch {
+	case rt.Status != 0:
+		return rt.Status
+	case rt.Response == nil:
+		return http.StatusNoContent
+	}
+	return http.StatusOK
+}
+
+func paramsDoc(ps []Param) []interface{} {
+	out := []interface{}{}
+	for _, p := range ps {
+		out = append(out, map[string]interface{}{
+			"name":        p.Name,
+			"in":          p.In,
+			"required":    p.In == "path" || p.Required,
+			"description": p.Desc,
+			"schema":      map[string]interface{}{"type": p.Type},
+		})
+	}
+	return out
+}
+
+// SchemaOf returns the schema of t, registering named structs under
+// components/schemas and referencing them.
+func SchemaOf(t reflect.Type, schemas map[string]interface{}) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if t == reflect.TypeOf(time.Duration(0)) {
+			return map[string]interface{}{"type": "integer", "description": "nanoseconds"}
+		}
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 && t.Kind() == reflect.Slice {
+			return map[string]interface{}{"type": "string", "format": "byte"}
+		}
+		return map[string]interface{}{"type": "array", "items": SchemaOf(t.Elem(), schemas)}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": SchemaOf(t.Elem(), schemas)}
+	case reflect.Interface:
+		return map[string]interface{}{}
+	case reflect.Struct:
+		if t == timeType {
+			return map[string]interface{}{"type": "string", "format": "date-time"}
+		}
+		name := t.Name()
+		if name == "" {
+			return structSchema(t, schemas)
+		}
+		name = strings.ToUpper(name[:1]) + name[1:]
+		ref := map[string]interface{}{"$ref": "#/components/schemas/" + name}
+		if _, ok := schemas[name]; ok {
+			return ref
+		}
+		schemas[name] = map[string]interface{}{"type": "object"} // break cycles
+		schemas[name] = structSchema(t, schemas)
+		return ref
+	}
+	return map[string]interface{}{}
+}
+
+func structSchema(t reflect.Type, schemas map[string]interface{}) map[string]interface{} {
+	props := map[string]interface{}{}
+	var required []string
+	for _, f := range jsonFields(t) {
+		s := SchemaOf(f.Type, schemas)
+		r := parseRules(f.Tag.Get("validate"))
+		if r.required {
+			required = append(required, f.key)
+		}
+		if len(r.enum) > 0 || r.min != nil || r.max != nil || r.format != "" {
+			c := make(map[string]interface{}, len(s)+3)
+			for k, v := range s {
+				c[k] = v
+			}
+			if len(r.enum) > 0 {
+				c["enum"] = r.enum
+			}
+			if r.min != nil {
+				c["minimum"] = *r.min
+			}
+			if r.max != nil {
+				c["maximum"] = *r.max
+			}
+			if r.format != "" {
+				c["format"] = r.format
+			}
+			s = c
+		}
+		props[f.key] = s
+	}
+	out := map[string]interface{}{"type": "object", "properties": props}
+	if len(required) > 0 {
+		out["required"] = required
+	}
+	return out
+}
+
+// jsonField is an exported struct field and the JSON key it encodes as.
+type jsonField struct {
+	reflect.StructField
+	key string
+}
+
+func jsonFields(t reflect.Type) []jsonField {
+	var out []jsonField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		// Untagged embedded structs are flattened, as encoding/json does.
+		if ft := f.Type; f.Anonymous && f.Tag.Get("json") == "" {
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				out = append(out, jsonFields(ft)...)
+				continue
+			}
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+		key := f.Name
+		if tag := f.Tag.Get("json"); tag != "" {
+			if tag == "-" {
+				continue
+			}
+			if n := strings.Split(tag, ",")[0]; n != "" {
+				key = n
+			}
+		}
+		out = append(out, jsonField{StructField: f, key: key})
+	}
+	return out
+}
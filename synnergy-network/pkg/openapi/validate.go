@@ -0,0 +1,336 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	core "synnergy-network/core"
+)
+
+// MaxBodyBytes bounds the request bodies the validator reads.
+const MaxBodyBytes = 1 << 20
+
+// rules are the parsed `validate` tag of a field.
+type rules struct {
+	required bool
+	min, max *float64
+	enum     []string
+	format   string
+}
+
+func parseRules(tag string) rules {
+	var r rules
+	for _, part := range strings.Split(tag, ",") {
+		k, v, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch k {
+		case "required":
+			r.required = true
+		case "min", "max":
+			n, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			if k == "min" {
+				r.min = &n
+			} else {
+				r.max = &n
+			}
+		case "enum":
+			r.enum = strings.Split(v, "|")
+		case "format":
+			r.format = v
+		}
+	}
+	return r
+}
+
+// Validator returns middleware that checks requests matching a route of s
+// before they reach the handler: path and query parameters must parse as
+// their declared type, required query parameters must be present, and JSON
+// bodies must decode into the route's Request type without unknown fields
+// and satisfy its `validate` tags. Rejections are answered with the shared
+// error envelope, code INVALID_ARGUMENT, attributed to module; the offending
+// field is reported in details.field. Requests matching no route pass
+// through unchanged.
+func (s Spec) Validator(module string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rt, vars := s.match(r.Method, r.URL.Path)
+			if rt == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			status, err := validateRequest(rt, vars, r)
+			if err != nil {
+				e := core.ClassifyError(err)
+				e.Module = module
+				core.WriteHTTPError(w, status, module, e)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// match finds the route for method and path and extracts its path
+// variables.
+func (s Spec) match(method, path string) (*Route, map[string]string) {
+	segs := strings.Split(strings.Trim(path, "/"), "/")
+	for i := range s.Routes {
+		rt := &s.Routes[i]
+		if !strings.EqualFold(rt.Method, method) {
+			continue
+		}
+		tmpl := strings.Split(strings.Trim(rt.Path, "/"), "/")
+		if len(tmpl) != len(segs) {
+			continue
+		}
+		vars := map[string]string{}
+		for j, t := range tmpl {
+			if strings.HasPrefix(t, "{") && strings.HasSuffix(t, "}") {
+				name, _, _ := strings.Cut(t[1:len(t)-1], ":")
+				vars[name] = segs[j]
+			} else if t != segs[j] {
+				vars = nil
+				break
+			}
+		}
+		if vars != nil {
+			return rt, vars
+		}
+	}
+	return nil, nil
+}
+
+func invalid(field, format string, args ...interface{}) error {
+	return core.NewError(core.CodeInvalidArgument, "", "%s: %s", field, fmt.Sprintf(format, args...)).WithDetail("field", field)
+}
+
+func validateRequest(rt *Route, vars map[string]string, r *http.Request) (int, error) {
+	q := r.URL.Query()
+	for _, p := range rt.Params {
+		var v string
+		var ok bool
+		switch p.In {
+		case "path":
+			v, ok = vars[p.Name]
+		case "query":
+			v = q.Get(p.Name)
+			ok = v != ""
+		}
+		if !ok {
+			if p.Required {
+				return http.StatusBadRequest, invalid(p.Name, "required %s parameter missing", p.In)
+			}
+			continue
+		}
+		if err := checkScalar(p.Type, v); err != nil {
+			return http.StatusBadRequest, invalid(p.Name, "%v", err)
+		}
+	}
+	if rt.Request == nil {
+		return 0, nil
+	}
+	if mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mt != "" && mt != "application/json" {
+		return http.StatusUnsupportedMediaType, core.NewError(core.CodeInvalidArgument, "", "content type must be application/json")
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, MaxBodyBytes+1))
+	r.Body.Close()
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+	if len(body) > MaxBodyBytes {
+		return http.StatusRequestEntityTooLarge, core.NewError(core.CodeInvalidArgument, "", "request body exceeds %d bytes", MaxBodyBytes)
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	if err := ValidateBody(reflect.TypeOf(rt.Request), body); err != nil {
+		return http.StatusBadRequest, err
+	}
+	return 0, nil
+}
+
+func checkScalar(typ, v string) error {
+	var err error
+	switch typ {
+	case "integer":
+		_, err = strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			_, err = strconv.ParseUint(v, 10, 64)
+		}
+	case "number":
+		_, err = strconv.ParseFloat(v, 64)
+	case "boolean":
+		_, err = strconv.ParseBool(v)
+	}
+	if err != nil {
+		return fmt.Errorf("%q is not a valid %s", v, typ)
+	}
+	return nil
+}
+
+// ValidateBody checks that body is a JSON document decoding into t without
+// unknown fields and satisfying the `validate` tags of its fields.
+func ValidateBody(t reflect.Type, body []byte) error {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return invalid("body", "request body required")
+	}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(reflect.New(t).Interface()); err != nil {
+		return decodeError(err)
+	}
+	return checkValue(t, body, "")
+}
+
+// decodeError turns an encoding/json error into a field-level error.
+func decodeError(err error) error {
+	var te *json.UnmarshalTypeError
+	var se *json.SyntaxError
+	switch {
+	case errors.As(err, &te):
+		field := te.Field
+		if field == "" {
+			field = "body"
+		}
+		return invalid(field, "expected %s, got %s", te.Type, te.Value)
+	case errors.As(err, &se):
+		return invalid("body", "malformed JSON at offset %d", se.Offset)
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		return invalid(field, "unknown field")
+	}
+	return invalid("body", "%v", err)
+}
+
+// checkValue applies validate tags to the JSON raw decoded as t; path is
+// the dotted location of raw for error reporting.
+func checkValue(t reflect.Type, raw json.RawMessage, path string) error {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		if t == timeType {
+			return nil
+		}
+		var obj map[string]json.RawMessage
+		if json.Unmarshal(raw, &obj) != nil || obj == nil {
+			return nil
+		}
+		for _, f := range jsonFields(t) {
+			name := join(path, f.key)
+			v, ok := lookupKey(obj, f.key)
+			r := parseRules(f.Tag.Get("validate"))
+			if !ok || string(v) == "null" {
+				if r.required {
+					return invalid(name, "required")
+				}
+				continue
+			}
+			if err := checkRules(r, v, name); err != nil {
+				return err
+			}
+			if err := checkValue(f.Type, v, name); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		var items []json.RawMessage
+		if json.Unmarshal(raw, &items) != nil {
+			return nil
+		}
+		for i, it := range items {
+			if err := checkValue(t.Elem(), it, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		var m map[string]json.RawMessage
+		if json.Unmarshal(raw, &m) != nil {
+			return nil
+		}
+		for k, v := range m {
+			if err := checkValue(t.Elem(), v, join(path, k)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// lookupKey finds key in obj, falling back to the case-insensitive match
+// encoding/json accepts.
+func lookupKey(obj map[string]json.RawMessage, key string) (json.RawMessage, bool) {
+	if v, ok := obj[key]; ok {
+		return v, true
+	}
+	for k, v := range obj {
+		if strings.EqualFold(k, key) {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+func checkRules(r rules, v json.RawMessage, name string) error {
+	if r.min != nil || r.max != nil {
+		var n float64
+		if json.Unmarshal(v, &n) == nil {
+			if r.min != nil && n < *r.min {
+				return invalid(name, "must be at least %v", *r.min)
+			}
+			if r.max != nil && n > *r.max {
+				return invalid(name, "must be at most %v", *r.max)
+			}
+		}
+	}
+	if len(r.enum) == 0 && r.format == "" {
+		return nil
+	}
+	var s string
+	if json.Unmarshal(v, &s) != nil {
+		return nil
+	}
+	if len(r.enum) > 0 {
+		found := false
+		for _, e := range r.enum {
+			found = found || s == e
+		}
+		if !found {
+			return invalid(name, "must be one of %s", strings.Join(r.enum, ", "))
+		}
+	}
+	if s == "" && !r.required {
+		return nil
+	}
+	var err error
+	switch r.format {
+	case "address":
+		_, err = core.DecodeAddress(s)
+	case "hex":
+		_, err = hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	case "duration":
+		_, err = time.ParseDuration(s)
+	}
+	if err != nil {
+		return invalid(name, "invalid %s", r.format)
+	}
+	return nil
+}
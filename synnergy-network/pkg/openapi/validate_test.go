@@ -0,0 +1,89 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type testLimit struct {
+	Window string `json:"window" validate:"format=duration"`
+}
+
+type testRequest struct {
+	To     string               `json:"to" validate:"required,format=address"`
+	Amount uint64               `json:"amount" validate:"required,min=1"`
+	Limits map[string]testLimit `json:"limits"`
+}
+
+func TestValidatorRejectsMalformedRequests(t *testing.T) {
+	spec := Spec{Routes: []Route{{
+		Method: http.MethodPost, Path: "/api/things/{id}",
+		Params:  []Param{{Name: "id", In: "path", Type: "integer"}},
+		Request: testRequest{},
+	}}}
+	reached := 0
+	h := spec.Validator("test")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req testRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Amount != 5 {
+			t.Errorf("handler got %+v, %v", req, err)
+		}
+		reached++
+	}))
+	const to = `"to":"0x00000000000000000000000000000000000000aa"`
+	cases := []struct {
+		path, body, field string
+	}{
+		{"/api/things/1", `{` + to + `,"amount":5,"limits":{"a":{"window":"10m"}}}`, ""},
+		{"/api/things/x", `{` + to + `,"amount":5}`, "id"},
+		{"/api/things/1", `{"amount":5}`, "to"},
+		{"/api/things/1", `{"to":"nope","amount":5}`, "to"},
+		{"/api/things/1", `{` + to + `,"amount":0}`, "amount"},
+		{"/api/things/1", `{` + to + `,"amount":"5"}`, "amount"},
+		{"/api/things/1", `{` + to + `,"amount":5,"extra":1}`, "extra"},
+		{"/api/things/1", `{` + to + `,"amount":5,"limits":{"a":{"window":"soon"}}}`, "limits.a.window"},
+	}
+	for _, c := range cases {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, c.path, strings.NewReader(c.body)))
+		if c.field == "" {
+			if rr.Code != http.StatusOK {
+				t.Fatalf("%s: unexpected %d %s", c.body, rr.Code, rr.Body)
+			}
+			continue
+		}
+		var env struct {
+			Error struct {
+				Code    string                 `json:"code"`
+				Details map[string]interface{} `json:"details"`
+			} `json:"error"`
+		}
+		if err := json.Unmarshal(rr.Body.Bytes(), &env); err != nil || rr.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400 envelope, got %d %s", c.body, rr.Code, rr.Body)
+		}
+		if env.Error.Code != "INVALID_ARGUMENT" || env.Error.Details["field"] != c.field {
+			t.Fatalf("%s: got %+v, want field %s", c.body, env.Error, c.field)
+		}
+	}
+	if reached != 1 {
+		t.Fatalf("handler reached %d times", reached)
+	}
+}
+
+func TestDocumentIncludesConstraints(t *testing.T) {
+	doc := Spec{Title: "t", Version: "1", Routes: []Route{{Method: http.MethodPost, Path: "/x", Request: testRequest{}}}}.Document()
+	schemas := doc["components"].(map[string]interface{})["schemas"].(map[string]interface{})
+	req := schemas["TestRequest"].(map[string]interface{})
+	if got := req["required"].([]string); len(got) != 2 || got[0] != "to" || got[1] != "amount" {
+		t.Fatalf("required = %v", got)
+	}
+	amount := req["properties"].(map[string]interface{})["amount"].(map[string]interface{})
+	if amount["minimum"] != 1.0 {
+		t.Fatalf("amount schema = %v", amount)
+	}
+	if _, ok := schemas["ErrorEnvelope"]; !ok {
+		t.Fatal("error envelope schema missing")
+	}
+}
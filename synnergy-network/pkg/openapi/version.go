@@ -0,0 +1,4 @@
+package openapi
+
+// Version is the semantic version of the openapi package.
+const Version = "v0.1.0"
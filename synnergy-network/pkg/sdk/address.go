@@ -0,0 +1,200 @@
+package sdk
+
+// address.go – account addresses and their text forms.
+//
+// Nodes accept bech32 addresses under the network's prefix ("syn" on main
+// network, "tsyn" on test networks) and legacy hex, with an EIP-55
+// checksum when the hex is mixed-case. The SDK parses the same forms so a
+// mistyped recipient fails locally instead of at the node.
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	// MainnetAddressHRP is the bech32 prefix of main network addresses.
+	MainnetAddressHRP = "syn"
+	// TestnetAddressHRP is the bech32 prefix of test network addresses.
+	TestnetAddressHRP = "tsyn"
+	// NameTLD is the suffix of names registered with the name service.
+	NameTLD = ".syn"
+)
+
+// Address is a 20-byte account address. Like the node, it encodes in JSON
+// as an array of bytes.
+type Address [20]byte
+
+// Hash is a 32-byte transaction or block hash.
+type Hash [32]byte
+
+// Hex returns the 0x-prefixed lower-case hex form of the address.
+func (a Address) Hex() string { return "0x" + hex.EncodeToString(a[:]) }
+
+// Bech32 returns the checksummed bech32 form of the address under hrp.
+func (a Address) Bech32(hrp string) string {
+	return bech32Encode(hrp, convertBits(a[:], 8, 5, true))
+}
+
+// ChecksumHex returns the EIP-55 mixed-case hex form of the address.
+func (a Address) ChecksumHex() string {
+	lower := hex.EncodeToString(a[:])
+	sum := crypto.Keccak256([]byte(lower))
+	out := []byte(lower)
+	for i, c := range out {
+		if c >= 'a' && sum[i/2]>>(4*(1-uint(i)%2))&0xf >= 8 {
+			out[i] = c - 'a' + 'A'
+		}
+	}
+	return "0x" + string(out)
+}
+
+// Hex returns the hash as hex without prefix, as nodes print it.
+func (h Hash) Hex() string { return hex.EncodeToString(h[:]) }
+
+// IsName reports whether s is a name-service name rather than an address.
+func IsName(s string) bool {
+	return strings.HasSuffix(strings.ToLower(strings.TrimSpace(s)), NameTLD)
+}
+
+// DecodeAddress parses a bech32 address under hrp or a legacy hex address.
+// Checksums are verified whenever the input carries one.
+func DecodeAddress(s, hrp string) (Address, error) {
+	s = strings.TrimSpace(s)
+	h := strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X")
+	if raw, err := hex.DecodeString(h); err == nil && len(raw) == len(Address{}) {
+		var a Address
+		copy(a[:], raw)
+		if h != strings.ToLower(h) && h != strings.ToUpper(h) && h != a.ChecksumHex()[2:] {
+			return Address{}, NewError(CodeInvalidArgument, "address", "checksum mismatch: 0x%s", h)
+		}
+		return a, nil
+	}
+	got, data, err := bech32Decode(s)
+	if err != nil {
+		return Address{}, NewError(CodeInvalidArgument, "address", "invalid address %s: %v", s, err)
+	}
+	if got != hrp {
+		return Address{}, NewError(CodeInvalidArgument, "address", "prefix %q, want %q", got, hrp)
+	}
+	raw, ok := convertBits5to8(data)
+	if !ok || len(raw) != len(Address{}) {
+		return Address{}, NewError(CodeInvalidArgument, "address", "invalid address %s", s)
+	}
+	var a Address
+	copy(a[:], raw)
+	return a, nil
+}
+
+//---------------------------------------------------------------------
+// bech32 (BIP-173)
+//---------------------------------------------------------------------
+
+const bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+var bech32Gen = [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+
+func bech32Polymod(values []byte) uint32 {
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (top>>uint(i))&1 == 1 {
+				chk ^= bech32Gen[i]
+			}
+		}
+	}
+	return chk
+}
+
+func bech32HRPExpand(hrp string) []byte {
+	out := make([]byte, 0, 2*len(hrp)+1)
+	for i := 0; i < len(hrp); i++ {
+		out = append(out, hrp[i]>>5)
+	}
+	out = append(out, 0)
+	for i := 0; i < len(hrp); i++ {
+		out = append(out, hrp[i]&31)
+	}
+	return out
+}
+
+func bech32Encode(hrp string, data []byte) string {
+	values := append(bech32HRPExpand(hrp), data...)
+	mod := bech32Polymod(append(values, 0, 0, 0, 0, 0, 0)) ^ 1
+	var sb strings.Builder
+	sb.WriteString(hrp)
+	sb.WriteByte('1')
+	for _, d := range data {
+		sb.WriteByte(bech32Charset[d])
+	}
+	for i := 0; i < 6; i++ {
+		sb.WriteByte(bech32Charset[mod>>uint(5*(5-i))&31])
+	}
+	return sb.String()
+}
+
+func bech32Decode(s string) (string, []byte, error) {
+	if len(s) > 90 {
+		return "", nil, errors.New("too long")
+	}
+	if strings.ToLower(s) != s && strings.ToUpper(s) != s {
+		return "", nil, errors.New("mixed case")
+	}
+	s = strings.ToLower(s)
+	pos := strings.LastIndexByte(s, '1')
+	if pos < 1 || pos+7 > len(s) {
+		return "", nil, errors.New("missing separator or checksum")
+	}
+	hrp := s[:pos]
+	data := make([]byte, 0, len(s)-pos-1)
+	for i := pos + 1; i < len(s); i++ {
+		d := strings.IndexByte(bech32Charset, s[i])
+		if d < 0 {
+			return "", nil, fmt.Errorf("invalid character %q", s[i])
+		}
+		data = append(data, byte(d))
+	}
+	if bech32Polymod(append(bech32HRPExpand(hrp), data...)) != 1 {
+		return "", nil, errors.New("checksum mismatch")
+	}
+	return hrp, data[:len(data)-6], nil
+}
+
+// convertBits regroups data from groups of from bits into groups of to
+// bits, zero-padding the final group when pad is set.
+func convertBits(data []byte, from, to uint, pad bool) []byte {
+	var acc, bits uint
+	maxv := uint(1)<<to - 1
+	out := make([]byte, 0, len(data)*int(from)/int(to)+1)
+	for _, v := range data {
+		acc = acc<<from | uint(v)
+		bits += from
+		for bits >= to {
+			bits -= to
+			out = append(out, byte(acc>>bits&maxv))
+		}
+	}
+	if pad && bits > 0 {
+		out = append(out, byte(acc<<(to-bits)&maxv))
+	}
+	return out
+}
+
+// convertBits5to8 is the inverse of convertBits(data, 8, 5, true); it fails
+// when the padding is longer than four bits or not zero.
+func convertBits5to8(data []byte) ([]byte, bool) {
+	if len(data) == 0 {
+		return nil, false
+	}
+	bits := uint(len(data)*5) % 8
+	if bits >= 5 || data[len(data)-1]&(1<<bits-1) != 0 {
+		return nil, false
+	}
+	return convertBits(data, 5, 8, false), true
+}
@@ -0,0 +1,131 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// BatchCall is one request of a batch: the method (GET by default), path
+// and JSON body it would have been sent with on its own.
+type BatchCall struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Method string          `json:"method,omitempty"`
+	Path   string          `json:"path"`
+	Body   json.RawMessage `json:"body,omitempty"`
+}
+
+// BatchResult is the status and body a BatchCall received.
+type BatchResult struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Status int             `json:"status"`
+	Body   json.RawMessage `json:"body,omitempty"`
+}
+
+// MaxBatchCalls is the number of calls the SDK packs into one batch
+// request. It matches the servers' default limit; longer inputs are split.
+const MaxBatchCalls = 100
+
+// batch runs calls through the batch endpoint at base+path, splitting them
+// into requests of at most MaxBatchCalls. Results are in call order.
+func (c *Client) batch(ctx context.Context, base, path string, calls []BatchCall) ([]BatchResult, error) {
+	out := make([]BatchResult, 0, len(calls))
+	for len(calls) > 0 {
+		n := min(len(calls), MaxBatchCalls)
+		var res []BatchResult
+		if err := c.do(ctx, http.MethodPost, base, path, calls[:n], &res); err != nil {
+			return nil, err
+		}
+		if len(res) != n {
+			return nil, fmt.Errorf("sdk: batch returned %d results for %d calls", len(res), n)
+		}
+		out = append(out, res...)
+		calls = calls[n:]
+	}
+	return out, nil
+}
+
+// resultError returns the typed error of a failed batch result.
+func resultError(r BatchResult) error {
+	if r.Status < 300 {
+		return nil
+	}
+	return decodeErrorBody(r.Status, r.Body)
+}
+
+// Balances looks up the balance and nonce of many addresses with the
+// node's /batch endpoint. The result is parallel to addrs; entries whose
+// lookup failed are nil and their errors are joined into the returned
+// error, so callers can keep the successful lookups.
+func (c *Client) Balances(ctx context.Context, addrs []string) ([]*Balance, error) {
+	calls := make([]BatchCall, len(addrs))
+	for i, a := range addrs {
+		calls[i] = BatchCall{Path: "/balance/" + url.PathEscape(a)}
+	}
+	res, err := c.batch(ctx, c.node, "/batch", calls)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*Balance, len(addrs))
+	var errs []error
+	for i, r := range res {
+		if err := resultError(r); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", addrs[i], err))
+			continue
+		}
+		var b Balance
+		if err := json.Unmarshal(r.Body, &b); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", addrs[i], err))
+			continue
+		}
+		out[i] = &b
+	}
+	return out, errors.Join(errs...)
+}
+
+// Receipts fetches the receipts of many transactions with the explorer's
+// /api/batch endpoint. Like Balances, the result is parallel to hashes and
+// pending or failed lookups are nil, with their errors joined.
+func (c *Client) Receipts(ctx context.Context, hashes []string) ([]*Receipt, error) {
+	if c.explorer == "" {
+		return nil, ErrNoExplorer
+	}
+	calls := make([]BatchCall, len(hashes))
+	for i, h := range hashes {
+		body, err := json.Marshal(map[string]interface{}{"query": receiptQuery, "variables": map[string]interface{}{"hash": h}})
+		if err != nil {
+			return nil, err
+		}
+		calls[i] = BatchCall{Method: http.MethodPost, Path: "/graphql", Body: body}
+	}
+	res, err := c.batch(ctx, c.explorer, "/api/batch", calls)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*Receipt, len(hashes))
+	var errs []error
+	for i, r := range res {
+		var resp gqlResponse
+		err := json.Unmarshal(r.Body, &resp)
+		if err == nil {
+			err = resp.err()
+		}
+		if err == nil {
+			err = resultError(r)
+		}
+		var data receiptData
+		if err == nil {
+			err = json.Unmarshal(resp.Data, &data)
+		}
+		if err == nil {
+			out[i], err = data.receipt(hashes[i])
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", hashes[i], err))
+		}
+	}
+	return out, errors.Join(errs...)
+}
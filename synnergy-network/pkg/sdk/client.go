@@ -0,0 +1,227 @@
+// Package sdk is the Go client for Synnergy nodes. It wraps the API node
+// (balances, blocks, transaction submission, contract calls and the event
+// stream) and the explorer API (transactions, receipts, pools, accounts and
+// names) behind typed methods, so integrators do not depend on the wire
+// formats of individual daemons.
+//
+// Every method takes a context. Failures are returned as *Error values
+// carrying a stable code; use IsCode and IsRetryable to branch on them.
+// Requests failing with a retryable error are retried with exponential
+// backoff.
+//
+// The package depends only on the nodes' wire formats, not on the node
+// implementation, so integrators can vendor it alone. See Version for the
+// module's semantic version.
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	defaultMaxRetries   = 3
+	defaultRetryBackoff = 250 * time.Millisecond
+	maxRetryBackoff     = 5 * time.Second
+	defaultTimeout      = 30 * time.Second
+	maxResponseBytes    = 32 << 20
+)
+
+// ErrNoExplorer is returned by explorer-backed methods when the client was
+// configured without an ExplorerURL.
+var ErrNoExplorer = errors.New("sdk: explorer URL not configured")
+
+// Config configures a Client.
+type Config struct {
+	// NodeURL is the base URL of an API node, e.g. "http://127.0.0.1:8080".
+	NodeURL string
+	// ExplorerURL is the base URL of an explorer, e.g.
+	// "http://127.0.0.1:8081". Optional; required for Tx, Receipt, Pools,
+	// Account, Blocks and name resolution.
+	ExplorerURL string
+	// HTTPClient defaults to a client with a 30s timeout.
+	HTTPClient *http.Client
+	// MaxRetries bounds the retries of a failed request; 0 selects the
+	// default of 3, a negative value disables retries.
+	MaxRetries int
+	// RetryBackoff is the wait before the first retry, doubled for each
+	// further attempt up to 5s. Defaults to 250ms.
+	RetryBackoff time.Duration
+	// UserAgent is sent with every request.
+	UserAgent string
+	// AddressHRP is the bech32 prefix of the network's addresses; it
+	// defaults to MainnetAddressHRP.
+	AddressHRP string
+}
+
+// Client talks to a Synnergy API node and, optionally, an explorer. It is
+// safe for concurrent use.
+type Client struct {
+	node     string
+	explorer string
+	http     *http.Client
+	retries  int
+	backoff  time.Duration
+	ua       string
+	hrp      string
+}
+
+// New validates cfg and returns a Client.
+func New(cfg Config) (*Client, error) {
+	node, err := baseURL(cfg.NodeURL)
+	if err != nil {
+		return nil, fmt.Errorf("sdk: node URL: %w", err)
+	}
+	c := &Client{node: node, http: cfg.HTTPClient, retries: cfg.MaxRetries, backoff: cfg.RetryBackoff, ua: cfg.UserAgent, hrp: cfg.AddressHRP}
+	if cfg.ExplorerURL != "" {
+		if c.explorer, err = baseURL(cfg.ExplorerURL); err != nil {
+			return nil, fmt.Errorf("sdk: explorer URL: %w", err)
+		}
+	}
+	if c.http == nil {
+		c.http = &http.Client{Timeout: defaultTimeout}
+	}
+	switch {
+	case c.retries == 0:
+		c.retries = defaultMaxRetries
+	case c.retries < 0:
+		c.retries = 0
+	}
+	if c.backoff <= 0 {
+		c.backoff = defaultRetryBackoff
+	}
+	if c.hrp == "" {
+		c.hrp = MainnetAddressHRP
+	}
+	if c.ua == "" {
+		c.ua = "synnergy-go-sdk/" + Version
+	}
+	return c, nil
+}
+
+func baseURL(raw string) (string, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return "", fmt.Errorf("%q is not an http(s) URL", raw)
+	}
+	return strings.TrimRight(u.String(), "/"), nil
+}
+
+// IsCode reports whether err carries the given error code.
+func IsCode(err error, code ErrorCode) bool {
+	var e *Error
+	return errors.As(err, &e) && e.Code == code
+}
+
+// IsRetryable reports whether repeating the failed request may succeed.
+func IsRetryable(err error) bool {
+	var e *Error
+	return errors.As(err, &e) && e.Retryable
+}
+
+// getNode and friends issue a request against the node or explorer.
+func (c *Client) getNode(ctx context.Context, path string, out interface{}) error {
+	return c.do(ctx, http.MethodGet, c.node, path, nil, out)
+}
+
+func (c *Client) postNode(ctx context.Context, path string, in, out interface{}) error {
+	return c.do(ctx, http.MethodPost, c.node, path, in, out)
+}
+
+func (c *Client) getExplorer(ctx context.Context, path string, out interface{}) error {
+	if c.explorer == "" {
+		return ErrNoExplorer
+	}
+	return c.do(ctx, http.MethodGet, c.explorer, path, nil, out)
+}
+
+// do performs a request, retrying retryable failures. Transaction
+// submission is retried too: resending the same signed transaction is
+// idempotent because the pool keys transactions by hash.
+func (c *Client) do(ctx context.Context, method, base, path string, in, out interface{}) error {
+	var body []byte
+	if in != nil {
+		var err error
+		if body, err = json.Marshal(in); err != nil {
+			return err
+		}
+	}
+	wait := c.backoff
+	for attempt := 0; ; attempt++ {
+		err := c.once(ctx, method, base+path, body, out)
+		if err == nil || attempt >= c.retries || !IsRetryable(err) {
+			return err
+		}
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-t.C:
+		}
+		if wait *= 2; wait > maxRetryBackoff {
+			wait = maxRetryBackoff
+		}
+	}
+}
+
+// errorBody is implemented by responses that carry their own error format
+// on failure, such as GraphQL. decodeError returns nil when data is not in
+// that format.
+type errorBody interface {
+	decodeError(data []byte) error
+}
+
+func (c *Client) once(ctx context.Context, method, u string, body []byte, out interface{}) error {
+	var rd io.Reader
+	if body != nil {
+		rd = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, rd)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", c.ua)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return classifyError(err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return classifyError(err)
+	}
+	if resp.StatusCode >= 300 {
+		if eb, ok := out.(errorBody); ok {
+			if err := eb.decodeError(data); err != nil {
+				return err
+			}
+		}
+		return decodeErrorBody(resp.StatusCode, data)
+	}
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("sdk: decode %s: %w", u, err)
+	}
+	return nil
+}
@@ -0,0 +1,247 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// writeError answers with the error envelope nodes use.
+func writeError(w http.ResponseWriter, status int, e *Error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]*Error{"error": e})
+}
+
+func newTestClient(t *testing.T, h http.Handler) *Client {
+	t.Helper()
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+	c, err := New(Config{NodeURL: srv.URL, ExplorerURL: srv.URL, RetryBackoff: time.Millisecond})
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	return c
+}
+
+func TestRetriesRetryableErrors(t *testing.T) {
+	var calls int32
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			writeError(w, http.StatusServiceUnavailable, NewError(CodeUnavailable, "api", "syncing"))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(NodeStatus{Height: 7})
+	}))
+	st, err := c.Status(context.Background())
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if st.Height != 7 || atomic.LoadInt32(&calls) != 3 {
+		t.Fatalf("height %d after %d calls", st.Height, calls)
+	}
+}
+
+func TestTypedErrors(t *testing.T) {
+	var calls int32
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		writeError(w, http.StatusNotFound, NewError(CodeNotFound, "core", "resource not found"))
+	}))
+	_, err := c.Block(context.Background(), 99)
+	if !IsCode(err, CodeNotFound) || IsRetryable(err) {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("non-retryable error retried: %d calls", calls)
+	}
+}
+
+func TestTransferSignsWithNodeNonce(t *testing.T) {
+	key, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	to, _ := GenerateKey()
+	var sent Transaction
+	mux := http.NewServeMux()
+	mux.HandleFunc("/balance/", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(Balance{Balance: 100, Nonce: 4})
+	})
+	mux.HandleFunc("/tx", func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&sent); err != nil {
+			t.Errorf("decode tx: %v", err)
+		}
+		w.WriteHeader(http.StatusAccepted)
+		_ = json.NewEncoder(w).Encode(map[string]string{"status": "accepted", "hash": sent.Hash.Hex()})
+	})
+	c := newTestClient(t, mux)
+
+	hash, err := c.Transfer(context.Background(), key, to.Address().Bech32(MainnetAddressHRP), 25, nil)
+	if err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+	if sent.Nonce != 4 || sent.Value != 25 || sent.To != to.Address() || sent.From != key.Address() {
+		t.Fatalf("unexpected tx %+v", sent)
+	}
+	if hash != sent.Hash.Hex() {
+		t.Fatalf("hash %s, want %s", hash, sent.Hash.Hex())
+	}
+	pub, err := crypto.SigToPub(sent.Hash[:], sent.Sig)
+	if err != nil || Address(crypto.PubkeyToAddress(*pub)) != key.Address() {
+		t.Fatalf("signature does not recover the sender: %v", err)
+	}
+}
+
+func TestSweepBatchesAndSkipsDust(t *testing.T) {
+	var keys []*Key
+	balances := map[string]uint64{}
+	for _, bal := range []uint64{50_000, 10, 100_000} {
+		k, _ := GenerateKey()
+		keys = append(keys, k)
+		balances[k.Address().Hex()] = bal
+	}
+	cold, _ := GenerateKey()
+	var sent []Transaction
+	var txBatches int
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var calls []BatchCall
+		if err := json.NewDecoder(r.Body).Decode(&calls); err != nil {
+			t.Errorf("decode batch: %v", err)
+		}
+		out := make([]BatchResult, len(calls))
+		for i, call := range calls {
+			var body interface{}
+			switch {
+			case strings.HasPrefix(call.Path, "/balance/"):
+				body = Balance{Balance: balances[strings.TrimPrefix(call.Path, "/balance/")], Nonce: 3}
+			case call.Path == "/tx":
+				var tx Transaction
+				_ = json.Unmarshal(call.Body, &tx)
+				sent = append(sent, tx)
+				body = map[string]string{"hash": tx.Hash.Hex()}
+				if i == 0 {
+					txBatches++
+				}
+			}
+			raw, _ := json.Marshal(body)
+			out[i] = BatchResult{Status: http.StatusOK, Body: raw}
+		}
+		_ = json.NewEncoder(w).Encode(out)
+	}))
+
+	rep, err := c.Sweep(context.Background(), keys, SweepOptions{
+		Cold: cold.Address(), GasLimit: 21_000, GasPrice: 1, BatchSize: 1,
+	}, nil)
+	if err != nil {
+		t.Fatalf("sweep: %v", err)
+	}
+	if len(sent) != 2 || txBatches != 2 {
+		t.Fatalf("sent %d txs in %d batches, want 2 in 2", len(sent), txBatches)
+	}
+	// largest balance first, each emptied down to the fee
+	if sent[0].From != keys[2].Address() || sent[0].Value != 79_000 || sent[1].Value != 29_000 {
+		t.Fatalf("unexpected transfers %+v", sent)
+	}
+	for _, tx := range sent {
+		if tx.To != cold.Address() || tx.Nonce != 3 || len(tx.Sig) != 65 {
+			t.Fatalf("unexpected tx %+v", tx)
+		}
+	}
+	if rep.Sent != 108_000 || rep.Failed != 0 || len(rep.Plan.Skipped) != 1 || rep.Plan.Skipped[0].Address != keys[1].Address() {
+		t.Fatalf("unexpected report %+v", rep)
+	}
+}
+
+func TestReceiptFromGraphQL(t *testing.T) {
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Variables map[string]string `json:"variables"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if req.Variables["hash"] != "ab" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"data":null,"errors":[{"message":"bad hash"}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":{"tx":{"hash":"ab","blockHeight":3,"blockHash":"cd","index":1,
+			"receipt":{"status":"success","gasLimit":"21000","gasPrice":"2","fee":"42000","logs":[]}}}}`))
+	}))
+	r, err := c.Receipt(context.Background(), "ab")
+	if err != nil {
+		t.Fatalf("receipt: %v", err)
+	}
+	if r.BlockHeight != 3 || r.Fee != 42000 || r.Status != "success" {
+		t.Fatalf("unexpected receipt %+v", r)
+	}
+	if _, err := c.Receipt(context.Background(), "ff"); !IsCode(err, CodeInvalidArgument) {
+		t.Fatalf("expected GraphQL error, got %v", err)
+	}
+}
+
+func TestKeystoreRoundTrip(t *testing.T) {
+	key, _ := GenerateKey()
+	data, err := key.ExportKeystore("pw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	back, err := LoadKeystore(data, "pw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if back.Address() != key.Address() {
+		t.Fatal("address changed after keystore round trip")
+	}
+}
+
+func TestDecodeAddress(t *testing.T) {
+	key, _ := GenerateKey()
+	a := key.Address()
+	for _, s := range []string{a.Hex(), a.Hex()[2:], a.ChecksumHex(), a.Bech32(MainnetAddressHRP)} {
+		if got, err := DecodeAddress(s, MainnetAddressHRP); err != nil || got != a {
+			t.Fatalf("%s: %x err=%v", s, got, err)
+		}
+	}
+	sum := []byte(a.ChecksumHex())
+	for i := 2; i < len(sum); i++ {
+		if c := sum[i]; c >= 'a' && c <= 'f' {
+			sum[i] = c - 'a' + 'A' // break the EIP-55 checksum
+			break
+		}
+		if i == len(sum)-1 {
+			t.Skip("address has no lower-case letter")
+		}
+	}
+	for _, bad := range []string{string(sum), a.Bech32(TestnetAddressHRP), a.Bech32(MainnetAddressHRP)[:40] + "qq", "alice.syn"} {
+		if _, err := DecodeAddress(bad, MainnetAddressHRP); !IsCode(err, CodeInvalidArgument) {
+			t.Fatalf("%s accepted: err=%v", bad, err)
+		}
+	}
+}
+
+func TestSignTxHashesWireFields(t *testing.T) {
+	key, _ := GenerateKey()
+	tx := &Transaction{Type: TxPayment, Value: 5, GasLimit: 21_000, GasPrice: 1, Nonce: 2, Timestamp: 1}
+	if err := key.SignTx(tx); err != nil {
+		t.Fatal(err)
+	}
+	signed := tx.Hash
+	if tx.From != key.Address() || tx.HashTx() != signed {
+		t.Fatal("hash depends on the signature")
+	}
+	tx.Memo = "order 42"
+	if tx.HashTx() == signed {
+		t.Fatal("memo not covered by the hash")
+	}
+	tx.Calls = json.RawMessage(`[{"to":[]}]`)
+	if err := key.SignTx(tx); err == nil {
+		t.Fatal("signed a multicall")
+	}
+}
@@ -0,0 +1,92 @@
+package sdk
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// CallOptions configures a read-only contract call.
+type CallOptions struct {
+	// From is the caller the contract observes; zero by default.
+	From Address
+	// Value is the coin amount the call pretends to send.
+	Value uint64
+	// Gas caps execution; 0 lets the node pick its default.
+	Gas uint64
+	// Height pins the call to a historical block; 0 uses the current head.
+	Height uint64
+}
+
+// archiveCallRequest is the body of POST /archive/{height}/call.
+type archiveCallRequest struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Input string `json:"input"` // hex
+	Value uint64 `json:"value"`
+	Gas   uint64 `json:"gas"`
+}
+
+// CallContract executes input against contract without creating a
+// transaction and returns the output. It is served by the node's archive
+// API, so historical heights require an archive node.
+func (c *Client) CallContract(ctx context.Context, contract Address, input []byte, opts *CallOptions) ([]byte, error) {
+	if opts == nil {
+		opts = &CallOptions{}
+	}
+	height := opts.Height
+	if height == 0 {
+		st, err := c.Status(ctx)
+		if err != nil {
+			return nil, err
+		}
+		height = st.Height
+	}
+	req := archiveCallRequest{
+		From:  opts.From.Hex(),
+		To:    contract.Hex(),
+		Input: hex.EncodeToString(input),
+		Value: opts.Value,
+		Gas:   opts.Gas,
+	}
+	var resp struct {
+		Output string `json:"output"`
+	}
+	if err := c.postNode(ctx, "/archive/"+strconv.FormatUint(height, 10)+"/call", req, &resp); err != nil {
+		return nil, err
+	}
+	out, err := hex.DecodeString(resp.Output)
+	if err != nil {
+		return nil, fmt.Errorf("sdk: decode call output: %w", err)
+	}
+	return out, nil
+}
+
+// InvokeContract signs and submits a contract call transaction carrying
+// input as payload, returning the transaction hash.
+func (c *Client) InvokeContract(ctx context.Context, key *Key, contract Address, input []byte, value uint64, opts *TxOptions) (string, error) {
+	o := TxOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	o.Payload = input
+	tx, err := c.BuildTx(ctx, key.Address(), TxContractCall, contract, value, &o)
+	if err != nil {
+		return "", err
+	}
+	if err := key.SignTx(tx); err != nil {
+		return "", err
+	}
+	return c.SendTx(ctx, tx)
+}
+
+// ContractEvents returns the events indexed for contract.
+func (c *Client) ContractEvents(ctx context.Context, contract Address) ([]Event, error) {
+	var evs []Event
+	if err := c.getNode(ctx, "/events/contract/"+url.PathEscape(contract.Hex()), &evs); err != nil {
+		return nil, err
+	}
+	return evs, nil
+}
@@ -0,0 +1,162 @@
+package sdk
+
+// errors.go – the node's error taxonomy as seen by clients.
+//
+// Servers answer failures with the envelope
+//
+//	{"error": {"code": "NOT_FOUND", "module": "ledger",
+//	           "message": "resource not found", "retryable": false}}
+//
+// which decodes into *Error. Codes are stable across releases; messages
+// are for people and may change.
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+// ErrorCode is a stable, machine-readable failure class.
+type ErrorCode string
+
+const (
+	CodeInvalidArgument    ErrorCode = "INVALID_ARGUMENT"
+	CodeNotFound           ErrorCode = "NOT_FOUND"
+	CodeAlreadyExists      ErrorCode = "ALREADY_EXISTS"
+	CodeUnauthenticated    ErrorCode = "UNAUTHENTICATED"
+	CodePermissionDenied   ErrorCode = "PERMISSION_DENIED"
+	CodeInsufficientFunds  ErrorCode = "INSUFFICIENT_FUNDS"
+	CodeFailedPrecondition ErrorCode = "FAILED_PRECONDITION"
+	CodeConflict           ErrorCode = "CONFLICT"
+	CodeRateLimited        ErrorCode = "RATE_LIMITED"
+	CodeRejected           ErrorCode = "REJECTED"
+	CodeTimeout            ErrorCode = "TIMEOUT"
+	CodeUnavailable        ErrorCode = "UNAVAILABLE"
+	CodeInternal           ErrorCode = "INTERNAL"
+)
+
+// Error is a failure reported by a node or explorer, or a transport
+// failure classified the same way.
+type Error struct {
+	Code      ErrorCode              `json:"code"`
+	Module    string                 `json:"module"`
+	Message   string                 `json:"message"`
+	Retryable bool                   `json:"retryable"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+
+	cause error
+}
+
+// NewError builds an *Error with a formatted message. Retryable defaults to
+// the usual behaviour of code.
+func NewError(code ErrorCode, module, format string, args ...interface{}) *Error {
+	return &Error{Code: code, Module: module, Message: fmt.Sprintf(format, args...), Retryable: retryableCode(code)}
+}
+
+func (e *Error) Error() string {
+	if e.Module == "" {
+		return e.Message
+	}
+	return e.Module + ": " + e.Message
+}
+
+// Unwrap returns the transport error e was classified from, if any.
+func (e *Error) Unwrap() error { return e.cause }
+
+// Is matches another *Error with the same code, so callers can test
+// errors.Is(err, &Error{Code: CodeNotFound}).
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code && (t.Module == "" || t.Module == e.Module)
+}
+
+func retryableCode(c ErrorCode) bool {
+	switch c {
+	case CodeRateLimited, CodeTimeout, CodeUnavailable:
+		return true
+	}
+	return false
+}
+
+// classifyError converts a transport failure into an *Error: timeouts and
+// refused connections are retryable, anything else is CodeInternal.
+func classifyError(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+	out := &Error{Code: CodeInternal, Module: "sdk", Message: err.Error(), cause: err}
+	var ne net.Error
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		out.Code, out.Retryable = CodeTimeout, true
+	case errors.Is(err, context.Canceled):
+		out.Code = CodeUnavailable
+	case errors.As(err, &ne) && ne.Timeout():
+		out.Code, out.Module, out.Retryable = CodeTimeout, "network", true
+	case errors.As(err, new(*net.OpError)):
+		out.Code, out.Module, out.Retryable = CodeUnavailable, "network", true
+	}
+	return out
+}
+
+// codeForStatus is the code of an error response without an envelope.
+func codeForStatus(status int) ErrorCode {
+	switch status {
+	case http.StatusBadRequest:
+		return CodeInvalidArgument
+	case http.StatusUnauthorized:
+		return CodeUnauthenticated
+	case http.StatusForbidden:
+		return CodePermissionDenied
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusPaymentRequired:
+		return CodeInsufficientFunds
+	case http.StatusPreconditionFailed:
+		return CodeFailedPrecondition
+	case http.StatusUnprocessableEntity:
+		return CodeRejected
+	case http.StatusTooManyRequests:
+		return CodeRateLimited
+	case http.StatusServiceUnavailable, http.StatusBadGateway:
+		return CodeUnavailable
+	case http.StatusGatewayTimeout, http.StatusRequestTimeout:
+		return CodeTimeout
+	}
+	return CodeInternal
+}
+
+// decodeErrorBody extracts the error of an HTTP error response. Bodies
+// that are not an envelope become an *Error whose code follows status and
+// whose message is the body text. Older daemons send the error member as a
+// plain string.
+func decodeErrorBody(status int, body []byte) *Error {
+	var env struct {
+		Error json.RawMessage `json:"error"`
+	}
+	if json.Unmarshal(body, &env) == nil && len(env.Error) > 0 {
+		var e Error
+		if json.Unmarshal(env.Error, &e) == nil && e.Code != "" {
+			return &e
+		}
+		var msg string
+		if json.Unmarshal(env.Error, &msg) == nil && msg != "" {
+			return &Error{Code: CodeInternal, Message: msg}
+		}
+	}
+	msg := string(body)
+	if len(msg) > 0 && msg[len(msg)-1] == '\n' {
+		msg = msg[:len(msg)-1]
+	}
+	if msg == "" {
+		msg = http.StatusText(status)
+	}
+	code := codeForStatus(status)
+	return &Error{Code: code, Message: msg, Retryable: retryableCode(code)}
+}
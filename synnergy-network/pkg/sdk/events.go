@@ -0,0 +1,154 @@
+package sdk
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Event is a notification published by the node. Seq numbers every event
+// in emission order starting at 1.
+type Event struct {
+	Seq       uint64 `json:"seq"`
+	ID        string `json:"id"`
+	Type      string `json:"type"`
+	Data      []byte `json:"data"`
+	Height    uint64 `json:"height"`
+	Timestamp int64  `json:"ts"`
+}
+
+// EventFilter selects the events of a subscription. From is the first
+// sequence number delivered; 0 follows new events only.
+type EventFilter struct {
+	From   uint64   `json:"from"`
+	Topics []string `json:"topics,omitempty"`
+}
+
+// Subscription is a live event stream. Events is closed when the
+// subscription ends, after which Err reports why.
+type Subscription struct {
+	Events <-chan Event
+
+	cancel context.CancelFunc
+	mu     sync.Mutex
+	err    error
+}
+
+// Close ends the subscription.
+func (s *Subscription) Close() { s.cancel() }
+
+// Err returns the error that ended the subscription, or nil while it is
+// running or after Close.
+func (s *Subscription) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// Subscribe streams events matching f from the node's /events/ws endpoint
+// until ctx ends or Close is called. Dropped connections are re-established
+// with backoff, resuming after the last delivered sequence number so no
+// event is lost or repeated. The subscription gives up after MaxRetries
+// consecutive failed connection attempts.
+func (c *Client) Subscribe(ctx context.Context, f EventFilter) (*Subscription, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	conn, err := c.dialEvents(ctx, f)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	ch := make(chan Event)
+	s := &Subscription{Events: ch, cancel: cancel}
+	go c.streamEvents(ctx, s, conn, f, ch)
+	return s, nil
+}
+
+func (c *Client) dialEvents(ctx context.Context, f EventFilter) (*websocket.Conn, error) {
+	u := "ws" + strings.TrimPrefix(c.node, "http") + "/events/ws"
+	q := url.Values{}
+	if f.From > 0 {
+		q.Set("from", strconv.FormatUint(f.From, 10))
+	}
+	if len(f.Topics) > 0 {
+		q.Set("topics", strings.Join(f.Topics, ","))
+	}
+	if len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+	conn, resp, err := websocket.DefaultDialer.DialContext(ctx, u, nil)
+	if err != nil {
+		if resp != nil && resp.StatusCode >= 300 {
+			body, _ := io.ReadAll(resp.Body)
+			return nil, decodeErrorBody(resp.StatusCode, body)
+		}
+		return nil, classifyError(err)
+	}
+	return conn, nil
+}
+
+func (c *Client) streamEvents(ctx context.Context, s *Subscription, conn *websocket.Conn, f EventFilter, ch chan<- Event) {
+	defer close(ch)
+	// close the socket on cancellation to unblock ReadJSON
+	stop := context.AfterFunc(ctx, func() {
+		s.mu.Lock()
+		if conn != nil {
+			conn.Close()
+		}
+		s.mu.Unlock()
+	})
+	defer stop()
+	failures := 0
+	wait := c.backoff
+	for {
+		for {
+			var ev Event
+			if err := conn.ReadJSON(&ev); err != nil {
+				break
+			}
+			failures, wait = 0, c.backoff
+			f.From = ev.Seq + 1
+			select {
+			case ch <- ev:
+			case <-ctx.Done():
+				conn.Close()
+				return
+			}
+		}
+		conn.Close()
+		for {
+			if ctx.Err() != nil {
+				return
+			}
+			if failures++; failures > c.retries {
+				s.mu.Lock()
+				s.err = NewError(CodeUnavailable, "events", "event stream lost after %d reconnect attempts", c.retries)
+				s.mu.Unlock()
+				return
+			}
+			t := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				t.Stop()
+				return
+			case <-t.C:
+			}
+			if wait *= 2; wait > maxRetryBackoff {
+				wait = maxRetryBackoff
+			}
+			next, err := c.dialEvents(ctx, f)
+			if err != nil {
+				continue
+			}
+			s.mu.Lock()
+			conn = next
+			s.mu.Unlock()
+			break
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package sdk
+
+import (
+	"crypto/ecdsa"
+	"encoding/hex"
+	"errors"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/uuid"
+)
+
+// Key is a secp256k1 account key, the scheme the ledger verifies
+// transaction signatures with.
+type Key struct {
+	priv *ecdsa.PrivateKey
+}
+
+// GenerateKey creates a random key.
+func GenerateKey() (*Key, error) {
+	priv, err := crypto.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	return &Key{priv: priv}, nil
+}
+
+// KeyFromHex loads a raw 32-byte private key given as hex.
+func KeyFromHex(s string) (*Key, error) {
+	priv, err := crypto.HexToECDSA(strings.TrimPrefix(s, "0x"))
+	if err != nil {
+		return nil, err
+	}
+	return &Key{priv: priv}, nil
+}
+
+// KeyFromECDSA wraps an existing private key.
+func KeyFromECDSA(priv *ecdsa.PrivateKey) (*Key, error) {
+	if priv == nil {
+		return nil, errors.New("sdk: nil private key")
+	}
+	return &Key{priv: priv}, nil
+}
+
+// LoadKeystore decrypts a Web3 Secret Storage (keystore v3) JSON document.
+func LoadKeystore(data []byte, passphrase string) (*Key, error) {
+	k, err := keystore.DecryptKey(data, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return &Key{priv: k.PrivateKey}, nil
+}
+
+// ExportKeystore encrypts the key as a keystore v3 JSON document using the
+// standard scrypt parameters.
+func (k *Key) ExportKeystore(passphrase string) ([]byte, error) {
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return nil, err
+	}
+	return keystore.EncryptKey(&keystore.Key{
+		Id:         id,
+		Address:    crypto.PubkeyToAddress(k.priv.PublicKey),
+		PrivateKey: k.priv,
+	}, passphrase, keystore.StandardScryptN, keystore.StandardScryptP)
+}
+
+// Address returns the account address of the key.
+func (k *Key) Address() Address {
+	return Address(crypto.PubkeyToAddress(k.priv.PublicKey))
+}
+
+// PrivateKeyHex returns the raw private key as hex. Handle with care.
+func (k *Key) PrivateKeyHex() string {
+	return hex.EncodeToString(crypto.FromECDSA(k.priv))
+}
+
+// SignTx hashes and signs tx, setting its Hash, Sig and From fields. The
+// signature is the 65-byte recoverable form the ledger verifies.
+func (k *Key) SignTx(tx *Transaction) error {
+	if len(tx.Calls) > 0 {
+		return errors.New("sdk: multicall transactions cannot be signed")
+	}
+	tx.From = k.Address()
+	tx.HashTx()
+	sig, err := crypto.Sign(tx.Hash[:], k.priv)
+	if err != nil {
+		return err
+	}
+	tx.Sig = sig
+	return nil
+}
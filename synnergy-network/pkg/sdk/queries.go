@@ -0,0 +1,291 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Status returns the chain height, peer count and version of the node.
+func (c *Client) Status(ctx context.Context) (*NodeStatus, error) {
+	var st NodeStatus
+	if err := c.getNode(ctx, "/status", &st); err != nil {
+		return nil, err
+	}
+	return &st, nil
+}
+
+// Balance returns the coin balance of addr and the nonce its next
+// transaction must carry. addr may be hex or bech32.
+func (c *Client) Balance(ctx context.Context, addr string) (*Balance, error) {
+	var b Balance
+	if err := c.getNode(ctx, "/balance/"+url.PathEscape(addr), &b); err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// Block returns the block at height.
+func (c *Client) Block(ctx context.Context, height uint64) (*Block, error) {
+	var b Block
+	if err := c.getNode(ctx, "/block/"+strconv.FormatUint(height, 10), &b); err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// EstimateFee returns the gas price the node expects to be included
+// within blocks blocks, estimated from its recent blocks.
+func (c *Client) EstimateFee(ctx context.Context, blocks int) (*FeeEstimate, error) {
+	var est FeeEstimate
+	if err := c.getNode(ctx, "/fees/estimate?blocks="+strconv.Itoa(blocks), &est); err != nil {
+		return nil, err
+	}
+	return &est, nil
+}
+
+// Blocks lists block summaries newest first. Pages start at 1.
+func (c *Client) Blocks(ctx context.Context, page, perPage int) ([]BlockSummary, *Pagination, error) {
+	var out []BlockSummary
+	p, err := c.page(ctx, "/api/v1/blocks", page, perPage, &out)
+	return out, p, err
+}
+
+// Tx returns an included transaction by its hex hash.
+func (c *Client) Tx(ctx context.Context, hash string) (*TxInfo, error) {
+	var tx TxInfo
+	if err := c.getExplorer(ctx, "/api/v1/txs/"+url.PathEscape(hash), &tx); err != nil {
+		return nil, err
+	}
+	return &tx, nil
+}
+
+// Account returns the balances and transaction count of an address; addr
+// may be hex, bech32 or a .syn name.
+func (c *Client) Account(ctx context.Context, addr string) (*Account, error) {
+	var a Account
+	if err := c.getExplorer(ctx, "/api/v1/address/"+url.PathEscape(addr), &a); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// AccountTxs lists the transactions of an address newest first.
+func (c *Client) AccountTxs(ctx context.Context, addr string, page, perPage int) ([]TxInfo, *Pagination, error) {
+	var out []TxInfo
+	p, err := c.page(ctx, "/api/v1/address/"+url.PathEscape(addr)+"/txs", page, perPage, &out)
+	return out, p, err
+}
+
+// Name returns the registration and resolver records of an SNS name.
+func (c *Client) Name(ctx context.Context, name string) (*NameRecord, error) {
+	var n NameRecord
+	if err := c.getExplorer(ctx, "/api/v1/names/"+url.PathEscape(name), &n); err != nil {
+		return nil, err
+	}
+	return &n, nil
+}
+
+// ResolveRecipient turns a .syn name or an encoded address into an
+// address. Names are resolved through the explorer.
+func (c *Client) ResolveRecipient(ctx context.Context, s string) (Address, error) {
+	if !IsName(s) {
+		return DecodeAddress(s, c.hrp)
+	}
+	n, err := c.Name(ctx, s)
+	if err != nil {
+		return Address{}, err
+	}
+	if !n.Active || n.Address == "" {
+		return Address{}, NewError(CodeFailedPrecondition, "sns", "%s does not resolve to an address", n.Name)
+	}
+	return DecodeAddress(n.Address, c.hrp)
+}
+
+// page fetches one page of an explorer list endpoint.
+func (c *Client) page(ctx context.Context, path string, page, perPage int, out interface{}) (*Pagination, error) {
+	q := url.Values{}
+	if page > 0 {
+		q.Set("page", strconv.Itoa(page))
+	}
+	if perPage > 0 {
+		q.Set("per_page", strconv.Itoa(perPage))
+	}
+	if len(q) > 0 {
+		path += "?" + q.Encode()
+	}
+	var resp struct {
+		Data       json.RawMessage `json:"data"`
+		Pagination Pagination      `json:"pagination"`
+	}
+	if err := c.getExplorer(ctx, path, &resp); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(resp.Data, out); err != nil {
+		return nil, fmt.Errorf("sdk: decode %s: %w", path, err)
+	}
+	return &resp.Pagination, nil
+}
+
+// gqlResponse is the envelope of a GraphQL response.
+type gqlResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+func (r *gqlResponse) err() error {
+	if len(r.Errors) == 0 {
+		return nil
+	}
+	msgs := make([]string, len(r.Errors))
+	for i, e := range r.Errors {
+		msgs[i] = e.Message
+	}
+	return NewError(CodeInvalidArgument, "graphql", "%s", strings.Join(msgs, "; "))
+}
+
+func (r *gqlResponse) decodeError(data []byte) error {
+	if json.Unmarshal(data, r) != nil {
+		return nil
+	}
+	return r.err()
+}
+
+// graphql runs a query against the explorer's /graphql endpoint and decodes
+// its data into out.
+func (c *Client) graphql(ctx context.Context, query string, vars map[string]interface{}, out interface{}) error {
+	if c.explorer == "" {
+		return ErrNoExplorer
+	}
+	var resp gqlResponse
+	req := map[string]interface{}{"query": query, "variables": vars}
+	if err := c.do(ctx, http.MethodPost, c.explorer, "/graphql", req, &resp); err != nil {
+		return err
+	}
+	if err := resp.err(); err != nil {
+		return err
+	}
+	return json.Unmarshal(resp.Data, out)
+}
+
+// amount parses the decimal strings GraphQL uses for 64-bit amounts.
+type amount uint64
+
+func (a *amount) UnmarshalJSON(b []byte) error {
+	n, err := strconv.ParseUint(strings.Trim(string(b), `"`), 10, 64)
+	*a = amount(n)
+	return err
+}
+
+const receiptQuery = `query($hash: String!) {
+  tx(hash: $hash) {
+    hash blockHeight blockHash index
+    receipt { status gasLimit gasPrice fee logs { contract name data } }
+  }
+}`
+
+// receiptData is the data of receiptQuery.
+type receiptData struct {
+	Tx *struct {
+		Hash        string `json:"hash"`
+		BlockHeight uint64 `json:"blockHeight"`
+		BlockHash   string `json:"blockHash"`
+		Index       int    `json:"index"`
+		Receipt     *struct {
+			Status   string `json:"status"`
+			GasLimit amount `json:"gasLimit"`
+			GasPrice amount `json:"gasPrice"`
+			Fee      amount `json:"fee"`
+			Logs     []Log  `json:"logs"`
+		} `json:"receipt"`
+	} `json:"tx"`
+}
+
+func (d *receiptData) receipt(hash string) (*Receipt, error) {
+	if d.Tx == nil || d.Tx.Receipt == nil {
+		return nil, NewError(CodeNotFound, "explorer", "no receipt for %s", hash)
+	}
+	r := d.Tx.Receipt
+	return &Receipt{
+		TxHash:      d.Tx.Hash,
+		Status:      r.Status,
+		BlockHeight: d.Tx.BlockHeight,
+		BlockHash:   d.Tx.BlockHash,
+		Index:       d.Tx.Index,
+		GasLimit:    uint64(r.GasLimit),
+		GasPrice:    uint64(r.GasPrice),
+		Fee:         uint64(r.Fee),
+		Logs:        r.Logs,
+	}, nil
+}
+
+// Receipt returns the receipt of an included transaction. It fails with
+// CodeNotFound while the transaction is still pending.
+func (c *Client) Receipt(ctx context.Context, hash string) (*Receipt, error) {
+	var data receiptData
+	if err := c.graphql(ctx, receiptQuery, map[string]interface{}{"hash": hash}, &data); err != nil {
+		return nil, err
+	}
+	return data.receipt(hash)
+}
+
+// WaitForReceipt polls for the receipt of hash every interval (default
+// one second) until it is available or ctx ends.
+func (c *Client) WaitForReceipt(ctx context.Context, hash string, interval time.Duration) (*Receipt, error) {
+	if interval <= 0 {
+		interval = time.Second
+	}
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		r, err := c.Receipt(ctx, hash)
+		if err == nil || !IsCode(err, CodeNotFound) {
+			return r, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+const poolsQuery = `{ pools { id tokenA tokenB reserveA reserveB totalLP feeBps } }`
+
+// Pools lists the AMM liquidity pools.
+func (c *Client) Pools(ctx context.Context) ([]Pool, error) {
+	var data struct {
+		Pools []struct {
+			ID       uint64 `json:"id"`
+			TokenA   uint64 `json:"tokenA"`
+			TokenB   uint64 `json:"tokenB"`
+			ReserveA amount `json:"reserveA"`
+			ReserveB amount `json:"reserveB"`
+			TotalLP  amount `json:"totalLP"`
+			FeeBps   uint16 `json:"feeBps"`
+		} `json:"pools"`
+	}
+	if err := c.graphql(ctx, poolsQuery, nil, &data); err != nil {
+		return nil, err
+	}
+	out := make([]Pool, len(data.Pools))
+	for i, p := range data.Pools {
+		out[i] = Pool{
+			ID:       p.ID,
+			TokenA:   p.TokenA,
+			TokenB:   p.TokenB,
+			ReserveA: uint64(p.ReserveA),
+			ReserveB: uint64(p.ReserveB),
+			TotalLP:  uint64(p.TotalLP),
+			FeeBps:   p.FeeBps,
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,273 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/bits"
+	"net/http"
+	"sort"
+	"strconv"
+)
+
+// SweepOptions configure a sweep of deposit addresses into cold storage.
+type SweepOptions struct {
+	Cold      Address `json:"cold"`
+	GasLimit  uint64  `json:"gas_limit"`  // per transfer
+	GasPrice  uint64  `json:"gas_price"`  // per gas unit
+	MinAmount uint64  `json:"min_amount"` // smallest amount worth moving after fees
+	BatchSize int     `json:"batch_size"` // default MaxBatchCalls
+}
+
+// SweepTransfer moves a deposit address's balance, less the fee, to cold
+// storage.
+type SweepTransfer struct {
+	From   Address `json:"from"`
+	Amount uint64  `json:"amount"`
+	Fee    uint64  `json:"fee"`
+	Nonce  uint64  `json:"nonce"`
+}
+
+// SweepSkip is a deposit address left alone and why.
+type SweepSkip struct {
+	Address Address `json:"address"`
+	Balance uint64  `json:"balance"`
+	Reason  string  `json:"reason"`
+}
+
+// SweepPlan is the set of transfers of a sweep.
+type SweepPlan struct {
+	Cold      Address         `json:"cold"`
+	GasLimit  uint64          `json:"gas_limit"`
+	GasPrice  uint64          `json:"gas_price"`
+	BatchSize int             `json:"batch_size"`
+	Transfers []SweepTransfer `json:"transfers"`
+	Skipped   []SweepSkip     `json:"skipped,omitempty"`
+	Total     uint64          `json:"total"`
+	Fees      uint64          `json:"fees"`
+}
+
+// Batches splits the transfers into groups of at most BatchSize.
+func (p *SweepPlan) Batches() [][]SweepTransfer {
+	n := p.BatchSize
+	if n <= 0 {
+		n = MaxBatchCalls
+	}
+	var out [][]SweepTransfer
+	for t := p.Transfers; len(t) > 0; {
+		k := min(n, len(t))
+		out = append(out, t[:k])
+		t = t[k:]
+	}
+	return out
+}
+
+// AuditLog records the steps of a sweep; a node's audit trail satisfies it.
+type AuditLog interface {
+	Log(event string, meta map[string]string) error
+}
+
+// sweepSource is a deposit address with its balance and next nonce.
+type sweepSource struct {
+	addr    Address
+	balance uint64
+	nonce   uint64
+}
+
+// planSweep plans moving the balances of sources to opts.Cold. Each
+// transfer leaves exactly the fee behind so the address is emptied. The
+// largest balances come first, so a sweep cut short has moved the most.
+func planSweep(sources []sweepSource, opts SweepOptions) (*SweepPlan, error) {
+	if opts.Cold == (Address{}) {
+		return nil, NewError(CodeInvalidArgument, "exchange", "cold address required")
+	}
+	if opts.GasLimit == 0 || opts.GasPrice == 0 {
+		return nil, NewError(CodeInvalidArgument, "exchange", "gas limit and price must be >0")
+	}
+	hi, fee := bits.Mul64(opts.GasLimit, opts.GasPrice)
+	if hi != 0 {
+		return nil, NewError(CodeInvalidArgument, "exchange", "sweep fee overflows")
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = MaxBatchCalls
+	}
+	p := &SweepPlan{Cold: opts.Cold, GasLimit: opts.GasLimit, GasPrice: opts.GasPrice, BatchSize: opts.BatchSize}
+	seen := make(map[Address]bool, len(sources))
+	for _, s := range sources {
+		switch {
+		case seen[s.addr]:
+			continue
+		case s.addr == opts.Cold:
+			p.Skipped = append(p.Skipped, SweepSkip{s.addr, s.balance, "cold address"})
+		case s.balance <= fee || s.balance-fee < opts.MinAmount:
+			p.Skipped = append(p.Skipped, SweepSkip{s.addr, s.balance, "balance does not cover fee and minimum"})
+		default:
+			p.Transfers = append(p.Transfers, SweepTransfer{From: s.addr, Amount: s.balance - fee, Fee: fee, Nonce: s.nonce})
+			p.Total += s.balance - fee
+			p.Fees += fee
+		}
+		seen[s.addr] = true
+	}
+	sort.SliceStable(p.Transfers, func(i, j int) bool { return p.Transfers[i].Amount > p.Transfers[j].Amount })
+	return p, nil
+}
+
+// SweepResult is the outcome of one transfer of a sweep.
+type SweepResult struct {
+	SweepTransfer
+	Batch  int    `json:"batch"`
+	TxHash string `json:"tx_hash,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// SweepReport is what Sweep planned and submitted.
+type SweepReport struct {
+	Plan    *SweepPlan    `json:"plan"`
+	Results []SweepResult `json:"results,omitempty"`
+	Sent    uint64        `json:"sent"`
+	Failed  int           `json:"failed"`
+}
+
+// PlanSweep fetches the balances and nonces of the deposit keys with batched
+// lookups and plans moving them to opts.Cold.
+func (c *Client) PlanSweep(ctx context.Context, keys []*Key, opts SweepOptions) (*SweepPlan, error) {
+	addrs := make([]string, len(keys))
+	for i, k := range keys {
+		addrs[i] = k.Address().Hex()
+	}
+	bals, err := c.Balances(ctx, addrs)
+	if bals == nil {
+		return nil, err
+	}
+	var sources []sweepSource
+	var missing []SweepSkip
+	for i, b := range bals {
+		if b == nil {
+			missing = append(missing, SweepSkip{Address: keys[i].Address(), Reason: "balance lookup failed"})
+			continue
+		}
+		sources = append(sources, sweepSource{addr: keys[i].Address(), balance: b.Balance, nonce: b.Nonce})
+	}
+	plan, perr := planSweep(sources, opts)
+	if perr != nil {
+		return nil, perr
+	}
+	plan.Skipped = append(plan.Skipped, missing...)
+	return plan, nil
+}
+
+// Sweep plans and executes a sweep of the deposit keys to opts.Cold. Each
+// batch of signed transfers goes to the node in a single /batch request.
+// When audit is non-nil every planned, skipped, submitted and failed
+// transfer is recorded in it. A failed transfer does not stop the sweep;
+// the report lists it and the returned error joins the failures.
+func (c *Client) Sweep(ctx context.Context, keys []*Key, opts SweepOptions, audit AuditLog) (*SweepReport, error) {
+	plan, err := c.PlanSweep(ctx, keys, opts)
+	if err != nil {
+		return nil, err
+	}
+	rep := &SweepReport{Plan: plan}
+	log := func(event string, meta map[string]string) {
+		if audit != nil {
+			_ = audit.Log(event, meta)
+		}
+	}
+	log("sweep.plan", map[string]string{
+		"cold":      plan.Cold.Hex(),
+		"transfers": strconv.Itoa(len(plan.Transfers)),
+		"skipped":   strconv.Itoa(len(plan.Skipped)),
+		"total":     strconv.FormatUint(plan.Total, 10),
+		"fees":      strconv.FormatUint(plan.Fees, 10),
+		"gas_price": strconv.FormatUint(plan.GasPrice, 10),
+	})
+	for _, s := range plan.Skipped {
+		log("sweep.skip", map[string]string{
+			"from":    s.Address.Hex(),
+			"balance": strconv.FormatUint(s.Balance, 10),
+			"reason":  s.Reason,
+		})
+	}
+
+	byAddr := make(map[Address]*Key, len(keys))
+	for _, k := range keys {
+		byAddr[k.Address()] = k
+	}
+	var errs []error
+	for bi, batch := range plan.Batches() {
+		calls := make([]BatchCall, len(batch))
+		results := make([]SweepResult, len(batch))
+		for i, t := range batch {
+			results[i] = SweepResult{SweepTransfer: t, Batch: bi}
+			nonce := t.Nonce
+			tx, err := c.BuildTx(ctx, t.From, TxPayment, plan.Cold, t.Amount,
+				&TxOptions{GasLimit: plan.GasLimit, GasPrice: plan.GasPrice, Nonce: &nonce})
+			if err == nil {
+				err = byAddr[t.From].SignTx(tx)
+			}
+			if err != nil {
+				results[i].Error = err.Error()
+				continue
+			}
+			results[i].TxHash = tx.Hash.Hex()
+			body, _ := json.Marshal(tx)
+			calls[i] = BatchCall{Method: http.MethodPost, Path: "/tx", Body: body}
+		}
+		res, err := c.batch(ctx, c.node, "/batch", compactCalls(calls))
+		if err != nil {
+			// nothing from this batch is known to have been accepted
+			for i := range results {
+				if results[i].Error == "" {
+					results[i].Error = err.Error()
+				}
+			}
+		}
+		j := 0
+		for i := range results {
+			if calls[i].Path == "" || err != nil {
+				continue
+			}
+			if rerr := resultError(res[j]); rerr != nil {
+				results[i].Error = rerr.Error()
+			}
+			j++
+		}
+		for _, r := range results {
+			meta := map[string]string{
+				"batch":  strconv.Itoa(bi),
+				"from":   r.From.Hex(),
+				"to":     plan.Cold.Hex(),
+				"amount": strconv.FormatUint(r.Amount, 10),
+				"fee":    strconv.FormatUint(r.Fee, 10),
+				"nonce":  strconv.FormatUint(r.Nonce, 10),
+				"tx":     r.TxHash,
+			}
+			if r.Error != "" {
+				meta["error"] = r.Error
+				log("sweep.failed", meta)
+				errs = append(errs, fmt.Errorf("%s: %s", r.From.Hex(), r.Error))
+				rep.Failed++
+			} else {
+				log("sweep.submitted", meta)
+				rep.Sent += r.Amount
+			}
+		}
+		rep.Results = append(rep.Results, results...)
+	}
+	log("sweep.done", map[string]string{
+		"sent":   strconv.FormatUint(rep.Sent, 10),
+		"failed": strconv.Itoa(rep.Failed),
+	})
+	return rep, errors.Join(errs...)
+}
+
+// compactCalls drops the calls of transfers that could not be signed.
+func compactCalls(calls []BatchCall) []BatchCall {
+	out := calls[:0:0]
+	for _, c := range calls {
+		if c.Path != "" {
+			out = append(out, c)
+		}
+	}
+	return out
+}
@@ -0,0 +1,207 @@
+package sdk
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"time"
+)
+
+// TxType selects how the ledger applies a transaction.
+type TxType uint8
+
+const (
+	// TxPayment transfers value between addresses.
+	TxPayment TxType = iota + 1
+	// TxContractCall executes a smart contract.
+	TxContractCall
+)
+
+// TokenID identifies a token registered on the ledger.
+type TokenID uint32
+
+// TokenTransfer is a token movement carried by a transaction.
+type TokenTransfer struct {
+	From   Address
+	To     Address
+	Token  TokenID
+	Amount uint64
+}
+
+// Transaction is the wire form of a ledger transaction. Fields the SDK does
+// not build itself (UTXO inputs and outputs, contract metadata, multicall
+// calls) are kept as raw JSON so transactions read from a node round-trip
+// unchanged.
+type Transaction struct {
+	Type             TxType            `json:"type"`
+	From             Address           `json:"from"`
+	To               Address           `json:"to"`
+	Value            uint64            `json:"value"`
+	GasLimit         uint64            `json:"gas_limit"`
+	GasPrice         uint64            `json:"gas_price"`
+	Nonce            uint64            `json:"nonce"`
+	Timestamp        int64             `json:"timestamp"`
+	Payload          []byte            `json:"payload,omitempty"`
+	DestinationTag   uint64            `json:"dest_tag,omitempty"`
+	Memo             string            `json:"memo,omitempty"`
+	Private          bool              `json:"private,omitempty"`
+	EncryptedPayload []byte            `json:"encrypted_payload,omitempty"`
+	AuthSigs         [][]byte          `json:"auth_sigs,omitempty"`
+	OriginalTx       Hash              `json:"orig,omitempty"`
+	Sig              []byte            `json:"sig"`
+	Hash             Hash              `json:"hash"`
+	Inputs           json.RawMessage   `json:"inputs,omitempty"`
+	Outputs          json.RawMessage   `json:"outputs,omitempty"`
+	StateChanges     map[string][]byte `json:"state,omitempty"`
+	Contract         json.RawMessage   `json:"contract,omitempty"`
+	TokenTransfers   []TokenTransfer   `json:"token_transfers,omitempty"`
+	Calls            json.RawMessage   `json:"calls,omitempty"`
+}
+
+// HashTx computes the hash the ledger signs and verifies, stores it in
+// tx.Hash and returns it. Multicall transactions are not covered; SignTx
+// refuses them.
+func (tx *Transaction) HashTx() Hash {
+	h := sha256.New()
+	h.Write([]byte{byte(tx.Type)})
+	h.Write(tx.From[:])
+	h.Write(tx.To[:])
+	buf := make([]byte, 8)
+	for _, v := range []uint64{tx.Value, tx.GasLimit, tx.GasPrice, tx.Nonce} {
+		binary.LittleEndian.PutUint64(buf, v)
+		h.Write(buf)
+	}
+	h.Write(tx.Payload)
+	h.Write(tx.EncryptedPayload)
+	h.Write(tx.OriginalTx[:])
+	binary.LittleEndian.PutUint64(buf, uint64(tx.Timestamp))
+	h.Write(buf)
+	// untagged transactions keep the hash they had before tags existed
+	if tx.DestinationTag != 0 || tx.Memo != "" {
+		binary.LittleEndian.PutUint64(buf, tx.DestinationTag)
+		h.Write(buf)
+		h.Write([]byte(tx.Memo))
+	}
+	tx.Hash = sha256.Sum256(h.Sum(nil))
+	return tx.Hash
+}
+
+// Default gas parameters for transactions built by the SDK.
+const (
+	DefaultGasLimit uint64 = 21000
+	DefaultGasPrice uint64 = 1
+)
+
+// TxOptions overrides the defaults BuildTx fills in. A nil Nonce is fetched
+// from the node.
+type TxOptions struct {
+	GasLimit uint64
+	GasPrice uint64
+	Nonce    *uint64
+	Payload  []byte
+	// DestinationTag and Memo tell an exchange's hot address which
+	// customer to credit.
+	DestinationTag uint64
+	Memo           string
+	// TargetBlocks, when GasPrice is unset, prices the transaction with the
+	// node's fee estimate for inclusion within that many blocks.
+	TargetBlocks int
+}
+
+// BuildTx assembles an unsigned transaction sent by from. Unless
+// opts sets it, the nonce is the next one the node expects.
+func (c *Client) BuildTx(ctx context.Context, from Address, typ TxType, to Address, value uint64, opts *TxOptions) (*Transaction, error) {
+	if opts == nil {
+		opts = &TxOptions{}
+	}
+	tx := &Transaction{
+		Type:      typ,
+		From:      from,
+		To:        to,
+		Value:     value,
+		GasLimit:  opts.GasLimit,
+		GasPrice:  opts.GasPrice,
+		Payload:   opts.Payload,
+		Timestamp: time.Now().UnixMilli(),
+
+		DestinationTag: opts.DestinationTag,
+		Memo:           opts.Memo,
+	}
+	if tx.GasLimit == 0 {
+		tx.GasLimit = DefaultGasLimit
+	}
+	if tx.GasPrice == 0 && opts.TargetBlocks > 0 {
+		est, err := c.EstimateFee(ctx, opts.TargetBlocks)
+		if err != nil {
+			return nil, err
+		}
+		tx.GasPrice = est.GasPrice
+	}
+	if tx.GasPrice == 0 {
+		tx.GasPrice = DefaultGasPrice
+	}
+	if opts.Nonce != nil {
+		tx.Nonce = *opts.Nonce
+	} else {
+		b, err := c.Balance(ctx, from.Hex())
+		if err != nil {
+			return nil, err
+		}
+		tx.Nonce = b.Nonce
+	}
+	return tx, nil
+}
+
+// SendTx submits a signed transaction to the node's pool and returns its
+// hash. Use WaitForReceipt to follow its inclusion.
+func (c *Client) SendTx(ctx context.Context, tx *Transaction) (string, error) {
+	if len(tx.Sig) == 0 {
+		return "", NewError(CodeInvalidArgument, "sdk", "transaction is not signed")
+	}
+	var resp struct {
+		Hash string `json:"hash"`
+	}
+	if err := c.postNode(ctx, "/tx", tx, &resp); err != nil {
+		return "", err
+	}
+	if resp.Hash == "" {
+		resp.Hash = tx.Hash.Hex()
+	}
+	return resp.Hash, nil
+}
+
+// Simulate dry-runs tx on the node without committing it and returns the
+// expected receipt, fee and state changes. tx need not be signed; opts may
+// be nil.
+func (c *Client) Simulate(ctx context.Context, tx *Transaction, opts *SimulateOptions) (*SimulationResult, error) {
+	req := struct {
+		Tx Transaction `json:"tx"`
+		SimulateOptions
+	}{Tx: *tx}
+	if opts != nil {
+		req.SimulateOptions = *opts
+	}
+	var res SimulationResult
+	if err := c.postNode(ctx, "/tx/simulate", req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// Transfer sends value coins from key's account to a hex or bech32
+// address or .syn name.
+func (c *Client) Transfer(ctx context.Context, key *Key, to string, value uint64, opts *TxOptions) (string, error) {
+	addr, err := c.ResolveRecipient(ctx, to)
+	if err != nil {
+		return "", err
+	}
+	tx, err := c.BuildTx(ctx, key.Address(), TxPayment, addr, value, opts)
+	if err != nil {
+		return "", err
+	}
+	if err := key.SignTx(tx); err != nil {
+		return "", err
+	}
+	return c.SendTx(ctx, tx)
+}
@@ -0,0 +1,224 @@
+package sdk
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Balance is the coin balance of an address and the nonce its next
+// transaction must use.
+type Balance struct {
+	Balance uint64 `json:"balance"`
+	Nonce   uint64 `json:"nonce"`
+}
+
+// BlockSummary is the list representation of a block.
+type BlockSummary struct {
+	Height    uint64 `json:"height"`
+	Hash      string `json:"hash"`
+	PrevHash  string `json:"prev_hash"`
+	Timestamp int64  `json:"timestamp"`
+	Txs       int    `json:"txs"`
+	Miner     string `json:"miner,omitempty"`
+}
+
+// TxInfo is an included transaction and its position in the chain.
+type TxInfo struct {
+	Hash        string       `json:"hash"`
+	BlockHeight uint64       `json:"block_height"`
+	BlockHash   string       `json:"block_hash"`
+	Index       int          `json:"index"`
+	From        string       `json:"from"`
+	To          string       `json:"to"`
+	Value       uint64       `json:"value"`
+	Timestamp   int64        `json:"timestamp"`
+	Tx          *Transaction `json:"tx,omitempty"`
+}
+
+// Receipt is the outcome of an included transaction.
+type Receipt struct {
+	TxHash      string `json:"tx_hash"`
+	Status      string `json:"status"`
+	BlockHeight uint64 `json:"block_height"`
+	BlockHash   string `json:"block_hash"`
+	Index       int    `json:"index"`
+	GasLimit    uint64 `json:"gas_limit"`
+	GasPrice    uint64 `json:"gas_price"`
+	Fee         uint64 `json:"fee"`
+	Logs        []Log  `json:"logs"`
+}
+
+// Log is a contract event emitted by a transaction; Data is hex.
+type Log struct {
+	Contract string `json:"contract"`
+	Name     string `json:"name"`
+	Data     string `json:"data"`
+}
+
+// Pool is an AMM liquidity pool.
+type Pool struct {
+	ID       uint64 `json:"id"`
+	TokenA   uint64 `json:"token_a"`
+	TokenB   uint64 `json:"token_b"`
+	ReserveA uint64 `json:"reserve_a"`
+	ReserveB uint64 `json:"reserve_b"`
+	TotalLP  uint64 `json:"total_lp"`
+	FeeBps   uint16 `json:"fee_bps"`
+}
+
+// TokenBalance is one token held by an account.
+type TokenBalance struct {
+	ID      TokenID `json:"id"`
+	Symbol  string  `json:"symbol"`
+	Balance uint64  `json:"balance"`
+}
+
+// Account summarises an address as indexed by the explorer.
+type Account struct {
+	Address string         `json:"address"`
+	Name    string         `json:"name,omitempty"`
+	Balance uint64         `json:"balance"`
+	Tokens  []TokenBalance `json:"tokens"`
+	TxCount int            `json:"tx_count"`
+}
+
+// NameRecord is a registered SNS name.
+type NameRecord struct {
+	Name    string            `json:"name"`
+	Owner   string            `json:"owner"`
+	Address string            `json:"address,omitempty"`
+	Content string            `json:"content,omitempty"`
+	Text    map[string]string `json:"text,omitempty"`
+	Expires int64             `json:"expires"`
+	Active  bool              `json:"active"`
+}
+
+// Pagination describes one page of a list.
+type Pagination struct {
+	Page    int `json:"page"`
+	PerPage int `json:"per_page"`
+	Total   int `json:"total"`
+}
+
+// NodeStatus is the health summary of an API node. Consensus is the latest
+// adaptive weight sample, if the node has recorded one.
+type NodeStatus struct {
+	Height    uint64          `json:"height"`
+	Peers     int             `json:"peers"`
+	Version   string          `json:"version"`
+	Consensus json.RawMessage `json:"consensus,omitempty"`
+	ReadView  ReadViewStats   `json:"read_view"`
+}
+
+// ReadViewStats describes the snapshot the node serves reads from.
+type ReadViewStats struct {
+	Active    bool          `json:"active"`
+	Height    uint64        `json:"height"`
+	Head      uint64        `json:"head"`
+	LagBlocks uint64        `json:"lag_blocks"`
+	Age       time.Duration `json:"age"`
+}
+
+// BlockHeader is the header of a block as the node encodes it.
+type BlockHeader struct {
+	Height    uint64
+	Timestamp int64
+	PrevHash  []byte
+	PoWHash   []byte
+	Nonce     uint64
+	MinerPk   []byte
+	LogsBloom json.RawMessage `json:",omitempty"`
+}
+
+// Block is a block with its transactions. Body holds the sub-block
+// headers and logs as sent by the node.
+type Block struct {
+	Header       BlockHeader     `json:"header"`
+	Body         json.RawMessage `json:"body"`
+	Transactions []*Transaction  `json:"txs"`
+}
+
+// FeeEstimate is a gas price likely to be included within TargetBlocks.
+type FeeEstimate struct {
+	TargetBlocks int     `json:"target_blocks"`
+	GasPrice     uint64  `json:"gas_price"`
+	Confidence   float64 `json:"confidence"`
+	Bucket       string  `json:"bucket"`
+	Fullness     float64 `json:"fullness"`
+	Samples      int     `json:"samples"`
+	Height       uint64  `json:"height"`
+}
+
+// StateOverride replaces parts of an account's state for one simulation.
+type StateOverride struct {
+	Balance *uint64           `json:"balance,omitempty"`
+	Nonce   *uint64           `json:"nonce,omitempty"`
+	Code    string            `json:"code,omitempty"`    // hex contract bytecode
+	Storage map[string]string `json:"storage,omitempty"` // hex state key -> hex value, "" deletes
+}
+
+// SimulateOptions select the state a transaction is simulated against.
+type SimulateOptions struct {
+	// Height simulates against the state after that block; nil is the head.
+	// Past heights need an archive node.
+	Height *uint64 `json:"height,omitempty"`
+	// Overrides are keyed by hex or bech32 address.
+	Overrides map[string]StateOverride `json:"overrides,omitempty"`
+}
+
+// ExecReceipt is the execution outcome of a simulated transaction. Logs,
+// the decoded revert payload and multicall results are left as the node
+// encodes them.
+type ExecReceipt struct {
+	Status     bool            `json:"status"`
+	GasUsed    uint64          `json:"gas_used"`
+	GasRefund  uint64          `json:"gas_refund,omitempty"`
+	ReturnData []byte          `json:"return_data,omitempty"`
+	Logs       json.RawMessage `json:"logs,omitempty"`
+	Error      string          `json:"error,omitempty"`
+	Revert     json.RawMessage `json:"revert,omitempty"`
+	Calls      json.RawMessage `json:"calls,omitempty"`
+}
+
+// SimulationResult is the expected outcome of a transaction.
+type SimulationResult struct {
+	Height  uint64      `json:"height"`
+	Receipt ExecReceipt `json:"receipt"`
+	Fee     uint64      `json:"fee"`
+	// Rejected is why the pool would refuse the transaction; it was not
+	// executed.
+	Rejected string `json:"rejected,omitempty"`
+	// RevertReason is why an executed contract call failed.
+	RevertReason string    `json:"revert_reason,omitempty"`
+	Diff         StateDiff `json:"diff"`
+}
+
+// AccountDiff is the coin balance change of one account.
+type AccountDiff struct {
+	Address string `json:"address"`
+	Before  uint64 `json:"before"`
+	After   uint64 `json:"after"`
+	Delta   int64  `json:"delta"`
+}
+
+// StorageDiff is the change of one raw state key, all values hex.
+type StorageDiff struct {
+	Key     string `json:"key"`
+	Before  string `json:"before,omitempty"`
+	After   string `json:"after,omitempty"`
+	Created bool   `json:"created,omitempty"`
+	Deleted bool   `json:"deleted,omitempty"`
+}
+
+// StateDiff is the state a transaction or block changed.
+type StateDiff struct {
+	Height             uint64        `json:"height"`
+	BlockHash          string        `json:"block_hash,omitempty"`
+	TxHash             string        `json:"tx_hash,omitempty"`
+	Accounts           []AccountDiff `json:"accounts"`
+	Storage            []StorageDiff `json:"storage"`
+	ContractsCreated   []string      `json:"contracts_created,omitempty"`
+	ContractsDestroyed []string      `json:"contracts_destroyed,omitempty"`
+	UTXOsSpent         []string      `json:"utxos_spent,omitempty"`
+	UTXOsCreated       []string      `json:"utxos_created,omitempty"`
+}
@@ -0,0 +1,4 @@
+package sdk
+
+// Version is the semantic version of the SDK.
+const Version = "v0.1.0"
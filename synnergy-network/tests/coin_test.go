@@ -22,9 +22,9 @@ func bytesToAddress(b []byte) Address {
 	return a
 }
 
-// keyForLedgerBalance replicates the key format used by Ledger.MintToken.
-func keyForLedgerBalance(a Address) string {
-	return fmt.Sprintf("%s:%s", a.String(), Code)
+// keyForLedgerBalance returns the key Ledger.MintToken credits for the coin.
+func keyForLedgerBalance(a Address) BalanceKey {
+	return CoinKey(a)
 }
 
 /*
@@ -41,13 +41,13 @@ func TestNewCoin(t *testing.T) {
 
 	tests := []struct {
 		name          string
-		tokenBalances map[string]uint64
+		tokenBalances map[BalanceKey]uint64
 		wantTotal     uint64
 		wantErr       bool
 	}{
 		{
 			name: "success balances under cap",
-			tokenBalances: map[string]uint64{
+			tokenBalances: map[BalanceKey]uint64{
 				keyForLedgerBalance(addrA): 100,
 				keyForLedgerBalance(addrB): 200,
 			},
@@ -55,7 +55,7 @@ func TestNewCoin(t *testing.T) {
 		},
 		{
 			name: "total exceeds MaxSupply",
-			tokenBalances: map[string]uint64{
+			tokenBalances: map[BalanceKey]uint64{
 				keyForLedgerBalance(addrA): MaxSupply + 1,
 			},
 			wantErr: true,
@@ -67,7 +67,7 @@ func TestNewCoin(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			// Build an in-memory ledger with the desired balances.
 			ldg := &Ledger{
-				TokenBalances: tc.tokenBalances,
+				Balances: tc.tokenBalances,
 			}
 
 			c, err := NewCoin(ldg)
@@ -124,7 +124,7 @@ func TestCoinMint(t *testing.T) {
 	for _, tc := range tests {
 		tc := tc
 		t.Run(tc.name, func(t *testing.T) {
-			ldg := &Ledger{TokenBalances: map[string]uint64{}}
+			ldg := &Ledger{Balances: map[BalanceKey]uint64{}}
 
 			c := &Coin{
 				ledger:      ldg,
@@ -183,7 +183,7 @@ func TestLedgerSnapshotMarshals(t *testing.T) {
 */
 
 func TestLedgerMintTokenZeroAmount(t *testing.T) {
-	ldg := &Ledger{TokenBalances: map[string]uint64{}}
+	ldg := &Ledger{Balances: map[BalanceKey]uint64{}}
 	var addr Address
 	err := ldg.MintToken(addr, Code, 0)
 	if !errors.Is(err, fmt.Errorf("mint amount must be positive")) && err == nil {
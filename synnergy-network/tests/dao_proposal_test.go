@@ -19,9 +19,9 @@ func TestDAOProposalQuadraticVoting(t *testing.T) {
 		t.Fatalf("create dao: %v", err)
 	}
 	led := core.CurrentLedger()
-	led.TokenBalances[creator.String()+":"+core.Code] = 100
+	led.Balances[core.CoinKey(creator)] = 100
 	voter := core.Address{2}
-	led.TokenBalances[voter.String()+":"+core.Code] = 25
+	led.Balances[core.CoinKey(voter)] = 25
 	if err := core.JoinDAO(dao.ID, voter); err != nil {
 		t.Fatalf("join dao: %v", err)
 	}
@@ -1,7 +1,6 @@
 package core_test
 
 import (
-	"fmt"
 	core "synnergy-network/core"
 	"testing"
 )
@@ -9,7 +8,7 @@ import (
 func TestProposalLifecycle(t *testing.T) {
 	// init in-memory store and ledger
 	appStore = &InMemoryStore{data: make(map[string][]byte)}
-	ledger = &Ledger{TokenBalances: map[string]uint64{fmt.Sprintf("%x", []byte{1}): 1}}
+	ledger = &Ledger{Balances: map[BalanceKey]uint64{CoinKey(Address{1}): 1}}
 
 	creator := Address{1}
 	prop := &GovProposal{Creator: creator, Description: "test"}
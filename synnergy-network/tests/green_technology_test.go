@@ -1,6 +1,8 @@
 package core_test
 
 import (
+	"crypto/ed25519"
+	"crypto/rand"
 	"encoding/json"
 	"errors"
 	"sync"
@@ -79,6 +81,43 @@ func (m *greenMockLedger) EmitApproval(TokenID, Address, Address, uint64) {}
 func (m *greenMockLedger) EmitTransfer(TokenID, Address, Address, uint64) {}
 func (m *greenMockLedger) WithinBlock(func() error) error                 { return nil }
 
+//------------------------------------------------------------
+// meter oracle helpers
+//------------------------------------------------------------
+
+type testMeter struct {
+	id   string
+	v    Address
+	priv ed25519.PrivateKey
+}
+
+// newTestMeter registers an oracle and binds it to v directly in state.
+func newTestMeter(t *testing.T, led *greenMockLedger, v Address) *testMeter {
+	pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+	id := "meter-" + v.Hex()
+	if err := RegisterOracle(Oracle{ID: id, Source: "meter", PubKey: pub, Algo: AlgoEd25519}); err != nil {
+		t.Fatalf("register oracle: %v", err)
+	}
+	raw, _ := json.Marshal(MeterOracle{OracleID: id, Validator: v})
+	led.SetState([]byte("green:meter:"+id), raw)
+	return &testMeter{id: id, v: v, priv: priv}
+}
+
+func (m *testMeter) reading(energy, carbon, offset float64) (MeterReading, []byte) {
+	r := MeterReading{OracleID: m.id, Validator: m.v, EnergyKWh: energy, CarbonKg: carbon, OffsetKg: offset, Timestamp: time.Now().Unix()}
+	return r, ed25519.Sign(m.priv, MeterReadingMessage(r))
+}
+
+func (m *testMeter) usage(energy, carbon float64) error {
+	r, sig := m.reading(energy, carbon, 0)
+	return Green().RecordUsage(r, sig)
+}
+
+func (m *testMeter) offset(kg float64) error {
+	r, sig := m.reading(0, 0, kg)
+	return Green().RecordOffset(r, sig)
+}
+
 //------------------------------------------------------------
 // Tests
 //------------------------------------------------------------
@@ -87,22 +126,43 @@ func TestRecordUsageAndOffset(t *testing.T) {
 	led := newGreenLedger()
 	InitGreenTech(led)
 	v := Address{0x01}
+	m := newTestMeter(t, led, v)
 
 	// invalid params
-	if err := Green().RecordUsage(v, 0, 10); err == nil {
+	if err := m.usage(0, 10); err == nil {
 		t.Fatalf("expected error for zero energy")
 	}
-	if err := Green().RecordOffset(v, 0); err == nil {
+	if err := m.offset(0); err == nil {
 		t.Fatalf("expected error offset")
 	}
 
-	if err := Green().RecordUsage(v, 100, 50); err != nil {
+	if err := m.usage(100, 50); err != nil {
 		t.Fatalf("record usage %v", err)
 	}
-	if err := Green().RecordOffset(v, 40); err != nil {
+	if err := m.offset(40); err != nil {
 		t.Fatalf("offset %v", err)
 	}
 
+	// tampered and replayed readings are rejected
+	r, sig := m.reading(100, 10, 0)
+	r.CarbonKg = 1
+	if err := Green().RecordUsage(r, sig); !errors.Is(err, ErrMeterSignature) {
+		t.Fatalf("expected signature error, got %v", err)
+	}
+	r, sig = m.reading(100, 20, 0)
+	if err := Green().RecordUsage(r, sig); err != nil {
+		t.Fatalf("record usage %v", err)
+	}
+	if err := Green().RecordUsage(r, sig); !errors.Is(err, ErrReadingReplay) {
+		t.Fatalf("expected replay error, got %v", err)
+	}
+	// a reading for another validator is rejected
+	r, sig = m.reading(100, 20, 0)
+	r.Validator = Address{0x02}
+	if err := Green().RecordUsage(r, sig); !errors.Is(err, ErrMeterUnknown) {
+		t.Fatalf("expected meter error, got %v", err)
+	}
+
 	// ensure states written
 	if ok, _ := led.HasState([]byte("usage:")); !ok {
 		t.Errorf("usage not stored")
@@ -120,15 +180,19 @@ func TestCertifyAndThrottle(t *testing.T) {
 	vBronze := Address{0x30}
 	vNone := Address{0x40}
 
+	meters := map[Address]*testMeter{}
+	for _, v := range []Address{vGold, vSilver, vBronze, vNone} {
+		meters[v] = newTestMeter(t, led, v)
+	}
 	// helper to write records quickly
-	recUsage := func(a Address, kg float64) { Green().RecordUsage(a, 100, kg) } // energy not used in score
-	Green().RecordOffset(vGold, 75)                                             // emitted 50 later -> score 0.5
+	recUsage := func(a Address, kg float64) { meters[a].usage(100, kg) } // energy not used in score
+	meters[vGold].offset(75)                                             // emitted 50 later -> score 0.5
 	recUsage(vGold, 50)
 
-	Green().RecordOffset(vSilver, 50) // emitted 50 -> score 0
+	meters[vSilver].offset(50) // emitted 50 -> score 0
 	recUsage(vSilver, 50)
 
-	Green().RecordOffset(vBronze, 40) // emitted 50 -> -0.2
+	meters[vBronze].offset(40) // emitted 50 -> -0.2
 	recUsage(vBronze, 50)
 
 	recUsage(vNone, 50) // no offset -> -1.0
@@ -155,6 +219,20 @@ func TestCertifyAndThrottle(t *testing.T) {
 			t.Fatalf("throttle of %x got %v want %v", tc.addr, th, tc.throttle)
 		}
 	}
+
+	// signed export round-trips
+	_, key, _ := ed25519.GenerateKey(rand.Reader)
+	exp, err := Green().ExportGreenCertificate(vGold, key)
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if err := VerifyGreenCertificateExport(exp); err != nil {
+		t.Fatalf("verify export: %v", err)
+	}
+	exp.Certificate.Score = 1
+	if err := VerifyGreenCertificateExport(exp); err == nil {
+		t.Fatalf("expected tampered export to fail")
+	}
 }
 
 func TestListCertificates(t *testing.T) {
@@ -163,10 +241,11 @@ func TestListCertificates(t *testing.T) {
 	a1 := Address{0x01}
 	a2 := Address{0x02}
 
-	Green().RecordUsage(a1, 100, 40)
-	Green().RecordOffset(a1, 50) // score 0.25 -> Bronze
-	Green().RecordUsage(a2, 100, 60)
-	Green().RecordOffset(a2, 90) // score 0.5 -> Gold
+	m1, m2 := newTestMeter(t, led, a1), newTestMeter(t, led, a2)
+	m1.usage(100, 40)
+	m1.offset(50) // score 0.25 -> Bronze
+	m2.usage(100, 60)
+	m2.offset(90) // score 0.5 -> Gold
 
 	Green().Certify()
 
@@ -9,6 +9,11 @@ import (
 
 type ServerConfig struct {
 	Port string
+	// NodeURL is the node the invoice watcher, address monitor, fee
+	// estimates and nonce manager use; empty disables them.
+	NodeURL string
+	// EmailHook is the mail relay address watch e-mails are POSTed to.
+	EmailHook string
 }
 
 var AppConfig ServerConfig
@@ -21,6 +26,10 @@ func Load() error {
 	if port == "" {
 		port = "8081"
 	}
-	AppConfig = ServerConfig{Port: port}
+	AppConfig = ServerConfig{
+		Port:      port,
+		NodeURL:   os.Getenv("WALLET_NODE_URL"),
+		EmailHook: os.Getenv("WALLET_EMAIL_HOOK"),
+	}
 	return nil
 }
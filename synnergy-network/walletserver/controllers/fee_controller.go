@@ -0,0 +1,28 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	core "synnergy-network/core"
+)
+
+func (wc *WalletController) EstimateFee(w http.ResponseWriter, r *http.Request) {
+	blocks := core.DefaultFeeTarget
+	if v := r.URL.Query().Get("blocks"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			writeError(w, http.StatusBadRequest, errors.New("invalid blocks"))
+			return
+		}
+		blocks = n
+	}
+	est, err := wc.svc.EstimateFee(r.Context(), blocks)
+	if err != nil {
+		writeError(w, 0, err)
+		return
+	}
+	json.NewEncoder(w).Encode(est)
+}
@@ -0,0 +1,144 @@
+package controllers
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	core "synnergy-network/core"
+)
+
+// InvoiceCreateRequest is the body of POST /api/invoices.
+type InvoiceCreateRequest struct {
+	Merchant      string       `json:"merchant" validate:"required,format=address"`
+	PayTo         string       `json:"pay_to" validate:"format=address"` // default: the merchant
+	Amount        uint64       `json:"amount" validate:"required,min=1"`
+	Token         core.TokenID `json:"token"` // 0 for the coin
+	Memo          string       `json:"memo"`
+	Seconds       uint64       `json:"expires_in"`    // default one hour
+	Confirmations uint64       `json:"confirmations"` // default 6
+	Webhook       string       `json:"webhook"`
+	Secret        string       `json:"webhook_secret"` // required with webhook
+	Nonce         uint64       `json:"nonce"`
+	Signature     string       `json:"signature"` // hex, the merchant's over the request's typed data
+}
+
+// RegistrationDocument is the typed data an invoice or watch request is
+// signed over, with the registration nonce it carries.
+type RegistrationDocument struct {
+	Nonce     uint64         `json:"nonce"`
+	TypedData core.TypedData `json:"typed_data"`
+}
+
+// InvoiceView is an invoice with its QR payload.
+type InvoiceView struct {
+	*core.Invoice
+	QR string `json:"qr"`
+}
+
+func (req InvoiceCreateRequest) invoiceRequest() (core.InvoiceRequest, error) {
+	merchant, err := core.DecodeAddress(req.Merchant)
+	if err != nil {
+		return core.InvoiceRequest{}, err
+	}
+	payTo := merchant
+	if req.PayTo != "" {
+		if payTo, err = core.DecodeAddress(req.PayTo); err != nil {
+			return core.InvoiceRequest{}, err
+		}
+	}
+	return core.InvoiceRequest{
+		Merchant:      merchant,
+		PayTo:         payTo,
+		Amount:        req.Amount,
+		Token:         req.Token,
+		Memo:          req.Memo,
+		TTL:           time.Duration(req.Seconds) * time.Second,
+		Confirmations: req.Confirmations,
+		Webhook:       req.Webhook,
+		Secret:        req.Secret,
+		Nonce:         req.Nonce,
+	}, nil
+}
+
+// decodeSignature decodes the hex signature of a registration request.
+func decodeSignature(s string) ([]byte, error) {
+	sig, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil || len(sig) == 0 {
+		return nil, core.ErrRegistrationSig
+	}
+	return sig, nil
+}
+
+// InvoiceTypedData returns the document the merchant signs for the
+// request in the body, which needs no nonce or signature yet.
+func (wc *WalletController) InvoiceTypedData(w http.ResponseWriter, r *http.Request) {
+	var body InvoiceCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	req, err := body.invoiceRequest()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	req.Nonce = wc.svc.RegistrationNonce(req.Merchant)
+	json.NewEncoder(w).Encode(RegistrationDocument{Nonce: req.Nonce, TypedData: core.InvoiceRequestTypedData(req)})
+}
+
+func (wc *WalletController) CreateInvoice(w http.ResponseWriter, r *http.Request) {
+	var body InvoiceCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	req, err := body.invoiceRequest()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	sig, err := decodeSignature(body.Signature)
+	if err != nil {
+		writeError(w, 0, err)
+		return
+	}
+	inv, err := wc.svc.CreateInvoice(req, sig)
+	if err != nil {
+		writeError(w, 0, err)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(InvoiceView{inv, inv.URI})
+}
+
+func (wc *WalletController) Invoices(w http.ResponseWriter, r *http.Request) {
+	merchant, err := core.DecodeAddress(r.URL.Query().Get("merchant"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	list, err := wc.svc.Invoices(merchant)
+	if err != nil {
+		writeError(w, 0, err)
+		return
+	}
+	out := make([]InvoiceView, len(list))
+	for i := range list {
+		out[i] = InvoiceView{&list[i], list[i].URI}
+	}
+	json.NewEncoder(w).Encode(out)
+}
+
+func (wc *WalletController) Invoice(w http.ResponseWriter, r *http.Request) {
+	inv, err := wc.svc.Invoice(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, 0, err)
+		return
+	}
+	json.NewEncoder(w).Encode(InvoiceView{inv, inv.URI})
+}
@@ -0,0 +1,33 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	core "synnergy-network/core"
+)
+
+// MulticallRequest is the body of POST /api/wallet/multicall.
+type MulticallRequest struct {
+	Wallet   core.HDWallet `validate:"required"`
+	Calls    []core.Call   `json:"calls" validate:"required"`
+	GasLimit uint64        `json:"gas_limit" validate:"required"`
+	GasPrice uint64        `json:"gas_price"`
+	Nonce    uint64        `json:"nonce"`
+	Account  uint32
+	Index    uint32
+}
+
+func (wc *WalletController) Multicall(w http.ResponseWriter, r *http.Request) {
+	var req MulticallRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	tx, err := wc.svc.SignMulticall(&req.Wallet, req.Calls, req.GasLimit, req.GasPrice, req.Nonce, req.Account, req.Index)
+	if err != nil {
+		writeError(w, 0, err)
+		return
+	}
+	json.NewEncoder(w).Encode(tx)
+}
@@ -0,0 +1,118 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	core "synnergy-network/core"
+)
+
+// NonceSenderRequest is the body of POST /api/nonces/senders. The server
+// holds no keys: the sender signs its own transactions, and replaces stuck
+// ones through /api/nonces/submit.
+type NonceSenderRequest struct {
+	Address string `json:"address" validate:"required,format=address"`
+}
+
+// NonceReserveRequest is the body of POST /api/nonces/{address}/reserve.
+type NonceReserveRequest struct {
+	Count int `json:"count" validate:"required,min=1,max=1000"`
+}
+
+// NonceReleaseRequest is the body of POST /api/nonces/{address}/release.
+type NonceReleaseRequest struct {
+	Nonces []uint64 `json:"nonces" validate:"required"`
+}
+
+func pathAddress(w http.ResponseWriter, r *http.Request) (core.Address, bool) {
+	addr, err := core.DecodeAddress(mux.Vars(r)["address"])
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return core.Address{}, false
+	}
+	return addr, true
+}
+
+func (wc *WalletController) RegisterNonceSender(w http.ResponseWriter, r *http.Request) {
+	var req NonceSenderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	addr, err := core.DecodeAddress(req.Address)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	st, err := wc.svc.RegisterNonceAddress(addr)
+	if err != nil {
+		writeError(w, 0, err)
+		return
+	}
+	json.NewEncoder(w).Encode(st)
+}
+
+func (wc *WalletController) NonceState(w http.ResponseWriter, r *http.Request) {
+	addr, ok := pathAddress(w, r)
+	if !ok {
+		return
+	}
+	st, err := wc.svc.NonceState(addr)
+	if err != nil {
+		writeError(w, 0, err)
+		return
+	}
+	json.NewEncoder(w).Encode(st)
+}
+
+func (wc *WalletController) ReserveNonces(w http.ResponseWriter, r *http.Request) {
+	addr, ok := pathAddress(w, r)
+	if !ok {
+		return
+	}
+	var req NonceReserveRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	res, err := wc.svc.ReserveNonces(addr, req.Count)
+	if err != nil {
+		writeError(w, 0, err)
+		return
+	}
+	json.NewEncoder(w).Encode(res)
+}
+
+func (wc *WalletController) ReleaseNonces(w http.ResponseWriter, r *http.Request) {
+	addr, ok := pathAddress(w, r)
+	if !ok {
+		return
+	}
+	var req NonceReleaseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := wc.svc.ReleaseNonces(addr, req.Nonces); err != nil {
+		writeError(w, 0, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (wc *WalletController) SubmitTx(w http.ResponseWriter, r *http.Request) {
+	var tx core.Transaction
+	if err := json.NewDecoder(r.Body).Decode(&tx); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	info, err := wc.svc.SubmitTx(&tx)
+	if err != nil {
+		writeError(w, 0, err)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(info)
+}
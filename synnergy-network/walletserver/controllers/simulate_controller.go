@@ -0,0 +1,22 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	core "synnergy-network/core"
+)
+
+func (wc *WalletController) Simulate(w http.ResponseWriter, r *http.Request) {
+	var req core.SimulateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	res, err := wc.svc.SimulateTx(r.Context(), &req.Tx, req.SimulateOptions)
+	if err != nil {
+		writeError(w, 0, err)
+		return
+	}
+	json.NewEncoder(w).Encode(res)
+}
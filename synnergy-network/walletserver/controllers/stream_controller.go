@@ -0,0 +1,120 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	core "synnergy-network/core"
+)
+
+// StreamCreateRequest is the body of POST /api/streams. The sender is the
+// address at Account/Index of Wallet.
+type StreamCreateRequest struct {
+	Wallet    core.HDWallet `validate:"required"`
+	Account   uint32
+	Index     uint32
+	Recipient string       `json:"recipient" validate:"required,format=address"`
+	Token     core.TokenID `json:"token"` // 0 streams the coin
+	Rate      uint64       `json:"rate_per_second" validate:"required,min=1"`
+	Seconds   uint64       `json:"seconds" validate:"required,min=1"`
+	Start     time.Time    `json:"start"` // default: now
+}
+
+// StreamActionRequest is the body of the withdraw and cancel endpoints. The
+// caller is the address at Account/Index of Wallet.
+type StreamActionRequest struct {
+	Wallet  core.HDWallet `validate:"required"`
+	Account uint32
+	Index   uint32
+	Amount  uint64 `json:"amount"` // withdraw only; 0 withdraws everything vested
+}
+
+// StreamView is a stream with its balances at the time of the response.
+type StreamView struct {
+	*core.PaymentStream
+	Vested       uint64 `json:"vested"`
+	Withdrawable uint64 `json:"withdrawable"`
+}
+
+func streamView(s *core.PaymentStream) StreamView {
+	now := time.Now().UTC()
+	return StreamView{PaymentStream: s, Vested: s.Vested(now), Withdrawable: s.Withdrawable(now)}
+}
+
+func (wc *WalletController) CreateStream(w http.ResponseWriter, r *http.Request) {
+	var req StreamCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	to, err := core.DecodeAddress(req.Recipient)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	s, err := wc.svc.CreateStream(&req.Wallet, req.Account, req.Index, to, req.Token, req.Rate, req.Seconds, req.Start)
+	if err != nil {
+		writeError(w, 0, err)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(streamView(s))
+}
+
+func (wc *WalletController) Streams(w http.ResponseWriter, r *http.Request) {
+	addr, err := core.DecodeAddress(r.URL.Query().Get("address"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	list, err := wc.svc.Streams(addr)
+	if err != nil {
+		writeError(w, 0, err)
+		return
+	}
+	out := make([]StreamView, len(list))
+	for i := range list {
+		out[i] = streamView(&list[i])
+	}
+	json.NewEncoder(w).Encode(out)
+}
+
+func (wc *WalletController) Stream(w http.ResponseWriter, r *http.Request) {
+	s, err := wc.svc.Stream(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, 0, err)
+		return
+	}
+	json.NewEncoder(w).Encode(streamView(s))
+}
+
+func (wc *WalletController) WithdrawStream(w http.ResponseWriter, r *http.Request) {
+	var req StreamActionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	s, err := wc.svc.WithdrawStream(&req.Wallet, req.Account, req.Index, mux.Vars(r)["id"], req.Amount)
+	if err != nil {
+		writeError(w, 0, err)
+		return
+	}
+	json.NewEncoder(w).Encode(streamView(s))
+}
+
+func (wc *WalletController) CancelStream(w http.ResponseWriter, r *http.Request) {
+	var req StreamActionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	s, err := wc.svc.CancelStream(&req.Wallet, req.Account, req.Index, mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, 0, err)
+		return
+	}
+	json.NewEncoder(w).Encode(streamView(s))
+}
@@ -0,0 +1,51 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	core "synnergy-network/core"
+)
+
+// TypedSignRequest is the body of POST /api/wallet/sign-typed.
+type TypedSignRequest struct {
+	Wallet  core.HDWallet  `validate:"required"`
+	Data    core.TypedData `json:"data" validate:"required"`
+	Account uint32
+	Index   uint32
+}
+
+// TypedVerifyRequest is the body of POST /api/wallet/verify-typed.
+type TypedVerifyRequest struct {
+	Data   core.TypedData `json:"data" validate:"required"`
+	Sig    string         `json:"sig" validate:"required,format=hex"`
+	Signer string         `json:"signer" validate:"required,format=address"`
+}
+
+func (wc *WalletController) SignTyped(w http.ResponseWriter, r *http.Request) {
+	var req TypedSignRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	sig, err := wc.svc.SignTypedData(&req.Wallet, req.Data, req.Account, req.Index)
+	if err != nil {
+		writeError(w, 0, err)
+		return
+	}
+	json.NewEncoder(w).Encode(sig)
+}
+
+func (wc *WalletController) VerifyTyped(w http.ResponseWriter, r *http.Request) {
+	var req TypedVerifyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	ok, err := wc.svc.VerifyTypedData(req.Data, req.Sig, req.Signer)
+	if err != nil {
+		writeError(w, 0, err)
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]bool{"valid": ok})
+}
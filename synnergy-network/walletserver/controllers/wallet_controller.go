@@ -18,6 +18,39 @@ func NewWalletController(svc *services.WalletService) *WalletController {
 	return &WalletController{svc: svc}
 }
 
+// CreateResponse is the body returned by Create.
+type CreateResponse struct {
+	Mnemonic string `json:"mnemonic"`
+	Seed     []byte `json:"seed"`
+}
+
+// ImportRequest is the body of POST /api/wallet/import.
+type ImportRequest struct {
+	Mnemonic   string `validate:"required"`
+	Passphrase string
+}
+
+// AddressRequest is the body of POST /api/wallet/address.
+type AddressRequest struct {
+	Wallet  core.HDWallet `validate:"required"`
+	Account uint32
+	Index   uint32
+}
+
+// SignRequest is the body of POST /api/wallet/sign.
+type SignRequest struct {
+	Wallet  core.HDWallet    `validate:"required"`
+	Tx      core.Transaction `validate:"required"`
+	Account uint32
+	Index   uint32
+	Gas     uint64
+}
+
+// writeError answers with the shared error envelope (see core.ErrorEnvelope).
+func writeError(w http.ResponseWriter, status int, err error) {
+	core.WriteHTTPError(w, status, "wallet", err)
+}
+
 func (wc *WalletController) Create(w http.ResponseWriter, r *http.Request) {
 	bitsStr := r.URL.Query().Get("bits")
 	bits, _ := strconv.Atoi(bitsStr)
@@ -26,58 +59,48 @@ func (wc *WalletController) Create(w http.ResponseWriter, r *http.Request) {
 	}
 	wallet, mnemonic, err := wc.svc.CreateWallet(bits)
 	if err != nil {
-		http.Error(w, err.Error(), 500)
+		writeError(w, http.StatusInternalServerError, err)
 		return
 	}
-	json.NewEncoder(w).Encode(map[string]any{"mnemonic": mnemonic, "seed": wallet.Seed()})
+	json.NewEncoder(w).Encode(CreateResponse{Mnemonic: mnemonic, Seed: wallet.Seed()})
 }
 
 func (wc *WalletController) Import(w http.ResponseWriter, r *http.Request) {
-	var req struct{ Mnemonic, Passphrase string }
+	var req ImportRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, err.Error(), 400)
+		writeError(w, http.StatusBadRequest, err)
 		return
 	}
 	wallet, err := wc.svc.ImportWallet(req.Mnemonic, req.Passphrase)
 	if err != nil {
-		http.Error(w, err.Error(), 500)
+		writeError(w, http.StatusInternalServerError, err)
 		return
 	}
 	json.NewEncoder(w).Encode(map[string]any{"address": wallet})
 }
 
 func (wc *WalletController) Address(w http.ResponseWriter, r *http.Request) {
-	var req struct {
-		Wallet  core.HDWallet
-		Account uint32
-		Index   uint32
-	}
+	var req AddressRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, err.Error(), 400)
+		writeError(w, http.StatusBadRequest, err)
 		return
 	}
 	addr, err := wc.svc.DeriveAddress(&req.Wallet, req.Account, req.Index)
 	if err != nil {
-		http.Error(w, err.Error(), 500)
+		writeError(w, http.StatusInternalServerError, err)
 		return
 	}
 	json.NewEncoder(w).Encode(map[string]string{"address": addr.Hex()})
 }
 
 func (wc *WalletController) Sign(w http.ResponseWriter, r *http.Request) {
-	var req struct {
-		Wallet  core.HDWallet
-		Tx      core.Transaction
-		Account uint32
-		Index   uint32
-		Gas     uint64
-	}
+	var req SignRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, err.Error(), 400)
+		writeError(w, http.StatusBadRequest, err)
 		return
 	}
 	if err := wc.svc.SignTransaction(&req.Wallet, &req.Tx, req.Account, req.Index, req.Gas); err != nil {
-		http.Error(w, err.Error(), 500)
+		writeError(w, http.StatusInternalServerError, err)
 		return
 	}
 	json.NewEncoder(w).Encode(req.Tx)
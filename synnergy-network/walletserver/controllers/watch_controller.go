@@ -0,0 +1,157 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	core "synnergy-network/core"
+)
+
+// WatchOnlyRequest is the body of POST /api/wallet/watch-only.
+type WatchOnlyRequest struct {
+	Wallet  core.HDWallet `validate:"required"`
+	Account uint32
+	Count   uint32 `json:"count" validate:"required,min=1,max=1000"`
+}
+
+// WatchCreateRequest is the body of POST /api/watches. Addresses may be hex
+// or bech32; accounts are exports of /api/wallet/watch-only.
+type WatchCreateRequest struct {
+	Owner            string                  `json:"owner" validate:"required,format=address"`
+	Label            string                  `json:"label"`
+	Addresses        []string                `json:"addresses"`
+	Accounts         []core.WatchOnlyAccount `json:"accounts"`
+	Events           []string                `json:"events"` // incoming, outgoing, balance, contract
+	MinAmount        uint64                  `json:"min_amount"`
+	BalanceThreshold uint64                  `json:"balance_threshold"`
+	Webhook          string                  `json:"webhook"`
+	Secret           string                  `json:"webhook_secret"` // required with webhook
+	Email            string                  `json:"email"`
+	Nonce            uint64                  `json:"nonce"`
+	Signature        string                  `json:"signature"` // hex, the owner's over the request's typed data
+}
+
+func (wc *WalletController) WatchOnly(w http.ResponseWriter, r *http.Request) {
+	var req WatchOnlyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	acct, err := wc.svc.WatchOnly(&req.Wallet, req.Account, req.Count)
+	if err != nil {
+		writeError(w, 0, err)
+		return
+	}
+	json.NewEncoder(w).Encode(acct)
+}
+
+func (req WatchCreateRequest) watchRequest() (core.WatchRequest, error) {
+	owner, err := core.DecodeAddress(req.Owner)
+	if err != nil {
+		return core.WatchRequest{}, err
+	}
+	addrs := make([]core.Address, 0, len(req.Addresses))
+	for _, s := range req.Addresses {
+		a, err := core.DecodeAddress(s)
+		if err != nil {
+			return core.WatchRequest{}, err
+		}
+		addrs = append(addrs, a)
+	}
+	return core.WatchRequest{
+		Owner:            owner,
+		Label:            req.Label,
+		Addresses:        addrs,
+		Accounts:         req.Accounts,
+		Events:           req.Events,
+		MinAmount:        req.MinAmount,
+		BalanceThreshold: req.BalanceThreshold,
+		Webhook:          req.Webhook,
+		Secret:           req.Secret,
+		Email:            req.Email,
+		Nonce:            req.Nonce,
+	}, nil
+}
+
+// WatchTypedData returns the document the owner signs for the request in
+// the body, which needs no nonce or signature yet.
+func (wc *WalletController) WatchTypedData(w http.ResponseWriter, r *http.Request) {
+	var body WatchCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	req, err := body.watchRequest()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	req.Nonce = wc.svc.RegistrationNonce(req.Owner)
+	json.NewEncoder(w).Encode(RegistrationDocument{Nonce: req.Nonce, TypedData: core.WatchRequestTypedData(req)})
+}
+
+func (wc *WalletController) CreateWatch(w http.ResponseWriter, r *http.Request) {
+	var body WatchCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	req, err := body.watchRequest()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	sig, err := decodeSignature(body.Signature)
+	if err != nil {
+		writeError(w, 0, err)
+		return
+	}
+	watch, err := wc.svc.CreateWatch(req, sig)
+	if err != nil {
+		writeError(w, 0, err)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(watch)
+}
+
+func (wc *WalletController) Watches(w http.ResponseWriter, r *http.Request) {
+	list, err := wc.svc.Watches()
+	if err != nil {
+		writeError(w, 0, err)
+		return
+	}
+	json.NewEncoder(w).Encode(list)
+}
+
+func (wc *WalletController) Watch(w http.ResponseWriter, r *http.Request) {
+	watch, err := wc.svc.Watch(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, 0, err)
+		return
+	}
+	json.NewEncoder(w).Encode(watch)
+}
+
+func (wc *WalletController) DeleteWatch(w http.ResponseWriter, r *http.Request) {
+	if err := wc.svc.DeleteWatch(mux.Vars(r)["id"]); err != nil {
+		writeError(w, 0, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (wc *WalletController) WatchNotifications(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	height, _ := strconv.ParseUint(q.Get("from_height"), 10, 64)
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	list, err := wc.svc.WatchNotifications(mux.Vars(r)["id"], q.Get("type"), height, limit)
+	if err != nil {
+		writeError(w, 0, err)
+		return
+	}
+	json.NewEncoder(w).Encode(list)
+}
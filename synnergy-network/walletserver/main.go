@@ -1,11 +1,17 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"flag"
 	"net/http"
+	"os"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
 
+	core "synnergy-network/core"
 	"synnergy-network/walletserver/config"
 	"synnergy-network/walletserver/controllers"
 	"synnergy-network/walletserver/routes"
@@ -13,11 +19,37 @@ import (
 )
 
 func main() {
+	openapi := flag.Bool("openapi", false, "print the OpenAPI spec and exit")
+	flag.Parse()
+	if *openapi {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(routes.Spec(controllers.NewWalletController(services.NewService())).Document())
+		return
+	}
 	if err := config.Load(); err != nil {
 		logrus.Fatalf("failed to load config: %v", err)
 	}
 	svc := services.NewService()
 	ctrl := controllers.NewWalletController(svc)
+	if config.AppConfig.NodeURL != "" {
+		if err := svc.UseNode(config.AppConfig.NodeURL); err != nil {
+			logrus.Fatalf("node: %v", err)
+		}
+		if err := svc.StartNonceManager(context.Background(), core.NonceConfig{}, 5*time.Second); err != nil {
+			logrus.Fatalf("nonce manager: %v", err)
+		}
+		if err := svc.StartInvoiceWatcher(context.Background(), config.AppConfig.NodeURL, 5*time.Second); err != nil {
+			logrus.Fatalf("invoice watcher: %v", err)
+		}
+		var email core.EmailSender
+		if config.AppConfig.EmailHook != "" {
+			email = services.EmailHook{URL: config.AppConfig.EmailHook}
+		}
+		if err := svc.StartAddressMonitor(context.Background(), config.AppConfig.NodeURL, email, 5*time.Second); err != nil {
+			logrus.Fatalf("address monitor: %v", err)
+		}
+	}
 
 	r := mux.NewRouter()
 	routes.Register(r, ctrl)
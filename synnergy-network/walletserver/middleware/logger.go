@@ -1,15 +1,9 @@
 package middleware
 
 import (
-	"github.com/sirupsen/logrus"
-	"net/http"
-	"time"
+	"synnergy-network/pkg/logging"
 )
 
-func Logger(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		start := time.Now()
-		next.ServeHTTP(w, r)
-		logrus.Infof("%s %s %s", r.Method, r.RequestURI, time.Since(start))
-	})
-}
+// Logger tags each request with an ID and writes one structured access
+// line for it on the wallet module logger (see logging.RequestLogger).
+var Logger = logging.RequestLogger("wallet")
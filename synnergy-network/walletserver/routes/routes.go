@@ -1,17 +1,114 @@
 package routes
 
 import (
+	"encoding/json"
+	"net/http"
+
 	"github.com/gorilla/mux"
+
+	core "synnergy-network/core"
+	"synnergy-network/pkg/openapi"
 	"synnergy-network/walletserver/controllers"
 	"synnergy-network/walletserver/middleware"
 )
 
+// Spec returns the wallet API route table bound to wc. It drives the mux
+// registration, request validation and the OpenAPI document.
+func Spec(wc *controllers.WalletController) openapi.Spec {
+	return openapi.Spec{Title: "Synnergy Wallet API", Version: "1.0.0", Routes: []openapi.Route{
+		{Method: http.MethodGet, Path: "/api/wallet/create", Summary: "Create a wallet and return its mnemonic and seed",
+			Params:   []openapi.Param{{Name: "bits", In: "query", Desc: "entropy bits, default 128", Type: "integer"}},
+			Response: controllers.CreateResponse{}, Handler: wc.Create},
+		{Method: http.MethodPost, Path: "/api/wallet/import", Summary: "Restore a wallet from its mnemonic",
+			Request: controllers.ImportRequest{}, Response: map[string]interface{}{}, Handler: wc.Import},
+		{Method: http.MethodPost, Path: "/api/wallet/address", Summary: "Derive the address at account/index",
+			Request: controllers.AddressRequest{}, Response: map[string]string{}, Handler: wc.Address},
+		{Method: http.MethodPost, Path: "/api/wallet/sign", Summary: "Sign a transaction with the key at account/index",
+			Request: controllers.SignRequest{}, Response: core.Transaction{}, Handler: wc.Sign},
+		{Method: http.MethodPost, Path: "/api/wallet/simulate", Summary: "Dry-run a transaction before signing: receipt, logs, fee, revert reason and state changes",
+			Request: core.SimulateRequest{}, Response: core.SimulationResult{}, Handler: wc.Simulate},
+		{Method: http.MethodPost, Path: "/api/wallet/sign-typed", Summary: "Sign typed structured data (orders, votes, permits) bound to a domain",
+			Request: controllers.TypedSignRequest{}, Response: core.TypedDataSignature{}, Handler: wc.SignTyped},
+		{Method: http.MethodPost, Path: "/api/wallet/verify-typed", Summary: "Check a typed structured data signature against its signer",
+			Request: controllers.TypedVerifyRequest{}, Response: map[string]bool{}, Handler: wc.VerifyTyped},
+		{Method: http.MethodPost, Path: "/api/wallet/multicall", Summary: "Sign a multicall: ordered calls and transfers executed atomically in one transaction",
+			Request: controllers.MulticallRequest{}, Response: core.Transaction{}, Handler: wc.Multicall},
+		{Method: http.MethodGet, Path: "/api/wallet/opcodes", Summary: "Wallet opcode catalogue",
+			Response: map[string]string{}, Handler: wc.Opcodes},
+		{Method: http.MethodPost, Path: "/api/wallet/watch-only", Summary: "Export the addresses of an account for watch-only use",
+			Request: controllers.WatchOnlyRequest{}, Response: core.WatchOnlyAccount{}, Handler: wc.WatchOnly},
+		{Method: http.MethodPost, Path: "/api/streams", Summary: "Stream a coin or token per second to a recipient",
+			Request: controllers.StreamCreateRequest{}, Response: controllers.StreamView{}, Status: http.StatusCreated, Handler: wc.CreateStream},
+		{Method: http.MethodGet, Path: "/api/streams", Summary: "Streams an address sends or receives",
+			Params:   []openapi.Param{{Name: "address", In: "query", Desc: "hex or bech32 address", Type: "string", Required: true}},
+			Response: []controllers.StreamView{}, Handler: wc.Streams},
+		{Method: http.MethodGet, Path: "/api/streams/{id}", Summary: "A stream with its vested and withdrawable amounts",
+			Params:   []openapi.Param{{Name: "id", In: "path", Desc: "stream ID", Type: "string"}},
+			Response: controllers.StreamView{}, Handler: wc.Stream},
+		{Method: http.MethodPost, Path: "/api/streams/{id}/withdraw", Summary: "Withdraw vested funds as the recipient",
+			Params:  []openapi.Param{{Name: "id", In: "path", Desc: "stream ID", Type: "string"}},
+			Request: controllers.StreamActionRequest{}, Response: controllers.StreamView{}, Handler: wc.WithdrawStream},
+		{Method: http.MethodPost, Path: "/api/streams/{id}/cancel", Summary: "Cancel a stream, settling it pro rata",
+			Params:  []openapi.Param{{Name: "id", In: "path", Desc: "stream ID", Type: "string"}},
+			Request: controllers.StreamActionRequest{}, Response: controllers.StreamView{}, Handler: wc.CancelStream},
+		{Method: http.MethodPost, Path: "/api/invoices", Summary: "Create an invoice with its payment URI and QR payload",
+			Request: controllers.InvoiceCreateRequest{}, Response: controllers.InvoiceView{}, Status: http.StatusCreated, Handler: wc.CreateInvoice},
+		{Method: http.MethodPost, Path: "/api/invoices/typed-data", Summary: "Typed data the merchant signs to create an invoice",
+			Request: controllers.InvoiceCreateRequest{}, Response: controllers.RegistrationDocument{}, Handler: wc.InvoiceTypedData},
+		{Method: http.MethodGet, Path: "/api/invoices", Summary: "Invoices of a merchant",
+			Params:   []openapi.Param{{Name: "merchant", In: "query", Desc: "hex or bech32 address", Type: "string", Required: true}},
+			Response: []controllers.InvoiceView{}, Handler: wc.Invoices},
+		{Method: http.MethodGet, Path: "/api/invoices/{id}", Summary: "An invoice and its payment status",
+			Params:   []openapi.Param{{Name: "id", In: "path", Desc: "invoice ID", Type: "string"}},
+			Response: controllers.InvoiceView{}, Handler: wc.Invoice},
+		{Method: http.MethodGet, Path: "/api/fees/estimate", Summary: "Gas price likely to be included within a number of blocks",
+			Params:   []openapi.Param{{Name: "blocks", In: "query", Desc: "target blocks (default 3)", Type: "integer"}},
+			Response: core.FeeEstimate{}, Handler: wc.EstimateFee},
+		{Method: http.MethodPost, Path: "/api/nonces/senders", Summary: "Manage the nonces of a sender that signs its own transactions",
+			Request: controllers.NonceSenderRequest{}, Response: core.NonceSenderState{}, Handler: wc.RegisterNonceSender},
+		{Method: http.MethodPost, Path: "/api/nonces/submit", Summary: "Send a signed transaction carrying a reserved nonce and track it",
+			Request: core.Transaction{}, Response: core.InFlightTx{}, Status: http.StatusAccepted, Handler: wc.SubmitTx},
+		{Method: http.MethodGet, Path: "/api/nonces/{address}", Summary: "Reservations, in-flight transactions, gaps and stuck transactions of a sender",
+			Params:   []openapi.Param{{Name: "address", In: "path", Desc: "sender address", Type: "string"}},
+			Response: core.NonceSenderState{}, Handler: wc.NonceState},
+		{Method: http.MethodPost, Path: "/api/nonces/{address}/reserve", Summary: "Reserve nonces for transactions signed elsewhere",
+			Params:  []openapi.Param{{Name: "address", In: "path", Desc: "sender address", Type: "string"}},
+			Request: controllers.NonceReserveRequest{}, Response: core.NonceReservation{}, Handler: wc.ReserveNonces},
+		{Method: http.MethodPost, Path: "/api/nonces/{address}/release", Summary: "Return reserved nonces that will not be used",
+			Params:  []openapi.Param{{Name: "address", In: "path", Desc: "sender address", Type: "string"}},
+			Request: controllers.NonceReleaseRequest{}, Handler: wc.ReleaseNonces},
+		{Method: http.MethodPost, Path: "/api/watches", Summary: "Monitor addresses and notify on transfers, balance changes and contract calls",
+			Request: controllers.WatchCreateRequest{}, Response: core.Watch{}, Status: http.StatusCreated, Handler: wc.CreateWatch},
+		{Method: http.MethodPost, Path: "/api/watches/typed-data", Summary: "Typed data the owner signs to create a watch",
+			Request: controllers.WatchCreateRequest{}, Response: controllers.RegistrationDocument{}, Handler: wc.WatchTypedData},
+		{Method: http.MethodGet, Path: "/api/watches", Summary: "All watches",
+			Response: []core.Watch{}, Handler: wc.Watches},
+		{Method: http.MethodGet, Path: "/api/watches/{id}", Summary: "A watch",
+			Params:   []openapi.Param{{Name: "id", In: "path", Desc: "watch ID", Type: "string"}},
+			Response: core.Watch{}, Handler: wc.Watch},
+		{Method: http.MethodDelete, Path: "/api/watches/{id}", Summary: "Stop a watch, keeping its history",
+			Params:  []openapi.Param{{Name: "id", In: "path", Desc: "watch ID", Type: "string"}},
+			Handler: wc.DeleteWatch},
+		{Method: http.MethodGet, Path: "/api/watches/{id}/notifications", Summary: "Notification history of a watch, oldest first",
+			Params: []openapi.Param{
+				{Name: "id", In: "path", Desc: "watch ID", Type: "string"},
+				{Name: "type", In: "query", Desc: "incoming, outgoing, balance or contract", Type: "string"},
+				{Name: "from_height", In: "query", Desc: "first block height", Type: "integer"},
+				{Name: "limit", In: "query", Desc: "maximum notifications", Type: "integer"},
+			},
+			Response: []core.WatchNotification{}, Handler: wc.WatchNotifications},
+	}}
+}
+
 func Register(r *mux.Router, wc *controllers.WalletController) {
+	spec := Spec(wc)
 	r.Use(middleware.Logger)
-	r.HandleFunc("/api/wallet/create", wc.Create).Methods("GET")
-	r.HandleFunc("/api/wallet/import", wc.Import).Methods("POST")
-	r.HandleFunc("/api/wallet/address", wc.Address).Methods("POST")
-	r.HandleFunc("/api/wallet/sign", wc.Sign).Methods("POST")
-	r.HandleFunc("/api/wallet/opcodes", wc.Opcodes).Methods("GET")
-
+	r.Use(spec.Validator("wallet"))
+	for _, rt := range spec.Routes {
+		r.HandleFunc(rt.Path, rt.Handler).Methods(rt.Method)
+	}
+	r.HandleFunc("/api/openapi.json", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(spec.Document())
+	}).Methods(http.MethodGet)
 }
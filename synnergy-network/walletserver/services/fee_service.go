@@ -0,0 +1,56 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	core "synnergy-network/core"
+	"synnergy-network/pkg/sdk"
+)
+
+// UseNode points the node-backed calls, such as fee estimation, at the API
+// node at nodeURL.
+func (ws *WalletService) UseNode(nodeURL string) error {
+	c, err := sdk.New(sdk.Config{NodeURL: nodeURL})
+	if err != nil {
+		return err
+	}
+	ws.node = c
+	return nil
+}
+
+// convertWire copies in to out through their JSON form. The SDK mirrors the
+// node's wire types, so core values and their SDK counterparts convert
+// without loss.
+func convertWire(in, out interface{}) error {
+	raw, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, out)
+}
+
+// nodeError keeps the code of an error the node answered with, so it is
+// reported to wallet clients as the node classified it.
+func nodeError(err error) error {
+	var e *sdk.Error
+	if errors.As(err, &e) {
+		return &core.Error{Code: core.ErrorCode(e.Code), Module: e.Module, Message: e.Message, Retryable: e.Retryable, Details: e.Details}
+	}
+	return err
+}
+
+// EstimateFee asks the node for a gas price likely to be included within
+// blocks blocks.
+func (ws *WalletService) EstimateFee(ctx context.Context, blocks int) (*core.FeeEstimate, error) {
+	if ws.node == nil {
+		return nil, core.NewError(core.CodeUnavailable, "wallet", "no node configured")
+	}
+	est, err := ws.node.EstimateFee(ctx, blocks)
+	if err != nil {
+		return nil, nodeError(err)
+	}
+	var out core.FeeEstimate
+	return &out, convertWire(est, &out)
+}
@@ -0,0 +1,68 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	core "synnergy-network/core"
+	"synnergy-network/pkg/sdk"
+)
+
+// CreateInvoice stores a payment request signed by its merchant.
+func (ws *WalletService) CreateInvoice(req core.InvoiceRequest, sig []byte) (*core.Invoice, error) {
+	return core.CreateInvoice(req, sig)
+}
+
+// RegistrationNonce returns the nonce the next invoice or watch request of
+// owner must carry.
+func (ws *WalletService) RegistrationNonce(owner core.Address) uint64 {
+	return core.RegistrationNonce(owner)
+}
+
+// Invoice returns an invoice by ID.
+func (ws *WalletService) Invoice(id string) (*core.Invoice, error) {
+	return core.GetInvoice(id)
+}
+
+// Invoices lists the invoices of a merchant.
+func (ws *WalletService) Invoices(merchant core.Address) ([]core.Invoice, error) {
+	return core.ListInvoices(merchant)
+}
+
+// nodeBlocks reads blocks from a node over its HTTP API for the invoice
+// watcher.
+type nodeBlocks struct {
+	c *sdk.Client
+}
+
+func (n nodeBlocks) LastHeight() uint64 {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	st, err := n.c.Status(ctx)
+	if err != nil {
+		return 0
+	}
+	return st.Height
+}
+
+func (n nodeBlocks) GetBlock(height uint64) (*core.Block, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	b, err := n.c.Block(ctx, height)
+	if err != nil {
+		return nil, nodeError(err)
+	}
+	var out core.Block
+	return &out, convertWire(b, &out)
+}
+
+// StartInvoiceWatcher follows the chain of the node at nodeURL, detecting
+// invoice payments and delivering their webhooks every interval.
+func (ws *WalletService) StartInvoiceWatcher(ctx context.Context, nodeURL string, interval time.Duration) error {
+	c, err := sdk.New(sdk.Config{NodeURL: nodeURL})
+	if err != nil {
+		return err
+	}
+	core.NewInvoiceWatcher(nodeBlocks{c}).Start(ctx, interval)
+	return nil
+}
@@ -0,0 +1,18 @@
+package services
+
+import (
+	core "synnergy-network/core"
+)
+
+// SignMulticall builds a multicall transaction of calls and signs it with
+// the key at account/index of w.
+func (ws *WalletService) SignMulticall(w *core.HDWallet, calls []core.Call, gasLimit, gasPrice, nonce uint64, account, index uint32) (*core.Transaction, error) {
+	tx := &core.Transaction{Type: core.TxMulticall, Calls: calls, GasLimit: gasLimit, GasPrice: gasPrice, Nonce: nonce}
+	if err := core.ValidateMulticall(tx); err != nil {
+		return nil, err
+	}
+	if err := w.SignTx(tx, account, index, gasPrice); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
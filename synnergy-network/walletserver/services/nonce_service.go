@@ -0,0 +1,101 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	core "synnergy-network/core"
+	"synnergy-network/pkg/sdk"
+)
+
+// nodeNonces reads nonces from and sends transactions to a node over its
+// HTTP API for the nonce manager.
+type nodeNonces struct {
+	c *sdk.Client
+}
+
+func (n nodeNonces) NextNonce(addr core.Address) (uint64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	b, err := n.c.Balance(ctx, addr.Hex())
+	if err != nil {
+		return 0, nodeError(err)
+	}
+	return b.Nonce, nil
+}
+
+func (n nodeNonces) SendTx(tx *core.Transaction) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	var wire sdk.Transaction
+	if err := convertWire(tx, &wire); err != nil {
+		return err
+	}
+	_, err := n.c.SendTx(ctx, &wire)
+	return nodeError(err)
+}
+
+// StartNonceManager allocates nonces against the node set by UseNode and
+// checks senders for gaps and stuck transactions every interval.
+func (ws *WalletService) StartNonceManager(ctx context.Context, cfg core.NonceConfig, interval time.Duration) error {
+	if ws.node == nil {
+		return core.NewError(core.CodeUnavailable, "wallet", "no node configured")
+	}
+	ws.nonces = core.NewNonceManager(nodeNonces{ws.node}, cfg)
+	ws.nonces.Start(ctx, interval)
+	return nil
+}
+
+func (ws *WalletService) nonceManager() (*core.NonceManager, error) {
+	if ws.nonces == nil {
+		return nil, core.NewError(core.CodeUnavailable, "wallet", "nonce manager not running")
+	}
+	return ws.nonces, nil
+}
+
+// RegisterNonceAddress manages the nonces of a sender that signs its own
+// transactions.
+func (ws *WalletService) RegisterNonceAddress(addr core.Address) (*core.NonceSenderState, error) {
+	m, err := ws.nonceManager()
+	if err != nil {
+		return nil, err
+	}
+	return m.Register(addr, nil, false)
+}
+
+// NonceState returns the nonces, reservations and in-flight transactions
+// of a sender.
+func (ws *WalletService) NonceState(addr core.Address) (*core.NonceSenderState, error) {
+	m, err := ws.nonceManager()
+	if err != nil {
+		return nil, err
+	}
+	return m.State(addr)
+}
+
+// ReserveNonces leases count nonces of addr.
+func (ws *WalletService) ReserveNonces(addr core.Address, count int) (*core.NonceReservation, error) {
+	m, err := ws.nonceManager()
+	if err != nil {
+		return nil, err
+	}
+	return m.Reserve(addr, count)
+}
+
+// ReleaseNonces returns unused leased nonces.
+func (ws *WalletService) ReleaseNonces(addr core.Address, nonces []uint64) error {
+	m, err := ws.nonceManager()
+	if err != nil {
+		return err
+	}
+	return m.Release(addr, nonces)
+}
+
+// SubmitTx sends a signed transaction carrying a leased nonce and tracks it.
+func (ws *WalletService) SubmitTx(tx *core.Transaction) (*core.InFlightTx, error) {
+	m, err := ws.nonceManager()
+	if err != nil {
+		return nil, err
+	}
+	return m.Submit(tx)
+}
@@ -0,0 +1,30 @@
+package services
+
+import (
+	"context"
+
+	core "synnergy-network/core"
+	"synnergy-network/pkg/sdk"
+)
+
+// SimulateTx dry-runs tx on the node so its outcome can be shown before it
+// is signed.
+func (ws *WalletService) SimulateTx(ctx context.Context, tx *core.Transaction, opts core.SimulateOptions) (*core.SimulationResult, error) {
+	if ws.node == nil {
+		return nil, core.NewError(core.CodeUnavailable, "wallet", "no node configured")
+	}
+	var wtx sdk.Transaction
+	var wopts sdk.SimulateOptions
+	if err := convertWire(tx, &wtx); err != nil {
+		return nil, err
+	}
+	if err := convertWire(opts, &wopts); err != nil {
+		return nil, err
+	}
+	res, err := ws.node.Simulate(ctx, &wtx, &wopts)
+	if err != nil {
+		return nil, nodeError(err)
+	}
+	var out core.SimulationResult
+	return &out, convertWire(res, &out)
+}
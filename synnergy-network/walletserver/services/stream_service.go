@@ -0,0 +1,51 @@
+package services
+
+import (
+	"time"
+
+	core "synnergy-network/core"
+)
+
+// CreateStream streams rate per second of token (0 for the coin) from the
+// address at account/index of w to recipient for the given seconds.
+func (ws *WalletService) CreateStream(w *core.HDWallet, account, index uint32, recipient core.Address, token core.TokenID, rate, seconds uint64, start time.Time) (*core.PaymentStream, error) {
+	from, err := w.NewAddress(account, index)
+	if err != nil {
+		return nil, err
+	}
+	asset := core.AssetRef{Kind: core.AssetCoin}
+	if token != 0 {
+		asset = core.AssetRef{Kind: core.AssetToken, TokenID: token}
+	}
+	return core.CreatePaymentStream(&core.Context{Caller: from}, recipient, asset, rate, time.Duration(seconds)*time.Second, start)
+}
+
+// WithdrawStream withdraws amount, or everything vested when 0, for the
+// recipient at account/index of w.
+func (ws *WalletService) WithdrawStream(w *core.HDWallet, account, index uint32, id string, amount uint64) (*core.PaymentStream, error) {
+	caller, err := w.NewAddress(account, index)
+	if err != nil {
+		return nil, err
+	}
+	return core.WithdrawFromStream(&core.Context{Caller: caller}, id, amount)
+}
+
+// CancelStream cancels a stream on behalf of the sender or recipient at
+// account/index of w.
+func (ws *WalletService) CancelStream(w *core.HDWallet, account, index uint32, id string) (*core.PaymentStream, error) {
+	caller, err := w.NewAddress(account, index)
+	if err != nil {
+		return nil, err
+	}
+	return core.CancelPaymentStream(&core.Context{Caller: caller}, id)
+}
+
+// Stream returns a stream by ID.
+func (ws *WalletService) Stream(id string) (*core.PaymentStream, error) {
+	return core.GetPaymentStream(id)
+}
+
+// Streams lists the streams an address sends or receives.
+func (ws *WalletService) Streams(addr core.Address) ([]core.PaymentStream, error) {
+	return core.ListPaymentStreams(addr)
+}
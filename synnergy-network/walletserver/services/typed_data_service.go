@@ -0,0 +1,27 @@
+package services
+
+import (
+	"encoding/hex"
+	"strings"
+
+	core "synnergy-network/core"
+)
+
+// SignTypedData signs typed structured data with the key at account/index
+// of w.
+func (ws *WalletService) SignTypedData(w *core.HDWallet, td core.TypedData, account, index uint32) (*core.TypedDataSignature, error) {
+	return w.SignTypedData(td, account, index)
+}
+
+// VerifyTypedData reports whether sig (hex) is signer's signature over td.
+func (ws *WalletService) VerifyTypedData(td core.TypedData, sig, signer string) (bool, error) {
+	raw, err := hex.DecodeString(strings.TrimPrefix(sig, "0x"))
+	if err != nil {
+		return false, core.NewError(core.CodeInvalidArgument, "wallet", "sig: %v", err)
+	}
+	addr, err := core.DecodeAddress(signer)
+	if err != nil {
+		return false, core.NewError(core.CodeInvalidArgument, "wallet", "signer: %v", err)
+	}
+	return core.VerifyTypedData(td, raw, addr)
+}
@@ -2,11 +2,15 @@ package services
 
 import (
 	core "synnergy-network/core"
+	"synnergy-network/pkg/sdk"
 	"synnergy-network/walletserver/smartcontracts"
 )
 
 // WalletService wraps core wallet operations used by the HTTP API.
-type WalletService struct{}
+type WalletService struct {
+	node   *sdk.Client        // set by UseNode; nil without a node
+	nonces *core.NonceManager // set by StartNonceManager
+}
 
 func NewService() *WalletService { return &WalletService{} }
 